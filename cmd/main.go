@@ -1,24 +1,59 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jmoiron/sqlx"
 	"github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 
 	_ "music-library/docs"
+	"music-library/internal/acoustid"
+	"music-library/internal/analytics"
 	"music-library/internal/api"
+	"music-library/internal/api/middleware"
+	"music-library/internal/auth"
+	"music-library/internal/breaker"
+	"music-library/internal/broker"
+	"music-library/internal/budget"
+	"music-library/internal/captcha"
+	"music-library/internal/capture"
+	"music-library/internal/classifier"
+	"music-library/internal/config"
+	"music-library/internal/embeddings"
+	"music-library/internal/jobs"
+	"music-library/internal/lyrics"
+	"music-library/internal/metrics"
+	"music-library/internal/migrator"
+	"music-library/internal/models"
+	"music-library/internal/outbox"
 	"music-library/internal/repository"
+	"music-library/internal/routes"
+	"music-library/internal/rpc"
 	"music-library/internal/service"
+	"music-library/internal/spotify"
+	"music-library/internal/standby"
+	"music-library/internal/tracing"
+	"music-library/internal/webhooks"
 )
 
 // @title Music Library API
@@ -26,8 +61,26 @@ import (
 // @description API for managing music library
 // @host localhost:8080
 // @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token from POST /auth/login, sent as "Bearer <token>"
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name X-Admin-Token
+// @description The ADMIN_TOKEN of the deployment, also accepted as a bearer token
 func main() {
-	logger, err := zap.NewDevelopment()
+	mockMode := flag.Bool("mock", false, "serve canned example responses without a database")
+	backfillMode := flag.Bool("backfill", false, "normalize songs written under the legacy data conventions and exit")
+	dryRun := flag.Bool("dry-run", false, "with -backfill, report the legacy rows without changing them")
+	forceMigration := flag.String("force-migration", "", "clear a dirty migration state by forcing this version, then apply the pending migrations and exit")
+	flag.Parse()
+
+	// The level is reloadable, see registerReloadables
+	logLevel := zap.NewAtomicLevelAt(zap.DebugLevel)
+	loggerConfig := zap.NewDevelopmentConfig()
+	loggerConfig.Level = logLevel
+	logger, err := loggerConfig.Build()
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
@@ -36,83 +89,965 @@ func main() {
 	logger.Info("Starting application...")
 	logger.Debug("Initializing logger")
 
-	dbHost := getEnv("DB_HOST", "postgres")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "postgres")
-	dbPassword := getEnv("DB_PASSWORD", "123456")
-	dbName := getEnv("DB_NAME", "music_library")
+	timeouts, err := service.TimeoutConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid timeout configuration", zap.Error(err))
+	}
 
-	logger.Debug("Fetching environment variables", zap.String("DB_HOST", dbHost), zap.String("DB_PORT", dbPort))
+	if *mockMode {
+		runMockServer(logger, timeouts)
+		return
+	}
 
-	sqlxConnStr := "host=" + dbHost + " port=" + dbPort + " user=" + dbUser + " password=" + dbPassword + " dbname=" + dbName + " sslmode=disable"
-	logger.Info("Connection string for sqlx", zap.String("sqlxConnStr", sqlxConnStr))
+	dbDriver := getEnv("DB_DRIVER", "postgres")
+	var db *sqlx.DB
+	var mongoDB *mongo.Database
+	var migrateConnStr, migrationURL string
+	switch dbDriver {
+	case "postgres":
+		dbHost := getEnv("DB_HOST", "postgres")
+		dbPort := getEnv("DB_PORT", "5432")
+		dbUser := getEnv("DB_USER", "postgres")
+		dbPassword := getEnv("DB_PASSWORD", "123456")
+		dbName := getEnv("DB_NAME", "music_library")
 
-	migrateConnStr := "postgres://" + dbUser + ":" + dbPassword + "@" + dbHost + ":" + dbPort + "/" + dbName + "?sslmode=disable"
-	logger.Info("Connection string for migrate", zap.String("migrateConnStr", migrateConnStr))
+		logger.Debug("Fetching environment variables", zap.String("DB_HOST", dbHost), zap.String("DB_PORT", dbPort))
 
-	logger.Debug("Attempting to connect to database")
-	var db *sqlx.DB
-	for i := 0; i < 10; i++ {
-		db, err = sqlx.Connect("postgres", sqlxConnStr)
-		if err == nil {
-			if err := db.Ping(); err == nil {
+		sqlxConnStr := "host=" + dbHost + " port=" + dbPort + " user=" + dbUser + " password=" + dbPassword + " dbname=" + dbName + " sslmode=disable"
+		logger.Info("Connection string for sqlx", zap.String("sqlxConnStr", sqlxConnStr))
+
+		migrateConnStr = "postgres://" + dbUser + ":" + dbPassword + "@" + dbHost + ":" + dbPort + "/" + dbName + "?sslmode=disable"
+		logger.Info("Connection string for migrate", zap.String("migrateConnStr", migrateConnStr))
+		migrationURL = "file:///app/migrations"
+
+		logger.Debug("Attempting to connect to database")
+		for i := 0; i < 10; i++ {
+			db, err = sqlx.Connect("postgres", sqlxConnStr)
+			if err == nil {
+				if err := db.Ping(); err == nil {
+					break
+				}
+			}
+			logger.Warn("Failed to connect to database, retrying...", zap.Error(err), zap.Int("attempt", i+1))
+			time.Sleep(5 * time.Second)
+		}
+		if err != nil {
+			logger.Fatal("Failed to connect to database after retries", zap.Error(err))
+		}
+	case "sqlite":
+		// A single file, for deployments too small to run a database server
+		dbPath := getEnv("DB_PATH", "music_library.db")
+		logger.Info("Opening SQLite database", zap.String("DB_PATH", dbPath))
+		db, err = repository.OpenSQLite(dbPath)
+		if err != nil {
+			logger.Fatal("Failed to open database", zap.String("path", dbPath), zap.Error(err))
+		}
+		migrateConnStr = "sqlite3://" + dbPath
+		migrationURL = "file:///app/migrations/sqlite"
+	case "mysql":
+		// MySQL 8.0 or MariaDB 10.6 and later, for infrastructure standardized on them
+		dbHost := getEnv("DB_HOST", "mysql")
+		dbPort := getEnv("DB_PORT", "3306")
+		dbUser := getEnv("DB_USER", "root")
+		dbPassword := getEnv("DB_PASSWORD", "123456")
+		dbName := getEnv("DB_NAME", "music_library")
+
+		logger.Debug("Fetching environment variables", zap.String("DB_HOST", dbHost), zap.String("DB_PORT", dbPort))
+
+		dsn := dbUser + ":" + dbPassword + "@tcp(" + dbHost + ":" + dbPort + ")/" + dbName
+		migrateConnStr = "mysql://" + dsn + "?multiStatements=true"
+		migrationURL = "file:///app/migrations/mysql"
+
+		logger.Debug("Attempting to connect to database")
+		for i := 0; i < 10; i++ {
+			db, err = repository.OpenMySQL(dsn)
+			if err == nil {
+				break
+			}
+			logger.Warn("Failed to connect to database, retrying...", zap.Error(err), zap.Int("attempt", i+1))
+			time.Sleep(5 * time.Second)
+		}
+		if err != nil {
+			logger.Fatal("Failed to connect to database after retries", zap.Error(err))
+		}
+	case "mongodb":
+		// MongoDB 4.4 or later, run as a replica set since the repository relies on multi-document transactions
+		dbURI := getEnv("DB_URI", "mongodb://mongo:27017/?replicaSet=rs0")
+		dbName := getEnv("DB_NAME", "music_library")
+
+		uri, err := url.Parse(dbURI)
+		if err != nil {
+			logger.Fatal("Invalid DB_URI", zap.Error(err))
+		}
+		// The migrate driver takes the database from the URI path
+		uri.Path = "/" + dbName
+		migrateConnStr = uri.String()
+		migrationURL = "file:///app/migrations/mongodb"
+
+		logger.Debug("Attempting to connect to database")
+		for i := 0; i < 10; i++ {
+			mongoDB, err = repository.OpenMongo(dbURI, dbName)
+			if err == nil {
 				break
 			}
+			logger.Warn("Failed to connect to database, retrying...", zap.Error(err), zap.Int("attempt", i+1))
+			time.Sleep(5 * time.Second)
+		}
+		if err != nil {
+			logger.Fatal("Failed to connect to database after retries", zap.Error(err))
 		}
-		logger.Warn("Failed to connect to database, retrying...", zap.Error(err), zap.Int("attempt", i+1))
-		time.Sleep(5 * time.Second)
+	default:
+		logger.Fatal("DB_DRIVER must be postgres, sqlite, mysql or mongodb", zap.String("DB_DRIVER", dbDriver))
 	}
-	if err != nil {
-		logger.Fatal("Failed to connect to database after retries", zap.Error(err))
+	closeDB := func() error {
+		if mongoDB != nil {
+			return mongoDB.Client().Disconnect(context.Background())
+		}
+		return db.Close()
 	}
-	defer db.Close()
 
 	logger.Info("Successfully connected to database")
 
-	migrationURL := "file:///app/migrations"
 	logger.Info("Attempting to initialize migrations with URL", zap.String("migrationURL", migrationURL))
 	logger.Debug("Running migrations")
 
-	migrations, err := migrate.New(migrationURL, migrateConnStr)
+	migrations, err := migrator.New(migrationURL, migrateConnStr, logger)
 	if err != nil {
 		logger.Fatal("Failed to initialize migrations", zap.Error(err))
 	}
-
-	if err := migrations.Up(); err != nil {
-		if err == migrate.ErrNoChange {
-			logger.Info("No migrations to apply")
-		} else {
-			logger.Error("Migration failed", zap.Error(err))
-			logger.Fatal("Application cannot start due to migration failure", zap.Error(err))
+	// Environment migrations run after the core ones, which create the tables they seed or index
+	sets := []*migrator.Migrator{migrations}
+	environment, err := migrator.EnvironmentConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid migration environment", zap.Error(err))
+	}
+	if environment.Name != "" && dbDriver != "postgres" {
+		// The environment migrations are written for PostgreSQL
+		logger.Warn("Environment migrations are only supported with PostgreSQL, skipping them",
+			zap.String("environment", environment.Name), zap.String("DB_DRIVER", dbDriver))
+	} else if environment.Name != "" {
+		logger.Info("Applying environment migrations", zap.String("environment", environment.Name))
+		environmentMigrations, err := migrator.NewEnvironment(migrationURL, migrateConnStr, environment, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize environment migrations", zap.Error(err))
 		}
-	} else {
-		logger.Info("Migrations applied successfully")
-		migrations.Close()
+		sets = append(sets, environmentMigrations)
+	}
+	if *forceMigration != "" {
+		runForceMigration(logger, sets, *forceMigration)
+		closeMigrations(sets)
+		closeDB()
+		return
+	}
+	err = migrateUp(sets)
+	closeMigrations(sets)
+	var dirty *migrator.DirtyError
+	if errors.As(err, &dirty) {
+		// Crashing would only restart into the same state; stay up but unready until an operator recovers
+		logger.Error("Database migration is dirty, serving in maintenance mode", zap.String("migration_set", dirty.Set), zap.Uint("dirty_version", dirty.Version),
+			zap.String("recovery", fmt.Sprintf("verify the schema, then run with -force-migration=%d if the migration was fully applied "+
+				"or -force-migration=%d if it was not", dirty.Version, dirty.Previous)))
+		runMaintenanceServer(logger, dirty, timeouts)
+		closeDB()
+		return
+	}
+	if err != nil {
+		logger.Fatal("Application cannot start due to migration failure", zap.Error(err))
 	}
 
 	logger.Debug("Initializing dependencies")
-	repo := repository.NewPostgresRepository(db, logger)
-	svc := service.NewMusicService(repo, logger, &http.Client{})
+	var backend repository.Repository = repository.NewPostgresRepository(db, logger)
+	switch dbDriver {
+	case "sqlite":
+		backend = repository.NewSQLiteRepository(db, logger)
+	case "mysql":
+		backend = repository.NewMySQLRepository(db, logger)
+	case "mongodb":
+		backend = repository.NewMongoRepository(mongoDB, logger)
+	}
+	repo := repository.NewInstrumentedRepository(backend, logger,
+		getEnvInt(logger, "DB_SERIALIZATION_RETRIES", repository.DefaultSerializationRetries))
+	captureConfig, err := capture.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid API capture configuration", zap.Error(err))
+	}
+	recorder := capture.NewRecorder(repo, logger, captureConfig)
+	if captureConfig.SampleRate > 0 {
+		logger.Warn("Capturing external API exchanges", zap.Float64("sample_rate", captureConfig.SampleRate))
+	}
+	svc := service.NewMusicService(repo, logger, tracing.Wrap(service.ExternalAPIProvider, recorder.Wrap(service.ExternalAPIProvider, &http.Client{}), logger))
+	svc.ConfigureTimeouts(timeouts)
+	if *backfillMode {
+		runBackfill(logger, svc, *dryRun)
+		closeDB()
+		return
+	}
+	svc.ConfigureEnrichment(service.EnrichmentConfig{
+		Concurrency:   getEnvInt(logger, "ENRICH_CONCURRENCY", service.DefaultEnrichmentConfig.Concurrency),
+		RatePerSecond: float64(getEnvInt(logger, "ENRICH_RATE_PER_SECOND", int(service.DefaultEnrichmentConfig.RatePerSecond))),
+	})
+	svc.ConfigureImport(service.ImportConfig{
+		BufferSize:      getEnvInt(logger, "IMPORT_BUFFER_SIZE", service.DefaultImportConfig.BufferSize),
+		ValidateWorkers: getEnvInt(logger, "IMPORT_VALIDATE_WORKERS", service.DefaultImportConfig.ValidateWorkers),
+		BatchSize:       getEnvInt(logger, "IMPORT_BATCH_SIZE", service.DefaultImportConfig.BatchSize),
+	})
+	svc.ConfigureVerseDelimiter(getEnv("VERSE_DELIMITER", service.DefaultVerseDelimiter))
+	svc.ConfigureBudget(budget.NewManager(repo, logger, map[string]budget.Limit{
+		service.ExternalAPIProvider: {
+			PerMinute: getEnvInt(logger, "EXTERNAL_API_BUDGET_PER_MINUTE", 0),
+			PerDay:    getEnvInt(logger, "EXTERNAL_API_BUDGET_PER_DAY", 0),
+		},
+	}))
+	svc.ConfigureResilience(service.RetryConfig{
+		Attempts:  getEnvInt(logger, "EXTERNAL_API_RETRY_ATTEMPTS", service.DefaultRetryConfig.Attempts),
+		BaseDelay: getEnvDuration(logger, "EXTERNAL_API_RETRY_BASE_DELAY", service.DefaultRetryConfig.BaseDelay),
+		MaxDelay:  getEnvDuration(logger, "EXTERNAL_API_RETRY_MAX_DELAY", service.DefaultRetryConfig.MaxDelay),
+	}, breaker.New(service.ExternalAPIProvider, breaker.Config{
+		FailureThreshold: getEnvInt(logger, "EXTERNAL_API_BREAKER_FAILURES", breaker.DefaultConfig.FailureThreshold),
+		OpenTimeout:      getEnvDuration(logger, "EXTERNAL_API_BREAKER_OPEN_TIMEOUT", breaker.DefaultConfig.OpenTimeout),
+		HalfOpenCalls:    getEnvInt(logger, "EXTERNAL_API_BREAKER_HALF_OPEN_CALLS", breaker.DefaultConfig.HalfOpenCalls),
+	}, logger))
+	fallback, err := service.FallbackConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid fallback configuration", zap.Error(err))
+	}
+	svc.ConfigureFallback(fallback)
+	provider, err := service.ProviderConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid external API configuration", zap.Error(err))
+	}
+	svc.ConfigureProvider(provider)
+	enricher, err := svc.EnrichmentProviderFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid enrichment provider configuration", zap.Error(err))
+	}
+	lyricsClient, err := lyrics.FromEnv(tracing.Wrap(service.EnrichmentProviderLyrics, recorder.Wrap(service.EnrichmentProviderLyrics, &http.Client{}), logger))
+	if err != nil {
+		logger.Fatal("Invalid lyrics provider configuration", zap.Error(err))
+	}
+	if lyricsClient != nil {
+		enricher = service.ChainProvider{enricher, svc.NewLyricsProvider(lyricsClient)}
+	}
+	logger.Info("Using enrichment provider", zap.String("provider", enricher.Name()))
+	svc.ConfigureEnrichmentProvider(enricher)
+	popularity, err := service.PopularityConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid popularity configuration", zap.Error(err))
+	}
+	if err := svc.ConfigurePopularity(popularity); err != nil {
+		logger.Fatal("Invalid popularity configuration", zap.Error(err))
+	}
+	authConfig, err := auth.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid authentication configuration", zap.Error(err))
+	}
+	tokens := auth.NewTokens(authConfig)
+	svc.ConfigureAuth(tokens)
 	handler := api.NewHandler(svc, logger)
+	visibility, err := api.FieldVisibilityFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid field visibility configuration", zap.Error(err))
+	}
+	handler.ConfigureFieldVisibility(visibility)
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	jobManager := jobs.NewManager(repo, logger, jobs.Config{
+		Workers:          getEnvInt(logger, "JOB_WORKERS", jobs.DefaultConfig.Workers),
+		Capacity:         getEnvInt(logger, "JOB_QUEUE_CAPACITY", jobs.DefaultConfig.Capacity),
+		ProgressInterval: getEnvDuration(logger, "JOB_PROGRESS_INTERVAL", jobs.DefaultConfig.ProgressInterval),
+	})
+	jobManager.Start(jobsCtx)
+	svc.ConfigureJobs(jobManager)
+	svc.ConfigureStatsCache(getEnvDuration(logger, "GROUP_STATS_CACHE_TTL", service.DefaultStatsCacheTTL))
+	// Without DEGRADED_CACHE_SIZE the degraded mode is off and every endpoint fails while the database is down
+	svc.ConfigureDegradedMode(service.DegradedConfig{
+		CacheSize:     getEnvInt(logger, "DEGRADED_CACHE_SIZE", service.DefaultDegradedConfig.CacheSize),
+		CheckInterval: getEnvDuration(logger, "DEGRADED_CHECK_INTERVAL", service.DefaultDegradedConfig.CheckInterval),
+	})
+	svc.StartDatabaseMonitor(jobsCtx)
+	svc.StartEventPruning(jobsCtx, getEnvDuration(logger, "EVENT_RETENTION", service.DefaultEventRetention))
+	svc.ConfigureTrashRetention(getEnvDuration(logger, "TRASH_RETENTION", service.DefaultTrashRetention))
+	svc.StartTrashPurging(jobsCtx)
+	webhookConfig, err := webhooks.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid webhook configuration", zap.Error(err))
+	}
+	dispatcher := webhooks.NewDispatcher(repo, &http.Client{}, logger, webhookConfig)
+	dispatcher.Start(jobsCtx)
+	// Catalog changes are written to the outbox with the data they record, and the relay pushes them to the
+	// change hub and the webhooks once committed
+	outboxConfig, err := outbox.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid outbox configuration", zap.Error(err))
+	}
+	relay := outbox.NewRelay(repo, logger, outboxConfig)
+	svc.ConfigureOutbox(relay)
+	relay.Subscribe("webhooks", dispatcher.Enqueue)
+	// Without STANDBY_LOG_DIR the change feed is not copied to flat files
+	standbyConfig, err := standby.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid standby log configuration", zap.Error(err))
+	}
+	var standbyLog *standby.Log
+	if standbyConfig.Dir != "" {
+		if standbyLog, err = standby.NewLog(repo, &http.Client{}, logger, standbyConfig); err != nil {
+			logger.Fatal("Failed to create standby log", zap.Error(err))
+		}
+		standbyLog.Start(jobsCtx)
+		relay.Subscribe("standby", standbyLog.Append)
+	}
+	// Without BROKER_KIND the catalog events are not published to a message broker
+	brokerConfig, err := broker.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid broker configuration", zap.Error(err))
+	}
+	publisher, err := broker.New(brokerConfig, &http.Client{})
+	if err != nil {
+		logger.Fatal("Failed to create broker publisher", zap.Error(err))
+	}
+	if publisher != nil {
+		logger.Info("Publishing catalog events to the message broker", zap.String("broker", brokerConfig.Kind), zap.String("topic", brokerConfig.Topic))
+		relay.Subscribe("broker", broker.Subscriber(publisher, brokerConfig.Kind, brokerConfig.Topic))
+	}
+	relay.Start(jobsCtx)
+	svc.StartDigestScheduler(jobsCtx, api.DigestPeriod)
+	svc.StartViewFlusher(jobsCtx, getEnvDuration(logger, "VIEWS_FLUSH_INTERVAL", 30*time.Second))
+	svc.StartTrendingScheduler(jobsCtx, getEnvDuration(logger, "TRENDING_INTERVAL", 15*time.Minute))
+	svc.StartSearchTermsRefresher(jobsCtx, getEnvDuration(logger, "SEARCH_TERMS_INTERVAL", time.Hour))
+	exporter, err := analytics.ExporterFromEnv(&http.Client{})
+	if err != nil {
+		logger.Fatal("Invalid analytics configuration", zap.Error(err))
+	}
+	if exporter != nil {
+		svc.StartAnalyticsExport(jobsCtx, analytics.NewBatcher(exporter, logger, analytics.BatcherConfigFromEnv()))
+	}
+	embedder, err := embeddings.FromEnv(&http.Client{})
+	if err != nil {
+		logger.Fatal("Invalid embeddings configuration", zap.Error(err))
+	}
+	if embedder != nil {
+		svc.ConfigureEmbeddings(embedder)
+		svc.StartEmbeddingScheduler(jobsCtx, getEnvDuration(logger, "EMBEDDINGS_INTERVAL", 10*time.Minute))
+	}
+	songClassifier, err := classifier.FromEnv(&http.Client{})
+	if err != nil {
+		logger.Fatal("Invalid classifier configuration", zap.Error(err))
+	}
+	if songClassifier != nil {
+		svc.ConfigureClassifier(songClassifier)
+	}
+	spotifyClient, err := spotify.FromEnv(tracing.Wrap("spotify", recorder.Wrap("spotify", &http.Client{}), logger))
+	if err != nil {
+		logger.Fatal("Invalid Spotify configuration", zap.Error(err))
+	}
+	if spotifyClient != nil {
+		svc.ConfigureSpotify(spotifyClient)
+		svc.StartMetadataSync(jobsCtx, getEnvDuration(logger, "SPOTIFY_SYNC_INTERVAL", time.Hour))
+	}
+	enrichmentQueue, err := service.EnrichmentQueueConfigFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid enrichment queue configuration", zap.Error(err))
+	}
+	// ENRICHMENT_WORKERS=0 keeps enrichment on the insert path of AddSong
+	if enrichmentQueue.Workers > 0 {
+		svc.StartEnrichmentWorkers(jobsCtx, enrichmentQueue)
+	}
+	if acoustidClient := acoustid.FromEnv(tracing.Wrap("acoustid", recorder.Wrap("acoustid", &http.Client{}), logger)); acoustidClient != nil {
+		fpcalc := acoustid.FpcalcFromEnv()
+		logger.Info("Fingerprint matching enabled", zap.Bool("audio_uploads", fpcalc != nil))
+		svc.ConfigureAcoustID(acoustidClient, fpcalc)
+	}
+	svc.StartListenerRefresher(jobsCtx, getEnvDuration(logger, "POPULARITY_REFRESH_INTERVAL", time.Hour))
+	svc.StartReenrichmentScheduler(jobsCtx, getEnvDuration(logger, "REENRICH_INTERVAL", time.Hour), service.ReenrichmentConfig{
+		StaleAfter: getEnvDuration(logger, "REENRICH_STALE_AFTER", service.DefaultReenrichmentConfig.StaleAfter),
+		BatchSize:  getEnvInt(logger, "REENRICH_BATCH_SIZE", service.DefaultReenrichmentConfig.BatchSize),
+	})
 
 	logger.Debug("Configuring Gin router")
+	httpMetrics := middleware.NewMetrics()
+	limiter := newRateLimiter(logger)
+	middlewares := newMiddlewareRegistry(logger, httpMetrics, limiter, timeouts)
+	middlewares.Register(middleware.NameAuth, middleware.RequireUser(tokens, logger))
+	middlewares.Register(middleware.NameAuthOptional, middleware.OptionalUser(tokens, logger))
+	middlewares.Register(middleware.NameAdmin, middleware.AdminAuth(getEnv("ADMIN_TOKEN", ""), logger))
+	middlewares.Register(middleware.NameViewer, middleware.RequireRole(models.RoleViewer, logger))
+	middlewares.Register(middleware.NameEditor, middleware.RequireRole(models.RoleEditor, logger))
+	middlewares.Register(middleware.NameOwner, middleware.RequireRole(models.RoleAdmin, logger))
+	middlewares.Register(middleware.NameReadOnly, middleware.ReadOnly(svc.Degraded, logger))
+	verifier, err := captcha.FromEnv(&http.Client{Timeout: timeouts.ExternalAPI})
+	if err != nil {
+		logger.Fatal("Invalid captcha configuration", zap.Error(err))
+	}
+	if verifier != nil {
+		middlewares.Register(middleware.NameCaptcha, middleware.RequireHuman(verifier, logger))
+	}
+	chains, err := middlewares.Build(middleware.ChainsFromEnv())
+	if err != nil {
+		logger.Fatal("Invalid middleware configuration", zap.Error(err))
+	}
+
+	// CONFIG_FILE holds the reloadable settings to apply on SIGHUP or POST /admin/config/reload; they are
+	// loaded once now, so the file also takes effect at startup
+	reloader := config.NewReloader(config.FileSource(os.Getenv("CONFIG_FILE")))
+	registerReloadables(reloader, logLevel, limiter, svc, lyricsClient, spotifyClient)
+	if _, err := reloader.Reload(); err != nil {
+		logger.Fatal("Invalid reloadable configuration", zap.Error(err))
+	}
+	handler.ConfigureReloader(reloader)
+	watchReloadSignal(jobsCtx, logger, reloader)
+
 	gin.SetMode(gin.ReleaseMode)
-	r := gin.Default()
+	r := gin.New()
 	r.SetTrustedProxies([]string{"127.0.0.1"})
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	r.GET("/songs", handler.GetSongs)
-	r.POST("/songs", handler.AddSong)
-	r.GET("/songs/:id/verses", handler.GetVerses)
-	r.PUT("/songs/:id", handler.UpdateSong)
-	r.DELETE("/songs/:id", handler.DeleteSong)
-	r.POST("/songs/truncate", handler.TruncateSongs)
+	r.Use(chains[middleware.GroupGlobal]...)
+	readiness := &api.Readiness{}
+	routeManifest := &routes.Manifest{}
+	err = routes.Register(func() {
+		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+		r.GET("/healthz", handler.Healthz)
+		r.GET("/readyz", handler.Readyz(readiness))
+		// Scrapers authenticate with the admin token as a bearer token, like the /admin routes
+		r.Group("/metrics", chains[middleware.GroupAdmin]...).GET("", gin.WrapH(metrics.Handler()))
+
+		public := r.Group("/", chains[middleware.GroupPublic]...)
+		public.GET("/songs", handler.GetSongs)
+		public.HEAD("/songs", handler.CountSongs)
+		public.GET("/songs/exists", handler.SongExists)
+		public.GET("/songs/trending", handler.GetTrendingSongs)
+		public.GET("/songs/search", handler.SearchSongs)
+		public.GET("/songs/:id/verses", handler.GetVerses)
+		public.GET("/songs/:id/subtitles", handler.GetSubtitles)
+		public.GET("/songs/:id/enrichment-status", handler.GetEnrichmentStatus)
+		public.GET("/songs/:id/tags", handler.GetSongTags)
+		public.GET("/calendar.ics", handler.GetReleaseCalendar)
+		public.GET("/digests/latest", handler.GetLatestDigest)
+		public.GET("/changes/poll", handler.PollChanges)
+		public.GET("/jobs/:id", handler.GetJob)
+		public.GET("/groups/:name/stats", handler.GetGroupStats)
+		public.GET("/albums", handler.GetAlbums)
+		public.GET("/albums/:id", handler.GetAlbum)
+		public.GET("/albums/:id/songs", handler.GetAlbumSongs)
+		public.GET("/artists", handler.GetArtists)
+		public.GET("/artists/:id", handler.GetArtist)
+		public.GET("/artists/:id/songs", handler.GetArtistSongs)
+		public.GET("/genres", handler.GetGenres)
+		public.GET("/genres/:id", handler.GetGenre)
+		public.GET("/songs/:id/genres", handler.GetSongGenres)
+		public.GET("/songs/:id/titles", handler.GetSongTitles)
+		public.GET("/songs/:id/revisions", handler.GetSongRevisions)
+
+		authentication := r.Group("/auth", chains[middleware.GroupAuth]...)
+		authentication.POST("/register", handler.Register)
+		authentication.POST("/login", handler.Login)
+		authentication.POST("/refresh", handler.RefreshToken)
+
+		account := r.Group("/me", chains[middleware.GroupAccount]...)
+		account.GET("/preferences", handler.GetPreferences)
+		account.PUT("/preferences", handler.UpdatePreferences)
+		account.GET("/overrides/:id", handler.GetSongOverride)
+		account.PUT("/overrides/:id", handler.SaveSongOverride)
+		account.DELETE("/overrides/:id", handler.DeleteSongOverride)
+
+		// Ratings and favorites belong to the user, so they take the account chain under the song's path
+		ratings := r.Group("/songs", chains[middleware.GroupAccount]...)
+		ratings.POST("/:id/rating", handler.RateSong)
+		ratings.DELETE("/:id/rating", handler.DeleteSongRating)
+		ratings.POST("/:id/favorite", handler.FavoriteSong)
+		ratings.DELETE("/:id/favorite", handler.UnfavoriteSong)
+
+		submissions := r.Group("/", chains[middleware.GroupSubmit]...)
+		submissions.POST("/songs", handler.AddSong)
 
+		writes := r.Group("/", chains[middleware.GroupWrite]...)
+		writes.POST("/songs/bulk", handler.AddSongs)
+		writes.POST("/songs/fingerprints", handler.MatchFingerprints)
+		writes.PUT("/songs/:id", handler.UpdateSong)
+		writes.PATCH("/songs/:id", handler.PatchSong)
+		writes.POST("/songs/:id/enrich", handler.ReenrichSong)
+		writes.POST("/songs/:id/tags", handler.AddSongTags)
+		writes.DELETE("/songs/:id/tags/:tag", handler.RemoveSongTag)
+		writes.POST("/songs/tags/bulk", handler.BulkTagSongs)
+		writes.POST("/albums", handler.CreateAlbum)
+		writes.PUT("/albums/:id", handler.UpdateAlbum)
+		writes.POST("/artists", handler.CreateArtist)
+		writes.PUT("/artists/:id", handler.UpdateArtist)
+		writes.POST("/genres", handler.CreateGenre)
+		writes.PUT("/genres/:id", handler.UpdateGenre)
+		writes.PUT("/songs/:id/genres", handler.SetSongGenres)
+		writes.PUT("/songs/:id/titles/:lang", handler.SetSongTitle)
+		writes.DELETE("/songs/:id/titles/:lang", handler.DeleteSongTitle)
+		writes.POST("/songs/:id/revisions/:rev/restore", handler.RestoreSongRevision)
+
+		imports := r.Group("/songs/import", chains[middleware.GroupImport]...)
+		imports.POST("", handler.ImportSongs)
+		imports.POST("/preview", handler.PreviewImport)
+
+		exports := r.Group("/songs/export", chains[middleware.GroupExport]...)
+		exports.GET("", handler.ExportSongs)
+
+		events := r.Group("/ws", chains[middleware.GroupEvents]...)
+		events.GET("/events", handler.StreamEvents)
+		streams := r.Group("/songs", chains[middleware.GroupEvents]...)
+		streams.GET("/stream", handler.StreamSongEvents)
+
+		hooks := r.Group("/webhooks", chains[middleware.GroupWebhooks]...)
+		hooks.POST("", handler.CreateWebhook)
+		hooks.GET("", handler.GetWebhooks)
+		hooks.DELETE("/:id", handler.DeleteWebhook)
+		hooks.GET("/:id/deliveries", handler.GetWebhookDeliveries)
+		hooks.POST("/:id/deliveries/:delivery/retry", handler.RetryWebhookDelivery)
+		hooks.POST("/:id/secret/rotate", handler.RotateWebhookSecret)
+
+		destructive := r.Group("/", chains[middleware.GroupDestructive]...)
+		destructive.DELETE("/songs/:id", handler.DeleteSong)
+		destructive.POST("/songs/truncate", handler.TruncateSongs)
+		destructive.DELETE("/albums/:id", handler.DeleteAlbum)
+		destructive.DELETE("/artists/:id", handler.DeleteArtist)
+		destructive.DELETE("/genres/:id", handler.DeleteGenre)
+
+		// Deleted songs go to the trash, managed by those allowed to delete them
+		trash := r.Group("/trash", chains[middleware.GroupDestructive]...)
+		trash.GET("", handler.GetTrash)
+		trash.POST("/:id/restore", handler.RestoreTrashedSong)
+		trash.DELETE("/:id", handler.PurgeTrashedSong)
+
+		admin := r.Group("/admin", chains[middleware.GroupAdmin]...)
+		admin.GET("/http-metrics", httpMetrics.Handler())
+		admin.GET("/users", handler.GetUsers)
+		admin.PUT("/users/:id/role", handler.SetUserRole)
+		admin.PUT("/songs/:id/legal-hold", handler.SetLegalHold)
+		admin.GET("/query-log", handler.GetQueryLog)
+		admin.GET("/providers", handler.GetProviderBudgets)
+		admin.GET("/api-captures", handler.GetAPICaptures)
+		admin.GET("/config/export", handler.ExportConfig)
+		admin.POST("/config/import", handler.ImportConfig)
+		admin.GET("/config/live", handler.GetLiveConfig)
+		admin.POST("/config/reload", handler.ReloadConfig)
+		admin.POST("/similarity-report", handler.StartSimilarityReport)
+		admin.GET("/similarity-report", handler.GetSimilarityReport)
+		admin.POST("/enrich-all", handler.StartEnrichAll)
+		admin.GET("/jobs/:id", handler.GetJob)
+		admin.GET("/classifications", handler.GetClassificationSuggestions)
+		admin.POST("/classifications/:id/accept", handler.AcceptClassificationSuggestion)
+		admin.POST("/classifications/:id/reject", handler.RejectClassificationSuggestion)
+		admin.GET("/routes", handler.GetRoutes(routeManifest))
+		admin.GET("/snapshots", handler.GetSnapshots)
+		admin.POST("/snapshots/:id/restore", handler.RestoreSnapshot)
+	})
+	if err != nil {
+		logger.Fatal("Invalid route table", zap.Error(err))
+	}
+	grpcServer := rpc.NewGRPCServer(svc, tokens, logger)
+	*routeManifest = checkRoutes(logger, r, grpcServer)
+
+	stopGRPC := serveGRPC(logger, grpcServer, ":"+getEnv("GRPC_PORT", "9090"))
 	port := getEnv("PORT", "8080")
-	logger.Info("Starting server", zap.String("port", port))
-	logger.Debug("Server starting on port", zap.String("port", port))
-	if err := r.Run(":" + port); err != nil {
+	if err := runServer(logger, &http.Server{Addr: ":" + port, Handler: r}, readiness); err != nil {
 		logger.Fatal("Failed to start server", zap.Error(err))
 	}
+	stopGRPC()
+
+	// Requests are finished, so the background jobs can be stopped before the pool they use is closed
+	stopJobs()
+	svc.Wait()
+	jobManager.Wait()
+	dispatcher.Wait()
+	relay.Wait()
+	if standbyLog != nil {
+		standbyLog.Wait()
+	}
+	if publisher != nil {
+		if err := publisher.Close(); err != nil {
+			logger.Error("Failed to close broker publisher", zap.Error(err))
+		}
+	}
+	if err := closeDB(); err != nil {
+		logger.Error("Failed to close database connections", zap.Error(err))
+	}
+	logger.Info("Server stopped")
+}
+
+// runServer serves until SIGTERM or SIGINT, then shuts down gracefully: readiness fails first so load balancers
+// stop routing new requests here, and in-flight requests get SHUTDOWN_TIMEOUT to finish before their contexts
+// are cancelled and the remaining connections closed. It returns an error only when the server cannot start.
+func runServer(logger *zap.Logger, srv *http.Server, readiness *api.Readiness) error {
+	drainPeriod := getEnvDuration(logger, "DRAIN_PERIOD", 5*time.Second)
+	shutdownTimeout := getEnvDuration(logger, "SHUTDOWN_TIMEOUT", 30*time.Second)
+
+	// Every request context derives from requestsCtx, so cancelling it reaches handlers that are still running
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	srv.BaseContext = func(net.Listener) context.Context { return requestsCtx }
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stopSignals()
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("Starting server", zap.String("addr", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-signals.Done():
+	}
+	// A second signal kills the process instead of waiting for the drain
+	stopSignals()
+
+	logger.Info("Shutdown signal received, draining", zap.Duration("drain_period", drainPeriod))
+	readiness.SetDraining()
+	time.Sleep(drainPeriod)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("In-flight requests did not finish in time, cancelling them", zap.Error(err), zap.Duration("timeout", shutdownTimeout))
+		cancelRequests()
+		if err := srv.Close(); err != nil {
+			logger.Error("Failed to close connections", zap.Error(err))
+		}
+	}
+	logger.Info("HTTP server shut down")
+	return nil
+}
+
+// serveGRPC serves the gRPC API for internal consumers on its own address in the background. The returned
+// function stops it, letting in-flight calls finish for up to SHUTDOWN_TIMEOUT before cancelling them.
+func serveGRPC(logger *zap.Logger, server *grpc.Server, addr string) func() {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Fatal("Failed to listen for gRPC", zap.String("addr", addr), zap.Error(err))
+	}
+	go func() {
+		logger.Info("Starting gRPC server", zap.String("addr", addr))
+		if err := server.Serve(listener); err != nil {
+			logger.Error("gRPC server failed", zap.Error(err))
+		}
+	}()
+	return func() {
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		shutdownTimeout := getEnvDuration(logger, "SHUTDOWN_TIMEOUT", 30*time.Second)
+		select {
+		case <-stopped:
+		case <-time.After(shutdownTimeout):
+			logger.Warn("gRPC calls did not finish in time, cancelling them", zap.Duration("timeout", shutdownTimeout))
+			server.Stop()
+		}
+		logger.Info("gRPC server shut down")
+	}
+}
+
+// migrateUp applies the pending migrations of every set in order, stopping at the first failure
+func migrateUp(sets []*migrator.Migrator) error {
+	for _, set := range sets {
+		if err := set.Up(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeMigrations releases the sources and database connections of the migration sets
+func closeMigrations(sets []*migrator.Migrator) {
+	for _, set := range sets {
+		set.Close()
+	}
+}
+
+// runForceMigration forces the version of the dirty migration set, which must be the interrupted migration or
+// the one before it, and resumes the pending migrations of every set
+func runForceMigration(logger *zap.Logger, sets []*migrator.Migrator, value string) {
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		logger.Fatal("Invalid -force-migration version", zap.String("version", value))
+	}
+	forced := false
+	for _, set := range sets {
+		status, err := set.Status()
+		if err != nil {
+			logger.Fatal("Failed to read migration status", zap.Error(err))
+		}
+		if !status.Dirty {
+			continue
+		}
+		if err := set.Force(version); err != nil {
+			logger.Fatal("Failed to force migration version", zap.Error(err))
+		}
+		forced = true
+		break
+	}
+	if !forced {
+		logger.Fatal("Failed to force migration version", zap.Error(migrator.ErrNotDirty))
+	}
+	if err := migrateUp(sets); err != nil {
+		logger.Fatal("Failed to resume migrations", zap.Error(err))
+	}
+	for _, set := range sets {
+		status, err := set.Status()
+		if err != nil {
+			logger.Fatal("Failed to read migration status", zap.Error(err))
+		}
+		logger.Info("Migrations recovered", zap.String("migration_set", set.Name()), zap.Uint("version", status.Version))
+	}
+}
+
+// runMaintenanceServer keeps the instance alive but unready while the database is dirty: probes and metrics
+// answer, every other request is refused
+func runMaintenanceServer(logger *zap.Logger, dirty *migrator.DirtyError, timeouts service.TimeoutConfig) {
+	middlewares := newMiddlewareRegistry(logger, middleware.NewMetrics(), newRateLimiter(logger), timeouts)
+	global, err := middlewares.Chain(middleware.ChainsFromEnv()[middleware.GroupGlobal])
+	if err != nil {
+		logger.Fatal("Invalid middleware configuration", zap.Error(err))
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(global...)
+	r.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	r.GET("/readyz", func(c *gin.Context) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "migration dirty", "dirty_version": dirty.Version})
+	})
+	r.GET("/metrics", middleware.AdminAuth(getEnv("ADMIN_TOKEN", ""), logger), gin.WrapH(metrics.Handler()))
+	r.NoRoute(func(c *gin.Context) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Service unavailable: database migration needs recovery"})
+	})
+
+	port := getEnv("PORT", "8080")
+	readiness := &api.Readiness{}
+	if err := runServer(logger, &http.Server{Addr: ":" + port, Handler: r}, readiness); err != nil {
+		logger.Fatal("Failed to start maintenance server", zap.Error(err))
+	}
+	logger.Info("Maintenance server stopped")
+}
+
+// runBackfill normalizes the legacy rows, or only reports them on a dry run, and prints the report as JSON
+func runBackfill(logger *zap.Logger, svc *service.MusicService, dryRun bool) {
+	report, err := svc.BackfillLegacyRows(context.Background(), dryRun)
+	if err != nil {
+		logger.Fatal("Backfill failed", zap.Error(err))
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logger.Fatal("Failed to write backfill report", zap.Error(err))
+	}
+}
+
+// runMockServer serves example responses for every endpoint, without a database or external API
+func runMockServer(logger *zap.Logger, timeouts service.TimeoutConfig) {
+	logger.Info("Running in mock mode")
+	middlewares := newMiddlewareRegistry(logger, middleware.NewMetrics(), newRateLimiter(logger), timeouts)
+	global, err := middlewares.Chain(middleware.ChainsFromEnv()[middleware.GroupGlobal])
+	if err != nil {
+		logger.Fatal("Invalid middleware configuration", zap.Error(err))
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(global...)
+	registerMockServerRoutes(r, logger)
+
+	port := getEnv("PORT", "8080")
+	if err := runServer(logger, &http.Server{Addr: ":" + port, Handler: r}, &api.Readiness{}); err != nil {
+		logger.Fatal("Failed to start mock server", zap.Error(err))
+	}
+	logger.Info("Mock server stopped")
+}
+
+// registerMockServerRoutes registers the routes of the mock server
+func registerMockServerRoutes(r gin.IRouter, logger *zap.Logger) {
+	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	api.RegisterMockRoutes(r, logger)
+}
+
+// checkRoutes runs the router self-test against the swagger documentation, the mock server and the gRPC
+// services and returns the route manifest. The issues found, including routes missing from the documentation,
+// are logged, or fail the startup when ROUTES_STRICT is true.
+func checkRoutes(logger *zap.Logger, r *gin.Engine, grpcServer *grpc.Server) routes.Manifest {
+	spec, err := swag.ReadDoc()
+	if err != nil {
+		logger.Fatal("Failed to read the swagger documentation", zap.Error(err))
+	}
+	mock := gin.New()
+	registerMockServerRoutes(mock, zap.NewNop())
+	manifest, err := routes.Check(r.Routes(), routes.Sources{Spec: []byte(spec), Mock: mock.Routes(), GRPC: grpcServer.GetServiceInfo(),
+		Internal: []string{"GET /swagger/*any", "GET /metrics"}})
+	if err != nil {
+		logger.Fatal("Router self-test failed", zap.Error(err))
+	}
+	strict := getEnv("ROUTES_STRICT", "false") == "true"
+	for _, issue := range manifest.Issues {
+		fields := []zap.Field{zap.String("kind", issue.Kind), zap.String("method", issue.Method), zap.String("path", issue.Path), zap.String("detail", issue.Detail)}
+		if strict {
+			logger.Error("Router self-test found an issue", fields...)
+		} else {
+			logger.Warn("Router self-test found an issue", fields...)
+		}
+	}
+	if strict && len(manifest.Issues) > 0 {
+		logger.Fatal("Router self-test failed", zap.Int("issues", len(manifest.Issues)))
+	}
+	logger.Info("Router self-test finished", zap.Int("routes", len(manifest.Routes)), zap.Int("documented", manifest.Documented),
+		zap.Int("grpc_methods", len(manifest.GRPCMethods)), zap.Int("issues", len(manifest.Issues)))
+	return manifest
+}
+
+// newMiddlewareRegistry registers the middlewares that need no service dependencies, configured from the environment
+func newMiddlewareRegistry(logger *zap.Logger, metrics *middleware.Metrics, limiter *middleware.RateLimiter, timeouts service.TimeoutConfig) *middleware.Registry {
+	middlewares := middleware.NewRegistry()
+	middlewares.Register(middleware.NameRecovery, middleware.Recovery(logger))
+	middlewares.Register(middleware.NameRequestID, middleware.RequestID())
+	middlewares.Register(middleware.NameLogger, middleware.Logger(logger))
+	middlewares.Register(middleware.NameMetrics, metrics.Middleware())
+	middlewares.Register(middleware.NamePrometheus, middleware.Prometheus())
+	middlewares.Register(middleware.NameCORS, middleware.CORS(middleware.ParseOrigins(getEnv("CORS_ALLOWED_ORIGINS", ""))))
+	middlewares.Register(middleware.NameCompression, middleware.Compression())
+	middlewares.Register(middleware.NameTimeout, middleware.Timeout(timeouts.Request))
+	middlewares.Register(middleware.NameRateLimit, limiter.Middleware())
+	return middlewares
+}
+
+// newRateLimiter creates the rate limiter of the API, configured from the environment
+func newRateLimiter(logger *zap.Logger) *middleware.RateLimiter {
+	return middleware.NewRateLimiter(middleware.RateLimitConfig{
+		PerSecond: float64(getEnvInt(logger, "RATE_LIMIT_PER_SECOND", int(middleware.DefaultRateLimitConfig.PerSecond))),
+		Burst:     getEnvInt(logger, "RATE_LIMIT_BURST", middleware.DefaultRateLimitConfig.Burst),
+	}, logger)
+}
+
+// registerReloadables registers how each reloadable setting is checked and applied: the log level, the
+// rate limit, the group stats and listener count cache TTLs, the fallback data and the URLs of the external
+// API, and, when they were enabled at startup, the lyrics API and cache and the Spotify endpoints. Feature
+// flags that decide which providers are built, LYRICS_ENABLED among them, take effect on restart only.
+// Settings left unset return to their defaults on reload.
+func registerReloadables(reloader *config.Reloader, logLevel zap.AtomicLevel, limiter *middleware.RateLimiter, svc *service.MusicService,
+	lyricsClient *lyrics.Client, spotifyClient *spotify.Client) {
+	reloader.Register("log", func(snapshot config.Snapshot) (func(), error) {
+		level := zapcore.DebugLevel
+		if value := snapshot.Get("LOG_LEVEL"); value != "" {
+			var err error
+			if level, err = zapcore.ParseLevel(value); err != nil {
+				return nil, fmt.Errorf("LOG_LEVEL: %w", err)
+			}
+		}
+		return func() { logLevel.SetLevel(level) }, nil
+	})
+	reloader.Register("rate limit", func(snapshot config.Snapshot) (func(), error) {
+		perSecond, err := snapshot.Int("RATE_LIMIT_PER_SECOND", int(middleware.DefaultRateLimitConfig.PerSecond))
+		if err != nil {
+			return nil, err
+		}
+		burst, err := snapshot.Int("RATE_LIMIT_BURST", middleware.DefaultRateLimitConfig.Burst)
+		if err != nil {
+			return nil, err
+		}
+		return func() { limiter.Configure(middleware.RateLimitConfig{PerSecond: float64(perSecond), Burst: burst}) }, nil
+	})
+	reloader.Register("stats cache", func(snapshot config.Snapshot) (func(), error) {
+		ttl, err := snapshot.Duration("GROUP_STATS_CACHE_TTL", service.DefaultStatsCacheTTL)
+		if err != nil {
+			return nil, err
+		}
+		return func() { svc.ConfigureStatsCache(ttl) }, nil
+	})
+	reloader.Register("fallback", func(snapshot config.Snapshot) (func(), error) {
+		fallback, err := service.FallbackConfigFrom(snapshot.Get)
+		if err != nil {
+			return nil, err
+		}
+		return func() { svc.ConfigureFallback(fallback) }, nil
+	})
+	reloader.Register("external API", func(snapshot config.Snapshot) (func(), error) {
+		apiURL := snapshot.Get("EXTERNAL_API_URL")
+		if apiURL != "" {
+			if err := checkHTTPURL("EXTERNAL_API_URL", apiURL); err != nil {
+				return nil, err
+			}
+		}
+		return func() { svc.ConfigureExternalAPIURL(apiURL) }, nil
+	})
+	reloader.Register("popularity cache", func(snapshot config.Snapshot) (func(), error) {
+		ttl, err := snapshot.Duration("POPULARITY_CACHE_TTL", service.DefaultPopularityConfig.CacheTTL)
+		if err != nil {
+			return nil, err
+		}
+		return func() { svc.ConfigurePopularityCacheTTL(ttl) }, nil
+	})
+	if lyricsClient != nil {
+		reloader.Register("lyrics", func(snapshot config.Snapshot) (func(), error) {
+			apiURL, cacheCfg, err := lyrics.ConfigFrom(snapshot.Get)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkHTTPURL("LYRICS_API_URL", apiURL); err != nil {
+				return nil, err
+			}
+			return func() { lyricsClient.Configure(apiURL, cacheCfg) }, nil
+		})
+	}
+	if spotifyClient != nil {
+		reloader.Register("spotify", func(snapshot config.Snapshot) (func(), error) {
+			for _, name := range []string{"SPOTIFY_TOKEN_URL", "SPOTIFY_API_URL"} {
+				if value := snapshot.Get(name); value != "" {
+					if err := checkHTTPURL(name, value); err != nil {
+						return nil, err
+					}
+				}
+			}
+			tokenURL, apiURL := snapshot.Get("SPOTIFY_TOKEN_URL"), snapshot.Get("SPOTIFY_API_URL")
+			return func() { spotifyClient.Configure(tokenURL, apiURL) }, nil
+		})
+	}
+}
+
+// checkHTTPURL returns an error unless the value of the setting is an absolute http or https URL
+func checkHTTPURL(name, value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%s must be an http or https URL, got %q", name, value)
+	}
+	return nil
+}
+
+// watchReloadSignal reloads the reloadable settings on every SIGHUP until the context is done. A rejected
+// reload is logged and leaves the running configuration unchanged.
+func watchReloadSignal(ctx context.Context, logger *zap.Logger, reloader *config.Reloader) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hangups)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangups:
+			}
+			logger.Info("SIGHUP received, reloading configuration")
+			result, err := reloader.Reload()
+			if err != nil {
+				logger.Error("Configuration reload failed, keeping the running configuration", zap.Error(err))
+				continue
+			}
+			for _, change := range result.Changes {
+				logger.Info("Setting reloaded", zap.String("name", change.Name), zap.String("action", change.Action))
+			}
+			if len(result.Pending) > 0 {
+				logger.Warn("Changed settings take effect on restart only", zap.Strings("settings", result.Pending))
+			}
+			logger.Info("Configuration reloaded", zap.Int("version", result.Snapshot.Version), zap.Int("changes", len(result.Changes)))
+		}
+	}()
 }
 
 func getEnv(key, fallback string) string {
@@ -121,3 +1056,29 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvDuration(logger *zap.Logger, key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		logger.Warn("Invalid duration in environment, using default", zap.String("key", key), zap.String("value", value))
+		return fallback
+	}
+	return duration
+}
+
+func getEnvInt(logger *zap.Logger, key string, fallback int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		logger.Warn("Invalid number in environment, using default", zap.String("key", key), zap.String("value", value))
+		return fallback
+	}
+	return number
+}
@@ -0,0 +1,179 @@
+package acoustid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DefaultURL is the AcoustID lookup endpoint
+const DefaultURL = "https://api.acoustid.org/v2/lookup"
+
+// ErrNotFound is returned when AcoustID knows no recording for a fingerprint
+var ErrNotFound = errors.New("no matching recording")
+
+// Fingerprint is a Chromaprint fingerprint of a recording, with the recording's duration in seconds
+type Fingerprint struct {
+	Fingerprint string `json:"fingerprint" validate:"required" example:"AQADtMmybfGO8NCNEESLnzHyXNOHeHnG"`
+	Duration    int    `json:"duration" validate:"required,gt=0" example:"212"`
+}
+
+// Recording is the recording AcoustID matched a fingerprint to
+type Recording struct {
+	// ID is the MusicBrainz recording ID
+	ID     string  `json:"id"`
+	Title  string  `json:"title"`
+	Artist string  `json:"artist"`
+	Score  float64 `json:"score"`
+}
+
+// Client looks fingerprints up in the AcoustID web service
+type Client struct {
+	URL    string
+	Key    string
+	Client *http.Client
+}
+
+// NewClient creates a Client for the application API key
+func NewClient(key string, client *http.Client) *Client {
+	return &Client{URL: DefaultURL, Key: key, Client: client}
+}
+
+// FromEnv builds the client from ACOUSTID_API_KEY, returning nil when it is not set.
+// ACOUSTID_API_URL overrides the endpoint.
+func FromEnv(client *http.Client) *Client {
+	key := os.Getenv("ACOUSTID_API_KEY")
+	if key == "" {
+		return nil
+	}
+	c := NewClient(key, client)
+	if value := os.Getenv("ACOUSTID_API_URL"); value != "" {
+		c.URL = value
+	}
+	return c
+}
+
+// lookupResponse is the part of the AcoustID lookup response used to pick a recording
+type lookupResponse struct {
+	Status string `json:"status"`
+	Error  struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Results []struct {
+		Score      float64 `json:"score"`
+		Recordings []struct {
+			ID      string `json:"id"`
+			Title   string `json:"title"`
+			Artists []struct {
+				Name       string `json:"name"`
+				JoinPhrase string `json:"joinphrase"`
+			} `json:"artists"`
+		} `json:"recordings"`
+	} `json:"results"`
+}
+
+// Lookup returns the best scored recording matching the fingerprint. Fingerprints are sent in a form
+// body, as they are too long for a query string.
+func (c *Client) Lookup(ctx context.Context, fp Fingerprint) (Recording, error) {
+	form := url.Values{
+		"client":      {c.Key},
+		"meta":        {"recordings"},
+		"duration":    {strconv.Itoa(fp.Duration)},
+		"fingerprint": {fp.Fingerprint},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Recording{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return Recording{}, err
+	}
+	defer resp.Body.Close()
+
+	var result lookupResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return Recording{}, fmt.Errorf("AcoustID returned %d: %w", resp.StatusCode, err)
+	}
+	if result.Status != "ok" {
+		return Recording{}, fmt.Errorf("AcoustID returned %d: %s", resp.StatusCode, result.Error.Message)
+	}
+
+	var best Recording
+	for _, match := range result.Results {
+		if match.Score <= best.Score {
+			continue
+		}
+		for _, recording := range match.Recordings {
+			var artist strings.Builder
+			for i, a := range recording.Artists {
+				artist.WriteString(a.Name)
+				if a.JoinPhrase != "" {
+					artist.WriteString(a.JoinPhrase)
+				} else if i < len(recording.Artists)-1 {
+					artist.WriteString(", ")
+				}
+			}
+			if recording.Title == "" || artist.Len() == 0 {
+				continue
+			}
+			best = Recording{ID: recording.ID, Title: recording.Title, Artist: artist.String(), Score: match.Score}
+			break
+		}
+	}
+	if best.ID == "" {
+		return Recording{}, ErrNotFound
+	}
+	return best, nil
+}
+
+// Fpcalc computes fingerprints of audio files with the fpcalc tool shipped with Chromaprint
+type Fpcalc struct {
+	Path string
+}
+
+// FpcalcFromEnv finds fpcalc at FPCALC_PATH, or on the PATH, returning nil when it is not installed,
+// in which case only precomputed fingerprints can be matched
+func FpcalcFromEnv() *Fpcalc {
+	name := os.Getenv("FPCALC_PATH")
+	if name == "" {
+		name = "fpcalc"
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil
+	}
+	return &Fpcalc{Path: path}
+}
+
+// Compute fingerprints the audio file at the path
+func (f *Fpcalc) Compute(ctx context.Context, path string) (Fingerprint, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, f.Path, "-json", path)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("fpcalc failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	var result struct {
+		Duration    float64 `json:"duration"`
+		Fingerprint string  `json:"fingerprint"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return Fingerprint{}, fmt.Errorf("fpcalc output: %w", err)
+	}
+	if result.Fingerprint == "" {
+		return Fingerprint{}, errors.New("fpcalc produced no fingerprint")
+	}
+	return Fingerprint{Fingerprint: result.Fingerprint, Duration: int(result.Duration + 0.5)}, nil
+}
@@ -0,0 +1,61 @@
+package acoustid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "key", r.FormValue("client"))
+		assert.Equal(t, "213", r.FormValue("duration"))
+		switch r.FormValue("fingerprint") {
+		case "AQAA":
+			w.Write([]byte(`{"status": "ok", "results": [
+				{"id": "a", "score": 0.4, "recordings": [{"id": "low", "title": "Other", "artists": [{"name": "Muse"}]}]},
+				{"id": "b", "score": 0.9, "recordings": [
+					{"id": "untitled"},
+					{"id": "rec", "title": "Uprising", "artists": [{"name": "Muse", "joinphrase": " feat. "}, {"name": "Guest"}]}
+				]}
+			]}`))
+		case "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status": "error", "error": {"code": 3, "message": "invalid fingerprint"}}`))
+		default:
+			w.Write([]byte(`{"status": "ok", "results": []}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient("key", server.Client())
+	client.URL = server.URL
+
+	recording, err := client.Lookup(context.Background(), Fingerprint{Fingerprint: "AQAA", Duration: 213})
+	require.NoError(t, err)
+	assert.Equal(t, Recording{ID: "rec", Title: "Uprising", Artist: "Muse feat. Guest", Score: 0.9}, recording)
+
+	_, err = client.Lookup(context.Background(), Fingerprint{Fingerprint: "unknown", Duration: 213})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = client.Lookup(context.Background(), Fingerprint{Fingerprint: "bad", Duration: 213})
+	assert.ErrorContains(t, err, "invalid fingerprint")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("ACOUSTID_API_KEY", "")
+	assert.Nil(t, FromEnv(http.DefaultClient))
+
+	t.Setenv("ACOUSTID_API_KEY", "key")
+	t.Setenv("ACOUSTID_API_URL", "http://acoustid.local/lookup")
+	client := FromEnv(http.DefaultClient)
+	require.NotNil(t, client)
+	assert.Equal(t, "http://acoustid.local/lookup", client.URL)
+
+	t.Setenv("FPCALC_PATH", "/nonexistent/fpcalc")
+	assert.Nil(t, FpcalcFromEnv())
+}
@@ -0,0 +1,159 @@
+package analytics
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Event types exported to the analytics store
+const (
+	EventView          = "view"
+	EventSongAdded     = "song_added"
+	EventSongUpdated   = "song_updated"
+	EventSongDeleted   = "song_deleted"
+	EventSongsImported = "songs_imported"
+	EventSongsCleared  = "songs_truncated"
+	// Legal hold events form the audit trail of the holds set and released, and of the operations they blocked
+	EventLegalHoldSet      = "legal_hold_set"
+	EventLegalHoldReleased = "legal_hold_released"
+	EventLegalHoldBlocked  = "legal_hold_blocked"
+)
+
+// Event is a single analytics record. SongID is zero for events not tied to a song.
+type Event struct {
+	Type       string    `json:"type"`
+	SongID     int       `json:"song_id"`
+	Count      int       `json:"count"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Exporter writes a batch of events to an analytics store
+type Exporter interface {
+	Export(ctx context.Context, events []Event) error
+}
+
+// BatcherConfig controls how events are grouped into micro-batches
+type BatcherConfig struct {
+	// BatchSize is the number of events that triggers an export
+	BatchSize int
+	// FlushInterval is the longest time an event waits before being exported
+	FlushInterval time.Duration
+	// BufferSize is the number of events held in memory; events published to a full buffer are dropped
+	BufferSize int
+	// Timeout bounds a single export
+	Timeout time.Duration
+}
+
+// DefaultBatcherConfig is used for fields left unset
+var DefaultBatcherConfig = BatcherConfig{
+	BatchSize:     500,
+	FlushInterval: 10 * time.Second,
+	BufferSize:    10000,
+	Timeout:       30 * time.Second,
+}
+
+// Batcher collects events in memory and hands them to an Exporter in micro-batches.
+// Publishing never blocks: the operational path must not wait for the analytics store.
+type Batcher struct {
+	exporter Exporter
+	logger   *zap.Logger
+	cfg      BatcherConfig
+	events   chan Event
+	dropped  atomic.Int64
+}
+
+// NewBatcher creates a Batcher; call Run to start exporting
+func NewBatcher(exporter Exporter, logger *zap.Logger, cfg BatcherConfig) *Batcher {
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = DefaultBatcherConfig.BatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultBatcherConfig.FlushInterval
+	}
+	if cfg.BufferSize < 1 {
+		cfg.BufferSize = DefaultBatcherConfig.BufferSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultBatcherConfig.Timeout
+	}
+	return &Batcher{
+		exporter: exporter,
+		logger:   logger,
+		cfg:      cfg,
+		events:   make(chan Event, cfg.BufferSize),
+	}
+}
+
+// Publish queues an event for export, dropping it when the buffer is full
+func (b *Batcher) Publish(event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	select {
+	case b.events <- event:
+	default:
+		b.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of events dropped because the buffer was full
+func (b *Batcher) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// Run exports batches until ctx is cancelled, then exports the events still buffered and returns
+func (b *Batcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.cfg.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]Event, 0, b.cfg.BatchSize)
+	for {
+		select {
+		case event := <-b.events:
+			batch = append(batch, event)
+			if len(batch) >= b.cfg.BatchSize {
+				batch = b.export(batch)
+			}
+		case <-ticker.C:
+			batch = b.export(batch)
+		case <-ctx.Done():
+			b.drain(batch)
+			return
+		}
+	}
+}
+
+// drain exports the batch together with every event still buffered
+func (b *Batcher) drain(batch []Event) {
+	for {
+		select {
+		case event := <-b.events:
+			batch = append(batch, event)
+			if len(batch) >= b.cfg.BatchSize {
+				batch = b.export(batch)
+			}
+		default:
+			b.export(batch)
+			return
+		}
+	}
+}
+
+// export sends the batch and returns an empty batch to reuse. Failed batches are dropped
+// rather than retried, so a broken analytics store cannot exhaust memory.
+func (b *Batcher) export(batch []Event) []Event {
+	if len(batch) == 0 {
+		return batch
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), b.cfg.Timeout)
+	defer cancel()
+	if err := b.exporter.Export(ctx, batch); err != nil {
+		b.dropped.Add(int64(len(batch)))
+		b.logger.Error("Failed to export analytics events", zap.Int("events", len(batch)), zap.Error(err))
+	} else {
+		b.logger.Debug("Analytics events exported", zap.Int("events", len(batch)))
+	}
+	return batch[:0]
+}
@@ -0,0 +1,102 @@
+package analytics
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type recordingExporter struct {
+	mu      sync.Mutex
+	batches [][]Event
+}
+
+func (e *recordingExporter) Export(ctx context.Context, events []Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.batches = append(e.batches, append([]Event(nil), events...))
+	return nil
+}
+
+func TestBatcherMicroBatches(t *testing.T) {
+	exporter := &recordingExporter{}
+	batcher := NewBatcher(exporter, zap.NewNop(), BatcherConfig{BatchSize: 3, FlushInterval: time.Hour, BufferSize: 10})
+	for i := 1; i <= 7; i++ {
+		batcher.Publish(Event{Type: EventView, SongID: i, Count: 1})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		batcher.Run(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		exporter.mu.Lock()
+		defer exporter.mu.Unlock()
+		return len(exporter.batches) == 2
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	// The remaining event is exported on shutdown
+	require.Len(t, exporter.batches, 3)
+	assert.Len(t, exporter.batches[0], 3)
+	assert.Len(t, exporter.batches[2], 1)
+	assert.Equal(t, 7, exporter.batches[2][0].SongID)
+	assert.False(t, exporter.batches[0][0].OccurredAt.IsZero())
+}
+
+func TestBatcherDropsWhenFull(t *testing.T) {
+	batcher := NewBatcher(&recordingExporter{}, zap.NewNop(), BatcherConfig{BufferSize: 2})
+	for i := 0; i < 5; i++ {
+		batcher.Publish(Event{Type: EventView})
+	}
+	assert.Equal(t, int64(3), batcher.Dropped())
+}
+
+func TestClickHouseExporter(t *testing.T) {
+	var query, user string
+	var rows []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		user = r.Header.Get("X-ClickHouse-User")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var event Event
+			json.Unmarshal(scanner.Bytes(), &event)
+			rows = append(rows, event)
+		}
+	}))
+	defer server.Close()
+
+	exporter := &ClickHouseExporter{URL: server.URL, Table: "events", User: "writer", Client: server.Client()}
+	err := exporter.Export(context.Background(), []Event{{Type: EventSongAdded, SongID: 1, Count: 1}, {Type: EventView, SongID: 1, Count: 1}})
+	require.NoError(t, err)
+	assert.Equal(t, "INSERT INTO events FORMAT JSONEachRow", query)
+	assert.Equal(t, "writer", user)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, EventSongAdded, rows[0].Type)
+}
+
+func TestBigQueryExporterRowErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/projects/p/datasets/d/tables/t/insertAll", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"insertErrors": [{"index": 0}]}`))
+	}))
+	defer server.Close()
+
+	exporter := &BigQueryExporter{Project: "p", Dataset: "d", Table: "t", Token: "token", Endpoint: server.URL, Client: server.Client()}
+	err := exporter.Export(context.Background(), []Event{{Type: EventView, SongID: 1, Count: 1}})
+	assert.ErrorContains(t, err, "rejected 1 of 1 rows")
+}
@@ -0,0 +1,88 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// bigQueryEndpoint is the streaming insert API of BigQuery
+const bigQueryEndpoint = "https://bigquery.googleapis.com/bigquery/v2"
+
+// BigQueryExporter streams events into a BigQuery table with the insertAll API.
+// The table needs the columns type STRING, song_id INT64, count INT64 and occurred_at TIMESTAMP.
+type BigQueryExporter struct {
+	Project string
+	Dataset string
+	Table   string
+	// Token is an OAuth access token; when TokenFile is set the token is re-read from it on every export,
+	// so an external process can keep it refreshed
+	Token     string
+	TokenFile string
+	// Endpoint overrides bigQueryEndpoint, for tests
+	Endpoint string
+	Client   *http.Client
+}
+
+// Export streams the events into the table
+func (e *BigQueryExporter) Export(ctx context.Context, events []Event) error {
+	type row struct {
+		JSON Event `json:"json"`
+	}
+	payload := struct {
+		Rows []row `json:"rows"`
+	}{Rows: make([]row, len(events))}
+	for i, event := range events {
+		payload.Rows[i] = row{JSON: event}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	token := e.Token
+	if e.TokenFile != "" {
+		data, err := os.ReadFile(e.TokenFile)
+		if err != nil {
+			return fmt.Errorf("read bigquery token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	endpoint := e.Endpoint
+	if endpoint == "" {
+		endpoint = bigQueryEndpoint
+	}
+	url := fmt.Sprintf("%s/projects/%s/datasets/%s/tables/%s/insertAll", endpoint, e.Project, e.Dataset, e.Table)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("bigquery returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	// insertAll reports per-row failures with a 200 status
+	var result struct {
+		InsertErrors []json.RawMessage `json:"insertErrors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && err != io.EOF {
+		return fmt.Errorf("decode bigquery response: %w", err)
+	}
+	if len(result.InsertErrors) > 0 {
+		return fmt.Errorf("bigquery rejected %d of %d rows", len(result.InsertErrors), len(events))
+	}
+	return nil
+}
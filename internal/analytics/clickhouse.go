@@ -0,0 +1,56 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ClickHouseExporter inserts events into a ClickHouse table through the HTTP interface.
+// The table needs the columns type String, song_id Int64, count Int64 and occurred_at DateTime64.
+type ClickHouseExporter struct {
+	URL      string
+	Table    string
+	User     string
+	Password string
+	Client   *http.Client
+}
+
+// Export inserts the events as JSONEachRow
+func (e *ClickHouseExporter) Export(ctx context.Context, events []Event) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+
+	query := url.Values{}
+	query.Set("query", fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", e.Table))
+	query.Set("date_time_input_format", "best_effort")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL+"/?"+query.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if e.User != "" {
+		req.Header.Set("X-ClickHouse-User", e.User)
+		req.Header.Set("X-ClickHouse-Key", e.Password)
+	}
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("clickhouse returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
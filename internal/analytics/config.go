@@ -0,0 +1,84 @@
+package analytics
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"music-library/internal/secrets"
+)
+
+// Supported analytics exporters
+const (
+	ExporterClickHouse = "clickhouse"
+	ExporterBigQuery   = "bigquery"
+)
+
+// ExporterFromEnv builds the exporter selected by ANALYTICS_EXPORTER, or returns nil when it is unset.
+// Secrets can also be read from a file named by the same variable with a _FILE suffix.
+func ExporterFromEnv(client *http.Client) (Exporter, error) {
+	switch kind := os.Getenv("ANALYTICS_EXPORTER"); kind {
+	case "":
+		return nil, nil
+	case ExporterClickHouse:
+		exporter := &ClickHouseExporter{
+			URL:    strings.TrimSuffix(os.Getenv("ANALYTICS_CLICKHOUSE_URL"), "/"),
+			Table:  getEnv("ANALYTICS_CLICKHOUSE_TABLE", "music_library_events"),
+			User:   os.Getenv("ANALYTICS_CLICKHOUSE_USER"),
+			Client: client,
+		}
+		password, err := secrets.Read("ANALYTICS_CLICKHOUSE_PASSWORD")
+		if err != nil {
+			return nil, err
+		}
+		exporter.Password = password
+		if exporter.URL == "" {
+			return nil, fmt.Errorf("ANALYTICS_CLICKHOUSE_URL is required for the clickhouse exporter")
+		}
+		return exporter, nil
+	case ExporterBigQuery:
+		exporter := &BigQueryExporter{
+			Project:   os.Getenv("ANALYTICS_BIGQUERY_PROJECT"),
+			Dataset:   os.Getenv("ANALYTICS_BIGQUERY_DATASET"),
+			Table:     getEnv("ANALYTICS_BIGQUERY_TABLE", "music_library_events"),
+			Token:     os.Getenv("ANALYTICS_BIGQUERY_TOKEN"),
+			TokenFile: os.Getenv("ANALYTICS_BIGQUERY_TOKEN_FILE"),
+			Client:    client,
+		}
+		if exporter.Project == "" || exporter.Dataset == "" {
+			return nil, fmt.Errorf("ANALYTICS_BIGQUERY_PROJECT and ANALYTICS_BIGQUERY_DATASET are required for the bigquery exporter")
+		}
+		if exporter.Token == "" && exporter.TokenFile == "" {
+			return nil, fmt.Errorf("ANALYTICS_BIGQUERY_TOKEN or ANALYTICS_BIGQUERY_TOKEN_FILE is required for the bigquery exporter")
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unsupported analytics exporter %q", kind)
+	}
+}
+
+// BatcherConfigFromEnv reads the micro-batch settings from ANALYTICS_* environment variables,
+// keeping the defaults for unset or invalid values
+func BatcherConfigFromEnv() BatcherConfig {
+	cfg := DefaultBatcherConfig
+	if value, err := time.ParseDuration(os.Getenv("ANALYTICS_FLUSH_INTERVAL")); err == nil && value > 0 {
+		cfg.FlushInterval = value
+	}
+	if size, err := strconv.Atoi(os.Getenv("ANALYTICS_BATCH_SIZE")); err == nil && size > 0 {
+		cfg.BatchSize = size
+	}
+	if size, err := strconv.Atoi(os.Getenv("ANALYTICS_BUFFER_SIZE")); err == nil && size > 0 {
+		cfg.BufferSize = size
+	}
+	return cfg
+}
+
+func getEnv(key, fallback string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return fallback
+}
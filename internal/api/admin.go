@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetQueryLog handles the request to inspect recently executed repository queries
+// @Summary Get the query log
+// @Description List the recently executed repository queries
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {array} repository.QueryLogEntry
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/query-log [get]
+func (h *Handler) GetQueryLog(c *gin.Context) {
+	h.logger.Info("Handling GetQueryLog request")
+
+	entries := h.svc.RecentQueries()
+
+	h.logger.Info("Query log retrieved successfully", zap.Int("count", len(entries)))
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetProviderBudgets handles the request to inspect the call budgets of external providers
+// @Summary Get provider budgets
+// @Description Inspect the call budgets of the external providers
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {array} budget.Status
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/providers [get]
+func (h *Handler) GetProviderBudgets(c *gin.Context) {
+	h.logger.Info("Handling GetProviderBudgets request")
+
+	statuses := h.svc.ProviderBudgets(c.Request.Context())
+
+	h.logger.Info("Provider budgets retrieved successfully", zap.Int("count", len(statuses)))
+	c.JSON(http.StatusOK, statuses)
+}
+
+// GetAPICaptures handles the request to inspect the sampled external API exchanges, newest first.
+// The provider query parameter narrows them to one provider.
+// @Summary Get external API captures
+// @Description List the sampled external API exchanges, newest first
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param provider query string false "Only the exchanges with this provider"
+// @Param limit query int false "Maximum number of captures" default(50)
+// @Success 200 {array} models.APICapture
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/api-captures [get]
+func (h *Handler) GetAPICaptures(c *gin.Context) {
+	h.logger.Info("Handling GetAPICaptures request")
+
+	provider := c.Query("provider")
+	limitStr := c.DefaultQuery("limit", "50")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		h.logger.Error("Invalid limit", zap.String("limit", limitStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	captures, err := h.svc.GetAPICaptures(c.Request.Context(), provider, limit)
+	if err != nil {
+		h.logger.Error("Failed to fetch API captures", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("API captures retrieved successfully", zap.Int("count", len(captures)))
+	c.JSON(http.StatusOK, captures)
+}
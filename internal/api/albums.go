@@ -0,0 +1,307 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/service"
+)
+
+// CreateAlbum handles the request to add an album
+// @Summary Create an album
+// @Tags albums
+// @Accept json
+// @Produce json,xml
+// @Security BearerAuth
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Param album body models.AlbumInput true "Album to create"
+// @Success 201 {object} models.Album
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /albums [post]
+func (h *Handler) CreateAlbum(c *gin.Context) {
+	h.logger.Info("Handling CreateAlbum request")
+
+	album, dateFormat, ok := h.albumInput(c)
+	if !ok {
+		return
+	}
+	created, err := h.svc.CreateAlbum(c.Request.Context(), album)
+	if err != nil {
+		h.respondAlbumError(c, err)
+		return
+	}
+
+	service.FormatAlbumDate(&created, dateFormat)
+	h.logger.Info("Album created successfully", zap.Int("album_id", created.ID))
+	render(c, http.StatusCreated, created)
+}
+
+// GetAlbums handles the request to list the albums page by page
+// @Summary Get albums
+// @Tags albums
+// @Produce json,xml
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Success 200 {object} models.AlbumPage
+// @Header 200 {integer} X-Total-Count "Number of albums"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /albums [get]
+func (h *Handler) GetAlbums(c *gin.Context) {
+	h.logger.Info("Handling GetAlbums request")
+
+	page, limit, ok := h.listPage(c)
+	if !ok {
+		return
+	}
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return
+	}
+
+	albums, err := h.svc.GetAlbums(c.Request.Context(), page, limit)
+	if err != nil {
+		h.logger.Error("Failed to fetch albums", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	for i := range albums.Data {
+		service.FormatAlbumDate(&albums.Data[i], dateFormat)
+	}
+	c.Header("X-Total-Count", strconv.Itoa(albums.Total))
+	h.logger.Info("Albums retrieved successfully", zap.Int("count", len(albums.Data)), zap.Int("total", albums.Total))
+	render(c, http.StatusOK, albums)
+}
+
+// GetAlbum handles the request to fetch an album
+// @Summary Get an album
+// @Tags albums
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Album ID"
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Success 200 {object} models.Album
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /albums/{id} [get]
+func (h *Handler) GetAlbum(c *gin.Context) {
+	h.logger.Info("Handling GetAlbum request")
+
+	id, ok := h.albumID(c)
+	if !ok {
+		return
+	}
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return
+	}
+
+	album, err := h.svc.GetAlbum(c.Request.Context(), id)
+	if err != nil {
+		h.respondAlbumError(c, err)
+		return
+	}
+
+	service.FormatAlbumDate(&album, dateFormat)
+	h.logger.Info("Album retrieved successfully", zap.Int("album_id", id))
+	render(c, http.StatusOK, album)
+}
+
+// UpdateAlbum handles the request to replace the fields of an album
+// @Summary Update an album
+// @Tags albums
+// @Accept json
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Album ID"
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Param album body models.AlbumInput true "Updated album"
+// @Success 200 {object} models.Album
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /albums/{id} [put]
+func (h *Handler) UpdateAlbum(c *gin.Context) {
+	h.logger.Info("Handling UpdateAlbum request")
+
+	id, ok := h.albumID(c)
+	if !ok {
+		return
+	}
+	album, dateFormat, ok := h.albumInput(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.svc.UpdateAlbum(c.Request.Context(), id, album)
+	if err != nil {
+		h.respondAlbumError(c, err)
+		return
+	}
+
+	service.FormatAlbumDate(&updated, dateFormat)
+	h.logger.Info("Album updated successfully", zap.Int("album_id", id))
+	render(c, http.StatusOK, updated)
+}
+
+// DeleteAlbum handles the request to delete an album. Its songs are kept outside of any album.
+// @Summary Delete an album
+// @Description Delete an album, keeping its songs outside of any album
+// @Tags albums
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Album ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /albums/{id} [delete]
+func (h *Handler) DeleteAlbum(c *gin.Context) {
+	h.logger.Info("Handling DeleteAlbum request")
+
+	id, ok := h.albumID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteAlbum(c.Request.Context(), id); err != nil {
+		h.respondAlbumError(c, err)
+		return
+	}
+
+	h.logger.Info("Album deleted successfully", zap.Int("album_id", id))
+	c.JSON(http.StatusOK, gin.H{"message": "Album deleted successfully"})
+}
+
+// GetAlbumSongs handles the request to list the songs of an album page by page
+// @Summary Get the songs of an album
+// @Tags albums
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Album ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Success 200 {object} models.SongPage
+// @Header 200 {integer} X-Total-Count "Number of songs of the album"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /albums/{id}/songs [get]
+func (h *Handler) GetAlbumSongs(c *gin.Context) {
+	h.logger.Info("Handling GetAlbumSongs request")
+
+	id, ok := h.albumID(c)
+	if !ok {
+		return
+	}
+	page, limit, ok := h.listPage(c)
+	if !ok {
+		return
+	}
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return
+	}
+
+	songs, err := h.svc.GetAlbumSongs(c.Request.Context(), id, page, limit)
+	if err != nil {
+		h.respondAlbumError(c, err)
+		return
+	}
+
+	service.FormatSongDates(songs.Data, dateFormat)
+	c.Header("X-Total-Count", strconv.Itoa(songs.Total))
+	h.logger.Info("Album songs retrieved successfully", zap.Int("album_id", id), zap.Int("count", len(songs.Data)))
+	h.renderSongs(c, http.StatusOK, songs)
+}
+
+// albumInput parses the album of a create or replace request, with its release date sent in the requested
+// date format, responding with 400 when it is invalid
+func (h *Handler) albumInput(c *gin.Context) (models.AlbumInput, string, bool) {
+	var album models.AlbumInput
+	if err := c.ShouldBindJSON(&album); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return album, "", false
+	}
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return album, "", false
+	}
+	releaseDate, err := service.NormalizeReleaseDate(album.ReleaseDate, dateFormat)
+	if err != nil {
+		h.logger.Warn("Invalid release date", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return album, "", false
+	}
+	album.ReleaseDate = releaseDate
+	return album, dateFormat, true
+}
+
+// listPage parses the page and limit of an album or artist listing, responding with 400 when they are invalid
+func (h *Handler) listPage(c *gin.Context) (int, int, bool) {
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "10")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		h.logger.Error("Invalid page number", zap.String("page", pageStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return 0, 0, false
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		h.logger.Error("Invalid limit", zap.String("limit", limitStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return 0, 0, false
+	}
+	return page, limit, true
+}
+
+// albumID parses the album ID of a request, responding with 400 when it is invalid
+func (h *Handler) albumID(c *gin.Context) (int, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Invalid album ID", zap.String("album_id", idStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid album ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondAlbumError maps the errors of the album operations to responses
+func (h *Handler) respondAlbumError(c *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Album not found"})
+	case errors.Is(err, service.ErrInvalidAlbum) || errors.Is(err, service.ErrInvalidReleaseDate):
+		h.logger.Warn("Invalid album", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("Failed to process album request", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/service"
+)
+
+// CreateArtist handles the request to add an artist
+// @Summary Create an artist
+// @Tags artists
+// @Accept json
+// @Produce json,xml
+// @Security BearerAuth
+// @Param artist body models.ArtistInput true "Artist to create"
+// @Success 201 {object} models.Artist
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /artists [post]
+func (h *Handler) CreateArtist(c *gin.Context) {
+	h.logger.Info("Handling CreateArtist request")
+
+	var artist models.ArtistInput
+	if err := c.ShouldBindJSON(&artist); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	created, err := h.svc.CreateArtist(c.Request.Context(), artist)
+	if err != nil {
+		h.respondArtistError(c, err)
+		return
+	}
+
+	h.logger.Info("Artist created successfully", zap.Int("artist_id", created.ID))
+	render(c, http.StatusCreated, created)
+}
+
+// GetArtists handles the request to list the artists page by page
+// @Summary Get artists
+// @Tags artists
+// @Produce json,xml
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} models.ArtistPage
+// @Header 200 {integer} X-Total-Count "Number of artists"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /artists [get]
+func (h *Handler) GetArtists(c *gin.Context) {
+	h.logger.Info("Handling GetArtists request")
+
+	page, limit, ok := h.listPage(c)
+	if !ok {
+		return
+	}
+
+	artists, err := h.svc.GetArtists(c.Request.Context(), page, limit)
+	if err != nil {
+		h.logger.Error("Failed to fetch artists", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(artists.Total))
+	h.logger.Info("Artists retrieved successfully", zap.Int("count", len(artists.Data)), zap.Int("total", artists.Total))
+	render(c, http.StatusOK, artists)
+}
+
+// GetArtist handles the request to fetch an artist
+// @Summary Get an artist
+// @Tags artists
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Artist ID"
+// @Success 200 {object} models.Artist
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /artists/{id} [get]
+func (h *Handler) GetArtist(c *gin.Context) {
+	h.logger.Info("Handling GetArtist request")
+
+	id, ok := h.artistID(c)
+	if !ok {
+		return
+	}
+
+	artist, err := h.svc.GetArtist(c.Request.Context(), id)
+	if err != nil {
+		h.respondArtistError(c, err)
+		return
+	}
+
+	h.logger.Info("Artist retrieved successfully", zap.Int("artist_id", id))
+	render(c, http.StatusOK, artist)
+}
+
+// UpdateArtist handles the request to replace the fields of an artist. A new name is carried over to the
+// group of the artist's songs.
+// @Summary Update an artist
+// @Description Replace the fields of an artist; a new name is carried over to the group of its songs
+// @Tags artists
+// @Accept json
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Artist ID"
+// @Param artist body models.ArtistInput true "Updated artist"
+// @Success 200 {object} models.Artist
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /artists/{id} [put]
+func (h *Handler) UpdateArtist(c *gin.Context) {
+	h.logger.Info("Handling UpdateArtist request")
+
+	id, ok := h.artistID(c)
+	if !ok {
+		return
+	}
+	var artist models.ArtistInput
+	if err := c.ShouldBindJSON(&artist); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.svc.UpdateArtist(c.Request.Context(), id, artist)
+	if err != nil {
+		h.respondArtistError(c, err)
+		return
+	}
+
+	h.logger.Info("Artist updated successfully", zap.Int("artist_id", id))
+	render(c, http.StatusOK, updated)
+}
+
+// DeleteArtist handles the request to delete an artist without songs
+// @Summary Delete an artist
+// @Description Delete an artist without songs
+// @Tags artists
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Artist ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /artists/{id} [delete]
+func (h *Handler) DeleteArtist(c *gin.Context) {
+	h.logger.Info("Handling DeleteArtist request")
+
+	id, ok := h.artistID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteArtist(c.Request.Context(), id); err != nil {
+		h.respondArtistError(c, err)
+		return
+	}
+
+	h.logger.Info("Artist deleted successfully", zap.Int("artist_id", id))
+	c.JSON(http.StatusOK, gin.H{"message": "Artist deleted successfully"})
+}
+
+// GetArtistSongs handles the request to list the songs of an artist page by page
+// @Summary Get the songs of an artist
+// @Tags artists
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Artist ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Success 200 {object} models.SongPage
+// @Header 200 {integer} X-Total-Count "Number of songs of the artist"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /artists/{id}/songs [get]
+func (h *Handler) GetArtistSongs(c *gin.Context) {
+	h.logger.Info("Handling GetArtistSongs request")
+
+	id, ok := h.artistID(c)
+	if !ok {
+		return
+	}
+	page, limit, ok := h.listPage(c)
+	if !ok {
+		return
+	}
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return
+	}
+
+	songs, err := h.svc.GetArtistSongs(c.Request.Context(), id, page, limit)
+	if err != nil {
+		h.respondArtistError(c, err)
+		return
+	}
+
+	service.FormatSongDates(songs.Data, dateFormat)
+	c.Header("X-Total-Count", strconv.Itoa(songs.Total))
+	h.logger.Info("Artist songs retrieved successfully", zap.Int("artist_id", id), zap.Int("count", len(songs.Data)))
+	h.renderSongs(c, http.StatusOK, songs)
+}
+
+// artistID parses the artist ID of a request, responding with 400 when it is invalid
+func (h *Handler) artistID(c *gin.Context) (int, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Invalid artist ID", zap.String("artist_id", idStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid artist ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondArtistError maps the errors of the artist operations to responses
+func (h *Handler) respondArtistError(c *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artist not found"})
+	case errors.Is(err, service.ErrInvalidArtist):
+		h.logger.Warn("Invalid artist", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrArtistExists) || errors.Is(err, service.ErrArtistHasSongs):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrLegalHold):
+		c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("Failed to process artist request", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
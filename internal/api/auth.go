@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/auth"
+	"music-library/internal/service"
+)
+
+// Credentials defines the request body of registration and login
+type Credentials struct {
+	Username string `json:"username" validate:"required" example:"alice"`
+	Password string `json:"password" validate:"required" example:"correct horse battery staple"`
+}
+
+// Register handles the request to create a user account
+// @Summary Register a user
+// @Description Create a user account with the viewer role
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body Credentials true "Username and password"
+// @Success 201 {object} models.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/register [post]
+func (h *Handler) Register(c *gin.Context) {
+	h.logger.Info("Handling Register request")
+
+	var req Credentials
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.svc.Register(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRegistration):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrUserExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "Username already taken"})
+		default:
+			h.logger.Error("Failed to register user", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	h.logger.Info("User registered successfully", zap.Int("user_id", user.ID))
+	c.JSON(http.StatusCreated, user)
+}
+
+// Login handles the request to exchange a username and password for a token pair
+// @Summary Log in
+// @Description Exchange a username and password for an access and refresh token pair
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body Credentials true "Username and password"
+// @Success 200 {object} auth.TokenPair
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /auth/login [post]
+func (h *Handler) Login(c *gin.Context) {
+	h.logger.Info("Handling Login request")
+
+	var req Credentials
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.logger.Warn("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Field validation failed: " + err.Error()})
+		return
+	}
+
+	tokens, err := h.svc.Login(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		h.respondAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RefreshTokenRequest defines the request body for refreshing a token pair
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJzdWIiOiIxIiwidG9rZW5fdHlwZSI6InJlZnJlc2gifQ.signature"`
+}
+
+// RefreshToken handles the request to exchange a refresh token for a new token pair
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new token pair
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} auth.TokenPair
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /auth/refresh [post]
+func (h *Handler) RefreshToken(c *gin.Context) {
+	h.logger.Info("Handling RefreshToken request")
+
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		h.logger.Warn("Validation failed", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Field validation failed: " + err.Error()})
+		return
+	}
+
+	tokens, err := h.svc.RefreshToken(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		h.respondAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// respondAuthError maps login and refresh failures to responses
+func (h *Handler) respondAuthError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+	case errors.Is(err, auth.ErrInvalidToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+	case errors.Is(err, service.ErrAuthDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Authentication is not configured"})
+	default:
+		h.logger.Error("Failed to issue tokens", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// maxBulkSongs is the maximum number of songs accepted by a single bulk request
+const maxBulkSongs = 1000
+
+// AddSongs handles the request to add many songs at once
+// @Summary Add songs in bulk
+// @Description Add up to 1000 songs at once, reporting the outcome of each
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param songs body []service.BulkSong true "Songs to add"
+// @Success 200 {array} service.BulkItemResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/bulk [post]
+func (h *Handler) AddSongs(c *gin.Context) {
+	h.logger.Info("Handling AddSongs request")
+
+	var req []service.BulkSong
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req) == 0 || len(req) > maxBulkSongs {
+		h.logger.Warn("Invalid bulk size", zap.Int("count", len(req)))
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Request must contain between 1 and %d songs", maxBulkSongs)})
+		return
+	}
+
+	results, err := h.svc.AddSongs(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to add songs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Bulk request processed", zap.Int("count", len(results)))
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetReleaseCalendar handles the request to export release anniversaries as an iCalendar feed
+// @Summary Get the release calendar
+// @Description Export the release anniversaries of the songs as an iCalendar feed
+// @Tags songs
+// @Produce text/calendar
+// @Security BearerAuth
+// @Param group query string false "Only the songs of this group"
+// @Param song query string false "Only the songs with this title"
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /calendar.ics [get]
+func (h *Handler) GetReleaseCalendar(c *gin.Context) {
+	h.logger.Info("Handling GetReleaseCalendar request")
+
+	calendar, err := h.svc.GetReleaseCalendar(c.Request.Context(), c.Query("group"), c.Query("song"))
+	if err != nil {
+		h.logger.Error("Failed to build release calendar", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Release calendar built successfully")
+	c.Header("Content-Disposition", `inline; filename="calendar.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(calendar))
+}
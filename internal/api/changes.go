@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// PollChanges handles the long-poll request for the library changes after a cursor, for clients that cannot
+// keep a stream open. Without a cursor the request waits for the next change. The request timeout still applies,
+// so a poll outlasting it ends early with no changes.
+// @Summary Poll library changes
+// @Description Wait for the library changes after a cursor, for clients that cannot keep a stream open.
+// @Description Without a cursor the request waits for the next change.
+// @Tags events
+// @Produce json
+// @Security BearerAuth
+// @Param since query int false "Cursor of the last change received"
+// @Param timeout query string false "How long to wait for a change, at most 2m" default(30s)
+// @Success 200 {object} changes.Batch
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /changes/poll [get]
+func (h *Handler) PollChanges(c *gin.Context) {
+	h.logger.Info("Handling PollChanges request")
+
+	since := h.svc.LatestChange()
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		parsed, err := strconv.ParseUint(sinceStr, 10, 64)
+		if err != nil {
+			h.logger.Error("Invalid change cursor", zap.String("since", sinceStr))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since"})
+			return
+		}
+		since = parsed
+	}
+	timeout := service.DefaultChangePollTimeout
+	if timeoutStr := c.Query("timeout"); timeoutStr != "" {
+		parsed, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			h.logger.Error("Invalid poll timeout", zap.String("timeout", timeoutStr))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timeout"})
+			return
+		}
+		timeout = parsed
+	}
+
+	batch, err := h.svc.PollChanges(c.Request.Context(), since, timeout)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPollTimeout) {
+			h.logger.Warn("Invalid poll timeout", zap.Duration("timeout", timeout))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to poll changes", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Changes polled successfully", zap.Int("count", len(batch.Changes)), zap.Uint64("next", batch.Next))
+	c.JSON(http.StatusOK, batch)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/service"
+)
+
+// GetClassificationSuggestions handles the request to list genre and mood suggestions awaiting review
+// @Summary Get classification suggestions
+// @Description List the genre and mood suggestions by their review status
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param status query string false "Review status" Enums(pending,accepted,rejected) default(pending)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(50)
+// @Success 200 {array} models.ClassificationSuggestion
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/classifications [get]
+func (h *Handler) GetClassificationSuggestions(c *gin.Context) {
+	h.logger.Info("Handling GetClassificationSuggestions request")
+
+	status := c.DefaultQuery("status", models.SuggestionPending)
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "50")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		h.logger.Error("Invalid page number", zap.String("page", pageStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		h.logger.Error("Invalid limit", zap.String("limit", limitStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	suggestions, err := h.svc.GetClassificationSuggestions(c.Request.Context(), status, page, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedSuggestionStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+			return
+		}
+		h.logger.Error("Failed to fetch classification suggestions", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Classification suggestions retrieved successfully", zap.Int("count", len(suggestions)))
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// AcceptClassificationSuggestion handles the request to accept a pending suggestion
+// @Summary Accept a classification suggestion
+// @Description Assigns the suggested genre to the song, or adds the suggested mood to its tags
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "Suggestion ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/classifications/{id}/accept [post]
+func (h *Handler) AcceptClassificationSuggestion(c *gin.Context) {
+	h.reviewClassificationSuggestion(c, true)
+}
+
+// RejectClassificationSuggestion handles the request to reject a pending suggestion
+// @Summary Reject a classification suggestion
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "Suggestion ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/classifications/{id}/reject [post]
+func (h *Handler) RejectClassificationSuggestion(c *gin.Context) {
+	h.reviewClassificationSuggestion(c, false)
+}
+
+// reviewClassificationSuggestion accepts or rejects the suggestion named in the path
+func (h *Handler) reviewClassificationSuggestion(c *gin.Context, accept bool) {
+	h.logger.Info("Handling ReviewClassificationSuggestion request", zap.Bool("accept", accept))
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Invalid suggestion ID", zap.String("id", idStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid suggestion ID"})
+		return
+	}
+
+	if err := h.svc.ReviewClassificationSuggestion(c.Request.Context(), id, accept); err != nil {
+		if err == sql.ErrNoRows {
+			h.logger.Warn("Pending suggestion not found", zap.Int("id", id))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pending suggestion not found"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidGenre) || errors.Is(err, service.ErrInvalidTag) {
+			h.logger.Warn("Suggestion cannot be applied", zap.Int("id", id), zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrLegalHold) {
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to review classification suggestion", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Classification suggestion reviewed successfully", zap.Int("id", id), zap.Bool("accept", accept))
+	c.JSON(http.StatusOK, gin.H{"message": "Suggestion reviewed successfully"})
+}
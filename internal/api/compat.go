@@ -0,0 +1,43 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-library/internal/models"
+)
+
+// CompatHeader selects the response shapes for a request: "legacy" keeps the shapes from before the
+// pagination and search envelopes during the migration window, "current" opts out of a user's legacy preference
+const CompatHeader = "X-API-Compat"
+
+// compatCurrent is the X-API-Compat value selecting the current response shapes
+const compatCurrent = "current"
+
+// legacyResponses reports whether the request gets the legacy response shapes, from the X-API-Compat header
+// or else from the user's api_compat preference. Legacy responses echo the header so clients can tell.
+func legacyResponses(c *gin.Context, preferences models.Preferences) bool {
+	c.Writer.Header().Add("Vary", CompatHeader)
+	legacy := preferences.APICompat == models.APICompatLegacy
+	switch c.GetHeader(CompatHeader) {
+	case models.APICompatLegacy:
+		legacy = true
+	case compatCurrent:
+		legacy = false
+	}
+	if legacy {
+		c.Header(CompatHeader, models.APICompatLegacy)
+	}
+	return legacy
+}
+
+// legacySongPage shapes a page of songs as GET /songs returned it before the pagination envelope: a bare
+// array, or the songs and facets alone when facets were requested. The total stays in X-Total-Count.
+func legacySongPage(page models.SongPage) any {
+	data := page.Data
+	if data == nil {
+		data = []models.Song{}
+	}
+	if page.Facets == nil {
+		return data
+	}
+	return gin.H{"data": data, "facets": page.Facets}
+}
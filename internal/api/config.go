@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/config"
+)
+
+// ExportConfig handles the request to export the instance configuration as a portable document, with the
+// credentials redacted, for promoting it to another deployment
+// @Summary Export the configuration
+// @Description Export the instance configuration, with the credentials redacted, to promote it to another deployment
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} config.Document
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/config/export [get]
+func (h *Handler) ExportConfig(c *gin.Context) {
+	h.logger.Info("Handling ExportConfig request")
+
+	document := config.Export(config.Environ(), time.Now())
+
+	h.logger.Info("Configuration exported successfully", zap.Int("runtime", len(document.Runtime)),
+		zap.Int("features", len(document.Features)), zap.Int("schedulers", len(document.Schedulers)))
+	c.JSON(http.StatusOK, document)
+}
+
+// ImportConfig handles the request to import a configuration document exported by another deployment. The
+// settings are read from the environment, so the document is validated and compared with the running
+// configuration rather than applied: the response lists the changes to deploy, and a restart is required
+// unless every change is to a setting a reload applies.
+// @Summary Import a configuration
+// @Description Compare a configuration document exported by another deployment with the running configuration,
+// @Description listing the changes to deploy and whether they require a restart. Nothing is applied.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param document body config.Document true "Exported configuration"
+// @Success 200 {object} ImportConfigResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/config/import [post]
+func (h *Handler) ImportConfig(c *gin.Context) {
+	h.logger.Info("Handling ImportConfig request")
+
+	var document config.Document
+	if err := c.ShouldBindJSON(&document); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	changes, err := config.Plan(config.Environ(), document)
+	if err != nil {
+		if errors.Is(err, config.ErrUnsupportedVersion) || errors.Is(err, config.ErrUnknownSetting) {
+			h.logger.Warn("Invalid configuration document", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to compare configuration", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	restart := false
+	for _, change := range changes {
+		restart = restart || !change.Reloadable
+	}
+	h.logger.Info("Configuration compared successfully", zap.Int("changes", len(changes)))
+	c.JSON(http.StatusOK, gin.H{"changes": changes, "restart_required": restart})
+}
+
+// ConfigureReloader sets the reloader applying the reloadable settings on request
+func (h *Handler) ConfigureReloader(reloader *config.Reloader) {
+	h.reloader = reloader
+}
+
+// GetLiveConfig handles the request to show the reloadable settings in effect and the reload that applied them
+// @Summary Get the live configuration
+// @Description Show the reloadable settings in effect and the reload that applied them
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} config.Snapshot
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /admin/config/live [get]
+func (h *Handler) GetLiveConfig(c *gin.Context) {
+	h.logger.Info("Handling GetLiveConfig request")
+
+	if h.reloader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Configuration reload is not configured"})
+		return
+	}
+	c.JSON(http.StatusOK, h.reloader.Current())
+}
+
+// ReloadConfig handles the request to reload the reloadable settings, as SIGHUP does. Invalid settings are
+// rejected with 400 and leave the running configuration unchanged.
+// @Summary Reload the configuration
+// @Description Reload the reloadable settings as SIGHUP does. Invalid settings leave the running configuration unchanged.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} config.ReloadResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /admin/config/reload [post]
+func (h *Handler) ReloadConfig(c *gin.Context) {
+	h.logger.Info("Handling ReloadConfig request")
+
+	if h.reloader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Configuration reload is not configured"})
+		return
+	}
+	result, err := h.reloader.Reload()
+	if err != nil {
+		if errors.Is(err, config.ErrInvalidReload) {
+			h.logger.Warn("Configuration reload rejected", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to reload configuration", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Configuration reloaded successfully", zap.Int("version", result.Snapshot.Version),
+		zap.Int("changes", len(result.Changes)), zap.Strings("pending", result.Pending))
+	c.JSON(http.StatusOK, result)
+}
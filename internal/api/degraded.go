@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"music-library/internal/service"
+)
+
+// staleWarning is the Warning header of responses served from the warm cache while degraded
+const staleWarning = `110 - "Response is Stale"`
+
+// withStaleness returns the request context for a read that may be served from the warm cache while the
+// database is unreachable, and a function to call before responding, which marks a stale response with
+// its Age and a Warning header
+func withStaleness(c *gin.Context) (context.Context, func()) {
+	ctx, staleness := service.WithStaleness(c.Request.Context())
+	return ctx, func() {
+		storedAt, stale := staleness.StoredAt()
+		if !stale {
+			return
+		}
+		c.Header("Age", strconv.Itoa(int(time.Since(storedAt).Seconds())))
+		c.Header("Warning", staleWarning)
+	}
+}
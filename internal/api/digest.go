@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// DigestPeriod is the length of the period covered by a library change digest
+const DigestPeriod = 7 * 24 * time.Hour
+
+// GetLatestDigest handles the request to retrieve the latest weekly digest of library changes
+// @Summary Get the latest digest
+// @Description Retrieve the songs added and updated over the last week
+// @Tags songs
+// @Produce json,xml
+// @Security BearerAuth
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Success 200 {object} models.Digest
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /digests/latest [get]
+func (h *Handler) GetLatestDigest(c *gin.Context) {
+	h.logger.Info("Handling GetLatestDigest request")
+
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return
+	}
+
+	digest, err := h.svc.LatestDigest(c.Request.Context(), DigestPeriod)
+	if err != nil {
+		h.logger.Error("Failed to fetch digest", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	digest = service.FormatDigestDates(digest, dateFormat)
+
+	h.logger.Info("Digest retrieved successfully", zap.Time("period_end", digest.PeriodEnd))
+	h.renderSongs(c, http.StatusOK, digest)
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// GetEnrichmentStatus handles the request to check whether the details of an added song have been fetched
+// @Summary Get the enrichment status of a song
+// @Description Check whether the details of an added song have been fetched
+// @Tags songs
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} models.EnrichmentStatus
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/enrichment-status [get]
+func (h *Handler) GetEnrichmentStatus(c *gin.Context) {
+	h.logger.Info("Handling GetEnrichmentStatus request")
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Invalid song ID", zap.String("id", idStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	status, err := h.svc.GetEnrichmentStatus(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.logger.Warn("Song not found", zap.Int("id", id))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+			return
+		}
+		h.logger.Error("Failed to fetch enrichment status", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Enrichment status retrieved successfully", zap.Int("id", id), zap.String("status", status.Status))
+	c.JSON(http.StatusOK, status)
+}
+
+// ReenrichSong handles the request to fetch a song's data from the external API again. The optional
+// "fields" query parameter, a comma separated subset of release_date, text and link, limits the fields overwritten.
+// @Summary Re-enrich a song
+// @Description Fetch the data of a song from the external API again
+// @Tags songs
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param fields query string false "Comma separated fields to overwrite, all of them by default" Enums(release_date,text,link)
+// @Success 200 {object} models.Song
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /songs/{id}/enrich [post]
+func (h *Handler) ReenrichSong(c *gin.Context) {
+	h.logger.Info("Handling ReenrichSong request")
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Invalid song ID", zap.String("id", idStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+	var fields []string
+	if fieldsStr := c.Query("fields"); fieldsStr != "" {
+		fields = strings.Split(fieldsStr, ",")
+	}
+
+	song, err := h.svc.ReenrichSong(c.Request.Context(), id, fields)
+	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedField) {
+			h.logger.Warn("Invalid re-enrichment fields", zap.Strings("fields", fields))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fields: " + err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrNoExternalData) {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "External API provided no data for the song"})
+			return
+		}
+		if errors.Is(err, service.ErrLegalHold) {
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+			return
+		}
+		if err == sql.ErrNoRows {
+			h.logger.Warn("Song not found", zap.Int("id", id))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+			return
+		}
+		h.logger.Error("Failed to re-enrich song", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Song re-enriched successfully", zap.Int("id", id))
+	h.renderSongs(c, http.StatusOK, song)
+}
+
+// StartEnrichAll handles the request to re-enrich songs in a background job. Songs are selected with the
+// GetSongs filters; without any, the songs still holding the fallback placeholder text are re-enriched.
+// The job's progress is available from GET /jobs/:id.
+// @Summary Re-enrich songs in the background
+// @Description Re-enrich the songs selected by the GetSongs filters in a background job followed with GET /admin/jobs/{id}.
+// @Description Without filters, the songs still holding the fallback placeholder text are re-enriched.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param group query string false "Filter by group"
+// @Param song query string false "Filter by song"
+// @Param stale_than query string false "Only songs not enriched within this age, e.g. 90d or 12h"
+// @Param missing query string false "Comma separated fields the songs miss" Enums(release_date,text,link)
+// @Param tag query []string false "Only songs with every one of these tags" collectionFormat(multi)
+// @Param genre query string false "Only songs of this genre or its subgenres"
+// @Param favorites query bool false "Only the authenticated user's favorites"
+// @Param min_rating query int false "Only songs with at least this average rating" minimum(1) maximum(5)
+// @Success 202 {object} models.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /admin/enrich-all [post]
+func (h *Handler) StartEnrichAll(c *gin.Context) {
+	h.logger.Info("Handling StartEnrichAll request")
+
+	filter, ok := h.songFilter(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.svc.StartEnrichAll(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrEnrichAllRunning) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Batch re-enrichment already running"})
+			return
+		}
+		if errors.Is(err, service.ErrUnsupportedField) {
+			h.logger.Warn("Invalid missing fields", zap.Strings("missing", filter.Missing))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid missing: " + err.Error()})
+			return
+		}
+		h.respondJobError(c, err)
+		return
+	}
+
+	h.logger.Info("Batch re-enrichment queued", zap.String("job_id", job.ID))
+	c.JSON(http.StatusAccepted, job)
+}
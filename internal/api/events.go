@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	"music-library/internal/api/middleware"
+	"music-library/internal/changes"
+)
+
+const (
+	// eventsPingInterval is the time between two pings on an idle feed
+	eventsPingInterval = 30 * time.Second
+	// eventsPongWait is how long the feed waits for any frame from the client before dropping it
+	eventsPongWait = 2 * eventsPingInterval
+	// eventsWriteTimeout bounds the write of a single frame to a slow client
+	eventsWriteTimeout = 10 * time.Second
+)
+
+// eventsUpgrader upgrades feed requests to WebSocket connections. Any origin is accepted: the feed is
+// authenticated by access tokens rather than cookies, so a foreign page cannot ride on a user's session.
+var eventsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// catalogEvents converts a batch of changes into the events pushed to clients, starting with a resync event
+// when changes were missed
+func catalogEvents(batch changes.Batch) []changes.Event {
+	events := make([]changes.Event, 0, len(batch.Changes)+1)
+	if batch.Missed {
+		events = append(events, changes.Event{ID: batch.Next, Type: changes.EventResync, OccurredAt: time.Now().UTC()})
+	}
+	for _, change := range batch.Changes {
+		if event, ok := changes.EventOf(change); ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// StreamEvents handles GET /ws/events, upgrading the request to a WebSocket connection that pushes every catalog
+// change as a JSON catalog event until the client disconnects or the server shuts down. The connection is pinged
+// while idle, and a client that stops answering is dropped.
+// @Summary Stream catalog events over WebSocket
+// @Description Upgrade to a WebSocket connection pushing every catalog change as a JSON event. Browsers may
+// @Description pass the access token in access_token since they cannot set headers on the handshake.
+// @Tags events
+// @Security BearerAuth
+// @Param access_token query string false "Access token, for clients that cannot set the Authorization header"
+// @Success 101 {object} changes.Event "Switching protocols, then one event per message"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /ws/events [get]
+func (h *Handler) StreamEvents(c *gin.Context) {
+	h.logger.Info("Handling StreamEvents request")
+
+	cursor := h.svc.LatestChange()
+	conn, err := eventsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already responded with the error
+		h.logger.Warn("Failed to upgrade to WebSocket", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	// Reading answers pings and close frames; the client sends nothing else, and a failed read ends the feed
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(eventsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(eventsPongWait))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	sent := 0
+	for {
+		batch, err := h.svc.PollChanges(ctx, cursor, eventsPingInterval)
+		if err != nil || ctx.Err() != nil {
+			break
+		}
+		cursor = batch.Next
+		events := catalogEvents(batch)
+		if len(events) == 0 {
+			conn.SetWriteDeadline(time.Now().Add(eventsWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				break
+			}
+			continue
+		}
+		for _, event := range events {
+			conn.SetWriteDeadline(time.Now().Add(eventsWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				h.logger.Info("Event feed client gone", zap.Int("sent", sent), zap.Error(err))
+				return
+			}
+			sent++
+		}
+	}
+	conn.SetWriteDeadline(time.Now().Add(eventsWriteTimeout))
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+	h.logger.Info("Event feed closed", zap.Int("sent", sent))
+}
+
+// StreamSongEvents handles GET /songs/stream, a server-sent event stream of the catalog events for clients that
+// cannot use WebSockets. Events carry their ID in the event log, so a client reconnecting with the Last-Event-ID
+// header, or the last_event_id query parameter, resumes after the last event it received as long as the log
+// still holds it. A comment is sent while the stream is idle to keep proxies from closing it.
+// @Summary Stream catalog events as server-sent events
+// @Description Stream the catalog events for clients that cannot use WebSockets. A client reconnecting with
+// @Description Last-Event-ID resumes after the last event it received while the event log still holds it.
+// @Tags events
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Param last_event_id query string false "ID of the last event received, when the header cannot be set"
+// @Success 200 {object} changes.Event "One event per message"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/stream [get]
+func (h *Handler) StreamSongEvents(c *gin.Context) {
+	h.logger.Info("Handling StreamSongEvents request")
+
+	ctx := c.Request.Context()
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var cursor int64
+	if lastEventID != "" {
+		parsed, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || parsed < 0 {
+			h.logger.Warn("Invalid last event ID", zap.String("last_event_id", lastEventID))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid last event ID"})
+			return
+		}
+		cursor = parsed
+	} else {
+		latest, err := h.svc.LatestSongEvent(ctx)
+		if err != nil {
+			h.logger.Error("Failed to fetch latest song event", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		cursor = latest
+	}
+
+	c.Header("Content-Type", middleware.ContentTypeEventStream)
+	c.Header("Cache-Control", "no-cache")
+	// Keeps nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	sent := 0
+	for {
+		events, err := h.svc.WaitSongEvents(ctx, cursor, eventsPingInterval)
+		if ctx.Err() != nil {
+			h.logger.Info("Event stream closed", zap.Int("sent", sent))
+			return
+		}
+		if err != nil {
+			// The status is already sent, so the stream ends and the client resumes from its last event
+			h.logger.Error("Failed to read song events", zap.Int64("after_id", cursor), zap.Error(err))
+			return
+		}
+		if len(events) == 0 {
+			_, err = io.WriteString(c.Writer, ": ping\n\n")
+		}
+		for _, event := range events {
+			data, _ := json.Marshal(changes.Event{
+				ID:         uint64(event.ID),
+				Type:       event.Type,
+				SongID:     event.SongID,
+				Count:      event.Count,
+				OccurredAt: event.OccurredAt,
+			})
+			if _, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				break
+			}
+			cursor = event.ID
+			sent++
+		}
+		if err != nil {
+			h.logger.Info("Event stream client gone", zap.Int("sent", sent), zap.Error(err))
+			return
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/api/middleware"
+	"music-library/internal/service"
+)
+
+// exportContentTypes maps export formats to their MIME types
+var exportContentTypes = map[string]string{
+	service.ExportFormatCSV: "text/csv; charset=utf-8",
+}
+
+// ExportSongs handles the request to download the catalog, narrowed by the GetSongs filters, as a file.
+// The songs are streamed as they are read, so once the download started an error can only cut it short.
+// @Summary Export songs
+// @Description Download the songs matching the GetSongs filters as a file
+// @Tags exports
+// @Produce text/csv
+// @Security BearerAuth
+// @Param format query string false "File format" Enums(csv) default(csv)
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Param group query string false "Filter by group"
+// @Param song query string false "Filter by song"
+// @Param stale_than query string false "Only songs not enriched within this age, e.g. 90d or 12h"
+// @Param missing query string false "Comma separated fields the songs miss" Enums(release_date,text,link)
+// @Param tag query []string false "Only songs with every one of these tags" collectionFormat(multi)
+// @Param genre query string false "Only songs of this genre or its subgenres"
+// @Param favorites query bool false "Only the authenticated user's favorites"
+// @Param min_rating query int false "Only songs with at least this average rating" minimum(1) maximum(5)
+// @Success 200 {file} file "The exported songs"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /songs/export [get]
+func (h *Handler) ExportSongs(c *gin.Context) {
+	h.logger.Info("Handling ExportSongs request")
+
+	format := c.DefaultQuery("format", service.ExportFormatCSV)
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return
+	}
+	filter, ok := h.songFilter(c)
+	if !ok {
+		return
+	}
+	if err := service.ValidateExport(filter, format, dateFormat); err != nil {
+		if errors.Is(err, service.ErrUnsupportedExportFormat) {
+			h.logger.Warn("Invalid export format", zap.String("format", format))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format"})
+			return
+		}
+		h.logger.Warn("Invalid missing fields", zap.Strings("missing", filter.Missing))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid missing: " + err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("songs-%s.%s", time.Now().UTC().Format("20060102"), format)
+	c.Header("Content-Type", exportContentTypes[format])
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+	hidden := h.visibility.hidden(c.GetString(middleware.ContextRole))
+	count, err := h.svc.ExportSongs(c.Request.Context(), c.Writer, filter, format, dateFormat, hidden)
+	if err != nil {
+		h.logger.Error("Export interrupted", zap.Int("exported", count), zap.Error(err))
+		// Aborting the handler makes the server drop the connection, so the client sees a failed download
+		// rather than a complete looking file
+		panic(http.ErrAbortHandler)
+	}
+
+	h.logger.Info("Songs exported successfully", zap.Int("count", count))
+}
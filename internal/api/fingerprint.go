@@ -0,0 +1,111 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/acoustid"
+	"music-library/internal/service"
+)
+
+// MatchFingerprints handles the request to identify recordings by their audio fingerprints and link them to
+// catalog songs. The body is a JSON array of Chromaprint fingerprints with their durations, or a multipart
+// form carrying audio files in "files" to be fingerprinted on the server.
+// @Summary Match audio fingerprints
+// @Description Identify recordings by their Chromaprint fingerprints and link them to catalog songs. A multipart
+// @Description form carrying audio files in "files" is fingerprinted on the server instead.
+// @Tags songs
+// @Accept json,mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param fingerprints body []acoustid.Fingerprint false "Fingerprints with their durations"
+// @Success 200 {array} service.FingerprintMatch
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Router /songs/fingerprints [post]
+func (h *Handler) MatchFingerprints(c *gin.Context) {
+	h.logger.Info("Handling MatchFingerprints request")
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		h.identifyAudio(c)
+		return
+	}
+
+	var req []acoustid.Fingerprint
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req) == 0 || len(req) > maxBulkSongs {
+		h.logger.Warn("Invalid fingerprint count", zap.Int("count", len(req)))
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Request must contain between 1 and %d fingerprints", maxBulkSongs)})
+		return
+	}
+
+	results, err := h.svc.MatchFingerprints(c.Request.Context(), req)
+	h.respondFingerprintMatches(c, results, err)
+}
+
+// identifyAudio stores the uploaded audio files in a temporary directory for fpcalc and matches them
+func (h *Handler) identifyAudio(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		h.logger.Warn("Failed to parse multipart form", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart form"})
+		return
+	}
+	files := form.File["files"]
+	if len(files) == 0 || len(files) > maxBulkSongs {
+		h.logger.Warn("Invalid audio file count", zap.Int("count", len(files)))
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Request must contain between 1 and %d audio files", maxBulkSongs)})
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "fingerprint-")
+	if err != nil {
+		h.logger.Error("Failed to create temporary directory", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	defer os.RemoveAll(dir)
+	paths := make([]string, len(files))
+	for i, file := range files {
+		// Uploaded names are not trusted as paths
+		paths[i] = filepath.Join(dir, fmt.Sprintf("%d%s", i, filepath.Ext(filepath.Base(file.Filename))))
+		if err := c.SaveUploadedFile(file, paths[i]); err != nil {
+			h.logger.Error("Failed to store audio file", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+	}
+
+	results, err := h.svc.IdentifyAudio(c.Request.Context(), paths)
+	h.respondFingerprintMatches(c, results, err)
+}
+
+// respondFingerprintMatches writes the per item results of a fingerprint request
+func (h *Handler) respondFingerprintMatches(c *gin.Context, results []service.FingerprintMatch, err error) {
+	if err != nil {
+		if errors.Is(err, service.ErrFingerprintingUnavailable) || errors.Is(err, service.ErrAudioFingerprintingUnavailable) {
+			h.logger.Warn("Fingerprint matching unavailable", zap.Error(err))
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to match fingerprints", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Fingerprint request processed", zap.Int("count", len(results)))
+	c.JSON(http.StatusOK, results)
+}
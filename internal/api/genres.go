@@ -0,0 +1,294 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/service"
+)
+
+// CreateGenre handles the request to add a genre, optionally as a subgenre of another
+// @Summary Create a genre
+// @Description Add a genre, optionally as a subgenre of another
+// @Tags genres
+// @Accept json
+// @Produce json,xml
+// @Security BearerAuth
+// @Param genre body models.GenreInput true "Genre to create"
+// @Success 201 {object} models.Genre
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /genres [post]
+func (h *Handler) CreateGenre(c *gin.Context) {
+	h.logger.Info("Handling CreateGenre request")
+
+	var genre models.GenreInput
+	if err := c.ShouldBindJSON(&genre); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	created, err := h.svc.CreateGenre(c.Request.Context(), genre)
+	if err != nil {
+		h.respondGenreError(c, err)
+		return
+	}
+
+	h.logger.Info("Genre created successfully", zap.Int("genre_id", created.ID))
+	render(c, http.StatusCreated, created)
+}
+
+// GetGenres handles the request to list the genre taxonomy
+// @Summary Get genres
+// @Description List the genre taxonomy
+// @Tags genres
+// @Produce json,xml
+// @Security BearerAuth
+// @Success 200 {object} models.GenreList
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /genres [get]
+func (h *Handler) GetGenres(c *gin.Context) {
+	h.logger.Info("Handling GetGenres request")
+
+	genres, err := h.svc.GetGenres(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to fetch genres", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Genres retrieved successfully", zap.Int("count", len(genres.Data)))
+	render(c, http.StatusOK, genres)
+}
+
+// GetGenre handles the request to fetch a genre
+// @Summary Get a genre
+// @Tags genres
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Genre ID"
+// @Success 200 {object} models.Genre
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /genres/{id} [get]
+func (h *Handler) GetGenre(c *gin.Context) {
+	h.logger.Info("Handling GetGenre request")
+
+	id, ok := h.genreID(c)
+	if !ok {
+		return
+	}
+
+	genre, err := h.svc.GetGenre(c.Request.Context(), id)
+	if err != nil {
+		h.respondGenreError(c, err)
+		return
+	}
+
+	h.logger.Info("Genre retrieved successfully", zap.Int("genre_id", id))
+	render(c, http.StatusOK, genre)
+}
+
+// UpdateGenre handles the request to rename a genre or move it under another parent
+// @Summary Update a genre
+// @Description Rename a genre or move it under another parent
+// @Tags genres
+// @Accept json
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Genre ID"
+// @Param genre body models.GenreInput true "Updated genre"
+// @Success 200 {object} models.Genre
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /genres/{id} [put]
+func (h *Handler) UpdateGenre(c *gin.Context) {
+	h.logger.Info("Handling UpdateGenre request")
+
+	id, ok := h.genreID(c)
+	if !ok {
+		return
+	}
+	var genre models.GenreInput
+	if err := c.ShouldBindJSON(&genre); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.svc.UpdateGenre(c.Request.Context(), id, genre)
+	if err != nil {
+		h.respondGenreError(c, err)
+		return
+	}
+
+	h.logger.Info("Genre updated successfully", zap.Int("genre_id", id))
+	render(c, http.StatusOK, updated)
+}
+
+// DeleteGenre handles the request to delete a genre without subgenres
+// @Summary Delete a genre
+// @Description Delete a genre without subgenres
+// @Tags genres
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Genre ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /genres/{id} [delete]
+func (h *Handler) DeleteGenre(c *gin.Context) {
+	h.logger.Info("Handling DeleteGenre request")
+
+	id, ok := h.genreID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteGenre(c.Request.Context(), id); err != nil {
+		h.respondGenreError(c, err)
+		return
+	}
+
+	h.logger.Info("Genre deleted successfully", zap.Int("genre_id", id))
+	c.JSON(http.StatusOK, gin.H{"message": "Genre deleted successfully"})
+}
+
+// GetSongGenres handles the request to list the genres of a song
+// @Summary Get the genres of a song
+// @Tags genres
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} models.SongGenres
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/genres [get]
+func (h *Handler) GetSongGenres(c *gin.Context) {
+	h.logger.Info("Handling GetSongGenres request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	genres, err := h.svc.GetSongGenres(c.Request.Context(), songID)
+	if err != nil {
+		h.respondSongGenresError(c, err)
+		return
+	}
+
+	h.logger.Info("Song genres retrieved successfully", zap.Int("song_id", songID), zap.Int("count", len(genres.Genres)))
+	render(c, http.StatusOK, genres)
+}
+
+// SetSongGenresRequest defines the request body for replacing the genres of a song
+type SetSongGenresRequest struct {
+	GenreIDs []int `json:"genre_ids" binding:"required" example:"2"`
+}
+
+// SetSongGenres handles the request to replace the genres of a song
+// @Summary Set the genres of a song
+// @Description Replace the genres of a song
+// @Tags genres
+// @Accept json
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param genres body SetSongGenresRequest true "Genres of the song"
+// @Success 200 {object} models.SongGenres
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/genres [put]
+func (h *Handler) SetSongGenres(c *gin.Context) {
+	h.logger.Info("Handling SetSongGenres request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	var req SetSongGenresRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	genres, err := h.svc.SetSongGenres(c.Request.Context(), songID, req.GenreIDs)
+	if err != nil {
+		h.respondSongGenresError(c, err)
+		return
+	}
+
+	h.logger.Info("Song genres assigned successfully", zap.Int("song_id", songID), zap.Int("count", len(genres.Genres)))
+	render(c, http.StatusOK, genres)
+}
+
+// genreID parses the genre ID of a request, responding with 400 when it is invalid
+func (h *Handler) genreID(c *gin.Context) (int, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Invalid genre ID", zap.String("genre_id", idStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid genre ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondGenreError maps the errors of the genre operations to responses
+func (h *Handler) respondGenreError(c *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+	case errors.Is(err, service.ErrInvalidGenre):
+		h.logger.Warn("Invalid genre", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrGenreExists) || errors.Is(err, service.ErrGenreHasSubgenres):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("Failed to process genre request", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// respondSongGenresError maps the errors of the song genre operations to responses
+func (h *Handler) respondSongGenresError(c *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+	case errors.Is(err, service.ErrInvalidGenre):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrLegalHold):
+		c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("Failed to handle song genres", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -2,40 +2,67 @@ package api
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
-	_ "music-library/internal/models"
+	"music-library/internal/config"
+	"music-library/internal/models"
 	"music-library/internal/service"
 )
 
 // Handler handles HTTP requests for the music library API
 type Handler struct {
-	svc      *service.MusicService
-	logger   *zap.Logger
-	validate *validator.Validate
+	svc        *service.MusicService
+	logger     *zap.Logger
+	validate   *validator.Validate
+	visibility FieldVisibility
+	reloader   *config.Reloader
 }
 
 // NewHandler creates a new instance of Handler
 func NewHandler(svc *service.MusicService, logger *zap.Logger) *Handler {
 	return &Handler{
-		svc:      svc,
-		logger:   logger,
-		validate: validator.New(),
+		svc:        svc,
+		logger:     logger,
+		validate:   validator.New(),
+		visibility: DefaultFieldVisibility,
 	}
 }
 
+// AddSongRequest defines the request body for adding a song
+type AddSongRequest struct {
+	Group string `json:"group" validate:"required" minLength:"1" maxLength:"255" example:"Muse"`
+	Song  string `json:"song" validate:"required" minLength:"1" maxLength:"255" example:"Supermassive Black Hole"`
+}
+
 // AddSong handles the request to add a new song
+// @Summary Add a song
+// @Description Add a new song to the library, fetching additional details from an external API. When the
+// @Description details are fetched in the background the song is accepted with a pending enrichment status.
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param song body AddSongRequest true "Song to add"
+// @Success 200 {object} AddSongResponse
+// @Success 202 {object} AddSongResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /songs [post]
 func (h *Handler) AddSong(c *gin.Context) {
 	h.logger.Info("Handling AddSong request")
 
-	var req struct {
-		Group string `json:"group" validate:"required"`
-		Song  string `json:"song" validate:"required"`
-	}
+	var req AddSongRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warn("Failed to parse request body", zap.Error(err))
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -50,24 +77,62 @@ func (h *Handler) AddSong(c *gin.Context) {
 	}
 
 	h.logger.Debug("Request parsed", zap.String("group", req.Group), zap.String("song", req.Song))
-	id, err := h.svc.AddSong(req.Group, req.Song)
+	id, status, err := h.svc.AddSong(c.Request.Context(), req.Group, req.Song)
 	if err != nil {
+		if errors.Is(err, service.ErrNoExternalData) {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "External API provided no data for the song"})
+			return
+		}
 		h.logger.Error("Failed to add song", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
+	if status == models.EnrichmentPending {
+		c.JSON(http.StatusAccepted, gin.H{"id": id, "enrichment_status": status})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"id": id})
 }
 
-// GetSongs handles the request to retrieve songs with filtering and pagination
+// GetSongs handles the request to retrieve songs with filtering and pagination. With
+// Accept: application/x-ndjson every matching song is streamed instead, see streamSongs.
+// @Summary Get songs
+// @Description Retrieve songs with optional filtering, sorting, facets and pagination. With Accept:
+// @Description application/x-ndjson every matching song is streamed, one JSON object per line.
+// @Tags songs
+// @Produce json,xml,application/x-ndjson
+// @Security BearerAuth
+// @Param group query string false "Filter by group"
+// @Param song query string false "Filter by song"
+// @Param stale_than query string false "Only songs not enriched within this age, e.g. 90d or 12h"
+// @Param missing query string false "Comma separated fields the songs miss" Enums(release_date,text,link)
+// @Param tag query []string false "Only songs with every one of these tags" collectionFormat(multi)
+// @Param genre query string false "Only songs of this genre or its subgenres"
+// @Param favorites query bool false "Only the authenticated user's favorites"
+// @Param min_rating query int false "Only songs with at least this average rating" minimum(1) maximum(5)
+// @Param sort query string false "Sort order" Enums(id,views,rating,popularity) default(id)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param facets query string false "Comma separated facets to count the matching songs by" Enums(year,decade,group,genre)
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Param personal query bool false "Apply the authenticated user's personal overrides"
+// @Param title_lang query string false "Comma separated languages to localize titles in, preferred over Accept-Language"
+// @Param X-API-Compat header string false "legacy for the response shapes from before the envelopes" Enums(legacy,current)
+// @Success 200 {object} models.SongPage
+// @Header 200 {integer} X-Total-Count "Number of matching songs"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs [get]
 func (h *Handler) GetSongs(c *gin.Context) {
 	h.logger.Info("Handling GetSongs request")
 
-	group := c.Query("group")
-	song := c.Query("song")
+	preferences := h.userPreferences(c)
+	sort := c.DefaultQuery("sort", preferredSort(preferences, "id"))
 	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
+	limitStr := c.DefaultQuery("limit", preferredLimit(preferences, "10"))
 
 	page, err := strconv.Atoi(pageStr)
 	if err != nil || page < 1 {
@@ -83,18 +148,219 @@ func (h *Handler) GetSongs(c *gin.Context) {
 		return
 	}
 
-	songs, err := h.svc.GetSongs(group, song, page, limit)
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return
+	}
+
+	filter, ok := h.songFilter(c)
+	if !ok {
+		return
+	}
+	personalUserID, ok := h.personalUser(c)
+	if !ok {
+		return
+	}
+	// The representation depends on Accept
+	varyOnAccept(c)
+	if wantsStream(c) {
+		h.streamSongs(c, filter, dateFormat)
+		return
+	}
+
+	songs, err := h.svc.GetSongs(c.Request.Context(), filter, sort, page, limit)
 	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedSort) {
+			h.logger.Warn("Invalid sort", zap.String("sort", sort))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort"})
+			return
+		}
+		if errors.Is(err, service.ErrUnsupportedField) {
+			h.logger.Warn("Invalid missing fields", zap.Strings("missing", filter.Missing))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid missing: " + err.Error()})
+			return
+		}
 		h.logger.Error("Failed to fetch songs", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
+	if personalUserID != 0 {
+		if err := h.svc.ApplySongOverrides(c.Request.Context(), personalUserID, songs.Data); err != nil {
+			h.logger.Error("Failed to apply song overrides", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+	}
+	localized := make([]*models.Song, len(songs.Data))
+	for i := range songs.Data {
+		localized[i] = &songs.Data[i]
+	}
+	h.localizeTitles(c, preferences, localized...)
+	service.FormatSongDates(songs.Data, dateFormat)
+	c.Header("X-Total-Count", strconv.Itoa(songs.Total))
+
+	facetsStr := c.Query("facets")
+	if facetsStr == "" {
+		h.logger.Info("Songs retrieved successfully", zap.Int("count", len(songs.Data)), zap.Int("total", songs.Total))
+		h.renderSongPage(c, songs, preferences)
+		return
+	}
 
-	h.logger.Info("Songs retrieved successfully", zap.Int("count", len(songs)))
-	c.JSON(http.StatusOK, songs)
+	facets, err := h.svc.GetSongFacets(c.Request.Context(), filter, strings.Split(facetsStr, ","))
+	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedFacet) {
+			h.logger.Warn("Invalid facets", zap.String("facets", facetsStr))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid facets: " + err.Error()})
+			return
+		}
+		h.logger.Error("Failed to fetch facets", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	songs.Facets = facets
+	h.logger.Info("Songs retrieved successfully", zap.Int("count", len(songs.Data)), zap.Int("facets", len(facets)))
+	h.renderSongPage(c, songs, preferences)
+}
+
+// renderSongPage responds with the page of songs in the envelope, or in the legacy shape when the request
+// asks for it. XML has no legacy shape.
+func (h *Handler) renderSongPage(c *gin.Context, songs models.SongPage, preferences models.Preferences) {
+	if !wantsXML(c) && legacyResponses(c, preferences) {
+		h.renderSongs(c, http.StatusOK, legacySongPage(songs))
+		return
+	}
+	h.renderSongs(c, http.StatusOK, songs)
+}
+
+// CountSongs handles HEAD /songs, reporting the number of songs matching the GetSongs filters
+// in the X-Total-Count header without fetching any of them
+// @Summary Count songs
+// @Description Report the number of songs matching the GetSongs filters in X-Total-Count, without a body
+// @Tags songs
+// @Security BearerAuth
+// @Param group query string false "Filter by group"
+// @Param song query string false "Filter by song"
+// @Param stale_than query string false "Only songs not enriched within this age, e.g. 90d or 12h"
+// @Param missing query string false "Comma separated fields the songs miss" Enums(release_date,text,link)
+// @Param tag query []string false "Only songs with every one of these tags" collectionFormat(multi)
+// @Param genre query string false "Only songs of this genre or its subgenres"
+// @Param favorites query bool false "Only the authenticated user's favorites"
+// @Param min_rating query int false "Only songs with at least this average rating" minimum(1) maximum(5)
+// @Success 200
+// @Header 200 {integer} X-Total-Count "Number of matching songs"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 500
+// @Router /songs [head]
+func (h *Handler) CountSongs(c *gin.Context) {
+	h.logger.Info("Handling CountSongs request")
+
+	filter, ok := h.songFilter(c)
+	if !ok {
+		return
+	}
+
+	total, err := h.svc.CountSongs(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedField) {
+			h.logger.Warn("Invalid missing fields", zap.Strings("missing", filter.Missing))
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to count songs", zap.Error(err))
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("Songs counted successfully", zap.Int("total", total))
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.Status(http.StatusOK)
+}
+
+// SongExists handles the request to check whether a song with the exact group and title exists, ignoring case
+// @Summary Check whether a song exists
+// @Description Look up a song by its exact group and title, ignoring case
+// @Tags songs
+// @Produce json
+// @Security BearerAuth
+// @Param group query string true "Group"
+// @Param song query string true "Song"
+// @Success 200 {object} SongExistsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/exists [get]
+func (h *Handler) SongExists(c *gin.Context) {
+	h.logger.Info("Handling SongExists request")
+
+	group := c.Query("group")
+	song := c.Query("song")
+	if group == "" || song == "" {
+		h.logger.Error("Missing group or song", zap.String("group", group), zap.String("song", song))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group and song are required"})
+		return
+	}
+
+	id, exists, err := h.svc.SongExists(c.Request.Context(), group, song)
+	if err != nil {
+		h.logger.Error("Failed to look up song", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	if !exists {
+		c.JSON(http.StatusOK, gin.H{"exists": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"exists": true, "id": id})
+}
+
+// songFilter reads the group, song, stale_than, missing, tag and genre query parameters shared by the song listings.
+// It responds with 400 and returns false when stale_than is invalid.
+func (h *Handler) songFilter(c *gin.Context) (models.SongFilter, bool) {
+	filter := models.SongFilter{Group: c.Query("group"), Song: c.Query("song")}
+	if staleStr := c.Query("stale_than"); staleStr != "" {
+		staleThan, err := parseAge(staleStr)
+		if err != nil {
+			h.logger.Error("Invalid stale_than", zap.String("stale_than", staleStr))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stale_than"})
+			return filter, false
+		}
+		filter.StaleThan = staleThan
+	}
+	if missingStr := c.Query("missing"); missingStr != "" {
+		filter.Missing = strings.Split(missingStr, ",")
+	}
+	filter.Tags = filterTags(c.QueryArray("tag"))
+	filter.Genre = strings.TrimSpace(c.Query("genre"))
+	if !h.ratingFilter(c, &filter) {
+		return filter, false
+	}
+	return filter, true
 }
 
 // GetVerses handles the request to retrieve verses for a song
+// @Summary Get song verses
+// @Description Retrieve the verses of a song with pagination
+// @Tags songs
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param delimiter query string false "Verse delimiter overriding the configured one, e.g. \n\n"
+// @Param personal query bool false "Apply the authenticated user's personal overrides"
+// @Success 200 {object} service.VersePage
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /songs/{id}/verses [get]
 func (h *Handler) GetVerses(c *gin.Context) {
 	h.logger.Info("Handling GetVerses request")
 
@@ -123,23 +389,76 @@ func (h *Handler) GetVerses(c *gin.Context) {
 		return
 	}
 
-	verses, err := h.svc.GetVerses(songID, page, limit)
+	personalUserID, ok := h.personalUser(c)
+	if !ok {
+		return
+	}
+
+	ctx, markStale := withStaleness(c)
+	var verses *service.VersePage
+	if personalUserID != 0 {
+		verses, err = h.svc.GetPersonalVerses(ctx, personalUserID, songID, page, limit, c.Query("delimiter"))
+	} else {
+		verses, err = h.svc.GetVerses(ctx, songID, page, limit, c.Query("delimiter"))
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			h.logger.Warn("Song not found", zap.Int("song_id", songID))
 			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
 			return
 		}
+		if errors.Is(err, service.ErrPageOutOfRange) {
+			h.logger.Warn("Verse page out of range", zap.Int("song_id", songID), zap.Int("page", page))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number: " + err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidVerseDelimiter) {
+			h.logger.Warn("Invalid verse delimiter", zap.String("delimiter", c.Query("delimiter")))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrDegraded) {
+			h.logger.Warn("Verses unavailable while degraded", zap.Int("song_id", songID))
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Database unavailable"})
+			return
+		}
 		h.logger.Error("Failed to fetch verses", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	h.logger.Info("Verses retrieved successfully", zap.Int("song_id", songID), zap.Int("count", len(verses)))
-	c.JSON(http.StatusOK, verses)
+	h.logger.Info("Verses retrieved successfully", zap.Int("song_id", songID), zap.Int("count", len(verses.Verses)))
+	markStale()
+	render(c, http.StatusOK, verses)
+}
+
+// UpdateSongRequest defines the request body for updating a song
+type UpdateSongRequest struct {
+	Group       string `json:"group" minLength:"1" maxLength:"255" example:"Muse"`
+	Song        string `json:"song" minLength:"1" maxLength:"255" example:"Supermassive Black Hole"`
+	ReleaseDate string `json:"release_date" maxLength:"10" example:"16.07.2006"`
+	Text        string `json:"text" example:"Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?"`
+	Link        string `json:"link" maxLength:"255" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
 }
 
 // UpdateSong handles the request to update an existing song
+// @Summary Update a song
+// @Description Replace the fields of an existing song
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Param song body UpdateSongRequest true "Updated song"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id} [put]
 func (h *Handler) UpdateSong(c *gin.Context) {
 	h.logger.Info("Handling UpdateSong request")
 
@@ -151,22 +470,109 @@ func (h *Handler) UpdateSong(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Group       string `json:"group"`
-		Song        string `json:"song"`
-		ReleaseDate string `json:"release_date"`
-		Text        string `json:"text"`
-		Link        string `json:"link"`
-	}
+	var req UpdateSongRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warn("Failed to parse request body", zap.Error(err))
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return
+	}
+	req.ReleaseDate, err = service.NormalizeReleaseDate(req.ReleaseDate, dateFormat)
+	if err != nil {
+		h.logger.Warn("Invalid release date", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	h.logger.Debug("Request parsed", zap.String("group", req.Group), zap.String("song", req.Song))
-	err = h.svc.UpdateSong(songID, req.Group, req.Song, req.ReleaseDate, req.Text, req.Link)
+	err = h.svc.UpdateSong(c.Request.Context(), songID, req.Group, req.Song, req.ReleaseDate, req.Text, req.Link)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidReleaseDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrLegalHold) {
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+			return
+		}
+		if err == sql.ErrNoRows {
+			h.logger.Warn("Song not found", zap.Int("song_id", songID))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+			return
+		}
+		h.logger.Error("Failed to update song", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Song updated successfully", zap.Int("song_id", songID))
+	c.JSON(http.StatusOK, gin.H{"message": "Song updated successfully"})
+}
+
+// PatchSong handles the request to update only the provided fields of a song.
+// Omitted fields are left unchanged, null clears optional fields.
+// @Summary Patch a song
+// @Description Update only the provided fields of a song. Omitted fields are left unchanged, null clears optional fields.
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Param patch body models.SongPatch true "Fields to change"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id} [patch]
+func (h *Handler) PatchSong(c *gin.Context) {
+	h.logger.Info("Handling PatchSong request")
+
+	songIDStr := c.Param("id")
+	songID, err := strconv.Atoi(songIDStr)
 	if err != nil {
+		h.logger.Error("Invalid song ID", zap.String("song_id", songIDStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	var patch models.SongPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return
+	}
+	if field := &patch.ReleaseDate; field.Set && !field.Null && field.Value != "" {
+		field.Value, err = service.NormalizeReleaseDate(field.Value, dateFormat)
+		if err != nil {
+			h.logger.Warn("Invalid release date", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	err = h.svc.UpdateSongPartial(c.Request.Context(), songID, patch)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPatch) || errors.Is(err, service.ErrInvalidReleaseDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrLegalHold) {
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+			return
+		}
 		if err == sql.ErrNoRows {
 			h.logger.Warn("Song not found", zap.Int("song_id", songID))
 			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
@@ -182,6 +588,20 @@ func (h *Handler) UpdateSong(c *gin.Context) {
 }
 
 // DeleteSong handles the request to delete a song
+// @Summary Delete a song
+// @Description Delete a song by its ID. The song is kept in the trash until it is purged.
+// @Tags songs
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id} [delete]
 func (h *Handler) DeleteSong(c *gin.Context) {
 	h.logger.Info("Handling DeleteSong request")
 
@@ -193,8 +613,12 @@ func (h *Handler) DeleteSong(c *gin.Context) {
 		return
 	}
 
-	err = h.svc.DeleteSong(songID)
+	err = h.svc.DeleteSong(c.Request.Context(), songID)
 	if err != nil {
+		if errors.Is(err, service.ErrLegalHold) {
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+			return
+		}
 		if err == sql.ErrNoRows {
 			h.logger.Warn("Song not found", zap.Int("song_id", songID))
 			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
@@ -209,17 +633,72 @@ func (h *Handler) DeleteSong(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Song deleted successfully"})
 }
 
-// TruncateSongs handles the request to truncate the songs table
+// TruncateSongs handles the request to truncate the songs table. With ?backup=true a snapshot of the songs is
+// taken first, and its ID returned for the restore endpoint.
+// @Summary Truncate songs
+// @Description Delete all songs and reset the ID sequence. With backup=true a snapshot of the songs is taken first.
+// @Tags songs
+// @Produce json
+// @Security BearerAuth
+// @Param backup query bool false "Take a snapshot of the songs first" default(false)
+// @Success 200 {object} TruncateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/truncate [post]
 func (h *Handler) TruncateSongs(c *gin.Context) {
 	h.logger.Info("Handling TruncateSongs request")
 
-	err := h.svc.TruncateSongs()
+	backup, err := strconv.ParseBool(c.DefaultQuery("backup", "false"))
+	if err != nil {
+		h.logger.Error("Invalid backup flag", zap.String("backup", c.Query("backup")))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid backup flag"})
+		return
+	}
+	snapshot, err := h.svc.TruncateSongs(c.Request.Context(), backup)
 	if err != nil {
+		if errors.Is(err, service.ErrLegalHold) {
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("Failed to truncate table", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
 	h.logger.Info("Table truncated and sequence reset")
+	if snapshot != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Table truncated and sequence reset", "snapshot_id": snapshot.ID})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Table truncated and sequence reset"})
 }
+
+// parseAge parses a positive age such as "90d", "12h" or "30m"; days are accepted in addition to time.ParseDuration units
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age <= 0 {
+		return 0, fmt.Errorf("invalid age %q", value)
+	}
+	return age, nil
+}
+
+// dateFormat reads the date_format query parameter, responding with 400 when it is unsupported
+func (h *Handler) dateFormat(c *gin.Context) (string, bool) {
+	format := c.Query("date_format")
+	if err := service.ValidateDateFormat(format); err != nil {
+		h.logger.Warn("Invalid date_format", zap.String("date_format", format))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date_format"})
+		return "", false
+	}
+	return format, true
+}
@@ -1,44 +1,51 @@
 package api
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"music-library/docs"
+	"music-library/internal/api/middleware"
+	"music-library/internal/auth"
+	"music-library/internal/changes"
+	"music-library/internal/config"
+	"music-library/internal/jobs"
 	"music-library/internal/models"
 	"music-library/internal/repository"
 	"music-library/internal/service"
 )
 
-// AddSongRequest defines the request body for adding a song
-type AddSongRequest struct {
-	Group string `json:"group" validate:"required"`
-	Song  string `json:"song" validate:"required"`
-}
+// testAdminToken is the admin token used by the test router
+const testAdminToken = "test-admin-token"
 
-// UpdateSongRequest defines the request body for updating a song
-type UpdateSongRequest struct {
-	Group       string `json:"group"`
-	Song        string `json:"song"`
-	ReleaseDate string `json:"release_date"`
-	Text        string `json:"text"`
-	Link        string `json:"link"`
-}
+// selectSongByID reads a song row without the generated search column
+const selectSongByID = "SELECT id, group_name, song_name, release_date, text, link, created_at, updated_at, enriched_at FROM songs WHERE id=$1"
 
-// ErrorResponse defines the structure of an error response
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
+// testTokens signs the access tokens accepted by the test router
+var testTokens = auth.NewTokens(auth.Config{Secret: []byte("test-secret-test-secret-test-secret")})
+
+// testReadiness is the readiness state used by the test router
+var testReadiness = &Readiness{}
 
 func setupTest(t *testing.T) (*gin.Engine, *sqlx.DB, func()) {
 	logger, err := zap.NewDevelopment()
@@ -57,19 +64,110 @@ func setupTest(t *testing.T) (*gin.Engine, *sqlx.DB, func()) {
 	repo := repository.NewPostgresRepository(db, logger)
 	httpClient := &http.Client{Timeout: 10 * time.Second}
 	svc := service.NewMusicService(repo, logger, httpClient)
+	svc.ConfigureAuth(testTokens)
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	manager := jobs.NewManager(repo, logger, jobs.Config{ProgressInterval: 10 * time.Millisecond})
+	manager.Start(jobsCtx)
+	svc.ConfigureJobs(manager)
 	handler := NewHandler(svc, logger)
 
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.GET("/healthz", handler.Healthz)
+	r.GET("/readyz", func(c *gin.Context) { handler.Readyz(testReadiness)(c) })
 	r.POST("/songs", handler.AddSong)
-	r.GET("/songs", handler.GetSongs)
-	r.GET("/songs/:id/verses", handler.GetVerses)
+	r.POST("/songs/bulk", handler.AddSongs)
+	r.GET("/songs", middleware.OptionalUser(testTokens, logger), handler.GetSongs)
+	r.HEAD("/songs", handler.CountSongs)
+	r.GET("/songs/exists", handler.SongExists)
+	r.GET("/songs/trending", handler.GetTrendingSongs)
+	r.GET("/songs/search", handler.SearchSongs)
+	r.GET("/songs/export", handler.ExportSongs)
+	r.GET("/songs/:id/verses", middleware.OptionalUser(testTokens, logger), handler.GetVerses)
+	r.GET("/songs/:id/subtitles", handler.GetSubtitles)
+	r.GET("/songs/:id/enrichment-status", handler.GetEnrichmentStatus)
 	r.PUT("/songs/:id", handler.UpdateSong)
+	r.PATCH("/songs/:id", handler.PatchSong)
+	r.POST("/songs/:id/enrich", handler.ReenrichSong)
 	r.DELETE("/songs/:id", handler.DeleteSong)
 	r.POST("/songs/truncate", handler.TruncateSongs)
+	r.GET("/trash", handler.GetTrash)
+	r.POST("/trash/:id/restore", handler.RestoreTrashedSong)
+	r.DELETE("/trash/:id", handler.PurgeTrashedSong)
+	r.POST("/songs/import", handler.ImportSongs)
+	r.POST("/songs/import/preview", handler.PreviewImport)
+	r.POST("/songs/tags/bulk", handler.BulkTagSongs)
+	r.GET("/songs/:id/tags", handler.GetSongTags)
+	r.POST("/songs/:id/tags", handler.AddSongTags)
+	r.DELETE("/songs/:id/tags/:tag", handler.RemoveSongTag)
+	r.GET("/songs/:id/genres", handler.GetSongGenres)
+	r.GET("/songs/:id/titles", handler.GetSongTitles)
+	r.PUT("/songs/:id/titles/:lang", handler.SetSongTitle)
+	r.DELETE("/songs/:id/titles/:lang", handler.DeleteSongTitle)
+	r.GET("/songs/:id/revisions", handler.GetSongRevisions)
+	r.POST("/songs/:id/revisions/:rev/restore", handler.RestoreSongRevision)
+	r.PUT("/songs/:id/genres", handler.SetSongGenres)
+	r.POST("/genres", handler.CreateGenre)
+	r.GET("/genres/:id", handler.GetGenre)
+	r.PUT("/genres/:id", handler.UpdateGenre)
+	r.DELETE("/genres/:id", handler.DeleteGenre)
+	r.GET("/calendar.ics", handler.GetReleaseCalendar)
+	r.GET("/digests/latest", handler.GetLatestDigest)
+	r.GET("/changes/poll", handler.PollChanges)
+	r.GET("/ws/events", handler.StreamEvents)
+	r.GET("/songs/stream", handler.StreamSongEvents)
+	r.POST("/webhooks", handler.CreateWebhook)
+	r.GET("/webhooks", handler.GetWebhooks)
+	r.DELETE("/webhooks/:id", handler.DeleteWebhook)
+	r.GET("/webhooks/:id/deliveries", handler.GetWebhookDeliveries)
+	r.POST("/webhooks/:id/deliveries/:delivery/retry", handler.RetryWebhookDelivery)
+	r.POST("/webhooks/:id/secret/rotate", handler.RotateWebhookSecret)
+	r.GET("/jobs/:id", middleware.RequireUser(testTokens, logger), handler.GetJob)
+	r.GET("/groups/:name/stats", handler.GetGroupStats)
+	r.POST("/auth/register", handler.Register)
+	r.POST("/auth/login", handler.Login)
+	r.POST("/auth/refresh", handler.RefreshToken)
+	r.GET("/auth/me", middleware.RequireUser(testTokens, logger), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetInt(middleware.ContextUserID), "username": c.GetString(middleware.ContextUsername), "role": c.GetString(middleware.ContextRole)})
+	})
+
+	me := r.Group("/me", middleware.RequireUser(testTokens, logger))
+	me.GET("/preferences", handler.GetPreferences)
+	me.PUT("/preferences", handler.UpdatePreferences)
+	me.GET("/overrides/:id", handler.GetSongOverride)
+	me.PUT("/overrides/:id", handler.SaveSongOverride)
+	me.DELETE("/overrides/:id", handler.DeleteSongOverride)
+	rated := r.Group("/songs", middleware.RequireUser(testTokens, logger))
+	rated.POST("/:id/rating", handler.RateSong)
+	rated.DELETE("/:id/rating", handler.DeleteSongRating)
+	rated.POST("/:id/favorite", handler.FavoriteSong)
+	rated.DELETE("/:id/favorite", handler.UnfavoriteSong)
+
+	admin := r.Group("/admin", middleware.AdminAuth(testAdminToken, logger))
+	admin.GET("/query-log", handler.GetQueryLog)
+	admin.GET("/providers", handler.GetProviderBudgets)
+	admin.GET("/api-captures", handler.GetAPICaptures)
+	admin.GET("/config/export", handler.ExportConfig)
+	admin.POST("/config/import", handler.ImportConfig)
+	admin.GET("/config/live", handler.GetLiveConfig)
+	admin.POST("/config/reload", handler.ReloadConfig)
+	admin.GET("/users", handler.GetUsers)
+	admin.PUT("/users/:id/role", handler.SetUserRole)
+	admin.PUT("/songs/:id/legal-hold", handler.SetLegalHold)
+	admin.POST("/similarity-report", handler.StartSimilarityReport)
+	admin.GET("/similarity-report", handler.GetSimilarityReport)
+	admin.POST("/enrich-all", handler.StartEnrichAll)
+	admin.GET("/jobs/:id", handler.GetJob)
+	admin.GET("/classifications", handler.GetClassificationSuggestions)
+	admin.POST("/classifications/:id/accept", handler.AcceptClassificationSuggestion)
+	admin.POST("/classifications/:id/reject", handler.RejectClassificationSuggestion)
+	admin.GET("/snapshots", handler.GetSnapshots)
+	admin.POST("/snapshots/:id/restore", handler.RestoreSnapshot)
 
 	cleanup := func() {
-		_, err := db.Exec("TRUNCATE TABLE songs RESTART IDENTITY")
+		stopJobs()
+		manager.Wait()
+		_, err := db.Exec("TRUNCATE TABLE songs, imports, users, user_preferences, song_overrides, song_tags, tags, jobs, api_captures, webhooks, webhook_deliveries, song_events, snapshots, genres, trashed_songs RESTART IDENTITY CASCADE")
 		if err != nil {
 			t.Logf("Failed to truncate table in cleanup: %v", err)
 		}
@@ -100,7 +198,7 @@ func TestAddSong(t *testing.T) {
 
 		// Проверка в БД
 		var song models.Song
-		err = db.Get(&song, "SELECT * FROM songs WHERE id=$1", resp["id"])
+		err = db.Get(&song, selectSongByID, resp["id"])
 		assert.NoError(t, err)
 		assert.Equal(t, "Muse", song.Group)
 	})
@@ -122,6 +220,95 @@ func TestAddSong(t *testing.T) {
 	})
 }
 
+func TestAddSongs(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	reqBody := []service.BulkSong{
+		{Group: "Muse", Song: "Uprising"},
+		{Group: "", Song: "No group"},
+		{Group: "Muse", Song: "Starlight"},
+	}
+	bodyBytes, _ := json.Marshal(reqBody)
+	req, _ := http.NewRequest(http.MethodPost, "/songs/bulk", bytes.NewBuffer(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results []service.BulkItemResult
+	err := json.Unmarshal(w.Body.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.NotZero(t, results[0].ID)
+	assert.Equal(t, "group and song are required", results[1].Error)
+	assert.NotZero(t, results[2].ID)
+
+	var count int
+	err = db.Get(&count, "SELECT COUNT(*) FROM songs")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestCompatModes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterMockRoutes(r, zap.NewNop())
+	get := func(target, compat string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		if compat != "" {
+			req.Header.Set(CompatHeader, compat)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/songs", "")
+	var page models.SongPage
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Len(t, page.Data, 1)
+	assert.Empty(t, w.Header().Get(CompatHeader))
+
+	w = get("/songs", models.APICompatLegacy)
+	var songs []models.Song
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &songs))
+	assert.Len(t, songs, 1)
+	assert.Equal(t, models.APICompatLegacy, w.Header().Get(CompatHeader))
+	assert.Contains(t, w.Header().Values("Vary"), CompatHeader)
+
+	w = get("/songs?facets=year", models.APICompatLegacy)
+	var faceted struct {
+		Data   []models.Song                   `json:"data"`
+		Facets map[string][]models.FacetBucket `json:"facets"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &faceted))
+	assert.Len(t, faceted.Data, 1)
+	assert.NotEmpty(t, faceted.Facets)
+	assert.NotContains(t, w.Body.String(), "total_pages")
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest(http.MethodGet, "/songs", nil)
+	assert.True(t, legacyResponses(c, models.Preferences{APICompat: models.APICompatLegacy}), "the preference selects legacy shapes")
+	c.Request.Header.Set(CompatHeader, "current")
+	assert.False(t, legacyResponses(c, models.Preferences{APICompat: models.APICompatLegacy}), "the header overrides the preference")
+
+	// Errors keep the {"error": "..."} shape in both modes
+	handler := NewHandler(service.NewMusicService(nil, zap.NewNop(), http.DefaultClient), zap.NewNop())
+	errorRouter := gin.New()
+	errorRouter.GET("/songs", handler.GetSongs)
+	for _, compat := range []string{"", models.APICompatLegacy} {
+		req, _ := http.NewRequest(http.MethodGet, "/songs?page=0", nil)
+		req.Header.Set(CompatHeader, compat)
+		w := httptest.NewRecorder()
+		errorRouter.ServeHTTP(w, req)
+		var resp ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "Invalid page number", resp.Error)
+	}
+}
+
 func TestGetSongs(t *testing.T) {
 	r, db, cleanup := setupTest(t)
 	defer cleanup()
@@ -138,11 +325,157 @@ func TestGetSongs(t *testing.T) {
 		r.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		var songs []models.Song
+		var songs models.SongPage
 		err := json.Unmarshal(w.Body.Bytes(), &songs)
 		assert.NoError(t, err)
+		assert.Len(t, songs.Data, 1)
+		assert.Equal(t, "Muse", songs.Data[0].Group)
+		assert.Equal(t, 1, songs.Total)
+		assert.Equal(t, 1, songs.Page)
+		assert.Equal(t, 10, songs.Limit)
+		assert.Equal(t, 1, songs.TotalPages)
+	})
+
+	t.Run("Legacy Shape", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs?group=Muse", nil)
+		req.Header.Set(CompatHeader, models.APICompatLegacy)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, models.APICompatLegacy, w.Header().Get(CompatHeader))
+		assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+		var songs []models.Song
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &songs), "legacy responses are a bare array")
 		assert.Len(t, songs, 1)
-		assert.Equal(t, "Muse", songs[0].Group)
+	})
+
+	t.Run("NDJSON Stream", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs?group=Muse&date_format=iso", nil)
+		req.Header.Set("Accept", middleware.ContentTypeNDJSON)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, middleware.ContentTypeNDJSON, w.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+		assert.Len(t, lines, 1)
+		var song models.Song
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &song))
+		assert.Equal(t, "Supermassive Black Hole", song.Song)
+		if assert.NotNil(t, song.ReleaseDate) {
+			assert.Equal(t, "2006-07-16", *song.ReleaseDate)
+		}
+
+		req, _ = http.NewRequest(http.MethodGet, "/songs?sort=views", nil)
+		req.Header.Set("Accept", middleware.ContentTypeNDJSON)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "streams are only sorted by id")
+	})
+
+	t.Run("Sort By Popularity", func(t *testing.T) {
+		var id int
+		err := db.Get(&id, `INSERT INTO songs (group_name, song_name, release_date) VALUES ('Muse', 'Starlight', '03.09.2006') RETURNING id`)
+		assert.NoError(t, err)
+		defer db.Exec("DELETE FROM songs WHERE id = $1", id)
+		_, err = db.Exec("INSERT INTO song_views (song_id, views) VALUES ($1, 5)", id)
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodGet, "/songs?group=Muse&sort=popularity", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var songs models.SongPage
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &songs))
+		assert.Len(t, songs.Data, 2)
+		assert.Equal(t, "Starlight", songs.Data[0].Song, "the most played song comes first")
+	})
+
+	t.Run("Count Only", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodHead, "/songs?group=muse", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+		assert.Empty(t, w.Body.String())
+
+		req, _ = http.NewRequest(http.MethodHead, "/songs?missing=song_name", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs/exists?group=muse&song=supermassive+black+hole", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"exists": true, "id": 1}`, w.Body.String())
+
+		req, _ = http.NewRequest(http.MethodGet, "/songs/exists?group=Muse&song=Supermassive", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"exists": false}`, w.Body.String(), "titles must match exactly")
+
+		req, _ = http.NewRequest(http.MethodGet, "/songs/exists?group=Muse", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Stale Than", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO songs (group_name, song_name, release_date, enriched_at) VALUES 
+			('Muse', 'Uprising', '07.09.2009', NOW()), ('Muse', 'Hysteria', '01.12.2003', NOW() - INTERVAL '100 days')`)
+		assert.NoError(t, err)
+		defer db.Exec("DELETE FROM songs WHERE song_name IN ('Uprising', 'Hysteria')")
+
+		req, _ := http.NewRequest(http.MethodGet, "/songs?group=Muse&stale_than=90d", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var songs models.SongPage
+		err = json.Unmarshal(w.Body.Bytes(), &songs)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, songs.Total, "never-enriched and 100-day-old songs are stale")
+		for _, song := range songs.Data {
+			assert.NotEqual(t, "Uprising", song.Song)
+		}
+
+		req, _ = http.NewRequest(http.MethodGet, "/songs?stale_than=soon", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Missing Fields", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO songs (group_name, song_name) VALUES ('Muse', 'Unknown Demo')`)
+		assert.NoError(t, err)
+		defer db.Exec("DELETE FROM songs WHERE song_name = 'Unknown Demo'")
+
+		req, _ := http.NewRequest(http.MethodGet, "/songs?missing=text,release_date", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"text":null`)
+		var songs models.SongPage
+		err = json.Unmarshal(w.Body.Bytes(), &songs)
+		assert.NoError(t, err)
+		if assert.Len(t, songs.Data, 1) {
+			assert.Equal(t, "Unknown Demo", songs.Data[0].Song)
+			assert.Nil(t, songs.Data[0].Text)
+			assert.Nil(t, songs.Data[0].ReleaseDate)
+		}
+
+		req, _ = http.NewRequest(http.MethodGet, "/songs?missing=group", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 
 	t.Run("Invalid Page", func(t *testing.T) {
@@ -156,6 +489,60 @@ func TestGetSongs(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "Invalid page number", resp.Error)
 	})
+
+	t.Run("Invalid Sort", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs?sort=plays", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Facets", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs?facets=year,decade,group", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data   []models.Song                   `json:"data"`
+			Facets map[string][]models.FacetBucket `json:"facets"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		assert.Len(t, resp.Data, 1)
+		assert.Equal(t, []models.FacetBucket{{Value: "Muse", Count: 1}}, resp.Facets["group"])
+		// release_date is text, so the year and decade are read out of the stored DD.MM.YYYY value
+		assert.Equal(t, []models.FacetBucket{{Value: "2006", Count: 1}}, resp.Facets["year"])
+		assert.Equal(t, []models.FacetBucket{{Value: "2000s", Count: 1}}, resp.Facets["decade"])
+	})
+
+	t.Run("Genre Facet", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO genres (name) VALUES ('Rock'), ('Electronic')`)
+		assert.NoError(t, err)
+		_, err = db.Exec(`INSERT INTO song_genres (song_id, genre_id) SELECT s.id, g.id FROM songs s CROSS JOIN genres g`)
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodGet, "/songs?facets=genre", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Facets map[string][]models.FacetBucket `json:"facets"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		// A song counts once for each of its genres
+		assert.Equal(t, []models.FacetBucket{{Value: "Electronic", Count: 1}, {Value: "Rock", Count: 1}}, resp.Facets["genre"])
+	})
+
+	t.Run("Unsupported Facet", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs?facets=mood", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
 }
 
 func TestGetVerses(t *testing.T) {
@@ -175,72 +562,81 @@ func TestGetVerses(t *testing.T) {
 		r.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		var verses []service.Verse
+		var verses service.VersePage
 		err := json.Unmarshal(w.Body.Bytes(), &verses)
 		assert.NoError(t, err)
-		assert.Len(t, verses, 2)
-		assert.Equal(t, "Verse 1", verses[0].Text)
-		assert.Equal(t, "Verse 2", verses[1].Text)
+		assert.Equal(t, songID, verses.SongID)
+		assert.Equal(t, "Muse", verses.Group)
+		assert.Equal(t, "Supermassive Black Hole", verses.Song)
+		assert.Equal(t, 3, verses.TotalVerses)
+		assert.Equal(t, 1, verses.Page)
+		assert.Len(t, verses.Verses, 2)
+		assert.Equal(t, "Verse 1", verses.Verses[0].Text)
+		assert.Equal(t, "Verse 2", verses.Verses[1].Text)
 	})
 
-	t.Run("Song Not Found", func(t *testing.T) {
-		req, _ := http.NewRequest(http.MethodGet, "/songs/999/verses", nil)
+	t.Run("Custom Delimiter", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/songs/%d/verses?delimiter=line&page=2&limit=1", songID), nil)
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusNotFound, w.Code)
-		var resp ErrorResponse
-		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var verses service.VersePage
+		err := json.Unmarshal(w.Body.Bytes(), &verses)
 		assert.NoError(t, err)
-		assert.Equal(t, "Song not found", resp.Error)
+		assert.Equal(t, 3, verses.TotalVerses)
+		if assert.Len(t, verses.Verses, 1) {
+			assert.Equal(t, 2, verses.Verses[0].Number)
+			assert.Equal(t, "Verse 2", verses.Verses[0].Text)
+		}
 	})
-}
-
-func TestUpdateSong(t *testing.T) {
-	r, db, cleanup := setupTest(t)
-	defer cleanup()
 
-	// Подготовка данных
-	var songID int
-	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING id`,
-		"Muse", "Supermassive Black Hole", "16.07.2006", "Verse 1", "https://example.com").Scan(&songID)
-	assert.NoError(t, err)
+	t.Run("Page Bounds", func(t *testing.T) {
+		// The first read indexes the verses, the second reads them by the index
+		for i := 0; i < 2; i++ {
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/songs/%d/verses?page=2&limit=2", songID), nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var verses service.VersePage
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &verses))
+			assert.Equal(t, 3, verses.TotalVerses)
+			assert.Equal(t, 2, verses.TotalPages)
+			if assert.Len(t, verses.Verses, 1) {
+				assert.Equal(t, 3, verses.Verses[0].Number)
+				assert.Equal(t, "Verse 3", verses.Verses[0].Text)
+			}
+		}
 
-	t.Run("Successful UpdateSong", func(t *testing.T) {
-		reqBody := UpdateSongRequest{
-			Group:       "Muse",
-			Song:        "New Song",
-			ReleaseDate: "01.01.2007",
-			Text:        "Updated Verse 1",
-			Link:        "https://newlink.com",
+		for _, page := range []string{"3", "9223372036854775807"} {
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/songs/%d/verses?page=%s&limit=2", songID, page), nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, "page %s", page)
 		}
-		bodyBytes, _ := json.Marshal(reqBody)
-		req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("/songs/%d", songID), bytes.NewBuffer(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
+	})
 
+	t.Run("Edited Text", func(t *testing.T) {
+		_, err := db.Exec("UPDATE songs SET text = $2 WHERE id = $1", songID, "Ünï\r\n\r\nVerse B")
+		assert.NoError(t, err)
+
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/songs/%d/verses", songID), nil)
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		var resp map[string]string
-		err := json.Unmarshal(w.Body.Bytes(), &resp)
-		assert.NoError(t, err)
-		assert.Equal(t, "Song updated successfully", resp["message"]) // Updated to match handler.go
-
-		// Проверка обновления в БД
-		var song models.Song
-		err = db.Get(&song, "SELECT * FROM songs WHERE id=$1", songID)
-		assert.NoError(t, err)
-		assert.Equal(t, "New Song", song.Song)
+		var verses service.VersePage
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &verses))
+		assert.Equal(t, 2, verses.TotalVerses, "an index of the old text is not used")
+		if assert.Len(t, verses.Verses, 2) {
+			assert.Equal(t, "Ünï", verses.Verses[0].Text)
+			assert.Equal(t, "Verse B", verses.Verses[1].Text)
+		}
 	})
 
 	t.Run("Song Not Found", func(t *testing.T) {
-		reqBody := UpdateSongRequest{Group: "Muse", Song: "New Song"}
-		bodyBytes, _ := json.Marshal(reqBody)
-		req, _ := http.NewRequest(http.MethodPut, "/songs/999", bytes.NewBuffer(bodyBytes))
-		req.Header.Set("Content-Type", "application/json")
-
+		req, _ := http.NewRequest(http.MethodGet, "/songs/999/verses", nil)
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
@@ -252,152 +648,2182 @@ func TestUpdateSong(t *testing.T) {
 	})
 }
 
-func TestDeleteSong(t *testing.T) {
+func TestGetSubtitles(t *testing.T) {
 	r, db, cleanup := setupTest(t)
 	defer cleanup()
 
-	// Подготовка данных
-	var songID int
+	var songID, plainID int
 	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING id`,
-		"Muse", "Supermassive Black Hole", "16.07.2006", "Verse 1", "https://example.com").Scan(&songID)
+		"Muse", "Supermassive Black Hole", "16.07.2006", "[00:12.34]Ooh baby\n[00:15.00]Can you hear me moan?", "https://example.com").Scan(&songID)
+	assert.NoError(t, err)
+	err = db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING id`,
+		"Muse", "Uprising", "07.09.2009", "Verse 1", "https://example.com").Scan(&plainID)
 	assert.NoError(t, err)
 
-	t.Run("Successful DeleteSong", func(t *testing.T) {
-		req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/songs/%d", songID), nil)
+	t.Run("SRT", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/songs/%d/subtitles", songID), nil)
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		var resp map[string]string
-		err := json.Unmarshal(w.Body.Bytes(), &resp)
-		assert.NoError(t, err)
-		assert.Equal(t, "Song deleted successfully", resp["message"]) // Updated to match handler.go
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/x-subrip")
+		assert.Equal(t, "1\n00:00:12,340 --> 00:00:15,000\nOoh baby\n\n2\n00:00:15,000 --> 00:00:20,000\nCan you hear me moan?\n\n", w.Body.String())
+	})
 
-		// Проверка удаления из БД
-		var count int
-		err = db.Get(&count, "SELECT COUNT(*) FROM songs WHERE id=$1", songID)
-		assert.NoError(t, err)
-		assert.Equal(t, 0, count)
+	t.Run("VTT", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/songs/%d/subtitles?format=vtt", songID), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/vtt")
+		assert.Contains(t, w.Body.String(), "WEBVTT\n\n00:00:12.340 --> 00:00:15.000\n")
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/songs/%d/subtitles?format=ass", songID), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("No Timestamps", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/songs/%d/subtitles", plainID), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
 	})
 
 	t.Run("Song Not Found", func(t *testing.T) {
-		req, _ := http.NewRequest(http.MethodDelete, "/songs/999", nil)
+		req, _ := http.NewRequest(http.MethodGet, "/songs/999/subtitles", nil)
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
-		var resp ErrorResponse
-		err := json.Unmarshal(w.Body.Bytes(), &resp)
-		assert.NoError(t, err)
-		assert.Equal(t, "Song not found", resp.Error)
 	})
 }
 
-func TestTruncateSongs(t *testing.T) {
+func TestGetEnrichmentStatus(t *testing.T) {
 	r, db, cleanup := setupTest(t)
 	defer cleanup()
 
-	// Подготовка данных
-	_, err := db.Exec(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())`,
-		"Muse", "Supermassive Black Hole", "16.07.2006", "Verse 1", "https://example.com")
+	var songID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, enrichment_status, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW()) RETURNING id`,
+		"Muse", "Uprising", models.EnrichmentPending).Scan(&songID)
 	assert.NoError(t, err)
 
-	t.Run("Successful TruncateSongs", func(t *testing.T) {
-		req, _ := http.NewRequest(http.MethodPost, "/songs/truncate", nil)
+	t.Run("Pending", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/songs/%d/enrichment-status", songID), nil)
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		var resp map[string]string
+		var resp models.EnrichmentStatus
 		err := json.Unmarshal(w.Body.Bytes(), &resp)
 		assert.NoError(t, err)
-		assert.Equal(t, "Table truncated and sequence reset", resp["message"]) // Updated to match handler.go
+		assert.Equal(t, songID, resp.SongID)
+		assert.Equal(t, models.EnrichmentPending, resp.Status)
+		assert.Nil(t, resp.EnrichedAt)
+	})
 
-		// Проверка очистки таблицы
-		var count int
-		err = db.Get(&count, "SELECT COUNT(*) FROM songs")
-		assert.NoError(t, err)
-		assert.Equal(t, 0, count)
+	t.Run("Song Not Found", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs/999/enrichment-status", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 }
 
-func TestFullWorkflow(t *testing.T) {
+func TestReenrichSong(t *testing.T) {
 	r, db, cleanup := setupTest(t)
 	defer cleanup()
 
-	// 1. Добавление песни
-	reqBody := AddSongRequest{Group: "Muse", Song: "Supermassive Black Hole"}
+	var songID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING id`,
+		"Muse", "Uprising", "07.09.2009", "Verse 1", "https://example.com").Scan(&songID)
+	assert.NoError(t, err)
+
+	t.Run("Invalid Fields", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/songs/%d/enrich?fields=text,group", songID), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("External API Unavailable", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/songs/%d/enrich?fields=text", songID), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+		var song models.Song
+		err := db.Get(&song, selectSongByID, songID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Verse 1", models.StringValue(song.Text), "the stored text is kept")
+	})
+
+	t.Run("Song Not Found", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/songs/999/enrich", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestEnrichAll(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	_, err := db.Exec(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())`,
+		"Muse", "Uprising", "01.01.2000", service.DefaultFallbackConfig.Text, "https://example.com")
+	assert.NoError(t, err)
+
+	request := func(method, target string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodPost, "/admin/enrich-all?missing=group")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request(http.MethodPost, "/admin/enrich-all")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var job models.Job
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, models.JobKindEnrichAll, job.Kind)
+
+	assert.Eventually(t, func() bool {
+		w := request(http.MethodGet, "/admin/jobs/"+job.ID)
+		return json.Unmarshal(w.Body.Bytes(), &job) == nil && job.Status == models.JobCompleted
+	}, 30*time.Second, 100*time.Millisecond)
+	assert.Equal(t, 1, job.Total, "only the placeholder song is selected")
+	assert.Equal(t, 1, job.Processed)
+	assert.Equal(t, 1, job.Failed, "the external API is unreachable in tests")
+}
+
+func TestGetJob(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	tokens := make(map[string]string)
+	var ownerID int
+	for _, name := range []string{"alice", "bob"} {
+		var userID int
+		err := db.QueryRow(`INSERT INTO users (username, password_hash, role) VALUES ($1, 'hash', 'editor') RETURNING id`, name).Scan(&userID)
+		assert.NoError(t, err)
+		pair, err := testTokens.Issue(userID, name, models.RoleEditor)
+		assert.NoError(t, err)
+		tokens[name] = pair.AccessToken
+		if name == "alice" {
+			ownerID = userID
+		}
+	}
+	_, err := db.Exec(`INSERT INTO jobs (id, kind, owner_id) VALUES ('alices', 'import', $1)`, ownerID)
+	assert.NoError(t, err)
+
+	request := func(target, header, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set(header, token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		w := request("/jobs/missing", "Authorization", "Bearer "+tokens["alice"])
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Owner", func(t *testing.T) {
+		w := request("/jobs/alices", "Authorization", "Bearer "+tokens["alice"])
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), fmt.Sprintf(`"owner_id":%d`, ownerID))
+	})
+
+	t.Run("Other User", func(t *testing.T) {
+		w := request("/jobs/alices", "Authorization", "Bearer "+tokens["bob"])
+		assert.Equal(t, http.StatusNotFound, w.Code, "jobs of other users are not disclosed")
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		w := request("/admin/jobs/alices", "X-Admin-Token", testAdminToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestUpdateSong(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	// Подготовка данных
+	var songID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING id`,
+		"Muse", "Supermassive Black Hole", "16.07.2006", "Verse 1", "https://example.com").Scan(&songID)
+	assert.NoError(t, err)
+
+	t.Run("Successful UpdateSong", func(t *testing.T) {
+		reqBody := UpdateSongRequest{
+			Group:       "Muse",
+			Song:        "New Song",
+			ReleaseDate: "01.01.2007",
+			Text:        "Updated Verse 1",
+			Link:        "https://newlink.com",
+		}
+		bodyBytes, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("/songs/%d", songID), bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		assert.Equal(t, "Song updated successfully", resp["message"]) // Updated to match handler.go
+
+		// Проверка обновления в БД
+		var song models.Song
+		err = db.Get(&song, selectSongByID, songID)
+		assert.NoError(t, err)
+		assert.Equal(t, "New Song", song.Song)
+	})
+
+	t.Run("Release Date Format", func(t *testing.T) {
+		put := func(path, releaseDate string) *httptest.ResponseRecorder {
+			bodyBytes, _ := json.Marshal(UpdateSongRequest{Group: "Muse", Song: "New Song", ReleaseDate: releaseDate})
+			req, _ := http.NewRequest(http.MethodPut, path, bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w
+		}
+
+		w := put(fmt.Sprintf("/songs/%d", songID), "2006-07-16")
+		assert.Equal(t, http.StatusBadRequest, w.Code, "ISO dates are rejected unless requested")
+
+		w = put(fmt.Sprintf("/songs/%d?date_format=iso", songID), "2006-07-16")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var releaseDate string
+		err := db.Get(&releaseDate, "SELECT release_date FROM songs WHERE id=$1", songID)
+		assert.NoError(t, err)
+		assert.Equal(t, "16.07.2006", releaseDate, "dates are stored as DD.MM.YYYY")
+
+		w = put(fmt.Sprintf("/songs/%d?date_format=us", songID), "07/16/2006")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Song Not Found", func(t *testing.T) {
+		reqBody := UpdateSongRequest{Group: "Muse", Song: "New Song"}
+		bodyBytes, _ := json.Marshal(reqBody)
+		req, _ := http.NewRequest(http.MethodPut, "/songs/999", bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var resp ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		assert.Equal(t, "Song not found", resp.Error)
+	})
+}
+
+func TestPatchSong(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var songID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING id`,
+		"Muse", "Supermassive Black Hole", "16.07.2006", "Verse 1", "https://example.com").Scan(&songID)
+	assert.NoError(t, err)
+
+	patch := func(id int, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("/songs/%d", id), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Only Provided Fields", func(t *testing.T) {
+		w := patch(songID, `{"song": "Uprising", "link": null, "text": ""}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var song struct {
+			Song        string         `db:"song_name"`
+			ReleaseDate string         `db:"release_date"`
+			Text        sql.NullString `db:"text"`
+			Link        sql.NullString `db:"link"`
+		}
+		err := db.Get(&song, "SELECT song_name, release_date, text, link FROM songs WHERE id=$1", songID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Uprising", song.Song)
+		assert.Equal(t, "16.07.2006", song.ReleaseDate)
+		assert.Equal(t, sql.NullString{String: "", Valid: true}, song.Text)
+		assert.False(t, song.Link.Valid)
+	})
+
+	t.Run("Required Field Cleared", func(t *testing.T) {
+		w := patch(songID, `{"group": null}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Release Date Unknown", func(t *testing.T) {
+		w := patch(songID, `{"release_date": null}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = patch(songID, `{"release_date": ""}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Song Not Found", func(t *testing.T) {
+		w := patch(999, `{"song": "Uprising"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestDeleteSong(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	// Подготовка данных
+	var songID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING id`,
+		"Muse", "Supermassive Black Hole", "16.07.2006", "Verse 1", "https://example.com").Scan(&songID)
+	assert.NoError(t, err)
+
+	t.Run("Successful DeleteSong", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/songs/%d", songID), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		assert.Equal(t, "Song deleted successfully", resp["message"]) // Updated to match handler.go
+
+		// Проверка удаления из БД
+		var count int
+		err = db.Get(&count, "SELECT COUNT(*) FROM songs WHERE id=$1", songID)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("Song Not Found", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodDelete, "/songs/999", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var resp ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		assert.Equal(t, "Song not found", resp.Error)
+	})
+}
+
+func TestTruncateSongs(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	// Подготовка данных
+	_, err := db.Exec(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())`,
+		"Muse", "Supermassive Black Hole", "16.07.2006", "Verse 1", "https://example.com")
+	assert.NoError(t, err)
+
+	t.Run("Successful TruncateSongs", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/songs/truncate", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		assert.Equal(t, "Table truncated and sequence reset", resp["message"]) // Updated to match handler.go
+
+		// Проверка очистки таблицы
+		var count int
+		err = db.Get(&count, "SELECT COUNT(*) FROM songs")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("TruncateSongs with backup and restore", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO songs (group_name, song_name, release_date, text, link)
+			VALUES ('Muse', 'Hysteria', '01.12.2003', 'Verse 1', 'https://example.com')`)
+		assert.NoError(t, err)
+		var songID int
+		assert.NoError(t, db.Get(&songID, "SELECT id FROM songs"))
+
+		req, _ := http.NewRequest(http.MethodPost, "/songs/truncate?backup=true", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			SnapshotID int `json:"snapshot_id"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.NotZero(t, resp.SnapshotID)
+
+		restore := func() *httptest.ResponseRecorder {
+			req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/admin/snapshots/%d/restore", resp.SnapshotID), nil)
+			req.Header.Set("Authorization", "Bearer "+testAdminToken)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w
+		}
+		w = restore()
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"message": "Snapshot restored successfully", "restored": 1}`, w.Body.String())
+		var restoredID int
+		assert.NoError(t, db.Get(&restoredID, "SELECT id FROM songs WHERE song_name = 'Hysteria'"))
+		assert.Equal(t, songID, restoredID, "restored songs keep their IDs")
+		assert.Equal(t, http.StatusConflict, restore().Code, "a snapshot is only restored into an empty catalog")
+
+		req, _ = http.NewRequest(http.MethodPost, "/songs/truncate?backup=maybe", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestClassificationSuggestions(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var songID, suggestionID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING id`,
+		"Muse", "Supermassive Black Hole", "16.07.2006", "Verse 1", "https://example.com").Scan(&songID)
+	assert.NoError(t, err)
+	err = db.QueryRow(`INSERT INTO classification_suggestions (song_id, kind, value, confidence, source)
+		VALUES ($1, 'mood', 'romantic', 0.5, 'wordlist') RETURNING id`, songID).Scan(&suggestionID)
+	assert.NoError(t, err)
+
+	t.Run("List Pending", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/admin/classifications", nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var suggestions []models.ClassificationSuggestion
+		err := json.Unmarshal(w.Body.Bytes(), &suggestions)
+		assert.NoError(t, err)
+		if assert.Len(t, suggestions, 1) {
+			assert.Equal(t, "Muse", suggestions[0].Group)
+			assert.Equal(t, "romantic", suggestions[0].Value)
+		}
+	})
+
+	t.Run("Accept", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/admin/classifications/%d/accept", suggestionID), nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var status string
+		err := db.Get(&status, "SELECT status FROM classification_suggestions WHERE id = $1", suggestionID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.SuggestionAccepted, status)
+	})
+
+	t.Run("Already Reviewed", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/admin/classifications/%d/reject", suggestionID), nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invalid Status", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/admin/classifications?status=maybe", nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestQueryLog(t *testing.T) {
+	r, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	req, _ := http.NewRequest(http.MethodGet, "/songs?group=Muse", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/admin/query-log", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Successful QueryLog", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/admin/query-log", nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var entries []repository.QueryLogEntry
+		err := json.Unmarshal(w.Body.Bytes(), &entries)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, entries)
+		assert.Contains(t, entries[0].Query, "FROM songs")
+		assert.NotContains(t, entries[0].Query, "Muse")
+	})
+}
+
+func TestConfigExportImport(t *testing.T) {
+	r, _, cleanup := setupTest(t)
+	defer cleanup()
+	t.Setenv("TRENDING_INTERVAL", "5m")
+	t.Setenv("JWT_SECRET", "test-jwt-secret")
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/config/export", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "test-jwt-secret")
+	var document config.Document
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, "5m", document.Schedulers["TRENDING_INTERVAL"])
+	assert.Equal(t, config.Redacted, document.Credentials["JWT_SECRET"])
+
+	importDocument := func(document config.Document) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(document)
+		req, _ := http.NewRequest(http.MethodPost, "/admin/config/import", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	w = importDocument(document)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"changes": [], "restart_required": false}`, w.Body.String(), "the instance matches its own export")
+
+	document.Schedulers["TRENDING_INTERVAL"] = "10m"
+	w = importDocument(document)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"action":"update"`)
+
+	document.Version = 99
+	assert.Equal(t, http.StatusBadRequest, importDocument(document).Code)
+}
+
+func TestReloadConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler(nil, zap.NewNop())
+	r := gin.New()
+	r.GET("/admin/config/live", handler.GetLiveConfig)
+	r.POST("/admin/config/reload", handler.ReloadConfig)
+	request := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, request(http.MethodPost, "/admin/config/reload").Code)
+
+	environ := map[string]string{"RATE_LIMIT_BURST": "40"}
+	burst := 0
+	reloader := config.NewReloader(func() (map[string]string, error) { return environ, nil })
+	reloader.Register("rate limit", func(snapshot config.Snapshot) (func(), error) {
+		value, err := snapshot.Int("RATE_LIMIT_BURST", 10)
+		return func() { burst = value }, err
+	})
+	handler.ConfigureReloader(reloader)
+
+	environ["RATE_LIMIT_BURST"] = "80"
+	w := request(http.MethodPost, "/admin/config/reload")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var result config.ReloadResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 80, burst)
+	assert.Equal(t, 1, result.Snapshot.Version)
+	if assert.Len(t, result.Changes, 1) {
+		assert.Equal(t, "RATE_LIMIT_BURST", result.Changes[0].Name)
+	}
+
+	environ["RATE_LIMIT_BURST"] = "lots"
+	w = request(http.MethodPost, "/admin/config/reload")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "RATE_LIMIT_BURST")
+	assert.Equal(t, 80, burst, "a rejected reload changes nothing")
+
+	w = request(http.MethodGet, "/admin/config/live")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var snapshot config.Snapshot
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &snapshot))
+	assert.Equal(t, "80", snapshot.Values["RATE_LIMIT_BURST"])
+}
+
+func TestLegalHold(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var songID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		"Muse", "Uprising", "2009-09-07", "Paranoia is in bloom", "https://example.com").Scan(&songID)
+	assert.NoError(t, err)
+
+	request := func(method, path, body string, admin bool) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if admin {
+			req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	holdPath := fmt.Sprintf("/admin/songs/%d/legal-hold", songID)
+	songPath := fmt.Sprintf("/songs/%d", songID)
+
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodPut, holdPath, `{"legal_hold": true}`, false).Code)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPut, holdPath, `{}`, true).Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPut, "/admin/songs/999999/legal-hold", `{"legal_hold": true}`, true).Code)
+	w := request(http.MethodPut, holdPath, `{"legal_hold": true}`, true)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"id": %d, "legal_hold": true}`, songID), w.Body.String())
+
+	assert.Equal(t, http.StatusLocked, request(http.MethodPut, songPath, `{"group": "Muse", "song": "Uprising!"}`, false).Code)
+	assert.Equal(t, http.StatusLocked, request(http.MethodPatch, songPath, `{"song": "Uprising!"}`, false).Code)
+	assert.Equal(t, http.StatusLocked, request(http.MethodDelete, songPath, "", false).Code)
+	assert.Equal(t, http.StatusLocked, request(http.MethodPost, "/songs/truncate", "", false).Code)
+	var song models.Song
+	assert.NoError(t, db.Get(&song, "SELECT id, song_name, legal_hold FROM songs WHERE id = $1", songID))
+	assert.Equal(t, "Uprising", song.Song, "a held song is left unchanged")
+	assert.True(t, song.LegalHold)
+
+	assert.Equal(t, http.StatusOK, request(http.MethodPut, holdPath, `{"legal_hold": false}`, true).Code)
+	assert.Equal(t, http.StatusOK, request(http.MethodPatch, songPath, `{"song": "Uprising!"}`, false).Code)
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, songPath, "", false).Code)
+}
+
+func TestWebhooks(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodPost, "/webhooks", `{"url": "https://example.com/hooks", "events": ["song.created", "song.deleted"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var webhook models.Webhook
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &webhook))
+	assert.Len(t, webhook.Secret, 64, "a secret is generated and shown once")
+	assert.Equal(t, []string{"song.created", "song.deleted"}, []string(webhook.Events))
+
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/webhooks", `{"url": "ftp://example.com", "events": ["song.created"]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/webhooks", `{"url": "https://example.com", "events": ["song.played"]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/webhooks", `{"url": "https://example.com", "events": []}`).Code)
+
+	w = request(http.MethodGet, "/webhooks", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), webhook.Secret)
+	assert.Contains(t, w.Body.String(), "https://example.com/hooks")
+
+	_, err := db.Exec(`INSERT INTO webhook_deliveries (webhook_id, event_type, payload, status, attempts, error)
+		VALUES ($1, 'song.deleted', '{"type": "song.deleted"}', 'failed', 8, 'unexpected response status 500')`, webhook.ID)
+	assert.NoError(t, err)
+	deliveriesPath := fmt.Sprintf("/webhooks/%d/deliveries", webhook.ID)
+	w = request(http.MethodGet, deliveriesPath+"?status=failed", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var deliveries []models.WebhookDelivery
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &deliveries))
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, models.DeliveryFailed, deliveries[0].Status)
+		assert.JSONEq(t, `{"type": "song.deleted"}`, string(deliveries[0].Payload))
+	}
+	w = request(http.MethodGet, deliveriesPath+"?status=delivered", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodGet, deliveriesPath+"?status=lost", "").Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/webhooks/999999/deliveries", "").Code)
+
+	retryPath := fmt.Sprintf("%s/%d/retry", deliveriesPath, deliveries[0].ID)
+	w = request(http.MethodPost, retryPath, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var retried models.WebhookDelivery
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &retried))
+	assert.Equal(t, models.DeliveryPending, retried.Status)
+	assert.Equal(t, 0, retried.Attempts)
+	assert.Equal(t, http.StatusConflict, request(http.MethodPost, retryPath, "").Code, "a pending delivery is already queued")
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPost, deliveriesPath+"/999999/retry", "").Code)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, deliveriesPath+"/abc/retry", "").Code)
+
+	rotatePath := fmt.Sprintf("/webhooks/%d/secret/rotate", webhook.ID)
+	w = request(http.MethodPost, rotatePath, `{"secret": "rotated-secret"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var rotated models.Webhook
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+	assert.Equal(t, "rotated-secret", rotated.Secret)
+	assert.NotNil(t, rotated.SecretRotatedAt)
+	assert.NotContains(t, w.Body.String(), webhook.Secret, "the previous secret is not shown")
+	w = request(http.MethodPost, rotatePath, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+	assert.Len(t, rotated.Secret, 64, "a secret is generated when none is given")
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPost, "/webhooks/999999/secret/rotate", "").Code)
+
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, fmt.Sprintf("/webhooks/%d", webhook.ID), "").Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, fmt.Sprintf("/webhooks/%d", webhook.ID), "").Code)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodDelete, "/webhooks/abc", "").Code)
+}
+
+func TestAPICaptures(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	_, err := db.Exec(`INSERT INTO api_captures (provider, method, url, request_headers, status, response_body, duration_ms)
+		VALUES ('external_api', 'GET', 'https://api.example.com/info?group=Muse', '{}', 200, '{}', 12.5),
+		('lyrics', 'GET', 'https://lyrics.example.com/search', '{}', 404, '{}', 8)`)
+	assert.NoError(t, err)
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/admin/api-captures", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Successful APICaptures", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/admin/api-captures?provider=lyrics", nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var captures []models.APICapture
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &captures))
+		if assert.Len(t, captures, 1) {
+			assert.Equal(t, "lyrics", captures[0].Provider)
+			if assert.NotNil(t, captures[0].Status) {
+				assert.Equal(t, http.StatusNotFound, *captures[0].Status)
+			}
+		}
+	})
+
+	t.Run("Invalid Limit", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/admin/api-captures?limit=0", nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestLatestDigest(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	// Подготовка данных
+	_, err := db.Exec(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())`,
+		"Muse", "Supermassive Black Hole", "16.07.2006", "Verse 1", "https://example.com")
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, "/digests/latest", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var digest models.Digest
+	err = json.Unmarshal(w.Body.Bytes(), &digest)
+	assert.NoError(t, err)
+	assert.Len(t, digest.NewSongs, 1)
+	assert.Empty(t, digest.UpdatedSongs)
+}
+
+// importRequest builds a multipart import request for the CSV content and extra form fields
+func importRequest(path, csvContent string, fields map[string]string) *http.Request {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "songs.csv")
+	part.Write([]byte(csvContent))
+	for name, value := range fields {
+		form.WriteField(name, value)
+	}
+	form.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestImportSongs(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	file := "group,song,release_date,text,link\n" +
+		"Muse,Uprising,07.09.2009,Verse 1,https://example.com\n" +
+		",Missing group,,,\n" +
+		"Muse,Starlight,04.09.2006,Verse 1,https://example.com\n"
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, importRequest("/songs/import", file, nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var result service.ImportResult
+	err := json.Unmarshal(w.Body.Bytes(), &result)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImportStatusCompleted, result.Status)
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, []service.ImportItemResult{{Row: 3, Error: "group and song are required"}}, result.Failures)
+
+	t.Run("Resume Completed Import", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, importRequest("/songs/import", file, map[string]string{"import_id": result.ImportID}))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resumed service.ImportResult
+		err := json.Unmarshal(w.Body.Bytes(), &resumed)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, resumed.ResumedFromRow)
+		assert.Equal(t, 2, resumed.Created)
+
+		var count int
+		err = db.Get(&count, "SELECT COUNT(*) FROM songs")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("Unknown Import", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, importRequest("/songs/import", file, map[string]string{"import_id": "missing"}))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Async Import", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, importRequest("/songs/import", "group,song,release_date,text,link\n"+
+			"Muse,Hysteria,01.12.2003,Verse 1,https://example.com\n", map[string]string{"async": "true"}))
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		var job models.Job
+		err := json.Unmarshal(w.Body.Bytes(), &job)
+		assert.NoError(t, err)
+		assert.Equal(t, models.JobKindImport, job.Kind)
+
+		assert.Eventually(t, func() bool {
+			// Imported without a user, the job is only visible to admins
+			req, _ := http.NewRequest(http.MethodGet, "/admin/jobs/"+job.ID, nil)
+			req.Header.Set("X-Admin-Token", testAdminToken)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return json.Unmarshal(w.Body.Bytes(), &job) == nil && job.Status == models.JobCompleted
+		}, 30*time.Second, 100*time.Millisecond)
+		assert.Equal(t, 1, job.Processed)
+		var imported service.ImportResult
+		if assert.NotNil(t, job.Result) {
+			assert.NoError(t, json.Unmarshal(*job.Result, &imported))
+		}
+		assert.Equal(t, 1, imported.Created)
+	})
+}
+
+func TestPreviewImport(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	// Подготовка данных
+	_, err := db.Exec(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())`,
+		"Muse", "Supermassive Black Hole", "16.07.2006", "Verse 1", "https://example.com")
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, importRequest("/songs/import/preview",
+		"Track,Lyrics\nMuse - Supermassive Black Hole,Updated\nMuse - Uprising,\nbroken row,\n",
+		map[string]string{"mapping": `{"columns": {"Lyrics": "text"}, "split_artist": {"column": "Track"}}`}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var preview service.ImportPreview
+	err = json.Unmarshal(w.Body.Bytes(), &preview)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, preview.Create)
+	assert.Equal(t, 1, preview.Update)
+	assert.Equal(t, 1, preview.Skip)
+
+	// Предпросмотр ничего не записывает
+	var count int
+	err = db.Get(&count, "SELECT COUNT(*) FROM songs")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestGetTrendingSongs(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	// Подготовка данных
+	var songID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING id`,
+		"Muse", "Supermassive Black Hole", "16.07.2006", "Verse 1", "https://example.com").Scan(&songID)
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO song_trending (song_id, score) VALUES ($1, $2)", songID, 4.2)
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, "/songs/trending?limit=5", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var songs []models.TrendingSong
+	err = json.Unmarshal(w.Body.Bytes(), &songs)
+	assert.NoError(t, err)
+	assert.Len(t, songs, 1)
+	assert.Equal(t, songID, songs[0].ID)
+	assert.Equal(t, 4.2, songs[0].Score)
+}
+
+func TestSearchSongs(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	_, err := db.Exec(`INSERT INTO songs (group_name, song_name, release_date, text, link) VALUES
+		('Muse', 'Supermassive Black Hole', '16.07.2006', 'Ooh baby, don''t you know I suffer?', 'https://example.com'),
+		('Queen', 'Bohemian Rhapsody', '31.10.1975', 'Is this the real life? Is this just fantasy?', 'https://example.com')`)
+	assert.NoError(t, err)
+
+	t.Run("Keyword", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs/search?q=fantasy", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var page models.SearchPage
+		err := json.Unmarshal(w.Body.Bytes(), &page)
+		assert.NoError(t, err)
+		if assert.Len(t, page.Data, 1) {
+			assert.Equal(t, "Bohemian Rhapsody", page.Data[0].Song.Song)
+			assert.Contains(t, page.Data[0].Snippet, "<mark>fantasy</mark>")
+			assert.Greater(t, page.Data[0].Score, 0.0)
+		}
+		assert.Empty(t, page.Suggestions)
+	})
+
+	t.Run("Legacy Shape", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs/search?q=fantasy", nil)
+		req.Header.Set(CompatHeader, models.APICompatLegacy)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var results []models.SearchResult
+		err := json.Unmarshal(w.Body.Bytes(), &results)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("Suggestions", func(t *testing.T) {
+		_, err := db.Exec("REFRESH MATERIALIZED VIEW search_terms")
+		if err != nil {
+			t.Skip("pg_trgm is not installed")
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/songs/search?q=Bohemain+Rapsody", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var page models.SearchPage
+		err = json.Unmarshal(w.Body.Bytes(), &page)
+		assert.NoError(t, err)
+		assert.Empty(t, page.Data)
+		if assert.NotEmpty(t, page.Suggestions) {
+			assert.Equal(t, "Bohemian Rhapsody", page.Suggestions[0].Query)
+			assert.Equal(t, models.SuggestionSong, page.Suggestions[0].Source)
+		}
+	})
+
+	t.Run("Missing Query", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs/search", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Invalid Mode", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs/search?q=love&mode=psychic", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Semantic Without Provider", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs/search?q=songs+about+heartbreak&mode=semantic", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
+
+func TestExportSongs(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	_, err := db.Exec(`INSERT INTO songs (group_name, song_name, release_date, text, link) VALUES
+		('Muse', 'Uprising', '07.09.2009', 'Paranoia is in bloom,
+The PR transmissions will resume', 'https://example.com'),
+		('Queen', 'Bohemian Rhapsody', '31.10.1975', 'Is this the real life? "Is this just fantasy?"', 'https://example.com')`)
+	assert.NoError(t, err)
+
+	t.Run("CSV", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs/export?format=csv&group=Queen&date_format=iso", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+		records, err := csv.NewReader(w.Body).ReadAll()
+		assert.NoError(t, err)
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, []string{"group", "song", "release_date", "text", "link"}, records[0][:5])
+			assert.Equal(t, []string{"Queen", "Bohemian Rhapsody", "1975-10-31", `Is this the real life? "Is this just fantasy?"`}, records[1][:4])
+			assert.NotContains(t, records[0], "notes", "staff fields are hidden from anonymous callers")
+		}
+	})
+
+	t.Run("Multiline Lyrics", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs/export", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		records, err := csv.NewReader(w.Body).ReadAll()
+		assert.NoError(t, err)
+		if assert.Len(t, records, 3) {
+			assert.Equal(t, "Paranoia is in bloom,\nThe PR transmissions will resume", records[1][3])
+		}
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/songs/export?format=xlsx", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestGetGroupStats(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var ids []int
+	err := db.Select(&ids, `INSERT INTO songs (group_name, song_name, release_date, text, link) VALUES
+		('Muse', 'Showbiz', '27.09.1999', 'Verse 1', 'https://example.com'),
+		('Muse', 'Uprising', '07.09.2009', 'Verse 1', 'https://example.com'),
+		('Muse', 'Unreleased', NULL, NULL, NULL),
+		('Queen', 'Bohemian Rhapsody', '31.10.1975', 'Verse 1', 'https://example.com')
+		RETURNING id`)
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO song_views (song_id, views) VALUES ($1, 3), ($2, 10)", ids[0], ids[1])
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, "/groups/muse/stats", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var stats models.GroupStats
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, "Muse", stats.Group)
+	assert.Equal(t, 3, stats.Songs)
+	assert.Equal(t, models.NullableString("27.09.1999"), stats.EarliestRelease)
+	assert.Equal(t, models.NullableString("07.09.2009"), stats.LatestRelease)
+	assert.Equal(t, int64(13), stats.TotalViews)
+	if assert.Len(t, stats.MostViewed, 3) {
+		assert.Equal(t, "Uprising", stats.MostViewed[0].Song)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/groups/Nobody/stats", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestReadiness(t *testing.T) {
+	r, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	testReadiness.SetDraining()
+	defer func() { testReadiness = &Readiness{} }()
+
+	req, _ = http.NewRequest(http.MethodGet, "/readyz", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/healthz", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMockRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterMockRoutes(r, zap.NewNop())
+
+	req, _ := http.NewRequest(http.MethodGet, "/songs?facets=year", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data []models.Song `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "Muse", resp.Data[0].Group)
+
+	req, _ = http.NewRequest(http.MethodGet, "/songs/7/verses", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestMockRoutesServeDocumentation requests every documented operation from the mock server, which must
+// answer with the documented success status
+func TestMockRoutesServeDocumentation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterMockRoutes(r, zap.NewNop())
+
+	var spec mockSpec
+	assert.NoError(t, json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &spec))
+	for path, operations := range spec.Paths {
+		// The WebSocket feed only answers upgrade requests
+		if path == "/ws/events" {
+			continue
+		}
+		target := path
+		for _, segment := range strings.Split(path, "/") {
+			if strings.HasPrefix(segment, "{") {
+				target = strings.Replace(target, segment, "1", 1)
+			}
+		}
+		for method, operation := range operations {
+			status, _, err := successResponse(operation)
+			assert.NoError(t, err, "%s %s", method, path)
+
+			req, _ := http.NewRequest(strings.ToUpper(method), target, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, status, w.Code, "%s %s", method, path)
+		}
+	}
+}
+
+func TestFullWorkflow(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	// 1. Добавление песни
+	reqBody := AddSongRequest{Group: "Muse", Song: "Supermassive Black Hole"}
 	bodyBytes, _ := json.Marshal(reqBody)
 	req, _ := http.NewRequest(http.MethodPost, "/songs", bytes.NewBuffer(bodyBytes))
 	req.Header.Set("Content-Type", "application/json")
 
-	w := httptest.NewRecorder()
-	r.ServeHTTP(w, req)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]int
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	songID := resp["id"]
+
+	// Проверка в БД
+	var song models.Song
+	err = db.Get(&song, selectSongByID, songID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Muse", song.Group)
+
+	// 2. Получение списка песен
+	req, _ = http.NewRequest(http.MethodGet, "/songs?group=Muse", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var songs models.SongPage
+	err = json.Unmarshal(w.Body.Bytes(), &songs)
+	assert.NoError(t, err)
+	assert.Len(t, songs.Data, 1)
+	assert.Equal(t, 1, songs.Total)
+
+	// 3. Получение куплетов
+	req, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/songs/%d/verses?page=1&limit=2", songID), nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var verses service.VersePage
+	err = json.Unmarshal(w.Body.Bytes(), &verses)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, len(verses.Verses), 1)
+	assert.Equal(t, songID, verses.SongID)
+
+	// 4. Обновление песни
+	updateBody := UpdateSongRequest{Group: "Muse", Song: "New Song", ReleaseDate: "01.01.2007"}
+	updateBytes, _ := json.Marshal(updateBody)
+	req, _ = http.NewRequest(http.MethodPut, fmt.Sprintf("/songs/%d", songID), bytes.NewBuffer(updateBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Проверка обновления в БД
+	err = db.Get(&song, selectSongByID, songID)
+	assert.NoError(t, err)
+	assert.Equal(t, "New Song", song.Song)
+
+	// 5. Удаление песни
+	req, _ = http.NewRequest(http.MethodDelete, fmt.Sprintf("/songs/%d", songID), nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Проверка удаления
+	var count int
+	err = db.Get(&count, "SELECT COUNT(*) FROM songs WHERE id=$1", songID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	// Проверка полного списка
+	req, _ = http.NewRequest(http.MethodGet, "/songs?group=Muse", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	err = json.Unmarshal(w.Body.Bytes(), &songs)
+	assert.NoError(t, err)
+	assert.Len(t, songs, 0)
+}
+
+func TestAuth(t *testing.T) {
+	r, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Register", func(t *testing.T) {
+		w := post("/auth/register", `{"username": "alice", "password": "correct horse"}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NotContains(t, w.Body.String(), "password")
+
+		w = post("/auth/register", `{"username": "alice", "password": "another horse"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = post("/auth/register", `{"username": "bob", "password": "short"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Login And Refresh", func(t *testing.T) {
+		w := post("/auth/login", `{"username": "alice", "password": "wrong horse"}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = post("/auth/login", `{"username": "alice", "password": "correct horse"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var pair auth.TokenPair
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &pair))
+
+		req, _ := http.NewRequest(http.MethodGet, "/auth/me", nil)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"username":"alice"`)
+
+		w = post("/auth/refresh", fmt.Sprintf(`{"refresh_token": %q}`, pair.AccessToken))
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "access tokens cannot be used as refresh tokens")
+
+		w = post("/auth/refresh", fmt.Sprintf(`{"refresh_token": %q}`, pair.RefreshToken))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Role Granted By Admin", func(t *testing.T) {
+		setRole := func(body string) int {
+			req, _ := http.NewRequest(http.MethodPut, "/admin/users/1/role", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Admin-Token", testAdminToken)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w.Code
+		}
+		assert.Equal(t, http.StatusBadRequest, setRole(`{"role": "superuser"}`))
+		assert.Equal(t, http.StatusOK, setRole(`{"role": "editor"}`))
+
+		w := post("/auth/login", `{"username": "alice", "password": "correct horse"}`)
+		var pair auth.TokenPair
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &pair))
+		req, _ := http.NewRequest(http.MethodGet, "/auth/me", nil)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Contains(t, w.Body.String(), `"role":"editor"`)
+	})
+}
+
+func TestPreferences(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var userID int
+	err := db.QueryRow(`INSERT INTO users (username, password_hash) VALUES ('alice', 'hash') RETURNING id`).Scan(&userID)
+	assert.NoError(t, err)
+	pair, err := testTokens.Issue(userID, "alice", models.RoleViewer)
+	assert.NoError(t, err)
+
+	request := func(method, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/me/preferences", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		w := request(http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var preferences models.Preferences
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &preferences))
+		assert.Zero(t, preferences.PageSize)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		w := request(http.MethodPut, `{"page_size": 25, "sort": "views", "language": "pt-BR", "explicit_filter": true}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = request(http.MethodGet, "")
+		var preferences models.Preferences
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &preferences))
+		assert.Equal(t, 25, preferences.PageSize)
+		assert.Equal(t, "views", preferences.Sort)
+		assert.True(t, preferences.ExplicitFilter)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPut, `{"page_size": 1000}`).Code)
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPut, `{"sort": "random"}`).Code)
+	})
+}
+
+func TestSongRatings(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var ids []int
+	tokens := make([]string, 2)
+	for i, name := range []string{"alice", "bob"} {
+		var userID int
+		err := db.QueryRow(`INSERT INTO users (username, password_hash) VALUES ($1, 'hash') RETURNING id`, name).Scan(&userID)
+		assert.NoError(t, err)
+		pair, err := testTokens.Issue(userID, name, models.RoleViewer)
+		assert.NoError(t, err)
+		tokens[i] = pair.AccessToken
+	}
+	for _, title := range []string{"Uprising", "Madness", "Resistance"} {
+		var id int
+		err := db.QueryRow(`INSERT INTO songs (group_name, song_name) VALUES ('Muse', $1) RETURNING id`, title).Scan(&id)
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	request := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	songs := func(token, query string) []models.Song {
+		w := request(token, http.MethodGet, "/songs"+query, "")
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var page models.SongPage
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		return page.Data
+	}
+
+	t.Run("Rate", func(t *testing.T) {
+		ratingPath := fmt.Sprintf("/songs/%d/rating", ids[0])
+		assert.Equal(t, http.StatusBadRequest, request(tokens[0], http.MethodPost, ratingPath, `{"rating": 6}`).Code)
+		assert.Equal(t, http.StatusNotFound, request(tokens[0], http.MethodPost, "/songs/999999/rating", `{"rating": 3}`).Code)
+		assert.Equal(t, http.StatusUnauthorized, request("", http.MethodPost, ratingPath, `{"rating": 3}`).Code)
+
+		assert.Equal(t, http.StatusOK, request(tokens[0], http.MethodPost, ratingPath, `{"rating": 3}`).Code)
+		assert.Equal(t, http.StatusOK, request(tokens[0], http.MethodPost, ratingPath, `{"rating": 4}`).Code, "rating again replaces the rating")
+		w := request(tokens[1], http.MethodPost, ratingPath, `{"rating": 5}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var rating models.SongRating
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rating))
+		if assert.NotNil(t, rating.Rating) && assert.NotNil(t, rating.AverageRating) {
+			assert.Equal(t, 5, *rating.Rating)
+			assert.InDelta(t, 4.5, *rating.AverageRating, 0.001)
+		}
+		assert.Equal(t, 2, rating.RatingCount)
+		assert.Equal(t, http.StatusOK, request(tokens[0], http.MethodPost, fmt.Sprintf("/songs/%d/rating", ids[1]), `{"rating": 2}`).Code)
+
+		listed := songs("", "?min_rating=4")
+		if assert.Len(t, listed, 1) && assert.NotNil(t, listed[0].AverageRating) {
+			assert.Equal(t, ids[0], listed[0].ID)
+			assert.InDelta(t, 4.5, *listed[0].AverageRating, 0.001)
+			assert.Equal(t, 2, listed[0].RatingCount)
+		}
+		listed = songs("", "?sort=rating")
+		if assert.Len(t, listed, 3) {
+			assert.Equal(t, []int{ids[0], ids[1], ids[2]}, []int{listed[0].ID, listed[1].ID, listed[2].ID}, "unrated songs come last")
+			assert.Nil(t, listed[2].AverageRating)
+		}
+		assert.Equal(t, http.StatusBadRequest, request("", http.MethodGet, "/songs?min_rating=high", "").Code)
+	})
+
+	t.Run("Favorites", func(t *testing.T) {
+		favoritePath := fmt.Sprintf("/songs/%d/favorite", ids[2])
+		assert.Equal(t, http.StatusOK, request(tokens[0], http.MethodPost, favoritePath, "").Code)
+		assert.Equal(t, http.StatusOK, request(tokens[0], http.MethodPost, favoritePath, "").Code, "marking a favorite again changes nothing")
+		assert.Equal(t, http.StatusOK, request(tokens[0], http.MethodPost, fmt.Sprintf("/songs/%d/favorite", ids[0]), "").Code)
+
+		listed := songs(tokens[0], "?favorites=true")
+		assert.Len(t, listed, 2)
+		assert.Empty(t, songs(tokens[1], "?favorites=true"), "favorites are per user")
+		listed = songs(tokens[0], "?favorites=true&min_rating=4")
+		if assert.Len(t, listed, 1) {
+			assert.Equal(t, ids[0], listed[0].ID)
+		}
+		assert.Equal(t, http.StatusUnauthorized, request("", http.MethodGet, "/songs?favorites=true", "").Code)
+
+		assert.Equal(t, http.StatusOK, request(tokens[0], http.MethodDelete, favoritePath, "").Code)
+		assert.Equal(t, http.StatusNotFound, request(tokens[0], http.MethodDelete, favoritePath, "").Code)
+		assert.Len(t, songs(tokens[0], "?favorites=true"), 1)
+	})
+}
+
+func TestSongOverrides(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var userID, songID int
+	err := db.QueryRow(`INSERT INTO users (username, password_hash) VALUES ('alice', 'hash') RETURNING id`).Scan(&userID)
+	assert.NoError(t, err)
+	err = db.QueryRow(`INSERT INTO songs (group_name, song_name, text) VALUES ('Muse', 'Uprising', 'Shared verse one\n\nShared verse two') RETURNING id`).Scan(&songID)
+	assert.NoError(t, err)
+	pair, err := testTokens.Issue(userID, "alice", models.RoleViewer)
+	assert.NoError(t, err)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	overridePath := fmt.Sprintf("/me/overrides/%d", songID)
+
+	t.Run("Save", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, request(http.MethodGet, overridePath, "").Code)
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPut, overridePath, `{"text": "  "}`).Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodPut, "/me/overrides/999999", `{"text": "Mine"}`).Code)
+
+		w := request(http.MethodPut, overridePath, `{"text": "My verse one\n\nMy verse two\n\nMy verse three"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = request(http.MethodGet, overridePath, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var override models.SongOverride
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &override))
+		assert.Equal(t, songID, override.SongID)
+		assert.Contains(t, override.Text, "My verse three")
+	})
+
+	t.Run("Personal Reads", func(t *testing.T) {
+		var page models.SongPage
+		w := request(http.MethodGet, "/songs?personal=true", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		if assert.Len(t, page.Data, 1) {
+			assert.Contains(t, models.StringValue(page.Data[0].Text), "My verse one")
+		}
+		w = request(http.MethodGet, "/songs", "")
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		if assert.Len(t, page.Data, 1) {
+			assert.Contains(t, models.StringValue(page.Data[0].Text), "Shared verse one", "the shared record is unchanged")
+		}
+
+		var verses service.VersePage
+		w = request(http.MethodGet, fmt.Sprintf("/songs/%d/verses?personal=true", songID), "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &verses))
+		assert.Equal(t, 3, verses.TotalVerses)
+
+		req, _ := http.NewRequest(http.MethodGet, "/songs?personal=true", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "anonymous requests have no overrides")
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodGet, "/songs?personal=maybe", "").Code)
+	})
+
+	t.Run("Discard", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(http.MethodDelete, overridePath, "").Code)
+		assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, overridePath, "").Code)
+
+		var verses service.VersePage
+		w := request(http.MethodGet, fmt.Sprintf("/songs/%d/verses?personal=true", songID), "")
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &verses))
+		assert.Equal(t, 2, verses.TotalVerses, "without an override the shared verses are served")
+	})
+}
+
+func TestBulkTagSongs(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var ids [3]int
+	for i, title := range []string{"Uprising", "Resistance", "Hysteria"} {
+		err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link)
+			VALUES ('Muse', $1, '16.07.2006', 'Verse', 'https://example.com') RETURNING id`, title).Scan(&ids[i])
+		assert.NoError(t, err)
+	}
+	bulk := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/songs/tags/bulk", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	tagCount := func(tag string) int {
+		var count int
+		err := db.Get(&count, "SELECT COUNT(*) FROM song_tags st JOIN tags t ON t.id = st.tag_id WHERE t.name = $1", tag)
+		assert.NoError(t, err)
+		return count
+	}
+
+	t.Run("By IDs", func(t *testing.T) {
+		w := bulk(fmt.Sprintf(`{"ids": [%d, %d, 999], "add": ["Live", "acoustic"]}`, ids[0], ids[1]))
+		assert.Equal(t, http.StatusOK, w.Code)
+		var result models.BulkTagResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, models.BulkTagResult{Matched: 2, MissingIDs: []int{999}, Added: 4}, result)
+		assert.Equal(t, 2, tagCount("live"))
+	})
+
+	t.Run("By Filter", func(t *testing.T) {
+		w := bulk(`{"filter": {"group": "Muse"}, "add": ["live"], "remove": ["acoustic"]}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var result models.BulkTagResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, models.BulkTagResult{Matched: 3, Added: 1, Removed: 2}, result)
+		assert.Equal(t, 3, tagCount("live"))
+		assert.Equal(t, 0, tagCount("acoustic"))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, bulk(`{"add": ["live"]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, bulk(`{"ids": [1], "filter": {}, "add": ["live"]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, bulk(`{"ids": [1]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, bulk(`{"ids": [1], "add": ["live"], "remove": ["Live"]}`).Code)
+	})
+}
+
+func TestSongTags(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var ids [2]int
+	for i, title := range []string{"Uprising", "Hysteria"} {
+		err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link)
+			VALUES ('Muse', $1, '16.07.2006', 'Verse', 'https://example.com') RETURNING id`, title).Scan(&ids[i])
+		assert.NoError(t, err)
+	}
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	tagsPath := fmt.Sprintf("/songs/%d/tags", ids[0])
+
+	w := call(http.MethodPost, tagsPath, `{"tags": ["Live", "acoustic", "live"]}`)
 	assert.Equal(t, http.StatusOK, w.Code)
-	var resp map[string]int
-	err := json.Unmarshal(w.Body.Bytes(), &resp)
-	assert.NoError(t, err)
-	songID := resp["id"]
+	assert.JSONEq(t, fmt.Sprintf(`{"song_id": %d, "tags": ["acoustic", "live"]}`, ids[0]), w.Body.String())
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, fmt.Sprintf("/songs/%d/tags", ids[1]), `{"tags": ["live"]}`).Code)
 
-	// Проверка в БД
-	var song models.Song
-	err = db.Get(&song, "SELECT * FROM songs WHERE id=$1", songID)
+	w = call(http.MethodGet, "/songs?tag=live", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-Total-Count"))
+	w = call(http.MethodGet, "/songs?tag=live&tag=Acoustic", "")
+	assert.Equal(t, "1", w.Header().Get("X-Total-Count"), "songs must carry every tag filtered by")
+
+	w = call(http.MethodDelete, tagsPath+"/acoustic", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"song_id": %d, "tags": ["live"]}`, ids[0]), w.Body.String())
+	w = call(http.MethodGet, "/songs?tag=acoustic", "")
+	assert.Equal(t, "0", w.Header().Get("X-Total-Count"))
+
+	assert.Equal(t, http.StatusNotFound, call(http.MethodGet, "/songs/999999/tags", "").Code)
+	assert.Equal(t, http.StatusNotFound, call(http.MethodPost, "/songs/999999/tags", `{"tags": ["live"]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, tagsPath, `{"tags": [" "]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, tagsPath, `{"tags": []}`).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodGet, "/songs/abc/tags", "").Code)
+}
+
+func TestGenres(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var songID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link)
+		VALUES ('Muse', 'Uprising', '16.07.2006', 'Verse', 'https://example.com') RETURNING id`).Scan(&songID)
 	assert.NoError(t, err)
-	assert.Equal(t, "Muse", song.Group)
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	create := func(body string) int {
+		w := call(http.MethodPost, "/genres", body)
+		assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var genre models.Genre
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &genre))
+		return genre.ID
+	}
+	rock := create(`{"name": "Rock"}`)
+	alternative := create(fmt.Sprintf(`{"name": "Alternative Rock", "parent_id": %d}`, rock))
+	pop := create(`{"name": "Pop"}`)
 
-	// 2. Получение списка песен
-	req, _ = http.NewRequest(http.MethodGet, "/songs?group=Muse", nil)
-	w = httptest.NewRecorder()
-	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, call(http.MethodPost, "/genres", `{"name": "rock"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/genres", `{"name": "Indie", "parent_id": 999999}`).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPut, fmt.Sprintf("/genres/%d", rock),
+		fmt.Sprintf(`{"name": "Rock", "parent_id": %d}`, alternative)).Code, "a genre cannot move under its subgenre")
+
+	songGenres := fmt.Sprintf("/songs/%d/genres", songID)
+	w := call(http.MethodPut, songGenres, fmt.Sprintf(`{"genre_ids": [%d]}`, alternative))
 	assert.Equal(t, http.StatusOK, w.Code)
-	var songs []models.Song
-	err = json.Unmarshal(w.Body.Bytes(), &songs)
+	assert.Contains(t, w.Body.String(), `"Alternative Rock"`)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPut, songGenres, `{"genre_ids": [999999]}`).Code)
+	assert.Equal(t, http.StatusNotFound, call(http.MethodGet, "/songs/999999/genres", "").Code)
+
+	for genre, total := range map[string]string{"Rock": "1", "alternative rock": "1", "Pop": "0"} {
+		w = call(http.MethodGet, "/songs?genre="+strings.ReplaceAll(genre, " ", "+"), "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, total, w.Header().Get("X-Total-Count"), genre)
+	}
+
+	assert.Equal(t, http.StatusConflict, call(http.MethodDelete, fmt.Sprintf("/genres/%d", rock), "").Code)
+	assert.Equal(t, http.StatusOK, call(http.MethodDelete, fmt.Sprintf("/genres/%d", pop), "").Code)
+	assert.Equal(t, http.StatusNotFound, call(http.MethodGet, fmt.Sprintf("/genres/%d", pop), "").Code)
+}
+
+func TestSongTitles(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var songID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link)
+		VALUES ('Кино', 'Группа крови', '01.01.1988', 'Verse', 'https://example.com') RETURNING id`).Scan(&songID)
 	assert.NoError(t, err)
-	assert.Len(t, songs, 1)
+	call := func(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	titlesPath := fmt.Sprintf("/songs/%d/titles", songID)
 
-	// 3. Получение куплетов
-	req, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/songs/%d/verses?page=1&limit=2", songID), nil)
-	w = httptest.NewRecorder()
-	r.ServeHTTP(w, req)
+	w := call(http.MethodPut, titlesPath+"/EN", `{"title": "Blood Type"}`)
 	assert.Equal(t, http.StatusOK, w.Code)
-	var verses []service.Verse
-	err = json.Unmarshal(w.Body.Bytes(), &verses)
+	assert.Contains(t, w.Body.String(), `"lang":"en"`)
+	assert.Equal(t, http.StatusOK, call(http.MethodPut, titlesPath+"/ru-Latn", `{"title": "Gruppa krovi", "kind": "transliteration"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPut, titlesPath+"/english", `{"title": "Blood Type"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPut, titlesPath+"/en", `{"title": " "}`).Code)
+	assert.Equal(t, http.StatusNotFound, call(http.MethodPut, "/songs/999999/titles/en", `{"title": "Blood Type"}`).Code)
+
+	w = call(http.MethodGet, "/songs", "", "Accept-Language", "de-DE, en-GB;q=0.8")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"localized_title":{"lang":"en","kind":"translation","title":"Blood Type"}`)
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Language")
+	w = call(http.MethodGet, "/songs?title_lang=ru-Latn", "", "Accept-Language", "en")
+	assert.Contains(t, w.Body.String(), `"title":"Gruppa krovi"`)
+
+	// Every title variant is searchable
+	for _, query := range []string{"blood", "Gruppa", "крови"} {
+		w = call(http.MethodGet, "/songs?song="+query, "")
+		assert.Equal(t, "1", w.Header().Get("X-Total-Count"), query)
+	}
+
+	w = call(http.MethodGet, titlesPath, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"lang":"ru-Latn"`)
+	assert.Equal(t, http.StatusOK, call(http.MethodDelete, titlesPath+"/en", "").Code)
+	assert.Equal(t, http.StatusNotFound, call(http.MethodDelete, titlesPath+"/en", "").Code)
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"fr-CH", "fr", "en"}, parseAcceptLanguage("fr-CH, fr;q=0.9, en;q=0.8, de;q=0, *;q=0.5"))
+	assert.Equal(t, []string{"ja", "en-US"}, parseAcceptLanguage("en-US;q=0.5, ja"))
+	assert.Empty(t, parseAcceptLanguage("*"))
+}
+
+func TestBackfillLegacyRows(t *testing.T) {
+	_, db, cleanup := setupTest(t)
+	defer cleanup()
+	svc := service.NewMusicService(repository.NewPostgresRepository(db, zap.NewNop()), zap.NewNop(), http.DefaultClient)
+
+	_, err := db.Exec(`INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at) VALUES
+		('Muse', 'Hysteria', '', NULL, 'https://example.com', NULL, NULL),
+		(' muse', 'HYSTERIA ', '01.12.2003', 'Verse', '', NOW(), NOW()),
+		('Muse', 'Uprising', '07.09.2009', '  ', NULL, NOW(), NULL)`)
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO song_views (song_id, views) VALUES (1, 2), (2, 3)")
 	assert.NoError(t, err)
-	assert.GreaterOrEqual(t, len(verses), 1)
 
-	// 4. Обновление песни
-	updateBody := UpdateSongRequest{Group: "Muse", Song: "New Song", ReleaseDate: "01.01.2007"}
-	updateBytes, _ := json.Marshal(updateBody)
-	req, _ = http.NewRequest(http.MethodPut, fmt.Sprintf("/songs/%d", songID), bytes.NewBuffer(updateBytes))
+	expected := models.BackfillReport{
+		MissingCreatedAt: 1,
+		MissingUpdatedAt: 2,
+		EmptyFields:      map[string]int{"release_date": 1, "text": 1, "link": 1},
+		Duplicates:       []models.DuplicateSongs{{Group: "Muse", Song: "Hysteria", KeptID: 1, RemovedIDs: []int{2}}},
+	}
+
+	t.Run("Dry Run", func(t *testing.T) {
+		report, err := svc.BackfillLegacyRows(context.Background(), true)
+		assert.NoError(t, err)
+		dryRun := expected
+		dryRun.DryRun = true
+		assert.Equal(t, dryRun, report)
+
+		var count int
+		assert.NoError(t, db.Get(&count, "SELECT COUNT(*) FROM songs"))
+		assert.Equal(t, 3, count, "a dry run changes nothing")
+	})
+
+	t.Run("Backfill", func(t *testing.T) {
+		report, err := svc.BackfillLegacyRows(context.Background(), false)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, report)
+
+		var song models.Song
+		assert.NoError(t, db.Get(&song, selectSongByID, 1))
+		assert.Equal(t, "01.12.2003", *song.ReleaseDate, "the kept song takes over the known fields of its duplicates")
+		assert.Equal(t, "Verse", *song.Text)
+		assert.False(t, song.CreatedAt.IsZero())
+		var views int
+		assert.NoError(t, db.Get(&views, "SELECT views FROM song_views WHERE song_id = 1"))
+		assert.Equal(t, 5, views)
+
+		report, err = svc.BackfillLegacyRows(context.Background(), false)
+		assert.NoError(t, err)
+		assert.Empty(t, report.Duplicates, "backfilling is idempotent")
+		assert.Zero(t, report.MissingCreatedAt)
+	})
+}
+
+func TestPollChanges(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var songID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		"Muse", "Uprising", "07.09.2009", "Verse 1", "https://example.com").Scan(&songID)
+	assert.NoError(t, err)
+
+	poll := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/changes/poll?"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Times Out Without Changes", func(t *testing.T) {
+		w := poll("timeout=50ms")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"changes": [], "next": 0, "missed": false}`, w.Body.String())
+	})
+
+	t.Run("Returns Change Once Published", func(t *testing.T) {
+		done := make(chan *httptest.ResponseRecorder)
+		go func() { done <- poll("since=0&timeout=5s") }()
+		time.Sleep(50 * time.Millisecond)
+
+		req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/songs/%d", songID), nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		w := <-done
+		assert.Equal(t, http.StatusOK, w.Code)
+		var batch struct {
+			Changes []struct {
+				ID     uint64 `json:"id"`
+				Type   string `json:"type"`
+				SongID int    `json:"song_id"`
+			} `json:"changes"`
+			Next uint64 `json:"next"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &batch))
+		if !assert.Len(t, batch.Changes, 1) {
+			return
+		}
+		assert.Equal(t, "song_deleted", batch.Changes[0].Type)
+		assert.Equal(t, songID, batch.Changes[0].SongID)
+		assert.Equal(t, uint64(1), batch.Next)
+	})
+
+	t.Run("Invalid Parameters", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, poll("since=-1").Code)
+		assert.Equal(t, http.StatusBadRequest, poll("timeout=soon").Code)
+		assert.Equal(t, http.StatusBadRequest, poll("timeout=1h").Code)
+	})
+}
+
+func TestStreamEvents(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	var songID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, release_date, text, link)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		"Muse", "Uprising", "07.09.2009", "Verse 1", "https://example.com").Scan(&songID)
+	assert.NoError(t, err)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/events", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("/songs/%d", songID), bytes.NewBufferString(
+		`{"group": "Muse", "song": "Uprising", "release_date": "07.09.2009", "text": "Verse 2", "link": "https://example.com"}`))
 	req.Header.Set("Content-Type", "application/json")
-	w = httptest.NewRecorder()
+	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
+	req, _ = http.NewRequest(http.MethodDelete, fmt.Sprintf("/songs/%d", songID), nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
 
-	// Проверка обновления в БД
-	err = db.Get(&song, "SELECT * FROM songs WHERE id=$1", songID)
-	assert.NoError(t, err)
-	assert.Equal(t, "New Song", song.Song)
+	for _, expected := range []string{changes.EventSongUpdated, changes.EventSongDeleted} {
+		var event changes.Event
+		if assert.NoError(t, conn.ReadJSON(&event)) {
+			assert.Equal(t, expected, event.Type)
+			assert.Equal(t, songID, event.SongID)
+		}
+	}
 
-	// 5. Удаление песни
-	req, _ = http.NewRequest(http.MethodDelete, fmt.Sprintf("/songs/%d", songID), nil)
+	req, _ = http.NewRequest(http.MethodGet, "/ws/events", nil)
 	w = httptest.NewRecorder()
 	r.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "plain requests are not upgraded")
+}
 
-	// Проверка удаления
-	var count int
-	err = db.Get(&count, "SELECT COUNT(*) FROM songs WHERE id=$1", songID)
+func TestStreamSongEvents(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	var firstID, secondID int64
+	err := db.QueryRow(`INSERT INTO song_events (event_type, song_id, count) VALUES ($1, $2, 1) RETURNING id`,
+		changes.EventSongCreated, 7).Scan(&firstID)
+	assert.NoError(t, err)
+	err = db.QueryRow(`INSERT INTO song_events (event_type, song_id, count) VALUES ($1, $2, 1) RETURNING id`,
+		changes.EventSongDeleted, 7).Scan(&secondID)
 	assert.NoError(t, err)
-	assert.Equal(t, 0, count)
 
-	// Проверка полного списка
-	req, _ = http.NewRequest(http.MethodGet, "/songs?group=Muse", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/songs/stream", nil)
+	req.Header.Set("Last-Event-ID", strconv.FormatInt(firstID, 10))
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return
+		}
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, ":") {
+			lines = append(lines, line)
+		}
+	}
+	assert.Equal(t, fmt.Sprintf("id: %d", secondID), lines[0], "events up to Last-Event-ID are skipped")
+	assert.Equal(t, "event: "+changes.EventSongDeleted, lines[1])
+	assert.Contains(t, lines[2], `"song_id":7`)
+
+	req, _ = http.NewRequest(http.MethodGet, "/songs/stream", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFieldVisibility(t *testing.T) {
+	t.Setenv("FIELD_VISIBILITY", "licensing_fee=admin, text=editor, notes=public")
+	visibility, err := FieldVisibilityFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, FieldVisibility{"licensing_fee": models.RoleAdmin, "text": models.RoleEditor}, visibility)
+
+	t.Setenv("FIELD_VISIBILITY", "salary=admin")
+	_, err = FieldVisibilityFromEnv()
+	assert.Error(t, err)
+	t.Setenv("FIELD_VISIBILITY", "notes=owner")
+	_, err = FieldVisibilityFromEnv()
+	assert.Error(t, err)
+
+	notes, fee := "Cleared for sync", 120.5
+	page := models.SongPage{Data: []models.Song{{ID: 1, Group: "Muse", Song: "Uprising", Notes: &notes, LicensingFee: &fee}}, Total: 1}
+	handler := NewHandler(nil, zap.NewNop())
+	request := func(role string) map[string]any {
+		r := gin.New()
+		r.GET("/songs", func(c *gin.Context) {
+			if role != "" {
+				c.Set(middleware.ContextRole, role)
+			}
+			handler.renderSongs(c, http.StatusOK, page)
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs", nil))
+		var resp struct {
+			Data  []map[string]any `json:"data"`
+			Total int              `json:"total"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 1, resp.Total)
+		return resp.Data[0]
+	}
+
+	song := request("")
+	assert.NotContains(t, song, "notes")
+	assert.NotContains(t, song, "licensing_fee")
+	assert.Equal(t, "Uprising", song["song"])
+	assert.NotContains(t, request(models.RoleViewer), "notes")
+	song = request(models.RoleEditor)
+	assert.Equal(t, "Cleared for sync", song["notes"])
+	assert.Equal(t, 120.5, song["licensing_fee"])
+}
+
+func TestXMLResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	notes := "Cleared for sync"
+	page := models.SongPage{
+		Data:   []models.Song{{ID: 1, Group: "Muse", Song: "Uprising", Notes: &notes}},
+		Total:  1,
+		Facets: models.Facets{"year": {{Value: "2009", Count: 1}}},
+	}
+	handler := NewHandler(nil, zap.NewNop())
+	r := gin.New()
+	r.GET("/songs", func(c *gin.Context) { handler.renderSongs(c, http.StatusOK, page) })
+	r.GET("/songs/search", func(c *gin.Context) { handler.renderSongs(c, http.StatusOK, page.Data) })
+
+	req, _ := http.NewRequest(http.MethodGet, "/songs", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/xml")
+	assert.Contains(t, w.Header().Values("Vary"), "Accept")
+	var resp struct {
+		XMLName xml.Name      `xml:"songs"`
+		Data    []models.Song `xml:"data>song"`
+		Total   int           `xml:"total"`
+		Facets  []struct {
+			Name    string               `xml:"name,attr"`
+			Buckets []models.FacetBucket `xml:"bucket"`
+		} `xml:"facets>facet"`
+	}
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Total)
+	if assert.Len(t, resp.Data, 1) {
+		assert.Equal(t, "Uprising", resp.Data[0].Song)
+		assert.Nil(t, resp.Data[0].Notes, "hidden fields are left out")
+	}
+	if assert.Len(t, resp.Facets, 1) {
+		assert.Equal(t, "year", resp.Facets[0].Name)
+		assert.Equal(t, []models.FacetBucket{{Value: "2009", Count: 1}}, resp.Facets[0].Buckets)
+	}
+	assert.Equal(t, &notes, page.Data[0].Notes, "hiding fields leaves the payload untouched")
+
+	req, _ = http.NewRequest(http.MethodGet, "/songs/search", nil)
+	req.Header.Set("Accept", "text/xml")
 	w = httptest.NewRecorder()
 	r.ServeHTTP(w, req)
-	err = json.Unmarshal(w.Body.Bytes(), &songs)
+	var list struct {
+		Songs []models.Song `xml:"song"`
+	}
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Songs, 1, "lists get a root element")
+
+	req, _ = http.NewRequest(http.MethodGet, "/songs", nil)
+	req.Header.Set("Accept", "*/*")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json", "JSON stays the default")
+
+	mock := gin.New()
+	RegisterMockRoutes(mock, zap.NewNop())
+	req, _ = http.NewRequest(http.MethodGet, "/songs/7/verses", nil)
+	req.Header.Set("Accept", "application/xml")
+	w = httptest.NewRecorder()
+	mock.ServeHTTP(w, req)
+	var verses service.VersePage
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &verses))
+	assert.Equal(t, 1, verses.TotalVerses, "the mock serves the documented example")
+	if assert.Len(t, verses.Verses, 1) {
+		assert.Equal(t, 1, verses.Verses[0].Number)
+		assert.Contains(t, verses.Verses[0].Text, "Ooh baby")
+	}
+}
+
+func TestSongRevisions(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var songID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, text) VALUES ('Muse', 'Uprising', 'Paranoia is in bloom') RETURNING id`).Scan(&songID)
 	assert.NoError(t, err)
-	assert.Len(t, songs, 0)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	revisionsPath := fmt.Sprintf("/songs/%d/revisions", songID)
+
+	assert.Equal(t, http.StatusOK, request(http.MethodPatch, fmt.Sprintf("/songs/%d", songID), `{"text": "vandalized"}`).Code)
+	w := request(http.MethodGet, revisionsPath, "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var revisions models.SongRevisionList
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &revisions))
+	if assert.Len(t, revisions.Data, 2, "the first update records the original too") {
+		assert.Equal(t, 2, revisions.Data[0].Revision, "newest first")
+		assert.Equal(t, "vandalized", *revisions.Data[0].Data.Text)
+		assert.Equal(t, models.RevisionOriginal, revisions.Data[1].Reason)
+		assert.Equal(t, "Paranoia is in bloom", *revisions.Data[1].Data.Text)
+	}
+
+	w = request(http.MethodPost, revisionsPath+"/1/restore", "")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var restored models.SongRevision
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+	assert.Equal(t, 3, restored.Revision)
+	assert.Equal(t, models.RevisionRestore, restored.Reason)
+	var text string
+	assert.NoError(t, db.Get(&text, "SELECT text FROM songs WHERE id = $1", songID))
+	assert.Equal(t, "Paranoia is in bloom", text)
+
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPost, revisionsPath+"/99/restore", "").Code)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, revisionsPath+"/latest/restore", "").Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/songs/999999/revisions", "").Code)
+}
+
+func TestTrash(t *testing.T) {
+	r, db, cleanup := setupTest(t)
+	defer cleanup()
+
+	var songID, tagID int
+	err := db.QueryRow(`INSERT INTO songs (group_name, song_name, text) VALUES ('Muse', 'Uprising', 'Paranoia is in bloom') RETURNING id`).Scan(&songID)
+	assert.NoError(t, err)
+	assert.NoError(t, db.QueryRow(`INSERT INTO tags (name) VALUES ('rock') RETURNING id`).Scan(&tagID))
+	_, err = db.Exec(`INSERT INTO song_tags (song_id, tag_id) VALUES ($1, $2)`, songID, tagID)
+	assert.NoError(t, err)
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, fmt.Sprintf("/songs/%d", songID)).Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, fmt.Sprintf("/songs/%d", songID)).Code, "a trashed song leaves the catalog")
+
+	w := request(http.MethodGet, "/trash")
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var trash models.TrashPage
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &trash))
+	if assert.Len(t, trash.Data, 1) {
+		assert.Equal(t, songID, trash.Data[0].ID)
+		assert.Equal(t, "Uprising", trash.Data[0].Song)
+		assert.True(t, trash.Data[0].PurgeAt.After(trash.Data[0].DeletedAt))
+	}
+
+	w = request(http.MethodPost, fmt.Sprintf("/trash/%d/restore", songID))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var song models.Song
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &song))
+	assert.Equal(t, songID, song.ID)
+	var tags int
+	assert.NoError(t, db.Get(&tags, "SELECT COUNT(*) FROM song_tags WHERE song_id = $1", songID))
+	assert.Equal(t, 1, tags, "the song's tags are restored with it")
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPost, fmt.Sprintf("/trash/%d/restore", songID)).Code, "a restored song leaves the trash")
+
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, fmt.Sprintf("/songs/%d", songID)).Code)
+	assert.Equal(t, http.StatusOK, request(http.MethodDelete, fmt.Sprintf("/trash/%d", songID)).Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, fmt.Sprintf("/trash/%d", songID)).Code)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/trash/abc/restore").Code)
 }
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"music-library/internal/apistub"
+	"music-library/internal/breaker"
+	"music-library/internal/repository/mocks"
+	"music-library/internal/service"
+)
+
+// setupUnitTest returns a router over a mock repository, which runs transactions in place, and a stub
+// of the external API, so the handlers can be tested without a database
+func setupUnitTest(t *testing.T) (*gin.Engine, *mocks.MockRepository, *apistub.Server, *service.MusicService) {
+	api := apistub.New(t)
+	repo := mocks.NewMockRepository(gomock.NewController(t))
+	repo.EXPECT().ConfigureStatementTimeout(gomock.Any()).AnyTimes()
+	repo.EXPECT().RunInTransaction(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) })
+
+	logger := zap.NewNop()
+	svc := service.NewMusicService(repo, logger, api.Client())
+	svc.ConfigureResilience(service.RetryConfig{Attempts: 1}, breaker.New(service.ExternalAPIProvider, breaker.DefaultConfig, logger))
+	handler := NewHandler(svc, logger)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/songs", handler.AddSong)
+	r.GET("/songs/:id/tags", handler.GetSongTags)
+	return r, repo, api, svc
+}
+
+func TestAddSongUnit(t *testing.T) {
+	r, repo, api, svc := setupUnitTest(t)
+	api.AddSong("Muse", "Uprising", apistub.Song{ReleaseDate: "07.09.2009", Text: "Paranoia is in bloom", Link: "https://example.com/uprising"})
+	repo.EXPECT().AddSong(gomock.Any(), "Muse", "Uprising", "07.09.2009", "Paranoia is in bloom", "https://example.com/uprising", gomock.Any()).Return(7, nil)
+	repo.EXPECT().AddSongEvent(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+	repo.EXPECT().SaveVerseIndex(gomock.Any(), 7, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/songs", bytes.NewBufferString(`{"group": "Muse", "song": "Uprising"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id": 7}`, w.Body.String())
+
+	// Without fallback data a song the API does not know is refused before anything is written
+	svc.ConfigureFallback(service.FallbackConfig{Mode: service.FallbackDisabled})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/songs", bytes.NewBufferString(`{"group": "Muse", "song": "Unknown"}`)))
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/songs", bytes.NewBufferString(`{"group": "Muse"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, api.Requests(), 2, "invalid requests do not reach the external API")
+}
+
+func TestGetSongTagsUnit(t *testing.T) {
+	r, repo, _, _ := setupUnitTest(t)
+	repo.EXPECT().GetSongTags(gomock.Any(), 7).Return([]string{"rock", "live"}, nil)
+	repo.EXPECT().GetSongTags(gomock.Any(), 8).Return(nil, sql.ErrNoRows)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/7/tags", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		SongID int      `json:"song_id"`
+		Tags   []string `json:"tags"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 7, response.SongID)
+	assert.Equal(t, []string{"rock", "live"}, response.Tags)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/8/tags", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/songs/abc/tags", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// readinessTimeout bounds the database check performed by the readiness probe
+const readinessTimeout = 2 * time.Second
+
+// Readiness tracks whether the instance should receive new traffic.
+// It is flipped to draining on shutdown so load balancers stop routing before connections are closed.
+type Readiness struct {
+	draining atomic.Bool
+}
+
+// SetDraining marks the instance as shutting down
+func (r *Readiness) SetDraining() {
+	r.draining.Store(true)
+}
+
+// Draining reports whether the instance is shutting down
+func (r *Readiness) Draining() bool {
+	return r.draining.Load()
+}
+
+// Healthz handles the liveness probe
+// @Summary Liveness probe
+// @Tags health
+// @Produce json
+// @Success 200 {object} StatusResponse
+// @Router /healthz [get]
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz returns the readiness probe handler, which fails while draining or when the database is unreachable,
+// unless the degraded mode serves reads without it
+// @Summary Readiness probe
+// @Description Fail while the instance drains or when the database is unreachable, unless the degraded mode serves reads without it
+// @Tags health
+// @Produce json
+// @Success 200 {object} StatusResponse
+// @Failure 503 {object} StatusResponse
+// @Router /readyz [get]
+func (h *Handler) Readyz(readiness *Readiness) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if readiness.Draining() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		defer cancel()
+		if err := h.svc.Ping(ctx); err != nil {
+			if h.svc.DegradedModeEnabled() {
+				// Reads are still served from the warm cache, so the instance keeps its traffic
+				h.logger.Warn("Readiness check found the database unavailable, serving degraded", zap.Error(err))
+				c.JSON(http.StatusOK, gin.H{"status": "degraded"})
+				return
+			}
+			h.logger.Warn("Readiness check failed", zap.Error(err))
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "database unavailable"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ready"})
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/api/middleware"
+	"music-library/internal/service"
+)
+
+// Default and maximum number of rows parsed by an import preview
+const (
+	defaultPreviewRows = 20
+	maxPreviewRows     = 1000
+)
+
+// ImportSongs handles the request to import songs from an uploaded CSV file.
+// The multipart form carries the file in "file", an optional JSON column mapping in "mapping",
+// and optionally the "import_id" of an interrupted import to resume after its last checkpoint.
+// With "async" set to true the import runs as a background job, followed with GET /jobs/:id.
+// @Summary Import songs
+// @Description Import songs from an uploaded CSV file. An interrupted import is resumed after its last checkpoint
+// @Description with its import_id. With async=true the import runs as a background job followed with GET /jobs/{id}.
+// @Tags imports
+// @Accept mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV file"
+// @Param mapping formData string false "JSON mapping of the song fields to CSV columns"
+// @Param import_id formData string false "ID of an interrupted import to resume"
+// @Param async query bool false "Run the import as a background job" default(false)
+// @Success 200 {object} service.ImportResult
+// @Success 202 {object} models.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /songs/import [post]
+func (h *Handler) ImportSongs(c *gin.Context) {
+	h.logger.Info("Handling ImportSongs request")
+
+	file, mapping, ok := h.importUpload(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	if async, _ := strconv.ParseBool(c.DefaultPostForm("async", c.Query("async"))); async {
+		h.startImport(c, file, mapping)
+		return
+	}
+
+	result, err := h.svc.ImportSongs(c.Request.Context(), file, mapping, c.PostForm("import_id"))
+	if err != nil {
+		if result != nil {
+			h.logger.Error("Import interrupted", zap.String("import_id", result.ImportID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Import interrupted, resume it with import_id", "import_id": result.ImportID})
+			return
+		}
+		h.respondImportError(c, err)
+		return
+	}
+
+	h.logger.Info("Songs imported successfully", zap.String("import_id", result.ImportID),
+		zap.Int("created", result.Created), zap.Int("updated", result.Updated), zap.Int("failed", result.Failed))
+	c.JSON(http.StatusOK, result)
+}
+
+// startImport copies the upload to a temporary file, which outlives the request, and queues its import
+func (h *Handler) startImport(c *gin.Context, file multipart.File, mapping service.ImportMapping) {
+	spool, err := os.CreateTemp("", "import-*.csv")
+	if err != nil {
+		h.logger.Error("Failed to create import file", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	_, err = io.Copy(spool, file)
+	if closeErr := spool.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(spool.Name())
+		h.logger.Error("Failed to store import file", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	job, err := h.svc.StartImport(c.Request.Context(), c.GetInt(middleware.ContextUserID), spool.Name(), mapping, c.PostForm("import_id"))
+	if err != nil {
+		h.respondJobError(c, err)
+		return
+	}
+
+	h.logger.Info("Import queued", zap.String("job_id", job.ID))
+	c.JSON(http.StatusAccepted, job)
+}
+
+// PreviewImport handles the request to preview what an import would create or update without writing anything
+// @Summary Preview an import
+// @Description Show what an import would create or update without writing anything
+// @Tags imports
+// @Accept mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV file"
+// @Param mapping formData string false "JSON mapping of the song fields to CSV columns"
+// @Param rows query int false "Number of rows to preview" default(20) minimum(1) maximum(1000)
+// @Success 200 {object} service.ImportPreview
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/import/preview [post]
+func (h *Handler) PreviewImport(c *gin.Context) {
+	h.logger.Info("Handling PreviewImport request")
+
+	rowsStr := c.DefaultPostForm("rows", c.DefaultQuery("rows", strconv.Itoa(defaultPreviewRows)))
+	rows, err := strconv.Atoi(rowsStr)
+	if err != nil || rows < 1 || rows > maxPreviewRows {
+		h.logger.Error("Invalid preview rows", zap.String("rows", rowsStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rows"})
+		return
+	}
+
+	file, mapping, ok := h.importUpload(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	preview, err := h.svc.PreviewImport(c.Request.Context(), file, mapping, rows)
+	if err != nil {
+		h.respondImportError(c, err)
+		return
+	}
+
+	h.logger.Info("Import preview built successfully", zap.Int("items", len(preview.Items)))
+	c.JSON(http.StatusOK, preview)
+}
+
+// importUpload extracts the uploaded file and column mapping from an import request,
+// writing an error response and returning false when either is unusable
+func (h *Handler) importUpload(c *gin.Context) (multipart.File, service.ImportMapping, bool) {
+	mapping, err := service.ParseImportMapping(c.PostForm("mapping"))
+	if err != nil {
+		h.logger.Warn("Invalid import mapping", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, mapping, false
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		h.logger.Warn("Missing import file", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing import file"})
+		return nil, mapping, false
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.logger.Error("Failed to open import file", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return nil, mapping, false
+	}
+	return file, mapping, true
+}
+
+// respondImportError writes the response for a failed import or preview
+func (h *Handler) respondImportError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrImportNotFound) {
+		h.logger.Warn("Import not found", zap.Error(err))
+		c.JSON(http.StatusNotFound, gin.H{"error": "Import not found"})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidImport) {
+		h.logger.Warn("Invalid import file", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Error("Failed to process import", zap.Error(err))
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/api/middleware"
+	"music-library/internal/jobs"
+	"music-library/internal/models"
+	"music-library/internal/service"
+)
+
+// GetJob handles the request to retrieve the status, progress and result of a background job. Only the
+// user who started the job and admins may read it; other users are told it does not exist.
+// @Summary Get a background job
+// @Description Retrieve the status, progress and result of a background job started by the caller, or of any job for admins
+// @Tags jobs
+// @Produce json
+// @Security BearerAuth
+// @Security AdminToken
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.Job
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /jobs/{id} [get]
+// @Router /admin/jobs/{id} [get]
+func (h *Handler) GetJob(c *gin.Context) {
+	h.logger.Info("Handling GetJob request")
+
+	id := c.Param("id")
+	job, err := h.svc.GetJob(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.logger.Warn("Job not found", zap.String("job_id", id))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		h.respondJobError(c, err)
+		return
+	}
+	if !canReadJob(c, job) {
+		h.logger.Warn("Rejected request for another user's job", zap.String("job_id", id), zap.Int("user_id", c.GetInt(middleware.ContextUserID)))
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	h.logger.Info("Job retrieved successfully", zap.String("job_id", id), zap.String("status", job.Status))
+	c.JSON(http.StatusOK, job)
+}
+
+// canReadJob reports whether the caller is an admin or the authenticated user who owns the job
+func canReadJob(c *gin.Context, job models.Job) bool {
+	if models.HasRole(c.GetString(middleware.ContextRole), models.RoleAdmin) {
+		return true
+	}
+	_, authenticated := c.Get(middleware.ContextUserID)
+	return authenticated && job.OwnerID != nil && *job.OwnerID == c.GetInt(middleware.ContextUserID)
+}
+
+// respondJobError writes the response for a background job that could not be queued or read
+func (h *Handler) respondJobError(c *gin.Context, err error) {
+	if errors.Is(err, jobs.ErrQueueFull) || errors.Is(err, service.ErrJobsUnavailable) {
+		h.logger.Warn("Background job unavailable", zap.Error(err))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Error("Failed to process background job", zap.Error(err))
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+}
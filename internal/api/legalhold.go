@@ -0,0 +1,65 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SetLegalHoldRequest defines the request body for setting or releasing the legal hold of a song
+type SetLegalHoldRequest struct {
+	LegalHold *bool `json:"legal_hold" binding:"required" example:"true"`
+}
+
+// SetLegalHold handles the admin request to set or release the legal hold of a song. While it is set,
+// requests modifying or deleting the song are rejected with 423 Locked.
+// @Summary Set the legal hold of a song
+// @Description While the hold is set, requests modifying or deleting the song are rejected with 423
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "Song ID"
+// @Param hold body SetLegalHoldRequest true "Legal hold"
+// @Success 200 {object} LegalHoldResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/songs/{id}/legal-hold [put]
+func (h *Handler) SetLegalHold(c *gin.Context) {
+	h.logger.Info("Handling SetLegalHold request")
+
+	songIDStr := c.Param("id")
+	songID, err := strconv.Atoi(songIDStr)
+	if err != nil {
+		h.logger.Error("Invalid song ID", zap.String("song_id", songIDStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	var req SetLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.SetLegalHold(c.Request.Context(), songID, *req.LegalHold); err != nil {
+		if err == sql.ErrNoRows {
+			h.logger.Warn("Song not found", zap.Int("song_id", songID))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+			return
+		}
+		h.logger.Error("Failed to set legal hold", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Legal hold set successfully", zap.Int("song_id", songID), zap.Bool("legal_hold", *req.LegalHold))
+	c.JSON(http.StatusOK, gin.H{"id": songID, "legal_hold": *req.LegalHold})
+}
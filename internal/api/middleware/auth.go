@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/auth"
+	"music-library/internal/models"
+)
+
+// AdminAuth returns a middleware that only lets requests carrying the admin token through.
+// The token is accepted either as a bearer token or in the X-Admin-Token header, and grants the admin role.
+// When no token is configured, admin endpoints are disabled entirely.
+func AdminAuth(token string, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			logger.Warn("Admin endpoint requested but ADMIN_TOKEN is not configured", zap.String("path", c.FullPath()))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin API is disabled"})
+			return
+		}
+
+		provided := c.GetHeader("X-Admin-Token")
+		if provided == "" {
+			provided = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			logger.Warn("Rejected admin request", zap.String("path", c.FullPath()))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		c.Set(ContextRole, models.RoleAdmin)
+		c.Next()
+	}
+}
+
+// Context keys under which RequireUser stores the authenticated user
+const (
+	ContextUserID   = "user_id"
+	ContextUsername = "username"
+	ContextRole     = "role"
+)
+
+// RequireUser returns a middleware that only lets requests carrying a valid access token through.
+// The token is expected as a bearer token; the user is stored in the context under the Context* keys.
+// Browsers cannot set headers on WebSocket handshakes, so those may pass the token as access_token instead.
+func RequireUser(tokens *auth.Tokens, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok && c.IsWebsocket() {
+			token, ok = c.GetQuery("access_token")
+		}
+		if !ok || token == "" {
+			logger.Warn("Rejected request without access token", zap.String("path", c.FullPath()))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		claims, err := tokens.Verify(token, auth.TokenAccess)
+		if err != nil {
+			logger.Warn("Rejected request with invalid access token", zap.String("path", c.FullPath()), zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		c.Set(ContextUserID, claims.UserID)
+		c.Set(ContextUsername, claims.Username)
+		c.Set(ContextRole, claims.Role)
+		c.Next()
+	}
+}
+
+// OptionalUser returns a middleware that stores the user of a valid access token like RequireUser,
+// but lets requests without one through as anonymous. Invalid tokens are still rejected.
+func OptionalUser(tokens *auth.Tokens, logger *zap.Logger) gin.HandlerFunc {
+	requireUser := RequireUser(tokens, logger)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" && !(c.IsWebsocket() && c.Query("access_token") != "") {
+			c.Next()
+			return
+		}
+		requireUser(c)
+	}
+}
+
+// RequireRole returns a middleware that only lets users holding the role, or a more privileged one, through.
+// It must run after RequireUser; requests without an authenticated user are rejected as unauthorized.
+func RequireRole(role string, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(ContextRole); !ok {
+			logger.Warn("Role required but no user is authenticated", zap.String("path", c.FullPath()))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+		if userRole := c.GetString(ContextRole); !models.HasRole(userRole, role) {
+			logger.Warn("Rejected request lacking role", zap.String("path", c.FullPath()),
+				zap.Int("user_id", c.GetInt(ContextUserID)), zap.String("required", role), zap.String("role", userRole))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
+		c.Next()
+	}
+}
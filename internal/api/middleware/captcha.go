@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/captcha"
+)
+
+// CaptchaHeader carries the token a client obtained by solving the verifier's challenge
+const CaptchaHeader = "X-Captcha-Token"
+
+// Error codes of the structured errors returned by RequireHuman
+const (
+	CodeCaptchaRequired    = "captcha_required"
+	CodeCaptchaInvalid     = "captcha_invalid"
+	CodeCaptchaUnavailable = "captcha_unavailable"
+)
+
+// RequireHuman returns a middleware that lets unauthenticated requests through only with a token in the
+// X-Captcha-Token header the verifier accepts. Requests of authenticated users are not challenged.
+// Rejections carry a code and the provider, so clients can render the right challenge.
+func RequireHuman(verifier captcha.Verifier, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(ContextUserID); ok {
+			c.Next()
+			return
+		}
+		reject := func(status int, code, message string) {
+			c.AbortWithStatusJSON(status, gin.H{"error": message, "code": code, "provider": verifier.Name()})
+		}
+
+		token := c.GetHeader(CaptchaHeader)
+		if token == "" {
+			logger.Warn("Rejected unverified request", zap.String("path", c.FullPath()))
+			reject(http.StatusBadRequest, CodeCaptchaRequired, "Verification token required in the "+CaptchaHeader+" header")
+			return
+		}
+		if err := verifier.Verify(c.Request.Context(), token, c.ClientIP()); err != nil {
+			if errors.Is(err, captcha.ErrRejected) {
+				logger.Warn("Rejected request with invalid verification token", zap.String("path", c.FullPath()), zap.Error(err))
+				reject(http.StatusForbidden, CodeCaptchaInvalid, "Verification failed")
+				return
+			}
+			logger.Error("Failed to verify token", zap.String("provider", verifier.Name()), zap.Error(err))
+			reject(http.StatusServiceUnavailable, CodeCaptchaUnavailable, "Verification is unavailable")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gzipWriter compresses everything the handler writes
+type gzipWriter struct {
+	gin.ResponseWriter
+	writer *gzip.Writer
+}
+
+// Write compresses the data; a Content-Length set by the handler describes the uncompressed body, so it is dropped
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	w.Header().Del("Content-Length")
+	return w.writer.Write(data)
+}
+
+// WriteString compresses the string
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush pushes the compressed data written so far to the client
+func (w *gzipWriter) Flush() {
+	w.writer.Flush()
+	w.ResponseWriter.Flush()
+}
+
+// Compression returns a middleware that gzips responses for clients accepting gzip
+func Compression() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+			c.Next()
+			return
+		}
+
+		gz := gzip.NewWriter(c.Writer)
+		c.Header("Content-Encoding", "gzip")
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		c.Writer = &gzipWriter{ResponseWriter: c.Writer, writer: gz}
+		defer gz.Close()
+		c.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/tracing"
+)
+
+// Logger returns a middleware that logs every finished request with its status and latency
+func Logger(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+		}
+		if id := tracing.RequestID(c.Request.Context()); id != "" {
+			fields = append(fields, zap.String("request_id", id))
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+		switch status := c.Writer.Status(); {
+		case status >= http.StatusInternalServerError:
+			logger.Error("Request failed", fields...)
+		case status >= http.StatusBadRequest:
+			logger.Warn("Request rejected", fields...)
+		default:
+			logger.Info("Request handled", fields...)
+		}
+	}
+}
+
+// Recovery returns a middleware that turns panics in handlers into 500 responses. A panic with
+// http.ErrAbortHandler is passed on to the server, which drops the connection: streaming handlers use it
+// to cut short a response already under way.
+func Recovery(logger *zap.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		if recovered == http.ErrAbortHandler {
+			panic(recovered)
+		}
+		logger.Error("Recovered from panic", zap.String("path", c.Request.URL.Path), zap.Any("panic", recovered), zap.Stack("stack"))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	})
+}
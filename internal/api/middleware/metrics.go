@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteMetrics are the request counts and latencies of a route
+type RouteMetrics struct {
+	Method       string        `json:"method" example:"GET"`
+	Route        string        `json:"route" example:"/songs"`
+	Requests     int64         `json:"requests" example:"120"`
+	Statuses     map[int]int64 `json:"statuses" swaggertype:"object"`
+	AvgLatencyMs float64       `json:"avg_latency_ms" example:"3.2"`
+	MaxLatencyMs float64       `json:"max_latency_ms" example:"41.7"`
+	totalLatency time.Duration
+	maxLatency   time.Duration
+}
+
+// Metrics counts requests per route in memory
+type Metrics struct {
+	mu     sync.Mutex
+	routes map[string]*RouteMetrics
+}
+
+// NewMetrics creates an empty Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{routes: make(map[string]*RouteMetrics)}
+}
+
+// Middleware returns a middleware recording every request in the metrics.
+// Requests that match no route are recorded under the route "unmatched".
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		key := c.Request.Method + " " + route
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		rm, ok := m.routes[key]
+		if !ok {
+			rm = &RouteMetrics{Method: c.Request.Method, Route: route, Statuses: make(map[int]int64)}
+			m.routes[key] = rm
+		}
+		rm.Requests++
+		rm.Statuses[c.Writer.Status()]++
+		rm.totalLatency += latency
+		if latency > rm.maxLatency {
+			rm.maxLatency = latency
+		}
+	}
+}
+
+// Snapshot returns the metrics of every route, sorted by route and method
+func (m *Metrics) Snapshot() []RouteMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := make([]RouteMetrics, 0, len(m.routes))
+	for _, rm := range m.routes {
+		copied := *rm
+		copied.Statuses = make(map[int]int64, len(rm.Statuses))
+		for status, count := range rm.Statuses {
+			copied.Statuses[status] = count
+		}
+		copied.AvgLatencyMs = float64(rm.totalLatency.Microseconds()) / 1000 / float64(rm.Requests)
+		copied.MaxLatencyMs = float64(rm.maxLatency.Microseconds()) / 1000
+		snapshot = append(snapshot, copied)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].Route != snapshot[j].Route {
+			return snapshot[i].Route < snapshot[j].Route
+		}
+		return snapshot[i].Method < snapshot[j].Method
+	})
+	return snapshot
+}
+
+// Handler returns a handler responding with the metrics snapshot
+// @Summary Get HTTP metrics
+// @Description Report the request counts and latencies of every route
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {array} middleware.RouteMetrics
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /admin/http-metrics [get]
+func (m *Metrics) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, m.Snapshot())
+	}
+}
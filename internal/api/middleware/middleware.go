@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrUnknownMiddleware is returned when a chain names a middleware that is not registered
+var ErrUnknownMiddleware = errors.New("unknown middleware")
+
+// Names under which the built-in middlewares are registered
+const (
+	NameRecovery     = "recovery"
+	NameLogger       = "logger"
+	NameRequestID    = "request-id"
+	NameMetrics      = "metrics"
+	NamePrometheus   = "prometheus"
+	NameCORS         = "cors"
+	NameCompression  = "compression"
+	NameTimeout      = "timeout"
+	NameRateLimit    = "ratelimit"
+	NameAuth         = "auth"
+	NameAuthOptional = "auth-optional"
+	NameAdmin        = "admin"
+	NameViewer       = "role-viewer"
+	NameEditor       = "role-editor"
+	NameOwner        = "role-admin"
+	NameCaptcha      = "captcha"
+	NameReadOnly     = "read-only"
+)
+
+// Route groups that get their own middleware chain
+const (
+	// GroupGlobal runs for every request, including unmatched routes
+	GroupGlobal = "global"
+	// GroupAuth runs for the registration, login and refresh endpoints
+	GroupAuth = "auth"
+	// GroupPublic runs for the read endpoints
+	GroupPublic = "public"
+	// GroupWrite runs for the endpoints adding and modifying songs
+	GroupWrite = "write"
+	// GroupSubmit runs for single song submissions. Public deployments may accept anonymous submissions
+	// without opening the other write endpoints, e.g. with "ratelimit,auth-optional,captcha,timeout".
+	GroupSubmit = "submit"
+	// GroupImport runs for the file import endpoints, which may outlast the request timeout on large files
+	GroupImport = "import"
+	// GroupExport runs for the catalog download endpoints, which stream for as long as the catalog takes to read
+	GroupExport = "export"
+	// GroupEvents runs for the WebSocket and server-sent event feeds of catalog changes, whose connections stay
+	// open for as long as the client listens, so neither the request timeout nor compression applies
+	GroupEvents = "events"
+	// GroupDestructive runs for the endpoints deleting songs
+	GroupDestructive = "destructive"
+	// GroupAccount runs for the endpoints managing the authenticated user's own account
+	GroupAccount = "account"
+	// GroupWebhooks runs for the endpoints registering webhooks and reading their deliveries
+	GroupWebhooks = "webhooks"
+	// GroupAdmin runs for the /admin endpoints
+	GroupAdmin = "admin"
+)
+
+// Chains maps a route group to the names of its middlewares, in the order they run
+type Chains map[string][]string
+
+// DefaultChains are the chains used for groups without a MIDDLEWARE_<GROUP> override
+var DefaultChains = Chains{
+	GroupGlobal:      {NameRecovery, NameRequestID, NameLogger, NameMetrics, NamePrometheus, NameCORS},
+	GroupAuth:        {NameRateLimit, NameTimeout},
+	GroupPublic:      {NameRateLimit, NameAuth, NameViewer, NameCompression, NameTimeout},
+	GroupWrite:       {NameRateLimit, NameAuth, NameEditor, NameReadOnly, NameTimeout},
+	GroupSubmit:      {NameRateLimit, NameAuth, NameEditor, NameReadOnly, NameTimeout},
+	GroupImport:      {NameRateLimit, NameAuth, NameEditor, NameReadOnly},
+	GroupExport:      {NameRateLimit, NameAuth, NameViewer, NameCompression},
+	GroupEvents:      {NameRateLimit, NameAuth, NameViewer},
+	GroupDestructive: {NameRateLimit, NameAuth, NameOwner, NameReadOnly, NameTimeout},
+	GroupAccount:     {NameRateLimit, NameAuth, NameTimeout},
+	GroupWebhooks:    {NameRateLimit, NameAuth, NameOwner, NameReadOnly, NameTimeout},
+	GroupAdmin:       {NameAdmin, NameCompression},
+}
+
+// ChainsFromEnv returns the default chains, with the chain of a group replaced by the comma separated
+// names in MIDDLEWARE_<GROUP> when it is set. "none" leaves the group without middlewares.
+func ChainsFromEnv() Chains {
+	chains := make(Chains, len(DefaultChains))
+	for group, names := range DefaultChains {
+		chains[group] = names
+		value, ok := os.LookupEnv("MIDDLEWARE_" + strings.ToUpper(group))
+		if !ok {
+			continue
+		}
+		chains[group] = nil
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" && name != "none" {
+				chains[group] = append(chains[group], name)
+			}
+		}
+	}
+	return chains
+}
+
+// Registry holds the configured middlewares by name, so chains can be assembled from configuration.
+// Every middleware is created once and shared by the chains using it.
+type Registry struct {
+	middlewares map[string]gin.HandlerFunc
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{middlewares: make(map[string]gin.HandlerFunc)}
+}
+
+// Register adds a middleware under the name, replacing any middleware registered under it before
+func (r *Registry) Register(name string, middleware gin.HandlerFunc) {
+	r.middlewares[name] = middleware
+}
+
+// Chain returns the named middlewares in order, failing on the first name that is not registered
+func (r *Registry) Chain(names []string) ([]gin.HandlerFunc, error) {
+	chain := make([]gin.HandlerFunc, 0, len(names))
+	for _, name := range names {
+		middleware, ok := r.middlewares[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownMiddleware, name)
+		}
+		chain = append(chain, middleware)
+	}
+	return chain, nil
+}
+
+// Build returns the chain of every group, failing when any of them names an unregistered middleware
+func (r *Registry) Build(chains Chains) (map[string][]gin.HandlerFunc, error) {
+	built := make(map[string][]gin.HandlerFunc, len(chains))
+	for group, names := range chains {
+		chain, err := r.Chain(names)
+		if err != nil {
+			return nil, fmt.Errorf("middleware chain %s: %w", group, err)
+		}
+		built[group] = chain
+	}
+	return built, nil
+}
@@ -0,0 +1,315 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/auth"
+	"music-library/internal/captcha"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"music-library/internal/tracing"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serve runs a request through a router with the middlewares in front of a handler responding "ok"
+func serve(req *http.Request, middlewares ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Any("/songs", append(middlewares, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetInt(ContextUserID), "message": "ok"})
+	})...)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRegistryChainOrder(t *testing.T) {
+	var order []string
+	record := func(name string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			order = append(order, name)
+			c.Next()
+		}
+	}
+	registry := NewRegistry()
+	registry.Register("first", record("first"))
+	registry.Register("second", record("second"))
+
+	chains, err := registry.Build(Chains{GroupPublic: {"second", "first"}})
+	require.NoError(t, err)
+	serve(httptest.NewRequest(http.MethodGet, "/songs", nil), chains[GroupPublic]...)
+	assert.Equal(t, []string{"second", "first"}, order, "middlewares run in the configured order")
+
+	_, err = registry.Build(Chains{GroupWrite: {"first", "missing"}})
+	assert.ErrorIs(t, err, ErrUnknownMiddleware)
+}
+
+func TestChainsFromEnv(t *testing.T) {
+	t.Setenv("MIDDLEWARE_PUBLIC", " compression , logger")
+	t.Setenv("MIDDLEWARE_ADMIN", "none")
+	chains := ChainsFromEnv()
+	assert.Equal(t, []string{NameCompression, NameLogger}, chains[GroupPublic])
+	assert.Empty(t, chains[GroupAdmin])
+	assert.Equal(t, DefaultChains[GroupWrite], chains[GroupWrite])
+}
+
+func TestRequireUser(t *testing.T) {
+	tokens := auth.NewTokens(auth.Config{Secret: []byte("test-secret-test-secret-test-secret")})
+	pair, err := tokens.Issue(7, "alice", models.RoleEditor)
+	require.NoError(t, err)
+	request := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/songs", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return serve(req, RequireUser(tokens, zap.NewNop()))
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request("").Code)
+	assert.Equal(t, http.StatusUnauthorized, request("Bearer "+pair.RefreshToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, request("Bearer not-a-token").Code)
+
+	w := request("Bearer " + pair.AccessToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id": 7, "message": "ok"}`, w.Body.String())
+
+	optional := OptionalUser(tokens, zap.NewNop())
+	w = serve(httptest.NewRequest(http.MethodPost, "/songs", nil), optional)
+	assert.JSONEq(t, `{"user_id": 0, "message": "ok"}`, w.Body.String(), "anonymous requests pass")
+	req := httptest.NewRequest(http.MethodPost, "/songs", nil)
+	req.Header.Set("Authorization", "Bearer not-a-token")
+	assert.Equal(t, http.StatusUnauthorized, serve(req, optional).Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/songs?access_token="+pair.AccessToken, nil)
+	assert.Equal(t, http.StatusUnauthorized, serve(req, RequireUser(tokens, zap.NewNop())).Code,
+		"the query token is only taken from WebSocket handshakes")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	w = serve(req, RequireUser(tokens, zap.NewNop()))
+	assert.JSONEq(t, `{"user_id": 7, "message": "ok"}`, w.Body.String())
+}
+
+func TestRequireRole(t *testing.T) {
+	request := func(role string, required string) int {
+		setRole := func(c *gin.Context) {
+			if role != "" {
+				c.Set(ContextRole, role)
+			}
+		}
+		return serve(httptest.NewRequest(http.MethodDelete, "/songs", nil), setRole, RequireRole(required, zap.NewNop())).Code
+	}
+
+	assert.Equal(t, http.StatusOK, request(models.RoleAdmin, models.RoleAdmin))
+	assert.Equal(t, http.StatusOK, request(models.RoleAdmin, models.RoleViewer), "roles include the permissions of lesser roles")
+	assert.Equal(t, http.StatusOK, request(models.RoleEditor, models.RoleEditor))
+	assert.Equal(t, http.StatusForbidden, request(models.RoleEditor, models.RoleAdmin))
+	assert.Equal(t, http.StatusForbidden, request(models.RoleViewer, models.RoleEditor))
+	assert.Equal(t, http.StatusForbidden, request("superuser", models.RoleViewer))
+	assert.Equal(t, http.StatusUnauthorized, request("", models.RoleViewer))
+}
+
+// stubVerifier accepts the token "solved" and cannot be reached for "unreachable"
+type stubVerifier struct{}
+
+func (stubVerifier) Name() string {
+	return captcha.ProviderHCaptcha
+}
+
+func (stubVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	switch token {
+	case "solved":
+		return nil
+	case "unreachable":
+		return errors.New("connection refused")
+	default:
+		return captcha.ErrRejected
+	}
+}
+
+func TestRequireHuman(t *testing.T) {
+	request := func(token string, authenticated bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/songs", nil)
+		if token != "" {
+			req.Header.Set(CaptchaHeader, token)
+		}
+		setUser := func(c *gin.Context) {
+			if authenticated {
+				c.Set(ContextUserID, 7)
+			}
+		}
+		return serve(req, setUser, RequireHuman(stubVerifier{}, zap.NewNop()))
+	}
+
+	w := request("", false)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "Verification token required in the X-Captcha-Token header", "code": "captcha_required", "provider": "hcaptcha"}`, w.Body.String())
+	assert.Equal(t, http.StatusForbidden, request("guessed", false).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, request("unreachable", false).Code)
+	assert.Equal(t, http.StatusOK, request("solved", false).Code)
+	assert.Equal(t, http.StatusOK, request("", true).Code, "authenticated users are not challenged")
+}
+
+func TestRateLimit(t *testing.T) {
+	limit := RateLimit(RateLimitConfig{PerSecond: 0.001, Burst: 2}, zap.NewNop())
+	request := func(ip string) int {
+		req := httptest.NewRequest(http.MethodGet, "/songs", nil)
+		req.RemoteAddr = ip + ":1234"
+		return serve(req, limit).Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("10.0.0.1"))
+	assert.Equal(t, http.StatusOK, request("10.0.0.1"))
+	assert.Equal(t, http.StatusTooManyRequests, request("10.0.0.1"))
+	assert.Equal(t, http.StatusOK, request("10.0.0.2"), "clients are limited separately")
+}
+
+func TestRateLimiterConfigure(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{PerSecond: 0.001, Burst: 1}, zap.NewNop())
+	request := func(ip string) int {
+		req := httptest.NewRequest(http.MethodGet, "/songs", nil)
+		req.RemoteAddr = ip + ":1234"
+		return serve(req, limiter.Middleware()).Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("10.0.0.1"))
+	assert.Equal(t, http.StatusTooManyRequests, request("10.0.0.1"))
+
+	limiter.Configure(RateLimitConfig{PerSecond: 1000, Burst: 3})
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, request("10.0.0.2"), "new clients get the new burst")
+	}
+	assert.Eventually(t, func() bool { return request("10.0.0.1") == http.StatusOK }, time.Second, 10*time.Millisecond,
+		"clients already seen get the new rate")
+}
+
+func TestReadOnly(t *testing.T) {
+	degraded := false
+	readOnly := ReadOnly(func() bool { return degraded }, zap.NewNop())
+
+	assert.Equal(t, http.StatusOK, serve(httptest.NewRequest(http.MethodPost, "/songs", nil), readOnly).Code)
+	degraded = true
+	assert.Equal(t, http.StatusServiceUnavailable, serve(httptest.NewRequest(http.MethodPost, "/songs", nil), readOnly).Code)
+}
+
+func TestCORS(t *testing.T) {
+	cors := CORS([]string{"https://app.example.com"})
+
+	req := httptest.NewRequest(http.MethodOptions, "/songs", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	w := serve(req, cors)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+
+	req = httptest.NewRequest(http.MethodGet, "/songs", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w = serve(req, cors)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	r := gin.New()
+	r.GET("/songs", RequestID(), func(c *gin.Context) {
+		seen = tracing.RequestID(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/songs", nil)
+	req.Header.Set(tracing.HeaderRequestID, "client-id")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "client-id", w.Header().Get(tracing.HeaderRequestID))
+	assert.Equal(t, "client-id", seen)
+
+	req = httptest.NewRequest(http.MethodGet, "/songs", nil)
+	req.Header.Set(tracing.HeaderRequestID, "not valid")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Len(t, w.Header().Get(tracing.HeaderRequestID), 32)
+	assert.Equal(t, w.Header().Get(tracing.HeaderRequestID), seen)
+}
+
+func TestCompression(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/songs", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	w := serve(req, Compression())
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	reader, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user_id": 0, "message": "ok"}`, string(body))
+
+	w = serve(httptest.NewRequest(http.MethodGet, "/songs", nil), Compression())
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.JSONEq(t, `{"user_id": 0, "message": "ok"}`, w.Body.String())
+}
+
+func TestRecoveryAndTimeout(t *testing.T) {
+	r := gin.New()
+	r.Use(Recovery(zap.NewNop()), Timeout(10*time.Millisecond))
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+	r.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": c.Request.Context().Err().Error()})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error": "Internal server error"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code, "the request context is cancelled after the timeout")
+
+	r.GET("/stream", func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		c.JSON(http.StatusOK, gin.H{"deadline": hasDeadline})
+	})
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	req.Header.Set("Accept", ContentTypeNDJSON)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.JSONEq(t, `{"deadline": false}`, w.Body.String(), "streams are bounded by the connection")
+
+	r.GET("/abort", func(c *gin.Context) { panic(http.ErrAbortHandler) })
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	}, "aborts are left to the server")
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics()
+	serve(httptest.NewRequest(http.MethodGet, "/songs", nil), metrics.Middleware())
+	serve(httptest.NewRequest(http.MethodGet, "/songs", nil), metrics.Middleware())
+
+	snapshot := metrics.Snapshot()
+	require.Len(t, snapshot, 1)
+	assert.Equal(t, "/songs", snapshot[0].Route)
+	assert.Equal(t, int64(2), snapshot[0].Requests)
+	assert.Equal(t, int64(2), snapshot[0].Statuses[http.StatusOK])
+}
+
+func TestPrometheus(t *testing.T) {
+	before := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/songs", "200"))
+	serve(httptest.NewRequest(http.MethodGet, "/songs", nil), Prometheus())
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/songs", "200")))
+}
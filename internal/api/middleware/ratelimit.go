@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitConfig is the request rate each client is allowed
+type RateLimitConfig struct {
+	PerSecond float64
+	Burst     int
+}
+
+// DefaultRateLimitConfig is used when no rate limit is configured
+var DefaultRateLimitConfig = RateLimitConfig{PerSecond: 20, Burst: 40}
+
+// clientIdleAfter is how long a client's limiter is kept after its last request
+const clientIdleAfter = 10 * time.Minute
+
+// client is the limiter of a single client IP
+type client struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter limits the request rate of every client IP. Its rate can be changed while it serves.
+type RateLimiter struct {
+	logger *zap.Logger
+
+	mu        sync.Mutex
+	cfg       RateLimitConfig
+	clients   map[string]*client
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a rate limiter allowing each client the configured rate
+func NewRateLimiter(cfg RateLimitConfig, logger *zap.Logger) *RateLimiter {
+	l := &RateLimiter{logger: logger, clients: make(map[string]*client), lastSweep: time.Now()}
+	l.Configure(cfg)
+	return l
+}
+
+// Configure replaces the rate allowed to each client, including the clients already seen, which keep the
+// tokens they have left up to the new burst
+func (l *RateLimiter) Configure(cfg RateLimitConfig) {
+	if cfg.PerSecond <= 0 {
+		cfg.PerSecond = DefaultRateLimitConfig.PerSecond
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = DefaultRateLimitConfig.Burst
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cfg = cfg
+	for _, cl := range l.clients {
+		cl.limiter.SetLimit(rate.Limit(cfg.PerSecond))
+		cl.limiter.SetBurst(cfg.Burst)
+	}
+}
+
+// Middleware returns the middleware responding with 429 Too Many Requests once a client exceeds its rate
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		now := time.Now()
+
+		l.mu.Lock()
+		if now.Sub(l.lastSweep) > clientIdleAfter {
+			for key, seen := range l.clients {
+				if now.Sub(seen.lastSeen) > clientIdleAfter {
+					delete(l.clients, key)
+				}
+			}
+			l.lastSweep = now
+		}
+		cl, ok := l.clients[ip]
+		if !ok {
+			cl = &client{limiter: rate.NewLimiter(rate.Limit(l.cfg.PerSecond), l.cfg.Burst)}
+			l.clients[ip] = cl
+		}
+		cl.lastSeen = now
+		allowed := cl.limiter.Allow()
+		l.mu.Unlock()
+
+		if !allowed {
+			l.logger.Warn("Rate limit exceeded", zap.String("client_ip", ip), zap.String("path", c.FullPath()))
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimit returns a middleware that limits the request rate of every client IP,
+// responding with 429 Too Many Requests once a client exceeds it
+func RateLimit(cfg RateLimitConfig, logger *zap.Logger) gin.HandlerFunc {
+	return NewRateLimiter(cfg, logger).Middleware()
+}
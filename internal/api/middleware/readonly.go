@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ReadOnly returns a middleware that responds with 503 Service Unavailable while degraded reports the
+// database unreachable, so writes fail at once instead of waiting out connection timeouts
+func ReadOnly(degraded func() bool, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if degraded() {
+			logger.Warn("Rejected write while degraded", zap.String("path", c.FullPath()))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Database unavailable, the service is read-only"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-library/internal/tracing"
+)
+
+// RequestID returns a middleware identifying every request by the X-Request-ID header of the client, or
+// by a new ID when the client sent none or an unusable one. The ID is echoed in the response and carried,
+// with the trace of the client's traceparent header, by the request context, so the calls made to
+// enrichment providers while handling the request are sent the same headers.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(tracing.HeaderRequestID)
+		if !tracing.ValidRequestID(id) {
+			id = tracing.NewRequestID()
+		}
+		c.Header(tracing.HeaderRequestID, id)
+		ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+		c.Request = c.Request.WithContext(tracing.WithRequestID(ctx, id))
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTimeout bounds requests when no timeout is configured
+const DefaultTimeout = 30 * time.Second
+
+// ContentTypeNDJSON is the media type of responses streaming one JSON value per line
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// ContentTypeEventStream is the media type of server-sent event streams
+const ContentTypeEventStream = "text/event-stream"
+
+// streamingTypes are the media types of responses that stream for as long as their data lasts.
+// Requests accepting them are bounded by the client connection rather than the request timeout.
+var streamingTypes = []string{ContentTypeNDJSON, ContentTypeEventStream}
+
+// Timeout returns a middleware that cancels the request context once the timeout passes,
+// so database queries and external calls made with it are abandoned
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return func(c *gin.Context) {
+		if acceptsStream(c) {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// acceptsStream reports whether the request asks for a streamed response
+func acceptsStream(c *gin.Context) bool {
+	accept := c.GetHeader("Accept")
+	for _, contentType := range streamingTypes {
+		if strings.Contains(accept, contentType) {
+			return true
+		}
+	}
+	return false
+}
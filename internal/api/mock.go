@@ -0,0 +1,369 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	"music-library/docs"
+	"music-library/internal/api/middleware"
+	"music-library/internal/changes"
+	"music-library/internal/models"
+	"music-library/internal/service"
+)
+
+// exampleCalendar is the iCalendar feed returned by the mock calendar endpoint
+const exampleCalendar = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Music Library//Release Anniversaries//EN\r\n" +
+	"BEGIN:VEVENT\r\nUID:song-1-release@music-library\r\nDTSTAMP:20240115T120000Z\r\nDTSTART;VALUE=DATE:20060716\r\n" +
+	"RRULE:FREQ=YEARLY\r\nSUMMARY:Muse – Supermassive Black Hole (released 2006)\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+// exampleSubtitles is the SRT file returned by the mock subtitles endpoint
+const exampleSubtitles = "1\n00:00:12,340 --> 00:00:15,000\nOoh baby, don't you know I suffer?\n\n" +
+	"2\n00:00:15,000 --> 00:00:20,000\nOoh baby, can you hear me moan?\n\n"
+
+// exampleExport is the CSV file returned by the mock export endpoint
+const exampleExport = "group,song,release_date,text,link,id\n" +
+	"Muse,Supermassive Black Hole,16.07.2006,\"Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\"," +
+	"https://www.youtube.com/watch?v=Xsp3_a-PMTw,1\n"
+
+// exampleFacets are the facets of the mock song listing, which the documentation leaves without example
+var exampleFacets = map[string][]models.FacetBucket{
+	"year":   {{Value: "2006", Count: 1}},
+	"decade": {{Value: "2000s", Count: 1}},
+	"group":  {{Value: "Muse", Count: 1}},
+}
+
+// mockXMLTypes are the types the examples of the endpoints producing XML are decoded into to be rendered,
+// by swagger definition name
+var mockXMLTypes = map[string]reflect.Type{
+	"models.Album":            reflect.TypeOf(models.Album{}),
+	"models.AlbumPage":        reflect.TypeOf(models.AlbumPage{}),
+	"models.Artist":           reflect.TypeOf(models.Artist{}),
+	"models.ArtistPage":       reflect.TypeOf(models.ArtistPage{}),
+	"models.Digest":           reflect.TypeOf(models.Digest{}),
+	"models.Genre":            reflect.TypeOf(models.Genre{}),
+	"models.GenreList":        reflect.TypeOf(models.GenreList{}),
+	"models.GroupStats":       reflect.TypeOf(models.GroupStats{}),
+	"models.Song":             reflect.TypeOf(models.Song{}),
+	"models.SongGenres":       reflect.TypeOf(models.SongGenres{}),
+	"models.SongPage":         reflect.TypeOf(models.SongPage{}),
+	"models.SongRevision":     reflect.TypeOf(models.SongRevision{}),
+	"models.SongRevisionList": reflect.TypeOf(models.SongRevisionList{}),
+	"models.SongTitle":        reflect.TypeOf(models.SongTitle{}),
+	"models.SongTitles":       reflect.TypeOf(models.SongTitles{}),
+	"models.TrashPage":        reflect.TypeOf(models.TrashPage{}),
+	"models.TrendingSong":     reflect.TypeOf(models.TrendingSong{}),
+	"service.VersePage":       reflect.TypeOf(service.VersePage{}),
+}
+
+// mockOverrides build the mock handlers of the endpoints whose response is not JSON, or changes shape with
+// the request, from the example of their documented response. They are keyed by method and swagger path.
+var mockOverrides = map[string]func(example []byte) (gin.HandlerFunc, error){
+	"GET /songs":                mockSongs,
+	"HEAD /songs":               mockCountSongs,
+	"GET /songs/search":         mockSearch,
+	"GET /songs/export":         mockFile(exportContentTypes[service.ExportFormatCSV], `attachment; filename="songs-20240115.csv"`, exampleExport),
+	"GET /songs/{id}/subtitles": mockFile(subtitleContentTypes[service.SubtitleFormatSRT], `attachment; filename="song-1.srt"`, exampleSubtitles),
+	"GET /calendar.ics":         mockFile("text/calendar; charset=utf-8", `inline; filename="calendar.ics"`, exampleCalendar),
+	"GET /ws/events":            mockEvents,
+	"GET /songs/stream":         mockEventStream,
+}
+
+// mockSpec is the part of the swagger documentation the mock server is built from
+type mockSpec struct {
+	Paths       map[string]map[string]mockOperation `json:"paths"`
+	Definitions map[string]*mockSchema              `json:"definitions"`
+}
+
+// mockOperation is a documented operation
+type mockOperation struct {
+	Produces  []string                `json:"produces"`
+	Responses map[string]mockResponse `json:"responses"`
+}
+
+// mockResponse is a documented response of an operation
+type mockResponse struct {
+	Schema *mockSchema `json:"schema"`
+}
+
+// mockSchema is a documented body, or a part of it
+type mockSchema struct {
+	Ref        string                 `json:"$ref"`
+	Type       string                 `json:"type"`
+	Properties map[string]*mockSchema `json:"properties"`
+	Items      *mockSchema            `json:"items"`
+	AllOf      []*mockSchema          `json:"allOf"`
+	Example    json.RawMessage        `json:"example"`
+}
+
+// RegisterMockRoutes registers every endpoint of the swagger documentation with the example of its
+// success response, so clients can be developed without a database or external API
+func RegisterMockRoutes(r gin.IRouter, logger *zap.Logger) {
+	logger.Info("Registering mock routes")
+
+	var spec mockSpec
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &spec); err != nil {
+		// The documentation is generated with the code, so it can only be broken by a bad generation
+		panic(fmt.Sprintf("invalid swagger documentation: %v", err))
+	}
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		methods := make([]string, 0, len(spec.Paths[path]))
+		for method := range spec.Paths[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			method := strings.ToUpper(method)
+			handler, err := spec.mockHandler(method, path, spec.Paths[path][strings.ToLower(method)])
+			if err != nil {
+				panic(fmt.Sprintf("mock %s %s: %v", method, path, err))
+			}
+			r.Handle(method, ginPath(path), handler)
+		}
+	}
+}
+
+// ginPath converts the {name} parameters of a swagger path to the :name parameters of gin
+func ginPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			segments[i] = ":" + strings.TrimSuffix(name, "}")
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// mockHandler builds the handler responding to the operation with the example of its first success response
+func (s *mockSpec) mockHandler(method, path string, operation mockOperation) (gin.HandlerFunc, error) {
+	status, response, err := successResponse(operation)
+	if err != nil {
+		return nil, err
+	}
+	var example []byte
+	if response.Schema != nil {
+		if example, err = json.Marshal(s.example(response.Schema, map[string]bool{})); err != nil {
+			return nil, err
+		}
+	}
+	if override, ok := mockOverrides[method+" "+path]; ok {
+		return override(example)
+	}
+	if example == nil {
+		return func(c *gin.Context) {
+			c.Status(status)
+		}, nil
+	}
+	if !producesXML(operation) {
+		return func(c *gin.Context) {
+			c.Data(status, gin.MIMEJSON+"; charset=utf-8", example)
+		}, nil
+	}
+
+	payload, err := s.decodeExample(response.Schema, example)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *gin.Context) {
+		if wantsXML(c) {
+			render(c, status, payload)
+			return
+		}
+		varyOnAccept(c)
+		c.Data(status, gin.MIMEJSON+"; charset=utf-8", example)
+	}, nil
+}
+
+// successResponse returns the documented response of the operation with the lowest 1xx or 2xx status
+func successResponse(operation mockOperation) (int, mockResponse, error) {
+	status := 0
+	for code := range operation.Responses {
+		if n, err := strconv.Atoi(code); err == nil && n < 300 && (status == 0 || n < status) {
+			status = n
+		}
+	}
+	if status == 0 {
+		return 0, mockResponse{}, fmt.Errorf("no success response documented")
+	}
+	return status, operation.Responses[strconv.Itoa(status)], nil
+}
+
+// producesXML reports whether the operation is documented to respond with XML
+func producesXML(operation mockOperation) bool {
+	for _, mime := range operation.Produces {
+		if mime == gin.MIMEXML || mime == gin.MIMEXML2 {
+			return true
+		}
+	}
+	return false
+}
+
+// example builds the example value of the schema from the examples of its fields. Fields without an
+// example are left out, and arrays get a single item. It returns nil when nothing in the schema has an example.
+func (s *mockSpec) example(schema *mockSchema, seen map[string]bool) any {
+	if schema == nil {
+		return nil
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/definitions/")
+		// A recursive type, like a genre and its subgenres, ends at its first repetition
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		defer delete(seen, name)
+		return s.example(s.Definitions[name], seen)
+	}
+	switch {
+	case len(schema.AllOf) > 0:
+		merged := make(map[string]any)
+		for _, part := range schema.AllOf {
+			if object, ok := s.example(part, seen).(map[string]any); ok {
+				maps.Copy(merged, object)
+			}
+		}
+		return merged
+	case schema.Type == "array":
+		if item := s.example(schema.Items, seen); item != nil {
+			return []any{item}
+		}
+		return []any{}
+	case schema.Type == "object":
+		object := make(map[string]any)
+		for name, property := range schema.Properties {
+			if value := s.example(property, seen); value != nil {
+				object[name] = value
+			}
+		}
+		return object
+	}
+	return nil
+}
+
+// decodeExample decodes the example of a response into its Go type to render it as XML
+func (s *mockSpec) decodeExample(schema *mockSchema, example []byte) (any, error) {
+	name, list := schema.Ref, false
+	if schema.Type == "array" && schema.Items != nil {
+		name, list = schema.Items.Ref, true
+	}
+	name = strings.TrimPrefix(name, "#/definitions/")
+	payloadType, ok := mockXMLTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("no type to render %q as XML", name)
+	}
+	if list {
+		payloadType = reflect.SliceOf(payloadType)
+	}
+	payload := reflect.New(payloadType)
+	if err := json.Unmarshal(example, payload.Interface()); err != nil {
+		return nil, err
+	}
+	return payload.Elem().Interface(), nil
+}
+
+// mockSongs serves the example page of songs in the shapes GetSongs negotiates: streamed as NDJSON,
+// with facets when they are asked for, and in the legacy shape for legacy compat clients
+func mockSongs(example []byte) (gin.HandlerFunc, error) {
+	var page models.SongPage
+	if err := json.Unmarshal(example, &page); err != nil {
+		return nil, err
+	}
+	// The facets are only listed when they are asked for
+	page.Facets = nil
+	return func(c *gin.Context) {
+		page := page
+		if c.Query("facets") != "" {
+			page.Facets = exampleFacets
+		}
+		varyOnAccept(c)
+		if wantsStream(c) {
+			var lines []byte
+			for _, song := range page.Data {
+				line, _ := songLine(song, nil)
+				lines = append(lines, line...)
+			}
+			c.Data(http.StatusOK, middleware.ContentTypeNDJSON, lines)
+			return
+		}
+		c.Header("X-Total-Count", strconv.Itoa(page.Total))
+		if !wantsXML(c) && legacyResponses(c, models.Preferences{}) {
+			c.JSON(http.StatusOK, legacySongPage(page))
+			return
+		}
+		render(c, http.StatusOK, page)
+	}, nil
+}
+
+// mockCountSongs reports a single song in X-Total-Count, like the example listing
+func mockCountSongs([]byte) (gin.HandlerFunc, error) {
+	return func(c *gin.Context) {
+		c.Header("X-Total-Count", "1")
+		c.Status(http.StatusOK)
+	}, nil
+}
+
+// mockSearch serves the example search results, as the bare array for legacy compat clients
+func mockSearch(example []byte) (gin.HandlerFunc, error) {
+	var page models.SearchPage
+	if err := json.Unmarshal(example, &page); err != nil {
+		return nil, err
+	}
+	return func(c *gin.Context) {
+		if !wantsXML(c) && legacyResponses(c, models.Preferences{}) {
+			c.JSON(http.StatusOK, page.Data)
+			return
+		}
+		render(c, http.StatusOK, page)
+	}, nil
+}
+
+// mockFile returns the builder of a handler serving a file the documentation has no example of
+func mockFile(contentType, disposition, content string) func([]byte) (gin.HandlerFunc, error) {
+	return func([]byte) (gin.HandlerFunc, error) {
+		return func(c *gin.Context) {
+			c.Header("Content-Disposition", disposition)
+			c.Data(http.StatusOK, contentType, []byte(content))
+		}, nil
+	}
+}
+
+// mockEvents pushes the example catalog event over a WebSocket connection, then closes it
+func mockEvents(example []byte) (gin.HandlerFunc, error) {
+	return func(c *gin.Context) {
+		conn, err := eventsUpgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, example)
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}, nil
+}
+
+// mockEventStream sends the example catalog event as a server-sent event
+func mockEventStream(example []byte) (gin.HandlerFunc, error) {
+	var event changes.Event
+	if err := json.Unmarshal(example, &event); err != nil {
+		return nil, err
+	}
+	return func(c *gin.Context) {
+		c.Header("Content-Type", middleware.ContentTypeEventStream)
+		c.String(http.StatusOK, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, example)
+	}, nil
+}
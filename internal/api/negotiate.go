@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/xml"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"music-library/internal/models"
+)
+
+// xmlList is the root element of a list rendered as XML, which unlike JSON needs a single root
+type xmlList struct {
+	XMLName xml.Name `xml:"songs"`
+	Items   any
+}
+
+// songFieldIndexes maps each type holding song fields, the song itself and the content of its revisions,
+// to the index of every field by its JSON name
+var songFieldIndexes = map[reflect.Type]map[string]int{
+	reflect.TypeOf(models.Song{}):             fieldIndexes(reflect.TypeOf(models.Song{})),
+	reflect.TypeOf(models.SongRevisionData{}): fieldIndexes(reflect.TypeOf(models.SongRevisionData{})),
+}
+
+// fieldIndexes maps the JSON name of every field of the struct type to its index
+func fieldIndexes(structType reflect.Type) map[string]int {
+	indexes := make(map[string]int)
+	for i := 0; i < structType.NumField(); i++ {
+		if name := jsonName(structType.Field(i)); name != "" {
+			indexes[name] = i
+		}
+	}
+	return indexes
+}
+
+// wantsXML reports whether the request prefers XML to JSON by its Accept header. JSON stays the
+// default for a missing header or a wildcard.
+func wantsXML(c *gin.Context) bool {
+	switch c.NegotiateFormat(gin.MIMEJSON, gin.MIMEXML, gin.MIMEXML2) {
+	case gin.MIMEXML, gin.MIMEXML2:
+		return true
+	}
+	return false
+}
+
+// varyOnAccept records that the response depends on the Accept header
+func varyOnAccept(c *gin.Context) {
+	for _, value := range c.Writer.Header().Values("Vary") {
+		if value == "Accept" {
+			return
+		}
+	}
+	c.Writer.Header().Add("Vary", "Accept")
+}
+
+// render responds with the payload as XML when the request asks for it, as JSON otherwise
+func render(c *gin.Context, status int, payload any) {
+	varyOnAccept(c)
+	if wantsXML(c) {
+		c.XML(status, xmlPayload(payload))
+		return
+	}
+	c.JSON(status, payload)
+}
+
+// xmlPayload wraps a top-level list in a root element
+func xmlPayload(payload any) any {
+	if reflect.ValueOf(payload).Kind() == reflect.Slice {
+		return xmlList{Items: payload}
+	}
+	return payload
+}
+
+// hideSongFields returns a copy of the payload with the hidden fields of every song in it zeroed, which
+// leaves them out of the XML. The payload itself is not changed, as it may be shared with a cache.
+func hideSongFields(payload any, hidden map[string]bool) any {
+	if len(hidden) == 0 || payload == nil {
+		return payload
+	}
+	return hideFields(reflect.ValueOf(payload), hidden).Interface()
+}
+
+// hideFields copies the value, zeroing the hidden fields of the songs it holds
+func hideFields(v reflect.Value, hidden map[string]bool) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Elem().Type())
+		copied.Elem().Set(hideFields(v.Elem(), hidden))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(hideFields(v.Elem(), hidden))
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(hideFields(v.Index(i), hidden))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), hideFields(iter.Value(), hidden))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		if indexes, ok := songFieldIndexes[v.Type()]; ok {
+			for field := range hidden {
+				if index, ok := indexes[field]; ok {
+					copied.Field(index).SetZero()
+				}
+			}
+			return copied
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if copied.Field(i).CanSet() {
+				copied.Field(i).Set(hideFields(v.Field(i), hidden))
+			}
+		}
+		return copied
+	}
+	return v
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/api/middleware"
+	"music-library/internal/service"
+)
+
+// GetSongOverride handles the request to retrieve the authenticated user's personal override of a song
+// @Summary Get a personal override
+// @Description Retrieve the authenticated user's override of a song's text
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} models.SongOverride
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/overrides/{id} [get]
+func (h *Handler) GetSongOverride(c *gin.Context) {
+	h.logger.Info("Handling GetSongOverride request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	userID := c.GetInt(middleware.ContextUserID)
+	override, err := h.svc.GetSongOverride(c.Request.Context(), userID, songID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Override not found"})
+			return
+		}
+		h.logger.Error("Failed to fetch song override", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, override)
+}
+
+// SongOverrideRequest defines the request body for a personal override of a song's text
+type SongOverrideRequest struct {
+	Text string `json:"text" example:"Paranoia is in bloom,\nThe PR transmissions will resume"`
+}
+
+// SaveSongOverride handles the request to create or replace the authenticated user's personal override of a
+// song's text. The song itself is left unchanged.
+// @Summary Save a personal override
+// @Description Create or replace the authenticated user's override of a song's text, leaving the song unchanged
+// @Tags account
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param override body SongOverrideRequest true "Personal text of the song"
+// @Success 200 {object} models.SongOverride
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/overrides/{id} [put]
+func (h *Handler) SaveSongOverride(c *gin.Context) {
+	h.logger.Info("Handling SaveSongOverride request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	var req SongOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt(middleware.ContextUserID)
+	override, err := h.svc.SaveSongOverride(c.Request.Context(), userID, songID, req.Text)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.logger.Warn("Song not found", zap.Int("song_id", songID))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidOverride) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to save song override", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Song override saved successfully", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	c.JSON(http.StatusOK, override)
+}
+
+// DeleteSongOverride handles the request to discard the authenticated user's personal override of a song
+// @Summary Delete a personal override
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/overrides/{id} [delete]
+func (h *Handler) DeleteSongOverride(c *gin.Context) {
+	h.logger.Info("Handling DeleteSongOverride request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	userID := c.GetInt(middleware.ContextUserID)
+	if err := h.svc.DeleteSongOverride(c.Request.Context(), userID, songID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Override not found"})
+			return
+		}
+		h.logger.Error("Failed to delete song override", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Song override deleted successfully", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	c.JSON(http.StatusOK, gin.H{"message": "Override deleted successfully"})
+}
+
+// songID parses the song ID of a request, responding with 400 when it is invalid
+func (h *Handler) songID(c *gin.Context) (int, bool) {
+	songIDStr := c.Param("id")
+	songID, err := strconv.Atoi(songIDStr)
+	if err != nil {
+		h.logger.Error("Invalid song ID", zap.String("song_id", songIDStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return 0, false
+	}
+	return songID, true
+}
+
+// personalUser returns the authenticated user whose personal overrides the request asks to see with
+// ?personal=true, or zero when it does not. It responds with 400 for an invalid value and with 401 when
+// the request is anonymous.
+func (h *Handler) personalUser(c *gin.Context) (int, bool) {
+	value := c.Query("personal")
+	if value == "" {
+		return 0, true
+	}
+	personal, err := strconv.ParseBool(value)
+	if err != nil {
+		h.logger.Warn("Invalid personal flag", zap.String("personal", value))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid personal: must be true or false"})
+		return 0, false
+	}
+	if !personal {
+		return 0, true
+	}
+	userID := c.GetInt(middleware.ContextUserID)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Personal overrides require authentication"})
+		return 0, false
+	}
+	return userID, true
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/api/middleware"
+	"music-library/internal/models"
+	"music-library/internal/service"
+)
+
+// GetPreferences handles the request to retrieve the preferences of the authenticated user
+// @Summary Get preferences
+// @Description Retrieve the preferences of the authenticated user
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.Preferences
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/preferences [get]
+func (h *Handler) GetPreferences(c *gin.Context) {
+	h.logger.Info("Handling GetPreferences request")
+
+	userID := c.GetInt(middleware.ContextUserID)
+	preferences, err := h.svc.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to fetch preferences", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences handles the request to replace the preferences of the authenticated user
+// @Summary Update preferences
+// @Description Replace the preferences of the authenticated user
+// @Tags account
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param preferences body models.Preferences true "Preferences"
+// @Success 200 {object} models.Preferences
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/preferences [put]
+func (h *Handler) UpdatePreferences(c *gin.Context) {
+	h.logger.Info("Handling UpdatePreferences request")
+
+	var req models.Preferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt(middleware.ContextUserID)
+	preferences, err := h.svc.UpdatePreferences(c.Request.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPreferences) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to update preferences", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Preferences updated successfully", zap.Int("user_id", userID))
+	c.JSON(http.StatusOK, preferences)
+}
+
+// userPreferences returns the preferences of the authenticated user, or no preferences for anonymous
+// requests. Failing to load them is logged and the request proceeds with the defaults.
+func (h *Handler) userPreferences(c *gin.Context) models.Preferences {
+	userID := c.GetInt(middleware.ContextUserID)
+	if userID == 0 {
+		return models.Preferences{}
+	}
+	preferences, err := h.svc.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Warn("Failed to load preferences, using defaults", zap.Int("user_id", userID), zap.Error(err))
+		return models.Preferences{}
+	}
+	return preferences
+}
+
+// preferredLimit is the page size used when the request has no limit: the user's preferred one, or the fallback
+func preferredLimit(preferences models.Preferences, fallback string) string {
+	if preferences.PageSize > 0 {
+		return strconv.Itoa(preferences.PageSize)
+	}
+	return fallback
+}
+
+// preferredSort is the sort used when the request has none: the user's preferred one, or the fallback
+func preferredSort(preferences models.Preferences, fallback string) string {
+	if preferences.Sort != "" {
+		return preferences.Sort
+	}
+	return fallback
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/api/middleware"
+	"music-library/internal/models"
+	"music-library/internal/service"
+)
+
+// RateSongRequest defines the request body for rating a song
+type RateSongRequest struct {
+	Rating int `json:"rating" minimum:"1" maximum:"5" example:"4"`
+}
+
+// RateSong handles the request to rate a song from 1 to 5 on behalf of the authenticated user, replacing
+// their previous rating. The response carries the song's new average rating.
+// @Summary Rate a song
+// @Description Rate a song from 1 to 5, replacing the previous rating of the authenticated user
+// @Tags ratings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param rating body RateSongRequest true "Rating"
+// @Success 200 {object} models.SongRating
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/rating [post]
+func (h *Handler) RateSong(c *gin.Context) {
+	h.logger.Info("Handling RateSong request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	var req RateSongRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetInt(middleware.ContextUserID)
+	rating, err := h.svc.RateSong(c.Request.Context(), userID, songID, req.Rating)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.logger.Warn("Song not found", zap.Int("song_id", songID))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidRating) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to rate song", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Song rated successfully", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	c.JSON(http.StatusOK, rating)
+}
+
+// DeleteSongRating handles the request to discard the authenticated user's rating of a song
+// @Summary Delete a rating
+// @Description Discard the authenticated user's rating of a song
+// @Tags ratings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} models.SongRating
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/rating [delete]
+func (h *Handler) DeleteSongRating(c *gin.Context) {
+	h.logger.Info("Handling DeleteSongRating request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	userID := c.GetInt(middleware.ContextUserID)
+	rating, err := h.svc.DeleteSongRating(c.Request.Context(), userID, songID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rating not found"})
+			return
+		}
+		h.logger.Error("Failed to delete song rating", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Song rating deleted successfully", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	c.JSON(http.StatusOK, rating)
+}
+
+// FavoriteSong handles the request to mark a song as one of the authenticated user's favorites
+// @Summary Favorite a song
+// @Tags ratings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} models.SongFavorite
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/favorite [post]
+func (h *Handler) FavoriteSong(c *gin.Context) {
+	h.logger.Info("Handling FavoriteSong request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	userID := c.GetInt(middleware.ContextUserID)
+	favorite, err := h.svc.FavoriteSong(c.Request.Context(), userID, songID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.logger.Warn("Song not found", zap.Int("song_id", songID))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+			return
+		}
+		h.logger.Error("Failed to mark song as favorite", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, favorite)
+}
+
+// UnfavoriteSong handles the request to remove a song from the authenticated user's favorites
+// @Summary Unfavorite a song
+// @Tags ratings
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/favorite [delete]
+func (h *Handler) UnfavoriteSong(c *gin.Context) {
+	h.logger.Info("Handling UnfavoriteSong request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	userID := c.GetInt(middleware.ContextUserID)
+	if err := h.svc.UnfavoriteSong(c.Request.Context(), userID, songID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Favorite not found"})
+			return
+		}
+		h.logger.Error("Failed to unmark song as favorite", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Favorite removed successfully"})
+}
+
+// ratingFilter reads ?favorites= and ?min_rating= into the filter, responding with 400 for invalid values
+// and with 401 when an anonymous request asks for its favorites
+func (h *Handler) ratingFilter(c *gin.Context, filter *models.SongFilter) bool {
+	if value := c.Query("favorites"); value != "" {
+		favorites, err := strconv.ParseBool(value)
+		if err != nil {
+			h.logger.Warn("Invalid favorites flag", zap.String("favorites", value))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid favorites: must be true or false"})
+			return false
+		}
+		if favorites {
+			filter.FavoritesOf = c.GetInt(middleware.ContextUserID)
+			if filter.FavoritesOf == 0 {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Favorites require authentication"})
+				return false
+			}
+		}
+	}
+	if value := c.Query("min_rating"); value != "" {
+		minRating, err := strconv.ParseFloat(value, 64)
+		if err != nil || minRating < models.MinRating || minRating > models.MaxRating {
+			h.logger.Warn("Invalid min_rating", zap.String("min_rating", value))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_rating: must be between 1 and 5"})
+			return false
+		}
+		filter.MinRating = minRating
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package api
+
+import "music-library/internal/config"
+
+// The types below describe, for the swagger documentation and the mock server built from it, the
+// response bodies the handlers write as gin.H
+
+// ErrorResponse defines the structure of an error response
+type ErrorResponse struct {
+	Error string `json:"error" example:"Song not found"`
+}
+
+// MessageResponse is the body confirming a change that returns nothing else
+type MessageResponse struct {
+	Message string `json:"message" example:"Song deleted successfully"`
+}
+
+// StatusResponse is the body of the health probes
+type StatusResponse struct {
+	Status string `json:"status" example:"ok"`
+}
+
+// AddSongResponse carries the ID of an added song, and its enrichment status while its details are
+// fetched in the background
+type AddSongResponse struct {
+	ID               int    `json:"id" example:"1"`
+	EnrichmentStatus string `json:"enrichment_status,omitempty"`
+}
+
+// SongExistsResponse reports whether a song exists, with its ID when it does
+type SongExistsResponse struct {
+	Exists bool `json:"exists" example:"true"`
+	ID     int  `json:"id,omitempty" example:"1"`
+}
+
+// TruncateResponse confirms a truncation, with the snapshot taken before it when one was asked for
+type TruncateResponse struct {
+	Message    string `json:"message" example:"Table truncated and sequence reset"`
+	SnapshotID int    `json:"snapshot_id,omitempty" example:"1"`
+}
+
+// SongTagsResponse lists the tags of a song
+type SongTagsResponse struct {
+	SongID int      `json:"song_id" example:"1"`
+	Tags   []string `json:"tags" example:"live,rock"`
+}
+
+// LegalHoldResponse reports the legal hold of a song
+type LegalHoldResponse struct {
+	ID        int  `json:"id" example:"1"`
+	LegalHold bool `json:"legal_hold" example:"true"`
+}
+
+// RestoreSnapshotResponse confirms a snapshot restore with the number of songs put back
+type RestoreSnapshotResponse struct {
+	Message  string `json:"message" example:"Snapshot restored successfully"`
+	Restored int    `json:"restored" example:"1"`
+}
+
+// ImportConfigResponse lists the changes an imported configuration document made, and whether some of
+// them only take effect on restart
+type ImportConfigResponse struct {
+	Changes         []config.Change `json:"changes"`
+	RestartRequired bool            `json:"restart_required" example:"true"`
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// GetSongRevisions handles the request to list the revisions of a song, newest first
+// @Summary Get the revisions of a song
+// @Description List the revisions of a song, newest first
+// @Tags revisions
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} models.SongRevisionList
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/revisions [get]
+func (h *Handler) GetSongRevisions(c *gin.Context) {
+	h.logger.Info("Handling GetSongRevisions request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	revisions, err := h.svc.GetSongRevisions(c.Request.Context(), songID)
+	if err != nil {
+		h.respondSongRevisionError(c, err, "Song not found")
+		return
+	}
+
+	h.logger.Info("Song revisions retrieved successfully", zap.Int("song_id", songID), zap.Int("count", len(revisions.Data)))
+	h.renderSongs(c, http.StatusOK, revisions)
+}
+
+// RestoreSongRevision handles the request to set a song back to one of its revisions
+// @Summary Restore a revision
+// @Description Set a song back to one of its revisions
+// @Tags revisions
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} models.SongRevision
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/revisions/{rev}/restore [post]
+func (h *Handler) RestoreSongRevision(c *gin.Context) {
+	h.logger.Info("Handling RestoreSongRevision request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	revision, err := strconv.Atoi(c.Param("rev"))
+	if err != nil || revision < 1 {
+		h.logger.Warn("Invalid revision", zap.String("revision", c.Param("rev")))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+		return
+	}
+	restored, err := h.svc.RestoreSongRevision(c.Request.Context(), songID, revision)
+	if err != nil {
+		h.respondSongRevisionError(c, err, "Revision not found")
+		return
+	}
+
+	h.logger.Info("Song revision restored successfully", zap.Int("song_id", songID), zap.Int("revision", revision))
+	h.renderSongs(c, http.StatusOK, restored)
+}
+
+// respondSongRevisionError responds to a failed revision request, with the message for a missing song or
+// revision
+func (h *Handler) respondSongRevisionError(c *gin.Context, err error, notFound string) {
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	case errors.Is(err, service.ErrLegalHold):
+		c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("Failed to handle song revisions", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
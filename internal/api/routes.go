@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"music-library/internal/routes"
+)
+
+// GetRoutes returns the handler of the route manifest, listing the HTTP routes and gRPC methods with the
+// issues the router self-test found at startup
+// @Summary Get the route manifest
+// @Description List the HTTP routes and gRPC methods with the issues the router self-test found at startup
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} routes.Manifest
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/routes [get]
+func (h *Handler) GetRoutes(manifest *routes.Manifest) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.logger.Info("Handling GetRoutes request")
+		c.JSON(http.StatusOK, manifest)
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/service"
+)
+
+// maxSearchLimit bounds the number of results of a single search
+const maxSearchLimit = 100
+
+// SearchSongs handles the request to search songs by keywords (the default) or by the meaning of their lyrics.
+// Results come in a data envelope, along with "did you mean" suggestions when nothing was found;
+// legacy compat clients get the bare array of results.
+// @Summary Search songs
+// @Description Search songs by keywords or by the meaning of their lyrics. Suggestions are returned when
+// @Description nothing was found; legacy compat clients get the bare array of results.
+// @Tags songs
+// @Produce json,xml
+// @Security BearerAuth
+// @Param q query string true "Search query"
+// @Param mode query string false "Search mode" Enums(keyword,semantic,hybrid) default(keyword)
+// @Param limit query int false "Maximum number of results" default(10) maximum(100)
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Param title_lang query string false "Comma separated languages to localize titles in, preferred over Accept-Language"
+// @Param X-API-Compat header string false "legacy for the response shapes from before the envelopes" Enums(legacy,current)
+// @Success 200 {object} models.SearchPage
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 501 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /songs/search [get]
+func (h *Handler) SearchSongs(c *gin.Context) {
+	h.logger.Info("Handling SearchSongs request")
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		h.logger.Warn("Missing search query")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter q is required"})
+		return
+	}
+	mode := c.DefaultQuery("mode", service.SearchModeKeyword)
+	preferences := h.userPreferences(c)
+	limitStr := c.DefaultQuery("limit", preferredLimit(preferences, "10"))
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > maxSearchLimit {
+		h.logger.Error("Invalid limit", zap.String("limit", limitStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return
+	}
+
+	ctx, markStale := withStaleness(c)
+	results, err := h.svc.SearchSongs(ctx, query, mode, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnsupportedSearchMode):
+			h.logger.Warn("Invalid search mode", zap.String("mode", mode))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mode"})
+		case errors.Is(err, service.ErrSemanticSearchUnavailable):
+			h.logger.Warn("Semantic search unavailable", zap.Error(err))
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrDegraded):
+			h.logger.Warn("Search unavailable while degraded", zap.String("query", query))
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Database unavailable"})
+		default:
+			h.logger.Error("Failed to search songs", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	localized := make([]*models.Song, len(results))
+	for i := range results {
+		localized[i] = &results[i].Song
+	}
+	h.localizeTitles(c, preferences, localized...)
+	for i := range results {
+		service.FormatSongDate(&results[i].Song, dateFormat)
+	}
+
+	h.logger.Info("Songs searched successfully", zap.Int("count", len(results)))
+	markStale()
+	// XML has no legacy shape
+	if !wantsXML(c) && legacyResponses(c, preferences) {
+		h.renderSongs(c, http.StatusOK, results)
+		return
+	}
+	page := models.SearchPage{Data: results}
+	if len(results) == 0 {
+		// Suggestions are best effort, a failure leaves the empty result as is
+		suggestions, err := h.svc.SuggestSearches(c.Request.Context(), query)
+		if err != nil {
+			h.logger.Warn("Failed to suggest searches", zap.Error(err))
+		}
+		page.Suggestions = suggestions
+	}
+	h.renderSongs(c, http.StatusOK, page)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// StartSimilarityReport handles the request to compute a new lyrics similarity report in a background job.
+// The job's status is available from GET /jobs/:id.
+// @Summary Start a similarity report
+// @Description Compute a new lyrics similarity report in a background job followed with GET /admin/jobs/{id}
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param threshold query number false "Minimum similarity of the reported pairs" default(0.8) minimum(0) maximum(1)
+// @Success 202 {object} models.SimilarityReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /admin/similarity-report [post]
+func (h *Handler) StartSimilarityReport(c *gin.Context) {
+	h.logger.Info("Handling StartSimilarityReport request")
+
+	thresholdStr := c.DefaultQuery("threshold", strconv.FormatFloat(service.DefaultSimilarityThreshold, 'f', -1, 64))
+	threshold, err := strconv.ParseFloat(thresholdStr, 64)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		h.logger.Error("Invalid threshold", zap.String("threshold", thresholdStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid threshold"})
+		return
+	}
+
+	report, err := h.svc.StartSimilarityReport(c.Request.Context(), threshold)
+	if err != nil {
+		if errors.Is(err, service.ErrReportRunning) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Similarity report already running"})
+			return
+		}
+		h.respondJobError(c, err)
+		return
+	}
+
+	h.logger.Info("Similarity report queued", zap.String("job_id", report.JobID), zap.Float64("threshold", threshold))
+	c.JSON(http.StatusAccepted, report)
+}
+
+// GetSimilarityReport handles the request to retrieve the latest lyrics similarity report
+// @Summary Get the similarity report
+// @Description Retrieve the latest lyrics similarity report
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} models.SimilarityReport
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/similarity-report [get]
+func (h *Handler) GetSimilarityReport(c *gin.Context) {
+	h.logger.Info("Handling GetSimilarityReport request")
+
+	report, err := h.svc.SimilarityReport()
+	if err != nil {
+		if errors.Is(err, service.ErrNoReport) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No similarity report has been run"})
+			return
+		}
+		h.logger.Error("Failed to fetch similarity report", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Similarity report retrieved successfully", zap.String("status", report.Status), zap.Int("pairs", len(report.Pairs)))
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// GetSnapshots handles the request to list the snapshots taken before destructive changes
+// @Summary Get snapshots
+// @Description List the snapshots taken before destructive changes
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {array} models.Snapshot
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/snapshots [get]
+func (h *Handler) GetSnapshots(c *gin.Context) {
+	h.logger.Info("Handling GetSnapshots request")
+
+	snapshots, err := h.svc.GetSnapshots(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to fetch snapshots", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Snapshots retrieved successfully", zap.Int("count", len(snapshots)))
+	c.JSON(http.StatusOK, snapshots)
+}
+
+// RestoreSnapshot handles the request to put the songs of a snapshot back into the empty catalog
+// @Summary Restore a snapshot
+// @Description Put the songs of a snapshot back into the empty catalog
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "Snapshot ID"
+// @Success 200 {object} RestoreSnapshotResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/snapshots/{id}/restore [post]
+func (h *Handler) RestoreSnapshot(c *gin.Context) {
+	h.logger.Info("Handling RestoreSnapshot request")
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Invalid snapshot ID", zap.String("snapshot_id", idStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid snapshot ID"})
+		return
+	}
+
+	restored, err := h.svc.RestoreSnapshot(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Snapshot not found"})
+			return
+		}
+		if errors.Is(err, service.ErrCatalogNotEmpty) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Snapshots can only be restored into an empty catalog"})
+			return
+		}
+		h.logger.Error("Failed to restore snapshot", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Snapshot restored successfully", zap.Int("snapshot_id", id), zap.Int("songs", restored))
+	c.JSON(http.StatusOK, gin.H{"message": "Snapshot restored successfully", "restored": restored})
+}
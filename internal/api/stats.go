@@ -0,0 +1,51 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// GetGroupStats handles the request to summarize the songs of a group, matched by name case-insensitively
+// @Summary Get group statistics
+// @Description Summarize the songs of a group, matched by name case-insensitively
+// @Tags songs
+// @Produce json,xml
+// @Security BearerAuth
+// @Param name path string true "Group name"
+// @Param date_format query string false "Format of the release dates, DD.MM.YYYY when empty" Enums(iso)
+// @Success 200 {object} models.GroupStats
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /groups/{name}/stats [get]
+func (h *Handler) GetGroupStats(c *gin.Context) {
+	h.logger.Info("Handling GetGroupStats request")
+
+	group := c.Param("name")
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.svc.GetGroupStats(c.Request.Context(), group)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.logger.Warn("Group not found", zap.String("group", group))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			return
+		}
+		h.logger.Error("Failed to fetch group stats", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	service.FormatSongDates(stats.MostViewed, dateFormat)
+
+	h.logger.Info("Group stats retrieved successfully", zap.String("group", stats.Group), zap.Int("songs", stats.Songs))
+	h.renderSongs(c, http.StatusOK, stats)
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/api/middleware"
+	"music-library/internal/models"
+	"music-library/internal/service"
+)
+
+// streamFlushEvery is the number of NDJSON lines sent to the client at a time
+const streamFlushEvery = 100
+
+// wantsStream reports whether the request asks for the songs as newline delimited JSON
+func wantsStream(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), middleware.ContentTypeNDJSON)
+}
+
+// streamSongs responds with every song matching the filter as newline delimited JSON, one song per line in
+// ID order, writing the songs as they are read. Paging does not apply; once the first line is sent an error
+// can only cut the response short.
+func (h *Handler) streamSongs(c *gin.Context, filter models.SongFilter, dateFormat string) {
+	if sort := c.Query("sort"); sort != "" && sort != "id" {
+		h.logger.Warn("Invalid sort for stream", zap.String("sort", sort))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Streamed songs are only sorted by id"})
+		return
+	}
+	hidden := h.visibility.hidden(c.GetString(middleware.ContextRole))
+	written := 0
+	count, err := h.svc.StreamSongs(c.Request.Context(), filter, dateFormat, func(song models.Song) error {
+		line, err := songLine(song, hidden)
+		if err != nil {
+			return err
+		}
+		// The headers wait for the first song, so errors found before it get a regular response
+		if written == 0 {
+			writeStreamHeaders(c)
+		}
+		if _, err := c.Writer.Write(line); err != nil {
+			return err
+		}
+		if written++; written%streamFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil && written == 0 {
+		if errors.Is(err, service.ErrUnsupportedField) {
+			h.logger.Warn("Invalid missing fields", zap.Strings("missing", filter.Missing))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid missing: " + err.Error()})
+			return
+		}
+		h.logger.Error("Failed to stream songs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Song stream interrupted", zap.Int("streamed", count), zap.Error(err))
+		// Aborting the handler makes the server drop the connection, so the client can tell a cut stream
+		// from a complete one
+		panic(http.ErrAbortHandler)
+	}
+	if written == 0 {
+		writeStreamHeaders(c)
+	}
+	c.Writer.Flush()
+
+	h.logger.Info("Songs streamed successfully", zap.Int("count", count))
+}
+
+// writeStreamHeaders starts an NDJSON response
+func writeStreamHeaders(c *gin.Context) {
+	c.Header("Content-Type", middleware.ContentTypeNDJSON)
+	c.Status(http.StatusOK)
+}
+
+// songLine serializes a song as one NDJSON line, leaving out the hidden fields
+func songLine(song models.Song, hidden map[string]bool) ([]byte, error) {
+	data, err := json.Marshal(song)
+	if err != nil || len(hidden) == 0 {
+		return append(data, '\n'), err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	if data, err = json.Marshal(redactSongs(tree, hidden)); err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// subtitleContentTypes maps the subtitle formats to their media types
+var subtitleContentTypes = map[string]string{
+	service.SubtitleFormatSRT: "application/x-subrip; charset=utf-8",
+	service.SubtitleFormatVTT: "text/vtt; charset=utf-8",
+}
+
+// GetSubtitles handles the request to export the LRC timed lyrics of a song as an SRT or WebVTT file
+// @Summary Get subtitles
+// @Description Export the LRC timed lyrics of a song as an SRT or WebVTT file
+// @Tags songs
+// @Produce application/x-subrip,text/vtt
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param format query string false "Subtitle format" Enums(srt,vtt) default(srt)
+// @Success 200 {file} file "Subtitles"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /songs/{id}/subtitles [get]
+func (h *Handler) GetSubtitles(c *gin.Context) {
+	h.logger.Info("Handling GetSubtitles request")
+
+	songIDStr := c.Param("id")
+	songID, err := strconv.Atoi(songIDStr)
+	if err != nil {
+		h.logger.Error("Invalid song ID", zap.String("song_id", songIDStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+	format := c.DefaultQuery("format", service.SubtitleFormatSRT)
+
+	ctx, markStale := withStaleness(c)
+	subtitles, err := h.svc.GetSubtitles(ctx, songID, format)
+	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedSubtitleFormat) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subtitle format"})
+			return
+		}
+		if errors.Is(err, service.ErrNoTimestamps) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Song lyrics have no LRC timestamps"})
+			return
+		}
+		if err == sql.ErrNoRows {
+			h.logger.Warn("Song not found", zap.Int("song_id", songID))
+			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+			return
+		}
+		if errors.Is(err, service.ErrDegraded) {
+			h.logger.Warn("Subtitles unavailable while degraded", zap.Int("song_id", songID))
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Database unavailable"})
+			return
+		}
+		h.logger.Error("Failed to build subtitles", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Subtitles built successfully", zap.Int("song_id", songID), zap.String("format", format))
+	markStale()
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="song-%d.%s"`, songID, format))
+	c.Data(http.StatusOK, subtitleContentTypes[format], []byte(subtitles))
+}
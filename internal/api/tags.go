@@ -0,0 +1,218 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/service"
+)
+
+// BulkTagRequest defines the request body for tagging songs in bulk. The songs are selected either by
+// IDs or by a filter with the meaning of the GetSongs query parameters.
+type BulkTagRequest struct {
+	IDs    []int          `json:"ids" example:"1,7"`
+	Filter *BulkTagFilter `json:"filter"`
+	Add    []string       `json:"add" example:"acoustic"`
+	Remove []string       `json:"remove" example:"demo"`
+}
+
+// BulkTagFilter selects the songs of a bulk tagging like the GetSongs query parameters
+type BulkTagFilter struct {
+	Group     string   `json:"group" example:"Muse"`
+	Song      string   `json:"song"`
+	StaleThan string   `json:"stale_than"`
+	Missing   []string `json:"missing"`
+	Tags      []string `json:"tags"`
+	Genre     string   `json:"genre"`
+}
+
+// BulkTagSongs handles the request to add and remove tags on many songs at once,
+// selected either by an ID list or by the GetSongs filters
+// @Summary Tag songs in bulk
+// @Description Add and remove tags on many songs at once, selected by IDs or by the GetSongs filters
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BulkTagRequest true "Songs and tags"
+// @Success 200 {object} models.BulkTagResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/tags/bulk [post]
+func (h *Handler) BulkTagSongs(c *gin.Context) {
+	h.logger.Info("Handling BulkTagSongs request")
+
+	var req BulkTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bulk := service.BulkTagRequest{IDs: req.IDs, Add: req.Add, Remove: req.Remove}
+	if req.Filter != nil {
+		bulk.Filter = &models.SongFilter{Group: req.Filter.Group, Song: req.Filter.Song, Missing: req.Filter.Missing,
+			Tags: filterTags(req.Filter.Tags), Genre: strings.TrimSpace(req.Filter.Genre)}
+		if req.Filter.StaleThan != "" {
+			staleThan, err := parseAge(req.Filter.StaleThan)
+			if err != nil {
+				h.logger.Error("Invalid stale_than", zap.String("stale_than", req.Filter.StaleThan))
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stale_than"})
+				return
+			}
+			bulk.Filter.StaleThan = staleThan
+		}
+	}
+
+	result, err := h.svc.BulkTagSongs(c.Request.Context(), bulk)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBulkTag) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrLegalHold) {
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to tag songs in bulk", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Songs tagged in bulk successfully", zap.Int("matched", result.Matched))
+	c.JSON(http.StatusOK, result)
+}
+
+// GetSongTags handles the request to list the tags of a song
+// @Summary Get the tags of a song
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} SongTagsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/tags [get]
+func (h *Handler) GetSongTags(c *gin.Context) {
+	h.logger.Info("Handling GetSongTags request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	tags, err := h.svc.GetSongTags(c.Request.Context(), songID)
+	h.respondSongTags(c, songID, tags, err)
+}
+
+// AddSongTagsRequest defines the request body for adding tags to a song
+type AddSongTagsRequest struct {
+	Tags []string `json:"tags" binding:"required" example:"acoustic"`
+}
+
+// AddSongTags handles the request to add tags to a song
+// @Summary Add tags to a song
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param tags body AddSongTagsRequest true "Tags to add"
+// @Success 200 {object} SongTagsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/tags [post]
+func (h *Handler) AddSongTags(c *gin.Context) {
+	h.logger.Info("Handling AddSongTags request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	var req AddSongTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tags, err := h.svc.AddSongTags(c.Request.Context(), songID, req.Tags)
+	h.respondSongTags(c, songID, tags, err)
+}
+
+// RemoveSongTag handles the request to remove a tag from a song
+// @Summary Remove a tag from a song
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param tag path string true "Tag"
+// @Success 200 {object} SongTagsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/tags/{tag} [delete]
+func (h *Handler) RemoveSongTag(c *gin.Context) {
+	h.logger.Info("Handling RemoveSongTag request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	tags, err := h.svc.RemoveSongTag(c.Request.Context(), songID, c.Param("tag"))
+	h.respondSongTags(c, songID, tags, err)
+}
+
+// respondSongTags responds with the tags of a song, or with the status of the error
+func (h *Handler) respondSongTags(c *gin.Context, songID int, tags []string, err error) {
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidTag) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrLegalHold) {
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to handle song tags", zap.Int("song_id", songID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	h.logger.Info("Song tags retrieved successfully", zap.Int("song_id", songID), zap.Int("count", len(tags)))
+	c.JSON(http.StatusOK, gin.H{"song_id": songID, "tags": tags})
+}
+
+// filterTags lowercases and deduplicates the tags songs are filtered by, dropping empty ones, so they match
+// the stored tags
+func filterTags(values []string) []string {
+	var tags []string
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		tag := strings.ToLower(strings.TrimSpace(value))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
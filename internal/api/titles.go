@@ -0,0 +1,210 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/service"
+)
+
+// maxTitleLanguages bounds the number of preferred languages a request is localized by
+const maxTitleLanguages = 10
+
+// GetSongTitles handles the request to list the titles of a song in every language
+// @Summary Get the titles of a song
+// @Description List the titles of a song in every language
+// @Tags titles
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} models.SongTitles
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/titles [get]
+func (h *Handler) GetSongTitles(c *gin.Context) {
+	h.logger.Info("Handling GetSongTitles request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	titles, err := h.svc.GetSongTitles(c.Request.Context(), songID)
+	if err != nil {
+		h.respondSongTitleError(c, err)
+		return
+	}
+
+	h.logger.Info("Song titles retrieved successfully", zap.Int("song_id", songID), zap.Int("count", len(titles.Titles)))
+	render(c, http.StatusOK, titles)
+}
+
+// SetSongTitle handles the request to add or replace the title of a song in a language
+// @Summary Set the title of a song in a language
+// @Description Add or replace the title of a song in a language
+// @Tags titles
+// @Accept json
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param lang path string true "BCP 47 language tag"
+// @Param title body models.SongTitleInput true "Localized title"
+// @Success 200 {object} models.SongTitle
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/titles/{lang} [put]
+func (h *Handler) SetSongTitle(c *gin.Context) {
+	h.logger.Info("Handling SetSongTitle request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	var title models.SongTitleInput
+	if err := c.ShouldBindJSON(&title); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stored, err := h.svc.SetSongTitle(c.Request.Context(), songID, c.Param("lang"), title)
+	if err != nil {
+		h.respondSongTitleError(c, err)
+		return
+	}
+
+	h.logger.Info("Song title set successfully", zap.Int("song_id", songID), zap.String("lang", stored.Lang))
+	render(c, http.StatusOK, stored)
+}
+
+// DeleteSongTitle handles the request to delete the title of a song in a language
+// @Summary Delete the title of a song in a language
+// @Tags titles
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Param lang path string true "BCP 47 language tag"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 423 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/{id}/titles/{lang} [delete]
+func (h *Handler) DeleteSongTitle(c *gin.Context) {
+	h.logger.Info("Handling DeleteSongTitle request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteSongTitle(c.Request.Context(), songID, c.Param("lang")); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Title not found"})
+			return
+		}
+		h.respondSongTitleError(c, err)
+		return
+	}
+
+	h.logger.Info("Song title deleted successfully", zap.Int("song_id", songID))
+	c.JSON(http.StatusOK, gin.H{"message": "Title deleted successfully"})
+}
+
+// respondSongTitleError maps the errors of the song title operations to responses
+func (h *Handler) respondSongTitleError(c *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+	case errors.Is(err, service.ErrInvalidTitle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrLegalHold):
+		c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("Failed to handle song titles", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// localizeTitles sets the localized title of the songs from the languages the request prefers. A failure
+// to read the titles is logged and leaves the songs with their original titles only, as the listing
+// itself succeeded.
+func (h *Handler) localizeTitles(c *gin.Context, preferences models.Preferences, songs ...*models.Song) {
+	langs := titleLanguages(c, preferences)
+	if len(langs) == 0 {
+		return
+	}
+	if err := h.svc.ApplySongTitles(c.Request.Context(), langs, songs...); err != nil {
+		h.logger.Warn("Failed to localize song titles", zap.Strings("langs", langs), zap.Error(err))
+	}
+}
+
+// titleLanguages returns the languages the titles of a request are localized in, most preferred first:
+// the comma separated ?title_lang= when given, the Accept-Language header otherwise, and the language of
+// the user's preferences when the request names none. Invalid tags and the "*" wildcard are skipped.
+func titleLanguages(c *gin.Context, preferences models.Preferences) []string {
+	if value := c.Query("title_lang"); value != "" {
+		var langs []string
+		for _, tag := range strings.Split(value, ",") {
+			if lang, ok := service.NormalizeLanguage(tag); ok && len(langs) < maxTitleLanguages {
+				langs = append(langs, lang)
+			}
+		}
+		return langs
+	}
+	if header := c.GetHeader("Accept-Language"); header != "" {
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		if langs := parseAcceptLanguage(header); len(langs) > 0 {
+			return langs
+		}
+	}
+	if lang, ok := service.NormalizeLanguage(preferences.Language); ok {
+		return []string{lang}
+	}
+	return nil
+}
+
+// parseAcceptLanguage returns the languages of an Accept-Language header in order of their quality,
+// leaving out the wildcard and those with a quality of zero
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang    string
+		quality float64
+	}
+	var entries []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		lang, ok := service.NormalizeLanguage(tag)
+		if !ok || quality <= 0 {
+			continue
+		}
+		entries = append(entries, weighted{lang: lang, quality: quality})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].quality > entries[j].quality })
+	langs := make([]string, 0, min(len(entries), maxTitleLanguages))
+	for _, entry := range entries[:min(len(entries), maxTitleLanguages)] {
+		langs = append(langs, entry.lang)
+	}
+	return langs
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// GetTrash handles the request to list the deleted songs that can still be restored
+// @Summary Get the trash
+// @Description List the deleted songs that can still be restored
+// @Tags trash
+// @Produce json,xml
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} models.TrashPage
+// @Header 200 {integer} X-Total-Count "Number of songs in the trash"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /trash [get]
+func (h *Handler) GetTrash(c *gin.Context) {
+	h.logger.Info("Handling GetTrash request")
+
+	page, limit, ok := h.listPage(c)
+	if !ok {
+		return
+	}
+	trash, err := h.svc.GetTrash(c.Request.Context(), page, limit)
+	if err != nil {
+		h.logger.Error("Failed to fetch trash", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(trash.Total))
+	h.logger.Info("Trash retrieved successfully", zap.Int("count", len(trash.Data)), zap.Int("total", trash.Total))
+	render(c, http.StatusOK, trash)
+}
+
+// RestoreTrashedSong handles the request to put a deleted song back into the catalog
+// @Summary Restore a deleted song
+// @Tags trash
+// @Produce json,xml
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} models.Song
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /trash/{id}/restore [post]
+func (h *Handler) RestoreTrashedSong(c *gin.Context) {
+	h.logger.Info("Handling RestoreTrashedSong request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	song, err := h.svc.RestoreTrashedSong(c.Request.Context(), songID)
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found in trash"})
+		case errors.Is(err, service.ErrSongIDTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": "Another song has taken the ID of the deleted song"})
+		default:
+			h.logger.Error("Failed to restore song from trash", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	h.logger.Info("Song restored from trash successfully", zap.Int("song_id", songID))
+	h.renderSongs(c, http.StatusOK, song)
+}
+
+// PurgeTrashedSong handles the request to delete a song in the trash for good
+// @Summary Purge a deleted song
+// @Description Delete a song in the trash for good
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /trash/{id} [delete]
+func (h *Handler) PurgeTrashedSong(c *gin.Context) {
+	h.logger.Info("Handling PurgeTrashedSong request")
+
+	songID, ok := h.songID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.PurgeTrashedSong(c.Request.Context(), songID); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found in trash"})
+			return
+		}
+		h.logger.Error("Failed to purge song from trash", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Song purged from trash successfully", zap.Int("song_id", songID))
+	c.JSON(http.StatusOK, gin.H{"message": "Song purged from trash successfully"})
+}
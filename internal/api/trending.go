@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// GetTrendingSongs handles the request to retrieve the songs that are hot right now
+// @Summary Get trending songs
+// @Description Retrieve the songs that are hot right now
+// @Tags songs
+// @Produce json,xml
+// @Security BearerAuth
+// @Param limit query int false "Maximum number of songs" default(10)
+// @Success 200 {array} models.TrendingSong
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /songs/trending [get]
+func (h *Handler) GetTrendingSongs(c *gin.Context) {
+	h.logger.Info("Handling GetTrendingSongs request")
+
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		h.logger.Error("Invalid limit", zap.String("limit", limitStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	dateFormat, ok := h.dateFormat(c)
+	if !ok {
+		return
+	}
+
+	songs, err := h.svc.GetTrendingSongs(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to fetch trending songs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	for i := range songs {
+		service.FormatSongDate(&songs[i].Song, dateFormat)
+	}
+
+	h.logger.Info("Trending songs retrieved successfully", zap.Int("count", len(songs)))
+	h.renderSongs(c, http.StatusOK, songs)
+}
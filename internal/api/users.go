@@ -0,0 +1,114 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// GetUsers handles the request to list user accounts and their roles
+// @Summary Get users
+// @Description List the user accounts and their roles
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(50)
+// @Success 200 {array} models.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/users [get]
+func (h *Handler) GetUsers(c *gin.Context) {
+	h.logger.Info("Handling GetUsers request")
+
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "50")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		h.logger.Error("Invalid page number", zap.String("page", pageStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		h.logger.Error("Invalid limit", zap.String("limit", limitStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	users, err := h.svc.GetUsers(c.Request.Context(), page, limit)
+	if err != nil {
+		h.logger.Error("Failed to fetch users", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Users retrieved successfully", zap.Int("count", len(users)))
+	c.JSON(http.StatusOK, users)
+}
+
+// SetUserRoleRequest defines the request body for changing the role of a user
+type SetUserRoleRequest struct {
+	Role string `json:"role" validate:"required" enums:"viewer,editor,admin" example:"editor"`
+}
+
+// SetUserRole handles the request to change the role of a user
+// @Summary Set the role of a user
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "User ID"
+// @Param role body SetUserRoleRequest true "Role"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/users/{id}/role [put]
+func (h *Handler) SetUserRole(c *gin.Context) {
+	h.logger.Info("Handling SetUserRole request")
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Invalid user ID", zap.String("id", idStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req SetUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.svc.SetUserRole(c.Request.Context(), id, req.Role); err != nil {
+		if errors.Is(err, service.ErrUnsupportedRole) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+			return
+		}
+		if err == sql.ErrNoRows {
+			h.logger.Warn("User not found", zap.Int("id", id))
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		h.logger.Error("Failed to set user role", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("User role set successfully", zap.Int("id", id), zap.String("role", req.Role))
+	c.JSON(http.StatusOK, gin.H{"message": "User role updated successfully"})
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/api/middleware"
+	"music-library/internal/models"
+)
+
+// VisibilityPublic makes a field visible to every caller, including unauthenticated ones
+const VisibilityPublic = "public"
+
+// FieldVisibility maps a song field, by its JSON name, to the least role allowed to see it.
+// Fields not listed are visible to every caller.
+type FieldVisibility map[string]string
+
+// DefaultFieldVisibility keeps the staff fields from viewers
+var DefaultFieldVisibility = FieldVisibility{
+	"notes":         models.RoleEditor,
+	"licensing_fee": models.RoleEditor,
+}
+
+// songFields are the JSON names of the song fields visibility rules may apply to
+var songFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	songType := reflect.TypeOf(models.Song{})
+	for i := 0; i < songType.NumField(); i++ {
+		if name := jsonName(songType.Field(i)); name != "" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// jsonName returns the JSON name of a struct field, empty for fields left out of JSON
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// FieldVisibilityFromEnv reads FIELD_VISIBILITY, comma separated field=role pairs
+// (e.g. "notes=admin,licensing_fee=editor") overriding the defaults. A field set to "public" is shown to everyone.
+func FieldVisibilityFromEnv() (FieldVisibility, error) {
+	visibility := make(FieldVisibility, len(DefaultFieldVisibility))
+	for field, role := range DefaultFieldVisibility {
+		visibility[field] = role
+	}
+	value := os.Getenv("FIELD_VISIBILITY")
+	if value == "" {
+		return visibility, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		field, role, ok := strings.Cut(pair, "=")
+		field, role = strings.TrimSpace(field), strings.TrimSpace(role)
+		if !ok || !songFields[field] {
+			return nil, fmt.Errorf("FIELD_VISIBILITY: unknown song field in %q", pair)
+		}
+		switch {
+		case role == VisibilityPublic:
+			delete(visibility, field)
+		case models.IsValidRole(role):
+			visibility[field] = role
+		default:
+			return nil, fmt.Errorf("FIELD_VISIBILITY: unsupported role in %q", pair)
+		}
+	}
+	return visibility, nil
+}
+
+// hidden returns the fields the role may not see; an empty role is an unauthenticated caller
+func (v FieldVisibility) hidden(role string) map[string]bool {
+	hidden := make(map[string]bool)
+	for field, required := range v {
+		if !models.HasRole(role, required) {
+			hidden[field] = true
+		}
+	}
+	return hidden
+}
+
+// ConfigureFieldVisibility sets the roles allowed to see restricted song fields
+func (h *Handler) ConfigureFieldVisibility(visibility FieldVisibility) {
+	h.visibility = visibility
+}
+
+// renderSongs responds with the payload as JSON, or as XML when the request asks for it, leaving out of
+// every song in it the fields the caller's role may not see
+func (h *Handler) renderSongs(c *gin.Context, status int, payload any) {
+	hidden := h.visibility.hidden(c.GetString(middleware.ContextRole))
+	varyOnAccept(c)
+	if wantsXML(c) {
+		c.XML(status, xmlPayload(hideSongFields(payload, hidden)))
+		return
+	}
+	if len(hidden) == 0 {
+		c.JSON(status, payload)
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		h.logger.Error("Failed to serialize response", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		h.logger.Error("Failed to serialize response", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	c.JSON(status, redactSongs(tree, hidden))
+}
+
+// redactSongs removes the hidden fields from every song object in the decoded JSON value.
+// Objects are taken for songs when they carry the id, group and song fields.
+func redactSongs(value any, hidden map[string]bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		_, hasID := v["id"]
+		_, hasGroup := v["group"]
+		_, hasSong := v["song"]
+		isSong := hasID && hasGroup && hasSong
+		for key, item := range v {
+			if isSong && hidden[key] {
+				delete(v, key)
+				continue
+			}
+			v[key] = redactSongs(item, hidden)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactSongs(item, hidden)
+		}
+	}
+	return value
+}
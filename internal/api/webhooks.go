@@ -0,0 +1,288 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"music-library/internal/service"
+)
+
+// CreateWebhookRequest defines the request body for registering a webhook. A signing secret is generated
+// when none is given.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required" example:"https://example.com/hooks/music"`
+	Events []string `json:"events" binding:"required" example:"song.created,song.updated"`
+	Secret string   `json:"secret"`
+}
+
+// CreateWebhook handles the request to register a URL notified of catalog events. The response carries the
+// signing secret, which is not shown again.
+// @Summary Register a webhook
+// @Description Register a URL notified of catalog events. The response carries the signing secret, which is not shown again.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param webhook body CreateWebhookRequest true "Webhook"
+// @Success 201 {object} models.Webhook
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks [post]
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	h.logger.Info("Handling CreateWebhook request")
+
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Failed to parse request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.svc.CreateWebhook(c.Request.Context(), req.URL, req.Events, req.Secret)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidWebhook) {
+			h.logger.Warn("Invalid webhook", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to create webhook", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Webhook created successfully", zap.Int("webhook_id", webhook.ID))
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// GetWebhooks handles the request to list the registered webhooks
+// @Summary Get webhooks
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Webhook
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks [get]
+func (h *Handler) GetWebhooks(c *gin.Context) {
+	h.logger.Info("Handling GetWebhooks request")
+
+	webhooks, err := h.svc.GetWebhooks(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to fetch webhooks", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Webhooks retrieved successfully", zap.Int("count", len(webhooks)))
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhook handles the request to unregister a webhook, dropping its pending deliveries
+// @Summary Delete a webhook
+// @Description Unregister a webhook, dropping its pending deliveries
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	h.logger.Info("Handling DeleteWebhook request")
+
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteWebhook(c.Request.Context(), id); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		h.logger.Error("Failed to delete webhook", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Webhook deleted successfully", zap.Int("webhook_id", id))
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetWebhookDeliveries handles the request to list the newest deliveries of a webhook with the outcome of
+// their latest attempt, optionally filtered by status
+// @Summary Get webhook deliveries
+// @Description List the newest deliveries of a webhook with the outcome of their latest attempt
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param status query string false "Only the deliveries with this status" Enums(pending,delivered,failed)
+// @Param limit query int false "Maximum number of deliveries" default(50)
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/{id}/deliveries [get]
+func (h *Handler) GetWebhookDeliveries(c *gin.Context) {
+	h.logger.Info("Handling GetWebhookDeliveries request")
+
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+	limitStr := c.DefaultQuery("limit", "50")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > service.MaxWebhookDeliveries {
+		h.logger.Error("Invalid limit", zap.String("limit", limitStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	deliveries, err := h.svc.GetWebhookDeliveries(c.Request.Context(), id, c.Query("status"), limit)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidDeliveryStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to fetch webhook deliveries", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Webhook deliveries retrieved successfully", zap.Int("webhook_id", id), zap.Int("count", len(deliveries)))
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// RotateWebhookSecretRequest defines the optional request body for rotating the signing secret of a
+// webhook. A secret is generated when none is given.
+type RotateWebhookSecretRequest struct {
+	Secret string `json:"secret"`
+}
+
+// RotateWebhookSecret handles the request to replace the signing secret of a webhook. The response carries
+// the new secret, which is not shown again; deliveries are also signed with the replaced one for a grace period.
+// @Summary Rotate the secret of a webhook
+// @Description Replace the signing secret of a webhook. The response carries the new secret, which is not shown again;
+// @Description deliveries are also signed with the replaced one for a grace period.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param secret body RotateWebhookSecretRequest false "New secret, generated when omitted"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/{id}/secret/rotate [post]
+func (h *Handler) RotateWebhookSecret(c *gin.Context) {
+	h.logger.Info("Handling RotateWebhookSecret request")
+
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+	var req RotateWebhookSecretRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.Warn("Failed to parse request body", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	webhook, err := h.svc.RotateWebhookSecret(c.Request.Context(), id, req.Secret)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		h.logger.Error("Failed to rotate webhook secret", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Webhook secret rotated successfully", zap.Int("webhook_id", id))
+	c.JSON(http.StatusOK, webhook)
+}
+
+// RetryWebhookDelivery handles the request to send a delivered or failed delivery of a webhook again
+// @Summary Retry a webhook delivery
+// @Description Send a delivered or failed delivery of a webhook again
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param delivery path int true "Delivery ID"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/{id}/deliveries/{delivery}/retry [post]
+func (h *Handler) RetryWebhookDelivery(c *gin.Context) {
+	h.logger.Info("Handling RetryWebhookDelivery request")
+
+	id, ok := h.webhookID(c)
+	if !ok {
+		return
+	}
+	deliveryStr := c.Param("delivery")
+	deliveryID, err := strconv.ParseInt(deliveryStr, 10, 64)
+	if err != nil {
+		h.logger.Error("Invalid delivery ID", zap.String("delivery_id", deliveryStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	delivery, err := h.svc.RetryWebhookDelivery(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+			return
+		}
+		if errors.Is(err, service.ErrDeliveryPending) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Delivery is still pending"})
+			return
+		}
+		h.logger.Error("Failed to retry webhook delivery", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	h.logger.Info("Webhook delivery queued for retry", zap.Int("webhook_id", id), zap.Int64("delivery_id", deliveryID))
+	c.JSON(http.StatusOK, delivery)
+}
+
+// webhookID parses the webhook ID of a request, responding with 400 when it is invalid
+func (h *Handler) webhookID(c *gin.Context) (int, bool) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Error("Invalid webhook ID", zap.String("webhook_id", idStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return 0, false
+	}
+	return id, true
+}
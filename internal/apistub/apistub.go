@@ -0,0 +1,86 @@
+// Package apistub serves a stand-in for the external song info API over httptest, so the service and
+// handler layers can be unit tested without the mock API container or any network access.
+package apistub
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// Song is the answer the stub gives for a song, in the format of the /info API
+type Song struct {
+	ReleaseDate string `json:"release_date"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+	Listeners   *int64 `json:"listeners,omitempty"`
+}
+
+// Server answers /info with the songs added to it and 404 for the others, recording every request
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	songs    map[[2]string]Song
+	status   int
+	requests []url.Values
+}
+
+// New starts a stub that is closed when the test ends and points EXTERNAL_API_URL at it for the test,
+// so a MusicService created afterwards enriches songs from it
+func New(t testing.TB) *Server {
+	s := &Server{songs: make(map[[2]string]Song)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.info))
+	t.Cleanup(s.Close)
+	t.Setenv("EXTERNAL_API_URL", s.URL)
+	return s
+}
+
+// AddSong makes the stub answer requests for the song with its details
+func (s *Server) AddSong(group, song string, details Song) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.songs[[2]string{group, song}] = details
+}
+
+// FailWith makes the stub answer every request with the status, or normally again when it is 0
+func (s *Server) FailWith(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+// Requests returns the query parameters of the requests received so far, in order
+func (s *Server) Requests() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]url.Values(nil), s.requests...)
+}
+
+func (s *Server) info(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/info" {
+		http.NotFound(w, r)
+		return
+	}
+	query := r.URL.Query()
+	s.mu.Lock()
+	s.requests = append(s.requests, query)
+	status := s.status
+	details, found := s.songs[[2]string{query.Get("group"), query.Get("song")}]
+	s.mu.Unlock()
+
+	switch {
+	case status != 0:
+		http.Error(w, http.StatusText(status), status)
+	case query.Get("group") == "" || query.Get("song") == "":
+		http.Error(w, "Missing group or song", http.StatusBadRequest)
+	case !found:
+		http.NotFound(w, r)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(details)
+	}
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestTokensRoundTrip(t *testing.T) {
+	tokens := NewTokens(Config{Secret: []byte(testSecret)})
+	pair, err := tokens.Issue(42, "alice", "editor")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", pair.TokenType)
+	assert.Equal(t, int(DefaultConfig.AccessTTL.Seconds()), pair.ExpiresIn)
+
+	claims, err := tokens.Verify(pair.AccessToken, TokenAccess)
+	require.NoError(t, err)
+	assert.Equal(t, 42, claims.UserID)
+	assert.Equal(t, "alice", claims.Username)
+	assert.Equal(t, "editor", claims.Role)
+
+	claims, err = tokens.Verify(pair.RefreshToken, TokenRefresh)
+	require.NoError(t, err)
+	assert.Equal(t, 42, claims.UserID)
+
+	_, err = tokens.Verify(pair.RefreshToken, TokenAccess)
+	assert.ErrorIs(t, err, ErrInvalidToken, "refresh tokens do not authorize requests")
+}
+
+func TestTokensRejectForgedAndExpired(t *testing.T) {
+	tokens := NewTokens(Config{Secret: []byte(testSecret), AccessTTL: time.Minute})
+	pair, err := tokens.Issue(1, "alice", "viewer")
+	require.NoError(t, err)
+
+	other := NewTokens(Config{Secret: []byte(strings.Repeat("x", 32))})
+	_, err = other.Verify(pair.AccessToken, TokenAccess)
+	assert.ErrorIs(t, err, ErrInvalidToken, "tokens signed with another secret are rejected")
+
+	parts := strings.Split(pair.AccessToken, ".")
+	_, err = tokens.Verify(parts[0]+"."+parts[1]+"x."+parts[2], TokenAccess)
+	assert.ErrorIs(t, err, ErrInvalidToken, "tampered payloads are rejected")
+
+	_, err = tokens.Verify("not-a-token", TokenAccess)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	tokens.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	_, err = tokens.Verify(pair.AccessToken, TokenAccess)
+	assert.ErrorIs(t, err, ErrInvalidToken, "expired tokens are rejected")
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("JWT_SECRET", "short")
+	_, err := ConfigFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("JWT_SECRET", testSecret)
+	t.Setenv("JWT_ACCESS_TTL", "5m")
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.AccessTTL)
+	assert.Equal(t, DefaultConfig.RefreshTTL, cfg.RefreshTTL)
+
+	t.Setenv("JWT_REFRESH_TTL", "soon")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+}
+
+func TestPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	require.NoError(t, err)
+	assert.True(t, CheckPassword(hash, "correct horse"))
+	assert.False(t, CheckPassword(hash, "wrong horse"))
+}
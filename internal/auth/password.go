@@ -0,0 +1,20 @@
+package auth
+
+import "golang.org/x/crypto/bcrypt"
+
+// MinPasswordLength is the minimum number of bytes of a password
+const MinPasswordLength = 8
+
+// HashPassword hashes the password with bcrypt
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether the password matches the bcrypt hash
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, forged, expired or of the wrong type
+var ErrInvalidToken = errors.New("invalid token")
+
+// Token types. Access tokens authorize requests, refresh tokens are only exchanged for a new token pair.
+const (
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
+// minSecretLength is the minimum length of the signing secret, the size of an HS256 key
+const minSecretLength = 32
+
+// Config holds the signing secret and the lifetimes of issued tokens
+type Config struct {
+	Secret     []byte
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// DefaultConfig holds the token lifetimes used when none are configured
+var DefaultConfig = Config{
+	AccessTTL:  15 * time.Minute,
+	RefreshTTL: 30 * 24 * time.Hour,
+}
+
+// ConfigFromEnv reads the token configuration from JWT_SECRET, JWT_ACCESS_TTL and JWT_REFRESH_TTL.
+// JWT_SECRET is required and must be at least 32 bytes long.
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig
+	cfg.Secret = []byte(os.Getenv("JWT_SECRET"))
+	if len(cfg.Secret) < minSecretLength {
+		return cfg, fmt.Errorf("JWT_SECRET must be set to at least %d bytes", minSecretLength)
+	}
+	for key, ttl := range map[string]*time.Duration{"JWT_ACCESS_TTL": &cfg.AccessTTL, "JWT_REFRESH_TTL": &cfg.RefreshTTL} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return cfg, fmt.Errorf("%s must be a positive duration: %q", key, value)
+		}
+		*ttl = parsed
+	}
+	return cfg, nil
+}
+
+// Claims identify the user a token was issued to
+type Claims struct {
+	UserID    int    `json:"-"`
+	Subject   string `json:"sub"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	Type      string `json:"token_type"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// TokenPair is the response to a successful login or refresh
+type TokenPair struct {
+	AccessToken  string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJzdWIiOiIxIiwidXNlcm5hbWUiOiJhbGljZSJ9.signature"`
+	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJzdWIiOiIxIiwidG9rZW5fdHlwZSI6InJlZnJlc2gifQ.signature"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int    `json:"expires_in" example:"900"`
+}
+
+// Tokens issues and verifies HS256 signed JWTs
+type Tokens struct {
+	cfg Config
+	now func() time.Time
+}
+
+// NewTokens creates a Tokens signing with the configured secret; zero lifetimes take the defaults
+func NewTokens(cfg Config) *Tokens {
+	if cfg.AccessTTL <= 0 {
+		cfg.AccessTTL = DefaultConfig.AccessTTL
+	}
+	if cfg.RefreshTTL <= 0 {
+		cfg.RefreshTTL = DefaultConfig.RefreshTTL
+	}
+	return &Tokens{cfg: cfg, now: time.Now}
+}
+
+// jwtHeader is the encoded header of every issued token
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Issue creates an access and a refresh token for the user. The role is carried by the tokens,
+// so a role change applies once the access token is refreshed.
+func (t *Tokens) Issue(userID int, username, role string) (TokenPair, error) {
+	access, err := t.sign(userID, username, role, TokenAccess, t.cfg.AccessTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refresh, err := t.sign(userID, username, role, TokenRefresh, t.cfg.RefreshTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(t.cfg.AccessTTL.Seconds()),
+	}, nil
+}
+
+// sign encodes and signs a token of the type for the user
+func (t *Tokens) sign(userID int, username, role, tokenType string, ttl time.Duration) (string, error) {
+	now := t.now()
+	payload, err := json.Marshal(Claims{
+		Subject:   strconv.Itoa(userID),
+		Username:  username,
+		Role:      role,
+		Type:      tokenType,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + t.signature(unsigned), nil
+}
+
+// signature computes the encoded HMAC-SHA256 signature of the header and payload
+func (t *Tokens) signature(unsigned string) string {
+	mac := hmac.New(sha256.New, t.cfg.Secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature, expiry and type of the token and returns its claims
+func (t *Tokens) Verify(token, tokenType string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return Claims{}, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(t.signature(parts[0]+"."+parts[1]))) {
+		return Claims{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	if claims.Type != tokenType {
+		return Claims{}, fmt.Errorf("%w: expected a %s token", ErrInvalidToken, tokenType)
+	}
+	if t.now().Unix() >= claims.ExpiresAt {
+		return Claims{}, fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+	claims.UserID, err = strconv.Atoi(claims.Subject)
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
@@ -0,0 +1,152 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+)
+
+// ErrOpen is returned by Allow while the breaker is open, or half-open with its probe calls in flight
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a breaker
+type State int
+
+// Breaker states. Closed lets every call through, Open rejects every call until the open timeout passes,
+// HalfOpen lets a few probe calls through whose outcome closes or reopens the breaker.
+const (
+	Closed State = iota
+	HalfOpen
+	Open
+)
+
+// String returns the name of the state, as used in logs and metric labels
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half_open"
+	case Open:
+		return "open"
+	}
+	return "unknown"
+}
+
+// Config tunes when a breaker opens and how it recovers
+type Config struct {
+	// FailureThreshold is the number of consecutive failures that opens the breaker
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before letting probe calls through
+	OpenTimeout time.Duration
+	// HalfOpenCalls is the number of probe calls let through while half-open
+	HalfOpenCalls int
+}
+
+// DefaultConfig is used for the values a Config leaves at zero
+var DefaultConfig = Config{
+	FailureThreshold: 5,
+	OpenTimeout:      30 * time.Second,
+	HalfOpenCalls:    1,
+}
+
+// Breaker stops calls to a failing dependency, so callers fall back at once instead of waiting for
+// every call to time out, and probes the dependency to detect its recovery
+type Breaker struct {
+	name   string
+	cfg    Config
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probes   int
+}
+
+// New creates a closed Breaker; the name labels its logs and metrics
+func New(name string, cfg Config, logger *zap.Logger) *Breaker {
+	if cfg.FailureThreshold < 1 {
+		cfg.FailureThreshold = DefaultConfig.FailureThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = DefaultConfig.OpenTimeout
+	}
+	if cfg.HalfOpenCalls < 1 {
+		cfg.HalfOpenCalls = DefaultConfig.HalfOpenCalls
+	}
+	metrics.CircuitBreakerState.WithLabelValues(name).Set(float64(Closed))
+	return &Breaker{name: name, cfg: cfg, logger: logger, now: time.Now}
+}
+
+// State returns the current state, moving an open breaker whose timeout passed to half-open
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.checkTimeout()
+	return b.state
+}
+
+// Allow reports whether a call may be made, returning ErrOpen when it may not. Every allowed call
+// must be followed by Success or Failure.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.checkTimeout()
+	switch b.state {
+	case Open:
+		return ErrOpen
+	case HalfOpen:
+		if b.probes >= b.cfg.HalfOpenCalls {
+			return ErrOpen
+		}
+		b.probes++
+	}
+	return nil
+}
+
+// Success records a successful call; a successful probe closes the breaker
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	if b.state == HalfOpen {
+		b.transition(Closed)
+	}
+}
+
+// Failure records a failed call; a failed probe, or reaching the failure threshold, opens the breaker
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == HalfOpen || (b.state == Closed && b.failures >= b.cfg.FailureThreshold) {
+		b.openedAt = b.now()
+		b.transition(Open)
+	}
+}
+
+// checkTimeout moves an open breaker to half-open once the open timeout passed. The caller holds mu.
+func (b *Breaker) checkTimeout() {
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		b.transition(HalfOpen)
+	}
+}
+
+// transition enters the state, resetting the probes and recording the change. The caller holds mu.
+func (b *Breaker) transition(state State) {
+	from := b.state
+	b.state = state
+	b.probes = 0
+	if state == Closed {
+		b.failures = 0
+	}
+	metrics.CircuitBreakerState.WithLabelValues(b.name).Set(float64(state))
+	metrics.CircuitBreakerTransitions.WithLabelValues(b.name, state.String()).Inc()
+	b.logger.Warn("Circuit breaker changed state", zap.String("breaker", b.name),
+		zap.String("from", from.String()), zap.String("to", state.String()), zap.Int("failures", b.failures))
+}
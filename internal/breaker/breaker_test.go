@@ -0,0 +1,42 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+)
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	now := time.Now()
+	b := New("test", Config{FailureThreshold: 2, OpenTimeout: time.Minute}, zap.NewNop())
+	b.now = func() time.Time { return now }
+
+	assert.NoError(t, b.Allow())
+	b.Failure()
+	b.Success()
+	b.Failure()
+	assert.Equal(t, Closed, b.State(), "a success resets the consecutive failures")
+	b.Failure()
+	assert.Equal(t, Open, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrOpen)
+	assert.Equal(t, float64(Open), testutil.ToFloat64(metrics.CircuitBreakerState.WithLabelValues("test")))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, HalfOpen, b.State())
+	assert.NoError(t, b.Allow(), "a probe is let through once the open timeout passed")
+	assert.ErrorIs(t, b.Allow(), ErrOpen, "further calls wait for the probe")
+	b.Failure()
+	assert.Equal(t, Open, b.State(), "a failed probe reopens the breaker")
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	b.Success()
+	assert.Equal(t, Closed, b.State(), "a successful probe closes the breaker")
+	assert.NoError(t, b.Allow())
+	assert.Equal(t, float64(Closed), testutil.ToFloat64(metrics.CircuitBreakerState.WithLabelValues("test")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.CircuitBreakerTransitions.WithLabelValues("test", Open.String())))
+}
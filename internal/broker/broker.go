@@ -0,0 +1,118 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"music-library/internal/changes"
+	"music-library/internal/secrets"
+)
+
+// Supported message brokers
+const (
+	KindKafka = "kafka"
+	KindNATS  = "nats"
+)
+
+// DefaultTopic is the Kafka topic, or the NATS subject prefix, the events are published to
+const DefaultTopic = "music-library.songs"
+
+// Publisher sends messages to a message broker
+type Publisher interface {
+	// Publish sends a message to the topic, returning once the broker acknowledged it. The key orders the
+	// messages of a song where the broker supports it.
+	Publish(ctx context.Context, topic, key string, payload []byte) error
+	Close() error
+}
+
+// Subscriber returns the outbox relay subscriber publishing the catalog events. Kafka receives every event on
+// the topic, keyed by song, so consumers read the events of a song in order; NATS receives them on the subject
+// <topic>.<event type>, so consumers can subscribe to some types only. An event that fails to be published is
+// relayed again, so consumers dedupe by event ID.
+func Subscriber(publisher Publisher, kind, topic string) func(ctx context.Context, event changes.Event) error {
+	return func(ctx context.Context, event changes.Event) error {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		subject := topic
+		if kind == KindNATS {
+			subject = topic + "." + event.Type
+		}
+		key := ""
+		if event.SongID != 0 {
+			key = strconv.Itoa(event.SongID)
+		}
+		return publisher.Publish(ctx, subject, key, payload)
+	}
+}
+
+// Config selects the broker
+type Config struct {
+	// Kind is KindKafka or KindNATS; events are not published to a broker without it
+	Kind string
+	// URL is the address of the Kafka REST proxy, or the nats://host:port address of a NATS server
+	URL      string
+	Topic    string
+	User     string
+	Password string
+	// Timeout bounds a publication
+	Timeout time.Duration
+}
+
+// ConfigFromEnv reads the broker configuration from BROKER_KIND, BROKER_URL, BROKER_TOPIC, BROKER_USER,
+// BROKER_PASSWORD, read from the file named by BROKER_PASSWORD_FILE when not set, and BROKER_TIMEOUT
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Kind:    os.Getenv("BROKER_KIND"),
+		URL:     strings.TrimSuffix(os.Getenv("BROKER_URL"), "/"),
+		Topic:   os.Getenv("BROKER_TOPIC"),
+		User:    os.Getenv("BROKER_USER"),
+		Timeout: 10 * time.Second,
+	}
+	if cfg.Topic == "" {
+		cfg.Topic = DefaultTopic
+	}
+	password, err := secrets.Read("BROKER_PASSWORD")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Password = password
+	if value := os.Getenv("BROKER_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return cfg, fmt.Errorf("BROKER_TIMEOUT must be a positive duration: %q", value)
+		}
+		cfg.Timeout = timeout
+	}
+	switch cfg.Kind {
+	case "":
+	case KindKafka, KindNATS:
+		if cfg.URL == "" {
+			return cfg, fmt.Errorf("BROKER_URL is required for the %s broker", cfg.Kind)
+		}
+	default:
+		return cfg, fmt.Errorf("unsupported broker %q", cfg.Kind)
+	}
+	return cfg, nil
+}
+
+// New builds the publisher of the configured broker, or returns nil when none is configured
+func New(cfg Config, client *http.Client) (Publisher, error) {
+	switch cfg.Kind {
+	case "":
+		return nil, nil
+	case KindKafka:
+		return &KafkaPublisher{URL: cfg.URL, User: cfg.User, Password: cfg.Password, Timeout: cfg.Timeout, Client: client}, nil
+	case KindNATS:
+		return NewNATSPublisher(cfg.URL, cfg.User, cfg.Password, cfg.Timeout)
+	default:
+		return nil, fmt.Errorf("unsupported broker %q", cfg.Kind)
+	}
+}
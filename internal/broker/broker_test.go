@@ -0,0 +1,122 @@
+package broker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-library/internal/changes"
+)
+
+func TestKafkaPublisher(t *testing.T) {
+	var path, contentType, user string
+	var body map[string][]map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		user, _, _ = r.BasicAuth()
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"offsets": [{"partition": 0, "offset": 12}]}`))
+	}))
+	defer server.Close()
+
+	publisher := &KafkaPublisher{URL: server.URL, User: "producer", Client: server.Client()}
+	publish := Subscriber(publisher, KindKafka, DefaultTopic)
+	require.NoError(t, publish(context.Background(), changes.Event{ID: 4, Type: changes.EventSongUpdated, SongID: 7}))
+	assert.Equal(t, "/topics/"+DefaultTopic, path)
+	assert.Equal(t, kafkaContentType, contentType)
+	assert.Equal(t, "producer", user)
+	require.Len(t, body["records"], 1)
+	assert.Equal(t, "7", body["records"][0]["key"])
+	assert.Equal(t, changes.EventSongUpdated, body["records"][0]["value"].(map[string]any)["type"])
+}
+
+func TestKafkaPublisherRecordErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"offsets": [{"error_code": 50003, "error": "leader not available"}]}`))
+	}))
+	defer server.Close()
+
+	publisher := &KafkaPublisher{URL: server.URL, Client: server.Client()}
+	err := publisher.Publish(context.Background(), "songs", "", []byte(`{}`))
+	assert.ErrorContains(t, err, "leader not available")
+}
+
+func TestNATSPublisher(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "CONNECT "):
+				if !strings.Contains(line, `"user":"producer"`) {
+					fmt.Fprint(conn, "-ERR 'Authorization Violation'\r\n")
+					return
+				}
+			case strings.HasPrefix(line, "PUB "):
+				var subject string
+				var size int
+				fmt.Sscanf(line, "PUB %s %d", &subject, &size)
+				payload := make([]byte, size+2)
+				io.ReadFull(reader, payload)
+				received <- subject + " " + string(payload[:size])
+			case line == "PING\r\n":
+				fmt.Fprint(conn, "PONG\r\n")
+			}
+		}
+	}()
+
+	publisher, err := NewNATSPublisher("nats://producer:secret@"+listener.Addr().String(), "", "", time.Second)
+	require.NoError(t, err)
+	defer publisher.Close()
+	publish := Subscriber(publisher, KindNATS, DefaultTopic)
+	require.NoError(t, publish(context.Background(), changes.Event{ID: 5, Type: changes.EventSongDeleted, SongID: 7}))
+	select {
+	case message := <-received:
+		assert.True(t, strings.HasPrefix(message, DefaultTopic+"."+changes.EventSongDeleted+" {"), message)
+		assert.Contains(t, message, `"song_id":7`)
+	case <-time.After(time.Second):
+		t.Fatal("no message was published")
+	}
+
+	_, err = NewNATSPublisher("http://localhost:4222", "", "", time.Second)
+	assert.Error(t, err)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("BROKER_KIND", "")
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTopic, cfg.Topic)
+
+	t.Setenv("BROKER_KIND", KindNATS)
+	_, err = ConfigFromEnv()
+	assert.ErrorContains(t, err, "BROKER_URL is required")
+
+	t.Setenv("BROKER_KIND", "rabbitmq")
+	t.Setenv("BROKER_URL", "amqp://localhost")
+	_, err = ConfigFromEnv()
+	assert.ErrorContains(t, err, "unsupported broker")
+}
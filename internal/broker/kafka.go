@@ -0,0 +1,89 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// kafkaContentType is the embedded JSON format of the v2 API of the Kafka REST proxy
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaPublisher produces messages through the v2 API of a Kafka REST proxy, as run by Confluent and Redpanda,
+// so no Kafka client is linked into the service
+type KafkaPublisher struct {
+	URL      string
+	User     string
+	Password string
+	Timeout  time.Duration
+	Client   *http.Client
+}
+
+// Publish produces a message with a JSON value to the topic
+func (p *KafkaPublisher) Publish(ctx context.Context, topic, key string, payload []byte) error {
+	type record struct {
+		Key   *string         `json:"key,omitempty"`
+		Value json.RawMessage `json:"value"`
+	}
+	rec := record{Value: payload}
+	if key != "" {
+		rec.Key = &key
+	}
+	body, err := json.Marshal(struct {
+		Records []record `json:"records"`
+	}{Records: []record{rec}})
+	if err != nil {
+		return err
+	}
+
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	if p.User != "" {
+		req.SetBasicAuth(p.User, p.Password)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("kafka rest proxy returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	// A record the proxy failed to produce is reported in its offset rather than by the status
+	var result struct {
+		Offsets []struct {
+			ErrorCode *int   `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return fmt.Errorf("decode kafka rest proxy response: %w", err)
+	}
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("kafka rejected the message (%d): %s", *offset.ErrorCode, offset.Error)
+		}
+	}
+	return nil
+}
+
+// Close releases nothing, the proxy being reached over HTTP
+func (p *KafkaPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,186 @@
+package broker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsDefaultPort is the client port of a NATS server
+const natsDefaultPort = "4222"
+
+// NATSPublisher publishes messages to a NATS server over its text protocol, so no NATS client is linked into
+// the service. Each publication is followed by a PING and waits for the PONG, which the server sends once
+// the message is processed, so a publication that returns nil reached the server. The connection is opened
+// on first use and again after a failure.
+type NATSPublisher struct {
+	address  string
+	user     string
+	password string
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSPublisher creates a publisher of the server at a nats://[user:password@]host[:port] address; the
+// user and password given take precedence over the ones of the address
+func NewNATSPublisher(address, user, password string, timeout time.Duration) (*NATSPublisher, error) {
+	parsed, err := url.Parse(address)
+	if err != nil || parsed.Scheme != "nats" || parsed.Hostname() == "" {
+		return nil, fmt.Errorf("invalid NATS address %q", address)
+	}
+	host := parsed.Host
+	if parsed.Port() == "" {
+		host = net.JoinHostPort(parsed.Hostname(), natsDefaultPort)
+	}
+	if user == "" && parsed.User != nil {
+		user = parsed.User.Username()
+		password, _ = parsed.User.Password()
+	}
+	return &NATSPublisher{address: host, user: user, password: password, timeout: timeout}, nil
+}
+
+// Publish sends a message on the subject; NATS has no message keys, so the key is ignored
+func (p *NATSPublisher) Publish(ctx context.Context, subject, _ string, payload []byte) error {
+	if strings.ContainsAny(subject, " \t\r\n") {
+		return fmt.Errorf("invalid NATS subject %q", subject)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	p.conn.SetDeadline(p.deadline(ctx))
+	message := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload)
+	if _, err := p.conn.Write([]byte(message)); err != nil {
+		p.reset()
+		return err
+	}
+	if err := p.awaitPong(); err != nil {
+		p.reset()
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn, p.reader = nil, nil
+	return err
+}
+
+// connect opens the connection and authenticates
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: p.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return err
+	}
+	p.conn, p.reader = conn, bufio.NewReader(conn)
+	p.conn.SetDeadline(p.deadline(ctx))
+
+	line, err := p.readLine()
+	if err != nil {
+		p.reset()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		p.reset()
+		return fmt.Errorf("unexpected NATS greeting %q", line)
+	}
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info); err == nil && info.TLSRequired {
+		p.reset()
+		return errors.New("the NATS server requires TLS, which is not supported")
+	}
+	options, err := json.Marshal(map[string]any{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "music-library",
+		"lang":     "go",
+		"user":     p.user,
+		"pass":     p.password,
+	})
+	if err != nil {
+		p.reset()
+		return err
+	}
+	if _, err := p.conn.Write([]byte("CONNECT " + string(options) + "\r\nPING\r\n")); err != nil {
+		p.reset()
+		return err
+	}
+	if err := p.awaitPong(); err != nil {
+		p.reset()
+		return err
+	}
+	return nil
+}
+
+// awaitPong reads until the PONG answering our PING, answering the pings of the server meanwhile
+func (p *NATSPublisher) awaitPong() error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", strings.Trim(strings.TrimPrefix(line, "-ERR"), " '"))
+		}
+		// INFO updates and +OK acknowledgements need no answer
+	}
+}
+
+// readLine reads a protocol line without its CRLF
+func (p *NATSPublisher) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// deadline bounds the exchanges of a publication by the timeout and ctx
+func (p *NATSPublisher) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(p.timeout)
+	if p.timeout <= 0 {
+		deadline = time.Time{}
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+	return deadline
+}
+
+// reset drops a connection left in an unknown state, so the next publication reconnects
+func (p *NATSPublisher) reset() {
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	p.conn, p.reader = nil, nil
+}
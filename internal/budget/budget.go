@@ -0,0 +1,189 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrExhausted is returned when the daily budget of a provider is spent
+var ErrExhausted = errors.New("provider budget exhausted")
+
+// Limit is the number of calls a provider accepts per minute and per day; zero means unlimited
+type Limit struct {
+	PerMinute int
+	PerDay    int
+}
+
+// Store persists daily call counters, so budgets survive restarts and are shared between instances
+type Store interface {
+	// IncrementProviderUsage counts a call to the provider on the day and returns the day's total
+	IncrementProviderUsage(ctx context.Context, provider string, day time.Time) (int, error)
+	// GetProviderUsage returns the number of calls counted for the provider on the day
+	GetProviderUsage(ctx context.Context, provider string, day time.Time) (int, error)
+}
+
+// Status is the current budget usage of a provider
+type Status struct {
+	Provider    string    `json:"provider" example:"external_api"`
+	PerMinute   int       `json:"per_minute" example:"60"`
+	PerDay      int       `json:"per_day" example:"10000"`
+	MinuteUsed  int       `json:"minute_used" example:"12"`
+	DayUsed     int       `json:"day_used" example:"4210"`
+	Queued      int       `json:"queued"`
+	Exhausted   bool      `json:"exhausted"`
+	DayResetsAt time.Time `json:"day_resets_at" example:"2024-01-16T00:00:00Z"`
+}
+
+// counter tracks the calls made to a provider in the current minute and day
+type counter struct {
+	minuteStart time.Time
+	dayStart    time.Time
+	minute      int
+	day         int
+	queued      int
+}
+
+// Manager hands out calls to external providers within their budgets. Calls beyond the per-minute
+// budget are queued until the next minute; once the daily budget is spent, calls fail with ErrExhausted.
+type Manager struct {
+	store  Store
+	logger *zap.Logger
+	limits map[string]Limit
+	now    func() time.Time
+
+	mu       sync.Mutex
+	counters map[string]*counter
+}
+
+// NewManager creates a Manager enforcing the limits; providers without a limit are unlimited but still counted.
+// The store may be nil, in which case counters are kept in memory only.
+func NewManager(store Store, logger *zap.Logger, limits map[string]Limit) *Manager {
+	if limits == nil {
+		limits = make(map[string]Limit)
+	}
+	return &Manager{
+		store:    store,
+		logger:   logger,
+		limits:   limits,
+		now:      func() time.Time { return time.Now().UTC() },
+		counters: make(map[string]*counter),
+	}
+}
+
+// Acquire consumes one call from the provider's budget, waiting while the per-minute budget is spent.
+// It returns ErrExhausted when the daily budget is spent, or the context error when ctx ends while queued.
+func (m *Manager) Acquire(ctx context.Context, provider string) error {
+	limit := m.limits[provider]
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m.mu.Lock()
+		now := m.now()
+		c := m.counter(ctx, provider, now)
+		if limit.PerDay > 0 && c.day >= limit.PerDay {
+			m.mu.Unlock()
+			return ErrExhausted
+		}
+		if limit.PerMinute == 0 || c.minute < limit.PerMinute {
+			c.minute++
+			c.day++
+			day := c.dayStart
+			m.mu.Unlock()
+			m.persist(ctx, provider, day)
+			return nil
+		}
+		c.queued++
+		wait := c.minuteStart.Add(time.Minute).Sub(now)
+		m.mu.Unlock()
+
+		m.logger.Debug("Provider budget spent for this minute, queueing call", zap.String("provider", provider), zap.Duration("wait", wait))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		m.mu.Lock()
+		c.queued--
+		m.mu.Unlock()
+	}
+}
+
+// counter returns the provider's counter, starting new windows as time passes. The day count is
+// loaded from the store when a day starts. The caller holds m.mu.
+func (m *Manager) counter(ctx context.Context, provider string, now time.Time) *counter {
+	c, ok := m.counters[provider]
+	if !ok {
+		c = &counter{}
+		m.counters[provider] = c
+	}
+	if minute := now.Truncate(time.Minute); !c.minuteStart.Equal(minute) {
+		c.minuteStart, c.minute = minute, 0
+	}
+	if day := now.Truncate(24 * time.Hour); !c.dayStart.Equal(day) {
+		c.dayStart, c.day = day, 0
+		if m.store != nil {
+			calls, err := m.store.GetProviderUsage(ctx, provider, day)
+			if err != nil {
+				m.logger.Error("Failed to load provider usage", zap.String("provider", provider), zap.Error(err))
+			}
+			c.day = calls
+		}
+	}
+	return c
+}
+
+// persist counts the call in the store and adopts the stored total, which includes calls from other instances
+func (m *Manager) persist(ctx context.Context, provider string, day time.Time) {
+	if m.store == nil {
+		return
+	}
+	calls, err := m.store.IncrementProviderUsage(ctx, provider, day)
+	if err != nil {
+		m.logger.Error("Failed to persist provider usage", zap.String("provider", provider), zap.Error(err))
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c := m.counters[provider]; c.dayStart.Equal(day) && calls > c.day {
+		c.day = calls
+	}
+}
+
+// Status returns the budget usage of every configured or used provider, sorted by name
+func (m *Manager) Status(ctx context.Context) []Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	providers := make(map[string]struct{})
+	for provider := range m.limits {
+		providers[provider] = struct{}{}
+	}
+	for provider := range m.counters {
+		providers[provider] = struct{}{}
+	}
+
+	statuses := make([]Status, 0, len(providers))
+	for provider := range providers {
+		limit := m.limits[provider]
+		c := m.counter(ctx, provider, now)
+		statuses = append(statuses, Status{
+			Provider:    provider,
+			PerMinute:   limit.PerMinute,
+			PerDay:      limit.PerDay,
+			MinuteUsed:  c.minute,
+			DayUsed:     c.day,
+			Queued:      c.queued,
+			Exhausted:   limit.PerDay > 0 && c.day >= limit.PerDay,
+			DayResetsAt: c.dayStart.Add(24 * time.Hour),
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Provider < statuses[j].Provider })
+	return statuses
+}
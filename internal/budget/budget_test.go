@@ -0,0 +1,74 @@
+package budget
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type memoryStore struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (s *memoryStore) IncrementProviderUsage(_ context.Context, provider string, day time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[provider+day.Format(time.DateOnly)]++
+	return s.calls[provider+day.Format(time.DateOnly)], nil
+}
+
+func (s *memoryStore) GetProviderUsage(_ context.Context, provider string, day time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[provider+day.Format(time.DateOnly)], nil
+}
+
+func TestManagerDailyBudgetSurvivesRestart(t *testing.T) {
+	store := &memoryStore{calls: map[string]int{}}
+	limits := map[string]Limit{"api": {PerDay: 3}}
+
+	first := NewManager(store, zap.NewNop(), limits)
+	require.NoError(t, first.Acquire(context.Background(), "api"))
+	require.NoError(t, first.Acquire(context.Background(), "api"))
+
+	restarted := NewManager(store, zap.NewNop(), limits)
+	require.NoError(t, restarted.Acquire(context.Background(), "api"))
+	assert.ErrorIs(t, restarted.Acquire(context.Background(), "api"), ErrExhausted)
+
+	status := restarted.Status(context.Background())
+	require.Len(t, status, 1)
+	assert.Equal(t, 3, status[0].DayUsed)
+	assert.True(t, status[0].Exhausted)
+}
+
+func TestManagerQueuesBeyondMinuteBudget(t *testing.T) {
+	manager := NewManager(nil, zap.NewNop(), map[string]Limit{"api": {PerMinute: 1}})
+	now := time.Date(2024, time.January, 15, 12, 0, 30, 0, time.UTC)
+	manager.now = func() time.Time { return now }
+	require.NoError(t, manager.Acquire(context.Background(), "api"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- manager.Acquire(ctx, "api") }()
+	require.Eventually(t, func() bool { return manager.Status(context.Background())[0].Queued == 1 }, time.Second, 5*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, 0, manager.Status(context.Background())[0].Queued)
+}
+
+func TestManagerUnlimitedProvidersAreCounted(t *testing.T) {
+	manager := NewManager(nil, zap.NewNop(), nil)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, manager.Acquire(context.Background(), "api"))
+	}
+	status := manager.Status(context.Background())
+	require.Len(t, status, 1)
+	assert.Equal(t, 5, status[0].MinuteUsed)
+	assert.False(t, status[0].Exhausted)
+}
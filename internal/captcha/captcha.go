@@ -0,0 +1,110 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Supported verification providers
+const (
+	ProviderHCaptcha  = "hcaptcha"
+	ProviderTurnstile = "turnstile"
+)
+
+// Siteverify endpoints of the supported providers
+const (
+	HCaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+	TurnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+// ErrRejected is returned when the provider does not accept a token as proof of a human
+var ErrRejected = errors.New("verification token rejected")
+
+// Verifier checks the token a client obtained by solving a challenge
+type Verifier interface {
+	// Name identifies the provider, so clients know which challenge to solve
+	Name() string
+	// Verify returns ErrRejected when the token does not prove a solved challenge, or another error
+	// when the provider could not be asked
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// SiteverifyVerifier checks tokens against a siteverify endpoint, the API shared by hCaptcha and Turnstile.
+// It posts the secret, the token as "response" and the client IP as "remoteip", and expects
+// {"success": true} back for valid tokens.
+type SiteverifyVerifier struct {
+	Provider string
+	URL      string
+	Secret   string
+	Client   *http.Client
+}
+
+// Name returns the provider name
+func (v *SiteverifyVerifier) Name() string {
+	return v.Provider
+}
+
+// Verify asks the provider whether the token is valid
+func (v *SiteverifyVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	form := url.Values{"secret": {v.Secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s siteverify returned %d", v.Provider, resp.StatusCode)
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrRejected, strings.Join(result.ErrorCodes, ", "))
+	}
+	return nil
+}
+
+// FromEnv builds the verifier selected by CAPTCHA_PROVIDER ("hcaptcha" or "turnstile") with the secret
+// in CAPTCHA_SECRET, returning nil when no provider is set. CAPTCHA_VERIFY_URL overrides the provider's
+// siteverify endpoint.
+func FromEnv(client *http.Client) (Verifier, error) {
+	provider := os.Getenv("CAPTCHA_PROVIDER")
+	var verifyURL string
+	switch provider {
+	case "":
+		return nil, nil
+	case ProviderHCaptcha:
+		verifyURL = HCaptchaVerifyURL
+	case ProviderTurnstile:
+		verifyURL = TurnstileVerifyURL
+	default:
+		return nil, fmt.Errorf("unsupported captcha provider %q", provider)
+	}
+	secret := os.Getenv("CAPTCHA_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("CAPTCHA_SECRET is required for the %s provider", provider)
+	}
+	if override := os.Getenv("CAPTCHA_VERIFY_URL"); override != "" {
+		verifyURL = override
+	}
+	return &SiteverifyVerifier{Provider: provider, URL: verifyURL, Secret: secret, Client: client}, nil
+}
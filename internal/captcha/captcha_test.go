@@ -0,0 +1,57 @@
+package captcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteverifyVerifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "secret", r.PostForm.Get("secret"))
+		assert.Equal(t, "10.0.0.1", r.PostForm.Get("remoteip"))
+		if r.PostForm.Get("response") == "solved" {
+			w.Write([]byte(`{"success": true}`))
+			return
+		}
+		w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+	}))
+	defer server.Close()
+	verifier := &SiteverifyVerifier{Provider: ProviderTurnstile, URL: server.URL, Secret: "secret", Client: server.Client()}
+
+	assert.NoError(t, verifier.Verify(context.Background(), "solved", "10.0.0.1"))
+	err := verifier.Verify(context.Background(), "guessed", "10.0.0.1")
+	assert.ErrorIs(t, err, ErrRejected)
+	assert.ErrorContains(t, err, "invalid-input-response")
+
+	server.Close()
+	err = verifier.Verify(context.Background(), "solved", "10.0.0.1")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRejected, "an unreachable provider is not a rejection")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("CAPTCHA_PROVIDER", "")
+	verifier, err := FromEnv(http.DefaultClient)
+	require.NoError(t, err)
+	assert.Nil(t, verifier)
+
+	t.Setenv("CAPTCHA_PROVIDER", ProviderHCaptcha)
+	_, err = FromEnv(http.DefaultClient)
+	assert.Error(t, err, "the secret is required")
+
+	t.Setenv("CAPTCHA_SECRET", "secret")
+	verifier, err = FromEnv(http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderHCaptcha, verifier.Name())
+	assert.Equal(t, HCaptchaVerifyURL, verifier.(*SiteverifyVerifier).URL)
+
+	t.Setenv("CAPTCHA_PROVIDER", "recaptcha")
+	_, err = FromEnv(http.DefaultClient)
+	assert.Error(t, err)
+}
@@ -0,0 +1,295 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// Redacted replaces the values of credentials in captured requests and responses
+const Redacted = "[REDACTED]"
+
+// sensitiveParts mark header, query, form and JSON field names holding credentials
+var sensitiveParts = []string{"authorization", "authentication", "token", "secret", "apikey", "api_key", "api-key",
+	"password", "cookie", "session", "signature"}
+
+// sensitiveNames are credential names too short to be matched as parts, like the AcoustID application key
+var sensitiveNames = map[string]bool{"key": true, "client": true}
+
+// Store keeps the captured exchanges
+type Store interface {
+	// AddAPICapture stores a capture, keeping only the newest keep ones
+	AddAPICapture(ctx context.Context, capture models.APICapture, keep int) error
+}
+
+// Config sets how much traffic is captured
+type Config struct {
+	// SampleRate is the fraction of requests captured, from 0 (capture off) to 1 (every request)
+	SampleRate float64
+	// MaxBody is the number of bytes of a body stored, the rest is cut off
+	MaxBody int
+	// Keep is the number of captures stored, older ones are dropped
+	Keep int
+}
+
+// DefaultConfig keeps capture off
+var DefaultConfig = Config{
+	SampleRate: 0,
+	MaxBody:    64 << 10,
+	Keep:       1000,
+}
+
+// ConfigFromEnv reads the capture configuration from API_CAPTURE_SAMPLE_RATE, API_CAPTURE_MAX_BODY and
+// API_CAPTURE_KEEP
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig
+	var err error
+	if value := os.Getenv("API_CAPTURE_SAMPLE_RATE"); value != "" {
+		if cfg.SampleRate, err = strconv.ParseFloat(value, 64); err != nil || cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+			return cfg, fmt.Errorf("API_CAPTURE_SAMPLE_RATE must be a number from 0 to 1: %q", value)
+		}
+	}
+	if value := os.Getenv("API_CAPTURE_MAX_BODY"); value != "" {
+		if cfg.MaxBody, err = strconv.Atoi(value); err != nil || cfg.MaxBody < 0 {
+			return cfg, fmt.Errorf("API_CAPTURE_MAX_BODY must be a non-negative number: %q", value)
+		}
+	}
+	if value := os.Getenv("API_CAPTURE_KEEP"); value != "" {
+		if cfg.Keep, err = strconv.Atoi(value); err != nil || cfg.Keep < 1 {
+			return cfg, fmt.Errorf("API_CAPTURE_KEEP must be a positive number: %q", value)
+		}
+	}
+	return cfg, nil
+}
+
+// Recorder captures a sample of the exchanges of the HTTP clients it wraps
+type Recorder struct {
+	store  Store
+	logger *zap.Logger
+	cfg    Config
+	// sample reports whether the next request is captured
+	sample func() bool
+}
+
+// NewRecorder creates a recorder storing its captures in the store
+func NewRecorder(store Store, logger *zap.Logger, cfg Config) *Recorder {
+	return &Recorder{
+		store:  store,
+		logger: logger,
+		cfg:    cfg,
+		sample: func() bool { return rand.Float64() < cfg.SampleRate },
+	}
+}
+
+// Wrap returns a copy of the client whose requests are sampled under the provider name. The client is
+// returned as is when the recorder is nil or its sample rate is zero, so capture costs nothing while off.
+func (r *Recorder) Wrap(provider string, client *http.Client) *http.Client {
+	if r == nil || r.cfg.SampleRate <= 0 {
+		return client
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped := *client
+	wrapped.Transport = &transport{recorder: r, provider: provider, base: base}
+	return &wrapped
+}
+
+// transport captures the sampled requests it sends
+type transport struct {
+	recorder *Recorder
+	provider string
+	base     http.RoundTripper
+}
+
+// RoundTrip sends the request, capturing it with its response when it is sampled. The response body is
+// read to be captured and handed to the caller from memory.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.recorder.sample() {
+		return t.base.RoundTrip(req)
+	}
+
+	var requestBody []byte
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			requestBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	capture := models.APICapture{
+		Provider:       t.provider,
+		Method:         req.Method,
+		URL:            redactURL(req.URL),
+		RequestHeaders: redactHeaders(req.Header),
+		RequestBody:    t.recorder.body(requestBody, req.Header.Get("Content-Type")),
+	}
+	if err != nil {
+		message := err.Error()
+		capture.Error = &message
+	} else {
+		responseBody, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		var body io.Reader = bytes.NewReader(responseBody)
+		if readErr != nil {
+			// The caller meets the same failure after reading what arrived
+			body = io.MultiReader(body, errorReader{readErr})
+			message := readErr.Error()
+			capture.Error = &message
+		}
+		resp.Body = io.NopCloser(body)
+		status := resp.StatusCode
+		headers := redactHeaders(resp.Header)
+		capture.Status = &status
+		capture.ResponseHeaders = &headers
+		capture.ResponseBody = t.recorder.body(responseBody, resp.Header.Get("Content-Type"))
+	}
+	capture.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+
+	// A capture is diagnostics only, so storing it neither follows the request's cancellation nor fails it
+	if storeErr := t.recorder.store.AddAPICapture(context.WithoutCancel(req.Context()), capture, t.recorder.cfg.Keep); storeErr != nil {
+		t.recorder.logger.Warn("Failed to store API capture", zap.String("provider", t.provider), zap.Error(storeErr))
+	}
+	return resp, err
+}
+
+// errorReader fails every read with its error
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// body prepares a captured body for storage: credentials are redacted from form and JSON bodies, binary
+// bodies are replaced by their size and long ones are cut off at MaxBody bytes
+func (r *Recorder) body(data []byte, contentType string) *string {
+	if len(data) == 0 {
+		return nil
+	}
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		text := fmt.Sprintf("[binary body, %d bytes]", len(data))
+		return &text
+	}
+	data = redactBody(data, contentType)
+	text := string(data)
+	if len(data) > r.cfg.MaxBody {
+		// Cut on a rune boundary so the stored text stays valid UTF-8
+		cut := r.cfg.MaxBody
+		for cut > 0 && !utf8.RuneStart(data[cut]) {
+			cut--
+		}
+		text = fmt.Sprintf("%s[truncated, %d bytes]", data[:cut], len(data))
+	}
+	return &text
+}
+
+// sensitive reports whether a header, parameter or field name holds a credential
+func sensitive(name string) bool {
+	name = strings.ToLower(name)
+	if sensitiveNames[name] {
+		return true
+	}
+	for _, part := range sensitiveParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeaders serializes the headers with the values of credentials redacted
+func redactHeaders(header http.Header) json.RawMessage {
+	redacted := make(map[string][]string, len(header))
+	for name, values := range header {
+		if sensitive(name) {
+			values = []string{Redacted}
+		}
+		redacted[name] = values
+	}
+	data, _ := json.Marshal(redacted)
+	return data
+}
+
+// redactURL returns the URL with user info and credential query parameters redacted
+func redactURL(u *url.URL) string {
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User(Redacted)
+	}
+	if redacted.RawQuery != "" {
+		redacted.RawQuery = redactValues(redacted.Query()).Encode()
+	}
+	return redacted.String()
+}
+
+// redactValues redacts the credential parameters of a query string or form
+func redactValues(values url.Values) url.Values {
+	for name := range values {
+		if sensitive(name) {
+			values[name] = []string{Redacted}
+		}
+	}
+	return values
+}
+
+// redactBody redacts the credentials of a form or JSON body, returning other bodies as they are
+func redactBody(data []byte, contentType string) []byte {
+	switch {
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		values, err := url.ParseQuery(string(data))
+		if err != nil {
+			return data
+		}
+		return []byte(redactValues(values).Encode())
+	case strings.Contains(contentType, "json"):
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var tree any
+		if err := decoder.Decode(&tree); err != nil {
+			return data
+		}
+		redacted, err := json.Marshal(redactJSON(tree))
+		if err != nil {
+			return data
+		}
+		return redacted
+	}
+	return data
+}
+
+// redactJSON redacts the credential fields of every object in the decoded JSON value. Only string values are
+// taken for credentials, so data like the musical key of a track is kept.
+func redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if _, isString := item.(string); isString && sensitive(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = redactJSON(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,136 @@
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// memoryStore keeps captures in memory
+type memoryStore struct {
+	mu       sync.Mutex
+	captures []models.APICapture
+}
+
+func (s *memoryStore) AddAPICapture(_ context.Context, capture models.APICapture, keep int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.captures = append(s.captures, capture)
+	if len(s.captures) > keep {
+		s.captures = s.captures[len(s.captures)-keep:]
+	}
+	return nil
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Write([]byte(`{"access_token":"secret-token","key":5,"text":"Ooh baby"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRecorderCapturesRedacted(t *testing.T) {
+	server := newTestServer(t)
+	store := &memoryStore{}
+	recorder := NewRecorder(store, zap.NewNop(), Config{SampleRate: 1, MaxBody: 1024, Keep: 10})
+	client := recorder.Wrap("spotify", &http.Client{})
+
+	form := url.Values{"client": {"app-key"}, "grant_type": {"client_credentials"}}
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/token?api_key=k&group=Muse", strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer xyz")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "secret-token", "the caller gets the response untouched")
+
+	require.Len(t, store.captures, 1)
+	capture := store.captures[0]
+	assert.Equal(t, "spotify", capture.Provider)
+	assert.Equal(t, http.MethodPost, capture.Method)
+	assert.Contains(t, capture.URL, "group=Muse")
+	assert.NotContains(t, capture.URL, "api_key=k")
+
+	var headers map[string][]string
+	require.NoError(t, json.Unmarshal(capture.RequestHeaders, &headers))
+	assert.Equal(t, []string{Redacted}, headers["Authorization"])
+	require.NotNil(t, capture.RequestBody)
+	assert.NotContains(t, *capture.RequestBody, "app-key")
+	assert.Contains(t, *capture.RequestBody, "grant_type=client_credentials")
+
+	require.NotNil(t, capture.Status)
+	assert.Equal(t, http.StatusOK, *capture.Status)
+	require.NotNil(t, capture.ResponseHeaders)
+	require.NoError(t, json.Unmarshal(*capture.ResponseHeaders, &headers))
+	assert.Equal(t, []string{Redacted}, headers["Set-Cookie"])
+	require.NotNil(t, capture.ResponseBody)
+	assert.NotContains(t, *capture.ResponseBody, "secret-token")
+	assert.Contains(t, *capture.ResponseBody, `"key":5`, "non-string fields are kept")
+	assert.Nil(t, capture.Error)
+}
+
+func TestRecorderSampling(t *testing.T) {
+	server := newTestServer(t)
+	client := &http.Client{}
+	assert.Same(t, client, (*Recorder)(nil).Wrap("lyrics", client))
+	assert.Same(t, client, NewRecorder(&memoryStore{}, zap.NewNop(), DefaultConfig).Wrap("lyrics", client),
+		"capture is off by default")
+
+	store := &memoryStore{}
+	recorder := NewRecorder(store, zap.NewNop(), Config{SampleRate: 0.5, MaxBody: 1024, Keep: 10})
+	sampled := false
+	recorder.sample = func() bool {
+		sampled = !sampled
+		return sampled
+	}
+	wrapped := recorder.Wrap("lyrics", client)
+	for i := 0; i < 4; i++ {
+		resp, err := wrapped.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Len(t, store.captures, 2)
+}
+
+func TestRecorderTruncatesBodies(t *testing.T) {
+	recorder := NewRecorder(&memoryStore{}, zap.NewNop(), Config{SampleRate: 1, MaxBody: 4})
+
+	body := recorder.body([]byte("Ünïcode text"), "text/plain")
+	require.NotNil(t, body)
+	assert.Equal(t, "Ün[truncated, 14 bytes]", *body, "the cut keeps whole characters")
+
+	body = recorder.body([]byte{0x00, 0xff, 0x10}, "application/octet-stream")
+	require.NotNil(t, body)
+	assert.Equal(t, "[binary body, 3 bytes]", *body)
+
+	assert.Nil(t, recorder.body(nil, "text/plain"))
+}
+
+func TestRecorderCapturesFailures(t *testing.T) {
+	store := &memoryStore{}
+	recorder := NewRecorder(store, zap.NewNop(), Config{SampleRate: 1, MaxBody: 1024, Keep: 10})
+	client := recorder.Wrap("acoustid", &http.Client{})
+
+	_, err := client.Get("http://127.0.0.1:1/lookup")
+	require.Error(t, err)
+	require.Len(t, store.captures, 1)
+	assert.Nil(t, store.captures[0].Status)
+	assert.NotNil(t, store.captures[0].Error)
+}
@@ -0,0 +1,68 @@
+package changes
+
+import (
+	"time"
+
+	"music-library/internal/analytics"
+)
+
+// Catalog event types, the names under which changes are pushed to clients and webhooks
+const (
+	EventSongCreated    = "song.created"
+	EventSongUpdated    = "song.updated"
+	EventSongDeleted    = "song.deleted"
+	EventSongsImported  = "songs.imported"
+	EventSongsTruncated = "songs.truncated"
+	// EventResync tells a client that changes were missed, so it must reload its state
+	EventResync = "resync"
+)
+
+// eventTypes maps the change types published by the service to the catalog event types
+var eventTypes = map[string]string{
+	analytics.EventSongAdded:     EventSongCreated,
+	analytics.EventSongUpdated:   EventSongUpdated,
+	analytics.EventSongDeleted:   EventSongDeleted,
+	analytics.EventSongsImported: EventSongsImported,
+	analytics.EventSongsCleared:  EventSongsTruncated,
+}
+
+// IsEventType reports whether the name is one of the catalog event types
+func IsEventType(name string) bool {
+	for _, eventType := range eventTypes {
+		if eventType == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeTypeOf returns the change type published by the service for a catalog event type, and false for
+// names that are not catalog event types
+func ChangeTypeOf(eventType string) (string, bool) {
+	for changeType, name := range eventTypes {
+		if name == eventType {
+			return changeType, true
+		}
+	}
+	return "", false
+}
+
+// Event is a change to the catalog as pushed to clients and webhooks. ID is the change cursor also used by
+// GET /changes/poll for events read from a hub, and the outbox ID for events relayed from the outbox; SongID
+// is zero for events not tied to a single song.
+type Event struct {
+	ID         uint64    `json:"id" example:"42"`
+	Type       string    `json:"type" example:"song.updated"`
+	SongID     int       `json:"song_id,omitempty" example:"1"`
+	Count      int       `json:"count,omitempty" example:"1"`
+	OccurredAt time.Time `json:"occurred_at" example:"2024-01-15T12:00:00Z"`
+}
+
+// EventOf returns the catalog event of a change, and false for changes not pushed as events
+func EventOf(change Change) (Event, bool) {
+	eventType, ok := eventTypes[change.Type]
+	if !ok {
+		return Event{}, false
+	}
+	return Event{ID: change.ID, Type: eventType, SongID: change.SongID, Count: change.Count, OccurredAt: change.OccurredAt}, true
+}
@@ -0,0 +1,121 @@
+package changes
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultBufferSize is the number of recent changes a hub keeps for clients catching up
+const DefaultBufferSize = 1024
+
+// Change is a single change to the library. IDs increase by one with every change published by the process,
+// so a client resumes from the last ID it saw. SongID is zero for changes not tied to a song.
+type Change struct {
+	ID         uint64    `json:"id" example:"42"`
+	Type       string    `json:"type" example:"song.updated"`
+	SongID     int       `json:"song_id,omitempty" example:"1"`
+	Count      int       `json:"count,omitempty" example:"1"`
+	OccurredAt time.Time `json:"occurred_at" example:"2024-01-15T12:00:00Z"`
+}
+
+// Batch is the answer to a client asking for the changes after a cursor
+type Batch struct {
+	Changes []Change `json:"changes"`
+	// Next is the cursor to ask for the following changes with
+	Next uint64 `json:"next" example:"42"`
+	// Missed is set when changes after the cursor are no longer buffered, or the cursor is from before
+	// a restart, so the client must reload its state instead of applying the changes
+	Missed bool `json:"missed"`
+}
+
+// Hub is the in-process pub/sub hub of library changes. It keeps the most recent changes in a ring buffer
+// and wakes every waiting subscriber when a change is published.
+type Hub struct {
+	mu      sync.Mutex
+	buffer  []Change
+	last    uint64
+	updated chan struct{}
+	now     func() time.Time
+}
+
+// NewHub creates a Hub keeping the size most recent changes
+func NewHub(size int) *Hub {
+	if size < 1 {
+		size = DefaultBufferSize
+	}
+	return &Hub{
+		buffer:  make([]Change, 0, size),
+		updated: make(chan struct{}),
+		now:     time.Now,
+	}
+}
+
+// Publish records a change and wakes the waiting subscribers
+func (h *Hub) Publish(changeType string, songID, count int) Change {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last++
+	change := Change{ID: h.last, Type: changeType, SongID: songID, Count: count, OccurredAt: h.now().UTC()}
+	if len(h.buffer) == cap(h.buffer) {
+		copy(h.buffer, h.buffer[1:])
+		h.buffer = h.buffer[:len(h.buffer)-1]
+	}
+	h.buffer = append(h.buffer, change)
+	close(h.updated)
+	h.updated = make(chan struct{})
+	return change
+}
+
+// Last returns the ID of the latest change, the cursor of a client that wants only future changes
+func (h *Hub) Last() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last
+}
+
+// Since returns the buffered changes after the cursor
+func (h *Hub) Since(cursor uint64) Batch {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	batch, _ := h.since(cursor)
+	return batch
+}
+
+// Wait returns the changes after the cursor, blocking until there is at least one or ctx is done,
+// in which case the batch is empty
+func (h *Hub) Wait(ctx context.Context, cursor uint64) Batch {
+	for {
+		h.mu.Lock()
+		batch, updated := h.since(cursor)
+		h.mu.Unlock()
+		if len(batch.Changes) > 0 || batch.Missed {
+			return batch
+		}
+		select {
+		case <-ctx.Done():
+			return batch
+		case <-updated:
+		}
+	}
+}
+
+// since collects the changes after the cursor and returns the channel closed by the next publish.
+// The caller holds mu.
+func (h *Hub) since(cursor uint64) (Batch, <-chan struct{}) {
+	batch := Batch{Changes: []Change{}, Next: h.last}
+	if cursor > h.last {
+		// The cursor was handed out before a restart
+		batch.Missed = true
+		return batch, h.updated
+	}
+	if len(h.buffer) > 0 && cursor+1 < h.buffer[0].ID {
+		batch.Missed = true
+	}
+	for _, change := range h.buffer {
+		if change.ID > cursor {
+			batch.Changes = append(batch.Changes, change)
+		}
+	}
+	return batch, h.updated
+}
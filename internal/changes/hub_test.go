@@ -0,0 +1,50 @@
+package changes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHubWait(t *testing.T) {
+	hub := NewHub(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	batch := hub.Wait(ctx, hub.Last())
+	assert.Empty(t, batch.Changes, "an empty batch is returned once ctx is done")
+	assert.Equal(t, uint64(0), batch.Next)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		hub.Publish("song_added", 7, 1)
+	}()
+	batch = hub.Wait(context.Background(), 0)
+	require.Len(t, batch.Changes, 1)
+	assert.Equal(t, Change{ID: 1, Type: "song_added", SongID: 7, Count: 1, OccurredAt: batch.Changes[0].OccurredAt}, batch.Changes[0])
+	assert.Equal(t, uint64(1), batch.Next)
+	assert.False(t, batch.Missed)
+}
+
+func TestHubSinceMissed(t *testing.T) {
+	hub := NewHub(2)
+	for id := 1; id <= 3; id++ {
+		hub.Publish("song_updated", id, 1)
+	}
+
+	batch := hub.Since(1)
+	assert.Len(t, batch.Changes, 2)
+	assert.False(t, batch.Missed)
+
+	batch = hub.Since(0)
+	assert.Len(t, batch.Changes, 2)
+	assert.True(t, batch.Missed, "the first change is no longer buffered")
+
+	batch = hub.Since(10)
+	assert.Empty(t, batch.Changes)
+	assert.True(t, batch.Missed, "a cursor ahead of the hub was handed out before a restart")
+	assert.Equal(t, uint64(3), batch.Next)
+}
@@ -0,0 +1,177 @@
+package classifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Supported classifier providers
+const (
+	ProviderWordlist = "wordlist"
+	ProviderHTTP     = "http"
+	ProviderNone     = "none"
+)
+
+// Suggestion is a genre or mood proposed for a song
+type Suggestion struct {
+	Kind       string  `json:"kind"`
+	Value      string  `json:"value"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Classifier proposes genres and moods for a song from its lyrics
+type Classifier interface {
+	// Name identifies the classifier as the source of its suggestions
+	Name() string
+	// Classify returns the suggestions for the lyrics, possibly none
+	Classify(ctx context.Context, lyrics string) ([]Suggestion, error)
+}
+
+// WordlistClassifier proposes a value when the lyrics contain enough distinct words from its wordlist
+type WordlistClassifier struct {
+	// Wordlists maps a kind to its values and the lowercase words indicating each value
+	Wordlists map[string]map[string][]string
+	// MinMatches is the number of distinct wordlist words the lyrics must contain
+	MinMatches int
+}
+
+// DefaultWordlists are the genre and mood wordlists used when none are configured
+var DefaultWordlists = map[string]map[string][]string{
+	"genre": {
+		"hip-hop":   {"rap", "rhyme", "rhymes", "mic", "beat", "beats", "flow", "hood", "homie", "hustle"},
+		"country":   {"truck", "whiskey", "cowboy", "dirt", "porch", "tractor", "county", "honky", "rodeo", "boots"},
+		"christmas": {"christmas", "santa", "snow", "sleigh", "mistletoe", "reindeer", "jingle", "holly", "presents"},
+		"dance":     {"dance", "dancing", "floor", "club", "party", "dj", "groove", "move", "tonight"},
+		"gospel":    {"lord", "jesus", "heaven", "praise", "glory", "hallelujah", "amen", "spirit", "grace"},
+	},
+	"mood": {
+		"happy":    {"happy", "smile", "sunshine", "laugh", "joy", "bright", "celebrate", "good"},
+		"sad":      {"cry", "tears", "alone", "lonely", "goodbye", "broken", "pain", "sorrow", "miss"},
+		"romantic": {"love", "kiss", "heart", "darling", "baby", "forever", "hold", "arms"},
+		"angry":    {"hate", "rage", "fight", "burn", "scream", "kill", "war", "blood"},
+	},
+}
+
+// NewWordlistClassifier creates a WordlistClassifier with the default wordlists
+func NewWordlistClassifier() *WordlistClassifier {
+	return &WordlistClassifier{Wordlists: DefaultWordlists, MinMatches: 3}
+}
+
+// Name returns the classifier name
+func (c *WordlistClassifier) Name() string {
+	return ProviderWordlist
+}
+
+// Classify counts, for every value, the distinct wordlist words found in the lyrics. Values reaching
+// MinMatches are proposed, with the share of the value's wordlist found as confidence.
+func (c *WordlistClassifier) Classify(ctx context.Context, lyrics string) ([]Suggestion, error) {
+	words := make(map[string]struct{})
+	for _, word := range strings.FieldsFunc(strings.ToLower(lyrics), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-'
+	}) {
+		words[word] = struct{}{}
+	}
+
+	var suggestions []Suggestion
+	for kind, values := range c.Wordlists {
+		for value, wordlist := range values {
+			matches := 0
+			for _, word := range wordlist {
+				if _, ok := words[word]; ok {
+					matches++
+				}
+			}
+			if matches >= c.MinMatches {
+				suggestions = append(suggestions, Suggestion{Kind: kind, Value: value, Confidence: float64(matches) / float64(len(wordlist))})
+			}
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].Kind+suggestions[i].Value < suggestions[j].Kind+suggestions[j].Value
+	})
+	return suggestions, nil
+}
+
+// HTTPClassifier delegates to an external ML service. It posts {"lyrics": "..."} to URL
+// and expects {"suggestions": [{"kind": "genre", "value": "rock", "confidence": 0.9}]} back.
+type HTTPClassifier struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// Name returns the classifier name
+func (c *HTTPClassifier) Name() string {
+	return ProviderHTTP
+}
+
+// Classify sends the lyrics to the external service
+func (c *HTTPClassifier) Classify(ctx context.Context, lyrics string) ([]Suggestion, error) {
+	body, err := json.Marshal(map[string]string{"lyrics": lyrics})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("classifier returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	var result struct {
+		Suggestions []Suggestion `json:"suggestions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	suggestions := result.Suggestions[:0]
+	for _, suggestion := range result.Suggestions {
+		if suggestion.Kind == "" || suggestion.Value == "" {
+			continue
+		}
+		suggestion.Value = strings.ToLower(strings.TrimSpace(suggestion.Value))
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
+
+// FromEnv builds the classifier selected by CLASSIFIER_PROVIDER: the wordlist classifier by default,
+// the external service at CLASSIFIER_URL for "http", or nil for "none".
+func FromEnv(client *http.Client) (Classifier, error) {
+	switch provider := os.Getenv("CLASSIFIER_PROVIDER"); provider {
+	case "", ProviderWordlist:
+		return NewWordlistClassifier(), nil
+	case ProviderHTTP:
+		url := os.Getenv("CLASSIFIER_URL")
+		if url == "" {
+			return nil, fmt.Errorf("CLASSIFIER_URL is required for the %s classifier", provider)
+		}
+		return &HTTPClassifier{URL: url, APIKey: os.Getenv("CLASSIFIER_API_KEY"), Client: client}, nil
+	case ProviderNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported classifier provider %q", provider)
+	}
+}
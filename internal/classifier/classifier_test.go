@@ -0,0 +1,60 @@
+package classifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordlistClassifier(t *testing.T) {
+	c := NewWordlistClassifier()
+	suggestions, err := c.Classify(context.Background(), "Tears on my pillow, I cry alone. Goodbye, my broken heart")
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, "mood", suggestions[0].Kind)
+	assert.Equal(t, "sad", suggestions[0].Value)
+	assert.InDelta(t, 5.0/9, suggestions[0].Confidence, 0.001)
+
+	suggestions, err = c.Classify(context.Background(), "Verse 1\n\nVerse 2")
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+}
+
+func TestHTTPClassifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		var req struct {
+			Lyrics string `json:"lyrics"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		assert.Equal(t, "lyrics", req.Lyrics)
+		w.Write([]byte(`{"suggestions": [{"kind": "genre", "value": " Rock ", "confidence": 0.9}, {"kind": "mood", "value": ""}]}`))
+	}))
+	defer server.Close()
+
+	c := &HTTPClassifier{URL: server.URL, APIKey: "key", Client: server.Client()}
+	suggestions, err := c.Classify(context.Background(), "lyrics")
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{Kind: "genre", Value: "rock", Confidence: 0.9}}, suggestions)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("CLASSIFIER_PROVIDER", "")
+	c, err := FromEnv(http.DefaultClient)
+	require.NoError(t, err)
+	assert.IsType(t, &WordlistClassifier{}, c)
+
+	t.Setenv("CLASSIFIER_PROVIDER", ProviderHTTP)
+	_, err = FromEnv(http.DefaultClient)
+	assert.Error(t, err)
+
+	t.Setenv("CLASSIFIER_PROVIDER", ProviderNone)
+	c, err = FromEnv(http.DefaultClient)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+}
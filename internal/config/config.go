@@ -0,0 +1,348 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Version is the version of the document format written by Export
+const Version = 1
+
+// Redacted replaces the values of credentials in exported documents
+const Redacted = "[REDACTED]"
+
+// Sections of the configuration document
+const (
+	// SectionRuntime holds the limits, timeouts, pool sizes and middleware chains the instance runs with
+	SectionRuntime = "runtime"
+	// SectionFeatures holds the settings switching optional features and providers on and off
+	SectionFeatures = "features"
+	// SectionSchedulers holds the intervals of the background schedulers
+	SectionSchedulers = "schedulers"
+	// SectionCredentials holds API keys, tokens and secrets, whose values are redacted on export
+	SectionCredentials = "credentials"
+)
+
+// ErrUnsupportedVersion is returned when importing a document of an unknown format version
+var ErrUnsupportedVersion = errors.New("unsupported configuration document version")
+
+// ErrUnknownSetting is returned when importing a document with a setting the instance does not know,
+// or a setting in the wrong section
+var ErrUnknownSetting = errors.New("unknown setting")
+
+// Setting is a configuration setting read from the environment
+type Setting struct {
+	Name    string
+	Section string
+	// Secret settings are credentials, redacted on export
+	Secret bool
+	// Prefix settings stand for every variable whose name starts with Name, like MIDDLEWARE_<GROUP>
+	Prefix bool
+	// Reloadable settings are applied again on reload, without restarting the process; see Reloader
+	Reloadable bool
+}
+
+// Settings are the settings carried by the document. The database connection and listening ports are left
+// out: they belong to the deployment rather than the configuration promoted between deployments.
+var Settings = []Setting{
+	{Name: "LOG_LEVEL", Section: SectionRuntime, Reloadable: true},
+	{Name: "REQUEST_TIMEOUT", Section: SectionRuntime},
+	{Name: "DB_STATEMENT_TIMEOUT", Section: SectionRuntime},
+	{Name: "DB_SERIALIZATION_RETRIES", Section: SectionRuntime},
+	{Name: "SHUTDOWN_TIMEOUT", Section: SectionRuntime},
+	{Name: "DRAIN_PERIOD", Section: SectionRuntime},
+	{Name: "MIDDLEWARE_", Section: SectionRuntime, Prefix: true},
+	{Name: "CORS_ALLOWED_ORIGINS", Section: SectionRuntime},
+	{Name: "ROUTES_STRICT", Section: SectionRuntime},
+	{Name: "RATE_LIMIT_PER_SECOND", Section: SectionRuntime, Reloadable: true},
+	{Name: "RATE_LIMIT_BURST", Section: SectionRuntime, Reloadable: true},
+	{Name: "FIELD_VISIBILITY", Section: SectionRuntime},
+	{Name: "VERSE_DELIMITER", Section: SectionRuntime},
+	{Name: "GROUP_STATS_CACHE_TTL", Section: SectionRuntime, Reloadable: true},
+	{Name: "DEGRADED_CACHE_SIZE", Section: SectionRuntime},
+	{Name: "DEGRADED_CHECK_INTERVAL", Section: SectionRuntime},
+	{Name: "JOB_WORKERS", Section: SectionRuntime},
+	{Name: "JOB_QUEUE_CAPACITY", Section: SectionRuntime},
+	{Name: "JOB_PROGRESS_INTERVAL", Section: SectionRuntime},
+	{Name: "IMPORT_BUFFER_SIZE", Section: SectionRuntime},
+	{Name: "IMPORT_VALIDATE_WORKERS", Section: SectionRuntime},
+	{Name: "IMPORT_BATCH_SIZE", Section: SectionRuntime},
+	{Name: "MIGRATION_CONCURRENT_INDEX_ROWS", Section: SectionRuntime},
+	{Name: "ENRICH_CONCURRENCY", Section: SectionRuntime},
+	{Name: "ENRICH_RATE_PER_SECOND", Section: SectionRuntime},
+	{Name: "ENRICH_TIMEOUT", Section: SectionRuntime},
+	{Name: "ENRICHMENT_WORKERS", Section: SectionRuntime},
+	{Name: "ENRICHMENT_QUEUE_CAPACITY", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_URL", Section: SectionRuntime, Reloadable: true},
+	{Name: "EXTERNAL_API_TIMEOUT", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_AUTH", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_KEY_HEADER", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_KEY_PARAM", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_USERNAME", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_HEADERS", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_PARAMS", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_BUDGET_PER_MINUTE", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_BUDGET_PER_DAY", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_RETRY_ATTEMPTS", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_RETRY_BASE_DELAY", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_RETRY_MAX_DELAY", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_BREAKER_FAILURES", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_BREAKER_OPEN_TIMEOUT", Section: SectionRuntime},
+	{Name: "EXTERNAL_API_BREAKER_HALF_OPEN_CALLS", Section: SectionRuntime},
+	{Name: "API_CAPTURE_SAMPLE_RATE", Section: SectionRuntime},
+	{Name: "API_CAPTURE_MAX_BODY", Section: SectionRuntime},
+	{Name: "API_CAPTURE_KEEP", Section: SectionRuntime},
+	{Name: "FALLBACK_RELEASE_DATE", Section: SectionRuntime, Reloadable: true},
+	{Name: "FALLBACK_TEXT", Section: SectionRuntime, Reloadable: true},
+	{Name: "FALLBACK_LINK", Section: SectionRuntime, Reloadable: true},
+	{Name: "REENRICH_STALE_AFTER", Section: SectionRuntime},
+	{Name: "REENRICH_BATCH_SIZE", Section: SectionRuntime},
+	{Name: "POPULARITY_WEIGHTS", Section: SectionRuntime},
+	{Name: "POPULARITY_BATCH_SIZE", Section: SectionRuntime},
+	{Name: "POPULARITY_CACHE_TTL", Section: SectionRuntime, Reloadable: true},
+	{Name: "LYRICS_API_URL", Section: SectionRuntime, Reloadable: true},
+	{Name: "LYRICS_CACHE_SIZE", Section: SectionRuntime, Reloadable: true},
+	{Name: "LYRICS_CACHE_TTL", Section: SectionRuntime, Reloadable: true},
+	{Name: "SPOTIFY_API_URL", Section: SectionRuntime, Reloadable: true},
+	{Name: "SPOTIFY_TOKEN_URL", Section: SectionRuntime, Reloadable: true},
+	{Name: "ACOUSTID_API_URL", Section: SectionRuntime},
+	{Name: "FPCALC_PATH", Section: SectionRuntime},
+	{Name: "EMBEDDINGS_URL", Section: SectionRuntime},
+	{Name: "EMBEDDINGS_MODEL", Section: SectionRuntime},
+	{Name: "CLASSIFIER_URL", Section: SectionRuntime},
+	{Name: "CAPTCHA_VERIFY_URL", Section: SectionRuntime},
+	{Name: "ANALYTICS_CLICKHOUSE_URL", Section: SectionRuntime},
+	{Name: "ANALYTICS_CLICKHOUSE_USER", Section: SectionRuntime},
+	{Name: "ANALYTICS_CLICKHOUSE_TABLE", Section: SectionRuntime},
+	{Name: "ANALYTICS_BIGQUERY_PROJECT", Section: SectionRuntime},
+	{Name: "ANALYTICS_BIGQUERY_DATASET", Section: SectionRuntime},
+	{Name: "ANALYTICS_BIGQUERY_TABLE", Section: SectionRuntime},
+	{Name: "ANALYTICS_BATCH_SIZE", Section: SectionRuntime},
+	{Name: "ANALYTICS_BUFFER_SIZE", Section: SectionRuntime},
+	{Name: "WEBHOOK_WORKERS", Section: SectionRuntime},
+	{Name: "WEBHOOK_TARGET_CONCURRENCY", Section: SectionRuntime},
+	{Name: "WEBHOOK_TARGET_RATE", Section: SectionRuntime},
+	{Name: "WEBHOOK_SECRET_GRACE_PERIOD", Section: SectionRuntime},
+	{Name: "WEBHOOK_TIMEOUT", Section: SectionRuntime},
+	{Name: "WEBHOOK_MAX_ATTEMPTS", Section: SectionRuntime},
+	{Name: "WEBHOOK_RETRY_BASE_DELAY", Section: SectionRuntime},
+	{Name: "WEBHOOK_RETRY_MAX_DELAY", Section: SectionRuntime},
+	{Name: "EVENT_RETENTION", Section: SectionRuntime},
+	{Name: "TRASH_RETENTION", Section: SectionRuntime},
+	{Name: "OUTBOX_POLL_INTERVAL", Section: SectionRuntime},
+	{Name: "OUTBOX_BATCH_SIZE", Section: SectionRuntime},
+	{Name: "STANDBY_UPLOAD_URL", Section: SectionRuntime},
+	{Name: "BROKER_URL", Section: SectionRuntime},
+	{Name: "BROKER_TOPIC", Section: SectionRuntime},
+	{Name: "BROKER_TIMEOUT", Section: SectionRuntime},
+
+	{Name: "ENRICHMENT_PROVIDERS", Section: SectionFeatures},
+	{Name: "FALLBACK_MODE", Section: SectionFeatures, Reloadable: true},
+	{Name: "LYRICS_ENABLED", Section: SectionFeatures},
+	{Name: "POPULARITY_SOURCE", Section: SectionFeatures},
+	{Name: "EMBEDDINGS_PROVIDER", Section: SectionFeatures},
+	{Name: "CLASSIFIER_PROVIDER", Section: SectionFeatures},
+	{Name: "CAPTCHA_PROVIDER", Section: SectionFeatures},
+	{Name: "ANALYTICS_EXPORTER", Section: SectionFeatures},
+	{Name: "MIGRATION_ENV", Section: SectionFeatures},
+	{Name: "STANDBY_LOG_DIR", Section: SectionFeatures},
+	{Name: "BROKER_KIND", Section: SectionFeatures},
+
+	{Name: "ENRICHMENT_SWEEP_INTERVAL", Section: SectionSchedulers},
+	{Name: "REENRICH_INTERVAL", Section: SectionSchedulers},
+	{Name: "POPULARITY_REFRESH_INTERVAL", Section: SectionSchedulers},
+	{Name: "VIEWS_FLUSH_INTERVAL", Section: SectionSchedulers},
+	{Name: "TRENDING_INTERVAL", Section: SectionSchedulers},
+	{Name: "SEARCH_TERMS_INTERVAL", Section: SectionSchedulers},
+	{Name: "EMBEDDINGS_INTERVAL", Section: SectionSchedulers},
+	{Name: "SPOTIFY_SYNC_INTERVAL", Section: SectionSchedulers},
+	{Name: "ANALYTICS_FLUSH_INTERVAL", Section: SectionSchedulers},
+
+	{Name: "ADMIN_TOKEN", Section: SectionCredentials, Secret: true},
+	{Name: "JWT_SECRET", Section: SectionCredentials, Secret: true},
+	{Name: "EXTERNAL_API_KEY", Section: SectionCredentials, Secret: true},
+	{Name: "EXTERNAL_API_KEY_FILE", Section: SectionCredentials},
+	{Name: "EXTERNAL_API_TOKEN", Section: SectionCredentials, Secret: true},
+	{Name: "EXTERNAL_API_TOKEN_FILE", Section: SectionCredentials},
+	{Name: "EXTERNAL_API_PASSWORD", Section: SectionCredentials, Secret: true},
+	{Name: "EXTERNAL_API_PASSWORD_FILE", Section: SectionCredentials},
+	{Name: "LYRICS_API_TOKEN", Section: SectionCredentials, Secret: true},
+	{Name: "SPOTIFY_CLIENT_ID", Section: SectionCredentials},
+	{Name: "SPOTIFY_CLIENT_SECRET", Section: SectionCredentials, Secret: true},
+	{Name: "ACOUSTID_API_KEY", Section: SectionCredentials, Secret: true},
+	{Name: "EMBEDDINGS_API_KEY", Section: SectionCredentials, Secret: true},
+	{Name: "EMBEDDINGS_API_KEY_FILE", Section: SectionCredentials},
+	{Name: "CLASSIFIER_API_KEY", Section: SectionCredentials, Secret: true},
+	{Name: "CAPTCHA_SECRET", Section: SectionCredentials, Secret: true},
+	{Name: "ANALYTICS_CLICKHOUSE_PASSWORD", Section: SectionCredentials, Secret: true},
+	{Name: "ANALYTICS_CLICKHOUSE_PASSWORD_FILE", Section: SectionCredentials},
+	{Name: "ANALYTICS_BIGQUERY_TOKEN", Section: SectionCredentials, Secret: true},
+	{Name: "ANALYTICS_BIGQUERY_TOKEN_FILE", Section: SectionCredentials},
+	{Name: "STANDBY_UPLOAD_TOKEN", Section: SectionCredentials, Secret: true},
+	{Name: "STANDBY_UPLOAD_TOKEN_FILE", Section: SectionCredentials},
+	{Name: "BROKER_USER", Section: SectionCredentials},
+	{Name: "BROKER_PASSWORD", Section: SectionCredentials, Secret: true},
+	{Name: "BROKER_PASSWORD_FILE", Section: SectionCredentials},
+}
+
+// Document is the portable configuration of an instance. Each section maps the names of the settings set
+// on the instance to their values; settings left out take their defaults.
+type Document struct {
+	Version     int               `json:"version" example:"1"`
+	ExportedAt  time.Time         `json:"exported_at" example:"2024-01-15T12:00:00Z"`
+	Runtime     map[string]string `json:"runtime" example:"REQUEST_TIMEOUT:30s,ENRICHMENT_WORKERS:4"`
+	Features    map[string]string `json:"features" example:"LYRICS_ENABLED:true"`
+	Schedulers  map[string]string `json:"schedulers" example:"TRENDING_INTERVAL:15m"`
+	Credentials map[string]string `json:"credentials" example:"JWT_SECRET:[REDACTED]"`
+}
+
+// section returns the map of the document holding the section
+func (d *Document) section(name string) map[string]string {
+	switch name {
+	case SectionRuntime:
+		return d.Runtime
+	case SectionFeatures:
+		return d.Features
+	case SectionSchedulers:
+		return d.Schedulers
+	case SectionCredentials:
+		return d.Credentials
+	}
+	return nil
+}
+
+// Environ returns the variables of the process environment by name
+func Environ() map[string]string {
+	environ := make(map[string]string)
+	for _, variable := range os.Environ() {
+		if name, value, ok := strings.Cut(variable, "="); ok {
+			environ[name] = value
+		}
+	}
+	return environ
+}
+
+// Export builds the document of the settings set in the environment, with the credentials redacted
+func Export(environ map[string]string, now time.Time) Document {
+	document := Document{
+		Version:     Version,
+		ExportedAt:  now.UTC(),
+		Runtime:     map[string]string{},
+		Features:    map[string]string{},
+		Schedulers:  map[string]string{},
+		Credentials: map[string]string{},
+	}
+	for name, value := range environ {
+		setting, ok := lookup(name)
+		if !ok || value == "" {
+			continue
+		}
+		if setting.Secret {
+			value = Redacted
+		}
+		document.section(setting.Section)[name] = value
+	}
+	return document
+}
+
+// lookup returns the setting of an environment variable
+func lookup(name string) (Setting, bool) {
+	for _, setting := range Settings {
+		if setting.Name == name || (setting.Prefix && strings.HasPrefix(name, setting.Name) && len(name) > len(setting.Name)) {
+			return setting, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Change is a difference between the running configuration and an imported document. Credentials are
+// compared but never shown; a redacted imported credential keeps the running value.
+type Change struct {
+	Name    string `json:"name" example:"RATE_LIMIT_PER_SECOND"`
+	Section string `json:"section" example:"runtime"`
+	// Action is "set" for a setting the instance does not have, "update" for a different value and
+	// "unset" for a setting the document leaves to its default
+	Action   string  `json:"action" example:"update"`
+	Current  *string `json:"current" example:"20"`
+	Imported *string `json:"imported" example:"50"`
+	// Reloadable is set for changes a reload applies; the others take effect on restart
+	Reloadable bool `json:"reloadable" example:"true"`
+}
+
+// Change actions
+const (
+	ActionSet    = "set"
+	ActionUpdate = "update"
+	ActionUnset  = "unset"
+)
+
+// Plan validates the imported document and lists the changes that would bring the environment to it,
+// sorted by section and name
+func Plan(environ map[string]string, imported Document) ([]Change, error) {
+	if imported.Version != Version {
+		return nil, fmt.Errorf("%w: %d, expected %d", ErrUnsupportedVersion, imported.Version, Version)
+	}
+	current := make(map[string]string)
+	for name, value := range environ {
+		if _, ok := lookup(name); ok && value != "" {
+			current[name] = value
+		}
+	}
+
+	changes := []Change{}
+	wanted := make(map[string]bool)
+	for _, section := range []string{SectionRuntime, SectionFeatures, SectionSchedulers, SectionCredentials} {
+		for name, value := range imported.section(section) {
+			setting, ok := lookup(name)
+			if !ok || setting.Section != section {
+				return nil, fmt.Errorf("%w: %s in %s", ErrUnknownSetting, name, section)
+			}
+			wanted[name] = true
+			running, set := current[name]
+			if setting.Secret && value == Redacted {
+				// The document does not carry the credential, so the instance keeps its own
+				continue
+			}
+			if set && running == value {
+				continue
+			}
+			change := Change{Name: name, Section: section, Action: ActionSet, Imported: show(setting, value), Reloadable: setting.Reloadable}
+			if set {
+				change.Action, change.Current = ActionUpdate, show(setting, running)
+			}
+			changes = append(changes, change)
+		}
+	}
+	for name, running := range current {
+		if wanted[name] {
+			continue
+		}
+		setting, _ := lookup(name)
+		changes = append(changes, Change{Name: name, Section: setting.Section, Action: ActionUnset, Current: show(setting, running),
+			Reloadable: setting.Reloadable})
+	}
+	sortChanges(changes)
+	return changes, nil
+}
+
+// sortChanges sorts changes by section and name
+func sortChanges(changes []Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Section != changes[j].Section {
+			return changes[i].Section < changes[j].Section
+		}
+		return changes[i].Name < changes[j].Name
+	})
+}
+
+// show returns the value as shown in a plan, redacted for credentials
+func show(setting Setting, value string) *string {
+	if setting.Secret {
+		value = Redacted
+	}
+	return &value
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	environ := map[string]string{
+		"REQUEST_TIMEOUT":    "45s",
+		"MIDDLEWARE_PUBLIC":  "ratelimit,timeout",
+		"LYRICS_ENABLED":     "true",
+		"TRENDING_INTERVAL":  "5m",
+		"JWT_SECRET":         "very-secret",
+		"SPOTIFY_CLIENT_ID":  "client",
+		"DB_PASSWORD":        "123456",
+		"PORT":               "8080",
+		"ENRICHMENT_WORKERS": "",
+		"HOME":               "/root",
+	}
+	now := time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC)
+	document := Export(environ, now)
+
+	assert.Equal(t, Version, document.Version)
+	assert.Equal(t, now, document.ExportedAt)
+	assert.Equal(t, map[string]string{"REQUEST_TIMEOUT": "45s", "MIDDLEWARE_PUBLIC": "ratelimit,timeout"}, document.Runtime)
+	assert.Equal(t, map[string]string{"LYRICS_ENABLED": "true"}, document.Features)
+	assert.Equal(t, map[string]string{"TRENDING_INTERVAL": "5m"}, document.Schedulers)
+	assert.Equal(t, map[string]string{"JWT_SECRET": Redacted, "SPOTIFY_CLIENT_ID": "client"}, document.Credentials)
+
+	data, err := json.Marshal(document)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "very-secret")
+	assert.NotContains(t, string(data), "123456", "the database connection is left out")
+}
+
+func TestPlan(t *testing.T) {
+	production := map[string]string{
+		"REQUEST_TIMEOUT":   "30s",
+		"TRENDING_INTERVAL": "15m",
+		"JWT_SECRET":        "production-secret",
+		"ADMIN_TOKEN":       "production-token",
+	}
+	staging := Export(map[string]string{
+		"REQUEST_TIMEOUT":   "45s",
+		"TRENDING_INTERVAL": "15m",
+		"LYRICS_ENABLED":    "true",
+		"JWT_SECRET":        "staging-secret",
+	}, time.Now())
+
+	changes, err := Plan(production, staging)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	assert.Equal(t, "ADMIN_TOKEN", changes[0].Name)
+	assert.Equal(t, ActionUnset, changes[0].Action)
+	assert.Equal(t, Redacted, *changes[0].Current, "credentials are never shown")
+	assert.Equal(t, "LYRICS_ENABLED", changes[1].Name)
+	assert.Equal(t, ActionSet, changes[1].Action)
+	assert.Nil(t, changes[1].Current)
+	assert.Equal(t, "REQUEST_TIMEOUT", changes[2].Name)
+	assert.Equal(t, ActionUpdate, changes[2].Action)
+	assert.Equal(t, "30s", *changes[2].Current)
+	assert.Equal(t, "45s", *changes[2].Imported)
+
+	staging.Version = 2
+	_, err = Plan(production, staging)
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	staging.Version = Version
+	staging.Features["DB_PASSWORD"] = "guess"
+	_, err = Plan(production, staging)
+	assert.ErrorIs(t, err, ErrUnknownSetting)
+	delete(staging.Features, "DB_PASSWORD")
+	staging.Features["REQUEST_TIMEOUT"] = "10s"
+	_, err = Plan(production, staging)
+	assert.ErrorIs(t, err, ErrUnknownSetting, "settings must be in their own section")
+}
+
+func TestReloader(t *testing.T) {
+	t.Setenv("REQUEST_TIMEOUT", "30s")
+	environ := map[string]string{"REQUEST_TIMEOUT": "30s", "LOG_LEVEL": "debug", "RATE_LIMIT_BURST": "40"}
+	reloader := NewReloader(func() (map[string]string, error) { return environ, nil })
+	var applied []string
+	reloader.Register("log", func(snapshot Snapshot) (func(), error) {
+		level := snapshot.Get("LOG_LEVEL")
+		return func() { applied = append(applied, level) }, nil
+	})
+	reloader.Register("rate limit", func(snapshot Snapshot) (func(), error) {
+		_, err := snapshot.Int("RATE_LIMIT_BURST", 40)
+		return func() {}, err
+	})
+
+	result, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Snapshot.Version)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "debug", "RATE_LIMIT_BURST": "40"}, result.Snapshot.Values,
+		"only reloadable settings are in the snapshot")
+	assert.Len(t, result.Changes, 2)
+	assert.Empty(t, result.Pending)
+	assert.Equal(t, []string{"debug"}, applied)
+
+	environ = map[string]string{"REQUEST_TIMEOUT": "45s", "LOG_LEVEL": "info", "RATE_LIMIT_BURST": "none"}
+	_, err = reloader.Reload()
+	assert.ErrorIs(t, err, ErrInvalidReload)
+	assert.Equal(t, []string{"debug"}, applied, "nothing is applied when any setting is invalid")
+	assert.Equal(t, 1, reloader.Current().Version)
+
+	environ["RATE_LIMIT_BURST"] = ""
+	result, err = reloader.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"debug", "info"}, applied)
+	assert.Equal(t, 2, reloader.Current().Version)
+	assert.Equal(t, []string{"REQUEST_TIMEOUT"}, result.Pending, "settings that are not reloadable wait for a restart")
+	require.Len(t, result.Changes, 2)
+	assert.Equal(t, "LOG_LEVEL", result.Changes[0].Name)
+	assert.Equal(t, ActionUpdate, result.Changes[0].Action)
+	assert.Equal(t, "RATE_LIMIT_BURST", result.Changes[1].Name)
+	assert.Equal(t, ActionUnset, result.Changes[1].Action)
+}
+
+func TestFileSource(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("RATE_LIMIT_BURST", "40")
+	path := filepath.Join(t.TempDir(), "reload.env")
+	require.NoError(t, os.WriteFile(path, []byte("# reloadable settings\n\nLOG_LEVEL=warn\nexport FALLBACK_TEXT=\"Lyrics unavailable\"\n"), 0o600))
+
+	environ, err := FileSource(path)()
+	require.NoError(t, err)
+	assert.Equal(t, "warn", environ["LOG_LEVEL"], "the file overrides the environment")
+	assert.Equal(t, "Lyrics unavailable", environ["FALLBACK_TEXT"])
+	assert.Equal(t, "40", environ["RATE_LIMIT_BURST"])
+
+	require.NoError(t, os.WriteFile(path, []byte("LOG_LEVEL\n"), 0o600))
+	_, err = FileSource(path)()
+	assert.Error(t, err)
+	_, err = FileSource(filepath.Join(t.TempDir(), "missing.env"))()
+	assert.Error(t, err)
+}
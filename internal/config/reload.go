@@ -0,0 +1,207 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInvalidReload is returned when a reload reads settings an applier rejects; nothing is applied then
+var ErrInvalidReload = errors.New("invalid configuration reload")
+
+// Snapshot is the values of the reloadable settings at one reload. It is replaced as a whole, so readers
+// never see the settings of two reloads mixed.
+type Snapshot struct {
+	// Version counts the reloads applied since the process started, from 1 for the startup configuration
+	Version  int               `json:"version" example:"2"`
+	LoadedAt time.Time         `json:"loaded_at" example:"2024-01-15T12:00:00Z"`
+	Values   map[string]string `json:"values" example:"LOG_LEVEL:info,RATE_LIMIT_PER_SECOND:50"`
+}
+
+// Get returns the value of a setting, or an empty string when it is not set
+func (s Snapshot) Get(name string) string {
+	return s.Values[name]
+}
+
+// Int returns the value of a setting as a positive number, or the fallback when it is not set
+func (s Snapshot) Int(name string, fallback int) (int, error) {
+	value, ok := s.Values[name]
+	if !ok {
+		return fallback, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number, got %q", name, value)
+	}
+	return number, nil
+}
+
+// Duration returns the value of a setting as a positive duration, or the fallback when it is not set
+func (s Snapshot) Duration(name string, fallback time.Duration) (time.Duration, error) {
+	value, ok := s.Values[name]
+	if !ok {
+		return fallback, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration, got %q", name, value)
+	}
+	return duration, nil
+}
+
+// Applier checks the settings of a snapshot it depends on and returns the function that applies them.
+// Appliers must not change anything before apply is called, as the reload is abandoned when any of them
+// returns an error.
+type Applier func(snapshot Snapshot) (apply func(), err error)
+
+// ReloadResult reports a reload
+type ReloadResult struct {
+	Snapshot Snapshot `json:"snapshot"`
+	// Changes are the reloadable settings the reload changed
+	Changes []Change `json:"changes"`
+	// Pending are the settings that differ from the running ones but only take effect on restart
+	Pending []string `json:"pending" example:"REQUEST_TIMEOUT"`
+}
+
+// Reloader reads the reloadable settings from its source and applies them without restarting the process,
+// on SIGHUP or on request. Each reload is validated by every applier before any applies it, so a bad value
+// leaves the running configuration untouched.
+type Reloader struct {
+	source func() (map[string]string, error)
+	// startup is the environment the process started with, which the other settings keep until a restart
+	startup map[string]string
+
+	// mu serializes reloads; readers load the current snapshot without it
+	mu       sync.Mutex
+	appliers []namedApplier
+	current  atomic.Pointer[Snapshot]
+}
+
+// namedApplier is an applier with the name its errors are reported under
+type namedApplier struct {
+	name    string
+	applier Applier
+}
+
+// NewReloader creates a reloader reading the settings from the source. Nothing is loaded until Reload is
+// called, which is first done at startup once the appliers are registered.
+func NewReloader(source func() (map[string]string, error)) *Reloader {
+	r := &Reloader{source: source, startup: Environ()}
+	r.current.Store(&Snapshot{Values: map[string]string{}})
+	return r
+}
+
+// Register adds an applier run on every reload, in the order registered
+func (r *Reloader) Register(name string, applier Applier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.appliers = append(r.appliers, namedApplier{name: name, applier: applier})
+}
+
+// Current returns the snapshot of the last applied reload
+func (r *Reloader) Current() Snapshot {
+	return *r.current.Load()
+}
+
+// Reload reads the source and, when every applier accepts the settings, applies them and makes them the
+// current snapshot. An error wrapping ErrInvalidReload is returned when an applier rejects them.
+func (r *Reloader) Reload() (ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	environ, err := r.source()
+	if err != nil {
+		return ReloadResult{}, fmt.Errorf("read configuration: %w", err)
+	}
+	previous := r.current.Load()
+	snapshot := Snapshot{Version: previous.Version + 1, LoadedAt: time.Now().UTC(), Values: map[string]string{}}
+	result := ReloadResult{Changes: []Change{}, Pending: []string{}}
+	for name, value := range environ {
+		setting, ok := lookup(name)
+		switch {
+		case !ok || value == "":
+		case setting.Reloadable:
+			snapshot.Values[name] = value
+		case value != r.startup[name]:
+			result.Pending = append(result.Pending, name)
+		}
+	}
+
+	applies := make([]func(), 0, len(r.appliers))
+	for _, applier := range r.appliers {
+		apply, err := applier.applier(snapshot)
+		if err != nil {
+			return ReloadResult{}, fmt.Errorf("%w: %s: %v", ErrInvalidReload, applier.name, err)
+		}
+		applies = append(applies, apply)
+	}
+	for _, apply := range applies {
+		apply()
+	}
+	r.current.Store(&snapshot)
+
+	for name, value := range snapshot.Values {
+		running, set := previous.Values[name]
+		if set && running == value {
+			continue
+		}
+		setting, _ := lookup(name)
+		change := Change{Name: name, Section: setting.Section, Action: ActionSet, Imported: show(setting, value), Reloadable: true}
+		if set {
+			change.Action, change.Current = ActionUpdate, show(setting, running)
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	for name, running := range previous.Values {
+		if _, ok := snapshot.Values[name]; !ok {
+			setting, _ := lookup(name)
+			result.Changes = append(result.Changes, Change{Name: name, Section: setting.Section, Action: ActionUnset,
+				Current: show(setting, running), Reloadable: true})
+		}
+	}
+	sortChanges(result.Changes)
+	sort.Strings(result.Pending)
+	result.Snapshot = snapshot
+	return result, nil
+}
+
+// FileSource returns a source reading the process environment overridden by the variables of the env file
+// at the path, if any. The environment of a running process cannot be changed from outside, so the file is
+// how new values reach a reload. Each line of the file is NAME=VALUE, optionally quoted; blank lines and
+// lines starting with # are skipped.
+func FileSource(path string) func() (map[string]string, error) {
+	return func() (map[string]string, error) {
+		environ := Environ()
+		if path == "" {
+			return environ, nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+			name, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("%s:%d: expected NAME=VALUE", path, line)
+			}
+			value = strings.TrimSpace(value)
+			if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+				value = value[1 : len(value)-1]
+			}
+			environ[strings.TrimSpace(name)] = value
+		}
+		return environ, scanner.Err()
+	}
+}
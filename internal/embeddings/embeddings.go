@@ -0,0 +1,153 @@
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"music-library/internal/secrets"
+)
+
+// Supported embedding providers
+const (
+	ProviderOpenAI = "openai"
+	ProviderOllama = "ollama"
+)
+
+// Embedder computes vector embeddings for texts
+type Embedder interface {
+	// Model identifies the embedding model, so vectors from different models are never compared
+	Model() string
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// OpenAIEmbedder calls an OpenAI-compatible /embeddings endpoint. Many self-hosted
+// inference servers expose the same API, so it also covers local models.
+type OpenAIEmbedder struct {
+	URL       string
+	ModelName string
+	APIKey    string
+	Client    *http.Client
+}
+
+// Model returns the configured model name
+func (e *OpenAIEmbedder) Model() string {
+	return e.ModelName
+}
+
+// Embed embeds all texts in a single request
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	err := postJSON(ctx, e.Client, e.URL+"/embeddings", e.APIKey, map[string]any{"model": e.ModelName, "input": texts}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding provider returned %d vectors for %d texts", len(resp.Data), len(texts))
+	}
+	vectors := make([][]float32, len(texts))
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding provider returned index %d out of range", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
+
+// OllamaEmbedder calls the /api/embed endpoint of a local Ollama server
+type OllamaEmbedder struct {
+	URL       string
+	ModelName string
+	Client    *http.Client
+}
+
+// Model returns the configured model name
+func (e *OllamaEmbedder) Model() string {
+	return e.ModelName
+}
+
+// Embed embeds all texts in a single request
+func (e *OllamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	err := postJSON(ctx, e.Client, e.URL+"/api/embed", "", map[string]any{"model": e.ModelName, "input": texts}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embedding provider returned %d vectors for %d texts", len(resp.Embeddings), len(texts))
+	}
+	return resp.Embeddings, nil
+}
+
+// FromEnv builds the embedder selected by EMBEDDINGS_PROVIDER, or returns nil when it is unset.
+// The API key can also be read from the file named by EMBEDDINGS_API_KEY_FILE.
+func FromEnv(client *http.Client) (Embedder, error) {
+	url := strings.TrimSuffix(os.Getenv("EMBEDDINGS_URL"), "/")
+	model := os.Getenv("EMBEDDINGS_MODEL")
+	switch provider := os.Getenv("EMBEDDINGS_PROVIDER"); provider {
+	case "":
+		return nil, nil
+	case ProviderOpenAI:
+		if url == "" {
+			url = "https://api.openai.com/v1"
+		}
+		if model == "" {
+			model = "text-embedding-3-small"
+		}
+		apiKey, err := secrets.Read("EMBEDDINGS_API_KEY")
+		if err != nil {
+			return nil, err
+		}
+		return &OpenAIEmbedder{URL: url, ModelName: model, APIKey: apiKey, Client: client}, nil
+	case ProviderOllama:
+		if url == "" {
+			url = "http://localhost:11434"
+		}
+		if model == "" {
+			model = "nomic-embed-text"
+		}
+		return &OllamaEmbedder{URL: url, ModelName: model, Client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported embeddings provider %q", provider)
+	}
+}
+
+// postJSON sends payload as JSON and decodes the JSON response into result
+func postJSON(ctx context.Context, client *http.Client, url, apiKey string, payload, result any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("embedding provider returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,61 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIEmbedderOrdersByIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		assert.Equal(t, "small", req.Model)
+		assert.Equal(t, []string{"first", "second"}, req.Input)
+		w.Write([]byte(`{"data": [{"index": 1, "embedding": [0.3, 0.4]}, {"index": 0, "embedding": [0.1, 0.2]}]}`))
+	}))
+	defer server.Close()
+
+	embedder := &OpenAIEmbedder{URL: server.URL + "/v1", ModelName: "small", APIKey: "key", Client: server.Client()}
+	vectors, err := embedder.Embed(context.Background(), []string{"first", "second"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{0.1, 0.2}, {0.3, 0.4}}, vectors)
+}
+
+func TestOllamaEmbedderCountMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/embed", r.URL.Path)
+		w.Write([]byte(`{"embeddings": [[0.1, 0.2]]}`))
+	}))
+	defer server.Close()
+
+	embedder := &OllamaEmbedder{URL: server.URL, ModelName: "nomic-embed-text", Client: server.Client()}
+	_, err := embedder.Embed(context.Background(), []string{"first", "second"})
+	assert.Error(t, err)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("EMBEDDINGS_PROVIDER", "")
+	embedder, err := FromEnv(http.DefaultClient)
+	require.NoError(t, err)
+	assert.Nil(t, embedder)
+
+	t.Setenv("EMBEDDINGS_PROVIDER", ProviderOllama)
+	embedder, err = FromEnv(http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, "nomic-embed-text", embedder.Model())
+
+	t.Setenv("EMBEDDINGS_PROVIDER", "word2vec")
+	_, err = FromEnv(http.DefaultClient)
+	assert.Error(t, err)
+}
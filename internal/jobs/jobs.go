@@ -0,0 +1,296 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// ErrQueueFull is returned when a job is submitted while every queue slot is taken
+var ErrQueueFull = errors.New("job queue is full")
+
+// ErrAlreadyRunning is returned when an exclusive job is submitted while another job of its kind is unfinished
+var ErrAlreadyRunning = errors.New("a job of this kind is already running")
+
+// Store persists jobs and their progress
+type Store interface {
+	CreateJob(ctx context.Context, id, kind string, ownerID *int) (models.Job, error)
+	GetJob(ctx context.Context, id string) (models.Job, error)
+	StartJob(ctx context.Context, id string) error
+	UpdateJobProgress(ctx context.Context, id string, total, processed, failed int) error
+	FinishJob(ctx context.Context, id, status string, total, processed, failed int, result []byte, message string) error
+	FailUnfinishedJobs(ctx context.Context, message string) (int64, error)
+}
+
+// Func is the work of a job. It reports its progress as it goes and returns the outcome stored as the
+// job result, which is serialized to JSON; an error fails the job.
+type Func func(ctx context.Context, progress *Progress) (any, error)
+
+// Config sizes the worker pool
+type Config struct {
+	// Workers is the number of jobs run concurrently
+	Workers int
+	// Capacity is the number of jobs waiting for a worker
+	Capacity int
+	// ProgressInterval is the least time between two progress writes of a job
+	ProgressInterval time.Duration
+}
+
+// DefaultConfig is used for the values NewManager is not given
+var DefaultConfig = Config{
+	Workers:          2,
+	Capacity:         100,
+	ProgressInterval: time.Second,
+}
+
+// task is a submitted job waiting for a worker
+type task struct {
+	job       models.Job
+	fn        Func
+	exclusive bool
+}
+
+// Manager runs submitted jobs on a bounded worker pool, persisting their status and progress in the store.
+// Jobs live in the process that accepted them: jobs left unfinished by a restart are marked failed on Start.
+type Manager struct {
+	store  Store
+	logger *zap.Logger
+	cfg    Config
+	queue  chan task
+
+	mu sync.Mutex
+	// active holds the kinds of the exclusive jobs queued or running
+	active map[string]bool
+	wg     sync.WaitGroup
+}
+
+// NewManager creates a manager; a zero configuration takes the defaults
+func NewManager(store Store, logger *zap.Logger, cfg Config) *Manager {
+	if cfg.Workers < 1 {
+		cfg.Workers = DefaultConfig.Workers
+	}
+	if cfg.Capacity < 1 {
+		cfg.Capacity = DefaultConfig.Capacity
+	}
+	if cfg.ProgressInterval <= 0 {
+		cfg.ProgressInterval = DefaultConfig.ProgressInterval
+	}
+	return &Manager{
+		store:  store,
+		logger: logger,
+		cfg:    cfg,
+		queue:  make(chan task, cfg.Capacity),
+		active: make(map[string]bool),
+	}
+}
+
+// Start fails the jobs a previous process left unfinished and runs the workers until ctx is cancelled.
+// Jobs running at that point are failed as interrupted.
+func (m *Manager) Start(ctx context.Context) {
+	if failed, err := m.store.FailUnfinishedJobs(ctx, "interrupted by a restart"); err != nil {
+		m.logger.Error("Failed to fail unfinished jobs", zap.Error(err))
+	} else if failed > 0 {
+		m.logger.Warn("Failed jobs interrupted by a restart", zap.Int64("count", failed))
+	}
+	m.logger.Info("Starting job workers", zap.Int("workers", m.cfg.Workers), zap.Int("capacity", m.cfg.Capacity))
+	for i := 0; i < m.cfg.Workers; i++ {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case t := <-m.queue:
+					m.run(ctx, t)
+				}
+			}
+		}()
+	}
+}
+
+// Wait blocks until the workers have stopped
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// Submit queues a job of the kind, owned by the user with the ID unless it is nil, and returns it as stored
+func (m *Manager) Submit(ctx context.Context, kind string, ownerID *int, fn Func) (models.Job, error) {
+	return m.submit(ctx, kind, ownerID, false, fn)
+}
+
+// SubmitExclusive queues a job of the kind, owned by no user, unless another one is queued or running, in
+// which case ErrAlreadyRunning is returned
+func (m *Manager) SubmitExclusive(ctx context.Context, kind string, fn Func) (models.Job, error) {
+	return m.submit(ctx, kind, nil, true, fn)
+}
+
+func (m *Manager) submit(ctx context.Context, kind string, ownerID *int, exclusive bool, fn Func) (models.Job, error) {
+	if exclusive {
+		m.mu.Lock()
+		if m.active[kind] {
+			m.mu.Unlock()
+			return models.Job{}, ErrAlreadyRunning
+		}
+		m.active[kind] = true
+		m.mu.Unlock()
+	}
+	release := func() {
+		if exclusive {
+			m.mu.Lock()
+			delete(m.active, kind)
+			m.mu.Unlock()
+		}
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		release()
+		return models.Job{}, err
+	}
+	job, err := m.store.CreateJob(ctx, hex.EncodeToString(id), kind, ownerID)
+	if err != nil {
+		release()
+		return models.Job{}, err
+	}
+	select {
+	case m.queue <- task{job: job, fn: fn, exclusive: exclusive}:
+		m.logger.Info("Job queued", zap.String("job_id", job.ID), zap.String("kind", kind))
+		return job, nil
+	default:
+		release()
+		m.logger.Warn("Job queue full", zap.String("job_id", job.ID), zap.String("kind", kind))
+		if err := m.store.FinishJob(context.WithoutCancel(ctx), job.ID, models.JobFailed, 0, 0, 0, nil, ErrQueueFull.Error()); err != nil {
+			m.logger.Error("Failed to fail rejected job", zap.String("job_id", job.ID), zap.Error(err))
+		}
+		return models.Job{}, ErrQueueFull
+	}
+}
+
+// Get returns a job with its latest stored progress
+func (m *Manager) Get(ctx context.Context, id string) (models.Job, error) {
+	return m.store.GetJob(ctx, id)
+}
+
+// run executes a job and stores its outcome. The outcome is stored even when ctx is cancelled by shutdown.
+func (m *Manager) run(ctx context.Context, t task) {
+	logger := m.logger.With(zap.String("job_id", t.job.ID), zap.String("kind", t.job.Kind))
+	defer func() {
+		if t.exclusive {
+			m.mu.Lock()
+			delete(m.active, t.job.Kind)
+			m.mu.Unlock()
+		}
+	}()
+	if err := m.store.StartJob(ctx, t.job.ID); err != nil {
+		logger.Error("Failed to start job", zap.Error(err))
+	}
+	logger.Info("Job started")
+
+	progress := &Progress{store: m.store, logger: logger, id: t.job.ID, interval: m.cfg.ProgressInterval}
+	result, err := m.call(ctx, t.fn, progress)
+
+	status, message := models.JobCompleted, ""
+	if err != nil {
+		status, message = models.JobFailed, err.Error()
+		if ctx.Err() != nil {
+			message = "interrupted by shutdown: " + message
+		}
+	}
+	var data []byte
+	if result != nil {
+		if data, err = json.Marshal(result); err != nil {
+			logger.Error("Failed to serialize job result", zap.Error(err))
+			data = nil
+		}
+		// A nil pointer result is stored as no result rather than a JSON null
+		if string(data) == "null" {
+			data = nil
+		}
+	}
+	total, processed, failed := progress.counts()
+	if err := m.store.FinishJob(context.WithoutCancel(ctx), t.job.ID, status, total, processed, failed, data, message); err != nil {
+		logger.Error("Failed to store job outcome", zap.Error(err))
+	}
+	logger.Info("Job finished", zap.String("status", status), zap.Int("processed", processed), zap.Int("failed", failed))
+}
+
+// call runs the job function, turning a panic into a failure so one job cannot take down the workers
+func (m *Manager) call(ctx context.Context, fn Func, progress *Progress) (result any, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			m.logger.Error("Job panicked", zap.Any("panic", recovered), zap.Stack("stack"))
+			result, err = nil, errors.New("job panicked")
+		}
+	}()
+	return fn(ctx, progress)
+}
+
+// Progress records how far a job has come. Writes to the store are throttled to the progress interval;
+// the final counts are stored with the job outcome.
+type Progress struct {
+	store    Store
+	logger   *zap.Logger
+	id       string
+	interval time.Duration
+
+	mu        sync.Mutex
+	total     int
+	processed int
+	failed    int
+	flushed   time.Time
+}
+
+// SetTotal sets the number of items the job processes. Like Add, it does nothing on a nil Progress,
+// so code shared with synchronous callers can report unconditionally.
+func (p *Progress) SetTotal(total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.total = total
+	p.mu.Unlock()
+	p.flush()
+}
+
+// Add counts processed items, failed ones included
+func (p *Progress) Add(processed, failed int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.processed += processed
+	p.failed += failed
+	p.mu.Unlock()
+	p.flush()
+}
+
+// counts returns the current counters
+func (p *Progress) counts() (total, processed, failed int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.total, p.processed, p.failed
+}
+
+// flush stores the counters unless they were stored within the progress interval
+func (p *Progress) flush() {
+	p.mu.Lock()
+	if time.Since(p.flushed) < p.interval {
+		p.mu.Unlock()
+		return
+	}
+	p.flushed = time.Now()
+	total, processed, failed := p.total, p.processed, p.failed
+	p.mu.Unlock()
+	// Progress is informational, so it is written even while the job is being cancelled
+	if err := p.store.UpdateJobProgress(context.Background(), p.id, total, processed, failed); err != nil {
+		p.logger.Warn("Failed to store job progress", zap.Error(err))
+	}
+}
@@ -0,0 +1,212 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// memoryStore keeps jobs in memory
+type memoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]models.Job
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{jobs: make(map[string]models.Job)}
+}
+
+func (s *memoryStore) CreateJob(_ context.Context, id, kind string, ownerID *int) (models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job := models.Job{ID: id, Kind: kind, Status: models.JobQueued, OwnerID: ownerID, CreatedAt: time.Now()}
+	s.jobs[id] = job
+	return job, nil
+}
+
+func (s *memoryStore) GetJob(_ context.Context, id string) (models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return job, sql.ErrNoRows
+	}
+	return job, nil
+}
+
+func (s *memoryStore) update(id string, fn func(job *models.Job)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	fn(&job)
+	s.jobs[id] = job
+	return nil
+}
+
+func (s *memoryStore) StartJob(_ context.Context, id string) error {
+	return s.update(id, func(job *models.Job) { job.Status = models.JobRunning })
+}
+
+func (s *memoryStore) UpdateJobProgress(_ context.Context, id string, total, processed, failed int) error {
+	return s.update(id, func(job *models.Job) { job.Total, job.Processed, job.Failed = total, processed, failed })
+}
+
+func (s *memoryStore) FinishJob(_ context.Context, id, status string, total, processed, failed int, result []byte, message string) error {
+	return s.update(id, func(job *models.Job) {
+		job.Status, job.Total, job.Processed, job.Failed = status, total, processed, failed
+		if result != nil {
+			raw := append([]byte(nil), result...)
+			job.Result = (*json.RawMessage)(&raw)
+		}
+		if message != "" {
+			job.Error = &message
+		}
+	})
+}
+
+func (s *memoryStore) FailUnfinishedJobs(_ context.Context, message string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var failed int64
+	for id, job := range s.jobs {
+		if job.Status == models.JobQueued || job.Status == models.JobRunning {
+			job.Status, job.Error = models.JobFailed, &message
+			s.jobs[id] = job
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+// waitFinished polls the job until it is no longer queued or running
+func waitFinished(t *testing.T, m *Manager, id string) models.Job {
+	var job models.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = m.Get(context.Background(), id)
+		return err == nil && job.Status != models.JobQueued && job.Status != models.JobRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestManagerRunsJob(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewManager(newMemoryStore(), zap.NewNop(), Config{})
+	m.Start(ctx)
+
+	owner := 7
+	job, err := m.Submit(ctx, models.JobKindImport, &owner, func(ctx context.Context, progress *Progress) (any, error) {
+		progress.SetTotal(3)
+		progress.Add(2, 0)
+		progress.Add(1, 1)
+		return map[string]int{"created": 2}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.JobQueued, job.Status)
+	assert.Len(t, job.ID, 32)
+	assert.Equal(t, &owner, job.OwnerID)
+
+	job = waitFinished(t, m, job.ID)
+	assert.Equal(t, models.JobCompleted, job.Status)
+	assert.Equal(t, 3, job.Total)
+	assert.Equal(t, 3, job.Processed)
+	assert.Equal(t, 1, job.Failed)
+	require.NotNil(t, job.Result)
+	assert.JSONEq(t, `{"created": 2}`, string(*job.Result))
+	assert.Nil(t, job.Error)
+}
+
+func TestManagerRecordsFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewManager(newMemoryStore(), zap.NewNop(), Config{})
+	m.Start(ctx)
+
+	failing, err := m.Submit(ctx, models.JobKindImport, nil, func(context.Context, *Progress) (any, error) {
+		return nil, errors.New("broken file")
+	})
+	require.NoError(t, err)
+	panicking, err := m.Submit(ctx, models.JobKindImport, nil, func(context.Context, *Progress) (any, error) {
+		panic("boom")
+	})
+	require.NoError(t, err)
+
+	job := waitFinished(t, m, failing.ID)
+	assert.Equal(t, models.JobFailed, job.Status)
+	require.NotNil(t, job.Error)
+	assert.Equal(t, "broken file", *job.Error)
+	assert.Nil(t, job.Result)
+
+	job = waitFinished(t, m, panicking.ID)
+	assert.Equal(t, models.JobFailed, job.Status)
+}
+
+func TestManagerExclusiveKind(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewManager(newMemoryStore(), zap.NewNop(), Config{})
+	m.Start(ctx)
+
+	release := make(chan struct{})
+	first, err := m.SubmitExclusive(ctx, models.JobKindEnrichAll, func(context.Context, *Progress) (any, error) {
+		<-release
+		return nil, nil
+	})
+	require.NoError(t, err)
+	_, err = m.SubmitExclusive(ctx, models.JobKindEnrichAll, func(context.Context, *Progress) (any, error) { return nil, nil })
+	assert.ErrorIs(t, err, ErrAlreadyRunning)
+
+	close(release)
+	waitFinished(t, m, first.ID)
+	_, err = m.SubmitExclusive(ctx, models.JobKindEnrichAll, func(context.Context, *Progress) (any, error) { return nil, nil })
+	assert.NoError(t, err)
+}
+
+func TestManagerQueueFull(t *testing.T) {
+	store := newMemoryStore()
+	// Not started, so nothing drains the queue
+	m := NewManager(store, zap.NewNop(), Config{Workers: 1, Capacity: 1})
+	noop := func(context.Context, *Progress) (any, error) { return nil, nil }
+
+	_, err := m.Submit(context.Background(), models.JobKindImport, nil, noop)
+	require.NoError(t, err)
+	_, err = m.Submit(context.Background(), models.JobKindImport, nil, noop)
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	failed := 0
+	for _, job := range store.jobs {
+		if job.Status == models.JobFailed {
+			failed++
+		}
+	}
+	assert.Equal(t, 1, failed, "the rejected job is recorded as failed")
+}
+
+func TestManagerFailsInterruptedJobs(t *testing.T) {
+	store := newMemoryStore()
+	stale, err := store.CreateJob(context.Background(), "stale", models.JobKindImport, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := NewManager(store, zap.NewNop(), Config{})
+	m.Start(ctx)
+	cancel()
+	m.Wait()
+
+	job, err := m.Get(context.Background(), stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobFailed, job.Status)
+}
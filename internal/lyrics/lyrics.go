@@ -0,0 +1,217 @@
+package lyrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when the lyrics API has no lyrics for a song
+var ErrNotFound = errors.New("lyrics not found")
+
+// CacheConfig bounds the cache of fetched lyrics
+type CacheConfig struct {
+	// TTL is how long fetched lyrics, and the songs the API has none for, are remembered
+	TTL time.Duration
+	// Size is the maximum number of cached songs
+	Size int
+}
+
+// DefaultCacheConfig is used when no cache is configured
+var DefaultCacheConfig = CacheConfig{TTL: 24 * time.Hour, Size: 1000}
+
+// Client fetches song lyrics from a Genius-style lyrics API. It requests
+// GET {URL}/lyrics?artist=...&title=... and expects {"lyrics": "..."} back.
+// Answers, including not found, are cached so repeated lookups do not reach the API.
+type Client struct {
+	Token  string
+	Client *http.Client
+
+	// mu guards the URL and cache configuration, which Configure changes while lookups run, and the cache
+	mu       sync.Mutex
+	url      string
+	cacheCfg CacheConfig
+	cache    map[string]cacheEntry
+}
+
+// cacheEntry holds the lyrics of a song, empty when the API had none
+type cacheEntry struct {
+	lyrics  string
+	expires time.Time
+}
+
+// NewClient creates a Client for the API at the URL; a zero cache configuration takes the defaults
+func NewClient(apiURL, token string, client *http.Client, cacheCfg CacheConfig) *Client {
+	c := &Client{Token: token, Client: client, cache: make(map[string]cacheEntry)}
+	c.Configure(apiURL, cacheCfg)
+	return c
+}
+
+// Configure moves the client to another URL of the API and cache configuration while lookups run; a zero
+// cache configuration takes the defaults. Moving to another URL, or shrinking the cache below the songs it
+// holds, drops the cached answers; a new TTL applies to lyrics fetched from then on.
+func (c *Client) Configure(apiURL string, cacheCfg CacheConfig) {
+	if cacheCfg.TTL <= 0 {
+		cacheCfg.TTL = DefaultCacheConfig.TTL
+	}
+	if cacheCfg.Size <= 0 {
+		cacheCfg.Size = DefaultCacheConfig.Size
+	}
+	apiURL = strings.TrimSuffix(apiURL, "/")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if apiURL != c.url || len(c.cache) > cacheCfg.Size {
+		c.cache = make(map[string]cacheEntry)
+	}
+	c.url = apiURL
+	c.cacheCfg = cacheCfg
+}
+
+// URL returns the URL of the API the client fetches from
+func (c *Client) URL() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.url
+}
+
+// Fetch returns the lyrics of the song, from the cache when they were fetched within the cache TTL
+func (c *Client) Fetch(ctx context.Context, artist, title string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(artist)) + "\x00" + strings.ToLower(strings.TrimSpace(title))
+	if lyrics, ok := c.cached(key); ok {
+		if lyrics == "" {
+			return "", ErrNotFound
+		}
+		return lyrics, nil
+	}
+
+	lyrics, err := c.request(ctx, artist, title)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+	c.store(key, lyrics)
+	return lyrics, err
+}
+
+// request asks the API for the lyrics of the song
+func (c *Client) request(ctx context.Context, artist, title string) (string, error) {
+	endpoint := fmt.Sprintf("%s/lyrics?artist=%s&title=%s", c.URL(), url.QueryEscape(artist), url.QueryEscape(title))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("lyrics API returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	var result struct {
+		Lyrics string `json:"lyrics"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if lyrics := strings.TrimSpace(result.Lyrics); lyrics != "" {
+		return lyrics, nil
+	}
+	return "", ErrNotFound
+}
+
+// cached returns the unexpired cache entry for the key
+func (c *Client) cached(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.cache[key]
+	if !ok || time.Now().After(entry.expires) {
+		return "", false
+	}
+	return entry.lyrics, true
+}
+
+// store caches the lyrics under the key. A full cache first drops its expired entries and then,
+// if still full, the entry closest to expiry.
+func (c *Client) store(key, lyrics string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.cache[key]; !ok && len(c.cache) >= c.cacheCfg.Size {
+		now := time.Now()
+		oldest := ""
+		for k, entry := range c.cache {
+			if now.After(entry.expires) {
+				delete(c.cache, k)
+			} else if oldest == "" || entry.expires.Before(c.cache[oldest].expires) {
+				oldest = k
+			}
+		}
+		if len(c.cache) >= c.cacheCfg.Size {
+			delete(c.cache, oldest)
+		}
+	}
+	c.cache[key] = cacheEntry{lyrics: lyrics, expires: time.Now().Add(c.cacheCfg.TTL)}
+}
+
+// FromEnv builds the lyrics client when LYRICS_ENABLED is true, returning nil otherwise. The API is read
+// from LYRICS_API_URL, which is then required, and LYRICS_API_TOKEN; the cache from LYRICS_CACHE_TTL
+// and LYRICS_CACHE_SIZE.
+func FromEnv(client *http.Client) (*Client, error) {
+	value := os.Getenv("LYRICS_ENABLED")
+	if value == "" {
+		return nil, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("LYRICS_ENABLED must be a boolean: %q", value)
+	}
+	if !enabled {
+		return nil, nil
+	}
+	apiURL, cacheCfg, err := ConfigFrom(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(apiURL, os.Getenv("LYRICS_API_TOKEN"), client, cacheCfg), nil
+}
+
+// ConfigFrom reads the API URL and cache configuration from LYRICS_API_URL, which is required,
+// LYRICS_CACHE_TTL and LYRICS_CACHE_SIZE as returned by getenv, for FromEnv and configuration reloads
+func ConfigFrom(getenv func(string) string) (string, CacheConfig, error) {
+	apiURL := getenv("LYRICS_API_URL")
+	if apiURL == "" {
+		return "", CacheConfig{}, errors.New("LYRICS_API_URL is required when LYRICS_ENABLED is set")
+	}
+
+	var err error
+	cacheCfg := DefaultCacheConfig
+	if value := getenv("LYRICS_CACHE_TTL"); value != "" {
+		if cacheCfg.TTL, err = time.ParseDuration(value); err != nil || cacheCfg.TTL <= 0 {
+			return "", CacheConfig{}, fmt.Errorf("LYRICS_CACHE_TTL must be a positive duration: %q", value)
+		}
+	}
+	if value := getenv("LYRICS_CACHE_SIZE"); value != "" {
+		if cacheCfg.Size, err = strconv.Atoi(value); err != nil || cacheCfg.Size <= 0 {
+			return "", CacheConfig{}, fmt.Errorf("LYRICS_CACHE_SIZE must be a positive integer: %q", value)
+		}
+	}
+	return apiURL, cacheCfg, nil
+}
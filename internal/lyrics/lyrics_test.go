@@ -0,0 +1,93 @@
+package lyrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientFetchCaches(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/lyrics", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if r.URL.Query().Get("title") != "Uprising" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"lyrics": "Paranoia is in bloom\n"}`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL+"/", "token", server.Client(), CacheConfig{})
+
+	lyrics, err := client.Fetch(context.Background(), "Muse", "Uprising")
+	require.NoError(t, err)
+	assert.Equal(t, "Paranoia is in bloom", lyrics)
+	lyrics, err = client.Fetch(context.Background(), "muse ", "UPRISING")
+	require.NoError(t, err)
+	assert.Equal(t, "Paranoia is in bloom", lyrics)
+	assert.Equal(t, 1, requests, "lyrics are served from the cache")
+
+	_, err = client.Fetch(context.Background(), "Muse", "Missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = client.Fetch(context.Background(), "Muse", "Missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 2, requests, "songs without lyrics are cached too")
+}
+
+func TestClientCacheEviction(t *testing.T) {
+	client := NewClient("http://lyrics.invalid", "", http.DefaultClient, CacheConfig{TTL: time.Hour, Size: 2})
+	client.cache["a"] = cacheEntry{lyrics: "A", expires: time.Now().Add(time.Minute)}
+	client.cache["b"] = cacheEntry{lyrics: "B", expires: time.Now().Add(time.Hour)}
+	client.store("c", "C")
+	assert.Len(t, client.cache, 2)
+	_, ok := client.cached("a")
+	assert.False(t, ok, "the entry closest to expiry is evicted")
+	lyrics, ok := client.cached("c")
+	assert.True(t, ok)
+	assert.Equal(t, "C", lyrics)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("LYRICS_ENABLED", "")
+	client, err := FromEnv(http.DefaultClient)
+	require.NoError(t, err)
+	assert.Nil(t, client)
+
+	t.Setenv("LYRICS_ENABLED", "true")
+	_, err = FromEnv(http.DefaultClient)
+	assert.Error(t, err, "the API URL is required")
+
+	t.Setenv("LYRICS_API_URL", "https://lyrics.example.com")
+	t.Setenv("LYRICS_CACHE_TTL", "1h")
+	client, err = FromEnv(http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, client.cacheCfg.TTL)
+	assert.Equal(t, DefaultCacheConfig.Size, client.cacheCfg.Size)
+
+	t.Setenv("LYRICS_CACHE_SIZE", "none")
+	_, err = FromEnv(http.DefaultClient)
+	assert.Error(t, err)
+}
+
+func TestClientConfigure(t *testing.T) {
+	client := NewClient("http://lyrics.invalid/", "", http.DefaultClient, CacheConfig{})
+	client.store("a", "A")
+
+	client.Configure("http://lyrics.invalid", CacheConfig{TTL: time.Minute})
+	_, ok := client.cached("a")
+	assert.True(t, ok, "the cache outlives a change of its TTL")
+	assert.Equal(t, time.Minute, client.cacheCfg.TTL)
+	assert.Equal(t, DefaultCacheConfig.Size, client.cacheCfg.Size)
+
+	client.Configure("http://other-lyrics.invalid/", CacheConfig{})
+	assert.Equal(t, "http://other-lyrics.invalid", client.URL())
+	_, ok = client.cached("a")
+	assert.False(t, ok, "answers of the previous API are dropped")
+}
@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric of the service
+const namespace = "music_library"
+
+// Outcomes of an observed operation
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	// OutcomeNotFound is a lookup that found nothing, which is an answer rather than a failure
+	OutcomeNotFound = "not_found"
+)
+
+// Reasons external API calls fail, used as the reason label of ExternalAPIErrors
+const (
+	ReasonBudget  = "budget"
+	ReasonRequest = "request"
+	ReasonStatus  = "status"
+	ReasonDecode  = "decode"
+	// ReasonCircuitOpen is a call not made because the circuit breaker is open
+	ReasonCircuitOpen = "circuit_open"
+)
+
+var (
+	// HTTPRequests counts handled requests by method, route and status
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Handled HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration measures request latency by method and route
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// ServiceDuration measures MusicService operations by operation and outcome
+	ServiceDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "service_operation_duration_seconds",
+		Help:      "Duration of music service operations by operation and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "outcome"})
+
+	// DBQueryDuration measures repository queries by SQL statement type and outcome
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of database queries by statement type and outcome.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"statement", "outcome"})
+
+	// RepositoryDuration measures repository calls, including their retries, by method and outcome
+	RepositoryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_call_duration_seconds",
+		Help:      "Duration of repository calls by method and outcome.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"method", "outcome"})
+
+	// RepositoryRetries counts repository calls repeated after a serialization failure or deadlock
+	RepositoryRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "repository_retries_total",
+		Help:      "Repository calls repeated after a serialization failure or deadlock, by method.",
+	}, []string{"method"})
+
+	// ExternalAPIDuration measures the calls to the external song API that were made
+	ExternalAPIDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "external_api_request_duration_seconds",
+		Help:      "Duration of external song API calls by outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"outcome"})
+
+	// ExternalAPIErrors counts failed or skipped external API calls by reason
+	ExternalAPIErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "external_api_errors_total",
+		Help:      "External song API calls that failed or were not made, by reason.",
+	}, []string{"reason"})
+
+	// ExternalAPIRetries counts external API calls retried after a transient failure
+	ExternalAPIRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "external_api_retries_total",
+		Help:      "External song API calls retried after a transient failure.",
+	})
+
+	// CircuitBreakerState is the current state of each circuit breaker: 0 closed, 1 half-open, 2 open
+	CircuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_state",
+		Help:      "Circuit breaker state by breaker: 0 closed, 1 half-open, 2 open.",
+	}, []string{"breaker"})
+
+	// CircuitBreakerTransitions counts circuit breaker state changes by breaker and state entered
+	CircuitBreakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_transitions_total",
+		Help:      "Circuit breaker state changes by breaker and state entered.",
+	}, []string{"breaker", "state"})
+)
+
+// Handler serves the collected metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Outcome returns the outcome label for an operation that finished with err
+func Outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
+
+// ObserveOperation records a MusicService operation started at start. It is meant to be deferred
+// with a pointer to the operation's named error result.
+func ObserveOperation(operation string, start time.Time, err *error) {
+	ServiceDuration.WithLabelValues(operation, Outcome(*err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveQuery records a database query by its statement type, keeping the label set small
+func ObserveQuery(query string, duration time.Duration, err error) {
+	DBQueryDuration.WithLabelValues(Statement(query), Outcome(err)).Observe(duration.Seconds())
+}
+
+// Statement returns the lowercased leading keyword of the query, such as "select" or "insert"
+func Statement(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "unknown"
+	}
+	return strings.ToLower(strings.TrimLeft(fields[0], "("))
+}
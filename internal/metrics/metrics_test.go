@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatement(t *testing.T) {
+	assert.Equal(t, "select", Statement("\n\t\tSELECT id FROM songs"))
+	assert.Equal(t, "with", Statement("WITH matched AS (SELECT 1) SELECT * FROM matched"))
+	assert.Equal(t, "unknown", Statement("  "))
+}
+
+func TestObserveOperation(t *testing.T) {
+	observe := func(err error) {
+		defer ObserveOperation("test_operation", time.Now(), &err)
+	}
+	observe(nil)
+	observe(errors.New("boom"))
+	observe(errors.New("boom"))
+
+	assert.Equal(t, 2, testutil.CollectAndCount(ServiceDuration), "one series per outcome")
+}
@@ -0,0 +1,179 @@
+package migrator
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidEnvironment is returned for environment names that cannot name a migration set
+var ErrInvalidEnvironment = errors.New("invalid migration environment")
+
+// DefaultConcurrentIndexRows is the estimated table size from which environment migrations build indexes concurrently
+const DefaultConcurrentIndexRows = 100000
+
+// EnvironmentConfig selects the supplemental migrations applied after the core ones, such as seed tables
+// for test databases or indexes only production needs
+type EnvironmentConfig struct {
+	// Name is the environment, whose migrations are in environments/<name> under the migrations directory.
+	// An empty name applies no supplemental migrations.
+	Name string
+	// ConcurrentIndexRows is the estimated row count from which CREATE INDEX on a table is run CONCURRENTLY,
+	// so writes are not locked out while the index builds; zero never rewrites the statements
+	ConcurrentIndexRows int64
+}
+
+// environmentName restricts environment names to what can be used in the name of their version table
+var environmentName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// EnvironmentConfigFromEnv reads the environment from MIGRATION_ENV and the concurrent index threshold
+// from MIGRATION_CONCURRENT_INDEX_ROWS
+func EnvironmentConfigFromEnv() (EnvironmentConfig, error) {
+	cfg := EnvironmentConfig{Name: os.Getenv("MIGRATION_ENV"), ConcurrentIndexRows: DefaultConcurrentIndexRows}
+	if cfg.Name != "" && !environmentName.MatchString(cfg.Name) {
+		return cfg, fmt.Errorf("%w: MIGRATION_ENV must be lowercase letters, digits and underscores: %q", ErrInvalidEnvironment, cfg.Name)
+	}
+	if value := os.Getenv("MIGRATION_CONCURRENT_INDEX_ROWS"); value != "" {
+		rows, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || rows < 0 {
+			return cfg, fmt.Errorf("MIGRATION_CONCURRENT_INDEX_ROWS must be a non-negative number: %q", value)
+		}
+		cfg.ConcurrentIndexRows = rows
+	}
+	return cfg, nil
+}
+
+// NewEnvironment opens the migrations of the configured environment, found under the core source directory.
+// They are versioned in their own schema_migrations_<name> table, so they never interfere with the core
+// versions, and run statement by statement outside a transaction, which CREATE INDEX CONCURRENTLY requires.
+func NewEnvironment(sourceURL, databaseURL string, cfg EnvironmentConfig, logger *zap.Logger) (*Migrator, error) {
+	if !environmentName.MatchString(cfg.Name) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidEnvironment, cfg.Name)
+	}
+	sourceURL = strings.TrimSuffix(sourceURL, "/") + "/environments/" + cfg.Name
+	src, err := source.Open(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	migrateSrc, err := source.Open(sourceURL)
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		src.Close()
+		migrateSrc.Close()
+		return nil, err
+	}
+	driver, err := postgres.WithInstance(db, &postgres.Config{
+		MigrationsTable:       "schema_migrations_" + cfg.Name,
+		MultiStatementEnabled: true,
+	})
+	if err != nil {
+		src.Close()
+		migrateSrc.Close()
+		db.Close()
+		return nil, err
+	}
+	indexes := &concurrentIndexSource{
+		Driver:    migrateSrc,
+		threshold: cfg.ConcurrentIndexRows,
+		rows:      func(table string) (int64, error) { return estimateRows(db, table) },
+		logger:    logger,
+	}
+	m, err := migrate.NewWithInstance("file", indexes, "postgres", driver)
+	if err != nil {
+		src.Close()
+		driver.Close()
+		return nil, err
+	}
+	return &Migrator{migrate: m, source: src, logger: logger.With(zap.String("migration_set", cfg.Name)), set: cfg.Name}, nil
+}
+
+// estimateRows returns the planner's row estimate of a table, -1 when the table was never analyzed
+// and 0 when it does not exist yet
+func estimateRows(db *sql.DB, table string) (int64, error) {
+	var rows sql.NullFloat64
+	err := db.QueryRow("SELECT reltuples FROM pg_class WHERE oid = to_regclass($1)", table).Scan(&rows)
+	if err == sql.ErrNoRows || (err == nil && !rows.Valid) {
+		return 0, nil
+	}
+	return int64(rows.Float64), err
+}
+
+// createIndex matches a CREATE INDEX statement up to its table name
+var createIndex = regexp.MustCompile(`(?i)\bCREATE\s+(UNIQUE\s+)?INDEX\s+(CONCURRENTLY\s+)?((?:IF\s+NOT\s+EXISTS\s+)?\S+\s+ON\s+(?:ONLY\s+)?([\w."]+))`)
+
+// concurrentIndexSource reads up migrations with their CREATE INDEX statements on large tables rewritten
+// to CREATE INDEX CONCURRENTLY
+type concurrentIndexSource struct {
+	source.Driver
+	threshold int64
+	rows      func(table string) (int64, error)
+	logger    *zap.Logger
+}
+
+// ReadUp returns the up migration of the version, rewritten for the current table sizes
+func (s *concurrentIndexSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadUp(version)
+	if err != nil || s.threshold <= 0 {
+		return r, identifier, err
+	}
+	defer r.Close()
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, identifier, err
+	}
+	rewritten, err := rewriteIndexes(string(body), s.threshold, s.rows)
+	if err != nil {
+		return nil, identifier, fmt.Errorf("migration %d: %w", version, err)
+	}
+	if rewritten != string(body) {
+		s.logger.Info("Building indexes concurrently on large tables", zap.Uint("version", version), zap.String("identifier", identifier))
+	}
+	return io.NopCloser(strings.NewReader(rewritten)), identifier, nil
+}
+
+// rewriteIndexes makes every CREATE INDEX on a table with at least threshold estimated rows, or whose size
+// is unknown because it was never analyzed, build CONCURRENTLY. Statements already concurrent are left as is.
+func rewriteIndexes(migration string, threshold int64, rows func(table string) (int64, error)) (string, error) {
+	var lookupErr error
+	sizes := make(map[string]int64)
+	rewritten := createIndex.ReplaceAllStringFunc(migration, func(statement string) string {
+		match := createIndex.FindStringSubmatch(statement)
+		if match[2] != "" || lookupErr != nil {
+			return statement
+		}
+		table := match[4]
+		size, ok := sizes[table]
+		if !ok {
+			if size, lookupErr = rows(table); lookupErr != nil {
+				return statement
+			}
+			sizes[table] = size
+		}
+		if size >= 0 && size < threshold {
+			return statement
+		}
+		unique := ""
+		if match[1] != "" {
+			unique = "UNIQUE "
+		}
+		return "CREATE " + unique + "INDEX CONCURRENTLY " + match[3]
+	})
+	if lookupErr != nil {
+		return "", fmt.Errorf("estimate table size: %w", lookupErr)
+	}
+	return rewritten, nil
+}
@@ -0,0 +1,171 @@
+package migrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/mongodb"
+	_ "github.com/golang-migrate/migrate/v4/database/mysql"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"go.uber.org/zap"
+)
+
+// ErrNotDirty is returned when forcing a version while no migration was interrupted
+var ErrNotDirty = errors.New("database is not in a dirty migration state")
+
+// ErrUnsafeForce is returned when forcing a version other than the interrupted migration or the one before it
+var ErrUnsafeForce = errors.New("forced version must be the dirty version or the version before it")
+
+// DirtyError reports a migration that was interrupted and left the database marked dirty
+type DirtyError struct {
+	// Set is the environment whose migration was interrupted, empty for the core migrations
+	Set string
+	// Version is the migration that was interrupted
+	Version uint
+	// Previous is the version before it, -1 when it is the first migration
+	Previous int
+}
+
+// Error describes the dirty state and both ways out of it
+func (e *DirtyError) Error() string {
+	if e.Set != "" {
+		return fmt.Sprintf("%s environment migration %d was interrupted and the database is dirty: verify the schema, then force version %d "+
+			"if the migration was fully applied or %d if it was not", e.Set, e.Version, e.Version, e.Previous)
+	}
+	return fmt.Sprintf("migration %d was interrupted and the database is dirty: verify the schema, then force version %d "+
+		"if the migration was fully applied or %d if it was not", e.Version, e.Version, e.Previous)
+}
+
+// Status is the migration state of the database
+type Status struct {
+	// Version is the last applied migration, 0 when none was applied
+	Version uint
+	Dirty   bool
+}
+
+// Migrator applies the migrations in a source directory to the database
+type Migrator struct {
+	migrate *migrate.Migrate
+	source  source.Driver
+	logger  *zap.Logger
+	// set is the environment of supplemental migrations, empty for the core migrations
+	set string
+}
+
+// New opens the migration source and the database
+func New(sourceURL, databaseURL string, logger *zap.Logger) (*Migrator, error) {
+	src, err := source.Open(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrate.New(sourceURL, databaseURL)
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+	return &Migrator{migrate: m, source: src, logger: logger}, nil
+}
+
+// Close releases the source and the database connection
+func (m *Migrator) Close() {
+	m.source.Close()
+	m.migrate.Close()
+}
+
+// Name returns the environment of the migration set, empty for the core migrations
+func (m *Migrator) Name() string {
+	return m.set
+}
+
+// Status returns the current migration state of the database
+func (m *Migrator) Status() (Status, error) {
+	version, dirty, err := m.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+	return Status{Version: version, Dirty: dirty}, nil
+}
+
+// Up applies every pending migration. It does not touch a dirty database and returns a *DirtyError instead,
+// which is also returned when a migration fails midway and leaves the database dirty.
+func (m *Migrator) Up() error {
+	if err := m.checkDirty(); err != nil {
+		return err
+	}
+	err := m.migrate.Up()
+	if errors.Is(err, migrate.ErrNoChange) {
+		m.logger.Info("No migrations to apply")
+		return nil
+	}
+	if err != nil {
+		m.logger.Error("Migration failed", zap.Error(err))
+		if dirtyErr := m.checkDirty(); dirtyErr != nil {
+			return fmt.Errorf("%w (%v)", dirtyErr, err)
+		}
+		return err
+	}
+	m.logger.Info("Migrations applied successfully")
+	return nil
+}
+
+// Force clears the dirty flag by recording version as the current one. Only the interrupted migration,
+// when it was in fact fully applied, or the version before it, when it was not, can be forced; any other
+// version would make later runs skip or repeat migrations.
+func (m *Migrator) Force(version int) error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if !status.Dirty {
+		return fmt.Errorf("%w (current version %d)", ErrNotDirty, status.Version)
+	}
+	previous, err := m.previous(status.Version)
+	if err != nil {
+		return err
+	}
+	if version != int(status.Version) && version != previous {
+		return fmt.Errorf("%w: dirty version is %d, previous is %d", ErrUnsafeForce, status.Version, previous)
+	}
+	m.logger.Warn("Forcing migration version", zap.Uint("dirty_version", status.Version), zap.Int("version", version))
+	return m.migrate.Force(version)
+}
+
+// checkDirty returns a *DirtyError when the database is dirty
+func (m *Migrator) checkDirty() error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if !status.Dirty {
+		return nil
+	}
+	previous, err := m.previous(status.Version)
+	if err != nil {
+		return err
+	}
+	return &DirtyError{Set: m.set, Version: status.Version, Previous: previous}
+}
+
+// previous returns the migration before version in the source, -1 when version is the first one
+func (m *Migrator) previous(version uint) (int, error) {
+	prev, err := m.source.Prev(version)
+	if errors.Is(err, os.ErrNotExist) {
+		first, err := m.source.First()
+		if err != nil || first != version {
+			return 0, fmt.Errorf("migration %d is not in the migration source", version)
+		}
+		return -1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int(prev), nil
+}
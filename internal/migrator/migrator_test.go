@@ -0,0 +1,88 @@
+package migrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrevious(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0001_create.up.sql", "000003_alter.up.sql", "000004_index.up.sql"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0o644))
+	}
+	src, err := source.Open("file://" + dir)
+	require.NoError(t, err)
+	defer src.Close()
+	m := &Migrator{source: src}
+
+	previous, err := m.previous(4)
+	require.NoError(t, err)
+	assert.Equal(t, 3, previous)
+
+	previous, err = m.previous(3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, previous, "gaps in the numbering are skipped")
+
+	previous, err = m.previous(1)
+	require.NoError(t, err)
+	assert.Equal(t, -1, previous, "the first migration has no previous version")
+
+	_, err = m.previous(2)
+	assert.Error(t, err)
+}
+
+func TestDirtyError(t *testing.T) {
+	err := &DirtyError{Version: 12, Previous: 11}
+	assert.Contains(t, err.Error(), "force version 12 if the migration was fully applied or 11 if it was not")
+}
+
+func TestRewriteIndexes(t *testing.T) {
+	sizes := map[string]int64{"songs": 5_000_000, "song_views": 10, "song_tags": -1}
+	rows := func(table string) (int64, error) { return sizes[table], nil }
+	migration := `CREATE INDEX IF NOT EXISTS idx_songs_group ON songs (group_name);
+CREATE UNIQUE INDEX idx_views ON song_views (song_id);
+create index idx_tags on only song_tags (tag_id);
+CREATE INDEX CONCURRENTLY idx_songs_link ON songs (link);`
+
+	rewritten, err := rewriteIndexes(migration, 100000, rows)
+	require.NoError(t, err)
+	assert.Equal(t, `CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_songs_group ON songs (group_name);
+CREATE UNIQUE INDEX idx_views ON song_views (song_id);
+CREATE INDEX CONCURRENTLY idx_tags on only song_tags (tag_id);
+CREATE INDEX CONCURRENTLY idx_songs_link ON songs (link);`, rewritten, "large and never analyzed tables are indexed concurrently")
+
+	_, err = rewriteIndexes(migration, 100000, func(string) (int64, error) { return 0, errors.New("connection refused") })
+	assert.Error(t, err)
+}
+
+func TestEnvironmentConfigFromEnv(t *testing.T) {
+	cfg, err := EnvironmentConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, EnvironmentConfig{ConcurrentIndexRows: DefaultConcurrentIndexRows}, cfg)
+
+	t.Setenv("MIGRATION_ENV", "production")
+	t.Setenv("MIGRATION_CONCURRENT_INDEX_ROWS", "0")
+	cfg, err = EnvironmentConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, EnvironmentConfig{Name: "production"}, cfg)
+
+	t.Setenv("MIGRATION_ENV", "prod; DROP TABLE songs")
+	_, err = EnvironmentConfigFromEnv()
+	assert.ErrorIs(t, err, ErrInvalidEnvironment)
+}
+
+func TestEnvironmentMigrationsParse(t *testing.T) {
+	for _, environment := range []string{"test", "production"} {
+		src, err := source.Open("file://../../migrations/environments/" + environment)
+		require.NoError(t, err, environment)
+		_, err = src.First()
+		assert.NoError(t, err, environment)
+		src.Close()
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Album groups songs released together. Songs link to it by their AlbumID.
+type Album struct {
+	XMLName xml.Name `json:"-" db:"-" xml:"album"`
+	ID      int      `json:"id" db:"id" xml:"id" example:"1"`
+	Title   string   `json:"title" db:"title" xml:"title" example:"Black Holes and Revelations"`
+	Group   string   `json:"group" db:"group_name" xml:"group" example:"Muse"`
+	// ReleaseDate and ArtworkURL are null when unknown
+	ReleaseDate *string   `json:"release_date" db:"release_date" xml:"release_date,omitempty" example:"03.07.2006"`
+	ArtworkURL  *string   `json:"artwork_url" db:"artwork_url" xml:"artwork_url,omitempty" example:"https://i.scdn.co/image/ab67616d0000b27328933b808bfb4cbbd0385400"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at" xml:"created_at" example:"2024-01-15T12:00:00Z"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at" xml:"updated_at" example:"2024-01-15T12:00:00Z"`
+}
+
+// AlbumInput holds the fields of an album being created or replaced; empty optional fields are stored as null
+type AlbumInput struct {
+	Title       string `json:"title" example:"Black Holes and Revelations"`
+	Group       string `json:"group" example:"Muse"`
+	ReleaseDate string `json:"release_date" example:"03.07.2006"`
+	ArtworkURL  string `json:"artwork_url" example:"https://i.scdn.co/image/ab67616d0000b27328933b808bfb4cbbd0385400"`
+}
+
+// AlbumPage is one page of albums with its pagination metadata
+type AlbumPage struct {
+	XMLName    xml.Name `json:"-" xml:"albums"`
+	Data       []Album  `json:"data" xml:"data>album"`
+	Total      int      `json:"total" xml:"total" example:"1"`
+	Page       int      `json:"page" xml:"page" example:"1"`
+	Limit      int      `json:"limit" xml:"limit" example:"10"`
+	TotalPages int      `json:"total_pages" xml:"total_pages" example:"1"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Artist is a group or performer. Songs are linked to the artist named by their group, which is created
+// when a song names a new group.
+type Artist struct {
+	XMLName xml.Name `json:"-" db:"-" xml:"artist"`
+	ID      int      `json:"id" db:"id" xml:"id" example:"1"`
+	Name    string   `json:"name" db:"name" xml:"name" example:"Muse"`
+	// Country is the ISO 3166-1 alpha-2 code of the country the artist comes from; Country, FormedYear and
+	// Bio are null when unknown
+	Country    *string   `json:"country" db:"country" xml:"country,omitempty" example:"GB"`
+	FormedYear *int      `json:"formed_year" db:"formed_year" xml:"formed_year,omitempty" example:"1994"`
+	Bio        *string   `json:"bio" db:"bio" xml:"bio,omitempty" example:"English rock band from Teignmouth, Devon."`
+	CreatedAt  time.Time `json:"created_at" db:"created_at" xml:"created_at" example:"2024-01-15T12:00:00Z"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at" xml:"updated_at" example:"2024-01-15T12:00:00Z"`
+}
+
+// ArtistInput holds the fields of an artist being created or replaced; empty optional fields are stored as null
+type ArtistInput struct {
+	Name       string `json:"name" example:"Muse"`
+	Country    string `json:"country" example:"GB"`
+	FormedYear int    `json:"formed_year" example:"1994"`
+	Bio        string `json:"bio" example:"English rock band from Teignmouth, Devon."`
+}
+
+// ArtistPage is one page of artists with its pagination metadata
+type ArtistPage struct {
+	XMLName    xml.Name `json:"-" xml:"artists"`
+	Data       []Artist `json:"data" xml:"data>artist"`
+	Total      int      `json:"total" xml:"total" example:"1"`
+	Page       int      `json:"page" xml:"page" example:"1"`
+	Limit      int      `json:"limit" xml:"limit" example:"10"`
+	TotalPages int      `json:"total_pages" xml:"total_pages" example:"1"`
+}
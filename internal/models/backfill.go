@@ -0,0 +1,22 @@
+package models
+
+// BackfillReport summarizes the legacy rows found, and normalized unless DryRun is set
+type BackfillReport struct {
+	DryRun bool `json:"dry_run"`
+	// MissingCreatedAt and MissingUpdatedAt count the songs without timestamps
+	MissingCreatedAt int `json:"missing_created_at"`
+	MissingUpdatedAt int `json:"missing_updated_at"`
+	// EmptyFields counts the blank optional fields stored as empty strings instead of NULL, by field
+	EmptyFields map[string]int `json:"empty_fields"`
+	// Duplicates lists the songs sharing a group and title, ignoring case and surrounding spaces
+	Duplicates []DuplicateSongs `json:"duplicates"`
+}
+
+// DuplicateSongs is a set of songs with the same group and title. The oldest song is kept and takes over
+// the data, views and tags of the others, which are removed.
+type DuplicateSongs struct {
+	Group      string `json:"group" db:"group_name"`
+	Song       string `json:"song" db:"song_name"`
+	KeptID     int    `json:"kept_id" db:"kept_id"`
+	RemovedIDs []int  `json:"removed_ids" db:"-"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// APICapture is a sampled request to an external provider and the response it got, kept to debug how
+// provider data is mapped. Credentials are redacted and bodies truncated before it is stored.
+type APICapture struct {
+	ID       int64  `json:"id" db:"id" example:"1"`
+	Provider string `json:"provider" db:"provider" example:"external_api"`
+	Method   string `json:"method" db:"method" example:"GET"`
+	URL      string `json:"url" db:"url" example:"https://api.example.com/info?group=Muse&song=Supermassive+Black+Hole"`
+	// RequestHeaders and ResponseHeaders map header names to their values
+	RequestHeaders json.RawMessage `json:"request_headers" db:"request_headers" swaggertype:"object"`
+	RequestBody    *string         `json:"request_body,omitempty" db:"request_body"`
+	// Status and the response are missing when the request failed before a response arrived
+	Status          *int             `json:"status,omitempty" db:"status" example:"200"`
+	ResponseHeaders *json.RawMessage `json:"response_headers,omitempty" db:"response_headers" swaggertype:"object"`
+	ResponseBody    *string          `json:"response_body,omitempty" db:"response_body" example:"{\"releaseDate\":\"16.07.2006\",\"text\":\"Ooh baby, don't you know I suffer?\",\"link\":\"https://www.youtube.com/watch?v=Xsp3_a-PMTw\"}"`
+	DurationMs      float64          `json:"duration_ms" db:"duration_ms" example:"182.4"`
+	Error           *string          `json:"error,omitempty" db:"error"`
+	CreatedAt       time.Time        `json:"created_at" db:"created_at" example:"2024-01-15T12:00:00Z"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Classification kinds
+const (
+	ClassificationGenre = "genre"
+	ClassificationMood  = "mood"
+)
+
+// Classification suggestion statuses
+const (
+	SuggestionPending  = "pending"
+	SuggestionAccepted = "accepted"
+	SuggestionRejected = "rejected"
+)
+
+// ClassificationSuggestion is a genre or mood proposed for a song by a classifier, awaiting review
+type ClassificationSuggestion struct {
+	ID         int        `db:"id" json:"id" example:"1"`
+	SongID     int        `db:"song_id" json:"song_id" example:"1"`
+	Group      string     `db:"group_name" json:"group" example:"Muse"`
+	Song       string     `db:"song_name" json:"song" example:"Supermassive Black Hole"`
+	Kind       string     `db:"kind" json:"kind" example:"mood"`
+	Value      string     `db:"value" json:"value" example:"romantic"`
+	Confidence float64    `db:"confidence" json:"confidence" example:"0.38"`
+	Source     string     `db:"source" json:"source" example:"wordlist"`
+	Status     string     `db:"status" json:"status" example:"pending"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at" example:"2024-01-15T12:00:00Z"`
+	ReviewedAt *time.Time `db:"reviewed_at" json:"reviewed_at,omitempty"`
+}
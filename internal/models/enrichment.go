@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Enrichment statuses of a song
+const (
+	// EnrichmentPending songs were added and wait for a background worker to fetch their details
+	EnrichmentPending = "pending_enrichment"
+	// EnrichmentComplete songs have their details, from the external API or the fallback data
+	EnrichmentComplete = "complete"
+	// EnrichmentFailed songs got no details: the external API had none and fallback is disabled
+	EnrichmentFailed = "failed"
+)
+
+// EnrichmentStatus reports how far the enrichment of a song has come
+type EnrichmentStatus struct {
+	SongID int    `json:"song_id" db:"id" example:"1"`
+	Status string `json:"status" db:"enrichment_status" example:"complete"`
+	// Error explains why enrichment failed
+	Error      *string    `json:"error,omitempty" db:"enrichment_error"`
+	EnrichedAt *time.Time `json:"enriched_at" db:"enriched_at" example:"2024-01-15T12:00:00Z"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at" example:"2024-01-15T12:00:00Z"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// SongEvent is a catalog event stored in the event log, from which event streams resume. SongID is zero for
+// events not tied to a song.
+type SongEvent struct {
+	ID         int64     `json:"id" db:"id"`
+	Type       string    `json:"type" db:"event_type"`
+	SongID     int       `json:"song_id,omitempty" db:"song_id"`
+	Count      int       `json:"count,omitempty" db:"count"`
+	OccurredAt time.Time `json:"occurred_at" db:"occurred_at"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Genre is a genre of the taxonomy songs are classified by. A genre may have a parent, of which it is a
+// subgenre, such as Alternative Rock under Rock; filtering songs by a genre matches its subgenres too.
+type Genre struct {
+	XMLName xml.Name `json:"-" db:"-" xml:"genre"`
+	ID      int      `json:"id" db:"id" xml:"id" example:"2"`
+	Name    string   `json:"name" db:"name" xml:"name" example:"Alternative Rock"`
+	// ParentID is null for a top-level genre
+	ParentID  *int      `json:"parent_id" db:"parent_id" xml:"parent_id,omitempty" example:"1"`
+	CreatedAt time.Time `json:"created_at" db:"created_at" xml:"created_at" example:"2024-01-15T12:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at" xml:"updated_at" example:"2024-01-15T12:00:00Z"`
+}
+
+// GenreInput holds the fields of a genre being created or replaced; a nil parent makes it a top-level genre
+type GenreInput struct {
+	Name     string `json:"name" example:"Alternative Rock"`
+	ParentID *int   `json:"parent_id" example:"1"`
+}
+
+// GenreList is the whole genre taxonomy, in name order
+type GenreList struct {
+	XMLName xml.Name `json:"-" xml:"genres"`
+	Data    []Genre  `json:"data" xml:"data>genre"`
+}
+
+// SongGenres are the genres a song is assigned, in name order
+type SongGenres struct {
+	XMLName xml.Name `json:"-" xml:"song_genres"`
+	SongID  int      `json:"song_id" xml:"song_id" example:"1"`
+	Genres  []Genre  `json:"genres" xml:"genres>genre"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Import statuses
+const (
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+type Import struct {
+	ID            string    `json:"id" db:"id"`
+	Status        string    `json:"status" db:"status"`
+	CheckpointRow int       `json:"checkpoint_row" db:"checkpoint_row"`
+	Created       int       `json:"created" db:"created"`
+	Updated       int       `json:"updated" db:"updated"`
+	Failed        int       `json:"failed" db:"failed"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ImportSong is a song written by an import batch; a non-zero ExistingID updates that song instead of inserting
+type ImportSong struct {
+	Row         int
+	ExistingID  int
+	Group       string
+	Song        string
+	ReleaseDate string
+	Text        string
+	Link        string
+	EnrichedAt  *time.Time
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// Job kinds
+const (
+	JobKindImport           = "import"
+	JobKindEnrichAll        = "enrich_all"
+	JobKindSimilarityReport = "similarity_report"
+)
+
+// Job is a long-running operation executed in the background, with its progress and outcome
+type Job struct {
+	ID     string `json:"id" db:"id" example:"5f2b7c0e9a1d4e3f8b6a0c2d4e6f8a1b"`
+	Kind   string `json:"kind" db:"kind" example:"enrich_all"`
+	Status string `json:"status" db:"status" example:"running"`
+	// OwnerID is the user who started the job; jobs started with the admin token have none
+	OwnerID *int `json:"owner_id,omitempty" db:"owner_id" example:"1"`
+	// Total is the number of items the job processes, zero while it is unknown
+	Total     int `json:"total" db:"total" example:"120"`
+	Processed int `json:"processed" db:"processed" example:"45"`
+	Failed    int `json:"failed" db:"failed" example:"4"`
+	// Result is the outcome reported by a finished job
+	Result     *json.RawMessage `json:"result,omitempty" db:"result" swaggertype:"object"`
+	Error      *string          `json:"error,omitempty" db:"error"`
+	CreatedAt  time.Time        `json:"created_at" db:"created_at" example:"2024-01-15T12:00:00Z"`
+	StartedAt  *time.Time       `json:"started_at,omitempty" db:"started_at" example:"2024-01-15T12:00:00Z"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty" db:"finished_at"`
+	UpdatedAt  time.Time        `json:"updated_at" db:"updated_at" example:"2024-01-15T12:00:00Z"`
+}
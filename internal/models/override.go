@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// SongOverride is a user's personal version of a song's text, such as their own corrections of the lyrics or
+// chords. It is shown to that user alone, in place of the shared text, and leaves the song itself unchanged.
+type SongOverride struct {
+	SongID    int       `db:"song_id" json:"song_id" example:"1"`
+	Text      string    `db:"text" json:"text" example:"Paranoia is in bloom,\nThe PR transmissions will resume"`
+	CreatedAt time.Time `db:"created_at" json:"created_at" example:"2024-01-15T12:00:00Z"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at" example:"2024-01-15T12:00:00Z"`
+}
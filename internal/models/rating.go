@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Bounds of a song rating
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// SongRating is a user's rating of a song together with the average of every user's ratings of it
+type SongRating struct {
+	SongID int `db:"song_id" json:"song_id" example:"1"`
+	// Rating is the user's rating from 1 to 5; null when the user has not rated the song
+	Rating *int `db:"rating" json:"rating" example:"4"`
+	// AverageRating is null while nobody has rated the song
+	AverageRating *float64 `db:"average_rating" json:"average_rating" example:"4.5"`
+	RatingCount   int      `db:"rating_count" json:"rating_count" example:"2"`
+}
+
+// SongFavorite is a song a user has marked as a favorite
+type SongFavorite struct {
+	SongID    int       `db:"song_id" json:"song_id" example:"1"`
+	CreatedAt time.Time `db:"created_at" json:"created_at" example:"2024-01-15T12:00:00Z"`
+}
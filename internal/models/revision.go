@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Reasons a song revision was recorded
+const (
+	// RevisionOriginal is the song as it was before its first recorded update
+	RevisionOriginal = "original"
+	RevisionUpdate   = "update"
+	RevisionRestore  = "restore"
+)
+
+// SongRevision is a full copy of the editable fields of a song, recorded each time the song is updated.
+// Revisions are numbered from 1 for each song; the highest is the song as it currently is.
+type SongRevision struct {
+	XMLName  xml.Name `json:"-" db:"-" xml:"revision"`
+	SongID   int      `json:"song_id" db:"song_id" xml:"song_id" example:"1"`
+	Revision int      `json:"revision" db:"revision" xml:"number" example:"2"`
+	Reason   string   `json:"reason" db:"reason" xml:"reason" example:"update"`
+	// RestoredFrom is the revision a restore brought back; null for other reasons
+	RestoredFrom *int             `json:"restored_from" db:"restored_from" xml:"restored_from,omitempty"`
+	Data         SongRevisionData `json:"data" db:"-" xml:"data"`
+	CreatedAt    time.Time        `json:"created_at" db:"created_at" xml:"created_at" example:"2024-01-15T12:00:00Z"`
+}
+
+// SongRevisionData is the content of a revision: the ID of the song and the fields updates can change. The
+// fields keep the JSON names of the song's, so the field visibility rules apply to them alike.
+type SongRevisionData struct {
+	ID            int      `json:"id" xml:"id" example:"1"`
+	Group         string   `json:"group" xml:"group" example:"Muse"`
+	Song          string   `json:"song" xml:"song" example:"Supermassive Black Hole"`
+	ReleaseDate   *string  `json:"release_date" xml:"release_date,omitempty" example:"16.07.2006"`
+	Text          *string  `json:"text" xml:"text,omitempty" example:"Ooh baby, don't you know I suffer?"`
+	Link          *string  `json:"link" xml:"link,omitempty" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	Notes         *string  `json:"notes" xml:"notes,omitempty"`
+	LicensingFee  *float64 `json:"licensing_fee" xml:"licensing_fee,omitempty"`
+	SplitStrategy *string  `json:"split_strategy" xml:"split_strategy,omitempty"`
+	AlbumID       *int     `json:"album_id" xml:"album_id,omitempty"`
+}
+
+// SongRevisionList is the revisions of a song, newest first
+type SongRevisionList struct {
+	XMLName xml.Name       `json:"-" xml:"song_revisions"`
+	SongID  int            `json:"song_id" xml:"song_id" example:"1"`
+	Data    []SongRevision `json:"data" xml:"revisions>revision"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// SongSummary identifies a song in reports
+type SongSummary struct {
+	ID    int    `json:"id" example:"1"`
+	Group string `json:"group" example:"Muse"`
+	Song  string `json:"song" example:"Supermassive Black Hole"`
+}
+
+// SimilarityPair is a pair of songs whose lyrics are suspiciously similar
+type SimilarityPair struct {
+	First      SongSummary `json:"first"`
+	Second     SongSummary `json:"second"`
+	Similarity float64     `json:"similarity" example:"0.94"`
+}
+
+// Similarity report statuses
+const (
+	SimilarityReportRunning   = "running"
+	SimilarityReportCompleted = "completed"
+	SimilarityReportFailed    = "failed"
+)
+
+// SimilarityReport lists the song pairs whose estimated lyrics similarity reaches the threshold
+type SimilarityReport struct {
+	// JobID is the background job computing the report, whose progress is available from GET /jobs/:id
+	JobID          string           `json:"job_id,omitempty" example:"5f2b7c0e9a1d4e3f8b6a0c2d4e6f8a1b"`
+	Status         string           `json:"status" example:"completed"`
+	Threshold      float64          `json:"threshold" example:"0.8"`
+	SongsScanned   int              `json:"songs_scanned" example:"2"`
+	CandidatePairs int              `json:"candidate_pairs" example:"1"`
+	SkippedBuckets int              `json:"skipped_buckets"`
+	Pairs          []SimilarityPair `json:"pairs"`
+	PairsTruncated bool             `json:"pairs_truncated"`
+	Error          string           `json:"error,omitempty"`
+	StartedAt      time.Time        `json:"started_at" example:"2024-01-15T12:00:00Z"`
+	FinishedAt     *time.Time       `json:"finished_at,omitempty" example:"2024-01-15T12:00:02Z"`
+}
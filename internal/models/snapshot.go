@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Reasons a snapshot was taken for
+const (
+	SnapshotTruncate = "truncate"
+)
+
+// Snapshot is a copy of the songs and the rows attached to them, taken before a destructive change so the
+// change can be reversed by restoring it
+type Snapshot struct {
+	ID        int       `json:"id" db:"id" example:"1"`
+	Reason    string    `json:"reason" db:"reason" example:"truncate"`
+	SongCount int       `json:"song_count" db:"song_count" example:"1"`
+	CreatedAt time.Time `json:"created_at" db:"created_at" example:"2024-01-15T12:00:00Z"`
+	// RestoredAt is the last time the snapshot was restored, null when it never was
+	RestoredAt *time.Time `json:"restored_at" db:"restored_at"`
+}
@@ -1,19 +1,261 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"encoding/xml"
+	"sort"
+	"time"
+)
 
 type Song struct {
-	ID          int       `json:"id" db:"id"`
-	Group       string    `json:"group" db:"group_name"`
-	Song        string    `json:"song" db:"song_name"`
-	ReleaseDate string    `json:"release_date" db:"release_date"`
-	Text        string    `json:"text" db:"text"`
-	Link        string    `json:"link" db:"link"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	XMLName xml.Name `json:"-" db:"-" xml:"song"`
+	ID      int      `json:"id" db:"id" xml:"id" example:"1"`
+	Group   string   `json:"group" db:"group_name" xml:"group" example:"Muse"`
+	Song    string   `json:"song" db:"song_name" xml:"song" example:"Supermassive Black Hole"`
+	// ReleaseDate, Text and Link are null when unknown, which is distinct from an empty value. XML leaves
+	// out null fields.
+	ReleaseDate *string   `json:"release_date" db:"release_date" xml:"release_date,omitempty" example:"16.07.2006"`
+	Text        *string   `json:"text" db:"text" xml:"text,omitempty" example:"Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n\nOoh baby, don't you know I suffer?\nOoh baby, can you hear me moan?"`
+	Link        *string   `json:"link" db:"link" xml:"link,omitempty" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at" xml:"created_at" example:"2024-01-15T12:00:00Z"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at" xml:"updated_at" example:"2024-01-15T12:00:00Z"`
+	// EnrichedAt is when the external API last provided data for the song; null when it never did
+	EnrichedAt *time.Time `json:"enriched_at" db:"enriched_at" xml:"enriched_at,omitempty" example:"2024-01-15T12:00:00Z"`
+	// EnrichmentStatus is pending_enrichment until a background worker has fetched the song's details
+	EnrichmentStatus string `json:"enrichment_status" db:"enrichment_status" xml:"enrichment_status" example:"complete"`
+	// Notes and LicensingFee are staff fields, hidden from lesser roles by the field visibility rules
+	Notes        *string  `json:"notes" db:"notes" xml:"notes,omitempty"`
+	LicensingFee *float64 `json:"licensing_fee" db:"licensing_fee" xml:"licensing_fee,omitempty"`
+	// Album, DurationMs, ISRC and ArtworkURL are synced from Spotify; null until a matching track is found
+	Album      *string `json:"album" db:"album" xml:"album,omitempty" example:"Black Holes and Revelations"`
+	DurationMs *int    `json:"duration_ms" db:"duration_ms" xml:"duration_ms,omitempty" example:"212000"`
+	ISRC       *string `json:"isrc" db:"isrc" xml:"isrc,omitempty" example:"GBAHT0500600"`
+	ArtworkURL *string `json:"artwork_url" db:"artwork_url" xml:"artwork_url,omitempty" example:"https://i.scdn.co/image/ab67616d0000b27328933b808bfb4cbbd0385400"`
+	Views      int64   `json:"views" db:"views" xml:"views" example:"42"`
+	// LegalHold is set by admins to freeze the song: while it is set the song cannot be modified or deleted
+	LegalHold bool `json:"legal_hold" db:"legal_hold" xml:"legal_hold"`
+	// SplitStrategy is how the song's text is split into verses when a request names no delimiter; null
+	// for the configured default
+	SplitStrategy *string `json:"split_strategy" db:"split_strategy" xml:"split_strategy,omitempty" example:"blank-line"`
+	// AlbumID is the album the song belongs to; null when it belongs to none
+	AlbumID *int `json:"album_id" db:"album_id" xml:"album_id,omitempty" example:"1"`
+	// ArtistID is the artist named by Group, which follows the group as it changes
+	ArtistID *int `json:"artist_id" db:"artist_id" xml:"artist_id,omitempty" example:"1"`
+	// LocalizedTitle is the title in the language the request asked for by Accept-Language or ?title_lang=,
+	// absent when the song has none in that language; Song stays the original title
+	LocalizedTitle *LocalizedTitle `json:"localized_title,omitempty" db:"-" xml:"localized_title,omitempty"`
+	// AverageRating is the average of the users' 1 to 5 ratings of the song; null while nobody has rated it
+	AverageRating *float64 `json:"average_rating" db:"average_rating" xml:"average_rating,omitempty" example:"4.5"`
+	RatingCount   int      `json:"rating_count" db:"rating_count" xml:"rating_count" example:"2"`
+}
+
+// TrackMetadata is the metadata of a song's track in a streaming catalog; nil fields are unknown
+type TrackMetadata struct {
+	Album      *string
+	DurationMs *int
+	ISRC       *string
+	ArtworkURL *string
+}
+
+// StringValue returns the value of a nullable field, or an empty string when it is null
+func StringValue(field *string) string {
+	if field == nil {
+		return ""
+	}
+	return *field
+}
+
+// NullableString returns a pointer to the value, or nil when it is empty
+func NullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// SongFilter selects the songs listed by GetSongs
+type SongFilter struct {
+	// Group and Song match songs whose group and title contain the values
+	Group string
+	Song  string
+	// StaleThan, when positive, keeps only songs not enriched within that duration
+	StaleThan time.Duration
+	// Missing keeps only songs whose listed optional fields (release_date, text, link) are unknown
+	Missing []string
+	// Text, when set, keeps only songs whose text equals the value
+	Text string
+	// Tags keeps only songs carrying every listed tag
+	Tags []string
+	// Genre, when set, keeps only songs assigned the genre of that name or one of its subgenres
+	Genre string
+	// FavoritesOf, when set, keeps only the songs the user with that ID has marked as favorites
+	FavoritesOf int
+	// MinRating, when positive, keeps only songs whose average rating is at least the value
+	MinRating float64
 }
 
 type Verse struct {
 	Number int    `json:"number"`
 	Text   string `json:"text"`
 }
+
+// SongPage is one page of songs together with the pagination metadata clients need to render paginators
+type SongPage struct {
+	XMLName    xml.Name `json:"-" xml:"songs"`
+	Data       []Song   `json:"data" xml:"data>song"`
+	Total      int      `json:"total" xml:"total" example:"1"`
+	Page       int      `json:"page" xml:"page" example:"1"`
+	Limit      int      `json:"limit" xml:"limit" example:"10"`
+	TotalPages int      `json:"total_pages" xml:"total_pages" example:"1"`
+	Facets     Facets   `json:"facets,omitempty" xml:"facets,omitempty" swaggertype:"object"`
+}
+
+// Facets maps the name of a facet to its buckets
+type Facets map[string][]FacetBucket
+
+// MarshalXML writes the facets as facet elements named by their name attribute, in name order, since
+// XML has no maps
+func (f Facets) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, name := range names {
+		facet := xml.StartElement{Name: xml.Name{Local: "facet"}, Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: name}}}
+		buckets := struct {
+			Buckets []FacetBucket `xml:"bucket"`
+		}{f[name]}
+		if err := e.EncodeElement(buckets, facet); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+type FacetBucket struct {
+	Value string `json:"value" db:"value" xml:"value,attr" example:"2006"`
+	Count int    `json:"count" db:"count" xml:"count,attr" example:"1"`
+}
+
+type Digest struct {
+	XMLName      xml.Name  `json:"-" xml:"digest"`
+	PeriodStart  time.Time `json:"period_start" xml:"period_start" example:"2024-01-08T12:00:00Z"`
+	PeriodEnd    time.Time `json:"period_end" xml:"period_end" example:"2024-01-15T12:00:00Z"`
+	NewSongs     []Song    `json:"new_songs" xml:"new_songs>song"`
+	UpdatedSongs []Song    `json:"updated_songs" xml:"updated_songs>song"`
+	GeneratedAt  time.Time `json:"generated_at" xml:"generated_at" example:"2024-01-15T12:00:00Z"`
+}
+
+// SearchResult is a song matched by a search, with its relevance score (higher is better).
+// Keyword searches also return a lyrics snippet with the matches wrapped in <mark> tags.
+type SearchResult struct {
+	Song
+	Score   float64 `json:"score" db:"score" xml:"score" example:"0.87"`
+	Snippet string  `json:"snippet,omitempty" db:"snippet" xml:"snippet,omitempty" example:"Ooh <mark>baby</mark>, don't you know I suffer?"`
+}
+
+// Search suggestion sources
+const (
+	SuggestionGroup  = "group"
+	SuggestionSong   = "song"
+	SuggestionLyrics = "lyrics"
+)
+
+// SearchSuggestion is a corrected query offered when a search finds nothing: a similarly spelled group or
+// song name, or the query with its words replaced by similar lyric terms. Higher scores are closer.
+type SearchSuggestion struct {
+	Query  string  `json:"query" db:"query" xml:"query" example:"Muse"`
+	Source string  `json:"source" db:"source" xml:"source" example:"group"`
+	Score  float64 `json:"score" db:"score" xml:"score" example:"0.8"`
+}
+
+// SearchPage is the response of a search, with suggestions when it found nothing
+type SearchPage struct {
+	XMLName     xml.Name           `json:"-" xml:"search"`
+	Data        []SearchResult     `json:"data" xml:"data>song"`
+	Suggestions []SearchSuggestion `json:"suggestions,omitempty" xml:"suggestions>suggestion,omitempty"`
+}
+
+type TrendingSong struct {
+	Song
+	Score float64 `json:"score" db:"score" xml:"score" example:"3.7"`
+}
+
+type SongInput struct {
+	Group       string
+	Song        string
+	ReleaseDate string
+	Text        string
+	Link        string
+	EnrichedAt  *time.Time
+}
+
+// OptionalString is a JSON field that records whether it was present in the payload and whether it was null
+type OptionalString struct {
+	Set   bool
+	Null  bool
+	Value string
+}
+
+// UnmarshalJSON marks the field as set; a JSON null sets Null instead of Value
+func (o *OptionalString) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+// OptionalFloat is a numeric JSON field that records whether it was present in the payload and whether it was null
+type OptionalFloat struct {
+	Set   bool
+	Null  bool
+	Value float64
+}
+
+// UnmarshalJSON marks the field as set; a JSON null sets Null instead of Value
+func (o *OptionalFloat) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+// OptionalInt is an integer JSON field that records whether it was present in the payload and whether it was null
+type OptionalInt struct {
+	Set   bool
+	Null  bool
+	Value int
+}
+
+// UnmarshalJSON marks the field as set; a JSON null sets Null instead of Value
+func (o *OptionalInt) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+// SongPatch holds the fields of a partial song update. Fields absent from the payload are left unchanged.
+type SongPatch struct {
+	Group        OptionalString `json:"group" swaggertype:"string" example:"Muse"`
+	Song         OptionalString `json:"song" swaggertype:"string" example:"Supermassive Black Hole"`
+	ReleaseDate  OptionalString `json:"release_date" swaggertype:"string" example:"16.07.2006"`
+	Text         OptionalString `json:"text" swaggertype:"string"`
+	Link         OptionalString `json:"link" swaggertype:"string"`
+	Notes        OptionalString `json:"notes" swaggertype:"string"`
+	LicensingFee OptionalFloat  `json:"licensing_fee" swaggertype:"number"`
+	// SplitStrategy set to null returns the song to the configured verse delimiter
+	SplitStrategy OptionalString `json:"split_strategy" swaggertype:"string" example:"markers"`
+	// AlbumID set to null removes the song from its album
+	AlbumID OptionalInt `json:"album_id" swaggertype:"integer" example:"1"`
+}
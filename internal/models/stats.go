@@ -0,0 +1,17 @@
+package models
+
+import "encoding/xml"
+
+// GroupStats summarizes the catalog of one group
+type GroupStats struct {
+	XMLName xml.Name `json:"-" db:"-" xml:"group_stats"`
+	Group   string   `json:"group" db:"group_name" xml:"group" example:"Muse"`
+	Songs   int      `json:"songs" db:"songs" xml:"songs" example:"1"`
+	// EarliestRelease and LatestRelease are the extreme release dates of the group's songs, DD.MM.YYYY
+	EarliestRelease *string `json:"earliest_release" db:"earliest_release" xml:"earliest_release,omitempty" example:"16.07.2006"`
+	LatestRelease   *string `json:"latest_release" db:"latest_release" xml:"latest_release,omitempty" example:"16.07.2006"`
+	TotalViews      int64   `json:"total_views" db:"total_views" xml:"total_views" example:"42"`
+	// AverageRating stays null while songs carry no ratings
+	AverageRating *float64 `json:"average_rating" db:"average_rating" xml:"average_rating,omitempty" example:"4.5"`
+	MostViewed    []Song   `json:"most_viewed" db:"-" xml:"most_viewed>song"`
+}
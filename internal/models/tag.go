@@ -0,0 +1,14 @@
+package models
+
+// BulkTagResult summarizes a bulk tag assignment
+type BulkTagResult struct {
+	// Matched is the number of songs the tags were applied to
+	Matched int `json:"matched" example:"2"`
+	// MissingIDs lists the requested song IDs that do not exist
+	MissingIDs []int `json:"missing_ids,omitempty" example:"7"`
+	// Added and Removed count the song-tag assignments actually created and deleted
+	Added   int `json:"added" example:"3"`
+	Removed int `json:"removed" example:"1"`
+	// SongIDs lists the songs the tags were applied to, for the service to check their legal holds
+	SongIDs []int `json:"-"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Kinds of song titles
+const (
+	// TitleTranslation is the title translated into another language
+	TitleTranslation = "translation"
+	// TitleTransliteration is the original title written in another script, such as Cyrillic in Latin letters
+	TitleTransliteration = "transliteration"
+)
+
+// SongTitle is a title of a song in a language other than its original one
+type SongTitle struct {
+	XMLName xml.Name `json:"-" db:"-" xml:"title"`
+	SongID  int      `json:"song_id" db:"song_id" xml:"song_id" example:"1"`
+	// Lang is the BCP 47 language tag of the title, such as "en", "ja-Latn" or "zh-Hans"
+	Lang      string    `json:"lang" db:"lang" xml:"lang" example:"ja"`
+	Title     string    `json:"title" db:"title" xml:"text" example:"スーパーマッシブ・ブラックホール"`
+	Kind      string    `json:"kind" db:"kind" xml:"kind" example:"translation"`
+	CreatedAt time.Time `json:"created_at" db:"created_at" xml:"created_at" example:"2024-01-15T12:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at" xml:"updated_at" example:"2024-01-15T12:00:00Z"`
+}
+
+// SongTitleInput holds the fields of a song title being set; an empty kind is a translation
+type SongTitleInput struct {
+	Title string `json:"title" example:"スーパーマッシブ・ブラックホール"`
+	Kind  string `json:"kind" example:"translation"`
+}
+
+// SongTitles are the titles of a song, in language order
+type SongTitles struct {
+	XMLName xml.Name    `json:"-" xml:"song_titles"`
+	SongID  int         `json:"song_id" xml:"song_id" example:"1"`
+	Titles  []SongTitle `json:"titles" xml:"titles>title"`
+}
+
+// LocalizedTitle is the title of a song in the language a request asked for
+type LocalizedTitle struct {
+	Lang  string `json:"lang" xml:"lang,attr" example:"ja"`
+	Kind  string `json:"kind" xml:"kind,attr" example:"translation"`
+	Title string `json:"title" xml:",chardata" example:"スーパーマッシブ・ブラックホール"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// TrashedSong is a deleted song kept in the trash, from which it can be restored until it is purged
+type TrashedSong struct {
+	XMLName xml.Name `json:"-" db:"-" xml:"song"`
+	// ID is the ID the song had, and gets back when restored
+	ID        int       `json:"id" db:"id" xml:"id" example:"1"`
+	Group     string    `json:"group" db:"group_name" xml:"group" example:"Muse"`
+	Song      string    `json:"song" db:"song_name" xml:"song" example:"Supermassive Black Hole"`
+	DeletedAt time.Time `json:"deleted_at" db:"deleted_at" xml:"deleted_at" example:"2024-01-15T12:00:00Z"`
+	// PurgeAt is when the song is deleted for good unless restored before
+	PurgeAt time.Time `json:"purge_at" db:"-" xml:"purge_at" example:"2024-02-14T12:00:00Z"`
+}
+
+// TrashPage is a page of the trash, most recently deleted first
+type TrashPage struct {
+	XMLName    xml.Name      `json:"-" xml:"trash"`
+	Data       []TrashedSong `json:"data" xml:"data>song"`
+	Total      int           `json:"total" xml:"total" example:"1"`
+	Page       int           `json:"page" xml:"page" example:"1"`
+	Limit      int           `json:"limit" xml:"limit" example:"10"`
+	TotalPages int           `json:"total_pages" xml:"total_pages" example:"1"`
+}
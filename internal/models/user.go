@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// User roles, from the least to the most privileged. Every role includes the permissions of the roles before it.
+const (
+	// RoleViewer may read the library
+	RoleViewer = "viewer"
+	// RoleEditor may also add and modify songs
+	RoleEditor = "editor"
+	// RoleAdmin may also delete songs and truncate the library
+	RoleAdmin = "admin"
+)
+
+// roleRanks orders the roles by privilege
+var roleRanks = map[string]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3}
+
+// IsValidRole reports whether the role is one of the known roles
+func IsValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// HasRole reports whether the role grants the permissions of the required role
+func HasRole(role, required string) bool {
+	return IsValidRole(role) && roleRanks[role] >= roleRanks[required]
+}
+
+// User is an account allowed to access the library
+type User struct {
+	ID           int       `db:"id" json:"id" example:"1"`
+	Username     string    `db:"username" json:"username" example:"alice"`
+	PasswordHash string    `db:"password_hash" json:"-"`
+	Role         string    `db:"role" json:"role" example:"editor"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at" example:"2024-01-15T12:00:00Z"`
+}
+
+// Preferences are a user's defaults, applied when a request does not specify the value itself.
+// Zero values mean the user has no preference.
+type Preferences struct {
+	PageSize int    `db:"page_size" json:"page_size" example:"25"`
+	Sort     string `db:"sort" json:"sort" example:"views"`
+	// Language picks the localized song titles when a request names no language. ExplicitFilter is stored
+	// for clients, as songs carry no content rating yet.
+	Language       string `db:"language" json:"language" example:"en"`
+	ExplicitFilter bool   `db:"explicit_filter" json:"explicit_filter"`
+	// APICompat set to "legacy" keeps the response shapes from before the pagination envelope
+	APICompat string    `db:"api_compat" json:"api_compat"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at" example:"2024-01-15T12:00:00Z"`
+}
+
+// APICompatLegacy is the api_compat preference, and X-API-Compat header value, selecting the legacy response shapes
+const APICompatLegacy = "legacy"
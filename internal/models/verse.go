@@ -0,0 +1,31 @@
+package models
+
+// VerseIndex is the stored verse layout of a song's text, read with the song it belongs to. Indexed is false
+// when the song has no layout for the delimiter, or its text changed since the layout was stored.
+type VerseIndex struct {
+	SongID int    `db:"song_id"`
+	Group  string `db:"group_name"`
+	Song   string `db:"song_name"`
+	// SplitStrategy is the song's own verse delimiter, null for the configured default
+	SplitStrategy *string `db:"split_strategy"`
+	// Indexed is set when an index of the song's current text is stored, split at Delimiter
+	Indexed     bool   `db:"indexed"`
+	Delimiter   string `db:"delimiter"`
+	TotalVerses int    `db:"total_verses"`
+}
+
+// VerseSpan locates a verse in a song's text by characters, counted in the text with CRLF line endings
+// turned into LF
+type VerseSpan struct {
+	Number int    `db:"number"`
+	Label  string `db:"label"`
+	Start  int    `db:"start_offset"`
+	Length int    `db:"length"`
+}
+
+// IndexedVerse is a verse cut out of a song's text by its stored span
+type IndexedVerse struct {
+	Number int    `db:"number"`
+	Label  string `db:"label"`
+	Text   string `db:"text"`
+}
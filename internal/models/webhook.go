@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is a URL notified of the catalog events it subscribes to. Deliveries are signed with the secret,
+// which is only shown when the webhook is created or its secret rotated.
+type Webhook struct {
+	ID        int            `json:"id" db:"id" example:"1"`
+	URL       string         `json:"url" db:"url" example:"https://example.com/hooks/music"`
+	Events    pq.StringArray `json:"events" db:"events" swaggertype:"array,string" example:"song.created,song.updated"`
+	Secret    string         `json:"secret,omitempty" db:"secret" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at" example:"2024-01-15T12:00:00Z"`
+	// PreviousSecret is the secret replaced by the latest rotation; deliveries are signed with it too until
+	// the rotation grace period is over
+	PreviousSecret  *string    `json:"-" db:"previous_secret"`
+	SecretRotatedAt *time.Time `json:"secret_rotated_at,omitempty" db:"secret_rotated_at"`
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a webhook, with the outcome of its latest attempt
+type WebhookDelivery struct {
+	ID        int64           `json:"id" db:"id" example:"7"`
+	WebhookID int             `json:"webhook_id" db:"webhook_id" example:"1"`
+	EventType string          `json:"event_type" db:"event_type" example:"song.updated"`
+	Payload   json.RawMessage `json:"payload" db:"payload" swaggertype:"object"`
+	Status    string          `json:"status" db:"status" example:"delivered"`
+	Attempts  int             `json:"attempts" db:"attempts" example:"1"`
+	// NextAttemptAt is when a pending delivery is attempted next
+	NextAttemptAt  time.Time  `json:"next_attempt_at" db:"next_attempt_at" example:"2024-01-15T12:00:00Z"`
+	ResponseStatus *int       `json:"response_status,omitempty" db:"response_status" example:"200"`
+	Error          *string    `json:"error,omitempty" db:"error"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at" example:"2024-01-15T12:00:00Z"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" db:"delivered_at" example:"2024-01-15T12:00:01Z"`
+}
+
+// DueDelivery is a delivery claimed for an attempt, with the webhook it goes to
+type DueDelivery struct {
+	WebhookDelivery
+	URL             string     `db:"url"`
+	Secret          string     `db:"secret"`
+	PreviousSecret  *string    `db:"previous_secret"`
+	SecretRotatedAt *time.Time `db:"secret_rotated_at"`
+}
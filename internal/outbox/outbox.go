@@ -0,0 +1,192 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/changes"
+	"music-library/internal/models"
+)
+
+// Store holds the outbox: the catalog events written with the changes they record and not yet published
+type Store interface {
+	RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	ClaimSongEvents(ctx context.Context, limit int) ([]models.SongEvent, error)
+	MarkSongEventsPublished(ctx context.Context, ids []int64) error
+}
+
+// Subscriber receives the events published from the outbox. It runs within the transaction marking the event
+// published, so writes it makes with ctx are committed together with that mark. An error leaves the event in
+// the outbox to be published again, to every subscriber.
+type Subscriber func(ctx context.Context, event changes.Event) error
+
+// Config sizes the relay
+type Config struct {
+	// PollInterval is the time between two checks of the outbox when the relay is not notified, which picks
+	// up events left by a crash or written by other instances
+	PollInterval time.Duration
+	// BatchSize is the number of events published in one transaction
+	BatchSize int
+}
+
+// DefaultConfig is used for the values NewRelay is not given
+var DefaultConfig = Config{
+	PollInterval: 5 * time.Second,
+	BatchSize:    100,
+}
+
+// ConfigFromEnv reads the relay configuration from OUTBOX_POLL_INTERVAL and OUTBOX_BATCH_SIZE
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig
+	if value := os.Getenv("OUTBOX_POLL_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return cfg, fmt.Errorf("OUTBOX_POLL_INTERVAL must be a positive duration: %q", value)
+		}
+		cfg.PollInterval = interval
+	}
+	if value := os.Getenv("OUTBOX_BATCH_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 {
+			return cfg, fmt.Errorf("OUTBOX_BATCH_SIZE must be a positive number: %q", value)
+		}
+		cfg.BatchSize = size
+	}
+	return cfg, nil
+}
+
+// subscription is a named subscriber of the relay
+type subscription struct {
+	name    string
+	deliver Subscriber
+}
+
+// Relay publishes the events of the outbox to its subscribers in the order they were written. Events are
+// published at least once: one a subscriber fails on is published again, including to the subscribers that
+// already received it, so subscribers tolerate duplicates and dedupe by event ID.
+type Relay struct {
+	store       Store
+	logger      *zap.Logger
+	cfg         Config
+	subscribers []subscription
+	// wake starts a publication early when events were written
+	wake chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewRelay creates a relay; a zero configuration takes the defaults
+func NewRelay(store Store, logger *zap.Logger, cfg Config) *Relay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultConfig.PollInterval
+	}
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = DefaultConfig.BatchSize
+	}
+	return &Relay{
+		store:  store,
+		logger: logger,
+		cfg:    cfg,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Subscribe adds a subscriber receiving every event published from now on. Subscribers are added before Start.
+func (r *Relay) Subscribe(name string, subscriber Subscriber) {
+	r.subscribers = append(r.subscribers, subscription{name: name, deliver: subscriber})
+}
+
+// Start publishes the outbox until ctx is cancelled, starting with the events left by a previous process
+func (r *Relay) Start(ctx context.Context) {
+	r.logger.Info("Starting outbox relay", zap.Int("subscribers", len(r.subscribers)), zap.Duration("poll_interval", r.cfg.PollInterval))
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.cfg.PollInterval)
+		defer ticker.Stop()
+		for {
+			r.drain(ctx)
+			select {
+			case <-ctx.Done():
+				r.logger.Info("Outbox relay stopped")
+				return
+			case <-ticker.C:
+			case <-r.wake:
+			}
+		}
+	}()
+}
+
+// Notify tells the relay that events were committed to the outbox, so they are published without waiting
+// for the next poll
+func (r *Relay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Wait blocks until the relay has stopped
+func (r *Relay) Wait() {
+	r.wg.Wait()
+}
+
+// drain publishes batches of events until the outbox is empty, a batch fails or ctx is done
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		published, err := r.publish(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.logger.Error("Failed to publish outbox events, retrying later", zap.Error(err))
+			}
+			return
+		}
+		if published < r.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// publish hands a batch of events to every subscriber and marks them published in one transaction, returning
+// the number of events published
+func (r *Relay) publish(ctx context.Context) (int, error) {
+	published := 0
+	err := r.store.RunInTransaction(ctx, func(ctx context.Context) error {
+		events, err := r.store.ClaimSongEvents(ctx, r.cfg.BatchSize)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		ids := make([]int64, len(events))
+		for i, stored := range events {
+			event := changes.Event{
+				ID:         uint64(stored.ID),
+				Type:       stored.Type,
+				SongID:     stored.SongID,
+				Count:      stored.Count,
+				OccurredAt: stored.OccurredAt,
+			}
+			for _, subscriber := range r.subscribers {
+				if err := subscriber.deliver(ctx, event); err != nil {
+					return fmt.Errorf("subscriber %s failed on event %d: %w", subscriber.name, event.ID, err)
+				}
+			}
+			ids[i] = stored.ID
+		}
+		if err := r.store.MarkSongEventsPublished(ctx, ids); err != nil {
+			return err
+		}
+		published = len(events)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if published > 0 {
+		r.logger.Debug("Outbox events published", zap.Int("count", published))
+	}
+	return published, nil
+}
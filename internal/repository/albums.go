@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// selectAlbums selects albums
+const selectAlbums = "SELECT id, title, group_name, release_date, artwork_url, created_at, updated_at FROM albums"
+
+// albumValues maps the fields of an album to its columns
+func albumValues(album models.AlbumInput) map[string]any {
+	return map[string]any{
+		"title":        album.Title,
+		"group_name":   album.Group,
+		"release_date": nullIfEmpty(album.ReleaseDate),
+		"artwork_url":  nullIfEmpty(album.ArtworkURL),
+	}
+}
+
+// CreateAlbum adds an album and returns its ID
+func (r *PostgresRepository) CreateAlbum(ctx context.Context, album models.AlbumInput) (int, error) {
+	r.logger.Debug("Creating album", zap.String("title", album.Title), zap.String("group", album.Group))
+	return r.albums.Insert(ctx, albumValues(album))
+}
+
+// GetAlbum retrieves an album, returning sql.ErrNoRows when it does not exist
+func (r *PostgresRepository) GetAlbum(ctx context.Context, id int) (models.Album, error) {
+	return r.albums.Get(ctx, id)
+}
+
+// GetAlbums retrieves a page of albums ordered by ID together with the number of albums
+func (r *PostgresRepository) GetAlbums(ctx context.Context, page, limit int) ([]models.Album, int, error) {
+	r.logger.Debug("Fetching albums", zap.Int("page", page), zap.Int("limit", limit))
+	albums, err := r.albums.List(ctx, "", nil, "id", page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.albums.Count(ctx, "", nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return albums, total, nil
+}
+
+// UpdateAlbum replaces the fields of an album, returning sql.ErrNoRows when it does not exist
+func (r *PostgresRepository) UpdateAlbum(ctx context.Context, id int, album models.AlbumInput) error {
+	r.logger.Debug("Updating album", zap.Int("id", id))
+	return r.albums.Update(ctx, id, albumValues(album))
+}
+
+// DeleteAlbum deletes an album, returning sql.ErrNoRows when it does not exist. Its songs are kept and
+// no longer belong to an album.
+func (r *PostgresRepository) DeleteAlbum(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting album", zap.Int("id", id))
+	if err := r.albums.Delete(ctx, id); err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to delete album", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// GetAlbumSongs retrieves a page of the songs of an album ordered by ID together with the number of its songs
+func (r *PostgresRepository) GetAlbumSongs(ctx context.Context, albumID, page, limit int) ([]models.Song, int, error) {
+	r.logger.Debug("Fetching album songs", zap.Int("album_id", albumID), zap.Int("page", page), zap.Int("limit", limit))
+	where, args := "s.album_id = $1", []any{albumID}
+	songs, err := r.songs.List(ctx, where, args, "s.id", page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.songs.Count(ctx, where, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	return songs, total, nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// selectArtists selects artists
+const selectArtists = "SELECT id, name, country, formed_year, bio, created_at, updated_at FROM artists"
+
+// nullIfZero stores an unknown year as NULL
+func nullIfZero(value int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(value), Valid: value != 0}
+}
+
+// CreateArtist adds an artist and returns its ID, or sql.ErrNoRows when the name is taken
+func (r *PostgresRepository) CreateArtist(ctx context.Context, artist models.ArtistInput) (int, error) {
+	r.logger.Debug("Creating artist", zap.String("name", artist.Name))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO artists (name, country, formed_year, bio) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO NOTHING RETURNING id`
+	var id int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &id, query, artist.Name, nullIfEmpty(artist.Country), nullIfZero(artist.FormedYear), nullIfEmpty(artist.Bio))
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to create artist", zap.String("name", artist.Name), zap.Error(err))
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetArtist retrieves an artist, returning sql.ErrNoRows when it does not exist
+func (r *PostgresRepository) GetArtist(ctx context.Context, id int) (models.Artist, error) {
+	return r.artists.Get(ctx, id)
+}
+
+// GetArtistByName retrieves the artist with the name, returning sql.ErrNoRows when there is none
+func (r *PostgresRepository) GetArtistByName(ctx context.Context, name string) (models.Artist, error) {
+	artists, err := r.artists.Find(ctx, "name = $1", []any{name}, "id")
+	if err != nil {
+		return models.Artist{}, err
+	}
+	if len(artists) == 0 {
+		return models.Artist{}, sql.ErrNoRows
+	}
+	return artists[0], nil
+}
+
+// GetArtists retrieves a page of artists ordered by ID together with the number of artists
+func (r *PostgresRepository) GetArtists(ctx context.Context, page, limit int) ([]models.Artist, int, error) {
+	r.logger.Debug("Fetching artists", zap.Int("page", page), zap.Int("limit", limit))
+	artists, err := r.artists.List(ctx, "", nil, "id", page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.artists.Count(ctx, "", nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return artists, total, nil
+}
+
+// UpdateArtist replaces the fields of an artist, returning sql.ErrNoRows when it does not exist. A new name
+// is carried over to the group of the artist's songs, whose IDs are returned.
+func (r *PostgresRepository) UpdateArtist(ctx context.Context, id int, artist models.ArtistInput) ([]int, error) {
+	r.logger.Debug("Updating artist", zap.Int("id", id))
+	err := r.artists.Update(ctx, id, map[string]any{
+		"name":        artist.Name,
+		"country":     nullIfEmpty(artist.Country),
+		"formed_year": nullIfZero(artist.FormedYear),
+		"bio":         nullIfEmpty(artist.Bio),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "UPDATE songs SET group_name = $2 WHERE artist_id = $1 AND group_name <> $2 RETURNING id"
+	renamed := []int{}
+	start := time.Now()
+	err = r.conn(ctx).SelectContext(ctx, &renamed, query, id, artist.Name)
+	r.track(query, start, int64(len(renamed)), err)
+	if err != nil {
+		r.logger.Error("Failed to rename artist songs", zap.Int("id", id), zap.Error(err))
+		return nil, err
+	}
+	return renamed, nil
+}
+
+// DeleteArtist deletes an artist, returning sql.ErrNoRows when it does not exist. Artists with songs
+// cannot be deleted.
+func (r *PostgresRepository) DeleteArtist(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting artist", zap.Int("id", id))
+	if err := r.artists.Delete(ctx, id); err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to delete artist", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// GetArtistSongs retrieves a page of the songs of an artist ordered by ID together with the number of its songs
+func (r *PostgresRepository) GetArtistSongs(ctx context.Context, artistID, page, limit int) ([]models.Song, int, error) {
+	r.logger.Debug("Fetching artist songs", zap.Int("artist_id", artistID), zap.Int("page", page), zap.Int("limit", limit))
+	where, args := "s.artist_id = $1", []any{artistID}
+	songs, err := r.songs.List(ctx, where, args, "s.id", page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.songs.Count(ctx, where, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	return songs, total, nil
+}
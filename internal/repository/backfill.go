@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// backfillFields are the optional song columns that legacy rows store as empty strings instead of NULL
+var backfillFields = []string{"release_date", "text", "link"}
+
+// mergedSongTable is a table whose rows belong to a song and are carried over to the kept song when
+// duplicates are merged
+type mergedSongTable struct {
+	name string
+	// keys identify a row among the rows of a song; a row whose keys the kept song already has is dropped
+	keys []string
+	// columns are the other columns copied with the row
+	columns []string
+	// order picks the row carried over when several removed songs have one with the same keys: the latest
+	// rating or override of a user, the title of the oldest song
+	order string
+}
+
+// mergedSongTables are the tables carried over by a merge, besides the views, tags and revisions that get
+// statements of their own. A table referencing songs that is missing here loses its rows to the cascade
+// of the merge's delete.
+var mergedSongTables = []mergedSongTable{
+	{name: "song_genres", keys: []string{"genre_id"}, columns: []string{"created_at"}, order: "created_at, song_id"},
+	{name: "song_titles", keys: []string{"lang"}, columns: []string{"title", "kind", "created_at", "updated_at"}, order: "song_id"},
+	{name: "song_ratings", keys: []string{"user_id"}, columns: []string{"rating", "created_at", "updated_at"}, order: "updated_at DESC, song_id"},
+	{name: "song_favorites", keys: []string{"user_id"}, columns: []string{"created_at"}, order: "created_at, song_id"},
+	{name: "song_overrides", keys: []string{"user_id"}, columns: []string{"text", "created_at", "updated_at"}, order: "updated_at DESC, song_id"},
+	{name: "classification_suggestions", keys: []string{"kind", "value"},
+		columns: []string{"confidence", "source", "status", "created_at", "reviewed_at"}, order: "id"},
+}
+
+// mergeSongRowsQueries returns the statements carrying the rows of the removed songs over to the kept song,
+// in SQL shared by the Postgres, SQLite and MySQL repositories. kept is the expression of the kept song's
+// ID and removed the condition matching the song_id of the removed songs. Revisions are numbered after
+// those of the kept song in the order they were recorded, restored_from following the renumbering.
+func mergeSongRowsQueries(kept, removed string) []string {
+	queries := make([]string, 0, len(mergedSongTables)+1)
+	for _, table := range mergedSongTables {
+		keys := strings.Join(table.keys, ", ")
+		columns := keys + ", " + strings.Join(table.columns, ", ")
+		matches := make([]string, len(table.keys))
+		for i, key := range table.keys {
+			matches[i] = "k." + key + " = d." + key
+		}
+		queries = append(queries, `INSERT INTO `+table.name+` (song_id, `+columns+`)
+		SELECT `+kept+`, `+columns+` FROM (
+			SELECT `+columns+`, ROW_NUMBER() OVER (PARTITION BY `+keys+` ORDER BY `+table.order+`) AS pick
+			FROM `+table.name+` WHERE song_id `+removed+`
+		) d
+		WHERE d.pick = 1 AND NOT EXISTS (SELECT 1 FROM `+table.name+` k WHERE k.song_id = `+kept+` AND `+strings.Join(matches, " AND ")+`)`)
+	}
+	return append(queries, `INSERT INTO song_revisions (song_id, revision, reason, restored_from, data, created_at)
+		WITH numbered AS (
+			SELECT song_id, revision, reason, restored_from, data, created_at,
+				(SELECT COALESCE(MAX(revision), 0) FROM song_revisions WHERE song_id = `+kept+`)
+					+ ROW_NUMBER() OVER (ORDER BY created_at, song_id, revision) AS new_revision
+			FROM song_revisions WHERE song_id `+removed+`
+		)
+		SELECT `+kept+`, n.new_revision, n.reason, o.new_revision, n.data, n.created_at
+		FROM numbered n LEFT JOIN numbered o ON o.song_id = n.song_id AND o.revision = n.restored_from`)
+}
+
+// BackfillLegacyRows normalizes rows written under the legacy data conventions in a single transaction:
+// missing timestamps are filled, blank optional fields become NULL and duplicate songs are merged into
+// the oldest one. With dryRun the same work is done and reported, then rolled back.
+func (r *PostgresRepository) BackfillLegacyRows(ctx context.Context, dryRun bool) (models.BackfillReport, error) {
+	r.logger.Debug("Backfilling legacy rows", zap.Bool("dry_run", dryRun))
+	report := models.BackfillReport{DryRun: dryRun, EmptyFields: make(map[string]int)}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return report, err
+	}
+	defer tx.Rollback()
+
+	// The update_timestamp trigger would stamp every normalized row as just updated
+	if _, err := tx.ExecContext(ctx, "ALTER TABLE songs DISABLE TRIGGER update_timestamp"); err != nil {
+		r.logger.Error("Failed to disable the timestamp trigger", zap.Error(err))
+		return report, err
+	}
+
+	if report.MissingCreatedAt, err = r.backfillExec(ctx, tx, "UPDATE songs SET created_at = COALESCE(updated_at, NOW()) WHERE created_at IS NULL"); err != nil {
+		return report, err
+	}
+	if report.MissingUpdatedAt, err = r.backfillExec(ctx, tx, "UPDATE songs SET updated_at = created_at WHERE updated_at IS NULL"); err != nil {
+		return report, err
+	}
+	for _, field := range backfillFields {
+		query := fmt.Sprintf("UPDATE songs SET %[1]s = NULL WHERE BTRIM(%[1]s) = ''", field)
+		if report.EmptyFields[field], err = r.backfillExec(ctx, tx, query); err != nil {
+			return report, err
+		}
+	}
+	if report.Duplicates, err = r.mergeDuplicateSongs(ctx, tx); err != nil {
+		return report, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "ALTER TABLE songs ENABLE TRIGGER update_timestamp"); err != nil {
+		r.logger.Error("Failed to enable the timestamp trigger", zap.Error(err))
+		return report, err
+	}
+	if dryRun {
+		r.logger.Info("Legacy rows backfill dry run finished", zap.Int("duplicates", len(report.Duplicates)))
+		return report, nil
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit legacy rows backfill", zap.Error(err))
+		return report, err
+	}
+	r.logger.Info("Legacy rows backfilled", zap.Int("duplicates", len(report.Duplicates)))
+	return report, nil
+}
+
+// backfillExec runs a normalizing statement and returns the number of rows it changed
+func (r *PostgresRepository) backfillExec(ctx context.Context, tx *sqlx.Tx, query string) (int, error) {
+	start := time.Now()
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to backfill legacy rows", zap.String("query", query), zap.Error(err))
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	return int(rows), err
+}
+
+// mergeDuplicateSongs merges every set of songs sharing a group and title into its oldest song,
+// which keeps its own values and takes the first known value of the others for its NULL fields.
+// Songs on legal hold are neither merged into nor merged away.
+func (r *PostgresRepository) mergeDuplicateSongs(ctx context.Context, tx *sqlx.Tx) ([]models.DuplicateSongs, error) {
+	var rows []struct {
+		models.DuplicateSongs
+		RemovedIDs pq.Int64Array `db:"removed_ids"`
+	}
+	query := `
+		SELECT (ARRAY_AGG(group_name ORDER BY id))[1] AS group_name, (ARRAY_AGG(song_name ORDER BY id))[1] AS song_name,
+			MIN(id) AS kept_id,
+			(ARRAY_AGG(id ORDER BY id))[2:] AS removed_ids
+		FROM songs
+		WHERE NOT legal_hold
+		GROUP BY LOWER(BTRIM(group_name)), LOWER(BTRIM(song_name))
+		HAVING COUNT(*) > 1
+		ORDER BY kept_id`
+	start := time.Now()
+	err := tx.SelectContext(ctx, &rows, query)
+	r.track(query, start, int64(len(rows)), err)
+	if err != nil {
+		r.logger.Error("Failed to find duplicate songs", zap.Error(err))
+		return nil, err
+	}
+
+	duplicates := make([]models.DuplicateSongs, 0, len(rows))
+	for _, row := range rows {
+		duplicate := row.DuplicateSongs
+		for _, id := range row.RemovedIDs {
+			duplicate.RemovedIDs = append(duplicate.RemovedIDs, int(id))
+		}
+		if err := r.mergeSongs(ctx, tx, duplicate.KeptID, duplicate.RemovedIDs); err != nil {
+			r.logger.Error("Failed to merge duplicate songs", zap.Int("kept_id", duplicate.KeptID), zap.Error(err))
+			return nil, err
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+	return duplicates, nil
+}
+
+// mergeSongs fills the NULL fields of the kept song from the removed ones, adds up their views,
+// carries over their tags and the rows of mergedSongTables and their revisions, and deletes them
+func (r *PostgresRepository) mergeSongs(ctx context.Context, tx *sqlx.Tx, keptID int, removedIDs []int) error {
+	removed := pq.Array(removedIDs)
+	queries := []string{
+		`UPDATE songs s SET
+			release_date = COALESCE(s.release_date, (SELECT d.release_date FROM songs d WHERE d.id = ANY($2) AND d.release_date IS NOT NULL ORDER BY d.id LIMIT 1)),
+			text = COALESCE(s.text, (SELECT d.text FROM songs d WHERE d.id = ANY($2) AND d.text IS NOT NULL ORDER BY d.id LIMIT 1)),
+			link = COALESCE(s.link, (SELECT d.link FROM songs d WHERE d.id = ANY($2) AND d.link IS NOT NULL ORDER BY d.id LIMIT 1))
+		WHERE s.id = $1`,
+		`INSERT INTO song_views (song_id, views)
+		SELECT $1::int, SUM(views) FROM song_views WHERE song_id = ANY($2) HAVING COUNT(*) > 0
+		ON CONFLICT (song_id) DO UPDATE SET views = song_views.views + EXCLUDED.views`,
+		`INSERT INTO song_view_days (song_id, day, views)
+		SELECT $1::int, day, SUM(views) FROM song_view_days WHERE song_id = ANY($2) GROUP BY day
+		ON CONFLICT (song_id, day) DO UPDATE SET views = song_view_days.views + EXCLUDED.views`,
+		`INSERT INTO song_tags (song_id, tag_id)
+		SELECT DISTINCT $1::int, tag_id FROM song_tags WHERE song_id = ANY($2)
+		ON CONFLICT DO NOTHING`,
+	}
+	queries = append(queries, mergeSongRowsQueries("$1::int", "= ANY($2)")...)
+	for _, query := range append(queries, `DELETE FROM songs WHERE id = ANY($2)`) {
+		start := time.Now()
+		result, err := tx.ExecContext(ctx, query, keptID, removed)
+		var rows int64
+		if err == nil {
+			rows, _ = result.RowsAffected()
+		}
+		r.track(query, start, rows, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// IncrementProviderUsage counts a call to the provider on the day and returns the day's total
+func (r *PostgresRepository) IncrementProviderUsage(ctx context.Context, provider string, day time.Time) (int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO provider_usage (provider, day, calls) VALUES ($1, $2, 1)
+		ON CONFLICT (provider, day) DO UPDATE SET calls = provider_usage.calls + 1 RETURNING calls`
+	var calls int
+	start := time.Now()
+	err := r.db.GetContext(ctx, &calls, query, provider, day)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to increment provider usage", zap.String("provider", provider), zap.Error(err))
+		return 0, err
+	}
+	return calls, nil
+}
+
+// GetProviderUsage returns the number of calls counted for the provider on the day
+func (r *PostgresRepository) GetProviderUsage(ctx context.Context, provider string, day time.Time) (int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT calls FROM provider_usage WHERE provider = $1 AND day = $2"
+	var calls int
+	start := time.Now()
+	err := r.db.GetContext(ctx, &calls, query, provider, day)
+	r.track(query, start, 1, err)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to fetch provider usage", zap.String("provider", provider), zap.Error(err))
+		return 0, err
+	}
+	return calls, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// AddSongs inserts several songs in a single transaction and returns an ID or an error for each of them.
+// Every insert runs inside its own savepoint, so a failing song does not abort the others;
+// the returned error is only set when the transaction itself fails, in which case nothing is stored.
+func (r *PostgresRepository) AddSongs(ctx context.Context, songs []models.SongInput) ([]int, []error, error) {
+	r.logger.Debug("Adding songs in bulk", zap.Int("count", len(songs)))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO songs (group_name, song_name, release_date, text, link, enriched_at) 
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	ids := make([]int, len(songs))
+	errs := make([]error, len(songs))
+	inserted := 0
+	start := time.Now()
+	for i, song := range songs {
+		savepoint := fmt.Sprintf("bulk_song_%d", i)
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+			r.logger.Error("Failed to create savepoint", zap.Error(err))
+			return nil, nil, err
+		}
+		err := tx.QueryRowContext(ctx, query, song.Group, song.Song, nullIfEmpty(song.ReleaseDate), nullIfEmpty(song.Text), nullIfEmpty(song.Link), song.EnrichedAt).Scan(&ids[i])
+		if err != nil {
+			r.logger.Warn("Failed to add song in bulk", zap.Int("index", i), zap.Error(err))
+			errs[i] = err
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); err != nil {
+				r.logger.Error("Failed to roll back savepoint", zap.Error(err))
+				return nil, nil, err
+			}
+			continue
+		}
+		inserted++
+	}
+	if err := tx.Commit(); err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to commit bulk insert", zap.Error(err))
+		return nil, nil, err
+	}
+	r.track(query, start, int64(inserted), nil)
+	r.logger.Info("Songs added to database in bulk", zap.Int("inserted", inserted), zap.Int("failed", len(songs)-inserted))
+	return ids, errs, nil
+}
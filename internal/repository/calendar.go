@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// GetSongsWithReleaseDate retrieves all songs matching the filters that have a release date set
+func (r *PostgresRepository) GetSongsWithReleaseDate(ctx context.Context, group, song string) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs with release date", zap.String("group", group), zap.String("song", song))
+	songs, err := r.songs.Find(ctx, "s.group_name ILIKE $1 AND s.song_name ILIKE $2 AND s.release_date IS NOT NULL",
+		[]any{"%" + group + "%", "%" + song + "%"}, "s.id")
+	if err != nil {
+		r.logger.Error("Failed to fetch songs with release date", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs with release date fetched from database", zap.Int("count", len(songs)))
+	return songs, nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// AddAPICapture stores a captured external API exchange and drops all but the newest keep captures
+func (r *PostgresRepository) AddAPICapture(ctx context.Context, capture models.APICapture, keep int) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	// The delete runs against the table as it was before the insert, so the new capture itself counts
+	// toward keep through its ID
+	query := `WITH inserted AS (
+			INSERT INTO api_captures (provider, method, url, request_headers, request_body, status,
+				response_headers, response_body, duration_ms, error)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id
+		)
+		DELETE FROM api_captures WHERE id <= (SELECT id FROM inserted) - $11`
+	var responseHeaders any
+	if capture.ResponseHeaders != nil {
+		responseHeaders = string(*capture.ResponseHeaders)
+	}
+	start := time.Now()
+	result, err := r.db.ExecContext(ctx, query, capture.Provider, capture.Method, capture.URL, string(capture.RequestHeaders),
+		capture.RequestBody, capture.Status, responseHeaders, capture.ResponseBody, capture.DurationMs, capture.Error, keep)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to store API capture", zap.String("provider", capture.Provider), zap.Error(err))
+		return err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	return err
+}
+
+// GetAPICaptures returns the newest captured exchanges, of every provider when provider is empty
+func (r *PostgresRepository) GetAPICaptures(ctx context.Context, provider string, limit int) ([]models.APICapture, error) {
+	r.logger.Debug("Fetching API captures", zap.String("provider", provider), zap.Int("limit", limit))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT * FROM api_captures WHERE ($1 = '' OR provider = $1) ORDER BY id DESC LIMIT $2"
+	captures := []models.APICapture{}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &captures, query, provider, limit)
+	r.track(query, start, int64(len(captures)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch API captures", zap.Error(err))
+		return nil, err
+	}
+	return captures, nil
+}
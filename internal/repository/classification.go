@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// selectSuggestions selects classification suggestions together with the title of their song
+const selectSuggestions = `SELECT c.id, c.song_id, s.group_name, s.song_name, c.kind, c.value, c.confidence, c.source,
+	c.status, c.created_at, c.reviewed_at FROM classification_suggestions c JOIN songs s ON s.id = c.song_id`
+
+// AddClassificationSuggestions queues suggestions for review. A value already suggested for the song
+// is left as is, so rejected suggestions are not proposed again.
+func (r *PostgresRepository) AddClassificationSuggestions(ctx context.Context, songID int, source string, suggestions []models.ClassificationSuggestion) (int, error) {
+	r.logger.Debug("Adding classification suggestions", zap.Int("song_id", songID), zap.Int("count", len(suggestions)))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO classification_suggestions (song_id, kind, value, confidence, source)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT (song_id, kind, value) DO NOTHING`
+	var added int64
+	for _, suggestion := range suggestions {
+		start := time.Now()
+		result, err := r.db.ExecContext(ctx, query, songID, suggestion.Kind, suggestion.Value, suggestion.Confidence, source)
+		r.track(query, start, 1, err)
+		if err != nil {
+			r.logger.Error("Failed to add classification suggestion", zap.Int("song_id", songID), zap.Error(err))
+			return int(added), err
+		}
+		rows, _ := result.RowsAffected()
+		added += rows
+	}
+	return int(added), nil
+}
+
+// GetClassificationSuggestions retrieves a page of suggestions with the status, oldest first
+func (r *PostgresRepository) GetClassificationSuggestions(ctx context.Context, status string, page, limit int) ([]models.ClassificationSuggestion, error) {
+	r.logger.Debug("Fetching classification suggestions", zap.String("status", status), zap.Int("page", page), zap.Int("limit", limit))
+	suggestions, err := r.suggestions.List(ctx, "c.status = $1", []any{status}, "c.created_at, c.id", page, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch classification suggestions", zap.Error(err))
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// GetClassificationSuggestion retrieves a suggestion, returning sql.ErrNoRows when it does not exist
+func (r *PostgresRepository) GetClassificationSuggestion(ctx context.Context, id int) (models.ClassificationSuggestion, error) {
+	return r.suggestions.Get(ctx, id)
+}
+
+// ReviewClassificationSuggestion accepts or rejects a pending suggestion,
+// returning sql.ErrNoRows when there is no pending suggestion with the ID
+func (r *PostgresRepository) ReviewClassificationSuggestion(ctx context.Context, id int, status string) error {
+	r.logger.Debug("Reviewing classification suggestion", zap.Int("id", id), zap.String("status", status))
+	return r.suggestions.exec(ctx, `UPDATE classification_suggestions SET status = $2, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'`, id, status)
+}
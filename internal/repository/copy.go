@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// copyColumns are the columns of the songs written in bulk, their IDs being allocated beforehand
+var copyColumns = []string{"id", "group_name", "song_name", "release_date", "text", "link", "enriched_at"}
+
+// insertBatchRows is the number of songs per INSERT statement when COPY is not available, keeping the
+// statement well below the 65535 parameters Postgres accepts
+const insertBatchRows = 1000
+
+// CopySongs inserts songs in bulk and returns their IDs in the order of songs. The songs are streamed with
+// COPY FROM, an order of magnitude faster than inserting them one by one, when the database is reached
+// through lib/pq, and inserted by batches of multi-row INSERTs otherwise. Either every song is inserted or
+// none is.
+func (r *PostgresRepository) CopySongs(ctx context.Context, songs []models.SongInput) ([]int, error) {
+	r.logger.Debug("Copying songs in bulk", zap.Int("count", len(songs)))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids, err := r.copySongs(ctx, tx.Tx, songs)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit bulk copy", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs copied to database in bulk", zap.Int("count", len(songs)))
+	return ids, nil
+}
+
+// copySongs writes the songs in tx, allocating their IDs from the songs sequence first, since neither COPY
+// nor a multi-row INSERT reliably returns them in the order of the rows
+func (r *PostgresRepository) copySongs(ctx context.Context, tx *sqlx.Tx, songs []models.SongInput) ([]int, error) {
+	if len(songs) == 0 {
+		return []int{}, nil
+	}
+	query := "SELECT nextval(pg_get_serial_sequence('songs', 'id')) AS id FROM generate_series(1, $1) ORDER BY id"
+	ids := make([]int, 0, len(songs))
+	start := time.Now()
+	err := tx.SelectContext(ctx, &ids, query, len(songs))
+	r.track(query, start, int64(len(ids)), err)
+	if err != nil {
+		r.logger.Error("Failed to allocate song IDs", zap.Error(err))
+		return nil, err
+	}
+
+	if r.db.DriverName() == "postgres" {
+		err = r.copyIn(ctx, tx, ids, songs)
+	} else {
+		err = r.insertBatches(ctx, tx, ids, songs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// copyIn streams the songs with COPY FROM through lib/pq
+func (r *PostgresRepository) copyIn(ctx context.Context, tx *sqlx.Tx, ids []int, songs []models.SongInput) error {
+	query := pq.CopyIn("songs", copyColumns...)
+	start := time.Now()
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to start copy", zap.Error(err))
+		return err
+	}
+	defer stmt.Close()
+	for i, song := range songs {
+		if _, err := stmt.ExecContext(ctx, ids[i], song.Group, song.Song, nullIfEmpty(song.ReleaseDate), nullIfEmpty(song.Text), nullIfEmpty(song.Link), song.EnrichedAt); err != nil {
+			r.track(query, start, int64(i), err)
+			r.logger.Error("Failed to copy song", zap.Int("index", i), zap.Error(err))
+			return err
+		}
+	}
+	// The rows are buffered until the statement is executed without arguments
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to copy songs", zap.Error(err))
+		return err
+	}
+	r.track(query, start, int64(len(songs)), nil)
+	return nil
+}
+
+// insertBatches inserts the songs by multi-row INSERTs of up to insertBatchRows songs
+func (r *PostgresRepository) insertBatches(ctx context.Context, tx *sqlx.Tx, ids []int, songs []models.SongInput) error {
+	for offset := 0; offset < len(songs); offset += insertBatchRows {
+		end := min(offset+insertBatchRows, len(songs))
+		query := insertSongsQuery(end - offset)
+		args := make([]any, 0, (end-offset)*len(copyColumns))
+		for i := offset; i < end; i++ {
+			song := songs[i]
+			args = append(args, ids[i], song.Group, song.Song, nullIfEmpty(song.ReleaseDate), nullIfEmpty(song.Text), nullIfEmpty(song.Link), song.EnrichedAt)
+		}
+		start := time.Now()
+		_, err := tx.ExecContext(ctx, query, args...)
+		r.track(query, start, int64(end-offset), err)
+		if err != nil {
+			r.logger.Error("Failed to insert song batch", zap.Int("offset", offset), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// insertSongsQuery returns the INSERT of rows songs with the copy columns
+func insertSongsQuery(rows int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO songs (%s) VALUES ", strings.Join(copyColumns, ", "))
+	for row := 0; row < rows; row++ {
+		if row > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for column := range copyColumns {
+			if column > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "$%d", row*len(copyColumns)+column+1)
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
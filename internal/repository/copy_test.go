@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertSongsQuery(t *testing.T) {
+	assert.Equal(t, "INSERT INTO songs (id, group_name, song_name, release_date, text, link, enriched_at) VALUES "+
+		"($1, $2, $3, $4, $5, $6, $7), ($8, $9, $10, $11, $12, $13, $14)", insertSongsQuery(2))
+
+	query := insertSongsQuery(insertBatchRows)
+	assert.True(t, strings.HasSuffix(query, "$7000)"))
+	assert.Less(t, insertBatchRows*len(copyColumns), 65535, "a batch stays within the parameter limit")
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+)
+
+// Table provides the CRUD plumbing shared by every entity repository:
+// listing with filters and pagination, fetching by ID, inserting, updating and deleting.
+// Entity repositories embed or hold a Table and add their custom queries next to it.
+type Table[T any] struct {
+	db       *sqlx.DB
+	logger   *zap.Logger
+	queryLog *QueryLog
+	name     string
+	selectQ  string
+	idColumn string
+	// timeout bounds each statement, zero leaves statements bounded only by the caller's context
+	timeout time.Duration
+	// dialect adapts the statements, written for PostgreSQL, to the database the table is stored in; nil
+	// runs them as they are
+	dialect func(queryer) queryer
+	// lastInsertID reads the IDs of inserted rows from the statement result, for databases without
+	// INSERT ... RETURNING
+	lastInsertID bool
+}
+
+// NewTable creates a Table for the named database table.
+// selectQuery is the SELECT ... FROM part used for reads; an empty value selects every column of the table.
+// idColumn is the (possibly qualified) primary key column as it appears in selectQuery.
+func NewTable[T any](db *sqlx.DB, logger *zap.Logger, queryLog *QueryLog, name, selectQuery, idColumn string) *Table[T] {
+	if selectQuery == "" {
+		selectQuery = "SELECT * FROM " + name
+	}
+	return &Table[T]{
+		db:       db,
+		logger:   logger,
+		queryLog: queryLog,
+		name:     name,
+		selectQ:  selectQuery,
+		idColumn: idColumn,
+	}
+}
+
+// track records a finished query in the query log
+func (t *Table[T]) track(query string, start time.Time, rows int64, err error) {
+	duration := time.Since(start)
+	t.queryLog.Record(query, duration, rows, err)
+	metrics.ObserveQuery(query, duration, err)
+}
+
+// statementContext bounds a statement by the table's statement timeout
+func (t *Table[T]) statementContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withStatementTimeout(ctx, t.timeout)
+}
+
+// withStatementTimeout derives the context a single statement runs with: ctx bounded by the timeout
+// when one is set. Transactions are bounded by their caller's context only, so long imports are not cut off.
+func withStatementTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Get retrieves a single row by its ID, returning sql.ErrNoRows when it does not exist
+func (t *Table[T]) Get(ctx context.Context, id int) (T, error) {
+	ctx, cancel := t.statementContext(ctx)
+	defer cancel()
+	var item T
+	query := t.selectQ + " WHERE " + t.idColumn + " = $1"
+	start := time.Now()
+	err := t.conn(ctx).GetContext(ctx, &item, query, id)
+	t.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		t.logger.Error("Failed to fetch row", zap.String("table", t.name), zap.Int("id", id), zap.Error(err))
+	}
+	return item, err
+}
+
+// Find retrieves every row matching the where clause (which may be empty) in the given order
+func (t *Table[T]) Find(ctx context.Context, where string, args []any, orderBy string) ([]T, error) {
+	ctx, cancel := t.statementContext(ctx)
+	defer cancel()
+	query := t.selectQ
+	if where != "" {
+		query += " WHERE " + where
+	}
+	if orderBy == "" {
+		orderBy = t.idColumn
+	}
+	query += " ORDER BY " + orderBy
+
+	items := []T{}
+	start := time.Now()
+	err := t.conn(ctx).SelectContext(ctx, &items, query, args...)
+	t.track(query, start, int64(len(items)), err)
+	if err != nil {
+		t.logger.Error("Failed to find rows", zap.String("table", t.name), zap.Error(err))
+		return nil, err
+	}
+	return items, nil
+}
+
+// List retrieves a page of rows matching the where clause (which may be empty) in the given order.
+// The where clause uses $1..$n placeholders for args.
+func (t *Table[T]) List(ctx context.Context, where string, args []any, orderBy string, page, limit int) ([]T, error) {
+	ctx, cancel := t.statementContext(ctx)
+	defer cancel()
+	query := t.selectQ
+	if where != "" {
+		query += " WHERE " + where
+	}
+	if orderBy == "" {
+		orderBy = t.idColumn
+	}
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", orderBy, len(args)+1, len(args)+2)
+	args = append(args, limit, (page-1)*limit)
+
+	items := []T{}
+	start := time.Now()
+	err := t.conn(ctx).SelectContext(ctx, &items, query, args...)
+	t.track(query, start, int64(len(items)), err)
+	if err != nil {
+		t.logger.Error("Failed to list rows", zap.String("table", t.name), zap.Error(err))
+		return nil, err
+	}
+	return items, nil
+}
+
+// Count returns the number of rows matching the where clause (which may be empty)
+func (t *Table[T]) Count(ctx context.Context, where string, args []any) (int, error) {
+	ctx, cancel := t.statementContext(ctx)
+	defer cancel()
+	query := t.selectQ
+	if where != "" {
+		query += " WHERE " + where
+	}
+	query = "SELECT COUNT(*) FROM (" + query + ") AS counted"
+
+	var count int
+	start := time.Now()
+	err := t.conn(ctx).GetContext(ctx, &count, query, args...)
+	t.track(query, start, 1, err)
+	if err != nil {
+		t.logger.Error("Failed to count rows", zap.String("table", t.name), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// Insert adds a row with the given column values and returns its generated ID
+func (t *Table[T]) Insert(ctx context.Context, values map[string]any) (int, error) {
+	ctx, cancel := t.statementContext(ctx)
+	defer cancel()
+	columns, args := sortedColumns(values)
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", t.name, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+
+	var id int
+	start := time.Now()
+	var err error
+	if t.lastInsertID {
+		id, err = insertID(t.conn(ctx).ExecContext(ctx, query, args...))
+	} else {
+		query += " RETURNING id"
+		err = t.conn(ctx).QueryRowContext(ctx, query, args...).Scan(&id)
+	}
+	t.track(query, start, 1, err)
+	if err != nil {
+		t.logger.Error("Failed to insert row", zap.String("table", t.name), zap.Error(err))
+		return 0, err
+	}
+	return id, nil
+}
+
+// insertID returns the ID an INSERT statement generated, for databases without INSERT ... RETURNING
+func insertID(result sql.Result, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// Update sets the given column values on the row with the ID, returning sql.ErrNoRows when it does not exist
+func (t *Table[T]) Update(ctx context.Context, id int, values map[string]any) error {
+	columns, args := sortedColumns(values)
+	assignments := make([]string, len(columns))
+	for i, column := range columns {
+		assignments[i] = fmt.Sprintf("%s = $%d", column, i+2)
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $1", t.name, strings.Join(assignments, ", "))
+	return t.exec(ctx, query, append([]any{id}, args...)...)
+}
+
+// Delete removes the row with the ID, returning sql.ErrNoRows when it does not exist
+func (t *Table[T]) Delete(ctx context.Context, id int) error {
+	return t.exec(ctx, "DELETE FROM "+t.name+" WHERE id = $1", id)
+}
+
+// exec runs a statement expected to affect at least one row
+func (t *Table[T]) exec(ctx context.Context, query string, args ...any) error {
+	ctx, cancel := t.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := t.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		t.track(query, start, 0, err)
+		t.logger.Error("Failed to execute statement", zap.String("table", t.name), zap.Error(err))
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	t.track(query, start, rowsAffected, err)
+	if err != nil {
+		t.logger.Error("Failed to check rows affected", zap.String("table", t.name), zap.Error(err))
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// sortedColumns splits a column/value map into column names and values in a stable order
+func sortedColumns(values map[string]any) ([]string, []any) {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	args := make([]any, len(columns))
+	for i, column := range columns {
+		args[i] = values[column]
+	}
+	return columns, args
+}
+
+// jsonList encodes values as a JSON array, the counterpart of pq.Array for the databases without arrays,
+// whose statements expand it into rows: "id IN (SELECT value FROM json_each($1))" in SQLite
+func jsonList[T any](values []T) string {
+	if values == nil {
+		values = []T{}
+	}
+	data, _ := json.Marshal(values)
+	return string(data)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// GetSongsCreatedBetween retrieves songs added within the [from, to) interval
+func (r *PostgresRepository) GetSongsCreatedBetween(ctx context.Context, from, to time.Time) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs created in period", zap.Time("from", from), zap.Time("to", to))
+	songs, err := r.songs.Find(ctx, "s.created_at >= $1 AND s.created_at < $2", []any{from, to}, "s.created_at")
+	if err != nil {
+		r.logger.Error("Failed to fetch songs created in period", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetSongsUpdatedBetween retrieves songs created before the interval and edited within [from, to)
+func (r *PostgresRepository) GetSongsUpdatedBetween(ctx context.Context, from, to time.Time) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs updated in period", zap.Time("from", from), zap.Time("to", to))
+	songs, err := r.songs.Find(ctx, "s.updated_at >= $1 AND s.updated_at < $2 AND s.created_at < $1", []any{from, to}, "s.updated_at")
+	if err != nil {
+		r.logger.Error("Failed to fetch songs updated in period", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// songContentHash is the SQL counterpart of SongContentHash
+const songContentHash = `md5(s.group_name || E'\n' || s.song_name || E'\n' || COALESCE(s.text, ''))`
+
+// SongContentHash identifies the content an embedding was computed from, so edited songs are re-embedded
+func SongContentHash(song models.Song) string {
+	sum := md5.Sum([]byte(song.Group + "\n" + song.Song + "\n" + models.StringValue(song.Text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// HasSongEmbeddings reports whether the song_embeddings table exists; it is only created where pgvector is installed
+func (r *PostgresRepository) HasSongEmbeddings(ctx context.Context) (bool, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	var exists bool
+	query := "SELECT to_regclass('song_embeddings') IS NOT NULL"
+	start := time.Now()
+	err := r.db.GetContext(ctx, &exists, query)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to check for song embeddings", zap.Error(err))
+	}
+	return exists, err
+}
+
+// GetSongsNeedingEmbedding retrieves up to limit songs without an embedding from the model,
+// or whose content changed since it was computed
+func (r *PostgresRepository) GetSongsNeedingEmbedding(ctx context.Context, model string, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs needing embeddings", zap.String("model", model), zap.Int("limit", limit))
+	songs, err := r.songs.List(ctx, `NOT EXISTS (SELECT 1 FROM song_embeddings e
+		WHERE e.song_id = s.id AND e.model = $1 AND e.content_hash = `+songContentHash+`)`, []any{model}, "", 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs needing embeddings", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// SaveSongEmbedding stores the embedding of a song, replacing any previous one
+func (r *PostgresRepository) SaveSongEmbedding(ctx context.Context, songID int, model, contentHash string, embedding []float32) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_embeddings (song_id, model, content_hash, embedding) VALUES ($1, $2, $3, $4::vector)
+		ON CONFLICT (song_id) DO UPDATE SET model = EXCLUDED.model, content_hash = EXCLUDED.content_hash,
+		embedding = EXCLUDED.embedding, updated_at = NOW()`
+	start := time.Now()
+	_, err := r.db.ExecContext(ctx, query, songID, model, contentHash, vectorLiteral(embedding))
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to save song embedding", zap.Int("song_id", songID), zap.Error(err))
+	}
+	return err
+}
+
+// SearchSongsSemantic ranks songs embedded with the model by cosine similarity to the query embedding.
+// When keywordWeight is positive, the similarity is blended with the full-text rank of the keywords:
+// score = (1 - keywordWeight) * similarity + keywordWeight * rank, both in [0, 1].
+func (r *PostgresRepository) SearchSongsSemantic(ctx context.Context, model string, embedding []float32, keywords string, keywordWeight float64, limit int) ([]models.SearchResult, error) {
+	r.logger.Debug("Searching songs semantically", zap.String("model", model), zap.Float64("keyword_weight", keywordWeight))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT ` + songColumns + `, COALESCE(v.views, 0) AS views,
+		(1 - $3::float8) * (1 - (e.embedding <=> $2::vector)) +
+		$3::float8 * ts_rank(s.search_vector, websearch_to_tsquery('simple', $4), 32) AS score
+		FROM songs s
+		JOIN song_embeddings e ON e.song_id = s.id AND e.model = $1
+		LEFT JOIN song_views v ON v.song_id = s.id
+		ORDER BY score DESC, s.id LIMIT $5`
+	results := []models.SearchResult{}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &results, query, model, vectorLiteral(embedding), keywordWeight, keywords, limit)
+	r.track(query, start, int64(len(results)), err)
+	if err != nil {
+		r.logger.Error("Failed to search songs semantically", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Semantic search finished", zap.Int("count", len(results)))
+	return results, nil
+}
+
+// vectorLiteral formats an embedding in the pgvector text representation
+func vectorLiteral(embedding []float32) string {
+	parts := make([]string, len(embedding))
+	for i, value := range embedding {
+		parts[i] = strconv.FormatFloat(float64(value), 'f', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
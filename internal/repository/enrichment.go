@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// AddPendingSong inserts a song whose details are still to be fetched by the enrichment workers
+func (r *PostgresRepository) AddPendingSong(ctx context.Context, group, song string) (int, error) {
+	r.logger.Debug("Adding pending song to database", zap.String("group", group), zap.String("song", song))
+	id, err := r.songs.Insert(ctx, map[string]any{
+		"group_name":        group,
+		"song_name":         song,
+		"enrichment_status": models.EnrichmentPending,
+	})
+	if err != nil {
+		r.logger.Error("Failed to add pending song", zap.Error(err))
+		return 0, err
+	}
+	r.logger.Info("Pending song added to database", zap.Int("id", id))
+	return id, nil
+}
+
+// CompleteEnrichment stores the fetched details of a pending song and marks it complete. Fields edited
+// while the song was pending keep their value.
+func (r *PostgresRepository) CompleteEnrichment(ctx context.Context, id int, releaseDate, text, link string, enrichedAt *time.Time) error {
+	r.logger.Debug("Completing song enrichment", zap.Int("id", id))
+	query := `UPDATE songs SET release_date = COALESCE(release_date, NULLIF($2, '')),
+		text = COALESCE(text, NULLIF($3, '')), link = COALESCE(link, NULLIF($4, '')), enriched_at = $5,
+		enrichment_status = $6, enrichment_error = NULL WHERE id = $1`
+	return r.updateEnrichment(ctx, id, query, id, releaseDate, text, link, enrichedAt, models.EnrichmentComplete)
+}
+
+// FailEnrichment marks a pending song failed with the reason
+func (r *PostgresRepository) FailEnrichment(ctx context.Context, id int, reason string) error {
+	r.logger.Debug("Failing song enrichment", zap.Int("id", id), zap.String("reason", reason))
+	query := `UPDATE songs SET enrichment_status = $2, enrichment_error = $3 WHERE id = $1`
+	return r.updateEnrichment(ctx, id, query, id, models.EnrichmentFailed, reason)
+}
+
+// updateEnrichment runs an enrichment status update, returning sql.ErrNoRows when the song was deleted
+func (r *PostgresRepository) updateEnrichment(ctx context.Context, id int, query string, args ...any) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to update song enrichment", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetEnrichmentStatus retrieves the enrichment status of a song
+func (r *PostgresRepository) GetEnrichmentStatus(ctx context.Context, id int) (models.EnrichmentStatus, error) {
+	r.logger.Debug("Fetching enrichment status", zap.Int("id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT id, enrichment_status, enrichment_error, enriched_at, updated_at FROM songs WHERE id = $1`
+	var status models.EnrichmentStatus
+	start := time.Now()
+	err := r.db.GetContext(ctx, &status, query, id)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch enrichment status", zap.Int("id", id), zap.Error(err))
+	}
+	return status, err
+}
+
+// GetPendingEnrichments retrieves up to limit songs waiting for enrichment, oldest first
+func (r *PostgresRepository) GetPendingEnrichments(ctx context.Context, exclude []int, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs pending enrichment", zap.Int("limit", limit))
+	where := "s.enrichment_status = $1"
+	args := []any{models.EnrichmentPending}
+	if len(exclude) > 0 {
+		args = append(args, pq.Array(exclude))
+		where += fmt.Sprintf(" AND s.id <> ALL($%d)", len(args))
+	}
+	songs, err := r.songs.List(ctx, where, args, "s.id", 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs pending enrichment", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// outboxLockID is the key of the advisory lock held by the relay publishing the outbox
+const outboxLockID = 0x6f7574626f78 // "outbox"
+
+// AddSongEvent appends a catalog event to the event log and returns its ID. The event stays in the outbox
+// until the relay marks it published; added within RunInTransaction, it is stored with the change it records.
+func (r *PostgresRepository) AddSongEvent(ctx context.Context, event models.SongEvent) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_events (event_type, song_id, count, occurred_at) VALUES ($1, NULLIF($2, 0), $3, $4) RETURNING id`
+	var id int64
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &id, query, event.Type, event.SongID, event.Count, event.OccurredAt)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to store song event", zap.String("event_type", event.Type), zap.Error(err))
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetSongEventsAfter returns up to limit events of the event log following the event with the given ID, oldest first
+func (r *PostgresRepository) GetSongEventsAfter(ctx context.Context, afterID int64, limit int) ([]models.SongEvent, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT id, event_type, COALESCE(song_id, 0) AS song_id, count, occurred_at FROM song_events
+		WHERE id > $1 ORDER BY id LIMIT $2`
+	events := []models.SongEvent{}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &events, query, afterID, limit)
+	r.track(query, start, int64(len(events)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch song events", zap.Int64("after_id", afterID), zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
+
+// ClaimSongEvents returns up to limit events of the outbox, the events not yet published, oldest first. It
+// runs within RunInTransaction and takes a transaction-wide lock, so a single relay publishes at a time and
+// events keep their order; another relay gets no events until the transaction ends.
+func (r *PostgresRepository) ClaimSongEvents(ctx context.Context, limit int) ([]models.SongEvent, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	lockQuery := "SELECT pg_try_advisory_xact_lock($1)"
+	var locked bool
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &locked, lockQuery, outboxLockID)
+	r.track(lockQuery, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to lock the outbox", zap.Error(err))
+		return nil, err
+	}
+	events := []models.SongEvent{}
+	if !locked {
+		return events, nil
+	}
+	query := `SELECT id, event_type, COALESCE(song_id, 0) AS song_id, count, occurred_at FROM song_events
+		WHERE published_at IS NULL ORDER BY id LIMIT $1`
+	start = time.Now()
+	err = r.conn(ctx).SelectContext(ctx, &events, query, limit)
+	r.track(query, start, int64(len(events)), err)
+	if err != nil {
+		r.logger.Error("Failed to claim outbox events", zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkSongEventsPublished takes the events out of the outbox
+func (r *PostgresRepository) MarkSongEventsPublished(ctx context.Context, ids []int64) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "UPDATE song_events SET published_at = NOW() WHERE id = ANY($1)"
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, pq.Array(ids))
+	var rows int64
+	if err == nil {
+		rows, err = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to mark outbox events published", zap.Int("count", len(ids)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetLatestSongEventID returns the ID of the latest event of the event log, zero when it is empty
+func (r *PostgresRepository) GetLatestSongEventID(ctx context.Context) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT COALESCE(MAX(id), 0) FROM song_events"
+	var id int64
+	start := time.Now()
+	err := r.db.GetContext(ctx, &id, query)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to fetch latest song event", zap.Error(err))
+		return 0, err
+	}
+	return id, nil
+}
+
+// DeleteSongEventsBefore drops the published events of the event log that occurred before the given time and
+// returns how many were dropped
+func (r *PostgresRepository) DeleteSongEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "DELETE FROM song_events WHERE occurred_at < $1 AND published_at IS NOT NULL"
+	start := time.Now()
+	result, err := r.db.ExecContext(ctx, query, before)
+	var rows int64
+	if err == nil {
+		rows, err = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to prune song events", zap.Error(err))
+		return 0, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// facetExpressions maps supported facet names to the SQL expression used to group songs
+// release_date is stored as text, so the year is the first four-digit run in it
+var facetExpressions = map[string]string{
+	"year":   `substring(s.release_date from '\d{4}')`,
+	"decade": `(substring(s.release_date from '\d{4}')::int / 10 * 10)::text || 's'`,
+	"group":  "s.group_name",
+	"genre":  "fg.name",
+}
+
+// facetJoins maps the facets grouping songs by a related table to the joins reaching it, in every SQL
+// dialect. A song assigned several genres counts once for each.
+var facetJoins = map[string]string{
+	"genre": "JOIN song_genres fsg ON fsg.song_id = s.id JOIN genres fg ON fg.id = fsg.genre_id",
+}
+
+// IsFacetSupported reports whether the repository knows how to compute the given facet
+func IsFacetSupported(facet string) bool {
+	_, ok := facetExpressions[facet]
+	return ok
+}
+
+// GetSongFacets computes value/count buckets for each requested facet using the same filters as GetSongs
+func (r *PostgresRepository) GetSongFacets(ctx context.Context, filter models.SongFilter, facets []string) (map[string][]models.FacetBucket, error) {
+	r.logger.Debug("Fetching song facets from database", zap.Strings("facets", facets))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	result := make(map[string][]models.FacetBucket, len(facets))
+	for _, facet := range facets {
+		expr, ok := facetExpressions[facet]
+		if !ok {
+			return nil, fmt.Errorf("unsupported facet %q", facet)
+		}
+		where, args := songFilterClause(filter)
+		query := fmt.Sprintf(`SELECT %[1]s AS value, COUNT(*) AS count FROM songs s %[3]s
+			WHERE %[2]s AND %[1]s IS NOT NULL 
+			GROUP BY 1 ORDER BY count DESC, value`, expr, where, facetJoins[facet])
+		buckets := []models.FacetBucket{}
+		start := time.Now()
+		err := r.db.SelectContext(ctx, &buckets, query, args...)
+		r.track(query, start, int64(len(buckets)), err)
+		if err != nil {
+			r.logger.Error("Failed to fetch facet", zap.String("facet", facet), zap.Error(err))
+			return nil, err
+		}
+		result[facet] = buckets
+	}
+	r.logger.Info("Song facets fetched from database", zap.Int("count", len(result)))
+	return result, nil
+}
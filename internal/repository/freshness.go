@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// GetStalestSongs retrieves up to limit songs not enriched within staleAfter, never-enriched songs first
+// and then the longest-unrefreshed ones. Songs whose IDs are in exclude and songs on legal hold are skipped.
+func (r *PostgresRepository) GetStalestSongs(ctx context.Context, staleAfter time.Duration, exclude []int, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching stalest songs", zap.Duration("stale_after", staleAfter), zap.Int("limit", limit))
+	where, args := songFilterClause(models.SongFilter{StaleThan: staleAfter})
+	where += " AND NOT s.legal_hold"
+	if len(exclude) > 0 {
+		args = append(args, pq.Array(exclude))
+		where += fmt.Sprintf(" AND s.id <> ALL($%d)", len(args))
+	}
+	songs, err := r.songs.List(ctx, where, args, "s.enriched_at NULLS FIRST, s.id", 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch stalest songs", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetSongsAfter retrieves up to limit songs matching the filter with IDs above afterID, in ID order,
+// so that callers can walk every match while updating the songs they have seen
+func (r *PostgresRepository) GetSongsAfter(ctx context.Context, filter models.SongFilter, afterID, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs after ID", zap.Int("after_id", afterID), zap.Int("limit", limit))
+	where, args := songFilterClause(filter)
+	args = append(args, afterID)
+	where += fmt.Sprintf(" AND s.id > $%d", len(args))
+	songs, err := r.songs.List(ctx, where, args, "s.id", 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs after ID", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// RefreshSongData stores data freshly fetched from the external API and marks the song as enriched now,
+// settling its enrichment status. Empty values leave the stored field unchanged.
+func (r *PostgresRepository) RefreshSongData(ctx context.Context, id int, releaseDate, text, link string) error {
+	r.logger.Debug("Refreshing song data", zap.Int("id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `UPDATE songs SET release_date = COALESCE(NULLIF($2, ''), release_date), 
+		text = COALESCE(NULLIF($3, ''), text), link = COALESCE(NULLIF($4, ''), link), 
+		enriched_at = NOW(), enrichment_status = 'complete', enrichment_error = NULL WHERE id = $1`
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, id, releaseDate, text, link)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to refresh song data", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// Command gen generates the instrumented decorator of a repository interface. It is run by go generate
+// in the repository package:
+//
+//	go run ./gen -type Repository -output instrumented.go
+//
+// Every method taking a context and returning an error is wrapped by the decorator's call, which logs,
+// measures, traces and retries it; any other method is passed through unchanged.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	typeName := flag.String("type", "Repository", "interface to decorate")
+	output := flag.String("output", "instrumented.go", "file to write the decorator to")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	file, iface, err := findInterface(fset, ".", *typeName, *output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	source, err := generate(fset, file, *typeName, iface)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, source, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// findInterface parses the package in dir, skipping tests and the output file, and returns the interface
+// type named typeName together with the file declaring it
+func findInterface(fset *token.FileSet, dir, typeName, output string) (*ast.File, *ast.InterfaceType, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || filepath.Base(path) == filepath.Base(output) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok && typeSpec.Name.Name == typeName {
+					return file, iface, nil
+				}
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("interface %s not found", typeName)
+}
+
+// param is a named parameter or result of a method
+type param struct {
+	name     string
+	typ      string
+	variadic bool
+}
+
+// fields flattens a parameter or result list, naming unnamed entries with the prefix and their position
+func fields(fset *token.FileSet, list *ast.FieldList, prefix string) []param {
+	if list == nil {
+		return nil
+	}
+	var params []param
+	for _, field := range list.List {
+		typ := field.Type
+		variadic := false
+		if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+			typ, variadic = ellipsis.Elt, true
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, fset, typ)
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{nil}
+		}
+		for _, name := range names {
+			p := param{name: fmt.Sprintf("%s%d", prefix, len(params)), typ: buf.String(), variadic: variadic}
+			if name != nil && name.Name != "_" {
+				p.name = name.Name
+			}
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// generate renders the decorator source
+func generate(fset *token.FileSet, file *ast.File, typeName string, iface *ast.InterfaceType) ([]byte, error) {
+	imports := map[string]bool{`"context"`: true}
+	for _, spec := range file.Imports {
+		imports[spec.Path.Value] = true
+	}
+	// Standard library imports first, then the others, as goimports groups them
+	var std, other []string
+	for path := range imports {
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") || strings.HasPrefix(path, `"music-library`) {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	paths := std
+	if len(other) > 0 {
+		paths = append(append(paths, ""), other...)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by go run ./gen -type %s; DO NOT EDIT.\n\n", typeName)
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n\t%s\n)\n\n", file.Name.Name, strings.Join(paths, "\n\t"))
+	fmt.Fprintf(&buf, "var _ %s = (*Instrumented%s)(nil)\n", typeName, typeName)
+
+	for _, method := range iface.Methods.List {
+		if len(method.Names) == 0 {
+			return nil, fmt.Errorf("embedded interfaces are not supported")
+		}
+		name := method.Names[0].Name
+		signature := method.Type.(*ast.FuncType)
+		params := fields(fset, signature.Params, "p")
+		results := fields(fset, signature.Results, "result")
+		writeMethod(&buf, typeName, name, params, results)
+	}
+	return format.Source(buf.Bytes())
+}
+
+// writeMethod renders the decorator method for one interface method
+func writeMethod(buf *bytes.Buffer, typeName, name string, params, results []param) {
+	var declared, args []string
+	for _, p := range params {
+		if p.variadic {
+			declared = append(declared, p.name+" ..."+p.typ)
+			args = append(args, p.name+"...")
+			continue
+		}
+		declared = append(declared, p.name+" "+p.typ)
+		args = append(args, p.name)
+	}
+	instrumented := len(params) > 0 && params[0].typ == "context.Context" &&
+		len(results) > 0 && results[len(results)-1].typ == "error"
+
+	var resultDecl, resultNames []string
+	for _, r := range results {
+		resultDecl = append(resultDecl, r.name+" "+r.typ)
+		resultNames = append(resultNames, r.name)
+	}
+	call := fmt.Sprintf("r.next.%s(%s)", name, strings.Join(args, ", "))
+
+	if !instrumented {
+		fmt.Fprintf(buf, "\n// %s calls the wrapped %s's %s\n", name, typeName, name)
+		fmt.Fprintf(buf, "func (r *Instrumented%s) %s(%s) (%s) {\n", typeName, name, strings.Join(declared, ", "), strings.Join(resultDecl, ", "))
+		if len(results) > 0 {
+			fmt.Fprintf(buf, "\treturn %s\n}\n", call)
+		} else {
+			fmt.Fprintf(buf, "\t%s\n}\n", call)
+		}
+		return
+	}
+
+	ctx := params[0].name
+	errName := results[len(results)-1].name
+	fmt.Fprintf(buf, "\n// %s calls the wrapped %s's %s, instrumented and retried on serialization failures\n", name, typeName, name)
+	fmt.Fprintf(buf, "func (r *Instrumented%s) %s(%s) (%s) {\n", typeName, name, strings.Join(declared, ", "), strings.Join(resultDecl, ", "))
+	fmt.Fprintf(buf, "\t%s = r.call(%s, %q, func(%s context.Context) error {\n", errName, ctx, name, ctx)
+	if len(results) == 1 {
+		fmt.Fprintf(buf, "\t\treturn %s\n", call)
+	} else {
+		fmt.Fprintf(buf, "\t\tvar %s error\n", errName)
+		fmt.Fprintf(buf, "\t\t%s = %s\n", strings.Join(resultNames, ", "), call)
+		fmt.Fprintf(buf, "\t\treturn %s\n", errName)
+	}
+	fmt.Fprintf(buf, "\t})\n\treturn %s\n}\n", strings.Join(resultNames, ", "))
+}
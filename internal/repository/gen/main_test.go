@@ -0,0 +1,21 @@
+package main
+
+import (
+	"go/token"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedDecoratorUpToDate(t *testing.T) {
+	fset := token.NewFileSet()
+	file, iface, err := findInterface(fset, "..", "Repository", "instrumented.go")
+	require.NoError(t, err)
+	generated, err := generate(fset, file, "Repository", iface)
+	require.NoError(t, err)
+	current, err := os.ReadFile("../instrumented.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(generated), string(current), "instrumented.go is stale, run go generate ./internal/repository")
+}
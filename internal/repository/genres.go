@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// selectGenres selects genres
+const selectGenres = "SELECT id, name, parent_id, created_at, updated_at FROM genres"
+
+// genreSubtree selects the IDs of the genre named by its parameter and of all its subgenres; the parameter
+// placeholder is filled in by the caller
+const genreSubtree = `WITH RECURSIVE subtree AS (
+		SELECT id FROM genres WHERE LOWER(name) = LOWER(%s)
+		UNION ALL
+		SELECT g.id FROM genres g JOIN subtree ON g.parent_id = subtree.id
+	) SELECT id FROM subtree`
+
+// nullIfNil stores a missing reference as NULL
+func nullIfNil(value *int) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*value), Valid: true}
+}
+
+// CreateGenre adds a genre and returns its ID, or sql.ErrNoRows when the name is taken, regardless of case
+func (r *PostgresRepository) CreateGenre(ctx context.Context, genre models.GenreInput) (int, error) {
+	r.logger.Debug("Creating genre", zap.String("name", genre.Name))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO genres (name, parent_id) VALUES ($1, $2)
+		ON CONFLICT ((LOWER(name))) DO NOTHING RETURNING id`
+	var id int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &id, query, genre.Name, nullIfNil(genre.ParentID))
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to create genre", zap.String("name", genre.Name), zap.Error(err))
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetGenre retrieves a genre, returning sql.ErrNoRows when it does not exist
+func (r *PostgresRepository) GetGenre(ctx context.Context, id int) (models.Genre, error) {
+	return r.genres.Get(ctx, id)
+}
+
+// GetGenreByName retrieves the genre with the name regardless of case, returning sql.ErrNoRows when there is none
+func (r *PostgresRepository) GetGenreByName(ctx context.Context, name string) (models.Genre, error) {
+	genres, err := r.genres.Find(ctx, "LOWER(name) = LOWER($1)", []any{name}, "id")
+	if err != nil {
+		return models.Genre{}, err
+	}
+	if len(genres) == 0 {
+		return models.Genre{}, sql.ErrNoRows
+	}
+	return genres[0], nil
+}
+
+// GetGenres retrieves every genre in name order
+func (r *PostgresRepository) GetGenres(ctx context.Context) ([]models.Genre, error) {
+	r.logger.Debug("Fetching genres")
+	return r.genres.Find(ctx, "", nil, "name, id")
+}
+
+// GetGenreSubtree returns the IDs of the genre and of all its subgenres, or none when it does not exist
+func (r *PostgresRepository) GetGenreSubtree(ctx context.Context, id int) ([]int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `WITH RECURSIVE subtree AS (
+			SELECT id FROM genres WHERE id = $1
+			UNION ALL
+			SELECT g.id FROM genres g JOIN subtree ON g.parent_id = subtree.id
+		) SELECT id FROM subtree`
+	ids := []int{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &ids, query, id)
+	r.track(query, start, int64(len(ids)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch genre subtree", zap.Int("id", id), zap.Error(err))
+		return nil, err
+	}
+	return ids, nil
+}
+
+// UpdateGenre replaces the name and parent of a genre, returning sql.ErrNoRows when it does not exist
+func (r *PostgresRepository) UpdateGenre(ctx context.Context, id int, genre models.GenreInput) error {
+	r.logger.Debug("Updating genre", zap.Int("id", id))
+	return r.genres.Update(ctx, id, map[string]any{
+		"name":      genre.Name,
+		"parent_id": nullIfNil(genre.ParentID),
+	})
+}
+
+// DeleteGenre deletes a genre and unassigns it from its songs, returning sql.ErrNoRows when it does not exist.
+// Genres with subgenres cannot be deleted.
+func (r *PostgresRepository) DeleteGenre(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting genre", zap.Int("id", id))
+	if err := r.genres.Delete(ctx, id); err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to delete genre", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// CountSubgenres returns the number of genres whose parent is the genre
+func (r *PostgresRepository) CountSubgenres(ctx context.Context, id int) (int, error) {
+	return r.genres.Count(ctx, "parent_id = $1", []any{id})
+}
+
+// GetSongGenres retrieves the genres assigned to a song in name order
+func (r *PostgresRepository) GetSongGenres(ctx context.Context, songID int) ([]models.Genre, error) {
+	r.logger.Debug("Fetching song genres", zap.Int("song_id", songID))
+	return r.genres.Find(ctx, "id IN (SELECT genre_id FROM song_genres WHERE song_id = $1)", []any{songID}, "name, id")
+}
+
+// SetSongGenres replaces the genres assigned to a song with the genres with the IDs
+func (r *PostgresRepository) SetSongGenres(ctx context.Context, songID int, genreIDs []int) error {
+	r.logger.Debug("Assigning song genres", zap.Int("song_id", songID), zap.Ints("genre_ids", genreIDs))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "DELETE FROM song_genres WHERE song_id = $1 AND NOT (genre_id = ANY($2))"
+	start := time.Now()
+	result, err := tx.ExecContext(ctx, query, songID, pq.Array(genreIDs))
+	var rows int64
+	if err == nil {
+		rows, _ = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to unassign song genres", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	query = "INSERT INTO song_genres (song_id, genre_id) SELECT $1, unnest($2::int[]) ON CONFLICT DO NOTHING"
+	start = time.Now()
+	result, err = tx.ExecContext(ctx, query, songID, pq.Array(genreIDs))
+	if err == nil {
+		rows, _ = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to assign song genres", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+	return nil
+}
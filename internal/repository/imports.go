@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// CreateImport registers a new import so its progress can be checkpointed
+func (r *PostgresRepository) CreateImport(ctx context.Context, id string) (models.Import, error) {
+	r.logger.Debug("Creating import", zap.String("import_id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "INSERT INTO imports (id) VALUES ($1) RETURNING *"
+	var imp models.Import
+	start := time.Now()
+	err := r.db.GetContext(ctx, &imp, query, id)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to create import", zap.Error(err))
+		return imp, err
+	}
+	return imp, nil
+}
+
+// GetImport retrieves an import and its checkpoint
+func (r *PostgresRepository) GetImport(ctx context.Context, id string) (models.Import, error) {
+	r.logger.Debug("Fetching import", zap.String("import_id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT * FROM imports WHERE id = $1"
+	var imp models.Import
+	start := time.Now()
+	err := r.db.GetContext(ctx, &imp, query, id)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Warn("Failed to fetch import", zap.String("import_id", id), zap.Error(err))
+		return imp, err
+	}
+	return imp, nil
+}
+
+// ImportBatch writes a batch of imported songs and advances the import checkpoint in one transaction,
+// so an interrupted import resumes exactly after the last committed batch.
+// Existing songs only have their release date, text and link replaced by non-empty values.
+func (r *PostgresRepository) ImportBatch(ctx context.Context, importID string, songs []models.ImportSong, checkpointRow, failed int) ([]int, error) {
+	r.logger.Debug("Writing import batch", zap.String("import_id", importID), zap.Int("songs", len(songs)), zap.Int("checkpoint_row", checkpointRow))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	updateQuery := `UPDATE songs SET release_date = COALESCE(NULLIF($2, ''), release_date), 
+		text = COALESCE(NULLIF($3, ''), text), link = COALESCE(NULLIF($4, ''), link), 
+		enriched_at = COALESCE($5, enriched_at) WHERE id = $1`
+	start := time.Now()
+	ids := make([]int, len(songs))
+	var created []int
+	var inputs []models.SongInput
+	for i, song := range songs {
+		if song.ExistingID != 0 {
+			if _, err := tx.ExecContext(ctx, updateQuery, song.ExistingID, song.ReleaseDate, song.Text, song.Link, song.EnrichedAt); err != nil {
+				r.track(updateQuery, start, int64(i), err)
+				r.logger.Error("Failed to update imported song", zap.Int("row", song.Row), zap.Error(err))
+				return nil, err
+			}
+			ids[i] = song.ExistingID
+			continue
+		}
+		created = append(created, i)
+		inputs = append(inputs, models.SongInput{Group: song.Group, Song: song.Song, ReleaseDate: song.ReleaseDate,
+			Text: song.Text, Link: song.Link, EnrichedAt: song.EnrichedAt})
+	}
+	if len(songs) > len(created) {
+		r.track(updateQuery, start, int64(len(songs)-len(created)), nil)
+	}
+	// The new songs are written at once, which is what makes large imports fast
+	createdIDs, err := r.copySongs(ctx, tx.Tx, inputs)
+	if err != nil {
+		r.logger.Error("Failed to insert imported songs", zap.Int("first_row", songs[created[0]].Row), zap.Error(err))
+		return nil, err
+	}
+	for i, index := range created {
+		ids[index] = createdIDs[i]
+	}
+
+	checkpointQuery := `UPDATE imports SET checkpoint_row = $2, created = created + $3, updated = updated + $4, 
+		failed = failed + $5, updated_at = NOW() WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, checkpointQuery, importID, checkpointRow, len(created), len(songs)-len(created), failed); err != nil {
+		r.track(checkpointQuery, start, 0, err)
+		r.logger.Error("Failed to checkpoint import", zap.Error(err))
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit import batch", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Import batch written", zap.String("import_id", importID), zap.Int("created", len(created)), zap.Int("updated", len(songs)-len(created)))
+	return ids, nil
+}
+
+// FinishImport records the final status of an import
+func (r *PostgresRepository) FinishImport(ctx context.Context, id, status string) (models.Import, error) {
+	r.logger.Debug("Finishing import", zap.String("import_id", id), zap.String("status", status))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "UPDATE imports SET status = $2, updated_at = NOW() WHERE id = $1 RETURNING *"
+	var imp models.Import
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &imp, query, id, status)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to finish import", zap.String("import_id", id), zap.Error(err))
+		return imp, err
+	}
+	return imp, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/tracing"
+)
+
+// DefaultSerializationRetries is the number of times a call failing with a serialization failure is retried
+const DefaultSerializationRetries = 3
+
+// retryableCodes are the PostgreSQL errors after which the whole call can be repeated: the transaction
+// was rolled back and would likely succeed when run again
+var retryableCodes = map[pq.ErrorCode]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
+// mongoWriteConflict is the MongoDB error raised when a write conflicts with a concurrent transaction
+const mongoWriteConflict = 112
+
+// unavailableCodes are the PostgreSQL errors raised while the server goes down or fails over, besides
+// the connection exceptions of class 08
+var unavailableCodes = map[pq.ErrorCode]bool{
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+}
+
+// InstrumentedRepository decorates a Repository: every call taking a context is logged, measured,
+// traced and, when it fails with a serialization failure or deadlock, retried. Its methods are generated
+// from the Repository interface by go generate.
+type InstrumentedRepository struct {
+	next    Repository
+	logger  *zap.Logger
+	tracer  trace.Tracer
+	retries int
+}
+
+// NewInstrumentedRepository wraps next; retries is the number of repeats after a serialization failure,
+// zero disables them
+func NewInstrumentedRepository(next Repository, logger *zap.Logger, retries int) *InstrumentedRepository {
+	return &InstrumentedRepository{
+		next:    next,
+		logger:  logger,
+		tracer:  otel.Tracer("music-library/internal/repository"),
+		retries: max(retries, 0),
+	}
+}
+
+// call runs fn in a span named after the method, retrying it on serialization failures, and records
+// its duration and outcome in the metrics and in a log entry carrying the trace and request ID, as no SDK
+// records the spans. Calls within RunInTransaction are not retried on their own, as the failure
+// aborted the transaction; RunInTransaction is retried as a whole instead.
+func (r *InstrumentedRepository) call(ctx context.Context, method string, fn func(ctx context.Context) error) error {
+	ctx, span := r.tracer.Start(ctx, "Repository."+method)
+	defer span.End()
+	start := time.Now()
+	fields := []zap.Field{zap.String("method", method)}
+	if sc := span.SpanContext(); sc.IsValid() {
+		fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+	}
+	if id := tracing.RequestID(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+
+	retries := r.retries
+	if inTransaction(ctx) {
+		retries = 0
+	}
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = fn(ctx)
+		if err == nil || attempt >= retries || !isRetryable(err) || ctx.Err() != nil {
+			break
+		}
+		r.logger.Warn("Retrying repository call after serialization failure", append(fields, zap.Int("attempt", attempt+1), zap.Error(err))...)
+		metrics.RepositoryRetries.WithLabelValues(method).Inc()
+		time.Sleep(time.Duration(attempt+1) * 10 * time.Millisecond)
+	}
+
+	duration := time.Since(start)
+	outcome := metrics.Outcome(err)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Not found is an answer, not a failure of the storage
+		outcome = metrics.OutcomeNotFound
+	}
+	metrics.RepositoryDuration.WithLabelValues(method, outcome).Observe(duration.Seconds())
+	if outcome == metrics.OutcomeError {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		r.logger.Error("Repository call failed", append(fields, zap.Duration("took", duration), zap.Error(err))...)
+		return err
+	}
+	r.logger.Debug("Repository call finished", append(fields, zap.Duration("took", duration))...)
+	return err
+}
+
+// isRetryable reports whether err is a PostgreSQL serialization failure or deadlock, a MySQL deadlock or
+// lock wait timeout, a SQLite database that stayed locked by another writer beyond the busy timeout, or a
+// MongoDB write conflict or other transient transaction error
+func isRetryable(err error) bool {
+	var mongoErr mongo.ServerError
+	if errors.As(err, &mongoErr) {
+		return mongoErr.HasErrorLabel("TransientTransactionError") || mongoErr.HasErrorCode(mongoWriteConflict)
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return retryableCodes[pqErr.Code]
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	}
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked)
+}
+
+// IsUnavailable reports whether err comes from the database being unreachable or shutting down, rather
+// than from the query itself
+func IsUnavailable(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || mongo.IsNetworkError(err) {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && (pqErr.Code.Class() == "08" || unavailableCodes[pqErr.Code])
+}
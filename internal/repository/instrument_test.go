@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"music-library/internal/tracing"
+)
+
+// fakeRepository fails GetSongByID with the queued errors before answering
+type fakeRepository struct {
+	Repository
+	errs  []error
+	calls int
+}
+
+func (f *fakeRepository) GetSongByID(ctx context.Context, id int) (models.Song, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return models.Song{}, err
+	}
+	return models.Song{ID: id}, nil
+}
+
+func TestInstrumentedRepositoryRetries(t *testing.T) {
+	serialization := &pq.Error{Code: "40001"}
+	fake := &fakeRepository{errs: []error{serialization, serialization}}
+	repo := NewInstrumentedRepository(fake, zap.NewNop(), 3)
+	retries := testutil.ToFloat64(metrics.RepositoryRetries.WithLabelValues("GetSongByID"))
+
+	song, err := repo.GetSongByID(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, song.ID)
+	assert.Equal(t, 3, fake.calls, "serialization failures are retried")
+	assert.Equal(t, retries+2, testutil.ToFloat64(metrics.RepositoryRetries.WithLabelValues("GetSongByID")))
+
+	fake = &fakeRepository{errs: []error{sql.ErrNoRows}}
+	_, err = NewInstrumentedRepository(fake, zap.NewNop(), 3).GetSongByID(context.Background(), 7)
+	assert.Equal(t, sql.ErrNoRows, err, "errors are returned unwrapped")
+	assert.Equal(t, 1, fake.calls)
+
+	fake = &fakeRepository{errs: []error{serialization, errors.New("connection reset")}}
+	_, err = NewInstrumentedRepository(fake, zap.NewNop(), 0).GetSongByID(context.Background(), 7)
+	assert.ErrorIs(t, err, serialization)
+	assert.Equal(t, 1, fake.calls, "retries can be disabled")
+}
+
+func TestInstrumentedRepositoryLogsRequest(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	fake := &fakeRepository{errs: []error{errors.New("connection reset")}}
+	ctx := tracing.WithRequestID(tracing.Extract(context.Background(), nil), "req-1")
+
+	_, err := NewInstrumentedRepository(fake, zap.New(core), 0).GetSongByID(ctx, 7)
+	assert.Error(t, err)
+	if entries := logs.FilterMessage("Repository call failed").AllUntimed(); assert.Len(t, entries, 1) {
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "GetSongByID", fields["method"])
+		assert.Equal(t, "req-1", fields["request_id"])
+		assert.Len(t, fields["trace_id"], 32)
+	}
+}
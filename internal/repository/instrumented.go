@@ -0,0 +1,1383 @@
+// Code generated by go run ./gen -type Repository; DO NOT EDIT.
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"music-library/internal/models"
+)
+
+var _ Repository = (*InstrumentedRepository)(nil)
+
+// ConfigurePopularity calls the wrapped Repository's ConfigurePopularity
+func (r *InstrumentedRepository) ConfigurePopularity(provider PopularityProvider) {
+	r.next.ConfigurePopularity(provider)
+}
+
+// ConfigureStatementTimeout calls the wrapped Repository's ConfigureStatementTimeout
+func (r *InstrumentedRepository) ConfigureStatementTimeout(timeout time.Duration) {
+	r.next.ConfigureStatementTimeout(timeout)
+}
+
+// Ping calls the wrapped Repository's Ping, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) Ping(ctx context.Context) (result0 error) {
+	result0 = r.call(ctx, "Ping", func(ctx context.Context) error {
+		return r.next.Ping(ctx)
+	})
+	return result0
+}
+
+// RecentQueries calls the wrapped Repository's RecentQueries
+func (r *InstrumentedRepository) RecentQueries() (result0 []QueryLogEntry) {
+	return r.next.RecentQueries()
+}
+
+// RunInTransaction calls the wrapped Repository's RunInTransaction, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) (result0 error) {
+	result0 = r.call(ctx, "RunInTransaction", func(ctx context.Context) error {
+		return r.next.RunInTransaction(ctx, fn)
+	})
+	return result0
+}
+
+// AddSong calls the wrapped Repository's AddSong, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) AddSong(ctx context.Context, group string, song string, releaseDate string, text string, link string, enrichedAt *time.Time) (result0 int, result1 error) {
+	result1 = r.call(ctx, "AddSong", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.AddSong(ctx, group, song, releaseDate, text, link, enrichedAt)
+		return result1
+	})
+	return result0, result1
+}
+
+// AddSongs calls the wrapped Repository's AddSongs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) AddSongs(ctx context.Context, songs []models.SongInput) (result0 []int, result1 []error, result2 error) {
+	result2 = r.call(ctx, "AddSongs", func(ctx context.Context) error {
+		var result2 error
+		result0, result1, result2 = r.next.AddSongs(ctx, songs)
+		return result2
+	})
+	return result0, result1, result2
+}
+
+// CopySongs calls the wrapped Repository's CopySongs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CopySongs(ctx context.Context, songs []models.SongInput) (result0 []int, result1 error) {
+	result1 = r.call(ctx, "CopySongs", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CopySongs(ctx, songs)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongByID calls the wrapped Repository's GetSongByID, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongByID(ctx context.Context, id int) (result0 models.Song, result1 error) {
+	result1 = r.call(ctx, "GetSongByID", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongByID(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongs calls the wrapped Repository's GetSongs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongs(ctx context.Context, filter models.SongFilter, sort string, page int, limit int) (result0 []models.Song, result1 error) {
+	result1 = r.call(ctx, "GetSongs", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongs(ctx, filter, sort, page, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// CountSongs calls the wrapped Repository's CountSongs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CountSongs(ctx context.Context, filter models.SongFilter) (result0 int, result1 error) {
+	result1 = r.call(ctx, "CountSongs", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CountSongs(ctx, filter)
+		return result1
+	})
+	return result0, result1
+}
+
+// FindSongID calls the wrapped Repository's FindSongID, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) FindSongID(ctx context.Context, group string, song string) (result0 int, result1 error) {
+	result1 = r.call(ctx, "FindSongID", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.FindSongID(ctx, group, song)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongFacets calls the wrapped Repository's GetSongFacets, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongFacets(ctx context.Context, filter models.SongFilter, facets []string) (result0 map[string][]models.FacetBucket, result1 error) {
+	result1 = r.call(ctx, "GetSongFacets", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongFacets(ctx, filter, facets)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongsCreatedBetween calls the wrapped Repository's GetSongsCreatedBetween, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongsCreatedBetween(ctx context.Context, from time.Time, to time.Time) (result0 []models.Song, result1 error) {
+	result1 = r.call(ctx, "GetSongsCreatedBetween", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongsCreatedBetween(ctx, from, to)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongsUpdatedBetween calls the wrapped Repository's GetSongsUpdatedBetween, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongsUpdatedBetween(ctx context.Context, from time.Time, to time.Time) (result0 []models.Song, result1 error) {
+	result1 = r.call(ctx, "GetSongsUpdatedBetween", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongsUpdatedBetween(ctx, from, to)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongsWithLyrics calls the wrapped Repository's GetSongsWithLyrics, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongsWithLyrics(ctx context.Context) (result0 []models.Song, result1 error) {
+	result1 = r.call(ctx, "GetSongsWithLyrics", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongsWithLyrics(ctx)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongsWithReleaseDate calls the wrapped Repository's GetSongsWithReleaseDate, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongsWithReleaseDate(ctx context.Context, group string, song string) (result0 []models.Song, result1 error) {
+	result1 = r.call(ctx, "GetSongsWithReleaseDate", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongsWithReleaseDate(ctx, group, song)
+		return result1
+	})
+	return result0, result1
+}
+
+// UpdateSong calls the wrapped Repository's UpdateSong, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) UpdateSong(ctx context.Context, id int, group string, song string, releaseDate string, text string, link string) (result0 error) {
+	result0 = r.call(ctx, "UpdateSong", func(ctx context.Context) error {
+		return r.next.UpdateSong(ctx, id, group, song, releaseDate, text, link)
+	})
+	return result0
+}
+
+// UpdateSongPartial calls the wrapped Repository's UpdateSongPartial, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) UpdateSongPartial(ctx context.Context, id int, patch models.SongPatch) (result0 error) {
+	result0 = r.call(ctx, "UpdateSongPartial", func(ctx context.Context) error {
+		return r.next.UpdateSongPartial(ctx, id, patch)
+	})
+	return result0
+}
+
+// DeleteSong calls the wrapped Repository's DeleteSong, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) DeleteSong(ctx context.Context, id int) (result0 error) {
+	result0 = r.call(ctx, "DeleteSong", func(ctx context.Context) error {
+		return r.next.DeleteSong(ctx, id)
+	})
+	return result0
+}
+
+// TruncateSongs calls the wrapped Repository's TruncateSongs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) TruncateSongs(ctx context.Context) (result0 error) {
+	result0 = r.call(ctx, "TruncateSongs", func(ctx context.Context) error {
+		return r.next.TruncateSongs(ctx)
+	})
+	return result0
+}
+
+// CreateSnapshot calls the wrapped Repository's CreateSnapshot, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CreateSnapshot(ctx context.Context, reason string) (result0 models.Snapshot, result1 error) {
+	result1 = r.call(ctx, "CreateSnapshot", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CreateSnapshot(ctx, reason)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSnapshots calls the wrapped Repository's GetSnapshots, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSnapshots(ctx context.Context) (result0 []models.Snapshot, result1 error) {
+	result1 = r.call(ctx, "GetSnapshots", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSnapshots(ctx)
+		return result1
+	})
+	return result0, result1
+}
+
+// RestoreSnapshot calls the wrapped Repository's RestoreSnapshot, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) RestoreSnapshot(ctx context.Context, id int) (result0 int, result1 error) {
+	result1 = r.call(ctx, "RestoreSnapshot", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.RestoreSnapshot(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// SetLegalHold calls the wrapped Repository's SetLegalHold, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SetLegalHold(ctx context.Context, id int, held bool) (result0 error) {
+	result0 = r.call(ctx, "SetLegalHold", func(ctx context.Context) error {
+		return r.next.SetLegalHold(ctx, id, held)
+	})
+	return result0
+}
+
+// GetLegalHolds calls the wrapped Repository's GetLegalHolds, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetLegalHolds(ctx context.Context, ids []int) (result0 []int, result1 error) {
+	result1 = r.call(ctx, "GetLegalHolds", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetLegalHolds(ctx, ids)
+		return result1
+	})
+	return result0, result1
+}
+
+// CountLegalHolds calls the wrapped Repository's CountLegalHolds, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CountLegalHolds(ctx context.Context) (result0 int, result1 error) {
+	result1 = r.call(ctx, "CountLegalHolds", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CountLegalHolds(ctx)
+		return result1
+	})
+	return result0, result1
+}
+
+// BackfillLegacyRows calls the wrapped Repository's BackfillLegacyRows, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) BackfillLegacyRows(ctx context.Context, dryRun bool) (result0 models.BackfillReport, result1 error) {
+	result1 = r.call(ctx, "BackfillLegacyRows", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.BackfillLegacyRows(ctx, dryRun)
+		return result1
+	})
+	return result0, result1
+}
+
+// SaveVerseIndex calls the wrapped Repository's SaveVerseIndex, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SaveVerseIndex(ctx context.Context, songID int, delimiter string, textHash string, spans []models.VerseSpan) (result0 error) {
+	result0 = r.call(ctx, "SaveVerseIndex", func(ctx context.Context) error {
+		return r.next.SaveVerseIndex(ctx, songID, delimiter, textHash, spans)
+	})
+	return result0
+}
+
+// GetVerseIndex calls the wrapped Repository's GetVerseIndex, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetVerseIndex(ctx context.Context, songID int) (result0 models.VerseIndex, result1 error) {
+	result1 = r.call(ctx, "GetVerseIndex", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetVerseIndex(ctx, songID)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetIndexedVerses calls the wrapped Repository's GetIndexedVerses, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetIndexedVerses(ctx context.Context, songID int, delimiter string, from int, to int) (result0 []models.IndexedVerse, result1 error) {
+	result1 = r.call(ctx, "GetIndexedVerses", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetIndexedVerses(ctx, songID, delimiter, from, to)
+		return result1
+	})
+	return result0, result1
+}
+
+// IncrementSongViews calls the wrapped Repository's IncrementSongViews, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) IncrementSongViews(ctx context.Context, counts map[int]int64) (result0 error) {
+	result0 = r.call(ctx, "IncrementSongViews", func(ctx context.Context) error {
+		return r.next.IncrementSongViews(ctx, counts)
+	})
+	return result0
+}
+
+// RefreshTrending calls the wrapped Repository's RefreshTrending, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) RefreshTrending(ctx context.Context, gravity float64, windowDays int) (result0 error) {
+	result0 = r.call(ctx, "RefreshTrending", func(ctx context.Context) error {
+		return r.next.RefreshTrending(ctx, gravity, windowDays)
+	})
+	return result0
+}
+
+// GetTrendingSongs calls the wrapped Repository's GetTrendingSongs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetTrendingSongs(ctx context.Context, limit int) (result0 []models.TrendingSong, result1 error) {
+	result1 = r.call(ctx, "GetTrendingSongs", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetTrendingSongs(ctx, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetGroupStats calls the wrapped Repository's GetGroupStats, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetGroupStats(ctx context.Context, group string) (result0 models.GroupStats, result1 error) {
+	result1 = r.call(ctx, "GetGroupStats", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetGroupStats(ctx, group)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetMostViewedGroupSongs calls the wrapped Repository's GetMostViewedGroupSongs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetMostViewedGroupSongs(ctx context.Context, group string, limit int) (result0 []models.Song, result1 error) {
+	result1 = r.call(ctx, "GetMostViewedGroupSongs", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetMostViewedGroupSongs(ctx, group, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongsNeedingListeners calls the wrapped Repository's GetSongsNeedingListeners, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongsNeedingListeners(ctx context.Context, maxAge time.Duration, exclude []int, limit int) (result0 []models.Song, result1 error) {
+	result1 = r.call(ctx, "GetSongsNeedingListeners", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongsNeedingListeners(ctx, maxAge, exclude, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// SaveListenerCount calls the wrapped Repository's SaveListenerCount, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SaveListenerCount(ctx context.Context, id int, listeners int64) (result0 error) {
+	result0 = r.call(ctx, "SaveListenerCount", func(ctx context.Context) error {
+		return r.next.SaveListenerCount(ctx, id, listeners)
+	})
+	return result0
+}
+
+// GetStalestSongs calls the wrapped Repository's GetStalestSongs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetStalestSongs(ctx context.Context, staleAfter time.Duration, exclude []int, limit int) (result0 []models.Song, result1 error) {
+	result1 = r.call(ctx, "GetStalestSongs", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetStalestSongs(ctx, staleAfter, exclude, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongsAfter calls the wrapped Repository's GetSongsAfter, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongsAfter(ctx context.Context, filter models.SongFilter, afterID int, limit int) (result0 []models.Song, result1 error) {
+	result1 = r.call(ctx, "GetSongsAfter", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongsAfter(ctx, filter, afterID, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// RefreshSongData calls the wrapped Repository's RefreshSongData, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) RefreshSongData(ctx context.Context, id int, releaseDate string, text string, link string) (result0 error) {
+	result0 = r.call(ctx, "RefreshSongData", func(ctx context.Context) error {
+		return r.next.RefreshSongData(ctx, id, releaseDate, text, link)
+	})
+	return result0
+}
+
+// AddPendingSong calls the wrapped Repository's AddPendingSong, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) AddPendingSong(ctx context.Context, group string, song string) (result0 int, result1 error) {
+	result1 = r.call(ctx, "AddPendingSong", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.AddPendingSong(ctx, group, song)
+		return result1
+	})
+	return result0, result1
+}
+
+// CompleteEnrichment calls the wrapped Repository's CompleteEnrichment, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CompleteEnrichment(ctx context.Context, id int, releaseDate string, text string, link string, enrichedAt *time.Time) (result0 error) {
+	result0 = r.call(ctx, "CompleteEnrichment", func(ctx context.Context) error {
+		return r.next.CompleteEnrichment(ctx, id, releaseDate, text, link, enrichedAt)
+	})
+	return result0
+}
+
+// FailEnrichment calls the wrapped Repository's FailEnrichment, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) FailEnrichment(ctx context.Context, id int, reason string) (result0 error) {
+	result0 = r.call(ctx, "FailEnrichment", func(ctx context.Context) error {
+		return r.next.FailEnrichment(ctx, id, reason)
+	})
+	return result0
+}
+
+// GetEnrichmentStatus calls the wrapped Repository's GetEnrichmentStatus, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetEnrichmentStatus(ctx context.Context, id int) (result0 models.EnrichmentStatus, result1 error) {
+	result1 = r.call(ctx, "GetEnrichmentStatus", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetEnrichmentStatus(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetPendingEnrichments calls the wrapped Repository's GetPendingEnrichments, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetPendingEnrichments(ctx context.Context, exclude []int, limit int) (result0 []models.Song, result1 error) {
+	result1 = r.call(ctx, "GetPendingEnrichments", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetPendingEnrichments(ctx, exclude, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongsNeedingMetadata calls the wrapped Repository's GetSongsNeedingMetadata, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongsNeedingMetadata(ctx context.Context, exclude []int, limit int) (result0 []models.Song, result1 error) {
+	result1 = r.call(ctx, "GetSongsNeedingMetadata", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongsNeedingMetadata(ctx, exclude, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// SaveSongMetadata calls the wrapped Repository's SaveSongMetadata, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SaveSongMetadata(ctx context.Context, id int, metadata models.TrackMetadata) (result0 error) {
+	result0 = r.call(ctx, "SaveSongMetadata", func(ctx context.Context) error {
+		return r.next.SaveSongMetadata(ctx, id, metadata)
+	})
+	return result0
+}
+
+// CreateImport calls the wrapped Repository's CreateImport, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CreateImport(ctx context.Context, id string) (result0 models.Import, result1 error) {
+	result1 = r.call(ctx, "CreateImport", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CreateImport(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetImport calls the wrapped Repository's GetImport, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetImport(ctx context.Context, id string) (result0 models.Import, result1 error) {
+	result1 = r.call(ctx, "GetImport", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetImport(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// ImportBatch calls the wrapped Repository's ImportBatch, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) ImportBatch(ctx context.Context, importID string, songs []models.ImportSong, checkpointRow int, failed int) (result0 []int, result1 error) {
+	result1 = r.call(ctx, "ImportBatch", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.ImportBatch(ctx, importID, songs, checkpointRow, failed)
+		return result1
+	})
+	return result0, result1
+}
+
+// FinishImport calls the wrapped Repository's FinishImport, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) FinishImport(ctx context.Context, id string, status string) (result0 models.Import, result1 error) {
+	result1 = r.call(ctx, "FinishImport", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.FinishImport(ctx, id, status)
+		return result1
+	})
+	return result0, result1
+}
+
+// CreateJob calls the wrapped Repository's CreateJob, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CreateJob(ctx context.Context, id string, kind string, ownerID *int) (result0 models.Job, result1 error) {
+	result1 = r.call(ctx, "CreateJob", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CreateJob(ctx, id, kind, ownerID)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetJob calls the wrapped Repository's GetJob, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetJob(ctx context.Context, id string) (result0 models.Job, result1 error) {
+	result1 = r.call(ctx, "GetJob", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetJob(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// StartJob calls the wrapped Repository's StartJob, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) StartJob(ctx context.Context, id string) (result0 error) {
+	result0 = r.call(ctx, "StartJob", func(ctx context.Context) error {
+		return r.next.StartJob(ctx, id)
+	})
+	return result0
+}
+
+// UpdateJobProgress calls the wrapped Repository's UpdateJobProgress, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) UpdateJobProgress(ctx context.Context, id string, total int, processed int, failed int) (result0 error) {
+	result0 = r.call(ctx, "UpdateJobProgress", func(ctx context.Context) error {
+		return r.next.UpdateJobProgress(ctx, id, total, processed, failed)
+	})
+	return result0
+}
+
+// FinishJob calls the wrapped Repository's FinishJob, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) FinishJob(ctx context.Context, id string, status string, total int, processed int, failed int, result []byte, message string) (result0 error) {
+	result0 = r.call(ctx, "FinishJob", func(ctx context.Context) error {
+		return r.next.FinishJob(ctx, id, status, total, processed, failed, result, message)
+	})
+	return result0
+}
+
+// FailUnfinishedJobs calls the wrapped Repository's FailUnfinishedJobs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) FailUnfinishedJobs(ctx context.Context, message string) (result0 int64, result1 error) {
+	result1 = r.call(ctx, "FailUnfinishedJobs", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.FailUnfinishedJobs(ctx, message)
+		return result1
+	})
+	return result0, result1
+}
+
+// SearchSongs calls the wrapped Repository's SearchSongs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SearchSongs(ctx context.Context, query string, limit int) (result0 []models.SearchResult, result1 error) {
+	result1 = r.call(ctx, "SearchSongs", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.SearchSongs(ctx, query, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// HasSongEmbeddings calls the wrapped Repository's HasSongEmbeddings, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) HasSongEmbeddings(ctx context.Context) (result0 bool, result1 error) {
+	result1 = r.call(ctx, "HasSongEmbeddings", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.HasSongEmbeddings(ctx)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongsNeedingEmbedding calls the wrapped Repository's GetSongsNeedingEmbedding, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongsNeedingEmbedding(ctx context.Context, model string, limit int) (result0 []models.Song, result1 error) {
+	result1 = r.call(ctx, "GetSongsNeedingEmbedding", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongsNeedingEmbedding(ctx, model, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// SaveSongEmbedding calls the wrapped Repository's SaveSongEmbedding, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SaveSongEmbedding(ctx context.Context, songID int, model string, contentHash string, embedding []float32) (result0 error) {
+	result0 = r.call(ctx, "SaveSongEmbedding", func(ctx context.Context) error {
+		return r.next.SaveSongEmbedding(ctx, songID, model, contentHash, embedding)
+	})
+	return result0
+}
+
+// SearchSongsSemantic calls the wrapped Repository's SearchSongsSemantic, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SearchSongsSemantic(ctx context.Context, model string, embedding []float32, keywords string, keywordWeight float64, limit int) (result0 []models.SearchResult, result1 error) {
+	result1 = r.call(ctx, "SearchSongsSemantic", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.SearchSongsSemantic(ctx, model, embedding, keywords, keywordWeight, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// HasSearchSuggestions calls the wrapped Repository's HasSearchSuggestions, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) HasSearchSuggestions(ctx context.Context) (result0 bool, result1 error) {
+	result1 = r.call(ctx, "HasSearchSuggestions", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.HasSearchSuggestions(ctx)
+		return result1
+	})
+	return result0, result1
+}
+
+// SimilarSongNames calls the wrapped Repository's SimilarSongNames, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SimilarSongNames(ctx context.Context, query string, limit int) (result0 []models.SearchSuggestion, result1 error) {
+	result1 = r.call(ctx, "SimilarSongNames", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.SimilarSongNames(ctx, query, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// SimilarSearchTerms calls the wrapped Repository's SimilarSearchTerms, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SimilarSearchTerms(ctx context.Context, words []string) (result0 map[string]models.SearchSuggestion, result1 error) {
+	result1 = r.call(ctx, "SimilarSearchTerms", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.SimilarSearchTerms(ctx, words)
+		return result1
+	})
+	return result0, result1
+}
+
+// RefreshSearchTerms calls the wrapped Repository's RefreshSearchTerms, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) RefreshSearchTerms(ctx context.Context) (result0 error) {
+	result0 = r.call(ctx, "RefreshSearchTerms", func(ctx context.Context) error {
+		return r.next.RefreshSearchTerms(ctx)
+	})
+	return result0
+}
+
+// AddClassificationSuggestions calls the wrapped Repository's AddClassificationSuggestions, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) AddClassificationSuggestions(ctx context.Context, songID int, source string, suggestions []models.ClassificationSuggestion) (result0 int, result1 error) {
+	result1 = r.call(ctx, "AddClassificationSuggestions", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.AddClassificationSuggestions(ctx, songID, source, suggestions)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetClassificationSuggestions calls the wrapped Repository's GetClassificationSuggestions, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetClassificationSuggestions(ctx context.Context, status string, page int, limit int) (result0 []models.ClassificationSuggestion, result1 error) {
+	result1 = r.call(ctx, "GetClassificationSuggestions", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetClassificationSuggestions(ctx, status, page, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetClassificationSuggestion calls the wrapped Repository's GetClassificationSuggestion, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetClassificationSuggestion(ctx context.Context, id int) (result0 models.ClassificationSuggestion, result1 error) {
+	result1 = r.call(ctx, "GetClassificationSuggestion", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetClassificationSuggestion(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// ReviewClassificationSuggestion calls the wrapped Repository's ReviewClassificationSuggestion, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) ReviewClassificationSuggestion(ctx context.Context, id int, status string) (result0 error) {
+	result0 = r.call(ctx, "ReviewClassificationSuggestion", func(ctx context.Context) error {
+		return r.next.ReviewClassificationSuggestion(ctx, id, status)
+	})
+	return result0
+}
+
+// BulkTagSongs calls the wrapped Repository's BulkTagSongs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) BulkTagSongs(ctx context.Context, ids []int, filter models.SongFilter, add []string, remove []string) (result0 models.BulkTagResult, result1 error) {
+	result1 = r.call(ctx, "BulkTagSongs", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.BulkTagSongs(ctx, ids, filter, add, remove)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongTags calls the wrapped Repository's GetSongTags, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongTags(ctx context.Context, songID int) (result0 []string, result1 error) {
+	result1 = r.call(ctx, "GetSongTags", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongTags(ctx, songID)
+		return result1
+	})
+	return result0, result1
+}
+
+// IncrementProviderUsage calls the wrapped Repository's IncrementProviderUsage, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) IncrementProviderUsage(ctx context.Context, provider string, day time.Time) (result0 int, result1 error) {
+	result1 = r.call(ctx, "IncrementProviderUsage", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.IncrementProviderUsage(ctx, provider, day)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetProviderUsage calls the wrapped Repository's GetProviderUsage, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetProviderUsage(ctx context.Context, provider string, day time.Time) (result0 int, result1 error) {
+	result1 = r.call(ctx, "GetProviderUsage", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetProviderUsage(ctx, provider, day)
+		return result1
+	})
+	return result0, result1
+}
+
+// AddAPICapture calls the wrapped Repository's AddAPICapture, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) AddAPICapture(ctx context.Context, capture models.APICapture, keep int) (result0 error) {
+	result0 = r.call(ctx, "AddAPICapture", func(ctx context.Context) error {
+		return r.next.AddAPICapture(ctx, capture, keep)
+	})
+	return result0
+}
+
+// GetAPICaptures calls the wrapped Repository's GetAPICaptures, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetAPICaptures(ctx context.Context, provider string, limit int) (result0 []models.APICapture, result1 error) {
+	result1 = r.call(ctx, "GetAPICaptures", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetAPICaptures(ctx, provider, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// CreateWebhook calls the wrapped Repository's CreateWebhook, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CreateWebhook(ctx context.Context, url string, events []string, secret string) (result0 models.Webhook, result1 error) {
+	result1 = r.call(ctx, "CreateWebhook", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CreateWebhook(ctx, url, events, secret)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetWebhooks calls the wrapped Repository's GetWebhooks, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetWebhooks(ctx context.Context) (result0 []models.Webhook, result1 error) {
+	result1 = r.call(ctx, "GetWebhooks", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetWebhooks(ctx)
+		return result1
+	})
+	return result0, result1
+}
+
+// DeleteWebhook calls the wrapped Repository's DeleteWebhook, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) DeleteWebhook(ctx context.Context, id int) (result0 error) {
+	result0 = r.call(ctx, "DeleteWebhook", func(ctx context.Context) error {
+		return r.next.DeleteWebhook(ctx, id)
+	})
+	return result0
+}
+
+// EnqueueWebhookDeliveries calls the wrapped Repository's EnqueueWebhookDeliveries, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) EnqueueWebhookDeliveries(ctx context.Context, eventType string, payload []byte) (result0 int64, result1 error) {
+	result1 = r.call(ctx, "EnqueueWebhookDeliveries", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.EnqueueWebhookDeliveries(ctx, eventType, payload)
+		return result1
+	})
+	return result0, result1
+}
+
+// ClaimWebhookDeliveries calls the wrapped Repository's ClaimWebhookDeliveries, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration, exclude []int) (result0 []models.DueDelivery, result1 error) {
+	result1 = r.call(ctx, "ClaimWebhookDeliveries", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.ClaimWebhookDeliveries(ctx, limit, lease, exclude)
+		return result1
+	})
+	return result0, result1
+}
+
+// DeferWebhookDelivery calls the wrapped Repository's DeferWebhookDelivery, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) DeferWebhookDelivery(ctx context.Context, id int64, after time.Duration) (result0 error) {
+	result0 = r.call(ctx, "DeferWebhookDelivery", func(ctx context.Context) error {
+		return r.next.DeferWebhookDelivery(ctx, id, after)
+	})
+	return result0
+}
+
+// FinishWebhookDelivery calls the wrapped Repository's FinishWebhookDelivery, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) FinishWebhookDelivery(ctx context.Context, id int64, status string, responseStatus *int, message *string, retryAfter time.Duration) (result0 error) {
+	result0 = r.call(ctx, "FinishWebhookDelivery", func(ctx context.Context) error {
+		return r.next.FinishWebhookDelivery(ctx, id, status, responseStatus, message, retryAfter)
+	})
+	return result0
+}
+
+// GetWebhookDeliveries calls the wrapped Repository's GetWebhookDeliveries, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetWebhookDeliveries(ctx context.Context, webhookID int, status string, limit int) (result0 []models.WebhookDelivery, result1 error) {
+	result1 = r.call(ctx, "GetWebhookDeliveries", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetWebhookDeliveries(ctx, webhookID, status, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetWebhookDelivery calls the wrapped Repository's GetWebhookDelivery, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetWebhookDelivery(ctx context.Context, webhookID int, id int64) (result0 models.WebhookDelivery, result1 error) {
+	result1 = r.call(ctx, "GetWebhookDelivery", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetWebhookDelivery(ctx, webhookID, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// RetryWebhookDelivery calls the wrapped Repository's RetryWebhookDelivery, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) RetryWebhookDelivery(ctx context.Context, webhookID int, id int64) (result0 models.WebhookDelivery, result1 error) {
+	result1 = r.call(ctx, "RetryWebhookDelivery", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.RetryWebhookDelivery(ctx, webhookID, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// RotateWebhookSecret calls the wrapped Repository's RotateWebhookSecret, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) RotateWebhookSecret(ctx context.Context, id int, secret string) (result0 models.Webhook, result1 error) {
+	result1 = r.call(ctx, "RotateWebhookSecret", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.RotateWebhookSecret(ctx, id, secret)
+		return result1
+	})
+	return result0, result1
+}
+
+// AddSongEvent calls the wrapped Repository's AddSongEvent, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) AddSongEvent(ctx context.Context, event models.SongEvent) (result0 int64, result1 error) {
+	result1 = r.call(ctx, "AddSongEvent", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.AddSongEvent(ctx, event)
+		return result1
+	})
+	return result0, result1
+}
+
+// ClaimSongEvents calls the wrapped Repository's ClaimSongEvents, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) ClaimSongEvents(ctx context.Context, limit int) (result0 []models.SongEvent, result1 error) {
+	result1 = r.call(ctx, "ClaimSongEvents", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.ClaimSongEvents(ctx, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// MarkSongEventsPublished calls the wrapped Repository's MarkSongEventsPublished, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) MarkSongEventsPublished(ctx context.Context, ids []int64) (result0 error) {
+	result0 = r.call(ctx, "MarkSongEventsPublished", func(ctx context.Context) error {
+		return r.next.MarkSongEventsPublished(ctx, ids)
+	})
+	return result0
+}
+
+// GetSongEventsAfter calls the wrapped Repository's GetSongEventsAfter, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongEventsAfter(ctx context.Context, afterID int64, limit int) (result0 []models.SongEvent, result1 error) {
+	result1 = r.call(ctx, "GetSongEventsAfter", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongEventsAfter(ctx, afterID, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetLatestSongEventID calls the wrapped Repository's GetLatestSongEventID, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetLatestSongEventID(ctx context.Context) (result0 int64, result1 error) {
+	result1 = r.call(ctx, "GetLatestSongEventID", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetLatestSongEventID(ctx)
+		return result1
+	})
+	return result0, result1
+}
+
+// DeleteSongEventsBefore calls the wrapped Repository's DeleteSongEventsBefore, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) DeleteSongEventsBefore(ctx context.Context, before time.Time) (result0 int64, result1 error) {
+	result1 = r.call(ctx, "DeleteSongEventsBefore", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.DeleteSongEventsBefore(ctx, before)
+		return result1
+	})
+	return result0, result1
+}
+
+// CreateUser calls the wrapped Repository's CreateUser, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CreateUser(ctx context.Context, username string, passwordHash string, role string) (result0 int, result1 error) {
+	result1 = r.call(ctx, "CreateUser", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CreateUser(ctx, username, passwordHash, role)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetUserByID calls the wrapped Repository's GetUserByID, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetUserByID(ctx context.Context, id int) (result0 models.User, result1 error) {
+	result1 = r.call(ctx, "GetUserByID", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetUserByID(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetUserByUsername calls the wrapped Repository's GetUserByUsername, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetUserByUsername(ctx context.Context, username string) (result0 models.User, result1 error) {
+	result1 = r.call(ctx, "GetUserByUsername", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetUserByUsername(ctx, username)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetUsers calls the wrapped Repository's GetUsers, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetUsers(ctx context.Context, page int, limit int) (result0 []models.User, result1 error) {
+	result1 = r.call(ctx, "GetUsers", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetUsers(ctx, page, limit)
+		return result1
+	})
+	return result0, result1
+}
+
+// SetUserRole calls the wrapped Repository's SetUserRole, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SetUserRole(ctx context.Context, id int, role string) (result0 error) {
+	result0 = r.call(ctx, "SetUserRole", func(ctx context.Context) error {
+		return r.next.SetUserRole(ctx, id, role)
+	})
+	return result0
+}
+
+// GetPreferences calls the wrapped Repository's GetPreferences, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetPreferences(ctx context.Context, userID int) (result0 models.Preferences, result1 error) {
+	result1 = r.call(ctx, "GetPreferences", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetPreferences(ctx, userID)
+		return result1
+	})
+	return result0, result1
+}
+
+// SavePreferences calls the wrapped Repository's SavePreferences, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SavePreferences(ctx context.Context, userID int, preferences models.Preferences) (result0 error) {
+	result0 = r.call(ctx, "SavePreferences", func(ctx context.Context) error {
+		return r.next.SavePreferences(ctx, userID, preferences)
+	})
+	return result0
+}
+
+// GetSongOverride calls the wrapped Repository's GetSongOverride, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongOverride(ctx context.Context, userID int, songID int) (result0 models.SongOverride, result1 error) {
+	result1 = r.call(ctx, "GetSongOverride", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongOverride(ctx, userID, songID)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongOverrides calls the wrapped Repository's GetSongOverrides, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongOverrides(ctx context.Context, userID int, songIDs []int) (result0 []models.SongOverride, result1 error) {
+	result1 = r.call(ctx, "GetSongOverrides", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongOverrides(ctx, userID, songIDs)
+		return result1
+	})
+	return result0, result1
+}
+
+// SaveSongOverride calls the wrapped Repository's SaveSongOverride, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SaveSongOverride(ctx context.Context, userID int, songID int, text string) (result0 models.SongOverride, result1 error) {
+	result1 = r.call(ctx, "SaveSongOverride", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.SaveSongOverride(ctx, userID, songID, text)
+		return result1
+	})
+	return result0, result1
+}
+
+// DeleteSongOverride calls the wrapped Repository's DeleteSongOverride, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) DeleteSongOverride(ctx context.Context, userID int, songID int) (result0 error) {
+	result0 = r.call(ctx, "DeleteSongOverride", func(ctx context.Context) error {
+		return r.next.DeleteSongOverride(ctx, userID, songID)
+	})
+	return result0
+}
+
+// GetSongRating calls the wrapped Repository's GetSongRating, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongRating(ctx context.Context, userID int, songID int) (result0 models.SongRating, result1 error) {
+	result1 = r.call(ctx, "GetSongRating", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongRating(ctx, userID, songID)
+		return result1
+	})
+	return result0, result1
+}
+
+// RateSong calls the wrapped Repository's RateSong, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) RateSong(ctx context.Context, userID int, songID int, rating int) (result0 error) {
+	result0 = r.call(ctx, "RateSong", func(ctx context.Context) error {
+		return r.next.RateSong(ctx, userID, songID, rating)
+	})
+	return result0
+}
+
+// DeleteSongRating calls the wrapped Repository's DeleteSongRating, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) DeleteSongRating(ctx context.Context, userID int, songID int) (result0 error) {
+	result0 = r.call(ctx, "DeleteSongRating", func(ctx context.Context) error {
+		return r.next.DeleteSongRating(ctx, userID, songID)
+	})
+	return result0
+}
+
+// FavoriteSong calls the wrapped Repository's FavoriteSong, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) FavoriteSong(ctx context.Context, userID int, songID int) (result0 models.SongFavorite, result1 error) {
+	result1 = r.call(ctx, "FavoriteSong", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.FavoriteSong(ctx, userID, songID)
+		return result1
+	})
+	return result0, result1
+}
+
+// UnfavoriteSong calls the wrapped Repository's UnfavoriteSong, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) UnfavoriteSong(ctx context.Context, userID int, songID int) (result0 error) {
+	result0 = r.call(ctx, "UnfavoriteSong", func(ctx context.Context) error {
+		return r.next.UnfavoriteSong(ctx, userID, songID)
+	})
+	return result0
+}
+
+// AddSongRevision calls the wrapped Repository's AddSongRevision, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) AddSongRevision(ctx context.Context, songID int, reason string, restoredFrom *int) (result0 int, result1 error) {
+	result1 = r.call(ctx, "AddSongRevision", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.AddSongRevision(ctx, songID, reason, restoredFrom)
+		return result1
+	})
+	return result0, result1
+}
+
+// CountSongRevisions calls the wrapped Repository's CountSongRevisions, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CountSongRevisions(ctx context.Context, songID int) (result0 int, result1 error) {
+	result1 = r.call(ctx, "CountSongRevisions", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CountSongRevisions(ctx, songID)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongRevisions calls the wrapped Repository's GetSongRevisions, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongRevisions(ctx context.Context, songID int) (result0 []models.SongRevision, result1 error) {
+	result1 = r.call(ctx, "GetSongRevisions", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongRevisions(ctx, songID)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongRevision calls the wrapped Repository's GetSongRevision, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongRevision(ctx context.Context, songID int, revision int) (result0 models.SongRevision, result1 error) {
+	result1 = r.call(ctx, "GetSongRevision", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongRevision(ctx, songID, revision)
+		return result1
+	})
+	return result0, result1
+}
+
+// TrashSong calls the wrapped Repository's TrashSong, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) TrashSong(ctx context.Context, id int) (result0 error) {
+	result0 = r.call(ctx, "TrashSong", func(ctx context.Context) error {
+		return r.next.TrashSong(ctx, id)
+	})
+	return result0
+}
+
+// GetTrashedSong calls the wrapped Repository's GetTrashedSong, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetTrashedSong(ctx context.Context, id int) (result0 models.TrashedSong, result1 error) {
+	result1 = r.call(ctx, "GetTrashedSong", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetTrashedSong(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetTrash calls the wrapped Repository's GetTrash, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetTrash(ctx context.Context, page int, limit int) (result0 []models.TrashedSong, result1 int, result2 error) {
+	result2 = r.call(ctx, "GetTrash", func(ctx context.Context) error {
+		var result2 error
+		result0, result1, result2 = r.next.GetTrash(ctx, page, limit)
+		return result2
+	})
+	return result0, result1, result2
+}
+
+// RestoreTrashedSong calls the wrapped Repository's RestoreTrashedSong, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) RestoreTrashedSong(ctx context.Context, id int) (result0 error) {
+	result0 = r.call(ctx, "RestoreTrashedSong", func(ctx context.Context) error {
+		return r.next.RestoreTrashedSong(ctx, id)
+	})
+	return result0
+}
+
+// DeleteTrashedSong calls the wrapped Repository's DeleteTrashedSong, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) DeleteTrashedSong(ctx context.Context, id int) (result0 error) {
+	result0 = r.call(ctx, "DeleteTrashedSong", func(ctx context.Context) error {
+		return r.next.DeleteTrashedSong(ctx, id)
+	})
+	return result0
+}
+
+// PurgeTrash calls the wrapped Repository's PurgeTrash, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) PurgeTrash(ctx context.Context, before time.Time) (result0 int64, result1 error) {
+	result1 = r.call(ctx, "PurgeTrash", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.PurgeTrash(ctx, before)
+		return result1
+	})
+	return result0, result1
+}
+
+// CreateAlbum calls the wrapped Repository's CreateAlbum, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CreateAlbum(ctx context.Context, album models.AlbumInput) (result0 int, result1 error) {
+	result1 = r.call(ctx, "CreateAlbum", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CreateAlbum(ctx, album)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetAlbum calls the wrapped Repository's GetAlbum, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetAlbum(ctx context.Context, id int) (result0 models.Album, result1 error) {
+	result1 = r.call(ctx, "GetAlbum", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetAlbum(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetAlbums calls the wrapped Repository's GetAlbums, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetAlbums(ctx context.Context, page int, limit int) (result0 []models.Album, result1 int, result2 error) {
+	result2 = r.call(ctx, "GetAlbums", func(ctx context.Context) error {
+		var result2 error
+		result0, result1, result2 = r.next.GetAlbums(ctx, page, limit)
+		return result2
+	})
+	return result0, result1, result2
+}
+
+// UpdateAlbum calls the wrapped Repository's UpdateAlbum, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) UpdateAlbum(ctx context.Context, id int, album models.AlbumInput) (result0 error) {
+	result0 = r.call(ctx, "UpdateAlbum", func(ctx context.Context) error {
+		return r.next.UpdateAlbum(ctx, id, album)
+	})
+	return result0
+}
+
+// DeleteAlbum calls the wrapped Repository's DeleteAlbum, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) DeleteAlbum(ctx context.Context, id int) (result0 error) {
+	result0 = r.call(ctx, "DeleteAlbum", func(ctx context.Context) error {
+		return r.next.DeleteAlbum(ctx, id)
+	})
+	return result0
+}
+
+// GetAlbumSongs calls the wrapped Repository's GetAlbumSongs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetAlbumSongs(ctx context.Context, albumID int, page int, limit int) (result0 []models.Song, result1 int, result2 error) {
+	result2 = r.call(ctx, "GetAlbumSongs", func(ctx context.Context) error {
+		var result2 error
+		result0, result1, result2 = r.next.GetAlbumSongs(ctx, albumID, page, limit)
+		return result2
+	})
+	return result0, result1, result2
+}
+
+// CreateArtist calls the wrapped Repository's CreateArtist, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CreateArtist(ctx context.Context, artist models.ArtistInput) (result0 int, result1 error) {
+	result1 = r.call(ctx, "CreateArtist", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CreateArtist(ctx, artist)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetArtist calls the wrapped Repository's GetArtist, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetArtist(ctx context.Context, id int) (result0 models.Artist, result1 error) {
+	result1 = r.call(ctx, "GetArtist", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetArtist(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetArtistByName calls the wrapped Repository's GetArtistByName, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetArtistByName(ctx context.Context, name string) (result0 models.Artist, result1 error) {
+	result1 = r.call(ctx, "GetArtistByName", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetArtistByName(ctx, name)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetArtists calls the wrapped Repository's GetArtists, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetArtists(ctx context.Context, page int, limit int) (result0 []models.Artist, result1 int, result2 error) {
+	result2 = r.call(ctx, "GetArtists", func(ctx context.Context) error {
+		var result2 error
+		result0, result1, result2 = r.next.GetArtists(ctx, page, limit)
+		return result2
+	})
+	return result0, result1, result2
+}
+
+// UpdateArtist calls the wrapped Repository's UpdateArtist, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) UpdateArtist(ctx context.Context, id int, artist models.ArtistInput) (result0 []int, result1 error) {
+	result1 = r.call(ctx, "UpdateArtist", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.UpdateArtist(ctx, id, artist)
+		return result1
+	})
+	return result0, result1
+}
+
+// DeleteArtist calls the wrapped Repository's DeleteArtist, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) DeleteArtist(ctx context.Context, id int) (result0 error) {
+	result0 = r.call(ctx, "DeleteArtist", func(ctx context.Context) error {
+		return r.next.DeleteArtist(ctx, id)
+	})
+	return result0
+}
+
+// GetArtistSongs calls the wrapped Repository's GetArtistSongs, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetArtistSongs(ctx context.Context, artistID int, page int, limit int) (result0 []models.Song, result1 int, result2 error) {
+	result2 = r.call(ctx, "GetArtistSongs", func(ctx context.Context) error {
+		var result2 error
+		result0, result1, result2 = r.next.GetArtistSongs(ctx, artistID, page, limit)
+		return result2
+	})
+	return result0, result1, result2
+}
+
+// CreateGenre calls the wrapped Repository's CreateGenre, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CreateGenre(ctx context.Context, genre models.GenreInput) (result0 int, result1 error) {
+	result1 = r.call(ctx, "CreateGenre", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CreateGenre(ctx, genre)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetGenre calls the wrapped Repository's GetGenre, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetGenre(ctx context.Context, id int) (result0 models.Genre, result1 error) {
+	result1 = r.call(ctx, "GetGenre", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetGenre(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetGenreByName calls the wrapped Repository's GetGenreByName, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetGenreByName(ctx context.Context, name string) (result0 models.Genre, result1 error) {
+	result1 = r.call(ctx, "GetGenreByName", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetGenreByName(ctx, name)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetGenres calls the wrapped Repository's GetGenres, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetGenres(ctx context.Context) (result0 []models.Genre, result1 error) {
+	result1 = r.call(ctx, "GetGenres", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetGenres(ctx)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetGenreSubtree calls the wrapped Repository's GetGenreSubtree, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetGenreSubtree(ctx context.Context, id int) (result0 []int, result1 error) {
+	result1 = r.call(ctx, "GetGenreSubtree", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetGenreSubtree(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// UpdateGenre calls the wrapped Repository's UpdateGenre, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) UpdateGenre(ctx context.Context, id int, genre models.GenreInput) (result0 error) {
+	result0 = r.call(ctx, "UpdateGenre", func(ctx context.Context) error {
+		return r.next.UpdateGenre(ctx, id, genre)
+	})
+	return result0
+}
+
+// DeleteGenre calls the wrapped Repository's DeleteGenre, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) DeleteGenre(ctx context.Context, id int) (result0 error) {
+	result0 = r.call(ctx, "DeleteGenre", func(ctx context.Context) error {
+		return r.next.DeleteGenre(ctx, id)
+	})
+	return result0
+}
+
+// CountSubgenres calls the wrapped Repository's CountSubgenres, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) CountSubgenres(ctx context.Context, id int) (result0 int, result1 error) {
+	result1 = r.call(ctx, "CountSubgenres", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.CountSubgenres(ctx, id)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongGenres calls the wrapped Repository's GetSongGenres, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongGenres(ctx context.Context, songID int) (result0 []models.Genre, result1 error) {
+	result1 = r.call(ctx, "GetSongGenres", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongGenres(ctx, songID)
+		return result1
+	})
+	return result0, result1
+}
+
+// SetSongGenres calls the wrapped Repository's SetSongGenres, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SetSongGenres(ctx context.Context, songID int, genreIDs []int) (result0 error) {
+	result0 = r.call(ctx, "SetSongGenres", func(ctx context.Context) error {
+		return r.next.SetSongGenres(ctx, songID, genreIDs)
+	})
+	return result0
+}
+
+// GetSongTitles calls the wrapped Repository's GetSongTitles, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongTitles(ctx context.Context, songID int) (result0 []models.SongTitle, result1 error) {
+	result1 = r.call(ctx, "GetSongTitles", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongTitles(ctx, songID)
+		return result1
+	})
+	return result0, result1
+}
+
+// GetSongTitlesIn calls the wrapped Repository's GetSongTitlesIn, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) GetSongTitlesIn(ctx context.Context, songIDs []int, langs []string) (result0 []models.SongTitle, result1 error) {
+	result1 = r.call(ctx, "GetSongTitlesIn", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.GetSongTitlesIn(ctx, songIDs, langs)
+		return result1
+	})
+	return result0, result1
+}
+
+// SetSongTitle calls the wrapped Repository's SetSongTitle, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) SetSongTitle(ctx context.Context, songID int, lang string, title models.SongTitleInput) (result0 models.SongTitle, result1 error) {
+	result1 = r.call(ctx, "SetSongTitle", func(ctx context.Context) error {
+		var result1 error
+		result0, result1 = r.next.SetSongTitle(ctx, songID, lang, title)
+		return result1
+	})
+	return result0, result1
+}
+
+// DeleteSongTitle calls the wrapped Repository's DeleteSongTitle, instrumented and retried on serialization failures
+func (r *InstrumentedRepository) DeleteSongTitle(ctx context.Context, songID int, lang string) (result0 error) {
+	result0 = r.call(ctx, "DeleteSongTitle", func(ctx context.Context) error {
+		return r.next.DeleteSongTitle(ctx, songID, lang)
+	})
+	return result0
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"music-library/internal/models"
+)
+
+// DefaultIteratorBatchSize is the number of songs a SongIterator loads at a time when not told otherwise
+const DefaultIteratorBatchSize = 500
+
+// SongIterator walks every song matching a filter in ID order. It keeps a cursor on the last ID seen and loads
+// one batch at a time, so memory use does not depend on the catalog size and no statement outlives a batch.
+type SongIterator struct {
+	repo      Repository
+	filter    models.SongFilter
+	batchSize int
+
+	batch   []models.Song
+	pos     int
+	afterID int
+	done    bool
+	err     error
+}
+
+// NewSongIterator creates an iterator over the songs matching the filter; a batch size below 1 takes the default
+func NewSongIterator(repo Repository, filter models.SongFilter, batchSize int) *SongIterator {
+	if batchSize < 1 {
+		batchSize = DefaultIteratorBatchSize
+	}
+	return &SongIterator{repo: repo, filter: filter, batchSize: batchSize, pos: -1}
+}
+
+// Next advances to the next song, loading the following batch when the current one is exhausted.
+// It returns false once every song has been seen or a batch fails to load, which Err then reports.
+func (it *SongIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	it.pos++
+	if it.pos < len(it.batch) {
+		return true
+	}
+	if it.done {
+		return false
+	}
+	batch, err := it.repo.GetSongsAfter(ctx, it.filter, it.afterID, it.batchSize)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	// A short batch is the last one, which saves a query returning nothing
+	it.done = len(batch) < it.batchSize
+	it.batch, it.pos = batch, 0
+	if len(batch) == 0 {
+		return false
+	}
+	it.afterID = batch[len(batch)-1].ID
+	return true
+}
+
+// Song returns the current song
+func (it *SongIterator) Song() models.Song {
+	return it.batch[it.pos]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *SongIterator) Err() error {
+	return it.err
+}
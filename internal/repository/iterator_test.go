@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"music-library/internal/models"
+)
+
+// pagedRepository serves GetSongsAfter from a fixed catalog, failing once the failAfter ID is passed
+type pagedRepository struct {
+	Repository
+	ids       []int
+	failAfter int
+	queries   int
+}
+
+func (p *pagedRepository) GetSongsAfter(ctx context.Context, filter models.SongFilter, afterID, limit int) ([]models.Song, error) {
+	p.queries++
+	if p.failAfter != 0 && afterID >= p.failAfter {
+		return nil, errors.New("connection reset")
+	}
+	var songs []models.Song
+	for _, id := range p.ids {
+		if id > afterID && len(songs) < limit {
+			songs = append(songs, models.Song{ID: id})
+		}
+	}
+	return songs, nil
+}
+
+func TestSongIterator(t *testing.T) {
+	repo := &pagedRepository{ids: []int{1, 2, 5, 8, 9}}
+	it := NewSongIterator(repo, models.SongFilter{}, 2)
+	var ids []int
+	for it.Next(context.Background()) {
+		ids = append(ids, it.Song().ID)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []int{1, 2, 5, 8, 9}, ids)
+	assert.Equal(t, 3, repo.queries, "the short last batch ends the iteration")
+
+	repo = &pagedRepository{ids: []int{1, 2, 5, 8, 9}, failAfter: 2}
+	it = NewSongIterator(repo, models.SongFilter{}, 2)
+	ids = nil
+	for it.Next(context.Background()) {
+		ids = append(ids, it.Song().ID)
+	}
+	assert.EqualError(t, it.Err(), "connection reset")
+	assert.Equal(t, []int{1, 2}, ids)
+	assert.False(t, it.Next(context.Background()))
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// CreateJob registers a queued job, owned by the user with the ID unless it is nil
+func (r *PostgresRepository) CreateJob(ctx context.Context, id, kind string, ownerID *int) (models.Job, error) {
+	r.logger.Debug("Creating job", zap.String("job_id", id), zap.String("kind", kind))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "INSERT INTO jobs (id, kind, owner_id) VALUES ($1, $2, $3) RETURNING *"
+	var job models.Job
+	start := time.Now()
+	err := r.db.GetContext(ctx, &job, query, id, kind, ownerID)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to create job", zap.Error(err))
+		return job, err
+	}
+	return job, nil
+}
+
+// GetJob retrieves a job and its progress
+func (r *PostgresRepository) GetJob(ctx context.Context, id string) (models.Job, error) {
+	r.logger.Debug("Fetching job", zap.String("job_id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT * FROM jobs WHERE id = $1"
+	var job models.Job
+	start := time.Now()
+	err := r.db.GetContext(ctx, &job, query, id)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch job", zap.String("job_id", id), zap.Error(err))
+	}
+	return job, err
+}
+
+// StartJob marks a queued job running
+func (r *PostgresRepository) StartJob(ctx context.Context, id string) error {
+	query := `UPDATE jobs SET status = $2, started_at = NOW(), updated_at = NOW() WHERE id = $1`
+	return r.execJob(ctx, id, query, id, models.JobRunning)
+}
+
+// UpdateJobProgress stores the progress counters of a running job
+func (r *PostgresRepository) UpdateJobProgress(ctx context.Context, id string, total, processed, failed int) error {
+	query := `UPDATE jobs SET total = $2, processed = $3, failed = $4, updated_at = NOW() WHERE id = $1`
+	return r.execJob(ctx, id, query, id, total, processed, failed)
+}
+
+// FinishJob stores the final status, counters and outcome of a job. An empty result or message is stored as NULL.
+func (r *PostgresRepository) FinishJob(ctx context.Context, id, status string, total, processed, failed int, result []byte, message string) error {
+	query := `UPDATE jobs SET status = $2, total = $3, processed = $4, failed = $5, result = $6, error = NULLIF($7, ''),
+		finished_at = NOW(), updated_at = NOW() WHERE id = $1`
+	var resultArg any
+	if len(result) > 0 {
+		resultArg = string(result)
+	}
+	return r.execJob(ctx, id, query, id, status, total, processed, failed, resultArg, message)
+}
+
+// FailUnfinishedJobs marks failed the jobs left queued or running, whose work was lost when the process stopped
+func (r *PostgresRepository) FailUnfinishedJobs(ctx context.Context, message string) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `UPDATE jobs SET status = $1, error = $2, finished_at = NOW(), updated_at = NOW()
+		WHERE status IN ($3, $4)`
+	start := time.Now()
+	result, err := r.db.ExecContext(ctx, query, models.JobFailed, message, models.JobQueued, models.JobRunning)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to fail unfinished jobs", zap.Error(err))
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	return rows, err
+}
+
+// execJob runs an update of a single job, returning sql.ErrNoRows when it does not exist
+func (r *PostgresRepository) execJob(ctx context.Context, id, query string, args ...any) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to update job", zap.String("job_id", id), zap.Error(err))
+		return err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// SetLegalHold sets or releases the legal hold of a song, returning sql.ErrNoRows when it does not exist
+func (r *PostgresRepository) SetLegalHold(ctx context.Context, id int, held bool) error {
+	r.logger.Debug("Setting legal hold", zap.Int("id", id), zap.Bool("held", held))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "UPDATE songs SET legal_hold = $2 WHERE id = $1"
+	start := time.Now()
+	result, err := r.db.ExecContext(ctx, query, id, held)
+	var rows int64
+	if err == nil {
+		rows, err = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to set legal hold", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetLegalHolds returns the IDs of the given songs that are on legal hold. Within a transaction every one of
+// the songs, held or not, stays locked until it ends, so no hold can be set before the writes it guards.
+func (r *PostgresRepository) GetLegalHolds(ctx context.Context, ids []int) ([]int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT id, legal_hold FROM songs WHERE id = ANY($1) ORDER BY id"
+	if inTransaction(ctx) {
+		query += " FOR UPDATE"
+	}
+	var songs []struct {
+		ID   int  `db:"id"`
+		Held bool `db:"legal_hold"`
+	}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &songs, query, pq.Array(ids))
+	r.track(query, start, int64(len(songs)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch legal holds", zap.Error(err))
+		return nil, err
+	}
+	held := []int{}
+	for _, song := range songs {
+		if song.Held {
+			held = append(held, song.ID)
+		}
+	}
+	return held, nil
+}
+
+// CountLegalHolds returns the number of songs on legal hold. Within a transaction the songs table is first
+// locked against writes until it ends, so no hold can be set before the truncation the count guards.
+func (r *PostgresRepository) CountLegalHolds(ctx context.Context) (int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	if inTransaction(ctx) {
+		// SHARE ROW EXCLUSIVE blocks the updates setting holds and, unlike SHARE, concurrent truncations too
+		lock := "LOCK TABLE songs IN SHARE ROW EXCLUSIVE MODE"
+		start := time.Now()
+		_, err := r.conn(ctx).ExecContext(ctx, lock)
+		r.track(lock, start, 0, err)
+		if err != nil {
+			r.logger.Error("Failed to lock songs", zap.Error(err))
+			return 0, err
+		}
+	}
+	query := "SELECT COUNT(*) FROM songs WHERE legal_hold"
+	var count int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &count, query)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to count legal holds", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
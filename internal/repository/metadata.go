@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// GetSongsNeedingMetadata retrieves up to limit songs whose track metadata was never synced, skipping songs on
+// legal hold
+func (r *PostgresRepository) GetSongsNeedingMetadata(ctx context.Context, exclude []int, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs needing track metadata", zap.Int("limit", limit))
+	where := "s.metadata_synced_at IS NULL AND NOT s.legal_hold"
+	var args []any
+	if len(exclude) > 0 {
+		args = append(args, pq.Array(exclude))
+		where += fmt.Sprintf(" AND s.id <> ALL($%d)", len(args))
+	}
+	songs, err := r.songs.List(ctx, where, args, "s.id", 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs needing track metadata", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// SaveSongMetadata stores the track metadata of the song and marks it synced. Unknown fields keep
+// their current value, so a lookup that found nothing only marks the song.
+func (r *PostgresRepository) SaveSongMetadata(ctx context.Context, id int, metadata models.TrackMetadata) error {
+	r.logger.Debug("Saving track metadata", zap.Int("id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `
+		UPDATE songs SET album = COALESCE($2, album), duration_ms = COALESCE($3, duration_ms),
+			isrc = COALESCE($4, isrc), artwork_url = COALESCE($5, artwork_url), metadata_synced_at = NOW()
+		WHERE id = $1`
+	start := time.Now()
+	_, err := r.db.ExecContext(ctx, query, id, metadata.Album, metadata.DurationMs, metadata.ISRC, metadata.ArtworkURL)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to save track metadata", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
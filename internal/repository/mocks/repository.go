@@ -0,0 +1,2200 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "music-library/internal/models"
+	repository "music-library/internal/repository"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddAPICapture mocks base method.
+func (m *MockRepository) AddAPICapture(ctx context.Context, capture models.APICapture, keep int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAPICapture", ctx, capture, keep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAPICapture indicates an expected call of AddAPICapture.
+func (mr *MockRepositoryMockRecorder) AddAPICapture(ctx, capture, keep interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAPICapture", reflect.TypeOf((*MockRepository)(nil).AddAPICapture), ctx, capture, keep)
+}
+
+// AddClassificationSuggestions mocks base method.
+func (m *MockRepository) AddClassificationSuggestions(ctx context.Context, songID int, source string, suggestions []models.ClassificationSuggestion) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddClassificationSuggestions", ctx, songID, source, suggestions)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddClassificationSuggestions indicates an expected call of AddClassificationSuggestions.
+func (mr *MockRepositoryMockRecorder) AddClassificationSuggestions(ctx, songID, source, suggestions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddClassificationSuggestions", reflect.TypeOf((*MockRepository)(nil).AddClassificationSuggestions), ctx, songID, source, suggestions)
+}
+
+// AddPendingSong mocks base method.
+func (m *MockRepository) AddPendingSong(ctx context.Context, group, song string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPendingSong", ctx, group, song)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPendingSong indicates an expected call of AddPendingSong.
+func (mr *MockRepositoryMockRecorder) AddPendingSong(ctx, group, song interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPendingSong", reflect.TypeOf((*MockRepository)(nil).AddPendingSong), ctx, group, song)
+}
+
+// AddSong mocks base method.
+func (m *MockRepository) AddSong(ctx context.Context, group, song, releaseDate, text, link string, enrichedAt *time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSong", ctx, group, song, releaseDate, text, link, enrichedAt)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSong indicates an expected call of AddSong.
+func (mr *MockRepositoryMockRecorder) AddSong(ctx, group, song, releaseDate, text, link, enrichedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSong", reflect.TypeOf((*MockRepository)(nil).AddSong), ctx, group, song, releaseDate, text, link, enrichedAt)
+}
+
+// AddSongEvent mocks base method.
+func (m *MockRepository) AddSongEvent(ctx context.Context, event models.SongEvent) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSongEvent", ctx, event)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSongEvent indicates an expected call of AddSongEvent.
+func (mr *MockRepositoryMockRecorder) AddSongEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSongEvent", reflect.TypeOf((*MockRepository)(nil).AddSongEvent), ctx, event)
+}
+
+// AddSongRevision mocks base method.
+func (m *MockRepository) AddSongRevision(ctx context.Context, songID int, reason string, restoredFrom *int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSongRevision", ctx, songID, reason, restoredFrom)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSongRevision indicates an expected call of AddSongRevision.
+func (mr *MockRepositoryMockRecorder) AddSongRevision(ctx, songID, reason, restoredFrom interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSongRevision", reflect.TypeOf((*MockRepository)(nil).AddSongRevision), ctx, songID, reason, restoredFrom)
+}
+
+// AddSongs mocks base method.
+func (m *MockRepository) AddSongs(ctx context.Context, songs []models.SongInput) ([]int, []error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSongs", ctx, songs)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].([]error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AddSongs indicates an expected call of AddSongs.
+func (mr *MockRepositoryMockRecorder) AddSongs(ctx, songs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSongs", reflect.TypeOf((*MockRepository)(nil).AddSongs), ctx, songs)
+}
+
+// BackfillLegacyRows mocks base method.
+func (m *MockRepository) BackfillLegacyRows(ctx context.Context, dryRun bool) (models.BackfillReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackfillLegacyRows", ctx, dryRun)
+	ret0, _ := ret[0].(models.BackfillReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackfillLegacyRows indicates an expected call of BackfillLegacyRows.
+func (mr *MockRepositoryMockRecorder) BackfillLegacyRows(ctx, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackfillLegacyRows", reflect.TypeOf((*MockRepository)(nil).BackfillLegacyRows), ctx, dryRun)
+}
+
+// BulkTagSongs mocks base method.
+func (m *MockRepository) BulkTagSongs(ctx context.Context, ids []int, filter models.SongFilter, add, remove []string) (models.BulkTagResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkTagSongs", ctx, ids, filter, add, remove)
+	ret0, _ := ret[0].(models.BulkTagResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkTagSongs indicates an expected call of BulkTagSongs.
+func (mr *MockRepositoryMockRecorder) BulkTagSongs(ctx, ids, filter, add, remove interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkTagSongs", reflect.TypeOf((*MockRepository)(nil).BulkTagSongs), ctx, ids, filter, add, remove)
+}
+
+// ClaimSongEvents mocks base method.
+func (m *MockRepository) ClaimSongEvents(ctx context.Context, limit int) ([]models.SongEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimSongEvents", ctx, limit)
+	ret0, _ := ret[0].([]models.SongEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimSongEvents indicates an expected call of ClaimSongEvents.
+func (mr *MockRepositoryMockRecorder) ClaimSongEvents(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimSongEvents", reflect.TypeOf((*MockRepository)(nil).ClaimSongEvents), ctx, limit)
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration, exclude []int) ([]models.DueDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", ctx, limit, lease, exclude)
+	ret0, _ := ret[0].([]models.DueDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) ClaimWebhookDeliveries(ctx, limit, lease, exclude interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).ClaimWebhookDeliveries), ctx, limit, lease, exclude)
+}
+
+// CompleteEnrichment mocks base method.
+func (m *MockRepository) CompleteEnrichment(ctx context.Context, id int, releaseDate, text, link string, enrichedAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteEnrichment", ctx, id, releaseDate, text, link, enrichedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteEnrichment indicates an expected call of CompleteEnrichment.
+func (mr *MockRepositoryMockRecorder) CompleteEnrichment(ctx, id, releaseDate, text, link, enrichedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteEnrichment", reflect.TypeOf((*MockRepository)(nil).CompleteEnrichment), ctx, id, releaseDate, text, link, enrichedAt)
+}
+
+// ConfigurePopularity mocks base method.
+func (m *MockRepository) ConfigurePopularity(provider repository.PopularityProvider) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ConfigurePopularity", provider)
+}
+
+// ConfigurePopularity indicates an expected call of ConfigurePopularity.
+func (mr *MockRepositoryMockRecorder) ConfigurePopularity(provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigurePopularity", reflect.TypeOf((*MockRepository)(nil).ConfigurePopularity), provider)
+}
+
+// ConfigureStatementTimeout mocks base method.
+func (m *MockRepository) ConfigureStatementTimeout(timeout time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ConfigureStatementTimeout", timeout)
+}
+
+// ConfigureStatementTimeout indicates an expected call of ConfigureStatementTimeout.
+func (mr *MockRepositoryMockRecorder) ConfigureStatementTimeout(timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureStatementTimeout", reflect.TypeOf((*MockRepository)(nil).ConfigureStatementTimeout), timeout)
+}
+
+// CopySongs mocks base method.
+func (m *MockRepository) CopySongs(ctx context.Context, songs []models.SongInput) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopySongs", ctx, songs)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopySongs indicates an expected call of CopySongs.
+func (mr *MockRepositoryMockRecorder) CopySongs(ctx, songs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopySongs", reflect.TypeOf((*MockRepository)(nil).CopySongs), ctx, songs)
+}
+
+// CountLegalHolds mocks base method.
+func (m *MockRepository) CountLegalHolds(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountLegalHolds", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountLegalHolds indicates an expected call of CountLegalHolds.
+func (mr *MockRepositoryMockRecorder) CountLegalHolds(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountLegalHolds", reflect.TypeOf((*MockRepository)(nil).CountLegalHolds), ctx)
+}
+
+// CountSongRevisions mocks base method.
+func (m *MockRepository) CountSongRevisions(ctx context.Context, songID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSongRevisions", ctx, songID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSongRevisions indicates an expected call of CountSongRevisions.
+func (mr *MockRepositoryMockRecorder) CountSongRevisions(ctx, songID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSongRevisions", reflect.TypeOf((*MockRepository)(nil).CountSongRevisions), ctx, songID)
+}
+
+// CountSongs mocks base method.
+func (m *MockRepository) CountSongs(ctx context.Context, filter models.SongFilter) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSongs", ctx, filter)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSongs indicates an expected call of CountSongs.
+func (mr *MockRepositoryMockRecorder) CountSongs(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSongs", reflect.TypeOf((*MockRepository)(nil).CountSongs), ctx, filter)
+}
+
+// CountSubgenres mocks base method.
+func (m *MockRepository) CountSubgenres(ctx context.Context, id int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSubgenres", ctx, id)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSubgenres indicates an expected call of CountSubgenres.
+func (mr *MockRepositoryMockRecorder) CountSubgenres(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSubgenres", reflect.TypeOf((*MockRepository)(nil).CountSubgenres), ctx, id)
+}
+
+// CreateAlbum mocks base method.
+func (m *MockRepository) CreateAlbum(ctx context.Context, album models.AlbumInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAlbum", ctx, album)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAlbum indicates an expected call of CreateAlbum.
+func (mr *MockRepositoryMockRecorder) CreateAlbum(ctx, album interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAlbum", reflect.TypeOf((*MockRepository)(nil).CreateAlbum), ctx, album)
+}
+
+// CreateArtist mocks base method.
+func (m *MockRepository) CreateArtist(ctx context.Context, artist models.ArtistInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateArtist", ctx, artist)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateArtist indicates an expected call of CreateArtist.
+func (mr *MockRepositoryMockRecorder) CreateArtist(ctx, artist interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateArtist", reflect.TypeOf((*MockRepository)(nil).CreateArtist), ctx, artist)
+}
+
+// CreateGenre mocks base method.
+func (m *MockRepository) CreateGenre(ctx context.Context, genre models.GenreInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGenre", ctx, genre)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGenre indicates an expected call of CreateGenre.
+func (mr *MockRepositoryMockRecorder) CreateGenre(ctx, genre interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGenre", reflect.TypeOf((*MockRepository)(nil).CreateGenre), ctx, genre)
+}
+
+// CreateImport mocks base method.
+func (m *MockRepository) CreateImport(ctx context.Context, id string) (models.Import, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImport", ctx, id)
+	ret0, _ := ret[0].(models.Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateImport indicates an expected call of CreateImport.
+func (mr *MockRepositoryMockRecorder) CreateImport(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImport", reflect.TypeOf((*MockRepository)(nil).CreateImport), ctx, id)
+}
+
+// CreateJob mocks base method.
+func (m *MockRepository) CreateJob(ctx context.Context, id, kind string, ownerID *int) (models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", ctx, id, kind, ownerID)
+	ret0, _ := ret[0].(models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockRepositoryMockRecorder) CreateJob(ctx, id, kind, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockRepository)(nil).CreateJob), ctx, id, kind, ownerID)
+}
+
+// CreateSnapshot mocks base method.
+func (m *MockRepository) CreateSnapshot(ctx context.Context, reason string) (models.Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSnapshot", ctx, reason)
+	ret0, _ := ret[0].(models.Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSnapshot indicates an expected call of CreateSnapshot.
+func (mr *MockRepositoryMockRecorder) CreateSnapshot(ctx, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSnapshot", reflect.TypeOf((*MockRepository)(nil).CreateSnapshot), ctx, reason)
+}
+
+// CreateUser mocks base method.
+func (m *MockRepository) CreateUser(ctx context.Context, username, passwordHash, role string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, username, passwordHash, role)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockRepositoryMockRecorder) CreateUser(ctx, username, passwordHash, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockRepository)(nil).CreateUser), ctx, username, passwordHash, role)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockRepository) CreateWebhook(ctx context.Context, url string, events []string, secret string) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, url, events, secret)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockRepositoryMockRecorder) CreateWebhook(ctx, url, events, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockRepository)(nil).CreateWebhook), ctx, url, events, secret)
+}
+
+// DeferWebhookDelivery mocks base method.
+func (m *MockRepository) DeferWebhookDelivery(ctx context.Context, id int64, after time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeferWebhookDelivery", ctx, id, after)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeferWebhookDelivery indicates an expected call of DeferWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) DeferWebhookDelivery(ctx, id, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeferWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).DeferWebhookDelivery), ctx, id, after)
+}
+
+// DeleteAlbum mocks base method.
+func (m *MockRepository) DeleteAlbum(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAlbum", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAlbum indicates an expected call of DeleteAlbum.
+func (mr *MockRepositoryMockRecorder) DeleteAlbum(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAlbum", reflect.TypeOf((*MockRepository)(nil).DeleteAlbum), ctx, id)
+}
+
+// DeleteArtist mocks base method.
+func (m *MockRepository) DeleteArtist(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteArtist", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteArtist indicates an expected call of DeleteArtist.
+func (mr *MockRepositoryMockRecorder) DeleteArtist(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteArtist", reflect.TypeOf((*MockRepository)(nil).DeleteArtist), ctx, id)
+}
+
+// DeleteGenre mocks base method.
+func (m *MockRepository) DeleteGenre(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGenre", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGenre indicates an expected call of DeleteGenre.
+func (mr *MockRepositoryMockRecorder) DeleteGenre(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGenre", reflect.TypeOf((*MockRepository)(nil).DeleteGenre), ctx, id)
+}
+
+// DeleteSong mocks base method.
+func (m *MockRepository) DeleteSong(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSong", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSong indicates an expected call of DeleteSong.
+func (mr *MockRepositoryMockRecorder) DeleteSong(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSong", reflect.TypeOf((*MockRepository)(nil).DeleteSong), ctx, id)
+}
+
+// DeleteSongEventsBefore mocks base method.
+func (m *MockRepository) DeleteSongEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSongEventsBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSongEventsBefore indicates an expected call of DeleteSongEventsBefore.
+func (mr *MockRepositoryMockRecorder) DeleteSongEventsBefore(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSongEventsBefore", reflect.TypeOf((*MockRepository)(nil).DeleteSongEventsBefore), ctx, before)
+}
+
+// DeleteSongOverride mocks base method.
+func (m *MockRepository) DeleteSongOverride(ctx context.Context, userID, songID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSongOverride", ctx, userID, songID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSongOverride indicates an expected call of DeleteSongOverride.
+func (mr *MockRepositoryMockRecorder) DeleteSongOverride(ctx, userID, songID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSongOverride", reflect.TypeOf((*MockRepository)(nil).DeleteSongOverride), ctx, userID, songID)
+}
+
+// DeleteSongRating mocks base method.
+func (m *MockRepository) DeleteSongRating(ctx context.Context, userID, songID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSongRating", ctx, userID, songID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSongRating indicates an expected call of DeleteSongRating.
+func (mr *MockRepositoryMockRecorder) DeleteSongRating(ctx, userID, songID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSongRating", reflect.TypeOf((*MockRepository)(nil).DeleteSongRating), ctx, userID, songID)
+}
+
+// DeleteSongTitle mocks base method.
+func (m *MockRepository) DeleteSongTitle(ctx context.Context, songID int, lang string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSongTitle", ctx, songID, lang)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSongTitle indicates an expected call of DeleteSongTitle.
+func (mr *MockRepositoryMockRecorder) DeleteSongTitle(ctx, songID, lang interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSongTitle", reflect.TypeOf((*MockRepository)(nil).DeleteSongTitle), ctx, songID, lang)
+}
+
+// DeleteTrashedSong mocks base method.
+func (m *MockRepository) DeleteTrashedSong(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTrashedSong", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTrashedSong indicates an expected call of DeleteTrashedSong.
+func (mr *MockRepositoryMockRecorder) DeleteTrashedSong(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTrashedSong", reflect.TypeOf((*MockRepository)(nil).DeleteTrashedSong), ctx, id)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockRepository) DeleteWebhook(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockRepositoryMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockRepository)(nil).DeleteWebhook), ctx, id)
+}
+
+// EnqueueWebhookDeliveries mocks base method.
+func (m *MockRepository) EnqueueWebhookDeliveries(ctx context.Context, eventType string, payload []byte) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueWebhookDeliveries", ctx, eventType, payload)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueWebhookDeliveries indicates an expected call of EnqueueWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) EnqueueWebhookDeliveries(ctx, eventType, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).EnqueueWebhookDeliveries), ctx, eventType, payload)
+}
+
+// FailEnrichment mocks base method.
+func (m *MockRepository) FailEnrichment(ctx context.Context, id int, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailEnrichment", ctx, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailEnrichment indicates an expected call of FailEnrichment.
+func (mr *MockRepositoryMockRecorder) FailEnrichment(ctx, id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailEnrichment", reflect.TypeOf((*MockRepository)(nil).FailEnrichment), ctx, id, reason)
+}
+
+// FailUnfinishedJobs mocks base method.
+func (m *MockRepository) FailUnfinishedJobs(ctx context.Context, message string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailUnfinishedJobs", ctx, message)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailUnfinishedJobs indicates an expected call of FailUnfinishedJobs.
+func (mr *MockRepositoryMockRecorder) FailUnfinishedJobs(ctx, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailUnfinishedJobs", reflect.TypeOf((*MockRepository)(nil).FailUnfinishedJobs), ctx, message)
+}
+
+// FavoriteSong mocks base method.
+func (m *MockRepository) FavoriteSong(ctx context.Context, userID, songID int) (models.SongFavorite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FavoriteSong", ctx, userID, songID)
+	ret0, _ := ret[0].(models.SongFavorite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FavoriteSong indicates an expected call of FavoriteSong.
+func (mr *MockRepositoryMockRecorder) FavoriteSong(ctx, userID, songID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FavoriteSong", reflect.TypeOf((*MockRepository)(nil).FavoriteSong), ctx, userID, songID)
+}
+
+// FindSongID mocks base method.
+func (m *MockRepository) FindSongID(ctx context.Context, group, song string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSongID", ctx, group, song)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSongID indicates an expected call of FindSongID.
+func (mr *MockRepositoryMockRecorder) FindSongID(ctx, group, song interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSongID", reflect.TypeOf((*MockRepository)(nil).FindSongID), ctx, group, song)
+}
+
+// FinishImport mocks base method.
+func (m *MockRepository) FinishImport(ctx context.Context, id, status string) (models.Import, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishImport", ctx, id, status)
+	ret0, _ := ret[0].(models.Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishImport indicates an expected call of FinishImport.
+func (mr *MockRepositoryMockRecorder) FinishImport(ctx, id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishImport", reflect.TypeOf((*MockRepository)(nil).FinishImport), ctx, id, status)
+}
+
+// FinishJob mocks base method.
+func (m *MockRepository) FinishJob(ctx context.Context, id, status string, total, processed, failed int, result []byte, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishJob", ctx, id, status, total, processed, failed, result, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishJob indicates an expected call of FinishJob.
+func (mr *MockRepositoryMockRecorder) FinishJob(ctx, id, status, total, processed, failed, result, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishJob", reflect.TypeOf((*MockRepository)(nil).FinishJob), ctx, id, status, total, processed, failed, result, message)
+}
+
+// FinishWebhookDelivery mocks base method.
+func (m *MockRepository) FinishWebhookDelivery(ctx context.Context, id int64, status string, responseStatus *int, message *string, retryAfter time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishWebhookDelivery", ctx, id, status, responseStatus, message, retryAfter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishWebhookDelivery indicates an expected call of FinishWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) FinishWebhookDelivery(ctx, id, status, responseStatus, message, retryAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).FinishWebhookDelivery), ctx, id, status, responseStatus, message, retryAfter)
+}
+
+// GetAPICaptures mocks base method.
+func (m *MockRepository) GetAPICaptures(ctx context.Context, provider string, limit int) ([]models.APICapture, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPICaptures", ctx, provider, limit)
+	ret0, _ := ret[0].([]models.APICapture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPICaptures indicates an expected call of GetAPICaptures.
+func (mr *MockRepositoryMockRecorder) GetAPICaptures(ctx, provider, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPICaptures", reflect.TypeOf((*MockRepository)(nil).GetAPICaptures), ctx, provider, limit)
+}
+
+// GetAlbum mocks base method.
+func (m *MockRepository) GetAlbum(ctx context.Context, id int) (models.Album, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlbum", ctx, id)
+	ret0, _ := ret[0].(models.Album)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlbum indicates an expected call of GetAlbum.
+func (mr *MockRepositoryMockRecorder) GetAlbum(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlbum", reflect.TypeOf((*MockRepository)(nil).GetAlbum), ctx, id)
+}
+
+// GetAlbumSongs mocks base method.
+func (m *MockRepository) GetAlbumSongs(ctx context.Context, albumID, page, limit int) ([]models.Song, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlbumSongs", ctx, albumID, page, limit)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAlbumSongs indicates an expected call of GetAlbumSongs.
+func (mr *MockRepositoryMockRecorder) GetAlbumSongs(ctx, albumID, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlbumSongs", reflect.TypeOf((*MockRepository)(nil).GetAlbumSongs), ctx, albumID, page, limit)
+}
+
+// GetAlbums mocks base method.
+func (m *MockRepository) GetAlbums(ctx context.Context, page, limit int) ([]models.Album, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlbums", ctx, page, limit)
+	ret0, _ := ret[0].([]models.Album)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAlbums indicates an expected call of GetAlbums.
+func (mr *MockRepositoryMockRecorder) GetAlbums(ctx, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlbums", reflect.TypeOf((*MockRepository)(nil).GetAlbums), ctx, page, limit)
+}
+
+// GetArtist mocks base method.
+func (m *MockRepository) GetArtist(ctx context.Context, id int) (models.Artist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArtist", ctx, id)
+	ret0, _ := ret[0].(models.Artist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArtist indicates an expected call of GetArtist.
+func (mr *MockRepositoryMockRecorder) GetArtist(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArtist", reflect.TypeOf((*MockRepository)(nil).GetArtist), ctx, id)
+}
+
+// GetArtistByName mocks base method.
+func (m *MockRepository) GetArtistByName(ctx context.Context, name string) (models.Artist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArtistByName", ctx, name)
+	ret0, _ := ret[0].(models.Artist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArtistByName indicates an expected call of GetArtistByName.
+func (mr *MockRepositoryMockRecorder) GetArtistByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArtistByName", reflect.TypeOf((*MockRepository)(nil).GetArtistByName), ctx, name)
+}
+
+// GetArtistSongs mocks base method.
+func (m *MockRepository) GetArtistSongs(ctx context.Context, artistID, page, limit int) ([]models.Song, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArtistSongs", ctx, artistID, page, limit)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetArtistSongs indicates an expected call of GetArtistSongs.
+func (mr *MockRepositoryMockRecorder) GetArtistSongs(ctx, artistID, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArtistSongs", reflect.TypeOf((*MockRepository)(nil).GetArtistSongs), ctx, artistID, page, limit)
+}
+
+// GetArtists mocks base method.
+func (m *MockRepository) GetArtists(ctx context.Context, page, limit int) ([]models.Artist, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArtists", ctx, page, limit)
+	ret0, _ := ret[0].([]models.Artist)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetArtists indicates an expected call of GetArtists.
+func (mr *MockRepositoryMockRecorder) GetArtists(ctx, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArtists", reflect.TypeOf((*MockRepository)(nil).GetArtists), ctx, page, limit)
+}
+
+// GetClassificationSuggestion mocks base method.
+func (m *MockRepository) GetClassificationSuggestion(ctx context.Context, id int) (models.ClassificationSuggestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClassificationSuggestion", ctx, id)
+	ret0, _ := ret[0].(models.ClassificationSuggestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClassificationSuggestion indicates an expected call of GetClassificationSuggestion.
+func (mr *MockRepositoryMockRecorder) GetClassificationSuggestion(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClassificationSuggestion", reflect.TypeOf((*MockRepository)(nil).GetClassificationSuggestion), ctx, id)
+}
+
+// GetClassificationSuggestions mocks base method.
+func (m *MockRepository) GetClassificationSuggestions(ctx context.Context, status string, page, limit int) ([]models.ClassificationSuggestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClassificationSuggestions", ctx, status, page, limit)
+	ret0, _ := ret[0].([]models.ClassificationSuggestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClassificationSuggestions indicates an expected call of GetClassificationSuggestions.
+func (mr *MockRepositoryMockRecorder) GetClassificationSuggestions(ctx, status, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClassificationSuggestions", reflect.TypeOf((*MockRepository)(nil).GetClassificationSuggestions), ctx, status, page, limit)
+}
+
+// GetEnrichmentStatus mocks base method.
+func (m *MockRepository) GetEnrichmentStatus(ctx context.Context, id int) (models.EnrichmentStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnrichmentStatus", ctx, id)
+	ret0, _ := ret[0].(models.EnrichmentStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEnrichmentStatus indicates an expected call of GetEnrichmentStatus.
+func (mr *MockRepositoryMockRecorder) GetEnrichmentStatus(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnrichmentStatus", reflect.TypeOf((*MockRepository)(nil).GetEnrichmentStatus), ctx, id)
+}
+
+// GetGenre mocks base method.
+func (m *MockRepository) GetGenre(ctx context.Context, id int) (models.Genre, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGenre", ctx, id)
+	ret0, _ := ret[0].(models.Genre)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGenre indicates an expected call of GetGenre.
+func (mr *MockRepositoryMockRecorder) GetGenre(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGenre", reflect.TypeOf((*MockRepository)(nil).GetGenre), ctx, id)
+}
+
+// GetGenreByName mocks base method.
+func (m *MockRepository) GetGenreByName(ctx context.Context, name string) (models.Genre, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGenreByName", ctx, name)
+	ret0, _ := ret[0].(models.Genre)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGenreByName indicates an expected call of GetGenreByName.
+func (mr *MockRepositoryMockRecorder) GetGenreByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGenreByName", reflect.TypeOf((*MockRepository)(nil).GetGenreByName), ctx, name)
+}
+
+// GetGenreSubtree mocks base method.
+func (m *MockRepository) GetGenreSubtree(ctx context.Context, id int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGenreSubtree", ctx, id)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGenreSubtree indicates an expected call of GetGenreSubtree.
+func (mr *MockRepositoryMockRecorder) GetGenreSubtree(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGenreSubtree", reflect.TypeOf((*MockRepository)(nil).GetGenreSubtree), ctx, id)
+}
+
+// GetGenres mocks base method.
+func (m *MockRepository) GetGenres(ctx context.Context) ([]models.Genre, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGenres", ctx)
+	ret0, _ := ret[0].([]models.Genre)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGenres indicates an expected call of GetGenres.
+func (mr *MockRepositoryMockRecorder) GetGenres(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGenres", reflect.TypeOf((*MockRepository)(nil).GetGenres), ctx)
+}
+
+// GetGroupStats mocks base method.
+func (m *MockRepository) GetGroupStats(ctx context.Context, group string) (models.GroupStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupStats", ctx, group)
+	ret0, _ := ret[0].(models.GroupStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupStats indicates an expected call of GetGroupStats.
+func (mr *MockRepositoryMockRecorder) GetGroupStats(ctx, group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupStats", reflect.TypeOf((*MockRepository)(nil).GetGroupStats), ctx, group)
+}
+
+// GetImport mocks base method.
+func (m *MockRepository) GetImport(ctx context.Context, id string) (models.Import, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImport", ctx, id)
+	ret0, _ := ret[0].(models.Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImport indicates an expected call of GetImport.
+func (mr *MockRepositoryMockRecorder) GetImport(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImport", reflect.TypeOf((*MockRepository)(nil).GetImport), ctx, id)
+}
+
+// GetIndexedVerses mocks base method.
+func (m *MockRepository) GetIndexedVerses(ctx context.Context, songID int, delimiter string, from, to int) ([]models.IndexedVerse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIndexedVerses", ctx, songID, delimiter, from, to)
+	ret0, _ := ret[0].([]models.IndexedVerse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIndexedVerses indicates an expected call of GetIndexedVerses.
+func (mr *MockRepositoryMockRecorder) GetIndexedVerses(ctx, songID, delimiter, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIndexedVerses", reflect.TypeOf((*MockRepository)(nil).GetIndexedVerses), ctx, songID, delimiter, from, to)
+}
+
+// GetJob mocks base method.
+func (m *MockRepository) GetJob(ctx context.Context, id string) (models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockRepositoryMockRecorder) GetJob(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockRepository)(nil).GetJob), ctx, id)
+}
+
+// GetLatestSongEventID mocks base method.
+func (m *MockRepository) GetLatestSongEventID(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestSongEventID", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestSongEventID indicates an expected call of GetLatestSongEventID.
+func (mr *MockRepositoryMockRecorder) GetLatestSongEventID(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestSongEventID", reflect.TypeOf((*MockRepository)(nil).GetLatestSongEventID), ctx)
+}
+
+// GetLegalHolds mocks base method.
+func (m *MockRepository) GetLegalHolds(ctx context.Context, ids []int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLegalHolds", ctx, ids)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLegalHolds indicates an expected call of GetLegalHolds.
+func (mr *MockRepositoryMockRecorder) GetLegalHolds(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLegalHolds", reflect.TypeOf((*MockRepository)(nil).GetLegalHolds), ctx, ids)
+}
+
+// GetMostViewedGroupSongs mocks base method.
+func (m *MockRepository) GetMostViewedGroupSongs(ctx context.Context, group string, limit int) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMostViewedGroupSongs", ctx, group, limit)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMostViewedGroupSongs indicates an expected call of GetMostViewedGroupSongs.
+func (mr *MockRepositoryMockRecorder) GetMostViewedGroupSongs(ctx, group, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMostViewedGroupSongs", reflect.TypeOf((*MockRepository)(nil).GetMostViewedGroupSongs), ctx, group, limit)
+}
+
+// GetPendingEnrichments mocks base method.
+func (m *MockRepository) GetPendingEnrichments(ctx context.Context, exclude []int, limit int) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingEnrichments", ctx, exclude, limit)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingEnrichments indicates an expected call of GetPendingEnrichments.
+func (mr *MockRepositoryMockRecorder) GetPendingEnrichments(ctx, exclude, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingEnrichments", reflect.TypeOf((*MockRepository)(nil).GetPendingEnrichments), ctx, exclude, limit)
+}
+
+// GetPreferences mocks base method.
+func (m *MockRepository) GetPreferences(ctx context.Context, userID int) (models.Preferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", ctx, userID)
+	ret0, _ := ret[0].(models.Preferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockRepositoryMockRecorder) GetPreferences(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockRepository)(nil).GetPreferences), ctx, userID)
+}
+
+// GetProviderUsage mocks base method.
+func (m *MockRepository) GetProviderUsage(ctx context.Context, provider string, day time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProviderUsage", ctx, provider, day)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProviderUsage indicates an expected call of GetProviderUsage.
+func (mr *MockRepositoryMockRecorder) GetProviderUsage(ctx, provider, day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProviderUsage", reflect.TypeOf((*MockRepository)(nil).GetProviderUsage), ctx, provider, day)
+}
+
+// GetSnapshots mocks base method.
+func (m *MockRepository) GetSnapshots(ctx context.Context) ([]models.Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSnapshots", ctx)
+	ret0, _ := ret[0].([]models.Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSnapshots indicates an expected call of GetSnapshots.
+func (mr *MockRepositoryMockRecorder) GetSnapshots(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSnapshots", reflect.TypeOf((*MockRepository)(nil).GetSnapshots), ctx)
+}
+
+// GetSongByID mocks base method.
+func (m *MockRepository) GetSongByID(ctx context.Context, id int) (models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongByID", ctx, id)
+	ret0, _ := ret[0].(models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongByID indicates an expected call of GetSongByID.
+func (mr *MockRepositoryMockRecorder) GetSongByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongByID", reflect.TypeOf((*MockRepository)(nil).GetSongByID), ctx, id)
+}
+
+// GetSongEventsAfter mocks base method.
+func (m *MockRepository) GetSongEventsAfter(ctx context.Context, afterID int64, limit int) ([]models.SongEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongEventsAfter", ctx, afterID, limit)
+	ret0, _ := ret[0].([]models.SongEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongEventsAfter indicates an expected call of GetSongEventsAfter.
+func (mr *MockRepositoryMockRecorder) GetSongEventsAfter(ctx, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongEventsAfter", reflect.TypeOf((*MockRepository)(nil).GetSongEventsAfter), ctx, afterID, limit)
+}
+
+// GetSongFacets mocks base method.
+func (m *MockRepository) GetSongFacets(ctx context.Context, filter models.SongFilter, facets []string) (map[string][]models.FacetBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongFacets", ctx, filter, facets)
+	ret0, _ := ret[0].(map[string][]models.FacetBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongFacets indicates an expected call of GetSongFacets.
+func (mr *MockRepositoryMockRecorder) GetSongFacets(ctx, filter, facets interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongFacets", reflect.TypeOf((*MockRepository)(nil).GetSongFacets), ctx, filter, facets)
+}
+
+// GetSongGenres mocks base method.
+func (m *MockRepository) GetSongGenres(ctx context.Context, songID int) ([]models.Genre, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongGenres", ctx, songID)
+	ret0, _ := ret[0].([]models.Genre)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongGenres indicates an expected call of GetSongGenres.
+func (mr *MockRepositoryMockRecorder) GetSongGenres(ctx, songID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongGenres", reflect.TypeOf((*MockRepository)(nil).GetSongGenres), ctx, songID)
+}
+
+// GetSongOverride mocks base method.
+func (m *MockRepository) GetSongOverride(ctx context.Context, userID, songID int) (models.SongOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongOverride", ctx, userID, songID)
+	ret0, _ := ret[0].(models.SongOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongOverride indicates an expected call of GetSongOverride.
+func (mr *MockRepositoryMockRecorder) GetSongOverride(ctx, userID, songID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongOverride", reflect.TypeOf((*MockRepository)(nil).GetSongOverride), ctx, userID, songID)
+}
+
+// GetSongOverrides mocks base method.
+func (m *MockRepository) GetSongOverrides(ctx context.Context, userID int, songIDs []int) ([]models.SongOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongOverrides", ctx, userID, songIDs)
+	ret0, _ := ret[0].([]models.SongOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongOverrides indicates an expected call of GetSongOverrides.
+func (mr *MockRepositoryMockRecorder) GetSongOverrides(ctx, userID, songIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongOverrides", reflect.TypeOf((*MockRepository)(nil).GetSongOverrides), ctx, userID, songIDs)
+}
+
+// GetSongRating mocks base method.
+func (m *MockRepository) GetSongRating(ctx context.Context, userID, songID int) (models.SongRating, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongRating", ctx, userID, songID)
+	ret0, _ := ret[0].(models.SongRating)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongRating indicates an expected call of GetSongRating.
+func (mr *MockRepositoryMockRecorder) GetSongRating(ctx, userID, songID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongRating", reflect.TypeOf((*MockRepository)(nil).GetSongRating), ctx, userID, songID)
+}
+
+// GetSongRevision mocks base method.
+func (m *MockRepository) GetSongRevision(ctx context.Context, songID, revision int) (models.SongRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongRevision", ctx, songID, revision)
+	ret0, _ := ret[0].(models.SongRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongRevision indicates an expected call of GetSongRevision.
+func (mr *MockRepositoryMockRecorder) GetSongRevision(ctx, songID, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongRevision", reflect.TypeOf((*MockRepository)(nil).GetSongRevision), ctx, songID, revision)
+}
+
+// GetSongRevisions mocks base method.
+func (m *MockRepository) GetSongRevisions(ctx context.Context, songID int) ([]models.SongRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongRevisions", ctx, songID)
+	ret0, _ := ret[0].([]models.SongRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongRevisions indicates an expected call of GetSongRevisions.
+func (mr *MockRepositoryMockRecorder) GetSongRevisions(ctx, songID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongRevisions", reflect.TypeOf((*MockRepository)(nil).GetSongRevisions), ctx, songID)
+}
+
+// GetSongTags mocks base method.
+func (m *MockRepository) GetSongTags(ctx context.Context, songID int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongTags", ctx, songID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongTags indicates an expected call of GetSongTags.
+func (mr *MockRepositoryMockRecorder) GetSongTags(ctx, songID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongTags", reflect.TypeOf((*MockRepository)(nil).GetSongTags), ctx, songID)
+}
+
+// GetSongTitles mocks base method.
+func (m *MockRepository) GetSongTitles(ctx context.Context, songID int) ([]models.SongTitle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongTitles", ctx, songID)
+	ret0, _ := ret[0].([]models.SongTitle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongTitles indicates an expected call of GetSongTitles.
+func (mr *MockRepositoryMockRecorder) GetSongTitles(ctx, songID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongTitles", reflect.TypeOf((*MockRepository)(nil).GetSongTitles), ctx, songID)
+}
+
+// GetSongTitlesIn mocks base method.
+func (m *MockRepository) GetSongTitlesIn(ctx context.Context, songIDs []int, langs []string) ([]models.SongTitle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongTitlesIn", ctx, songIDs, langs)
+	ret0, _ := ret[0].([]models.SongTitle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongTitlesIn indicates an expected call of GetSongTitlesIn.
+func (mr *MockRepositoryMockRecorder) GetSongTitlesIn(ctx, songIDs, langs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongTitlesIn", reflect.TypeOf((*MockRepository)(nil).GetSongTitlesIn), ctx, songIDs, langs)
+}
+
+// GetSongs mocks base method.
+func (m *MockRepository) GetSongs(ctx context.Context, filter models.SongFilter, sort string, page, limit int) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongs", ctx, filter, sort, page, limit)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongs indicates an expected call of GetSongs.
+func (mr *MockRepositoryMockRecorder) GetSongs(ctx, filter, sort, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongs", reflect.TypeOf((*MockRepository)(nil).GetSongs), ctx, filter, sort, page, limit)
+}
+
+// GetSongsAfter mocks base method.
+func (m *MockRepository) GetSongsAfter(ctx context.Context, filter models.SongFilter, afterID, limit int) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongsAfter", ctx, filter, afterID, limit)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongsAfter indicates an expected call of GetSongsAfter.
+func (mr *MockRepositoryMockRecorder) GetSongsAfter(ctx, filter, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongsAfter", reflect.TypeOf((*MockRepository)(nil).GetSongsAfter), ctx, filter, afterID, limit)
+}
+
+// GetSongsCreatedBetween mocks base method.
+func (m *MockRepository) GetSongsCreatedBetween(ctx context.Context, from, to time.Time) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongsCreatedBetween", ctx, from, to)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongsCreatedBetween indicates an expected call of GetSongsCreatedBetween.
+func (mr *MockRepositoryMockRecorder) GetSongsCreatedBetween(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongsCreatedBetween", reflect.TypeOf((*MockRepository)(nil).GetSongsCreatedBetween), ctx, from, to)
+}
+
+// GetSongsNeedingEmbedding mocks base method.
+func (m *MockRepository) GetSongsNeedingEmbedding(ctx context.Context, model string, limit int) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongsNeedingEmbedding", ctx, model, limit)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongsNeedingEmbedding indicates an expected call of GetSongsNeedingEmbedding.
+func (mr *MockRepositoryMockRecorder) GetSongsNeedingEmbedding(ctx, model, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongsNeedingEmbedding", reflect.TypeOf((*MockRepository)(nil).GetSongsNeedingEmbedding), ctx, model, limit)
+}
+
+// GetSongsNeedingListeners mocks base method.
+func (m *MockRepository) GetSongsNeedingListeners(ctx context.Context, maxAge time.Duration, exclude []int, limit int) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongsNeedingListeners", ctx, maxAge, exclude, limit)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongsNeedingListeners indicates an expected call of GetSongsNeedingListeners.
+func (mr *MockRepositoryMockRecorder) GetSongsNeedingListeners(ctx, maxAge, exclude, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongsNeedingListeners", reflect.TypeOf((*MockRepository)(nil).GetSongsNeedingListeners), ctx, maxAge, exclude, limit)
+}
+
+// GetSongsNeedingMetadata mocks base method.
+func (m *MockRepository) GetSongsNeedingMetadata(ctx context.Context, exclude []int, limit int) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongsNeedingMetadata", ctx, exclude, limit)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongsNeedingMetadata indicates an expected call of GetSongsNeedingMetadata.
+func (mr *MockRepositoryMockRecorder) GetSongsNeedingMetadata(ctx, exclude, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongsNeedingMetadata", reflect.TypeOf((*MockRepository)(nil).GetSongsNeedingMetadata), ctx, exclude, limit)
+}
+
+// GetSongsUpdatedBetween mocks base method.
+func (m *MockRepository) GetSongsUpdatedBetween(ctx context.Context, from, to time.Time) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongsUpdatedBetween", ctx, from, to)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongsUpdatedBetween indicates an expected call of GetSongsUpdatedBetween.
+func (mr *MockRepositoryMockRecorder) GetSongsUpdatedBetween(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongsUpdatedBetween", reflect.TypeOf((*MockRepository)(nil).GetSongsUpdatedBetween), ctx, from, to)
+}
+
+// GetSongsWithLyrics mocks base method.
+func (m *MockRepository) GetSongsWithLyrics(ctx context.Context) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongsWithLyrics", ctx)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongsWithLyrics indicates an expected call of GetSongsWithLyrics.
+func (mr *MockRepositoryMockRecorder) GetSongsWithLyrics(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongsWithLyrics", reflect.TypeOf((*MockRepository)(nil).GetSongsWithLyrics), ctx)
+}
+
+// GetSongsWithReleaseDate mocks base method.
+func (m *MockRepository) GetSongsWithReleaseDate(ctx context.Context, group, song string) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSongsWithReleaseDate", ctx, group, song)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSongsWithReleaseDate indicates an expected call of GetSongsWithReleaseDate.
+func (mr *MockRepositoryMockRecorder) GetSongsWithReleaseDate(ctx, group, song interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSongsWithReleaseDate", reflect.TypeOf((*MockRepository)(nil).GetSongsWithReleaseDate), ctx, group, song)
+}
+
+// GetStalestSongs mocks base method.
+func (m *MockRepository) GetStalestSongs(ctx context.Context, staleAfter time.Duration, exclude []int, limit int) ([]models.Song, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStalestSongs", ctx, staleAfter, exclude, limit)
+	ret0, _ := ret[0].([]models.Song)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStalestSongs indicates an expected call of GetStalestSongs.
+func (mr *MockRepositoryMockRecorder) GetStalestSongs(ctx, staleAfter, exclude, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStalestSongs", reflect.TypeOf((*MockRepository)(nil).GetStalestSongs), ctx, staleAfter, exclude, limit)
+}
+
+// GetTrash mocks base method.
+func (m *MockRepository) GetTrash(ctx context.Context, page, limit int) ([]models.TrashedSong, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrash", ctx, page, limit)
+	ret0, _ := ret[0].([]models.TrashedSong)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTrash indicates an expected call of GetTrash.
+func (mr *MockRepositoryMockRecorder) GetTrash(ctx, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrash", reflect.TypeOf((*MockRepository)(nil).GetTrash), ctx, page, limit)
+}
+
+// GetTrashedSong mocks base method.
+func (m *MockRepository) GetTrashedSong(ctx context.Context, id int) (models.TrashedSong, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrashedSong", ctx, id)
+	ret0, _ := ret[0].(models.TrashedSong)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrashedSong indicates an expected call of GetTrashedSong.
+func (mr *MockRepositoryMockRecorder) GetTrashedSong(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrashedSong", reflect.TypeOf((*MockRepository)(nil).GetTrashedSong), ctx, id)
+}
+
+// GetTrendingSongs mocks base method.
+func (m *MockRepository) GetTrendingSongs(ctx context.Context, limit int) ([]models.TrendingSong, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrendingSongs", ctx, limit)
+	ret0, _ := ret[0].([]models.TrendingSong)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrendingSongs indicates an expected call of GetTrendingSongs.
+func (mr *MockRepositoryMockRecorder) GetTrendingSongs(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrendingSongs", reflect.TypeOf((*MockRepository)(nil).GetTrendingSongs), ctx, limit)
+}
+
+// GetUserByID mocks base method.
+func (m *MockRepository) GetUserByID(ctx context.Context, id int) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, id)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockRepositoryMockRecorder) GetUserByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockRepository)(nil).GetUserByID), ctx, id)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockRepository) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", ctx, username)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockRepositoryMockRecorder) GetUserByUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockRepository)(nil).GetUserByUsername), ctx, username)
+}
+
+// GetUsers mocks base method.
+func (m *MockRepository) GetUsers(ctx context.Context, page, limit int) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", ctx, page, limit)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockRepositoryMockRecorder) GetUsers(ctx, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockRepository)(nil).GetUsers), ctx, page, limit)
+}
+
+// GetVerseIndex mocks base method.
+func (m *MockRepository) GetVerseIndex(ctx context.Context, songID int) (models.VerseIndex, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVerseIndex", ctx, songID)
+	ret0, _ := ret[0].(models.VerseIndex)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVerseIndex indicates an expected call of GetVerseIndex.
+func (mr *MockRepositoryMockRecorder) GetVerseIndex(ctx, songID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVerseIndex", reflect.TypeOf((*MockRepository)(nil).GetVerseIndex), ctx, songID)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockRepository) GetWebhookDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, webhookID, status, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) GetWebhookDeliveries(ctx, webhookID, status, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).GetWebhookDeliveries), ctx, webhookID, status, limit)
+}
+
+// GetWebhookDelivery mocks base method.
+func (m *MockRepository) GetWebhookDelivery(ctx context.Context, webhookID int, id int64) (models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDelivery", ctx, webhookID, id)
+	ret0, _ := ret[0].(models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDelivery indicates an expected call of GetWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) GetWebhookDelivery(ctx, webhookID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).GetWebhookDelivery), ctx, webhookID, id)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockRepository) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", ctx)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockRepositoryMockRecorder) GetWebhooks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockRepository)(nil).GetWebhooks), ctx)
+}
+
+// HasSearchSuggestions mocks base method.
+func (m *MockRepository) HasSearchSuggestions(ctx context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasSearchSuggestions", ctx)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasSearchSuggestions indicates an expected call of HasSearchSuggestions.
+func (mr *MockRepositoryMockRecorder) HasSearchSuggestions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasSearchSuggestions", reflect.TypeOf((*MockRepository)(nil).HasSearchSuggestions), ctx)
+}
+
+// HasSongEmbeddings mocks base method.
+func (m *MockRepository) HasSongEmbeddings(ctx context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasSongEmbeddings", ctx)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasSongEmbeddings indicates an expected call of HasSongEmbeddings.
+func (mr *MockRepositoryMockRecorder) HasSongEmbeddings(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasSongEmbeddings", reflect.TypeOf((*MockRepository)(nil).HasSongEmbeddings), ctx)
+}
+
+// ImportBatch mocks base method.
+func (m *MockRepository) ImportBatch(ctx context.Context, importID string, songs []models.ImportSong, checkpointRow, failed int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportBatch", ctx, importID, songs, checkpointRow, failed)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportBatch indicates an expected call of ImportBatch.
+func (mr *MockRepositoryMockRecorder) ImportBatch(ctx, importID, songs, checkpointRow, failed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportBatch", reflect.TypeOf((*MockRepository)(nil).ImportBatch), ctx, importID, songs, checkpointRow, failed)
+}
+
+// IncrementProviderUsage mocks base method.
+func (m *MockRepository) IncrementProviderUsage(ctx context.Context, provider string, day time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementProviderUsage", ctx, provider, day)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementProviderUsage indicates an expected call of IncrementProviderUsage.
+func (mr *MockRepositoryMockRecorder) IncrementProviderUsage(ctx, provider, day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementProviderUsage", reflect.TypeOf((*MockRepository)(nil).IncrementProviderUsage), ctx, provider, day)
+}
+
+// IncrementSongViews mocks base method.
+func (m *MockRepository) IncrementSongViews(ctx context.Context, counts map[int]int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementSongViews", ctx, counts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementSongViews indicates an expected call of IncrementSongViews.
+func (mr *MockRepositoryMockRecorder) IncrementSongViews(ctx, counts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementSongViews", reflect.TypeOf((*MockRepository)(nil).IncrementSongViews), ctx, counts)
+}
+
+// MarkSongEventsPublished mocks base method.
+func (m *MockRepository) MarkSongEventsPublished(ctx context.Context, ids []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSongEventsPublished", ctx, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSongEventsPublished indicates an expected call of MarkSongEventsPublished.
+func (mr *MockRepositoryMockRecorder) MarkSongEventsPublished(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSongEventsPublished", reflect.TypeOf((*MockRepository)(nil).MarkSongEventsPublished), ctx, ids)
+}
+
+// Ping mocks base method.
+func (m *MockRepository) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockRepositoryMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRepository)(nil).Ping), ctx)
+}
+
+// PurgeTrash mocks base method.
+func (m *MockRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockRepositoryMockRecorder) PurgeTrash(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockRepository)(nil).PurgeTrash), ctx, before)
+}
+
+// RateSong mocks base method.
+func (m *MockRepository) RateSong(ctx context.Context, userID, songID, rating int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateSong", ctx, userID, songID, rating)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RateSong indicates an expected call of RateSong.
+func (mr *MockRepositoryMockRecorder) RateSong(ctx, userID, songID, rating interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateSong", reflect.TypeOf((*MockRepository)(nil).RateSong), ctx, userID, songID, rating)
+}
+
+// RecentQueries mocks base method.
+func (m *MockRepository) RecentQueries() []repository.QueryLogEntry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecentQueries")
+	ret0, _ := ret[0].([]repository.QueryLogEntry)
+	return ret0
+}
+
+// RecentQueries indicates an expected call of RecentQueries.
+func (mr *MockRepositoryMockRecorder) RecentQueries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecentQueries", reflect.TypeOf((*MockRepository)(nil).RecentQueries))
+}
+
+// RefreshSearchTerms mocks base method.
+func (m *MockRepository) RefreshSearchTerms(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSearchTerms", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshSearchTerms indicates an expected call of RefreshSearchTerms.
+func (mr *MockRepositoryMockRecorder) RefreshSearchTerms(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSearchTerms", reflect.TypeOf((*MockRepository)(nil).RefreshSearchTerms), ctx)
+}
+
+// RefreshSongData mocks base method.
+func (m *MockRepository) RefreshSongData(ctx context.Context, id int, releaseDate, text, link string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSongData", ctx, id, releaseDate, text, link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshSongData indicates an expected call of RefreshSongData.
+func (mr *MockRepositoryMockRecorder) RefreshSongData(ctx, id, releaseDate, text, link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSongData", reflect.TypeOf((*MockRepository)(nil).RefreshSongData), ctx, id, releaseDate, text, link)
+}
+
+// RefreshTrending mocks base method.
+func (m *MockRepository) RefreshTrending(ctx context.Context, gravity float64, windowDays int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTrending", ctx, gravity, windowDays)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshTrending indicates an expected call of RefreshTrending.
+func (mr *MockRepositoryMockRecorder) RefreshTrending(ctx, gravity, windowDays interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTrending", reflect.TypeOf((*MockRepository)(nil).RefreshTrending), ctx, gravity, windowDays)
+}
+
+// RestoreSnapshot mocks base method.
+func (m *MockRepository) RestoreSnapshot(ctx context.Context, id int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSnapshot", ctx, id)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreSnapshot indicates an expected call of RestoreSnapshot.
+func (mr *MockRepositoryMockRecorder) RestoreSnapshot(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSnapshot", reflect.TypeOf((*MockRepository)(nil).RestoreSnapshot), ctx, id)
+}
+
+// RestoreTrashedSong mocks base method.
+func (m *MockRepository) RestoreTrashedSong(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTrashedSong", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTrashedSong indicates an expected call of RestoreTrashedSong.
+func (mr *MockRepositoryMockRecorder) RestoreTrashedSong(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTrashedSong", reflect.TypeOf((*MockRepository)(nil).RestoreTrashedSong), ctx, id)
+}
+
+// RetryWebhookDelivery mocks base method.
+func (m *MockRepository) RetryWebhookDelivery(ctx context.Context, webhookID int, id int64) (models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryWebhookDelivery", ctx, webhookID, id)
+	ret0, _ := ret[0].(models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryWebhookDelivery indicates an expected call of RetryWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) RetryWebhookDelivery(ctx, webhookID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).RetryWebhookDelivery), ctx, webhookID, id)
+}
+
+// ReviewClassificationSuggestion mocks base method.
+func (m *MockRepository) ReviewClassificationSuggestion(ctx context.Context, id int, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewClassificationSuggestion", ctx, id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReviewClassificationSuggestion indicates an expected call of ReviewClassificationSuggestion.
+func (mr *MockRepositoryMockRecorder) ReviewClassificationSuggestion(ctx, id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewClassificationSuggestion", reflect.TypeOf((*MockRepository)(nil).ReviewClassificationSuggestion), ctx, id, status)
+}
+
+// RotateWebhookSecret mocks base method.
+func (m *MockRepository) RotateWebhookSecret(ctx context.Context, id int, secret string) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateWebhookSecret", ctx, id, secret)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateWebhookSecret indicates an expected call of RotateWebhookSecret.
+func (mr *MockRepositoryMockRecorder) RotateWebhookSecret(ctx, id, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateWebhookSecret", reflect.TypeOf((*MockRepository)(nil).RotateWebhookSecret), ctx, id, secret)
+}
+
+// RunInTransaction mocks base method.
+func (m *MockRepository) RunInTransaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunInTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunInTransaction indicates an expected call of RunInTransaction.
+func (mr *MockRepositoryMockRecorder) RunInTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunInTransaction", reflect.TypeOf((*MockRepository)(nil).RunInTransaction), ctx, fn)
+}
+
+// SaveListenerCount mocks base method.
+func (m *MockRepository) SaveListenerCount(ctx context.Context, id int, listeners int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveListenerCount", ctx, id, listeners)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveListenerCount indicates an expected call of SaveListenerCount.
+func (mr *MockRepositoryMockRecorder) SaveListenerCount(ctx, id, listeners interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveListenerCount", reflect.TypeOf((*MockRepository)(nil).SaveListenerCount), ctx, id, listeners)
+}
+
+// SavePreferences mocks base method.
+func (m *MockRepository) SavePreferences(ctx context.Context, userID int, preferences models.Preferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePreferences", ctx, userID, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePreferences indicates an expected call of SavePreferences.
+func (mr *MockRepositoryMockRecorder) SavePreferences(ctx, userID, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePreferences", reflect.TypeOf((*MockRepository)(nil).SavePreferences), ctx, userID, preferences)
+}
+
+// SaveSongEmbedding mocks base method.
+func (m *MockRepository) SaveSongEmbedding(ctx context.Context, songID int, model, contentHash string, embedding []float32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSongEmbedding", ctx, songID, model, contentHash, embedding)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSongEmbedding indicates an expected call of SaveSongEmbedding.
+func (mr *MockRepositoryMockRecorder) SaveSongEmbedding(ctx, songID, model, contentHash, embedding interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSongEmbedding", reflect.TypeOf((*MockRepository)(nil).SaveSongEmbedding), ctx, songID, model, contentHash, embedding)
+}
+
+// SaveSongMetadata mocks base method.
+func (m *MockRepository) SaveSongMetadata(ctx context.Context, id int, metadata models.TrackMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSongMetadata", ctx, id, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSongMetadata indicates an expected call of SaveSongMetadata.
+func (mr *MockRepositoryMockRecorder) SaveSongMetadata(ctx, id, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSongMetadata", reflect.TypeOf((*MockRepository)(nil).SaveSongMetadata), ctx, id, metadata)
+}
+
+// SaveSongOverride mocks base method.
+func (m *MockRepository) SaveSongOverride(ctx context.Context, userID, songID int, text string) (models.SongOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSongOverride", ctx, userID, songID, text)
+	ret0, _ := ret[0].(models.SongOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveSongOverride indicates an expected call of SaveSongOverride.
+func (mr *MockRepositoryMockRecorder) SaveSongOverride(ctx, userID, songID, text interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSongOverride", reflect.TypeOf((*MockRepository)(nil).SaveSongOverride), ctx, userID, songID, text)
+}
+
+// SaveVerseIndex mocks base method.
+func (m *MockRepository) SaveVerseIndex(ctx context.Context, songID int, delimiter, textHash string, spans []models.VerseSpan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveVerseIndex", ctx, songID, delimiter, textHash, spans)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveVerseIndex indicates an expected call of SaveVerseIndex.
+func (mr *MockRepositoryMockRecorder) SaveVerseIndex(ctx, songID, delimiter, textHash, spans interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVerseIndex", reflect.TypeOf((*MockRepository)(nil).SaveVerseIndex), ctx, songID, delimiter, textHash, spans)
+}
+
+// SearchSongs mocks base method.
+func (m *MockRepository) SearchSongs(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchSongs", ctx, query, limit)
+	ret0, _ := ret[0].([]models.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchSongs indicates an expected call of SearchSongs.
+func (mr *MockRepositoryMockRecorder) SearchSongs(ctx, query, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchSongs", reflect.TypeOf((*MockRepository)(nil).SearchSongs), ctx, query, limit)
+}
+
+// SearchSongsSemantic mocks base method.
+func (m *MockRepository) SearchSongsSemantic(ctx context.Context, model string, embedding []float32, keywords string, keywordWeight float64, limit int) ([]models.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchSongsSemantic", ctx, model, embedding, keywords, keywordWeight, limit)
+	ret0, _ := ret[0].([]models.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchSongsSemantic indicates an expected call of SearchSongsSemantic.
+func (mr *MockRepositoryMockRecorder) SearchSongsSemantic(ctx, model, embedding, keywords, keywordWeight, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchSongsSemantic", reflect.TypeOf((*MockRepository)(nil).SearchSongsSemantic), ctx, model, embedding, keywords, keywordWeight, limit)
+}
+
+// SetLegalHold mocks base method.
+func (m *MockRepository) SetLegalHold(ctx context.Context, id int, held bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLegalHold", ctx, id, held)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLegalHold indicates an expected call of SetLegalHold.
+func (mr *MockRepositoryMockRecorder) SetLegalHold(ctx, id, held interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLegalHold", reflect.TypeOf((*MockRepository)(nil).SetLegalHold), ctx, id, held)
+}
+
+// SetSongGenres mocks base method.
+func (m *MockRepository) SetSongGenres(ctx context.Context, songID int, genreIDs []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSongGenres", ctx, songID, genreIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSongGenres indicates an expected call of SetSongGenres.
+func (mr *MockRepositoryMockRecorder) SetSongGenres(ctx, songID, genreIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSongGenres", reflect.TypeOf((*MockRepository)(nil).SetSongGenres), ctx, songID, genreIDs)
+}
+
+// SetSongTitle mocks base method.
+func (m *MockRepository) SetSongTitle(ctx context.Context, songID int, lang string, title models.SongTitleInput) (models.SongTitle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSongTitle", ctx, songID, lang, title)
+	ret0, _ := ret[0].(models.SongTitle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSongTitle indicates an expected call of SetSongTitle.
+func (mr *MockRepositoryMockRecorder) SetSongTitle(ctx, songID, lang, title interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSongTitle", reflect.TypeOf((*MockRepository)(nil).SetSongTitle), ctx, songID, lang, title)
+}
+
+// SetUserRole mocks base method.
+func (m *MockRepository) SetUserRole(ctx context.Context, id int, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, id, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockRepositoryMockRecorder) SetUserRole(ctx, id, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockRepository)(nil).SetUserRole), ctx, id, role)
+}
+
+// SimilarSearchTerms mocks base method.
+func (m *MockRepository) SimilarSearchTerms(ctx context.Context, words []string) (map[string]models.SearchSuggestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SimilarSearchTerms", ctx, words)
+	ret0, _ := ret[0].(map[string]models.SearchSuggestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimilarSearchTerms indicates an expected call of SimilarSearchTerms.
+func (mr *MockRepositoryMockRecorder) SimilarSearchTerms(ctx, words interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimilarSearchTerms", reflect.TypeOf((*MockRepository)(nil).SimilarSearchTerms), ctx, words)
+}
+
+// SimilarSongNames mocks base method.
+func (m *MockRepository) SimilarSongNames(ctx context.Context, query string, limit int) ([]models.SearchSuggestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SimilarSongNames", ctx, query, limit)
+	ret0, _ := ret[0].([]models.SearchSuggestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimilarSongNames indicates an expected call of SimilarSongNames.
+func (mr *MockRepositoryMockRecorder) SimilarSongNames(ctx, query, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimilarSongNames", reflect.TypeOf((*MockRepository)(nil).SimilarSongNames), ctx, query, limit)
+}
+
+// StartJob mocks base method.
+func (m *MockRepository) StartJob(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartJob", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartJob indicates an expected call of StartJob.
+func (mr *MockRepositoryMockRecorder) StartJob(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartJob", reflect.TypeOf((*MockRepository)(nil).StartJob), ctx, id)
+}
+
+// TrashSong mocks base method.
+func (m *MockRepository) TrashSong(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrashSong", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TrashSong indicates an expected call of TrashSong.
+func (mr *MockRepositoryMockRecorder) TrashSong(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrashSong", reflect.TypeOf((*MockRepository)(nil).TrashSong), ctx, id)
+}
+
+// TruncateSongs mocks base method.
+func (m *MockRepository) TruncateSongs(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TruncateSongs", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TruncateSongs indicates an expected call of TruncateSongs.
+func (mr *MockRepositoryMockRecorder) TruncateSongs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TruncateSongs", reflect.TypeOf((*MockRepository)(nil).TruncateSongs), ctx)
+}
+
+// UnfavoriteSong mocks base method.
+func (m *MockRepository) UnfavoriteSong(ctx context.Context, userID, songID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnfavoriteSong", ctx, userID, songID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnfavoriteSong indicates an expected call of UnfavoriteSong.
+func (mr *MockRepositoryMockRecorder) UnfavoriteSong(ctx, userID, songID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnfavoriteSong", reflect.TypeOf((*MockRepository)(nil).UnfavoriteSong), ctx, userID, songID)
+}
+
+// UpdateAlbum mocks base method.
+func (m *MockRepository) UpdateAlbum(ctx context.Context, id int, album models.AlbumInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAlbum", ctx, id, album)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAlbum indicates an expected call of UpdateAlbum.
+func (mr *MockRepositoryMockRecorder) UpdateAlbum(ctx, id, album interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAlbum", reflect.TypeOf((*MockRepository)(nil).UpdateAlbum), ctx, id, album)
+}
+
+// UpdateArtist mocks base method.
+func (m *MockRepository) UpdateArtist(ctx context.Context, id int, artist models.ArtistInput) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateArtist", ctx, id, artist)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateArtist indicates an expected call of UpdateArtist.
+func (mr *MockRepositoryMockRecorder) UpdateArtist(ctx, id, artist interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateArtist", reflect.TypeOf((*MockRepository)(nil).UpdateArtist), ctx, id, artist)
+}
+
+// UpdateGenre mocks base method.
+func (m *MockRepository) UpdateGenre(ctx context.Context, id int, genre models.GenreInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGenre", ctx, id, genre)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGenre indicates an expected call of UpdateGenre.
+func (mr *MockRepositoryMockRecorder) UpdateGenre(ctx, id, genre interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGenre", reflect.TypeOf((*MockRepository)(nil).UpdateGenre), ctx, id, genre)
+}
+
+// UpdateJobProgress mocks base method.
+func (m *MockRepository) UpdateJobProgress(ctx context.Context, id string, total, processed, failed int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateJobProgress", ctx, id, total, processed, failed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateJobProgress indicates an expected call of UpdateJobProgress.
+func (mr *MockRepositoryMockRecorder) UpdateJobProgress(ctx, id, total, processed, failed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJobProgress", reflect.TypeOf((*MockRepository)(nil).UpdateJobProgress), ctx, id, total, processed, failed)
+}
+
+// UpdateSong mocks base method.
+func (m *MockRepository) UpdateSong(ctx context.Context, id int, group, song, releaseDate, text, link string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSong", ctx, id, group, song, releaseDate, text, link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSong indicates an expected call of UpdateSong.
+func (mr *MockRepositoryMockRecorder) UpdateSong(ctx, id, group, song, releaseDate, text, link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSong", reflect.TypeOf((*MockRepository)(nil).UpdateSong), ctx, id, group, song, releaseDate, text, link)
+}
+
+// UpdateSongPartial mocks base method.
+func (m *MockRepository) UpdateSongPartial(ctx context.Context, id int, patch models.SongPatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSongPartial", ctx, id, patch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSongPartial indicates an expected call of UpdateSongPartial.
+func (mr *MockRepositoryMockRecorder) UpdateSongPartial(ctx, id, patch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSongPartial", reflect.TypeOf((*MockRepository)(nil).UpdateSongPartial), ctx, id, patch)
+}
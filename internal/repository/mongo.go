@@ -0,0 +1,546 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// mongoSortOrders maps supported sort keys to the sort documents of song listings. MongoDB orders null
+// below every value, so unrated songs come last in descending order without a special case.
+var mongoSortOrders = map[string]bson.D{
+	"id":     {{Key: "_id", Value: 1}},
+	"views":  {{Key: "views", Value: -1}, {Key: "_id", Value: 1}},
+	"rating": {{Key: "average_rating", Value: -1}, {Key: "rating_count", Value: -1}, {Key: "_id", Value: 1}},
+}
+
+// mongoSongProjection leaves out of the song documents read into models.Song the embedded data they do
+// not hold, the verses above all
+var mongoSongProjection = bson.M{"embedding": 0, "verse_index": 0, "titles": 0}
+
+// mongoStructTags reads the db tags of the models, so they are stored under their column names: the ID as
+// _id, embedded structs inlined, and fields without a tag under their lowercased name. A bson tag, used by
+// the documents only this backend defines, takes precedence.
+func mongoStructTags(field reflect.StructField) (bsoncodec.StructTags, error) {
+	tag, tagged := field.Tag.Lookup("bson")
+	if !tagged {
+		tag, tagged = field.Tag.Lookup("db")
+	}
+	name, flags, _ := strings.Cut(tag, ",")
+	tags := bsoncodec.StructTags{Name: name}
+	for _, flag := range strings.Split(flags, ",") {
+		switch flag {
+		case "omitempty":
+			tags.OmitEmpty = true
+		case "inline":
+			tags.Inline = true
+		}
+	}
+	switch {
+	case name == "-":
+		tags.Skip = true
+	case field.Anonymous && !tagged && field.Type.Kind() == reflect.Struct:
+		tags.Inline = true
+	case name == "":
+		tags.Name = strings.ToLower(field.Name)
+	case name == "id":
+		tags.Name = "_id"
+	}
+	return tags, nil
+}
+
+// mongoRegistry returns the codec registry encoding and decoding structs by mongoStructTags
+func mongoRegistry() *bsoncodec.Registry {
+	registry := bson.NewRegistry()
+	codec, err := bsoncodec.NewStructCodec(bsoncodec.StructTagParserFunc(mongoStructTags))
+	if err != nil {
+		// Only a nil parser is refused
+		panic(err)
+	}
+	registry.RegisterKindEncoder(reflect.Struct, codec)
+	registry.RegisterKindDecoder(reflect.Struct, codec)
+	return registry
+}
+
+// OpenMongo connects to the MongoDB deployment of the URI and returns its database of the name. Documents
+// are encoded by the db tags of the models. RunInTransaction needs the deployment to be a replica set,
+// which may have a single member.
+func OpenMongo(uri, name string) (*mongo.Database, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetRegistry(mongoRegistry()))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, err
+	}
+	return client.Database(name), nil
+}
+
+// MongoRepository stores the music library in MongoDB, for teams already running it. Its collections and
+// indexes are created by the migrations in migrations/mongodb. A song is a single document holding what
+// the SQL backends join from other tables: its views, listener count, rating average, tags, genres, titles,
+// embedding and verse index, whose verses are stored as an array so a page of them is read with $slice.
+// The rows kept per user, such as ratings and favorites, have collections of their own. IDs are allocated
+// from the counters collection, so they stay the integers the API exposes.
+type MongoRepository struct {
+	client   *mongo.Client
+	db       *mongo.Database
+	logger   *zap.Logger
+	queryLog *QueryLog
+	songs    *mongoCollection[models.Song]
+
+	suggestions *mongoCollection[models.ClassificationSuggestion]
+	users       *mongoCollection[models.User]
+	albums      *mongoCollection[models.Album]
+	artists     *mongoCollection[models.Artist]
+	genres      *mongoCollection[models.Genre]
+	trash       *mongoCollection[models.TrashedSong]
+
+	popularity PopularityProvider
+	// statementTimeout bounds each operation
+	statementTimeout time.Duration
+}
+
+var _ Repository = (*MongoRepository)(nil)
+
+// NewMongoRepository creates a MongoRepository on a database opened by OpenMongo
+func NewMongoRepository(db *mongo.Database, logger *zap.Logger) *MongoRepository {
+	r := &MongoRepository{
+		client:     db.Client(),
+		db:         db,
+		logger:     logger,
+		queryLog:   NewQueryLog(defaultQueryLogSize),
+		popularity: InternalPopularity{},
+	}
+	r.songs = newMongoCollection[models.Song](r, "songs", mongoSongProjection, "updated_at", "created_at", "updated_at")
+	r.suggestions = newMongoCollection[models.ClassificationSuggestion](r, "classification_suggestions", nil, "", "created_at")
+	r.users = newMongoCollection[models.User](r, "users", bson.M{"preferences": 0}, "", "created_at")
+	r.albums = newMongoCollection[models.Album](r, "albums", nil, "updated_at", "created_at", "updated_at")
+	r.artists = newMongoCollection[models.Artist](r, "artists", nil, "updated_at", "created_at", "updated_at")
+	r.genres = newMongoCollection[models.Genre](r, "genres", nil, "updated_at", "created_at", "updated_at")
+	r.trash = newMongoCollection[models.TrashedSong](r, "trashed_songs", bson.M{"data": 0}, "", "deleted_at")
+	return r
+}
+
+// ConfigureStatementTimeout bounds every operation; zero leaves operations bounded only by the caller's context
+func (r *MongoRepository) ConfigureStatementTimeout(timeout time.Duration) {
+	r.statementTimeout = timeout
+}
+
+// ConfigurePopularity sets the provider scoring songs for sort=popularity; the default counts internal plays
+func (r *MongoRepository) ConfigurePopularity(provider PopularityProvider) {
+	r.popularity = provider
+}
+
+// mongoPopularityScore returns the aggregation expression of the provider's score over a song document,
+// the counterpart of its SQL expression
+func mongoPopularityScore(provider PopularityProvider) any {
+	switch provider := provider.(type) {
+	case ExternalPopularity:
+		return bson.M{"$ifNull": bson.A{"$listeners", 0}}
+	case BlendedPopularity:
+		terms := bson.A{}
+		for source, weight := range provider.Weights {
+			terms = append(terms, bson.M{"$multiply": bson.A{weight, bson.M{"$ln": bson.M{"$add": bson.A{1, mongoPopularityScore(source)}}}}})
+		}
+		return bson.M{"$add": append(terms, 0)}
+	default:
+		return bson.M{"$ifNull": bson.A{"$views", 0}}
+	}
+}
+
+// statementContext bounds an operation by the configured statement timeout
+func (r *MongoRepository) statementContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withStatementTimeout(ctx, r.statementTimeout)
+}
+
+// Ping checks that the database is reachable
+func (r *MongoRepository) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx, nil)
+}
+
+// RecentQueries returns the most recently executed operations, newest first
+func (r *MongoRepository) RecentQueries() []QueryLogEntry {
+	return r.queryLog.Entries()
+}
+
+// track records a finished operation in the query log. The operation is described by its name, which
+// the query metrics are labeled with, its collection and its filter.
+func (r *MongoRepository) track(operation, collection string, filter any, start time.Time, rows int64, err error) {
+	query := operation + " " + collection
+	if filter != nil {
+		// Extended JSON only encodes documents, so the filter is wrapped in one and unwrapped from its text
+		if data, err := bson.MarshalExtJSON(bson.D{{Key: "filter", Value: filter}}, false, false); err == nil {
+			query += " " + strings.TrimSuffix(strings.TrimPrefix(string(data), `{"filter":`), "}")
+		}
+	}
+	duration := time.Since(start)
+	r.queryLog.Record(query, duration, rows, err)
+	metrics.ObserveQuery(query, duration, err)
+}
+
+// RunInTransaction runs fn in a single transaction, committed when fn returns nil and aborted otherwise.
+// The repository operations fn makes with the context it is given join the transaction. Nested calls join
+// the outer transaction. fn is run again when the transaction fails with a transient error, such as a write
+// conflict with a concurrent one.
+func (r *MongoRepository) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if inTransaction(ctx) {
+		return fn(ctx)
+	}
+	session, err := r.client.StartSession()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer session.EndSession(context.WithoutCancel(ctx))
+	_, err = session.WithTransaction(ctx, func(ctx mongo.SessionContext) (any, error) {
+		return nil, fn(ctx)
+	})
+	return err
+}
+
+// nextIDs allocates n consecutive IDs from the named counter and returns the first of them
+func (r *MongoRepository) nextIDs(ctx context.Context, name string, n int) (int, error) {
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	err := r.findOneAndUpdate(ctx, "counters", bson.M{"_id": name}, bson.M{"$inc": bson.M{"seq": n}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After), &counter)
+	if err != nil {
+		r.logger.Error("Failed to allocate IDs", zap.String("counter", name), zap.Error(err))
+		return 0, err
+	}
+	return counter.Seq - n + 1, nil
+}
+
+// advanceCounter moves the named counter past id, so documents stored with their own IDs do not collide
+// with the IDs allocated next
+func (r *MongoRepository) advanceCounter(ctx context.Context, name string, id int) error {
+	_, err := r.updateOne(ctx, "counters", bson.M{"_id": name}, bson.M{"$max": bson.M{"seq": id}}, options.Update().SetUpsert(true))
+	return err
+}
+
+// mongoNotFound returns sql.ErrNoRows for a missing document, as the callers of Repository expect
+func mongoNotFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// mongoEqualFold returns the regular expression matching the value regardless of case
+func mongoEqualFold(value string) bson.M {
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(value) + "$", "$options": "i"}
+}
+
+// mongoContains returns the regular expression matching the strings containing the value regardless of case,
+// or nil when the value is empty and every string contains it
+func mongoContains(value string) any {
+	if value == "" {
+		return nil
+	}
+	return bson.M{"$regex": regexp.QuoteMeta(value), "$options": "i"}
+}
+
+// mongoAnd combines conditions into a filter matching the documents that meet all of them
+func mongoAnd(conditions []bson.M) bson.M {
+	switch len(conditions) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return conditions[0]
+	}
+	return bson.M{"$and": conditions}
+}
+
+// find decodes every document of the collection matching the filter into the slice results points to
+func (r *MongoRepository) find(ctx context.Context, collection string, filter any, opts *options.FindOptions, results any) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	cursor, err := r.db.Collection(collection).Find(ctx, filter, opts)
+	if err == nil {
+		err = cursor.All(ctx, results)
+	}
+	r.track("find", collection, filter, start, int64(reflect.ValueOf(results).Elem().Len()), err)
+	return err
+}
+
+// findOne decodes the first document of the collection matching the filter into result, returning
+// sql.ErrNoRows when there is none
+func (r *MongoRepository) findOne(ctx context.Context, collection string, filter any, opts *options.FindOneOptions, result any) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	err := r.db.Collection(collection).FindOne(ctx, filter, opts).Decode(result)
+	r.track("find", collection, filter, start, 1, err)
+	return mongoNotFound(err)
+}
+
+// findOneAndUpdate updates the first document of the collection matching the filter and decodes it into
+// result, returning sql.ErrNoRows when there is none
+func (r *MongoRepository) findOneAndUpdate(ctx context.Context, collection string, filter, update any, opts *options.FindOneAndUpdateOptions, result any) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	err := r.db.Collection(collection).FindOneAndUpdate(ctx, filter, update, opts).Decode(result)
+	r.track("update", collection, filter, start, 1, err)
+	return mongoNotFound(err)
+}
+
+// aggregate runs the pipeline on the collection and decodes its output into the slice results points to
+func (r *MongoRepository) aggregate(ctx context.Context, collection string, pipeline mongo.Pipeline, results any) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	cursor, err := r.db.Collection(collection).Aggregate(ctx, pipeline)
+	if err == nil {
+		err = cursor.All(ctx, results)
+	}
+	r.track("aggregate", collection, pipeline, start, int64(reflect.ValueOf(results).Elem().Len()), err)
+	return err
+}
+
+// count returns the number of documents of the collection matching the filter
+func (r *MongoRepository) count(ctx context.Context, collection string, filter any) (int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	count, err := r.db.Collection(collection).CountDocuments(ctx, filter)
+	r.track("count", collection, filter, start, 1, err)
+	return int(count), err
+}
+
+// insertOne stores a document in the collection
+func (r *MongoRepository) insertOne(ctx context.Context, collection string, document any) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	_, err := r.db.Collection(collection).InsertOne(ctx, document)
+	r.track("insert", collection, nil, start, 1, err)
+	return err
+}
+
+// insertMany stores documents in the collection
+func (r *MongoRepository) insertMany(ctx context.Context, collection string, documents []any, opts *options.InsertManyOptions) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := r.db.Collection(collection).InsertMany(ctx, documents, opts)
+	var rows int64
+	if result != nil {
+		rows = int64(len(result.InsertedIDs))
+	}
+	r.track("insert", collection, nil, start, rows, err)
+	return err
+}
+
+// updateOne updates the first document of the collection matching the filter
+func (r *MongoRepository) updateOne(ctx context.Context, collection string, filter, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := r.db.Collection(collection).UpdateOne(ctx, filter, update, opts...)
+	var rows int64
+	if result != nil {
+		rows = result.MatchedCount + result.UpsertedCount
+	}
+	r.track("update", collection, filter, start, rows, err)
+	return result, err
+}
+
+// replaceOne replaces the first document of the collection matching the filter
+func (r *MongoRepository) replaceOne(ctx context.Context, collection string, filter, document any, opts *options.ReplaceOptions) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := r.db.Collection(collection).ReplaceOne(ctx, filter, document, opts)
+	var rows int64
+	if result != nil {
+		rows = result.MatchedCount + result.UpsertedCount
+	}
+	r.track("replace", collection, filter, start, rows, err)
+	return err
+}
+
+// updateMany updates every document of the collection matching the filter and returns how many it matched
+func (r *MongoRepository) updateMany(ctx context.Context, collection string, filter, update any) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := r.db.Collection(collection).UpdateMany(ctx, filter, update)
+	var rows int64
+	if result != nil {
+		rows = result.MatchedCount
+	}
+	r.track("update", collection, filter, start, rows, err)
+	return rows, err
+}
+
+// deleteMany deletes every document of the collection matching the filter and returns how many it deleted
+func (r *MongoRepository) deleteMany(ctx context.Context, collection string, filter any) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := r.db.Collection(collection).DeleteMany(ctx, filter)
+	var rows int64
+	if result != nil {
+		rows = result.DeletedCount
+	}
+	r.track("delete", collection, filter, start, rows, err)
+	return rows, err
+}
+
+// bulkWrite runs the writes on the collection in order
+func (r *MongoRepository) bulkWrite(ctx context.Context, collection string, writes []mongo.WriteModel) (*mongo.BulkWriteResult, error) {
+	if len(writes) == 0 {
+		return &mongo.BulkWriteResult{}, nil
+	}
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := r.db.Collection(collection).BulkWrite(ctx, writes)
+	var rows int64
+	if result != nil {
+		rows = result.InsertedCount + result.MatchedCount + result.UpsertedCount + result.DeletedCount
+	}
+	r.track("bulkWrite", collection, nil, start, rows, err)
+	return result, err
+}
+
+// mongoCollection is the counterpart of Table for MongoDB: it provides the CRUD plumbing of a collection
+// whose documents are read into T and identified by integer IDs allocated from the counter of its name
+type mongoCollection[T any] struct {
+	repo *MongoRepository
+	name string
+	// projection leaves out the fields of the documents T does not hold, nil reads them whole
+	projection bson.M
+	// touched is the field set to the current time by every update, empty for none
+	touched string
+	// stamped are the fields set to the current time by inserts
+	stamped []string
+}
+
+// newMongoCollection creates a mongoCollection for the named collection
+func newMongoCollection[T any](repo *MongoRepository, name string, projection bson.M, touched string, stamped ...string) *mongoCollection[T] {
+	return &mongoCollection[T]{repo: repo, name: name, projection: projection, touched: touched, stamped: stamped}
+}
+
+// Get retrieves a single document by its ID, returning sql.ErrNoRows when it does not exist
+func (c *mongoCollection[T]) Get(ctx context.Context, id int) (T, error) {
+	var item T
+	err := c.repo.findOne(ctx, c.name, bson.M{"_id": id}, options.FindOne().SetProjection(c.projection), &item)
+	if err != nil && err != sql.ErrNoRows {
+		c.repo.logger.Error("Failed to get document", zap.String("collection", c.name), zap.Int("id", id), zap.Error(err))
+	}
+	return item, err
+}
+
+// Find retrieves every document matching the filter in the given order
+func (c *mongoCollection[T]) Find(ctx context.Context, filter bson.M, sort bson.D) ([]T, error) {
+	items := []T{}
+	opts := options.Find().SetProjection(c.projection).SetSort(sort)
+	if err := c.repo.find(ctx, c.name, filter, opts, &items); err != nil {
+		c.repo.logger.Error("Failed to find documents", zap.String("collection", c.name), zap.Error(err))
+		return nil, err
+	}
+	return items, nil
+}
+
+// List retrieves a page of the documents matching the filter in the given order, by ID when sort is nil
+func (c *mongoCollection[T]) List(ctx context.Context, filter bson.M, sort bson.D, page, limit int) ([]T, error) {
+	if sort == nil {
+		sort = bson.D{{Key: "_id", Value: 1}}
+	}
+	items := []T{}
+	opts := options.Find().SetProjection(c.projection).SetSort(sort).SetSkip(int64((page - 1) * limit)).SetLimit(int64(limit))
+	if err := c.repo.find(ctx, c.name, filter, opts, &items); err != nil {
+		c.repo.logger.Error("Failed to list documents", zap.String("collection", c.name), zap.Error(err))
+		return nil, err
+	}
+	return items, nil
+}
+
+// Count returns the number of documents matching the filter
+func (c *mongoCollection[T]) Count(ctx context.Context, filter bson.M) (int, error) {
+	count, err := c.repo.count(ctx, c.name, filter)
+	if err != nil {
+		c.repo.logger.Error("Failed to count documents", zap.String("collection", c.name), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// Insert stores a document with the given field values under a newly allocated ID and returns the ID
+func (c *mongoCollection[T]) Insert(ctx context.Context, values bson.M) (int, error) {
+	id, err := c.repo.nextIDs(ctx, c.name, 1)
+	if err != nil {
+		return 0, err
+	}
+	document := bson.M{"_id": id}
+	now := time.Now().UTC()
+	for _, field := range c.stamped {
+		document[field] = now
+	}
+	for field, value := range values {
+		document[field] = value
+	}
+	if err := c.repo.insertOne(ctx, c.name, document); err != nil {
+		if !mongo.IsDuplicateKeyError(err) {
+			c.repo.logger.Error("Failed to insert document", zap.String("collection", c.name), zap.Error(err))
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// Update sets the given field values on the document with the ID, returning sql.ErrNoRows when it does not exist
+func (c *mongoCollection[T]) Update(ctx context.Context, id int, values bson.M) error {
+	set := bson.M{}
+	if c.touched != "" {
+		set[c.touched] = time.Now().UTC()
+	}
+	for field, value := range values {
+		set[field] = value
+	}
+	result, err := c.repo.updateOne(ctx, c.name, bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		c.repo.logger.Error("Failed to update document", zap.String("collection", c.name), zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Delete removes the document with the ID, returning sql.ErrNoRows when it does not exist
+func (c *mongoCollection[T]) Delete(ctx context.Context, id int) error {
+	deleted, err := c.repo.deleteMany(ctx, c.name, bson.M{"_id": id})
+	if err != nil {
+		c.repo.logger.Error("Failed to delete document", zap.String("collection", c.name), zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
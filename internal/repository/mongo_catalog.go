@@ -0,0 +1,561 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// errArtistHasSongs is returned when deleting an artist that still has songs, which the foreign key of the
+// SQL backends refuses
+var errArtistHasSongs = errors.New("artist has songs")
+
+// mongoAlbumValues returns the fields of an album stored from the input
+func mongoAlbumValues(album models.AlbumInput) bson.M {
+	return bson.M{
+		"title":        album.Title,
+		"group_name":   album.Group,
+		"release_date": mongoNullIfEmpty(album.ReleaseDate),
+		"artwork_url":  mongoNullIfEmpty(album.ArtworkURL),
+	}
+}
+
+// mongoArtistValues returns the fields of an artist stored from the input
+func mongoArtistValues(artist models.ArtistInput) bson.M {
+	var formedYear any
+	if artist.FormedYear != 0 {
+		formedYear = artist.FormedYear
+	}
+	return bson.M{
+		"name":        artist.Name,
+		"country":     mongoNullIfEmpty(artist.Country),
+		"formed_year": formedYear,
+		"bio":         mongoNullIfEmpty(artist.Bio),
+	}
+}
+
+// CreateAlbum adds an album and returns its ID
+func (r *MongoRepository) CreateAlbum(ctx context.Context, album models.AlbumInput) (int, error) {
+	r.logger.Debug("Creating album", zap.String("title", album.Title), zap.String("group", album.Group))
+	return r.albums.Insert(ctx, mongoAlbumValues(album))
+}
+
+// GetAlbum retrieves an album, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) GetAlbum(ctx context.Context, id int) (models.Album, error) {
+	return r.albums.Get(ctx, id)
+}
+
+// GetAlbums retrieves a page of albums ordered by ID together with the number of albums
+func (r *MongoRepository) GetAlbums(ctx context.Context, page, limit int) ([]models.Album, int, error) {
+	albums, err := r.albums.List(ctx, bson.M{}, nil, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.albums.Count(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+	return albums, total, nil
+}
+
+// UpdateAlbum replaces the fields of an album, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) UpdateAlbum(ctx context.Context, id int, album models.AlbumInput) error {
+	return r.albums.Update(ctx, id, mongoAlbumValues(album))
+}
+
+// DeleteAlbum deletes an album, returning sql.ErrNoRows when it does not exist. Its songs are kept and
+// no longer belong to an album.
+func (r *MongoRepository) DeleteAlbum(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting album", zap.Int("id", id))
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := r.albums.Delete(ctx, id); err != nil {
+			return err
+		}
+		update := bson.M{"$set": bson.M{"album_id": nil, "updated_at": time.Now().UTC()}}
+		_, err := r.updateMany(ctx, "songs", bson.M{"album_id": id}, update)
+		return err
+	})
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to delete album", zap.Int("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// GetAlbumSongs retrieves a page of the songs of an album ordered by ID together with the number of its songs
+func (r *MongoRepository) GetAlbumSongs(ctx context.Context, albumID, page, limit int) ([]models.Song, int, error) {
+	return r.pageSongs(ctx, bson.M{"album_id": albumID}, page, limit)
+}
+
+// pageSongs retrieves a page of the songs matching the filter ordered by ID together with their number
+func (r *MongoRepository) pageSongs(ctx context.Context, filter bson.M, page, limit int) ([]models.Song, int, error) {
+	songs, err := r.songs.List(ctx, filter, nil, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.songs.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return songs, total, nil
+}
+
+// CreateArtist adds an artist and returns its ID, or sql.ErrNoRows when the name is taken
+func (r *MongoRepository) CreateArtist(ctx context.Context, artist models.ArtistInput) (int, error) {
+	r.logger.Debug("Creating artist", zap.String("name", artist.Name))
+	// The name is looked up first, as a failed insert would abort the transaction ctx may carry
+	if _, err := r.GetArtistByName(ctx, artist.Name); err != sql.ErrNoRows {
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return 0, err
+	}
+	id, err := r.artists.Insert(ctx, mongoArtistValues(artist))
+	if mongo.IsDuplicateKeyError(err) {
+		return 0, sql.ErrNoRows
+	}
+	return id, err
+}
+
+// GetArtist retrieves an artist, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) GetArtist(ctx context.Context, id int) (models.Artist, error) {
+	return r.artists.Get(ctx, id)
+}
+
+// GetArtistByName retrieves the artist with the name, returning sql.ErrNoRows when there is none
+func (r *MongoRepository) GetArtistByName(ctx context.Context, name string) (models.Artist, error) {
+	var artist models.Artist
+	err := r.findOne(ctx, "artists", bson.M{"name": name}, nil, &artist)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch artist", zap.String("name", name), zap.Error(err))
+	}
+	return artist, err
+}
+
+// GetArtists retrieves a page of artists ordered by ID together with the number of artists
+func (r *MongoRepository) GetArtists(ctx context.Context, page, limit int) ([]models.Artist, int, error) {
+	artists, err := r.artists.List(ctx, bson.M{}, nil, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.artists.Count(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+	return artists, total, nil
+}
+
+// UpdateArtist replaces the fields of an artist, returning sql.ErrNoRows when it does not exist. A new name
+// is carried over to the group of the artist's songs, whose IDs are returned.
+func (r *MongoRepository) UpdateArtist(ctx context.Context, id int, artist models.ArtistInput) ([]int, error) {
+	r.logger.Debug("Updating artist", zap.Int("id", id))
+	var renamed []int
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := r.artists.Update(ctx, id, mongoArtistValues(artist)); err != nil {
+			return err
+		}
+		var err error
+		if renamed, err = r.findIDs(ctx, "songs", bson.M{"artist_id": id, "group_name": bson.M{"$ne": artist.Name}}); err != nil {
+			return err
+		}
+		update := bson.M{"$set": bson.M{"group_name": artist.Name, "updated_at": time.Now().UTC()}}
+		_, err = r.updateMany(ctx, "songs", bson.M{"_id": bson.M{"$in": renamed}}, update)
+		return err
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to update artist", zap.Int("id", id), zap.Error(err))
+		}
+		return nil, err
+	}
+	return renamed, nil
+}
+
+// DeleteArtist deletes an artist, returning sql.ErrNoRows when it does not exist. Artists with songs
+// cannot be deleted.
+func (r *MongoRepository) DeleteArtist(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting artist", zap.Int("id", id))
+	songs, err := r.songs.Count(ctx, bson.M{"artist_id": id})
+	if err != nil {
+		return err
+	}
+	if songs > 0 {
+		return errArtistHasSongs
+	}
+	if err := r.artists.Delete(ctx, id); err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to delete artist", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// GetArtistSongs retrieves a page of the songs of an artist ordered by ID together with the number of its songs
+func (r *MongoRepository) GetArtistSongs(ctx context.Context, artistID, page, limit int) ([]models.Song, int, error) {
+	return r.pageSongs(ctx, bson.M{"artist_id": artistID}, page, limit)
+}
+
+// CreateGenre adds a genre and returns its ID, or sql.ErrNoRows when the name is taken, regardless of case
+func (r *MongoRepository) CreateGenre(ctx context.Context, genre models.GenreInput) (int, error) {
+	r.logger.Debug("Creating genre", zap.String("name", genre.Name))
+	if _, err := r.GetGenreByName(ctx, genre.Name); err != sql.ErrNoRows {
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return 0, err
+	}
+	id, err := r.genres.Insert(ctx, bson.M{"name": genre.Name, "parent_id": genre.ParentID})
+	if mongo.IsDuplicateKeyError(err) {
+		return 0, sql.ErrNoRows
+	}
+	return id, err
+}
+
+// GetGenre retrieves a genre, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) GetGenre(ctx context.Context, id int) (models.Genre, error) {
+	return r.genres.Get(ctx, id)
+}
+
+// GetGenreByName retrieves the genre with the name regardless of case, returning sql.ErrNoRows when there is none
+func (r *MongoRepository) GetGenreByName(ctx context.Context, name string) (models.Genre, error) {
+	var genre models.Genre
+	err := r.findOne(ctx, "genres", bson.M{"name": mongoEqualFold(name)}, nil, &genre)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch genre", zap.String("name", name), zap.Error(err))
+	}
+	return genre, err
+}
+
+// GetGenres retrieves every genre in name order
+func (r *MongoRepository) GetGenres(ctx context.Context) ([]models.Genre, error) {
+	return r.genres.Find(ctx, bson.M{}, bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+}
+
+// GetGenreSubtree returns the IDs of the genre and of all its subgenres, or none when it does not exist
+func (r *MongoRepository) GetGenreSubtree(ctx context.Context, id int) ([]int, error) {
+	ids, err := r.genreSubtree(ctx, id)
+	if err != nil {
+		r.logger.Error("Failed to fetch genre subtree", zap.Int("id", id), zap.Error(err))
+		return nil, err
+	}
+	return ids, nil
+}
+
+// genreSubtree returns the IDs of the genre followed by those of all its subgenres, walked by $graphLookup
+func (r *MongoRepository) genreSubtree(ctx context.Context, id int) ([]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": id}}},
+		{{Key: "$graphLookup", Value: bson.M{
+			"from":             "genres",
+			"startWith":        "$_id",
+			"connectFromField": "_id",
+			"connectToField":   "parent_id",
+			"as":               "subgenres",
+		}}},
+		{{Key: "$project", Value: bson.M{"subgenres": "$subgenres._id"}}},
+	}
+	var genres []struct {
+		ID        int   `bson:"_id"`
+		Subgenres []int `bson:"subgenres"`
+	}
+	if err := r.aggregate(ctx, "genres", pipeline, &genres); err != nil {
+		return nil, err
+	}
+	ids := []int{}
+	for _, genre := range genres {
+		sort.Ints(genre.Subgenres)
+		ids = append(append(ids, genre.ID), genre.Subgenres...)
+	}
+	return ids, nil
+}
+
+// UpdateGenre replaces the name and parent of a genre, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) UpdateGenre(ctx context.Context, id int, genre models.GenreInput) error {
+	return r.genres.Update(ctx, id, bson.M{"name": genre.Name, "parent_id": genre.ParentID})
+}
+
+// DeleteGenre deletes a genre and unassigns it from its songs, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) DeleteGenre(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting genre", zap.Int("id", id))
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := r.genres.Delete(ctx, id); err != nil {
+			return err
+		}
+		_, err := r.updateMany(ctx, "songs", bson.M{"genre_ids": id}, bson.M{"$pull": bson.M{"genre_ids": id}})
+		return err
+	})
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to delete genre", zap.Int("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// CountSubgenres returns the number of genres whose parent is the genre
+func (r *MongoRepository) CountSubgenres(ctx context.Context, id int) (int, error) {
+	return r.genres.Count(ctx, bson.M{"parent_id": id})
+}
+
+// GetSongGenres retrieves the genres assigned to a song in name order
+func (r *MongoRepository) GetSongGenres(ctx context.Context, songID int) ([]models.Genre, error) {
+	var song struct {
+		GenreIDs []int `bson:"genre_ids"`
+	}
+	err := r.findOne(ctx, "songs", bson.M{"_id": songID}, options.FindOne().SetProjection(bson.M{"genre_ids": 1}), &song)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch song genres", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+	return r.genres.Find(ctx, bson.M{"_id": bson.M{"$in": append(song.GenreIDs, []int{}...)}}, bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+}
+
+// SetSongGenres replaces the genres assigned to a song with the genres with the IDs
+func (r *MongoRepository) SetSongGenres(ctx context.Context, songID int, genreIDs []int) error {
+	assigned := []int{}
+	seen := make(map[int]bool, len(genreIDs))
+	for _, id := range genreIDs {
+		if !seen[id] {
+			seen[id] = true
+			assigned = append(assigned, id)
+		}
+	}
+	if _, err := r.updateOne(ctx, "songs", bson.M{"_id": songID}, bson.M{"$set": bson.M{"genre_ids": assigned}}); err != nil {
+		r.logger.Error("Failed to assign song genres", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetSongTitles retrieves the titles of a song in language order
+func (r *MongoRepository) GetSongTitles(ctx context.Context, songID int) ([]models.SongTitle, error) {
+	return r.songTitles(ctx, bson.M{"_id": songID}, nil)
+}
+
+// GetSongTitlesIn retrieves the titles of the songs with the IDs in the languages
+func (r *MongoRepository) GetSongTitlesIn(ctx context.Context, songIDs []int, langs []string) ([]models.SongTitle, error) {
+	return r.songTitles(ctx, bson.M{"_id": bson.M{"$in": songIDs}}, bson.M{"titles.lang": bson.M{"$in": langs}})
+}
+
+// songTitles retrieves the titles held by the songs matching the filter that match the title filter, when
+// set, in song and language order
+func (r *MongoRepository) songTitles(ctx context.Context, filter, titleFilter bson.M) ([]models.SongTitle, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$project", Value: bson.M{"titles": 1}}},
+		{{Key: "$unwind", Value: "$titles"}},
+	}
+	if titleFilter != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: titleFilter}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$replaceWith", Value: bson.M{"$mergeObjects": bson.A{"$titles", bson.M{"song_id": "$_id"}}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "song_id", Value: 1}, {Key: "lang", Value: 1}}}},
+	)
+	titles := []models.SongTitle{}
+	if err := r.aggregate(ctx, "songs", pipeline, &titles); err != nil {
+		r.logger.Error("Failed to fetch song titles", zap.Error(err))
+		return nil, err
+	}
+	return titles, nil
+}
+
+// SetSongTitle adds or replaces the title of a song in the language and returns it as stored, or
+// sql.ErrNoRows when the song does not exist
+func (r *MongoRepository) SetSongTitle(ctx context.Context, songID int, lang string, title models.SongTitleInput) (models.SongTitle, error) {
+	now := time.Now().UTC()
+	replaced, err := r.updateOne(ctx, "songs", bson.M{"_id": songID, "titles.lang": lang}, bson.M{"$set": bson.M{
+		"titles.$.title":      title.Title,
+		"titles.$.kind":       title.Kind,
+		"titles.$.updated_at": now,
+	}})
+	if err == nil && replaced.MatchedCount == 0 {
+		var added *mongo.UpdateResult
+		added, err = r.updateOne(ctx, "songs", bson.M{"_id": songID, "titles.lang": bson.M{"$ne": lang}}, bson.M{"$push": bson.M{
+			"titles": bson.M{"lang": lang, "title": title.Title, "kind": title.Kind, "created_at": now, "updated_at": now},
+		}})
+		if err == nil && added.MatchedCount == 0 {
+			return models.SongTitle{}, sql.ErrNoRows
+		}
+	}
+	if err != nil {
+		r.logger.Error("Failed to set song title", zap.Int("song_id", songID), zap.String("lang", lang), zap.Error(err))
+		return models.SongTitle{}, err
+	}
+	titles, err := r.songTitles(ctx, bson.M{"_id": songID}, bson.M{"titles.lang": lang})
+	if err != nil {
+		return models.SongTitle{}, err
+	}
+	if len(titles) == 0 {
+		// Deleted since it was set
+		return models.SongTitle{}, sql.ErrNoRows
+	}
+	return titles[0], nil
+}
+
+// DeleteSongTitle deletes the title of a song in the language, returning sql.ErrNoRows when there is none
+func (r *MongoRepository) DeleteSongTitle(ctx context.Context, songID int, lang string) error {
+	result, err := r.updateOne(ctx, "songs", bson.M{"_id": songID, "titles.lang": lang}, bson.M{"$pull": bson.M{"titles": bson.M{"lang": lang}}})
+	if err != nil {
+		r.logger.Error("Failed to delete song title", zap.Int("song_id", songID), zap.String("lang", lang), zap.Error(err))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// BulkTagSongs adds and removes tags on the songs with the IDs, or on every song matched by the filter
+// when ids is nil, in a single transaction. Tags are stored by name on the songs.
+func (r *MongoRepository) BulkTagSongs(ctx context.Context, ids []int, filter models.SongFilter, add, remove []string) (models.BulkTagResult, error) {
+	var result models.BulkTagResult
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		songIDs, err := r.matchSongIDs(ctx, ids, filter)
+		if err != nil {
+			r.logger.Error("Failed to match songs for tagging", zap.Error(err))
+			return err
+		}
+		result = models.BulkTagResult{Matched: len(songIDs), MissingIDs: missingIDs(ids, songIDs), SongIDs: songIDs}
+		for _, tag := range add {
+			added, err := r.updateMany(ctx, "songs", bson.M{"_id": bson.M{"$in": songIDs}, "tags": bson.M{"$ne": tag}},
+				bson.M{"$push": bson.M{"tags": tag}})
+			if err != nil {
+				r.logger.Error("Failed to assign tag", zap.String("tag", tag), zap.Error(err))
+				return err
+			}
+			result.Added += int(added)
+		}
+		for _, tag := range remove {
+			removed, err := r.updateMany(ctx, "songs", bson.M{"_id": bson.M{"$in": songIDs}, "tags": tag},
+				bson.M{"$pull": bson.M{"tags": tag}})
+			if err != nil {
+				r.logger.Error("Failed to remove tags", zap.Error(err))
+				return err
+			}
+			result.Removed += int(removed)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.BulkTagResult{}, err
+	}
+	r.logger.Info("Songs tagged in bulk", zap.Int("matched", result.Matched), zap.Int("added", result.Added), zap.Int("removed", result.Removed))
+	return result, nil
+}
+
+// matchSongIDs returns the IDs of the existing songs among ids, or of the songs matched by the filter when ids is nil
+func (r *MongoRepository) matchSongIDs(ctx context.Context, ids []int, filter models.SongFilter) ([]int, error) {
+	if ids != nil {
+		return r.findIDs(ctx, "songs", bson.M{"_id": bson.M{"$in": ids}})
+	}
+	where, err := r.songFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return r.findIDs(ctx, "songs", where)
+}
+
+// GetSongTags returns the tags of a song in name order, or sql.ErrNoRows when the song does not exist
+func (r *MongoRepository) GetSongTags(ctx context.Context, songID int) ([]string, error) {
+	var song struct {
+		Tags []string `bson:"tags"`
+	}
+	err := r.findOne(ctx, "songs", bson.M{"_id": songID}, options.FindOne().SetProjection(bson.M{"tags": 1}), &song)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to fetch song tags", zap.Int("song_id", songID), zap.Error(err))
+		}
+		return nil, err
+	}
+	tags := append([]string{}, song.Tags...)
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// AddClassificationSuggestions queues suggestions for review. A value already suggested for the song
+// is left as is, so rejected suggestions are not proposed again.
+func (r *MongoRepository) AddClassificationSuggestions(ctx context.Context, songID int, source string, suggestions []models.ClassificationSuggestion) (int, error) {
+	added := 0
+	for _, suggestion := range suggestions {
+		key := bson.M{"song_id": songID, "kind": suggestion.Kind, "value": suggestion.Value}
+		suggested, err := r.suggestions.Count(ctx, key)
+		if err != nil {
+			return added, err
+		}
+		if suggested > 0 {
+			continue
+		}
+		_, err = r.suggestions.Insert(ctx, bson.M{
+			"song_id":     songID,
+			"kind":        suggestion.Kind,
+			"value":       suggestion.Value,
+			"confidence":  suggestion.Confidence,
+			"source":      source,
+			"status":      "pending",
+			"reviewed_at": nil,
+		})
+		switch {
+		case mongo.IsDuplicateKeyError(err):
+			// Suggested concurrently
+		case err != nil:
+			r.logger.Error("Failed to add classification suggestion", zap.Int("song_id", songID), zap.Error(err))
+			return added, err
+		default:
+			added++
+		}
+	}
+	return added, nil
+}
+
+// GetClassificationSuggestions retrieves a page of suggestions with the status, oldest first, with the
+// group and title of their songs
+func (r *MongoRepository) GetClassificationSuggestions(ctx context.Context, status string, page, limit int) ([]models.ClassificationSuggestion, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": status}}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$skip", Value: (page - 1) * limit}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":     "songs",
+			"let":      bson.M{"song_id": "$song_id"},
+			"pipeline": bson.A{bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$_id", "$$song_id"}}}}, bson.M{"$project": bson.M{"group_name": 1, "song_name": 1}}},
+			"as":       "song",
+		}}},
+		{{Key: "$unwind", Value: "$song"}},
+		{{Key: "$addFields", Value: bson.M{"group_name": "$song.group_name", "song_name": "$song.song_name"}}},
+		{{Key: "$project", Value: bson.M{"song": 0}}},
+	}
+	suggestions := []models.ClassificationSuggestion{}
+	if err := r.aggregate(ctx, "classification_suggestions", pipeline, &suggestions); err != nil {
+		r.logger.Error("Failed to fetch classification suggestions", zap.Error(err))
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// GetClassificationSuggestion retrieves a suggestion, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) GetClassificationSuggestion(ctx context.Context, id int) (models.ClassificationSuggestion, error) {
+	return r.suggestions.Get(ctx, id)
+}
+
+// ReviewClassificationSuggestion accepts or rejects a pending suggestion,
+// returning sql.ErrNoRows when there is no pending suggestion with the ID
+func (r *MongoRepository) ReviewClassificationSuggestion(ctx context.Context, id int, status string) error {
+	update := bson.M{"$set": bson.M{"status": status, "reviewed_at": time.Now().UTC()}}
+	result, err := r.updateOne(ctx, "classification_suggestions", bson.M{"_id": id, "status": "pending"}, update)
+	if err != nil {
+		r.logger.Error("Failed to review classification suggestion", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
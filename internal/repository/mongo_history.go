@@ -0,0 +1,883 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// mongoSnapshotCollections are the collections whose documents a snapshot and the trash hold, in the order
+// they are restored. A song's views, listeners, tags, genres and titles are part of its document. The
+// derived fields, such as the embedding and the verse index, are rebuilt rather than restored.
+var mongoSnapshotCollections = []string{"songs", "song_overrides", "song_ratings", "song_favorites", "song_revisions"}
+
+// mongoSnapshotProjection leaves the derived fields out of the songs a snapshot holds
+var mongoSnapshotProjection = bson.M{"embedding": 0, "verse_index": 0, "trending_score": 0}
+
+// errBackfillDryRun aborts the transaction of a backfill dry run once its report is complete
+var errBackfillDryRun = errors.New("backfill dry run")
+
+// mongoBlank matches the strings holding nothing but spaces
+var mongoBlank = bson.M{"$regex": "^ *$"}
+
+// mongoInt returns the integer value of a number read into a bson.M, whose width depends on how it was stored
+func mongoInt(value any) (int, bool) {
+	switch number := value.(type) {
+	case int32:
+		return int(number), true
+	case int64:
+		return int(number), true
+	case int:
+		return number, true
+	case float64:
+		return int(number), true
+	}
+	return 0, false
+}
+
+// songDocuments reads the documents of every snapshot collection matching the filter on song_id, by
+// collection; the songs themselves are matched by the filter on _id
+func (r *MongoRepository) songDocuments(ctx context.Context, filter bson.M) (map[string][]bson.M, error) {
+	documents := make(map[string][]bson.M, len(mongoSnapshotCollections))
+	for _, collection := range mongoSnapshotCollections {
+		where := filter
+		opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+		if collection == "songs" {
+			if id, ok := filter["song_id"]; ok {
+				where = bson.M{"_id": id}
+			}
+			opts.SetProjection(mongoSnapshotProjection)
+		}
+		found := []bson.M{}
+		if err := r.find(ctx, collection, where, opts, &found); err != nil {
+			r.logger.Error("Failed to read song documents", zap.String("collection", collection), zap.Error(err))
+			return nil, err
+		}
+		documents[collection] = found
+	}
+	return documents, nil
+}
+
+// existingIDs returns which of the IDs the collection holds a document with
+func (r *MongoRepository) existingIDs(ctx context.Context, collection string, ids []int) (map[int]bool, error) {
+	found, err := r.findIDs(ctx, collection, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[int]bool, len(found))
+	for _, id := range found {
+		existing[id] = true
+	}
+	return existing, nil
+}
+
+// referencedIDs returns the integer values of the field across the documents
+func referencedIDs(documents []bson.M, field string) []int {
+	ids := []int{}
+	for _, document := range documents {
+		switch value := document[field].(type) {
+		case bson.A:
+			for _, item := range value {
+				if id, ok := mongoInt(item); ok {
+					ids = append(ids, id)
+				}
+			}
+		default:
+			if id, ok := mongoInt(value); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// restoreDocuments inserts documents read by songDocuments back into their collections, the songs keeping
+// their IDs. Documents of a user deleted since are left out, genres deleted since are unassigned, and songs
+// whose album was deleted are restored without it. The artists of the songs are created again when missing.
+func (r *MongoRepository) restoreDocuments(ctx context.Context, documents map[string][]bson.M) error {
+	songs := documents["songs"]
+	genres, err := r.existingIDs(ctx, "genres", referencedIDs(songs, "genre_ids"))
+	if err != nil {
+		return err
+	}
+	albums, err := r.existingIDs(ctx, "albums", referencedIDs(songs, "album_id"))
+	if err != nil {
+		return err
+	}
+	var users []int
+	for _, collection := range mongoSnapshotCollections[1:] {
+		users = append(users, referencedIDs(documents[collection], "user_id")...)
+	}
+	existingUsers, err := r.existingIDs(ctx, "users", users)
+	if err != nil {
+		return err
+	}
+
+	artists := make(map[string]int)
+	lastID := 0
+	for _, song := range songs {
+		kept := bson.A{}
+		assigned, _ := song["genre_ids"].(bson.A)
+		for _, genre := range assigned {
+			if id, ok := mongoInt(genre); ok && genres[id] {
+				kept = append(kept, id)
+			}
+		}
+		song["genre_ids"] = kept
+		if id, ok := mongoInt(song["album_id"]); ok && !albums[id] {
+			song["album_id"] = nil
+		}
+		group, _ := song["group_name"].(string)
+		if song["artist_id"], err = r.artistID(ctx, group, artists); err != nil {
+			return err
+		}
+		if id, ok := mongoInt(song["_id"]); ok {
+			lastID = max(lastID, id)
+		}
+	}
+
+	for _, collection := range mongoSnapshotCollections {
+		restored := make([]any, 0, len(documents[collection]))
+		for _, document := range documents[collection] {
+			if userID, ok := mongoInt(document["user_id"]); ok && !existingUsers[userID] {
+				continue
+			}
+			restored = append(restored, document)
+		}
+		for offset := 0; offset < len(restored); offset += insertBatchRows {
+			if err := r.insertMany(ctx, collection, restored[offset:min(offset+insertBatchRows, len(restored))], nil); err != nil {
+				r.logger.Error("Failed to restore documents", zap.String("collection", collection), zap.Error(err))
+				return err
+			}
+		}
+	}
+	if len(songs) == 0 {
+		return nil
+	}
+	ids := referencedIDs(songs, "_id")
+	// The ratings of deleted users were left out, so the averages kept on the songs are computed again
+	if err := r.updateSongRatings(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		return err
+	}
+	return r.advanceCounter(ctx, "songs", lastID)
+}
+
+// CreateSnapshot stores a copy of the songs and the documents attached to them, each as a document of the
+// snapshot_documents collection. The copy is read in a transaction, so a change made in the same
+// transaction, such as a truncate, loses no song the snapshot does not hold.
+func (r *MongoRepository) CreateSnapshot(ctx context.Context, reason string) (models.Snapshot, error) {
+	r.logger.Debug("Creating snapshot", zap.String("reason", reason))
+	var snapshot models.Snapshot
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		documents, err := r.songDocuments(ctx, bson.M{})
+		if err != nil {
+			return err
+		}
+		id, err := r.nextIDs(ctx, "snapshots", 1)
+		if err != nil {
+			return err
+		}
+		snapshot = models.Snapshot{ID: id, Reason: reason, SongCount: len(documents["songs"]), CreatedAt: time.Now().UTC()}
+		for _, collection := range mongoSnapshotCollections {
+			held := make([]any, len(documents[collection]))
+			for i, document := range documents[collection] {
+				held[i] = bson.M{"snapshot_id": id, "collection": collection, "document": document}
+			}
+			for offset := 0; offset < len(held); offset += insertBatchRows {
+				if err := r.insertMany(ctx, "snapshot_documents", held[offset:min(offset+insertBatchRows, len(held))], nil); err != nil {
+					return err
+				}
+			}
+		}
+		return r.insertOne(ctx, "snapshots", snapshot)
+	})
+	if err != nil {
+		r.logger.Error("Failed to create snapshot", zap.Error(err))
+		return models.Snapshot{}, err
+	}
+	r.logger.Info("Snapshot created in database", zap.Int("id", snapshot.ID), zap.Int("songs", snapshot.SongCount))
+	return snapshot, nil
+}
+
+// GetSnapshots returns the snapshots, newest first
+func (r *MongoRepository) GetSnapshots(ctx context.Context) ([]models.Snapshot, error) {
+	snapshots := []models.Snapshot{}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	if err := r.find(ctx, "snapshots", bson.M{}, opts, &snapshots); err != nil {
+		r.logger.Error("Failed to fetch snapshots", zap.Error(err))
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// RestoreSnapshot inserts the documents a snapshot holds back into their collections, the songs keeping
+// their IDs, and returns the number of songs restored. The songs collection is expected to be empty.
+// Documents of a user deleted since are left out, and songs lose the genres and album deleted since.
+// sql.ErrNoRows is returned when the snapshot does not exist.
+func (r *MongoRepository) RestoreSnapshot(ctx context.Context, id int) (int, error) {
+	r.logger.Debug("Restoring snapshot", zap.Int("id", id))
+	var snapshot models.Snapshot
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := r.findOne(ctx, "snapshots", bson.M{"_id": id}, nil, &snapshot); err != nil {
+			return err
+		}
+		var held []struct {
+			Collection string `bson:"collection"`
+			Document   bson.M `bson:"document"`
+		}
+		opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+		if err := r.find(ctx, "snapshot_documents", bson.M{"snapshot_id": id}, opts, &held); err != nil {
+			return err
+		}
+		documents := make(map[string][]bson.M, len(mongoSnapshotCollections))
+		for _, document := range held {
+			documents[document.Collection] = append(documents[document.Collection], document.Document)
+		}
+		if err := r.restoreDocuments(ctx, documents); err != nil {
+			return err
+		}
+		_, err := r.updateOne(ctx, "snapshots", bson.M{"_id": id}, bson.M{"$set": bson.M{"restored_at": time.Now().UTC()}})
+		return err
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to restore snapshot", zap.Int("id", id), zap.Error(err))
+		}
+		return 0, err
+	}
+	r.logger.Info("Snapshot restored in database", zap.Int("id", id), zap.Int("songs", snapshot.SongCount))
+	return snapshot.SongCount, nil
+}
+
+// TrashSong moves a song to the trash: it is deleted together with the documents attached to it, which the
+// trash keeps to restore them, the same ones a snapshot holds. sql.ErrNoRows is returned when the song does
+// not exist. A song in the trash under the same ID, left from before the IDs were reset, is replaced.
+func (r *MongoRepository) TrashSong(ctx context.Context, id int) error {
+	r.logger.Debug("Moving song to trash", zap.Int("id", id))
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		documents, err := r.songDocuments(ctx, bson.M{"song_id": id})
+		if err != nil {
+			return err
+		}
+		if len(documents["songs"]) == 0 {
+			return sql.ErrNoRows
+		}
+		song := documents["songs"][0]
+		trashed := bson.M{
+			"_id":        id,
+			"group_name": song["group_name"],
+			"song_name":  song["song_name"],
+			"deleted_at": time.Now().UTC(),
+			"data":       documents,
+		}
+		if err := r.replaceOne(ctx, "trashed_songs", bson.M{"_id": id}, trashed, options.Replace().SetUpsert(true)); err != nil {
+			return err
+		}
+		if _, err := r.deleteMany(ctx, "songs", bson.M{"_id": id}); err != nil {
+			return err
+		}
+		return r.deleteSongDocuments(ctx, bson.M{"song_id": id})
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to move song to trash", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	r.logger.Info("Song moved to trash in database", zap.Int("id", id))
+	return nil
+}
+
+// GetTrashedSong retrieves a song in the trash, returning sql.ErrNoRows when it is not there
+func (r *MongoRepository) GetTrashedSong(ctx context.Context, id int) (models.TrashedSong, error) {
+	return r.trash.Get(ctx, id)
+}
+
+// GetTrash retrieves a page of the songs in the trash, most recently deleted first, and their total number
+func (r *MongoRepository) GetTrash(ctx context.Context, page, limit int) ([]models.TrashedSong, int, error) {
+	r.logger.Debug("Fetching trash", zap.Int("page", page), zap.Int("limit", limit))
+	songs, err := r.trash.List(ctx, bson.M{}, bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: -1}}, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.trash.Count(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+	return songs, total, nil
+}
+
+// RestoreTrashedSong puts a song in the trash back into the catalog under its ID, with the documents
+// attached to it, and takes it out of the trash. Documents of a user deleted since are left out, and the
+// song loses the genres and album deleted since. The ID is expected to be free. sql.ErrNoRows is returned
+// when the song is not in the trash.
+func (r *MongoRepository) RestoreTrashedSong(ctx context.Context, id int) error {
+	r.logger.Debug("Restoring song from trash", zap.Int("id", id))
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		var trashed struct {
+			Data map[string][]bson.M `bson:"data"`
+		}
+		if err := r.findOne(ctx, "trashed_songs", bson.M{"_id": id}, nil, &trashed); err != nil {
+			return err
+		}
+		if err := r.restoreDocuments(ctx, trashed.Data); err != nil {
+			return err
+		}
+		_, err := r.deleteMany(ctx, "trashed_songs", bson.M{"_id": id})
+		return err
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to restore song from trash", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	r.logger.Info("Song restored from trash in database", zap.Int("id", id))
+	return nil
+}
+
+// DeleteTrashedSong deletes a song in the trash for good, returning sql.ErrNoRows when it is not there
+func (r *MongoRepository) DeleteTrashedSong(ctx context.Context, id int) error {
+	r.logger.Debug("Purging song from trash", zap.Int("id", id))
+	return r.trash.Delete(ctx, id)
+}
+
+// PurgeTrash deletes for good the songs moved to the trash before the given time and returns how many were
+// deleted
+func (r *MongoRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := r.deleteMany(ctx, "trashed_songs", bson.M{"deleted_at": bson.M{"$lt": before}})
+	if err != nil {
+		r.logger.Error("Failed to purge trash", zap.Error(err))
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// mongoRevisionData is the content of a revision as stored, under the JSON names of models.SongRevisionData
+type mongoRevisionData struct {
+	ID            int      `bson:"id"`
+	Group         string   `bson:"group"`
+	Song          string   `bson:"song"`
+	ReleaseDate   *string  `bson:"release_date"`
+	Text          *string  `bson:"text"`
+	Link          *string  `bson:"link"`
+	Notes         *string  `bson:"notes"`
+	LicensingFee  *float64 `bson:"licensing_fee"`
+	SplitStrategy *string  `bson:"split_strategy"`
+	AlbumID       *int     `bson:"album_id"`
+}
+
+// mongoSongRevision is a song revision as stored
+type mongoSongRevision struct {
+	models.SongRevision `bson:",inline"`
+	Data                mongoRevisionData `bson:"data"`
+}
+
+// decode returns the revision with its data
+func (revision mongoSongRevision) decode() models.SongRevision {
+	decoded := revision.SongRevision
+	decoded.Data = models.SongRevisionData(revision.Data)
+	return decoded
+}
+
+// AddSongRevision records the song's editable fields as they currently are as its next revision and
+// returns the revision number, or sql.ErrNoRows when the song does not exist. restoredFrom is the revision
+// a restore brought back, nil for other reasons.
+func (r *MongoRepository) AddSongRevision(ctx context.Context, songID int, reason string, restoredFrom *int) (int, error) {
+	r.logger.Debug("Recording song revision", zap.Int("song_id", songID), zap.String("reason", reason))
+	var revision mongoSongRevision
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		song, err := r.songs.Get(ctx, songID)
+		if err != nil {
+			return err
+		}
+		var latest []mongoSongRevision
+		opts := options.Find().SetSort(bson.D{{Key: "revision", Value: -1}}).SetLimit(1)
+		if err := r.find(ctx, "song_revisions", bson.M{"song_id": songID}, opts, &latest); err != nil {
+			return err
+		}
+		number := 1
+		if len(latest) > 0 {
+			number = latest[0].Revision + 1
+		}
+		revision = mongoSongRevision{
+			SongRevision: models.SongRevision{
+				SongID: songID, Revision: number, Reason: reason, RestoredFrom: restoredFrom, CreatedAt: time.Now().UTC(),
+			},
+			Data: mongoRevisionData{
+				ID: song.ID, Group: song.Group, Song: song.Song, ReleaseDate: song.ReleaseDate, Text: song.Text,
+				Link: song.Link, Notes: song.Notes, LicensingFee: song.LicensingFee, SplitStrategy: song.SplitStrategy,
+				AlbumID: song.AlbumID,
+			},
+		}
+		return r.insertOne(ctx, "song_revisions", revision)
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to record song revision", zap.Int("song_id", songID), zap.Error(err))
+		}
+		return 0, err
+	}
+	return revision.Revision, nil
+}
+
+// CountSongRevisions returns the number of revisions recorded for the song
+func (r *MongoRepository) CountSongRevisions(ctx context.Context, songID int) (int, error) {
+	count, err := r.count(ctx, "song_revisions", bson.M{"song_id": songID})
+	if err != nil {
+		r.logger.Error("Failed to count song revisions", zap.Int("song_id", songID), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetSongRevisions retrieves the revisions of a song, newest first
+func (r *MongoRepository) GetSongRevisions(ctx context.Context, songID int) ([]models.SongRevision, error) {
+	r.logger.Debug("Fetching song revisions", zap.Int("song_id", songID))
+	var stored []mongoSongRevision
+	opts := options.Find().SetSort(bson.D{{Key: "revision", Value: -1}})
+	if err := r.find(ctx, "song_revisions", bson.M{"song_id": songID}, opts, &stored); err != nil {
+		r.logger.Error("Failed to fetch song revisions", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+	revisions := make([]models.SongRevision, len(stored))
+	for i, revision := range stored {
+		revisions[i] = revision.decode()
+	}
+	return revisions, nil
+}
+
+// GetSongRevision retrieves a revision of a song, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) GetSongRevision(ctx context.Context, songID, revision int) (models.SongRevision, error) {
+	var stored mongoSongRevision
+	err := r.findOne(ctx, "song_revisions", bson.M{"song_id": songID, "revision": revision}, nil, &stored)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to fetch song revision", zap.Int("song_id", songID), zap.Int("revision", revision), zap.Error(err))
+		}
+		return models.SongRevision{}, err
+	}
+	return stored.decode(), nil
+}
+
+// BackfillLegacyRows normalizes songs written under the legacy data conventions in a single transaction:
+// missing timestamps are filled, blank optional fields become null and duplicate songs are merged into
+// the oldest one. The songs are updated without touching updated_at. With dryRun the same work is done
+// and reported, then aborted.
+func (r *MongoRepository) BackfillLegacyRows(ctx context.Context, dryRun bool) (models.BackfillReport, error) {
+	r.logger.Debug("Backfilling legacy rows", zap.Bool("dry_run", dryRun))
+	var report models.BackfillReport
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		report = models.BackfillReport{DryRun: dryRun, EmptyFields: make(map[string]int)}
+		missing, err := r.updateMany(ctx, "songs", bson.M{"created_at": nil},
+			bson.A{bson.M{"$set": bson.M{"created_at": bson.M{"$ifNull": bson.A{"$updated_at", "$$NOW"}}}}})
+		if err != nil {
+			return err
+		}
+		report.MissingCreatedAt = int(missing)
+		if missing, err = r.updateMany(ctx, "songs", bson.M{"updated_at": nil}, bson.A{bson.M{"$set": bson.M{"updated_at": "$created_at"}}}); err != nil {
+			return err
+		}
+		report.MissingUpdatedAt = int(missing)
+		for _, field := range backfillFields {
+			empty, err := r.updateMany(ctx, "songs", bson.M{field: mongoBlank}, bson.M{"$set": bson.M{field: nil}})
+			if err != nil {
+				return err
+			}
+			report.EmptyFields[field] = int(empty)
+		}
+		if report.Duplicates, err = r.mergeDuplicateSongs(ctx); err != nil {
+			return err
+		}
+		if dryRun {
+			return errBackfillDryRun
+		}
+		return nil
+	})
+	if dryRun && err == errBackfillDryRun {
+		r.logger.Info("Legacy rows backfill dry run finished", zap.Int("duplicates", len(report.Duplicates)))
+		return report, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to backfill legacy rows", zap.Error(err))
+		return report, err
+	}
+	r.logger.Info("Legacy rows backfilled", zap.Int("duplicates", len(report.Duplicates)))
+	return report, nil
+}
+
+// mergeDuplicateSongs merges every set of songs sharing a group and title into its oldest song,
+// which keeps its own values and takes the first known value of the others for its null fields.
+// Songs on legal hold are neither merged into nor merged away.
+func (r *MongoRepository) mergeDuplicateSongs(ctx context.Context) ([]models.DuplicateSongs, error) {
+	var songs []models.Song
+	opts := options.Find().SetProjection(bson.M{"group_name": 1, "song_name": 1}).SetSort(bson.D{{Key: "_id", Value: 1}})
+	if err := r.find(ctx, "songs", bson.M{"legal_hold": bson.M{"$ne": true}}, opts, &songs); err != nil {
+		r.logger.Error("Failed to find duplicate songs", zap.Error(err))
+		return nil, err
+	}
+
+	var duplicates []*models.DuplicateSongs
+	byKey := make(map[[2]string]*models.DuplicateSongs)
+	for _, song := range songs {
+		key := [2]string{strings.ToLower(strings.Trim(song.Group, " ")), strings.ToLower(strings.Trim(song.Song, " "))}
+		if duplicate, ok := byKey[key]; ok {
+			duplicate.RemovedIDs = append(duplicate.RemovedIDs, song.ID)
+			continue
+		}
+		duplicate := &models.DuplicateSongs{Group: song.Group, Song: song.Song, KeptID: song.ID}
+		byKey[key] = duplicate
+		duplicates = append(duplicates, duplicate)
+	}
+
+	merged := make([]models.DuplicateSongs, 0)
+	for _, duplicate := range duplicates {
+		if len(duplicate.RemovedIDs) == 0 {
+			continue
+		}
+		if err := r.mergeSongs(ctx, duplicate.KeptID, duplicate.RemovedIDs); err != nil {
+			r.logger.Error("Failed to merge duplicate songs", zap.Int("kept_id", duplicate.KeptID), zap.Error(err))
+			return nil, err
+		}
+		merged = append(merged, *duplicate)
+	}
+	return merged, nil
+}
+
+// mergeSongs fills the null fields of the kept song from the removed ones, adds up their views, carries
+// over their tags, genres, titles and the documents of mongoMergedCollections and their revisions, and
+// deletes them
+func (r *MongoRepository) mergeSongs(ctx context.Context, keptID int, removedIDs []int) error {
+	kept, err := r.songs.Get(ctx, keptID)
+	if err != nil {
+		return err
+	}
+	var keptTitles struct {
+		Titles []bson.M `bson:"titles"`
+	}
+	if err := r.findOne(ctx, "songs", bson.M{"_id": keptID}, options.FindOne().SetProjection(bson.M{"titles": 1}), &keptTitles); err != nil {
+		return err
+	}
+	var removed []struct {
+		models.Song `bson:",inline"`
+		Tags        []string `bson:"tags"`
+		GenreIDs    []int    `bson:"genre_ids"`
+		Titles      []bson.M `bson:"titles"`
+	}
+	opts := options.Find().SetProjection(bson.M{"embedding": 0, "verse_index": 0}).SetSort(bson.D{{Key: "_id", Value: 1}})
+	if err := r.find(ctx, "songs", bson.M{"_id": bson.M{"$in": removedIDs}}, opts, &removed); err != nil {
+		return err
+	}
+	values := bson.M{}
+	tags, genres, titles := bson.A{}, bson.A{}, bson.A{}
+	langs := map[any]bool{}
+	for _, title := range keptTitles.Titles {
+		langs[title["lang"]] = true
+	}
+	var views int64
+	for _, song := range removed {
+		for field, value := range map[string]*string{"release_date": song.ReleaseDate, "text": song.Text, "link": song.Link} {
+			if _, set := values[field]; !set && value != nil {
+				values[field] = *value
+			}
+		}
+		views += song.Views
+		for _, tag := range song.Tags {
+			tags = append(tags, tag)
+		}
+		for _, genre := range song.GenreIDs {
+			genres = append(genres, genre)
+		}
+		// The title of the oldest song is kept for each language
+		for _, title := range song.Titles {
+			if !langs[title["lang"]] {
+				langs[title["lang"]] = true
+				titles = append(titles, title)
+			}
+		}
+	}
+	for field, value := range map[string]*string{"release_date": kept.ReleaseDate, "text": kept.Text, "link": kept.Link} {
+		if value != nil {
+			delete(values, field)
+		}
+	}
+	update := bson.M{
+		"$inc":      bson.M{"views": views},
+		"$addToSet": bson.M{"tags": bson.M{"$each": tags}, "genre_ids": bson.M{"$each": genres}},
+		"$push":     bson.M{"titles": bson.M{"$each": titles}},
+	}
+	if len(values) > 0 {
+		update["$set"] = values
+	}
+	if _, err := r.updateOne(ctx, "songs", bson.M{"_id": keptID}, update); err != nil {
+		return err
+	}
+	if err := r.mergeViewDays(ctx, keptID, removedIDs); err != nil {
+		return err
+	}
+	if err := r.mergeSongDocuments(ctx, keptID, removedIDs); err != nil {
+		return err
+	}
+	if err := r.mergeRevisions(ctx, keptID, removedIDs); err != nil {
+		return err
+	}
+	if _, err := r.deleteMany(ctx, "songs", bson.M{"_id": bson.M{"$in": removedIDs}}); err != nil {
+		return err
+	}
+	if err := r.deleteSongDocuments(ctx, bson.M{"song_id": bson.M{"$in": removedIDs}}); err != nil {
+		return err
+	}
+	return r.updateSongRatings(ctx, bson.M{"_id": keptID})
+}
+
+// mongoMergedCollection is a collection whose documents belong to a song and are carried over to the kept
+// song when duplicates are merged
+type mongoMergedCollection struct {
+	name string
+	// keys identify a document among those of a song; a document whose keys the kept song already has is
+	// left to be deleted with the removed song
+	keys []string
+	// sort picks the document carried over when several removed songs have one with the same keys
+	sort bson.D
+}
+
+// mongoMergedCollections are the collections carried over by a merge, as mergedSongTables are for the
+// SQL repositories
+var mongoMergedCollections = []mongoMergedCollection{
+	{name: "song_ratings", keys: []string{"user_id"}, sort: bson.D{{Key: "updated_at", Value: -1}, {Key: "song_id", Value: 1}}},
+	{name: "song_favorites", keys: []string{"user_id"}, sort: bson.D{{Key: "created_at", Value: 1}, {Key: "song_id", Value: 1}}},
+	{name: "song_overrides", keys: []string{"user_id"}, sort: bson.D{{Key: "updated_at", Value: -1}, {Key: "song_id", Value: 1}}},
+	{name: "classification_suggestions", keys: []string{"kind", "value"}, sort: bson.D{{Key: "_id", Value: 1}}},
+}
+
+// mergeSongDocuments moves the documents of mongoMergedCollections from the removed songs to the kept song
+func (r *MongoRepository) mergeSongDocuments(ctx context.Context, keptID int, removedIDs []int) error {
+	for _, collection := range mongoMergedCollections {
+		key := func(document bson.M) string {
+			values := make([]string, len(collection.keys))
+			for i, field := range collection.keys {
+				values[i] = fmt.Sprint(document[field])
+			}
+			return strings.Join(values, "\x00")
+		}
+		var existing, removed []bson.M
+		if err := r.find(ctx, collection.name, bson.M{"song_id": keptID}, nil, &existing); err != nil {
+			return err
+		}
+		opts := options.Find().SetSort(collection.sort)
+		if err := r.find(ctx, collection.name, bson.M{"song_id": bson.M{"$in": removedIDs}}, opts, &removed); err != nil {
+			return err
+		}
+		claimed := make(map[string]bool, len(existing))
+		for _, document := range existing {
+			claimed[key(document)] = true
+		}
+		var writes []mongo.WriteModel
+		for _, document := range removed {
+			if claimed[key(document)] {
+				continue
+			}
+			claimed[key(document)] = true
+			writes = append(writes, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": document["_id"]}).
+				SetUpdate(bson.M{"$set": bson.M{"song_id": keptID}}))
+		}
+		if len(writes) == 0 {
+			continue
+		}
+		if _, err := r.bulkWrite(ctx, collection.name, writes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeRevisions moves the revisions of the removed songs to the kept song, numbered after its own in the
+// order they were recorded, restored_from following the renumbering
+func (r *MongoRepository) mergeRevisions(ctx context.Context, keptID int, removedIDs []int) error {
+	var latest, removed []mongoSongRevision
+	opts := options.Find().SetSort(bson.D{{Key: "revision", Value: -1}}).SetLimit(1)
+	if err := r.find(ctx, "song_revisions", bson.M{"song_id": keptID}, opts, &latest); err != nil {
+		return err
+	}
+	opts = options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "song_id", Value: 1}, {Key: "revision", Value: 1}})
+	if err := r.find(ctx, "song_revisions", bson.M{"song_id": bson.M{"$in": removedIDs}}, opts, &removed); err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	next := 1
+	if len(latest) > 0 {
+		next = latest[0].Revision + 1
+	}
+	numbers := make(map[[2]int]int, len(removed))
+	for i, revision := range removed {
+		numbers[[2]int{revision.SongID, revision.Revision}] = next + i
+	}
+	writes := make([]mongo.WriteModel, len(removed))
+	for i, revision := range removed {
+		var restoredFrom any
+		if revision.RestoredFrom != nil {
+			if number, ok := numbers[[2]int{revision.SongID, *revision.RestoredFrom}]; ok {
+				restoredFrom = number
+			}
+		}
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"song_id": revision.SongID, "revision": revision.Revision}).
+			SetUpdate(bson.M{"$set": bson.M{"song_id": keptID, "revision": next + i, "restored_from": restoredFrom}})
+	}
+	_, err := r.bulkWrite(ctx, "song_revisions", writes)
+	return err
+}
+
+// mergeViewDays adds the daily views of the removed songs to those of the kept song
+func (r *MongoRepository) mergeViewDays(ctx context.Context, keptID int, removedIDs []int) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"song_id": bson.M{"$in": removedIDs}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$day", "views": bson.M{"$sum": "$views"}}}},
+	}
+	var days []struct {
+		Day   string `bson:"_id"`
+		Views int64  `bson:"views"`
+	}
+	if err := r.aggregate(ctx, "song_view_days", pipeline, &days); err != nil {
+		return err
+	}
+	if len(days) == 0 {
+		return nil
+	}
+	writes := make([]mongo.WriteModel, len(days))
+	for i, day := range days {
+		writes[i] = mongo.NewUpdateOneModel().SetFilter(bson.M{"song_id": keptID, "day": day.Day}).
+			SetUpdate(bson.M{"$inc": bson.M{"views": day.Views}}).SetUpsert(true)
+	}
+	_, err := r.bulkWrite(ctx, "song_view_days", writes)
+	return err
+}
+
+// mongoVerse is a verse as stored in a song's verse index
+type mongoVerse struct {
+	Number int    `bson:"number"`
+	Label  string `bson:"label"`
+	Text   string `bson:"text"`
+}
+
+// mongoVerseIndex is the verse index kept on a song's document. The verses are stored cut out of the text,
+// so a page of them is read with $slice without the text.
+type mongoVerseIndex struct {
+	Delimiter   string       `bson:"delimiter"`
+	ContentHash string       `bson:"content_hash"`
+	TotalVerses int          `bson:"total_verses"`
+	IndexedAt   time.Time    `bson:"indexed_at"`
+	Verses      []mongoVerse `bson:"verses"`
+}
+
+// cutVerses cuts the verses located by the spans out of the text, counting characters with CRLF line
+// endings turned into LF
+func cutVerses(text string, spans []models.VerseSpan) []mongoVerse {
+	runes := []rune(strings.ReplaceAll(text, "\r\n", "\n"))
+	verses := make([]mongoVerse, len(spans))
+	for i, span := range spans {
+		start := min(max(span.Start, 0), len(runes))
+		end := min(max(start+span.Length, start), len(runes))
+		verses[i] = mongoVerse{Number: span.Number, Label: span.Label, Text: string(runes[start:end])}
+	}
+	return verses
+}
+
+// SaveVerseIndex stores the verses of a song's text for the delimiter on its document, replacing its
+// previous index. Nothing is stored when the song's text no longer hashes to textHash, as another write
+// changed it since the spans were computed.
+func (r *MongoRepository) SaveVerseIndex(ctx context.Context, songID int, delimiter, textHash string, spans []models.VerseSpan) error {
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		song, err := r.songs.Get(ctx, songID)
+		if err != nil {
+			return err
+		}
+		text := models.StringValue(song.Text)
+		if TextHash(text) != textHash {
+			r.logger.Debug("Song text changed, verse index not stored", zap.Int("song_id", songID))
+			return nil
+		}
+		index := mongoVerseIndex{
+			Delimiter:   delimiter,
+			ContentHash: textHash,
+			TotalVerses: len(spans),
+			IndexedAt:   time.Now().UTC(),
+			Verses:      cutVerses(text, spans),
+		}
+		// The song's text is matched too, so an index is not stored over a concurrent edit
+		_, err = r.updateOne(ctx, "songs", bson.M{"_id": songID, "text": song.Text}, bson.M{"$set": bson.M{"verse_index": index}})
+		return err
+	})
+	if err != nil {
+		r.logger.Error("Failed to store verse index", zap.Int("song_id", songID), zap.Error(err))
+	}
+	return err
+}
+
+// GetVerseIndex returns a song with its split strategy and, when an index of its current text is stored,
+// the delimiter it was split at and the number of verses it has
+func (r *MongoRepository) GetVerseIndex(ctx context.Context, songID int) (models.VerseIndex, error) {
+	var song struct {
+		models.Song `bson:",inline"`
+		VerseIndex  *mongoVerseIndex `bson:"verse_index"`
+	}
+	projection := bson.M{
+		"group_name": 1, "song_name": 1, "split_strategy": 1, "text": 1,
+		"verse_index.delimiter": 1, "verse_index.content_hash": 1, "verse_index.total_verses": 1,
+	}
+	err := r.findOne(ctx, "songs", bson.M{"_id": songID}, options.FindOne().SetProjection(projection), &song)
+	if err != nil {
+		r.logger.Error("Failed to fetch verse index", zap.Int("song_id", songID), zap.Error(err))
+		return models.VerseIndex{}, err
+	}
+	index := models.VerseIndex{SongID: song.ID, Group: song.Group, Song: song.Song.Song, SplitStrategy: song.SplitStrategy}
+	if song.VerseIndex != nil && song.VerseIndex.ContentHash == TextHash(models.StringValue(song.Text)) {
+		index.Indexed, index.Delimiter, index.TotalVerses = true, song.VerseIndex.Delimiter, song.VerseIndex.TotalVerses
+	}
+	return index, nil
+}
+
+// GetIndexedVerses reads the verses numbered from through to out of a song's verse index, as a $slice of
+// the stored array. No verses are returned when the text changed since it was indexed.
+func (r *MongoRepository) GetIndexedVerses(ctx context.Context, songID int, delimiter string, from, to int) ([]models.IndexedVerse, error) {
+	verses := []models.IndexedVerse{}
+	from = max(from, 1)
+	if to < from {
+		return verses, nil
+	}
+	var song struct {
+		Text       *string          `bson:"text"`
+		VerseIndex *mongoVerseIndex `bson:"verse_index"`
+	}
+	projection := bson.M{
+		"text": 1, "verse_index.delimiter": 1, "verse_index.content_hash": 1,
+		"verse_index.verses": bson.M{"$slice": bson.A{from - 1, to - from + 1}},
+	}
+	filter := bson.M{"_id": songID, "verse_index.delimiter": delimiter}
+	err := r.findOne(ctx, "songs", filter, options.FindOne().SetProjection(projection), &song)
+	if err == sql.ErrNoRows {
+		return verses, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to fetch indexed verses", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+	if song.VerseIndex.ContentHash != TextHash(models.StringValue(song.Text)) {
+		return verses, nil
+	}
+	for _, verse := range song.VerseIndex.Verses {
+		verses = append(verses, models.IndexedVerse{Number: verse.Number, Label: verse.Label, Text: verse.Text})
+	}
+	return verses, nil
+}
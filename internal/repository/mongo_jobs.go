@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// mongoDayFormat is the format days are stored in
+const mongoDayFormat = "2006-01-02"
+
+// CreateImport registers a new import so its progress can be checkpointed
+func (r *MongoRepository) CreateImport(ctx context.Context, id string) (models.Import, error) {
+	r.logger.Debug("Creating import", zap.String("import_id", id))
+	now := time.Now().UTC()
+	imp := models.Import{ID: id, Status: "running", CreatedAt: now, UpdatedAt: now}
+	if err := r.insertOne(ctx, "imports", imp); err != nil {
+		r.logger.Error("Failed to create import", zap.Error(err))
+		return models.Import{}, err
+	}
+	return imp, nil
+}
+
+// GetImport retrieves an import and its checkpoint
+func (r *MongoRepository) GetImport(ctx context.Context, id string) (models.Import, error) {
+	r.logger.Debug("Fetching import", zap.String("import_id", id))
+	var imp models.Import
+	err := r.findOne(ctx, "imports", bson.M{"_id": id}, nil, &imp)
+	if err != nil {
+		r.logger.Warn("Failed to fetch import", zap.String("import_id", id), zap.Error(err))
+		return imp, err
+	}
+	return imp, nil
+}
+
+// ImportBatch writes a batch of imported songs and advances the import checkpoint in one transaction,
+// so an interrupted import resumes exactly after the last committed batch.
+// Existing songs only have their release date, text and link replaced by non-empty values.
+func (r *MongoRepository) ImportBatch(ctx context.Context, importID string, songs []models.ImportSong, checkpointRow, failed int) ([]int, error) {
+	r.logger.Debug("Writing import batch", zap.String("import_id", importID), zap.Int("songs", len(songs)), zap.Int("checkpoint_row", checkpointRow))
+	var ids []int
+	var created []int
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		ids, created = make([]int, len(songs)), nil
+		var inputs []models.SongInput
+		now := time.Now().UTC()
+		for i, song := range songs {
+			if song.ExistingID != 0 {
+				values := bson.M{"updated_at": now}
+				for field, value := range map[string]string{"release_date": song.ReleaseDate, "text": song.Text, "link": song.Link} {
+					if value != "" {
+						values[field] = value
+					}
+				}
+				if song.EnrichedAt != nil {
+					values["enriched_at"] = song.EnrichedAt
+				}
+				if _, err := r.updateOne(ctx, "songs", bson.M{"_id": song.ExistingID}, bson.M{"$set": values}); err != nil {
+					r.logger.Error("Failed to update imported song", zap.Int("row", song.Row), zap.Error(err))
+					return err
+				}
+				ids[i] = song.ExistingID
+				continue
+			}
+			created = append(created, i)
+			inputs = append(inputs, models.SongInput{Group: song.Group, Song: song.Song, ReleaseDate: song.ReleaseDate,
+				Text: song.Text, Link: song.Link, EnrichedAt: song.EnrichedAt})
+		}
+		// The new songs are written by batch inserts, which is what makes large imports fast
+		createdIDs, err := r.copySongs(ctx, inputs)
+		if err != nil {
+			r.logger.Error("Failed to insert imported songs", zap.Int("first_row", songs[created[0]].Row), zap.Error(err))
+			return err
+		}
+		for i, index := range created {
+			ids[index] = createdIDs[i]
+		}
+		_, err = r.updateOne(ctx, "imports", bson.M{"_id": importID}, bson.M{
+			"$set": bson.M{"checkpoint_row": checkpointRow, "updated_at": now},
+			"$inc": bson.M{"created": len(created), "updated": len(songs) - len(created), "failed": failed},
+		})
+		if err != nil {
+			r.logger.Error("Failed to checkpoint import", zap.Error(err))
+		}
+		return err
+	})
+	if err != nil {
+		r.logger.Error("Failed to write import batch", zap.String("import_id", importID), zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Import batch written", zap.String("import_id", importID), zap.Int("created", len(created)), zap.Int("updated", len(songs)-len(created)))
+	return ids, nil
+}
+
+// FinishImport records the final status of an import
+func (r *MongoRepository) FinishImport(ctx context.Context, id, status string) (models.Import, error) {
+	r.logger.Debug("Finishing import", zap.String("import_id", id), zap.String("status", status))
+	var imp models.Import
+	update := bson.M{"$set": bson.M{"status": status, "updated_at": time.Now().UTC()}}
+	err := r.findOneAndUpdate(ctx, "imports", bson.M{"_id": id}, update, options.FindOneAndUpdate().SetReturnDocument(options.After), &imp)
+	if err != nil {
+		r.logger.Error("Failed to finish import", zap.String("import_id", id), zap.Error(err))
+		return imp, err
+	}
+	return imp, nil
+}
+
+// CreateJob registers a queued job, owned by the user with the ID unless it is nil
+func (r *MongoRepository) CreateJob(ctx context.Context, id, kind string, ownerID *int) (models.Job, error) {
+	r.logger.Debug("Creating job", zap.String("job_id", id), zap.String("kind", kind))
+	now := time.Now().UTC()
+	job := models.Job{ID: id, Kind: kind, Status: models.JobQueued, OwnerID: ownerID, CreatedAt: now, UpdatedAt: now}
+	if err := r.insertOne(ctx, "jobs", job); err != nil {
+		r.logger.Error("Failed to create job", zap.Error(err))
+		return models.Job{}, err
+	}
+	return job, nil
+}
+
+// GetJob retrieves a job and its progress
+func (r *MongoRepository) GetJob(ctx context.Context, id string) (models.Job, error) {
+	r.logger.Debug("Fetching job", zap.String("job_id", id))
+	var job models.Job
+	err := r.findOne(ctx, "jobs", bson.M{"_id": id}, nil, &job)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch job", zap.String("job_id", id), zap.Error(err))
+	}
+	return job, err
+}
+
+// StartJob marks a queued job running
+func (r *MongoRepository) StartJob(ctx context.Context, id string) error {
+	now := time.Now().UTC()
+	return r.updateJob(ctx, id, bson.M{"status": models.JobRunning, "started_at": now, "updated_at": now})
+}
+
+// UpdateJobProgress stores the progress counters of a running job
+func (r *MongoRepository) UpdateJobProgress(ctx context.Context, id string, total, processed, failed int) error {
+	return r.updateJob(ctx, id, bson.M{"total": total, "processed": processed, "failed": failed, "updated_at": time.Now().UTC()})
+}
+
+// FinishJob stores the final status, counters and outcome of a job. An empty result or message is stored as null.
+func (r *MongoRepository) FinishJob(ctx context.Context, id, status string, total, processed, failed int, result []byte, message string) error {
+	now := time.Now().UTC()
+	// The result is stored as binary data, which is read back as the bytes json.RawMessage expects
+	var resultValue any
+	if len(result) > 0 {
+		resultValue = result
+	}
+	return r.updateJob(ctx, id, bson.M{
+		"status":      status,
+		"total":       total,
+		"processed":   processed,
+		"failed":      failed,
+		"result":      resultValue,
+		"error":       mongoNullIfEmpty(message),
+		"finished_at": now,
+		"updated_at":  now,
+	})
+}
+
+// FailUnfinishedJobs marks failed the jobs left queued or running, whose work was lost when the process stopped
+func (r *MongoRepository) FailUnfinishedJobs(ctx context.Context, message string) (int64, error) {
+	now := time.Now().UTC()
+	filter := bson.M{"status": bson.M{"$in": bson.A{models.JobQueued, models.JobRunning}}}
+	update := bson.M{"$set": bson.M{"status": models.JobFailed, "error": message, "finished_at": now, "updated_at": now}}
+	failed, err := r.updateMany(ctx, "jobs", filter, update)
+	if err != nil {
+		r.logger.Error("Failed to fail unfinished jobs", zap.Error(err))
+		return 0, err
+	}
+	return failed, nil
+}
+
+// updateJob sets fields of a single job, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) updateJob(ctx context.Context, id string, values bson.M) error {
+	result, err := r.updateOne(ctx, "jobs", bson.M{"_id": id}, bson.M{"$set": values})
+	if err != nil {
+		r.logger.Error("Failed to update job", zap.String("job_id", id), zap.Error(err))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// IncrementProviderUsage counts a call to the provider on the day and returns the day's total
+func (r *MongoRepository) IncrementProviderUsage(ctx context.Context, provider string, day time.Time) (int, error) {
+	var usage struct {
+		Calls int `bson:"calls"`
+	}
+	filter := bson.M{"provider": provider, "day": day.UTC().Format(mongoDayFormat)}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if err := r.findOneAndUpdate(ctx, "provider_usage", filter, bson.M{"$inc": bson.M{"calls": 1}}, opts, &usage); err != nil {
+		r.logger.Error("Failed to increment provider usage", zap.String("provider", provider), zap.Error(err))
+		return 0, err
+	}
+	return usage.Calls, nil
+}
+
+// GetProviderUsage returns the number of calls counted for the provider on the day
+func (r *MongoRepository) GetProviderUsage(ctx context.Context, provider string, day time.Time) (int, error) {
+	var usage struct {
+		Calls int `bson:"calls"`
+	}
+	err := r.findOne(ctx, "provider_usage", bson.M{"provider": provider, "day": day.UTC().Format(mongoDayFormat)}, nil, &usage)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to fetch provider usage", zap.String("provider", provider), zap.Error(err))
+		return 0, err
+	}
+	return usage.Calls, nil
+}
+
+// AddAPICapture stores a captured external API exchange and drops all but the newest keep captures
+func (r *MongoRepository) AddAPICapture(ctx context.Context, capture models.APICapture, keep int) error {
+	id, err := r.nextIDs(ctx, "api_captures", 1)
+	if err != nil {
+		return err
+	}
+	capture.ID, capture.CreatedAt = int64(id), time.Now().UTC()
+	// The headers are stored as binary data, which is read back as the bytes json.RawMessage expects
+	if err := r.insertOne(ctx, "api_captures", capture); err != nil {
+		r.logger.Error("Failed to store API capture", zap.String("provider", capture.Provider), zap.Error(err))
+		return err
+	}
+	// The new capture counts toward keep through its ID
+	if _, err := r.deleteMany(ctx, "api_captures", bson.M{"_id": bson.M{"$lte": id - keep}}); err != nil {
+		r.logger.Error("Failed to drop old API captures", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetAPICaptures returns the newest captured exchanges, of every provider when provider is empty
+func (r *MongoRepository) GetAPICaptures(ctx context.Context, provider string, limit int) ([]models.APICapture, error) {
+	r.logger.Debug("Fetching API captures", zap.String("provider", provider), zap.Int("limit", limit))
+	filter := bson.M{}
+	if provider != "" {
+		filter["provider"] = provider
+	}
+	captures := []models.APICapture{}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(limit))
+	if err := r.find(ctx, "api_captures", filter, opts, &captures); err != nil {
+		r.logger.Error("Failed to fetch API captures", zap.Error(err))
+		return nil, err
+	}
+	return captures, nil
+}
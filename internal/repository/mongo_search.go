@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// mongoTextSearch returns the $search string of the $text query selecting the songs that may match the
+// search: any of the words of its clauses, which $text ORs. The text index uses the language none, so
+// the words are neither stemmed nor dropped as stopwords.
+func mongoTextSearch(query searchQuery) string {
+	var words []string
+	seen := make(map[string]bool)
+	for _, clause := range query.clauses {
+		for _, phrase := range clause {
+			for _, word := range phrase {
+				if !seen[word] {
+					seen[word] = true
+					words = append(words, word)
+				}
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// mongoEmbedding is the embedding kept on a song's document
+type mongoEmbedding struct {
+	Model       string    `bson:"model"`
+	ContentHash string    `bson:"content_hash"`
+	Vector      []float64 `bson:"vector"`
+	UpdatedAt   time.Time `bson:"updated_at"`
+}
+
+// SearchSongs finds songs whose title, in any language, group or lyrics match the query, using web-search syntax
+// ("quoted phrases", OR, -excluded). Title matches rank above group matches, which rank above lyrics matches.
+// Each result carries a lyrics snippet with the matches wrapped in <mark> tags.
+// The text index narrows the songs down, which are then ranked as in SQLiteRepository, so that both
+// backends agree with PostgreSQL's simple text search configuration.
+func (r *MongoRepository) SearchSongs(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	r.logger.Debug("Searching songs", zap.String("query", query), zap.Int("limit", limit))
+	parsed := parseSearchQuery(query)
+	if len(parsed.clauses) == 0 {
+		return []models.SearchResult{}, nil
+	}
+
+	var candidates []struct {
+		models.Song `bson:",inline"`
+		Titles      []models.SongTitle `bson:"titles"`
+	}
+	opts := options.Find().SetProjection(bson.M{"group_name": 1, "song_name": 1, "text": 1, "titles.title": 1})
+	if err := r.find(ctx, "songs", bson.M{"$text": bson.M{"$search": mongoTextSearch(parsed)}}, opts, &candidates); err != nil {
+		r.logger.Error("Failed to search songs", zap.Error(err))
+		return nil, err
+	}
+
+	weights := []float64{titleWeight, groupWeight, textWeight}
+	scores := make(map[int]float64, len(candidates))
+	for _, song := range candidates {
+		score := parsed.rank([][]string{searchWords(song.Song.Song), searchWords(song.Group), searchWords(models.StringValue(song.Text))}, weights)
+		for _, title := range song.Titles {
+			score = max(score, parsed.rank([][]string{searchWords(title.Title), nil, nil}, weights))
+		}
+		if score > 0 {
+			scores[song.ID] = score
+		}
+	}
+
+	results, err := r.rankedSongs(ctx, scores, limit)
+	if err != nil {
+		r.logger.Error("Failed to search songs", zap.Error(err))
+		return nil, err
+	}
+	for i := range results {
+		results[i].Snippet = searchSnippet(query, models.StringValue(results[i].Text))
+	}
+	r.logger.Info("Songs searched in database", zap.Int("count", len(results)))
+	return results, nil
+}
+
+// rankedSongs retrieves the limit songs scoring highest, ties broken by ID, with their scores
+func (r *MongoRepository) rankedSongs(ctx context.Context, scores map[int]float64, limit int) ([]models.SearchResult, error) {
+	ids := make([]int, 0, len(scores))
+	for id, score := range scores {
+		if score > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	results := []models.SearchResult{}
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	songs, err := r.songs.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.Song, len(songs))
+	for _, song := range songs {
+		byID[song.ID] = song
+	}
+	for _, id := range ids {
+		// A song deleted since it was ranked is left out
+		if song, ok := byID[id]; ok {
+			results = append(results, models.SearchResult{Song: song, Score: scores[id]})
+		}
+	}
+	return results, nil
+}
+
+// HasSongEmbeddings reports whether song embeddings are stored, which they always are in MongoDB
+func (r *MongoRepository) HasSongEmbeddings(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// GetSongsNeedingEmbedding retrieves up to limit songs without an embedding from the model,
+// or whose content changed since it was computed. MongoDB cannot hash the content in a query, so the
+// songs are read in batches and compared here.
+func (r *MongoRepository) GetSongsNeedingEmbedding(ctx context.Context, model string, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs needing embeddings", zap.String("model", model), zap.Int("limit", limit))
+	needing := []models.Song{}
+	projection := bson.M{"embedding.vector": 0, "verse_index": 0, "titles": 0}
+	for lastID := 0; len(needing) < limit; {
+		var batch []struct {
+			models.Song `bson:",inline"`
+			Embedding   *mongoEmbedding `bson:"embedding"`
+		}
+		opts := options.Find().SetProjection(projection).SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(insertBatchRows)
+		if err := r.find(ctx, "songs", bson.M{"_id": bson.M{"$gt": lastID}}, opts, &batch); err != nil {
+			r.logger.Error("Failed to fetch songs needing embeddings", zap.Error(err))
+			return nil, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, song := range batch {
+			lastID = song.ID
+			if song.Embedding == nil || song.Embedding.Model != model || song.Embedding.ContentHash != SongContentHash(song.Song) {
+				needing = append(needing, song.Song)
+			}
+		}
+	}
+	if len(needing) > limit {
+		needing = needing[:limit]
+	}
+	return needing, nil
+}
+
+// SaveSongEmbedding stores the embedding of a song on its document, replacing any previous one
+func (r *MongoRepository) SaveSongEmbedding(ctx context.Context, songID int, model, contentHash string, embedding []float32) error {
+	vector := make([]float64, len(embedding))
+	for i, value := range embedding {
+		vector[i] = float64(value)
+	}
+	stored := mongoEmbedding{Model: model, ContentHash: contentHash, Vector: vector, UpdatedAt: time.Now().UTC()}
+	if _, err := r.updateOne(ctx, "songs", bson.M{"_id": songID}, bson.M{"$set": bson.M{"embedding": stored}}); err != nil {
+		r.logger.Error("Failed to save song embedding", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// SearchSongsSemantic ranks songs embedded with the model by cosine similarity to the query embedding.
+// When keywordWeight is positive, the similarity is blended with the full-text rank of the keywords:
+// score = (1 - keywordWeight) * similarity + keywordWeight * rank, both in [0, 1].
+// The embeddings are read and compared here.
+func (r *MongoRepository) SearchSongsSemantic(ctx context.Context, model string, embedding []float32, keywords string, keywordWeight float64, limit int) ([]models.SearchResult, error) {
+	r.logger.Debug("Searching songs semantically", zap.String("model", model), zap.Float64("keyword_weight", keywordWeight))
+	var songs []struct {
+		models.Song `bson:",inline"`
+		Embedding   mongoEmbedding `bson:"embedding"`
+	}
+	opts := options.Find().SetProjection(bson.M{"group_name": 1, "song_name": 1, "text": 1, "embedding.vector": 1})
+	if err := r.find(ctx, "songs", bson.M{"embedding.model": model}, opts, &songs); err != nil {
+		r.logger.Error("Failed to search songs semantically", zap.Error(err))
+		return nil, err
+	}
+
+	target := make([]float64, len(embedding))
+	for i, value := range embedding {
+		target[i] = float64(value)
+	}
+	parsed := parseSearchQuery(keywords)
+	weights := []float64{titleWeight, groupWeight, textWeight}
+	scores := make(map[int]float64, len(songs))
+	for _, song := range songs {
+		rank := parsed.rank([][]string{searchWords(song.Song.Song), searchWords(song.Group), searchWords(models.StringValue(song.Text))}, weights)
+		scores[song.ID] = (1-keywordWeight)*cosineSimilarity(song.Embedding.Vector, target) + keywordWeight*rank
+	}
+
+	results, err := r.rankedSongs(ctx, scores, limit)
+	if err != nil {
+		r.logger.Error("Failed to search songs semantically", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Semantic search finished", zap.Int("count", len(results)))
+	return results, nil
+}
+
+// HasSearchSuggestions reports whether search suggestions are available, which they always are in MongoDB
+func (r *MongoRepository) HasSearchSuggestions(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// SimilarSongNames retrieves up to limit group and song names within the trigram similarity threshold of
+// the query, closest first. MongoDB has no trigram similarity, so the names are read and compared here.
+func (r *MongoRepository) SimilarSongNames(ctx context.Context, query string, limit int) ([]models.SearchSuggestion, error) {
+	r.logger.Debug("Fetching similar song names", zap.String("query", query), zap.Int("limit", limit))
+	suggestions := []models.SearchSuggestion{}
+	for field, source := range map[string]string{"group_name": models.SuggestionGroup, "song_name": models.SuggestionSong} {
+		var names []struct {
+			Name string `bson:"_id"`
+		}
+		if err := r.aggregate(ctx, "songs", mongo.Pipeline{{{Key: "$group", Value: bson.M{"_id": "$" + field}}}}, &names); err != nil {
+			r.logger.Error("Failed to fetch similar song names", zap.Error(err))
+			return nil, err
+		}
+		for _, name := range names {
+			if score := trigramSimilarity(name.Name, query); score >= similarityThreshold {
+				suggestions = append(suggestions, models.SearchSuggestion{Query: name.Name, Source: source, Score: score})
+			}
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		if suggestions[i].Query != suggestions[j].Query {
+			return suggestions[i].Query < suggestions[j].Query
+		}
+		return suggestions[i].Source < suggestions[j].Source
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// SimilarSearchTerms maps each word that is not a known lyric term to the most similar one within the
+// trigram similarity threshold, preferring the terms found in more songs. Words without a match are left out.
+// The suggestions carry the term and its similarity to the word.
+func (r *MongoRepository) SimilarSearchTerms(ctx context.Context, words []string) (map[string]models.SearchSuggestion, error) {
+	r.logger.Debug("Fetching similar search terms", zap.Strings("words", words))
+	var known []struct {
+		Term string `bson:"_id"`
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "songs", Value: -1}, {Key: "_id", Value: 1}})
+	if err := r.find(ctx, "search_terms", bson.M{}, opts, &known); err != nil {
+		r.logger.Error("Failed to fetch similar search terms", zap.Error(err))
+		return nil, err
+	}
+
+	isKnown := make(map[string]bool, len(known))
+	for _, term := range known {
+		isKnown[term.Term] = true
+	}
+	terms := make(map[string]models.SearchSuggestion)
+	for _, word := range words {
+		if isKnown[word] {
+			continue
+		}
+		// The terms come in order of preference, so only a closer term replaces the best so far
+		best := models.SearchSuggestion{Source: models.SuggestionLyrics}
+		for _, term := range known {
+			if score := trigramSimilarity(term.Term, word); score >= similarityThreshold && score > best.Score {
+				best.Query, best.Score = term.Term, score
+			}
+		}
+		if best.Query != "" {
+			terms[word] = best
+		}
+	}
+	return terms, nil
+}
+
+// RefreshSearchTerms recomputes the lyric terms offered as search suggestions: the words of at least
+// searchTermMinLength letters found in the most songs. The text index exposes no statistics to queries,
+// so the lyrics are read and their words counted here.
+func (r *MongoRepository) RefreshSearchTerms(ctx context.Context) error {
+	var lyrics []struct {
+		Text *string `bson:"text"`
+	}
+	if err := r.find(ctx, "songs", bson.M{}, options.Find().SetProjection(bson.M{"text": 1}), &lyrics); err != nil {
+		r.logger.Error("Failed to read lyrics", zap.Error(err))
+		return err
+	}
+	counter := make(searchTermCounter)
+	for _, song := range lyrics {
+		counter.add(models.StringValue(song.Text))
+	}
+	terms, counts := counter.top()
+
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		if _, err := r.deleteMany(ctx, "search_terms", bson.M{}); err != nil {
+			return err
+		}
+		documents := make([]any, len(terms))
+		for i, term := range terms {
+			documents[i] = bson.M{"_id": term, "songs": counts[i]}
+		}
+		return r.insertMany(ctx, "search_terms", documents, nil)
+	})
+	if err != nil {
+		r.logger.Error("Failed to refresh search terms", zap.Error(err))
+	}
+	return err
+}
@@ -0,0 +1,1023 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// mongoSongCollections are the collections whose documents belong to a song by their song_id, deleted
+// with it as the rows of the tables referencing songs are
+var mongoSongCollections = []string{"song_view_days", "song_ratings", "song_favorites", "song_overrides", "song_revisions", "classification_suggestions"}
+
+// mongoYear is the year of a song's release date, the first four-digit run in it, or null when it has none
+var mongoYear = bson.M{"$let": bson.M{
+	"vars": bson.M{"found": bson.M{"$regexFind": bson.M{"input": "$release_date", "regex": `\d{4}`}}},
+	"in":   "$$found.match",
+}}
+
+// mongoFacetExpressions maps supported facet names to the aggregation expression used to group songs
+var mongoFacetExpressions = map[string]any{
+	"year": mongoYear,
+	"decade": bson.M{"$let": bson.M{
+		"vars": bson.M{"year": bson.M{"$toInt": mongoYear}},
+		"in":   bson.M{"$concat": bson.A{bson.M{"$toString": bson.M{"$subtract": bson.A{"$$year", bson.M{"$mod": bson.A{"$$year", 10}}}}}, "s"}},
+	}},
+	"group": "$group_name",
+	"genre": "$genre.name",
+}
+
+// mongoFacetStages maps the facets grouping songs by a related collection to the stages reaching it.
+// A song assigned several genres counts once for each.
+var mongoFacetStages = map[string]mongo.Pipeline{
+	"genre": {
+		{{Key: "$unwind", Value: "$genre_ids"}},
+		{{Key: "$lookup", Value: bson.M{"from": "genres", "localField": "genre_ids", "foreignField": "_id", "as": "genre"}}},
+		{{Key: "$unwind", Value: "$genre"}},
+	},
+}
+
+// mongoReleased is the release date of a song as YYYY-MM-DD, which orders as dates do, or null when it is
+// not a DD.MM.YYYY date
+var mongoReleased = bson.M{"$cond": bson.A{
+	bson.M{"$regexMatch": bson.M{"input": bson.M{"$ifNull": bson.A{"$release_date", ""}}, "regex": `^\d{2}\.\d{2}\.\d{4}$`}},
+	bson.M{"$concat": bson.A{
+		bson.M{"$substrCP": bson.A{"$release_date", 6, 4}}, "-",
+		bson.M{"$substrCP": bson.A{"$release_date", 3, 2}}, "-",
+		bson.M{"$substrCP": bson.A{"$release_date", 0, 2}},
+	}},
+	nil,
+}}
+
+// mongoNullIfEmpty returns nil for an empty string, stored as null, and the string otherwise
+func mongoNullIfEmpty(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// mongoExclude returns the condition leaving out the songs with the IDs, or nil when there are none
+func mongoExclude(exclude []int) bson.M {
+	if len(exclude) == 0 {
+		return nil
+	}
+	return bson.M{"_id": bson.M{"$nin": exclude}}
+}
+
+// songFilter returns the filter selecting the songs matched by the filter, followed by the extra conditions
+func (r *MongoRepository) songFilter(ctx context.Context, filter models.SongFilter, extra ...bson.M) (bson.M, error) {
+	var conditions []bson.M
+	if group := mongoContains(filter.Group); group != nil {
+		conditions = append(conditions, bson.M{"group_name": group})
+	}
+	if song := mongoContains(filter.Song); song != nil {
+		// A title in any language matches as well as the original one
+		conditions = append(conditions, bson.M{"$or": bson.A{bson.M{"song_name": song}, bson.M{"titles.title": song}}})
+	}
+	if filter.StaleThan > 0 {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"enriched_at": nil},
+			bson.M{"enriched_at": bson.M{"$lt": time.Now().Add(-filter.StaleThan)}},
+		}})
+	}
+	for _, field := range filter.Missing {
+		if _, ok := nullableColumns[field]; ok {
+			conditions = append(conditions, bson.M{field: nil})
+		}
+	}
+	if filter.Text != "" {
+		conditions = append(conditions, bson.M{"text": filter.Text})
+	}
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, bson.M{"tags": bson.M{"$all": filter.Tags}})
+	}
+	if filter.Genre != "" {
+		genreIDs := []int{}
+		var genre models.Genre
+		err := r.findOne(ctx, "genres", bson.M{"name": mongoEqualFold(filter.Genre)}, nil, &genre)
+		switch {
+		case err == nil:
+			if genreIDs, err = r.genreSubtree(ctx, genre.ID); err != nil {
+				return nil, err
+			}
+		case err != sql.ErrNoRows:
+			return nil, err
+		}
+		conditions = append(conditions, bson.M{"genre_ids": bson.M{"$in": genreIDs}})
+	}
+	if filter.FavoritesOf != 0 {
+		favorites, err := r.findSongIDs(ctx, "song_favorites", bson.M{"user_id": filter.FavoritesOf})
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, bson.M{"_id": bson.M{"$in": favorites}})
+	}
+	if filter.MinRating > 0 {
+		conditions = append(conditions, bson.M{"average_rating": bson.M{"$gte": filter.MinRating}})
+	}
+	for _, condition := range extra {
+		if condition != nil {
+			conditions = append(conditions, condition)
+		}
+	}
+	return mongoAnd(conditions), nil
+}
+
+// findSongIDs returns the song IDs of the documents of the collection matching the filter, in order
+func (r *MongoRepository) findSongIDs(ctx context.Context, collection string, filter bson.M) ([]int, error) {
+	var documents []struct {
+		SongID int `bson:"song_id"`
+	}
+	opts := options.Find().SetProjection(bson.M{"song_id": 1}).SetSort(bson.D{{Key: "song_id", Value: 1}})
+	if err := r.find(ctx, collection, filter, opts, &documents); err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(documents))
+	for i, document := range documents {
+		ids[i] = document.SongID
+	}
+	return ids, nil
+}
+
+// findIDs returns the IDs of the documents of the collection matching the filter, in order
+func (r *MongoRepository) findIDs(ctx context.Context, collection string, filter bson.M) ([]int, error) {
+	var documents []struct {
+		ID int `bson:"_id"`
+	}
+	opts := options.Find().SetProjection(bson.M{"_id": 1}).SetSort(bson.D{{Key: "_id", Value: 1}})
+	if err := r.find(ctx, collection, filter, opts, &documents); err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(documents))
+	for i, document := range documents {
+		ids[i] = document.ID
+	}
+	return ids, nil
+}
+
+// listSongs retrieves a page of the songs matching the filter in the order of the sort key, by ID for
+// unsupported keys. Popularity scores are computed by the aggregation pipeline.
+func (r *MongoRepository) listSongs(ctx context.Context, filter bson.M, sort string, page, limit int) ([]models.Song, error) {
+	if sort != SortPopularity {
+		order, ok := mongoSortOrders[sort]
+		if !ok {
+			order = mongoSortOrders["id"]
+		}
+		return r.songs.List(ctx, filter, order, page, limit)
+	}
+	projection := bson.M{"popularity": 0}
+	for field, value := range mongoSongProjection {
+		projection[field] = value
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{"popularity": mongoPopularityScore(r.popularity)}}},
+		{{Key: "$sort", Value: bson.D{{Key: "popularity", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$skip", Value: (page - 1) * limit}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: projection}},
+	}
+	songs := []models.Song{}
+	if err := r.aggregate(ctx, "songs", pipeline, &songs); err != nil {
+		return nil, err
+	}
+	return songs, nil
+}
+
+// artistID returns the ID of the artist named by a song's group, creating the artist when new, as the
+// triggers of the SQL backends do. Artists resolved before are looked up in known, which may be nil.
+func (r *MongoRepository) artistID(ctx context.Context, name string, known map[string]int) (int, error) {
+	if id, ok := known[name]; ok {
+		return id, nil
+	}
+	var artist models.Artist
+	err := r.findOne(ctx, "artists", bson.M{"name": name}, options.FindOne().SetProjection(bson.M{"_id": 1}), &artist)
+	if err == sql.ErrNoRows {
+		// The upsert keeps a concurrent insert of the same artist from failing on the unique name
+		var id int
+		if id, err = r.nextIDs(ctx, "artists", 1); err != nil {
+			return 0, err
+		}
+		now := time.Now().UTC()
+		err = r.findOneAndUpdate(ctx, "artists", bson.M{"name": name},
+			bson.M{"$setOnInsert": bson.M{"_id": id, "created_at": now, "updated_at": now}},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After).SetProjection(bson.M{"_id": 1}), &artist)
+	}
+	if err != nil {
+		r.logger.Error("Failed to resolve song artist", zap.String("name", name), zap.Error(err))
+		return 0, err
+	}
+	if known != nil {
+		known[name] = artist.ID
+	}
+	return artist.ID, nil
+}
+
+// newSong returns the document of a new song with the given fields, the defaults of the others and the
+// artist of its group
+func (r *MongoRepository) newSong(ctx context.Context, values bson.M, artists map[string]int) (bson.M, error) {
+	artistID, err := r.artistID(ctx, values["group_name"].(string), artists)
+	if err != nil {
+		return nil, err
+	}
+	document := bson.M{
+		"enrichment_status": models.EnrichmentComplete,
+		"legal_hold":        false,
+		"views":             int64(0),
+		"rating_count":      0,
+		"tags":              bson.A{},
+		"genre_ids":         bson.A{},
+		"titles":            bson.A{},
+		"artist_id":         artistID,
+	}
+	for field, value := range values {
+		document[field] = value
+	}
+	return document, nil
+}
+
+// songInputValues returns the fields of a song added from the input
+func songInputValues(song models.SongInput) bson.M {
+	return bson.M{
+		"group_name":   song.Group,
+		"song_name":    song.Song,
+		"release_date": mongoNullIfEmpty(song.ReleaseDate),
+		"text":         mongoNullIfEmpty(song.Text),
+		"link":         mongoNullIfEmpty(song.Link),
+		"enriched_at":  song.EnrichedAt,
+	}
+}
+
+// AddSong adds a new song to the database
+func (r *MongoRepository) AddSong(ctx context.Context, group, song, releaseDate, text, link string, enrichedAt *time.Time) (int, error) {
+	r.logger.Debug("Adding song to database", zap.String("group", group), zap.String("song", song))
+	document, err := r.newSong(ctx, songInputValues(models.SongInput{
+		Group: group, Song: song, ReleaseDate: releaseDate, Text: text, Link: link, EnrichedAt: enrichedAt,
+	}), nil)
+	if err != nil {
+		return 0, err
+	}
+	id, err := r.songs.Insert(ctx, document)
+	if err != nil {
+		r.logger.Error("Failed to add song", zap.Error(err))
+		return 0, err
+	}
+	r.logger.Info("Song added to database", zap.Int("id", id))
+	return id, nil
+}
+
+// AddSongs inserts several songs and returns an ID or an error for each of them. Every song is inserted on
+// its own, as a failed write aborts a MongoDB transaction, so a failing song does not keep the others from
+// being stored; the returned error is only set when the songs could not be inserted at all.
+func (r *MongoRepository) AddSongs(ctx context.Context, songs []models.SongInput) ([]int, []error, error) {
+	r.logger.Debug("Adding songs in bulk", zap.Int("count", len(songs)))
+	ids := make([]int, len(songs))
+	errs := make([]error, len(songs))
+	artists := make(map[string]int)
+	inserted := 0
+	for i, song := range songs {
+		document, err := r.newSong(ctx, songInputValues(song), artists)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ids[i], errs[i] = r.songs.Insert(ctx, document); errs[i] != nil {
+			r.logger.Warn("Failed to add song in bulk", zap.Int("index", i), zap.Error(errs[i]))
+			continue
+		}
+		inserted++
+	}
+	r.logger.Info("Songs added to database in bulk", zap.Int("inserted", inserted), zap.Int("failed", len(songs)-inserted))
+	return ids, errs, nil
+}
+
+// CopySongs inserts songs in bulk by batches of documents and returns their IDs in the order of songs.
+// Either every song is inserted or none is.
+func (r *MongoRepository) CopySongs(ctx context.Context, songs []models.SongInput) ([]int, error) {
+	r.logger.Debug("Copying songs in bulk", zap.Int("count", len(songs)))
+	var ids []int
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		ids, err = r.copySongs(ctx, songs)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("Failed to copy songs", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs copied to database in bulk", zap.Int("count", len(songs)))
+	return ids, nil
+}
+
+// copySongs writes the songs under consecutive IDs allocated at once
+func (r *MongoRepository) copySongs(ctx context.Context, songs []models.SongInput) ([]int, error) {
+	ids := make([]int, len(songs))
+	if len(songs) == 0 {
+		return ids, nil
+	}
+	first, err := r.nextIDs(ctx, "songs", len(songs))
+	if err != nil {
+		return nil, err
+	}
+	artists := make(map[string]int)
+	now := time.Now().UTC()
+	for offset := 0; offset < len(songs); offset += insertBatchRows {
+		end := min(offset+insertBatchRows, len(songs))
+		documents := make([]any, 0, end-offset)
+		for i := offset; i < end; i++ {
+			values := songInputValues(songs[i])
+			values["_id"], values["created_at"], values["updated_at"] = first+i, now, now
+			document, err := r.newSong(ctx, values, artists)
+			if err != nil {
+				return nil, err
+			}
+			ids[i] = first + i
+			documents = append(documents, document)
+		}
+		if err := r.insertMany(ctx, "songs", documents, nil); err != nil {
+			r.logger.Error("Failed to insert song batch", zap.Int("offset", offset), zap.Error(err))
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// GetSongs retrieves a list of songs with filtering, sorting and pagination
+func (r *MongoRepository) GetSongs(ctx context.Context, filter models.SongFilter, sort string, page, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs from database", zap.String("group", filter.Group), zap.String("song", filter.Song), zap.String("sort", sort))
+	where, err := r.songFilter(ctx, filter)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs", zap.Error(err))
+		return nil, err
+	}
+	songs, err := r.listSongs(ctx, where, sort, page, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs fetched from database", zap.Int("count", len(songs)))
+	return songs, nil
+}
+
+// CountSongs returns the number of songs matching the GetSongs filters
+func (r *MongoRepository) CountSongs(ctx context.Context, filter models.SongFilter) (int, error) {
+	r.logger.Debug("Counting songs in database", zap.String("group", filter.Group), zap.String("song", filter.Song))
+	where, err := r.songFilter(ctx, filter)
+	if err != nil {
+		r.logger.Error("Failed to count songs", zap.Error(err))
+		return 0, err
+	}
+	return r.songs.Count(ctx, where)
+}
+
+// GetSongByID retrieves a song by its ID
+func (r *MongoRepository) GetSongByID(ctx context.Context, id int) (models.Song, error) {
+	r.logger.Debug("Fetching song by ID", zap.Int("id", id))
+	song, err := r.songs.Get(ctx, id)
+	if err != nil {
+		r.logger.Error("Failed to fetch song", zap.Int("id", id), zap.Error(err))
+		return song, err
+	}
+	r.logger.Info("Song fetched from database", zap.Int("id", id))
+	return song, nil
+}
+
+// updateSong sets the fields of a song, relinking it to the artist of its group when that is set
+func (r *MongoRepository) updateSong(ctx context.Context, id int, values bson.M) error {
+	if group, ok := values["group_name"].(string); ok {
+		artistID, err := r.artistID(ctx, group, nil)
+		if err != nil {
+			return err
+		}
+		values["artist_id"] = artistID
+	}
+	return r.songs.Update(ctx, id, values)
+}
+
+// UpdateSong updates an existing song in the database
+func (r *MongoRepository) UpdateSong(ctx context.Context, id int, group, song, releaseDate, text, link string) error {
+	r.logger.Debug("Updating song in database", zap.Int("id", id))
+	err := r.updateSong(ctx, id, bson.M{
+		"group_name":   group,
+		"song_name":    song,
+		"release_date": mongoNullIfEmpty(releaseDate),
+		"text":         text,
+		"link":         link,
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to update song", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	r.logger.Info("Song updated in database", zap.Int("id", id))
+	return nil
+}
+
+// UpdateSongPartial updates only the fields present in the patch. Null fields are set to null.
+func (r *MongoRepository) UpdateSongPartial(ctx context.Context, id int, patch models.SongPatch) error {
+	r.logger.Debug("Partially updating song in database", zap.Int("id", id))
+	values := bson.M{}
+	for field, value := range map[string]models.OptionalString{
+		"group_name":     patch.Group,
+		"song_name":      patch.Song,
+		"release_date":   patch.ReleaseDate,
+		"text":           patch.Text,
+		"link":           patch.Link,
+		"notes":          patch.Notes,
+		"split_strategy": patch.SplitStrategy,
+	} {
+		switch {
+		case !value.Set:
+		case value.Null:
+			values[field] = nil
+		default:
+			values[field] = value.Value
+		}
+	}
+	switch field := patch.LicensingFee; {
+	case !field.Set:
+	case field.Null:
+		values["licensing_fee"] = nil
+	default:
+		values["licensing_fee"] = field.Value
+	}
+	switch field := patch.AlbumID; {
+	case !field.Set:
+	case field.Null:
+		values["album_id"] = nil
+	default:
+		values["album_id"] = field.Value
+	}
+	if len(values) == 0 {
+		// Nothing to change, but the song must still exist
+		_, err := r.songs.Get(ctx, id)
+		return err
+	}
+
+	if err := r.updateSong(ctx, id, values); err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to partially update song", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	r.logger.Info("Song partially updated in database", zap.Int("id", id), zap.Int("fields", len(values)))
+	return nil
+}
+
+// DeleteSong deletes a song from the database together with the documents belonging to it
+func (r *MongoRepository) DeleteSong(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting song from database", zap.Int("id", id))
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := r.songs.Delete(ctx, id); err != nil {
+			return err
+		}
+		return r.deleteSongDocuments(ctx, bson.M{"song_id": id})
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to delete song", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	r.logger.Info("Song deleted from database", zap.Int("id", id))
+	return nil
+}
+
+// deleteSongDocuments deletes the documents belonging to the songs matched by the filter on song_id
+func (r *MongoRepository) deleteSongDocuments(ctx context.Context, filter bson.M) error {
+	for _, collection := range mongoSongCollections {
+		if _, err := r.deleteMany(ctx, collection, filter); err != nil {
+			r.logger.Error("Failed to delete song documents", zap.String("collection", collection), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// TruncateSongs deletes every song, together with the documents belonging to them, and restarts the IDs
+func (r *MongoRepository) TruncateSongs(ctx context.Context) error {
+	r.logger.Debug("Truncating table")
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		if _, err := r.deleteMany(ctx, "songs", bson.M{}); err != nil {
+			return err
+		}
+		if err := r.deleteSongDocuments(ctx, bson.M{}); err != nil {
+			return err
+		}
+		_, err := r.deleteMany(ctx, "counters", bson.M{"_id": bson.M{"$in": bson.A{"songs", "classification_suggestions"}}})
+		return err
+	})
+	if err != nil {
+		r.logger.Error("Failed to truncate table", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Table truncated in database")
+	return nil
+}
+
+// FindSongID looks up the ID of a song by its group and title, ignoring case
+func (r *MongoRepository) FindSongID(ctx context.Context, group, song string) (int, error) {
+	r.logger.Debug("Looking up song ID", zap.String("group", group), zap.String("song", song))
+	var found models.Song
+	opts := options.FindOne().SetProjection(bson.M{"_id": 1}).SetSort(bson.D{{Key: "_id", Value: 1}})
+	err := r.findOne(ctx, "songs", bson.M{"group_name": mongoEqualFold(group), "song_name": mongoEqualFold(song)}, opts, &found)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to look up song ID", zap.Error(err))
+		}
+		return 0, err
+	}
+	return found.ID, nil
+}
+
+// GetSongFacets computes value/count buckets for each requested facet using the same filters as GetSongs
+func (r *MongoRepository) GetSongFacets(ctx context.Context, filter models.SongFilter, facets []string) (map[string][]models.FacetBucket, error) {
+	r.logger.Debug("Fetching song facets from database", zap.Strings("facets", facets))
+	where, err := r.songFilter(ctx, filter)
+	if err != nil {
+		r.logger.Error("Failed to fetch song facets", zap.Error(err))
+		return nil, err
+	}
+	result := make(map[string][]models.FacetBucket, len(facets))
+	for _, facet := range facets {
+		expression, ok := mongoFacetExpressions[facet]
+		if !ok {
+			return nil, fmt.Errorf("unsupported facet %q", facet)
+		}
+		pipeline := append(mongo.Pipeline{{{Key: "$match", Value: where}}}, mongoFacetStages[facet]...)
+		pipeline = append(pipeline, mongo.Pipeline{
+			{{Key: "$group", Value: bson.M{"_id": expression, "count": bson.M{"$sum": 1}}}},
+			{{Key: "$match", Value: bson.M{"_id": bson.M{"$ne": nil}}}},
+			{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+			{{Key: "$project", Value: bson.M{"_id": 0, "value": "$_id", "count": 1}}},
+		}...)
+		buckets := []models.FacetBucket{}
+		if err := r.aggregate(ctx, "songs", pipeline, &buckets); err != nil {
+			r.logger.Error("Failed to fetch facet", zap.String("facet", facet), zap.Error(err))
+			return nil, err
+		}
+		result[facet] = buckets
+	}
+	r.logger.Info("Song facets fetched from database", zap.Int("count", len(result)))
+	return result, nil
+}
+
+// GetSongsCreatedBetween retrieves songs added within the [from, to) interval
+func (r *MongoRepository) GetSongsCreatedBetween(ctx context.Context, from, to time.Time) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs created in period", zap.Time("from", from), zap.Time("to", to))
+	songs, err := r.songs.Find(ctx, bson.M{"created_at": bson.M{"$gte": from, "$lt": to}}, bson.D{{Key: "created_at", Value: 1}})
+	if err != nil {
+		r.logger.Error("Failed to fetch songs created in period", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetSongsUpdatedBetween retrieves songs created before the interval and edited within [from, to)
+func (r *MongoRepository) GetSongsUpdatedBetween(ctx context.Context, from, to time.Time) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs updated in period", zap.Time("from", from), zap.Time("to", to))
+	filter := bson.M{"updated_at": bson.M{"$gte": from, "$lt": to}, "created_at": bson.M{"$lt": from}}
+	songs, err := r.songs.Find(ctx, filter, bson.D{{Key: "updated_at", Value: 1}})
+	if err != nil {
+		r.logger.Error("Failed to fetch songs updated in period", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetSongsWithLyrics retrieves every song that has non-empty lyrics
+func (r *MongoRepository) GetSongsWithLyrics(ctx context.Context) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs with lyrics")
+	songs, err := r.songs.Find(ctx, bson.M{"text": bson.M{"$nin": bson.A{nil, ""}}}, mongoSortOrders["id"])
+	if err != nil {
+		r.logger.Error("Failed to fetch songs with lyrics", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs with lyrics fetched from database", zap.Int("count", len(songs)))
+	return songs, nil
+}
+
+// GetSongsWithReleaseDate retrieves all songs matching the filters that have a release date set
+func (r *MongoRepository) GetSongsWithReleaseDate(ctx context.Context, group, song string) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs with release date", zap.String("group", group), zap.String("song", song))
+	conditions := []bson.M{{"release_date": bson.M{"$ne": nil}}}
+	if group := mongoContains(group); group != nil {
+		conditions = append(conditions, bson.M{"group_name": group})
+	}
+	if song := mongoContains(song); song != nil {
+		conditions = append(conditions, bson.M{"song_name": song})
+	}
+	songs, err := r.songs.Find(ctx, mongoAnd(conditions), mongoSortOrders["id"])
+	if err != nil {
+		r.logger.Error("Failed to fetch songs with release date", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs with release date fetched from database", zap.Int("count", len(songs)))
+	return songs, nil
+}
+
+// SetLegalHold sets or releases the legal hold of a song, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) SetLegalHold(ctx context.Context, id int, held bool) error {
+	r.logger.Debug("Setting legal hold", zap.Int("id", id), zap.Bool("held", held))
+	if err := r.songs.Update(ctx, id, bson.M{"legal_hold": held}); err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to set legal hold", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// GetLegalHolds returns the IDs of the given songs that are on legal hold. Within a transaction a hold set
+// after the check makes the write to the song it guards fail with a write conflict, and the transaction
+// is retried, checking again.
+func (r *MongoRepository) GetLegalHolds(ctx context.Context, ids []int) ([]int, error) {
+	held, err := r.findIDs(ctx, "songs", bson.M{"_id": bson.M{"$in": ids}, "legal_hold": true})
+	if err != nil {
+		r.logger.Error("Failed to fetch legal holds", zap.Error(err))
+		return nil, err
+	}
+	return held, nil
+}
+
+// CountLegalHolds returns the number of songs on legal hold
+func (r *MongoRepository) CountLegalHolds(ctx context.Context) (int, error) {
+	return r.songs.Count(ctx, bson.M{"legal_hold": true})
+}
+
+// mongoToday is the current UTC date, the day views are counted on
+func mongoToday() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// IncrementSongViews adds the buffered view counts to the stored counters in a single transaction
+func (r *MongoRepository) IncrementSongViews(ctx context.Context, counts map[int]int64) error {
+	r.logger.Debug("Flushing song views", zap.Int("songs", len(counts)))
+	ids := make([]int, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	var flushed int
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		// Views of songs deleted since they were counted are dropped
+		existing, err := r.findIDs(ctx, "songs", bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return err
+		}
+		today := mongoToday()
+		totals := make([]mongo.WriteModel, len(existing))
+		days := make([]mongo.WriteModel, len(existing))
+		for i, id := range existing {
+			totals[i] = mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": id}).SetUpdate(bson.M{"$inc": bson.M{"views": counts[id]}})
+			days[i] = mongo.NewUpdateOneModel().SetFilter(bson.M{"song_id": id, "day": today}).
+				SetUpdate(bson.M{"$inc": bson.M{"views": counts[id]}}).SetUpsert(true)
+		}
+		if _, err := r.bulkWrite(ctx, "song_view_days", days); err != nil {
+			return err
+		}
+		_, err = r.bulkWrite(ctx, "songs", totals)
+		flushed = len(existing)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("Failed to flush song views", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Song views flushed to database", zap.Int("songs", flushed))
+	return nil
+}
+
+// RefreshTrending recomputes the materialized trending scores from the daily view buckets.
+// Each day's views are divided by (age in hours + 2) raised to gravity, so recent activity dominates.
+func (r *MongoRepository) RefreshTrending(ctx context.Context, gravity float64, windowDays int) error {
+	r.logger.Debug("Refreshing trending scores", zap.Float64("gravity", gravity), zap.Int("window_days", windowDays))
+	now := time.Now().UTC()
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"day": bson.M{"$gt": mongoToday().AddDate(0, 0, -windowDays)}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$song_id", "score": bson.M{"$sum": bson.M{"$divide": bson.A{
+			"$views",
+			bson.M{"$pow": bson.A{bson.M{"$add": bson.A{bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{now, "$day"}}, float64(time.Hour / time.Millisecond)}}, 2}}, gravity}},
+		}}}}}},
+	}
+	var scores []struct {
+		SongID int     `bson:"_id"`
+		Score  float64 `bson:"score"`
+	}
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		scores = nil
+		if err := r.aggregate(ctx, "song_view_days", pipeline, &scores); err != nil {
+			return err
+		}
+		if _, err := r.updateMany(ctx, "songs", bson.M{"trending_score": bson.M{"$exists": true}}, bson.M{"$unset": bson.M{"trending_score": ""}}); err != nil {
+			return err
+		}
+		writes := make([]mongo.WriteModel, len(scores))
+		for i, score := range scores {
+			writes[i] = mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": score.SongID}).
+				SetUpdate(bson.M{"$set": bson.M{"trending_score": score.Score}})
+		}
+		_, err := r.bulkWrite(ctx, "songs", writes)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("Failed to refresh trending scores", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Trending scores refreshed", zap.Int("songs", len(scores)))
+	return nil
+}
+
+// GetTrendingSongs retrieves the songs with the highest materialized trending score
+func (r *MongoRepository) GetTrendingSongs(ctx context.Context, limit int) ([]models.TrendingSong, error) {
+	r.logger.Debug("Fetching trending songs", zap.Int("limit", limit))
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"trending_score": bson.M{"$ne": nil}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "trending_score", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: mongoSongProjection}},
+		{{Key: "$addFields", Value: bson.M{"score": "$trending_score"}}},
+	}
+	songs := []models.TrendingSong{}
+	if err := r.aggregate(ctx, "songs", pipeline, &songs); err != nil {
+		r.logger.Error("Failed to fetch trending songs", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Trending songs fetched from database", zap.Int("count", len(songs)))
+	return songs, nil
+}
+
+// GetGroupStats aggregates the songs of a group, returning sql.ErrNoRows when it has none
+func (r *MongoRepository) GetGroupStats(ctx context.Context, group string) (models.GroupStats, error) {
+	r.logger.Debug("Fetching group stats", zap.String("group", group))
+	// Release dates are stored as DD.MM.YYYY, so they are compared as YYYY-MM-DD and turned back
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"group_name": mongoEqualFold(group)}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         nil,
+			"group_name":  bson.M{"$min": "$group_name"},
+			"songs":       bson.M{"$sum": 1},
+			"earliest":    bson.M{"$min": mongoReleased},
+			"latest":      bson.M{"$max": mongoReleased},
+			"total_views": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$views", 0}}},
+		}}},
+	}
+	var rows []struct {
+		Group      string  `bson:"group_name"`
+		Songs      int     `bson:"songs"`
+		Earliest   *string `bson:"earliest"`
+		Latest     *string `bson:"latest"`
+		TotalViews int64   `bson:"total_views"`
+	}
+	if err := r.aggregate(ctx, "songs", pipeline, &rows); err != nil {
+		r.logger.Error("Failed to fetch group stats", zap.String("group", group), zap.Error(err))
+		return models.GroupStats{}, err
+	}
+	if len(rows) == 0 {
+		return models.GroupStats{}, sql.ErrNoRows
+	}
+	row := rows[0]
+	return models.GroupStats{
+		Group:           row.Group,
+		Songs:           row.Songs,
+		EarliestRelease: mongoReleaseDate(row.Earliest),
+		LatestRelease:   mongoReleaseDate(row.Latest),
+		TotalViews:      row.TotalViews,
+	}, nil
+}
+
+// mongoReleaseDate turns a YYYY-MM-DD date computed by mongoReleased back into DD.MM.YYYY
+func mongoReleaseDate(released *string) *string {
+	if released == nil {
+		return nil
+	}
+	date := *released
+	date = date[8:10] + "." + date[5:7] + "." + date[0:4]
+	return &date
+}
+
+// GetMostViewedGroupSongs retrieves up to limit songs of a group, most viewed first
+func (r *MongoRepository) GetMostViewedGroupSongs(ctx context.Context, group string, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching most viewed group songs", zap.String("group", group), zap.Int("limit", limit))
+	songs, err := r.songs.List(ctx, bson.M{"group_name": mongoEqualFold(group)}, mongoSortOrders["views"], 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch most viewed group songs", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetSongsNeedingListeners retrieves up to limit songs whose listener count was never fetched
+// or was fetched longer than maxAge ago, never-fetched songs first
+func (r *MongoRepository) GetSongsNeedingListeners(ctx context.Context, maxAge time.Duration, exclude []int, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs needing listener counts", zap.Duration("max_age", maxAge), zap.Int("limit", limit))
+	filter := mongoAnd([]bson.M{{"$or": bson.A{
+		bson.M{"listeners_fetched_at": nil},
+		bson.M{"listeners_fetched_at": bson.M{"$lt": time.Now().Add(-maxAge)}},
+	}}, mongoExclude(exclude)})
+	// Null sorts first in ascending order
+	sort := bson.D{{Key: "listeners_fetched_at", Value: 1}, {Key: "_id", Value: 1}}
+	songs, err := r.songs.List(ctx, filter, sort, 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs needing listener counts", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// SaveListenerCount caches the listener count fetched for the song
+func (r *MongoRepository) SaveListenerCount(ctx context.Context, id int, listeners int64) error {
+	update := bson.M{"$set": bson.M{"listeners": listeners, "listeners_fetched_at": time.Now().UTC()}}
+	if _, err := r.updateOne(ctx, "songs", bson.M{"_id": id}, update); err != nil {
+		r.logger.Error("Failed to save listener count", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetStalestSongs retrieves up to limit songs not enriched within staleAfter, never-enriched songs first
+// and then the longest-unrefreshed ones. Songs whose IDs are in exclude and songs on legal hold are skipped.
+func (r *MongoRepository) GetStalestSongs(ctx context.Context, staleAfter time.Duration, exclude []int, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching stalest songs", zap.Duration("stale_after", staleAfter), zap.Int("limit", limit))
+	filter, err := r.songFilter(ctx, models.SongFilter{StaleThan: staleAfter}, bson.M{"legal_hold": bson.M{"$ne": true}}, mongoExclude(exclude))
+	if err != nil {
+		r.logger.Error("Failed to fetch stalest songs", zap.Error(err))
+		return nil, err
+	}
+	songs, err := r.songs.List(ctx, filter, bson.D{{Key: "enriched_at", Value: 1}, {Key: "_id", Value: 1}}, 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch stalest songs", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetSongsAfter retrieves up to limit songs matching the filter with IDs above afterID, in ID order,
+// so that callers can walk every match while updating the songs they have seen
+func (r *MongoRepository) GetSongsAfter(ctx context.Context, filter models.SongFilter, afterID, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs after ID", zap.Int("after_id", afterID), zap.Int("limit", limit))
+	where, err := r.songFilter(ctx, filter, bson.M{"_id": bson.M{"$gt": afterID}})
+	if err != nil {
+		r.logger.Error("Failed to fetch songs after ID", zap.Error(err))
+		return nil, err
+	}
+	songs, err := r.songs.List(ctx, where, nil, 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs after ID", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// RefreshSongData stores data freshly fetched from the external API and marks the song as enriched now,
+// settling its enrichment status. Empty values leave the stored field unchanged.
+func (r *MongoRepository) RefreshSongData(ctx context.Context, id int, releaseDate, text, link string) error {
+	r.logger.Debug("Refreshing song data", zap.Int("id", id))
+	values := bson.M{
+		"enriched_at":       time.Now().UTC(),
+		"enrichment_status": models.EnrichmentComplete,
+		"enrichment_error":  nil,
+	}
+	for field, value := range map[string]string{"release_date": releaseDate, "text": text, "link": link} {
+		if value != "" {
+			values[field] = value
+		}
+	}
+	return r.updateEnrichment(ctx, id, values)
+}
+
+// AddPendingSong inserts a song whose details are still to be fetched by the enrichment workers
+func (r *MongoRepository) AddPendingSong(ctx context.Context, group, song string) (int, error) {
+	r.logger.Debug("Adding pending song to database", zap.String("group", group), zap.String("song", song))
+	document, err := r.newSong(ctx, bson.M{
+		"group_name":        group,
+		"song_name":         song,
+		"release_date":      nil,
+		"text":              nil,
+		"link":              nil,
+		"enrichment_status": models.EnrichmentPending,
+	}, nil)
+	if err != nil {
+		return 0, err
+	}
+	id, err := r.songs.Insert(ctx, document)
+	if err != nil {
+		r.logger.Error("Failed to add pending song", zap.Error(err))
+		return 0, err
+	}
+	r.logger.Info("Pending song added to database", zap.Int("id", id))
+	return id, nil
+}
+
+// CompleteEnrichment stores the fetched details of a pending song and marks it complete. Fields edited
+// while the song was pending keep their value.
+func (r *MongoRepository) CompleteEnrichment(ctx context.Context, id int, releaseDate, text, link string, enrichedAt *time.Time) error {
+	r.logger.Debug("Completing song enrichment", zap.Int("id", id))
+	now := time.Now().UTC()
+	// The pipeline update keeps the stored values; the fetched ones are literals, which a leading $ would
+	// otherwise turn into field paths
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"release_date":      bson.M{"$ifNull": bson.A{"$release_date", bson.M{"$literal": mongoNullIfEmpty(releaseDate)}}},
+		"text":              bson.M{"$ifNull": bson.A{"$text", bson.M{"$literal": mongoNullIfEmpty(text)}}},
+		"link":              bson.M{"$ifNull": bson.A{"$link", bson.M{"$literal": mongoNullIfEmpty(link)}}},
+		"enriched_at":       bson.M{"$literal": enrichedAt},
+		"enrichment_status": models.EnrichmentComplete,
+		"enrichment_error":  nil,
+		"updated_at":        now,
+	}}}}
+	result, err := r.updateOne(ctx, "songs", bson.M{"_id": id}, update)
+	if err != nil {
+		r.logger.Error("Failed to update song enrichment", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// FailEnrichment marks a pending song failed with the reason
+func (r *MongoRepository) FailEnrichment(ctx context.Context, id int, reason string) error {
+	r.logger.Debug("Failing song enrichment", zap.Int("id", id), zap.String("reason", reason))
+	return r.updateEnrichment(ctx, id, bson.M{"enrichment_status": models.EnrichmentFailed, "enrichment_error": reason})
+}
+
+// updateEnrichment runs an enrichment update, returning sql.ErrNoRows when the song was deleted
+func (r *MongoRepository) updateEnrichment(ctx context.Context, id int, values bson.M) error {
+	if err := r.songs.Update(ctx, id, values); err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to update song enrichment", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// GetEnrichmentStatus retrieves the enrichment status of a song
+func (r *MongoRepository) GetEnrichmentStatus(ctx context.Context, id int) (models.EnrichmentStatus, error) {
+	r.logger.Debug("Fetching enrichment status", zap.Int("id", id))
+	var status models.EnrichmentStatus
+	opts := options.FindOne().SetProjection(bson.M{"enrichment_status": 1, "enrichment_error": 1, "enriched_at": 1, "updated_at": 1})
+	err := r.findOne(ctx, "songs", bson.M{"_id": id}, opts, &status)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch enrichment status", zap.Int("id", id), zap.Error(err))
+	}
+	return status, err
+}
+
+// GetPendingEnrichments retrieves up to limit songs waiting for enrichment, oldest first
+func (r *MongoRepository) GetPendingEnrichments(ctx context.Context, exclude []int, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs pending enrichment", zap.Int("limit", limit))
+	filter := mongoAnd([]bson.M{{"enrichment_status": models.EnrichmentPending}, mongoExclude(exclude)})
+	songs, err := r.songs.List(ctx, filter, nil, 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs pending enrichment", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetSongsNeedingMetadata retrieves up to limit songs whose track metadata was never synced, skipping songs on
+// legal hold
+func (r *MongoRepository) GetSongsNeedingMetadata(ctx context.Context, exclude []int, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs needing track metadata", zap.Int("limit", limit))
+	filter := mongoAnd([]bson.M{{"metadata_synced_at": nil, "legal_hold": bson.M{"$ne": true}}, mongoExclude(exclude)})
+	songs, err := r.songs.List(ctx, filter, nil, 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs needing track metadata", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// SaveSongMetadata stores the track metadata of the song and marks it synced. Unknown fields keep
+// their current value, so a lookup that found nothing only marks the song.
+func (r *MongoRepository) SaveSongMetadata(ctx context.Context, id int, metadata models.TrackMetadata) error {
+	r.logger.Debug("Saving track metadata", zap.Int("id", id))
+	values := bson.M{"metadata_synced_at": time.Now().UTC()}
+	if metadata.Album != nil {
+		values["album"] = *metadata.Album
+	}
+	if metadata.DurationMs != nil {
+		values["duration_ms"] = *metadata.DurationMs
+	}
+	if metadata.ISRC != nil {
+		values["isrc"] = *metadata.ISRC
+	}
+	if metadata.ArtworkURL != nil {
+		values["artwork_url"] = *metadata.ArtworkURL
+	}
+	err := r.songs.Update(ctx, id, values)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to save track metadata", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
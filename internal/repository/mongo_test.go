@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/mongo"
+	"music-library/internal/models"
+)
+
+func TestMongoStructTags(t *testing.T) {
+	type Embedded struct {
+		Note string `db:"note"`
+	}
+	type document struct {
+		ID    int    `db:"id"`
+		Name  string `db:"song_name"`
+		Label string `bson:"label" db:"ignored"`
+		Embedded
+	}
+
+	buf := new(bsonrw.SliceWriter)
+	writer, err := bsonrw.NewBSONValueWriter(buf)
+	require.NoError(t, err)
+	encoder, err := bson.NewEncoder(writer)
+	require.NoError(t, err)
+	encoder.SetRegistry(mongoRegistry())
+	require.NoError(t, encoder.Encode(document{ID: 7, Name: "Supermassive Black Hole", Label: "Muse", Embedded: Embedded{Note: "single"}}))
+
+	var raw bson.M
+	require.NoError(t, bson.Unmarshal(*buf, &raw))
+	assert.Equal(t, bson.M{"_id": int32(7), "song_name": "Supermassive Black Hole", "label": "Muse", "note": "single"}, raw)
+
+	var decoded document
+	require.NoError(t, bson.UnmarshalWithRegistry(mongoRegistry(), *buf, &decoded))
+	assert.Equal(t, document{ID: 7, Name: "Supermassive Black Hole", Label: "Muse", Embedded: Embedded{Note: "single"}}, decoded)
+}
+
+func TestMongoTextSearch(t *testing.T) {
+	assert.Equal(t, "love night time day", mongoTextSearch(parseSearchQuery(`love -war "night time" OR day`)))
+	assert.Equal(t, "love", mongoTextSearch(parseSearchQuery(`love OR love`)))
+}
+
+func TestCutVerses(t *testing.T) {
+	text := "Ooh baby\r\nHold me\r\n\r\nNaïve line"
+	verses := cutVerses(text, []models.VerseSpan{
+		{Number: 1, Label: "Verse 1", Start: 0, Length: 16},
+		{Number: 2, Label: "Verse 2", Start: 18, Length: 10},
+		{Number: 3, Label: "Verse 3", Start: 25, Length: 10},
+	})
+	assert.Equal(t, []mongoVerse{
+		{Number: 1, Label: "Verse 1", Text: "Ooh baby\nHold me"},
+		{Number: 2, Label: "Verse 2", Text: "Naïve line"},
+		// Spans running past the text are clipped
+		{Number: 3, Label: "Verse 3", Text: "ine"},
+	}, verses)
+}
+
+func TestMongoRetryable(t *testing.T) {
+	assert.True(t, isRetryable(mongo.CommandError{Code: 251, Labels: []string{"TransientTransactionError"}}))
+	assert.True(t, isRetryable(mongo.CommandError{Code: mongoWriteConflict}))
+	assert.False(t, isRetryable(mongo.CommandError{Code: 11000}))
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// CreateUser adds a user account with the role and returns its ID, or sql.ErrNoRows when the username is taken
+func (r *MongoRepository) CreateUser(ctx context.Context, username, passwordHash, role string) (int, error) {
+	r.logger.Debug("Creating user", zap.String("username", username), zap.String("role", role))
+	if _, err := r.GetUserByUsername(ctx, username); err != sql.ErrNoRows {
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return 0, err
+	}
+	id, err := r.users.Insert(ctx, bson.M{"username": username, "password_hash": passwordHash, "role": role})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return 0, sql.ErrNoRows
+		}
+		r.logger.Error("Failed to create user", zap.String("username", username), zap.Error(err))
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetUserByUsername retrieves a user account, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	users, err := r.users.Find(ctx, bson.M{"username": username}, bson.D{{Key: "_id", Value: 1}})
+	if err != nil {
+		return models.User{}, err
+	}
+	if len(users) == 0 {
+		return models.User{}, sql.ErrNoRows
+	}
+	return users[0], nil
+}
+
+// GetUserByID retrieves a user account, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) GetUserByID(ctx context.Context, id int) (models.User, error) {
+	return r.users.Get(ctx, id)
+}
+
+// GetUsers retrieves a page of user accounts ordered by ID
+func (r *MongoRepository) GetUsers(ctx context.Context, page, limit int) ([]models.User, error) {
+	r.logger.Debug("Fetching users", zap.Int("page", page), zap.Int("limit", limit))
+	return r.users.List(ctx, bson.M{}, nil, page, limit)
+}
+
+// SetUserRole changes the role of a user, returning sql.ErrNoRows when the user does not exist
+func (r *MongoRepository) SetUserRole(ctx context.Context, id int, role string) error {
+	r.logger.Debug("Setting user role", zap.Int("id", id), zap.String("role", role))
+	return r.users.Update(ctx, id, bson.M{"role": role})
+}
+
+// GetPreferences retrieves the preferences of the user, which are kept on the user's document,
+// returning sql.ErrNoRows when none were saved
+func (r *MongoRepository) GetPreferences(ctx context.Context, userID int) (models.Preferences, error) {
+	var user struct {
+		Preferences models.Preferences `bson:"preferences"`
+	}
+	filter := bson.M{"_id": userID, "preferences": bson.M{"$type": "object"}}
+	err := r.findOne(ctx, "users", filter, options.FindOne().SetProjection(bson.M{"preferences": 1}), &user)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch preferences", zap.Int("user_id", userID), zap.Error(err))
+	}
+	return user.Preferences, err
+}
+
+// SavePreferences creates or replaces the preferences of the user
+func (r *MongoRepository) SavePreferences(ctx context.Context, userID int, preferences models.Preferences) error {
+	r.logger.Debug("Saving preferences", zap.Int("user_id", userID))
+	preferences.UpdatedAt = time.Now().UTC()
+	_, err := r.updateOne(ctx, "users", bson.M{"_id": userID}, bson.M{"$set": bson.M{"preferences": preferences}})
+	if err != nil {
+		r.logger.Error("Failed to save preferences", zap.Int("user_id", userID), zap.Error(err))
+	}
+	return err
+}
+
+// GetSongOverride retrieves the user's override of the song, returning sql.ErrNoRows when there is none
+func (r *MongoRepository) GetSongOverride(ctx context.Context, userID, songID int) (models.SongOverride, error) {
+	var override models.SongOverride
+	err := r.findOne(ctx, "song_overrides", bson.M{"user_id": userID, "song_id": songID}, nil, &override)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch song override", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return override, err
+}
+
+// GetSongOverrides retrieves the user's overrides of any of the songs
+func (r *MongoRepository) GetSongOverrides(ctx context.Context, userID int, songIDs []int) ([]models.SongOverride, error) {
+	overrides := []models.SongOverride{}
+	filter := bson.M{"user_id": userID, "song_id": bson.M{"$in": append([]int{}, songIDs...)}}
+	if err := r.find(ctx, "song_overrides", filter, nil, &overrides); err != nil {
+		r.logger.Error("Failed to fetch song overrides", zap.Int("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// SaveSongOverride creates or replaces the user's override of the song, returning sql.ErrNoRows when the
+// song does not exist
+func (r *MongoRepository) SaveSongOverride(ctx context.Context, userID, songID int, text string) (models.SongOverride, error) {
+	r.logger.Debug("Saving song override", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	now := time.Now().UTC()
+	var override models.SongOverride
+	err := r.upsertUserSongDocument(ctx, "song_overrides", userID, songID, bson.M{
+		"$set":         bson.M{"text": text, "updated_at": now},
+		"$setOnInsert": bson.M{"created_at": now},
+	}, &override)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to save song override", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return override, err
+}
+
+// DeleteSongOverride discards the user's override of the song, returning sql.ErrNoRows when there is none
+func (r *MongoRepository) DeleteSongOverride(ctx context.Context, userID, songID int) error {
+	r.logger.Debug("Deleting song override", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return r.deleteUserSongDocument(ctx, "song_overrides", userID, songID)
+}
+
+// GetSongRating retrieves the user's rating of the song together with its average rating, returning
+// sql.ErrNoRows when the song does not exist
+func (r *MongoRepository) GetSongRating(ctx context.Context, userID, songID int) (models.SongRating, error) {
+	rating := models.SongRating{SongID: songID}
+	projection := options.FindOne().SetProjection(bson.M{"average_rating": 1, "rating_count": 1})
+	err := r.findOne(ctx, "songs", bson.M{"_id": songID}, projection, &rating)
+	if err == nil {
+		var own struct {
+			Rating int `bson:"rating"`
+		}
+		err = r.findOne(ctx, "song_ratings", bson.M{"user_id": userID, "song_id": songID}, nil, &own)
+		if err == nil {
+			rating.Rating = &own.Rating
+		} else if err == sql.ErrNoRows {
+			err = nil
+		}
+	}
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch song rating", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return rating, err
+}
+
+// RateSong creates or replaces the user's rating of the song, returning sql.ErrNoRows when the song does
+// not exist. The average kept on the song is updated with it.
+func (r *MongoRepository) RateSong(ctx context.Context, userID, songID, rating int) error {
+	r.logger.Debug("Rating song", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Int("rating", rating))
+	now := time.Now().UTC()
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		err := r.upsertUserSongDocument(ctx, "song_ratings", userID, songID, bson.M{
+			"$set":         bson.M{"rating": rating, "updated_at": now},
+			"$setOnInsert": bson.M{"created_at": now},
+		}, nil)
+		if err != nil {
+			return err
+		}
+		return r.updateSongRatings(ctx, bson.M{"_id": songID})
+	})
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to rate song", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return err
+}
+
+// DeleteSongRating discards the user's rating of the song, returning sql.ErrNoRows when there is none
+func (r *MongoRepository) DeleteSongRating(ctx context.Context, userID, songID int) error {
+	r.logger.Debug("Deleting song rating", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return r.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := r.deleteUserSongDocument(ctx, "song_ratings", userID, songID); err != nil {
+			return err
+		}
+		return r.updateSongRatings(ctx, bson.M{"_id": songID})
+	})
+}
+
+// updateSongRatings recomputes the rating count and average kept on the songs matching the filter from
+// their ratings
+func (r *MongoRepository) updateSongRatings(ctx context.Context, filter bson.M) error {
+	ids, err := r.findIDs(ctx, "songs", filter)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"song_id": bson.M{"$in": ids}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$song_id", "sum": bson.M{"$sum": "$rating"}, "count": bson.M{"$sum": 1}}}},
+	}
+	var ratings []struct {
+		SongID int `bson:"_id"`
+		Sum    int `bson:"sum"`
+		Count  int `bson:"count"`
+	}
+	if err := r.aggregate(ctx, "song_ratings", pipeline, &ratings); err != nil {
+		return err
+	}
+	writes := make([]mongo.WriteModel, 0, len(ids))
+	rated := make(map[int]bool, len(ratings))
+	for _, rating := range ratings {
+		rated[rating.SongID] = true
+		writes = append(writes, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": rating.SongID}).SetUpdate(bson.M{"$set": bson.M{
+			"rating_sum":     rating.Sum,
+			"rating_count":   rating.Count,
+			"average_rating": float64(rating.Sum) / float64(rating.Count),
+		}}))
+	}
+	for _, id := range ids {
+		if !rated[id] {
+			writes = append(writes, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": id}).SetUpdate(bson.M{"$set": bson.M{
+				"rating_sum":     0,
+				"rating_count":   0,
+				"average_rating": nil,
+			}}))
+		}
+	}
+	_, err = r.bulkWrite(ctx, "songs", writes)
+	return err
+}
+
+// FavoriteSong marks the song as one of the user's favorites and returns when it was first marked,
+// returning sql.ErrNoRows when the song does not exist
+func (r *MongoRepository) FavoriteSong(ctx context.Context, userID, songID int) (models.SongFavorite, error) {
+	r.logger.Debug("Marking song as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	var favorite models.SongFavorite
+	// Only set on insert, keeping when the song was first marked
+	update := bson.M{"$setOnInsert": bson.M{"created_at": time.Now().UTC()}}
+	err := r.upsertUserSongDocument(ctx, "song_favorites", userID, songID, update, &favorite)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to mark song as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return favorite, err
+}
+
+// UnfavoriteSong removes the song from the user's favorites, returning sql.ErrNoRows when it is not one
+func (r *MongoRepository) UnfavoriteSong(ctx context.Context, userID, songID int) error {
+	r.logger.Debug("Unmarking song as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return r.deleteUserSongDocument(ctx, "song_favorites", userID, songID)
+}
+
+// upsertUserSongDocument applies the update to the user's document about the song, creating it when
+// missing, and decodes the result into result when not nil. It returns sql.ErrNoRows when the song does
+// not exist.
+func (r *MongoRepository) upsertUserSongDocument(ctx context.Context, collection string, userID, songID int, update bson.M, result any) error {
+	return r.RunInTransaction(ctx, func(ctx context.Context) error {
+		songs, err := r.songs.Count(ctx, bson.M{"_id": songID})
+		if err != nil {
+			return err
+		}
+		if songs == 0 {
+			return sql.ErrNoRows
+		}
+		if result == nil {
+			result = &bson.M{}
+		}
+		opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+		return r.findOneAndUpdate(ctx, collection, bson.M{"user_id": userID, "song_id": songID}, update, opts, result)
+	})
+}
+
+// deleteUserSongDocument deletes the user's document about the song, returning sql.ErrNoRows when there is none
+func (r *MongoRepository) deleteUserSongDocument(ctx context.Context, collection string, userID, songID int) error {
+	deleted, err := r.deleteMany(ctx, collection, bson.M{"user_id": userID, "song_id": songID})
+	if err != nil {
+		r.logger.Error("Failed to delete user song document", zap.String("collection", collection), zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// CreateWebhook stores a webhook and returns it as stored
+func (r *MongoRepository) CreateWebhook(ctx context.Context, url string, events []string, secret string) (models.Webhook, error) {
+	r.logger.Debug("Creating webhook", zap.String("url", url), zap.Strings("events", events))
+	id, err := r.nextIDs(ctx, "webhooks", 1)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	webhook := models.Webhook{ID: id, URL: url, Events: append([]string{}, events...), Secret: secret, CreatedAt: time.Now().UTC()}
+	if err := r.insertOne(ctx, "webhooks", webhook); err != nil {
+		r.logger.Error("Failed to store webhook", zap.Error(err))
+		return models.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// GetWebhooks returns every webhook, oldest first
+func (r *MongoRepository) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	webhooks := []models.Webhook{}
+	if err := r.find(ctx, "webhooks", bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}), &webhooks); err != nil {
+		r.logger.Error("Failed to fetch webhooks", zap.Error(err))
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes a webhook with its deliveries, returning sql.ErrNoRows when it does not exist
+func (r *MongoRepository) DeleteWebhook(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting webhook", zap.Int("id", id))
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		deleted, err := r.deleteMany(ctx, "webhooks", bson.M{"_id": id})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return sql.ErrNoRows
+		}
+		_, err = r.deleteMany(ctx, "webhook_deliveries", bson.M{"webhook_id": id})
+		return err
+	})
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to delete webhook", zap.Int("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// EnqueueWebhookDeliveries queues the event for every webhook subscribed to its type and returns the
+// number of deliveries queued
+func (r *MongoRepository) EnqueueWebhookDeliveries(ctx context.Context, eventType string, payload []byte) (int64, error) {
+	var queued int64
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		webhooks, err := r.findIDs(ctx, "webhooks", bson.M{"events": eventType})
+		if err != nil || len(webhooks) == 0 {
+			queued = 0
+			return err
+		}
+		first, err := r.nextIDs(ctx, "webhook_deliveries", len(webhooks))
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		deliveries := make([]any, len(webhooks))
+		for i, webhookID := range webhooks {
+			deliveries[i] = models.WebhookDelivery{
+				ID: int64(first + i), WebhookID: webhookID, EventType: eventType, Payload: payload,
+				Status: "pending", NextAttemptAt: now, CreatedAt: now,
+			}
+		}
+		queued = int64(len(deliveries))
+		return r.insertMany(ctx, "webhook_deliveries", deliveries, nil)
+	})
+	if err != nil {
+		r.logger.Error("Failed to queue webhook deliveries", zap.String("event_type", eventType), zap.Error(err))
+		return 0, err
+	}
+	return queued, nil
+}
+
+// ClaimWebhookDeliveries claims up to limit pending deliveries that are due, skipping those to the excluded
+// webhooks, counting an attempt for each and pushing their next attempt lease into the future, so a delivery
+// left unfinished by a crash is retried then. The claim runs in a transaction: two workers claiming the same
+// delivery conflict, and the transaction of the later one is retried without it.
+func (r *MongoRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration, exclude []int) ([]models.DueDelivery, error) {
+	var deliveries []models.DueDelivery
+	err := r.RunInTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now().UTC()
+		due := bson.M{"status": "pending", "next_attempt_at": bson.M{"$lte": now}, "webhook_id": bson.M{"$nin": append([]int{}, exclude...)}}
+		var claimed []models.WebhookDelivery
+		opts := options.Find().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).SetLimit(int64(limit))
+		if err := r.find(ctx, "webhook_deliveries", due, opts, &claimed); err != nil {
+			return err
+		}
+		ids := make([]int64, len(claimed))
+		webhookIDs := make([]int, len(claimed))
+		for i, delivery := range claimed {
+			ids[i], webhookIDs[i] = delivery.ID, delivery.WebhookID
+		}
+		update := bson.M{"$inc": bson.M{"attempts": 1}, "$set": bson.M{"next_attempt_at": now.Add(lease)}}
+		if _, err := r.updateMany(ctx, "webhook_deliveries", bson.M{"_id": bson.M{"$in": ids}}, update); err != nil {
+			return err
+		}
+		claimed = nil
+		if err := r.find(ctx, "webhook_deliveries", bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}), &claimed); err != nil {
+			return err
+		}
+		var webhooks []models.Webhook
+		if err := r.find(ctx, "webhooks", bson.M{"_id": bson.M{"$in": webhookIDs}}, nil, &webhooks); err != nil {
+			return err
+		}
+		byID := make(map[int]models.Webhook, len(webhooks))
+		for _, webhook := range webhooks {
+			byID[webhook.ID] = webhook
+		}
+		deliveries = make([]models.DueDelivery, 0, len(claimed))
+		for _, delivery := range claimed {
+			webhook, ok := byID[delivery.WebhookID]
+			if !ok {
+				continue
+			}
+			deliveries = append(deliveries, models.DueDelivery{
+				WebhookDelivery: delivery, URL: webhook.URL, Secret: webhook.Secret,
+				PreviousSecret: webhook.PreviousSecret, SecretRotatedAt: webhook.SecretRotatedAt,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// DeferWebhookDelivery hands a claimed delivery back unsent, to be claimed again after the wait. The claim
+// does not count as an attempt.
+func (r *MongoRepository) DeferWebhookDelivery(ctx context.Context, id int64, after time.Duration) error {
+	update := bson.A{bson.M{"$set": bson.M{
+		"attempts":        bson.M{"$max": bson.A{bson.M{"$subtract": bson.A{"$attempts", 1}}, 0}},
+		"next_attempt_at": time.Now().UTC().Add(after),
+	}}}
+	_, err := r.updateOne(ctx, "webhook_deliveries", bson.M{"_id": id}, update)
+	if err != nil {
+		r.logger.Error("Failed to defer webhook delivery", zap.Int64("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// FinishWebhookDelivery records the outcome of a delivery attempt. A pending status schedules the next
+// attempt after retryAfter; a delivered status records the delivery time.
+func (r *MongoRepository) FinishWebhookDelivery(ctx context.Context, id int64, status string, responseStatus *int, message *string, retryAfter time.Duration) error {
+	now := time.Now().UTC()
+	var deliveredAt any
+	if status == "delivered" {
+		deliveredAt = now
+	}
+	_, err := r.updateOne(ctx, "webhook_deliveries", bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"status":          status,
+		"response_status": responseStatus,
+		"error":           message,
+		"next_attempt_at": now.Add(retryAfter),
+		"delivered_at":    deliveredAt,
+	}})
+	if err != nil {
+		r.logger.Error("Failed to record webhook delivery", zap.Int64("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// GetWebhookDeliveries returns the newest deliveries of a webhook, of every status when status is empty.
+// sql.ErrNoRows is returned when the webhook does not exist.
+func (r *MongoRepository) GetWebhookDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	webhooks, err := r.count(ctx, "webhooks", bson.M{"_id": webhookID})
+	if err != nil {
+		r.logger.Error("Failed to check webhook", zap.Int("id", webhookID), zap.Error(err))
+		return nil, err
+	}
+	if webhooks == 0 {
+		return nil, sql.ErrNoRows
+	}
+	filter := bson.M{"webhook_id": webhookID}
+	if status != "" {
+		filter["status"] = status
+	}
+	deliveries := []models.WebhookDelivery{}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(limit))
+	if err := r.find(ctx, "webhook_deliveries", filter, opts, &deliveries); err != nil {
+		r.logger.Error("Failed to fetch webhook deliveries", zap.Int("id", webhookID), zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// GetWebhookDelivery returns a delivery of a webhook, or sql.ErrNoRows when the webhook has no such delivery
+func (r *MongoRepository) GetWebhookDelivery(ctx context.Context, webhookID int, id int64) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.findOne(ctx, "webhook_deliveries", bson.M{"_id": id, "webhook_id": webhookID}, nil, &delivery)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch webhook delivery", zap.Int64("id", id), zap.Error(err))
+	}
+	return delivery, err
+}
+
+// RetryWebhookDelivery queues a finished delivery of a webhook to be sent again right away, with its attempts
+// reset, and returns it. sql.ErrNoRows is returned when the webhook has no such delivery or it is still pending.
+func (r *MongoRepository) RetryWebhookDelivery(ctx context.Context, webhookID int, id int64) (models.WebhookDelivery, error) {
+	r.logger.Debug("Retrying webhook delivery", zap.Int("webhook_id", webhookID), zap.Int64("id", id))
+	var delivery models.WebhookDelivery
+	filter := bson.M{"_id": id, "webhook_id": webhookID, "status": bson.M{"$ne": "pending"}}
+	update := bson.M{"$set": bson.M{"status": "pending", "attempts": 0, "next_attempt_at": time.Now().UTC(), "delivered_at": nil}}
+	err := r.findOneAndUpdate(ctx, "webhook_deliveries", filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After), &delivery)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to retry webhook delivery", zap.Int64("id", id), zap.Error(err))
+	}
+	return delivery, err
+}
+
+// RotateWebhookSecret replaces the secret of a webhook, keeping the replaced one as its previous secret, and
+// returns the webhook. sql.ErrNoRows is returned when it does not exist.
+func (r *MongoRepository) RotateWebhookSecret(ctx context.Context, id int, secret string) (models.Webhook, error) {
+	r.logger.Debug("Rotating webhook secret", zap.Int("id", id))
+	var webhook models.Webhook
+	update := bson.A{bson.M{"$set": bson.M{
+		"previous_secret":   "$secret",
+		"secret":            bson.M{"$literal": secret},
+		"secret_rotated_at": time.Now().UTC(),
+	}}}
+	err := r.findOneAndUpdate(ctx, "webhooks", bson.M{"_id": id}, update, options.FindOneAndUpdate().SetReturnDocument(options.After), &webhook)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to store webhook", zap.Error(err))
+	}
+	return webhook, err
+}
+
+// AddSongEvent appends a catalog event to the event log and returns its ID. The event stays in the outbox
+// until the relay marks it published; added within RunInTransaction, it is stored with the change it records.
+// The ID is allocated in the same transaction, so events are numbered in the order they are committed.
+func (r *MongoRepository) AddSongEvent(ctx context.Context, event models.SongEvent) (int64, error) {
+	id, err := r.nextIDs(ctx, "song_events", 1)
+	if err == nil {
+		var songID any
+		if event.SongID != 0 {
+			songID = event.SongID
+		}
+		err = r.insertOne(ctx, "song_events", bson.M{
+			"_id":          id,
+			"event_type":   event.Type,
+			"song_id":      songID,
+			"count":        event.Count,
+			"occurred_at":  event.OccurredAt,
+			"published_at": nil,
+		})
+	}
+	if err != nil {
+		r.logger.Error("Failed to store song event", zap.String("event_type", event.Type), zap.Error(err))
+		return 0, err
+	}
+	return int64(id), nil
+}
+
+// GetSongEventsAfter returns up to limit events of the event log following the event with the given ID, oldest first
+func (r *MongoRepository) GetSongEventsAfter(ctx context.Context, afterID int64, limit int) ([]models.SongEvent, error) {
+	events := []models.SongEvent{}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	if err := r.find(ctx, "song_events", bson.M{"_id": bson.M{"$gt": afterID}}, opts, &events); err != nil {
+		r.logger.Error("Failed to fetch song events", zap.Int64("after_id", afterID), zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
+
+// ClaimSongEvents returns up to limit events of the outbox, the events not yet published, oldest first. It
+// runs within RunInTransaction and writes the relay's lock document in the counters collection, so the
+// transaction of another relay conflicts with it: a single relay publishes at a time and events keep their order.
+func (r *MongoRepository) ClaimSongEvents(ctx context.Context, limit int) ([]models.SongEvent, error) {
+	_, err := r.updateOne(ctx, "counters", bson.M{"_id": "song_events_relay"}, bson.M{"$inc": bson.M{"seq": 1}}, options.Update().SetUpsert(true))
+	events := []models.SongEvent{}
+	if err == nil {
+		opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+		err = r.find(ctx, "song_events", bson.M{"published_at": nil}, opts, &events)
+	}
+	if err != nil {
+		r.logger.Error("Failed to claim outbox events", zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkSongEventsPublished takes the events out of the outbox
+func (r *MongoRepository) MarkSongEventsPublished(ctx context.Context, ids []int64) error {
+	filter := bson.M{"_id": bson.M{"$in": append([]int64{}, ids...)}}
+	if _, err := r.updateMany(ctx, "song_events", filter, bson.M{"$set": bson.M{"published_at": time.Now().UTC()}}); err != nil {
+		r.logger.Error("Failed to mark outbox events published", zap.Int("count", len(ids)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetLatestSongEventID returns the ID of the latest event of the event log, zero when it is empty
+func (r *MongoRepository) GetLatestSongEventID(ctx context.Context) (int64, error) {
+	var events []models.SongEvent
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(1).SetProjection(bson.M{"_id": 1})
+	if err := r.find(ctx, "song_events", bson.M{}, opts, &events); err != nil {
+		r.logger.Error("Failed to fetch latest song event", zap.Error(err))
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+	return events[0].ID, nil
+}
+
+// DeleteSongEventsBefore drops the published events of the event log that occurred before the given time and
+// returns how many were dropped
+func (r *MongoRepository) DeleteSongEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := r.deleteMany(ctx, "song_events", bson.M{"occurred_at": bson.M{"$lt": before}, "published_at": bson.M{"$ne": nil}})
+	if err != nil {
+		r.logger.Error("Failed to prune song events", zap.Error(err))
+		return 0, err
+	}
+	return deleted, nil
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// mysqlNow is the current time, with the microseconds the time columns keep. Connections run in UTC.
+const mysqlNow = "NOW(6)"
+
+// mysqlSongColumns lists the song columns read into models.Song
+const mysqlSongColumns = `s.id, s.group_name, s.song_name, s.release_date, s.text, s.link, s.created_at, s.updated_at, s.enriched_at,
+	s.enrichment_status, s.notes, s.licensing_fee, s.album, s.duration_ms, s.isrc, s.artwork_url, s.legal_hold,
+	s.split_strategy, s.album_id, s.artist_id,
+	(SELECT AVG(sr.rating) FROM song_ratings sr WHERE sr.song_id = s.id) AS average_rating,
+	(SELECT COUNT(*) FROM song_ratings sr WHERE sr.song_id = s.id) AS rating_count`
+
+// mysqlSelectSongs selects song rows together with their view counters
+const mysqlSelectSongs = `SELECT ` + mysqlSongColumns + `, COALESCE(v.views, 0) AS views FROM songs s LEFT JOIN song_views v ON v.song_id = s.id`
+
+// mysqlSortOrders overrides the sort orders MySQL cannot run: it has no NULLS LAST, and sorts NULL first
+var mysqlSortOrders = map[string]string{
+	"rating": "average_rating IS NULL, average_rating DESC, rating_count DESC, s.id",
+}
+
+// OpenMySQL opens the MySQL or MariaDB database of the DSN, in the user:password@tcp(host:port)/dbname form
+// of the driver. Connections run in UTC and read times as time.Time, and UPDATE reports the rows it
+// matched rather than the rows it changed, as PostgreSQL does.
+func OpenMySQL(dsn string) (*sqlx.DB, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+	cfg.ClientFoundRows = true
+	cfg.InterpolateParams = true
+	if cfg.Params == nil {
+		cfg.Params = map[string]string{}
+	}
+	cfg.Params["time_zone"] = "'+00:00'"
+	cfg.Params["charset"] = "utf8mb4"
+	db, err := sqlx.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// MySQLRepository stores the music library in MySQL 8.0 or MariaDB 10.6 and later, for infrastructure
+// standardized on them. Its schema is created by the migrations in migrations/mysql. Searches use FULLTEXT
+// indexes; the similarities PostgreSQL computes with pg_trgm and pgvector are computed here.
+type MySQLRepository struct {
+	db       *sqlx.DB
+	logger   *zap.Logger
+	queryLog *QueryLog
+	songs    *Table[models.Song]
+
+	suggestions *Table[models.ClassificationSuggestion]
+	users       *Table[models.User]
+	albums      *Table[models.Album]
+	artists     *Table[models.Artist]
+	genres      *Table[models.Genre]
+	trash       *Table[models.TrashedSong]
+
+	popularity PopularityProvider
+	// statementTimeout bounds each statement run outside a transaction
+	statementTimeout time.Duration
+}
+
+var _ Repository = (*MySQLRepository)(nil)
+
+// NewMySQLRepository creates a MySQLRepository on a database opened by OpenMySQL
+func NewMySQLRepository(db *sqlx.DB, logger *zap.Logger) *MySQLRepository {
+	queryLog := NewQueryLog(defaultQueryLogSize)
+	return &MySQLRepository{
+		db:       db,
+		logger:   logger,
+		queryLog: queryLog,
+		songs:    newMySQLTable[models.Song](db, logger, queryLog, "songs", mysqlSelectSongs, "s.id"),
+
+		suggestions: newMySQLTable[models.ClassificationSuggestion](db, logger, queryLog, "classification_suggestions", selectSuggestions, "c.id"),
+		users:       newMySQLTable[models.User](db, logger, queryLog, "users", selectUsers, "id"),
+		albums:      newMySQLTable[models.Album](db, logger, queryLog, "albums", selectAlbums, "id"),
+		artists:     newMySQLTable[models.Artist](db, logger, queryLog, "artists", selectArtists, "id"),
+		genres:      newMySQLTable[models.Genre](db, logger, queryLog, "genres", selectGenres, "id"),
+		trash:       newMySQLTable[models.TrashedSong](db, logger, queryLog, "trashed_songs", selectTrashedSongs, "id"),
+
+		popularity: InternalPopularity{},
+	}
+}
+
+// newMySQLTable creates a Table whose statements run on MySQL
+func newMySQLTable[T any](db *sqlx.DB, logger *zap.Logger, queryLog *QueryLog, name, selectQuery, idColumn string) *Table[T] {
+	table := NewTable[T](db, logger, queryLog, name, selectQuery, idColumn)
+	table.dialect = newMySQLConn
+	table.lastInsertID = true
+	return table
+}
+
+// ConfigureStatementTimeout bounds every statement run outside a transaction, including those of the
+// entity tables; zero leaves statements bounded only by the caller's context
+func (r *MySQLRepository) ConfigureStatementTimeout(timeout time.Duration) {
+	r.statementTimeout = timeout
+	r.songs.timeout = timeout
+	r.suggestions.timeout = timeout
+	r.users.timeout = timeout
+	r.albums.timeout = timeout
+	r.artists.timeout = timeout
+	r.genres.timeout = timeout
+	r.trash.timeout = timeout
+}
+
+// ConfigurePopularity sets the provider scoring songs for sort=popularity; the default counts internal plays
+func (r *MySQLRepository) ConfigurePopularity(provider PopularityProvider) {
+	r.popularity = provider
+}
+
+// orderBy returns the ORDER BY clause of the sort key, ordering by ID for unsupported keys
+func (r *MySQLRepository) orderBy(sort string) string {
+	if sort == SortPopularity {
+		return r.popularity.Score() + " DESC, s.id"
+	}
+	if orderBy, ok := mysqlSortOrders[sort]; ok {
+		return orderBy
+	}
+	if orderBy, ok := sortOrders[sort]; ok {
+		return orderBy
+	}
+	return sortOrders["id"]
+}
+
+// statementContext bounds a statement by the configured statement timeout
+func (r *MySQLRepository) statementContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withStatementTimeout(ctx, r.statementTimeout)
+}
+
+// Ping checks that the database is reachable
+func (r *MySQLRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// RecentQueries returns the most recently executed queries, newest first
+func (r *MySQLRepository) RecentQueries() []QueryLogEntry {
+	return r.queryLog.Entries()
+}
+
+// track records a finished query in the query log
+func (r *MySQLRepository) track(query string, start time.Time, rows int64, err error) {
+	duration := time.Since(start)
+	r.queryLog.Record(query, duration, rows, err)
+	metrics.ObserveQuery(query, duration, err)
+}
+
+// conn returns what the statements of a repository method run on
+func (r *MySQLRepository) conn(ctx context.Context) queryer {
+	return newMySQLConn(txOrDB(ctx, r.db))
+}
+
+// RunInTransaction runs fn in a single transaction, committed when fn returns nil and rolled back otherwise.
+// The repository writes fn makes with the context it is given join the transaction. Nested calls join the
+// outer transaction.
+func (r *MySQLRepository) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if inTransaction(ctx) {
+		return fn(ctx)
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// mysqlTx is the transaction of a repository method, whose statements run on MySQL
+type mysqlTx struct {
+	mysqlConn
+	scope txScope
+}
+
+// begin starts the transaction of a repository method, joining the one ctx carries if any
+func (r *MySQLRepository) begin(ctx context.Context) (mysqlTx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return mysqlTx{mysqlConn: mysqlConn{tx}, scope: txScope{Tx: tx}}, nil
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return mysqlTx{}, err
+	}
+	return mysqlTx{mysqlConn: mysqlConn{tx}, scope: txScope{Tx: tx, owned: true}}, nil
+}
+
+// Commit commits an owned transaction
+func (t mysqlTx) Commit() error {
+	return t.scope.Commit()
+}
+
+// Rollback rolls back an owned transaction
+func (t mysqlTx) Rollback() error {
+	return t.scope.Rollback()
+}
+
+// mysqlPlaceholder matches the $n placeholders of a statement
+var mysqlPlaceholder = regexp.MustCompile(`\$(\d+)`)
+
+// mysqlConn runs statements written with PostgreSQL's $n placeholders on MySQL, whose placeholders are
+// all written ? and bound in order, and binds JSON documents as text, which MySQL requires of JSON columns
+type mysqlConn struct {
+	queryer
+}
+
+// newMySQLConn wraps what the statements run on
+func newMySQLConn(q queryer) queryer {
+	return mysqlConn{q}
+}
+
+// mysqlStatement rewrites the placeholders of a statement for MySQL, repeating the arguments of the
+// placeholders used more than once
+func mysqlStatement(query string, args []any) (string, []any) {
+	bound := make([]any, 0, len(args))
+	statement := mysqlPlaceholder.ReplaceAllStringFunc(query, func(placeholder string) string {
+		n, _ := strconv.Atoi(placeholder[1:])
+		if n >= 1 && n <= len(args) {
+			bound = append(bound, mysqlArg(args[n-1]))
+		}
+		return "?"
+	})
+	return statement, bound
+}
+
+// mysqlArg returns the value MySQL binds for an argument: JSON documents and byte strings as text,
+// since MySQL rejects binary strings in JSON columns
+func mysqlArg(arg any) any {
+	switch value := arg.(type) {
+	case json.RawMessage:
+		if value == nil {
+			return nil
+		}
+		return string(value)
+	case *json.RawMessage:
+		if value == nil || *value == nil {
+			return nil
+		}
+		return string(*value)
+	case []byte:
+		if value == nil {
+			return nil
+		}
+		return string(value)
+	}
+	return arg
+}
+
+// ExecContext runs a statement
+func (c mysqlConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	query, args = mysqlStatement(query, args)
+	return c.queryer.ExecContext(ctx, query, args...)
+}
+
+// QueryContext runs a query
+func (c mysqlConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	query, args = mysqlStatement(query, args)
+	return c.queryer.QueryContext(ctx, query, args...)
+}
+
+// QueryxContext runs a query
+func (c mysqlConn) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+	query, args = mysqlStatement(query, args)
+	return c.queryer.QueryxContext(ctx, query, args...)
+}
+
+// QueryRowxContext runs a query returning a single row
+func (c mysqlConn) QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row {
+	query, args = mysqlStatement(query, args)
+	return c.queryer.QueryRowxContext(ctx, query, args...)
+}
+
+// QueryRowContext runs a query returning a single row
+func (c mysqlConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	query, args = mysqlStatement(query, args)
+	return c.queryer.QueryRowContext(ctx, query, args...)
+}
+
+// GetContext runs a query and scans its single row into dest
+func (c mysqlConn) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	query, args = mysqlStatement(query, args)
+	return c.queryer.GetContext(ctx, dest, query, args...)
+}
+
+// SelectContext runs a query and scans its rows into dest
+func (c mysqlConn) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	query, args = mysqlStatement(query, args)
+	return c.queryer.SelectContext(ctx, dest, query, args...)
+}
+
+// mysqlDuplicateKey reports whether the error is a unique constraint violation, which statements check for
+// where PostgreSQL's upsert would do nothing: INSERT IGNORE would also let other errors through as warnings
+func mysqlDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+// mysqlInts expands the JSON array of integers bound to the placeholder into rows of a value column,
+// as in "id IN (" + mysqlInts("$1") + ")"
+func mysqlInts(placeholder string) string {
+	return "SELECT value FROM JSON_TABLE(" + placeholder + ", '$[*]' COLUMNS (value BIGINT PATH '$')) AS list"
+}
+
+// mysqlStrings expands the JSON array of strings bound to the placeholder into rows of a value column
+func mysqlStrings(placeholder string) string {
+	return "SELECT value FROM JSON_TABLE(" + placeholder + ", '$[*]' COLUMNS (value VARCHAR(255) CHARACTER SET utf8mb4 PATH '$')) AS list"
+}
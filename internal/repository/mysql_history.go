@@ -0,0 +1,720 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// mysqlIdentifier quotes an identifier for MySQL
+func mysqlIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// tableColumns lists the columns of a table in their order
+func (r *MySQLRepository) tableColumns(ctx context.Context, q queryer, table string) ([]string, error) {
+	var columns []string
+	err := q.SelectContext(ctx, &columns, `SELECT column_name AS name FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = $1 ORDER BY ordinal_position`, table)
+	if err != nil {
+		r.logger.Error("Failed to list columns", zap.String("table", table), zap.Error(err))
+		return nil, err
+	}
+	return columns, nil
+}
+
+// snapshotData returns the expression of the JSON object holding the rows of every snapshot table, by table
+// name. songID is the placeholder of the song whose rows are held, or empty for the rows of every song.
+// MySQL cannot turn a whole row into JSON, so the columns of each table are listed.
+func (r *MySQLRepository) snapshotData(ctx context.Context, q queryer, songID string) (string, error) {
+	parts := make([]string, 0, len(snapshotTables))
+	for _, table := range snapshotTables {
+		columns, err := r.tableColumns(ctx, q, table.name)
+		if err != nil {
+			return "", err
+		}
+		fields := make([]string, len(columns))
+		for i, column := range columns {
+			fields[i] = fmt.Sprintf("'%s', r.%s", column, mysqlIdentifier(column))
+		}
+		where := ""
+		if songID != "" {
+			key := "song_id"
+			if table.name == "songs" {
+				key = "id"
+			}
+			where = fmt.Sprintf(" WHERE r.%s = %s", key, songID)
+		}
+		parts = append(parts, fmt.Sprintf("'%s', (SELECT COALESCE(JSON_ARRAYAGG(JSON_OBJECT(%s)), JSON_ARRAY()) FROM %s r%s)",
+			table.name, strings.Join(fields, ", "), mysqlIdentifier(table.name), where))
+	}
+	return "JSON_OBJECT(" + strings.Join(parts, ", ") + ")", nil
+}
+
+// CreateSnapshot stores a copy of the songs and the rows attached to them. The INSERT ... SELECT takes shared
+// locks on the rows it reads and the gaps between them until the transaction ends, so a change made in the
+// same transaction, such as a truncate, loses no song the snapshot does not hold.
+func (r *MySQLRepository) CreateSnapshot(ctx context.Context, reason string) (models.Snapshot, error) {
+	r.logger.Debug("Creating snapshot", zap.String("reason", reason))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return models.Snapshot{}, err
+	}
+	defer tx.Rollback()
+
+	data, err := r.snapshotData(ctx, tx, "")
+	if err != nil {
+		return models.Snapshot{}, err
+	}
+	query := fmt.Sprintf(`INSERT INTO snapshots (reason, song_count, data)
+		SELECT $1, (SELECT COUNT(*) FROM songs), %s`, data)
+	var snapshot models.Snapshot
+	start := time.Now()
+	id, err := insertID(tx.ExecContext(ctx, query, reason))
+	if err == nil {
+		err = tx.GetContext(ctx, &snapshot, "SELECT "+snapshotColumns+" FROM snapshots WHERE id = $1", id)
+	}
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to create snapshot", zap.Error(err))
+		return models.Snapshot{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return models.Snapshot{}, err
+	}
+	r.logger.Info("Snapshot created in database", zap.Int("id", snapshot.ID), zap.Int("songs", snapshot.SongCount))
+	return snapshot, nil
+}
+
+// GetSnapshots returns the snapshots, newest first
+func (r *MySQLRepository) GetSnapshots(ctx context.Context) ([]models.Snapshot, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT " + snapshotColumns + " FROM snapshots ORDER BY id DESC"
+	snapshots := []models.Snapshot{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &snapshots, query)
+	r.track(query, start, int64(len(snapshots)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch snapshots", zap.Error(err))
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// RestoreSnapshot inserts the rows a snapshot holds back into their tables, the songs keeping their IDs, and
+// returns the number of songs restored. The songs table is expected to be empty. Rows referencing a user,
+// a tag or a genre deleted since are left out, and songs whose album was deleted are restored without it. sql.ErrNoRows
+// is returned when the snapshot does not exist.
+func (r *MySQLRepository) RestoreSnapshot(ctx context.Context, id int) (int, error) {
+	r.logger.Debug("Restoring snapshot", zap.Int("id", id))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.GetContext(ctx, &count, "SELECT song_count FROM snapshots WHERE id = $1", id); err != nil {
+		return 0, err
+	}
+	// Songs inserted with their IDs move the AUTO_INCREMENT counter past them, so new songs cannot collide
+	if err := r.restoreRows(ctx, tx, "snapshots", id); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE snapshots SET restored_at = "+mysqlNow+" WHERE id = $1", id); err != nil {
+		r.logger.Error("Failed to mark snapshot restored", zap.Error(err))
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return 0, err
+	}
+	r.logger.Info("Snapshot restored in database", zap.Int("id", id), zap.Int("songs", count))
+	return count, nil
+}
+
+// restoreRows inserts the rows of the snapshot tables held in the data column of the source table's row with
+// the ID back into their tables, leaving out those referencing rows deleted since. The rows are decoded here
+// and inserted one by one, as JSON_TABLE would read a JSON null as the text "null".
+func (r *MySQLRepository) restoreRows(ctx context.Context, tx mysqlTx, source string, id int) error {
+	var data []byte
+	if err := tx.GetContext(ctx, &data, "SELECT data FROM "+mysqlIdentifier(source)+" WHERE id = $1", id); err != nil {
+		r.logger.Error("Failed to read rows to restore", zap.String("source", source), zap.Error(err))
+		return err
+	}
+	var held map[string][]map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&held); err != nil {
+		r.logger.Error("Failed to decode rows to restore", zap.String("source", source), zap.Error(err))
+		return err
+	}
+
+	for _, table := range snapshotTables {
+		columns, err := r.tableColumns(ctx, tx, table.name)
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		var restored int64
+		for _, row := range held[table.name] {
+			// Columns added since the rows were held take their default
+			var names, fields, values []string
+			var args []any
+			for _, column := range columns {
+				value, ok := row[column]
+				if !ok {
+					continue
+				}
+				if nested, ok := value.(map[string]any); ok {
+					encoded, err := json.Marshal(nested)
+					if err != nil {
+						return err
+					}
+					value = string(encoded)
+				}
+				args = append(args, value)
+				name := mysqlIdentifier(column)
+				names = append(names, name)
+				fields = append(fields, fmt.Sprintf("$%d AS %s", len(args), name))
+				expression := "r." + name
+				if override, ok := table.expressions[column]; ok {
+					expression = override
+				}
+				values = append(values, expression)
+			}
+			query := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM (SELECT %s) r",
+				mysqlIdentifier(table.name), strings.Join(names, ", "), strings.Join(values, ", "), strings.Join(fields, ", "))
+			if table.filter != "" {
+				query += " WHERE " + table.filter
+			}
+			result, err := tx.ExecContext(ctx, query, args...)
+			if err != nil {
+				r.track(query, start, restored, err)
+				r.logger.Error("Failed to restore rows", zap.String("source", source), zap.String("table", table.name), zap.Error(err))
+				return err
+			}
+			rows, _ := result.RowsAffected()
+			restored += rows
+		}
+		r.track("INSERT INTO "+table.name, start, restored, nil)
+	}
+	return nil
+}
+
+// TrashSong moves a song to the trash: it is deleted together with the rows attached to it, which the trash
+// keeps to restore them, the same ones a snapshot holds. sql.ErrNoRows is returned when the song does not
+// exist. A song in the trash under the same ID, left from before the IDs were reset, is replaced.
+func (r *MySQLRepository) TrashSong(ctx context.Context, id int) error {
+	r.logger.Debug("Moving song to trash", zap.Int("id", id))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	data, err := r.snapshotData(ctx, tx, "$1")
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO trashed_songs (id, group_name, song_name, data)
+		SELECT s.id, s.group_name, s.song_name, ` + data + `
+		FROM songs s WHERE s.id = $1
+		ON DUPLICATE KEY UPDATE group_name = VALUES(group_name), song_name = VALUES(song_name),
+			data = VALUES(data), deleted_at = ` + mysqlNow
+	start := time.Now()
+	result, err := tx.ExecContext(ctx, query, id)
+	var rows int64
+	if err == nil {
+		rows, err = result.RowsAffected()
+	}
+	if err == nil && rows == 0 {
+		err = sql.ErrNoRows
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to move song to trash", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	query = "DELETE FROM songs WHERE id = $1"
+	start = time.Now()
+	_, err = tx.ExecContext(ctx, query, id)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to delete song", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Song moved to trash in database", zap.Int("id", id))
+	return nil
+}
+
+// GetTrashedSong retrieves a song in the trash, returning sql.ErrNoRows when it is not there
+func (r *MySQLRepository) GetTrashedSong(ctx context.Context, id int) (models.TrashedSong, error) {
+	return r.trash.Get(ctx, id)
+}
+
+// GetTrash retrieves a page of the songs in the trash, most recently deleted first, and their total number
+func (r *MySQLRepository) GetTrash(ctx context.Context, page, limit int) ([]models.TrashedSong, int, error) {
+	r.logger.Debug("Fetching trash", zap.Int("page", page), zap.Int("limit", limit))
+	songs, err := r.trash.List(ctx, "", nil, "deleted_at DESC, id DESC", page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.trash.Count(ctx, "", nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return songs, total, nil
+}
+
+// RestoreTrashedSong puts a song in the trash back into the catalog under its ID, with the rows attached to
+// it, and takes it out of the trash. Rows referencing a user, a tag or a genre deleted since are left out,
+// and the song is restored without its album when that was deleted. The ID is expected to be free.
+// sql.ErrNoRows is returned when the song is not in the trash.
+func (r *MySQLRepository) RestoreTrashedSong(ctx context.Context, id int) error {
+	r.logger.Debug("Restoring song from trash", zap.Int("id", id))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.GetContext(ctx, &id, "SELECT id FROM trashed_songs WHERE id = $1", id); err != nil {
+		return err
+	}
+	// Inserting the song under its ID moves the AUTO_INCREMENT counter past it if it was below
+	if err := r.restoreRows(ctx, tx, "trashed_songs", id); err != nil {
+		return err
+	}
+	query := "DELETE FROM trashed_songs WHERE id = $1"
+	start := time.Now()
+	_, err = tx.ExecContext(ctx, query, id)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to take song out of trash", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Song restored from trash in database", zap.Int("id", id))
+	return nil
+}
+
+// DeleteTrashedSong deletes a song in the trash for good, returning sql.ErrNoRows when it is not there
+func (r *MySQLRepository) DeleteTrashedSong(ctx context.Context, id int) error {
+	r.logger.Debug("Purging song from trash", zap.Int("id", id))
+	return r.trash.Delete(ctx, id)
+}
+
+// PurgeTrash deletes for good the songs moved to the trash before the given time and returns how many were
+// deleted
+func (r *MySQLRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "DELETE FROM trashed_songs WHERE deleted_at < $1"
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, before)
+	var rows int64
+	if err == nil {
+		rows, err = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to purge trash", zap.Error(err))
+		return 0, err
+	}
+	return rows, nil
+}
+
+// AddSongRevision records the song's editable fields as they currently are as its next revision and
+// returns the revision number, or sql.ErrNoRows when the song does not exist. restoredFrom is the revision
+// a restore brought back, nil for other reasons.
+func (r *MySQLRepository) AddSongRevision(ctx context.Context, songID int, reason string, restoredFrom *int) (int, error) {
+	r.logger.Debug("Recording song revision", zap.Int("song_id", songID), zap.String("reason", reason))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO song_revisions (song_id, revision, reason, restored_from, data)
+		SELECT s.id, COALESCE((SELECT MAX(revision) FROM song_revisions WHERE song_id = s.id), 0) + 1, $2, $3,
+			JSON_OBJECT('id', s.id, 'group', s.group_name, 'song', s.song_name, 'release_date', s.release_date,
+				'text', s.text, 'link', s.link, 'notes', s.notes, 'licensing_fee', s.licensing_fee,
+				'split_strategy', s.split_strategy, 'album_id', s.album_id)
+		FROM songs s WHERE s.id = $1`
+	var revision int
+	start := time.Now()
+	result, err := tx.ExecContext(ctx, query, songID, reason, nullIfNil(restoredFrom))
+	var rows int64
+	if err == nil {
+		rows, err = result.RowsAffected()
+	}
+	if err == nil && rows == 0 {
+		err = sql.ErrNoRows
+	}
+	if err == nil {
+		err = tx.GetContext(ctx, &revision, "SELECT MAX(revision) FROM song_revisions WHERE song_id = $1", songID)
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to record song revision", zap.Int("song_id", songID), zap.Error(err))
+		}
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return 0, err
+	}
+	return revision, nil
+}
+
+// CountSongRevisions returns the number of revisions recorded for the song
+func (r *MySQLRepository) CountSongRevisions(ctx context.Context, songID int) (int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT COUNT(*) FROM song_revisions WHERE song_id = $1"
+	var count int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &count, query, songID)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to count song revisions", zap.Int("song_id", songID), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetSongRevisions retrieves the revisions of a song, newest first
+func (r *MySQLRepository) GetSongRevisions(ctx context.Context, songID int) ([]models.SongRevision, error) {
+	r.logger.Debug("Fetching song revisions", zap.Int("song_id", songID))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := selectSongRevisions + " WHERE song_id = $1 ORDER BY revision DESC"
+	var rows []songRevisionRow
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &rows, query, songID)
+	r.track(query, start, int64(len(rows)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch song revisions", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+	revisions := make([]models.SongRevision, 0, len(rows))
+	for _, row := range rows {
+		revision, err := row.decode()
+		if err != nil {
+			r.logger.Error("Failed to decode song revision", zap.Int("song_id", songID), zap.Int("revision", row.Revision), zap.Error(err))
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+// GetSongRevision retrieves a revision of a song, returning sql.ErrNoRows when it does not exist
+func (r *MySQLRepository) GetSongRevision(ctx context.Context, songID, revision int) (models.SongRevision, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := selectSongRevisions + " WHERE song_id = $1 AND revision = $2"
+	var row songRevisionRow
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &row, query, songID, revision)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to fetch song revision", zap.Int("song_id", songID), zap.Int("revision", revision), zap.Error(err))
+		}
+		return models.SongRevision{}, err
+	}
+	return row.decode()
+}
+
+// BackfillLegacyRows normalizes rows written under the legacy data conventions in a single transaction:
+// missing timestamps are filled, blank optional fields become NULL and duplicate songs are merged into
+// the oldest one. With dryRun the same work is done and reported, then rolled back.
+func (r *MySQLRepository) BackfillLegacyRows(ctx context.Context, dryRun bool) (models.BackfillReport, error) {
+	r.logger.Debug("Backfilling legacy rows", zap.Bool("dry_run", dryRun))
+	report := models.BackfillReport{DryRun: dryRun, EmptyFields: make(map[string]int)}
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return report, err
+	}
+	defer tx.Rollback()
+
+	// ON UPDATE would stamp every normalized row as just updated, unless updated_at is assigned as well
+	if report.MissingCreatedAt, err = r.backfillExec(ctx, tx, "UPDATE songs SET created_at = COALESCE(updated_at, "+mysqlNow+"), updated_at = updated_at WHERE created_at IS NULL"); err != nil {
+		return report, err
+	}
+	if report.MissingUpdatedAt, err = r.backfillExec(ctx, tx, "UPDATE songs SET updated_at = created_at WHERE updated_at IS NULL"); err != nil {
+		return report, err
+	}
+	for _, field := range backfillFields {
+		query := fmt.Sprintf("UPDATE songs SET %[1]s = NULL, updated_at = updated_at WHERE TRIM(%[1]s) = ''", field)
+		if report.EmptyFields[field], err = r.backfillExec(ctx, tx, query); err != nil {
+			return report, err
+		}
+	}
+	if report.Duplicates, err = r.mergeDuplicateSongs(ctx, tx); err != nil {
+		return report, err
+	}
+
+	if dryRun {
+		r.logger.Info("Legacy rows backfill dry run finished", zap.Int("duplicates", len(report.Duplicates)))
+		return report, nil
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit legacy rows backfill", zap.Error(err))
+		return report, err
+	}
+	r.logger.Info("Legacy rows backfilled", zap.Int("duplicates", len(report.Duplicates)))
+	return report, nil
+}
+
+// backfillExec runs a normalizing statement and returns the number of rows it changed
+func (r *MySQLRepository) backfillExec(ctx context.Context, tx mysqlTx, query string) (int, error) {
+	start := time.Now()
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to backfill legacy rows", zap.String("query", query), zap.Error(err))
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	return int(rows), err
+}
+
+// mergeDuplicateSongs merges every set of songs sharing a group and title into its oldest song,
+// which keeps its own values and takes the first known value of the others for its NULL fields.
+// Songs on legal hold are neither merged into nor merged away. The songs are grouped here rather than
+// in SQL, where the collation would also take names differing by accents for the same.
+func (r *MySQLRepository) mergeDuplicateSongs(ctx context.Context, tx mysqlTx) ([]models.DuplicateSongs, error) {
+	var rows []struct {
+		ID    int    `db:"id"`
+		Group string `db:"group_name"`
+		Song  string `db:"song_name"`
+	}
+	query := "SELECT id, group_name, song_name FROM songs WHERE NOT legal_hold ORDER BY id"
+	start := time.Now()
+	err := tx.SelectContext(ctx, &rows, query)
+	r.track(query, start, int64(len(rows)), err)
+	if err != nil {
+		r.logger.Error("Failed to find duplicate songs", zap.Error(err))
+		return nil, err
+	}
+
+	var duplicates []*models.DuplicateSongs
+	byKey := make(map[[2]string]*models.DuplicateSongs)
+	for _, row := range rows {
+		key := [2]string{strings.ToLower(strings.Trim(row.Group, " ")), strings.ToLower(strings.Trim(row.Song, " "))}
+		if duplicate, ok := byKey[key]; ok {
+			duplicate.RemovedIDs = append(duplicate.RemovedIDs, row.ID)
+			continue
+		}
+		duplicate := &models.DuplicateSongs{Group: row.Group, Song: row.Song, KeptID: row.ID}
+		byKey[key] = duplicate
+		duplicates = append(duplicates, duplicate)
+	}
+
+	merged := make([]models.DuplicateSongs, 0)
+	for _, duplicate := range duplicates {
+		if len(duplicate.RemovedIDs) == 0 {
+			continue
+		}
+		if err := r.mergeSongs(ctx, tx, duplicate.KeptID, duplicate.RemovedIDs); err != nil {
+			r.logger.Error("Failed to merge duplicate songs", zap.Int("kept_id", duplicate.KeptID), zap.Error(err))
+			return nil, err
+		}
+		merged = append(merged, *duplicate)
+	}
+	return merged, nil
+}
+
+// mergeSongs fills the NULL fields of the kept song from the removed ones, adds up their views,
+// carries over their tags and the rows of mergedSongTables and their revisions, and deletes them. MySQL
+// cannot update songs from a subquery reading songs, so the values filled in are read first.
+func (r *MySQLRepository) mergeSongs(ctx context.Context, tx mysqlTx, keptID int, removedIDs []int) error {
+	removed := jsonList(removedIDs)
+	var fill struct {
+		ReleaseDate *string `db:"release_date"`
+		Text        *string `db:"text"`
+		Link        *string `db:"link"`
+	}
+	query := `SELECT
+		(SELECT d.release_date FROM songs d WHERE d.id IN (` + mysqlInts("$1") + `) AND d.release_date IS NOT NULL ORDER BY d.id LIMIT 1) AS release_date,
+		(SELECT d.text FROM songs d WHERE d.id IN (` + mysqlInts("$1") + `) AND d.text IS NOT NULL ORDER BY d.id LIMIT 1) AS text,
+		(SELECT d.link FROM songs d WHERE d.id IN (` + mysqlInts("$1") + `) AND d.link IS NOT NULL ORDER BY d.id LIMIT 1) AS link`
+	start := time.Now()
+	err := tx.GetContext(ctx, &fill, query, removed)
+	r.track(query, start, 1, err)
+	if err != nil {
+		return err
+	}
+	query = `UPDATE songs SET release_date = COALESCE(release_date, $2), text = COALESCE(text, $3),
+		link = COALESCE(link, $4), updated_at = updated_at WHERE id = $1`
+	start = time.Now()
+	_, err = tx.ExecContext(ctx, query, keptID, fill.ReleaseDate, fill.Text, fill.Link)
+	r.track(query, start, 1, err)
+	if err != nil {
+		return err
+	}
+
+	queries := []string{
+		`INSERT INTO song_views (song_id, views)
+		SELECT $1, SUM(v.views) FROM song_views v WHERE v.song_id IN (` + mysqlInts("$2") + `) HAVING COUNT(*) > 0
+		ON DUPLICATE KEY UPDATE views = song_views.views + VALUES(views)`,
+		`INSERT INTO song_view_days (song_id, day, views)
+		SELECT $1, v.day, SUM(v.views) FROM song_view_days v WHERE v.song_id IN (` + mysqlInts("$2") + `) GROUP BY v.day
+		ON DUPLICATE KEY UPDATE views = song_view_days.views + VALUES(views)`,
+		`INSERT INTO song_tags (song_id, tag_id)
+		SELECT DISTINCT $1, t.tag_id FROM song_tags t WHERE t.song_id IN (` + mysqlInts("$2") + `)
+			AND NOT EXISTS (SELECT 1 FROM song_tags k WHERE k.song_id = $1 AND k.tag_id = t.tag_id)`,
+	}
+	queries = append(queries, mergeSongRowsQueries("$1", "IN ("+mysqlInts("$2")+")")...)
+	for _, query := range append(queries, `DELETE FROM songs WHERE id IN (`+mysqlInts("$2")+`)`) {
+		start := time.Now()
+		result, err := tx.ExecContext(ctx, query, keptID, removed)
+		var rows int64
+		if err == nil {
+			rows, _ = result.RowsAffected()
+		}
+		r.track(query, start, rows, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveVerseIndex stores the verse spans of a song's text for the delimiter, replacing its previous index.
+// Nothing is stored when the song's text no longer hashes to textHash, as another write changed it since
+// the spans were computed.
+func (r *MySQLRepository) SaveVerseIndex(ctx context.Context, songID int, delimiter, textHash string, spans []models.VerseSpan) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the song keeps its text from changing until the index is committed
+	checkQuery := `SELECT ` + songTextHash + ` = $2 FROM songs s WHERE s.id = $1 FOR UPDATE`
+	start := time.Now()
+	var current bool
+	err = tx.GetContext(ctx, &current, checkQuery, songID, textHash)
+	r.track(checkQuery, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to check song text", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	if !current {
+		r.logger.Debug("Song text changed, verse index not stored", zap.Int("song_id", songID))
+		return nil
+	}
+
+	indexQuery := `INSERT INTO song_verse_index (song_id, delimiter, content_hash, total_verses) VALUES ($1, $2, $3, $4)
+		ON DUPLICATE KEY UPDATE delimiter = VALUES(delimiter), content_hash = VALUES(content_hash),
+		total_verses = VALUES(total_verses), indexed_at = ` + mysqlNow
+	if _, err := tx.ExecContext(ctx, indexQuery, songID, delimiter, textHash, len(spans)); err != nil {
+		r.track(indexQuery, start, 0, err)
+		r.logger.Error("Failed to store verse index", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM song_verses WHERE song_id = $1", songID); err != nil {
+		r.logger.Error("Failed to drop old verse spans", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	// The spans are passed as a JSON array of objects
+	spansQuery := `INSERT INTO song_verses (song_id, number, label, start_offset, length)
+		SELECT $1, v.number, v.label, v.start, v.length
+		FROM JSON_TABLE($2, '$[*]' COLUMNS (number INT PATH '$.number', label VARCHAR(255) PATH '$.label',
+			start INT PATH '$.start', length INT PATH '$.length')) AS v`
+	rows := make([]map[string]any, len(spans))
+	for i, span := range spans {
+		rows[i] = map[string]any{"number": span.Number, "label": span.Label, "start": span.Start, "length": span.Length}
+	}
+	if _, err := tx.ExecContext(ctx, spansQuery, songID, jsonList(rows)); err != nil {
+		r.track(spansQuery, start, 0, err)
+		r.logger.Error("Failed to store verse spans", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit verse index", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	r.track(spansQuery, start, int64(len(spans)), nil)
+	return nil
+}
+
+// GetVerseIndex returns a song with its split strategy and, when an index of its current text is stored,
+// the delimiter it was split at and the number of verses it has
+func (r *MySQLRepository) GetVerseIndex(ctx context.Context, songID int) (models.VerseIndex, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT s.id AS song_id, s.group_name, s.song_name, s.split_strategy, i.song_id IS NOT NULL AS indexed,
+		COALESCE(i.delimiter, '') AS delimiter, COALESCE(i.total_verses, 0) AS total_verses
+		FROM songs s LEFT JOIN song_verse_index i
+			ON i.song_id = s.id AND i.content_hash = ` + songTextHash + `
+		WHERE s.id = $1`
+	var index models.VerseIndex
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &index, query, songID)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to fetch verse index", zap.Int("song_id", songID), zap.Error(err))
+	}
+	return index, err
+}
+
+// GetIndexedVerses cuts the verses numbered from through to out of a song's text by their stored spans.
+// No verses are returned when the text changed since it was indexed.
+func (r *MySQLRepository) GetIndexedVerses(ctx context.Context, songID int, delimiter string, from, to int) ([]models.IndexedVerse, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `WITH t AS (
+			SELECT REPLACE(COALESCE(s.text, ''), CHAR(13, 10 USING utf8mb4), CHAR(10 USING utf8mb4)) AS text
+			FROM songs s JOIN song_verse_index i ON i.song_id = s.id
+			WHERE s.id = $1 AND i.delimiter = $2 AND i.content_hash = ` + songTextHash + `
+		)
+		SELECT v.number, v.label, SUBSTRING(t.text, v.start_offset + 1, v.length) AS text
+		FROM song_verses v, t
+		WHERE v.song_id = $1 AND v.number BETWEEN $3 AND $4
+		ORDER BY v.number`
+	verses := []models.IndexedVerse{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &verses, query, songID, delimiter, from, to)
+	r.track(query, start, int64(len(verses)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch indexed verses", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+	return verses, nil
+}
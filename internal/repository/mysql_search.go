@@ -0,0 +1,415 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// mysqlSongContentHash is the SQL counterpart of SongContentHash
+const mysqlSongContentHash = `MD5(CONCAT(s.group_name, CHAR(10 USING utf8mb4), s.song_name, CHAR(10 USING utf8mb4), COALESCE(s.text, '')))`
+
+// mysqlFulltextMinLength is InnoDB's default innodb_ft_min_token_size: shorter words are not indexed
+const mysqlFulltextMinLength = 3
+
+// mysqlStopwords are InnoDB's default full-text stopwords, which are not indexed either
+var mysqlStopwords = map[string]bool{
+	"a": true, "about": true, "an": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"com": true, "de": true, "en": true, "for": true, "from": true, "how": true, "i": true, "in": true,
+	"is": true, "it": true, "la": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "was": true, "what": true, "when": true, "where": true, "who": true,
+	"will": true, "with": true, "und": true, "www": true,
+}
+
+// mysqlBooleanQuery returns the FULLTEXT boolean-mode query selecting the documents that may match the
+// search: each clause requires the indexed words of any of its phrases. Clauses with a phrase of unindexed
+// words only cannot be checked by the index and are left out, as are excluded phrases; an empty query
+// means every document may match.
+func mysqlBooleanQuery(query searchQuery) string {
+	var clauses []string
+	for _, clause := range query.clauses {
+		phrases := make([]string, 0, len(clause))
+		for _, phrase := range clause {
+			var words []string
+			for _, word := range phrase {
+				if utf8.RuneCountInString(word) >= mysqlFulltextMinLength && !mysqlStopwords[word] {
+					words = append(words, "+"+word)
+				}
+			}
+			if len(words) == 0 {
+				phrases = nil
+				break
+			}
+			phrases = append(phrases, "("+strings.Join(words, " ")+")")
+		}
+		if len(phrases) > 0 {
+			clauses = append(clauses, "+("+strings.Join(phrases, " ")+")")
+		}
+	}
+	return strings.Join(clauses, " ")
+}
+
+// SearchSongs finds songs whose title, in any language, group or lyrics match the query, using web-search syntax
+// ("quoted phrases", OR, -excluded). Title matches rank above group matches, which rank above lyrics matches.
+// Each result carries a lyrics snippet with the matches wrapped in <mark> tags.
+// The FULLTEXT indexes narrow the songs down, which are then ranked as in SQLiteRepository, so that both
+// backends agree with PostgreSQL's simple text search configuration.
+func (r *MySQLRepository) SearchSongs(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	r.logger.Debug("Searching songs", zap.String("query", query), zap.Int("limit", limit))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	parsed := parseSearchQuery(query)
+	if len(parsed.clauses) == 0 {
+		return []models.SearchResult{}, nil
+	}
+
+	sqlQuery := "SELECT s.id, s.group_name, s.song_name, s.text FROM songs s"
+	var args []any
+	if boolean := mysqlBooleanQuery(parsed); boolean != "" {
+		sqlQuery += ` WHERE MATCH (s.song_name, s.group_name, s.text) AGAINST ($1 IN BOOLEAN MODE)
+			OR s.id IN (SELECT t.song_id FROM song_titles t WHERE MATCH (t.title) AGAINST ($1 IN BOOLEAN MODE))`
+		args = append(args, boolean)
+	}
+	var candidates []models.Song
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &candidates, sqlQuery, args...)
+	r.track(sqlQuery, start, int64(len(candidates)), err)
+	if err != nil {
+		r.logger.Error("Failed to search songs", zap.Error(err))
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return []models.SearchResult{}, nil
+	}
+
+	ids := make([]int, len(candidates))
+	for i, song := range candidates {
+		ids[i] = song.ID
+	}
+	titlesQuery := "SELECT song_id, title FROM song_titles WHERE song_id IN (" + mysqlInts("$1") + ")"
+	var titles []struct {
+		SongID int    `db:"song_id"`
+		Title  string `db:"title"`
+	}
+	start = time.Now()
+	err = r.conn(ctx).SelectContext(ctx, &titles, titlesQuery, jsonList(ids))
+	r.track(titlesQuery, start, int64(len(titles)), err)
+	if err != nil {
+		r.logger.Error("Failed to search songs", zap.Error(err))
+		return nil, err
+	}
+
+	weights := []float64{titleWeight, groupWeight, textWeight}
+	scores := make(map[int]float64, len(candidates))
+	for _, song := range candidates {
+		text := ""
+		if song.Text != nil {
+			text = *song.Text
+		}
+		if score := parsed.rank([][]string{searchWords(song.Song), searchWords(song.Group), searchWords(text)}, weights); score > 0 {
+			scores[song.ID] = score
+		}
+	}
+	for _, title := range titles {
+		score := parsed.rank([][]string{searchWords(title.Title), nil, nil}, weights)
+		if score > scores[title.SongID] {
+			scores[title.SongID] = score
+		}
+	}
+
+	results, err := r.rankedSongs(ctx, scores, limit)
+	if err != nil {
+		r.logger.Error("Failed to search songs", zap.Error(err))
+		return nil, err
+	}
+	for i := range results {
+		text := ""
+		if results[i].Text != nil {
+			text = *results[i].Text
+		}
+		results[i].Snippet = searchSnippet(query, text)
+	}
+	r.logger.Info("Songs searched in database", zap.Int("count", len(results)))
+	return results, nil
+}
+
+// rankedSongs retrieves the limit songs scoring highest, ties broken by ID, with their scores
+func (r *MySQLRepository) rankedSongs(ctx context.Context, scores map[int]float64, limit int) ([]models.SearchResult, error) {
+	ids := make([]int, 0, len(scores))
+	for id, score := range scores {
+		if score > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	results := []models.SearchResult{}
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	query := mysqlSelectSongs + " WHERE s.id IN (" + mysqlInts("$1") + ")"
+	var songs []models.Song
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &songs, query, jsonList(ids))
+	r.track(query, start, int64(len(songs)), err)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.Song, len(songs))
+	for _, song := range songs {
+		byID[song.ID] = song
+	}
+	for _, id := range ids {
+		// A song deleted since it was ranked is left out
+		if song, ok := byID[id]; ok {
+			results = append(results, models.SearchResult{Song: song, Score: scores[id]})
+		}
+	}
+	return results, nil
+}
+
+// HasSongEmbeddings reports whether song embeddings are stored, which they always are in MySQL
+func (r *MySQLRepository) HasSongEmbeddings(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// GetSongsNeedingEmbedding retrieves up to limit songs without an embedding from the model,
+// or whose content changed since it was computed
+func (r *MySQLRepository) GetSongsNeedingEmbedding(ctx context.Context, model string, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs needing embeddings", zap.String("model", model), zap.Int("limit", limit))
+	songs, err := r.songs.List(ctx, `NOT EXISTS (SELECT 1 FROM song_embeddings e
+		WHERE e.song_id = s.id AND e.model = $1 AND e.content_hash = `+mysqlSongContentHash+`)`, []any{model}, "", 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs needing embeddings", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// SaveSongEmbedding stores the embedding of a song, as a JSON array, replacing any previous one
+func (r *MySQLRepository) SaveSongEmbedding(ctx context.Context, songID int, model, contentHash string, embedding []float32) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_embeddings (song_id, model, content_hash, embedding) VALUES ($1, $2, $3, $4)
+		ON DUPLICATE KEY UPDATE model = VALUES(model), content_hash = VALUES(content_hash),
+		embedding = VALUES(embedding), updated_at = ` + mysqlNow
+	start := time.Now()
+	_, err := r.conn(ctx).ExecContext(ctx, query, songID, model, contentHash, vectorLiteral(embedding))
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to save song embedding", zap.Int("song_id", songID), zap.Error(err))
+	}
+	return err
+}
+
+// SearchSongsSemantic ranks songs embedded with the model by cosine similarity to the query embedding.
+// When keywordWeight is positive, the similarity is blended with the full-text rank of the keywords:
+// score = (1 - keywordWeight) * similarity + keywordWeight * rank, both in [0, 1].
+// MySQL has no vector functions, so the embeddings are read and compared here.
+func (r *MySQLRepository) SearchSongsSemantic(ctx context.Context, model string, embedding []float32, keywords string, keywordWeight float64, limit int) ([]models.SearchResult, error) {
+	r.logger.Debug("Searching songs semantically", zap.String("model", model), zap.Float64("keyword_weight", keywordWeight))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT e.song_id, e.embedding, s.group_name, s.song_name, COALESCE(s.text, '') AS text
+		FROM song_embeddings e JOIN songs s ON s.id = e.song_id
+		WHERE e.model = $1`
+	var rows []struct {
+		SongID    int    `db:"song_id"`
+		Embedding string `db:"embedding"`
+		Group     string `db:"group_name"`
+		Song      string `db:"song_name"`
+		Text      string `db:"text"`
+	}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &rows, query, model)
+	r.track(query, start, int64(len(rows)), err)
+	if err != nil {
+		r.logger.Error("Failed to search songs semantically", zap.Error(err))
+		return nil, err
+	}
+
+	target := make([]float64, len(embedding))
+	for i, value := range embedding {
+		target[i] = float64(value)
+	}
+	parsed := parseSearchQuery(keywords)
+	weights := []float64{titleWeight, groupWeight, textWeight}
+	scores := make(map[int]float64, len(rows))
+	for _, row := range rows {
+		var stored []float64
+		if err := json.Unmarshal([]byte(row.Embedding), &stored); err != nil {
+			r.logger.Error("Failed to decode song embedding", zap.Int("song_id", row.SongID), zap.Error(err))
+			return nil, err
+		}
+		rank := parsed.rank([][]string{searchWords(row.Song), searchWords(row.Group), searchWords(row.Text)}, weights)
+		scores[row.SongID] = (1-keywordWeight)*cosineSimilarity(stored, target) + keywordWeight*rank
+	}
+
+	results, err := r.rankedSongs(ctx, scores, limit)
+	if err != nil {
+		r.logger.Error("Failed to search songs semantically", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Semantic search finished", zap.Int("count", len(results)))
+	return results, nil
+}
+
+// HasSearchSuggestions reports whether search suggestions are available, which they always are in MySQL
+func (r *MySQLRepository) HasSearchSuggestions(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// SimilarSongNames retrieves up to limit group and song names within the trigram similarity threshold of
+// the query, closest first. MySQL has no trigram similarity, so the names are read and compared here.
+func (r *MySQLRepository) SimilarSongNames(ctx context.Context, query string, limit int) ([]models.SearchSuggestion, error) {
+	r.logger.Debug("Fetching similar song names", zap.String("query", query), zap.Int("limit", limit))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	sqlQuery := `SELECT DISTINCT group_name AS query, $1 AS source FROM songs
+		UNION ALL
+		SELECT DISTINCT song_name, $2 FROM songs`
+	var names []models.SearchSuggestion
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &names, sqlQuery, models.SuggestionGroup, models.SuggestionSong)
+	r.track(sqlQuery, start, int64(len(names)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch similar song names", zap.Error(err))
+		return nil, err
+	}
+
+	suggestions := []models.SearchSuggestion{}
+	for _, name := range names {
+		if name.Score = trigramSimilarity(name.Query, query); name.Score >= similarityThreshold {
+			suggestions = append(suggestions, name)
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Query < suggestions[j].Query
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// SimilarSearchTerms maps each word that is not a known lyric term to the most similar one within the
+// trigram similarity threshold, preferring the terms found in more songs. Words without a match are left out.
+// The suggestions carry the term and its similarity to the word.
+func (r *MySQLRepository) SimilarSearchTerms(ctx context.Context, words []string) (map[string]models.SearchSuggestion, error) {
+	r.logger.Debug("Fetching similar search terms", zap.Strings("words", words))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT term FROM search_terms ORDER BY songs DESC, term"
+	var known []string
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &known, query)
+	r.track(query, start, int64(len(known)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch similar search terms", zap.Error(err))
+		return nil, err
+	}
+
+	isKnown := make(map[string]bool, len(known))
+	for _, term := range known {
+		isKnown[term] = true
+	}
+	terms := make(map[string]models.SearchSuggestion)
+	for _, word := range words {
+		if isKnown[word] {
+			continue
+		}
+		// The terms come in order of preference, so only a closer term replaces the best so far
+		best := models.SearchSuggestion{Source: models.SuggestionLyrics}
+		for _, term := range known {
+			if score := trigramSimilarity(term, word); score >= similarityThreshold && score > best.Score {
+				best.Query, best.Score = term, score
+			}
+		}
+		if best.Query != "" {
+			terms[word] = best
+		}
+	}
+	return terms, nil
+}
+
+// RefreshSearchTerms recomputes the lyric terms offered as search suggestions: the words of at least
+// searchTermMinLength letters found in the most songs. MySQL exposes no statistics of its FULLTEXT
+// indexes to queries, so the lyrics are read and their words counted here.
+func (r *MySQLRepository) RefreshSearchTerms(ctx context.Context) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "SELECT COALESCE(text, '') FROM songs"
+	start := time.Now()
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to read lyrics", zap.Error(err))
+		return err
+	}
+	counter := make(searchTermCounter)
+	var read int64
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			rows.Close()
+			r.logger.Error("Failed to read lyrics", zap.Error(err))
+			return err
+		}
+		read++
+		counter.add(text)
+	}
+	err = rows.Err()
+	rows.Close()
+	r.track(query, start, read, err)
+	if err != nil {
+		r.logger.Error("Failed to read lyrics", zap.Error(err))
+		return err
+	}
+
+	terms, counts := counter.top()
+
+	start = time.Now()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM search_terms"); err != nil {
+		r.logger.Error("Failed to clear search terms", zap.Error(err))
+		return err
+	}
+	query = `INSERT INTO search_terms (term, songs)
+		SELECT t.term, c.songs
+		FROM JSON_TABLE($1, '$[*]' COLUMNS (n FOR ORDINALITY, term VARCHAR(255) CHARACTER SET utf8mb4 PATH '$')) AS t
+		JOIN JSON_TABLE($2, '$[*]' COLUMNS (n FOR ORDINALITY, songs INT PATH '$')) AS c ON c.n = t.n`
+	_, err = tx.ExecContext(ctx, query, jsonList(terms), jsonList(counts))
+	r.track(query, start, int64(len(terms)), err)
+	if err != nil {
+		r.logger.Error("Failed to refresh search terms", zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit search terms", zap.Error(err))
+		return err
+	}
+	return nil
+}
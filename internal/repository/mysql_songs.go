@@ -0,0 +1,835 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// mysqlFacetExpressions maps supported facet names to the SQL expression used to group songs
+// release_date is stored as text, so the year is the first four-digit run in it
+var mysqlFacetExpressions = map[string]string{
+	"year":   `REGEXP_SUBSTR(s.release_date, '[0-9]{4}')`,
+	"decade": `CONCAT(CAST(REGEXP_SUBSTR(s.release_date, '[0-9]{4}') AS SIGNED) DIV 10 * 10, 's')`,
+	"group":  "s.group_name",
+	"genre":  "fg.name",
+}
+
+// mysqlReleased is the release date of a song as YYYY-MM-DD, which orders as dates do, or NULL when it is
+// not a DD.MM.YYYY date
+const mysqlReleased = `CASE WHEN s.release_date REGEXP '^[0-9]{2}[.][0-9]{2}[.][0-9]{4}$'
+	THEN CONCAT(SUBSTRING(s.release_date, 7, 4), '-', SUBSTRING(s.release_date, 4, 2), '-', SUBSTRING(s.release_date, 1, 2)) END`
+
+// AddSong adds a new song to the database
+func (r *MySQLRepository) AddSong(ctx context.Context, group, song, releaseDate, text, link string, enrichedAt *time.Time) (int, error) {
+	r.logger.Debug("Adding song to database", zap.String("group", group), zap.String("song", song))
+	id, err := r.songs.Insert(ctx, map[string]any{
+		"group_name":   group,
+		"song_name":    song,
+		"release_date": nullIfEmpty(releaseDate),
+		"text":         nullIfEmpty(text),
+		"link":         nullIfEmpty(link),
+		"enriched_at":  enrichedAt,
+	})
+	if err != nil {
+		r.logger.Error("Failed to add song", zap.Error(err))
+		return 0, err
+	}
+	r.logger.Info("Song added to database", zap.Int("id", id))
+	return id, nil
+}
+
+// AddSongs inserts several songs in a single transaction and returns an ID or an error for each of them.
+// Every insert runs inside its own savepoint, so a failing song does not abort the others;
+// the returned error is only set when the transaction itself fails, in which case nothing is stored.
+func (r *MySQLRepository) AddSongs(ctx context.Context, songs []models.SongInput) ([]int, []error, error) {
+	r.logger.Debug("Adding songs in bulk", zap.Int("count", len(songs)))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO songs (group_name, song_name, release_date, text, link, enriched_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	ids := make([]int, len(songs))
+	errs := make([]error, len(songs))
+	inserted := 0
+	start := time.Now()
+	for i, song := range songs {
+		savepoint := fmt.Sprintf("bulk_song_%d", i)
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+			r.logger.Error("Failed to create savepoint", zap.Error(err))
+			return nil, nil, err
+		}
+		id, err := insertID(tx.ExecContext(ctx, query, song.Group, song.Song, nullIfEmpty(song.ReleaseDate), nullIfEmpty(song.Text), nullIfEmpty(song.Link), song.EnrichedAt))
+		if err != nil {
+			r.logger.Warn("Failed to add song in bulk", zap.Int("index", i), zap.Error(err))
+			errs[i] = err
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); err != nil {
+				r.logger.Error("Failed to roll back savepoint", zap.Error(err))
+				return nil, nil, err
+			}
+			continue
+		}
+		ids[i] = id
+		inserted++
+	}
+	if err := tx.Commit(); err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to commit bulk insert", zap.Error(err))
+		return nil, nil, err
+	}
+	r.track(query, start, int64(inserted), nil)
+	r.logger.Info("Songs added to database in bulk", zap.Int("inserted", inserted), zap.Int("failed", len(songs)-inserted))
+	return ids, errs, nil
+}
+
+// CopySongs inserts songs in bulk and returns their IDs in the order of songs. Either every song is
+// inserted or none is.
+func (r *MySQLRepository) CopySongs(ctx context.Context, songs []models.SongInput) ([]int, error) {
+	r.logger.Debug("Copying songs in bulk", zap.Int("count", len(songs)))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids, err := r.copySongs(ctx, tx, songs)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit bulk copy", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs copied to database in bulk", zap.Int("count", len(songs)))
+	return ids, nil
+}
+
+// copySongs writes the songs in tx one INSERT at a time. InnoDB does not promise consecutive IDs to the
+// rows of a multi-row INSERT, so each ID is read back from its own statement.
+func (r *MySQLRepository) copySongs(ctx context.Context, tx mysqlTx, songs []models.SongInput) ([]int, error) {
+	query := `INSERT INTO songs (group_name, song_name, release_date, text, link, enriched_at) VALUES ($1, $2, $3, $4, $5, $6)`
+	ids := make([]int, len(songs))
+	start := time.Now()
+	for i, song := range songs {
+		id, err := insertID(tx.ExecContext(ctx, query, song.Group, song.Song, nullIfEmpty(song.ReleaseDate), nullIfEmpty(song.Text), nullIfEmpty(song.Link), song.EnrichedAt))
+		if err != nil {
+			r.track(query, start, int64(i), err)
+			r.logger.Error("Failed to insert song", zap.Int("index", i), zap.Error(err))
+			return nil, err
+		}
+		ids[i] = id
+	}
+	r.track(query, start, int64(len(songs)), nil)
+	return ids, nil
+}
+
+// GetSongs retrieves a list of songs with filtering, sorting and pagination
+func (r *MySQLRepository) GetSongs(ctx context.Context, filter models.SongFilter, sort string, page, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs from database", zap.String("group", filter.Group), zap.String("song", filter.Song), zap.String("sort", sort))
+	where, args := mysqlSongFilterClause(filter)
+	songs, err := r.songs.List(ctx, where, args, r.orderBy(sort), page, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs fetched from database", zap.Int("count", len(songs)))
+	return songs, nil
+}
+
+// CountSongs returns the number of songs matching the GetSongs filters
+func (r *MySQLRepository) CountSongs(ctx context.Context, filter models.SongFilter) (int, error) {
+	r.logger.Debug("Counting songs in database", zap.String("group", filter.Group), zap.String("song", filter.Song))
+	where, args := mysqlSongFilterClause(filter)
+	count, err := r.songs.Count(ctx, where, args)
+	if err != nil {
+		r.logger.Error("Failed to count songs", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// mysqlSongFilterClause returns the where clause and arguments selecting the songs matched by the filter.
+// LIKE compares with the collation of the columns, which ignores case as ILIKE does.
+func mysqlSongFilterClause(filter models.SongFilter) (string, []any) {
+	where := "s.group_name LIKE $1 AND s.song_name LIKE $2"
+	if filter.Song != "" {
+		// A title in any language matches as well as the original one
+		where = "s.group_name LIKE $1 AND (s.song_name LIKE $2 OR s.id IN (SELECT t.song_id FROM song_titles t WHERE t.title LIKE $2))"
+	}
+	args := []any{"%" + filter.Group + "%", "%" + filter.Song + "%"}
+	if filter.StaleThan > 0 {
+		args = append(args, time.Now().Add(-filter.StaleThan))
+		where += fmt.Sprintf(" AND (s.enriched_at IS NULL OR s.enriched_at < $%d)", len(args))
+	}
+	for _, field := range filter.Missing {
+		if column, ok := nullableColumns[field]; ok {
+			where += " AND " + column + " IS NULL"
+		}
+	}
+	if filter.Text != "" {
+		args = append(args, filter.Text)
+		where += fmt.Sprintf(" AND s.text = $%d", len(args))
+	}
+	if len(filter.Tags) > 0 {
+		args = append(args, jsonList(filter.Tags), len(filter.Tags))
+		where += fmt.Sprintf(` AND s.id IN (SELECT st.song_id FROM song_tags st JOIN tags t ON t.id = st.tag_id
+			WHERE t.name IN (`+mysqlStrings("$%d")+`) GROUP BY st.song_id HAVING COUNT(*) = $%d)`, len(args)-1, len(args))
+	}
+	if filter.Genre != "" {
+		args = append(args, filter.Genre)
+		where += " AND s.id IN (SELECT sg.song_id FROM song_genres sg WHERE sg.genre_id IN (" +
+			fmt.Sprintf(genreSubtree, fmt.Sprintf("$%d", len(args))) + "))"
+	}
+	if filter.FavoritesOf != 0 {
+		args = append(args, filter.FavoritesOf)
+		where += fmt.Sprintf(" AND s.id IN (SELECT sf.song_id FROM song_favorites sf WHERE sf.user_id = $%d)", len(args))
+	}
+	if filter.MinRating > 0 {
+		args = append(args, filter.MinRating)
+		where += fmt.Sprintf(" AND (SELECT AVG(sr.rating) FROM song_ratings sr WHERE sr.song_id = s.id) >= $%d", len(args))
+	}
+	return where, args
+}
+
+// mysqlExcludeClause returns the condition leaving out the songs with the IDs, appending its argument
+func mysqlExcludeClause(exclude []int, args []any) (string, []any) {
+	if len(exclude) == 0 {
+		return "", args
+	}
+	args = append(args, jsonList(exclude))
+	return fmt.Sprintf(" AND s.id NOT IN ("+mysqlInts("$%d")+")", len(args)), args
+}
+
+// GetSongByID retrieves a song by its ID
+func (r *MySQLRepository) GetSongByID(ctx context.Context, id int) (models.Song, error) {
+	r.logger.Debug("Fetching song by ID", zap.Int("id", id))
+	song, err := r.songs.Get(ctx, id)
+	if err != nil {
+		r.logger.Error("Failed to fetch song", zap.Int("id", id), zap.Error(err))
+		return song, err
+	}
+	r.logger.Info("Song fetched from database", zap.Int("id", id))
+	return song, nil
+}
+
+// UpdateSong updates an existing song in the database
+func (r *MySQLRepository) UpdateSong(ctx context.Context, id int, group, song, releaseDate, text, link string) error {
+	r.logger.Debug("Updating song in database", zap.Int("id", id))
+	err := r.songs.Update(ctx, id, map[string]any{
+		"group_name":   group,
+		"song_name":    song,
+		"release_date": nullIfEmpty(releaseDate),
+		"text":         text,
+		"link":         link,
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to update song", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	r.logger.Info("Song updated in database", zap.Int("id", id))
+	return nil
+}
+
+// UpdateSongPartial updates only the fields present in the patch. Null fields are set to NULL.
+func (r *MySQLRepository) UpdateSongPartial(ctx context.Context, id int, patch models.SongPatch) error {
+	r.logger.Debug("Partially updating song in database", zap.Int("id", id))
+	values := make(map[string]any)
+	for column, field := range map[string]models.OptionalString{
+		"group_name":     patch.Group,
+		"song_name":      patch.Song,
+		"release_date":   patch.ReleaseDate,
+		"text":           patch.Text,
+		"link":           patch.Link,
+		"notes":          patch.Notes,
+		"split_strategy": patch.SplitStrategy,
+	} {
+		switch {
+		case !field.Set:
+		case field.Null:
+			values[column] = nil
+		default:
+			values[column] = field.Value
+		}
+	}
+	switch field := patch.LicensingFee; {
+	case !field.Set:
+	case field.Null:
+		values["licensing_fee"] = nil
+	default:
+		values["licensing_fee"] = field.Value
+	}
+	switch field := patch.AlbumID; {
+	case !field.Set:
+	case field.Null:
+		values["album_id"] = nil
+	default:
+		values["album_id"] = field.Value
+	}
+	if len(values) == 0 {
+		// Nothing to change, but the song must still exist
+		_, err := r.songs.Get(ctx, id)
+		return err
+	}
+
+	err := r.songs.Update(ctx, id, values)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to partially update song", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	r.logger.Info("Song partially updated in database", zap.Int("id", id), zap.Int("fields", len(values)))
+	return nil
+}
+
+// DeleteSong deletes a song from the database
+func (r *MySQLRepository) DeleteSong(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting song from database", zap.Int("id", id))
+	if err := r.songs.Delete(ctx, id); err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to delete song", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	r.logger.Info("Song deleted from database", zap.Int("id", id))
+	return nil
+}
+
+// TruncateSongs deletes every song, together with the rows attached to them. The IDs are not restarted:
+// MySQL resets AUTO_INCREMENT only with DDL, which would commit the transaction truncating may run in.
+func (r *MySQLRepository) TruncateSongs(ctx context.Context) error {
+	r.logger.Debug("Truncating table")
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "DELETE FROM songs"
+	start := time.Now()
+	_, err := r.conn(ctx).ExecContext(ctx, query)
+	r.track(query, start, 0, err)
+	if err != nil {
+		r.logger.Error("Failed to truncate table", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Table truncated in database")
+	return nil
+}
+
+// FindSongID looks up the ID of a song by its group and title, ignoring case
+func (r *MySQLRepository) FindSongID(ctx context.Context, group, song string) (int, error) {
+	r.logger.Debug("Looking up song ID", zap.String("group", group), zap.String("song", song))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT id FROM songs WHERE LOWER(group_name) = LOWER($1) AND LOWER(song_name) = LOWER($2) ORDER BY id LIMIT 1"
+	var id int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &id, query, group, song)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to look up song ID", zap.Error(err))
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetSongFacets computes value/count buckets for each requested facet using the same filters as GetSongs
+func (r *MySQLRepository) GetSongFacets(ctx context.Context, filter models.SongFilter, facets []string) (map[string][]models.FacetBucket, error) {
+	r.logger.Debug("Fetching song facets from database", zap.Strings("facets", facets))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	result := make(map[string][]models.FacetBucket, len(facets))
+	for _, facet := range facets {
+		expr, ok := mysqlFacetExpressions[facet]
+		if !ok {
+			return nil, fmt.Errorf("unsupported facet %q", facet)
+		}
+		where, args := mysqlSongFilterClause(filter)
+		query := fmt.Sprintf(`SELECT %[1]s AS value, COUNT(*) AS count FROM songs s %[3]s
+			WHERE %[2]s AND %[1]s IS NOT NULL
+			GROUP BY 1 ORDER BY count DESC, value`, expr, where, facetJoins[facet])
+		buckets := []models.FacetBucket{}
+		start := time.Now()
+		err := r.conn(ctx).SelectContext(ctx, &buckets, query, args...)
+		r.track(query, start, int64(len(buckets)), err)
+		if err != nil {
+			r.logger.Error("Failed to fetch facet", zap.String("facet", facet), zap.Error(err))
+			return nil, err
+		}
+		result[facet] = buckets
+	}
+	r.logger.Info("Song facets fetched from database", zap.Int("count", len(result)))
+	return result, nil
+}
+
+// GetSongsCreatedBetween retrieves songs added within the [from, to) interval
+func (r *MySQLRepository) GetSongsCreatedBetween(ctx context.Context, from, to time.Time) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs created in period", zap.Time("from", from), zap.Time("to", to))
+	songs, err := r.songs.Find(ctx, "s.created_at >= $1 AND s.created_at < $2", []any{from, to}, "s.created_at")
+	if err != nil {
+		r.logger.Error("Failed to fetch songs created in period", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetSongsUpdatedBetween retrieves songs created before the interval and edited within [from, to)
+func (r *MySQLRepository) GetSongsUpdatedBetween(ctx context.Context, from, to time.Time) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs updated in period", zap.Time("from", from), zap.Time("to", to))
+	songs, err := r.songs.Find(ctx, "s.updated_at >= $1 AND s.updated_at < $2 AND s.created_at < $1", []any{from, to}, "s.updated_at")
+	if err != nil {
+		r.logger.Error("Failed to fetch songs updated in period", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetSongsWithLyrics retrieves every song that has non-empty lyrics
+func (r *MySQLRepository) GetSongsWithLyrics(ctx context.Context) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs with lyrics")
+	songs, err := r.songs.Find(ctx, "COALESCE(s.text, '') <> ''", nil, "s.id")
+	if err != nil {
+		r.logger.Error("Failed to fetch songs with lyrics", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs with lyrics fetched from database", zap.Int("count", len(songs)))
+	return songs, nil
+}
+
+// GetSongsWithReleaseDate retrieves all songs matching the filters that have a release date set
+func (r *MySQLRepository) GetSongsWithReleaseDate(ctx context.Context, group, song string) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs with release date", zap.String("group", group), zap.String("song", song))
+	songs, err := r.songs.Find(ctx, "s.group_name LIKE $1 AND s.song_name LIKE $2 AND s.release_date IS NOT NULL",
+		[]any{"%" + group + "%", "%" + song + "%"}, "s.id")
+	if err != nil {
+		r.logger.Error("Failed to fetch songs with release date", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs with release date fetched from database", zap.Int("count", len(songs)))
+	return songs, nil
+}
+
+// SetLegalHold sets or releases the legal hold of a song, returning sql.ErrNoRows when it does not exist
+func (r *MySQLRepository) SetLegalHold(ctx context.Context, id int, held bool) error {
+	r.logger.Debug("Setting legal hold", zap.Int("id", id), zap.Bool("held", held))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "UPDATE songs SET legal_hold = $2 WHERE id = $1"
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, id, held)
+	var rows int64
+	if err == nil {
+		rows, err = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to set legal hold", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetLegalHolds returns the IDs of the given songs that are on legal hold. Within a transaction every one of
+// the songs, held or not, stays locked until it ends, so no hold can be set before the writes it guards.
+func (r *MySQLRepository) GetLegalHolds(ctx context.Context, ids []int) ([]int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT id, legal_hold FROM songs WHERE id IN (" + mysqlInts("$1") + ") ORDER BY id"
+	if inTransaction(ctx) {
+		query += " FOR UPDATE"
+	}
+	var songs []struct {
+		ID   int  `db:"id"`
+		Held bool `db:"legal_hold"`
+	}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &songs, query, jsonList(ids))
+	r.track(query, start, int64(len(songs)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch legal holds", zap.Error(err))
+		return nil, err
+	}
+	held := []int{}
+	for _, song := range songs {
+		if song.Held {
+			held = append(held, song.ID)
+		}
+	}
+	return held, nil
+}
+
+// CountLegalHolds returns the number of songs on legal hold. Within a transaction the count is a locking
+// read, whose next-key locks keep holds from being set until it ends, so none can be set before the
+// truncation the count guards.
+func (r *MySQLRepository) CountLegalHolds(ctx context.Context) (int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT COUNT(*) FROM songs WHERE legal_hold"
+	if inTransaction(ctx) {
+		query += " FOR UPDATE"
+	}
+	var count int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &count, query)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to count legal holds", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// IncrementSongViews adds the buffered view counts to the stored counters in a single transaction
+func (r *MySQLRepository) IncrementSongViews(ctx context.Context, counts map[int]int64) error {
+	r.logger.Debug("Flushing song views", zap.Int("songs", len(counts)))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	// The counts are passed as a JSON array of song IDs and views
+	type songViews struct {
+		SongID int   `json:"song_id"`
+		Views  int64 `json:"views"`
+	}
+	rows := make([]songViews, 0, len(counts))
+	for id, views := range counts {
+		rows = append(rows, songViews{SongID: id, Views: views})
+	}
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	counted := "JSON_TABLE($1, '$[*]' COLUMNS (song_id BIGINT PATH '$.song_id', views BIGINT PATH '$.views')) AS c"
+	var rowsAffected int64
+	for _, query := range []string{
+		`INSERT INTO song_view_days (song_id, day, views)
+		SELECT s.id, CURRENT_DATE, c.views FROM ` + counted + ` JOIN songs s ON s.id = c.song_id
+		ON DUPLICATE KEY UPDATE views = song_view_days.views + VALUES(views)`,
+		`INSERT INTO song_views (song_id, views)
+		SELECT s.id, c.views FROM ` + counted + ` JOIN songs s ON s.id = c.song_id
+		ON DUPLICATE KEY UPDATE views = song_views.views + VALUES(views)`,
+	} {
+		start := time.Now()
+		result, err := tx.ExecContext(ctx, query, string(data))
+		if err != nil {
+			r.track(query, start, 0, err)
+			r.logger.Error("Failed to flush song views", zap.Error(err))
+			return err
+		}
+		rowsAffected, _ = result.RowsAffected()
+		r.track(query, start, rowsAffected, nil)
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit song views", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Song views flushed to database", zap.Int64("songs", rowsAffected))
+	return nil
+}
+
+// RefreshTrending recomputes the materialized trending scores from the daily view buckets.
+// Each day's views are divided by (age in hours + 2) raised to gravity, so recent activity dominates.
+func (r *MySQLRepository) RefreshTrending(ctx context.Context, gravity float64, windowDays int) error {
+	r.logger.Debug("Refreshing trending scores", zap.Float64("gravity", gravity), zap.Int("window_days", windowDays))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	start := time.Now()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM song_trending"); err != nil {
+		r.track("DELETE FROM song_trending", start, 0, err)
+		r.logger.Error("Failed to clear trending scores", zap.Error(err))
+		return err
+	}
+	query := `INSERT INTO song_trending (song_id, score, computed_at)
+		SELECT song_id, SUM(views / POWER(TIMESTAMPDIFF(SECOND, day, ` + mysqlNow + `) / 3600 + 2, $1)), ` + mysqlNow + `
+		FROM song_view_days WHERE day > CURRENT_DATE - INTERVAL $2 DAY
+		GROUP BY song_id`
+	result, err := tx.ExecContext(ctx, query, gravity, windowDays)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to compute trending scores", zap.Error(err))
+		return err
+	}
+	rowsAffected, _ := result.RowsAffected()
+	r.track(query, start, rowsAffected, nil)
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit trending scores", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Trending scores refreshed", zap.Int64("songs", rowsAffected))
+	return nil
+}
+
+// GetTrendingSongs retrieves the songs with the highest materialized trending score
+func (r *MySQLRepository) GetTrendingSongs(ctx context.Context, limit int) ([]models.TrendingSong, error) {
+	r.logger.Debug("Fetching trending songs", zap.Int("limit", limit))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT ` + mysqlSongColumns + `, COALESCE(v.views, 0) AS views, t.score FROM songs s
+		LEFT JOIN song_views v ON v.song_id = s.id
+		JOIN song_trending t ON t.song_id = s.id
+		ORDER BY t.score DESC, s.id LIMIT $1`
+	songs := []models.TrendingSong{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &songs, query, limit)
+	r.track(query, start, int64(len(songs)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch trending songs", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Trending songs fetched from database", zap.Int("count", len(songs)))
+	return songs, nil
+}
+
+// GetGroupStats aggregates the songs of a group, returning sql.ErrNoRows when it has none
+func (r *MySQLRepository) GetGroupStats(ctx context.Context, group string) (models.GroupStats, error) {
+	r.logger.Debug("Fetching group stats", zap.String("group", group))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	// Release dates are stored as DD.MM.YYYY text, so they are compared as YYYY-MM-DD and turned back
+	query := `SELECT group_name, songs,
+		CONCAT(SUBSTRING(earliest, 9, 2), '.', SUBSTRING(earliest, 6, 2), '.', SUBSTRING(earliest, 1, 4)) AS earliest_release,
+		CONCAT(SUBSTRING(latest, 9, 2), '.', SUBSTRING(latest, 6, 2), '.', SUBSTRING(latest, 1, 4)) AS latest_release,
+		total_views, NULL AS average_rating
+		FROM (SELECT MIN(s.group_name) AS group_name, COUNT(*) AS songs,
+			MIN(` + mysqlReleased + `) AS earliest, MAX(` + mysqlReleased + `) AS latest,
+			COALESCE(SUM(v.views), 0) AS total_views
+			FROM songs s
+			LEFT JOIN song_views v ON v.song_id = s.id
+			WHERE ` + groupMatch + `
+			HAVING COUNT(*) > 0) AS stats`
+	var stats models.GroupStats
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &stats, query, group)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to fetch group stats", zap.String("group", group), zap.Error(err))
+		}
+		return stats, err
+	}
+	return stats, nil
+}
+
+// GetMostViewedGroupSongs retrieves up to limit songs of a group, most viewed first
+func (r *MySQLRepository) GetMostViewedGroupSongs(ctx context.Context, group string, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching most viewed group songs", zap.String("group", group), zap.Int("limit", limit))
+	songs, err := r.songs.List(ctx, groupMatch, []any{group}, sortOrders["views"], 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch most viewed group songs", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetSongsNeedingListeners retrieves up to limit songs whose listener count was never fetched
+// or was fetched longer than maxAge ago, never-fetched songs first
+func (r *MySQLRepository) GetSongsNeedingListeners(ctx context.Context, maxAge time.Duration, exclude []int, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs needing listener counts", zap.Duration("max_age", maxAge), zap.Int("limit", limit))
+	where := "NOT EXISTS (SELECT 1 FROM song_listeners l WHERE l.song_id = s.id AND l.fetched_at >= $1)"
+	excluded, args := mysqlExcludeClause(exclude, []any{time.Now().Add(-maxAge)})
+	// MySQL sorts NULL first
+	orderBy := "(SELECT l.fetched_at FROM song_listeners l WHERE l.song_id = s.id), s.id"
+	songs, err := r.songs.List(ctx, where+excluded, args, orderBy, 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs needing listener counts", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// SaveListenerCount caches the listener count fetched for the song
+func (r *MySQLRepository) SaveListenerCount(ctx context.Context, id int, listeners int64) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `
+		INSERT INTO song_listeners (song_id, listeners, fetched_at) VALUES ($1, $2, ` + mysqlNow + `)
+		ON DUPLICATE KEY UPDATE listeners = VALUES(listeners), fetched_at = VALUES(fetched_at)`
+	start := time.Now()
+	_, err := r.conn(ctx).ExecContext(ctx, query, id, listeners)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to save listener count", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetStalestSongs retrieves up to limit songs not enriched within staleAfter, never-enriched songs first
+// and then the longest-unrefreshed ones. Songs whose IDs are in exclude and songs on legal hold are skipped.
+func (r *MySQLRepository) GetStalestSongs(ctx context.Context, staleAfter time.Duration, exclude []int, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching stalest songs", zap.Duration("stale_after", staleAfter), zap.Int("limit", limit))
+	where, args := mysqlSongFilterClause(models.SongFilter{StaleThan: staleAfter})
+	excluded, args := mysqlExcludeClause(exclude, args)
+	songs, err := r.songs.List(ctx, where+" AND NOT s.legal_hold"+excluded, args, "s.enriched_at, s.id", 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch stalest songs", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetSongsAfter retrieves up to limit songs matching the filter with IDs above afterID, in ID order,
+// so that callers can walk every match while updating the songs they have seen
+func (r *MySQLRepository) GetSongsAfter(ctx context.Context, filter models.SongFilter, afterID, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs after ID", zap.Int("after_id", afterID), zap.Int("limit", limit))
+	where, args := mysqlSongFilterClause(filter)
+	args = append(args, afterID)
+	where += fmt.Sprintf(" AND s.id > $%d", len(args))
+	songs, err := r.songs.List(ctx, where, args, "s.id", 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs after ID", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// RefreshSongData stores data freshly fetched from the external API and marks the song as enriched now,
+// settling its enrichment status. Empty values leave the stored field unchanged.
+func (r *MySQLRepository) RefreshSongData(ctx context.Context, id int, releaseDate, text, link string) error {
+	r.logger.Debug("Refreshing song data", zap.Int("id", id))
+	query := `UPDATE songs SET release_date = COALESCE(NULLIF($2, ''), release_date),
+		text = COALESCE(NULLIF($3, ''), text), link = COALESCE(NULLIF($4, ''), link),
+		enriched_at = ` + mysqlNow + `, enrichment_status = 'complete', enrichment_error = NULL WHERE id = $1`
+	return r.updateEnrichment(ctx, id, query, id, releaseDate, text, link)
+}
+
+// AddPendingSong inserts a song whose details are still to be fetched by the enrichment workers
+func (r *MySQLRepository) AddPendingSong(ctx context.Context, group, song string) (int, error) {
+	r.logger.Debug("Adding pending song to database", zap.String("group", group), zap.String("song", song))
+	id, err := r.songs.Insert(ctx, map[string]any{
+		"group_name":        group,
+		"song_name":         song,
+		"enrichment_status": models.EnrichmentPending,
+	})
+	if err != nil {
+		r.logger.Error("Failed to add pending song", zap.Error(err))
+		return 0, err
+	}
+	r.logger.Info("Pending song added to database", zap.Int("id", id))
+	return id, nil
+}
+
+// CompleteEnrichment stores the fetched details of a pending song and marks it complete. Fields edited
+// while the song was pending keep their value.
+func (r *MySQLRepository) CompleteEnrichment(ctx context.Context, id int, releaseDate, text, link string, enrichedAt *time.Time) error {
+	r.logger.Debug("Completing song enrichment", zap.Int("id", id))
+	query := `UPDATE songs SET release_date = COALESCE(release_date, NULLIF($2, '')),
+		text = COALESCE(text, NULLIF($3, '')), link = COALESCE(link, NULLIF($4, '')), enriched_at = $5,
+		enrichment_status = $6, enrichment_error = NULL WHERE id = $1`
+	return r.updateEnrichment(ctx, id, query, id, releaseDate, text, link, enrichedAt, models.EnrichmentComplete)
+}
+
+// FailEnrichment marks a pending song failed with the reason
+func (r *MySQLRepository) FailEnrichment(ctx context.Context, id int, reason string) error {
+	r.logger.Debug("Failing song enrichment", zap.Int("id", id), zap.String("reason", reason))
+	query := `UPDATE songs SET enrichment_status = $2, enrichment_error = $3 WHERE id = $1`
+	return r.updateEnrichment(ctx, id, query, id, models.EnrichmentFailed, reason)
+}
+
+// updateEnrichment runs an enrichment update, returning sql.ErrNoRows when the song was deleted
+func (r *MySQLRepository) updateEnrichment(ctx context.Context, id int, query string, args ...any) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to update song enrichment", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetEnrichmentStatus retrieves the enrichment status of a song
+func (r *MySQLRepository) GetEnrichmentStatus(ctx context.Context, id int) (models.EnrichmentStatus, error) {
+	r.logger.Debug("Fetching enrichment status", zap.Int("id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT id, enrichment_status, enrichment_error, enriched_at, updated_at FROM songs WHERE id = $1`
+	var status models.EnrichmentStatus
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &status, query, id)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch enrichment status", zap.Int("id", id), zap.Error(err))
+	}
+	return status, err
+}
+
+// GetPendingEnrichments retrieves up to limit songs waiting for enrichment, oldest first
+func (r *MySQLRepository) GetPendingEnrichments(ctx context.Context, exclude []int, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs pending enrichment", zap.Int("limit", limit))
+	excluded, args := mysqlExcludeClause(exclude, []any{models.EnrichmentPending})
+	songs, err := r.songs.List(ctx, "s.enrichment_status = $1"+excluded, args, "s.id", 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs pending enrichment", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetSongsNeedingMetadata retrieves up to limit songs whose track metadata was never synced, skipping songs on
+// legal hold
+func (r *MySQLRepository) GetSongsNeedingMetadata(ctx context.Context, exclude []int, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs needing track metadata", zap.Int("limit", limit))
+	excluded, args := mysqlExcludeClause(exclude, nil)
+	songs, err := r.songs.List(ctx, "s.metadata_synced_at IS NULL AND NOT s.legal_hold"+excluded, args, "s.id", 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs needing track metadata", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// SaveSongMetadata stores the track metadata of the song and marks it synced. Unknown fields keep
+// their current value, so a lookup that found nothing only marks the song.
+func (r *MySQLRepository) SaveSongMetadata(ctx context.Context, id int, metadata models.TrackMetadata) error {
+	r.logger.Debug("Saving track metadata", zap.Int("id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `
+		UPDATE songs SET album = COALESCE($2, album), duration_ms = COALESCE($3, duration_ms),
+			isrc = COALESCE($4, isrc), artwork_url = COALESCE($5, artwork_url), metadata_synced_at = ` + mysqlNow + `
+		WHERE id = $1`
+	start := time.Now()
+	_, err := r.conn(ctx).ExecContext(ctx, query, id, metadata.Album, metadata.DurationMs, metadata.ISRC, metadata.ArtworkURL)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to save track metadata", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMySQLStatement(t *testing.T) {
+	query, args := mysqlStatement("SELECT $2, $1 FROM JSON_TABLE($2, '$[*]' COLUMNS (value INT PATH '$')) AS list",
+		[]any{1, json.RawMessage(`[2]`)})
+	assert.Equal(t, "SELECT ?, ? FROM JSON_TABLE(?, '$[*]' COLUMNS (value INT PATH '$')) AS list", query)
+	assert.Equal(t, []any{"[2]", 1, "[2]"}, args)
+
+	_, args = mysqlStatement("UPDATE t SET a = $1, b = $2", []any{[]byte(nil), json.RawMessage(nil)})
+	assert.Equal(t, []any{nil, nil}, args)
+}
+
+func TestMySQLBooleanQuery(t *testing.T) {
+	assert.Equal(t, "+((+love)) +((+night +time) (+day))", mysqlBooleanQuery(parseSearchQuery(`love -war "night time" OR day`)))
+	// Stopwords and short words are not indexed, so a clause resting on them is left to the ranking
+	assert.Equal(t, "+((+love))", mysqlBooleanQuery(parseSearchQuery(`love "the" OR day`)))
+	assert.Equal(t, "+((+love))", mysqlBooleanQuery(parseSearchQuery(`love of`)))
+	assert.Empty(t, mysqlBooleanQuery(parseSearchQuery(`it is`)))
+}
+
+func TestMySQLRetryable(t *testing.T) {
+	assert.True(t, isRetryable(&mysql.MySQLError{Number: 1213}))
+	assert.True(t, isRetryable(&mysql.MySQLError{Number: 1205}))
+	assert.False(t, isRetryable(&mysql.MySQLError{Number: 1062}))
+	assert.True(t, mysqlDuplicateKey(&mysql.MySQLError{Number: 1062}))
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// GetSongOverride retrieves the user's override of the song, returning sql.ErrNoRows when there is none
+func (r *PostgresRepository) GetSongOverride(ctx context.Context, userID, songID int) (models.SongOverride, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT song_id, text, created_at, updated_at FROM song_overrides WHERE user_id = $1 AND song_id = $2"
+	var override models.SongOverride
+	start := time.Now()
+	err := r.db.GetContext(ctx, &override, query, userID, songID)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch song override", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return override, err
+}
+
+// GetSongOverrides retrieves the user's overrides of any of the songs
+func (r *PostgresRepository) GetSongOverrides(ctx context.Context, userID int, songIDs []int) ([]models.SongOverride, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT song_id, text, created_at, updated_at FROM song_overrides WHERE user_id = $1 AND song_id = ANY($2)"
+	overrides := []models.SongOverride{}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &overrides, query, userID, pq.Array(songIDs))
+	r.track(query, start, int64(len(overrides)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch song overrides", zap.Int("user_id", userID), zap.Error(err))
+	}
+	return overrides, err
+}
+
+// SaveSongOverride creates or replaces the user's override of the song, returning sql.ErrNoRows when the
+// song does not exist
+func (r *PostgresRepository) SaveSongOverride(ctx context.Context, userID, songID int, text string) (models.SongOverride, error) {
+	r.logger.Debug("Saving song override", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_overrides (user_id, song_id, text)
+		SELECT $1, id, $3 FROM songs WHERE id = $2
+		ON CONFLICT (user_id, song_id) DO UPDATE SET text = EXCLUDED.text, updated_at = NOW()
+		RETURNING song_id, text, created_at, updated_at`
+	var override models.SongOverride
+	start := time.Now()
+	err := r.db.GetContext(ctx, &override, query, userID, songID, text)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to save song override", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return override, err
+}
+
+// DeleteSongOverride discards the user's override of the song, returning sql.ErrNoRows when there is none
+func (r *PostgresRepository) DeleteSongOverride(ctx context.Context, userID, songID int) error {
+	r.logger.Debug("Deleting song override", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "DELETE FROM song_overrides WHERE user_id = $1 AND song_id = $2"
+	start := time.Now()
+	result, err := r.db.ExecContext(ctx, query, userID, songID)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to delete song override", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	r.track(query, start, rows, nil)
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// Popularity sources
+const (
+	PopularityInternal = "internal"
+	PopularityExternal = "external"
+	PopularityBlend    = "blend"
+)
+
+// SortPopularity is the sort key ordering songs by the configured PopularityProvider, most popular first
+const SortPopularity = "popularity"
+
+// PopularityProvider scores songs for sort=popularity
+type PopularityProvider interface {
+	// Name identifies the source
+	Name() string
+	// Score returns an SQL expression over the songs alias s and the view counters alias v;
+	// higher scores are more popular
+	Score() string
+}
+
+// InternalPopularity scores songs by the plays counted by this service
+type InternalPopularity struct{}
+
+// Name returns PopularityInternal
+func (InternalPopularity) Name() string { return PopularityInternal }
+
+// Score returns the view counter of the song
+func (InternalPopularity) Score() string { return "COALESCE(v.views, 0)" }
+
+// ExternalPopularity scores songs by the listener counts fetched from the external provider and cached
+// in song_listeners. Songs whose count has not been fetched yet score 0.
+type ExternalPopularity struct{}
+
+// Name returns PopularityExternal
+func (ExternalPopularity) Name() string { return PopularityExternal }
+
+// Score returns the cached listener count of the song
+func (ExternalPopularity) Score() string {
+	return "COALESCE((SELECT l.listeners FROM song_listeners l WHERE l.song_id = s.id), 0)"
+}
+
+// BlendedPopularity scores songs by a weighted sum of other providers. Each score is log-scaled first,
+// so sources counting on different scales can be weighted against each other.
+type BlendedPopularity struct {
+	Weights map[PopularityProvider]float64
+}
+
+// Name returns PopularityBlend
+func (BlendedPopularity) Name() string { return PopularityBlend }
+
+// Score returns the weighted sum of the log-scaled scores
+func (b BlendedPopularity) Score() string {
+	terms := make([]string, 0, len(b.Weights))
+	for provider, weight := range b.Weights {
+		terms = append(terms, fmt.Sprintf("%g * LN(1 + %s)", weight, provider.Score()))
+	}
+	if len(terms) == 0 {
+		return "0"
+	}
+	// Map iteration order is random; a stable expression keeps query logs comparable
+	sort.Strings(terms)
+	return strings.Join(terms, " + ")
+}
+
+// ConfigurePopularity sets the provider scoring songs for sort=popularity; the default counts internal plays
+func (r *PostgresRepository) ConfigurePopularity(provider PopularityProvider) {
+	r.popularity = provider
+}
+
+// orderBy returns the ORDER BY clause of the sort key, ordering by ID for unsupported keys
+func (r *PostgresRepository) orderBy(sort string) string {
+	if sort == SortPopularity {
+		return r.popularity.Score() + " DESC, s.id"
+	}
+	if orderBy, ok := sortOrders[sort]; ok {
+		return orderBy
+	}
+	return sortOrders["id"]
+}
+
+// GetSongsNeedingListeners retrieves up to limit songs whose listener count was never fetched
+// or was fetched longer than maxAge ago, never-fetched songs first
+func (r *PostgresRepository) GetSongsNeedingListeners(ctx context.Context, maxAge time.Duration, exclude []int, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs needing listener counts", zap.Duration("max_age", maxAge), zap.Int("limit", limit))
+	where := "NOT EXISTS (SELECT 1 FROM song_listeners l WHERE l.song_id = s.id AND l.fetched_at >= NOW() - make_interval(secs => $1))"
+	args := []any{maxAge.Seconds()}
+	if len(exclude) > 0 {
+		args = append(args, pq.Array(exclude))
+		where += fmt.Sprintf(" AND s.id <> ALL($%d)", len(args))
+	}
+	orderBy := "(SELECT l.fetched_at FROM song_listeners l WHERE l.song_id = s.id) NULLS FIRST, s.id"
+	songs, err := r.songs.List(ctx, where, args, orderBy, 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs needing listener counts", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// SaveListenerCount caches the listener count fetched for the song
+func (r *PostgresRepository) SaveListenerCount(ctx context.Context, id int, listeners int64) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `
+		INSERT INTO song_listeners (song_id, listeners, fetched_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (song_id) DO UPDATE SET listeners = EXCLUDED.listeners, fetched_at = EXCLUDED.fetched_at`
+	start := time.Now()
+	_, err := r.db.ExecContext(ctx, query, id, listeners)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to save listener count", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -1,37 +1,147 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
-	_ "fmt"
+	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
+	"music-library/internal/metrics"
 	"music-library/internal/models"
 )
 
+// songColumns lists the song columns read into models.Song
+const songColumns = `s.id, s.group_name, s.song_name, s.release_date, s.text, s.link, s.created_at, s.updated_at, s.enriched_at,
+	s.enrichment_status, s.notes, s.licensing_fee, s.album, s.duration_ms, s.isrc, s.artwork_url, s.legal_hold,
+	s.split_strategy, s.album_id, s.artist_id,
+	(SELECT AVG(sr.rating)::float8 FROM song_ratings sr WHERE sr.song_id = s.id) AS average_rating,
+	(SELECT COUNT(*) FROM song_ratings sr WHERE sr.song_id = s.id) AS rating_count`
+
+// selectSongs selects song rows together with their view counters
+const selectSongs = `SELECT ` + songColumns + `, COALESCE(v.views, 0) AS views FROM songs s LEFT JOIN song_views v ON v.song_id = s.id`
+
+// sortOrders maps supported sort keys to ORDER BY clauses for song listings
+var sortOrders = map[string]string{
+	"id":    "s.id",
+	"views": "views DESC, s.id",
+	// Unrated songs come last
+	"rating": "average_rating DESC NULLS LAST, rating_count DESC, s.id",
+	// Resolved by the configured PopularityProvider
+	SortPopularity: "",
+}
+
+// nullableColumns maps the optional song fields to their columns, which are NULL when the value is unknown
+var nullableColumns = map[string]string{
+	"release_date": "s.release_date",
+	"text":         "s.text",
+	"link":         "s.link",
+}
+
+// IsNullableField reports whether songs can be filtered by the given field being unknown
+func IsNullableField(field string) bool {
+	_, ok := nullableColumns[field]
+	return ok
+}
+
+// IsSortSupported reports whether songs can be listed in the given order
+func IsSortSupported(sort string) bool {
+	_, ok := sortOrders[sort]
+	return ok
+}
+
 // PostgresRepository handles database operations for the music library
 type PostgresRepository struct {
-	db     *sqlx.DB
-	logger *zap.Logger
+	db       *sqlx.DB
+	logger   *zap.Logger
+	queryLog *QueryLog
+	songs    *Table[models.Song]
+
+	suggestions *Table[models.ClassificationSuggestion]
+	users       *Table[models.User]
+	albums      *Table[models.Album]
+	artists     *Table[models.Artist]
+	genres      *Table[models.Genre]
+	trash       *Table[models.TrashedSong]
+
+	popularity PopularityProvider
+	// statementTimeout bounds each statement run outside a transaction
+	statementTimeout time.Duration
 }
 
 // NewPostgresRepository creates a new instance of PostgresRepository
 func NewPostgresRepository(db *sqlx.DB, logger *zap.Logger) *PostgresRepository {
+	queryLog := NewQueryLog(defaultQueryLogSize)
 	return &PostgresRepository{
-		db:     db,
-		logger: logger,
+		db:       db,
+		logger:   logger,
+		queryLog: queryLog,
+		songs:    NewTable[models.Song](db, logger, queryLog, "songs", selectSongs, "s.id"),
+
+		suggestions: NewTable[models.ClassificationSuggestion](db, logger, queryLog, "classification_suggestions", selectSuggestions, "c.id"),
+		users:       NewTable[models.User](db, logger, queryLog, "users", selectUsers, "id"),
+		albums:      NewTable[models.Album](db, logger, queryLog, "albums", selectAlbums, "id"),
+		artists:     NewTable[models.Artist](db, logger, queryLog, "artists", selectArtists, "id"),
+		genres:      NewTable[models.Genre](db, logger, queryLog, "genres", selectGenres, "id"),
+		trash:       NewTable[models.TrashedSong](db, logger, queryLog, "trashed_songs", selectTrashedSongs, "id"),
+
+		popularity: InternalPopularity{},
 	}
 }
 
+// nullIfEmpty stores an empty optional field as NULL, so unknown values are not mistaken for data
+func nullIfEmpty(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// ConfigureStatementTimeout bounds every statement run outside a transaction, including those of the
+// entity tables; zero leaves statements bounded only by the caller's context
+func (r *PostgresRepository) ConfigureStatementTimeout(timeout time.Duration) {
+	r.statementTimeout = timeout
+	r.songs.timeout = timeout
+	r.suggestions.timeout = timeout
+	r.users.timeout = timeout
+	r.albums.timeout = timeout
+	r.artists.timeout = timeout
+	r.genres.timeout = timeout
+	r.trash.timeout = timeout
+}
+
+// statementContext bounds a statement by the configured statement timeout
+func (r *PostgresRepository) statementContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withStatementTimeout(ctx, r.statementTimeout)
+}
+
+// Ping checks that the database is reachable
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// RecentQueries returns the most recently executed queries, newest first
+func (r *PostgresRepository) RecentQueries() []QueryLogEntry {
+	return r.queryLog.Entries()
+}
+
+// track records a finished query in the query log
+func (r *PostgresRepository) track(query string, start time.Time, rows int64, err error) {
+	duration := time.Since(start)
+	r.queryLog.Record(query, duration, rows, err)
+	metrics.ObserveQuery(query, duration, err)
+}
+
 // AddSong adds a new song to the database
-func (r *PostgresRepository) AddSong(group, song, releaseDate, text, link string) (int, error) {
+func (r *PostgresRepository) AddSong(ctx context.Context, group, song, releaseDate, text, link string, enrichedAt *time.Time) (int, error) {
 	r.logger.Debug("Adding song to database", zap.String("group", group), zap.String("song", song))
-	query := `
-		INSERT INTO songs (group_name, song_name, release_date, text, link, created_at, updated_at) 
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) 
-		RETURNING id`
-	var id int
-	err := r.db.QueryRow(query, group, song, releaseDate, text, link).Scan(&id)
+	id, err := r.songs.Insert(ctx, map[string]any{
+		"group_name":   group,
+		"song_name":    song,
+		"release_date": nullIfEmpty(releaseDate),
+		"text":         nullIfEmpty(text),
+		"link":         nullIfEmpty(link),
+		"enriched_at":  enrichedAt,
+	})
 	if err != nil {
 		r.logger.Error("Failed to add song", zap.Error(err))
 		return 0, err
@@ -40,39 +150,77 @@ func (r *PostgresRepository) AddSong(group, song, releaseDate, text, link string
 	return id, nil
 }
 
-// GetSongs retrieves a list of songs with filtering and pagination
-func (r *PostgresRepository) GetSongs(group, song string, page, limit int) ([]models.Song, error) {
-	r.logger.Debug("Fetching songs from database", zap.String("group", group), zap.String("song", song))
-	offset := (page - 1) * limit
-	query := `SELECT * FROM songs WHERE group_name ILIKE $1 AND song_name ILIKE $2 
-		ORDER BY id LIMIT $3 OFFSET $4`
-	rows, err := r.db.Queryx(query, "%"+group+"%", "%"+song+"%", limit, offset)
+// GetSongs retrieves a list of songs with filtering, sorting and pagination
+func (r *PostgresRepository) GetSongs(ctx context.Context, filter models.SongFilter, sort string, page, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs from database", zap.String("group", filter.Group), zap.String("song", filter.Song), zap.String("sort", sort))
+	where, args := songFilterClause(filter)
+	songs, err := r.songs.List(ctx, where, args, r.orderBy(sort), page, limit)
 	if err != nil {
 		r.logger.Error("Failed to fetch songs", zap.Error(err))
 		return nil, err
 	}
-	defer rows.Close()
+	r.logger.Info("Songs fetched from database", zap.Int("count", len(songs)))
+	return songs, nil
+}
 
-	var songs []models.Song
-	for rows.Next() {
-		var s models.Song
-		err := rows.StructScan(&s)
-		if err != nil {
-			r.logger.Error("Failed to scan song", zap.Error(err))
-			return nil, err
-		}
-		songs = append(songs, s)
+// CountSongs returns the number of songs matching the GetSongs filters
+func (r *PostgresRepository) CountSongs(ctx context.Context, filter models.SongFilter) (int, error) {
+	r.logger.Debug("Counting songs in database", zap.String("group", filter.Group), zap.String("song", filter.Song))
+	where, args := songFilterClause(filter)
+	count, err := r.songs.Count(ctx, where, args)
+	if err != nil {
+		r.logger.Error("Failed to count songs", zap.Error(err))
+		return 0, err
 	}
+	return count, nil
+}
 
-	r.logger.Info("Songs fetched from database", zap.Int("count", len(songs)))
-	return songs, nil
+// songFilterClause returns the where clause and arguments selecting the songs matched by the filter
+func songFilterClause(filter models.SongFilter) (string, []any) {
+	where := "s.group_name ILIKE $1 AND s.song_name ILIKE $2"
+	if filter.Song != "" {
+		// A title in any language matches as well as the original one
+		where = "s.group_name ILIKE $1 AND (s.song_name ILIKE $2 OR s.id IN (SELECT t.song_id FROM song_titles t WHERE t.title ILIKE $2))"
+	}
+	args := []any{"%" + filter.Group + "%", "%" + filter.Song + "%"}
+	if filter.StaleThan > 0 {
+		args = append(args, filter.StaleThan.Seconds())
+		where += fmt.Sprintf(" AND (s.enriched_at IS NULL OR s.enriched_at < NOW() - make_interval(secs => $%d))", len(args))
+	}
+	for _, field := range filter.Missing {
+		if column, ok := nullableColumns[field]; ok {
+			where += " AND " + column + " IS NULL"
+		}
+	}
+	if filter.Text != "" {
+		args = append(args, filter.Text)
+		where += fmt.Sprintf(" AND s.text = $%d", len(args))
+	}
+	if len(filter.Tags) > 0 {
+		args = append(args, pq.Array(filter.Tags), len(filter.Tags))
+		where += fmt.Sprintf(` AND s.id IN (SELECT st.song_id FROM song_tags st JOIN tags t ON t.id = st.tag_id
+			WHERE t.name = ANY($%d) GROUP BY st.song_id HAVING COUNT(*) = $%d)`, len(args)-1, len(args))
+	}
+	if filter.Genre != "" {
+		args = append(args, filter.Genre)
+		where += " AND s.id IN (SELECT sg.song_id FROM song_genres sg WHERE sg.genre_id IN (" +
+			fmt.Sprintf(genreSubtree, fmt.Sprintf("$%d", len(args))) + "))"
+	}
+	if filter.FavoritesOf != 0 {
+		args = append(args, filter.FavoritesOf)
+		where += fmt.Sprintf(" AND s.id IN (SELECT sf.song_id FROM song_favorites sf WHERE sf.user_id = $%d)", len(args))
+	}
+	if filter.MinRating > 0 {
+		args = append(args, filter.MinRating)
+		where += fmt.Sprintf(" AND (SELECT AVG(sr.rating) FROM song_ratings sr WHERE sr.song_id = s.id) >= $%d", len(args))
+	}
+	return where, args
 }
 
 // GetSongByID retrieves a song by its ID
-func (r *PostgresRepository) GetSongByID(id int) (models.Song, error) {
+func (r *PostgresRepository) GetSongByID(ctx context.Context, id int) (models.Song, error) {
 	r.logger.Debug("Fetching song by ID", zap.Int("id", id))
-	var song models.Song
-	err := r.db.Get(&song, "SELECT * FROM songs WHERE id = $1", id)
+	song, err := r.songs.Get(ctx, id)
 	if err != nil {
 		r.logger.Error("Failed to fetch song", zap.Int("id", id), zap.Error(err))
 		return song, err
@@ -82,52 +230,99 @@ func (r *PostgresRepository) GetSongByID(id int) (models.Song, error) {
 }
 
 // UpdateSong updates an existing song in the database
-func (r *PostgresRepository) UpdateSong(id int, group, song, releaseDate, text, link string) error {
+func (r *PostgresRepository) UpdateSong(ctx context.Context, id int, group, song, releaseDate, text, link string) error {
 	r.logger.Debug("Updating song in database", zap.Int("id", id))
-	query := `UPDATE songs SET group_name = $2, song_name = $3, release_date = $4, text = $5, link = $6, updated_at = NOW() 
-		WHERE id = $1`
-	result, err := r.db.Exec(query, id, group, song, releaseDate, text, link)
-	if err != nil {
-		r.logger.Error("Failed to update song", zap.Int("id", id), zap.Error(err))
-		return err
-	}
-	rowsAffected, err := result.RowsAffected()
+	err := r.songs.Update(ctx, id, map[string]any{
+		"group_name":   group,
+		"song_name":    song,
+		"release_date": nullIfEmpty(releaseDate),
+		"text":         text,
+		"link":         link,
+	})
 	if err != nil {
-		r.logger.Error("Failed to check rows affected", zap.Int("id", id), zap.Error(err))
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to update song", zap.Int("id", id), zap.Error(err))
+		}
 		return err
 	}
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
 	r.logger.Info("Song updated in database", zap.Int("id", id))
 	return nil
 }
 
-// DeleteSong deletes a song from the database
-func (r *PostgresRepository) DeleteSong(id int) error {
-	r.logger.Debug("Deleting song from database", zap.Int("id", id))
-	query := "DELETE FROM songs WHERE id = $1"
-	result, err := r.db.Exec(query, id)
-	if err != nil {
-		r.logger.Error("Failed to delete song", zap.Int("id", id), zap.Error(err))
+// UpdateSongPartial updates only the fields present in the patch. Null fields are set to NULL.
+func (r *PostgresRepository) UpdateSongPartial(ctx context.Context, id int, patch models.SongPatch) error {
+	r.logger.Debug("Partially updating song in database", zap.Int("id", id))
+	values := make(map[string]any)
+	for column, field := range map[string]models.OptionalString{
+		"group_name":     patch.Group,
+		"song_name":      patch.Song,
+		"release_date":   patch.ReleaseDate,
+		"text":           patch.Text,
+		"link":           patch.Link,
+		"notes":          patch.Notes,
+		"split_strategy": patch.SplitStrategy,
+	} {
+		switch {
+		case !field.Set:
+		case field.Null:
+			values[column] = nil
+		default:
+			values[column] = field.Value
+		}
+	}
+	switch field := patch.LicensingFee; {
+	case !field.Set:
+	case field.Null:
+		values["licensing_fee"] = nil
+	default:
+		values["licensing_fee"] = field.Value
+	}
+	switch field := patch.AlbumID; {
+	case !field.Set:
+	case field.Null:
+		values["album_id"] = nil
+	default:
+		values["album_id"] = field.Value
+	}
+	if len(values) == 0 {
+		// Nothing to change, but the song must still exist
+		_, err := r.songs.Get(ctx, id)
 		return err
 	}
-	rowsAffected, err := result.RowsAffected()
+
+	err := r.songs.Update(ctx, id, values)
 	if err != nil {
-		r.logger.Error("Failed to check rows affected", zap.Int("id", id), zap.Error(err))
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to partially update song", zap.Int("id", id), zap.Error(err))
+		}
 		return err
 	}
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	r.logger.Info("Song partially updated in database", zap.Int("id", id), zap.Int("fields", len(values)))
+	return nil
+}
+
+// DeleteSong deletes a song from the database
+func (r *PostgresRepository) DeleteSong(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting song from database", zap.Int("id", id))
+	if err := r.songs.Delete(ctx, id); err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to delete song", zap.Int("id", id), zap.Error(err))
+		}
+		return err
 	}
 	r.logger.Info("Song deleted from database", zap.Int("id", id))
 	return nil
 }
 
 // TruncateSongs truncates the songs table and resets the ID sequence
-func (r *PostgresRepository) TruncateSongs() error {
+func (r *PostgresRepository) TruncateSongs(ctx context.Context) error {
 	r.logger.Debug("Truncating table")
-	_, err := r.db.Exec("TRUNCATE TABLE songs RESTART IDENTITY")
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "TRUNCATE TABLE songs RESTART IDENTITY CASCADE"
+	start := time.Now()
+	_, err := r.conn(ctx).ExecContext(ctx, query)
+	r.track(query, start, 0, err)
 	if err != nil {
 		r.logger.Error("Failed to truncate table", zap.Error(err))
 		return err
@@ -135,3 +330,57 @@ func (r *PostgresRepository) TruncateSongs() error {
 	r.logger.Info("Table truncated in database")
 	return nil
 }
+
+// FindSongID looks up the ID of a song by its group and title, ignoring case
+func (r *PostgresRepository) FindSongID(ctx context.Context, group, song string) (int, error) {
+	r.logger.Debug("Looking up song ID", zap.String("group", group), zap.String("song", song))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT id FROM songs WHERE LOWER(group_name) = LOWER($1) AND LOWER(song_name) = LOWER($2) ORDER BY id LIMIT 1"
+	var id int
+	start := time.Now()
+	err := r.db.GetContext(ctx, &id, query, group, song)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to look up song ID", zap.Error(err))
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// IncrementSongViews adds the buffered view counts to the stored counters in a single statement
+func (r *PostgresRepository) IncrementSongViews(ctx context.Context, counts map[int]int64) error {
+	r.logger.Debug("Flushing song views", zap.Int("songs", len(counts)))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	ids := make([]int64, 0, len(counts))
+	views := make([]int64, 0, len(counts))
+	for id, count := range counts {
+		ids = append(ids, int64(id))
+		views = append(views, count)
+	}
+	query := `WITH counts AS (
+			SELECT c.song_id, c.views FROM unnest($1::int[], $2::bigint[]) AS c(song_id, views)
+			JOIN songs s ON s.id = c.song_id
+		), days AS (
+			INSERT INTO song_view_days (song_id, day, views)
+			SELECT song_id, CURRENT_DATE, views FROM counts
+			ON CONFLICT (song_id, day) DO UPDATE SET views = song_view_days.views + EXCLUDED.views
+		)
+		INSERT INTO song_views (song_id, views)
+		SELECT song_id, views FROM counts
+		ON CONFLICT (song_id) DO UPDATE SET views = song_views.views + EXCLUDED.views`
+	start := time.Now()
+	result, err := r.db.ExecContext(ctx, query, pq.Array(ids), pq.Array(views))
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to flush song views", zap.Error(err))
+		return err
+	}
+	rowsAffected, _ := result.RowsAffected()
+	r.track(query, start, rowsAffected, nil)
+	r.logger.Info("Song views flushed to database", zap.Int64("songs", rowsAffected))
+	return nil
+}
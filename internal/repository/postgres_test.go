@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newMockedPostgres returns a PostgresRepository over sqlmock, whose expectations must all be met by
+// the end of the test
+func newMockedPostgres(t *testing.T) (*PostgresRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	})
+	return NewPostgresRepository(sqlx.NewDb(db, "postgres"), zap.NewNop()), mock
+}
+
+func TestPostgresGetSongTags(t *testing.T) {
+	repo, mock := newMockedPostgres(t)
+	mock.ExpectQuery(`SELECT COALESCE\(array_agg\(t.name ORDER BY t.name\)`).WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow("{live,rock}"))
+	mock.ExpectQuery(`FROM songs s LEFT JOIN song_tags`).WithArgs(8).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}))
+
+	tags, err := repo.GetSongTags(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"live", "rock"}, tags)
+
+	_, err = repo.GetSongTags(context.Background(), 8)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestPostgresUpdateJobProgress(t *testing.T) {
+	repo, mock := newMockedPostgres(t)
+	mock.ExpectExec(`UPDATE jobs SET total = \$2, processed = \$3, failed = \$4`).WithArgs("job-1", 10, 4, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE jobs SET total`).WithArgs("missing", 10, 4, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.UpdateJobProgress(context.Background(), "job-1", 10, 4, 1))
+	assert.ErrorIs(t, repo.UpdateJobProgress(context.Background(), "missing", 10, 4, 1), sql.ErrNoRows)
+}
+
+func TestPostgresRunInTransaction(t *testing.T) {
+	repo, mock := newMockedPostgres(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM songs s LEFT JOIN song_tags`).WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow("{}"))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	err := repo.RunInTransaction(context.Background(), func(ctx context.Context) error {
+		// Statements of the repository methods run in the transaction
+		tags, err := repo.GetSongTags(ctx, 7)
+		assert.Empty(t, tags)
+		return err
+	})
+	assert.NoError(t, err)
+
+	failure := errors.New("failed")
+	err = repo.RunInTransaction(context.Background(), func(context.Context) error { return failure })
+	assert.ErrorIs(t, err, failure, "the transaction is rolled back")
+}
+
+func TestPostgresLegalHoldsLockInTransaction(t *testing.T) {
+	repo, mock := newMockedPostgres(t)
+	mock.ExpectQuery(`SELECT id, legal_hold FROM songs WHERE id = ANY\(\$1\) ORDER BY id$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "legal_hold"}).AddRow(1, false).AddRow(2, true))
+	mock.ExpectBegin()
+	// Every song checked is locked, so a hold cannot be set on one until the transaction ends
+	mock.ExpectQuery(`SELECT id, legal_hold FROM songs WHERE id = ANY\(\$1\) ORDER BY id FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "legal_hold"}).AddRow(1, false))
+	mock.ExpectExec(`LOCK TABLE songs IN SHARE ROW EXCLUSIVE MODE`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM songs WHERE legal_hold`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectCommit()
+
+	held, err := repo.GetLegalHolds(context.Background(), []int{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2}, held)
+
+	err = repo.RunInTransaction(context.Background(), func(ctx context.Context) error {
+		held, err := repo.GetLegalHolds(ctx, []int{1})
+		assert.Empty(t, held)
+		if err != nil {
+			return err
+		}
+		count, err := repo.CountLegalHolds(ctx)
+		assert.Zero(t, count)
+		return err
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// GetPreferences retrieves the preferences of the user, returning sql.ErrNoRows when none were saved
+func (r *PostgresRepository) GetPreferences(ctx context.Context, userID int) (models.Preferences, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT page_size, sort, language, explicit_filter, api_compat, updated_at FROM user_preferences WHERE user_id = $1"
+	var preferences models.Preferences
+	start := time.Now()
+	err := r.db.GetContext(ctx, &preferences, query, userID)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch preferences", zap.Int("user_id", userID), zap.Error(err))
+	}
+	return preferences, err
+}
+
+// SavePreferences creates or replaces the preferences of the user
+func (r *PostgresRepository) SavePreferences(ctx context.Context, userID int, preferences models.Preferences) error {
+	r.logger.Debug("Saving preferences", zap.Int("user_id", userID))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO user_preferences (user_id, page_size, sort, language, explicit_filter, api_compat, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET page_size = EXCLUDED.page_size, sort = EXCLUDED.sort,
+			language = EXCLUDED.language, explicit_filter = EXCLUDED.explicit_filter,
+			api_compat = EXCLUDED.api_compat, updated_at = NOW()`
+	start := time.Now()
+	_, err := r.db.ExecContext(ctx, query, userID, preferences.PageSize, preferences.Sort, preferences.Language,
+		preferences.ExplicitFilter, preferences.APICompat)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to save preferences", zap.Int("user_id", userID), zap.Error(err))
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultQueryLogSize is the number of recent queries kept by the repository
+const defaultQueryLogSize = 200
+
+// QueryLogEntry describes a single executed repository query
+type QueryLogEntry struct {
+	Query      string    `json:"query" example:"SELECT s.*, COALESCE(v.views, 0) AS views FROM songs s LEFT JOIN song_views v ON v.song_id = s.id WHERE s.id = $1"`
+	DurationMs float64   `json:"duration_ms" example:"0.42"`
+	Rows       int64     `json:"rows" example:"1"`
+	Error      string    `json:"error,omitempty"`
+	ExecutedAt time.Time `json:"executed_at" example:"2024-01-15T12:00:00Z"`
+}
+
+// QueryLog is a fixed-size ring buffer of recently executed queries
+type QueryLog struct {
+	mu      sync.Mutex
+	entries []QueryLogEntry
+	next    int
+	full    bool
+}
+
+// NewQueryLog creates a new QueryLog holding up to size entries
+func NewQueryLog(size int) *QueryLog {
+	return &QueryLog{entries: make([]QueryLogEntry, size)}
+}
+
+// Record stores a query in the ring buffer, overwriting the oldest entry when full.
+// Only the parameterized SQL text is kept, so argument values never reach the log.
+func (l *QueryLog) Record(query string, duration time.Duration, rows int64, err error) {
+	entry := QueryLogEntry{
+		Query:      strings.Join(strings.Fields(query), " "),
+		DurationMs: float64(duration.Microseconds()) / 1000,
+		Rows:       rows,
+		ExecutedAt: time.Now(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Entries returns the recorded queries, newest first
+func (l *QueryLog) Entries() []QueryLogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	count := l.next
+	if l.full {
+		count = len(l.entries)
+	}
+	result := make([]QueryLogEntry, 0, count)
+	for i := 1; i <= count; i++ {
+		result = append(result, l.entries[(l.next-i+len(l.entries))%len(l.entries)])
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// GetSongRating retrieves the user's rating of the song together with its average rating, returning
+// sql.ErrNoRows when the song does not exist
+func (r *PostgresRepository) GetSongRating(ctx context.Context, userID, songID int) (models.SongRating, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT s.id AS song_id,
+			(SELECT sr.rating FROM song_ratings sr WHERE sr.user_id = $1 AND sr.song_id = s.id) AS rating,
+			(SELECT AVG(sr.rating)::float8 FROM song_ratings sr WHERE sr.song_id = s.id) AS average_rating,
+			(SELECT COUNT(*) FROM song_ratings sr WHERE sr.song_id = s.id) AS rating_count
+		FROM songs s WHERE s.id = $2`
+	var rating models.SongRating
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &rating, query, userID, songID)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch song rating", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return rating, err
+}
+
+// RateSong creates or replaces the user's rating of the song, returning sql.ErrNoRows when the song does
+// not exist
+func (r *PostgresRepository) RateSong(ctx context.Context, userID, songID, rating int) error {
+	r.logger.Debug("Rating song", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Int("rating", rating))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_ratings (user_id, song_id, rating)
+		SELECT $1, id, $3 FROM songs WHERE id = $2
+		ON CONFLICT (user_id, song_id) DO UPDATE SET rating = EXCLUDED.rating, updated_at = NOW()
+		RETURNING song_id`
+	var id int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &id, query, userID, songID, rating)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to rate song", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return err
+}
+
+// DeleteSongRating discards the user's rating of the song, returning sql.ErrNoRows when there is none
+func (r *PostgresRepository) DeleteSongRating(ctx context.Context, userID, songID int) error {
+	r.logger.Debug("Deleting song rating", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return r.deleteUserSongRow(ctx, "DELETE FROM song_ratings WHERE user_id = $1 AND song_id = $2", userID, songID)
+}
+
+// FavoriteSong marks the song as one of the user's favorites and returns when it was first marked,
+// returning sql.ErrNoRows when the song does not exist
+func (r *PostgresRepository) FavoriteSong(ctx context.Context, userID, songID int) (models.SongFavorite, error) {
+	r.logger.Debug("Marking song as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	// The no-op update returns the existing row, keeping when the song was first marked
+	query := `INSERT INTO song_favorites (user_id, song_id)
+		SELECT $1, id FROM songs WHERE id = $2
+		ON CONFLICT (user_id, song_id) DO UPDATE SET created_at = song_favorites.created_at
+		RETURNING song_id, created_at`
+	var favorite models.SongFavorite
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &favorite, query, userID, songID)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to mark song as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return favorite, err
+}
+
+// UnfavoriteSong removes the song from the user's favorites, returning sql.ErrNoRows when it is not one
+func (r *PostgresRepository) UnfavoriteSong(ctx context.Context, userID, songID int) error {
+	r.logger.Debug("Unmarking song as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return r.deleteUserSongRow(ctx, "DELETE FROM song_favorites WHERE user_id = $1 AND song_id = $2", userID, songID)
+}
+
+// deleteUserSongRow runs a delete of the user's row about the song, returning sql.ErrNoRows when it
+// deletes nothing
+func (r *PostgresRepository) deleteUserSongRow(ctx context.Context, query string, userID, songID int) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, userID, songID)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to delete user song row", zap.String("query", query), zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	r.track(query, start, rows, nil)
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -1,12 +1,178 @@
 package repository
 
-import "music-library/internal/models"
+import (
+	"context"
+	"time"
 
+	"music-library/internal/models"
+)
+
+//go:generate go run ./gen -type Repository -output instrumented.go
+//go:generate go run github.com/golang/mock/mockgen@v1.6.0 -source=repository.go -destination=mocks/repository.go -package=mocks
+
+// Repository is the storage the music service works against. PostgresRepository, SQLiteRepository,
+// MySQLRepository and MongoRepository implement it, one per DB_DRIVER, and InstrumentedRepository, generated
+// from it, decorates any of them with logging, metrics, tracing and retries. Add new methods here, to every
+// implementation, and run go generate, so they are instrumented like the others and mocks.MockRepository,
+// the gomock double the unit tests use, gains them too.
 type Repository interface {
-	AddSong(group, song, releaseDate, text, link string) (int, error)
-	GetSongs(group, song, releaseDate, text, link, createdAt, updatedAt string, limit, offset int) ([]models.Song, error)
-	UpdateSong(id int, group, song, releaseDate, text, link string) error
-	DeleteSong(id int) error
-	TruncateSongs() error
-	GetSongByID(id int) (models.Song, error)
+	ConfigurePopularity(provider PopularityProvider)
+	ConfigureStatementTimeout(timeout time.Duration)
+	Ping(ctx context.Context) error
+	RecentQueries() []QueryLogEntry
+	RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+
+	AddSong(ctx context.Context, group, song, releaseDate, text, link string, enrichedAt *time.Time) (int, error)
+	AddSongs(ctx context.Context, songs []models.SongInput) ([]int, []error, error)
+	CopySongs(ctx context.Context, songs []models.SongInput) ([]int, error)
+	GetSongByID(ctx context.Context, id int) (models.Song, error)
+	GetSongs(ctx context.Context, filter models.SongFilter, sort string, page, limit int) ([]models.Song, error)
+	CountSongs(ctx context.Context, filter models.SongFilter) (int, error)
+	FindSongID(ctx context.Context, group, song string) (int, error)
+	GetSongFacets(ctx context.Context, filter models.SongFilter, facets []string) (map[string][]models.FacetBucket, error)
+	GetSongsCreatedBetween(ctx context.Context, from, to time.Time) ([]models.Song, error)
+	GetSongsUpdatedBetween(ctx context.Context, from, to time.Time) ([]models.Song, error)
+	GetSongsWithLyrics(ctx context.Context) ([]models.Song, error)
+	GetSongsWithReleaseDate(ctx context.Context, group, song string) ([]models.Song, error)
+	UpdateSong(ctx context.Context, id int, group, song, releaseDate, text, link string) error
+	UpdateSongPartial(ctx context.Context, id int, patch models.SongPatch) error
+	DeleteSong(ctx context.Context, id int) error
+	TruncateSongs(ctx context.Context) error
+	CreateSnapshot(ctx context.Context, reason string) (models.Snapshot, error)
+	GetSnapshots(ctx context.Context) ([]models.Snapshot, error)
+	RestoreSnapshot(ctx context.Context, id int) (int, error)
+	SetLegalHold(ctx context.Context, id int, held bool) error
+	GetLegalHolds(ctx context.Context, ids []int) ([]int, error)
+	CountLegalHolds(ctx context.Context) (int, error)
+	BackfillLegacyRows(ctx context.Context, dryRun bool) (models.BackfillReport, error)
+	SaveVerseIndex(ctx context.Context, songID int, delimiter, textHash string, spans []models.VerseSpan) error
+	GetVerseIndex(ctx context.Context, songID int) (models.VerseIndex, error)
+	GetIndexedVerses(ctx context.Context, songID int, delimiter string, from, to int) ([]models.IndexedVerse, error)
+
+	IncrementSongViews(ctx context.Context, counts map[int]int64) error
+	RefreshTrending(ctx context.Context, gravity float64, windowDays int) error
+	GetTrendingSongs(ctx context.Context, limit int) ([]models.TrendingSong, error)
+	GetGroupStats(ctx context.Context, group string) (models.GroupStats, error)
+	GetMostViewedGroupSongs(ctx context.Context, group string, limit int) ([]models.Song, error)
+	GetSongsNeedingListeners(ctx context.Context, maxAge time.Duration, exclude []int, limit int) ([]models.Song, error)
+	SaveListenerCount(ctx context.Context, id int, listeners int64) error
+
+	GetStalestSongs(ctx context.Context, staleAfter time.Duration, exclude []int, limit int) ([]models.Song, error)
+	GetSongsAfter(ctx context.Context, filter models.SongFilter, afterID, limit int) ([]models.Song, error)
+	RefreshSongData(ctx context.Context, id int, releaseDate, text, link string) error
+	AddPendingSong(ctx context.Context, group, song string) (int, error)
+	CompleteEnrichment(ctx context.Context, id int, releaseDate, text, link string, enrichedAt *time.Time) error
+	FailEnrichment(ctx context.Context, id int, reason string) error
+	GetEnrichmentStatus(ctx context.Context, id int) (models.EnrichmentStatus, error)
+	GetPendingEnrichments(ctx context.Context, exclude []int, limit int) ([]models.Song, error)
+	GetSongsNeedingMetadata(ctx context.Context, exclude []int, limit int) ([]models.Song, error)
+	SaveSongMetadata(ctx context.Context, id int, metadata models.TrackMetadata) error
+
+	CreateImport(ctx context.Context, id string) (models.Import, error)
+	GetImport(ctx context.Context, id string) (models.Import, error)
+	ImportBatch(ctx context.Context, importID string, songs []models.ImportSong, checkpointRow, failed int) ([]int, error)
+	FinishImport(ctx context.Context, id, status string) (models.Import, error)
+	CreateJob(ctx context.Context, id, kind string, ownerID *int) (models.Job, error)
+	GetJob(ctx context.Context, id string) (models.Job, error)
+	StartJob(ctx context.Context, id string) error
+	UpdateJobProgress(ctx context.Context, id string, total, processed, failed int) error
+	FinishJob(ctx context.Context, id, status string, total, processed, failed int, result []byte, message string) error
+	FailUnfinishedJobs(ctx context.Context, message string) (int64, error)
+
+	SearchSongs(ctx context.Context, query string, limit int) ([]models.SearchResult, error)
+	HasSongEmbeddings(ctx context.Context) (bool, error)
+	GetSongsNeedingEmbedding(ctx context.Context, model string, limit int) ([]models.Song, error)
+	SaveSongEmbedding(ctx context.Context, songID int, model, contentHash string, embedding []float32) error
+	SearchSongsSemantic(ctx context.Context, model string, embedding []float32, keywords string, keywordWeight float64, limit int) ([]models.SearchResult, error)
+	HasSearchSuggestions(ctx context.Context) (bool, error)
+	SimilarSongNames(ctx context.Context, query string, limit int) ([]models.SearchSuggestion, error)
+	SimilarSearchTerms(ctx context.Context, words []string) (map[string]models.SearchSuggestion, error)
+	RefreshSearchTerms(ctx context.Context) error
+
+	AddClassificationSuggestions(ctx context.Context, songID int, source string, suggestions []models.ClassificationSuggestion) (int, error)
+	GetClassificationSuggestions(ctx context.Context, status string, page, limit int) ([]models.ClassificationSuggestion, error)
+	GetClassificationSuggestion(ctx context.Context, id int) (models.ClassificationSuggestion, error)
+	ReviewClassificationSuggestion(ctx context.Context, id int, status string) error
+
+	BulkTagSongs(ctx context.Context, ids []int, filter models.SongFilter, add, remove []string) (models.BulkTagResult, error)
+	GetSongTags(ctx context.Context, songID int) ([]string, error)
+
+	IncrementProviderUsage(ctx context.Context, provider string, day time.Time) (int, error)
+	GetProviderUsage(ctx context.Context, provider string, day time.Time) (int, error)
+	AddAPICapture(ctx context.Context, capture models.APICapture, keep int) error
+	GetAPICaptures(ctx context.Context, provider string, limit int) ([]models.APICapture, error)
+	CreateWebhook(ctx context.Context, url string, events []string, secret string) (models.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	EnqueueWebhookDeliveries(ctx context.Context, eventType string, payload []byte) (int64, error)
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration, exclude []int) ([]models.DueDelivery, error)
+	DeferWebhookDelivery(ctx context.Context, id int64, after time.Duration) error
+	FinishWebhookDelivery(ctx context.Context, id int64, status string, responseStatus *int, message *string, retryAfter time.Duration) error
+	GetWebhookDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]models.WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, webhookID int, id int64) (models.WebhookDelivery, error)
+	RetryWebhookDelivery(ctx context.Context, webhookID int, id int64) (models.WebhookDelivery, error)
+	RotateWebhookSecret(ctx context.Context, id int, secret string) (models.Webhook, error)
+	AddSongEvent(ctx context.Context, event models.SongEvent) (int64, error)
+	ClaimSongEvents(ctx context.Context, limit int) ([]models.SongEvent, error)
+	MarkSongEventsPublished(ctx context.Context, ids []int64) error
+	GetSongEventsAfter(ctx context.Context, afterID int64, limit int) ([]models.SongEvent, error)
+	GetLatestSongEventID(ctx context.Context) (int64, error)
+	DeleteSongEventsBefore(ctx context.Context, before time.Time) (int64, error)
+
+	CreateUser(ctx context.Context, username, passwordHash, role string) (int, error)
+	GetUserByID(ctx context.Context, id int) (models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)
+	GetUsers(ctx context.Context, page, limit int) ([]models.User, error)
+	SetUserRole(ctx context.Context, id int, role string) error
+	GetPreferences(ctx context.Context, userID int) (models.Preferences, error)
+	SavePreferences(ctx context.Context, userID int, preferences models.Preferences) error
+	GetSongOverride(ctx context.Context, userID, songID int) (models.SongOverride, error)
+	GetSongOverrides(ctx context.Context, userID int, songIDs []int) ([]models.SongOverride, error)
+	SaveSongOverride(ctx context.Context, userID, songID int, text string) (models.SongOverride, error)
+	DeleteSongOverride(ctx context.Context, userID, songID int) error
+	GetSongRating(ctx context.Context, userID, songID int) (models.SongRating, error)
+	RateSong(ctx context.Context, userID, songID, rating int) error
+	DeleteSongRating(ctx context.Context, userID, songID int) error
+	FavoriteSong(ctx context.Context, userID, songID int) (models.SongFavorite, error)
+	UnfavoriteSong(ctx context.Context, userID, songID int) error
+	AddSongRevision(ctx context.Context, songID int, reason string, restoredFrom *int) (int, error)
+	CountSongRevisions(ctx context.Context, songID int) (int, error)
+	GetSongRevisions(ctx context.Context, songID int) ([]models.SongRevision, error)
+	GetSongRevision(ctx context.Context, songID, revision int) (models.SongRevision, error)
+	TrashSong(ctx context.Context, id int) error
+	GetTrashedSong(ctx context.Context, id int) (models.TrashedSong, error)
+	GetTrash(ctx context.Context, page, limit int) ([]models.TrashedSong, int, error)
+	RestoreTrashedSong(ctx context.Context, id int) error
+	DeleteTrashedSong(ctx context.Context, id int) error
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+
+	CreateAlbum(ctx context.Context, album models.AlbumInput) (int, error)
+	GetAlbum(ctx context.Context, id int) (models.Album, error)
+	GetAlbums(ctx context.Context, page, limit int) ([]models.Album, int, error)
+	UpdateAlbum(ctx context.Context, id int, album models.AlbumInput) error
+	DeleteAlbum(ctx context.Context, id int) error
+	GetAlbumSongs(ctx context.Context, albumID, page, limit int) ([]models.Song, int, error)
+	CreateArtist(ctx context.Context, artist models.ArtistInput) (int, error)
+	GetArtist(ctx context.Context, id int) (models.Artist, error)
+	GetArtistByName(ctx context.Context, name string) (models.Artist, error)
+	GetArtists(ctx context.Context, page, limit int) ([]models.Artist, int, error)
+	UpdateArtist(ctx context.Context, id int, artist models.ArtistInput) ([]int, error)
+	DeleteArtist(ctx context.Context, id int) error
+	GetArtistSongs(ctx context.Context, artistID, page, limit int) ([]models.Song, int, error)
+	CreateGenre(ctx context.Context, genre models.GenreInput) (int, error)
+	GetGenre(ctx context.Context, id int) (models.Genre, error)
+	GetGenreByName(ctx context.Context, name string) (models.Genre, error)
+	GetGenres(ctx context.Context) ([]models.Genre, error)
+	GetGenreSubtree(ctx context.Context, id int) ([]int, error)
+	UpdateGenre(ctx context.Context, id int, genre models.GenreInput) error
+	DeleteGenre(ctx context.Context, id int) error
+	CountSubgenres(ctx context.Context, id int) (int, error)
+	GetSongGenres(ctx context.Context, songID int) ([]models.Genre, error)
+	SetSongGenres(ctx context.Context, songID int, genreIDs []int) error
+	GetSongTitles(ctx context.Context, songID int) ([]models.SongTitle, error)
+	GetSongTitlesIn(ctx context.Context, songIDs []int, langs []string) ([]models.SongTitle, error)
+	SetSongTitle(ctx context.Context, songID int, lang string, title models.SongTitleInput) (models.SongTitle, error)
+	DeleteSongTitle(ctx context.Context, songID int, lang string) error
 }
+
+var _ Repository = (*PostgresRepository)(nil)
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// selectSongRevisions selects song revisions
+const selectSongRevisions = "SELECT song_id, revision, reason, restored_from, data, created_at FROM song_revisions"
+
+// songRevisionRow is a song revision as stored, with its data still encoded
+type songRevisionRow struct {
+	models.SongRevision
+	RawData []byte `db:"data"`
+}
+
+// decode returns the revision with its data decoded
+func (row songRevisionRow) decode() (models.SongRevision, error) {
+	revision := row.SongRevision
+	if err := json.Unmarshal(row.RawData, &revision.Data); err != nil {
+		return models.SongRevision{}, err
+	}
+	return revision, nil
+}
+
+// AddSongRevision records the song's editable fields as they currently are as its next revision and
+// returns the revision number, or sql.ErrNoRows when the song does not exist. restoredFrom is the revision
+// a restore brought back, nil for other reasons.
+func (r *PostgresRepository) AddSongRevision(ctx context.Context, songID int, reason string, restoredFrom *int) (int, error) {
+	r.logger.Debug("Recording song revision", zap.Int("song_id", songID), zap.String("reason", reason))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_revisions (song_id, revision, reason, restored_from, data)
+		SELECT s.id, COALESCE((SELECT MAX(revision) FROM song_revisions WHERE song_id = s.id), 0) + 1, $2, $3,
+			jsonb_build_object('id', s.id, 'group', s.group_name, 'song', s.song_name, 'release_date', s.release_date,
+				'text', s.text, 'link', s.link, 'notes', s.notes, 'licensing_fee', s.licensing_fee,
+				'split_strategy', s.split_strategy, 'album_id', s.album_id)
+		FROM songs s WHERE s.id = $1
+		RETURNING revision`
+	var revision int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &revision, query, songID, reason, nullIfNil(restoredFrom))
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to record song revision", zap.Int("song_id", songID), zap.Error(err))
+		}
+		return 0, err
+	}
+	return revision, nil
+}
+
+// CountSongRevisions returns the number of revisions recorded for the song
+func (r *PostgresRepository) CountSongRevisions(ctx context.Context, songID int) (int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT COUNT(*) FROM song_revisions WHERE song_id = $1"
+	var count int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &count, query, songID)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to count song revisions", zap.Int("song_id", songID), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetSongRevisions retrieves the revisions of a song, newest first
+func (r *PostgresRepository) GetSongRevisions(ctx context.Context, songID int) ([]models.SongRevision, error) {
+	r.logger.Debug("Fetching song revisions", zap.Int("song_id", songID))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := selectSongRevisions + " WHERE song_id = $1 ORDER BY revision DESC"
+	var rows []songRevisionRow
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &rows, query, songID)
+	r.track(query, start, int64(len(rows)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch song revisions", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+	revisions := make([]models.SongRevision, 0, len(rows))
+	for _, row := range rows {
+		revision, err := row.decode()
+		if err != nil {
+			r.logger.Error("Failed to decode song revision", zap.Int("song_id", songID), zap.Int("revision", row.Revision), zap.Error(err))
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+// GetSongRevision retrieves a revision of a song, returning sql.ErrNoRows when it does not exist
+func (r *PostgresRepository) GetSongRevision(ctx context.Context, songID, revision int) (models.SongRevision, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := selectSongRevisions + " WHERE song_id = $1 AND revision = $2"
+	var row songRevisionRow
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &row, query, songID, revision)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to fetch song revision", zap.Int("song_id", songID), zap.Int("revision", revision), zap.Error(err))
+		}
+		return models.SongRevision{}, err
+	}
+	return row.decode()
+}
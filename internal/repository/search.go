@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// SearchSongs finds songs whose title, in any language, group or lyrics match the query, using web-search syntax
+// ("quoted phrases", OR, -excluded). Title matches rank above group matches, which rank above lyrics matches.
+// Each result carries a lyrics snippet with the matches wrapped in <mark> tags.
+func (r *PostgresRepository) SearchSongs(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	r.logger.Debug("Searching songs", zap.String("query", query), zap.Int("limit", limit))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	sqlQuery := `SELECT ` + songColumns + `, COALESCE(v.views, 0) AS views,
+		GREATEST(ts_rank(s.search_vector, q.query, 32), COALESCE((SELECT MAX(ts_rank(t.search_vector, q.query, 32))
+			FROM song_titles t WHERE t.song_id = s.id AND t.search_vector @@ q.query), 0)) AS score,
+		ts_headline('simple', COALESCE(s.text, ''), q.query,
+			'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS snippet
+		FROM songs s
+		CROSS JOIN websearch_to_tsquery('simple', $1) AS q(query)
+		LEFT JOIN song_views v ON v.song_id = s.id
+		WHERE s.search_vector @@ q.query OR s.id IN (SELECT t.song_id FROM song_titles t WHERE t.search_vector @@ q.query)
+		ORDER BY score DESC, s.id LIMIT $2`
+	results := []models.SearchResult{}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &results, sqlQuery, query, limit)
+	r.track(sqlQuery, start, int64(len(results)), err)
+	if err != nil {
+		r.logger.Error("Failed to search songs", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs searched in database", zap.Int("count", len(results)))
+	return results, nil
+}
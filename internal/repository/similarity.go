@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// GetSongsWithLyrics retrieves every song that has non-empty lyrics
+func (r *PostgresRepository) GetSongsWithLyrics(ctx context.Context) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs with lyrics")
+	songs, err := r.songs.Find(ctx, "COALESCE(s.text, '') <> ''", nil, "s.id")
+	if err != nil {
+		r.logger.Error("Failed to fetch songs with lyrics", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs with lyrics fetched from database", zap.Int("count", len(songs)))
+	return songs, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// snapshotTable is a table whose rows a snapshot holds
+type snapshotTable struct {
+	name string
+	// filter restores only the rows whose referenced rows still exist
+	filter string
+	// expressions replace the restored value of the columns whose referenced rows may be gone
+	expressions map[string]string
+}
+
+// snapshotTables are the tables emptied with the songs, in the order they are restored. The derived ones,
+// such as the embeddings and the verse index, are rebuilt rather than restored.
+var snapshotTables = []snapshotTable{
+	{name: "songs", expressions: map[string]string{"album_id": "(SELECT a.id FROM albums a WHERE a.id = r.album_id)"}},
+	{name: "song_views"},
+	{name: "song_listeners"},
+	{name: "song_tags", filter: "r.tag_id IN (SELECT id FROM tags)"},
+	{name: "song_genres", filter: "r.genre_id IN (SELECT id FROM genres)"},
+	{name: "song_titles"},
+	{name: "song_overrides", filter: "r.user_id IN (SELECT id FROM users)"},
+	{name: "song_ratings", filter: "r.user_id IN (SELECT id FROM users)"},
+	{name: "song_favorites", filter: "r.user_id IN (SELECT id FROM users)"},
+	{name: "song_revisions"},
+}
+
+// snapshotData returns the expression of the JSON object holding the rows of every snapshot table, by table
+// name. songID is the placeholder of the song whose rows are held, or empty for the rows of every song.
+func snapshotData(songID string) string {
+	parts := make([]string, 0, len(snapshotTables))
+	for _, table := range snapshotTables {
+		where := ""
+		if songID != "" {
+			key := "song_id"
+			if table.name == "songs" {
+				key = "id"
+			}
+			where = fmt.Sprintf(" WHERE r.%s = %s", key, songID)
+		}
+		parts = append(parts, fmt.Sprintf("'%s', (SELECT COALESCE(jsonb_agg(to_jsonb(r)), '[]'::jsonb) FROM %s r%s)",
+			table.name, pq.QuoteIdentifier(table.name), where))
+	}
+	return "jsonb_build_object(" + strings.Join(parts, ", ") + ")"
+}
+
+// snapshotColumns are the columns of a snapshot listed and returned, leaving out its data
+const snapshotColumns = "id, reason, song_count, created_at, restored_at"
+
+// CreateSnapshot stores a copy of the songs and the rows attached to them. The songs table is locked against
+// writes until the transaction ends, so a change made in the same transaction, such as a truncate, loses no
+// song the snapshot does not hold.
+func (r *PostgresRepository) CreateSnapshot(ctx context.Context, reason string) (models.Snapshot, error) {
+	r.logger.Debug("Creating snapshot", zap.String("reason", reason))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return models.Snapshot{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE songs IN ACCESS EXCLUSIVE MODE"); err != nil {
+		r.logger.Error("Failed to lock songs", zap.Error(err))
+		return models.Snapshot{}, err
+	}
+	query := fmt.Sprintf(`INSERT INTO snapshots (reason, song_count, data)
+		SELECT $1, (SELECT COUNT(*) FROM songs), %s
+		RETURNING %s`, snapshotData(""), snapshotColumns)
+	var snapshot models.Snapshot
+	start := time.Now()
+	err = tx.GetContext(ctx, &snapshot, query, reason)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to create snapshot", zap.Error(err))
+		return models.Snapshot{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return models.Snapshot{}, err
+	}
+	r.logger.Info("Snapshot created in database", zap.Int("id", snapshot.ID), zap.Int("songs", snapshot.SongCount))
+	return snapshot, nil
+}
+
+// GetSnapshots returns the snapshots, newest first
+func (r *PostgresRepository) GetSnapshots(ctx context.Context) ([]models.Snapshot, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT " + snapshotColumns + " FROM snapshots ORDER BY id DESC"
+	snapshots := []models.Snapshot{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &snapshots, query)
+	r.track(query, start, int64(len(snapshots)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch snapshots", zap.Error(err))
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// RestoreSnapshot inserts the rows a snapshot holds back into their tables, the songs keeping their IDs, and
+// returns the number of songs restored. The songs table is expected to be empty. Rows referencing a user,
+// a tag or a genre deleted since are left out, and songs whose album was deleted are restored without it. sql.ErrNoRows
+// is returned when the snapshot does not exist.
+func (r *PostgresRepository) RestoreSnapshot(ctx context.Context, id int) (int, error) {
+	r.logger.Debug("Restoring snapshot", zap.Int("id", id))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE songs IN ACCESS EXCLUSIVE MODE"); err != nil {
+		r.logger.Error("Failed to lock songs", zap.Error(err))
+		return 0, err
+	}
+	var count int
+	if err := tx.GetContext(ctx, &count, "SELECT song_count FROM snapshots WHERE id = $1 FOR UPDATE", id); err != nil {
+		return 0, err
+	}
+	if err := r.restoreRows(ctx, tx, "snapshots", id); err != nil {
+		return 0, err
+	}
+	query := `SELECT setval(pg_get_serial_sequence('songs', 'id'), COALESCE((SELECT MAX(id) FROM songs), 0) + 1, false)`
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		r.logger.Error("Failed to reset song IDs", zap.Error(err))
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE snapshots SET restored_at = NOW() WHERE id = $1", id); err != nil {
+		r.logger.Error("Failed to mark snapshot restored", zap.Error(err))
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return 0, err
+	}
+	r.logger.Info("Snapshot restored in database", zap.Int("id", id), zap.Int("songs", count))
+	return count, nil
+}
+
+// restoreRows inserts the rows of the snapshot tables held in the data column of the source table's row with
+// the ID back into their tables, leaving out those referencing rows deleted since
+func (r *PostgresRepository) restoreRows(ctx context.Context, tx txScope, source string, id int) error {
+	for _, table := range snapshotTables {
+		// Generated columns are computed again rather than restored
+		var columns []string
+		err := tx.SelectContext(ctx, &columns, `SELECT column_name FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = $1 AND is_generated = 'NEVER' ORDER BY ordinal_position`, table.name)
+		if err != nil {
+			r.logger.Error("Failed to list columns", zap.String("table", table.name), zap.Error(err))
+			return err
+		}
+		names := make([]string, len(columns))
+		values := make([]string, len(columns))
+		for i, column := range columns {
+			names[i] = pq.QuoteIdentifier(column)
+			values[i] = "r." + names[i]
+			if expression, ok := table.expressions[column]; ok {
+				values[i] = expression
+			}
+		}
+		query := fmt.Sprintf(`INSERT INTO %[1]s (%[2]s)
+			SELECT %[3]s FROM %[5]s s, jsonb_populate_recordset(NULL::%[1]s, s.data->'%[4]s') r WHERE s.id = $1`,
+			pq.QuoteIdentifier(table.name), strings.Join(names, ", "), strings.Join(values, ", "), table.name, pq.QuoteIdentifier(source))
+		if table.filter != "" {
+			query += " AND " + table.filter
+		}
+		start := time.Now()
+		result, err := tx.ExecContext(ctx, query, id)
+		var rows int64
+		if err == nil {
+			rows, _ = result.RowsAffected()
+		}
+		r.track(query, start, rows, err)
+		if err != nil {
+			r.logger.Error("Failed to restore rows", zap.String("source", source), zap.String("table", table.name), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// sqliteDriver is the name of the SQLite driver whose connections provide the SQL functions the
+// statements of SQLiteRepository rely on
+const sqliteDriver = "sqlite3_music_library"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{ConnectHook: registerSQLiteFunctions})
+}
+
+// sqliteNow is the current time in the format SQLite stores times in
+const sqliteNow = "strftime('%Y-%m-%d %H:%M:%f', 'now')"
+
+// sqliteTimeFormat is the format times are stored in: UTC with milliseconds, so they compare as text
+const sqliteTimeFormat = "2006-01-02 15:04:05.000"
+
+// sqliteSongColumns lists the song columns read into models.Song
+const sqliteSongColumns = `s.id, s.group_name, s.song_name, s.release_date, s.text, s.link, s.created_at, s.updated_at, s.enriched_at,
+	s.enrichment_status, s.notes, s.licensing_fee, s.album, s.duration_ms, s.isrc, s.artwork_url, s.legal_hold,
+	s.split_strategy, s.album_id, s.artist_id,
+	(SELECT AVG(sr.rating) FROM song_ratings sr WHERE sr.song_id = s.id) AS average_rating,
+	(SELECT COUNT(*) FROM song_ratings sr WHERE sr.song_id = s.id) AS rating_count`
+
+// sqliteSelectSongs selects song rows together with their view counters
+const sqliteSelectSongs = `SELECT ` + sqliteSongColumns + `, COALESCE(v.views, 0) AS views FROM songs s LEFT JOIN song_views v ON v.song_id = s.id`
+
+// OpenSQLite opens the SQLite database file at the path, creating it when missing. Foreign keys are
+// enforced, writers wait for each other instead of failing, and transactions take the write lock when
+// they begin, so two of them cannot deadlock upgrading their read locks.
+func OpenSQLite(path string) (*sqlx.DB, error) {
+	db, err := sqlx.Open(sqliteDriver, "file:"+path+"?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate")
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// SQLiteRepository stores the music library in a SQLite database, for deployments too small to run
+// PostgreSQL. Its schema is created by the migrations in migrations/sqlite. The PostgreSQL features the
+// statements of PostgresRepository rely on (full-text search, trigram similarity, pgvector) are provided
+// by SQL functions implemented in Go, registered on every connection.
+type SQLiteRepository struct {
+	db       *sqlx.DB
+	logger   *zap.Logger
+	queryLog *QueryLog
+	songs    *Table[models.Song]
+
+	suggestions *Table[models.ClassificationSuggestion]
+	users       *Table[models.User]
+	albums      *Table[models.Album]
+	artists     *Table[models.Artist]
+	genres      *Table[models.Genre]
+	trash       *Table[models.TrashedSong]
+
+	popularity PopularityProvider
+	// statementTimeout bounds each statement run outside a transaction
+	statementTimeout time.Duration
+}
+
+var _ Repository = (*SQLiteRepository)(nil)
+
+// NewSQLiteRepository creates a SQLiteRepository on a database opened by OpenSQLite
+func NewSQLiteRepository(db *sqlx.DB, logger *zap.Logger) *SQLiteRepository {
+	queryLog := NewQueryLog(defaultQueryLogSize)
+	return &SQLiteRepository{
+		db:       db,
+		logger:   logger,
+		queryLog: queryLog,
+		songs:    newSQLiteTable[models.Song](db, logger, queryLog, "songs", sqliteSelectSongs, "s.id"),
+
+		suggestions: newSQLiteTable[models.ClassificationSuggestion](db, logger, queryLog, "classification_suggestions", selectSuggestions, "c.id"),
+		users:       newSQLiteTable[models.User](db, logger, queryLog, "users", selectUsers, "id"),
+		albums:      newSQLiteTable[models.Album](db, logger, queryLog, "albums", selectAlbums, "id"),
+		artists:     newSQLiteTable[models.Artist](db, logger, queryLog, "artists", selectArtists, "id"),
+		genres:      newSQLiteTable[models.Genre](db, logger, queryLog, "genres", selectGenres, "id"),
+		trash:       newSQLiteTable[models.TrashedSong](db, logger, queryLog, "trashed_songs", selectTrashedSongs, "id"),
+
+		popularity: InternalPopularity{},
+	}
+}
+
+// newSQLiteTable creates a Table whose statements run on SQLite
+func newSQLiteTable[T any](db *sqlx.DB, logger *zap.Logger, queryLog *QueryLog, name, selectQuery, idColumn string) *Table[T] {
+	table := NewTable[T](db, logger, queryLog, name, selectQuery, idColumn)
+	table.dialect = newSQLiteConn
+	return table
+}
+
+// ConfigureStatementTimeout bounds every statement run outside a transaction, including those of the
+// entity tables; zero leaves statements bounded only by the caller's context
+func (r *SQLiteRepository) ConfigureStatementTimeout(timeout time.Duration) {
+	r.statementTimeout = timeout
+	r.songs.timeout = timeout
+	r.suggestions.timeout = timeout
+	r.users.timeout = timeout
+	r.albums.timeout = timeout
+	r.artists.timeout = timeout
+	r.genres.timeout = timeout
+	r.trash.timeout = timeout
+}
+
+// ConfigurePopularity sets the provider scoring songs for sort=popularity; the default counts internal plays
+func (r *SQLiteRepository) ConfigurePopularity(provider PopularityProvider) {
+	r.popularity = provider
+}
+
+// orderBy returns the ORDER BY clause of the sort key, ordering by ID for unsupported keys
+func (r *SQLiteRepository) orderBy(sort string) string {
+	if sort == SortPopularity {
+		return r.popularity.Score() + " DESC, s.id"
+	}
+	if orderBy, ok := sortOrders[sort]; ok {
+		return orderBy
+	}
+	return sortOrders["id"]
+}
+
+// statementContext bounds a statement by the configured statement timeout
+func (r *SQLiteRepository) statementContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withStatementTimeout(ctx, r.statementTimeout)
+}
+
+// Ping checks that the database is reachable
+func (r *SQLiteRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// RecentQueries returns the most recently executed queries, newest first
+func (r *SQLiteRepository) RecentQueries() []QueryLogEntry {
+	return r.queryLog.Entries()
+}
+
+// track records a finished query in the query log
+func (r *SQLiteRepository) track(query string, start time.Time, rows int64, err error) {
+	duration := time.Since(start)
+	r.queryLog.Record(query, duration, rows, err)
+	metrics.ObserveQuery(query, duration, err)
+}
+
+// conn returns what the statements of a repository method run on. Statements always run in the
+// transaction ctx carries, if any: SQLite has a single writer, so a statement run beside it would wait
+// for the transaction to end.
+func (r *SQLiteRepository) conn(ctx context.Context) queryer {
+	return newSQLiteConn(txOrDB(ctx, r.db))
+}
+
+// RunInTransaction runs fn in a single transaction, committed when fn returns nil and rolled back otherwise.
+// The repository writes fn makes with the context it is given join the transaction. Nested calls join the
+// outer transaction.
+func (r *SQLiteRepository) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if inTransaction(ctx) {
+		return fn(ctx)
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// sqliteTx is the transaction of a repository method, whose statements run on SQLite
+type sqliteTx struct {
+	sqliteConn
+	scope txScope
+}
+
+// begin starts the transaction of a repository method, joining the one ctx carries if any
+func (r *SQLiteRepository) begin(ctx context.Context) (sqliteTx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return sqliteTx{sqliteConn: sqliteConn{tx}, scope: txScope{Tx: tx}}, nil
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return sqliteTx{}, err
+	}
+	return sqliteTx{sqliteConn: sqliteConn{tx}, scope: txScope{Tx: tx, owned: true}}, nil
+}
+
+// Commit commits an owned transaction
+func (t sqliteTx) Commit() error {
+	return t.scope.Commit()
+}
+
+// Rollback rolls back an owned transaction
+func (t sqliteTx) Rollback() error {
+	return t.scope.Rollback()
+}
+
+// sqlitePlaceholder matches the $n placeholders of a statement
+var sqlitePlaceholder = regexp.MustCompile(`\$(\d+)`)
+
+// sqliteConn runs statements written with PostgreSQL's $n placeholders on SQLite, where they are written
+// ?n, and binds times as stored: in UTC, formatted so that they compare as text
+type sqliteConn struct {
+	queryer
+}
+
+// newSQLiteConn wraps what the statements run on
+func newSQLiteConn(q queryer) queryer {
+	return sqliteConn{q}
+}
+
+// sqliteStatement rewrites the placeholders of a statement for SQLite
+func sqliteStatement(query string) string {
+	return sqlitePlaceholder.ReplaceAllString(query, "?$1")
+}
+
+// sqliteArgs formats the times among the arguments of a statement, leaving the caller's slice as it is
+func sqliteArgs(args []any) []any {
+	formatted := make([]any, len(args))
+	for i, arg := range args {
+		switch value := arg.(type) {
+		case time.Time:
+			formatted[i] = value.UTC().Format(sqliteTimeFormat)
+		case *time.Time:
+			if value != nil {
+				formatted[i] = value.UTC().Format(sqliteTimeFormat)
+			}
+		default:
+			formatted[i] = arg
+		}
+	}
+	return formatted
+}
+
+// ExecContext runs a statement
+func (c sqliteConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.queryer.ExecContext(ctx, sqliteStatement(query), sqliteArgs(args)...)
+}
+
+// QueryContext runs a query
+func (c sqliteConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.queryer.QueryContext(ctx, sqliteStatement(query), sqliteArgs(args)...)
+}
+
+// QueryxContext runs a query
+func (c sqliteConn) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+	return c.queryer.QueryxContext(ctx, sqliteStatement(query), sqliteArgs(args)...)
+}
+
+// QueryRowxContext runs a query returning a single row
+func (c sqliteConn) QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row {
+	return c.queryer.QueryRowxContext(ctx, sqliteStatement(query), sqliteArgs(args)...)
+}
+
+// QueryRowContext runs a query returning a single row
+func (c sqliteConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.queryer.QueryRowContext(ctx, sqliteStatement(query), sqliteArgs(args)...)
+}
+
+// GetContext runs a query and scans its single row into dest
+func (c sqliteConn) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return c.queryer.GetContext(ctx, dest, sqliteStatement(query), sqliteArgs(args)...)
+}
+
+// SelectContext runs a query and scans its rows into dest
+func (c sqliteConn) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return c.queryer.SelectContext(ctx, dest, sqliteStatement(query), sqliteArgs(args)...)
+}
+
+// parseSQLiteTime parses a time computed by a statement, which SQLite returns as text since it only
+// converts the values of columns declared as times
+func parseSQLiteTime(value string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02 15:04:05.999999999", value, time.UTC)
+}
@@ -0,0 +1,526 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// CreateAlbum adds an album and returns its ID
+func (r *SQLiteRepository) CreateAlbum(ctx context.Context, album models.AlbumInput) (int, error) {
+	r.logger.Debug("Creating album", zap.String("title", album.Title), zap.String("group", album.Group))
+	return r.albums.Insert(ctx, albumValues(album))
+}
+
+// GetAlbum retrieves an album, returning sql.ErrNoRows when it does not exist
+func (r *SQLiteRepository) GetAlbum(ctx context.Context, id int) (models.Album, error) {
+	return r.albums.Get(ctx, id)
+}
+
+// GetAlbums retrieves a page of albums ordered by ID together with the number of albums
+func (r *SQLiteRepository) GetAlbums(ctx context.Context, page, limit int) ([]models.Album, int, error) {
+	r.logger.Debug("Fetching albums", zap.Int("page", page), zap.Int("limit", limit))
+	albums, err := r.albums.List(ctx, "", nil, "id", page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.albums.Count(ctx, "", nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return albums, total, nil
+}
+
+// UpdateAlbum replaces the fields of an album, returning sql.ErrNoRows when it does not exist
+func (r *SQLiteRepository) UpdateAlbum(ctx context.Context, id int, album models.AlbumInput) error {
+	r.logger.Debug("Updating album", zap.Int("id", id))
+	return r.albums.Update(ctx, id, albumValues(album))
+}
+
+// DeleteAlbum deletes an album, returning sql.ErrNoRows when it does not exist. Its songs are kept and
+// no longer belong to an album.
+func (r *SQLiteRepository) DeleteAlbum(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting album", zap.Int("id", id))
+	if err := r.albums.Delete(ctx, id); err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to delete album", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// GetAlbumSongs retrieves a page of the songs of an album ordered by ID together with the number of its songs
+func (r *SQLiteRepository) GetAlbumSongs(ctx context.Context, albumID, page, limit int) ([]models.Song, int, error) {
+	r.logger.Debug("Fetching album songs", zap.Int("album_id", albumID), zap.Int("page", page), zap.Int("limit", limit))
+	return r.pageSongs(ctx, "s.album_id = $1", []any{albumID}, page, limit)
+}
+
+// pageSongs retrieves a page of the songs matching the where clause ordered by ID together with their number
+func (r *SQLiteRepository) pageSongs(ctx context.Context, where string, args []any, page, limit int) ([]models.Song, int, error) {
+	songs, err := r.songs.List(ctx, where, args, "s.id", page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.songs.Count(ctx, where, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	return songs, total, nil
+}
+
+// CreateArtist adds an artist and returns its ID, or sql.ErrNoRows when the name is taken
+func (r *SQLiteRepository) CreateArtist(ctx context.Context, artist models.ArtistInput) (int, error) {
+	r.logger.Debug("Creating artist", zap.String("name", artist.Name))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO artists (name, country, formed_year, bio) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO NOTHING RETURNING id`
+	var id int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &id, query, artist.Name, nullIfEmpty(artist.Country), nullIfZero(artist.FormedYear), nullIfEmpty(artist.Bio))
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to create artist", zap.String("name", artist.Name), zap.Error(err))
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetArtist retrieves an artist, returning sql.ErrNoRows when it does not exist
+func (r *SQLiteRepository) GetArtist(ctx context.Context, id int) (models.Artist, error) {
+	return r.artists.Get(ctx, id)
+}
+
+// GetArtistByName retrieves the artist with the name, returning sql.ErrNoRows when there is none
+func (r *SQLiteRepository) GetArtistByName(ctx context.Context, name string) (models.Artist, error) {
+	artists, err := r.artists.Find(ctx, "name = $1", []any{name}, "id")
+	if err != nil {
+		return models.Artist{}, err
+	}
+	if len(artists) == 0 {
+		return models.Artist{}, sql.ErrNoRows
+	}
+	return artists[0], nil
+}
+
+// GetArtists retrieves a page of artists ordered by ID together with the number of artists
+func (r *SQLiteRepository) GetArtists(ctx context.Context, page, limit int) ([]models.Artist, int, error) {
+	r.logger.Debug("Fetching artists", zap.Int("page", page), zap.Int("limit", limit))
+	artists, err := r.artists.List(ctx, "", nil, "id", page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.artists.Count(ctx, "", nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return artists, total, nil
+}
+
+// UpdateArtist replaces the fields of an artist, returning sql.ErrNoRows when it does not exist. A new name
+// is carried over to the group of the artist's songs, whose IDs are returned.
+func (r *SQLiteRepository) UpdateArtist(ctx context.Context, id int, artist models.ArtistInput) ([]int, error) {
+	r.logger.Debug("Updating artist", zap.Int("id", id))
+	err := r.artists.Update(ctx, id, map[string]any{
+		"name":        artist.Name,
+		"country":     nullIfEmpty(artist.Country),
+		"formed_year": nullIfZero(artist.FormedYear),
+		"bio":         nullIfEmpty(artist.Bio),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "UPDATE songs SET group_name = $2 WHERE artist_id = $1 AND group_name <> $2 RETURNING id"
+	renamed := []int{}
+	start := time.Now()
+	err = r.conn(ctx).SelectContext(ctx, &renamed, query, id, artist.Name)
+	r.track(query, start, int64(len(renamed)), err)
+	if err != nil {
+		r.logger.Error("Failed to rename artist songs", zap.Int("id", id), zap.Error(err))
+		return nil, err
+	}
+	return renamed, nil
+}
+
+// DeleteArtist deletes an artist, returning sql.ErrNoRows when it does not exist. Artists with songs
+// cannot be deleted.
+func (r *SQLiteRepository) DeleteArtist(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting artist", zap.Int("id", id))
+	if err := r.artists.Delete(ctx, id); err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to delete artist", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// GetArtistSongs retrieves a page of the songs of an artist ordered by ID together with the number of its songs
+func (r *SQLiteRepository) GetArtistSongs(ctx context.Context, artistID, page, limit int) ([]models.Song, int, error) {
+	r.logger.Debug("Fetching artist songs", zap.Int("artist_id", artistID), zap.Int("page", page), zap.Int("limit", limit))
+	return r.pageSongs(ctx, "s.artist_id = $1", []any{artistID}, page, limit)
+}
+
+// CreateGenre adds a genre and returns its ID, or sql.ErrNoRows when the name is taken, regardless of case.
+// The only uniqueness constraint of genres is on the lowercased name, so any conflict is one on the name.
+func (r *SQLiteRepository) CreateGenre(ctx context.Context, genre models.GenreInput) (int, error) {
+	r.logger.Debug("Creating genre", zap.String("name", genre.Name))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO genres (name, parent_id) VALUES ($1, $2) ON CONFLICT DO NOTHING RETURNING id`
+	var id int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &id, query, genre.Name, nullIfNil(genre.ParentID))
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to create genre", zap.String("name", genre.Name), zap.Error(err))
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetGenre retrieves a genre, returning sql.ErrNoRows when it does not exist
+func (r *SQLiteRepository) GetGenre(ctx context.Context, id int) (models.Genre, error) {
+	return r.genres.Get(ctx, id)
+}
+
+// GetGenreByName retrieves the genre with the name regardless of case, returning sql.ErrNoRows when there is none
+func (r *SQLiteRepository) GetGenreByName(ctx context.Context, name string) (models.Genre, error) {
+	genres, err := r.genres.Find(ctx, "LOWER(name) = LOWER($1)", []any{name}, "id")
+	if err != nil {
+		return models.Genre{}, err
+	}
+	if len(genres) == 0 {
+		return models.Genre{}, sql.ErrNoRows
+	}
+	return genres[0], nil
+}
+
+// GetGenres retrieves every genre in name order
+func (r *SQLiteRepository) GetGenres(ctx context.Context) ([]models.Genre, error) {
+	r.logger.Debug("Fetching genres")
+	return r.genres.Find(ctx, "", nil, "name, id")
+}
+
+// GetGenreSubtree returns the IDs of the genre and of all its subgenres, or none when it does not exist
+func (r *SQLiteRepository) GetGenreSubtree(ctx context.Context, id int) ([]int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `WITH RECURSIVE subtree AS (
+			SELECT id FROM genres WHERE id = $1
+			UNION ALL
+			SELECT g.id FROM genres g JOIN subtree ON g.parent_id = subtree.id
+		) SELECT id FROM subtree`
+	ids := []int{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &ids, query, id)
+	r.track(query, start, int64(len(ids)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch genre subtree", zap.Int("id", id), zap.Error(err))
+		return nil, err
+	}
+	return ids, nil
+}
+
+// UpdateGenre replaces the name and parent of a genre, returning sql.ErrNoRows when it does not exist
+func (r *SQLiteRepository) UpdateGenre(ctx context.Context, id int, genre models.GenreInput) error {
+	r.logger.Debug("Updating genre", zap.Int("id", id))
+	return r.genres.Update(ctx, id, map[string]any{
+		"name":      genre.Name,
+		"parent_id": nullIfNil(genre.ParentID),
+	})
+}
+
+// DeleteGenre deletes a genre and unassigns it from its songs, returning sql.ErrNoRows when it does not exist.
+// Genres with subgenres cannot be deleted.
+func (r *SQLiteRepository) DeleteGenre(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting genre", zap.Int("id", id))
+	if err := r.genres.Delete(ctx, id); err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to delete genre", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// CountSubgenres returns the number of genres whose parent is the genre
+func (r *SQLiteRepository) CountSubgenres(ctx context.Context, id int) (int, error) {
+	return r.genres.Count(ctx, "parent_id = $1", []any{id})
+}
+
+// GetSongGenres retrieves the genres assigned to a song in name order
+func (r *SQLiteRepository) GetSongGenres(ctx context.Context, songID int) ([]models.Genre, error) {
+	r.logger.Debug("Fetching song genres", zap.Int("song_id", songID))
+	return r.genres.Find(ctx, "id IN (SELECT genre_id FROM song_genres WHERE song_id = $1)", []any{songID}, "name, id")
+}
+
+// SetSongGenres replaces the genres assigned to a song with the genres with the IDs
+func (r *SQLiteRepository) SetSongGenres(ctx context.Context, songID int, genreIDs []int) error {
+	r.logger.Debug("Assigning song genres", zap.Int("song_id", songID), zap.Ints("genre_ids", genreIDs))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "DELETE FROM song_genres WHERE song_id = $1 AND genre_id NOT IN (SELECT value FROM json_each($2))"
+	start := time.Now()
+	result, err := tx.ExecContext(ctx, query, songID, jsonList(genreIDs))
+	var rows int64
+	if err == nil {
+		rows, _ = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to unassign song genres", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	query = "INSERT INTO song_genres (song_id, genre_id) SELECT $1, value FROM json_each($2) WHERE true ON CONFLICT DO NOTHING"
+	start = time.Now()
+	result, err = tx.ExecContext(ctx, query, songID, jsonList(genreIDs))
+	if err == nil {
+		rows, _ = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to assign song genres", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetSongTitles retrieves the titles of a song in language order
+func (r *SQLiteRepository) GetSongTitles(ctx context.Context, songID int) ([]models.SongTitle, error) {
+	r.logger.Debug("Fetching song titles", zap.Int("song_id", songID))
+	return r.selectSongTitles(ctx, selectSongTitles+" WHERE song_id = $1 ORDER BY lang", songID)
+}
+
+// GetSongTitlesIn retrieves the titles of the songs with the IDs in the languages
+func (r *SQLiteRepository) GetSongTitlesIn(ctx context.Context, songIDs []int, langs []string) ([]models.SongTitle, error) {
+	r.logger.Debug("Fetching localized song titles", zap.Int("songs", len(songIDs)), zap.Strings("langs", langs))
+	return r.selectSongTitles(ctx, selectSongTitles+` WHERE song_id IN (SELECT value FROM json_each($1))
+		AND lang IN (SELECT value FROM json_each($2))`, jsonList(songIDs), jsonList(langs))
+}
+
+// selectSongTitles runs a query selecting song titles
+func (r *SQLiteRepository) selectSongTitles(ctx context.Context, query string, args ...any) ([]models.SongTitle, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	titles := []models.SongTitle{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &titles, query, args...)
+	r.track(query, start, int64(len(titles)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch song titles", zap.Error(err))
+		return nil, err
+	}
+	return titles, nil
+}
+
+// SetSongTitle adds or replaces the title of a song in the language and returns it as stored
+func (r *SQLiteRepository) SetSongTitle(ctx context.Context, songID int, lang string, title models.SongTitleInput) (models.SongTitle, error) {
+	r.logger.Debug("Setting song title", zap.Int("song_id", songID), zap.String("lang", lang))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_titles (song_id, lang, title, kind) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (song_id, lang) DO UPDATE SET title = excluded.title, kind = excluded.kind
+		RETURNING song_id, lang, title, kind, created_at, updated_at`
+	var stored models.SongTitle
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &stored, query, songID, lang, title.Title, title.Kind)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to set song title", zap.Int("song_id", songID), zap.String("lang", lang), zap.Error(err))
+		return models.SongTitle{}, err
+	}
+	return stored, nil
+}
+
+// DeleteSongTitle deletes the title of a song in the language, returning sql.ErrNoRows when there is none
+func (r *SQLiteRepository) DeleteSongTitle(ctx context.Context, songID int, lang string) error {
+	r.logger.Debug("Deleting song title", zap.Int("song_id", songID), zap.String("lang", lang))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "DELETE FROM song_titles WHERE song_id = $1 AND lang = $2"
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, songID, lang)
+	var rows int64
+	if err == nil {
+		rows, _ = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to delete song title", zap.Int("song_id", songID), zap.String("lang", lang), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// BulkTagSongs adds and removes tags on the songs with the IDs, or on every song matched by the filter
+// when ids is nil, in a single transaction. Tags that do not exist yet are created.
+func (r *SQLiteRepository) BulkTagSongs(ctx context.Context, ids []int, filter models.SongFilter, add, remove []string) (models.BulkTagResult, error) {
+	r.logger.Debug("Tagging songs in bulk", zap.Int("ids", len(ids)), zap.Strings("add", add), zap.Strings("remove", remove))
+	start := time.Now()
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return models.BulkTagResult{}, err
+	}
+	defer tx.Rollback()
+
+	songIDs, err := r.matchSongIDs(ctx, tx, ids, filter)
+	if err != nil {
+		r.logger.Error("Failed to match songs for tagging", zap.Error(err))
+		return models.BulkTagResult{}, err
+	}
+	result := models.BulkTagResult{Matched: len(songIDs), MissingIDs: missingIDs(ids, songIDs), SongIDs: songIDs}
+
+	for _, tag := range add {
+		var tagID int
+		err := tx.GetContext(ctx, &tagID, `INSERT INTO tags (name) VALUES ($1)
+			ON CONFLICT (name) DO UPDATE SET name = excluded.name RETURNING id`, tag)
+		if err != nil {
+			r.logger.Error("Failed to create tag", zap.String("tag", tag), zap.Error(err))
+			return models.BulkTagResult{}, err
+		}
+		query := "INSERT INTO song_tags (song_id, tag_id) SELECT value, $2 FROM json_each($1) WHERE true ON CONFLICT DO NOTHING"
+		assigned, err := tx.ExecContext(ctx, query, jsonList(songIDs), tagID)
+		if err != nil {
+			r.track(query, start, 0, err)
+			r.logger.Error("Failed to assign tag", zap.String("tag", tag), zap.Error(err))
+			return models.BulkTagResult{}, err
+		}
+		rows, _ := assigned.RowsAffected()
+		r.track(query, start, rows, nil)
+		result.Added += int(rows)
+	}
+
+	if len(remove) > 0 {
+		query := `DELETE FROM song_tags WHERE song_id IN (SELECT value FROM json_each($1))
+			AND tag_id IN (SELECT id FROM tags WHERE name IN (SELECT value FROM json_each($2)))`
+		removed, err := tx.ExecContext(ctx, query, jsonList(songIDs), jsonList(remove))
+		if err != nil {
+			r.track(query, start, 0, err)
+			r.logger.Error("Failed to remove tags", zap.Error(err))
+			return models.BulkTagResult{}, err
+		}
+		rows, _ := removed.RowsAffected()
+		r.track(query, start, rows, nil)
+		result.Removed = int(rows)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit bulk tag assignment", zap.Error(err))
+		return models.BulkTagResult{}, err
+	}
+	r.logger.Info("Songs tagged in bulk", zap.Int("matched", result.Matched), zap.Int("added", result.Added), zap.Int("removed", result.Removed))
+	return result, nil
+}
+
+// GetSongTags returns the tags of a song in name order, or sql.ErrNoRows when the song does not exist
+func (r *SQLiteRepository) GetSongTags(ctx context.Context, songID int) ([]string, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT t.name FROM songs s LEFT JOIN song_tags st ON st.song_id = s.id LEFT JOIN tags t ON t.id = st.tag_id
+		WHERE s.id = $1 ORDER BY t.name`
+	var names []sql.NullString
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &names, query, songID)
+	r.track(query, start, int64(len(names)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch song tags", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	// A song without tags is a single row without a name
+	tags := []string{}
+	for _, name := range names {
+		if name.Valid {
+			tags = append(tags, name.String)
+		}
+	}
+	return tags, nil
+}
+
+// matchSongIDs returns the IDs of the existing songs among ids, or of the songs matched by the filter when ids is nil
+func (r *SQLiteRepository) matchSongIDs(ctx context.Context, tx sqliteTx, ids []int, filter models.SongFilter) ([]int, error) {
+	songIDs := []int{}
+	if ids != nil {
+		err := tx.SelectContext(ctx, &songIDs, "SELECT id FROM songs WHERE id IN (SELECT value FROM json_each($1)) ORDER BY id", jsonList(ids))
+		return songIDs, err
+	}
+	where, args := sqliteSongFilterClause(filter)
+	err := tx.SelectContext(ctx, &songIDs, "SELECT s.id FROM songs s WHERE "+where+" ORDER BY s.id", args...)
+	return songIDs, err
+}
+
+// AddClassificationSuggestions queues suggestions for review. A value already suggested for the song
+// is left as is, so rejected suggestions are not proposed again.
+func (r *SQLiteRepository) AddClassificationSuggestions(ctx context.Context, songID int, source string, suggestions []models.ClassificationSuggestion) (int, error) {
+	r.logger.Debug("Adding classification suggestions", zap.Int("song_id", songID), zap.Int("count", len(suggestions)))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO classification_suggestions (song_id, kind, value, confidence, source)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT (song_id, kind, value) DO NOTHING`
+	var added int64
+	for _, suggestion := range suggestions {
+		start := time.Now()
+		result, err := r.conn(ctx).ExecContext(ctx, query, songID, suggestion.Kind, suggestion.Value, suggestion.Confidence, source)
+		r.track(query, start, 1, err)
+		if err != nil {
+			r.logger.Error("Failed to add classification suggestion", zap.Int("song_id", songID), zap.Error(err))
+			return int(added), err
+		}
+		rows, _ := result.RowsAffected()
+		added += rows
+	}
+	return int(added), nil
+}
+
+// GetClassificationSuggestions retrieves a page of suggestions with the status, oldest first
+func (r *SQLiteRepository) GetClassificationSuggestions(ctx context.Context, status string, page, limit int) ([]models.ClassificationSuggestion, error) {
+	r.logger.Debug("Fetching classification suggestions", zap.String("status", status), zap.Int("page", page), zap.Int("limit", limit))
+	suggestions, err := r.suggestions.List(ctx, "c.status = $1", []any{status}, "c.created_at, c.id", page, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch classification suggestions", zap.Error(err))
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// GetClassificationSuggestion retrieves a suggestion, returning sql.ErrNoRows when it does not exist
+func (r *SQLiteRepository) GetClassificationSuggestion(ctx context.Context, id int) (models.ClassificationSuggestion, error) {
+	return r.suggestions.Get(ctx, id)
+}
+
+// ReviewClassificationSuggestion accepts or rejects a pending suggestion,
+// returning sql.ErrNoRows when there is no pending suggestion with the ID
+func (r *SQLiteRepository) ReviewClassificationSuggestion(ctx context.Context, id int, status string) error {
+	r.logger.Debug("Reviewing classification suggestion", zap.Int("id", id), zap.String("status", status))
+	return r.suggestions.exec(ctx, `UPDATE classification_suggestions SET status = $2, reviewed_at = `+sqliteNow+`
+		WHERE id = $1 AND status = 'pending'`, id, status)
+}
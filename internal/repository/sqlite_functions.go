@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"regexp"
+	"sync"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// registerSQLiteFunctions provides the functions the statements of SQLiteRepository call on a new
+// connection. SQL NULL reaches them as a nil []byte.
+func registerSQLiteFunctions(conn *sqlite3.SQLiteConn) error {
+	functions := map[string]any{
+		"ln":                sqliteLn,
+		"power":             sqlitePower,
+		"md5":               sqliteMD5,
+		"regexp_substr":     sqliteRegexpSubstr,
+		"similarity":        sqliteSimilarity,
+		"cosine_similarity": sqliteCosineSimilarity,
+		"search_rank":       sqliteSearchRank,
+		"search_snippet":    sqliteSearchSnippet,
+	}
+	for name, function := range functions {
+		if err := conn.RegisterFunc(name, function, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqliteText returns the text of a function argument, empty for NULL
+func sqliteText(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	}
+	return ""
+}
+
+// sqliteNumber returns the number of a function argument, false for NULL and non-numeric values
+func sqliteNumber(value any) (float64, bool) {
+	switch value := value.(type) {
+	case int64:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
+
+// sqliteLn returns the natural logarithm of x, or NULL when x is NULL
+func sqliteLn(x any) any {
+	value, ok := sqliteNumber(x)
+	if !ok {
+		return nil
+	}
+	return math.Log(value)
+}
+
+// sqlitePower returns x raised to y, or NULL when either is NULL
+func sqlitePower(x, y any) any {
+	base, ok := sqliteNumber(x)
+	exponent, ok2 := sqliteNumber(y)
+	if !ok || !ok2 {
+		return nil
+	}
+	return math.Pow(base, exponent)
+}
+
+// sqliteMD5 returns the hexadecimal MD5 hash of the text, or NULL when it is NULL
+func sqliteMD5(text any) any {
+	if value, ok := text.([]byte); ok && value == nil {
+		return nil
+	}
+	sum := md5.Sum([]byte(sqliteText(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// sqliteRegexps caches the compiled patterns of regexp_substr, which are constants of the statements
+var sqliteRegexps sync.Map
+
+// sqliteRegexpSubstr returns the first match of the pattern in the text, or NULL when there is none
+func sqliteRegexpSubstr(text any, pattern string) (any, error) {
+	compiled, ok := sqliteRegexps.Load(pattern)
+	if !ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled, _ = sqliteRegexps.LoadOrStore(pattern, re)
+	}
+	match := compiled.(*regexp.Regexp).FindStringIndex(sqliteText(text))
+	if match == nil {
+		return nil, nil
+	}
+	return sqliteText(text)[match[0]:match[1]], nil
+}
+
+// sqliteCosineSimilarity returns the cosine similarity of two embeddings stored as JSON arrays, 0 when
+// they cannot be compared
+func sqliteCosineSimilarity(a, b any) float64 {
+	var first, second []float64
+	if json.Unmarshal([]byte(sqliteText(a)), &first) != nil || json.Unmarshal([]byte(sqliteText(b)), &second) != nil {
+		return 0
+	}
+	return cosineSimilarity(first, second)
+}
+
+// sqliteSimilarity is trigramSimilarity for SQL arguments, NULL being similar to nothing
+func sqliteSimilarity(a, b any) float64 {
+	return trigramSimilarity(sqliteText(a), sqliteText(b))
+}
+
+// sqliteSearchRank ranks a song, or a title of it when group and text are empty, for a web-search query:
+// title matches weigh most, then group matches, then lyrics matches. Songs not matching rank 0.
+func sqliteSearchRank(query, title, group, text any) float64 {
+	fields := [][]string{searchWords(sqliteText(title)), searchWords(sqliteText(group)), searchWords(sqliteText(text))}
+	return parseSearchQuery(sqliteText(query)).rank(fields, []float64{titleWeight, groupWeight, textWeight})
+}
+
+// sqliteSearchSnippet is searchSnippet for SQL arguments, NULL lyrics having no snippet
+func sqliteSearchSnippet(query, text any) string {
+	return searchSnippet(sqliteText(query), sqliteText(text))
+}
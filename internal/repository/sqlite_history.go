@@ -0,0 +1,657 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// sqliteIdentifier quotes an identifier for SQLite
+func sqliteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// tableColumns lists the columns of a table in their order
+func (r *SQLiteRepository) tableColumns(ctx context.Context, q queryer, table string) ([]string, error) {
+	var columns []string
+	err := q.SelectContext(ctx, &columns, "SELECT name FROM pragma_table_info($1) ORDER BY cid", table)
+	if err != nil {
+		r.logger.Error("Failed to list columns", zap.String("table", table), zap.Error(err))
+		return nil, err
+	}
+	return columns, nil
+}
+
+// snapshotData returns the expression of the JSON object holding the rows of every snapshot table, by table
+// name. songID is the placeholder of the song whose rows are held, or empty for the rows of every song.
+// SQLite cannot turn a whole row into JSON, so the columns of each table are listed.
+func (r *SQLiteRepository) snapshotData(ctx context.Context, q queryer, songID string) (string, error) {
+	parts := make([]string, 0, len(snapshotTables))
+	for _, table := range snapshotTables {
+		columns, err := r.tableColumns(ctx, q, table.name)
+		if err != nil {
+			return "", err
+		}
+		fields := make([]string, len(columns))
+		for i, column := range columns {
+			fields[i] = fmt.Sprintf("'%s', r.%s", column, sqliteIdentifier(column))
+		}
+		where := ""
+		if songID != "" {
+			key := "song_id"
+			if table.name == "songs" {
+				key = "id"
+			}
+			where = fmt.Sprintf(" WHERE r.%s = %s", key, songID)
+		}
+		// json() keeps the array JSON rather than a string once it leaves the subquery
+		parts = append(parts, fmt.Sprintf("'%s', json((SELECT json_group_array(json_object(%s)) FROM %s r%s))",
+			table.name, strings.Join(fields, ", "), sqliteIdentifier(table.name), where))
+	}
+	return "json_object(" + strings.Join(parts, ", ") + ")", nil
+}
+
+// CreateSnapshot stores a copy of the songs and the rows attached to them. The transaction holds the
+// database's write lock from its start, so a change made in the same transaction, such as a truncate, loses
+// no song the snapshot does not hold.
+func (r *SQLiteRepository) CreateSnapshot(ctx context.Context, reason string) (models.Snapshot, error) {
+	r.logger.Debug("Creating snapshot", zap.String("reason", reason))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return models.Snapshot{}, err
+	}
+	defer tx.Rollback()
+
+	data, err := r.snapshotData(ctx, tx, "")
+	if err != nil {
+		return models.Snapshot{}, err
+	}
+	query := fmt.Sprintf(`INSERT INTO snapshots (reason, song_count, data)
+		SELECT $1, (SELECT COUNT(*) FROM songs), %s
+		RETURNING %s`, data, snapshotColumns)
+	var snapshot models.Snapshot
+	start := time.Now()
+	err = tx.GetContext(ctx, &snapshot, query, reason)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to create snapshot", zap.Error(err))
+		return models.Snapshot{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return models.Snapshot{}, err
+	}
+	r.logger.Info("Snapshot created in database", zap.Int("id", snapshot.ID), zap.Int("songs", snapshot.SongCount))
+	return snapshot, nil
+}
+
+// GetSnapshots returns the snapshots, newest first
+func (r *SQLiteRepository) GetSnapshots(ctx context.Context) ([]models.Snapshot, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT " + snapshotColumns + " FROM snapshots ORDER BY id DESC"
+	snapshots := []models.Snapshot{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &snapshots, query)
+	r.track(query, start, int64(len(snapshots)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch snapshots", zap.Error(err))
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// RestoreSnapshot inserts the rows a snapshot holds back into their tables, the songs keeping their IDs, and
+// returns the number of songs restored. The songs table is expected to be empty. Rows referencing a user,
+// a tag or a genre deleted since are left out, and songs whose album was deleted are restored without it. sql.ErrNoRows
+// is returned when the snapshot does not exist.
+func (r *SQLiteRepository) RestoreSnapshot(ctx context.Context, id int) (int, error) {
+	r.logger.Debug("Restoring snapshot", zap.Int("id", id))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.GetContext(ctx, &count, "SELECT song_count FROM snapshots WHERE id = $1", id); err != nil {
+		return 0, err
+	}
+	// Songs inserted with their IDs move the AUTOINCREMENT counter past them, so new songs cannot collide
+	if err := r.restoreRows(ctx, tx, "snapshots", id); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE snapshots SET restored_at = "+sqliteNow+" WHERE id = $1", id); err != nil {
+		r.logger.Error("Failed to mark snapshot restored", zap.Error(err))
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return 0, err
+	}
+	r.logger.Info("Snapshot restored in database", zap.Int("id", id), zap.Int("songs", count))
+	return count, nil
+}
+
+// restoreRows inserts the rows of the snapshot tables held in the data column of the source table's row with
+// the ID back into their tables, leaving out those referencing rows deleted since
+func (r *SQLiteRepository) restoreRows(ctx context.Context, tx sqliteTx, source string, id int) error {
+	for _, table := range snapshotTables {
+		columns, err := r.tableColumns(ctx, tx, table.name)
+		if err != nil {
+			return err
+		}
+		names := make([]string, len(columns))
+		fields := make([]string, len(columns))
+		values := make([]string, len(columns))
+		for i, column := range columns {
+			names[i] = sqliteIdentifier(column)
+			fields[i] = fmt.Sprintf("json_extract(j.value, '$.%s') AS %s", column, names[i])
+			values[i] = "r." + names[i]
+			if expression, ok := table.expressions[column]; ok {
+				values[i] = expression
+			}
+		}
+		query := fmt.Sprintf(`INSERT INTO %[1]s (%[2]s)
+			SELECT %[3]s FROM (SELECT %[4]s FROM %[6]s s, json_each(s.data, '$.%[5]s') j WHERE s.id = $1) r`,
+			sqliteIdentifier(table.name), strings.Join(names, ", "), strings.Join(values, ", "), strings.Join(fields, ", "),
+			table.name, sqliteIdentifier(source))
+		if table.filter != "" {
+			query += " WHERE " + table.filter
+		}
+		start := time.Now()
+		result, err := tx.ExecContext(ctx, query, id)
+		var rows int64
+		if err == nil {
+			rows, _ = result.RowsAffected()
+		}
+		r.track(query, start, rows, err)
+		if err != nil {
+			r.logger.Error("Failed to restore rows", zap.String("source", source), zap.String("table", table.name), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// TrashSong moves a song to the trash: it is deleted together with the rows attached to it, which the trash
+// keeps to restore them, the same ones a snapshot holds. sql.ErrNoRows is returned when the song does not
+// exist. A song in the trash under the same ID, left from before the IDs were reset, is replaced.
+func (r *SQLiteRepository) TrashSong(ctx context.Context, id int) error {
+	r.logger.Debug("Moving song to trash", zap.Int("id", id))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	data, err := r.snapshotData(ctx, tx, "$1")
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO trashed_songs (id, group_name, song_name, data)
+		SELECT s.id, s.group_name, s.song_name, ` + data + `
+		FROM songs s WHERE s.id = $1
+		ON CONFLICT (id) DO UPDATE SET group_name = excluded.group_name, song_name = excluded.song_name,
+			data = excluded.data, deleted_at = ` + sqliteNow + `
+		RETURNING id`
+	start := time.Now()
+	err = tx.GetContext(ctx, &id, query, id)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to move song to trash", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	query = "DELETE FROM songs WHERE id = $1"
+	start = time.Now()
+	_, err = tx.ExecContext(ctx, query, id)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to delete song", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Song moved to trash in database", zap.Int("id", id))
+	return nil
+}
+
+// GetTrashedSong retrieves a song in the trash, returning sql.ErrNoRows when it is not there
+func (r *SQLiteRepository) GetTrashedSong(ctx context.Context, id int) (models.TrashedSong, error) {
+	return r.trash.Get(ctx, id)
+}
+
+// GetTrash retrieves a page of the songs in the trash, most recently deleted first, and their total number
+func (r *SQLiteRepository) GetTrash(ctx context.Context, page, limit int) ([]models.TrashedSong, int, error) {
+	r.logger.Debug("Fetching trash", zap.Int("page", page), zap.Int("limit", limit))
+	songs, err := r.trash.List(ctx, "", nil, "deleted_at DESC, id DESC", page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.trash.Count(ctx, "", nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return songs, total, nil
+}
+
+// RestoreTrashedSong puts a song in the trash back into the catalog under its ID, with the rows attached to
+// it, and takes it out of the trash. Rows referencing a user, a tag or a genre deleted since are left out,
+// and the song is restored without its album when that was deleted. The ID is expected to be free.
+// sql.ErrNoRows is returned when the song is not in the trash.
+func (r *SQLiteRepository) RestoreTrashedSong(ctx context.Context, id int) error {
+	r.logger.Debug("Restoring song from trash", zap.Int("id", id))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.GetContext(ctx, &id, "SELECT id FROM trashed_songs WHERE id = $1", id); err != nil {
+		return err
+	}
+	// Inserting the song under its ID moves the AUTOINCREMENT counter past it if it was reset below
+	if err := r.restoreRows(ctx, tx, "trashed_songs", id); err != nil {
+		return err
+	}
+	query := "DELETE FROM trashed_songs WHERE id = $1"
+	start := time.Now()
+	_, err = tx.ExecContext(ctx, query, id)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to take song out of trash", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Song restored from trash in database", zap.Int("id", id))
+	return nil
+}
+
+// DeleteTrashedSong deletes a song in the trash for good, returning sql.ErrNoRows when it is not there
+func (r *SQLiteRepository) DeleteTrashedSong(ctx context.Context, id int) error {
+	r.logger.Debug("Purging song from trash", zap.Int("id", id))
+	return r.trash.Delete(ctx, id)
+}
+
+// PurgeTrash deletes for good the songs moved to the trash before the given time and returns how many were
+// deleted
+func (r *SQLiteRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "DELETE FROM trashed_songs WHERE deleted_at < $1"
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, before)
+	var rows int64
+	if err == nil {
+		rows, err = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to purge trash", zap.Error(err))
+		return 0, err
+	}
+	return rows, nil
+}
+
+// AddSongRevision records the song's editable fields as they currently are as its next revision and
+// returns the revision number, or sql.ErrNoRows when the song does not exist. restoredFrom is the revision
+// a restore brought back, nil for other reasons.
+func (r *SQLiteRepository) AddSongRevision(ctx context.Context, songID int, reason string, restoredFrom *int) (int, error) {
+	r.logger.Debug("Recording song revision", zap.Int("song_id", songID), zap.String("reason", reason))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_revisions (song_id, revision, reason, restored_from, data)
+		SELECT s.id, COALESCE((SELECT MAX(revision) FROM song_revisions WHERE song_id = s.id), 0) + 1, $2, $3,
+			json_object('id', s.id, 'group', s.group_name, 'song', s.song_name, 'release_date', s.release_date,
+				'text', s.text, 'link', s.link, 'notes', s.notes, 'licensing_fee', s.licensing_fee,
+				'split_strategy', s.split_strategy, 'album_id', s.album_id)
+		FROM songs s WHERE s.id = $1
+		RETURNING revision`
+	var revision int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &revision, query, songID, reason, nullIfNil(restoredFrom))
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to record song revision", zap.Int("song_id", songID), zap.Error(err))
+		}
+		return 0, err
+	}
+	return revision, nil
+}
+
+// CountSongRevisions returns the number of revisions recorded for the song
+func (r *SQLiteRepository) CountSongRevisions(ctx context.Context, songID int) (int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT COUNT(*) FROM song_revisions WHERE song_id = $1"
+	var count int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &count, query, songID)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to count song revisions", zap.Int("song_id", songID), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetSongRevisions retrieves the revisions of a song, newest first
+func (r *SQLiteRepository) GetSongRevisions(ctx context.Context, songID int) ([]models.SongRevision, error) {
+	r.logger.Debug("Fetching song revisions", zap.Int("song_id", songID))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := selectSongRevisions + " WHERE song_id = $1 ORDER BY revision DESC"
+	var rows []songRevisionRow
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &rows, query, songID)
+	r.track(query, start, int64(len(rows)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch song revisions", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+	revisions := make([]models.SongRevision, 0, len(rows))
+	for _, row := range rows {
+		revision, err := row.decode()
+		if err != nil {
+			r.logger.Error("Failed to decode song revision", zap.Int("song_id", songID), zap.Int("revision", row.Revision), zap.Error(err))
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+// GetSongRevision retrieves a revision of a song, returning sql.ErrNoRows when it does not exist
+func (r *SQLiteRepository) GetSongRevision(ctx context.Context, songID, revision int) (models.SongRevision, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := selectSongRevisions + " WHERE song_id = $1 AND revision = $2"
+	var row songRevisionRow
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &row, query, songID, revision)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to fetch song revision", zap.Int("song_id", songID), zap.Int("revision", revision), zap.Error(err))
+		}
+		return models.SongRevision{}, err
+	}
+	return row.decode()
+}
+
+// BackfillLegacyRows normalizes rows written under the legacy data conventions in a single transaction:
+// missing timestamps are filled, blank optional fields become NULL and duplicate songs are merged into
+// the oldest one. With dryRun the same work is done and reported, then rolled back.
+func (r *SQLiteRepository) BackfillLegacyRows(ctx context.Context, dryRun bool) (models.BackfillReport, error) {
+	r.logger.Debug("Backfilling legacy rows", zap.Bool("dry_run", dryRun))
+	report := models.BackfillReport{DryRun: dryRun, EmptyFields: make(map[string]int)}
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return report, err
+	}
+	defer tx.Rollback()
+
+	// The update_timestamp trigger would stamp every normalized row as just updated. SQLite cannot disable a
+	// trigger, so it is dropped and created again within the transaction.
+	var trigger string
+	if err := tx.GetContext(ctx, &trigger, "SELECT sql FROM sqlite_master WHERE type = 'trigger' AND name = 'update_timestamp'"); err != nil {
+		r.logger.Error("Failed to read the timestamp trigger", zap.Error(err))
+		return report, err
+	}
+	if _, err := tx.ExecContext(ctx, "DROP TRIGGER update_timestamp"); err != nil {
+		r.logger.Error("Failed to disable the timestamp trigger", zap.Error(err))
+		return report, err
+	}
+
+	if report.MissingCreatedAt, err = r.backfillExec(ctx, tx, "UPDATE songs SET created_at = COALESCE(updated_at, "+sqliteNow+") WHERE created_at IS NULL"); err != nil {
+		return report, err
+	}
+	if report.MissingUpdatedAt, err = r.backfillExec(ctx, tx, "UPDATE songs SET updated_at = created_at WHERE updated_at IS NULL"); err != nil {
+		return report, err
+	}
+	for _, field := range backfillFields {
+		query := fmt.Sprintf("UPDATE songs SET %[1]s = NULL WHERE trim(%[1]s, ' ') = ''", field)
+		if report.EmptyFields[field], err = r.backfillExec(ctx, tx, query); err != nil {
+			return report, err
+		}
+	}
+	if report.Duplicates, err = r.mergeDuplicateSongs(ctx, tx); err != nil {
+		return report, err
+	}
+
+	if _, err := tx.ExecContext(ctx, trigger); err != nil {
+		r.logger.Error("Failed to enable the timestamp trigger", zap.Error(err))
+		return report, err
+	}
+	if dryRun {
+		r.logger.Info("Legacy rows backfill dry run finished", zap.Int("duplicates", len(report.Duplicates)))
+		return report, nil
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit legacy rows backfill", zap.Error(err))
+		return report, err
+	}
+	r.logger.Info("Legacy rows backfilled", zap.Int("duplicates", len(report.Duplicates)))
+	return report, nil
+}
+
+// backfillExec runs a normalizing statement and returns the number of rows it changed
+func (r *SQLiteRepository) backfillExec(ctx context.Context, tx sqliteTx, query string) (int, error) {
+	start := time.Now()
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to backfill legacy rows", zap.String("query", query), zap.Error(err))
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	return int(rows), err
+}
+
+// mergeDuplicateSongs merges every set of songs sharing a group and title into its oldest song,
+// which keeps its own values and takes the first known value of the others for its NULL fields.
+// Songs on legal hold are neither merged into nor merged away. The songs are grouped here rather than
+// in SQL, as SQLite's LOWER only folds ASCII letters.
+func (r *SQLiteRepository) mergeDuplicateSongs(ctx context.Context, tx sqliteTx) ([]models.DuplicateSongs, error) {
+	var rows []struct {
+		ID    int    `db:"id"`
+		Group string `db:"group_name"`
+		Song  string `db:"song_name"`
+	}
+	query := "SELECT id, group_name, song_name FROM songs WHERE NOT legal_hold ORDER BY id"
+	start := time.Now()
+	err := tx.SelectContext(ctx, &rows, query)
+	r.track(query, start, int64(len(rows)), err)
+	if err != nil {
+		r.logger.Error("Failed to find duplicate songs", zap.Error(err))
+		return nil, err
+	}
+
+	var duplicates []*models.DuplicateSongs
+	byKey := make(map[[2]string]*models.DuplicateSongs)
+	for _, row := range rows {
+		key := [2]string{strings.ToLower(strings.Trim(row.Group, " ")), strings.ToLower(strings.Trim(row.Song, " "))}
+		if duplicate, ok := byKey[key]; ok {
+			duplicate.RemovedIDs = append(duplicate.RemovedIDs, row.ID)
+			continue
+		}
+		duplicate := &models.DuplicateSongs{Group: row.Group, Song: row.Song, KeptID: row.ID}
+		byKey[key] = duplicate
+		duplicates = append(duplicates, duplicate)
+	}
+
+	merged := make([]models.DuplicateSongs, 0)
+	for _, duplicate := range duplicates {
+		if len(duplicate.RemovedIDs) == 0 {
+			continue
+		}
+		if err := r.mergeSongs(ctx, tx, duplicate.KeptID, duplicate.RemovedIDs); err != nil {
+			r.logger.Error("Failed to merge duplicate songs", zap.Int("kept_id", duplicate.KeptID), zap.Error(err))
+			return nil, err
+		}
+		merged = append(merged, *duplicate)
+	}
+	return merged, nil
+}
+
+// mergeSongs fills the NULL fields of the kept song from the removed ones, adds up their views,
+// carries over their tags and the rows of mergedSongTables and their revisions, and deletes them
+func (r *SQLiteRepository) mergeSongs(ctx context.Context, tx sqliteTx, keptID int, removedIDs []int) error {
+	removed := jsonList(removedIDs)
+	queries := []string{
+		`UPDATE songs SET
+			release_date = COALESCE(release_date, (SELECT d.release_date FROM songs d WHERE d.id IN (SELECT value FROM json_each($2)) AND d.release_date IS NOT NULL ORDER BY d.id LIMIT 1)),
+			text = COALESCE(text, (SELECT d.text FROM songs d WHERE d.id IN (SELECT value FROM json_each($2)) AND d.text IS NOT NULL ORDER BY d.id LIMIT 1)),
+			link = COALESCE(link, (SELECT d.link FROM songs d WHERE d.id IN (SELECT value FROM json_each($2)) AND d.link IS NOT NULL ORDER BY d.id LIMIT 1))
+		WHERE id = $1`,
+		`INSERT INTO song_views (song_id, views)
+		SELECT $1, SUM(views) FROM song_views WHERE song_id IN (SELECT value FROM json_each($2)) HAVING COUNT(*) > 0
+		ON CONFLICT (song_id) DO UPDATE SET views = song_views.views + excluded.views`,
+		`INSERT INTO song_view_days (song_id, day, views)
+		SELECT $1, day, SUM(views) FROM song_view_days WHERE song_id IN (SELECT value FROM json_each($2)) GROUP BY day
+		ON CONFLICT (song_id, day) DO UPDATE SET views = song_view_days.views + excluded.views`,
+		`INSERT INTO song_tags (song_id, tag_id)
+		SELECT DISTINCT $1, tag_id FROM song_tags WHERE song_id IN (SELECT value FROM json_each($2))
+		ON CONFLICT DO NOTHING`,
+	}
+	queries = append(queries, mergeSongRowsQueries("$1", "IN (SELECT value FROM json_each($2))")...)
+	for _, query := range append(queries, `DELETE FROM songs WHERE id IN (SELECT value FROM json_each($2))`) {
+		start := time.Now()
+		result, err := tx.ExecContext(ctx, query, keptID, removed)
+		var rows int64
+		if err == nil {
+			rows, _ = result.RowsAffected()
+		}
+		r.track(query, start, rows, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveVerseIndex stores the verse spans of a song's text for the delimiter, replacing its previous index.
+// Nothing is stored when the song's text no longer hashes to textHash, as another write changed it since
+// the spans were computed.
+func (r *SQLiteRepository) SaveVerseIndex(ctx context.Context, songID int, delimiter, textHash string, spans []models.VerseSpan) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	// The transaction holds the write lock, which keeps the text from changing until the index is committed
+	checkQuery := `SELECT ` + songTextHash + ` = $2 FROM songs s WHERE s.id = $1`
+	start := time.Now()
+	var current bool
+	err = tx.GetContext(ctx, &current, checkQuery, songID, textHash)
+	r.track(checkQuery, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to check song text", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	if !current {
+		r.logger.Debug("Song text changed, verse index not stored", zap.Int("song_id", songID))
+		return nil
+	}
+
+	indexQuery := `INSERT INTO song_verse_index (song_id, delimiter, content_hash, total_verses) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (song_id) DO UPDATE SET delimiter = excluded.delimiter, content_hash = excluded.content_hash,
+		total_verses = excluded.total_verses, indexed_at = ` + sqliteNow
+	if _, err := tx.ExecContext(ctx, indexQuery, songID, delimiter, textHash, len(spans)); err != nil {
+		r.track(indexQuery, start, 0, err)
+		r.logger.Error("Failed to store verse index", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM song_verses WHERE song_id = $1", songID); err != nil {
+		r.logger.Error("Failed to drop old verse spans", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	// The spans are passed as a JSON array of objects
+	spansQuery := `INSERT INTO song_verses (song_id, number, label, start_offset, length)
+		SELECT $1, json_extract(value, '$.number'), json_extract(value, '$.label'),
+			json_extract(value, '$.start'), json_extract(value, '$.length')
+		FROM json_each($2)`
+	rows := make([]map[string]any, len(spans))
+	for i, span := range spans {
+		rows[i] = map[string]any{"number": span.Number, "label": span.Label, "start": span.Start, "length": span.Length}
+	}
+	if _, err := tx.ExecContext(ctx, spansQuery, songID, jsonList(rows)); err != nil {
+		r.track(spansQuery, start, 0, err)
+		r.logger.Error("Failed to store verse spans", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit verse index", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	r.track(spansQuery, start, int64(len(spans)), nil)
+	return nil
+}
+
+// GetVerseIndex returns a song with its split strategy and, when an index of its current text is stored,
+// the delimiter it was split at and the number of verses it has
+func (r *SQLiteRepository) GetVerseIndex(ctx context.Context, songID int) (models.VerseIndex, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT s.id AS song_id, s.group_name, s.song_name, s.split_strategy, i.song_id IS NOT NULL AS indexed,
+		COALESCE(i.delimiter, '') AS delimiter, COALESCE(i.total_verses, 0) AS total_verses
+		FROM songs s LEFT JOIN song_verse_index i
+			ON i.song_id = s.id AND i.content_hash = ` + songTextHash + `
+		WHERE s.id = $1`
+	var index models.VerseIndex
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &index, query, songID)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to fetch verse index", zap.Int("song_id", songID), zap.Error(err))
+	}
+	return index, err
+}
+
+// GetIndexedVerses cuts the verses numbered from through to out of a song's text by their stored spans.
+// No verses are returned when the text changed since it was indexed.
+func (r *SQLiteRepository) GetIndexedVerses(ctx context.Context, songID int, delimiter string, from, to int) ([]models.IndexedVerse, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `WITH t AS MATERIALIZED (
+			SELECT replace(COALESCE(s.text, ''), char(13, 10), char(10)) AS text
+			FROM songs s JOIN song_verse_index i ON i.song_id = s.id
+			WHERE s.id = $1 AND i.delimiter = $2 AND i.content_hash = ` + songTextHash + `
+		)
+		SELECT v.number, v.label, substr(t.text, v.start_offset + 1, v.length) AS text
+		FROM song_verses v, t
+		WHERE v.song_id = $1 AND v.number BETWEEN $3 AND $4
+		ORDER BY v.number`
+	verses := []models.IndexedVerse{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &verses, query, songID, delimiter, from, to)
+	r.track(query, start, int64(len(verses)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch indexed verses", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+	return verses, nil
+}
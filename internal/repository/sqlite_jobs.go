@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// sqliteDayFormat is the format days are stored in
+const sqliteDayFormat = "2006-01-02"
+
+// CreateImport registers a new import so its progress can be checkpointed
+func (r *SQLiteRepository) CreateImport(ctx context.Context, id string) (models.Import, error) {
+	r.logger.Debug("Creating import", zap.String("import_id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "INSERT INTO imports (id) VALUES ($1) RETURNING *"
+	var imp models.Import
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &imp, query, id)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to create import", zap.Error(err))
+		return imp, err
+	}
+	return imp, nil
+}
+
+// GetImport retrieves an import and its checkpoint
+func (r *SQLiteRepository) GetImport(ctx context.Context, id string) (models.Import, error) {
+	r.logger.Debug("Fetching import", zap.String("import_id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT * FROM imports WHERE id = $1"
+	var imp models.Import
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &imp, query, id)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Warn("Failed to fetch import", zap.String("import_id", id), zap.Error(err))
+		return imp, err
+	}
+	return imp, nil
+}
+
+// ImportBatch writes a batch of imported songs and advances the import checkpoint in one transaction,
+// so an interrupted import resumes exactly after the last committed batch.
+// Existing songs only have their release date, text and link replaced by non-empty values.
+func (r *SQLiteRepository) ImportBatch(ctx context.Context, importID string, songs []models.ImportSong, checkpointRow, failed int) ([]int, error) {
+	r.logger.Debug("Writing import batch", zap.String("import_id", importID), zap.Int("songs", len(songs)), zap.Int("checkpoint_row", checkpointRow))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	updateQuery := `UPDATE songs SET release_date = COALESCE(NULLIF($2, ''), release_date),
+		text = COALESCE(NULLIF($3, ''), text), link = COALESCE(NULLIF($4, ''), link),
+		enriched_at = COALESCE($5, enriched_at) WHERE id = $1`
+	start := time.Now()
+	ids := make([]int, len(songs))
+	var created []int
+	var inputs []models.SongInput
+	for i, song := range songs {
+		if song.ExistingID != 0 {
+			if _, err := tx.ExecContext(ctx, updateQuery, song.ExistingID, song.ReleaseDate, song.Text, song.Link, song.EnrichedAt); err != nil {
+				r.track(updateQuery, start, int64(i), err)
+				r.logger.Error("Failed to update imported song", zap.Int("row", song.Row), zap.Error(err))
+				return nil, err
+			}
+			ids[i] = song.ExistingID
+			continue
+		}
+		created = append(created, i)
+		inputs = append(inputs, models.SongInput{Group: song.Group, Song: song.Song, ReleaseDate: song.ReleaseDate,
+			Text: song.Text, Link: song.Link, EnrichedAt: song.EnrichedAt})
+	}
+	if len(songs) > len(created) {
+		r.track(updateQuery, start, int64(len(songs)-len(created)), nil)
+	}
+	// The new songs are written at once, which is what makes large imports fast
+	createdIDs, err := r.copySongs(ctx, tx, inputs)
+	if err != nil {
+		r.logger.Error("Failed to insert imported songs", zap.Int("first_row", songs[created[0]].Row), zap.Error(err))
+		return nil, err
+	}
+	for i, index := range created {
+		ids[index] = createdIDs[i]
+	}
+
+	checkpointQuery := `UPDATE imports SET checkpoint_row = $2, created = created + $3, updated = updated + $4,
+		failed = failed + $5, updated_at = ` + sqliteNow + ` WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, checkpointQuery, importID, checkpointRow, len(created), len(songs)-len(created), failed); err != nil {
+		r.track(checkpointQuery, start, 0, err)
+		r.logger.Error("Failed to checkpoint import", zap.Error(err))
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit import batch", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Import batch written", zap.String("import_id", importID), zap.Int("created", len(created)), zap.Int("updated", len(songs)-len(created)))
+	return ids, nil
+}
+
+// FinishImport records the final status of an import
+func (r *SQLiteRepository) FinishImport(ctx context.Context, id, status string) (models.Import, error) {
+	r.logger.Debug("Finishing import", zap.String("import_id", id), zap.String("status", status))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "UPDATE imports SET status = $2, updated_at = " + sqliteNow + " WHERE id = $1 RETURNING *"
+	var imp models.Import
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &imp, query, id, status)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to finish import", zap.String("import_id", id), zap.Error(err))
+		return imp, err
+	}
+	return imp, nil
+}
+
+// CreateJob registers a queued job, owned by the user with the ID unless it is nil
+func (r *SQLiteRepository) CreateJob(ctx context.Context, id, kind string, ownerID *int) (models.Job, error) {
+	r.logger.Debug("Creating job", zap.String("job_id", id), zap.String("kind", kind))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "INSERT INTO jobs (id, kind, owner_id) VALUES ($1, $2, $3) RETURNING *"
+	var job models.Job
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &job, query, id, kind, ownerID)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to create job", zap.Error(err))
+		return job, err
+	}
+	return job, nil
+}
+
+// GetJob retrieves a job and its progress
+func (r *SQLiteRepository) GetJob(ctx context.Context, id string) (models.Job, error) {
+	r.logger.Debug("Fetching job", zap.String("job_id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT * FROM jobs WHERE id = $1"
+	var job models.Job
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &job, query, id)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch job", zap.String("job_id", id), zap.Error(err))
+	}
+	return job, err
+}
+
+// StartJob marks a queued job running
+func (r *SQLiteRepository) StartJob(ctx context.Context, id string) error {
+	query := `UPDATE jobs SET status = $2, started_at = ` + sqliteNow + `, updated_at = ` + sqliteNow + ` WHERE id = $1`
+	return r.execJob(ctx, id, query, id, models.JobRunning)
+}
+
+// UpdateJobProgress stores the progress counters of a running job
+func (r *SQLiteRepository) UpdateJobProgress(ctx context.Context, id string, total, processed, failed int) error {
+	query := `UPDATE jobs SET total = $2, processed = $3, failed = $4, updated_at = ` + sqliteNow + ` WHERE id = $1`
+	return r.execJob(ctx, id, query, id, total, processed, failed)
+}
+
+// FinishJob stores the final status, counters and outcome of a job. An empty result or message is stored as NULL.
+func (r *SQLiteRepository) FinishJob(ctx context.Context, id, status string, total, processed, failed int, result []byte, message string) error {
+	query := `UPDATE jobs SET status = $2, total = $3, processed = $4, failed = $5, result = $6, error = NULLIF($7, ''),
+		finished_at = ` + sqliteNow + `, updated_at = ` + sqliteNow + ` WHERE id = $1`
+	// The result is stored as a BLOB, which is read back as the bytes json.RawMessage expects
+	var resultArg any
+	if len(result) > 0 {
+		resultArg = result
+	}
+	return r.execJob(ctx, id, query, id, status, total, processed, failed, resultArg, message)
+}
+
+// FailUnfinishedJobs marks failed the jobs left queued or running, whose work was lost when the process stopped
+func (r *SQLiteRepository) FailUnfinishedJobs(ctx context.Context, message string) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `UPDATE jobs SET status = $1, error = $2, finished_at = ` + sqliteNow + `, updated_at = ` + sqliteNow + `
+		WHERE status IN ($3, $4)`
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, models.JobFailed, message, models.JobQueued, models.JobRunning)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to fail unfinished jobs", zap.Error(err))
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	return rows, err
+}
+
+// execJob runs an update of a single job, returning sql.ErrNoRows when it does not exist
+func (r *SQLiteRepository) execJob(ctx context.Context, id, query string, args ...any) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to update job", zap.String("job_id", id), zap.Error(err))
+		return err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// IncrementProviderUsage counts a call to the provider on the day and returns the day's total
+func (r *SQLiteRepository) IncrementProviderUsage(ctx context.Context, provider string, day time.Time) (int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO provider_usage (provider, day, calls) VALUES ($1, $2, 1)
+		ON CONFLICT (provider, day) DO UPDATE SET calls = provider_usage.calls + 1 RETURNING calls`
+	var calls int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &calls, query, provider, day.UTC().Format(sqliteDayFormat))
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to increment provider usage", zap.String("provider", provider), zap.Error(err))
+		return 0, err
+	}
+	return calls, nil
+}
+
+// GetProviderUsage returns the number of calls counted for the provider on the day
+func (r *SQLiteRepository) GetProviderUsage(ctx context.Context, provider string, day time.Time) (int, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT calls FROM provider_usage WHERE provider = $1 AND day = $2"
+	var calls int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &calls, query, provider, day.UTC().Format(sqliteDayFormat))
+	r.track(query, start, 1, err)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to fetch provider usage", zap.String("provider", provider), zap.Error(err))
+		return 0, err
+	}
+	return calls, nil
+}
+
+// AddAPICapture stores a captured external API exchange and drops all but the newest keep captures
+func (r *SQLiteRepository) AddAPICapture(ctx context.Context, capture models.APICapture, keep int) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	// The headers are stored as BLOBs, which are read back as the bytes json.RawMessage expects
+	query := `INSERT INTO api_captures (provider, method, url, request_headers, request_body, status,
+			response_headers, response_body, duration_ms, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	var responseHeaders any
+	if capture.ResponseHeaders != nil {
+		responseHeaders = []byte(*capture.ResponseHeaders)
+	}
+	var id int
+	start := time.Now()
+	err = tx.GetContext(ctx, &id, query, capture.Provider, capture.Method, capture.URL, []byte(capture.RequestHeaders),
+		capture.RequestBody, capture.Status, responseHeaders, capture.ResponseBody, capture.DurationMs, capture.Error)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to store API capture", zap.String("provider", capture.Provider), zap.Error(err))
+		return err
+	}
+	// The new capture counts toward keep through its ID
+	query = "DELETE FROM api_captures WHERE id <= $1"
+	start = time.Now()
+	result, err := tx.ExecContext(ctx, query, id-keep)
+	var rows int64
+	if err == nil {
+		rows, err = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to drop old API captures", zap.Error(err))
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAPICaptures returns the newest captured exchanges, of every provider when provider is empty
+func (r *SQLiteRepository) GetAPICaptures(ctx context.Context, provider string, limit int) ([]models.APICapture, error) {
+	r.logger.Debug("Fetching API captures", zap.String("provider", provider), zap.Int("limit", limit))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT * FROM api_captures WHERE ($1 = '' OR provider = $1) ORDER BY id DESC LIMIT $2"
+	captures := []models.APICapture{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &captures, query, provider, limit)
+	r.track(query, start, int64(len(captures)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch API captures", zap.Error(err))
+		return nil, err
+	}
+	return captures, nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// sqliteSongContentHash is the SQL counterpart of SongContentHash
+const sqliteSongContentHash = `md5(s.group_name || char(10) || s.song_name || char(10) || COALESCE(s.text, ''))`
+
+// SearchSongs finds songs whose title, in any language, group or lyrics match the query, using web-search syntax
+// ("quoted phrases", OR, -excluded). Title matches rank above group matches, which rank above lyrics matches.
+// Each result carries a lyrics snippet with the matches wrapped in <mark> tags.
+// Without an index to search, every song is ranked.
+func (r *SQLiteRepository) SearchSongs(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	r.logger.Debug("Searching songs", zap.String("query", query), zap.Int("limit", limit))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	sqlQuery := `SELECT ` + sqliteSongColumns + `, COALESCE(v.views, 0) AS views,
+		max(search_rank($1, s.song_name, s.group_name, s.text), COALESCE((SELECT MAX(search_rank($1, t.title, '', ''))
+			FROM song_titles t WHERE t.song_id = s.id), 0)) AS score,
+		search_snippet($1, s.text) AS snippet
+		FROM songs s
+		LEFT JOIN song_views v ON v.song_id = s.id
+		WHERE score > 0
+		ORDER BY score DESC, s.id LIMIT $2`
+	results := []models.SearchResult{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &results, sqlQuery, query, limit)
+	r.track(sqlQuery, start, int64(len(results)), err)
+	if err != nil {
+		r.logger.Error("Failed to search songs", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Songs searched in database", zap.Int("count", len(results)))
+	return results, nil
+}
+
+// HasSongEmbeddings reports whether song embeddings are stored, which they always are in SQLite
+func (r *SQLiteRepository) HasSongEmbeddings(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// GetSongsNeedingEmbedding retrieves up to limit songs without an embedding from the model,
+// or whose content changed since it was computed
+func (r *SQLiteRepository) GetSongsNeedingEmbedding(ctx context.Context, model string, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching songs needing embeddings", zap.String("model", model), zap.Int("limit", limit))
+	songs, err := r.songs.List(ctx, `NOT EXISTS (SELECT 1 FROM song_embeddings e
+		WHERE e.song_id = s.id AND e.model = $1 AND e.content_hash = `+sqliteSongContentHash+`)`, []any{model}, "", 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch songs needing embeddings", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
+
+// SaveSongEmbedding stores the embedding of a song, as a JSON array, replacing any previous one
+func (r *SQLiteRepository) SaveSongEmbedding(ctx context.Context, songID int, model, contentHash string, embedding []float32) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_embeddings (song_id, model, content_hash, embedding) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (song_id) DO UPDATE SET model = excluded.model, content_hash = excluded.content_hash,
+		embedding = excluded.embedding, updated_at = ` + sqliteNow
+	start := time.Now()
+	_, err := r.conn(ctx).ExecContext(ctx, query, songID, model, contentHash, vectorLiteral(embedding))
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to save song embedding", zap.Int("song_id", songID), zap.Error(err))
+	}
+	return err
+}
+
+// SearchSongsSemantic ranks songs embedded with the model by cosine similarity to the query embedding.
+// When keywordWeight is positive, the similarity is blended with the full-text rank of the keywords:
+// score = (1 - keywordWeight) * similarity + keywordWeight * rank, both in [0, 1].
+func (r *SQLiteRepository) SearchSongsSemantic(ctx context.Context, model string, embedding []float32, keywords string, keywordWeight float64, limit int) ([]models.SearchResult, error) {
+	r.logger.Debug("Searching songs semantically", zap.String("model", model), zap.Float64("keyword_weight", keywordWeight))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT ` + sqliteSongColumns + `, COALESCE(v.views, 0) AS views,
+		(1 - $3) * cosine_similarity(e.embedding, $2) + $3 * search_rank($4, s.song_name, s.group_name, s.text) AS score
+		FROM songs s
+		JOIN song_embeddings e ON e.song_id = s.id AND e.model = $1
+		LEFT JOIN song_views v ON v.song_id = s.id
+		ORDER BY score DESC, s.id LIMIT $5`
+	results := []models.SearchResult{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &results, query, model, vectorLiteral(embedding), keywordWeight, keywords, limit)
+	r.track(query, start, int64(len(results)), err)
+	if err != nil {
+		r.logger.Error("Failed to search songs semantically", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Semantic search finished", zap.Int("count", len(results)))
+	return results, nil
+}
+
+// HasSearchSuggestions reports whether search suggestions are available, which they always are in SQLite
+func (r *SQLiteRepository) HasSearchSuggestions(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// SimilarSongNames retrieves up to limit group and song names within the trigram similarity threshold of
+// the query, closest first
+func (r *SQLiteRepository) SimilarSongNames(ctx context.Context, query string, limit int) ([]models.SearchSuggestion, error) {
+	r.logger.Debug("Fetching similar song names", zap.String("query", query), zap.Int("limit", limit))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	sqlQuery := `SELECT query, source, score FROM (
+			SELECT DISTINCT group_name AS query, $3 AS source, similarity(group_name, $1) AS score
+			FROM songs WHERE similarity(group_name, $1) >= $5
+			UNION ALL
+			SELECT DISTINCT song_name, $4, similarity(song_name, $1)
+			FROM songs WHERE similarity(song_name, $1) >= $5
+		) names
+		ORDER BY score DESC, query LIMIT $2`
+	suggestions := []models.SearchSuggestion{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &suggestions, sqlQuery, query, limit, models.SuggestionGroup, models.SuggestionSong, similarityThreshold)
+	r.track(sqlQuery, start, int64(len(suggestions)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch similar song names", zap.Error(err))
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// SimilarSearchTerms maps each word that is not a known lyric term to the most similar one within the
+// trigram similarity threshold, preferring the terms found in more songs. Words without a match are left out.
+// The suggestions carry the term and its similarity to the word.
+func (r *SQLiteRepository) SimilarSearchTerms(ctx context.Context, words []string) (map[string]models.SearchSuggestion, error) {
+	r.logger.Debug("Fetching similar search terms", zap.Strings("words", words))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT word, term, score FROM (
+			SELECT w.value AS word, t.term, similarity(t.term, w.value) AS score,
+				ROW_NUMBER() OVER (PARTITION BY w.value ORDER BY similarity(t.term, w.value) DESC, t.songs DESC, t.term) AS n
+			FROM json_each($1) w
+			JOIN search_terms t ON similarity(t.term, w.value) >= $2
+			WHERE NOT EXISTS (SELECT 1 FROM search_terms k WHERE k.term = w.value)
+		) WHERE n = 1`
+	var rows []struct {
+		Word  string  `db:"word"`
+		Term  string  `db:"term"`
+		Score float64 `db:"score"`
+	}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &rows, query, jsonList(words), similarityThreshold)
+	r.track(query, start, int64(len(rows)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch similar search terms", zap.Error(err))
+		return nil, err
+	}
+	terms := make(map[string]models.SearchSuggestion, len(rows))
+	for _, row := range rows {
+		terms[row.Word] = models.SearchSuggestion{Query: row.Term, Source: models.SuggestionLyrics, Score: row.Score}
+	}
+	return terms, nil
+}
+
+// RefreshSearchTerms recomputes the lyric terms offered as search suggestions: the words of at least
+// searchTermMinLength letters found in the most songs. SQLite has no text statistics, so the lyrics are
+// read and their words counted here.
+func (r *SQLiteRepository) RefreshSearchTerms(ctx context.Context) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "SELECT COALESCE(text, '') FROM songs"
+	start := time.Now()
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to read lyrics", zap.Error(err))
+		return err
+	}
+	counter := make(searchTermCounter)
+	var read int64
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			rows.Close()
+			r.logger.Error("Failed to read lyrics", zap.Error(err))
+			return err
+		}
+		read++
+		counter.add(text)
+	}
+	err = rows.Err()
+	rows.Close()
+	r.track(query, start, read, err)
+	if err != nil {
+		r.logger.Error("Failed to read lyrics", zap.Error(err))
+		return err
+	}
+
+	terms, counts := counter.top()
+
+	start = time.Now()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM search_terms"); err != nil {
+		r.logger.Error("Failed to clear search terms", zap.Error(err))
+		return err
+	}
+	query = `INSERT INTO search_terms (term, songs)
+		SELECT t.value, c.value FROM json_each($1) t JOIN json_each($2) c ON c.key = t.key`
+	_, err = tx.ExecContext(ctx, query, jsonList(terms), jsonList(counts))
+	r.track(query, start, int64(len(terms)), err)
+	if err != nil {
+		r.logger.Error("Failed to refresh search terms", zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit search terms", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// newTestSQLiteRepository migrates a fresh database file and opens a repository on it
+func newTestSQLiteRepository(t *testing.T) *SQLiteRepository {
+	path := filepath.Join(t.TempDir(), "music_library.db")
+	m, err := migrate.New("file://../../migrations/sqlite", "sqlite3://"+path)
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+	m.Close()
+
+	db, err := OpenSQLite(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return NewSQLiteRepository(db, zap.NewNop())
+}
+
+func TestSQLiteMigrationsDown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "music_library.db")
+	m, err := migrate.New("file://../../migrations/sqlite", "sqlite3://"+path)
+	require.NoError(t, err)
+	defer m.Close()
+	require.NoError(t, m.Up())
+	require.NoError(t, m.Down())
+}
+
+func TestSQLiteSongs(t *testing.T) {
+	repo := newTestSQLiteRepository(t)
+	ctx := context.Background()
+
+	id, err := repo.AddSong(ctx, "Muse", "Supermassive Black Hole", "16.07.2006", "Ooh baby, don't you know I suffer?", "https://example.com", nil)
+	require.NoError(t, err)
+
+	song, err := repo.GetSongByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Muse", song.Group)
+	assert.Equal(t, "16.07.2006", *song.ReleaseDate)
+	assert.False(t, song.CreatedAt.IsZero())
+
+	require.NoError(t, repo.UpdateSong(ctx, id, "Muse", "Starlight", "03.09.2006", "Far away", "https://example.com"))
+	song, err = repo.GetSongByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Starlight", song.Song)
+
+	songs, err := repo.GetSongs(ctx, models.SongFilter{Group: "muse"}, "", 1, 10)
+	require.NoError(t, err)
+	assert.Len(t, songs, 1)
+
+	rock, err := repo.CreateGenre(ctx, models.GenreInput{Name: "Rock"})
+	require.NoError(t, err)
+	require.NoError(t, repo.SetSongGenres(ctx, id, []int{rock}))
+	facets, err := repo.GetSongFacets(ctx, models.SongFilter{}, []string{"year", "genre"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.FacetBucket{{Value: "2006", Count: 1}}, facets["year"])
+	assert.Equal(t, []models.FacetBucket{{Value: "Rock", Count: 1}}, facets["genre"])
+
+	artist, err := repo.GetArtistByName(ctx, "Muse")
+	require.NoError(t, err, "adding a song creates its artist")
+	assert.Equal(t, "Muse", artist.Name)
+
+	require.NoError(t, repo.DeleteSong(ctx, id))
+	_, err = repo.GetSongByID(ctx, id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestSQLiteSearch(t *testing.T) {
+	repo := newTestSQLiteRepository(t)
+	ctx := context.Background()
+
+	_, err := repo.AddSong(ctx, "Muse", "Starlight", "03.09.2006", "Far away, the ship is taking me far away", "", nil)
+	require.NoError(t, err)
+	_, err = repo.AddSong(ctx, "Queen", "Bohemian Rhapsody", "31.10.1975", "Is this the real life? Is this just fantasy?", "", nil)
+	require.NoError(t, err)
+
+	results, err := repo.SearchSongs(ctx, "fantasy", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Bohemian Rhapsody", results[0].Song.Song)
+	assert.Contains(t, results[0].Snippet, "<mark>fantasy</mark>")
+
+	results, err = repo.SearchSongs(ctx, "starlight OR queen", 10)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	require.NoError(t, repo.RefreshSearchTerms(ctx))
+	terms, err := repo.SimilarSearchTerms(ctx, []string{"fantasi"})
+	require.NoError(t, err)
+	assert.Equal(t, "fantasy", terms["fantasi"].Query)
+
+	names, err := repo.SimilarSongNames(ctx, "Bohemian Rapsody", 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, names)
+	assert.Equal(t, "Bohemian Rhapsody", names[0].Query)
+}
+
+func TestSQLiteTrashAndSnapshots(t *testing.T) {
+	repo := newTestSQLiteRepository(t)
+	ctx := context.Background()
+
+	id, err := repo.AddSong(ctx, "Muse", "Starlight", "03.09.2006", "Far away", "", nil)
+	require.NoError(t, err)
+
+	snapshot, err := repo.CreateSnapshot(ctx, "test")
+	require.NoError(t, err)
+
+	require.NoError(t, repo.TrashSong(ctx, id))
+	trash, total, err := repo.GetTrash(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, trash, 1)
+	require.NoError(t, repo.RestoreTrashedSong(ctx, id))
+	_, err = repo.GetSongByID(ctx, id)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.DeleteSong(ctx, id))
+	restored, err := repo.RestoreSnapshot(ctx, snapshot.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored)
+	song, err := repo.GetSongByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Starlight", song.Song)
+}
+
+// TestSQLiteBackfillMergesSongRows merges two duplicate songs and checks the rows belonging to the removed
+// one are carried over to the kept one rather than lost to the cascade of its delete
+func TestSQLiteBackfillMergesSongRows(t *testing.T) {
+	repo := newTestSQLiteRepository(t)
+	ctx := context.Background()
+
+	keptID, err := repo.AddSong(ctx, "Muse", "Starlight", "03.09.2006", "Far away", "", nil)
+	require.NoError(t, err)
+	removedID, err := repo.AddSong(ctx, "muse", "Starlight ", "", "", "https://example.com", nil)
+	require.NoError(t, err)
+	alice, err := repo.CreateUser(ctx, "alice", "hash", models.RoleViewer)
+	require.NoError(t, err)
+	bob, err := repo.CreateUser(ctx, "bob", "hash", models.RoleViewer)
+	require.NoError(t, err)
+	rock, err := repo.CreateGenre(ctx, models.GenreInput{Name: "Rock"})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.RateSong(ctx, alice, keptID, 5))
+	require.NoError(t, repo.RateSong(ctx, alice, removedID, 1))
+	require.NoError(t, repo.RateSong(ctx, bob, removedID, 4))
+	require.NoError(t, repo.SetSongGenres(ctx, removedID, []int{rock}))
+	_, err = repo.SetSongTitle(ctx, keptID, "fr", models.SongTitleInput{Title: "Lumière des étoiles", Kind: "translation"})
+	require.NoError(t, err)
+	_, err = repo.SetSongTitle(ctx, removedID, "fr", models.SongTitleInput{Title: "Starlight", Kind: "translation"})
+	require.NoError(t, err)
+	_, err = repo.SetSongTitle(ctx, removedID, "ja", models.SongTitleInput{Title: "スターライト", Kind: "translation"})
+	require.NoError(t, err)
+	_, err = repo.AddSongRevision(ctx, keptID, "update", nil)
+	require.NoError(t, err)
+	_, err = repo.AddSongRevision(ctx, removedID, "update", nil)
+	require.NoError(t, err)
+
+	report, err := repo.BackfillLegacyRows(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, report.Duplicates, 1)
+	assert.Equal(t, []int{removedID}, report.Duplicates[0].RemovedIDs)
+	_, err = repo.GetSongByID(ctx, removedID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	rating, err := repo.GetSongRating(ctx, alice, keptID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, *rating.Rating, "the kept song's own rating wins")
+	assert.Equal(t, 2, rating.RatingCount)
+	rating, err = repo.GetSongRating(ctx, bob, keptID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, *rating.Rating)
+
+	genres, err := repo.GetSongGenres(ctx, keptID)
+	require.NoError(t, err)
+	require.Len(t, genres, 1)
+	assert.Equal(t, "Rock", genres[0].Name)
+
+	titles, err := repo.GetSongTitles(ctx, keptID)
+	require.NoError(t, err)
+	require.Len(t, titles, 2)
+	assert.Equal(t, "Lumière des étoiles", titles[0].Title)
+	assert.Equal(t, "スターライト", titles[1].Title)
+
+	revisions, err := repo.GetSongRevisions(ctx, keptID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, 2, revisions[0].Revision, "the carried over revision is numbered after the kept song's")
+}
+
+func TestSQLiteFunctions(t *testing.T) {
+	assert.InDelta(t, 1.0, trigramSimilarity("starlight", "starlight"), 1e-9)
+	assert.Zero(t, trigramSimilarity("abc", "xyz"))
+	assert.InDelta(t, 1.0, sqliteCosineSimilarity("[1,0]", "[2,0]"), 1e-9)
+}
+
+func TestSQLiteWebhooksAndJobs(t *testing.T) {
+	repo := newTestSQLiteRepository(t)
+	ctx := context.Background()
+
+	webhook, err := repo.CreateWebhook(ctx, "https://example.com/hook", []string{"song.created"}, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"song.created"}, []string(webhook.Events))
+
+	queued, err := repo.EnqueueWebhookDeliveries(ctx, "song.created", []byte(`{"id":1}`))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, queued)
+	queued, err = repo.EnqueueWebhookDeliveries(ctx, "song.deleted", []byte(`{"id":1}`))
+	require.NoError(t, err)
+	assert.Zero(t, queued, "webhooks only receive the events they subscribed to")
+
+	due, err := repo.ClaimWebhookDeliveries(ctx, 10, time.Minute, nil)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, webhook.URL, due[0].URL)
+	assert.JSONEq(t, `{"id":1}`, string(due[0].Payload))
+	due, err = repo.ClaimWebhookDeliveries(ctx, 10, time.Minute, nil)
+	require.NoError(t, err)
+	assert.Empty(t, due, "claimed deliveries are leased")
+
+	owner, err := repo.CreateUser(ctx, "alice", "hash", models.RoleEditor)
+	require.NoError(t, err)
+	job, err := repo.CreateJob(ctx, "job-1", "import", &owner)
+	require.NoError(t, err)
+	assert.Equal(t, &owner, job.OwnerID)
+	require.NoError(t, repo.StartJob(ctx, job.ID))
+	require.NoError(t, repo.FinishJob(ctx, job.ID, models.JobCompleted, 2, 2, 0, []byte(`{"imported":2}`), ""))
+	job, err = repo.GetJob(ctx, job.ID)
+	require.NoError(t, err)
+	require.NotNil(t, job.Result)
+	assert.JSONEq(t, `{"imported":2}`, string(*job.Result))
+	assert.NotNil(t, job.FinishedAt)
+
+	day := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for want := 1; want <= 2; want++ {
+		used, err := repo.IncrementProviderUsage(ctx, "external", day)
+		require.NoError(t, err)
+		assert.Equal(t, want, used)
+	}
+}
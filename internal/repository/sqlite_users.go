@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// CreateUser adds a user account with the role and returns its ID, or sql.ErrNoRows when the username is taken
+func (r *SQLiteRepository) CreateUser(ctx context.Context, username, passwordHash, role string) (int, error) {
+	r.logger.Debug("Creating user", zap.String("username", username), zap.String("role", role))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO users (username, password_hash, role) VALUES ($1, $2, $3)
+		ON CONFLICT (username) DO NOTHING RETURNING id`
+	var id int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &id, query, username, passwordHash, role)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to create user", zap.String("username", username), zap.Error(err))
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetUserByUsername retrieves a user account, returning sql.ErrNoRows when it does not exist
+func (r *SQLiteRepository) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	users, err := r.users.Find(ctx, "username = $1", []any{username}, "id")
+	if err != nil {
+		return models.User{}, err
+	}
+	if len(users) == 0 {
+		return models.User{}, sql.ErrNoRows
+	}
+	return users[0], nil
+}
+
+// GetUserByID retrieves a user account, returning sql.ErrNoRows when it does not exist
+func (r *SQLiteRepository) GetUserByID(ctx context.Context, id int) (models.User, error) {
+	return r.users.Get(ctx, id)
+}
+
+// GetUsers retrieves a page of user accounts ordered by ID
+func (r *SQLiteRepository) GetUsers(ctx context.Context, page, limit int) ([]models.User, error) {
+	r.logger.Debug("Fetching users", zap.Int("page", page), zap.Int("limit", limit))
+	return r.users.List(ctx, "", nil, "id", page, limit)
+}
+
+// SetUserRole changes the role of a user, returning sql.ErrNoRows when the user does not exist
+func (r *SQLiteRepository) SetUserRole(ctx context.Context, id int, role string) error {
+	r.logger.Debug("Setting user role", zap.Int("id", id), zap.String("role", role))
+	return r.users.Update(ctx, id, map[string]any{"role": role})
+}
+
+// GetPreferences retrieves the preferences of the user, returning sql.ErrNoRows when none were saved
+func (r *SQLiteRepository) GetPreferences(ctx context.Context, userID int) (models.Preferences, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT page_size, sort, language, explicit_filter, api_compat, updated_at FROM user_preferences WHERE user_id = $1"
+	var preferences models.Preferences
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &preferences, query, userID)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch preferences", zap.Int("user_id", userID), zap.Error(err))
+	}
+	return preferences, err
+}
+
+// SavePreferences creates or replaces the preferences of the user
+func (r *SQLiteRepository) SavePreferences(ctx context.Context, userID int, preferences models.Preferences) error {
+	r.logger.Debug("Saving preferences", zap.Int("user_id", userID))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO user_preferences (user_id, page_size, sort, language, explicit_filter, api_compat, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, ` + sqliteNow + `)
+		ON CONFLICT (user_id) DO UPDATE SET page_size = excluded.page_size, sort = excluded.sort,
+			language = excluded.language, explicit_filter = excluded.explicit_filter,
+			api_compat = excluded.api_compat, updated_at = excluded.updated_at`
+	start := time.Now()
+	_, err := r.conn(ctx).ExecContext(ctx, query, userID, preferences.PageSize, preferences.Sort, preferences.Language,
+		preferences.ExplicitFilter, preferences.APICompat)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to save preferences", zap.Int("user_id", userID), zap.Error(err))
+	}
+	return err
+}
+
+// GetSongOverride retrieves the user's override of the song, returning sql.ErrNoRows when there is none
+func (r *SQLiteRepository) GetSongOverride(ctx context.Context, userID, songID int) (models.SongOverride, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT song_id, text, created_at, updated_at FROM song_overrides WHERE user_id = $1 AND song_id = $2"
+	var override models.SongOverride
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &override, query, userID, songID)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch song override", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return override, err
+}
+
+// GetSongOverrides retrieves the user's overrides of any of the songs
+func (r *SQLiteRepository) GetSongOverrides(ctx context.Context, userID int, songIDs []int) ([]models.SongOverride, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT song_id, text, created_at, updated_at FROM song_overrides
+		WHERE user_id = $1 AND song_id IN (SELECT value FROM json_each($2))`
+	overrides := []models.SongOverride{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &overrides, query, userID, jsonList(songIDs))
+	r.track(query, start, int64(len(overrides)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch song overrides", zap.Int("user_id", userID), zap.Error(err))
+	}
+	return overrides, err
+}
+
+// SaveSongOverride creates or replaces the user's override of the song, returning sql.ErrNoRows when the
+// song does not exist
+func (r *SQLiteRepository) SaveSongOverride(ctx context.Context, userID, songID int, text string) (models.SongOverride, error) {
+	r.logger.Debug("Saving song override", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_overrides (user_id, song_id, text)
+		SELECT $1, id, $3 FROM songs WHERE id = $2
+		ON CONFLICT (user_id, song_id) DO UPDATE SET text = excluded.text, updated_at = ` + sqliteNow + `
+		RETURNING song_id, text, created_at, updated_at`
+	var override models.SongOverride
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &override, query, userID, songID, text)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to save song override", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return override, err
+}
+
+// DeleteSongOverride discards the user's override of the song, returning sql.ErrNoRows when there is none
+func (r *SQLiteRepository) DeleteSongOverride(ctx context.Context, userID, songID int) error {
+	r.logger.Debug("Deleting song override", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return r.deleteUserSongRow(ctx, "DELETE FROM song_overrides WHERE user_id = $1 AND song_id = $2", userID, songID)
+}
+
+// GetSongRating retrieves the user's rating of the song together with its average rating, returning
+// sql.ErrNoRows when the song does not exist
+func (r *SQLiteRepository) GetSongRating(ctx context.Context, userID, songID int) (models.SongRating, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT s.id AS song_id,
+			(SELECT sr.rating FROM song_ratings sr WHERE sr.user_id = $1 AND sr.song_id = s.id) AS rating,
+			(SELECT AVG(sr.rating) FROM song_ratings sr WHERE sr.song_id = s.id) AS average_rating,
+			(SELECT COUNT(*) FROM song_ratings sr WHERE sr.song_id = s.id) AS rating_count
+		FROM songs s WHERE s.id = $2`
+	var rating models.SongRating
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &rating, query, userID, songID)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch song rating", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return rating, err
+}
+
+// RateSong creates or replaces the user's rating of the song, returning sql.ErrNoRows when the song does
+// not exist
+func (r *SQLiteRepository) RateSong(ctx context.Context, userID, songID, rating int) error {
+	r.logger.Debug("Rating song", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Int("rating", rating))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_ratings (user_id, song_id, rating)
+		SELECT $1, id, $3 FROM songs WHERE id = $2
+		ON CONFLICT (user_id, song_id) DO UPDATE SET rating = excluded.rating, updated_at = ` + sqliteNow + `
+		RETURNING song_id`
+	var id int
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &id, query, userID, songID, rating)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to rate song", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return err
+}
+
+// DeleteSongRating discards the user's rating of the song, returning sql.ErrNoRows when there is none
+func (r *SQLiteRepository) DeleteSongRating(ctx context.Context, userID, songID int) error {
+	r.logger.Debug("Deleting song rating", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return r.deleteUserSongRow(ctx, "DELETE FROM song_ratings WHERE user_id = $1 AND song_id = $2", userID, songID)
+}
+
+// FavoriteSong marks the song as one of the user's favorites and returns when it was first marked,
+// returning sql.ErrNoRows when the song does not exist
+func (r *SQLiteRepository) FavoriteSong(ctx context.Context, userID, songID int) (models.SongFavorite, error) {
+	r.logger.Debug("Marking song as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	// The no-op update returns the existing row, keeping when the song was first marked
+	query := `INSERT INTO song_favorites (user_id, song_id)
+		SELECT $1, id FROM songs WHERE id = $2
+		ON CONFLICT (user_id, song_id) DO UPDATE SET created_at = song_favorites.created_at
+		RETURNING song_id, created_at`
+	var favorite models.SongFavorite
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &favorite, query, userID, songID)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to mark song as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+	}
+	return favorite, err
+}
+
+// UnfavoriteSong removes the song from the user's favorites, returning sql.ErrNoRows when it is not one
+func (r *SQLiteRepository) UnfavoriteSong(ctx context.Context, userID, songID int) error {
+	r.logger.Debug("Unmarking song as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return r.deleteUserSongRow(ctx, "DELETE FROM song_favorites WHERE user_id = $1 AND song_id = $2", userID, songID)
+}
+
+// deleteUserSongRow runs a delete of the user's row about the song, returning sql.ErrNoRows when it
+// deletes nothing
+func (r *SQLiteRepository) deleteUserSongRow(ctx context.Context, query string, userID, songID int) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, userID, songID)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to delete user song row", zap.String("query", query), zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	r.track(query, start, rows, nil)
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// CreateWebhook stores a webhook and returns it as stored
+func (r *SQLiteRepository) CreateWebhook(ctx context.Context, url string, events []string, secret string) (models.Webhook, error) {
+	r.logger.Debug("Creating webhook", zap.String("url", url), zap.Strings("events", events))
+	return r.getWebhook(ctx, "INSERT INTO webhooks (url, events, secret) VALUES ($1, $2, $3) RETURNING *", url, jsonList(events), secret)
+}
+
+// getWebhook runs a statement returning a single webhook
+func (r *SQLiteRepository) getWebhook(ctx context.Context, query string, args ...any) (models.Webhook, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	var row jsonWebhook
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &row, query, args...)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to store webhook", zap.Error(err))
+		}
+		return models.Webhook{}, err
+	}
+	return row.decode()
+}
+
+// GetWebhooks returns every webhook, oldest first
+func (r *SQLiteRepository) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT * FROM webhooks ORDER BY id"
+	var rows []jsonWebhook
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &rows, query)
+	r.track(query, start, int64(len(rows)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch webhooks", zap.Error(err))
+		return nil, err
+	}
+	webhooks := make([]models.Webhook, 0, len(rows))
+	for _, row := range rows {
+		webhook, err := row.decode()
+		if err != nil {
+			r.logger.Error("Failed to decode webhook", zap.Int("id", row.ID), zap.Error(err))
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes a webhook with its deliveries, returning sql.ErrNoRows when it does not exist
+func (r *SQLiteRepository) DeleteWebhook(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting webhook", zap.Int("id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "DELETE FROM webhooks WHERE id = $1"
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to delete webhook", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	r.track(query, start, rows, nil)
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// EnqueueWebhookDeliveries queues the event for every webhook subscribed to its type and returns the
+// number of deliveries queued
+func (r *SQLiteRepository) EnqueueWebhookDeliveries(ctx context.Context, eventType string, payload []byte) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+		SELECT id, $1, $2 FROM webhooks WHERE $1 IN (SELECT value FROM json_each(events))`
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, eventType, payload)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to queue webhook deliveries", zap.String("event_type", eventType), zap.Error(err))
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	return rows, err
+}
+
+// ClaimWebhookDeliveries claims up to limit pending deliveries that are due, skipping those to the excluded
+// webhooks, counting an attempt for each and pushing their next attempt lease into the future, so a delivery
+// left unfinished by a crash is retried then. The claim and the read of the claimed deliveries share a
+// transaction, which holds the write lock, so two workers never claim the same delivery.
+func (r *SQLiteRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration, exclude []int) ([]models.DueDelivery, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	query := `UPDATE webhook_deliveries SET attempts = attempts + 1, next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $3 AND webhook_id NOT IN (SELECT value FROM json_each($4))
+			ORDER BY next_attempt_at LIMIT $1
+		)
+		RETURNING id`
+	var ids []int64
+	start := time.Now()
+	err = tx.SelectContext(ctx, &ids, query, limit, now.Add(lease), now, jsonList(exclude))
+	r.track(query, start, int64(len(ids)), err)
+	if err != nil {
+		r.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+		return nil, err
+	}
+	query = `SELECT d.*, w.url, w.secret, w.previous_secret, w.secret_rotated_at
+		FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.id IN (SELECT value FROM json_each($1)) ORDER BY d.id`
+	deliveries := []models.DueDelivery{}
+	start = time.Now()
+	err = tx.SelectContext(ctx, &deliveries, query, jsonList(ids))
+	r.track(query, start, int64(len(deliveries)), err)
+	if err != nil {
+		r.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// DeferWebhookDelivery hands a claimed delivery back unsent, to be claimed again after the wait. The claim
+// does not count as an attempt.
+func (r *SQLiteRepository) DeferWebhookDelivery(ctx context.Context, id int64, after time.Duration) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `UPDATE webhook_deliveries SET attempts = max(attempts - 1, 0), next_attempt_at = $2 WHERE id = $1`
+	start := time.Now()
+	_, err := r.conn(ctx).ExecContext(ctx, query, id, time.Now().Add(after))
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to defer webhook delivery", zap.Int64("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// FinishWebhookDelivery records the outcome of a delivery attempt. A pending status schedules the next
+// attempt after retryAfter; a delivered status records the delivery time.
+func (r *SQLiteRepository) FinishWebhookDelivery(ctx context.Context, id int64, status string, responseStatus *int, message *string, retryAfter time.Duration) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `UPDATE webhook_deliveries SET status = $2, response_status = $3, error = $4, next_attempt_at = $5,
+			delivered_at = CASE WHEN $2 = 'delivered' THEN ` + sqliteNow + ` END
+		WHERE id = $1`
+	start := time.Now()
+	_, err := r.conn(ctx).ExecContext(ctx, query, id, status, responseStatus, message, time.Now().Add(retryAfter))
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to record webhook delivery", zap.Int64("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// GetWebhookDeliveries returns the newest deliveries of a webhook, of every status when status is empty.
+// sql.ErrNoRows is returned when the webhook does not exist.
+func (r *SQLiteRepository) GetWebhookDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	var exists bool
+	if err := r.conn(ctx).GetContext(ctx, &exists, "SELECT EXISTS (SELECT 1 FROM webhooks WHERE id = $1)", webhookID); err != nil {
+		r.logger.Error("Failed to check webhook", zap.Int("id", webhookID), zap.Error(err))
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	query := `SELECT * FROM webhook_deliveries WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC LIMIT $3`
+	deliveries := []models.WebhookDelivery{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &deliveries, query, webhookID, status, limit)
+	r.track(query, start, int64(len(deliveries)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch webhook deliveries", zap.Int("id", webhookID), zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// GetWebhookDelivery returns a delivery of a webhook, or sql.ErrNoRows when the webhook has no such delivery
+func (r *SQLiteRepository) GetWebhookDelivery(ctx context.Context, webhookID int, id int64) (models.WebhookDelivery, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT * FROM webhook_deliveries WHERE id = $2 AND webhook_id = $1"
+	var delivery models.WebhookDelivery
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &delivery, query, webhookID, id)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch webhook delivery", zap.Int64("id", id), zap.Error(err))
+	}
+	return delivery, err
+}
+
+// RetryWebhookDelivery queues a finished delivery of a webhook to be sent again right away, with its attempts
+// reset, and returns it. sql.ErrNoRows is returned when the webhook has no such delivery or it is still pending.
+func (r *SQLiteRepository) RetryWebhookDelivery(ctx context.Context, webhookID int, id int64) (models.WebhookDelivery, error) {
+	r.logger.Debug("Retrying webhook delivery", zap.Int("webhook_id", webhookID), zap.Int64("id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = ` + sqliteNow + `, delivered_at = NULL
+		WHERE id = $2 AND webhook_id = $1 AND status <> 'pending' RETURNING *`
+	var delivery models.WebhookDelivery
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &delivery, query, webhookID, id)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to retry webhook delivery", zap.Int64("id", id), zap.Error(err))
+	}
+	return delivery, err
+}
+
+// RotateWebhookSecret replaces the secret of a webhook, keeping the replaced one as its previous secret, and
+// returns the webhook. sql.ErrNoRows is returned when it does not exist.
+func (r *SQLiteRepository) RotateWebhookSecret(ctx context.Context, id int, secret string) (models.Webhook, error) {
+	r.logger.Debug("Rotating webhook secret", zap.Int("id", id))
+	return r.getWebhook(ctx, `UPDATE webhooks SET previous_secret = secret, secret = $2, secret_rotated_at = `+sqliteNow+`
+		WHERE id = $1 RETURNING *`, id, secret)
+}
+
+// AddSongEvent appends a catalog event to the event log and returns its ID. The event stays in the outbox
+// until the relay marks it published; added within RunInTransaction, it is stored with the change it records.
+func (r *SQLiteRepository) AddSongEvent(ctx context.Context, event models.SongEvent) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_events (event_type, song_id, count, occurred_at) VALUES ($1, NULLIF($2, 0), $3, $4) RETURNING id`
+	var id int64
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &id, query, event.Type, event.SongID, event.Count, event.OccurredAt)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to store song event", zap.String("event_type", event.Type), zap.Error(err))
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetSongEventsAfter returns up to limit events of the event log following the event with the given ID, oldest first
+func (r *SQLiteRepository) GetSongEventsAfter(ctx context.Context, afterID int64, limit int) ([]models.SongEvent, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT id, event_type, COALESCE(song_id, 0) AS song_id, count, occurred_at FROM song_events
+		WHERE id > $1 ORDER BY id LIMIT $2`
+	events := []models.SongEvent{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &events, query, afterID, limit)
+	r.track(query, start, int64(len(events)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch song events", zap.Int64("after_id", afterID), zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
+
+// ClaimSongEvents returns up to limit events of the outbox, the events not yet published, oldest first. It
+// runs within RunInTransaction, whose transaction holds the database's write lock, so a single relay
+// publishes at a time and events keep their order; another relay waits for the transaction to end.
+func (r *SQLiteRepository) ClaimSongEvents(ctx context.Context, limit int) ([]models.SongEvent, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT id, event_type, COALESCE(song_id, 0) AS song_id, count, occurred_at FROM song_events
+		WHERE published_at IS NULL ORDER BY id LIMIT $1`
+	events := []models.SongEvent{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &events, query, limit)
+	r.track(query, start, int64(len(events)), err)
+	if err != nil {
+		r.logger.Error("Failed to claim outbox events", zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkSongEventsPublished takes the events out of the outbox
+func (r *SQLiteRepository) MarkSongEventsPublished(ctx context.Context, ids []int64) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "UPDATE song_events SET published_at = " + sqliteNow + " WHERE id IN (SELECT value FROM json_each($1))"
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, jsonList(ids))
+	var rows int64
+	if err == nil {
+		rows, err = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to mark outbox events published", zap.Int("count", len(ids)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetLatestSongEventID returns the ID of the latest event of the event log, zero when it is empty
+func (r *SQLiteRepository) GetLatestSongEventID(ctx context.Context) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT COALESCE(MAX(id), 0) FROM song_events"
+	var id int64
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &id, query)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to fetch latest song event", zap.Error(err))
+		return 0, err
+	}
+	return id, nil
+}
+
+// DeleteSongEventsBefore drops the published events of the event log that occurred before the given time and
+// returns how many were dropped
+func (r *SQLiteRepository) DeleteSongEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "DELETE FROM song_events WHERE occurred_at < $1 AND published_at IS NOT NULL"
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, before)
+	var rows int64
+	if err == nil {
+		rows, err = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to prune song events", zap.Error(err))
+		return 0, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// groupMatch selects the songs of a group, whose name is compared case-insensitively
+const groupMatch = "lower(s.group_name) = lower($1)"
+
+// GetGroupStats aggregates the songs of a group, returning sql.ErrNoRows when it has none
+func (r *PostgresRepository) GetGroupStats(ctx context.Context, group string) (models.GroupStats, error) {
+	r.logger.Debug("Fetching group stats", zap.String("group", group))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	// Release dates are stored as DD.MM.YYYY text, so they are compared as dates
+	query := `SELECT MIN(s.group_name) AS group_name, COUNT(*) AS songs,
+		to_char(MIN(d.released), 'DD.MM.YYYY') AS earliest_release,
+		to_char(MAX(d.released), 'DD.MM.YYYY') AS latest_release,
+		COALESCE(SUM(v.views), 0) AS total_views, NULL::float8 AS average_rating
+		FROM songs s
+		LEFT JOIN song_views v ON v.song_id = s.id
+		CROSS JOIN LATERAL (SELECT CASE WHEN s.release_date ~ '^\d{2}\.\d{2}\.\d{4}$'
+			THEN to_date(s.release_date, 'DD.MM.YYYY') END AS released) d
+		WHERE ` + groupMatch + `
+		HAVING COUNT(*) > 0`
+	var stats models.GroupStats
+	start := time.Now()
+	err := r.db.GetContext(ctx, &stats, query, group)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to fetch group stats", zap.String("group", group), zap.Error(err))
+		}
+		return stats, err
+	}
+	return stats, nil
+}
+
+// GetMostViewedGroupSongs retrieves up to limit songs of a group, most viewed first
+func (r *PostgresRepository) GetMostViewedGroupSongs(ctx context.Context, group string, limit int) ([]models.Song, error) {
+	r.logger.Debug("Fetching most viewed group songs", zap.String("group", group), zap.Int("limit", limit))
+	songs, err := r.songs.List(ctx, groupMatch, []any{group}, sortOrders["views"], 1, limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch most viewed group songs", zap.Error(err))
+		return nil, err
+	}
+	return songs, nil
+}
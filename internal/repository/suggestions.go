@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// HasSearchSuggestions reports whether pg_trgm and the lyric terms view were installed by the migrations
+func (r *PostgresRepository) HasSearchSuggestions(ctx context.Context) (bool, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	var exists bool
+	query := "SELECT to_regclass('search_terms') IS NOT NULL"
+	start := time.Now()
+	err := r.db.GetContext(ctx, &exists, query)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to check for search suggestions", zap.Error(err))
+	}
+	return exists, err
+}
+
+// SimilarSongNames retrieves up to limit group and song names within the trigram similarity threshold of
+// the query, closest first
+func (r *PostgresRepository) SimilarSongNames(ctx context.Context, query string, limit int) ([]models.SearchSuggestion, error) {
+	r.logger.Debug("Fetching similar song names", zap.String("query", query), zap.Int("limit", limit))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	sqlQuery := `SELECT query, source, score FROM (
+			SELECT DISTINCT group_name AS query, $3::text AS source, similarity(group_name, $1) AS score
+			FROM songs WHERE group_name % $1
+			UNION ALL
+			SELECT DISTINCT song_name, $4::text, similarity(song_name, $1)
+			FROM songs WHERE song_name % $1
+		) names
+		ORDER BY score DESC, query LIMIT $2`
+	suggestions := []models.SearchSuggestion{}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &suggestions, sqlQuery, query, limit, models.SuggestionGroup, models.SuggestionSong)
+	r.track(sqlQuery, start, int64(len(suggestions)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch similar song names", zap.Error(err))
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// SimilarSearchTerms maps each word that is not a known lyric term to the most similar one within the
+// trigram similarity threshold, preferring the terms found in more songs. Words without a match are left out.
+// The suggestions carry the term and its similarity to the word.
+func (r *PostgresRepository) SimilarSearchTerms(ctx context.Context, words []string) (map[string]models.SearchSuggestion, error) {
+	r.logger.Debug("Fetching similar search terms", zap.Strings("words", words))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT DISTINCT ON (w.word) w.word, t.term, similarity(t.term, w.word) AS score
+		FROM unnest($1::text[]) AS w(word)
+		JOIN search_terms t ON t.term % w.word
+		WHERE NOT EXISTS (SELECT 1 FROM search_terms k WHERE k.term = w.word)
+		ORDER BY w.word, similarity(t.term, w.word) DESC, t.songs DESC, t.term`
+	var rows []struct {
+		Word  string  `db:"word"`
+		Term  string  `db:"term"`
+		Score float64 `db:"score"`
+	}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &rows, query, pq.Array(words))
+	r.track(query, start, int64(len(rows)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch similar search terms", zap.Error(err))
+		return nil, err
+	}
+	terms := make(map[string]models.SearchSuggestion, len(rows))
+	for _, row := range rows {
+		terms[row.Word] = models.SearchSuggestion{Query: row.Term, Source: models.SuggestionLyrics, Score: row.Score}
+	}
+	return terms, nil
+}
+
+// RefreshSearchTerms recomputes the lyric terms offered as search suggestions
+func (r *PostgresRepository) RefreshSearchTerms(ctx context.Context) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "REFRESH MATERIALIZED VIEW CONCURRENTLY search_terms"
+	start := time.Now()
+	_, err := r.db.ExecContext(ctx, query)
+	r.track(query, start, 0, err)
+	if err != nil {
+		r.logger.Error("Failed to refresh search terms", zap.Error(err))
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// BulkTagSongs adds and removes tags on the songs with the IDs, or on every song matched by the filter
+// when ids is nil, in a single transaction. Tags that do not exist yet are created.
+func (r *PostgresRepository) BulkTagSongs(ctx context.Context, ids []int, filter models.SongFilter, add, remove []string) (models.BulkTagResult, error) {
+	r.logger.Debug("Tagging songs in bulk", zap.Int("ids", len(ids)), zap.Strings("add", add), zap.Strings("remove", remove))
+	start := time.Now()
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return models.BulkTagResult{}, err
+	}
+	defer tx.Rollback()
+
+	songIDs, err := r.matchSongIDs(ctx, tx.Tx, ids, filter)
+	if err != nil {
+		r.logger.Error("Failed to match songs for tagging", zap.Error(err))
+		return models.BulkTagResult{}, err
+	}
+	result := models.BulkTagResult{Matched: len(songIDs), MissingIDs: missingIDs(ids, songIDs), SongIDs: songIDs}
+
+	for _, tag := range add {
+		var tagID int
+		err := tx.GetContext(ctx, &tagID, `INSERT INTO tags (name) VALUES ($1)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name RETURNING id`, tag)
+		if err != nil {
+			r.logger.Error("Failed to create tag", zap.String("tag", tag), zap.Error(err))
+			return models.BulkTagResult{}, err
+		}
+		query := "INSERT INTO song_tags (song_id, tag_id) SELECT unnest($1::int[]), $2 ON CONFLICT DO NOTHING"
+		assigned, err := tx.ExecContext(ctx, query, pq.Array(songIDs), tagID)
+		if err != nil {
+			r.track(query, start, 0, err)
+			r.logger.Error("Failed to assign tag", zap.String("tag", tag), zap.Error(err))
+			return models.BulkTagResult{}, err
+		}
+		rows, _ := assigned.RowsAffected()
+		r.track(query, start, rows, nil)
+		result.Added += int(rows)
+	}
+
+	if len(remove) > 0 {
+		query := "DELETE FROM song_tags WHERE song_id = ANY($1) AND tag_id IN (SELECT id FROM tags WHERE name = ANY($2))"
+		removed, err := tx.ExecContext(ctx, query, pq.Array(songIDs), pq.Array(remove))
+		if err != nil {
+			r.track(query, start, 0, err)
+			r.logger.Error("Failed to remove tags", zap.Error(err))
+			return models.BulkTagResult{}, err
+		}
+		rows, _ := removed.RowsAffected()
+		r.track(query, start, rows, nil)
+		result.Removed = int(rows)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit bulk tag assignment", zap.Error(err))
+		return models.BulkTagResult{}, err
+	}
+	r.logger.Info("Songs tagged in bulk", zap.Int("matched", result.Matched), zap.Int("added", result.Added), zap.Int("removed", result.Removed))
+	return result, nil
+}
+
+// GetSongTags returns the tags of a song in name order, or sql.ErrNoRows when the song does not exist
+func (r *PostgresRepository) GetSongTags(ctx context.Context, songID int) ([]string, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT COALESCE(array_agg(t.name ORDER BY t.name) FILTER (WHERE t.name IS NOT NULL), '{}')
+		FROM songs s LEFT JOIN song_tags st ON st.song_id = s.id LEFT JOIN tags t ON t.id = st.tag_id
+		WHERE s.id = $1 GROUP BY s.id`
+	var tags pq.StringArray
+	start := time.Now()
+	err := r.conn(ctx).QueryRowContext(ctx, query, songID).Scan(&tags)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to fetch song tags", zap.Int("song_id", songID), zap.Error(err))
+		}
+		return nil, err
+	}
+	return []string(tags), nil
+}
+
+// matchSongIDs returns the IDs of the existing songs among ids, or of the songs matched by the filter when ids is nil
+func (r *PostgresRepository) matchSongIDs(ctx context.Context, tx *sqlx.Tx, ids []int, filter models.SongFilter) ([]int, error) {
+	songIDs := []int{}
+	if ids != nil {
+		err := tx.SelectContext(ctx, &songIDs, "SELECT id FROM songs WHERE id = ANY($1) ORDER BY id", pq.Array(ids))
+		return songIDs, err
+	}
+	where, args := songFilterClause(filter)
+	err := tx.SelectContext(ctx, &songIDs, "SELECT s.id FROM songs s WHERE "+where+" ORDER BY s.id", args...)
+	return songIDs, err
+}
+
+// missingIDs returns the requested IDs that were not found
+func missingIDs(requested, found []int) []int {
+	existing := make(map[int]bool, len(found))
+	for _, id := range found {
+		existing[id] = true
+	}
+	var missing []int
+	for _, id := range requested {
+		if !existing[id] {
+			missing = append(missing, id)
+			existing[id] = true
+		}
+	}
+	return missing
+}
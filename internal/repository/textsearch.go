@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// similarityThreshold is the trigram similarity above which names are suggested, pg_trgm's default
+const similarityThreshold = 0.3
+
+// Weights of the song fields in search ranks, those of ts_rank for the weights of the search vector
+const (
+	titleWeight = 1.0
+	groupWeight = 0.4
+	textWeight  = 0.2
+)
+
+// Snippet bounds, those PostgresRepository gives ts_headline
+const (
+	snippetFragments = 2
+	snippetMaxWords  = 20
+	snippetMinWords  = 5
+)
+
+// Bounds of the lyric terms offered as search suggestions, those of the search_terms view of PostgreSQL
+const (
+	searchTermMinLength = 3
+	searchTermLimit     = 10000
+)
+
+// trigrams returns the trigrams of the words of the text as pg_trgm computes them: lowercased, each word
+// padded with two spaces before and one after
+func trigrams(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range searchWords(text) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// trigramSimilarity is pg_trgm's similarity: the share of the trigrams of either text that both have
+func trigramSimilarity(a, b string) float64 {
+	first, second := trigrams(a), trigrams(b)
+	if len(first) == 0 || len(second) == 0 {
+		return 0
+	}
+	common := 0
+	for trigram := range first {
+		if second[trigram] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(first)+len(second)-common)
+}
+
+// cosineSimilarity returns the cosine similarity of two embeddings, 0 when they cannot be compared
+func cosineSimilarity(first, second []float64) float64 {
+	if len(first) != len(second) {
+		return 0
+	}
+	var dot, firstNorm, secondNorm float64
+	for i := range first {
+		dot += first[i] * second[i]
+		firstNorm += first[i] * first[i]
+		secondNorm += second[i] * second[i]
+	}
+	if firstNorm == 0 || secondNorm == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(firstNorm) * math.Sqrt(secondNorm))
+}
+
+// searchWords splits text into lowercase words, as the simple text search configuration does
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchPhrase is a sequence of words matching consecutive words of a field
+type searchPhrase []string
+
+// searchQuery is a query in web-search syntax ("quoted phrases", OR, -excluded), as websearch_to_tsquery
+// parses it. A document matches when it matches every clause and no excluded phrase; a clause matches
+// when any of its phrases does.
+type searchQuery struct {
+	clauses  [][]searchPhrase
+	excluded []searchPhrase
+}
+
+// parseSearchQuery parses a query in web-search syntax
+func parseSearchQuery(query string) searchQuery {
+	var parsed searchQuery
+	or := false
+	for rest := query; ; {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			return parsed
+		}
+		excluded := strings.HasPrefix(rest, "-")
+		if excluded {
+			rest = rest[1:]
+		}
+		var term string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				term, rest = rest[1:], ""
+			} else {
+				term, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			term, rest = rest[:end], rest[end:]
+			if !excluded && strings.EqualFold(term, "or") {
+				or = len(parsed.clauses) > 0
+				continue
+			}
+		}
+		phrase := searchPhrase(searchWords(term))
+		switch {
+		case len(phrase) == 0:
+		case excluded:
+			parsed.excluded = append(parsed.excluded, phrase)
+		case or:
+			last := len(parsed.clauses) - 1
+			parsed.clauses[last] = append(parsed.clauses[last], phrase)
+		default:
+			parsed.clauses = append(parsed.clauses, []searchPhrase{phrase})
+		}
+		or = false
+	}
+}
+
+// in reports whether the phrase occurs in the words
+func (p searchPhrase) in(words []string) bool {
+	for i := 0; i+len(p) <= len(words); i++ {
+		matched := true
+		for j, word := range p {
+			if words[i+j] != word {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// rank scores a document made of weighted fields: zero when it does not match the query, and otherwise
+// the sum over the clauses of the weight of the best field matching them, normalized into (0, 1) as
+// ts_rank's normalization 32 does
+func (q searchQuery) rank(fields [][]string, weights []float64) float64 {
+	if len(q.clauses) == 0 {
+		return 0
+	}
+	for _, phrase := range q.excluded {
+		for _, words := range fields {
+			if phrase.in(words) {
+				return 0
+			}
+		}
+	}
+	var rank float64
+	for _, clause := range q.clauses {
+		best := 0.0
+		for _, phrase := range clause {
+			for i, words := range fields {
+				if weights[i] > best && phrase.in(words) {
+					best = weights[i]
+				}
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		rank += best
+	}
+	return rank / (rank + 1)
+}
+
+// searchSnippet returns up to two fragments of the lyrics around the words of the query, with those
+// words wrapped in <mark> tags, or the first words of the lyrics when none of them occurs
+func searchSnippet(query, lyrics string) string {
+	queried := make(map[string]bool)
+	for _, clause := range parseSearchQuery(query).clauses {
+		for _, phrase := range clause {
+			for _, word := range phrase {
+				queried[word] = true
+			}
+		}
+	}
+
+	// The byte offsets of each word of the lyrics
+	var spans [][2]int
+	start := -1
+	for i, r := range lyrics {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(lyrics)})
+	}
+	matched := make([]bool, len(spans))
+	var fragments [][2]int
+	for i, span := range spans {
+		matched[i] = queried[strings.ToLower(lyrics[span[0]:span[1]])]
+		if !matched[i] || len(fragments) == snippetFragments || (len(fragments) > 0 && i < fragments[len(fragments)-1][1]) {
+			continue
+		}
+		first := max(i-snippetMinWords, 0)
+		if len(fragments) > 0 {
+			first = max(first, fragments[len(fragments)-1][1])
+		}
+		fragments = append(fragments, [2]int{first, min(first+snippetMaxWords, len(spans))})
+	}
+	if len(fragments) == 0 {
+		if len(spans) == 0 {
+			return ""
+		}
+		return lyrics[spans[0][0]:spans[min(snippetMinWords, len(spans))-1][1]]
+	}
+
+	var snippet strings.Builder
+	for n, fragment := range fragments {
+		if n > 0 {
+			snippet.WriteString(" ... ")
+		}
+		for i := fragment[0]; i < fragment[1]; i++ {
+			if i > fragment[0] {
+				snippet.WriteString(lyrics[spans[i-1][1]:spans[i][0]])
+			}
+			word := lyrics[spans[i][0]:spans[i][1]]
+			if matched[i] {
+				word = "<mark>" + word + "</mark>"
+			}
+			snippet.WriteString(word)
+		}
+	}
+	return snippet.String()
+}
+
+// searchTermCounter counts the songs whose lyrics contain each word, as ts_stat counts documents
+type searchTermCounter map[string]int
+
+// add counts the words of the lyrics of a song
+func (c searchTermCounter) add(lyrics string) {
+	seen := make(map[string]bool)
+	for _, word := range searchWords(lyrics) {
+		if !seen[word] && utf8.RuneCountInString(word) >= searchTermMinLength {
+			seen[word] = true
+			c[word]++
+		}
+	}
+}
+
+// top returns the searchTermLimit words found in the most songs, with the number of songs of each
+func (c searchTermCounter) top() ([]string, []int) {
+	terms := make([]string, 0, len(c))
+	for term := range c {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if c[terms[i]] != c[terms[j]] {
+			return c[terms[i]] > c[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+	if len(terms) > searchTermLimit {
+		terms = terms[:searchTermLimit]
+	}
+	counts := make([]int, len(terms))
+	for i, term := range terms {
+		counts[i] = c[term]
+	}
+	return terms, counts
+}
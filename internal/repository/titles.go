@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// selectSongTitles selects song titles
+const selectSongTitles = "SELECT song_id, lang, title, kind, created_at, updated_at FROM song_titles"
+
+// GetSongTitles retrieves the titles of a song in language order
+func (r *PostgresRepository) GetSongTitles(ctx context.Context, songID int) ([]models.SongTitle, error) {
+	r.logger.Debug("Fetching song titles", zap.Int("song_id", songID))
+	return r.selectSongTitles(ctx, selectSongTitles+" WHERE song_id = $1 ORDER BY lang", songID)
+}
+
+// GetSongTitlesIn retrieves the titles of the songs with the IDs in the languages
+func (r *PostgresRepository) GetSongTitlesIn(ctx context.Context, songIDs []int, langs []string) ([]models.SongTitle, error) {
+	r.logger.Debug("Fetching localized song titles", zap.Int("songs", len(songIDs)), zap.Strings("langs", langs))
+	return r.selectSongTitles(ctx, selectSongTitles+" WHERE song_id = ANY($1) AND lang = ANY($2)", pq.Array(songIDs), pq.Array(langs))
+}
+
+// selectSongTitles runs a query selecting song titles
+func (r *PostgresRepository) selectSongTitles(ctx context.Context, query string, args ...any) ([]models.SongTitle, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	titles := []models.SongTitle{}
+	start := time.Now()
+	err := r.conn(ctx).SelectContext(ctx, &titles, query, args...)
+	r.track(query, start, int64(len(titles)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch song titles", zap.Error(err))
+		return nil, err
+	}
+	return titles, nil
+}
+
+// SetSongTitle adds or replaces the title of a song in the language and returns it as stored
+func (r *PostgresRepository) SetSongTitle(ctx context.Context, songID int, lang string, title models.SongTitleInput) (models.SongTitle, error) {
+	r.logger.Debug("Setting song title", zap.Int("song_id", songID), zap.String("lang", lang))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO song_titles (song_id, lang, title, kind) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (song_id, lang) DO UPDATE SET title = EXCLUDED.title, kind = EXCLUDED.kind
+		RETURNING song_id, lang, title, kind, created_at, updated_at`
+	var stored models.SongTitle
+	start := time.Now()
+	err := r.conn(ctx).GetContext(ctx, &stored, query, songID, lang, title.Title, title.Kind)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to set song title", zap.Int("song_id", songID), zap.String("lang", lang), zap.Error(err))
+		return models.SongTitle{}, err
+	}
+	return stored, nil
+}
+
+// DeleteSongTitle deletes the title of a song in the language, returning sql.ErrNoRows when there is none
+func (r *PostgresRepository) DeleteSongTitle(ctx context.Context, songID int, lang string) error {
+	r.logger.Debug("Deleting song title", zap.Int("song_id", songID), zap.String("lang", lang))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "DELETE FROM song_titles WHERE song_id = $1 AND lang = $2"
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, songID, lang)
+	var rows int64
+	if err == nil {
+		rows, _ = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to delete song title", zap.Int("song_id", songID), zap.String("lang", lang), zap.Error(err))
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
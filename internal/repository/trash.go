@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// selectTrashedSongs selects the songs in the trash, leaving out the rows they hold
+const selectTrashedSongs = "SELECT id, group_name, song_name, deleted_at FROM trashed_songs"
+
+// TrashSong moves a song to the trash: it is deleted together with the rows attached to it, which the trash
+// keeps to restore them, the same ones a snapshot holds. sql.ErrNoRows is returned when the song does not
+// exist. A song in the trash under the same ID, left from before the IDs were reset, is replaced.
+func (r *PostgresRepository) TrashSong(ctx context.Context, id int) error {
+	r.logger.Debug("Moving song to trash", zap.Int("id", id))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO trashed_songs (id, group_name, song_name, data)
+		SELECT s.id, s.group_name, s.song_name, ` + snapshotData("$1") + `
+		FROM songs s WHERE s.id = $1
+		ON CONFLICT (id) DO UPDATE SET group_name = EXCLUDED.group_name, song_name = EXCLUDED.song_name,
+			data = EXCLUDED.data, deleted_at = NOW()
+		RETURNING id`
+	start := time.Now()
+	err = tx.GetContext(ctx, &id, query, id)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to move song to trash", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	query = "DELETE FROM songs WHERE id = $1"
+	start = time.Now()
+	_, err = tx.ExecContext(ctx, query, id)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to delete song", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Song moved to trash in database", zap.Int("id", id))
+	return nil
+}
+
+// GetTrashedSong retrieves a song in the trash, returning sql.ErrNoRows when it is not there
+func (r *PostgresRepository) GetTrashedSong(ctx context.Context, id int) (models.TrashedSong, error) {
+	return r.trash.Get(ctx, id)
+}
+
+// GetTrash retrieves a page of the songs in the trash, most recently deleted first, and their total number
+func (r *PostgresRepository) GetTrash(ctx context.Context, page, limit int) ([]models.TrashedSong, int, error) {
+	r.logger.Debug("Fetching trash", zap.Int("page", page), zap.Int("limit", limit))
+	songs, err := r.trash.List(ctx, "", nil, "deleted_at DESC, id DESC", page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := r.trash.Count(ctx, "", nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return songs, total, nil
+}
+
+// RestoreTrashedSong puts a song in the trash back into the catalog under its ID, with the rows attached to
+// it, and takes it out of the trash. Rows referencing a user, a tag or a genre deleted since are left out,
+// and the song is restored without its album when that was deleted. The ID is expected to be free.
+// sql.ErrNoRows is returned when the song is not in the trash.
+func (r *PostgresRepository) RestoreTrashedSong(ctx context.Context, id int) error {
+	r.logger.Debug("Restoring song from trash", zap.Int("id", id))
+	tx, err := r.begin(ctx)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.GetContext(ctx, &id, "SELECT id FROM trashed_songs WHERE id = $1 FOR UPDATE", id); err != nil {
+		return err
+	}
+	if err := r.restoreRows(ctx, tx, "trashed_songs", id); err != nil {
+		return err
+	}
+	// The ID sequence may have been reset below the restored ID since, which later songs would then collide with
+	query := `SELECT setval(q.seq, $1) FROM (SELECT pg_get_serial_sequence('songs', 'id')::regclass AS seq) q
+		WHERE COALESCE(pg_sequence_last_value(q.seq), 0) < $1`
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		r.logger.Error("Failed to advance song IDs", zap.Error(err))
+		return err
+	}
+	query = "DELETE FROM trashed_songs WHERE id = $1"
+	start := time.Now()
+	_, err = tx.ExecContext(ctx, query, id)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to take song out of trash", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Song restored from trash in database", zap.Int("id", id))
+	return nil
+}
+
+// DeleteTrashedSong deletes a song in the trash for good, returning sql.ErrNoRows when it is not there
+func (r *PostgresRepository) DeleteTrashedSong(ctx context.Context, id int) error {
+	r.logger.Debug("Purging song from trash", zap.Int("id", id))
+	return r.trash.Delete(ctx, id)
+}
+
+// PurgeTrash deletes for good the songs moved to the trash before the given time and returns how many were
+// deleted
+func (r *PostgresRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "DELETE FROM trashed_songs WHERE deleted_at < $1"
+	start := time.Now()
+	result, err := r.db.ExecContext(ctx, query, before)
+	var rows int64
+	if err == nil {
+		rows, err = result.RowsAffected()
+	}
+	r.track(query, start, rows, err)
+	if err != nil {
+		r.logger.Error("Failed to purge trash", zap.Error(err))
+		return 0, err
+	}
+	return rows, nil
+}
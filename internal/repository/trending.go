@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// RefreshTrending recomputes the materialized trending scores from the daily view buckets.
+// Each day's views are divided by (age in hours + 2) raised to gravity, so recent activity dominates.
+func (r *PostgresRepository) RefreshTrending(ctx context.Context, gravity float64, windowDays int) error {
+	r.logger.Debug("Refreshing trending scores", zap.Float64("gravity", gravity), zap.Int("window_days", windowDays))
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	start := time.Now()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM song_trending"); err != nil {
+		r.track("DELETE FROM song_trending", start, 0, err)
+		r.logger.Error("Failed to clear trending scores", zap.Error(err))
+		return err
+	}
+	query := `INSERT INTO song_trending (song_id, score, computed_at)
+		SELECT song_id, SUM(views / POWER(EXTRACT(EPOCH FROM (NOW() - day::timestamptz)) / 3600 + 2, $1)), NOW()
+		FROM song_view_days WHERE day > CURRENT_DATE - $2::int 
+		GROUP BY song_id`
+	result, err := tx.ExecContext(ctx, query, gravity, windowDays)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to compute trending scores", zap.Error(err))
+		return err
+	}
+	rowsAffected, _ := result.RowsAffected()
+	r.track(query, start, rowsAffected, nil)
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit trending scores", zap.Error(err))
+		return err
+	}
+	r.logger.Info("Trending scores refreshed", zap.Int64("songs", rowsAffected))
+	return nil
+}
+
+// GetTrendingSongs retrieves the songs with the highest materialized trending score
+func (r *PostgresRepository) GetTrendingSongs(ctx context.Context, limit int) ([]models.TrendingSong, error) {
+	r.logger.Debug("Fetching trending songs", zap.Int("limit", limit))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT ` + songColumns + `, COALESCE(v.views, 0) AS views, t.score FROM songs s 
+		LEFT JOIN song_views v ON v.song_id = s.id 
+		JOIN song_trending t ON t.song_id = s.id 
+		ORDER BY t.score DESC, s.id LIMIT $1`
+	songs := []models.TrendingSong{}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &songs, query, limit)
+	r.track(query, start, int64(len(songs)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch trending songs", zap.Error(err))
+		return nil, err
+	}
+	r.logger.Info("Trending songs fetched from database", zap.Int("count", len(songs)))
+	return songs, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// queryer runs statements either directly on the database or inside a transaction
+type queryer interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txKey is the context key of the transaction started by RunInTransaction
+type txKey struct{}
+
+// inTransaction reports whether ctx carries a transaction started by RunInTransaction, which for
+// MongoDB is the session its transaction runs in
+func inTransaction(ctx context.Context) bool {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return true
+	}
+	return mongo.SessionFromContext(ctx) != nil
+}
+
+// txOrDB returns the transaction ctx carries, or db outside of RunInTransaction
+func txOrDB(ctx context.Context, db *sqlx.DB) queryer {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// conn returns what the statements of a repository method run on
+func (r *PostgresRepository) conn(ctx context.Context) queryer {
+	return txOrDB(ctx, r.db)
+}
+
+// conn returns what the statements of a table run on
+func (t *Table[T]) conn(ctx context.Context) queryer {
+	if t.dialect != nil {
+		return t.dialect(txOrDB(ctx, t.db))
+	}
+	return txOrDB(ctx, t.db)
+}
+
+// RunInTransaction runs fn in a single transaction, committed when fn returns nil and rolled back otherwise.
+// The repository writes fn makes with the context it is given join the transaction, so a change and the
+// events recorded for it are stored together or not at all. Nested calls join the outer transaction.
+func (r *PostgresRepository) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if inTransaction(ctx) {
+		return fn(ctx)
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// txScope is a transaction a repository method runs its statements in. It either owns the transaction or
+// joined the one started by RunInTransaction, which is then committed or rolled back by its owner.
+type txScope struct {
+	*sqlx.Tx
+	owned bool
+}
+
+// begin starts the transaction of a repository method, joining the one ctx carries if any
+func (r *PostgresRepository) begin(ctx context.Context) (txScope, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return txScope{Tx: tx}, nil
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return txScope{}, err
+	}
+	return txScope{Tx: tx, owned: true}, nil
+}
+
+// Commit commits an owned transaction
+func (s txScope) Commit() error {
+	if !s.owned {
+		return nil
+	}
+	return s.Tx.Commit()
+}
+
+// Rollback rolls back an owned transaction; a failure inside a joined one is returned to its owner instead
+func (s txScope) Rollback() error {
+	if !s.owned {
+		return nil
+	}
+	return s.Tx.Rollback()
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// selectUsers selects user accounts
+const selectUsers = "SELECT id, username, password_hash, role, created_at FROM users"
+
+// CreateUser adds a user account with the role and returns its ID, or sql.ErrNoRows when the username is taken
+func (r *PostgresRepository) CreateUser(ctx context.Context, username, passwordHash, role string) (int, error) {
+	r.logger.Debug("Creating user", zap.String("username", username), zap.String("role", role))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO users (username, password_hash, role) VALUES ($1, $2, $3)
+		ON CONFLICT (username) DO NOTHING RETURNING id`
+	var id int
+	start := time.Now()
+	err := r.db.GetContext(ctx, &id, query, username, passwordHash, role)
+	r.track(query, start, 1, err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.logger.Error("Failed to create user", zap.String("username", username), zap.Error(err))
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetUserByUsername retrieves a user account, returning sql.ErrNoRows when it does not exist
+func (r *PostgresRepository) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	users, err := r.users.Find(ctx, "username = $1", []any{username}, "id")
+	if err != nil {
+		return models.User{}, err
+	}
+	if len(users) == 0 {
+		return models.User{}, sql.ErrNoRows
+	}
+	return users[0], nil
+}
+
+// GetUserByID retrieves a user account, returning sql.ErrNoRows when it does not exist
+func (r *PostgresRepository) GetUserByID(ctx context.Context, id int) (models.User, error) {
+	return r.users.Get(ctx, id)
+}
+
+// GetUsers retrieves a page of user accounts ordered by ID
+func (r *PostgresRepository) GetUsers(ctx context.Context, page, limit int) ([]models.User, error) {
+	r.logger.Debug("Fetching users", zap.Int("page", page), zap.Int("limit", limit))
+	return r.users.List(ctx, "", nil, "id", page, limit)
+}
+
+// SetUserRole changes the role of a user, returning sql.ErrNoRows when the user does not exist
+func (r *PostgresRepository) SetUserRole(ctx context.Context, id int, role string) error {
+	r.logger.Debug("Setting user role", zap.Int("id", id), zap.String("role", role))
+	return r.users.Update(ctx, id, map[string]any{"role": role})
+}
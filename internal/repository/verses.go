@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// songTextHash is the SQL counterpart of TextHash
+const songTextHash = `md5(COALESCE(s.text, ''))`
+
+// TextHash identifies the text a verse index was computed from, so an index left behind by an edit is ignored
+func TextHash(text string) string {
+	sum := md5.Sum([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveVerseIndex stores the verse spans of a song's text for the delimiter, replacing its previous index.
+// Nothing is stored when the song's text no longer hashes to textHash, as another write changed it since
+// the spans were computed.
+func (r *PostgresRepository) SaveVerseIndex(ctx context.Context, songID int, delimiter, textHash string, spans []models.VerseSpan) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback()
+
+	// The row lock keeps the text from changing until the index is committed
+	checkQuery := `SELECT ` + songTextHash + ` = $2 FROM songs s WHERE s.id = $1 FOR SHARE`
+	start := time.Now()
+	var current bool
+	err = tx.GetContext(ctx, &current, checkQuery, songID, textHash)
+	r.track(checkQuery, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to check song text", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	if !current {
+		r.logger.Debug("Song text changed, verse index not stored", zap.Int("song_id", songID))
+		return nil
+	}
+
+	indexQuery := `INSERT INTO song_verse_index (song_id, delimiter, content_hash, total_verses) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (song_id) DO UPDATE SET delimiter = EXCLUDED.delimiter, content_hash = EXCLUDED.content_hash,
+		total_verses = EXCLUDED.total_verses, indexed_at = NOW()`
+	if _, err := tx.ExecContext(ctx, indexQuery, songID, delimiter, textHash, len(spans)); err != nil {
+		r.track(indexQuery, start, 0, err)
+		r.logger.Error("Failed to store verse index", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM song_verses WHERE song_id = $1", songID); err != nil {
+		r.logger.Error("Failed to drop old verse spans", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	numbers, labels := make([]int64, len(spans)), make([]string, len(spans))
+	starts, lengths := make([]int64, len(spans)), make([]int64, len(spans))
+	for i, span := range spans {
+		numbers[i], labels[i], starts[i], lengths[i] = int64(span.Number), span.Label, int64(span.Start), int64(span.Length)
+	}
+	spansQuery := `INSERT INTO song_verses (song_id, number, label, start_offset, length)
+		SELECT $1, * FROM unnest($2::int[], $3::text[], $4::int[], $5::int[])`
+	if _, err := tx.ExecContext(ctx, spansQuery, songID, pq.Array(numbers), pq.Array(labels), pq.Array(starts), pq.Array(lengths)); err != nil {
+		r.track(spansQuery, start, 0, err)
+		r.logger.Error("Failed to store verse spans", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit verse index", zap.Int("song_id", songID), zap.Error(err))
+		return err
+	}
+	r.track(spansQuery, start, int64(len(spans)), nil)
+	return nil
+}
+
+// GetVerseIndex returns a song with its split strategy and, when an index of its current text is stored,
+// the delimiter it was split at and the number of verses it has
+func (r *PostgresRepository) GetVerseIndex(ctx context.Context, songID int) (models.VerseIndex, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `SELECT s.id AS song_id, s.group_name, s.song_name, s.split_strategy, i.song_id IS NOT NULL AS indexed,
+		COALESCE(i.delimiter, '') AS delimiter, COALESCE(i.total_verses, 0) AS total_verses
+		FROM songs s LEFT JOIN song_verse_index i
+			ON i.song_id = s.id AND i.content_hash = ` + songTextHash + `
+		WHERE s.id = $1`
+	var index models.VerseIndex
+	start := time.Now()
+	err := r.db.GetContext(ctx, &index, query, songID)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to fetch verse index", zap.Int("song_id", songID), zap.Error(err))
+	}
+	return index, err
+}
+
+// GetIndexedVerses cuts the verses numbered from through to out of a song's text by their stored spans.
+// No verses are returned when the text changed since it was indexed.
+func (r *PostgresRepository) GetIndexedVerses(ctx context.Context, songID int, delimiter string, from, to int) ([]models.IndexedVerse, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `WITH t AS MATERIALIZED (
+			SELECT replace(COALESCE(s.text, ''), E'\r\n', E'\n') AS text
+			FROM songs s JOIN song_verse_index i ON i.song_id = s.id
+			WHERE s.id = $1 AND i.delimiter = $2 AND i.content_hash = ` + songTextHash + `
+		)
+		SELECT v.number, v.label, substr(t.text, v.start_offset + 1, v.length) AS text
+		FROM song_verses v, t
+		WHERE v.song_id = $1 AND v.number BETWEEN $3 AND $4
+		ORDER BY v.number`
+	verses := []models.IndexedVerse{}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &verses, query, songID, delimiter, from, to)
+	r.track(query, start, int64(len(verses)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch indexed verses", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+	return verses, nil
+}
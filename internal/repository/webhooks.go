@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// CreateWebhook stores a webhook and returns it as stored
+func (r *PostgresRepository) CreateWebhook(ctx context.Context, url string, events []string, secret string) (models.Webhook, error) {
+	r.logger.Debug("Creating webhook", zap.String("url", url), zap.Strings("events", events))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "INSERT INTO webhooks (url, events, secret) VALUES ($1, $2, $3) RETURNING *"
+	var webhook models.Webhook
+	start := time.Now()
+	err := r.db.GetContext(ctx, &webhook, query, url, pq.Array(events), secret)
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to create webhook", zap.Error(err))
+	}
+	return webhook, err
+}
+
+// GetWebhooks returns every webhook, oldest first
+func (r *PostgresRepository) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT * FROM webhooks ORDER BY id"
+	webhooks := []models.Webhook{}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &webhooks, query)
+	r.track(query, start, int64(len(webhooks)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch webhooks", zap.Error(err))
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes a webhook with its deliveries, returning sql.ErrNoRows when it does not exist
+func (r *PostgresRepository) DeleteWebhook(ctx context.Context, id int) error {
+	r.logger.Debug("Deleting webhook", zap.Int("id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "DELETE FROM webhooks WHERE id = $1"
+	start := time.Now()
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to delete webhook", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	r.track(query, start, rows, nil)
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// EnqueueWebhookDeliveries queues the event for every webhook subscribed to its type and returns the
+// number of deliveries queued
+func (r *PostgresRepository) EnqueueWebhookDeliveries(ctx context.Context, eventType string, payload []byte) (int64, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+		SELECT id, $1, $2 FROM webhooks WHERE $1 = ANY(events)`
+	start := time.Now()
+	result, err := r.conn(ctx).ExecContext(ctx, query, eventType, string(payload))
+	if err != nil {
+		r.track(query, start, 0, err)
+		r.logger.Error("Failed to queue webhook deliveries", zap.String("event_type", eventType), zap.Error(err))
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	r.track(query, start, rows, err)
+	return rows, err
+}
+
+// ClaimWebhookDeliveries claims up to limit pending deliveries that are due, skipping those to the excluded
+// webhooks, counting an attempt for each and pushing their next attempt lease into the future, so a delivery
+// left unfinished by a crash is retried then
+func (r *PostgresRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration, exclude []int) ([]models.DueDelivery, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	if exclude == nil {
+		exclude = []int{}
+	}
+	query := `WITH claimed AS (
+			UPDATE webhook_deliveries SET attempts = attempts + 1, next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond'
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = 'pending' AND next_attempt_at <= NOW() AND NOT (webhook_id = ANY($3))
+				ORDER BY next_attempt_at LIMIT $1 FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT claimed.*, w.url, w.secret, w.previous_secret, w.secret_rotated_at
+		FROM claimed JOIN webhooks w ON w.id = claimed.webhook_id ORDER BY claimed.id`
+	deliveries := []models.DueDelivery{}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &deliveries, query, limit, lease.Milliseconds(), pq.Array(exclude))
+	r.track(query, start, int64(len(deliveries)), err)
+	if err != nil {
+		r.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// DeferWebhookDelivery hands a claimed delivery back unsent, to be claimed again after the wait. The claim
+// does not count as an attempt.
+func (r *PostgresRepository) DeferWebhookDelivery(ctx context.Context, id int64, after time.Duration) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `UPDATE webhook_deliveries SET attempts = GREATEST(attempts - 1, 0), next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond'
+		WHERE id = $1`
+	start := time.Now()
+	_, err := r.db.ExecContext(ctx, query, id, after.Milliseconds())
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to defer webhook delivery", zap.Int64("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// FinishWebhookDelivery records the outcome of a delivery attempt. A pending status schedules the next
+// attempt after retryAfter; a delivered status records the delivery time.
+func (r *PostgresRepository) FinishWebhookDelivery(ctx context.Context, id int64, status string, responseStatus *int, message *string, retryAfter time.Duration) error {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `UPDATE webhook_deliveries SET status = $2, response_status = $3, error = $4,
+			next_attempt_at = NOW() + $5 * INTERVAL '1 millisecond',
+			delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() END
+		WHERE id = $1`
+	start := time.Now()
+	_, err := r.db.ExecContext(ctx, query, id, status, responseStatus, message, retryAfter.Milliseconds())
+	r.track(query, start, 1, err)
+	if err != nil {
+		r.logger.Error("Failed to record webhook delivery", zap.Int64("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// GetWebhookDeliveries returns the newest deliveries of a webhook, of every status when status is empty.
+// sql.ErrNoRows is returned when the webhook does not exist.
+func (r *PostgresRepository) GetWebhookDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, "SELECT EXISTS (SELECT 1 FROM webhooks WHERE id = $1)", webhookID); err != nil {
+		r.logger.Error("Failed to check webhook", zap.Int("id", webhookID), zap.Error(err))
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+	query := `SELECT * FROM webhook_deliveries WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC LIMIT $3`
+	deliveries := []models.WebhookDelivery{}
+	start := time.Now()
+	err := r.db.SelectContext(ctx, &deliveries, query, webhookID, status, limit)
+	r.track(query, start, int64(len(deliveries)), err)
+	if err != nil {
+		r.logger.Error("Failed to fetch webhook deliveries", zap.Int("id", webhookID), zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// GetWebhookDelivery returns a delivery of a webhook, or sql.ErrNoRows when the webhook has no such delivery
+func (r *PostgresRepository) GetWebhookDelivery(ctx context.Context, webhookID int, id int64) (models.WebhookDelivery, error) {
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := "SELECT * FROM webhook_deliveries WHERE id = $2 AND webhook_id = $1"
+	var delivery models.WebhookDelivery
+	start := time.Now()
+	err := r.db.GetContext(ctx, &delivery, query, webhookID, id)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to fetch webhook delivery", zap.Int64("id", id), zap.Error(err))
+	}
+	return delivery, err
+}
+
+// RetryWebhookDelivery queues a finished delivery of a webhook to be sent again right away, with its attempts
+// reset, and returns it. sql.ErrNoRows is returned when the webhook has no such delivery or it is still pending.
+func (r *PostgresRepository) RetryWebhookDelivery(ctx context.Context, webhookID int, id int64) (models.WebhookDelivery, error) {
+	r.logger.Debug("Retrying webhook delivery", zap.Int("webhook_id", webhookID), zap.Int64("id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL
+		WHERE id = $2 AND webhook_id = $1 AND status <> 'pending' RETURNING *`
+	var delivery models.WebhookDelivery
+	start := time.Now()
+	err := r.db.GetContext(ctx, &delivery, query, webhookID, id)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to retry webhook delivery", zap.Int64("id", id), zap.Error(err))
+	}
+	return delivery, err
+}
+
+// RotateWebhookSecret replaces the secret of a webhook, keeping the replaced one as its previous secret, and
+// returns the webhook. sql.ErrNoRows is returned when it does not exist.
+func (r *PostgresRepository) RotateWebhookSecret(ctx context.Context, id int, secret string) (models.Webhook, error) {
+	r.logger.Debug("Rotating webhook secret", zap.Int("id", id))
+	ctx, cancel := r.statementContext(ctx)
+	defer cancel()
+	query := `UPDATE webhooks SET previous_secret = secret, secret = $2, secret_rotated_at = NOW()
+		WHERE id = $1 RETURNING *`
+	var webhook models.Webhook
+	start := time.Now()
+	err := r.db.GetContext(ctx, &webhook, query, id, secret)
+	r.track(query, start, 1, err)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to rotate webhook secret", zap.Int("id", id), zap.Error(err))
+	}
+	return webhook, err
+}
+
+// jsonWebhook is a webhook as stored by the databases without arrays, with its events still encoded as
+// a JSON array
+type jsonWebhook struct {
+	models.Webhook
+	Events string `db:"events"`
+}
+
+// decode returns the webhook with its events decoded
+func (row jsonWebhook) decode() (models.Webhook, error) {
+	webhook := row.Webhook
+	if err := json.Unmarshal([]byte(row.Events), &webhook.Events); err != nil {
+		return models.Webhook{}, err
+	}
+	return webhook, nil
+}
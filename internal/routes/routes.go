@@ -0,0 +1,234 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+)
+
+// ErrConflict is returned when a route conflicts with one registered before it
+var ErrConflict = errors.New("conflicting route")
+
+// Kinds of issues found by the self-test
+const (
+	// IssueStaleDoc is a documented operation no route serves
+	IssueStaleDoc = "stale_documentation"
+	// IssueUndocumented is a route the documentation does not describe
+	IssueUndocumented = "undocumented"
+	// IssueParamMismatch is a path whose methods name its parameters differently, which clients and the
+	// documentation cannot both follow
+	IssueParamMismatch = "param_mismatch"
+	// IssueTrailingSlash is a route registered both with and without a trailing slash, one of which is
+	// unreachable since the router redirects between them
+	IssueTrailingSlash = "trailing_slash"
+	// IssueNotMocked is a route the mock server does not serve
+	IssueNotMocked = "not_mocked"
+	// IssueMockOnly is a mock route the server does not serve
+	IssueMockOnly = "mock_only"
+)
+
+// Route is a route served by the HTTP API
+type Route struct {
+	Method  string `json:"method" example:"GET"`
+	Path    string `json:"path" example:"/songs"`
+	Handler string `json:"handler" example:"music-library/internal/api.(*Handler).GetSongs-fm"`
+	// Documented reports whether the swagger documentation describes the route
+	Documented bool `json:"documented" example:"true"`
+	// Mocked reports whether the mock server serves the route
+	Mocked bool `json:"mocked" example:"true"`
+}
+
+// Issue is a mismatch found by the self-test
+type Issue struct {
+	Kind   string `json:"kind" example:"stale_documentation"`
+	Method string `json:"method,omitempty" example:"GET"`
+	Path   string `json:"path" example:"/songs/{id}/lyrics"`
+	Detail string `json:"detail" example:"the documentation describes an operation no route serves"`
+}
+
+// Manifest is the route table of the API as checked by the self-test
+type Manifest struct {
+	Routes []Route `json:"routes"`
+	// GRPCMethods are the full names of the methods of the gRPC services
+	GRPCMethods []string `json:"grpc_methods" example:"/music.v1.MusicService/ListSongs"`
+	// Documented is the number of routes the swagger documentation describes
+	Documented int     `json:"documented" example:"1"`
+	Issues     []Issue `json:"issues"`
+}
+
+// Sources are what the route table is checked against; a nil source is not checked
+type Sources struct {
+	// Spec is the swagger 2.0 JSON documentation of the API
+	Spec []byte
+	// Mock is the route table of the mock server
+	Mock gin.RoutesInfo
+	// GRPC is the service info of the gRPC server
+	GRPC map[string]grpc.ServiceInfo
+	// Internal are the routes, as "METHOD /path", left out of the documentation on purpose, like the
+	// documentation itself and the metrics scraped by Prometheus
+	Internal []string
+}
+
+// Register runs the registration of routes, turning the panic gin raises on a duplicate or conflicting
+// route into ErrConflict so the caller can report it
+func Register(register func()) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%w: %v", ErrConflict, recovered)
+		}
+	}()
+	register()
+	return nil
+}
+
+// Check walks the route table, verifying it against the documentation and the mock server and looking for
+// routes that conflict in ways the router accepts, and returns the manifest of the routes with the issues
+// found. Every route but the internal ones must be documented. An error is returned when the documentation
+// cannot be parsed.
+func Check(routes gin.RoutesInfo, sources Sources) (Manifest, error) {
+	manifest := Manifest{Routes: make([]Route, 0, len(routes)), GRPCMethods: []string{}, Issues: []Issue{}}
+
+	// documented maps the key of each documented operation to its documented path
+	documented := map[string]string{}
+	if sources.Spec != nil {
+		var spec struct {
+			BasePath string                    `json:"basePath"`
+			Paths    map[string]map[string]any `json:"paths"`
+		}
+		if err := json.Unmarshal(sources.Spec, &spec); err != nil {
+			return manifest, fmt.Errorf("parse swagger documentation: %w", err)
+		}
+		base := strings.TrimSuffix(spec.BasePath, "/")
+		for path, operations := range spec.Paths {
+			for method := range operations {
+				documented[routeKey(strings.ToUpper(method), base+path)] = base + path
+			}
+		}
+	}
+	mocked := map[string]bool{}
+	for _, route := range sources.Mock {
+		mocked[routeKey(route.Method, route.Path)] = true
+	}
+
+	internal := map[string]bool{}
+	for _, route := range sources.Internal {
+		internal[route] = true
+	}
+
+	served := map[string]bool{}
+	for _, route := range routes {
+		key := routeKey(route.Method, route.Path)
+		served[key] = true
+		entry := Route{Method: route.Method, Path: route.Path, Handler: route.Handler, Documented: documented[key] != "", Mocked: mocked[key]}
+		if entry.Documented {
+			manifest.Documented++
+		}
+		if sources.Spec != nil && !entry.Documented && !internal[route.Method+" "+route.Path] {
+			manifest.Issues = append(manifest.Issues, Issue{Kind: IssueUndocumented, Method: route.Method, Path: route.Path,
+				Detail: "the documentation does not describe this route"})
+		}
+		if sources.Mock != nil && !entry.Mocked {
+			manifest.Issues = append(manifest.Issues, Issue{Kind: IssueNotMocked, Method: route.Method, Path: route.Path,
+				Detail: "the mock server does not serve this route"})
+		}
+		manifest.Routes = append(manifest.Routes, entry)
+	}
+	for _, route := range sources.Mock {
+		if !served[routeKey(route.Method, route.Path)] {
+			manifest.Issues = append(manifest.Issues, Issue{Kind: IssueMockOnly, Method: route.Method, Path: route.Path,
+				Detail: "the mock server serves a route the API does not"})
+		}
+	}
+	for key, path := range documented {
+		if !served[key] {
+			method, _, _ := strings.Cut(key, " ")
+			manifest.Issues = append(manifest.Issues, Issue{Kind: IssueStaleDoc, Method: method, Path: path,
+				Detail: "the documentation describes an operation no route serves"})
+		}
+	}
+	manifest.Issues = append(manifest.Issues, conflicts(routes)...)
+
+	for service, info := range sources.GRPC {
+		for _, method := range info.Methods {
+			manifest.GRPCMethods = append(manifest.GRPCMethods, "/"+service+"/"+method.Name)
+		}
+	}
+
+	sort.Slice(manifest.Routes, func(i, j int) bool {
+		if manifest.Routes[i].Path != manifest.Routes[j].Path {
+			return manifest.Routes[i].Path < manifest.Routes[j].Path
+		}
+		return manifest.Routes[i].Method < manifest.Routes[j].Method
+	})
+	sort.Strings(manifest.GRPCMethods)
+	sort.Slice(manifest.Issues, func(i, j int) bool {
+		a, b := manifest.Issues[i], manifest.Issues[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	return manifest, nil
+}
+
+// conflicts finds the routes gin accepts but that conflict: the methods of a path naming its parameters
+// differently, and routes registered both with and without a trailing slash
+func conflicts(routes gin.RoutesInfo) []Issue {
+	var issues []Issue
+	spellings := map[string]map[string]bool{}
+	registered := map[string]bool{}
+	for _, route := range routes {
+		shape := routeKey("", route.Path)
+		if spellings[shape] == nil {
+			spellings[shape] = map[string]bool{}
+		}
+		spellings[shape][route.Path] = true
+		registered[route.Method+" "+route.Path] = true
+	}
+	for _, paths := range spellings {
+		if len(paths) < 2 {
+			continue
+		}
+		names := make([]string, 0, len(paths))
+		for path := range paths {
+			names = append(names, path)
+		}
+		sort.Strings(names)
+		issues = append(issues, Issue{Kind: IssueParamMismatch, Path: names[0],
+			Detail: "the path is also registered as " + strings.Join(names[1:], ", ")})
+	}
+	for _, route := range routes {
+		if route.Path != "/" && strings.HasSuffix(route.Path, "/") && registered[route.Method+" "+strings.TrimSuffix(route.Path, "/")] {
+			issues = append(issues, Issue{Kind: IssueTrailingSlash, Method: route.Method, Path: route.Path,
+				Detail: "the route is also registered without the trailing slash"})
+		}
+	}
+	return issues
+}
+
+// routeKey identifies a route by its method and path regardless of how its parameters are named, so gin's
+// :name and *name segments match the {name} segments of the documentation
+func routeKey(method, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"), strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			segments[i] = "{}"
+		case strings.HasPrefix(segment, "*"):
+			segments[i] = "{*}"
+		}
+	}
+	key := strings.Join(segments, "/")
+	if method == "" {
+		return key
+	}
+	return method + " " + key
+}
@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const spec = `{
+	"basePath": "/",
+	"paths": {
+		"/songs": {"get": {}, "post": {}},
+		"/songs/{id}": {"put": {}},
+		"/songs/{id}/lyrics": {"get": {}}
+	}
+}`
+
+func TestCheck(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := func(*gin.Context) {}
+	r := gin.New()
+	r.GET("/songs", handler)
+	r.POST("/songs", handler)
+	r.PUT("/songs/:id", handler)
+	r.DELETE("/songs/:songID", handler)
+	r.GET("/albums", handler)
+	r.GET("/albums/", handler)
+	r.GET("/metrics", handler)
+	mock := gin.New()
+	mock.GET("/songs", handler)
+	mock.POST("/songs", handler)
+	mock.PUT("/songs/:id", handler)
+	mock.DELETE("/songs/:id", handler)
+	mock.GET("/albums", handler)
+	mock.GET("/artists", handler)
+	mock.GET("/metrics", handler)
+
+	manifest, err := Check(r.Routes(), Sources{Spec: []byte(spec), Mock: mock.Routes(), Internal: []string{"GET /metrics"}})
+	require.NoError(t, err)
+	assert.Len(t, manifest.Routes, 7)
+	assert.Equal(t, 3, manifest.Documented)
+	assert.Equal(t, []Issue{
+		{Kind: IssueMockOnly, Method: "GET", Path: "/artists", Detail: "the mock server serves a route the API does not"},
+		{Kind: IssueNotMocked, Method: "GET", Path: "/albums/", Detail: "the mock server does not serve this route"},
+		{Kind: IssueParamMismatch, Path: "/songs/:id", Detail: "the path is also registered as /songs/:songID"},
+		{Kind: IssueStaleDoc, Method: "GET", Path: "/songs/{id}/lyrics", Detail: "the documentation describes an operation no route serves"},
+		{Kind: IssueTrailingSlash, Method: "GET", Path: "/albums/", Detail: "the route is also registered without the trailing slash"},
+		{Kind: IssueUndocumented, Method: "GET", Path: "/albums", Detail: "the documentation does not describe this route"},
+		{Kind: IssueUndocumented, Method: "GET", Path: "/albums/", Detail: "the documentation does not describe this route"},
+		{Kind: IssueUndocumented, Method: "DELETE", Path: "/songs/:songID", Detail: "the documentation does not describe this route"},
+	}, manifest.Issues)
+
+	_, err = Check(r.Routes(), Sources{Spec: []byte("{")})
+	assert.Error(t, err)
+}
+
+func TestRegisterReportsConflicts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	err := Register(func() {
+		r.GET("/songs/:id", func(*gin.Context) {})
+		r.GET("/songs/:id", func(*gin.Context) {})
+	})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.NoError(t, Register(func() { r.GET("/albums", func(*gin.Context) {}) }))
+}
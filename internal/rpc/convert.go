@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"music-library/internal/models"
+	"music-library/internal/rpc/musicpb"
+	"music-library/internal/service"
+)
+
+// songMessage converts a song, leaving out the staff fields
+func songMessage(song models.Song) *musicpb.Song {
+	message := &musicpb.Song{
+		Id:               int64(song.ID),
+		Group:            song.Group,
+		Song:             song.Song,
+		ReleaseDate:      song.ReleaseDate,
+		Text:             song.Text,
+		Link:             song.Link,
+		CreatedAt:        timestamp(&song.CreatedAt),
+		UpdatedAt:        timestamp(&song.UpdatedAt),
+		EnrichedAt:       timestamp(song.EnrichedAt),
+		EnrichmentStatus: song.EnrichmentStatus,
+		Album:            song.Album,
+		Isrc:             song.ISRC,
+		ArtworkUrl:       song.ArtworkURL,
+		Views:            song.Views,
+	}
+	if song.DurationMs != nil {
+		duration := int64(*song.DurationMs)
+		message.DurationMs = &duration
+	}
+	return message
+}
+
+// songMessages converts a list of songs
+func songMessages(songs []models.Song) []*musicpb.Song {
+	messages := make([]*musicpb.Song, len(songs))
+	for i, song := range songs {
+		messages[i] = songMessage(song)
+	}
+	return messages
+}
+
+// versesMessage converts a page of verses
+func versesMessage(page *service.VersePage) *musicpb.GetVersesResponse {
+	message := &musicpb.GetVersesResponse{
+		SongId:      int64(page.SongID),
+		Group:       page.Group,
+		Song:        page.Song,
+		TotalVerses: int32(page.TotalVerses),
+		TotalPages:  int32(page.TotalPages),
+		Page:        int32(page.Page),
+		Limit:       int32(page.Limit),
+		Verses:      make([]*musicpb.Verse, len(page.Verses)),
+	}
+	for i, verse := range page.Verses {
+		message.Verses[i] = &musicpb.Verse{Number: int32(verse.Number), Label: verse.Label, Text: verse.Text}
+	}
+	return message
+}
+
+// timestamp converts a time that may be unknown, leaving the field unset then
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
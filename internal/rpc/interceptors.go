@@ -0,0 +1,91 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"music-library/internal/auth"
+	"music-library/internal/models"
+	"music-library/internal/rpc/musicpb"
+)
+
+// writeRoles is the least role allowed to call each write method, the other methods are reads open to viewers
+var writeRoles = map[string]string{
+	musicpb.MusicService_AddSong_FullMethodName:    models.RoleEditor,
+	musicpb.MusicService_UpdateSong_FullMethodName: models.RoleEditor,
+	musicpb.MusicService_DeleteSong_FullMethodName: models.RoleAdmin,
+}
+
+// Recovery returns an interceptor turning a panicking call into an Internal error, so one call cannot take
+// down the server
+func Recovery(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.Error("gRPC call panicked", zap.String("method", info.FullMethod), zap.Any("panic", recovered), zap.Stack("stack"))
+				resp, err = nil, status.Error(codes.Internal, "internal server error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// Logger returns an interceptor logging every call with its status code and duration
+func Logger(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logger.Info("gRPC call", zap.String("method", info.FullMethod), zap.String("code", status.Code(err).String()),
+			zap.Duration("duration", time.Since(start)))
+		return resp, err
+	}
+}
+
+// Authorize returns an interceptor only letting calls through that carry a valid access token, as
+// "authorization: Bearer <token>" metadata, of a user holding the role the method needs
+func Authorize(tokens *auth.Tokens, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var token string
+		if values := md.Get("authorization"); len(values) > 0 {
+			token, _ = strings.CutPrefix(values[0], "Bearer ")
+		}
+		if token == "" {
+			logger.Warn("Rejected gRPC call without access token", zap.String("method", info.FullMethod))
+			return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+		}
+		claims, err := tokens.Verify(token, auth.TokenAccess)
+		if err != nil {
+			logger.Warn("Rejected gRPC call with invalid access token", zap.String("method", info.FullMethod), zap.Error(err))
+			return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+		}
+		required, ok := writeRoles[info.FullMethod]
+		if !ok {
+			required = models.RoleViewer
+		}
+		if !models.HasRole(claims.Role, required) {
+			logger.Warn("Rejected gRPC call lacking role", zap.String("method", info.FullMethod),
+				zap.Int("user_id", claims.UserID), zap.String("required", required), zap.String("role", claims.Role))
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ReadOnly returns an interceptor refusing write calls with Unavailable while degraded reports that the
+// database is unreachable
+func ReadOnly(degraded func() bool, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, write := writeRoles[info.FullMethod]; write && degraded() {
+			logger.Warn("Rejected gRPC write while degraded", zap.String("method", info.FullMethod))
+			return nil, status.Error(codes.Unavailable, "database unavailable, the service is read-only")
+		}
+		return handler(ctx, req)
+	}
+}
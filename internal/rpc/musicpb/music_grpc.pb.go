@@ -0,0 +1,491 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: music.proto
+
+package musicpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MusicService_ListSongs_FullMethodName           = "/music.v1.MusicService/ListSongs"
+	MusicService_CountSongs_FullMethodName          = "/music.v1.MusicService/CountSongs"
+	MusicService_FindSong_FullMethodName            = "/music.v1.MusicService/FindSong"
+	MusicService_SearchSongs_FullMethodName         = "/music.v1.MusicService/SearchSongs"
+	MusicService_GetVerses_FullMethodName           = "/music.v1.MusicService/GetVerses"
+	MusicService_GetEnrichmentStatus_FullMethodName = "/music.v1.MusicService/GetEnrichmentStatus"
+	MusicService_GetGroupStats_FullMethodName       = "/music.v1.MusicService/GetGroupStats"
+	MusicService_AddSong_FullMethodName             = "/music.v1.MusicService/AddSong"
+	MusicService_UpdateSong_FullMethodName          = "/music.v1.MusicService/UpdateSong"
+	MusicService_DeleteSong_FullMethodName          = "/music.v1.MusicService/DeleteSong"
+)
+
+// MusicServiceClient is the client API for MusicService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MusicService exposes the music library to internal services. It runs the same service layer as the HTTP
+// API: reads need a viewer access token, adding and updating songs an editor one and deleting songs an
+// admin one, sent as "authorization: Bearer <token>" metadata.
+type MusicServiceClient interface {
+	// ListSongs returns one page of the songs matching the filter
+	ListSongs(ctx context.Context, in *ListSongsRequest, opts ...grpc.CallOption) (*ListSongsResponse, error)
+	// CountSongs counts the songs matching the filter
+	CountSongs(ctx context.Context, in *CountSongsRequest, opts ...grpc.CallOption) (*CountSongsResponse, error)
+	// FindSong looks a song up by its exact group and title, ignoring case
+	FindSong(ctx context.Context, in *FindSongRequest, opts ...grpc.CallOption) (*FindSongResponse, error)
+	// SearchSongs finds the songs best matching the query
+	SearchSongs(ctx context.Context, in *SearchSongsRequest, opts ...grpc.CallOption) (*SearchSongsResponse, error)
+	// GetVerses returns one page of the verses of a song
+	GetVerses(ctx context.Context, in *GetVersesRequest, opts ...grpc.CallOption) (*GetVersesResponse, error)
+	// GetEnrichmentStatus reports how far the enrichment of a song has come
+	GetEnrichmentStatus(ctx context.Context, in *GetEnrichmentStatusRequest, opts ...grpc.CallOption) (*EnrichmentStatus, error)
+	// GetGroupStats summarizes the catalog of a group
+	GetGroupStats(ctx context.Context, in *GetGroupStatsRequest, opts ...grpc.CallOption) (*GroupStats, error)
+	// AddSong adds a song, fetching its details from the external API
+	AddSong(ctx context.Context, in *AddSongRequest, opts ...grpc.CallOption) (*AddSongResponse, error)
+	// UpdateSong replaces every field of a song
+	UpdateSong(ctx context.Context, in *UpdateSongRequest, opts ...grpc.CallOption) (*UpdateSongResponse, error)
+	// DeleteSong deletes a song
+	DeleteSong(ctx context.Context, in *DeleteSongRequest, opts ...grpc.CallOption) (*DeleteSongResponse, error)
+}
+
+type musicServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMusicServiceClient(cc grpc.ClientConnInterface) MusicServiceClient {
+	return &musicServiceClient{cc}
+}
+
+func (c *musicServiceClient) ListSongs(ctx context.Context, in *ListSongsRequest, opts ...grpc.CallOption) (*ListSongsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSongsResponse)
+	err := c.cc.Invoke(ctx, MusicService_ListSongs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) CountSongs(ctx context.Context, in *CountSongsRequest, opts ...grpc.CallOption) (*CountSongsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CountSongsResponse)
+	err := c.cc.Invoke(ctx, MusicService_CountSongs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) FindSong(ctx context.Context, in *FindSongRequest, opts ...grpc.CallOption) (*FindSongResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindSongResponse)
+	err := c.cc.Invoke(ctx, MusicService_FindSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) SearchSongs(ctx context.Context, in *SearchSongsRequest, opts ...grpc.CallOption) (*SearchSongsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchSongsResponse)
+	err := c.cc.Invoke(ctx, MusicService_SearchSongs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) GetVerses(ctx context.Context, in *GetVersesRequest, opts ...grpc.CallOption) (*GetVersesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetVersesResponse)
+	err := c.cc.Invoke(ctx, MusicService_GetVerses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) GetEnrichmentStatus(ctx context.Context, in *GetEnrichmentStatusRequest, opts ...grpc.CallOption) (*EnrichmentStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnrichmentStatus)
+	err := c.cc.Invoke(ctx, MusicService_GetEnrichmentStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) GetGroupStats(ctx context.Context, in *GetGroupStatsRequest, opts ...grpc.CallOption) (*GroupStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GroupStats)
+	err := c.cc.Invoke(ctx, MusicService_GetGroupStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) AddSong(ctx context.Context, in *AddSongRequest, opts ...grpc.CallOption) (*AddSongResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddSongResponse)
+	err := c.cc.Invoke(ctx, MusicService_AddSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) UpdateSong(ctx context.Context, in *UpdateSongRequest, opts ...grpc.CallOption) (*UpdateSongResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateSongResponse)
+	err := c.cc.Invoke(ctx, MusicService_UpdateSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) DeleteSong(ctx context.Context, in *DeleteSongRequest, opts ...grpc.CallOption) (*DeleteSongResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSongResponse)
+	err := c.cc.Invoke(ctx, MusicService_DeleteSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MusicServiceServer is the server API for MusicService service.
+// All implementations must embed UnimplementedMusicServiceServer
+// for forward compatibility.
+//
+// MusicService exposes the music library to internal services. It runs the same service layer as the HTTP
+// API: reads need a viewer access token, adding and updating songs an editor one and deleting songs an
+// admin one, sent as "authorization: Bearer <token>" metadata.
+type MusicServiceServer interface {
+	// ListSongs returns one page of the songs matching the filter
+	ListSongs(context.Context, *ListSongsRequest) (*ListSongsResponse, error)
+	// CountSongs counts the songs matching the filter
+	CountSongs(context.Context, *CountSongsRequest) (*CountSongsResponse, error)
+	// FindSong looks a song up by its exact group and title, ignoring case
+	FindSong(context.Context, *FindSongRequest) (*FindSongResponse, error)
+	// SearchSongs finds the songs best matching the query
+	SearchSongs(context.Context, *SearchSongsRequest) (*SearchSongsResponse, error)
+	// GetVerses returns one page of the verses of a song
+	GetVerses(context.Context, *GetVersesRequest) (*GetVersesResponse, error)
+	// GetEnrichmentStatus reports how far the enrichment of a song has come
+	GetEnrichmentStatus(context.Context, *GetEnrichmentStatusRequest) (*EnrichmentStatus, error)
+	// GetGroupStats summarizes the catalog of a group
+	GetGroupStats(context.Context, *GetGroupStatsRequest) (*GroupStats, error)
+	// AddSong adds a song, fetching its details from the external API
+	AddSong(context.Context, *AddSongRequest) (*AddSongResponse, error)
+	// UpdateSong replaces every field of a song
+	UpdateSong(context.Context, *UpdateSongRequest) (*UpdateSongResponse, error)
+	// DeleteSong deletes a song
+	DeleteSong(context.Context, *DeleteSongRequest) (*DeleteSongResponse, error)
+	mustEmbedUnimplementedMusicServiceServer()
+}
+
+// UnimplementedMusicServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMusicServiceServer struct{}
+
+func (UnimplementedMusicServiceServer) ListSongs(context.Context, *ListSongsRequest) (*ListSongsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSongs not implemented")
+}
+func (UnimplementedMusicServiceServer) CountSongs(context.Context, *CountSongsRequest) (*CountSongsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountSongs not implemented")
+}
+func (UnimplementedMusicServiceServer) FindSong(context.Context, *FindSongRequest) (*FindSongResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindSong not implemented")
+}
+func (UnimplementedMusicServiceServer) SearchSongs(context.Context, *SearchSongsRequest) (*SearchSongsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchSongs not implemented")
+}
+func (UnimplementedMusicServiceServer) GetVerses(context.Context, *GetVersesRequest) (*GetVersesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVerses not implemented")
+}
+func (UnimplementedMusicServiceServer) GetEnrichmentStatus(context.Context, *GetEnrichmentStatusRequest) (*EnrichmentStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEnrichmentStatus not implemented")
+}
+func (UnimplementedMusicServiceServer) GetGroupStats(context.Context, *GetGroupStatsRequest) (*GroupStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGroupStats not implemented")
+}
+func (UnimplementedMusicServiceServer) AddSong(context.Context, *AddSongRequest) (*AddSongResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddSong not implemented")
+}
+func (UnimplementedMusicServiceServer) UpdateSong(context.Context, *UpdateSongRequest) (*UpdateSongResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSong not implemented")
+}
+func (UnimplementedMusicServiceServer) DeleteSong(context.Context, *DeleteSongRequest) (*DeleteSongResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSong not implemented")
+}
+func (UnimplementedMusicServiceServer) mustEmbedUnimplementedMusicServiceServer() {}
+func (UnimplementedMusicServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeMusicServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MusicServiceServer will
+// result in compilation errors.
+type UnsafeMusicServiceServer interface {
+	mustEmbedUnimplementedMusicServiceServer()
+}
+
+func RegisterMusicServiceServer(s grpc.ServiceRegistrar, srv MusicServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMusicServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MusicService_ServiceDesc, srv)
+}
+
+func _MusicService_ListSongs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSongsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).ListSongs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_ListSongs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).ListSongs(ctx, req.(*ListSongsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_CountSongs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountSongsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).CountSongs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_CountSongs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).CountSongs(ctx, req.(*CountSongsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_FindSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).FindSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_FindSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).FindSong(ctx, req.(*FindSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_SearchSongs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchSongsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).SearchSongs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_SearchSongs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).SearchSongs(ctx, req.(*SearchSongsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_GetVerses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVersesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).GetVerses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_GetVerses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).GetVerses(ctx, req.(*GetVersesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_GetEnrichmentStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEnrichmentStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).GetEnrichmentStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_GetEnrichmentStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).GetEnrichmentStatus(ctx, req.(*GetEnrichmentStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_GetGroupStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGroupStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).GetGroupStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_GetGroupStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).GetGroupStats(ctx, req.(*GetGroupStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_AddSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).AddSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_AddSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).AddSong(ctx, req.(*AddSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_UpdateSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).UpdateSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_UpdateSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).UpdateSong(ctx, req.(*UpdateSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_DeleteSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).DeleteSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_DeleteSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).DeleteSong(ctx, req.(*DeleteSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MusicService_ServiceDesc is the grpc.ServiceDesc for MusicService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MusicService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "music.v1.MusicService",
+	HandlerType: (*MusicServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListSongs",
+			Handler:    _MusicService_ListSongs_Handler,
+		},
+		{
+			MethodName: "CountSongs",
+			Handler:    _MusicService_CountSongs_Handler,
+		},
+		{
+			MethodName: "FindSong",
+			Handler:    _MusicService_FindSong_Handler,
+		},
+		{
+			MethodName: "SearchSongs",
+			Handler:    _MusicService_SearchSongs_Handler,
+		},
+		{
+			MethodName: "GetVerses",
+			Handler:    _MusicService_GetVerses_Handler,
+		},
+		{
+			MethodName: "GetEnrichmentStatus",
+			Handler:    _MusicService_GetEnrichmentStatus_Handler,
+		},
+		{
+			MethodName: "GetGroupStats",
+			Handler:    _MusicService_GetGroupStats_Handler,
+		},
+		{
+			MethodName: "AddSong",
+			Handler:    _MusicService_AddSong_Handler,
+		},
+		{
+			MethodName: "UpdateSong",
+			Handler:    _MusicService_UpdateSong_Handler,
+		},
+		{
+			MethodName: "DeleteSong",
+			Handler:    _MusicService_DeleteSong_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "music.proto",
+}
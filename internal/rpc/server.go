@@ -0,0 +1,260 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"music-library/internal/auth"
+	"music-library/internal/models"
+	"music-library/internal/rpc/musicpb"
+	"music-library/internal/service"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative -I musicpb musicpb/music.proto
+
+// Defaults for paging fields left zero, as the HTTP API applies them to missing query parameters
+const (
+	defaultPage  = 1
+	defaultLimit = 10
+	// maxSearchLimit bounds the number of results of a single search
+	maxSearchLimit = 100
+)
+
+// invalidArgumentErrors are the service errors caused by the request itself
+var invalidArgumentErrors = []error{
+	service.ErrUnsupportedField,
+	service.ErrUnsupportedSort,
+	service.ErrUnsupportedSearchMode,
+	service.ErrInvalidReleaseDate,
+	service.ErrInvalidVerseDelimiter,
+}
+
+// Server implements the gRPC MusicService on the same service layer as the HTTP handlers
+type Server struct {
+	musicpb.UnimplementedMusicServiceServer
+	svc    *service.MusicService
+	logger *zap.Logger
+}
+
+// NewServer creates a Server running the operations on the music service
+func NewServer(svc *service.MusicService, logger *zap.Logger) *Server {
+	return &Server{svc: svc, logger: logger}
+}
+
+// NewGRPCServer creates a gRPC server exposing the music service. Calls are authenticated with the access
+// tokens of the HTTP API and, like HTTP writes, refused while the service is degraded.
+func NewGRPCServer(svc *service.MusicService, tokens *auth.Tokens, logger *zap.Logger) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		Recovery(logger),
+		Logger(logger),
+		Authorize(tokens, logger),
+		ReadOnly(svc.Degraded, logger),
+	))
+	musicpb.RegisterMusicServiceServer(server, NewServer(svc, logger))
+	return server
+}
+
+// statusError turns a service error into the gRPC status matching the HTTP API's response to it.
+// notFound describes what sql.ErrNoRows means for the call.
+func (s *Server) statusError(method string, err error, notFound string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return status.Error(codes.NotFound, notFound)
+	case errors.Is(err, service.ErrPageOutOfRange):
+		return status.Error(codes.OutOfRange, err.Error())
+	case errors.Is(err, service.ErrDegraded):
+		return status.Error(codes.Unavailable, "database unavailable")
+	case errors.Is(err, service.ErrNoExternalData):
+		return status.Error(codes.FailedPrecondition, "external API provided no data for the song")
+	case errors.Is(err, service.ErrLegalHold):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrSemanticSearchUnavailable):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	for _, invalid := range invalidArgumentErrors {
+		if errors.Is(err, invalid) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	s.logger.Error("gRPC call failed", zap.String("method", method), zap.Error(err))
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// paging applies the defaults to a zero page and limit and rejects negative ones
+func paging(page, limit int32) (int, int, error) {
+	if page == 0 {
+		page = defaultPage
+	}
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	if page < 1 {
+		return 0, 0, status.Error(codes.InvalidArgument, "invalid page number")
+	}
+	if limit < 1 {
+		return 0, 0, status.Error(codes.InvalidArgument, "invalid limit")
+	}
+	return int(page), int(limit), nil
+}
+
+// songFilter converts a filter message, which may be nil
+func songFilter(filter *musicpb.SongFilter) models.SongFilter {
+	return models.SongFilter{Group: filter.GetGroup(), Song: filter.GetSong(), Missing: filter.GetMissing()}
+}
+
+// ListSongs returns one page of the songs matching the filter
+func (s *Server) ListSongs(ctx context.Context, req *musicpb.ListSongsRequest) (*musicpb.ListSongsResponse, error) {
+	page, limit, err := paging(req.GetPage(), req.GetLimit())
+	if err != nil {
+		return nil, err
+	}
+	sort := req.GetSort()
+	if sort == "" {
+		sort = "id"
+	}
+	songs, err := s.svc.GetSongs(ctx, songFilter(req.GetFilter()), sort, page, limit)
+	if err != nil {
+		return nil, s.statusError("ListSongs", err, "")
+	}
+	return &musicpb.ListSongsResponse{
+		Songs:      songMessages(songs.Data),
+		Total:      int64(songs.Total),
+		Page:       int32(songs.Page),
+		Limit:      int32(songs.Limit),
+		TotalPages: int32(songs.TotalPages),
+	}, nil
+}
+
+// CountSongs counts the songs matching the filter
+func (s *Server) CountSongs(ctx context.Context, req *musicpb.CountSongsRequest) (*musicpb.CountSongsResponse, error) {
+	total, err := s.svc.CountSongs(ctx, songFilter(req.GetFilter()))
+	if err != nil {
+		return nil, s.statusError("CountSongs", err, "")
+	}
+	return &musicpb.CountSongsResponse{Total: int64(total)}, nil
+}
+
+// FindSong looks a song up by its exact group and title, ignoring case
+func (s *Server) FindSong(ctx context.Context, req *musicpb.FindSongRequest) (*musicpb.FindSongResponse, error) {
+	if req.GetGroup() == "" || req.GetSong() == "" {
+		return nil, status.Error(codes.InvalidArgument, "group and song are required")
+	}
+	id, exists, err := s.svc.SongExists(ctx, req.GetGroup(), req.GetSong())
+	if err != nil {
+		return nil, s.statusError("FindSong", err, "")
+	}
+	return &musicpb.FindSongResponse{Exists: exists, Id: int64(id)}, nil
+}
+
+// SearchSongs finds the songs best matching the query
+func (s *Server) SearchSongs(ctx context.Context, req *musicpb.SearchSongsRequest) (*musicpb.SearchSongsResponse, error) {
+	query := strings.TrimSpace(req.GetQuery())
+	if query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	mode := req.GetMode()
+	if mode == "" {
+		mode = service.SearchModeKeyword
+	}
+	_, limit, err := paging(0, req.GetLimit())
+	if err != nil {
+		return nil, err
+	}
+	if limit > maxSearchLimit {
+		return nil, status.Error(codes.InvalidArgument, "invalid limit")
+	}
+	results, err := s.svc.SearchSongs(ctx, query, mode, limit)
+	if err != nil {
+		return nil, s.statusError("SearchSongs", err, "")
+	}
+	resp := &musicpb.SearchSongsResponse{Results: make([]*musicpb.SearchResult, len(results))}
+	for i, result := range results {
+		resp.Results[i] = &musicpb.SearchResult{Song: songMessage(result.Song), Score: result.Score, Snippet: result.Snippet}
+	}
+	return resp, nil
+}
+
+// GetVerses returns one page of the verses of a song
+func (s *Server) GetVerses(ctx context.Context, req *musicpb.GetVersesRequest) (*musicpb.GetVersesResponse, error) {
+	page, limit, err := paging(req.GetPage(), req.GetLimit())
+	if err != nil {
+		return nil, err
+	}
+	verses, err := s.svc.GetVerses(ctx, int(req.GetSongId()), page, limit, req.GetDelimiter())
+	if err != nil {
+		return nil, s.statusError("GetVerses", err, "song not found")
+	}
+	return versesMessage(verses), nil
+}
+
+// GetEnrichmentStatus reports how far the enrichment of a song has come
+func (s *Server) GetEnrichmentStatus(ctx context.Context, req *musicpb.GetEnrichmentStatusRequest) (*musicpb.EnrichmentStatus, error) {
+	enrichment, err := s.svc.GetEnrichmentStatus(ctx, int(req.GetSongId()))
+	if err != nil {
+		return nil, s.statusError("GetEnrichmentStatus", err, "song not found")
+	}
+	return &musicpb.EnrichmentStatus{
+		SongId:     int64(enrichment.SongID),
+		Status:     enrichment.Status,
+		Error:      enrichment.Error,
+		EnrichedAt: timestamp(enrichment.EnrichedAt),
+		UpdatedAt:  timestamp(&enrichment.UpdatedAt),
+	}, nil
+}
+
+// GetGroupStats summarizes the catalog of a group
+func (s *Server) GetGroupStats(ctx context.Context, req *musicpb.GetGroupStatsRequest) (*musicpb.GroupStats, error) {
+	if req.GetGroup() == "" {
+		return nil, status.Error(codes.InvalidArgument, "group is required")
+	}
+	stats, err := s.svc.GetGroupStats(ctx, req.GetGroup())
+	if err != nil {
+		return nil, s.statusError("GetGroupStats", err, "group not found")
+	}
+	return &musicpb.GroupStats{
+		Group:           stats.Group,
+		Songs:           int64(stats.Songs),
+		EarliestRelease: stats.EarliestRelease,
+		LatestRelease:   stats.LatestRelease,
+		TotalViews:      stats.TotalViews,
+		AverageRating:   stats.AverageRating,
+		MostViewed:      songMessages(stats.MostViewed),
+	}, nil
+}
+
+// AddSong adds a song, fetching its details from the external API
+func (s *Server) AddSong(ctx context.Context, req *musicpb.AddSongRequest) (*musicpb.AddSongResponse, error) {
+	if req.GetGroup() == "" || req.GetSong() == "" {
+		return nil, status.Error(codes.InvalidArgument, "group and song are required")
+	}
+	id, enrichmentStatus, err := s.svc.AddSong(ctx, req.GetGroup(), req.GetSong())
+	if err != nil {
+		return nil, s.statusError("AddSong", err, "")
+	}
+	return &musicpb.AddSongResponse{Id: int64(id), EnrichmentStatus: enrichmentStatus}, nil
+}
+
+// UpdateSong replaces every field of a song
+func (s *Server) UpdateSong(ctx context.Context, req *musicpb.UpdateSongRequest) (*musicpb.UpdateSongResponse, error) {
+	err := s.svc.UpdateSong(ctx, int(req.GetId()), req.GetGroup(), req.GetSong(), req.GetReleaseDate(), req.GetText(), req.GetLink())
+	if err != nil {
+		return nil, s.statusError("UpdateSong", err, "song not found")
+	}
+	return &musicpb.UpdateSongResponse{}, nil
+}
+
+// DeleteSong deletes a song
+func (s *Server) DeleteSong(ctx context.Context, req *musicpb.DeleteSongRequest) (*musicpb.DeleteSongResponse, error) {
+	if err := s.svc.DeleteSong(ctx, int(req.GetId())); err != nil {
+		return nil, s.statusError("DeleteSong", err, "song not found")
+	}
+	return &musicpb.DeleteSongResponse{}, nil
+}
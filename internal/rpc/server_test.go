@@ -0,0 +1,127 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"music-library/internal/auth"
+	"music-library/internal/models"
+	"music-library/internal/rpc/musicpb"
+	"music-library/internal/service"
+)
+
+// newTestClient serves the music service over an in-memory connection
+func newTestClient(t *testing.T) (musicpb.MusicServiceClient, *auth.Tokens) {
+	tokens := auth.NewTokens(auth.Config{Secret: []byte("0123456789abcdef0123456789abcdef")})
+	server := NewGRPCServer(service.NewMusicService(nil, zap.NewNop(), http.DefaultClient), tokens, zap.NewNop())
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return musicpb.NewMusicServiceClient(conn), tokens
+}
+
+// withRole returns a context carrying the access token of a user holding the role
+func withRole(t *testing.T, tokens *auth.Tokens, role string) context.Context {
+	pair, err := tokens.Issue(7, "alice", role)
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+pair.AccessToken)
+}
+
+func TestAuthorize(t *testing.T) {
+	client, tokens := newTestClient(t)
+
+	_, err := client.SearchSongs(context.Background(), &musicpb.SearchSongsRequest{Query: "muse"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	bad := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer forged")
+	_, err = client.SearchSongs(bad, &musicpb.SearchSongsRequest{Query: "muse"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	viewer := withRole(t, tokens, models.RoleViewer)
+	_, err = client.DeleteSong(viewer, &musicpb.DeleteSongRequest{Id: 1})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.AddSong(viewer, &musicpb.AddSongRequest{Group: "Muse", Song: "Uprising"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Calls the role allows reach the validation
+	_, err = client.SearchSongs(viewer, &musicpb.SearchSongsRequest{Query: " "})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.ListSongs(viewer, &musicpb.ListSongsRequest{Page: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.AddSong(withRole(t, tokens, models.RoleEditor), &musicpb.AddSongRequest{Group: "Muse"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestReadOnlyAndRecovery(t *testing.T) {
+	degraded := false
+	readOnly := ReadOnly(func() bool { return degraded }, zap.NewNop())
+	ok := func(context.Context, any) (any, error) { return "done", nil }
+	write := &grpc.UnaryServerInfo{FullMethod: musicpb.MusicService_UpdateSong_FullMethodName}
+	read := &grpc.UnaryServerInfo{FullMethod: musicpb.MusicService_ListSongs_FullMethodName}
+
+	_, err := readOnly(context.Background(), nil, write, ok)
+	assert.NoError(t, err)
+	degraded = true
+	_, err = readOnly(context.Background(), nil, write, ok)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	_, err = readOnly(context.Background(), nil, read, ok)
+	assert.NoError(t, err, "reads go on while degraded")
+
+	_, err = Recovery(zap.NewNop())(context.Background(), nil, read, func(context.Context, any) (any, error) {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestStatusError(t *testing.T) {
+	s := NewServer(nil, zap.NewNop())
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{sql.ErrNoRows, codes.NotFound},
+		{fmt.Errorf("%w: page 9 is past the last page 2", service.ErrPageOutOfRange), codes.OutOfRange},
+		{fmt.Errorf("%w: bpm", service.ErrUnsupportedSort), codes.InvalidArgument},
+		{service.ErrDegraded, codes.Unavailable},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{fmt.Errorf("connection reset"), codes.Internal},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, status.Code(s.statusError("Test", tt.err, "song not found")), tt.err.Error())
+	}
+}
+
+func TestSongMessage(t *testing.T) {
+	releaseDate, duration := "16.07.2006", 212000
+	created := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	notes := "Cleared for sync"
+	message := songMessage(models.Song{ID: 3, Group: "Muse", Song: "Supermassive Black Hole", ReleaseDate: &releaseDate,
+		CreatedAt: created, DurationMs: &duration, Notes: &notes, Views: 12})
+
+	assert.Equal(t, int64(3), message.GetId())
+	assert.Equal(t, releaseDate, message.GetReleaseDate())
+	assert.Nil(t, message.Text, "unknown fields stay unset")
+	assert.Equal(t, created, message.GetCreatedAt().AsTime())
+	assert.Nil(t, message.GetEnrichedAt())
+	assert.Equal(t, int64(duration), message.GetDurationMs())
+	assert.Equal(t, int64(12), message.GetViews())
+}
@@ -0,0 +1,26 @@
+// Package secrets reads credentials from the environment, or from files mounted as Docker or Kubernetes
+// secrets, so they need not appear in the environment of the process.
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Read returns the value of the environment variable key, or the trimmed contents of the file named by
+// key_FILE when the variable itself is not set. An empty string is returned when neither is set.
+func Read(key string) (string, error) {
+	if value, ok := os.LookupEnv(key); ok {
+		return value, nil
+	}
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s_FILE: %w", key, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,33 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0o600))
+	t.Setenv("TEST_SECRET_FILE", path)
+
+	value, err := Read("TEST_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", value, "the file is read and trimmed when the variable is not set")
+
+	t.Setenv("TEST_SECRET", "from-env")
+	value, err = Read("TEST_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", value, "the variable takes precedence over the file")
+
+	t.Setenv("MISSING_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+	_, err = Read("MISSING_SECRET")
+	assert.ErrorContains(t, err, "MISSING_SECRET_FILE")
+
+	value, err = Read("UNSET_SECRET")
+	require.NoError(t, err)
+	assert.Empty(t, value)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// ErrInvalidAlbum is returned when creating or replacing an album without a title or group, or with an
+// invalid release date or artwork URL
+var ErrInvalidAlbum = errors.New("invalid album")
+
+// validateAlbum checks the fields of an album, whose release date is expected in the stored DD.MM.YYYY layout
+func validateAlbum(album models.AlbumInput) error {
+	if strings.TrimSpace(album.Title) == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidAlbum)
+	}
+	if strings.TrimSpace(album.Group) == "" {
+		return fmt.Errorf("%w: group is required", ErrInvalidAlbum)
+	}
+	if _, err := NormalizeReleaseDate(album.ReleaseDate, DateFormatDefault); err != nil {
+		return err
+	}
+	if album.ArtworkURL != "" {
+		parsed, err := url.Parse(album.ArtworkURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%w: artwork_url must be an absolute http or https URL", ErrInvalidAlbum)
+		}
+	}
+	return nil
+}
+
+// CreateAlbum adds an album and returns it as stored
+func (s *MusicService) CreateAlbum(ctx context.Context, album models.AlbumInput) (_ models.Album, err error) {
+	defer metrics.ObserveOperation("create_album", time.Now(), &err)
+	s.logger.Debug("Creating album", zap.String("title", album.Title), zap.String("group", album.Group))
+	if err := validateAlbum(album); err != nil {
+		s.logger.Warn("Invalid album", zap.Error(err))
+		return models.Album{}, err
+	}
+	id, err := s.repo.CreateAlbum(ctx, album)
+	if err != nil {
+		s.logger.Error("Failed to create album", zap.Error(err))
+		return models.Album{}, err
+	}
+	s.logger.Info("Album created successfully", zap.Int("id", id))
+	return s.repo.GetAlbum(ctx, id)
+}
+
+// GetAlbum returns an album, or sql.ErrNoRows when it does not exist
+func (s *MusicService) GetAlbum(ctx context.Context, id int) (models.Album, error) {
+	s.logger.Debug("Fetching album", zap.Int("id", id))
+	return s.repo.GetAlbum(ctx, id)
+}
+
+// GetAlbums returns a page of albums ordered by ID
+func (s *MusicService) GetAlbums(ctx context.Context, page, limit int) (_ models.AlbumPage, err error) {
+	defer metrics.ObserveOperation("get_albums", time.Now(), &err)
+	s.logger.Debug("Fetching albums", zap.Int("page", page), zap.Int("limit", limit))
+	albums, total, err := s.repo.GetAlbums(ctx, page, limit)
+	if err != nil {
+		s.logger.Error("Failed to fetch albums", zap.Error(err))
+		return models.AlbumPage{}, err
+	}
+	return models.AlbumPage{
+		Data:       albums,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
+}
+
+// UpdateAlbum replaces the fields of an album and returns it as stored. sql.ErrNoRows is returned when it
+// does not exist.
+func (s *MusicService) UpdateAlbum(ctx context.Context, id int, album models.AlbumInput) (_ models.Album, err error) {
+	defer metrics.ObserveOperation("update_album", time.Now(), &err)
+	s.logger.Debug("Updating album", zap.Int("id", id))
+	if err := validateAlbum(album); err != nil {
+		s.logger.Warn("Invalid album", zap.Int("id", id), zap.Error(err))
+		return models.Album{}, err
+	}
+	if err := s.repo.UpdateAlbum(ctx, id, album); err != nil {
+		if err != sql.ErrNoRows {
+			s.logger.Error("Failed to update album", zap.Int("id", id), zap.Error(err))
+		}
+		return models.Album{}, err
+	}
+	s.logger.Info("Album updated successfully", zap.Int("id", id))
+	return s.repo.GetAlbum(ctx, id)
+}
+
+// DeleteAlbum deletes an album, keeping its songs outside of any album. sql.ErrNoRows is returned when it
+// does not exist.
+func (s *MusicService) DeleteAlbum(ctx context.Context, id int) (err error) {
+	defer metrics.ObserveOperation("delete_album", time.Now(), &err)
+	s.logger.Debug("Deleting album", zap.Int("id", id))
+	if err := s.repo.DeleteAlbum(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Info("Album deleted successfully", zap.Int("id", id))
+	return nil
+}
+
+// GetAlbumSongs returns a page of the songs of an album ordered by ID. sql.ErrNoRows is returned when the
+// album does not exist.
+func (s *MusicService) GetAlbumSongs(ctx context.Context, id, page, limit int) (_ models.SongPage, err error) {
+	defer metrics.ObserveOperation("get_album_songs", time.Now(), &err)
+	s.logger.Debug("Fetching album songs", zap.Int("id", id), zap.Int("page", page), zap.Int("limit", limit))
+	if _, err := s.repo.GetAlbum(ctx, id); err != nil {
+		return models.SongPage{}, err
+	}
+	songs, total, err := s.repo.GetAlbumSongs(ctx, id, page, limit)
+	if err != nil {
+		s.logger.Error("Failed to fetch album songs", zap.Int("id", id), zap.Error(err))
+		return models.SongPage{}, err
+	}
+	return models.SongPage{
+		Data:       songs,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
+}
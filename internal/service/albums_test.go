@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// albumRepository knows no album
+type albumRepository struct {
+	repository.Repository
+}
+
+func (albumRepository) ConfigureStatementTimeout(time.Duration) {}
+
+func (albumRepository) GetAlbum(context.Context, int) (models.Album, error) {
+	return models.Album{}, sql.ErrNoRows
+}
+
+func TestValidateAlbum(t *testing.T) {
+	tests := []struct {
+		name  string
+		album models.AlbumInput
+		err   error
+	}{
+		{"complete", models.AlbumInput{Title: "Absolution", Group: "Muse", ReleaseDate: "15.09.2003", ArtworkURL: "https://example.com/a.jpg"}, nil},
+		{"optional fields unknown", models.AlbumInput{Title: "Absolution", Group: "Muse"}, nil},
+		{"no title", models.AlbumInput{Title: " ", Group: "Muse"}, ErrInvalidAlbum},
+		{"no group", models.AlbumInput{Title: "Absolution"}, ErrInvalidAlbum},
+		{"invalid release date", models.AlbumInput{Title: "Absolution", Group: "Muse", ReleaseDate: "2003-09-15"}, ErrInvalidReleaseDate},
+		{"relative artwork URL", models.AlbumInput{Title: "Absolution", Group: "Muse", ArtworkURL: "/a.jpg"}, ErrInvalidAlbum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAlbum(tt.album)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUpdateSongPartialRejectsUnknownAlbum(t *testing.T) {
+	svc := NewMusicService(albumRepository{}, zap.NewNop(), nil)
+	patch := models.SongPatch{AlbumID: models.OptionalInt{Set: true, Value: 9}}
+	err := svc.UpdateSongPartial(context.Background(), 1, patch)
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"music-library/internal/analytics"
+	"music-library/internal/changes"
+)
+
+// StartAnalyticsExport publishes view and audit events to the batcher and exports them until ctx is cancelled,
+// when the buffered events are exported one last time. Without it no events are collected.
+func (s *MusicService) StartAnalyticsExport(ctx context.Context, batcher *analytics.Batcher) {
+	s.logger.Info("Starting analytics export")
+	s.analytics = batcher
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		batcher.Run(ctx)
+		s.logger.Info("Analytics export stopped", zap.Int64("dropped", batcher.Dropped()))
+	}()
+}
+
+// publish queues an analytics event when analytics export is enabled, and notifies change subscribers of
+// every event but views. Catalog changes recorded in the outbox are pushed to subscribers by the relay, which
+// is only woken here; without a relay they are pushed directly.
+func (s *MusicService) publish(eventType string, songID, count int) {
+	_, catalog := changes.EventOf(changes.Change{Type: eventType})
+	switch {
+	case catalog && s.relay != nil:
+		s.relay.Notify()
+	case eventType != analytics.EventView:
+		s.changes.Publish(eventType, songID, count)
+	}
+	if s.analytics == nil {
+		return
+	}
+	s.analytics.Publish(analytics.Event{Type: eventType, SongID: songID, Count: count})
+}
+
+// audit queues an audit event when analytics export is enabled, without notifying change subscribers as
+// nothing changed
+func (s *MusicService) audit(eventType string, songID, count int) {
+	if s.analytics == nil {
+		return
+	}
+	s.analytics.Publish(analytics.Event{Type: eventType, SongID: songID, Count: count})
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/analytics"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// ErrInvalidArtist is returned when creating or replacing an artist without a name, or with an invalid
+// country or formation year
+var ErrInvalidArtist = errors.New("invalid artist")
+
+// ErrArtistExists is returned when naming an artist after another one
+var ErrArtistExists = errors.New("artist already exists")
+
+// ErrArtistHasSongs is returned when deleting an artist that still has songs
+var ErrArtistHasSongs = errors.New("artist has songs")
+
+// minFormedYear is the earliest formation year accepted
+const minFormedYear = 1000
+
+// normalizeArtist checks the fields of an artist and returns them with the name trimmed and the country
+// code upper-cased
+func normalizeArtist(artist models.ArtistInput) (models.ArtistInput, error) {
+	artist.Name = strings.TrimSpace(artist.Name)
+	if artist.Name == "" {
+		return artist, fmt.Errorf("%w: name is required", ErrInvalidArtist)
+	}
+	artist.Country = strings.ToUpper(strings.TrimSpace(artist.Country))
+	if artist.Country != "" && (len(artist.Country) != 2 || strings.Trim(artist.Country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "") {
+		return artist, fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", ErrInvalidArtist)
+	}
+	if artist.FormedYear != 0 && (artist.FormedYear < minFormedYear || artist.FormedYear > time.Now().Year()) {
+		return artist, fmt.Errorf("%w: formed_year must be between %d and the current year", ErrInvalidArtist, minFormedYear)
+	}
+	return artist, nil
+}
+
+// CreateArtist adds an artist and returns it as stored. ErrArtistExists is returned when the name is taken.
+func (s *MusicService) CreateArtist(ctx context.Context, artist models.ArtistInput) (_ models.Artist, err error) {
+	defer metrics.ObserveOperation("create_artist", time.Now(), &err)
+	s.logger.Debug("Creating artist", zap.String("name", artist.Name))
+	artist, err = normalizeArtist(artist)
+	if err != nil {
+		s.logger.Warn("Invalid artist", zap.Error(err))
+		return models.Artist{}, err
+	}
+	id, err := s.repo.CreateArtist(ctx, artist)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Artist{}, fmt.Errorf("%w: %s", ErrArtistExists, artist.Name)
+		}
+		s.logger.Error("Failed to create artist", zap.Error(err))
+		return models.Artist{}, err
+	}
+	s.logger.Info("Artist created successfully", zap.Int("id", id))
+	return s.repo.GetArtist(ctx, id)
+}
+
+// GetArtist returns an artist, or sql.ErrNoRows when it does not exist
+func (s *MusicService) GetArtist(ctx context.Context, id int) (models.Artist, error) {
+	s.logger.Debug("Fetching artist", zap.Int("id", id))
+	return s.repo.GetArtist(ctx, id)
+}
+
+// GetArtists returns a page of artists ordered by ID
+func (s *MusicService) GetArtists(ctx context.Context, page, limit int) (_ models.ArtistPage, err error) {
+	defer metrics.ObserveOperation("get_artists", time.Now(), &err)
+	s.logger.Debug("Fetching artists", zap.Int("page", page), zap.Int("limit", limit))
+	artists, total, err := s.repo.GetArtists(ctx, page, limit)
+	if err != nil {
+		s.logger.Error("Failed to fetch artists", zap.Error(err))
+		return models.ArtistPage{}, err
+	}
+	return models.ArtistPage{
+		Data:       artists,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
+}
+
+// UpdateArtist replaces the fields of an artist and returns it as stored. Renaming the artist renames the
+// group of its songs, which is refused with ErrLegalHold while one of them is on legal hold. sql.ErrNoRows
+// is returned when the artist does not exist and ErrArtistExists when another artist has the name.
+func (s *MusicService) UpdateArtist(ctx context.Context, id int, artist models.ArtistInput) (_ models.Artist, err error) {
+	defer metrics.ObserveOperation("update_artist", time.Now(), &err)
+	s.logger.Debug("Updating artist", zap.Int("id", id))
+	artist, err = normalizeArtist(artist)
+	if err != nil {
+		s.logger.Warn("Invalid artist", zap.Int("id", id), zap.Error(err))
+		return models.Artist{}, err
+	}
+	var renamed []int
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		other, err := s.repo.GetArtistByName(ctx, artist.Name)
+		switch {
+		case err == nil && other.ID != id:
+			return fmt.Errorf("%w: %s", ErrArtistExists, artist.Name)
+		case err != nil && err != sql.ErrNoRows:
+			return err
+		}
+		renamed, err = s.repo.UpdateArtist(ctx, id, artist)
+		if err != nil {
+			return err
+		}
+		if len(renamed) == 0 {
+			return nil
+		}
+		if err := s.checkLegalHold(ctx, "update_artist", renamed...); err != nil {
+			return err
+		}
+		for _, songID := range renamed {
+			if err := s.record(ctx, analytics.EventSongUpdated, songID, 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if err != sql.ErrNoRows && !errors.Is(err, ErrArtistExists) && !errors.Is(err, ErrLegalHold) {
+			s.logger.Error("Failed to update artist", zap.Int("id", id), zap.Error(err))
+		}
+		return models.Artist{}, err
+	}
+	for _, songID := range renamed {
+		s.publish(analytics.EventSongUpdated, songID, 1)
+	}
+	s.logger.Info("Artist updated successfully", zap.Int("id", id), zap.Int("renamed_songs", len(renamed)))
+	return s.repo.GetArtist(ctx, id)
+}
+
+// DeleteArtist deletes an artist without songs. sql.ErrNoRows is returned when it does not exist and
+// ErrArtistHasSongs while songs name it as their group.
+func (s *MusicService) DeleteArtist(ctx context.Context, id int) (err error) {
+	defer metrics.ObserveOperation("delete_artist", time.Now(), &err)
+	s.logger.Debug("Deleting artist", zap.Int("id", id))
+	_, songs, err := s.repo.GetArtistSongs(ctx, id, 1, 1)
+	if err != nil {
+		return err
+	}
+	if songs > 0 {
+		s.logger.Warn("Artist with songs not deleted", zap.Int("id", id), zap.Int("songs", songs))
+		return fmt.Errorf("%w: %d songs", ErrArtistHasSongs, songs)
+	}
+	if err := s.repo.DeleteArtist(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Info("Artist deleted successfully", zap.Int("id", id))
+	return nil
+}
+
+// GetArtistSongs returns a page of the songs of an artist ordered by ID. sql.ErrNoRows is returned when the
+// artist does not exist.
+func (s *MusicService) GetArtistSongs(ctx context.Context, id, page, limit int) (_ models.SongPage, err error) {
+	defer metrics.ObserveOperation("get_artist_songs", time.Now(), &err)
+	s.logger.Debug("Fetching artist songs", zap.Int("id", id), zap.Int("page", page), zap.Int("limit", limit))
+	if _, err := s.repo.GetArtist(ctx, id); err != nil {
+		return models.SongPage{}, err
+	}
+	songs, total, err := s.repo.GetArtistSongs(ctx, id, page, limit)
+	if err != nil {
+		s.logger.Error("Failed to fetch artist songs", zap.Int("id", id), zap.Error(err))
+		return models.SongPage{}, err
+	}
+	return models.SongPage{
+		Data:       songs,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// artistRepository serves an artist with a number of songs
+type artistRepository struct {
+	repository.Repository
+	songs   int
+	deleted bool
+}
+
+func (r *artistRepository) ConfigureStatementTimeout(time.Duration) {}
+
+func (r *artistRepository) GetArtistSongs(context.Context, int, int, int) ([]models.Song, int, error) {
+	return nil, r.songs, nil
+}
+
+func (r *artistRepository) DeleteArtist(context.Context, int) error {
+	r.deleted = true
+	return nil
+}
+
+func TestNormalizeArtist(t *testing.T) {
+	artist, err := normalizeArtist(models.ArtistInput{Name: " Muse ", Country: "gb", FormedYear: 1994})
+	require.NoError(t, err)
+	assert.Equal(t, models.ArtistInput{Name: "Muse", Country: "GB", FormedYear: 1994}, artist)
+
+	for name, input := range map[string]models.ArtistInput{
+		"no name":        {Name: " "},
+		"country name":   {Name: "Muse", Country: "England"},
+		"country digits": {Name: "Muse", Country: "44"},
+		"ancient year":   {Name: "Muse", FormedYear: 94},
+		"future year":    {Name: "Muse", FormedYear: time.Now().Year() + 1},
+	} {
+		_, err := normalizeArtist(input)
+		assert.ErrorIs(t, err, ErrInvalidArtist, name)
+	}
+}
+
+func TestDeleteArtistWithSongs(t *testing.T) {
+	repo := &artistRepository{songs: 3}
+	svc := NewMusicService(repo, zap.NewNop(), nil)
+	assert.ErrorIs(t, svc.DeleteArtist(context.Background(), 1), ErrArtistHasSongs)
+	assert.False(t, repo.deleted)
+
+	repo.songs = 0
+	require.NoError(t, svc.DeleteArtist(context.Background(), 1))
+	assert.True(t, repo.deleted)
+}
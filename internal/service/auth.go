@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"music-library/internal/auth"
+	"music-library/internal/models"
+)
+
+// ErrUserExists is returned when registering a username that is already taken
+var ErrUserExists = errors.New("username already taken")
+
+// ErrInvalidCredentials is returned when a login does not match any user and password
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// ErrInvalidRegistration is returned when a registration has an empty username or a too short password
+var ErrInvalidRegistration = errors.New("invalid registration")
+
+// ErrUnsupportedRole is returned when assigning a role that does not exist
+var ErrUnsupportedRole = errors.New("unsupported role")
+
+// ErrAuthDisabled is returned by the authentication methods until ConfigureAuth is called
+var ErrAuthDisabled = errors.New("authentication is not configured")
+
+// ConfigureAuth sets the issuer of the tokens handed out on login and refresh
+func (s *MusicService) ConfigureAuth(tokens *auth.Tokens) {
+	s.tokens = tokens
+}
+
+// Register creates a viewer account with a bcrypt hash of the password; admins grant further roles
+func (s *MusicService) Register(ctx context.Context, username, password string) (models.User, error) {
+	username = strings.TrimSpace(username)
+	s.logger.Debug("Registering user", zap.String("username", username))
+	if username == "" {
+		return models.User{}, fmt.Errorf("%w: username is required", ErrInvalidRegistration)
+	}
+	if len(password) < auth.MinPasswordLength {
+		return models.User{}, fmt.Errorf("%w: password must be at least %d characters", ErrInvalidRegistration, auth.MinPasswordLength)
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		s.logger.Error("Failed to hash password", zap.Error(err))
+		return models.User{}, err
+	}
+	id, err := s.repo.CreateUser(ctx, username, hash, models.RoleViewer)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			s.logger.Warn("Username already taken", zap.String("username", username))
+			return models.User{}, ErrUserExists
+		}
+		return models.User{}, err
+	}
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return models.User{}, err
+	}
+	s.logger.Info("User registered successfully", zap.Int("id", id))
+	return user, nil
+}
+
+// Login checks the credentials and issues a token pair for the user
+func (s *MusicService) Login(ctx context.Context, username, password string) (auth.TokenPair, error) {
+	s.logger.Debug("Logging in user", zap.String("username", username))
+	if s.tokens == nil {
+		return auth.TokenPair{}, ErrAuthDisabled
+	}
+	user, err := s.repo.GetUserByUsername(ctx, strings.TrimSpace(username))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			s.logger.Warn("Login for unknown user", zap.String("username", username))
+			return auth.TokenPair{}, ErrInvalidCredentials
+		}
+		return auth.TokenPair{}, err
+	}
+	if !auth.CheckPassword(user.PasswordHash, password) {
+		s.logger.Warn("Login with wrong password", zap.Int("id", user.ID))
+		return auth.TokenPair{}, ErrInvalidCredentials
+	}
+	s.logger.Info("User logged in successfully", zap.Int("id", user.ID))
+	return s.tokens.Issue(user.ID, user.Username, user.Role)
+}
+
+// RefreshToken exchanges a valid refresh token for a new token pair carrying the user's current role,
+// as long as the user still exists
+func (s *MusicService) RefreshToken(ctx context.Context, refreshToken string) (auth.TokenPair, error) {
+	if s.tokens == nil {
+		return auth.TokenPair{}, ErrAuthDisabled
+	}
+	claims, err := s.tokens.Verify(refreshToken, auth.TokenRefresh)
+	if err != nil {
+		s.logger.Warn("Rejected refresh token", zap.Error(err))
+		return auth.TokenPair{}, err
+	}
+	user, err := s.repo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			s.logger.Warn("Refresh token for deleted user", zap.Int("id", claims.UserID))
+			return auth.TokenPair{}, auth.ErrInvalidToken
+		}
+		return auth.TokenPair{}, err
+	}
+	s.logger.Debug("Refreshing tokens", zap.Int("id", user.ID))
+	return s.tokens.Issue(user.ID, user.Username, user.Role)
+}
+
+// GetUsers retrieves a page of user accounts
+func (s *MusicService) GetUsers(ctx context.Context, page, limit int) ([]models.User, error) {
+	s.logger.Debug("Fetching users", zap.Int("page", page), zap.Int("limit", limit))
+	return s.repo.GetUsers(ctx, page, limit)
+}
+
+// SetUserRole changes the role of a user; it applies to the user's tokens from their next refresh
+func (s *MusicService) SetUserRole(ctx context.Context, id int, role string) error {
+	s.logger.Debug("Setting user role", zap.Int("id", id), zap.String("role", role))
+	if !models.IsValidRole(role) {
+		s.logger.Warn("Unsupported role requested", zap.String("role", role))
+		return fmt.Errorf("%w: %s", ErrUnsupportedRole, role)
+	}
+	if err := s.repo.SetUserRole(ctx, id, role); err != nil {
+		return err
+	}
+	s.logger.Info("User role set successfully", zap.Int("id", id), zap.String("role", role))
+	return nil
+}
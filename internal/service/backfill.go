@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// BackfillLegacyRows normalizes songs written by deployments that predate the current data conventions:
+// missing timestamps, empty strings instead of NULL and duplicate songs. With dryRun nothing is changed
+// and the report lists what would be.
+func (s *MusicService) BackfillLegacyRows(ctx context.Context, dryRun bool) (models.BackfillReport, error) {
+	s.logger.Info("Backfilling legacy rows", zap.Bool("dry_run", dryRun))
+	report, err := s.repo.BackfillLegacyRows(ctx, dryRun)
+	if err != nil {
+		s.logger.Error("Failed to backfill legacy rows", zap.Error(err))
+		return models.BackfillReport{}, err
+	}
+	s.logger.Info("Legacy rows backfill finished",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("missing_created_at", report.MissingCreatedAt),
+		zap.Int("missing_updated_at", report.MissingUpdatedAt),
+		zap.Any("empty_fields", report.EmptyFields),
+		zap.Int("duplicates", len(report.Duplicates)))
+	return report, nil
+}
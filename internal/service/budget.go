@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+
+	"music-library/internal/budget"
+)
+
+// ExternalAPIProvider names the external song details API in provider budgets
+const ExternalAPIProvider = "external_api"
+
+// ConfigureBudget sets the manager every external API call consumes from: single additions,
+// bulk additions, imports and re-enrichment alike
+func (s *MusicService) ConfigureBudget(manager *budget.Manager) {
+	s.budget = manager
+}
+
+// ProviderBudgets returns the budget usage of the external providers
+func (s *MusicService) ProviderBudgets(ctx context.Context) []budget.Status {
+	return s.budget.Status(ctx)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/analytics"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// BulkSong identifies a song to add in bulk
+type BulkSong struct {
+	Group string `json:"group" example:"Muse"`
+	Song  string `json:"song" example:"Supermassive Black Hole"`
+}
+
+// BulkItemResult is the outcome of adding a single song in bulk
+type BulkItemResult struct {
+	Index int    `json:"index" example:"0"`
+	ID    int    `json:"id,omitempty" example:"1"`
+	Error string `json:"error,omitempty"`
+}
+
+// AddSongs adds several songs at once: external data for all of them is fetched concurrently,
+// and the songs are then stored in a single transaction. Invalid or failing songs are reported
+// per item without affecting the rest.
+func (s *MusicService) AddSongs(ctx context.Context, songs []BulkSong) (_ []BulkItemResult, err error) {
+	defer metrics.ObserveOperation("add_songs", time.Now(), &err)
+	s.logger.Info("Adding songs in bulk", zap.Int("count", len(songs)))
+	results := make([]BulkItemResult, len(songs))
+	var rows []ImportRow
+	var indexes []int
+	for i, song := range songs {
+		results[i].Index = i
+		if song.Group == "" || song.Song == "" {
+			results[i].Error = "group and song are required"
+			continue
+		}
+		rows = append(rows, ImportRow{Group: song.Group, Song: song.Song})
+		indexes = append(indexes, i)
+	}
+
+	enriched := s.enrichRows(ctx, rows)
+	var inputs []models.SongInput
+	var stored []int
+	for i, row := range rows {
+		if enriched[i].Err != nil {
+			results[indexes[i]].Error = enriched[i].Err.Error()
+			continue
+		}
+		inputs = append(inputs, models.SongInput{
+			Group:       row.Group,
+			Song:        row.Song,
+			ReleaseDate: enriched[i].ReleaseDate,
+			Text:        enriched[i].Text,
+			Link:        enriched[i].Link,
+			EnrichedAt:  enriched[i].EnrichedAt,
+		})
+		stored = append(stored, indexes[i])
+	}
+
+	var ids []int
+	var errs []error
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if ids, errs, err = s.repo.AddSongs(ctx, inputs); err != nil {
+			return err
+		}
+		for i := range ids {
+			if errs[i] != nil {
+				continue
+			}
+			if err := s.record(ctx, analytics.EventSongAdded, ids[i], 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to add songs in bulk", zap.Error(err))
+		return nil, err
+	}
+	added := 0
+	var targets []classificationTarget
+	for i, index := range stored {
+		if errs[i] != nil {
+			results[index].Error = "failed to store song"
+			continue
+		}
+		results[index].ID = ids[i]
+		s.publish(analytics.EventSongAdded, ids[i], 1)
+		s.indexVerses(ctx, ids[i], inputs[i].Text)
+		targets = append(targets, classificationTarget{ID: ids[i], Text: inputs[i].Text})
+		added++
+	}
+	s.classifySongs(targets...)
+
+	s.logger.Info("Songs added in bulk", zap.Int("added", added), zap.Int("failed", len(songs)-added))
+	return results, nil
+}
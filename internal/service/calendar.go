@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// releaseDateLayouts lists the formats release dates may be stored in
+var releaseDateLayouts = []string{"02.01.2006", time.RFC3339, "2006-01-02"}
+
+// parseReleaseDate parses a stored release date in any of the known layouts
+func parseReleaseDate(value string) (time.Time, error) {
+	for _, layout := range releaseDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized release date %q", value)
+}
+
+// GetReleaseCalendar builds an iCalendar feed with a yearly recurring event for every song release date
+func (s *MusicService) GetReleaseCalendar(ctx context.Context, group, song string) (string, error) {
+	s.logger.Debug("Building release calendar", zap.String("group", group), zap.String("song", song))
+	songs, err := s.repo.GetSongsWithReleaseDate(ctx, group, song)
+	if err != nil {
+		s.logger.Error("Failed to fetch songs for calendar", zap.Error(err))
+		return "", err
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	var b strings.Builder
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//Music Library//Release Anniversaries//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "X-WR-CALNAME:This day in music")
+	events := 0
+	for _, sg := range songs {
+		released, err := parseReleaseDate(models.StringValue(sg.ReleaseDate))
+		if err != nil {
+			s.logger.Warn("Skipping song with unparseable release date", zap.Int("id", sg.ID), zap.String("release_date", models.StringValue(sg.ReleaseDate)))
+			continue
+		}
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, fmt.Sprintf("UID:song-%d-release@music-library", sg.ID))
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		writeICalLine(&b, "DTSTART;VALUE=DATE:"+released.Format("20060102"))
+		writeICalLine(&b, "RRULE:FREQ=YEARLY")
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(fmt.Sprintf("%s – %s (released %d)", sg.Group, sg.Song, released.Year())))
+		if link := models.StringValue(sg.Link); link != "" {
+			writeICalLine(&b, "URL:"+link)
+		}
+		writeICalLine(&b, "TRANSP:TRANSPARENT")
+		writeICalLine(&b, "END:VEVENT")
+		events++
+	}
+	writeICalLine(&b, "END:VCALENDAR")
+
+	s.logger.Info("Release calendar built successfully", zap.Int("events", events))
+	return b.String(), nil
+}
+
+// escapeICalText escapes characters that have special meaning in iCalendar TEXT values
+func escapeICalText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(value)
+}
+
+// writeICalLine writes a content line terminated by CRLF, folding it at 75 octets as required by RFC 5545.
+// Continuation lines begin with a space, which counts towards their 75 octets. Lines are never cut inside
+// a UTF-8 sequence.
+func writeICalLine(b *strings.Builder, line string) {
+	const maxOctets = 75
+	limit := maxOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && (line[cut]&0xC0) == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReleaseDate(t *testing.T) {
+	for _, value := range []string{"16.07.2006", "2006-07-16", "2006-07-16T00:00:00Z"} {
+		released, err := parseReleaseDate(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, time.Date(2006, time.July, 16, 0, 0, 0, 0, time.UTC), released, value)
+	}
+
+	_, err := parseReleaseDate("July 2006")
+	assert.Error(t, err)
+}
+
+func TestWriteICalLine(t *testing.T) {
+	for _, value := range []string{
+		"SUMMARY:" + escapeICalText("Muse, live; \"Black Hole\"\n"+strings.Repeat("ж", 60)),
+		"DESCRIPTION:" + strings.Repeat("a", 300),
+	} {
+		var b strings.Builder
+		writeICalLine(&b, value)
+
+		lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+		assert.Greater(t, len(lines), 1)
+		for i, line := range lines {
+			assert.LessOrEqual(t, len(line), 75, "line %d", i)
+			assert.True(t, utf8.ValidString(line), "line %d", i)
+			if i > 0 {
+				assert.True(t, strings.HasPrefix(line, " "), "line %d", i)
+			}
+		}
+		assert.Equal(t, value, strings.ReplaceAll(b.String()[:b.Len()-2], "\r\n ", ""), "unfolding restores the line")
+	}
+}
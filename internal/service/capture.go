@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// maxAPICaptures bounds the number of captures returned at once
+const maxAPICaptures = 200
+
+// GetAPICaptures returns the newest captured external API exchanges, of a single provider when one is given
+func (s *MusicService) GetAPICaptures(ctx context.Context, provider string, limit int) (captures []models.APICapture, err error) {
+	defer metrics.ObserveOperation("get_api_captures", time.Now(), &err)
+	if limit > maxAPICaptures {
+		limit = maxAPICaptures
+	}
+	return s.repo.GetAPICaptures(ctx, provider, limit)
+}
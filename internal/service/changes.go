@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/changes"
+)
+
+// DefaultChangePollTimeout is how long a change poll waits when the client does not say
+const DefaultChangePollTimeout = 30 * time.Second
+
+// MaxChangePollTimeout bounds how long a change poll may hold its request
+const MaxChangePollTimeout = 2 * time.Minute
+
+// ErrInvalidPollTimeout is returned for change poll timeouts that are not positive or exceed MaxChangePollTimeout
+var ErrInvalidPollTimeout = fmt.Errorf("poll timeout must be positive and at most %s", MaxChangePollTimeout)
+
+// LatestChange returns the cursor of the latest library change, from which a client receives only future changes
+func (s *MusicService) LatestChange() uint64 {
+	return s.changes.Last()
+}
+
+// PollChanges returns the library changes after the cursor, holding the call until a change is published,
+// the timeout elapses or ctx is done. A batch without changes means none arrived in time.
+func (s *MusicService) PollChanges(ctx context.Context, since uint64, timeout time.Duration) (changes.Batch, error) {
+	if timeout <= 0 || timeout > MaxChangePollTimeout {
+		return changes.Batch{}, ErrInvalidPollTimeout
+	}
+	s.logger.Debug("Polling changes", zap.Uint64("since", since), zap.Duration("timeout", timeout))
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return s.changes.Wait(ctx, since), nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"music-library/internal/classifier"
+	"music-library/internal/models"
+)
+
+// ErrUnsupportedSuggestionStatus is returned when suggestions are listed by an unknown status
+var ErrUnsupportedSuggestionStatus = errors.New("unsupported suggestion status")
+
+// classificationTarget is a song whose lyrics are to be classified
+type classificationTarget struct {
+	ID   int
+	Text string
+}
+
+// ConfigureClassifier sets the classifier proposing genres and moods for new and enriched songs
+func (s *MusicService) ConfigureClassifier(c classifier.Classifier) {
+	s.classifier = c
+}
+
+// classifySongs queues genre and mood suggestions for the songs in the background,
+// so neither inserts nor enrichment wait for the classifier
+func (s *MusicService) classifySongs(targets ...classificationTarget) {
+	if s.classifier == nil || len(targets) == 0 {
+		return
+	}
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		for _, target := range targets {
+			s.classifySong(target)
+		}
+	}()
+}
+
+// classifySong runs the classifier on the lyrics of a song and stores its suggestions for review
+func (s *MusicService) classifySong(target classificationTarget) {
+	if target.Text == "" {
+		return
+	}
+	ctx := context.Background()
+	classifyCtx, cancel := context.WithTimeout(ctx, s.timeouts.ExternalAPI)
+	defer cancel()
+	proposed, err := s.classifier.Classify(classifyCtx, target.Text)
+	if err != nil {
+		s.logger.Error("Failed to classify song", zap.Int("song_id", target.ID), zap.String("classifier", s.classifier.Name()), zap.Error(err))
+		return
+	}
+	if len(proposed) == 0 {
+		return
+	}
+
+	suggestions := make([]models.ClassificationSuggestion, len(proposed))
+	for i, suggestion := range proposed {
+		suggestions[i] = models.ClassificationSuggestion{Kind: suggestion.Kind, Value: suggestion.Value, Confidence: suggestion.Confidence}
+	}
+	added, err := s.repo.AddClassificationSuggestions(ctx, target.ID, s.classifier.Name(), suggestions)
+	if err != nil {
+		s.logger.Error("Failed to store classification suggestions", zap.Int("song_id", target.ID), zap.Error(err))
+		return
+	}
+	s.logger.Debug("Classification suggestions queued", zap.Int("song_id", target.ID), zap.Int("count", added))
+}
+
+// GetClassificationSuggestions retrieves a page of suggestions with the status
+func (s *MusicService) GetClassificationSuggestions(ctx context.Context, status string, page, limit int) ([]models.ClassificationSuggestion, error) {
+	switch status {
+	case models.SuggestionPending, models.SuggestionAccepted, models.SuggestionRejected:
+	default:
+		return nil, ErrUnsupportedSuggestionStatus
+	}
+	s.logger.Debug("Fetching classification suggestions", zap.String("status", status))
+	suggestions, err := s.repo.GetClassificationSuggestions(ctx, status, page, limit)
+	if err != nil {
+		s.logger.Error("Failed to fetch classification suggestions", zap.Error(err))
+		return nil, err
+	}
+	s.logger.Info("Classification suggestions fetched successfully", zap.Int("count", len(suggestions)))
+	return suggestions, nil
+}
+
+// ReviewClassificationSuggestion accepts or rejects a pending suggestion. An accepted genre is assigned to
+// the song, created first when the taxonomy lacks it, and an accepted mood is added to the song's tags,
+// in the same transaction as the review.
+func (s *MusicService) ReviewClassificationSuggestion(ctx context.Context, id int, accept bool) error {
+	status := models.SuggestionRejected
+	if accept {
+		status = models.SuggestionAccepted
+	}
+	s.logger.Info("Reviewing classification suggestion", zap.Int("id", id), zap.String("status", status))
+	err := s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.ReviewClassificationSuggestion(ctx, id, status); err != nil || !accept {
+			return err
+		}
+		suggestion, err := s.repo.GetClassificationSuggestion(ctx, id)
+		if err != nil {
+			return err
+		}
+		return s.applySuggestion(ctx, suggestion)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrLegalHold) {
+			s.logger.Error("Failed to review classification suggestion", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// applySuggestion labels the song of an accepted suggestion with its genre or mood, returning ErrLegalHold
+// when the song is on legal hold
+func (s *MusicService) applySuggestion(ctx context.Context, suggestion models.ClassificationSuggestion) error {
+	if err := s.checkLegalHold(ctx, "accept_classification", suggestion.SongID); err != nil {
+		return err
+	}
+	switch suggestion.Kind {
+	case models.ClassificationGenre:
+		genreID, err := s.suggestedGenre(ctx, suggestion.Value)
+		if err != nil {
+			return err
+		}
+		genres, err := s.repo.GetSongGenres(ctx, suggestion.SongID)
+		if err != nil {
+			return err
+		}
+		ids := make([]int, 0, len(genres)+1)
+		for _, genre := range genres {
+			if genre.ID == genreID {
+				return nil
+			}
+			ids = append(ids, genre.ID)
+		}
+		if len(ids) >= MaxSongGenres {
+			return fmt.Errorf("%w: at most %d genres can be assigned to a song", ErrInvalidGenre, MaxSongGenres)
+		}
+		return s.repo.SetSongGenres(ctx, suggestion.SongID, append(ids, genreID))
+	case models.ClassificationMood:
+		tags, err := normalizeTags([]string{suggestion.Value})
+		if err != nil {
+			return err
+		}
+		_, err = s.repo.BulkTagSongs(ctx, []int{suggestion.SongID}, models.SongFilter{}, tags, nil)
+		return err
+	default:
+		return fmt.Errorf("unknown suggestion kind %q", suggestion.Kind)
+	}
+}
+
+// suggestedGenre returns the ID of the genre with the name, creating it at the top of the taxonomy when missing
+func (s *MusicService) suggestedGenre(ctx context.Context, name string) (int, error) {
+	genre, err := s.repo.GetGenreByName(ctx, strings.TrimSpace(name))
+	if err == nil {
+		return genre.ID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+	input, err := normalizeGenre(models.GenreInput{Name: name})
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.CreateGenre(ctx, input)
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"music-library/internal/models"
+)
+
+func TestAcceptingMoodSuggestionTagsSong(t *testing.T) {
+	svc, repo, _ := newMockedService(t)
+	repo.EXPECT().ReviewClassificationSuggestion(gomock.Any(), 3, models.SuggestionAccepted).Return(nil)
+	repo.EXPECT().GetClassificationSuggestion(gomock.Any(), 3).
+		Return(models.ClassificationSuggestion{ID: 3, SongID: 7, Kind: models.ClassificationMood, Value: "Romantic"}, nil)
+	repo.EXPECT().GetLegalHolds(gomock.Any(), []int{7}).Return(nil, nil)
+	repo.EXPECT().BulkTagSongs(gomock.Any(), []int{7}, models.SongFilter{}, []string{"romantic"}, nil).
+		Return(models.BulkTagResult{}, nil)
+
+	require.NoError(t, svc.ReviewClassificationSuggestion(context.Background(), 3, true))
+}
+
+func TestAcceptingGenreSuggestionAssignsGenre(t *testing.T) {
+	svc, repo, _ := newMockedService(t)
+	repo.EXPECT().ReviewClassificationSuggestion(gomock.Any(), 4, models.SuggestionAccepted).Return(nil)
+	repo.EXPECT().GetClassificationSuggestion(gomock.Any(), 4).
+		Return(models.ClassificationSuggestion{ID: 4, SongID: 7, Kind: models.ClassificationGenre, Value: "Space Rock"}, nil)
+	repo.EXPECT().GetLegalHolds(gomock.Any(), []int{7}).Return(nil, nil)
+	repo.EXPECT().GetGenreByName(gomock.Any(), "Space Rock").Return(models.Genre{}, sql.ErrNoRows)
+	repo.EXPECT().CreateGenre(gomock.Any(), models.GenreInput{Name: "Space Rock"}).Return(12, nil)
+	repo.EXPECT().GetSongGenres(gomock.Any(), 7).Return([]models.Genre{{ID: 2}}, nil)
+	repo.EXPECT().SetSongGenres(gomock.Any(), 7, []int{2, 12}).Return(nil)
+
+	require.NoError(t, svc.ReviewClassificationSuggestion(context.Background(), 4, true))
+}
+
+func TestRejectingSuggestionLeavesSongAlone(t *testing.T) {
+	svc, repo, _ := newMockedService(t)
+	repo.EXPECT().ReviewClassificationSuggestion(gomock.Any(), 5, models.SuggestionRejected).Return(nil)
+
+	require.NoError(t, svc.ReviewClassificationSuggestion(context.Background(), 5, false))
+}
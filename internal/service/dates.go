@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"music-library/internal/models"
+)
+
+// ErrInvalidReleaseDate is returned when a release date is not a valid date in the expected format
+var ErrInvalidReleaseDate = errors.New("invalid release date")
+
+// ErrUnsupportedDateFormat is returned when a client requests an unknown date format
+var ErrUnsupportedDateFormat = errors.New("unsupported date format")
+
+// Date formats clients can send and receive release dates in
+const (
+	// DateFormatDefault is DD.MM.YYYY, the format release dates are stored in
+	DateFormatDefault = ""
+	// DateFormatISO is the ISO 8601 calendar date, YYYY-MM-DD
+	DateFormatISO = "iso"
+)
+
+// isoDateLayout is the Go layout of ISO 8601 calendar dates
+const isoDateLayout = "2006-01-02"
+
+// ValidateDateFormat checks that the date format is supported
+func ValidateDateFormat(format string) error {
+	if format != DateFormatDefault && format != DateFormatISO {
+		return fmt.Errorf("%w: %s", ErrUnsupportedDateFormat, format)
+	}
+	return nil
+}
+
+// NormalizeReleaseDate strictly parses a release date sent in the client's format and returns it
+// in the stored DD.MM.YYYY layout. Empty values are returned unchanged.
+func NormalizeReleaseDate(value, format string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	layout, expected := canonicalDateLayout, "DD.MM.YYYY"
+	if format == DateFormatISO {
+		layout, expected = isoDateLayout, "YYYY-MM-DD"
+	}
+	released, err := time.Parse(layout, value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q is not a date formatted %s", ErrInvalidReleaseDate, value, expected)
+	}
+	return released.Format(canonicalDateLayout), nil
+}
+
+// normalizeExternalReleaseDate converts a release date provided by the external API into the stored layout.
+// The API is trusted to send either DD.MM.YYYY or ISO 8601 dates; anything else is treated as missing.
+func normalizeExternalReleaseDate(value string) (string, bool) {
+	if value == "" {
+		return "", true
+	}
+	released, err := parseReleaseDate(value)
+	if err != nil {
+		return "", false
+	}
+	return released.Format(canonicalDateLayout), true
+}
+
+// FormatSongDate rewrites the release date of the song into the requested output format.
+// Dates that cannot be parsed are left as stored.
+func FormatSongDate(song *models.Song, format string) {
+	if format != DateFormatISO || song.ReleaseDate == nil {
+		return
+	}
+	if released, err := parseReleaseDate(*song.ReleaseDate); err == nil {
+		iso := released.Format(isoDateLayout)
+		song.ReleaseDate = &iso
+	}
+}
+
+// FormatSongDates rewrites the release dates of the songs into the requested output format
+func FormatSongDates(songs []models.Song, format string) {
+	for i := range songs {
+		FormatSongDate(&songs[i], format)
+	}
+}
+
+// FormatAlbumDate rewrites the release date of the album into the requested output format.
+// Dates that cannot be parsed are left as stored.
+func FormatAlbumDate(album *models.Album, format string) {
+	if format != DateFormatISO || album.ReleaseDate == nil {
+		return
+	}
+	if released, err := parseReleaseDate(*album.ReleaseDate); err == nil {
+		iso := released.Format(isoDateLayout)
+		album.ReleaseDate = &iso
+	}
+}
+
+// FormatDigestDates returns a copy of the digest with its release dates in the requested output format.
+// The digest itself is shared between requests and is left untouched.
+func FormatDigestDates(digest *models.Digest, format string) *models.Digest {
+	if format != DateFormatISO {
+		return digest
+	}
+	formatted := *digest
+	formatted.NewSongs = append([]models.Song(nil), digest.NewSongs...)
+	formatted.UpdatedSongs = append([]models.Song(nil), digest.UpdatedSongs...)
+	FormatSongDates(formatted.NewSongs, format)
+	FormatSongDates(formatted.UpdatedSongs, format)
+	return &formatted
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"music-library/internal/models"
+)
+
+func TestNormalizeReleaseDate(t *testing.T) {
+	date, err := NormalizeReleaseDate("16.07.2006", DateFormatDefault)
+	assert.NoError(t, err)
+	assert.Equal(t, "16.07.2006", date)
+
+	date, err = NormalizeReleaseDate("2006-07-16", DateFormatISO)
+	assert.NoError(t, err)
+	assert.Equal(t, "16.07.2006", date)
+
+	for _, value := range []string{"2006-07-16", "16.7.2006", "31.02.2006", "16/07/2006"} {
+		_, err = NormalizeReleaseDate(value, DateFormatDefault)
+		assert.ErrorIs(t, err, ErrInvalidReleaseDate, value)
+	}
+	_, err = NormalizeReleaseDate("16.07.2006", DateFormatISO)
+	assert.ErrorIs(t, err, ErrInvalidReleaseDate)
+
+	date, err = NormalizeReleaseDate("", DateFormatDefault)
+	assert.NoError(t, err)
+	assert.Empty(t, date)
+}
+
+func TestFormatSongDates(t *testing.T) {
+	stored, legacy, broken := "16.07.2006", "2009-09-14", "soon"
+	songs := []models.Song{{ReleaseDate: &stored}, {ReleaseDate: &legacy}, {ReleaseDate: &broken}, {}}
+
+	FormatSongDates(songs, DateFormatISO)
+	assert.Equal(t, "2006-07-16", *songs[0].ReleaseDate)
+	assert.Equal(t, "2009-09-14", *songs[1].ReleaseDate)
+	assert.Equal(t, "soon", *songs[2].ReleaseDate)
+	assert.Nil(t, songs[3].ReleaseDate)
+	assert.Equal(t, "16.07.2006", stored, "stored values are not modified in place")
+
+	assert.NoError(t, ValidateDateFormat(DateFormatISO))
+	assert.ErrorIs(t, ValidateDateFormat("us"), ErrUnsupportedDateFormat)
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// ErrDegraded is returned while the database is unreachable, for writes and for reads the warm cache
+// cannot answer
+var ErrDegraded = errors.New("database unavailable, serving read-only")
+
+// DegradedConfig sets up the degraded mode: while the database is unreachable, song and search reads are
+// served from a warm cache of recent results and writes are refused
+type DegradedConfig struct {
+	// CacheSize is the number of song and search results kept warm, zero disables the degraded mode
+	CacheSize int
+	// CheckInterval is the time between two database checks deciding whether the service is degraded
+	CheckInterval time.Duration
+}
+
+// DefaultDegradedConfig leaves the degraded mode off
+var DefaultDegradedConfig = DegradedConfig{
+	CacheSize:     0,
+	CheckInterval: 5 * time.Second,
+}
+
+// warmEntry is a cached result with the time it was read from the database
+type warmEntry struct {
+	key      string
+	value    any
+	storedAt time.Time
+}
+
+// degradedMode tracks whether the database is reachable and keeps the most recently read results warm
+type degradedMode struct {
+	cfg DegradedConfig
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// recent orders the entries from the most to the least recently stored
+	recent *list.List
+	// downSince is when the database was found unreachable, zero while it is reachable
+	downSince time.Time
+}
+
+// ConfigureDegradedMode enables the degraded mode with a warm cache of the configured size
+func (s *MusicService) ConfigureDegradedMode(cfg DegradedConfig) {
+	if cfg.CacheSize < 1 {
+		s.degraded = nil
+		return
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = DefaultDegradedConfig.CheckInterval
+	}
+	s.degraded = &degradedMode{cfg: cfg, entries: make(map[string]*list.Element), recent: list.New()}
+}
+
+// DegradedModeEnabled reports whether reads are served from the warm cache while the database is unreachable
+func (s *MusicService) DegradedModeEnabled() bool {
+	return s.degraded != nil
+}
+
+// Degraded reports whether the database is currently found unreachable, so writes are refused
+func (s *MusicService) Degraded() bool {
+	_, down := s.degraded.since()
+	return down
+}
+
+// StartDatabaseMonitor pings the database every check interval while the degraded mode is enabled, entering
+// the mode when the database stops answering and leaving it when it answers again
+func (s *MusicService) StartDatabaseMonitor(ctx context.Context) {
+	if s.degraded == nil {
+		return
+	}
+	interval := s.degraded.cfg.CheckInterval
+	s.logger.Info("Starting database monitor", zap.Duration("interval", interval), zap.Int("cache_size", s.degraded.cfg.CacheSize))
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				pingCtx, cancel := context.WithTimeout(ctx, interval)
+				err := s.repo.Ping(pingCtx)
+				cancel()
+				if ctx.Err() != nil {
+					return
+				}
+				s.setDatabaseDown(err != nil, err)
+			}
+		}
+	}()
+}
+
+// setDatabaseDown records whether the database is reachable, logging the transitions
+func (s *MusicService) setDatabaseDown(down bool, err error) {
+	if s.degraded.setDown(down) {
+		if down {
+			s.logger.Error("Database unreachable, entering degraded mode", zap.Error(err))
+		} else {
+			s.logger.Info("Database reachable again, leaving degraded mode")
+		}
+	}
+}
+
+// unavailable reports whether a read failed because the database cannot be reached. A statement timing
+// out while the request still has time counts as unreachable, as queries hang rather than fail during
+// a failover.
+func unavailable(ctx context.Context, err error) bool {
+	return repository.IsUnavailable(err) || (errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil)
+}
+
+// cachedRead runs read, keeping its result warm under key. While the database is unreachable the warm
+// result is returned instead, without waiting on the database, and its age is recorded on the request's
+// Staleness; ErrDegraded is returned when there is none.
+func cachedRead[T any](ctx context.Context, s *MusicService, key string, clone func(T) T, read func() (T, error)) (T, error) {
+	if s.degraded == nil {
+		return read()
+	}
+	if s.Degraded() {
+		if value, ok := s.warmRead(ctx, key); ok {
+			return clone(value.(T)), nil
+		}
+	}
+	value, err := read()
+	if err == nil {
+		s.degraded.put(key, clone(value))
+		s.setDatabaseDown(false, nil)
+		return value, nil
+	}
+	if !unavailable(ctx, err) {
+		return value, err
+	}
+	s.setDatabaseDown(true, err)
+	cached, ok := s.warmRead(ctx, key)
+	if !ok {
+		s.logger.Warn("No warm result to serve while degraded", zap.String("key", key))
+		return value, fmt.Errorf("%w: %v", ErrDegraded, err)
+	}
+	return clone(cached.(T)), nil
+}
+
+// warmRead returns the warm result under key, recording its age on the request's Staleness
+func (s *MusicService) warmRead(ctx context.Context, key string) (any, bool) {
+	value, storedAt, ok := s.degraded.get(key)
+	if !ok {
+		return nil, false
+	}
+	s.logger.Warn("Serving warm result while degraded", zap.String("key", key), zap.Time("stored_at", storedAt))
+	recordStale(ctx, storedAt)
+	return value, true
+}
+
+// songByID reads a song, served from the warm cache while the database is unreachable
+func (s *MusicService) songByID(ctx context.Context, id int) (models.Song, error) {
+	return cachedRead(ctx, s, "song:"+strconv.Itoa(id), func(song models.Song) models.Song { return song }, func() (models.Song, error) {
+		return s.repo.GetSongByID(ctx, id)
+	})
+}
+
+// cloneSearchResults copies the results, so callers formatting them do not change the cached ones
+func cloneSearchResults(results []models.SearchResult) []models.SearchResult {
+	return append([]models.SearchResult(nil), results...)
+}
+
+// since returns when the database was found unreachable, and whether it still is
+func (d *degradedMode) since() (time.Time, bool) {
+	if d == nil {
+		return time.Time{}, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.downSince, !d.downSince.IsZero()
+}
+
+// setDown records whether the database is reachable and reports whether that changed
+func (d *degradedMode) setDown(down bool) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if down == !d.downSince.IsZero() {
+		return false
+	}
+	d.downSince = time.Time{}
+	if down {
+		d.downSince = time.Now()
+	}
+	return true
+}
+
+// put stores a result, dropping the least recently stored one when the cache is full
+func (d *degradedMode) put(key string, value any) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entry := &warmEntry{key: key, value: value, storedAt: time.Now()}
+	if element, ok := d.entries[key]; ok {
+		element.Value = entry
+		d.recent.MoveToFront(element)
+		return
+	}
+	d.entries[key] = d.recent.PushFront(entry)
+	if d.recent.Len() > d.cfg.CacheSize {
+		oldest := d.recent.Back()
+		d.recent.Remove(oldest)
+		delete(d.entries, oldest.Value.(*warmEntry).key)
+	}
+}
+
+// get returns a stored result and when it was read from the database
+func (d *degradedMode) get(key string) (any, time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	element, ok := d.entries[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	entry := element.Value.(*warmEntry)
+	return entry.value, entry.storedAt, true
+}
+
+// staleKey is the context key of the request's Staleness
+type staleKey struct{}
+
+// Staleness records the age of the oldest warm result a request was served while degraded
+type Staleness struct {
+	mu       sync.Mutex
+	storedAt time.Time
+}
+
+// WithStaleness returns a context recording on the returned Staleness when results are served stale
+func WithStaleness(ctx context.Context) (context.Context, *Staleness) {
+	staleness := &Staleness{}
+	return context.WithValue(ctx, staleKey{}, staleness), staleness
+}
+
+// StoredAt returns when the oldest stale result served was read from the database, and whether any was
+func (st *Staleness) StoredAt() (time.Time, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.storedAt, !st.storedAt.IsZero()
+}
+
+// recordStale records a stale result on the request's Staleness, if it has one
+func recordStale(ctx context.Context, storedAt time.Time) {
+	staleness, ok := ctx.Value(staleKey{}).(*Staleness)
+	if !ok {
+		return
+	}
+	staleness.mu.Lock()
+	defer staleness.mu.Unlock()
+	if staleness.storedAt.IsZero() || storedAt.Before(staleness.storedAt) {
+		staleness.storedAt = storedAt
+	}
+}
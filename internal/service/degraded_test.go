@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// flakyRepository serves songs until it is taken down, failing like a lost database connection after
+type flakyRepository struct {
+	repository.Repository
+	down  bool
+	reads int
+}
+
+func (r *flakyRepository) ConfigureStatementTimeout(time.Duration) {}
+
+func (r *flakyRepository) GetSongByID(_ context.Context, id int) (models.Song, error) {
+	r.reads++
+	if r.down {
+		return models.Song{}, driver.ErrBadConn
+	}
+	if id != 1 {
+		return models.Song{}, sql.ErrNoRows
+	}
+	return models.Song{ID: id, Group: "Muse", Song: "Starlight"}, nil
+}
+
+func TestDegradedModeServesWarmSongs(t *testing.T) {
+	repo := &flakyRepository{}
+	svc := NewMusicService(repo, zap.NewNop(), nil)
+	svc.ConfigureDegradedMode(DegradedConfig{CacheSize: 10})
+
+	song, err := svc.songByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.False(t, svc.Degraded())
+	_, err = svc.songByID(context.Background(), 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows, "a missing song is not a database failure")
+
+	repo.down = true
+	ctx, staleness := WithStaleness(context.Background())
+	cached, err := svc.songByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, song, cached)
+	assert.True(t, svc.Degraded())
+	_, stale := staleness.StoredAt()
+	assert.True(t, stale)
+
+	reads := repo.reads
+	_, err = svc.songByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, reads, repo.reads, "warm songs are served without waiting on the database while degraded")
+
+	_, err = svc.songByID(context.Background(), 3)
+	assert.ErrorIs(t, err, ErrDegraded)
+
+	repo.down = false
+	_, err = svc.songByID(context.Background(), 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = svc.songByID(context.Background(), 1)
+	require.NoError(t, err)
+	_, err = svc.songByID(context.Background(), 4)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDegradedModeDisabled(t *testing.T) {
+	repo := &flakyRepository{down: true}
+	svc := NewMusicService(repo, zap.NewNop(), nil)
+
+	_, err := svc.songByID(context.Background(), 1)
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.False(t, svc.Degraded())
+	assert.False(t, svc.DegradedModeEnabled())
+}
+
+func TestWarmCacheEvictsLeastRecent(t *testing.T) {
+	svc := NewMusicService(nil, zap.NewNop(), nil)
+	svc.ConfigureDegradedMode(DegradedConfig{CacheSize: 2})
+	svc.degraded.put("a", 1)
+	svc.degraded.put("b", 2)
+	svc.degraded.put("a", 3)
+	svc.degraded.put("c", 4)
+
+	_, _, ok := svc.degraded.get("b")
+	assert.False(t, ok, "the least recently stored entry is evicted")
+	value, _, ok := svc.degraded.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+}
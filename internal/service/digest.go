@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// BuildDigest compiles the library changes made during the period of the given length ending at end
+func (s *MusicService) BuildDigest(ctx context.Context, end time.Time, period time.Duration) (*models.Digest, error) {
+	start := end.Add(-period)
+	s.logger.Debug("Building digest", zap.Time("from", start), zap.Time("to", end))
+
+	created, err := s.repo.GetSongsCreatedBetween(ctx, start, end)
+	if err != nil {
+		s.logger.Error("Failed to fetch new songs for digest", zap.Error(err))
+		return nil, err
+	}
+	updated, err := s.repo.GetSongsUpdatedBetween(ctx, start, end)
+	if err != nil {
+		s.logger.Error("Failed to fetch updated songs for digest", zap.Error(err))
+		return nil, err
+	}
+
+	digest := &models.Digest{
+		PeriodStart:  start,
+		PeriodEnd:    end,
+		NewSongs:     created,
+		UpdatedSongs: updated,
+		GeneratedAt:  time.Now(),
+	}
+	s.logger.Info("Digest built successfully", zap.Int("new_songs", len(created)), zap.Int("updated_songs", len(updated)))
+	return digest, nil
+}
+
+// LatestDigest returns the most recently generated digest, building one on demand if the scheduler has not run yet
+func (s *MusicService) LatestDigest(ctx context.Context, period time.Duration) (*models.Digest, error) {
+	s.digestMu.RLock()
+	digest := s.latestDigest
+	s.digestMu.RUnlock()
+	if digest != nil {
+		return digest, nil
+	}
+	return s.refreshDigest(ctx, period)
+}
+
+// refreshDigest builds a digest for the period ending now and stores it as the latest one
+func (s *MusicService) refreshDigest(ctx context.Context, period time.Duration) (*models.Digest, error) {
+	digest, err := s.BuildDigest(ctx, time.Now(), period)
+	if err != nil {
+		return nil, err
+	}
+	s.digestMu.Lock()
+	s.latestDigest = digest
+	s.digestMu.Unlock()
+	return digest, nil
+}
+
+// StartDigestScheduler regenerates the digest every period until ctx is cancelled
+func (s *MusicService) StartDigestScheduler(ctx context.Context, period time.Duration) {
+	s.logger.Info("Starting digest scheduler", zap.Duration("period", period))
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				s.logger.Info("Digest scheduler stopped")
+				return
+			case <-ticker.C:
+				if _, err := s.refreshDigest(ctx, period); err != nil {
+					s.logger.Error("Scheduled digest failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+)
+
+// EnrichmentConfig limits how batch operations call the external API
+type EnrichmentConfig struct {
+	// Concurrency is the maximum number of enrichment calls in flight
+	Concurrency int
+	// RatePerSecond is the maximum number of enrichment calls started per second
+	RatePerSecond float64
+}
+
+// DefaultEnrichmentConfig is used until ConfigureEnrichment is called
+var DefaultEnrichmentConfig = EnrichmentConfig{
+	Concurrency:   8,
+	RatePerSecond: 10,
+}
+
+// ConfigureEnrichment replaces the limits used for batch enrichment
+func (s *MusicService) ConfigureEnrichment(cfg EnrichmentConfig) {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = DefaultEnrichmentConfig.Concurrency
+	}
+	if cfg.RatePerSecond <= 0 {
+		cfg.RatePerSecond = DefaultEnrichmentConfig.RatePerSecond
+	}
+	s.enrichment = cfg
+	s.enrichLimiter = rate.NewLimiter(rate.Limit(cfg.RatePerSecond), cfg.Concurrency)
+}
+
+// songData holds the fields completed by enrichment
+type songData struct {
+	ReleaseDate string
+	Text        string
+	Link        string
+	EnrichedAt  *time.Time
+	// Err is set when the row could not be completed
+	Err error
+}
+
+// enrichRow completes the missing fields of a row, waiting for the rate limiter and bounding the call
+// by the external API timeout. Rows whose call fails or times out receive fallback data, or an error when
+// fallback is disabled.
+func (s *MusicService) enrichRow(ctx context.Context, row ImportRow) (ImportRow, error) {
+	if row.ReleaseDate != "" && row.Text != "" && row.Link != "" {
+		return row, nil
+	}
+	// A failed wait means ctx is done, in which case the call fails fast and the row gets fallback data
+	_ = s.enrichLimiter.Wait(ctx)
+	releaseDate, text, link, enriched, err := s.completeSongData(ctx, row.Group, row.Song, row.ReleaseDate, row.Text, row.Link)
+	if err != nil {
+		return row, err
+	}
+	row.ReleaseDate, row.Text, row.Link = releaseDate, text, link
+	row.EnrichedAt = enrichedAt(enriched)
+	return row, nil
+}
+
+// enrichRows completes the missing fields of the rows concurrently, bounded by the configured
+// concurrency and rate limit, and collects partial results: every row gets an entry even when its call fails.
+func (s *MusicService) enrichRows(ctx context.Context, rows []ImportRow) []songData {
+	results := make([]songData, len(rows))
+	sem := semaphore.NewWeighted(int64(s.enrichment.Concurrency))
+	var g errgroup.Group
+	started := time.Now()
+
+	for i, row := range rows {
+		i, row := i, row
+		if err := sem.Acquire(ctx, 1); err != nil {
+			// The context is done: complete the remaining rows without waiting for the provider
+			row, err := s.enrichRow(ctx, row)
+			results[i] = songData{ReleaseDate: row.ReleaseDate, Text: row.Text, Link: row.Link, EnrichedAt: row.EnrichedAt, Err: err}
+			continue
+		}
+		g.Go(func() error {
+			defer sem.Release(1)
+			row, err := s.enrichRow(ctx, row)
+			results[i] = songData{ReleaseDate: row.ReleaseDate, Text: row.Text, Link: row.Link, EnrichedAt: row.EnrichedAt, Err: err}
+			return nil
+		})
+	}
+	g.Wait()
+
+	s.logger.Info("Batch enrichment finished", zap.Int("rows", len(rows)), zap.Duration("took", time.Since(started)))
+	return results
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"music-library/internal/breaker"
+)
+
+func TestEnrichRowsBoundedConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+		if r.URL.Query().Get("song") == "slow" {
+			time.Sleep(500 * time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprintf(w, `{"release_date": "16.07.2006", "text": "%s", "link": "https://example.com"}`, r.URL.Query().Get("song"))
+	}))
+	defer server.Close()
+	t.Setenv("EXTERNAL_API_URL", server.URL)
+
+	svc := NewMusicService(nil, zap.NewNop(), server.Client())
+	svc.ConfigureEnrichment(EnrichmentConfig{Concurrency: 4, RatePerSecond: 1000})
+	svc.ConfigureTimeouts(TimeoutConfig{ExternalAPI: 200 * time.Millisecond})
+	svc.ConfigureResilience(RetryConfig{Attempts: 1}, breaker.New(ExternalAPIProvider, breaker.DefaultConfig, zap.NewNop()))
+
+	rows := []ImportRow{{Group: "Muse", Song: "slow"}, {Group: "Muse", Song: "kept", Text: "own", ReleaseDate: "01.01.2001", Link: "https://own"}}
+	for i := 0; i < 20; i++ {
+		rows = append(rows, ImportRow{Group: "Muse", Song: fmt.Sprintf("song-%d", i)})
+	}
+
+	started := time.Now()
+	results := svc.enrichRows(context.Background(), rows)
+
+	assert.Less(t, time.Since(started), 400*time.Millisecond)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(4))
+	assert.Len(t, results, len(rows))
+	assert.Equal(t, "Verse 1\n\nVerse 2\n\nVerse 3", results[0].Text, "timed out call falls back to mock data")
+	assert.Equal(t, "own", results[1].Text, "complete rows are not enriched")
+	assert.Equal(t, "song-0", results[2].Text)
+}
+
+func TestCompleteSongDataFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"release_date": "16.07.2006"}`))
+	}))
+	defer server.Close()
+	t.Setenv("EXTERNAL_API_URL", server.URL)
+	svc := NewMusicService(nil, zap.NewNop(), server.Client())
+
+	releaseDate, text, link, enriched, err := svc.completeSongData(context.Background(), "Muse", "Uprising", "", "", "")
+	assert.NoError(t, err)
+	assert.False(t, enriched)
+	assert.Equal(t, "16.07.2006", releaseDate, "data the API did provide is kept")
+	assert.Equal(t, DefaultFallbackConfig.Text, text)
+	assert.Equal(t, DefaultFallbackConfig.Link, link)
+
+	svc.ConfigureFallback(FallbackConfig{Mode: FallbackTemplate, Text: "Lyrics unavailable"})
+	_, text, link, _, err = svc.completeSongData(context.Background(), "Muse", "Uprising", "", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Lyrics unavailable", text)
+	assert.Empty(t, link)
+
+	svc.ConfigureFallback(FallbackConfig{Mode: FallbackNull, Text: "ignored"})
+	_, text, _, _, err = svc.completeSongData(context.Background(), "Muse", "Uprising", "", "", "")
+	assert.NoError(t, err)
+	assert.Empty(t, text)
+
+	svc.ConfigureFallback(FallbackConfig{Mode: FallbackDisabled})
+	_, _, _, _, err = svc.completeSongData(context.Background(), "Muse", "Uprising", "", "", "")
+	assert.ErrorIs(t, err, ErrNoExternalData)
+}
+
+func TestFallbackConfigValidate(t *testing.T) {
+	assert.NoError(t, FallbackConfig{Mode: FallbackTemplate, ReleaseDate: "16.07.2006"}.Validate())
+	assert.Error(t, FallbackConfig{Mode: FallbackTemplate, ReleaseDate: "2006-07-16"}.Validate())
+	assert.Error(t, FallbackConfig{Mode: "random"}.Validate())
+}
+
+func TestConfigureExternalAPIURL(t *testing.T) {
+	serve := func(text string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"release_date": "16.07.2006", "text": "%s", "link": "https://example.com"}`, text)
+		}))
+	}
+	primary, secondary := serve("primary"), serve("secondary")
+	defer primary.Close()
+	defer secondary.Close()
+	t.Setenv("EXTERNAL_API_URL", primary.URL)
+	svc := NewMusicService(nil, zap.NewNop(), http.DefaultClient)
+
+	lookup := func() string {
+		_, text, _, _, err := svc.completeSongData(context.Background(), "Muse", "Uprising", "", "", "")
+		assert.NoError(t, err)
+		return text
+	}
+	assert.Equal(t, "primary", lookup())
+	svc.ConfigureExternalAPIURL(secondary.URL)
+	assert.Equal(t, "secondary", lookup(), "a reloaded URL replaces the one the provider was created with")
+	svc.ConfigureExternalAPIURL("")
+	assert.Equal(t, "primary", lookup())
+}
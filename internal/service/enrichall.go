@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"music-library/internal/jobs"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// ErrEnrichAllRunning is returned when a batch re-enrichment is requested while another one is running
+var ErrEnrichAllRunning = errors.New("batch re-enrichment already running")
+
+// enrichAllBatchSize is the number of songs loaded at a time by a batch re-enrichment
+const enrichAllBatchSize = 100
+
+// EnrichAllResult is the outcome of a finished batch re-enrichment job. Songs the external API had no data
+// for, or that could not be stored, are counted in the job's failed counter.
+type EnrichAllResult struct {
+	Refreshed int `json:"refreshed"`
+}
+
+// StartEnrichAll queues a background job re-enriching every song matching the filter, within the batch
+// enrichment rate limit. An empty filter selects the songs still holding the fallback placeholder text.
+// Only one batch runs at a time; its progress is available from GetJob.
+func (s *MusicService) StartEnrichAll(ctx context.Context, filter models.SongFilter) (models.Job, error) {
+	if s.jobs == nil {
+		return models.Job{}, ErrJobsUnavailable
+	}
+	for _, field := range filter.Missing {
+		if !repository.IsNullableField(field) {
+			return models.Job{}, fmt.Errorf("%w: %s", ErrUnsupportedField, field)
+		}
+	}
+	if filter.Group == "" && filter.Song == "" && filter.StaleThan == 0 && len(filter.Missing) == 0 && filter.Text == "" {
+		filter = s.placeholderFilter()
+	}
+
+	job, err := s.jobs.SubmitExclusive(ctx, models.JobKindEnrichAll, func(ctx context.Context, progress *jobs.Progress) (any, error) {
+		return s.runEnrichAll(ctx, filter, progress)
+	})
+	if errors.Is(err, jobs.ErrAlreadyRunning) {
+		return job, ErrEnrichAllRunning
+	}
+	if err != nil {
+		s.logger.Error("Failed to queue batch re-enrichment", zap.Error(err))
+		return job, err
+	}
+	s.logger.Info("Batch re-enrichment queued", zap.String("job_id", job.ID))
+	return job, nil
+}
+
+// placeholderFilter selects the songs whose text is the fallback placeholder, or unknown when the
+// fallback leaves it NULL
+func (s *MusicService) placeholderFilter() models.SongFilter {
+	fallback := s.fallbackConfig()
+	if fallback.Text == "" {
+		return models.SongFilter{Missing: []string{"text"}}
+	}
+	return models.SongFilter{Text: fallback.Text}
+}
+
+// runEnrichAll walks the songs matching the filter in ID order, refreshing each from the external API
+// and reporting the progress to the job
+func (s *MusicService) runEnrichAll(ctx context.Context, filter models.SongFilter, progress *jobs.Progress) (*EnrichAllResult, error) {
+	total, err := s.repo.CountSongs(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to count songs to re-enrich", zap.Error(err))
+		return nil, errors.New("failed to count songs")
+	}
+	progress.SetTotal(total)
+	s.logger.Info("Starting batch re-enrichment", zap.Int("total", total))
+
+	result := &EnrichAllResult{}
+	afterID := 0
+	for {
+		songs, err := s.repo.GetSongsAfter(ctx, filter, afterID, enrichAllBatchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			s.logger.Error("Failed to fetch songs to re-enrich", zap.Error(err))
+			return result, errors.New("failed to fetch songs")
+		}
+		if len(songs) == 0 {
+			return result, nil
+		}
+		for _, song := range songs {
+			afterID = song.ID
+			if err := s.enrichLimiter.Wait(ctx); err != nil {
+				return result, err
+			}
+			if err := s.refreshSong(ctx, song, EnrichableFields); err != nil {
+				progress.Add(1, 1)
+				continue
+			}
+			result.Refreshed++
+			progress.Add(1, 0)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/breaker"
+	"music-library/internal/lyrics"
+	"music-library/internal/metrics"
+)
+
+// Supported enrichment providers
+const (
+	EnrichmentProviderInfo   = "info"
+	EnrichmentProviderLyrics = "lyrics"
+	EnrichmentProviderNone   = "none"
+)
+
+// ErrNoDetails is returned by an enrichment provider that has no details for a song
+var ErrNoDetails = errors.New("no song details")
+
+// SongDetails are the details an enrichment provider found for a song; fields it cannot provide are empty
+type SongDetails struct {
+	ReleaseDate string `json:"release_date"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+	// Listeners is the provider's listener count, absent when the provider does not track it
+	Listeners *int64 `json:"listeners"`
+}
+
+// complete reports whether the release date, text and link are all known
+func (d SongDetails) complete() bool {
+	return d.ReleaseDate != "" && d.Text != "" && d.Link != ""
+}
+
+// fill sets the fields still missing from the other details
+func (d *SongDetails) fill(other SongDetails) {
+	if d.ReleaseDate == "" {
+		d.ReleaseDate = other.ReleaseDate
+	}
+	if d.Text == "" {
+		d.Text = other.Text
+	}
+	if d.Link == "" {
+		d.Link = other.Link
+	}
+	if d.Listeners == nil {
+		d.Listeners = other.Listeners
+	}
+}
+
+// EnrichmentProvider looks up the details of songs in an outside source
+type EnrichmentProvider interface {
+	// Name identifies the provider in logs
+	Name() string
+	// Lookup returns the details known for the song, or ErrNoDetails when there are none
+	Lookup(ctx context.Context, group, song string) (SongDetails, error)
+}
+
+// ConfigureEnrichmentProvider sets the provider completing the details of new and re-enriched songs
+func (s *MusicService) ConfigureEnrichmentProvider(provider EnrichmentProvider) {
+	s.enricher = provider
+}
+
+// defaultEnrichmentProvider uses the /info API when EXTERNAL_API_URL is set and no provider otherwise
+func (s *MusicService) defaultEnrichmentProvider() EnrichmentProvider {
+	if apiURL := os.Getenv("EXTERNAL_API_URL"); apiURL != "" {
+		return s.NewInfoAPIProvider(apiURL)
+	}
+	return NoopProvider{}
+}
+
+// EnrichmentProviderFromEnv builds the provider named by ENRICHMENT_PROVIDERS, a comma separated list
+// of providers tried in order. It defaults to the /info API when EXTERNAL_API_URL is set and to no
+// provider otherwise, so deployments without the API do not try to reach it.
+func (s *MusicService) EnrichmentProviderFromEnv() (EnrichmentProvider, error) {
+	value := os.Getenv("ENRICHMENT_PROVIDERS")
+	if value == "" {
+		return s.defaultEnrichmentProvider(), nil
+	}
+	var chain ChainProvider
+	for _, name := range strings.Split(value, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case EnrichmentProviderInfo:
+			apiURL := os.Getenv("EXTERNAL_API_URL")
+			if apiURL == "" {
+				return nil, fmt.Errorf("EXTERNAL_API_URL is required for the %s enrichment provider", name)
+			}
+			chain = append(chain, s.NewInfoAPIProvider(apiURL))
+		case EnrichmentProviderNone, "":
+		default:
+			return nil, fmt.Errorf("unsupported enrichment provider %q", name)
+		}
+	}
+	switch len(chain) {
+	case 0:
+		return NoopProvider{}, nil
+	case 1:
+		return chain[0], nil
+	default:
+		return chain, nil
+	}
+}
+
+// NoopProvider never has details, leaving songs to the fallback data
+type NoopProvider struct{}
+
+// Name returns the provider name
+func (NoopProvider) Name() string {
+	return EnrichmentProviderNone
+}
+
+// Lookup always returns ErrNoDetails
+func (NoopProvider) Lookup(ctx context.Context, group, song string) (SongDetails, error) {
+	return SongDetails{}, ErrNoDetails
+}
+
+// ChainProvider tries its providers in order. Details missing from a provider's answer are filled
+// from the next ones, until the release date, text and link are known.
+type ChainProvider []EnrichmentProvider
+
+// Name returns the names of the chained providers
+func (c ChainProvider) Name() string {
+	names := make([]string, len(c))
+	for i, provider := range c {
+		names[i] = provider.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Lookup merges the details of the providers, failing only when none of them found any
+func (c ChainProvider) Lookup(ctx context.Context, group, song string) (SongDetails, error) {
+	var details SongDetails
+	found := false
+	var errs []error
+	for _, provider := range c {
+		if details.complete() {
+			break
+		}
+		next, err := provider.Lookup(ctx, group, song)
+		if err != nil {
+			if !errors.Is(err, ErrNoDetails) {
+				errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			}
+			continue
+		}
+		details.fill(next)
+		found = true
+	}
+	if found {
+		return details, nil
+	}
+	if len(errs) == 0 {
+		return SongDetails{}, ErrNoDetails
+	}
+	return SongDetails{}, errors.Join(errs...)
+}
+
+// lyricsProvider completes the text of songs from a lyrics API
+type lyricsProvider struct {
+	s      *MusicService
+	client *lyrics.Client
+}
+
+// NewLyricsProvider creates a provider for the lyrics API, bounded by the external API timeout.
+// Chained after the /info API it fills in the text the API left empty.
+func (s *MusicService) NewLyricsProvider(client *lyrics.Client) EnrichmentProvider {
+	return &lyricsProvider{s: s, client: client}
+}
+
+// Name returns the provider name
+func (p *lyricsProvider) Name() string {
+	return EnrichmentProviderLyrics
+}
+
+// Lookup fetches the song's lyrics as its text
+func (p *lyricsProvider) Lookup(ctx context.Context, group, song string) (SongDetails, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.s.timeouts.ExternalAPI)
+	defer cancel()
+	text, err := p.client.Fetch(ctx, group, song)
+	if err != nil {
+		if errors.Is(err, lyrics.ErrNotFound) {
+			return SongDetails{}, ErrNoDetails
+		}
+		p.s.logger.Warn("Failed to fetch lyrics", zap.String("group", group), zap.String("song", song), zap.Error(err))
+		return SongDetails{}, err
+	}
+	return SongDetails{Text: text}, nil
+}
+
+// infoAPIProvider requests song details from the /info endpoint of the external API. Every call consumes
+// from the provider budget, waiting while the per-minute budget is spent, is bounded by the external API
+// timeout and retried on transient failures.
+type infoAPIProvider struct {
+	s      *MusicService
+	apiURL string
+}
+
+// NewInfoAPIProvider creates a provider for the /info API at the URL, authorized, budgeted and guarded
+// by the circuit breaker as configured on the service
+func (s *MusicService) NewInfoAPIProvider(apiURL string) EnrichmentProvider {
+	return &infoAPIProvider{s: s, apiURL: apiURL}
+}
+
+// Name returns the provider name
+func (p *infoAPIProvider) Name() string {
+	return EnrichmentProviderInfo
+}
+
+// Lookup requests the external API for the song. Transient failures are retried with backoff; while the
+// circuit breaker is open no call is made at all, so callers fall back at once instead of waiting for
+// every call to time out.
+func (p *infoAPIProvider) Lookup(ctx context.Context, group, song string) (SongDetails, error) {
+	s := p.s
+	for attempt := 1; ; attempt++ {
+		info, err := p.call(ctx, group, song)
+		if err == nil {
+			return info, nil
+		}
+		var callErr *externalCallError
+		if !errors.As(err, &callErr) || !callErr.retryable || attempt >= s.retry.Attempts {
+			return SongDetails{}, err
+		}
+		delay := s.retry.backoff(attempt)
+		s.logger.Debug("Retrying external API call", zap.Int("attempt", attempt), zap.Duration("delay", delay), zap.Error(err))
+		metrics.ExternalAPIRetries.Inc()
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return SongDetails{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// externalCallError is a failed call to the external API
+type externalCallError struct {
+	// retryable is set for failures that may pass on another attempt: transport errors, timeouts,
+	// rate limiting and server errors
+	retryable bool
+	err       error
+}
+
+func (e *externalCallError) Error() string {
+	return e.err.Error()
+}
+
+func (e *externalCallError) Unwrap() error {
+	return e.err
+}
+
+// call makes a single external API call within the provider budget and the circuit breaker.
+// Retryable failures count against the breaker; answers the API gave deliberately, such as not found, do not.
+func (p *infoAPIProvider) call(ctx context.Context, group, song string) (SongDetails, error) {
+	s := p.s
+	// Checked before acquiring the budget, so an open breaker does not spend it
+	if s.breaker.State() == breaker.Open {
+		s.logger.Warn("External API call not made", zap.String("group", group), zap.String("song", song), zap.Error(breaker.ErrOpen))
+		metrics.ExternalAPIErrors.WithLabelValues(metrics.ReasonCircuitOpen).Inc()
+		return SongDetails{}, breaker.ErrOpen
+	}
+	if err := s.budget.Acquire(ctx, ExternalAPIProvider); err != nil {
+		s.logger.Warn("External API call not made", zap.String("group", group), zap.String("song", song), zap.Error(err))
+		metrics.ExternalAPIErrors.WithLabelValues(metrics.ReasonBudget).Inc()
+		return SongDetails{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeouts.ExternalAPI)
+	defer cancel()
+
+	apiURL := p.apiURL
+	if reloaded := s.externalAPIURL.Load(); reloaded != nil {
+		apiURL = *reloaded
+	}
+	url := fmt.Sprintf("%s/info?group=%s&song=%s", apiURL, url.QueryEscape(group), url.QueryEscape(song))
+	s.logger.Debug("Fetching data from external API", zap.String("url", url), zap.String("auth", s.provider.AuthType))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		s.logger.Warn("Failed to build external API request", zap.Error(err))
+		return SongDetails{}, err
+	}
+	s.provider.authorize(req)
+	if err := s.breaker.Allow(); err != nil {
+		s.logger.Warn("External API call not made", zap.String("group", group), zap.String("song", song), zap.Error(err))
+		metrics.ExternalAPIErrors.WithLabelValues(metrics.ReasonCircuitOpen).Inc()
+		return SongDetails{}, err
+	}
+	start := time.Now()
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		s.logger.Warn("Failed to fetch data from external API", zap.Error(err))
+		metrics.ExternalAPIDuration.WithLabelValues(metrics.OutcomeError).Observe(time.Since(start).Seconds())
+		metrics.ExternalAPIErrors.WithLabelValues(metrics.ReasonRequest).Inc()
+		s.breaker.Failure()
+		return SongDetails{}, &externalCallError{retryable: true, err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		s.logger.Warn("External API returned non-OK status", zap.Int("status_code", resp.StatusCode))
+		metrics.ExternalAPIDuration.WithLabelValues(metrics.OutcomeError).Observe(time.Since(start).Seconds())
+		metrics.ExternalAPIErrors.WithLabelValues(metrics.ReasonStatus).Inc()
+		retryable := resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+		if retryable {
+			s.breaker.Failure()
+		} else {
+			s.breaker.Success()
+		}
+		return SongDetails{}, &externalCallError{retryable: retryable, err: fmt.Errorf("external API returned status %d", resp.StatusCode)}
+	}
+
+	var info SongDetails
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		s.logger.Warn("Failed to decode external API response", zap.Error(err))
+		metrics.ExternalAPIDuration.WithLabelValues(metrics.OutcomeError).Observe(time.Since(start).Seconds())
+		metrics.ExternalAPIErrors.WithLabelValues(metrics.ReasonDecode).Inc()
+		s.breaker.Failure()
+		return SongDetails{}, &externalCallError{err: err}
+	}
+
+	metrics.ExternalAPIDuration.WithLabelValues(metrics.OutcomeSuccess).Observe(time.Since(start).Seconds())
+	s.breaker.Success()
+	return info, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// staticProvider answers every lookup with the same details or error
+type staticProvider struct {
+	name    string
+	details SongDetails
+	err     error
+	calls   int
+}
+
+func (p *staticProvider) Name() string {
+	return p.name
+}
+
+func (p *staticProvider) Lookup(ctx context.Context, group, song string) (SongDetails, error) {
+	p.calls++
+	return p.details, p.err
+}
+
+func TestChainProvider(t *testing.T) {
+	listeners := int64(42)
+	failing := &staticProvider{name: "failing", err: errors.New("unavailable")}
+	partial := &staticProvider{name: "partial", details: SongDetails{ReleaseDate: "16.07.2006", Listeners: &listeners}}
+	full := &staticProvider{name: "full", details: SongDetails{ReleaseDate: "01.01.2000", Text: "Verse", Link: "https://example.com"}}
+	unused := &staticProvider{name: "unused", details: SongDetails{Text: "Other"}}
+
+	chain := ChainProvider{failing, NoopProvider{}, partial, full, unused}
+	assert.Equal(t, "failing,none,partial,full,unused", chain.Name())
+	details, err := chain.Lookup(context.Background(), "Muse", "Uprising")
+	require.NoError(t, err)
+	assert.Equal(t, "16.07.2006", details.ReleaseDate, "earlier providers take precedence")
+	assert.Equal(t, "Verse", details.Text)
+	assert.Equal(t, &listeners, details.Listeners)
+	assert.Zero(t, unused.calls, "the chain stops once the details are complete")
+
+	_, err = ChainProvider{NoopProvider{}}.Lookup(context.Background(), "Muse", "Uprising")
+	assert.ErrorIs(t, err, ErrNoDetails)
+	_, err = ChainProvider{failing, NoopProvider{}}.Lookup(context.Background(), "Muse", "Uprising")
+	assert.ErrorContains(t, err, "failing: unavailable")
+}
+
+func TestEnrichmentProviderFromEnv(t *testing.T) {
+	svc := NewMusicService(nil, zap.NewNop(), http.DefaultClient)
+
+	t.Setenv("EXTERNAL_API_URL", "")
+	t.Setenv("ENRICHMENT_PROVIDERS", "")
+	provider, err := svc.EnrichmentProviderFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, EnrichmentProviderNone, provider.Name(), "no provider without the external API")
+
+	t.Setenv("ENRICHMENT_PROVIDERS", "info")
+	_, err = svc.EnrichmentProviderFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("EXTERNAL_API_URL", "http://mock-api:8081")
+	t.Setenv("ENRICHMENT_PROVIDERS", "")
+	provider, err = svc.EnrichmentProviderFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, EnrichmentProviderInfo, provider.Name())
+
+	t.Setenv("ENRICHMENT_PROVIDERS", " info , none")
+	provider, err = svc.EnrichmentProviderFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, EnrichmentProviderInfo, provider.Name())
+
+	t.Setenv("ENRICHMENT_PROVIDERS", "info,scraper")
+	_, err = svc.EnrichmentProviderFromEnv()
+	assert.Error(t, err)
+}
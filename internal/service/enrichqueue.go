@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/analytics"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// EnrichmentQueueConfig sizes the background enrichment of added songs
+type EnrichmentQueueConfig struct {
+	// Workers is the number of songs enriched concurrently
+	Workers int
+	// Capacity is the number of added songs waiting for a worker; songs added while the queue is full
+	// are picked up by the next sweep
+	Capacity int
+	// SweepInterval is how often songs left pending, by a full queue or a restart, are queued again
+	SweepInterval time.Duration
+}
+
+// DefaultEnrichmentQueueConfig is used for the values StartEnrichmentWorkers is not given
+var DefaultEnrichmentQueueConfig = EnrichmentQueueConfig{
+	Workers:       4,
+	Capacity:      1000,
+	SweepInterval: time.Minute,
+}
+
+// EnrichmentQueueConfigFromEnv reads the enrichment queue configuration from ENRICHMENT_WORKERS,
+// ENRICHMENT_QUEUE_CAPACITY and ENRICHMENT_SWEEP_INTERVAL. ENRICHMENT_WORKERS=0 is valid and leaves Workers
+// at 0: no workers are started and AddSong keeps enriching songs on its insert path.
+func EnrichmentQueueConfigFromEnv() (EnrichmentQueueConfig, error) {
+	cfg := DefaultEnrichmentQueueConfig
+	var err error
+	if value := os.Getenv("ENRICHMENT_WORKERS"); value != "" {
+		if cfg.Workers, err = strconv.Atoi(value); err != nil || cfg.Workers < 0 {
+			return cfg, fmt.Errorf("ENRICHMENT_WORKERS must be a number of workers, or 0 to enrich on insert: %q", value)
+		}
+	}
+	if value := os.Getenv("ENRICHMENT_QUEUE_CAPACITY"); value != "" {
+		if cfg.Capacity, err = strconv.Atoi(value); err != nil || cfg.Capacity < 1 {
+			return cfg, fmt.Errorf("ENRICHMENT_QUEUE_CAPACITY must be a positive number: %q", value)
+		}
+	}
+	if value := os.Getenv("ENRICHMENT_SWEEP_INTERVAL"); value != "" {
+		if cfg.SweepInterval, err = time.ParseDuration(value); err != nil || cfg.SweepInterval <= 0 {
+			return cfg, fmt.Errorf("ENRICHMENT_SWEEP_INTERVAL must be a positive duration: %q", value)
+		}
+	}
+	return cfg, nil
+}
+
+// enrichmentJob is a pending song waiting for a worker
+type enrichmentJob struct {
+	ID    int
+	Group string
+	Song  string
+}
+
+// StartEnrichmentWorkers makes AddSong return as soon as the song is inserted, leaving it pending until
+// one of the workers has fetched its details. Pending songs found in the database, such as those left by
+// a restart, are queued on start and on every sweep.
+func (s *MusicService) StartEnrichmentWorkers(ctx context.Context, cfg EnrichmentQueueConfig) {
+	if cfg.Workers < 1 {
+		cfg.Workers = DefaultEnrichmentQueueConfig.Workers
+	}
+	if cfg.Capacity < 1 {
+		cfg.Capacity = DefaultEnrichmentQueueConfig.Capacity
+	}
+	if cfg.SweepInterval <= 0 {
+		cfg.SweepInterval = DefaultEnrichmentQueueConfig.SweepInterval
+	}
+	s.logger.Info("Starting enrichment workers", zap.Int("workers", cfg.Workers), zap.Int("capacity", cfg.Capacity))
+	s.enrichQueue = make(chan enrichmentJob, cfg.Capacity)
+	s.enrichQueued = make(map[int]bool)
+
+	for i := 0; i < cfg.Workers; i++ {
+		s.background.Add(1)
+		go func() {
+			defer s.background.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-s.enrichQueue:
+					s.enrichPendingSong(ctx, job)
+				}
+			}
+		}()
+	}
+
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ticker := time.NewTicker(cfg.SweepInterval)
+		defer ticker.Stop()
+		for {
+			s.sweepPendingEnrichments(ctx, cfg.Capacity)
+			select {
+			case <-ctx.Done():
+				s.logger.Info("Enrichment workers stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// enqueueEnrichment hands a pending song to the workers without blocking. It reports false when the
+// song is already queued or the queue is full.
+func (s *MusicService) enqueueEnrichment(job enrichmentJob) bool {
+	s.enrichMu.Lock()
+	defer s.enrichMu.Unlock()
+	if s.enrichQueued[job.ID] {
+		return false
+	}
+	select {
+	case s.enrichQueue <- job:
+		s.enrichQueued[job.ID] = true
+		return true
+	default:
+		return false
+	}
+}
+
+// sweepPendingEnrichments queues the pending songs not already waiting for a worker
+func (s *MusicService) sweepPendingEnrichments(ctx context.Context, limit int) {
+	s.enrichMu.Lock()
+	exclude := make([]int, 0, len(s.enrichQueued))
+	for id := range s.enrichQueued {
+		exclude = append(exclude, id)
+	}
+	s.enrichMu.Unlock()
+
+	songs, err := s.repo.GetPendingEnrichments(ctx, exclude, limit)
+	if err != nil {
+		s.logger.Error("Failed to fetch songs pending enrichment", zap.Error(err))
+		return
+	}
+	queued := 0
+	for _, song := range songs {
+		if s.enqueueEnrichment(enrichmentJob{ID: song.ID, Group: song.Group, Song: song.Song}) {
+			queued++
+		}
+	}
+	if queued > 0 {
+		s.logger.Info("Queued songs pending enrichment", zap.Int("count", queued))
+	}
+}
+
+// enrichPendingSong fetches the details of a pending song and completes it, or marks it failed when no
+// details were found and fallback is disabled. Songs whose enrichment is cut short by shutdown stay
+// pending for the next start.
+func (s *MusicService) enrichPendingSong(ctx context.Context, job enrichmentJob) {
+	var err error
+	defer metrics.ObserveOperation("enrich_pending_song", time.Now(), &err)
+	defer func() {
+		s.enrichMu.Lock()
+		delete(s.enrichQueued, job.ID)
+		s.enrichMu.Unlock()
+	}()
+
+	releaseDate, text, link, enriched, err := s.completeSongData(ctx, job.Group, job.Song, "", "", "")
+	if ctx.Err() != nil {
+		s.logger.Info("Enrichment interrupted, song left pending", zap.Int("id", job.ID))
+		return
+	}
+	if errors.Is(err, ErrNoExternalData) {
+		s.logger.Warn("Song enrichment failed", zap.Int("id", job.ID), zap.Error(err))
+		if err = s.repo.FailEnrichment(ctx, job.ID, err.Error()); err != nil && err != sql.ErrNoRows {
+			s.logger.Error("Failed to mark song enrichment failed", zap.Int("id", job.ID), zap.Error(err))
+		}
+		return
+	}
+
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.CompleteEnrichment(ctx, job.ID, releaseDate, text, link, enrichedAt(enriched)); err != nil {
+			return err
+		}
+		return s.record(ctx, analytics.EventSongUpdated, job.ID, 1)
+	})
+	if err == sql.ErrNoRows {
+		s.logger.Info("Song deleted before enrichment completed", zap.Int("id", job.ID))
+		err = nil
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to complete song enrichment", zap.Int("id", job.ID), zap.Error(err))
+		return
+	}
+	s.logger.Info("Song enrichment completed", zap.Int("id", job.ID), zap.Bool("enriched", enriched))
+	s.publish(analytics.EventSongUpdated, job.ID, 1)
+	s.indexVerses(ctx, job.ID, text)
+	s.classifySongs(classificationTarget{ID: job.ID, Text: text})
+}
+
+// GetEnrichmentStatus returns how far the enrichment of a song has come
+func (s *MusicService) GetEnrichmentStatus(ctx context.Context, id int) (_ models.EnrichmentStatus, err error) {
+	defer metrics.ObserveOperation("get_enrichment_status", time.Now(), &err)
+	s.logger.Debug("Fetching enrichment status", zap.Int("id", id))
+	status, err := s.repo.GetEnrichmentStatus(ctx, id)
+	if err != nil {
+		return models.EnrichmentStatus{}, err
+	}
+	return status, nil
+}
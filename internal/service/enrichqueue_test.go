@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrichmentQueueConfigFromEnv(t *testing.T) {
+	cfg, err := EnrichmentQueueConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultEnrichmentQueueConfig, cfg)
+
+	// 0 selects synchronous enrichment rather than falling back to the default
+	t.Setenv("ENRICHMENT_WORKERS", "0")
+	cfg, err = EnrichmentQueueConfigFromEnv()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.Workers)
+
+	t.Setenv("ENRICHMENT_WORKERS", "8")
+	t.Setenv("ENRICHMENT_QUEUE_CAPACITY", "50")
+	t.Setenv("ENRICHMENT_SWEEP_INTERVAL", "30s")
+	cfg, err = EnrichmentQueueConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, EnrichmentQueueConfig{Workers: 8, Capacity: 50, SweepInterval: 30 * time.Second}, cfg)
+
+	for _, workers := range []string{"-1", "four"} {
+		t.Setenv("ENRICHMENT_WORKERS", workers)
+		_, err = EnrichmentQueueConfigFromEnv()
+		assert.Error(t, err, workers)
+	}
+	t.Setenv("ENRICHMENT_WORKERS", "")
+	t.Setenv("ENRICHMENT_QUEUE_CAPACITY", "0")
+	_, err = EnrichmentQueueConfigFromEnv()
+	assert.Error(t, err)
+}
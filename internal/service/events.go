@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/changes"
+	"music-library/internal/models"
+	"music-library/internal/outbox"
+)
+
+// DefaultEventRetention is how long published catalog events stay in the event log for streams to resume from
+const DefaultEventRetention = 7 * 24 * time.Hour
+
+// eventBatchSize is the number of logged events read at once
+const eventBatchSize = 100
+
+// ConfigureOutbox hands the catalog changes recorded in the outbox to the relay, which pushes them to the
+// change subscribers once committed. The relay is started by the caller after its other subscribers are added.
+func (s *MusicService) ConfigureOutbox(relay *outbox.Relay) {
+	s.relay = relay
+	relay.Subscribe("changes", s.relayChange)
+}
+
+// record writes the catalog event of a change to the outbox. Called with the context of RunInTransaction,
+// the event is committed with the change, so it is published even if the process stops right after.
+// Changes that are not catalog events are not recorded.
+func (s *MusicService) record(ctx context.Context, changeType string, songID, count int) error {
+	event, ok := changes.EventOf(changes.Change{Type: changeType})
+	if !ok {
+		return nil
+	}
+	_, err := s.repo.AddSongEvent(ctx, models.SongEvent{
+		Type:       event.Type,
+		SongID:     songID,
+		Count:      count,
+		OccurredAt: time.Now().UTC(),
+	})
+	if err != nil {
+		s.logger.Error("Failed to record catalog event", zap.String("event_type", event.Type), zap.Error(err))
+	}
+	return err
+}
+
+// relayChange publishes a catalog event relayed from the outbox to the change hub
+func (s *MusicService) relayChange(_ context.Context, event changes.Event) error {
+	if changeType, ok := changes.ChangeTypeOf(event.Type); ok {
+		s.changes.Publish(changeType, event.SongID, event.Count)
+	}
+	return nil
+}
+
+// StartEventPruning drops the published catalog events older than retention from the event log every hour
+// until ctx is cancelled
+func (s *MusicService) StartEventPruning(ctx context.Context, retention time.Duration) {
+	if retention <= 0 {
+		retention = DefaultEventRetention
+	}
+	s.logger.Info("Starting event log pruning", zap.Duration("retention", retention))
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			if dropped, err := s.repo.DeleteSongEventsBefore(ctx, time.Now().Add(-retention)); err == nil && dropped > 0 {
+				s.logger.Info("Old catalog events dropped", zap.Int64("count", dropped))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// LatestSongEvent returns the ID of the latest logged catalog event, from which a stream receives only
+// future events
+func (s *MusicService) LatestSongEvent(ctx context.Context) (int64, error) {
+	return s.repo.GetLatestSongEventID(ctx)
+}
+
+// WaitSongEvents returns the logged catalog events after the given event ID, holding the call until one is
+// logged, the timeout elapses or ctx is done. No events means none arrived in time. Events logged by other
+// instances are only seen once the call returns, so callers keep the timeout short.
+func (s *MusicService) WaitSongEvents(ctx context.Context, afterID int64, timeout time.Duration) ([]models.SongEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		// Taken before reading, so a change published in between wakes the wait
+		cursor := s.changes.Last()
+		events, err := s.repo.GetSongEventsAfter(ctx, afterID, eventBatchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return []models.SongEvent{}, nil
+			}
+			return nil, err
+		}
+		if len(events) > 0 {
+			return events, nil
+		}
+		s.changes.Wait(ctx, cursor)
+		if ctx.Err() != nil {
+			return events, nil
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// ErrUnsupportedExportFormat is returned when a client requests an unknown export format
+var ErrUnsupportedExportFormat = errors.New("unsupported export format")
+
+// Export formats
+const (
+	ExportFormatCSV = "csv"
+)
+
+// exportColumn is a CSV export column, named like the JSON song field it holds
+type exportColumn struct {
+	name  string
+	value func(song models.Song) string
+}
+
+// exportColumns are the columns of a CSV export. The first ones match the default import mapping,
+// so an export can be imported back.
+var exportColumns = []exportColumn{
+	{"group", func(s models.Song) string { return s.Group }},
+	{"song", func(s models.Song) string { return s.Song }},
+	{"release_date", func(s models.Song) string { return derefString(s.ReleaseDate) }},
+	{"text", func(s models.Song) string { return derefString(s.Text) }},
+	{"link", func(s models.Song) string { return derefString(s.Link) }},
+	{"id", func(s models.Song) string { return strconv.Itoa(s.ID) }},
+	{"album", func(s models.Song) string { return derefString(s.Album) }},
+	{"duration_ms", func(s models.Song) string {
+		if s.DurationMs == nil {
+			return ""
+		}
+		return strconv.Itoa(*s.DurationMs)
+	}},
+	{"isrc", func(s models.Song) string { return derefString(s.ISRC) }},
+	{"artwork_url", func(s models.Song) string { return derefString(s.ArtworkURL) }},
+	{"notes", func(s models.Song) string { return derefString(s.Notes) }},
+	{"licensing_fee", func(s models.Song) string {
+		if s.LicensingFee == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*s.LicensingFee, 'f', -1, 64)
+	}},
+	{"enrichment_status", func(s models.Song) string { return s.EnrichmentStatus }},
+	{"split_strategy", func(s models.Song) string { return derefString(s.SplitStrategy) }},
+	{"album_id", func(s models.Song) string {
+		if s.AlbumID == nil {
+			return ""
+		}
+		return strconv.Itoa(*s.AlbumID)
+	}},
+	{"artist_id", func(s models.Song) string {
+		if s.ArtistID == nil {
+			return ""
+		}
+		return strconv.Itoa(*s.ArtistID)
+	}},
+	{"created_at", func(s models.Song) string { return s.CreatedAt.Format(time.RFC3339) }},
+	{"updated_at", func(s models.Song) string { return s.UpdatedAt.Format(time.RFC3339) }},
+	{"enriched_at", func(s models.Song) string {
+		if s.EnrichedAt == nil {
+			return ""
+		}
+		return s.EnrichedAt.Format(time.RFC3339)
+	}},
+}
+
+// derefString returns the string, or an empty one for NULL
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// ValidateExport checks an export request before anything is written, so that its errors can still be
+// reported with a status
+func ValidateExport(filter models.SongFilter, format, dateFormat string) error {
+	if format != ExportFormatCSV {
+		return fmt.Errorf("%w: %s", ErrUnsupportedExportFormat, format)
+	}
+	for _, field := range filter.Missing {
+		if !repository.IsNullableField(field) {
+			return fmt.Errorf("%w: %s", ErrUnsupportedField, field)
+		}
+	}
+	return ValidateDateFormat(dateFormat)
+}
+
+// ExportSongs streams every song matching the filter to w as CSV in ID order, leaving out the hidden fields.
+// Songs are streamed a batch at a time and each batch is flushed, so memory use does not depend on the catalog
+// size. It returns the number of songs written; an error past the first write leaves the output truncated.
+func (s *MusicService) ExportSongs(ctx context.Context, w io.Writer, filter models.SongFilter, format, dateFormat string, hidden map[string]bool) (count int, err error) {
+	defer metrics.ObserveOperation("export_songs", time.Now(), &err)
+	if err := ValidateExport(filter, format, dateFormat); err != nil {
+		return 0, err
+	}
+	s.logger.Info("Exporting songs", zap.String("format", format))
+
+	columns := make([]exportColumn, 0, len(exportColumns))
+	for _, column := range exportColumns {
+		if !hidden[column.name] {
+			columns = append(columns, column)
+		}
+	}
+	writer := csv.NewWriter(w)
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.name
+	}
+	if err := writer.Write(record); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	count, err = s.StreamSongs(ctx, filter, dateFormat, func(song models.Song) error {
+		for i, column := range columns {
+			record[i] = column.value(song)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+		// Each batch read is sent on to the client
+		if written++; written%streamBatchSize == 0 {
+			return flushCSV(writer, w)
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	if err := flushCSV(writer, w); err != nil {
+		return count, err
+	}
+	s.logger.Info("Songs exported", zap.Int("count", count))
+	return count, nil
+}
+
+// flushCSV writes the buffered records and, when w is an HTTP response, sends them to the client
+func flushCSV(writer *csv.Writer, w io.Writer) error {
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	if flusher, ok := w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+	return nil
+}
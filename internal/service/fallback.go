@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNoExternalData is returned when the external API cannot complete a song and fallback data is disabled
+var ErrNoExternalData = errors.New("external API provided no data")
+
+// Fallback modes applied when the external API cannot complete a song
+const (
+	// FallbackMock stores the built-in placeholder data
+	FallbackMock = "mock"
+	// FallbackTemplate stores the configured release date, text and link
+	FallbackTemplate = "template"
+	// FallbackNull leaves the missing fields NULL
+	FallbackNull = "null"
+	// FallbackDisabled refuses to store songs the external API cannot complete
+	FallbackDisabled = "disabled"
+)
+
+// FallbackConfig controls what is stored for fields the external API cannot provide
+type FallbackConfig struct {
+	Mode string
+	// ReleaseDate, Text and Link are the template values; empty values are stored as NULL
+	ReleaseDate string
+	Text        string
+	Link        string
+}
+
+// DefaultFallbackConfig is used until ConfigureFallback is called
+var DefaultFallbackConfig = FallbackConfig{
+	Mode:        FallbackMock,
+	ReleaseDate: "01.01.2000",
+	Text:        "Verse 1\n\nVerse 2\n\nVerse 3",
+	Link:        "https://example.com",
+}
+
+// FallbackConfigFromEnv reads the fallback configuration from FALLBACK_* environment variables.
+// FALLBACK_TEXT may use \n for line breaks.
+func FallbackConfigFromEnv() (FallbackConfig, error) {
+	return FallbackConfigFrom(os.Getenv)
+}
+
+// FallbackConfigFrom reads the fallback configuration from the FALLBACK_* settings returned by getenv,
+// such as those of a reloaded configuration snapshot
+func FallbackConfigFrom(getenv func(string) string) (FallbackConfig, error) {
+	cfg := FallbackConfig{
+		Mode:        getenv("FALLBACK_MODE"),
+		ReleaseDate: getenv("FALLBACK_RELEASE_DATE"),
+		Text:        strings.ReplaceAll(getenv("FALLBACK_TEXT"), `\n`, "\n"),
+		Link:        getenv("FALLBACK_LINK"),
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = DefaultFallbackConfig.Mode
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks the mode and the template release date
+func (c FallbackConfig) Validate() error {
+	switch c.Mode {
+	case FallbackMock, FallbackNull, FallbackDisabled:
+		return nil
+	case FallbackTemplate:
+		if c.ReleaseDate == "" {
+			return nil
+		}
+		if _, err := time.Parse("02.01.2006", c.ReleaseDate); err != nil {
+			return fmt.Errorf("FALLBACK_RELEASE_DATE must be formatted DD.MM.YYYY: %q", c.ReleaseDate)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported fallback mode %q", c.Mode)
+	}
+}
+
+// ConfigureFallback sets what is stored for fields the external API cannot provide. It may be called while
+// songs are added, which use either the previous or the new configuration as a whole.
+func (s *MusicService) ConfigureFallback(cfg FallbackConfig) {
+	switch cfg.Mode {
+	case FallbackTemplate:
+	case FallbackNull, FallbackDisabled:
+		cfg.ReleaseDate, cfg.Text, cfg.Link = "", "", ""
+	default:
+		cfg = DefaultFallbackConfig
+	}
+	s.fallback.Store(&cfg)
+}
+
+// fallbackConfig returns the fallback configuration in effect
+func (s *MusicService) fallbackConfig() FallbackConfig {
+	return *s.fallback.Load()
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/acoustid"
+	"music-library/internal/metrics"
+)
+
+// ErrFingerprintingUnavailable is returned when fingerprints are matched without an AcoustID API key configured
+var ErrFingerprintingUnavailable = errors.New("fingerprint matching is not configured")
+
+// ErrAudioFingerprintingUnavailable is returned when audio files are uploaded but fpcalc is not installed
+var ErrAudioFingerprintingUnavailable = errors.New("audio fingerprinting is not available")
+
+// FingerprintMatch is the outcome of identifying one fingerprint or audio file: the catalog song it was
+// linked to, created when the catalog did not have it yet, or the error that prevented a match
+type FingerprintMatch struct {
+	Index       int     `json:"index" example:"0"`
+	ID          int     `json:"id,omitempty" example:"1"`
+	Group       string  `json:"group,omitempty" example:"Muse"`
+	Song        string  `json:"song,omitempty" example:"Supermassive Black Hole"`
+	RecordingID string  `json:"recording_id,omitempty" example:"5c5a0b1e-8b3f-4a57-9d5e-3f6d1c0f2a7b"`
+	Score       float64 `json:"score,omitempty" example:"0.97"`
+	Created     bool    `json:"created"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// ConfigureAcoustID sets the AcoustID client identifying fingerprints and, when fpcalc is installed,
+// the tool fingerprinting uploaded audio
+func (s *MusicService) ConfigureAcoustID(client *acoustid.Client, fpcalc *acoustid.Fpcalc) {
+	s.acoustid = client
+	s.fpcalc = fpcalc
+}
+
+// MatchFingerprints identifies the recordings of Chromaprint fingerprints on AcoustID and links each to its
+// catalog song, adding the songs the catalog does not have. Failures are reported per fingerprint.
+func (s *MusicService) MatchFingerprints(ctx context.Context, fingerprints []acoustid.Fingerprint) (_ []FingerprintMatch, err error) {
+	defer metrics.ObserveOperation("match_fingerprints", time.Now(), &err)
+	if s.acoustid == nil {
+		return nil, ErrFingerprintingUnavailable
+	}
+	s.logger.Info("Matching fingerprints", zap.Int("count", len(fingerprints)))
+	results := make([]FingerprintMatch, len(fingerprints))
+	for i, fp := range fingerprints {
+		results[i] = s.matchFingerprint(ctx, i, fp)
+	}
+	s.logMatches(results)
+	return results, nil
+}
+
+// IdentifyAudio fingerprints the audio files at the paths with fpcalc and matches them like MatchFingerprints
+func (s *MusicService) IdentifyAudio(ctx context.Context, paths []string) (_ []FingerprintMatch, err error) {
+	defer metrics.ObserveOperation("identify_audio", time.Now(), &err)
+	if s.acoustid == nil {
+		return nil, ErrFingerprintingUnavailable
+	}
+	if s.fpcalc == nil {
+		return nil, ErrAudioFingerprintingUnavailable
+	}
+	s.logger.Info("Identifying audio files", zap.Int("count", len(paths)))
+	results := make([]FingerprintMatch, len(paths))
+	for i, path := range paths {
+		fp, err := s.fpcalc.Compute(ctx, path)
+		if err != nil {
+			s.logger.Warn("Failed to fingerprint audio file", zap.Int("index", i), zap.Error(err))
+			results[i] = FingerprintMatch{Index: i, Error: "failed to fingerprint audio file"}
+			continue
+		}
+		results[i] = s.matchFingerprint(ctx, i, fp)
+	}
+	s.logMatches(results)
+	return results, nil
+}
+
+// matchFingerprint looks up one fingerprint within the enrichment rate limit and links it to its song
+func (s *MusicService) matchFingerprint(ctx context.Context, index int, fp acoustid.Fingerprint) FingerprintMatch {
+	result := FingerprintMatch{Index: index}
+	if fp.Fingerprint == "" || fp.Duration <= 0 {
+		result.Error = "fingerprint and duration are required"
+		return result
+	}
+	if err := s.enrichLimiter.Wait(ctx); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, s.timeouts.ExternalAPI)
+	recording, err := s.acoustid.Lookup(lookupCtx, fp)
+	cancel()
+	if err != nil {
+		if errors.Is(err, acoustid.ErrNotFound) {
+			result.Error = err.Error()
+			return result
+		}
+		s.logger.Warn("Failed to look up fingerprint", zap.Int("index", index), zap.Error(err))
+		result.Error = "fingerprint lookup failed"
+		return result
+	}
+	result.Group, result.Song = recording.Artist, recording.Title
+	result.RecordingID, result.Score = recording.ID, recording.Score
+
+	id, exists, err := s.SongExists(ctx, recording.Artist, recording.Title)
+	if err != nil {
+		result.Error = "failed to look up song"
+		return result
+	}
+	if !exists {
+		if id, _, err = s.AddSong(ctx, recording.Artist, recording.Title); err != nil {
+			result.Error = "failed to add song"
+			return result
+		}
+		result.Created = true
+	}
+	result.ID = id
+	return result
+}
+
+// logMatches logs how many songs a batch identified and added
+func (s *MusicService) logMatches(results []FingerprintMatch) {
+	matched, created := 0, 0
+	for _, result := range results {
+		if result.ID != 0 {
+			matched++
+		}
+		if result.Created {
+			created++
+		}
+	}
+	s.logger.Info("Fingerprints matched", zap.Int("matched", matched), zap.Int("created", created),
+		zap.Int("unmatched", len(results)-matched))
+}
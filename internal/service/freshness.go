@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/analytics"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// EnrichableFields are the song fields re-enrichment may overwrite
+var EnrichableFields = []string{"release_date", "text", "link"}
+
+// ReenrichmentConfig controls the job that refreshes stale songs from the external API
+type ReenrichmentConfig struct {
+	// StaleAfter is how long enriched data stays fresh
+	StaleAfter time.Duration
+	// BatchSize is the number of songs refreshed per run, stalest first
+	BatchSize int
+}
+
+// DefaultReenrichmentConfig refreshes up to 50 songs per run whose data is older than 90 days
+var DefaultReenrichmentConfig = ReenrichmentConfig{
+	StaleAfter: 90 * 24 * time.Hour,
+	BatchSize:  50,
+}
+
+// ReenrichStale refreshes the stalest songs from the external API: never-enriched songs first,
+// then the ones enriched longest ago. Songs in skip are not attempted. It returns the number of songs
+// attempted and the IDs of those the API had no data for, which keep their enriched_at.
+func (s *MusicService) ReenrichStale(ctx context.Context, cfg ReenrichmentConfig, skip []int) (int, []int, error) {
+	s.logger.Debug("Re-enriching stale songs", zap.Duration("stale_after", cfg.StaleAfter), zap.Int("batch_size", cfg.BatchSize))
+	songs, err := s.repo.GetStalestSongs(ctx, cfg.StaleAfter, skip, cfg.BatchSize)
+	if err != nil {
+		s.logger.Error("Failed to fetch stale songs", zap.Error(err))
+		return 0, nil, err
+	}
+
+	var failed []int
+	var refreshed []classificationTarget
+	for _, song := range songs {
+		if err := s.enrichLimiter.Wait(ctx); err != nil {
+			s.classifySongs(refreshed...)
+			return len(songs), failed, err
+		}
+		releaseDate, text, link := s.fetchExternalData(ctx, song.Group, song.Song)
+		if releaseDate == "" && text == "" && link == "" {
+			failed = append(failed, song.ID)
+			continue
+		}
+		if err := s.repo.RefreshSongData(ctx, song.ID, releaseDate, text, link); err != nil {
+			s.logger.Error("Failed to store re-enriched song", zap.Int("id", song.ID), zap.Error(err))
+			failed = append(failed, song.ID)
+			continue
+		}
+		if text != "" {
+			s.indexVerses(ctx, song.ID, text)
+			refreshed = append(refreshed, classificationTarget{ID: song.ID, Text: text})
+		}
+	}
+	s.classifySongs(refreshed...)
+
+	s.logger.Info("Stale songs re-enriched", zap.Int("refreshed", len(songs)-len(failed)), zap.Int("failed", len(failed)))
+	return len(songs), failed, nil
+}
+
+// StartReenrichmentScheduler re-enriches stale songs immediately and then every interval until ctx is cancelled.
+// Songs the API has no data for are skipped on later runs until the whole stale backlog has been attempted,
+// so they cannot starve the rest of the queue.
+func (s *MusicService) StartReenrichmentScheduler(ctx context.Context, interval time.Duration, cfg ReenrichmentConfig) {
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = DefaultReenrichmentConfig.StaleAfter
+	}
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = DefaultReenrichmentConfig.BatchSize
+	}
+	s.logger.Info("Starting re-enrichment scheduler", zap.Duration("interval", interval), zap.Duration("stale_after", cfg.StaleAfter))
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var skip []int
+		for {
+			attempted, failed, err := s.ReenrichStale(ctx, cfg, skip)
+			if err == nil {
+				skip = append(skip, failed...)
+				if attempted < cfg.BatchSize {
+					// The backlog is exhausted: give the skipped songs another chance next run
+					skip = nil
+				}
+			}
+			select {
+			case <-ctx.Done():
+				s.logger.Info("Re-enrichment scheduler stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// ReenrichSong fetches the song's data from the external API again and overwrites the selected fields,
+// all of EnrichableFields when none are selected. Fields the API returns empty keep their value;
+// ErrNoExternalData is returned when it returns none of the selected ones. It returns the updated song.
+func (s *MusicService) ReenrichSong(ctx context.Context, id int, fields []string) (_ models.Song, err error) {
+	defer metrics.ObserveOperation("reenrich_song", time.Now(), &err)
+	s.logger.Debug("Re-enriching song", zap.Int("id", id), zap.Strings("fields", fields))
+	if len(fields) == 0 {
+		fields = EnrichableFields
+	}
+	for _, field := range fields {
+		if !slices.Contains(EnrichableFields, field) {
+			s.logger.Warn("Unsupported re-enrichment field requested", zap.String("field", field))
+			return models.Song{}, fmt.Errorf("%w: %s", ErrUnsupportedField, field)
+		}
+	}
+	// Checked up front as well, so a held song costs no external API call
+	if err := s.checkLegalHold(ctx, "reenrich_song", id); err != nil {
+		return models.Song{}, err
+	}
+	song, err := s.repo.GetSongByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch song", zap.Int("id", id), zap.Error(err))
+		return models.Song{}, err
+	}
+
+	if err := s.refreshSong(ctx, song, fields); err != nil {
+		return models.Song{}, err
+	}
+	s.logger.Info("Song re-enriched successfully", zap.Int("id", id))
+	return s.repo.GetSongByID(ctx, id)
+}
+
+// refreshSong fetches the song's data from the external API again and stores the selected fields,
+// returning ErrNoExternalData when the API has none of them
+func (s *MusicService) refreshSong(ctx context.Context, song models.Song, fields []string) error {
+	releaseDate, text, link := s.fetchExternalData(ctx, song.Group, song.Song)
+	if normalized, ok := normalizeExternalReleaseDate(releaseDate); ok {
+		releaseDate = normalized
+	} else {
+		s.logger.Warn("External API returned an invalid release date", zap.String("release_date", releaseDate))
+		releaseDate = ""
+	}
+	if !slices.Contains(fields, "release_date") {
+		releaseDate = ""
+	}
+	if !slices.Contains(fields, "text") {
+		text = ""
+	}
+	if !slices.Contains(fields, "link") {
+		link = ""
+	}
+	if releaseDate == "" && text == "" && link == "" {
+		s.logger.Warn("External API provided no data for re-enrichment", zap.Int("id", song.ID))
+		return fmt.Errorf("%w: %s - %s", ErrNoExternalData, song.Group, song.Song)
+	}
+
+	err := s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkLegalHold(ctx, "reenrich_song", song.ID); err != nil {
+			return err
+		}
+		if err := s.repo.RefreshSongData(ctx, song.ID, releaseDate, text, link); err != nil {
+			return err
+		}
+		return s.record(ctx, analytics.EventSongUpdated, song.ID, 1)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrLegalHold) {
+			s.logger.Error("Failed to store re-enriched song", zap.Int("id", song.ID), zap.Error(err))
+		}
+		return err
+	}
+	s.publish(analytics.EventSongUpdated, song.ID, 1)
+	if text != "" {
+		s.indexVerses(ctx, song.ID, text)
+		s.classifySongs(classificationTarget{ID: song.ID, Text: text})
+	}
+	return nil
+}
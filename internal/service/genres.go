@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// ErrInvalidGenre is returned when creating or replacing a genre without a name, with an unknown parent or
+// with a parent that would make it its own ancestor, and when assigning unknown genres to a song
+var ErrInvalidGenre = errors.New("invalid genre")
+
+// ErrGenreExists is returned when naming a genre after another one
+var ErrGenreExists = errors.New("genre already exists")
+
+// ErrGenreHasSubgenres is returned when deleting a genre that still has subgenres
+var ErrGenreHasSubgenres = errors.New("genre has subgenres")
+
+// maxGenreNameLength is the maximum number of characters of a genre name
+const maxGenreNameLength = 100
+
+// MaxSongGenres bounds the number of genres assigned to a song
+const MaxSongGenres = 10
+
+// normalizeGenre checks the fields of a genre and returns them with the name trimmed
+func normalizeGenre(genre models.GenreInput) (models.GenreInput, error) {
+	genre.Name = strings.TrimSpace(genre.Name)
+	if genre.Name == "" {
+		return genre, fmt.Errorf("%w: name is required", ErrInvalidGenre)
+	}
+	if utf8.RuneCountInString(genre.Name) > maxGenreNameLength {
+		return genre, fmt.Errorf("%w: name must be at most %d characters", ErrInvalidGenre, maxGenreNameLength)
+	}
+	return genre, nil
+}
+
+// checkGenreParent checks that the parent of a genre exists and is not the genre or one of its subgenres,
+// which would make the genre its own ancestor. id is zero for a new genre.
+func (s *MusicService) checkGenreParent(ctx context.Context, id int, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+	if _, err := s.repo.GetGenre(ctx, *parentID); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: parent genre %d does not exist", ErrInvalidGenre, *parentID)
+		}
+		return err
+	}
+	if id == 0 {
+		return nil
+	}
+	subtree, err := s.repo.GetGenreSubtree(ctx, id)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(subtree, *parentID) {
+		return fmt.Errorf("%w: a genre cannot be a subgenre of itself or of its subgenres", ErrInvalidGenre)
+	}
+	return nil
+}
+
+// CreateGenre adds a genre and returns it as stored. ErrGenreExists is returned when the name is taken.
+func (s *MusicService) CreateGenre(ctx context.Context, genre models.GenreInput) (_ models.Genre, err error) {
+	defer metrics.ObserveOperation("create_genre", time.Now(), &err)
+	s.logger.Debug("Creating genre", zap.String("name", genre.Name))
+	genre, err = normalizeGenre(genre)
+	if err != nil {
+		s.logger.Warn("Invalid genre", zap.Error(err))
+		return models.Genre{}, err
+	}
+	if err := s.checkGenreParent(ctx, 0, genre.ParentID); err != nil {
+		return models.Genre{}, err
+	}
+	id, err := s.repo.CreateGenre(ctx, genre)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Genre{}, fmt.Errorf("%w: %s", ErrGenreExists, genre.Name)
+		}
+		s.logger.Error("Failed to create genre", zap.Error(err))
+		return models.Genre{}, err
+	}
+	s.logger.Info("Genre created successfully", zap.Int("id", id))
+	return s.repo.GetGenre(ctx, id)
+}
+
+// GetGenre returns a genre, or sql.ErrNoRows when it does not exist
+func (s *MusicService) GetGenre(ctx context.Context, id int) (models.Genre, error) {
+	s.logger.Debug("Fetching genre", zap.Int("id", id))
+	return s.repo.GetGenre(ctx, id)
+}
+
+// GetGenres returns the whole genre taxonomy in name order; the hierarchy follows from the parent IDs
+func (s *MusicService) GetGenres(ctx context.Context) (_ models.GenreList, err error) {
+	defer metrics.ObserveOperation("get_genres", time.Now(), &err)
+	s.logger.Debug("Fetching genres")
+	genres, err := s.repo.GetGenres(ctx)
+	if err != nil {
+		s.logger.Error("Failed to fetch genres", zap.Error(err))
+		return models.GenreList{}, err
+	}
+	return models.GenreList{Data: genres}, nil
+}
+
+// UpdateGenre replaces the name and parent of a genre and returns it as stored. sql.ErrNoRows is returned
+// when the genre does not exist and ErrGenreExists when another genre has the name.
+func (s *MusicService) UpdateGenre(ctx context.Context, id int, genre models.GenreInput) (_ models.Genre, err error) {
+	defer metrics.ObserveOperation("update_genre", time.Now(), &err)
+	s.logger.Debug("Updating genre", zap.Int("id", id))
+	genre, err = normalizeGenre(genre)
+	if err != nil {
+		s.logger.Warn("Invalid genre", zap.Int("id", id), zap.Error(err))
+		return models.Genre{}, err
+	}
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.GetGenre(ctx, id); err != nil {
+			return err
+		}
+		other, err := s.repo.GetGenreByName(ctx, genre.Name)
+		switch {
+		case err == nil && other.ID != id:
+			return fmt.Errorf("%w: %s", ErrGenreExists, genre.Name)
+		case err != nil && err != sql.ErrNoRows:
+			return err
+		}
+		if err := s.checkGenreParent(ctx, id, genre.ParentID); err != nil {
+			return err
+		}
+		return s.repo.UpdateGenre(ctx, id, genre)
+	})
+	if err != nil {
+		if err != sql.ErrNoRows && !errors.Is(err, ErrGenreExists) && !errors.Is(err, ErrInvalidGenre) {
+			s.logger.Error("Failed to update genre", zap.Int("id", id), zap.Error(err))
+		}
+		return models.Genre{}, err
+	}
+	s.logger.Info("Genre updated successfully", zap.Int("id", id))
+	return s.repo.GetGenre(ctx, id)
+}
+
+// DeleteGenre deletes a genre without subgenres, unassigning it from its songs. sql.ErrNoRows is returned
+// when it does not exist and ErrGenreHasSubgenres while other genres name it as their parent.
+func (s *MusicService) DeleteGenre(ctx context.Context, id int) (err error) {
+	defer metrics.ObserveOperation("delete_genre", time.Now(), &err)
+	s.logger.Debug("Deleting genre", zap.Int("id", id))
+	subgenres, err := s.repo.CountSubgenres(ctx, id)
+	if err != nil {
+		return err
+	}
+	if subgenres > 0 {
+		s.logger.Warn("Genre with subgenres not deleted", zap.Int("id", id), zap.Int("subgenres", subgenres))
+		return fmt.Errorf("%w: %d subgenres", ErrGenreHasSubgenres, subgenres)
+	}
+	if err := s.repo.DeleteGenre(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Info("Genre deleted successfully", zap.Int("id", id))
+	return nil
+}
+
+// GetSongGenres returns the genres assigned to a song. sql.ErrNoRows is returned when the song does not exist.
+func (s *MusicService) GetSongGenres(ctx context.Context, songID int) (models.SongGenres, error) {
+	s.logger.Debug("Fetching song genres", zap.Int("song_id", songID))
+	if _, err := s.repo.GetSongByID(ctx, songID); err != nil {
+		return models.SongGenres{}, err
+	}
+	genres, err := s.repo.GetSongGenres(ctx, songID)
+	if err != nil {
+		s.logger.Error("Failed to fetch song genres", zap.Int("song_id", songID), zap.Error(err))
+		return models.SongGenres{}, err
+	}
+	return models.SongGenres{SongID: songID, Genres: genres}, nil
+}
+
+// SetSongGenres replaces the genres assigned to a song and returns them; an empty list unassigns them all.
+// sql.ErrNoRows is returned when the song does not exist, ErrInvalidGenre when a genre does not and
+// ErrLegalHold when the song is on legal hold.
+func (s *MusicService) SetSongGenres(ctx context.Context, songID int, genreIDs []int) (_ models.SongGenres, err error) {
+	defer metrics.ObserveOperation("set_song_genres", time.Now(), &err)
+	s.logger.Debug("Assigning song genres", zap.Int("song_id", songID), zap.Ints("genre_ids", genreIDs))
+	ids := make([]int, 0, len(genreIDs))
+	for _, id := range genreIDs {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > MaxSongGenres {
+		return models.SongGenres{}, fmt.Errorf("%w: at most %d genres can be assigned to a song", ErrInvalidGenre, MaxSongGenres)
+	}
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.GetSongByID(ctx, songID); err != nil {
+			return err
+		}
+		if err := s.checkLegalHold(ctx, "set_song_genres", songID); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if _, err := s.repo.GetGenre(ctx, id); err != nil {
+				if err == sql.ErrNoRows {
+					return fmt.Errorf("%w: genre %d does not exist", ErrInvalidGenre, id)
+				}
+				return err
+			}
+		}
+		return s.repo.SetSongGenres(ctx, songID, ids)
+	})
+	if err != nil {
+		if err != sql.ErrNoRows && !errors.Is(err, ErrInvalidGenre) && !errors.Is(err, ErrLegalHold) {
+			s.logger.Error("Failed to assign song genres", zap.Int("song_id", songID), zap.Error(err))
+		}
+		return models.SongGenres{}, err
+	}
+	s.logger.Info("Song genres assigned successfully", zap.Int("song_id", songID), zap.Int("count", len(ids)))
+	return s.GetSongGenres(ctx, songID)
+}
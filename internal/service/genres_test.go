@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// genreRepository serves a genre taxonomy by parent ID
+type genreRepository struct {
+	repository.Repository
+	parents map[int]int
+	updated bool
+}
+
+func (r *genreRepository) ConfigureStatementTimeout(time.Duration) {}
+
+func (r *genreRepository) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *genreRepository) GetGenre(_ context.Context, id int) (models.Genre, error) {
+	if _, ok := r.parents[id]; !ok {
+		return models.Genre{}, sql.ErrNoRows
+	}
+	return models.Genre{ID: id}, nil
+}
+
+func (r *genreRepository) GetGenreByName(context.Context, string) (models.Genre, error) {
+	return models.Genre{}, sql.ErrNoRows
+}
+
+func (r *genreRepository) GetGenreSubtree(_ context.Context, id int) ([]int, error) {
+	subtree := []int{id}
+	for i := 0; i < len(subtree); i++ {
+		for child, parent := range r.parents {
+			if parent == subtree[i] {
+				subtree = append(subtree, child)
+			}
+		}
+	}
+	return subtree, nil
+}
+
+func (r *genreRepository) UpdateGenre(context.Context, int, models.GenreInput) error {
+	r.updated = true
+	return nil
+}
+
+func TestNormalizeGenre(t *testing.T) {
+	genre, err := normalizeGenre(models.GenreInput{Name: " Alternative Rock "})
+	require.NoError(t, err)
+	assert.Equal(t, "Alternative Rock", genre.Name)
+
+	_, err = normalizeGenre(models.GenreInput{Name: " "})
+	assert.ErrorIs(t, err, ErrInvalidGenre)
+	_, err = normalizeGenre(models.GenreInput{Name: strings.Repeat("a", maxGenreNameLength+1)})
+	assert.ErrorIs(t, err, ErrInvalidGenre)
+}
+
+func TestUpdateGenreParent(t *testing.T) {
+	// Rock (1) > Alternative Rock (2) > Grunge (3), and Pop (4)
+	repo := &genreRepository{parents: map[int]int{1: 0, 2: 1, 3: 2, 4: 0}}
+	svc := NewMusicService(repo, zap.NewNop(), nil)
+	parent := func(id int) *int { return &id }
+
+	for name, parentID := range map[string]int{"itself": 1, "child": 2, "grandchild": 3, "unknown": 9} {
+		_, err := svc.UpdateGenre(context.Background(), 1, models.GenreInput{Name: "Rock", ParentID: parent(parentID)})
+		assert.ErrorIs(t, err, ErrInvalidGenre, name)
+	}
+	assert.False(t, repo.updated)
+
+	_, err := svc.UpdateGenre(context.Background(), 3, models.GenreInput{Name: "Grunge", ParentID: parent(4)})
+	require.NoError(t, err)
+	assert.True(t, repo.updated)
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrInvalidImport is returned when an uploaded file or its mapping cannot be used for an import
+var ErrInvalidImport = errors.New("invalid import")
+
+// canonicalDateLayout is the layout release dates are stored in
+const canonicalDateLayout = "02.01.2006"
+
+// importFields lists the song fields a source column can be mapped to
+var importFields = map[string]bool{
+	"group":        true,
+	"song":         true,
+	"release_date": true,
+	"text":         true,
+	"link":         true,
+}
+
+// ImportMapping describes how the columns of an arbitrary CSV layout map onto song fields
+type ImportMapping struct {
+	// Columns maps source headers to song fields (group, song, release_date, text, link)
+	Columns map[string]string `json:"columns"`
+	// DateFormat is the release date format of the source, e.g. "YYYY-MM-DD"; defaults to "DD.MM.YYYY"
+	DateFormat string `json:"date_format"`
+	// Trim strips surrounding whitespace from every value; defaults to true
+	Trim *bool `json:"trim"`
+	// SplitArtist splits a combined "Artist - Title" column into group and song
+	SplitArtist *SplitArtistRule `json:"split_artist"`
+}
+
+// SplitArtistRule describes a source column holding both the artist and the title
+type SplitArtistRule struct {
+	Column    string `json:"column"`
+	Separator string `json:"separator"`
+}
+
+// ImportRow is a single song parsed from an import file
+type ImportRow struct {
+	Row         int    `json:"row" example:"2"`
+	Group       string `json:"group" example:"Muse"`
+	Song        string `json:"song" example:"Supermassive Black Hole"`
+	ReleaseDate string `json:"release_date" example:"16.07.2006"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+	// EnrichedAt is set when the external API provided the missing fields
+	EnrichedAt *time.Time `json:"-"`
+}
+
+// ImportItemResult describes a row that could not be imported
+type ImportItemResult struct {
+	Row   int    `json:"row" example:"3"`
+	Error string `json:"error" example:"group and song are required"`
+}
+
+// ImportResult summarizes an import. Counters are cumulative over every run of a resumed import,
+// while failures are only collected for the current run and capped to keep memory bounded.
+type ImportResult struct {
+	ImportID          string             `json:"import_id" example:"5f2b8c0e9a1d4c3b8e7f6a5b4c3d2e1f"`
+	Status            string             `json:"status" example:"completed"`
+	ResumedFromRow    int                `json:"resumed_from_row,omitempty"`
+	Created           int                `json:"created" example:"1"`
+	Updated           int                `json:"updated"`
+	Failed            int                `json:"failed" example:"1"`
+	Failures          []ImportItemResult `json:"failures"`
+	FailuresTruncated bool               `json:"failures_truncated,omitempty"`
+}
+
+// Import actions describe what happens to a row
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionSkip   = "skip"
+)
+
+// ImportPreviewItem describes what importing a single row would do
+type ImportPreviewItem struct {
+	Row      int        `json:"row" example:"2"`
+	Action   string     `json:"action" example:"create"`
+	ID       int        `json:"id,omitempty"`
+	Song     *ImportRow `json:"song,omitempty"`
+	Warnings []string   `json:"warnings,omitempty" example:"link is missing and will be fetched from the external API"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// ImportPreview summarizes what an import would do without writing anything
+type ImportPreview struct {
+	Create int                 `json:"create" example:"1"`
+	Update int                 `json:"update"`
+	Skip   int                 `json:"skip" example:"1"`
+	Items  []ImportPreviewItem `json:"items"`
+}
+
+// ParseImportMapping decodes a JSON mapping spec, returning the default mapping for an empty spec
+func ParseImportMapping(spec string) (ImportMapping, error) {
+	var mapping ImportMapping
+	if strings.TrimSpace(spec) == "" {
+		return mapping, nil
+	}
+	if err := json.Unmarshal([]byte(spec), &mapping); err != nil {
+		return mapping, fmt.Errorf("%w: mapping is not valid JSON: %v", ErrInvalidImport, err)
+	}
+	for source, field := range mapping.Columns {
+		if !importFields[field] {
+			return mapping, fmt.Errorf("%w: column %q maps to unknown field %q", ErrInvalidImport, source, field)
+		}
+	}
+	if mapping.SplitArtist != nil && mapping.SplitArtist.Column == "" {
+		return mapping, fmt.Errorf("%w: split_artist requires a column", ErrInvalidImport)
+	}
+	return mapping, nil
+}
+
+// dateLayout converts a DD/MM/YYYY style date format into a Go time layout
+func (m ImportMapping) dateLayout() string {
+	if m.DateFormat == "" {
+		return canonicalDateLayout
+	}
+	return strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02").Replace(m.DateFormat)
+}
+
+// trim reports whether values should have surrounding whitespace removed
+func (m ImportMapping) trim() bool {
+	return m.Trim == nil || *m.Trim
+}
+
+// fieldColumns resolves the index of the source column for every mapped song field
+func (m ImportMapping) fieldColumns(header []string) (map[string]int, error) {
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if len(m.Columns) == 0 {
+			if field := strings.ToLower(name); importFields[field] {
+				columns[field] = i
+			}
+			continue
+		}
+		if field, ok := m.Columns[name]; ok {
+			columns[field] = i
+		}
+	}
+	if m.SplitArtist != nil {
+		found := false
+		for i, name := range header {
+			if strings.TrimSpace(name) == m.SplitArtist.Column {
+				columns["split_artist"] = i
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: split_artist column %q not found", ErrInvalidImport, m.SplitArtist.Column)
+		}
+	}
+	return columns, nil
+}
+
+// ParseImportCSV parses up to maxRows rows of a CSV file (all rows when maxRows is 0) using the mapping.
+// Rows that cannot be converted are returned as failed results instead of aborting the whole file.
+func ParseImportCSV(r io.Reader, mapping ImportMapping, maxRows int) ([]ImportRow, []ImportItemResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: cannot read header: %v", ErrInvalidImport, err)
+	}
+	columns, err := mapping.fieldColumns(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []ImportRow
+	var failures []ImportItemResult
+	for line := 2; maxRows == 0 || len(rows)+len(failures) < maxRows; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			failures = append(failures, ImportItemResult{Row: line, Error: err.Error()})
+			continue
+		}
+		row, err := mapping.convert(record, columns)
+		if err != nil {
+			failures = append(failures, ImportItemResult{Row: line, Error: err.Error()})
+			continue
+		}
+		row.Row = line
+		rows = append(rows, row)
+	}
+	return rows, failures, nil
+}
+
+// convert applies the mapping transformations to a single CSV record
+func (m ImportMapping) convert(record []string, columns map[string]int) (ImportRow, error) {
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		if m.trim() {
+			return strings.TrimSpace(record[i])
+		}
+		return record[i]
+	}
+
+	row := ImportRow{
+		Group: value("group"),
+		Song:  value("song"),
+		Text:  value("text"),
+		Link:  value("link"),
+	}
+	if m.SplitArtist != nil {
+		separator := m.SplitArtist.Separator
+		if separator == "" {
+			separator = " - "
+		}
+		if artist, title, ok := strings.Cut(value("split_artist"), separator); ok {
+			if row.Group == "" {
+				row.Group = strings.TrimSpace(artist)
+			}
+			if row.Song == "" {
+				row.Song = strings.TrimSpace(title)
+			}
+		}
+	}
+	if row.Group == "" || row.Song == "" {
+		return row, errors.New("group and song are required")
+	}
+	if date := value("release_date"); date != "" {
+		released, err := time.Parse(m.dateLayout(), date)
+		if err != nil {
+			return row, fmt.Errorf("invalid release date %q", date)
+		}
+		row.ReleaseDate = released.Format(canonicalDateLayout)
+	}
+	return row, nil
+}
+
+// PreviewImport parses the first maxRows rows of a CSV file and reports what importing them would do.
+// Nothing is written to the database.
+func (s *MusicService) PreviewImport(ctx context.Context, r io.Reader, mapping ImportMapping, maxRows int) (*ImportPreview, error) {
+	s.logger.Info("Previewing CSV import", zap.Int("max_rows", maxRows))
+	rows, failures, err := ParseImportCSV(r, mapping, maxRows)
+	if err != nil {
+		s.logger.Warn("Failed to parse import file", zap.Error(err))
+		return nil, err
+	}
+
+	preview := &ImportPreview{}
+	for _, failure := range failures {
+		preview.Items = append(preview.Items, ImportPreviewItem{Row: failure.Row, Action: ImportActionSkip, Error: failure.Error})
+		preview.Skip++
+	}
+
+	seen := make(map[string]int)
+	for _, row := range rows {
+		row := row
+		item := ImportPreviewItem{Row: row.Row, Song: &row}
+		key := strings.ToLower(row.Group) + "\x00" + strings.ToLower(row.Song)
+		if first, ok := seen[key]; ok {
+			item.Warnings = append(item.Warnings, fmt.Sprintf("duplicate of row %d", first))
+		} else {
+			seen[key] = row.Row
+		}
+
+		id, err := s.repo.FindSongID(ctx, row.Group, row.Song)
+		switch {
+		case err == sql.ErrNoRows:
+			item.Action = ImportActionCreate
+			preview.Create++
+			for field, value := range map[string]string{"release_date": row.ReleaseDate, "text": row.Text, "link": row.Link} {
+				if value == "" {
+					item.Warnings = append(item.Warnings, field+" is missing and will be fetched from the external API")
+				}
+			}
+		case err != nil:
+			s.logger.Error("Failed to look up song during preview", zap.Int("row", row.Row), zap.Error(err))
+			return nil, err
+		default:
+			item.ID = id
+			held, err := s.repo.GetLegalHolds(ctx, []int{id})
+			if err != nil {
+				s.logger.Error("Failed to check legal hold during preview", zap.Int("row", row.Row), zap.Error(err))
+				return nil, err
+			}
+			if len(held) > 0 {
+				item.Action = ImportActionSkip
+				item.Error = fmt.Sprintf("%v: song %d", ErrLegalHold, id)
+				preview.Skip++
+				break
+			}
+			item.Action = ImportActionUpdate
+			preview.Update++
+		}
+		sort.Strings(item.Warnings)
+		preview.Items = append(preview.Items, item)
+	}
+	sort.Slice(preview.Items, func(i, j int) bool { return preview.Items[i].Row < preview.Items[j].Row })
+
+	s.logger.Info("Import preview built", zap.Int("create", preview.Create), zap.Int("update", preview.Update), zap.Int("skip", preview.Skip))
+	return preview, nil
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
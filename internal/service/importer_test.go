@@ -0,0 +1,39 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImportCSVDefaultMapping(t *testing.T) {
+	file := "Group,Song,Release_Date\nMuse,Uprising,07.09.2009\n,Missing group,\n"
+
+	rows, failures, err := ParseImportCSV(strings.NewReader(file), ImportMapping{}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []ImportRow{{Row: 2, Group: "Muse", Song: "Uprising", ReleaseDate: "07.09.2009"}}, rows)
+	assert.Len(t, failures, 1)
+	assert.Equal(t, 3, failures[0].Row)
+}
+
+func TestParseImportCSVCustomMapping(t *testing.T) {
+	mapping, err := ParseImportMapping(`{
+		"columns": {"Released": "release_date", "URL": "link"},
+		"date_format": "YYYY-MM-DD",
+		"split_artist": {"column": "Track"}
+	}`)
+	assert.NoError(t, err)
+
+	file := "Track,Released,URL\n  Muse - Starlight ,2006-09-04, https://example.com \nNo separator,2006-09-04,\nMuse - Knights,04.09.2006,\n"
+	rows, failures, err := ParseImportCSV(strings.NewReader(file), mapping, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []ImportRow{{Row: 2, Group: "Muse", Song: "Starlight", ReleaseDate: "04.09.2006", Link: "https://example.com"}}, rows)
+	assert.Len(t, failures, 2)
+	assert.Contains(t, failures[1].Error, "invalid release date")
+}
+
+func TestParseImportMappingRejectsUnknownField(t *testing.T) {
+	_, err := ParseImportMapping(`{"columns": {"Genre": "genre"}}`)
+	assert.ErrorIs(t, err, ErrInvalidImport)
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"music-library/internal/jobs"
+	"music-library/internal/models"
+)
+
+// ErrJobsUnavailable is returned when a background job is requested before a job manager is configured
+var ErrJobsUnavailable = errors.New("background jobs are not available")
+
+// ConfigureJobs sets the manager running long operations, such as imports and batch re-enrichment, in the background
+func (s *MusicService) ConfigureJobs(manager *jobs.Manager) {
+	s.jobs = manager
+}
+
+// GetJob returns a background job with its progress and, once finished, its result
+func (s *MusicService) GetJob(ctx context.Context, id string) (models.Job, error) {
+	if s.jobs == nil {
+		return models.Job{}, ErrJobsUnavailable
+	}
+	return s.jobs.Get(ctx, id)
+}
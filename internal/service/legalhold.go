@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/analytics"
+	"music-library/internal/metrics"
+)
+
+// ErrLegalHold is returned when modifying or deleting a song on legal hold
+var ErrLegalHold = errors.New("song is on legal hold")
+
+// SetLegalHold sets or releases the legal hold of a song. While it is set, updates, deletions, merges and
+// truncation affecting the song are refused. sql.ErrNoRows is returned when the song does not exist.
+func (s *MusicService) SetLegalHold(ctx context.Context, id int, held bool) (err error) {
+	defer metrics.ObserveOperation("set_legal_hold", time.Now(), &err)
+	s.logger.Debug("Setting legal hold", zap.Int("id", id), zap.Bool("held", held))
+	if err := s.repo.SetLegalHold(ctx, id, held); err != nil {
+		s.logger.Error("Failed to set legal hold", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	if held {
+		s.publish(analytics.EventLegalHoldSet, id, 1)
+	} else {
+		s.publish(analytics.EventLegalHoldReleased, id, 1)
+	}
+	s.logger.Info("Legal hold set successfully", zap.Int("id", id), zap.Bool("held", held))
+	return nil
+}
+
+// checkLegalHold returns ErrLegalHold when one of the songs is on legal hold, recording the blocked
+// operation in the audit trail. It must run in the transaction of the write it guards: the repository then
+// locks the songs until it ends, so a hold set meanwhile cannot slip in between the check and the write.
+func (s *MusicService) checkLegalHold(ctx context.Context, operation string, ids ...int) error {
+	held, err := s.repo.GetLegalHolds(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if len(held) == 0 {
+		return nil
+	}
+	for _, id := range held {
+		s.audit(analytics.EventLegalHoldBlocked, id, 1)
+	}
+	s.logger.Warn("Operation blocked by legal hold", zap.String("operation", operation), zap.Ints("song_ids", held))
+	return fmt.Errorf("%w: song %d", ErrLegalHold, held[0])
+}
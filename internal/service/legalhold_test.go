@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository/mocks"
+)
+
+// TestLegalHoldSetBeforeWrite sets the hold of song 1 after each operation was requested but before its
+// transaction runs, as a concurrent PUT /admin/songs/1/legal-hold would. The hold must be seen by the
+// check in the transaction, so nothing is written.
+func TestLegalHoldSetBeforeWrite(t *testing.T) {
+	operations := map[string]func(svc *MusicService) error{
+		"update": func(svc *MusicService) error {
+			return svc.UpdateSong(context.Background(), 1, "Muse", "Uprising", "07.09.2009", "text", "https://example.com")
+		},
+		"patch": func(svc *MusicService) error {
+			return svc.UpdateSongPartial(context.Background(), 1, models.SongPatch{Text: models.OptionalString{Set: true, Value: "text"}})
+		},
+		"delete": func(svc *MusicService) error {
+			return svc.DeleteSong(context.Background(), 1)
+		},
+		"truncate": func(svc *MusicService) error {
+			_, err := svc.TruncateSongs(context.Background(), false)
+			return err
+		},
+	}
+	for name, operation := range operations {
+		t.Run(name, func(t *testing.T) {
+			held, inTransaction := false, false
+			// No write is expected: the strict mock fails the test on any unexpected call
+			repo := mocks.NewMockRepository(gomock.NewController(t))
+			repo.EXPECT().ConfigureStatementTimeout(gomock.Any()).AnyTimes()
+			repo.EXPECT().RunInTransaction(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					held, inTransaction = true, true
+					defer func() { inTransaction = false }()
+					return fn(ctx)
+				})
+			repo.EXPECT().GetLegalHolds(gomock.Any(), []int{1}).AnyTimes().
+				DoAndReturn(func(context.Context, []int) ([]int, error) {
+					assert.True(t, inTransaction, "the hold is checked in the transaction of the write")
+					if held {
+						return []int{1}, nil
+					}
+					return []int{}, nil
+				})
+			repo.EXPECT().CountLegalHolds(gomock.Any()).AnyTimes().
+				DoAndReturn(func(context.Context) (int, error) {
+					assert.True(t, inTransaction, "the holds are counted in the transaction of the truncation")
+					if held {
+						return 1, nil
+					}
+					return 0, nil
+				})
+			svc := NewMusicService(repo, zap.NewNop(), nil)
+
+			assert.ErrorIs(t, operation(svc), ErrLegalHold)
+		})
+	}
+}
+
+// TestLegalHoldBlocksLabelling checks that tags, genres and accepted suggestions are refused for a held song.
+// Tags are checked after BulkTagSongs matched the songs, as a filter selects them, and rolled back.
+func TestLegalHoldBlocksLabelling(t *testing.T) {
+	svc, repo, _ := newMockedService(t)
+	repo.EXPECT().GetLegalHolds(gomock.Any(), []int{7}).AnyTimes().Return([]int{7}, nil)
+
+	repo.EXPECT().BulkTagSongs(gomock.Any(), []int{7}, models.SongFilter{}, []string{"live"}, nil).
+		Return(models.BulkTagResult{Matched: 1, Added: 1, SongIDs: []int{7}}, nil)
+	_, err := svc.AddSongTags(context.Background(), 7, []string{"Live"})
+	assert.ErrorIs(t, err, ErrLegalHold)
+
+	repo.EXPECT().BulkTagSongs(gomock.Any(), []int(nil), models.SongFilter{Group: "Muse"}, []string{"live"}, nil).
+		Return(models.BulkTagResult{Matched: 1, Added: 1, SongIDs: []int{7}}, nil)
+	_, err = svc.BulkTagSongs(context.Background(), BulkTagRequest{Filter: &models.SongFilter{Group: "Muse"}, Add: []string{"live"}})
+	assert.ErrorIs(t, err, ErrLegalHold)
+
+	repo.EXPECT().GetSongByID(gomock.Any(), 7).Return(models.Song{ID: 7}, nil)
+	_, err = svc.SetSongGenres(context.Background(), 7, []int{2})
+	assert.ErrorIs(t, err, ErrLegalHold)
+
+	repo.EXPECT().ReviewClassificationSuggestion(gomock.Any(), 3, models.SuggestionAccepted).Return(nil)
+	repo.EXPECT().GetClassificationSuggestion(gomock.Any(), 3).
+		Return(models.ClassificationSuggestion{ID: 3, SongID: 7, Kind: models.ClassificationMood, Value: "calm"}, nil)
+	assert.ErrorIs(t, svc.ReviewClassificationSuggestion(context.Background(), 3, true), ErrLegalHold)
+}
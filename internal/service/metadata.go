@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/spotify"
+)
+
+// MetadataSyncBatchSize is the number of songs looked up on Spotify per sync run
+const MetadataSyncBatchSize = 100
+
+// ConfigureSpotify sets the client looking up the album, duration, ISRC and artwork of songs
+func (s *MusicService) ConfigureSpotify(client *spotify.Client) {
+	s.spotify = client
+}
+
+// SyncTrackMetadata looks up the songs never synced on Spotify and stores the metadata of their tracks.
+// Songs Spotify has no track for are marked synced as well; songs in skip are not attempted. It returns
+// the number of songs attempted and the IDs of those whose lookup failed.
+func (s *MusicService) SyncTrackMetadata(ctx context.Context, skip []int) (int, []int, error) {
+	s.logger.Debug("Syncing track metadata")
+	songs, err := s.repo.GetSongsNeedingMetadata(ctx, skip, MetadataSyncBatchSize)
+	if err != nil {
+		s.logger.Error("Failed to fetch songs needing track metadata", zap.Error(err))
+		return 0, nil, err
+	}
+
+	var failed []int
+	for _, song := range songs {
+		if err := s.enrichLimiter.Wait(ctx); err != nil {
+			return len(songs), failed, err
+		}
+		metadata, err := s.lookupTrack(ctx, song)
+		if err != nil {
+			failed = append(failed, song.ID)
+			continue
+		}
+		if err := s.repo.SaveSongMetadata(ctx, song.ID, metadata); err != nil {
+			failed = append(failed, song.ID)
+		}
+	}
+
+	s.logger.Info("Track metadata synced", zap.Int("synced", len(songs)-len(failed)), zap.Int("failed", len(failed)))
+	return len(songs), failed, nil
+}
+
+// lookupTrack searches Spotify for the song's track, returning empty metadata when there is none
+func (s *MusicService) lookupTrack(ctx context.Context, song models.Song) (models.TrackMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeouts.ExternalAPI)
+	defer cancel()
+	track, err := s.spotify.SearchTrack(ctx, song.Group, song.Song)
+	if err != nil {
+		if errors.Is(err, spotify.ErrNotFound) {
+			s.logger.Debug("No Spotify track for song", zap.Int("id", song.ID))
+			return models.TrackMetadata{}, nil
+		}
+		s.logger.Warn("Failed to look up Spotify track", zap.Int("id", song.ID), zap.Error(err))
+		return models.TrackMetadata{}, err
+	}
+	metadata := models.TrackMetadata{
+		Album:      models.NullableString(track.Album),
+		ISRC:       models.NullableString(track.ISRC),
+		ArtworkURL: models.NullableString(track.ArtworkURL),
+	}
+	if track.DurationMs > 0 {
+		metadata.DurationMs = &track.DurationMs
+	}
+	return metadata, nil
+}
+
+// StartMetadataSync syncs the track metadata of new songs immediately and then every interval until ctx
+// is cancelled. It does nothing unless Spotify is configured.
+func (s *MusicService) StartMetadataSync(ctx context.Context, interval time.Duration) {
+	if s.spotify == nil {
+		return
+	}
+	s.logger.Info("Starting track metadata sync", zap.Duration("interval", interval))
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var skip []int
+		for {
+			attempted, failed, err := s.SyncTrackMetadata(ctx, skip)
+			if err == nil {
+				skip = append(skip, failed...)
+				if attempted < MetadataSyncBatchSize {
+					// Every song has been attempted: retry the failed ones next run
+					skip = nil
+				}
+			}
+			select {
+			case <-ctx.Done():
+				s.logger.Info("Track metadata sync stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
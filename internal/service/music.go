@@ -1,174 +1,603 @@
 package service
 
 import (
-	"encoding/json"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
-	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	_ "github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"music-library/internal/acoustid"
+	"music-library/internal/analytics"
+	"music-library/internal/auth"
+	"music-library/internal/breaker"
+	"music-library/internal/budget"
+	"music-library/internal/changes"
+	"music-library/internal/classifier"
+	"music-library/internal/embeddings"
+	"music-library/internal/jobs"
+	"music-library/internal/metrics"
 	"music-library/internal/models"
+	"music-library/internal/outbox"
 	"music-library/internal/repository"
+	"music-library/internal/spotify"
+	"music-library/internal/tracing"
 )
 
+// tracer starts the spans of the enrichment lookups
+var tracer = otel.Tracer("music-library/internal/service")
+
+// attributeFound reports on an enrichment lookup span whether the provider found details
+const attributeFound = attribute.Key("enrichment.found")
+
+// ErrUnsupportedField is returned when songs are filtered by a field that cannot be missing
+var ErrUnsupportedField = errors.New("unsupported field")
+
+// ErrUnsupportedFacet is returned when a client requests a facet the library cannot compute
+var ErrUnsupportedFacet = errors.New("unsupported facet")
+
+// ErrUnsupportedSort is returned when a client requests an unknown sort order
+var ErrUnsupportedSort = errors.New("unsupported sort")
+
+// ErrInvalidPatch is returned when a partial update sets a required field to null or empty
+var ErrInvalidPatch = errors.New("invalid patch")
+
 // Verse represents a single verse of a song
 type Verse struct {
-	Number int    `json:"number"`
-	Text   string `json:"text"`
+	Number int `json:"number" xml:"number,attr" example:"1"`
+	// Label is the section marker the verse starts with, such as "Chorus", when split at markers
+	Label string `json:"label,omitempty" xml:"label,attr,omitempty"`
+	Text  string `json:"text" xml:",chardata" example:"Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?"`
+}
+
+// VersePage is one page of a song's verses together with the song metadata lyric viewers display
+type VersePage struct {
+	XMLName     xml.Name `json:"-" xml:"song_verses"`
+	SongID      int      `json:"song_id" xml:"song_id" example:"1"`
+	Group       string   `json:"group" xml:"group" example:"Muse"`
+	Song        string   `json:"song" xml:"song" example:"Supermassive Black Hole"`
+	TotalVerses int      `json:"total_verses" xml:"total_verses" example:"1"`
+	TotalPages  int      `json:"total_pages" xml:"total_pages" example:"1"`
+	Page        int      `json:"page" xml:"page" example:"1"`
+	Limit       int      `json:"limit" xml:"limit" example:"10"`
+	Verses      []Verse  `json:"verses" xml:"verses>verse"`
 }
 
 // MusicService handles the business logic for music operations
 type MusicService struct {
-	repo       *repository.PostgresRepository
+	repo       repository.Repository
 	logger     *zap.Logger
 	httpClient *http.Client
+
+	digestMu     sync.RWMutex
+	latestDigest *models.Digest
+
+	viewsMu      sync.Mutex
+	pendingViews map[int]int64
+
+	background sync.WaitGroup
+
+	// enrichQueue is set once the enrichment workers are started, making AddSong asynchronous
+	enrichQueue  chan enrichmentJob
+	enrichMu     sync.Mutex
+	enrichQueued map[int]bool
+
+	enrichment    EnrichmentConfig
+	timeouts      TimeoutConfig
+	enrichLimiter *rate.Limiter
+	importCfg     ImportConfig
+	provider      ProviderConfig
+	budget        *budget.Manager
+	breaker       *breaker.Breaker
+	retry         RetryConfig
+	enricher      EnrichmentProvider
+	analytics     *analytics.Batcher
+	embedder      embeddings.Embedder
+	classifier    classifier.Classifier
+	spotify       *spotify.Client
+	acoustid      *acoustid.Client
+	fpcalc        *acoustid.Fpcalc
+	tokens        *auth.Tokens
+	changes       *changes.Hub
+	popularity    PopularityConfig
+
+	// fallback, externalAPIURL and listenerCacheTTL are reloaded while requests read them, so they are
+	// swapped atomically
+	fallback         atomic.Pointer[FallbackConfig]
+	externalAPIURL   atomic.Pointer[string]
+	listenerCacheTTL atomic.Int64
+
+	verseDelimiter string
+	trashRetention time.Duration
+
+	similarityMu sync.Mutex
+	similarity   *models.SimilarityReport
+
+	jobs *jobs.Manager
+
+	stats statsCache
+
+	// degraded is set when reads are to be served from the warm cache while the database is unreachable
+	degraded *degradedMode
+
+	// relay publishes the catalog changes recorded in the outbox once it is configured; without it they
+	// are published to the change hub directly
+	relay *outbox.Relay
 }
 
 // NewMusicService creates a new instance of MusicService
-func NewMusicService(repo *repository.PostgresRepository, logger *zap.Logger, httpClient *http.Client) *MusicService {
-	return &MusicService{
-		repo:       repo,
-		logger:     logger,
-		httpClient: httpClient,
+func NewMusicService(repo repository.Repository, logger *zap.Logger, httpClient *http.Client) *MusicService {
+	s := &MusicService{
+		repo:         repo,
+		logger:       logger,
+		httpClient:   httpClient,
+		pendingViews: make(map[int]int64),
+		popularity:   DefaultPopularityConfig,
+		changes:      changes.NewHub(changes.DefaultBufferSize),
 	}
+	s.ConfigureEnrichment(DefaultEnrichmentConfig)
+	s.ConfigureTimeouts(DefaultTimeoutConfig)
+	s.ConfigureImport(DefaultImportConfig)
+	s.ConfigureVerseDelimiter(DefaultVerseDelimiter)
+	s.ConfigureTrashRetention(DefaultTrashRetention)
+	s.ConfigureBudget(budget.NewManager(nil, logger, nil))
+	s.ConfigureResilience(DefaultRetryConfig, breaker.New(ExternalAPIProvider, breaker.DefaultConfig, logger))
+	s.ConfigureFallback(DefaultFallbackConfig)
+	s.ConfigureStatsCache(DefaultStatsCacheTTL)
+	s.ConfigurePopularityCacheTTL(DefaultPopularityConfig.CacheTTL)
+	s.ConfigureEnrichmentProvider(s.defaultEnrichmentProvider())
+	return s
 }
 
-// AddSong adds a new song to the database, fetching additional data from an external API if available
-func (s *MusicService) AddSong(group, song string) (int, error) {
+// AddSong adds a new song to the database, fetching additional data from an external API if available,
+// and returns its ID and enrichment status. Once the enrichment workers are started the song is inserted
+// pending and its data fetched in the background.
+func (s *MusicService) AddSong(ctx context.Context, group, song string) (_ int, _ string, err error) {
+	defer metrics.ObserveOperation("add_song", time.Now(), &err)
 	s.logger.Info("Adding song", zap.String("group", group), zap.String("song", song))
 
-	releaseDate, text, link := s.fetchExternalData(group, song)
-	if releaseDate == "" || text == "" || link == "" {
-		s.logger.Warn("External API unavailable, using mock data", zap.Error(nil))
-		releaseDate = "01.01.2000"
-		text = "Verse 1\n\nVerse 2\n\nVerse 3"
-		link = "https://example.com"
+	if s.enrichQueue != nil {
+		var id int
+		err := s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			if id, err = s.repo.AddPendingSong(ctx, group, song); err != nil {
+				return err
+			}
+			return s.record(ctx, analytics.EventSongAdded, id, 1)
+		})
+		if err != nil {
+			s.logger.Error("Failed to add song to database", zap.Error(err))
+			return 0, "", err
+		}
+		s.publish(analytics.EventSongAdded, id, 1)
+		if !s.enqueueEnrichment(enrichmentJob{ID: id, Group: group, Song: song}) {
+			s.logger.Warn("Enrichment queue full, song left for the next sweep", zap.Int("id", id))
+		}
+		return id, models.EnrichmentPending, nil
+	}
+
+	releaseDate, text, link, enriched, err := s.completeSongData(ctx, group, song, "", "", "")
+	if err != nil {
+		s.logger.Warn("Song not added", zap.Error(err))
+		return 0, "", err
 	}
 
-	id, err := s.repo.AddSong(group, song, releaseDate, text, link)
+	var id int
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if id, err = s.repo.AddSong(ctx, group, song, releaseDate, text, link, enrichedAt(enriched)); err != nil {
+			return err
+		}
+		return s.record(ctx, analytics.EventSongAdded, id, 1)
+	})
 	if err != nil {
 		s.logger.Error("Failed to add song to database", zap.Error(err))
-		return 0, err
+		return 0, "", err
 	}
+	s.publish(analytics.EventSongAdded, id, 1)
+	s.indexVerses(ctx, id, text)
+	s.classifySongs(classificationTarget{ID: id, Text: text})
 
-	return id, nil
+	return id, models.EnrichmentComplete, nil
 }
 
-// fetchExternalData fetches song details from an external API
-func (s *MusicService) fetchExternalData(group, song string) (releaseDate, text, link string) {
-	apiURL := os.Getenv("EXTERNAL_API_URL")
-	if apiURL == "" {
-		s.logger.Error("EXTERNAL_API_URL environment variable not set")
-		return "", "", ""
+// completeSongData fills the missing release date, text and link of a song from the external API.
+// Fields the API cannot provide get the configured fallback data, or ErrNoExternalData is returned
+// when fallback is disabled. It reports whether the API provided all the data.
+func (s *MusicService) completeSongData(ctx context.Context, group, song, releaseDate, text, link string) (string, string, string, bool, error) {
+	if releaseDate != "" && text != "" && link != "" {
+		return releaseDate, text, link, false, nil
 	}
 
-	s.logger.Debug("Using EXTERNAL_API_URL", zap.String("api_url", apiURL))
-	url := fmt.Sprintf("%s/info?group=%s&song=%s", apiURL, url.QueryEscape(group), url.QueryEscape(song))
-	s.logger.Debug("Fetching data from external API", zap.String("url", url))
+	extReleaseDate, extText, extLink := s.fetchExternalData(ctx, group, song)
+	if normalized, ok := normalizeExternalReleaseDate(extReleaseDate); ok {
+		extReleaseDate = normalized
+	} else {
+		s.logger.Warn("External API returned an invalid release date", zap.String("release_date", extReleaseDate))
+		extReleaseDate = ""
+	}
+	enriched := extReleaseDate != "" && extText != "" && extLink != ""
+	if !enriched {
+		fallback := s.fallbackConfig()
+		if fallback.Mode == FallbackDisabled {
+			return "", "", "", false, fmt.Errorf("%w: %s - %s", ErrNoExternalData, group, song)
+		}
+		s.logger.Warn("External API unavailable, using fallback data", zap.String("mode", fallback.Mode))
+		if extReleaseDate == "" {
+			extReleaseDate = fallback.ReleaseDate
+		}
+		if extText == "" {
+			extText = fallback.Text
+		}
+		if extLink == "" {
+			extLink = fallback.Link
+		}
+	}
+	if releaseDate == "" {
+		releaseDate = extReleaseDate
+	}
+	if text == "" {
+		text = extText
+	}
+	if link == "" {
+		link = extLink
+	}
+	return releaseDate, text, link, enriched, nil
+}
 
-	resp, err := s.httpClient.Get(url)
-	if err != nil {
-		s.logger.Warn("Failed to fetch data from external API", zap.Error(err))
-		return "", "", ""
+// enrichedAt returns the current time when the external API provided data, nil otherwise
+func enrichedAt(enriched bool) *time.Time {
+	if !enriched {
+		return nil
 	}
-	defer resp.Body.Close()
+	now := time.Now()
+	return &now
+}
 
-	if resp.StatusCode != http.StatusOK {
-		s.logger.Warn("External API returned non-OK status", zap.Int("status_code", resp.StatusCode))
+// fetchExternalData fetches song details from the configured enrichment provider
+func (s *MusicService) fetchExternalData(ctx context.Context, group, song string) (releaseDate, text, link string) {
+	info, ok := s.fetchExternalInfo(ctx, group, song)
+	if !ok {
 		return "", "", ""
 	}
+	return info.ReleaseDate, info.Text, info.Link
+}
 
-	var data struct {
-		ReleaseDate string `json:"release_date"`
-		Text        string `json:"text"`
-		Link        string `json:"link"`
+// fetchExternalInfo looks the song up with the configured enrichment provider, reporting false when
+// the provider found nothing. The lookup runs in a span, under which the provider calls are traced, and
+// its outcome and time are logged with the request ID.
+func (s *MusicService) fetchExternalInfo(ctx context.Context, group, song string) (SongDetails, bool) {
+	ctx, span := tracer.Start(ctx, "Enrichment.Lookup", trace.WithAttributes(tracing.AttributeProvider.String(s.enricher.Name())))
+	defer span.End()
+	start := time.Now()
+	details, err := s.enricher.Lookup(ctx, group, song)
+	span.SetAttributes(attributeFound.Bool(err == nil))
+	fields := []zap.Field{
+		zap.String("provider", s.enricher.Name()),
+		zap.Bool("found", err == nil),
+		zap.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		zap.String("request_id", tracing.RequestID(ctx)),
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		s.logger.Warn("Failed to decode external API response", zap.Error(err))
-		return "", "", ""
+	if err != nil {
+		if !errors.Is(err, ErrNoDetails) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			s.logger.Debug("Enrichment provider found no details", append(fields, zap.Error(err))...)
+		}
+		return SongDetails{}, false
 	}
-
-	return data.ReleaseDate, data.Text, data.Link
+	s.logger.Debug("Enrichment lookup finished", fields...)
+	return details, true
 }
 
-// GetSongs retrieves a list of songs with filtering and pagination
-func (s *MusicService) GetSongs(group, song string, page, limit int) ([]models.Song, error) {
-	s.logger.Debug("Fetching songs", zap.String("group", group), zap.String("song", song))
-	songs, err := s.repo.GetSongs(group, song, page, limit)
+// GetSongs retrieves a page of songs with filtering and sorting, along with the total number of matches
+func (s *MusicService) GetSongs(ctx context.Context, filter models.SongFilter, sort string, page, limit int) (_ models.SongPage, err error) {
+	defer metrics.ObserveOperation("get_songs", time.Now(), &err)
+	s.logger.Debug("Fetching songs", zap.String("group", filter.Group), zap.String("song", filter.Song), zap.String("sort", sort))
+	if !repository.IsSortSupported(sort) {
+		s.logger.Warn("Unsupported sort requested", zap.String("sort", sort))
+		return models.SongPage{}, fmt.Errorf("%w: %s", ErrUnsupportedSort, sort)
+	}
+	for _, field := range filter.Missing {
+		if !repository.IsNullableField(field) {
+			s.logger.Warn("Unsupported missing field requested", zap.String("field", field))
+			return models.SongPage{}, fmt.Errorf("%w: %s", ErrUnsupportedField, field)
+		}
+	}
+	songs, err := s.repo.GetSongs(ctx, filter, sort, page, limit)
 	if err != nil {
 		s.logger.Error("Failed to fetch songs from database", zap.Error(err))
-		return nil, err
+		return models.SongPage{}, err
 	}
-	s.logger.Info("Songs fetched successfully", zap.Int("count", len(songs)))
-	return songs, nil
+	total, err := s.repo.CountSongs(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to count songs in database", zap.Error(err))
+		return models.SongPage{}, err
+	}
+	s.logger.Info("Songs fetched successfully", zap.Int("count", len(songs)), zap.Int("total", total))
+	return models.SongPage{
+		Data:       songs,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
 }
 
-// GetVerses retrieves verses for a song with pagination
-func (s *MusicService) GetVerses(songID int, page, limit int) ([]Verse, error) {
-	s.logger.Debug("Fetching verses for song", zap.Int("song_id", songID))
-	song, err := s.repo.GetSongByID(songID)
+// CountSongs returns the number of songs matching the GetSongs filters
+func (s *MusicService) CountSongs(ctx context.Context, filter models.SongFilter) (_ int, err error) {
+	defer metrics.ObserveOperation("count_songs", time.Now(), &err)
+	s.logger.Debug("Counting songs", zap.String("group", filter.Group), zap.String("song", filter.Song))
+	for _, field := range filter.Missing {
+		if !repository.IsNullableField(field) {
+			s.logger.Warn("Unsupported missing field requested", zap.String("field", field))
+			return 0, fmt.Errorf("%w: %s", ErrUnsupportedField, field)
+		}
+	}
+	total, err := s.repo.CountSongs(ctx, filter)
 	if err != nil {
-		s.logger.Error("Failed to fetch song", zap.Int("song_id", songID), zap.Error(err))
-		return nil, err
+		s.logger.Error("Failed to count songs in database", zap.Error(err))
+		return 0, err
 	}
+	return total, nil
+}
 
-	// Split text into verses by "\n\n"
-	verses := strings.Split(song.Text, "\n\n")
-	totalVerses := len(verses)
-	start := (page - 1) * limit
-	end := start + limit
-	if start >= totalVerses {
-		return []Verse{}, nil
+// SongExists reports whether a song with the group and title exists, ignoring case, and returns its ID
+func (s *MusicService) SongExists(ctx context.Context, group, song string) (int, bool, error) {
+	s.logger.Debug("Checking song existence", zap.String("group", group), zap.String("song", song))
+	id, err := s.repo.FindSongID(ctx, group, song)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
 	}
-	if end > totalVerses {
-		end = totalVerses
+	if err != nil {
+		s.logger.Error("Failed to look up song in database", zap.Error(err))
+		return 0, false, err
 	}
+	return id, true, nil
+}
+
+// GetSongFacets computes value/count buckets for the requested facets using the GetSongs filters
+func (s *MusicService) GetSongFacets(ctx context.Context, filter models.SongFilter, facets []string) (map[string][]models.FacetBucket, error) {
+	s.logger.Debug("Fetching song facets", zap.Strings("facets", facets))
+	for _, facet := range facets {
+		if !repository.IsFacetSupported(facet) {
+			s.logger.Warn("Unsupported facet requested", zap.String("facet", facet))
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedFacet, facet)
+		}
+	}
+	result, err := s.repo.GetSongFacets(ctx, filter, facets)
+	if err != nil {
+		s.logger.Error("Failed to fetch song facets from database", zap.Error(err))
+		return nil, err
+	}
+	s.logger.Info("Song facets fetched successfully", zap.Int("count", len(result)))
+	return result, nil
+}
 
-	result := make([]Verse, 0, end-start)
-	for i := start; i < end; i++ {
-		verseText := strings.TrimSpace(verses[i])
-		result = append(result, Verse{Number: i + 1, Text: verseText})
+// GetVerses retrieves one page of verses for a song along with the song metadata, total verse count and
+// page count. An empty delimiter selects the song's split strategy, or the configured default for songs
+// without one. A page past the last one returns ErrPageOutOfRange.
+func (s *MusicService) GetVerses(ctx context.Context, songID int, page, limit int, delimiter string) (_ *VersePage, err error) {
+	defer metrics.ObserveOperation("get_verses", time.Now(), &err)
+	s.logger.Debug("Fetching verses for song", zap.Int("song_id", songID), zap.String("delimiter", delimiter))
+	if page-1 > maxVerseOffset/limit {
+		s.logger.Warn("Verse page out of range", zap.Int("song_id", songID), zap.Int("page", page))
+		return nil, fmt.Errorf("%w: page %d", ErrPageOutOfRange, page)
+	}
+	if delimiter != "" {
+		if err := ValidateVerseDelimiter(delimiter); err != nil {
+			return nil, err
+		}
+	}
+	key := fmt.Sprintf("verses:%d:%s:%d:%d", songID, delimiter, page, limit)
+	result, err := cachedRead(ctx, s, key, cloneVersePage, func() (*VersePage, error) {
+		return s.readVerses(ctx, songID, page, limit, delimiter)
+	})
+	if errors.Is(err, ErrPageOutOfRange) {
+		s.logger.Warn("Verse page out of range", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
 	}
+	if err != nil {
+		s.logger.Error("Failed to fetch verses", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+	s.recordView(songID)
 
-	s.logger.Info("Verses retrieved successfully", zap.Int("song_id", songID), zap.Int("total_verses", totalVerses))
+	s.logger.Info("Verses retrieved successfully", zap.Int("song_id", songID), zap.Int("total_verses", result.TotalVerses))
 	return result, nil
 }
 
 // UpdateSong updates an existing song in the database
-func (s *MusicService) UpdateSong(id int, group, song, releaseDate, text, link string) error {
+func (s *MusicService) UpdateSong(ctx context.Context, id int, group, song, releaseDate, text, link string) (err error) {
+	defer metrics.ObserveOperation("update_song", time.Now(), &err)
 	s.logger.Debug("Updating song", zap.Int("id", id))
-	err := s.repo.UpdateSong(id, group, song, releaseDate, text, link)
+	if _, err := NormalizeReleaseDate(releaseDate, DateFormatDefault); err != nil {
+		s.logger.Warn("Invalid release date", zap.Int("id", id), zap.String("release_date", releaseDate))
+		return err
+	}
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkLegalHold(ctx, "update_song", id); err != nil {
+			return err
+		}
+		_, err := s.reviseSong(ctx, id, models.RevisionUpdate, nil, func(ctx context.Context) error {
+			return s.repo.UpdateSong(ctx, id, group, song, releaseDate, text, link)
+		})
+		if err != nil {
+			return err
+		}
+		return s.record(ctx, analytics.EventSongUpdated, id, 1)
+	})
 	if err != nil {
-		s.logger.Error("Failed to update song", zap.Int("id", id), zap.Error(err))
+		if !errors.Is(err, ErrLegalHold) {
+			s.logger.Error("Failed to update song", zap.Int("id", id), zap.Error(err))
+		}
 		return err
 	}
+	s.publish(analytics.EventSongUpdated, id, 1)
+	s.indexVerses(ctx, id, text)
 	s.logger.Info("Song updated successfully", zap.Int("id", id))
 	return nil
 }
 
-// DeleteSong deletes a song from the database
-func (s *MusicService) DeleteSong(id int) error {
-	s.logger.Debug("Deleting song", zap.Int("id", id))
-	err := s.repo.DeleteSong(id)
+// UpdateSongPartial updates only the fields present in the patch, leaving the others unchanged
+func (s *MusicService) UpdateSongPartial(ctx context.Context, id int, patch models.SongPatch) (err error) {
+	defer metrics.ObserveOperation("update_song_partial", time.Now(), &err)
+	s.logger.Debug("Partially updating song", zap.Int("id", id))
+	required := map[string]models.OptionalString{"group": patch.Group, "song": patch.Song}
+	for name, field := range required {
+		if field.Set && (field.Null || strings.TrimSpace(field.Value) == "") {
+			s.logger.Warn("Required field cleared in patch", zap.Int("id", id), zap.String("field", name))
+			return fmt.Errorf("%w: %s cannot be null or empty", ErrInvalidPatch, name)
+		}
+	}
+	if field := patch.ReleaseDate; field.Set && !field.Null && strings.TrimSpace(field.Value) == "" {
+		s.logger.Warn("Release date emptied in patch", zap.Int("id", id))
+		return fmt.Errorf("%w: release_date cannot be empty, use null when it is unknown", ErrInvalidPatch)
+	}
+	if field := patch.ReleaseDate; field.Set && !field.Null {
+		if _, err := NormalizeReleaseDate(field.Value, DateFormatDefault); err != nil {
+			s.logger.Warn("Invalid release date in patch", zap.Int("id", id), zap.String("release_date", field.Value))
+			return err
+		}
+	}
+	if field := patch.SplitStrategy; field.Set && !field.Null {
+		if err := ValidateVerseDelimiter(field.Value); err != nil {
+			s.logger.Warn("Invalid split strategy in patch", zap.Int("id", id), zap.String("split_strategy", field.Value))
+			return fmt.Errorf("%w: split_strategy: %v", ErrInvalidPatch, err)
+		}
+	}
+	if field := patch.LicensingFee; field.Set && !field.Null && field.Value < 0 {
+		s.logger.Warn("Negative licensing fee in patch", zap.Int("id", id), zap.Float64("licensing_fee", field.Value))
+		return fmt.Errorf("%w: licensing_fee cannot be negative", ErrInvalidPatch)
+	}
+	if field := patch.AlbumID; field.Set && !field.Null {
+		if _, err := s.repo.GetAlbum(ctx, field.Value); err != nil {
+			if err == sql.ErrNoRows {
+				s.logger.Warn("Unknown album in patch", zap.Int("id", id), zap.Int("album_id", field.Value))
+				return fmt.Errorf("%w: album %d does not exist", ErrInvalidPatch, field.Value)
+			}
+			return err
+		}
+	}
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkLegalHold(ctx, "update_song_partial", id); err != nil {
+			return err
+		}
+		_, err := s.reviseSong(ctx, id, models.RevisionUpdate, nil, func(ctx context.Context) error {
+			return s.repo.UpdateSongPartial(ctx, id, patch)
+		})
+		if err != nil {
+			return err
+		}
+		return s.record(ctx, analytics.EventSongUpdated, id, 1)
+	})
 	if err != nil {
-		s.logger.Error("Failed to delete song", zap.Int("id", id), zap.Error(err))
+		if !errors.Is(err, ErrLegalHold) {
+			s.logger.Error("Failed to partially update song", zap.Int("id", id), zap.Error(err))
+		}
 		return err
 	}
-	s.logger.Info("Song deleted successfully", zap.Int("id", id))
+	s.publish(analytics.EventSongUpdated, id, 1)
+	if patch.Text.Set {
+		s.indexVerses(ctx, id, patch.Text.Value)
+	}
+	s.logger.Info("Song partially updated successfully", zap.Int("id", id))
 	return nil
 }
 
-// TruncateSongs truncates the songs table and resets the ID sequence
-func (s *MusicService) TruncateSongs() error {
-	s.logger.Debug("Truncating table")
-	err := s.repo.TruncateSongs()
+// DeleteSong moves a song to the trash, from which it can be restored until the trash retention ends
+func (s *MusicService) DeleteSong(ctx context.Context, id int) (err error) {
+	defer metrics.ObserveOperation("delete_song", time.Now(), &err)
+	s.logger.Debug("Deleting song", zap.Int("id", id))
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkLegalHold(ctx, "delete_song", id); err != nil {
+			return err
+		}
+		if err := s.repo.TrashSong(ctx, id); err != nil {
+			return err
+		}
+		return s.record(ctx, analytics.EventSongDeleted, id, 1)
+	})
 	if err != nil {
-		s.logger.Error("Failed to truncate table", zap.Error(err))
+		if !errors.Is(err, ErrLegalHold) {
+			s.logger.Error("Failed to delete song", zap.Int("id", id), zap.Error(err))
+		}
 		return err
 	}
-	s.logger.Info("Table truncated successfully")
+	s.publish(analytics.EventSongDeleted, id, 1)
+	s.logger.Info("Song deleted successfully", zap.Int("id", id))
 	return nil
 }
+
+// Wait blocks until every background job started by the service has stopped.
+// Jobs stop when the context they were started with is cancelled.
+func (s *MusicService) Wait() {
+	s.background.Wait()
+}
+
+// Ping checks that the database is reachable
+func (s *MusicService) Ping(ctx context.Context) error {
+	return s.repo.Ping(ctx)
+}
+
+// RecentQueries returns the most recently executed repository queries
+func (s *MusicService) RecentQueries() []repository.QueryLogEntry {
+	return s.repo.RecentQueries()
+}
+
+// TruncateSongs truncates the songs table and resets the ID sequence. With backup, a snapshot of the songs
+// is taken in the same transaction and returned, so the truncate can be reversed by restoring it; no song
+// is removed when the snapshot fails. ErrLegalHold is returned while any song is on legal hold.
+func (s *MusicService) TruncateSongs(ctx context.Context, backup bool) (*models.Snapshot, error) {
+	s.logger.Debug("Truncating table", zap.Bool("backup", backup))
+	var snapshot *models.Snapshot
+	err := s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		snapshot = nil
+		// Counted in the transaction, which keeps holds from being set until the songs are gone
+		held, err := s.repo.CountLegalHolds(ctx)
+		if err != nil {
+			return err
+		}
+		if held > 0 {
+			s.audit(analytics.EventLegalHoldBlocked, 0, held)
+			s.logger.Warn("Truncation blocked by legal hold", zap.Int("held", held))
+			return fmt.Errorf("%w: %d songs", ErrLegalHold, held)
+		}
+		if backup {
+			created, err := s.repo.CreateSnapshot(ctx, models.SnapshotTruncate)
+			if err != nil {
+				return err
+			}
+			snapshot = &created
+		}
+		if err := s.repo.TruncateSongs(ctx); err != nil {
+			return err
+		}
+		return s.record(ctx, analytics.EventSongsCleared, 0, 1)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrLegalHold) {
+			s.logger.Error("Failed to truncate table", zap.Error(err))
+		}
+		return nil, err
+	}
+	s.publish(analytics.EventSongsCleared, 0, 1)
+	if snapshot != nil {
+		s.logger.Info("Table truncated successfully", zap.Int("snapshot_id", snapshot.ID), zap.Int("songs", snapshot.SongCount))
+	} else {
+		s.logger.Info("Table truncated successfully")
+	}
+	return snapshot, nil
+}
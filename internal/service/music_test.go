@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/apistub"
+	"music-library/internal/breaker"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+	"music-library/internal/repository/mocks"
+)
+
+// newMockedService returns a service over a mock repository, which runs transactions in place,
+// enriching songs from a stub of the external API
+func newMockedService(t *testing.T) (*MusicService, *mocks.MockRepository, *apistub.Server) {
+	api := apistub.New(t)
+	repo := mocks.NewMockRepository(gomock.NewController(t))
+	repo.EXPECT().ConfigureStatementTimeout(gomock.Any()).AnyTimes()
+	repo.EXPECT().RunInTransaction(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) })
+	svc := NewMusicService(repo, zap.NewNop(), api.Client())
+	svc.ConfigureResilience(RetryConfig{Attempts: 1}, breaker.New(ExternalAPIProvider, breaker.DefaultConfig, zap.NewNop()))
+	return svc, repo, api
+}
+
+func TestAddSongEnrichesFromExternalAPI(t *testing.T) {
+	svc, repo, api := newMockedService(t)
+	text := "Paranoia is in bloom\n\nThey will not force us"
+	api.AddSong("Muse", "Uprising", apistub.Song{ReleaseDate: "07.09.2009", Text: text, Link: "https://example.com/uprising"})
+
+	repo.EXPECT().AddSong(gomock.Any(), "Muse", "Uprising", "07.09.2009", text, "https://example.com/uprising", gomock.Not(gomock.Nil())).Return(7, nil)
+	repo.EXPECT().AddSongEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, event models.SongEvent) (int64, error) {
+		assert.Equal(t, 7, event.SongID)
+		return 1, nil
+	})
+	repo.EXPECT().SaveVerseIndex(gomock.Any(), 7, DefaultVerseDelimiter, repository.TextHash(text), gomock.Len(2)).Return(nil)
+
+	id, status, err := svc.AddSong(context.Background(), "Muse", "Uprising")
+	require.NoError(t, err)
+	assert.Equal(t, 7, id)
+	assert.Equal(t, models.EnrichmentComplete, status)
+	if assert.Len(t, api.Requests(), 1) {
+		assert.Equal(t, "Uprising", api.Requests()[0].Get("song"))
+	}
+}
+
+func TestAddSongFallsBackWhenExternalAPIFails(t *testing.T) {
+	svc, repo, api := newMockedService(t)
+	api.FailWith(http.StatusServiceUnavailable)
+
+	fallback := DefaultFallbackConfig
+	repo.EXPECT().AddSong(gomock.Any(), "Muse", "Uprising", fallback.ReleaseDate, fallback.Text, fallback.Link, (*time.Time)(nil)).Return(7, nil)
+	repo.EXPECT().AddSongEvent(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+	repo.EXPECT().SaveVerseIndex(gomock.Any(), 7, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	_, status, err := svc.AddSong(context.Background(), "Muse", "Uprising")
+	require.NoError(t, err)
+	assert.Equal(t, models.EnrichmentComplete, status)
+
+	svc.ConfigureFallback(FallbackConfig{Mode: FallbackDisabled})
+	_, _, err = svc.AddSong(context.Background(), "Muse", "Uprising")
+	assert.ErrorIs(t, err, ErrNoExternalData, "nothing is written without data")
+}
+
+func TestAddSongRepositoryFailure(t *testing.T) {
+	svc, repo, api := newMockedService(t)
+	api.AddSong("Muse", "Uprising", apistub.Song{ReleaseDate: "07.09.2009", Text: "text", Link: "https://example.com"})
+	failure := errors.New("connection refused")
+	repo.EXPECT().AddSong(gomock.Any(), "Muse", "Uprising", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(0, failure)
+
+	_, _, err := svc.AddSong(context.Background(), "Muse", "Uprising")
+	assert.ErrorIs(t, err, failure, "no event is recorded nor verses indexed for a song that was not added")
+}
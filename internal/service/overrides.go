@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// ErrInvalidOverride is returned when saving a personal override without text
+var ErrInvalidOverride = errors.New("invalid override")
+
+// GetSongOverride returns the user's personal override of the song, or sql.ErrNoRows when there is none
+func (s *MusicService) GetSongOverride(ctx context.Context, userID, songID int) (models.SongOverride, error) {
+	s.logger.Debug("Fetching song override", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return s.repo.GetSongOverride(ctx, userID, songID)
+}
+
+// SaveSongOverride creates or replaces the user's personal override of the song's text, leaving the song
+// unchanged. sql.ErrNoRows is returned when the song does not exist.
+func (s *MusicService) SaveSongOverride(ctx context.Context, userID, songID int, text string) (_ models.SongOverride, err error) {
+	defer metrics.ObserveOperation("save_song_override", time.Now(), &err)
+	s.logger.Debug("Saving song override", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	if strings.TrimSpace(text) == "" {
+		return models.SongOverride{}, fmt.Errorf("%w: text is required", ErrInvalidOverride)
+	}
+	override, err := s.repo.SaveSongOverride(ctx, userID, songID, text)
+	if err != nil {
+		return models.SongOverride{}, err
+	}
+	s.logger.Info("Song override saved successfully", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return override, nil
+}
+
+// DeleteSongOverride discards the user's personal override of the song, returning sql.ErrNoRows when there is none
+func (s *MusicService) DeleteSongOverride(ctx context.Context, userID, songID int) error {
+	s.logger.Debug("Deleting song override", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	if err := s.repo.DeleteSongOverride(ctx, userID, songID); err != nil {
+		return err
+	}
+	s.logger.Info("Song override deleted successfully", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return nil
+}
+
+// ApplySongOverrides replaces the text of the songs the user has a personal override of with the override
+func (s *MusicService) ApplySongOverrides(ctx context.Context, userID int, songs []models.Song) error {
+	if len(songs) == 0 {
+		return nil
+	}
+	ids := make([]int, len(songs))
+	for i, song := range songs {
+		ids[i] = song.ID
+	}
+	overrides, err := s.repo.GetSongOverrides(ctx, userID, ids)
+	if err != nil {
+		return err
+	}
+	texts := make(map[int]string, len(overrides))
+	for _, override := range overrides {
+		texts[override.SongID] = override.Text
+	}
+	for i := range songs {
+		if text, ok := texts[songs[i].ID]; ok {
+			songs[i].Text = &text
+		}
+	}
+	return nil
+}
+
+// GetPersonalVerses returns a page of the song's verses as the user sees them: split from the user's
+// personal override when there is one, and the shared verses otherwise
+func (s *MusicService) GetPersonalVerses(ctx context.Context, userID, songID, page, limit int, delimiter string) (_ *VersePage, err error) {
+	override, err := s.repo.GetSongOverride(ctx, userID, songID)
+	if err == sql.ErrNoRows {
+		return s.GetVerses(ctx, songID, page, limit, delimiter)
+	}
+	if err != nil {
+		s.logger.Error("Failed to fetch song override", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+
+	defer metrics.ObserveOperation("get_verses", time.Now(), &err)
+	if page-1 > maxVerseOffset/limit {
+		return nil, fmt.Errorf("%w: page %d", ErrPageOutOfRange, page)
+	}
+	if delimiter != "" {
+		if err := ValidateVerseDelimiter(delimiter); err != nil {
+			return nil, err
+		}
+	}
+	song, err := s.songByID(ctx, songID)
+	if err != nil {
+		return nil, err
+	}
+	if delimiter == "" {
+		delimiter = s.splitStrategy(song.SplitStrategy)
+	}
+	// Overrides are not indexed: they are read by their owner alone, so splitting them is cheap enough
+	normalized := normalizeLyrics(override.Text)
+	result, err := versePage(song, normalized, findVerses(normalized, delimiter), page, limit)
+	if err != nil {
+		s.logger.Warn("Verse page out of range", zap.Int("song_id", songID), zap.Error(err))
+		return nil, err
+	}
+	s.recordView(songID)
+	return result, nil
+}
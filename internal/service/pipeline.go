@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"music-library/internal/analytics"
+	"music-library/internal/jobs"
+	"music-library/internal/models"
+)
+
+// ErrImportNotFound is returned when resuming an import that does not exist
+var ErrImportNotFound = errors.New("import not found")
+
+// maxReportedFailures caps the failed rows kept in an import result
+const maxReportedFailures = 1000
+
+// ImportConfig controls the stages of the import pipeline
+type ImportConfig struct {
+	// BufferSize is the capacity of the channels between stages, which bounds memory use
+	BufferSize int
+	// ValidateWorkers is the number of goroutines validating rows and looking up existing songs
+	ValidateWorkers int
+	// BatchSize is the number of rows written, and checkpointed, per transaction. The new songs of a batch
+	// are copied at once, so larger batches import faster but redo more rows when resumed.
+	BatchSize int
+}
+
+// DefaultImportConfig is used until ConfigureImport is called
+var DefaultImportConfig = ImportConfig{
+	BufferSize:      256,
+	ValidateWorkers: 4,
+	BatchSize:       1000,
+}
+
+// ConfigureImport replaces the import pipeline settings
+func (s *MusicService) ConfigureImport(cfg ImportConfig) {
+	if cfg.BufferSize < 1 {
+		cfg.BufferSize = DefaultImportConfig.BufferSize
+	}
+	if cfg.ValidateWorkers < 1 {
+		cfg.ValidateWorkers = DefaultImportConfig.ValidateWorkers
+	}
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = DefaultImportConfig.BatchSize
+	}
+	s.importCfg = cfg
+}
+
+// pipelineRow is a row travelling through the import pipeline
+type pipelineRow struct {
+	row        int
+	record     []string
+	song       ImportRow
+	existingID int
+	err        string
+}
+
+// ImportSongs streams a CSV file through a bounded pipeline: parse → validate → enrich → batch write.
+// Every stage is connected by a buffered channel, so a slow stage applies backpressure to the ones before it
+// and memory use does not depend on the file size. Rows matching an existing song by group and title update it;
+// fields missing from new rows are completed from the external API.
+// Passing the ID of an earlier, interrupted import skips every row up to its last checkpoint.
+func (s *MusicService) ImportSongs(ctx context.Context, r io.Reader, mapping ImportMapping, importID string) (*ImportResult, error) {
+	return s.importSongs(ctx, r, mapping, importID, nil)
+}
+
+// StartImport queues a background job importing the CSV file at path like ImportSongs, reporting the rows
+// past the checkpoint as its progress. The job takes ownership of the file and removes it once done. It is
+// owned by the user with the ID, or by no one when the ID is zero.
+func (s *MusicService) StartImport(ctx context.Context, userID int, path string, mapping ImportMapping, importID string) (models.Job, error) {
+	if s.jobs == nil {
+		os.Remove(path)
+		return models.Job{}, ErrJobsUnavailable
+	}
+	var ownerID *int
+	if userID > 0 {
+		ownerID = &userID
+	}
+	job, err := s.jobs.Submit(ctx, models.JobKindImport, ownerID, func(ctx context.Context, progress *jobs.Progress) (any, error) {
+		defer os.Remove(path)
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		// An interrupted import still returns its result, keeping the import_id to resume it with
+		return s.importSongs(ctx, file, mapping, importID, progress)
+	})
+	if err != nil {
+		os.Remove(path)
+		s.logger.Error("Failed to queue import", zap.Error(err))
+		return job, err
+	}
+	s.logger.Info("Import queued", zap.String("job_id", job.ID))
+	return job, nil
+}
+
+// importSongs runs the import pipeline, reporting its progress when run as a job
+func (s *MusicService) importSongs(ctx context.Context, r io.Reader, mapping ImportMapping, importID string, progress *jobs.Progress) (*ImportResult, error) {
+	s.logger.Info("Importing songs from CSV", zap.String("import_id", importID))
+	imp, err := s.startImport(ctx, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read header: %v", ErrInvalidImport, err)
+	}
+	columns, err := mapping.fieldColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := s.importCfg
+	parsed := make(chan pipelineRow, cfg.BufferSize)
+	validated := make(chan pipelineRow, cfg.BufferSize)
+	enriched := make(chan pipelineRow, cfg.BufferSize)
+	g, gctx := errgroup.WithContext(ctx)
+
+	send := func(ch chan<- pipelineRow, row pipelineRow) error {
+		select {
+		case ch <- row:
+			return nil
+		case <-gctx.Done():
+			return gctx.Err()
+		}
+	}
+
+	// Parse: read records one at a time, skipping rows committed by an earlier run
+	g.Go(func() error {
+		defer close(parsed)
+		for row := 2; ; row++ {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if row <= imp.CheckpointRow {
+				continue
+			}
+			item := pipelineRow{row: row, record: record}
+			if err != nil {
+				item.err = err.Error()
+			}
+			if err := send(parsed, item); err != nil {
+				return err
+			}
+		}
+	})
+
+	// Validate: apply the mapping and look up songs that already exist
+	runStage(g, cfg.ValidateWorkers, validated, func() error {
+		for item := range parsed {
+			if item.err == "" {
+				song, err := mapping.convert(item.record, columns)
+				song.Row = item.row
+				item.song, item.record = song, nil
+				if err != nil {
+					item.err = err.Error()
+				} else if id, err := s.repo.FindSongID(gctx, song.Group, song.Song); err == nil {
+					item.existingID = id
+					// A song on legal hold is left as it is, failing its row
+					if err := s.checkLegalHold(gctx, "import", id); errors.Is(err, ErrLegalHold) {
+						item.err = err.Error()
+					} else if err != nil {
+						return err
+					}
+				} else if err != sql.ErrNoRows {
+					return err
+				}
+			}
+			if err := send(validated, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// Enrich: complete missing fields of new songs, respecting the provider limits
+	runStage(g, s.enrichment.Concurrency, enriched, func() error {
+		for item := range validated {
+			if item.err == "" && item.existingID == 0 {
+				song, err := s.enrichRow(gctx, item.song)
+				item.song = song
+				if err != nil {
+					item.err = err.Error()
+				}
+			}
+			if err := send(enriched, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// Write: commit batches and advance the checkpoint past every finished row
+	result := &ImportResult{ImportID: imp.ID, ResumedFromRow: imp.CheckpointRow, Failures: []ImportItemResult{}}
+	g.Go(func() error {
+		return s.writeImport(gctx, imp, enriched, cfg.BatchSize, result, progress)
+	})
+
+	runErr := g.Wait()
+	status := models.ImportStatusCompleted
+	if runErr != nil {
+		status = models.ImportStatusFailed
+		s.logger.Error("Import interrupted", zap.String("import_id", imp.ID), zap.Error(runErr))
+	}
+	// An import cancelled with its request is still recorded as failed, so it can be resumed. A completed
+	// import records its catalog event with its final status.
+	var final models.Import
+	err = s.repo.RunInTransaction(context.WithoutCancel(ctx), func(ctx context.Context) error {
+		var err error
+		if final, err = s.repo.FinishImport(ctx, imp.ID, status); err != nil || runErr != nil {
+			return err
+		}
+		return s.record(ctx, analytics.EventSongsImported, 0, final.Created+final.Updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Status = final.Status
+	result.Created, result.Updated, result.Failed = final.Created, final.Updated, final.Failed
+	if runErr != nil {
+		return result, runErr
+	}
+
+	s.publish(analytics.EventSongsImported, 0, result.Created+result.Updated)
+	s.logger.Info("Songs imported", zap.String("import_id", imp.ID), zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated), zap.Int("failed", result.Failed))
+	return result, nil
+}
+
+// startImport creates a new import or loads the checkpoint of the one being resumed
+func (s *MusicService) startImport(ctx context.Context, importID string) (models.Import, error) {
+	if importID != "" {
+		imp, err := s.repo.GetImport(ctx, importID)
+		if err == sql.ErrNoRows {
+			return imp, fmt.Errorf("%w: %s", ErrImportNotFound, importID)
+		}
+		return imp, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return models.Import{}, err
+	}
+	return s.repo.CreateImport(ctx, hex.EncodeToString(id))
+}
+
+// runStage runs fn in workers goroutines and closes out once all of them have returned
+func runStage(g *errgroup.Group, workers int, out chan pipelineRow, fn func() error) {
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		g.Go(func() error {
+			defer wg.Done()
+			return fn()
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+}
+
+// writeImport is the final pipeline stage. Rows arrive out of order from the concurrent stages,
+// so the checkpoint only advances to the highest row below which every row has been written or rejected.
+func (s *MusicService) writeImport(ctx context.Context, imp models.Import, in <-chan pipelineRow, batchSize int, result *ImportResult, progress *jobs.Progress) error {
+	nextRow := imp.CheckpointRow + 1
+	if nextRow < 2 {
+		nextRow = 2
+	}
+	checkpoint := nextRow
+	// finished holds rows past the checkpoint that are done: true when written, false when rejected
+	finished := make(map[int]bool)
+	batch := make([]models.ImportSong, 0, batchSize)
+
+	flush := func() error {
+		for _, song := range batch {
+			finished[song.Row] = true
+		}
+		failed := 0
+		for written, ok := finished[nextRow]; ok; written, ok = finished[nextRow] {
+			if !written {
+				failed++
+			}
+			delete(finished, nextRow)
+			nextRow++
+		}
+		ids, err := s.repo.ImportBatch(ctx, imp.ID, batch, nextRow-1, failed)
+		if err != nil {
+			return err
+		}
+		// Imports only replace the text of existing songs with a non-empty one
+		for i, id := range ids {
+			if batch[i].Text != "" || batch[i].ExistingID == 0 {
+				s.indexVerses(ctx, id, batch[i].Text)
+			}
+		}
+		progress.Add(nextRow-checkpoint, failed)
+		checkpoint = nextRow
+		batch = batch[:0]
+		return nil
+	}
+
+	for item := range in {
+		if item.err != "" {
+			if len(result.Failures) < maxReportedFailures {
+				result.Failures = append(result.Failures, ImportItemResult{Row: item.row, Error: item.err})
+			} else {
+				result.FailuresTruncated = true
+			}
+			finished[item.row] = false
+			continue
+		}
+		batch = append(batch, models.ImportSong{
+			Row:         item.row,
+			ExistingID:  item.existingID,
+			Group:       item.song.Group,
+			Song:        item.song.Song,
+			ReleaseDate: item.song.ReleaseDate,
+			Text:        item.song.Text,
+			Link:        item.song.Link,
+			EnrichedAt:  item.song.EnrichedAt,
+		})
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
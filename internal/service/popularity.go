@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/repository"
+)
+
+// PopularityConfig selects what sort=popularity ranks songs by
+type PopularityConfig struct {
+	// Source is one of the repository.Popularity* sources; empty means internal plays
+	Source string
+	// Weights maps the internal and external sources to their weight in a blend
+	Weights map[string]float64
+	// CacheTTL is how long a listener count fetched from the external provider is used before it is refetched
+	CacheTTL time.Duration
+	// BatchSize is the number of listener counts fetched per refresh run
+	BatchSize int
+}
+
+// DefaultPopularityConfig ranks by internal plays and refetches external listener counts daily
+var DefaultPopularityConfig = PopularityConfig{
+	Source:    repository.PopularityInternal,
+	CacheTTL:  24 * time.Hour,
+	BatchSize: 50,
+}
+
+// PopularityConfigFromEnv reads the popularity configuration from POPULARITY_SOURCE, POPULARITY_WEIGHTS
+// (e.g. "internal=0.7,external=0.3"), POPULARITY_CACHE_TTL and POPULARITY_BATCH_SIZE
+func PopularityConfigFromEnv() (PopularityConfig, error) {
+	cfg := DefaultPopularityConfig
+	if source := os.Getenv("POPULARITY_SOURCE"); source != "" {
+		cfg.Source = source
+	}
+	pairs, err := parsePairs(os.Getenv("POPULARITY_WEIGHTS"))
+	if err != nil {
+		return cfg, fmt.Errorf("POPULARITY_WEIGHTS: %w", err)
+	}
+	if len(pairs) > 0 {
+		cfg.Weights = make(map[string]float64, len(pairs))
+		for source, value := range pairs {
+			weight, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return cfg, fmt.Errorf("POPULARITY_WEIGHTS: invalid weight %q for %s", value, source)
+			}
+			cfg.Weights[source] = weight
+		}
+	}
+	if value := os.Getenv("POPULARITY_CACHE_TTL"); value != "" {
+		if cfg.CacheTTL, err = time.ParseDuration(value); err != nil || cfg.CacheTTL <= 0 {
+			return cfg, fmt.Errorf("POPULARITY_CACHE_TTL must be a positive duration: %q", value)
+		}
+	}
+	if value := os.Getenv("POPULARITY_BATCH_SIZE"); value != "" {
+		if cfg.BatchSize, err = strconv.Atoi(value); err != nil || cfg.BatchSize < 1 {
+			return cfg, fmt.Errorf("POPULARITY_BATCH_SIZE must be a positive number: %q", value)
+		}
+	}
+	_, err = cfg.Provider()
+	return cfg, err
+}
+
+// Provider builds the repository provider for the configured source
+func (c PopularityConfig) Provider() (repository.PopularityProvider, error) {
+	switch c.Source {
+	case "", repository.PopularityInternal:
+		return repository.InternalPopularity{}, nil
+	case repository.PopularityExternal:
+		return repository.ExternalPopularity{}, nil
+	case repository.PopularityBlend:
+		if len(c.Weights) == 0 {
+			return nil, fmt.Errorf("blend popularity requires weights")
+		}
+		blend := repository.BlendedPopularity{Weights: make(map[repository.PopularityProvider]float64)}
+		for source, weight := range c.Weights {
+			if weight < 0 {
+				return nil, fmt.Errorf("popularity weight of %s must not be negative", source)
+			}
+			switch source {
+			case repository.PopularityInternal:
+				blend.Weights[repository.InternalPopularity{}] = weight
+			case repository.PopularityExternal:
+				blend.Weights[repository.ExternalPopularity{}] = weight
+			default:
+				return nil, fmt.Errorf("unsupported popularity source in weights: %s", source)
+			}
+		}
+		return blend, nil
+	default:
+		return nil, fmt.Errorf("unsupported popularity source: %s", c.Source)
+	}
+}
+
+// usesExternal reports whether rankings depend on listener counts from the external provider
+func (c PopularityConfig) usesExternal() bool {
+	return c.Source == repository.PopularityExternal || (c.Source == repository.PopularityBlend && c.Weights[repository.PopularityExternal] > 0)
+}
+
+// ConfigurePopularity sets what sort=popularity ranks songs by
+func (s *MusicService) ConfigurePopularity(cfg PopularityConfig) error {
+	provider, err := cfg.Provider()
+	if err != nil {
+		return err
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = DefaultPopularityConfig.CacheTTL
+	}
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = DefaultPopularityConfig.BatchSize
+	}
+	s.popularity = cfg
+	s.ConfigurePopularityCacheTTL(cfg.CacheTTL)
+	s.repo.ConfigurePopularity(provider)
+	return nil
+}
+
+// ConfigurePopularityCacheTTL sets how long a fetched listener count is used before it is refetched while
+// the refresher runs; zero or less takes the default. Counts are compared against it on the next refresh.
+func (s *MusicService) ConfigurePopularityCacheTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultPopularityConfig.CacheTTL
+	}
+	s.listenerCacheTTL.Store(int64(ttl))
+}
+
+// RefreshListenerCounts fetches listener counts from the external provider for the songs whose cached count
+// is missing or older than the cache TTL. Songs in skip are not attempted. It returns the number of songs
+// attempted and the IDs of those the provider had no count for.
+func (s *MusicService) RefreshListenerCounts(ctx context.Context, skip []int) (int, []int, error) {
+	ttl := time.Duration(s.listenerCacheTTL.Load())
+	s.logger.Debug("Refreshing listener counts", zap.Duration("cache_ttl", ttl))
+	songs, err := s.repo.GetSongsNeedingListeners(ctx, ttl, skip, s.popularity.BatchSize)
+	if err != nil {
+		s.logger.Error("Failed to fetch songs needing listener counts", zap.Error(err))
+		return 0, nil, err
+	}
+
+	var failed []int
+	for _, song := range songs {
+		if err := s.enrichLimiter.Wait(ctx); err != nil {
+			return len(songs), failed, err
+		}
+		info, ok := s.fetchExternalInfo(ctx, song.Group, song.Song)
+		if !ok || info.Listeners == nil {
+			failed = append(failed, song.ID)
+			continue
+		}
+		if err := s.repo.SaveListenerCount(ctx, song.ID, *info.Listeners); err != nil {
+			failed = append(failed, song.ID)
+		}
+	}
+
+	s.logger.Info("Listener counts refreshed", zap.Int("refreshed", len(songs)-len(failed)), zap.Int("failed", len(failed)))
+	return len(songs), failed, nil
+}
+
+// StartListenerRefresher keeps the cached listener counts fresh, refreshing immediately and then every interval
+// until ctx is cancelled. It does nothing unless popularity rankings use the external provider.
+func (s *MusicService) StartListenerRefresher(ctx context.Context, interval time.Duration) {
+	if !s.popularity.usesExternal() {
+		return
+	}
+	s.logger.Info("Starting listener count refresher", zap.Duration("interval", interval),
+		zap.Duration("cache_ttl", time.Duration(s.listenerCacheTTL.Load())))
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var skip []int
+		for {
+			attempted, failed, err := s.RefreshListenerCounts(ctx, skip)
+			if err == nil {
+				skip = append(skip, failed...)
+				if attempted < s.popularity.BatchSize {
+					// Every song has been attempted: retry the ones without a count next run
+					skip = nil
+				}
+			}
+			select {
+			case <-ctx.Done():
+				s.logger.Info("Listener count refresher stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
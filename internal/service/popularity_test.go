@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-library/internal/repository"
+)
+
+func TestPopularityConfigFromEnv(t *testing.T) {
+	cfg, err := PopularityConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPopularityConfig, cfg)
+	assert.False(t, cfg.usesExternal())
+
+	t.Setenv("POPULARITY_SOURCE", "blend")
+	_, err = PopularityConfigFromEnv()
+	assert.Error(t, err, "blends require weights")
+
+	t.Setenv("POPULARITY_WEIGHTS", "internal=0.7,external=0.3")
+	cfg, err = PopularityConfigFromEnv()
+	require.NoError(t, err)
+	assert.True(t, cfg.usesExternal())
+	provider, err := cfg.Provider()
+	require.NoError(t, err)
+	assert.Equal(t, "0.3 * LN(1 + "+repository.ExternalPopularity{}.Score()+") + 0.7 * LN(1 + COALESCE(v.views, 0))", provider.Score())
+
+	t.Setenv("POPULARITY_WEIGHTS", "internal=0.7,charts=0.3")
+	_, err = PopularityConfigFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("POPULARITY_SOURCE", "charts")
+	t.Setenv("POPULARITY_WEIGHTS", "")
+	_, err = PopularityConfigFromEnv()
+	assert.Error(t, err)
+}
+
+func TestRefreshListenerCountsUsesReloadedCacheTTL(t *testing.T) {
+	svc, repo, _ := newMockedService(t)
+	svc.ConfigurePopularityCacheTTL(time.Hour)
+	repo.EXPECT().GetSongsNeedingListeners(gomock.Any(), time.Hour, gomock.Any(), DefaultPopularityConfig.BatchSize).Return(nil, nil)
+
+	attempted, failed, err := svc.RefreshListenerCounts(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Zero(t, attempted)
+	assert.Empty(t, failed)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// ErrInvalidPreferences is returned when saving preferences with an unsupported value
+var ErrInvalidPreferences = errors.New("invalid preferences")
+
+// MaxPageSize bounds the default page size a user may choose
+const MaxPageSize = 100
+
+// languageTag matches a simple BCP 47 language tag such as "en" or "pt-BR"
+var languageTag = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// GetPreferences returns the preferences of the user; users who never saved any get the zero Preferences
+func (s *MusicService) GetPreferences(ctx context.Context, userID int) (models.Preferences, error) {
+	s.logger.Debug("Fetching preferences", zap.Int("user_id", userID))
+	preferences, err := s.repo.GetPreferences(ctx, userID)
+	if err == sql.ErrNoRows {
+		return models.Preferences{}, nil
+	}
+	return preferences, err
+}
+
+// UpdatePreferences validates and saves the preferences of the user, returning the saved preferences
+func (s *MusicService) UpdatePreferences(ctx context.Context, userID int, preferences models.Preferences) (models.Preferences, error) {
+	s.logger.Debug("Updating preferences", zap.Int("user_id", userID))
+	switch {
+	case preferences.PageSize < 0 || preferences.PageSize > MaxPageSize:
+		return models.Preferences{}, fmt.Errorf("%w: page_size must be between 1 and %d, or 0 for the default", ErrInvalidPreferences, MaxPageSize)
+	case preferences.Sort != "" && !repository.IsSortSupported(preferences.Sort):
+		return models.Preferences{}, fmt.Errorf("%w: unsupported sort %q", ErrInvalidPreferences, preferences.Sort)
+	case preferences.Language != "" && !languageTag.MatchString(preferences.Language):
+		return models.Preferences{}, fmt.Errorf("%w: language must be a language tag such as \"en\" or \"pt-BR\"", ErrInvalidPreferences)
+	case preferences.APICompat != "" && preferences.APICompat != models.APICompatLegacy:
+		return models.Preferences{}, fmt.Errorf("%w: api_compat must be %q or empty", ErrInvalidPreferences, models.APICompatLegacy)
+	}
+
+	if err := s.repo.SavePreferences(ctx, userID, preferences); err != nil {
+		return models.Preferences{}, err
+	}
+	s.logger.Info("Preferences updated successfully", zap.Int("user_id", userID))
+	return s.GetPreferences(ctx, userID)
+}
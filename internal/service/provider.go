@@ -0,0 +1,155 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"music-library/internal/secrets"
+)
+
+// Supported authentication schemes of the external API
+const (
+	ProviderAuthNone   = "none"
+	ProviderAuthAPIKey = "api_key"
+	ProviderAuthBearer = "bearer"
+	ProviderAuthBasic  = "basic"
+)
+
+// ProviderConfig describes how requests to the external API are authenticated
+type ProviderConfig struct {
+	// AuthType is one of the ProviderAuth* constants; empty means no authentication
+	AuthType string
+	// APIKey is sent in APIKeyHeader, or in the APIKeyParam query parameter when it is set
+	APIKey       string
+	APIKeyHeader string
+	APIKeyParam  string
+	// BearerToken is sent as "Authorization: Bearer <token>"
+	BearerToken string
+	// Username and Password are sent as HTTP basic auth
+	Username string
+	Password string
+	// Headers and Params are added to every request
+	Headers map[string]string
+	Params  map[string]string
+}
+
+// ProviderConfigFromEnv reads the provider configuration from EXTERNAL_API_* environment variables.
+// Every secret can also be read from a file named by the same variable with a _FILE suffix.
+func ProviderConfigFromEnv() (ProviderConfig, error) {
+	cfg := ProviderConfig{
+		AuthType:     os.Getenv("EXTERNAL_API_AUTH"),
+		APIKeyHeader: os.Getenv("EXTERNAL_API_KEY_HEADER"),
+		APIKeyParam:  os.Getenv("EXTERNAL_API_KEY_PARAM"),
+		Username:     os.Getenv("EXTERNAL_API_USERNAME"),
+	}
+	var err error
+	if cfg.APIKey, err = secrets.Read("EXTERNAL_API_KEY"); err != nil {
+		return cfg, err
+	}
+	if cfg.BearerToken, err = secrets.Read("EXTERNAL_API_TOKEN"); err != nil {
+		return cfg, err
+	}
+	if cfg.Password, err = secrets.Read("EXTERNAL_API_PASSWORD"); err != nil {
+		return cfg, err
+	}
+	if cfg.Headers, err = parsePairs(os.Getenv("EXTERNAL_API_HEADERS")); err != nil {
+		return cfg, fmt.Errorf("EXTERNAL_API_HEADERS: %w", err)
+	}
+	if cfg.Params, err = parsePairs(os.Getenv("EXTERNAL_API_PARAMS")); err != nil {
+		return cfg, fmt.Errorf("EXTERNAL_API_PARAMS: %w", err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks that the credentials required by the auth type are present
+func (c ProviderConfig) Validate() error {
+	switch c.AuthType {
+	case "", ProviderAuthNone:
+		return nil
+	case ProviderAuthAPIKey:
+		if c.APIKey == "" {
+			return fmt.Errorf("api_key auth requires an API key")
+		}
+	case ProviderAuthBearer:
+		if c.BearerToken == "" {
+			return fmt.Errorf("bearer auth requires a token")
+		}
+	case ProviderAuthBasic:
+		if c.Username == "" {
+			return fmt.Errorf("basic auth requires a username")
+		}
+	default:
+		return fmt.Errorf("unsupported auth type %q", c.AuthType)
+	}
+	return nil
+}
+
+// authorize adds the configured credentials, headers and query parameters to a request
+func (c ProviderConfig) authorize(req *http.Request) {
+	query := req.URL.Query()
+	for key, value := range c.Params {
+		query.Set(key, value)
+	}
+	for key, value := range c.Headers {
+		req.Header.Set(key, value)
+	}
+
+	switch c.AuthType {
+	case ProviderAuthAPIKey:
+		if c.APIKeyParam != "" {
+			query.Set(c.APIKeyParam, c.APIKey)
+		} else {
+			header := c.APIKeyHeader
+			if header == "" {
+				header = "X-API-Key"
+			}
+			req.Header.Set(header, c.APIKey)
+		}
+	case ProviderAuthBearer:
+		req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+	case ProviderAuthBasic:
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	req.URL.RawQuery = query.Encode()
+}
+
+// ConfigureProvider replaces the authentication used for external API requests
+func (s *MusicService) ConfigureProvider(cfg ProviderConfig) {
+	s.provider = cfg
+}
+
+// ConfigureExternalAPIURL moves the /info providers to another URL of the external API while the service
+// runs; an empty URL returns them to the one they were created with. Lookups already sent finish against
+// the previous URL.
+func (s *MusicService) ConfigureExternalAPIURL(apiURL string) {
+	if apiURL == "" {
+		s.externalAPIURL.Store(nil)
+		return
+	}
+	s.externalAPIURL.Store(&apiURL)
+}
+
+// parsePairs parses a comma-separated list of key=value pairs. Values are URL-unescaped,
+// so a literal comma can be written as %2C.
+func parsePairs(raw string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid pair %q, expected key=value", item)
+		}
+		value, err := url.QueryUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %q: %w", key, err)
+		}
+		pairs[strings.TrimSpace(key)] = value
+	}
+	return pairs, nil
+}
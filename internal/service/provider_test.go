@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFetchExternalDataAuthorizes(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Write([]byte(`{"release_date": "16.07.2006", "text": "Verse", "link": "https://example.com"}`))
+	}))
+	defer server.Close()
+	t.Setenv("EXTERNAL_API_URL", server.URL)
+
+	svc := NewMusicService(nil, zap.NewNop(), server.Client())
+
+	svc.ConfigureProvider(ProviderConfig{
+		AuthType:    ProviderAuthBearer,
+		BearerToken: "secret",
+		Headers:     map[string]string{"X-Client": "mus-lib"},
+		Params:      map[string]string{"region": "eu"},
+	})
+	releaseDate, _, _ := svc.fetchExternalData(context.Background(), "Muse", "Uprising")
+	assert.Equal(t, "16.07.2006", releaseDate)
+	assert.Equal(t, "Bearer secret", got.Header.Get("Authorization"))
+	assert.Equal(t, "mus-lib", got.Header.Get("X-Client"))
+	assert.Equal(t, "eu", got.URL.Query().Get("region"))
+	assert.Equal(t, "Uprising", got.URL.Query().Get("song"))
+
+	svc.ConfigureProvider(ProviderConfig{AuthType: ProviderAuthAPIKey, APIKey: "key", APIKeyParam: "api_key"})
+	svc.fetchExternalData(context.Background(), "Muse", "Uprising")
+	assert.Equal(t, "key", got.URL.Query().Get("api_key"))
+	assert.Empty(t, got.Header.Get("Authorization"))
+
+	svc.ConfigureProvider(ProviderConfig{AuthType: ProviderAuthBasic, Username: "user", Password: "pass"})
+	svc.fetchExternalData(context.Background(), "Muse", "Uprising")
+	username, password, ok := got.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "pass", password)
+}
+
+func TestProviderConfigFromEnv(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("from-file\n"), 0o600))
+
+	t.Setenv("EXTERNAL_API_AUTH", ProviderAuthBearer)
+	t.Setenv("EXTERNAL_API_TOKEN_FILE", tokenFile)
+	t.Setenv("EXTERNAL_API_HEADERS", "X-Client=mus-lib, X-Tags=a%2Cb")
+	cfg, err := ProviderConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", cfg.BearerToken)
+	assert.Equal(t, map[string]string{"X-Client": "mus-lib", "X-Tags": "a,b"}, cfg.Headers)
+
+	t.Setenv("EXTERNAL_API_TOKEN_FILE", "")
+	_, err = ProviderConfigFromEnv()
+	assert.Error(t, err, "bearer auth without a token")
+
+	t.Setenv("EXTERNAL_API_AUTH", "oauth")
+	_, err = ProviderConfigFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// ErrInvalidRating is returned when rating a song outside the 1 to 5 scale
+var ErrInvalidRating = errors.New("invalid rating")
+
+// GetSongRating returns the user's rating of the song and its average rating. sql.ErrNoRows is returned
+// when the song does not exist.
+func (s *MusicService) GetSongRating(ctx context.Context, userID, songID int) (models.SongRating, error) {
+	s.logger.Debug("Fetching song rating", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return s.repo.GetSongRating(ctx, userID, songID)
+}
+
+// RateSong creates or replaces the user's rating of the song and returns it with the song's new average
+// rating. sql.ErrNoRows is returned when the song does not exist.
+func (s *MusicService) RateSong(ctx context.Context, userID, songID, rating int) (_ models.SongRating, err error) {
+	defer metrics.ObserveOperation("rate_song", time.Now(), &err)
+	s.logger.Debug("Rating song", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Int("rating", rating))
+	if rating < models.MinRating || rating > models.MaxRating {
+		return models.SongRating{}, fmt.Errorf("%w: rating must be between %d and %d", ErrInvalidRating, models.MinRating, models.MaxRating)
+	}
+	var rated models.SongRating
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.RateSong(ctx, userID, songID, rating); err != nil {
+			return err
+		}
+		rated, err = s.repo.GetSongRating(ctx, userID, songID)
+		return err
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			s.logger.Error("Failed to rate song", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Error(err))
+		}
+		return models.SongRating{}, err
+	}
+	s.logger.Info("Song rated successfully", zap.Int("user_id", userID), zap.Int("song_id", songID), zap.Int("rating", rating))
+	return rated, nil
+}
+
+// DeleteSongRating discards the user's rating of the song and returns the song's new average rating.
+// sql.ErrNoRows is returned when the user has not rated the song.
+func (s *MusicService) DeleteSongRating(ctx context.Context, userID, songID int) (_ models.SongRating, err error) {
+	defer metrics.ObserveOperation("delete_song_rating", time.Now(), &err)
+	s.logger.Debug("Deleting song rating", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	var rating models.SongRating
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteSongRating(ctx, userID, songID); err != nil {
+			return err
+		}
+		rating, err = s.repo.GetSongRating(ctx, userID, songID)
+		return err
+	})
+	if err != nil {
+		return models.SongRating{}, err
+	}
+	s.logger.Info("Song rating deleted successfully", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return rating, nil
+}
+
+// FavoriteSong marks the song as one of the user's favorites; marking it again changes nothing.
+// sql.ErrNoRows is returned when the song does not exist.
+func (s *MusicService) FavoriteSong(ctx context.Context, userID, songID int) (_ models.SongFavorite, err error) {
+	defer metrics.ObserveOperation("favorite_song", time.Now(), &err)
+	s.logger.Debug("Marking song as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	favorite, err := s.repo.FavoriteSong(ctx, userID, songID)
+	if err != nil {
+		return models.SongFavorite{}, err
+	}
+	s.logger.Info("Song marked as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return favorite, nil
+}
+
+// UnfavoriteSong removes the song from the user's favorites, returning sql.ErrNoRows when it is not one
+func (s *MusicService) UnfavoriteSong(ctx context.Context, userID, songID int) (err error) {
+	defer metrics.ObserveOperation("unfavorite_song", time.Now(), &err)
+	s.logger.Debug("Unmarking song as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	if err := s.repo.UnfavoriteSong(ctx, userID, songID); err != nil {
+		return err
+	}
+	s.logger.Info("Song unmarked as favorite", zap.Int("user_id", userID), zap.Int("song_id", songID))
+	return nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// ratingRepository keeps the ratings of song 1 by user ID
+type ratingRepository struct {
+	repository.Repository
+	ratings map[int]int
+}
+
+func (r *ratingRepository) ConfigureStatementTimeout(time.Duration) {}
+
+func (r *ratingRepository) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *ratingRepository) RateSong(_ context.Context, userID, songID, rating int) error {
+	if songID != 1 {
+		return sql.ErrNoRows
+	}
+	r.ratings[userID] = rating
+	return nil
+}
+
+func (r *ratingRepository) GetSongRating(_ context.Context, userID, songID int) (models.SongRating, error) {
+	rating := models.SongRating{SongID: songID, RatingCount: len(r.ratings)}
+	if own, ok := r.ratings[userID]; ok {
+		rating.Rating = &own
+	}
+	if len(r.ratings) > 0 {
+		var sum float64
+		for _, value := range r.ratings {
+			sum += float64(value)
+		}
+		average := sum / float64(len(r.ratings))
+		rating.AverageRating = &average
+	}
+	return rating, nil
+}
+
+func TestRateSong(t *testing.T) {
+	repo := &ratingRepository{ratings: map[int]int{2: 5}}
+	svc := NewMusicService(repo, zap.NewNop(), nil)
+
+	for _, rating := range []int{0, 6, -1} {
+		_, err := svc.RateSong(context.Background(), 1, 1, rating)
+		assert.ErrorIs(t, err, ErrInvalidRating, "rating %d", rating)
+	}
+	assert.Empty(t, repo.ratings[1], "invalid ratings are not stored")
+
+	rating, err := svc.RateSong(context.Background(), 1, 1, 2)
+	require.NoError(t, err)
+	require.NotNil(t, rating.Rating)
+	assert.Equal(t, 2, *rating.Rating)
+	require.NotNil(t, rating.AverageRating)
+	assert.InDelta(t, 3.5, *rating.AverageRating, 0.001, "the average includes every user's rating")
+	assert.Equal(t, 2, rating.RatingCount)
+
+	_, err = svc.RateSong(context.Background(), 1, 99, 3)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
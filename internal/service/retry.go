@@ -0,0 +1,52 @@
+package service
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"music-library/internal/breaker"
+)
+
+// RetryConfig controls how transient external API failures are retried
+type RetryConfig struct {
+	// Attempts is the maximum number of calls per lookup, including the first one
+	Attempts int
+	// BaseDelay is the delay before the first retry, doubled for every further retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts
+	MaxDelay time.Duration
+}
+
+// DefaultRetryConfig is used until ConfigureResilience is called
+var DefaultRetryConfig = RetryConfig{
+	Attempts:  3,
+	BaseDelay: 200 * time.Millisecond,
+	MaxDelay:  2 * time.Second,
+}
+
+// ConfigureResilience sets how external API calls are retried and the circuit breaker guarding them;
+// zero retry values take the defaults
+func (s *MusicService) ConfigureResilience(retry RetryConfig, circuit *breaker.Breaker) {
+	if retry.Attempts < 1 {
+		retry.Attempts = DefaultRetryConfig.Attempts
+	}
+	if retry.BaseDelay <= 0 {
+		retry.BaseDelay = DefaultRetryConfig.BaseDelay
+	}
+	if retry.MaxDelay < retry.BaseDelay {
+		retry.MaxDelay = max(DefaultRetryConfig.MaxDelay, retry.BaseDelay)
+	}
+	s.retry = retry
+	s.breaker = circuit
+}
+
+// backoff returns the delay before the retry following the given attempt: the exponential delay capped
+// at MaxDelay, of which the upper half is jittered so clients failing together do not retry in lockstep
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	delay := c.MaxDelay
+	if shift := attempt - 1; shift < 30 && c.BaseDelay<<shift < c.MaxDelay {
+		delay = c.BaseDelay << shift
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
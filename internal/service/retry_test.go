@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/breaker"
+)
+
+func TestBackoff(t *testing.T) {
+	cfg := RetryConfig{Attempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for i := 0; i < 20; i++ {
+		delay := cfg.backoff(1)
+		assert.True(t, delay >= 50*time.Millisecond && delay <= 100*time.Millisecond, delay)
+		delay = cfg.backoff(2)
+		assert.True(t, delay >= 100*time.Millisecond && delay <= 200*time.Millisecond, delay)
+		delay = cfg.backoff(10)
+		assert.True(t, delay >= 150*time.Millisecond && delay <= 300*time.Millisecond, "delays are capped: %s", delay)
+	}
+}
+
+func TestFetchExternalInfoRetriesAndBreaks(t *testing.T) {
+	var calls, failures atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Query().Get("song") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"release_date": "16.07.2006", "text": "text", "link": "https://example.com"}`))
+	}))
+	defer server.Close()
+	t.Setenv("EXTERNAL_API_URL", server.URL)
+
+	svc := NewMusicService(nil, zap.NewNop(), server.Client())
+	circuit := breaker.New(ExternalAPIProvider, breaker.Config{FailureThreshold: 3, OpenTimeout: time.Hour}, zap.NewNop())
+	svc.ConfigureResilience(RetryConfig{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, circuit)
+
+	failures.Store(2)
+	info, ok := svc.fetchExternalInfo(context.Background(), "Muse", "Uprising")
+	require.True(t, ok, "transient failures are retried")
+	assert.Equal(t, "16.07.2006", info.ReleaseDate)
+	assert.Equal(t, int32(3), calls.Load())
+
+	calls.Store(0)
+	_, ok = svc.fetchExternalInfo(context.Background(), "Muse", "missing")
+	assert.False(t, ok)
+	assert.Equal(t, int32(1), calls.Load(), "answers such as not found are not retried")
+
+	calls.Store(0)
+	failures.Store(100)
+	_, ok = svc.fetchExternalInfo(context.Background(), "Muse", "Uprising")
+	assert.False(t, ok)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, breaker.Open, circuit.State(), "consecutive failures open the breaker")
+
+	calls.Store(0)
+	_, ok = svc.fetchExternalInfo(context.Background(), "Muse", "Uprising")
+	assert.False(t, ok)
+	assert.Zero(t, calls.Load(), "no call is made while the breaker is open")
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/analytics"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// reviseSong runs an update of a song within the caller's transaction and records the song as updated as
+// its next revision, whose number it returns. The first time a song is updated, the song as it was before is recorded first, so the
+// original can be restored too.
+func (s *MusicService) reviseSong(ctx context.Context, id int, reason string, restoredFrom *int, update func(ctx context.Context) error) (int, error) {
+	count, err := s.repo.CountSongRevisions(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		if _, err := s.repo.AddSongRevision(ctx, id, models.RevisionOriginal, nil); err != nil {
+			return 0, err
+		}
+	}
+	if err := update(ctx); err != nil {
+		return 0, err
+	}
+	return s.repo.AddSongRevision(ctx, id, reason, restoredFrom)
+}
+
+// GetSongRevisions returns the revisions of a song, newest first. sql.ErrNoRows is returned when the song
+// does not exist.
+func (s *MusicService) GetSongRevisions(ctx context.Context, songID int) (models.SongRevisionList, error) {
+	s.logger.Debug("Fetching song revisions", zap.Int("song_id", songID))
+	if _, err := s.repo.GetSongByID(ctx, songID); err != nil {
+		return models.SongRevisionList{}, err
+	}
+	revisions, err := s.repo.GetSongRevisions(ctx, songID)
+	if err != nil {
+		s.logger.Error("Failed to fetch song revisions", zap.Int("song_id", songID), zap.Error(err))
+		return models.SongRevisionList{}, err
+	}
+	return models.SongRevisionList{SongID: songID, Data: revisions}, nil
+}
+
+// RestoreSongRevision sets the editable fields of a song back to those of one of its revisions and returns
+// the revision this records. Restoring is itself an update, so it can be undone by restoring the revision
+// before it. sql.ErrNoRows is returned when the song or the revision does not exist.
+func (s *MusicService) RestoreSongRevision(ctx context.Context, songID, revision int) (_ models.SongRevision, err error) {
+	defer metrics.ObserveOperation("restore_song_revision", time.Now(), &err)
+	s.logger.Debug("Restoring song revision", zap.Int("song_id", songID), zap.Int("revision", revision))
+	restored, err := s.repo.GetSongRevision(ctx, songID, revision)
+	if err != nil {
+		return models.SongRevision{}, err
+	}
+	data := restored.Data
+	patch := models.SongPatch{
+		Group:         models.OptionalString{Set: true, Value: data.Group},
+		Song:          models.OptionalString{Set: true, Value: data.Song},
+		ReleaseDate:   optionalString(data.ReleaseDate),
+		Text:          optionalString(data.Text),
+		Link:          optionalString(data.Link),
+		Notes:         optionalString(data.Notes),
+		SplitStrategy: optionalString(data.SplitStrategy),
+		LicensingFee:  models.OptionalFloat{Set: true, Null: data.LicensingFee == nil},
+		AlbumID:       models.OptionalInt{Set: true, Null: data.AlbumID == nil},
+	}
+	if data.LicensingFee != nil {
+		patch.LicensingFee.Value = *data.LicensingFee
+	}
+	if data.AlbumID != nil {
+		// The album may have been deleted since, which removed the song from it as well
+		switch _, err := s.repo.GetAlbum(ctx, *data.AlbumID); {
+		case err == sql.ErrNoRows:
+			patch.AlbumID.Null = true
+		case err != nil:
+			return models.SongRevision{}, err
+		default:
+			patch.AlbumID.Value = *data.AlbumID
+		}
+	}
+
+	var current models.SongRevision
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkLegalHold(ctx, "restore_song_revision", songID); err != nil {
+			return err
+		}
+		number, err := s.reviseSong(ctx, songID, models.RevisionRestore, &revision, func(ctx context.Context) error {
+			return s.repo.UpdateSongPartial(ctx, songID, patch)
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.record(ctx, analytics.EventSongUpdated, songID, 1); err != nil {
+			return err
+		}
+		current, err = s.repo.GetSongRevision(ctx, songID, number)
+		return err
+	})
+	if err != nil {
+		if err != sql.ErrNoRows && !errors.Is(err, ErrLegalHold) {
+			s.logger.Error("Failed to restore song revision", zap.Int("song_id", songID), zap.Int("revision", revision), zap.Error(err))
+		}
+		return models.SongRevision{}, err
+	}
+	s.publish(analytics.EventSongUpdated, songID, 1)
+	s.indexVerses(ctx, songID, patch.Text.Value)
+	s.logger.Info("Song revision restored successfully", zap.Int("song_id", songID), zap.Int("revision", revision),
+		zap.Int("new_revision", current.Revision))
+	return current, nil
+}
+
+// optionalString returns a patch field setting a nullable value
+func optionalString(value *string) models.OptionalString {
+	if value == nil {
+		return models.OptionalString{Set: true, Null: true}
+	}
+	return models.OptionalString{Set: true, Value: *value}
+}
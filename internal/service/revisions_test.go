@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// revisionRepository keeps song 1 and its revisions in memory
+type revisionRepository struct {
+	repository.Repository
+	song      models.SongRevisionData
+	revisions []models.SongRevision
+}
+
+func (r *revisionRepository) ConfigureStatementTimeout(time.Duration) {}
+
+func (r *revisionRepository) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *revisionRepository) GetLegalHolds(context.Context, []int) ([]int, error) {
+	return nil, nil
+}
+
+func (r *revisionRepository) AddSongEvent(context.Context, models.SongEvent) (int64, error) {
+	return 1, nil
+}
+
+func (r *revisionRepository) SaveVerseIndex(context.Context, int, string, string, []models.VerseSpan) error {
+	return nil
+}
+
+func (r *revisionRepository) GetAlbum(_ context.Context, id int) (models.Album, error) {
+	return models.Album{}, sql.ErrNoRows
+}
+
+func (r *revisionRepository) UpdateSong(_ context.Context, id int, group, song, releaseDate, text, link string) error {
+	if id != 1 {
+		return sql.ErrNoRows
+	}
+	r.song.Group, r.song.Song, r.song.Text = group, song, &text
+	return nil
+}
+
+func (r *revisionRepository) UpdateSongPartial(_ context.Context, id int, patch models.SongPatch) error {
+	if id != 1 {
+		return sql.ErrNoRows
+	}
+	r.song.Group, r.song.Song = patch.Group.Value, patch.Song.Value
+	r.song.Text = nil
+	if !patch.Text.Null {
+		r.song.Text = &patch.Text.Value
+	}
+	r.song.AlbumID = nil
+	if !patch.AlbumID.Null {
+		r.song.AlbumID = &patch.AlbumID.Value
+	}
+	return nil
+}
+
+func (r *revisionRepository) CountSongRevisions(context.Context, int) (int, error) {
+	return len(r.revisions), nil
+}
+
+func (r *revisionRepository) AddSongRevision(_ context.Context, songID int, reason string, restoredFrom *int) (int, error) {
+	if songID != 1 {
+		return 0, sql.ErrNoRows
+	}
+	revision := models.SongRevision{SongID: songID, Revision: len(r.revisions) + 1, Reason: reason, RestoredFrom: restoredFrom, Data: r.song}
+	r.revisions = append(r.revisions, revision)
+	return revision.Revision, nil
+}
+
+func (r *revisionRepository) GetSongRevision(_ context.Context, songID, revision int) (models.SongRevision, error) {
+	if songID != 1 || revision < 1 || revision > len(r.revisions) {
+		return models.SongRevision{}, sql.ErrNoRows
+	}
+	return r.revisions[revision-1], nil
+}
+
+func TestSongRevisions(t *testing.T) {
+	original := "Ooh baby, don't you know I suffer?"
+	album := 3
+	repo := &revisionRepository{song: models.SongRevisionData{Group: "Muse", Song: "Supermassive Black Hole", Text: &original, AlbumID: &album}}
+	svc := NewMusicService(repo, zap.NewNop(), nil)
+	ctx := context.Background()
+
+	require.NoError(t, svc.UpdateSong(ctx, 1, "Muse", "Supermassive Black Hole", "16.07.2006", "vandalized", ""))
+	require.Len(t, repo.revisions, 2, "the first update records the original too")
+	assert.Equal(t, models.RevisionOriginal, repo.revisions[0].Reason)
+	assert.Equal(t, original, *repo.revisions[0].Data.Text)
+	assert.Equal(t, models.RevisionUpdate, repo.revisions[1].Reason)
+	assert.Equal(t, "vandalized", *repo.revisions[1].Data.Text)
+
+	restored, err := svc.RestoreSongRevision(ctx, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, restored.Revision)
+	assert.Equal(t, models.RevisionRestore, restored.Reason)
+	require.NotNil(t, restored.RestoredFrom)
+	assert.Equal(t, 1, *restored.RestoredFrom)
+	assert.Equal(t, original, *repo.song.Text, "the text is back to the original")
+	assert.Nil(t, repo.song.AlbumID, "a deleted album is not restored")
+
+	_, err = svc.RestoreSongRevision(ctx, 1, 9)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Len(t, repo.revisions, 3, "a failed restore records nothing")
+}
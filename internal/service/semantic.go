@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/embeddings"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// ErrSemanticSearchUnavailable is returned when no embedding provider is configured or pgvector is not installed
+var ErrSemanticSearchUnavailable = errors.New("semantic search is not available")
+
+// ErrUnsupportedSearchMode is returned when a client requests an unknown search mode
+var ErrUnsupportedSearchMode = errors.New("unsupported search mode")
+
+// Search modes
+const (
+	SearchModeKeyword  = "keyword"
+	SearchModeSemantic = "semantic"
+	SearchModeHybrid   = "hybrid"
+)
+
+// Semantic search parameters: the share of the keyword rank in hybrid scores,
+// and how many songs are embedded per provider call
+const (
+	hybridKeywordWeight = 0.3
+	embeddingBatchSize  = 32
+)
+
+// ConfigureEmbeddings sets the provider used to embed lyrics and search queries
+func (s *MusicService) ConfigureEmbeddings(embedder embeddings.Embedder) {
+	s.embedder = embedder
+}
+
+// SearchSongs finds the songs best matching the query. The keyword mode uses full-text search over titles,
+// groups and lyrics; the semantic mode ranks songs by the similarity of their lyrics embedding to the query
+// embedding; the hybrid mode blends the semantic similarity with the keyword rank. Results are served from
+// the warm cache while the database is unreachable.
+func (s *MusicService) SearchSongs(ctx context.Context, query, mode string, limit int) (_ []models.SearchResult, err error) {
+	defer metrics.ObserveOperation("search_songs", time.Now(), &err)
+	key := fmt.Sprintf("search:%s:%d:%s", mode, limit, query)
+	return cachedRead(ctx, s, key, cloneSearchResults, func() ([]models.SearchResult, error) {
+		return s.searchSongs(ctx, query, mode, limit)
+	})
+}
+
+// searchSongs runs the search in the database
+func (s *MusicService) searchSongs(ctx context.Context, query, mode string, limit int) ([]models.SearchResult, error) {
+	s.logger.Debug("Searching songs", zap.String("query", query), zap.String("mode", mode))
+	var keywordWeight float64
+	switch mode {
+	case SearchModeKeyword:
+		results, err := s.repo.SearchSongs(ctx, query, limit)
+		if err != nil {
+			s.logger.Error("Failed to search songs", zap.Error(err))
+			return nil, err
+		}
+		s.logger.Info("Songs searched successfully", zap.String("mode", mode), zap.Int("count", len(results)))
+		return results, nil
+	case SearchModeSemantic:
+	case SearchModeHybrid:
+		keywordWeight = hybridKeywordWeight
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSearchMode, mode)
+	}
+	if err := s.semanticSearchAvailable(ctx); err != nil {
+		return nil, err
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, s.timeouts.ExternalAPI)
+	defer cancel()
+	vectors, err := s.embedder.Embed(callCtx, []string{query})
+	if err != nil {
+		s.logger.Error("Failed to embed search query", zap.Error(err))
+		return nil, err
+	}
+
+	results, err := s.repo.SearchSongsSemantic(ctx, s.embedder.Model(), vectors[0], query, keywordWeight, limit)
+	if err != nil {
+		s.logger.Error("Failed to search songs", zap.Error(err))
+		return nil, err
+	}
+	s.logger.Info("Songs searched successfully", zap.String("mode", mode), zap.Int("count", len(results)))
+	return results, nil
+}
+
+// semanticSearchAvailable checks that an embedder is configured and the embeddings table exists
+func (s *MusicService) semanticSearchAvailable(ctx context.Context) error {
+	if s.embedder == nil {
+		return fmt.Errorf("%w: no embedding provider configured", ErrSemanticSearchUnavailable)
+	}
+	exists, err := s.repo.HasSongEmbeddings(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: pgvector is not installed", ErrSemanticSearchUnavailable)
+	}
+	return nil
+}
+
+// EmbedSongs computes embeddings for songs that have none from the current model or whose content changed,
+// in batches until every song is embedded. It returns the number of songs embedded.
+func (s *MusicService) EmbedSongs(ctx context.Context) (int, error) {
+	if err := s.semanticSearchAvailable(ctx); err != nil {
+		return 0, err
+	}
+	model := s.embedder.Model()
+	embedded := 0
+	for ctx.Err() == nil {
+		songs, err := s.repo.GetSongsNeedingEmbedding(ctx, model, embeddingBatchSize)
+		if err != nil || len(songs) == 0 {
+			return embedded, err
+		}
+		texts := make([]string, len(songs))
+		for i, song := range songs {
+			texts[i] = strings.Join([]string{song.Group, song.Song, models.StringValue(song.Text)}, "\n")
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, s.timeouts.ExternalAPI)
+		vectors, err := s.embedder.Embed(callCtx, texts)
+		cancel()
+		if err != nil {
+			s.logger.Error("Failed to embed songs", zap.Int("songs", len(songs)), zap.Error(err))
+			return embedded, err
+		}
+		for i, song := range songs {
+			if err := s.repo.SaveSongEmbedding(ctx, song.ID, model, repository.SongContentHash(song), vectors[i]); err != nil {
+				return embedded, err
+			}
+			embedded++
+		}
+	}
+	return embedded, ctx.Err()
+}
+
+// StartEmbeddingScheduler embeds new and edited songs immediately and then every interval until ctx is cancelled
+func (s *MusicService) StartEmbeddingScheduler(ctx context.Context, interval time.Duration) {
+	s.logger.Info("Starting embedding scheduler", zap.Duration("interval", interval))
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if embedded, err := s.EmbedSongs(ctx); err != nil {
+				s.logger.Error("Failed to embed songs", zap.Error(err))
+			} else if embedded > 0 {
+				s.logger.Info("Songs embedded", zap.Int("count", embedded))
+			}
+			select {
+			case <-ctx.Done():
+				s.logger.Info("Embedding scheduler stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
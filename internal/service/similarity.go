@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+	"music-library/internal/jobs"
+	"music-library/internal/models"
+)
+
+// ErrReportRunning is returned when a similarity report is requested while another one is being computed
+var ErrReportRunning = errors.New("similarity report already running")
+
+// ErrNoReport is returned when no similarity report has been computed yet
+var ErrNoReport = errors.New("no similarity report")
+
+// MinHash/LSH parameters. Lyrics are compared as sets of 3-word shingles; each song gets a
+// 128-value MinHash signature split into 32 bands of 4 rows, so pairs with a Jaccard similarity
+// around 0.42 and above are likely to share a band and become candidates.
+const (
+	shingleSize      = 3
+	minHashSize      = 128
+	lshBands         = 32
+	lshRows          = minHashSize / lshBands
+	maxLSHBucketSize = 200
+	maxReportedPairs = 1000
+)
+
+// DefaultSimilarityThreshold is the estimated similarity from which song pairs are reported
+const DefaultSimilarityThreshold = 0.8
+
+// SimilarityReportResult is the outcome of a finished similarity report job; the report itself is
+// available from SimilarityReport
+type SimilarityReportResult struct {
+	SongsScanned int `json:"songs_scanned"`
+	Pairs        int `json:"pairs"`
+}
+
+// StartSimilarityReport queues a background job computing a new lyrics similarity report. The job is
+// cancelled at shutdown like any other. Only one report runs at a time; the latest one is available
+// from SimilarityReport and the job's status from GetJob.
+func (s *MusicService) StartSimilarityReport(ctx context.Context, threshold float64) (*models.SimilarityReport, error) {
+	if s.jobs == nil {
+		return nil, ErrJobsUnavailable
+	}
+	s.similarityMu.Lock()
+	defer s.similarityMu.Unlock()
+	if s.similarity != nil && s.similarity.Status == models.SimilarityReportRunning {
+		return nil, ErrReportRunning
+	}
+	startedAt := time.Now()
+
+	// The lock is held until the running report is set, so a job finishing early cannot be overwritten by it
+	job, err := s.jobs.SubmitExclusive(ctx, models.JobKindSimilarityReport, func(ctx context.Context, _ *jobs.Progress) (any, error) {
+		result := s.buildSimilarityReport(ctx, threshold)
+		result.JobID = s.currentSimilarityJob()
+		result.StartedAt = startedAt
+		finished := time.Now()
+		result.FinishedAt = &finished
+		s.similarityMu.Lock()
+		s.similarity = result
+		s.similarityMu.Unlock()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if result.Status != models.SimilarityReportCompleted {
+			return nil, errors.New(result.Error)
+		}
+		return &SimilarityReportResult{SongsScanned: result.SongsScanned, Pairs: len(result.Pairs)}, nil
+	})
+	if errors.Is(err, jobs.ErrAlreadyRunning) {
+		return nil, ErrReportRunning
+	}
+	if err != nil {
+		s.logger.Error("Failed to queue similarity report", zap.Error(err))
+		return nil, err
+	}
+
+	report := &models.SimilarityReport{JobID: job.ID, Status: models.SimilarityReportRunning, Threshold: threshold, StartedAt: startedAt}
+	s.similarity = report
+	snapshot := *report
+	s.logger.Info("Similarity report queued", zap.String("job_id", job.ID))
+	return &snapshot, nil
+}
+
+// currentSimilarityJob returns the job of the latest similarity report
+func (s *MusicService) currentSimilarityJob() string {
+	s.similarityMu.Lock()
+	defer s.similarityMu.Unlock()
+	if s.similarity == nil {
+		return ""
+	}
+	return s.similarity.JobID
+}
+
+// SimilarityReport returns the latest similarity report, which may still be running
+func (s *MusicService) SimilarityReport() (*models.SimilarityReport, error) {
+	s.similarityMu.Lock()
+	defer s.similarityMu.Unlock()
+	if s.similarity == nil {
+		return nil, ErrNoReport
+	}
+	report := *s.similarity
+	return &report, nil
+}
+
+// buildSimilarityReport loads every song with lyrics and finds the pairs at or above the threshold
+func (s *MusicService) buildSimilarityReport(ctx context.Context, threshold float64) *models.SimilarityReport {
+	s.logger.Info("Building lyrics similarity report", zap.Float64("threshold", threshold))
+	report := &models.SimilarityReport{Threshold: threshold, Pairs: []models.SimilarityPair{}}
+	songs, err := s.repo.GetSongsWithLyrics(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return interruptedSimilarityReport(report)
+		}
+		s.logger.Error("Failed to fetch songs for similarity report", zap.Error(err))
+		report.Status = models.SimilarityReportFailed
+		report.Error = "failed to fetch songs"
+		return report
+	}
+
+	signatures := make([][minHashSize]uint64, len(songs))
+	for i, song := range songs {
+		if ctx.Err() != nil {
+			return interruptedSimilarityReport(report)
+		}
+		signatures[i] = minHashSignature(shingles(models.StringValue(song.Text)))
+	}
+	candidates, skipped := lshCandidates(signatures)
+	report.SongsScanned = len(songs)
+	report.CandidatePairs = len(candidates)
+	report.SkippedBuckets = skipped
+
+	for pair := range candidates {
+		if ctx.Err() != nil {
+			return interruptedSimilarityReport(report)
+		}
+		similarity := signatureSimilarity(signatures[pair[0]], signatures[pair[1]])
+		if similarity < threshold {
+			continue
+		}
+		report.Pairs = append(report.Pairs, models.SimilarityPair{
+			First:      songSummary(songs[pair[0]]),
+			Second:     songSummary(songs[pair[1]]),
+			Similarity: similarity,
+		})
+	}
+	sort.Slice(report.Pairs, func(i, j int) bool {
+		a, b := report.Pairs[i], report.Pairs[j]
+		if a.Similarity != b.Similarity {
+			return a.Similarity > b.Similarity
+		}
+		if a.First.ID != b.First.ID {
+			return a.First.ID < b.First.ID
+		}
+		return a.Second.ID < b.Second.ID
+	})
+	if len(report.Pairs) > maxReportedPairs {
+		report.Pairs = report.Pairs[:maxReportedPairs]
+		report.PairsTruncated = true
+	}
+
+	report.Status = models.SimilarityReportCompleted
+	s.logger.Info("Lyrics similarity report built", zap.Int("songs", len(songs)),
+		zap.Int("candidates", len(candidates)), zap.Int("pairs", len(report.Pairs)))
+	return report
+}
+
+// interruptedSimilarityReport marks a report whose computation was cancelled, dropping its partial pairs
+func interruptedSimilarityReport(report *models.SimilarityReport) *models.SimilarityReport {
+	report.Status = models.SimilarityReportFailed
+	report.Error = "interrupted by shutdown"
+	report.Pairs = []models.SimilarityPair{}
+	return report
+}
+
+// songSummary returns the identifying fields of a song
+func songSummary(song models.Song) models.SongSummary {
+	return models.SongSummary{ID: song.ID, Group: song.Group, Song: song.Song}
+}
+
+// shingles returns the hashes of the overlapping word n-grams of the normalized lyrics.
+// Lyrics shorter than a shingle form a single shingle.
+func shingles(text string) map[uint64]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	result := make(map[uint64]struct{})
+	add := func(shingle []string) {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(shingle, " ")))
+		result[h.Sum64()] = struct{}{}
+	}
+	if len(words) > 0 && len(words) < shingleSize {
+		add(words)
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		add(words[i : i+shingleSize])
+	}
+	return result
+}
+
+// minHashSignature keeps, for each of the hash functions, the minimum hash over the shingles.
+// The hash functions are the shingle hash mixed with a different seed each.
+func minHashSignature(shingles map[uint64]struct{}) [minHashSize]uint64 {
+	var signature [minHashSize]uint64
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+	for shingle := range shingles {
+		for i := range signature {
+			if h := mix64(shingle ^ mix64(uint64(i)+1)); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+	return signature
+}
+
+// mix64 is the splitmix64 finalizer, a fast hash with good avalanche
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// lshCandidates groups signatures by band and returns the index pairs sharing at least one band.
+// Buckets larger than maxLSHBucketSize (typically placeholder lyrics shared by many songs) are skipped
+// to keep the comparison count bounded; their number is returned.
+func lshCandidates(signatures [][minHashSize]uint64) (map[[2]int]struct{}, int) {
+	candidates := make(map[[2]int]struct{})
+	skipped := 0
+	for band := 0; band < lshBands; band++ {
+		buckets := make(map[uint64][]int)
+		for i, signature := range signatures {
+			h := fnv.New64a()
+			var buf [8]byte
+			for _, value := range signature[band*lshRows : (band+1)*lshRows] {
+				binary.LittleEndian.PutUint64(buf[:], value)
+				h.Write(buf[:])
+			}
+			key := h.Sum64()
+			buckets[key] = append(buckets[key], i)
+		}
+		for _, bucket := range buckets {
+			if len(bucket) > maxLSHBucketSize {
+				skipped++
+				continue
+			}
+			for i := 0; i < len(bucket); i++ {
+				for j := i + 1; j < len(bucket); j++ {
+					candidates[[2]int{bucket[i], bucket[j]}] = struct{}{}
+				}
+			}
+		}
+	}
+	return candidates, skipped
+}
+
+// signatureSimilarity estimates the Jaccard similarity of two shingle sets from their signatures
+func signatureSimilarity(a, b [minHashSize]uint64) float64 {
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / minHashSize
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"music-library/internal/jobs"
+	"music-library/internal/models"
+)
+
+const similarityLyrics = "Ooh baby, don't you know I suffer? Ooh baby, can you hear me moan? " +
+	"You caught me under false pretenses, how long before you let me go? " +
+	"You set my soul alight, glaciers melting in the dead of night"
+
+func TestSignatureSimilarity(t *testing.T) {
+	original := minHashSignature(shingles(similarityLyrics))
+	edited := minHashSignature(shingles(similarityLyrics + ", and the superstars sucked into the supermassive"))
+	unrelated := minHashSignature(shingles("Is this the real life? Is this just fantasy? Caught in a landslide, no escape from reality"))
+
+	assert.Equal(t, 1.0, signatureSimilarity(original, original))
+	assert.Greater(t, signatureSimilarity(original, edited), 0.6)
+	assert.Less(t, signatureSimilarity(original, unrelated), 0.1)
+}
+
+func TestShinglesNormalizeText(t *testing.T) {
+	assert.Equal(t, shingles("Hear me MOAN!"), shingles("hear me, moan"))
+	assert.Len(t, shingles("short"), 1)
+	assert.Empty(t, shingles(" ... "))
+}
+
+func TestLSHCandidates(t *testing.T) {
+	signatures := [][minHashSize]uint64{
+		minHashSignature(shingles(similarityLyrics)),
+		minHashSignature(shingles("Is this the real life? Is this just fantasy? Caught in a landslide, no escape from reality")),
+		minHashSignature(shingles("Ooh BABY, don't you know I suffer! " + similarityLyrics[35:])),
+	}
+	candidates, skipped := lshCandidates(signatures)
+	assert.Contains(t, candidates, [2]int{0, 2})
+	assert.NotContains(t, candidates, [2]int{0, 1})
+	assert.Zero(t, skipped)
+}
+
+// TestSimilarityReportStopsAtShutdown cancels the jobs while the report is scanning the songs: the job
+// must give up instead of holding the shutdown until the scan ends.
+func TestSimilarityReportStopsAtShutdown(t *testing.T) {
+	svc, repo, _ := newMockedService(t)
+	ctx, shutdown := context.WithCancel(context.Background())
+	defer shutdown()
+
+	var finished models.Job
+	repo.EXPECT().FailUnfinishedJobs(gomock.Any(), gomock.Any()).Return(int64(0), nil)
+	repo.EXPECT().CreateJob(gomock.Any(), gomock.Any(), models.JobKindSimilarityReport, nil).
+		DoAndReturn(func(_ context.Context, id, kind string, _ *int) (models.Job, error) {
+			return models.Job{ID: id, Kind: kind, Status: models.JobQueued}, nil
+		})
+	repo.EXPECT().StartJob(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().UpdateJobProgress(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+	repo.EXPECT().FinishJob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id, status string, _, _, _ int, _ []byte, _ string) error {
+			finished = models.Job{ID: id, Status: status}
+			return nil
+		})
+	songs := make([]models.Song, 1000)
+	for i := range songs {
+		text := similarityLyrics
+		songs[i] = models.Song{ID: i + 1, Text: &text}
+	}
+	repo.EXPECT().GetSongsWithLyrics(gomock.Any()).DoAndReturn(func(context.Context) ([]models.Song, error) {
+		shutdown()
+		return songs, nil
+	})
+
+	manager := jobs.NewManager(repo, svc.logger, jobs.Config{})
+	manager.Start(ctx)
+	svc.ConfigureJobs(manager)
+	started, err := svc.StartSimilarityReport(context.Background(), DefaultSimilarityThreshold)
+	require.NoError(t, err)
+	assert.Equal(t, models.SimilarityReportRunning, started.Status)
+	manager.Wait()
+
+	report, err := svc.SimilarityReport()
+	require.NoError(t, err)
+	assert.Equal(t, models.SimilarityReportFailed, report.Status)
+	assert.Equal(t, started.JobID, report.JobID)
+	assert.Empty(t, report.Pairs)
+	assert.Equal(t, models.Job{ID: started.JobID, Status: models.JobFailed}, finished)
+}
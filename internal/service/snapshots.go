@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/analytics"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// ErrCatalogNotEmpty is returned when restoring a snapshot while the catalog holds songs, whose IDs the
+// restored songs could take
+var ErrCatalogNotEmpty = errors.New("the catalog is not empty")
+
+// GetSnapshots returns the snapshots taken before destructive changes, newest first
+func (s *MusicService) GetSnapshots(ctx context.Context) ([]models.Snapshot, error) {
+	s.logger.Debug("Fetching snapshots")
+	return s.repo.GetSnapshots(ctx)
+}
+
+// RestoreSnapshot puts the songs of a snapshot back into the empty catalog with their IDs, and returns their
+// number. ErrCatalogNotEmpty is returned when the catalog holds songs, and sql.ErrNoRows when the snapshot
+// does not exist.
+func (s *MusicService) RestoreSnapshot(ctx context.Context, id int) (restored int, err error) {
+	defer metrics.ObserveOperation("restore_snapshot", time.Now(), &err)
+	s.logger.Debug("Restoring snapshot", zap.Int("id", id))
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		count, err := s.repo.CountSongs(ctx, models.SongFilter{})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrCatalogNotEmpty
+		}
+		if restored, err = s.repo.RestoreSnapshot(ctx, id); err != nil {
+			return err
+		}
+		return s.record(ctx, analytics.EventSongsImported, 0, restored)
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.publish(analytics.EventSongsImported, 0, restored)
+	s.logger.Info("Snapshot restored successfully", zap.Int("id", id), zap.Int("songs", restored))
+	return restored, nil
+}
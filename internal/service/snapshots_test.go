@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// snapshotRepository holds songs and fails to take snapshots
+type snapshotRepository struct {
+	repository.Repository
+	songs     int
+	truncated bool
+}
+
+func (*snapshotRepository) ConfigureStatementTimeout(time.Duration) {}
+
+func (*snapshotRepository) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (*snapshotRepository) CountLegalHolds(context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r *snapshotRepository) CountSongs(context.Context, models.SongFilter) (int, error) {
+	return r.songs, nil
+}
+
+func (*snapshotRepository) CreateSnapshot(context.Context, string) (models.Snapshot, error) {
+	return models.Snapshot{}, errors.New("disk full")
+}
+
+func (r *snapshotRepository) TruncateSongs(context.Context) error {
+	r.truncated = true
+	return nil
+}
+
+func TestTruncateSongsKeepsSongsWhenBackupFails(t *testing.T) {
+	repo := &snapshotRepository{songs: 3}
+	svc := NewMusicService(repo, zap.NewNop(), nil)
+	_, err := svc.TruncateSongs(context.Background(), true)
+	assert.Error(t, err)
+	assert.False(t, repo.truncated)
+}
+
+func TestRestoreSnapshotRequiresEmptyCatalog(t *testing.T) {
+	svc := NewMusicService(&snapshotRepository{songs: 3}, zap.NewNop(), nil)
+	_, err := svc.RestoreSnapshot(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrCatalogNotEmpty)
+}
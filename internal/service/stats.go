@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// DefaultStatsCacheTTL is how long group stats are served from memory until ConfigureStatsCache is called
+const DefaultStatsCacheTTL = time.Minute
+
+// groupStatsTopSongs is the number of most viewed songs listed in group stats
+const groupStatsTopSongs = 5
+
+// statsCache remembers recently computed group stats, keyed by the lowercase group name
+type statsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]statsEntry
+}
+
+// statsEntry holds the stats of a group until they expire
+type statsEntry struct {
+	stats   models.GroupStats
+	expires time.Time
+}
+
+// ConfigureStatsCache sets how long group stats are served from memory; zero or less disables the cache
+func (s *MusicService) ConfigureStatsCache(ttl time.Duration) {
+	s.stats.mu.Lock()
+	defer s.stats.mu.Unlock()
+	s.stats.ttl = ttl
+	s.stats.entries = make(map[string]statsEntry)
+}
+
+// GetGroupStats summarizes the songs of a group: how many there are, their release span, total views and most
+// viewed songs. Stats are cached briefly, so they may lag behind the catalog. It returns sql.ErrNoRows when the
+// group has no songs.
+func (s *MusicService) GetGroupStats(ctx context.Context, group string) (_ models.GroupStats, err error) {
+	defer metrics.ObserveOperation("get_group_stats", time.Now(), &err)
+	key := strings.ToLower(group)
+	if stats, ok := s.stats.get(key); ok {
+		return stats, nil
+	}
+
+	stats, err := s.repo.GetGroupStats(ctx, group)
+	if err != nil {
+		return stats, err
+	}
+	stats.MostViewed, err = s.repo.GetMostViewedGroupSongs(ctx, group, groupStatsTopSongs)
+	if err != nil {
+		return stats, err
+	}
+	s.stats.put(key, stats)
+	s.logger.Info("Group stats computed", zap.String("group", stats.Group), zap.Int("songs", stats.Songs))
+	return stats, nil
+}
+
+// get returns the unexpired stats cached under the key
+func (c *statsCache) get(key string) (models.GroupStats, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return models.GroupStats{}, false
+	}
+	// Callers may rewrite the songs, e.g. their date format, so they get their own copy
+	stats := entry.stats
+	stats.MostViewed = append([]models.Song(nil), stats.MostViewed...)
+	return stats, true
+}
+
+// put caches the stats under the key, first dropping the expired entries so the cache only holds the groups
+// looked up within the TTL
+func (c *statsCache) put(key string, stats models.GroupStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ttl <= 0 {
+		return
+	}
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = statsEntry{stats: stats, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// streamBatchSize is the number of songs read from the database at a time by a stream
+const streamBatchSize = 500
+
+// StreamSongs calls yield with every song matching the filter, in ID order and with its release date in the
+// requested format. Songs are read a batch at a time, so memory use does not depend on the catalog size.
+// It stops at the first error, from the database or yield, and returns the number of songs yielded.
+func (s *MusicService) StreamSongs(ctx context.Context, filter models.SongFilter, dateFormat string, yield func(models.Song) error) (count int, err error) {
+	defer metrics.ObserveOperation("stream_songs", time.Now(), &err)
+	for _, field := range filter.Missing {
+		if !repository.IsNullableField(field) {
+			return 0, fmt.Errorf("%w: %s", ErrUnsupportedField, field)
+		}
+	}
+
+	it := repository.NewSongIterator(s.repo, filter, streamBatchSize)
+	for it.Next(ctx) {
+		song := it.Song()
+		FormatSongDate(&song, dateFormat)
+		if err := yield(song); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := it.Err(); err != nil {
+		s.logger.Error("Failed to stream songs", zap.Int("streamed", count), zap.Error(err))
+		return count, err
+	}
+	return count, nil
+}
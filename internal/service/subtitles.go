@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// Supported subtitle formats
+const (
+	SubtitleFormatSRT = "srt"
+	SubtitleFormatVTT = "vtt"
+)
+
+// ErrUnsupportedSubtitleFormat is returned when subtitles are requested in a format other than SRT or WebVTT
+var ErrUnsupportedSubtitleFormat = errors.New("unsupported subtitle format")
+
+// ErrNoTimestamps is returned when subtitles are requested for a song whose lyrics carry no LRC timestamps
+var ErrNoTimestamps = errors.New("lyrics have no LRC timestamps")
+
+// lastCueDuration is how long the last line is shown, as no later timestamp ends it
+const lastCueDuration = 5 * time.Second
+
+// lrcTimestamp matches an LRC time tag such as [01:23.45] at the start of a line
+var lrcTimestamp = regexp.MustCompile(`^\[(\d+):(\d{1,2})(?:[.:](\d{1,3}))?\]`)
+
+// lrcOffset matches the LRC offset tag, in milliseconds, shifting every timestamp
+var lrcOffset = regexp.MustCompile(`(?mi)^[ \t]*\[offset:[ \t]*([+-]?\d+)[ \t]*\]`)
+
+// lrcWordTimestamp matches the word timestamps of enhanced LRC, such as <01:23.45>
+var lrcWordTimestamp = regexp.MustCompile(`<\d+:\d{1,2}(?:[.:]\d{1,3})?>`)
+
+// subtitleCue is a line of lyrics shown from Start until End
+type subtitleCue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// GetSubtitles renders the LRC timed lyrics of a song as an SRT or WebVTT subtitle file
+func (s *MusicService) GetSubtitles(ctx context.Context, songID int, format string) (_ string, err error) {
+	defer metrics.ObserveOperation("get_subtitles", time.Now(), &err)
+	s.logger.Debug("Building subtitles for song", zap.Int("song_id", songID), zap.String("format", format))
+	if format != SubtitleFormatSRT && format != SubtitleFormatVTT {
+		s.logger.Warn("Unsupported subtitle format requested", zap.String("format", format))
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedSubtitleFormat, format)
+	}
+	song, err := s.songByID(ctx, songID)
+	if err != nil {
+		s.logger.Error("Failed to fetch song", zap.Int("song_id", songID), zap.Error(err))
+		return "", err
+	}
+
+	cues := parseLRC(models.StringValue(song.Text))
+	if len(cues) == 0 {
+		s.logger.Warn("Song has no timed lyrics", zap.Int("song_id", songID))
+		return "", fmt.Errorf("%w: song %d", ErrNoTimestamps, songID)
+	}
+	s.logger.Info("Subtitles built successfully", zap.Int("song_id", songID), zap.Int("cues", len(cues)))
+	if format == SubtitleFormatVTT {
+		return formatVTT(cues), nil
+	}
+	return formatSRT(cues), nil
+}
+
+// parseLRC reads the timed lines of LRC lyrics. A line may carry several timestamps, repeating it at each;
+// lines without one, such as the [ar:] and [ti:] metadata tags, are skipped. Every cue ends where the next
+// begins, so an empty timed line only ends the line before it.
+func parseLRC(text string) []subtitleCue {
+	var offset time.Duration
+	if match := lrcOffset.FindStringSubmatch(text); match != nil {
+		ms, _ := strconv.Atoi(match[1])
+		offset = time.Duration(ms) * time.Millisecond
+	}
+
+	var cues []subtitleCue
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		var starts []time.Duration
+		for {
+			match := lrcTimestamp.FindStringSubmatch(line)
+			if match == nil {
+				break
+			}
+			starts = append(starts, lrcTime(match[1], match[2], match[3])-offset)
+			line = strings.TrimSpace(line[len(match[0]):])
+		}
+		line = strings.Join(strings.Fields(lrcWordTimestamp.ReplaceAllString(line, "")), " ")
+		for _, start := range starts {
+			cues = append(cues, subtitleCue{Start: max(start, 0), Text: line})
+		}
+	}
+	sort.SliceStable(cues, func(i, j int) bool { return cues[i].Start < cues[j].Start })
+
+	timed := make([]subtitleCue, 0, len(cues))
+	for i, cue := range cues {
+		if cue.Text == "" {
+			continue
+		}
+		cue.End = cue.Start + lastCueDuration
+		for _, next := range cues[i+1:] {
+			if next.Start > cue.Start {
+				cue.End = next.Start
+				break
+			}
+		}
+		timed = append(timed, cue)
+	}
+	return timed
+}
+
+// lrcTime converts the minutes, seconds and fraction of an LRC timestamp. The fraction is read as
+// decimal digits, so both hundredths and milliseconds are supported.
+func lrcTime(minutes, seconds, fraction string) time.Duration {
+	m, _ := strconv.Atoi(minutes)
+	sec, _ := strconv.Atoi(seconds)
+	ms := 0
+	if fraction != "" {
+		ms, _ = strconv.Atoi((fraction + "00")[:3])
+	}
+	return time.Duration(m)*time.Minute + time.Duration(sec)*time.Second + time.Duration(ms)*time.Millisecond
+}
+
+// formatSRT renders the cues as a SubRip file
+func formatSRT(cues []subtitleCue) string {
+	var b strings.Builder
+	for i, cue := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, subtitleTime(cue.Start, ","), subtitleTime(cue.End, ","), cue.Text)
+	}
+	return b.String()
+}
+
+// formatVTT renders the cues as a WebVTT file, escaping the characters that start cue markup
+func formatVTT(cues []subtitleCue) string {
+	escape := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", subtitleTime(cue.Start, "."), subtitleTime(cue.End, "."), escape.Replace(cue.Text))
+	}
+	return b.String()
+}
+
+// subtitleTime formats a cue time as HH:MM:SS followed by the separator and milliseconds
+func subtitleTime(d time.Duration, separator string) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, separator, ms%1000)
+}
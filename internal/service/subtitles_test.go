@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLRC(t *testing.T) {
+	lyrics := "[ar:Muse]\n[ti:Uprising]\n[offset:+500]\n" +
+		"[00:10.50]Paranoia is in bloom\n" +
+		"[00:14.00][00:30.000]The <00:14.50>PR transmissions\n" +
+		"[00:20.00]\n" +
+		"Untimed line\n" +
+		"[00:25.5]Will resume"
+
+	cues := parseLRC(lyrics)
+	require.Len(t, cues, 4)
+	assert.Equal(t, subtitleCue{Start: 10 * time.Second, End: 13500 * time.Millisecond, Text: "Paranoia is in bloom"}, cues[0])
+	assert.Equal(t, subtitleCue{Start: 13500 * time.Millisecond, End: 19500 * time.Millisecond, Text: "The PR transmissions"}, cues[1], "an empty timed line ends the previous one")
+	assert.Equal(t, subtitleCue{Start: 25 * time.Second, End: 29500 * time.Millisecond, Text: "Will resume"}, cues[2])
+	assert.Equal(t, subtitleCue{Start: 29500 * time.Millisecond, End: 29500*time.Millisecond + lastCueDuration, Text: "The PR transmissions"}, cues[3], "repeated timestamps repeat the line")
+
+	assert.Empty(t, parseLRC("Verse 1\n\n[Chorus]\nVerse 2"))
+}
+
+func TestFormatSubtitles(t *testing.T) {
+	cues := []subtitleCue{
+		{Start: 1234 * time.Millisecond, End: time.Hour + 2*time.Minute + 3*time.Second, Text: "Rock & <roll>"},
+	}
+	assert.Equal(t, "1\n00:00:01,234 --> 01:02:03,000\nRock & <roll>\n\n", formatSRT(cues))
+	assert.Equal(t, "WEBVTT\n\n00:00:01.234 --> 01:02:03.000\nRock &amp; &lt;roll&gt;\n\n", formatVTT(cues))
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// maxSearchSuggestions caps the corrected queries offered for a search
+const maxSearchSuggestions = 5
+
+// minSuggestionWordLength is the shortest query word corrected against lyric terms, matching the terms kept
+const minSuggestionWordLength = 3
+
+// SuggestSearches offers "did you mean" queries for a search that found nothing: group and song names
+// spelled like the query, and the query with misspelled words replaced by popular lyric terms.
+// It returns nothing where pg_trgm is not installed.
+func (s *MusicService) SuggestSearches(ctx context.Context, query string) (_ []models.SearchSuggestion, err error) {
+	defer metrics.ObserveOperation("suggest_searches", time.Now(), &err)
+	available, err := s.repo.HasSearchSuggestions(ctx)
+	if err != nil || !available {
+		return nil, err
+	}
+
+	suggestions, err := s.repo.SimilarSongNames(ctx, query, maxSearchSuggestions)
+	if err != nil {
+		return nil, err
+	}
+	words := searchWords(query)
+	if len(words) > 0 {
+		terms, err := s.repo.SimilarSearchTerms(ctx, words)
+		if err != nil {
+			return nil, err
+		}
+		if corrected, ok := correctWords(words, terms); ok {
+			suggestions = append(suggestions, corrected)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].Score > suggestions[j].Score })
+	seen := map[string]bool{strings.ToLower(query): true}
+	result := []models.SearchSuggestion{}
+	for _, suggestion := range suggestions {
+		key := strings.ToLower(suggestion.Query)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, suggestion)
+		if len(result) == maxSearchSuggestions {
+			break
+		}
+	}
+	s.logger.Debug("Search suggestions computed", zap.String("query", query), zap.Int("count", len(result)))
+	return result, nil
+}
+
+// searchWords splits a query into the lowercase words full-text search indexes, skipping the short ones
+func searchWords(query string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) >= minSuggestionWordLength {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// correctWords rebuilds the query from its words with the corrected ones replaced. Its score is the mean
+// similarity of the words, counting unchanged words as exact; ok is false when no word was corrected.
+func correctWords(words []string, terms map[string]models.SearchSuggestion) (models.SearchSuggestion, bool) {
+	corrected := make([]string, len(words))
+	changed, score := false, 0.0
+	for i, word := range words {
+		term, ok := terms[word]
+		if !ok {
+			corrected[i] = word
+			score++
+			continue
+		}
+		corrected[i] = term.Query
+		score += term.Score
+		changed = true
+	}
+	return models.SearchSuggestion{
+		Query:  strings.Join(corrected, " "),
+		Source: models.SuggestionLyrics,
+		Score:  score / float64(len(words)),
+	}, changed
+}
+
+// RefreshSearchTerms recomputes the popular lyric terms search suggestions are drawn from,
+// doing nothing where pg_trgm is not installed
+func (s *MusicService) RefreshSearchTerms(ctx context.Context) error {
+	available, err := s.repo.HasSearchSuggestions(ctx)
+	if err != nil || !available {
+		return err
+	}
+	s.logger.Debug("Refreshing search terms")
+	if err := s.repo.RefreshSearchTerms(ctx); err != nil {
+		s.logger.Error("Failed to refresh search terms", zap.Error(err))
+		return err
+	}
+	s.logger.Info("Search terms refreshed successfully")
+	return nil
+}
+
+// StartSearchTermsRefresher refreshes the search terms every interval until ctx is cancelled. The migration
+// computes them, so the first refresh waits for the first tick.
+func (s *MusicService) StartSearchTermsRefresher(ctx context.Context, interval time.Duration) {
+	s.logger.Info("Starting search terms refresher", zap.Duration("interval", interval))
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				s.logger.Info("Search terms refresher stopped")
+				return
+			case <-ticker.C:
+				s.RefreshSearchTerms(ctx)
+			}
+		}
+	}()
+}
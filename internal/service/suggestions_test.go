@@ -0,0 +1,26 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"music-library/internal/models"
+)
+
+func TestSearchWords(t *testing.T) {
+	assert.Equal(t, []string{"bohemain", "rapsody", "любовь"}, searchWords("Bohemain Rapsody, of ЛЮБОВЬ!"))
+	assert.Nil(t, searchWords("a b"))
+}
+
+func TestCorrectWords(t *testing.T) {
+	suggestion, ok := correctWords([]string{"real", "lief"}, map[string]models.SearchSuggestion{
+		"lief": {Query: "life", Source: models.SuggestionLyrics, Score: 0.5},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, "real life", suggestion.Query)
+	assert.Equal(t, models.SuggestionLyrics, suggestion.Source)
+	assert.InDelta(t, 0.75, suggestion.Score, 1e-9)
+
+	_, ok = correctWords([]string{"real"}, nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// ErrInvalidBulkTag is returned when a bulk tag assignment does not select songs or tags correctly
+var ErrInvalidBulkTag = errors.New("invalid bulk tag assignment")
+
+// ErrInvalidTag is returned for an empty or overlong tag
+var ErrInvalidTag = errors.New("invalid tag")
+
+// MaxBulkTagIDs bounds the number of song IDs in a single bulk tag assignment
+const MaxBulkTagIDs = 10000
+
+// maxTagLength is the maximum number of characters of a tag
+const maxTagLength = 50
+
+// BulkTagRequest selects songs by ID or by filter and lists the tags to add to and remove from them
+type BulkTagRequest struct {
+	// IDs selects the songs by ID; when nil, Filter selects them
+	IDs    []int
+	Filter *models.SongFilter
+	Add    []string
+	Remove []string
+}
+
+// BulkTagSongs adds and removes tags on many songs at once, all or nothing.
+// Tags are trimmed and lowercased, so "Live" and "live " are the same tag.
+// ErrLegalHold is returned, and no song retagged, when one of the matched songs is on legal hold.
+func (s *MusicService) BulkTagSongs(ctx context.Context, req BulkTagRequest) (models.BulkTagResult, error) {
+	s.logger.Debug("Tagging songs in bulk", zap.Int("ids", len(req.IDs)), zap.Bool("filter", req.Filter != nil))
+	switch {
+	case (req.IDs == nil) == (req.Filter == nil):
+		return models.BulkTagResult{}, fmt.Errorf("%w: exactly one of ids and filter is required", ErrInvalidBulkTag)
+	case req.IDs != nil && len(req.IDs) == 0:
+		return models.BulkTagResult{}, fmt.Errorf("%w: ids must not be empty", ErrInvalidBulkTag)
+	case len(req.IDs) > MaxBulkTagIDs:
+		return models.BulkTagResult{}, fmt.Errorf("%w: at most %d ids are allowed", ErrInvalidBulkTag, MaxBulkTagIDs)
+	}
+	filter := models.SongFilter{}
+	if req.Filter != nil {
+		filter = *req.Filter
+		for _, field := range filter.Missing {
+			if !repository.IsNullableField(field) {
+				return models.BulkTagResult{}, fmt.Errorf("%w: unsupported missing field %q", ErrInvalidBulkTag, field)
+			}
+		}
+	}
+
+	add, err := normalizeTags(req.Add)
+	if err != nil {
+		return models.BulkTagResult{}, fmt.Errorf("%w: %v", ErrInvalidBulkTag, err)
+	}
+	remove, err := normalizeTags(req.Remove)
+	if err != nil {
+		return models.BulkTagResult{}, fmt.Errorf("%w: %v", ErrInvalidBulkTag, err)
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return models.BulkTagResult{}, fmt.Errorf("%w: no tags to add or remove", ErrInvalidBulkTag)
+	}
+	for _, tag := range add {
+		for _, removed := range remove {
+			if tag == removed {
+				return models.BulkTagResult{}, fmt.Errorf("%w: tag %q is both added and removed", ErrInvalidBulkTag, tag)
+			}
+		}
+	}
+
+	result, err := s.tagSongs(ctx, req.IDs, filter, add, remove)
+	if err != nil {
+		if !errors.Is(err, ErrLegalHold) {
+			s.logger.Error("Failed to tag songs in bulk", zap.Error(err))
+		}
+		return models.BulkTagResult{}, err
+	}
+	s.logger.Info("Songs tagged in bulk", zap.Int("matched", result.Matched), zap.Int("added", result.Added), zap.Int("removed", result.Removed))
+	return result, nil
+}
+
+// GetSongTags returns the tags of a song in name order. sql.ErrNoRows is returned when the song does not exist.
+func (s *MusicService) GetSongTags(ctx context.Context, songID int) ([]string, error) {
+	s.logger.Debug("Fetching song tags", zap.Int("song_id", songID))
+	return s.repo.GetSongTags(ctx, songID)
+}
+
+// AddSongTags adds tags to a song, creating the tags that do not exist yet, and returns the tags of the song.
+// sql.ErrNoRows is returned when the song does not exist and ErrLegalHold when it is on legal hold.
+func (s *MusicService) AddSongTags(ctx context.Context, songID int, tags []string) ([]string, error) {
+	s.logger.Debug("Adding song tags", zap.Int("song_id", songID), zap.Strings("tags", tags))
+	add, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(add) == 0 {
+		return nil, fmt.Errorf("%w: no tags to add", ErrInvalidTag)
+	}
+	return s.retagSong(ctx, songID, add, nil)
+}
+
+// RemoveSongTag removes a tag from a song and returns the remaining tags of the song. Removing a tag the song
+// does not carry changes nothing. sql.ErrNoRows is returned when the song does not exist and ErrLegalHold
+// when it is on legal hold.
+func (s *MusicService) RemoveSongTag(ctx context.Context, songID int, tag string) ([]string, error) {
+	s.logger.Debug("Removing song tag", zap.Int("song_id", songID), zap.String("tag", tag))
+	remove, err := normalizeTags([]string{tag})
+	if err != nil {
+		return nil, err
+	}
+	return s.retagSong(ctx, songID, nil, remove)
+}
+
+// tagSongs adds and removes normalized tags on the songs, rolling the changes back when one of the
+// matched songs is on legal hold
+func (s *MusicService) tagSongs(ctx context.Context, ids []int, filter models.SongFilter, add, remove []string) (models.BulkTagResult, error) {
+	var result models.BulkTagResult
+	err := s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = s.repo.BulkTagSongs(ctx, ids, filter, add, remove)
+		if err != nil || len(result.SongIDs) == 0 {
+			return err
+		}
+		return s.checkLegalHold(ctx, "tag", result.SongIDs...)
+	})
+	return result, err
+}
+
+// retagSong adds and removes normalized tags on a song and returns its tags
+func (s *MusicService) retagSong(ctx context.Context, songID int, add, remove []string) ([]string, error) {
+	result, err := s.tagSongs(ctx, []int{songID}, models.SongFilter{}, add, remove)
+	if err != nil {
+		if !errors.Is(err, ErrLegalHold) {
+			s.logger.Error("Failed to tag song", zap.Int("song_id", songID), zap.Error(err))
+		}
+		return nil, err
+	}
+	if result.Matched == 0 {
+		return nil, sql.ErrNoRows
+	}
+	s.logger.Info("Song tags updated successfully", zap.Int("song_id", songID), zap.Int("added", result.Added), zap.Int("removed", result.Removed))
+	return s.repo.GetSongTags(ctx, songID)
+}
+
+// normalizeTags trims, lowercases and deduplicates the tags, rejecting empty and overlong ones
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, fmt.Errorf("%w: tags must not be empty", ErrInvalidTag)
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidTag, tag, maxTagLength)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
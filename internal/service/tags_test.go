@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{" Live", "live", "Acoustic "})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"live", "acoustic"}, tags)
+
+	_, err = normalizeTags([]string{"live", "  "})
+	assert.ErrorIs(t, err, ErrInvalidTag)
+}
+
+func TestBulkTagSongsValidation(t *testing.T) {
+	svc := NewMusicService(nil, zap.NewNop(), http.DefaultClient)
+	for name, req := range map[string]BulkTagRequest{
+		"no selection":      {Add: []string{"live"}},
+		"both selections":   {IDs: []int{1}, Filter: &models.SongFilter{}, Add: []string{"live"}},
+		"empty ids":         {IDs: []int{}, Add: []string{"live"}},
+		"no tags":           {IDs: []int{1}},
+		"unsupported field": {Filter: &models.SongFilter{Missing: []string{"song_name"}}, Add: []string{"live"}},
+		"added and removed": {IDs: []int{1}, Add: []string{"Live"}, Remove: []string{"live"}},
+		"empty tag":         {IDs: []int{1}, Add: []string{" "}},
+	} {
+		_, err := svc.BulkTagSongs(context.Background(), req)
+		assert.ErrorIs(t, err, ErrInvalidBulkTag, name)
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// TimeoutConfig bounds how long each layer may spend on a request. Every timeout is enforced through the
+// context passed down to the layer, so a slow dependency fails the call instead of holding it.
+type TimeoutConfig struct {
+	// Request is the deadline of a whole API request, enforced by the timeout middleware
+	Request time.Duration
+	// Statement bounds a single database statement run outside a transaction
+	Statement time.Duration
+	// ExternalAPI bounds a single call to the external API, the embedder or the classifier.
+	// Waiting for the provider budget is bounded by the caller's context, the request deadline for API requests.
+	ExternalAPI time.Duration
+}
+
+// DefaultTimeoutConfig is used until ConfigureTimeouts is called
+var DefaultTimeoutConfig = TimeoutConfig{
+	Request:     30 * time.Second,
+	Statement:   10 * time.Second,
+	ExternalAPI: 5 * time.Second,
+}
+
+// TimeoutConfigFromEnv reads the timeouts from REQUEST_TIMEOUT, DB_STATEMENT_TIMEOUT and EXTERNAL_API_TIMEOUT.
+// ENRICH_TIMEOUT is still honored for the external API timeout when EXTERNAL_API_TIMEOUT is not set.
+func TimeoutConfigFromEnv() (TimeoutConfig, error) {
+	cfg := DefaultTimeoutConfig
+	external := "EXTERNAL_API_TIMEOUT"
+	if os.Getenv(external) == "" && os.Getenv("ENRICH_TIMEOUT") != "" {
+		external = "ENRICH_TIMEOUT"
+	}
+	for _, setting := range []struct {
+		key     string
+		timeout *time.Duration
+	}{
+		{"REQUEST_TIMEOUT", &cfg.Request},
+		{"DB_STATEMENT_TIMEOUT", &cfg.Statement},
+		{external, &cfg.ExternalAPI},
+	} {
+		value := os.Getenv(setting.key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return cfg, fmt.Errorf("%s must be a positive duration: %q", setting.key, value)
+		}
+		*setting.timeout = parsed
+	}
+	return cfg, nil
+}
+
+// ConfigureTimeouts sets the external API timeout and the database statement timeout;
+// zero values take the defaults. The request timeout is applied by the API's timeout middleware.
+func (s *MusicService) ConfigureTimeouts(cfg TimeoutConfig) {
+	if cfg.Request <= 0 {
+		cfg.Request = DefaultTimeoutConfig.Request
+	}
+	if cfg.Statement <= 0 {
+		cfg.Statement = DefaultTimeoutConfig.Statement
+	}
+	if cfg.ExternalAPI <= 0 {
+		cfg.ExternalAPI = DefaultTimeoutConfig.ExternalAPI
+	}
+	s.timeouts = cfg
+	if s.repo != nil {
+		s.repo.ConfigureStatementTimeout(cfg.Statement)
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTimeoutConfigFromEnv(t *testing.T) {
+	cfg, err := TimeoutConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTimeoutConfig, cfg)
+
+	t.Setenv("REQUEST_TIMEOUT", "1m")
+	t.Setenv("DB_STATEMENT_TIMEOUT", "2s")
+	t.Setenv("ENRICH_TIMEOUT", "3s")
+	cfg, err = TimeoutConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, TimeoutConfig{Request: time.Minute, Statement: 2 * time.Second, ExternalAPI: 3 * time.Second}, cfg)
+
+	t.Setenv("EXTERNAL_API_TIMEOUT", "4s")
+	cfg, err = TimeoutConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 4*time.Second, cfg.ExternalAPI, "EXTERNAL_API_TIMEOUT takes precedence over ENRICH_TIMEOUT")
+
+	t.Setenv("DB_STATEMENT_TIMEOUT", "-1s")
+	_, err = TimeoutConfigFromEnv()
+	assert.Error(t, err)
+}
+
+func TestExternalAPITimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	t.Setenv("EXTERNAL_API_URL", server.URL)
+
+	svc := NewMusicService(nil, zap.NewNop(), server.Client())
+	svc.ConfigureTimeouts(TimeoutConfig{ExternalAPI: 50 * time.Millisecond})
+
+	started := time.Now()
+	_, ok := svc.fetchExternalInfo(context.Background(), "Muse", "Uprising")
+	assert.False(t, ok)
+	assert.Less(t, time.Since(started), time.Second, "a hanging external API is abandoned after the timeout")
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// ErrInvalidTitle is returned when setting a song title without text, with an overlong one, of an unknown
+// kind or in an invalid language
+var ErrInvalidTitle = errors.New("invalid title")
+
+// maxTitleLength is the maximum number of characters of a song title
+const maxTitleLength = 255
+
+// NormalizeLanguage returns the canonical form of a BCP 47 language tag, with the language lowercased, the
+// script title-cased and the region uppercased ("zh-hant-tw" becomes "zh-Hant-TW"), or false when it is
+// not a valid tag
+func NormalizeLanguage(tag string) (string, bool) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if len(tag) > 35 || !languageTag.MatchString(strings.ToLower(tag)) {
+		return "", false
+	}
+	subtags := strings.Split(strings.ToLower(tag), "-")
+	for i, subtag := range subtags[1:] {
+		switch {
+		case len(subtag) == 4 && subtag[0] >= 'a' && subtag[0] <= 'z':
+			subtags[i+1] = strings.ToUpper(subtag[:1]) + subtag[1:]
+		case len(subtag) == 2:
+			subtags[i+1] = strings.ToUpper(subtag)
+		}
+	}
+	return strings.Join(subtags, "-"), true
+}
+
+// languageFallbacks returns the tags a title is looked up under for the preferred languages, in order:
+// each language followed by its less specific forms, so "zh-Hant-TW" falls back to "zh-Hant" and "zh"
+func languageFallbacks(langs []string) []string {
+	var fallbacks []string
+	seen := make(map[string]bool)
+	for _, lang := range langs {
+		for tag := lang; tag != ""; {
+			if !seen[tag] {
+				seen[tag] = true
+				fallbacks = append(fallbacks, tag)
+			}
+			i := strings.LastIndex(tag, "-")
+			if i < 0 {
+				break
+			}
+			tag = tag[:i]
+		}
+	}
+	return fallbacks
+}
+
+// ApplySongTitles sets the localized title of the songs to their title in the first of the preferred
+// languages they have one in. The languages are normalized BCP 47 tags in order of preference.
+func (s *MusicService) ApplySongTitles(ctx context.Context, langs []string, songs ...*models.Song) error {
+	if len(langs) == 0 || len(songs) == 0 {
+		return nil
+	}
+	fallbacks := languageFallbacks(langs)
+	ids := make([]int, len(songs))
+	for i, song := range songs {
+		ids[i] = song.ID
+	}
+	titles, err := s.repo.GetSongTitlesIn(ctx, ids, fallbacks)
+	if err != nil {
+		return err
+	}
+	byLang := make(map[int]map[string]models.SongTitle, len(titles))
+	for _, title := range titles {
+		if byLang[title.SongID] == nil {
+			byLang[title.SongID] = make(map[string]models.SongTitle)
+		}
+		byLang[title.SongID][title.Lang] = title
+	}
+	for _, song := range songs {
+		for _, lang := range fallbacks {
+			if title, ok := byLang[song.ID][lang]; ok {
+				song.LocalizedTitle = &models.LocalizedTitle{Lang: title.Lang, Kind: title.Kind, Title: title.Title}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// GetSongTitles returns the titles of a song. sql.ErrNoRows is returned when the song does not exist.
+func (s *MusicService) GetSongTitles(ctx context.Context, songID int) (models.SongTitles, error) {
+	s.logger.Debug("Fetching song titles", zap.Int("song_id", songID))
+	if _, err := s.repo.GetSongByID(ctx, songID); err != nil {
+		return models.SongTitles{}, err
+	}
+	titles, err := s.repo.GetSongTitles(ctx, songID)
+	if err != nil {
+		s.logger.Error("Failed to fetch song titles", zap.Int("song_id", songID), zap.Error(err))
+		return models.SongTitles{}, err
+	}
+	return models.SongTitles{SongID: songID, Titles: titles}, nil
+}
+
+// SetSongTitle adds or replaces the title of a song in a language and returns it as stored. sql.ErrNoRows
+// is returned when the song does not exist.
+func (s *MusicService) SetSongTitle(ctx context.Context, songID int, lang string, title models.SongTitleInput) (_ models.SongTitle, err error) {
+	defer metrics.ObserveOperation("set_song_title", time.Now(), &err)
+	s.logger.Debug("Setting song title", zap.Int("song_id", songID), zap.String("lang", lang))
+	normalized, ok := NormalizeLanguage(lang)
+	if !ok {
+		return models.SongTitle{}, fmt.Errorf("%w: %q is not a BCP 47 language tag", ErrInvalidTitle, lang)
+	}
+	title.Title = strings.TrimSpace(title.Title)
+	switch {
+	case title.Title == "":
+		return models.SongTitle{}, fmt.Errorf("%w: title is required", ErrInvalidTitle)
+	case utf8.RuneCountInString(title.Title) > maxTitleLength:
+		return models.SongTitle{}, fmt.Errorf("%w: title must be at most %d characters", ErrInvalidTitle, maxTitleLength)
+	}
+	switch title.Kind {
+	case "":
+		title.Kind = models.TitleTranslation
+	case models.TitleTranslation, models.TitleTransliteration:
+	default:
+		return models.SongTitle{}, fmt.Errorf("%w: kind must be %s or %s", ErrInvalidTitle, models.TitleTranslation, models.TitleTransliteration)
+	}
+
+	var stored models.SongTitle
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.GetSongByID(ctx, songID); err != nil {
+			return err
+		}
+		if err := s.checkLegalHold(ctx, "set_song_title", songID); err != nil {
+			return err
+		}
+		stored, err = s.repo.SetSongTitle(ctx, songID, normalized, title)
+		return err
+	})
+	if err != nil {
+		if err != sql.ErrNoRows && !errors.Is(err, ErrLegalHold) {
+			s.logger.Error("Failed to set song title", zap.Int("song_id", songID), zap.Error(err))
+		}
+		return models.SongTitle{}, err
+	}
+	s.logger.Info("Song title set successfully", zap.Int("song_id", songID), zap.String("lang", normalized))
+	return stored, nil
+}
+
+// DeleteSongTitle deletes the title of a song in a language. sql.ErrNoRows is returned when the song has
+// no title in the language.
+func (s *MusicService) DeleteSongTitle(ctx context.Context, songID int, lang string) (err error) {
+	defer metrics.ObserveOperation("delete_song_title", time.Now(), &err)
+	s.logger.Debug("Deleting song title", zap.Int("song_id", songID), zap.String("lang", lang))
+	normalized, ok := NormalizeLanguage(lang)
+	if !ok {
+		return fmt.Errorf("%w: %q is not a BCP 47 language tag", ErrInvalidTitle, lang)
+	}
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkLegalHold(ctx, "delete_song_title", songID); err != nil {
+			return err
+		}
+		return s.repo.DeleteSongTitle(ctx, songID, normalized)
+	})
+	if err != nil {
+		return err
+	}
+	s.logger.Info("Song title deleted successfully", zap.Int("song_id", songID), zap.String("lang", normalized))
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// titleRepository serves the titles of songs
+type titleRepository struct {
+	repository.Repository
+	titles []models.SongTitle
+}
+
+func (r *titleRepository) ConfigureStatementTimeout(time.Duration) {}
+
+func (r *titleRepository) GetSongTitlesIn(_ context.Context, songIDs []int, langs []string) ([]models.SongTitle, error) {
+	var titles []models.SongTitle
+	for _, title := range r.titles {
+		for _, lang := range langs {
+			if title.Lang == lang {
+				titles = append(titles, title)
+			}
+		}
+	}
+	return titles, nil
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	for tag, expected := range map[string]string{
+		"en":         "en",
+		"EN":         "en",
+		"pt_br":      "pt-BR",
+		"zh-hant-tw": "zh-Hant-TW",
+		"ja-Latn":    "ja-Latn",
+	} {
+		normalized, ok := NormalizeLanguage(tag)
+		assert.True(t, ok, tag)
+		assert.Equal(t, expected, normalized, tag)
+	}
+	for _, tag := range []string{"", "*", "english", "e", "en-", "en us"} {
+		_, ok := NormalizeLanguage(tag)
+		assert.False(t, ok, tag)
+	}
+}
+
+func TestLanguageFallbacks(t *testing.T) {
+	assert.Equal(t, []string{"zh-Hant-TW", "zh-Hant", "zh", "en-GB", "en"}, languageFallbacks([]string{"zh-Hant-TW", "en-GB", "zh"}))
+}
+
+func TestApplySongTitles(t *testing.T) {
+	repo := &titleRepository{titles: []models.SongTitle{
+		{SongID: 1, Lang: "ja", Title: "革命", Kind: models.TitleTranslation},
+		{SongID: 1, Lang: "en", Title: "Revolution", Kind: models.TitleTranslation},
+		{SongID: 2, Lang: "ru-Latn", Title: "Kino", Kind: models.TitleTransliteration},
+	}}
+	svc := NewMusicService(repo, zap.NewNop(), nil)
+	songs := []models.Song{{ID: 1, Song: "Revolución"}, {ID: 2, Song: "Кино"}, {ID: 3, Song: "Untitled"}}
+
+	require.NoError(t, svc.ApplySongTitles(context.Background(), []string{"en-US", "ja"}, &songs[0], &songs[1], &songs[2]))
+	assert.Equal(t, &models.LocalizedTitle{Lang: "en", Kind: models.TitleTranslation, Title: "Revolution"}, songs[0].LocalizedTitle)
+	assert.Nil(t, songs[1].LocalizedTitle)
+	assert.Nil(t, songs[2].LocalizedTitle)
+	assert.Equal(t, "Revolución", songs[0].Song, "the original title is kept")
+
+	require.NoError(t, svc.ApplySongTitles(context.Background(), []string{"ru-Latn"}, &songs[1]))
+	assert.Equal(t, "Kino", songs[1].LocalizedTitle.Title)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/analytics"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// DefaultTrashRetention is how long deleted songs stay in the trash before they are purged
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// trashPurgeInterval is how often the songs kept past the retention are purged from the trash
+const trashPurgeInterval = time.Hour
+
+// ErrSongIDTaken is returned when restoring a song from the trash under an ID a song added since has taken
+var ErrSongIDTaken = errors.New("song ID is taken")
+
+// ConfigureTrashRetention sets how long deleted songs can be restored from the trash
+func (s *MusicService) ConfigureTrashRetention(retention time.Duration) {
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+	s.trashRetention = retention
+}
+
+// StartTrashPurging deletes for good the songs kept in the trash past the retention every hour until ctx is
+// cancelled
+func (s *MusicService) StartTrashPurging(ctx context.Context) {
+	s.logger.Info("Starting trash purging", zap.Duration("retention", s.trashRetention))
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+		for {
+			if purged, err := s.repo.PurgeTrash(ctx, time.Now().Add(-s.trashRetention)); err == nil && purged > 0 {
+				s.logger.Info("Songs purged from trash", zap.Int64("count", purged))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// GetTrash returns a page of the deleted songs that can still be restored, most recently deleted first
+func (s *MusicService) GetTrash(ctx context.Context, page, limit int) (_ models.TrashPage, err error) {
+	defer metrics.ObserveOperation("get_trash", time.Now(), &err)
+	s.logger.Debug("Fetching trash", zap.Int("page", page), zap.Int("limit", limit))
+	songs, total, err := s.repo.GetTrash(ctx, page, limit)
+	if err != nil {
+		s.logger.Error("Failed to fetch trash", zap.Error(err))
+		return models.TrashPage{}, err
+	}
+	for i := range songs {
+		songs[i].PurgeAt = songs[i].DeletedAt.Add(s.trashRetention)
+	}
+	return models.TrashPage{
+		Data:       songs,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	}, nil
+}
+
+// RestoreTrashedSong puts a deleted song back into the catalog under its ID, with its tags, genres, titles,
+// ratings and revisions. sql.ErrNoRows is returned when it is not in the trash, and ErrSongIDTaken when a
+// song added since has its ID.
+func (s *MusicService) RestoreTrashedSong(ctx context.Context, id int) (_ models.Song, err error) {
+	defer metrics.ObserveOperation("restore_trashed_song", time.Now(), &err)
+	s.logger.Debug("Restoring song from trash", zap.Int("id", id))
+	err = s.repo.RunInTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.GetTrashedSong(ctx, id); err != nil {
+			return err
+		}
+		switch _, err := s.repo.GetSongByID(ctx, id); {
+		case err == nil:
+			return fmt.Errorf("%w: %d", ErrSongIDTaken, id)
+		case err != sql.ErrNoRows:
+			return err
+		}
+		if err := s.repo.RestoreTrashedSong(ctx, id); err != nil {
+			return err
+		}
+		return s.record(ctx, analytics.EventSongAdded, id, 1)
+	})
+	if err != nil {
+		if err != sql.ErrNoRows && !errors.Is(err, ErrSongIDTaken) {
+			s.logger.Error("Failed to restore song from trash", zap.Int("id", id), zap.Error(err))
+		}
+		return models.Song{}, err
+	}
+	s.publish(analytics.EventSongAdded, id, 1)
+	song, err := s.repo.GetSongByID(ctx, id)
+	if err != nil {
+		return models.Song{}, err
+	}
+	// The verse index is derived, so it is rebuilt rather than kept in the trash
+	if song.Text != nil {
+		s.indexVerses(ctx, id, *song.Text)
+	}
+	s.logger.Info("Song restored from trash successfully", zap.Int("id", id))
+	return song, nil
+}
+
+// PurgeTrashedSong deletes a song in the trash for good before its retention ends. sql.ErrNoRows is returned
+// when it is not in the trash.
+func (s *MusicService) PurgeTrashedSong(ctx context.Context, id int) (err error) {
+	defer metrics.ObserveOperation("purge_trashed_song", time.Now(), &err)
+	s.logger.Debug("Purging song from trash", zap.Int("id", id))
+	if err := s.repo.DeleteTrashedSong(ctx, id); err != nil {
+		if err != sql.ErrNoRows {
+			s.logger.Error("Failed to purge song from trash", zap.Int("id", id), zap.Error(err))
+		}
+		return err
+	}
+	s.logger.Info("Song purged from trash successfully", zap.Int("id", id))
+	return nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// trashRepository holds song 1 in the trash and the songs of the catalog by ID
+type trashRepository struct {
+	repository.Repository
+	deletedAt time.Time
+	catalog   map[int]bool
+	restored  []int
+}
+
+func (r *trashRepository) ConfigureStatementTimeout(time.Duration) {}
+
+func (r *trashRepository) RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *trashRepository) GetTrash(context.Context, int, int) ([]models.TrashedSong, int, error) {
+	return []models.TrashedSong{{ID: 1, Group: "Muse", Song: "Uprising", DeletedAt: r.deletedAt}}, 1, nil
+}
+
+func (r *trashRepository) GetTrashedSong(_ context.Context, id int) (models.TrashedSong, error) {
+	if id != 1 {
+		return models.TrashedSong{}, sql.ErrNoRows
+	}
+	return models.TrashedSong{ID: 1, Group: "Muse", Song: "Uprising", DeletedAt: r.deletedAt}, nil
+}
+
+func (r *trashRepository) GetSongByID(_ context.Context, id int) (models.Song, error) {
+	if !r.catalog[id] {
+		return models.Song{}, sql.ErrNoRows
+	}
+	return models.Song{ID: id, Group: "Muse", Song: "Uprising"}, nil
+}
+
+func (r *trashRepository) RestoreTrashedSong(_ context.Context, id int) error {
+	r.restored = append(r.restored, id)
+	r.catalog[id] = true
+	return nil
+}
+
+func (r *trashRepository) AddSongEvent(context.Context, models.SongEvent) (int64, error) {
+	return 1, nil
+}
+
+func TestGetTrashPurgeAt(t *testing.T) {
+	deletedAt := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	svc := NewMusicService(&trashRepository{deletedAt: deletedAt}, zap.NewNop(), nil)
+	svc.ConfigureTrashRetention(7 * 24 * time.Hour)
+
+	trash, err := svc.GetTrash(context.Background(), 1, 10)
+	require.NoError(t, err)
+	require.Len(t, trash.Data, 1)
+	assert.Equal(t, deletedAt.Add(7*24*time.Hour), trash.Data[0].PurgeAt)
+	assert.Equal(t, 1, trash.TotalPages)
+}
+
+func TestRestoreTrashedSong(t *testing.T) {
+	repo := &trashRepository{catalog: map[int]bool{1: true}}
+	svc := NewMusicService(repo, zap.NewNop(), nil)
+
+	_, err := svc.RestoreTrashedSong(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrSongIDTaken, "a song added since holds the ID")
+	assert.Empty(t, repo.restored)
+
+	_, err = svc.RestoreTrashedSong(context.Background(), 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	delete(repo.catalog, 1)
+	song, err := svc.RestoreTrashedSong(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, song.ID)
+	assert.Equal(t, []int{1}, repo.restored)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+)
+
+// Trending score parameters: views lose weight with age like Hacker News gravity,
+// and only the last week of activity is considered
+const (
+	trendingGravity    = 1.8
+	trendingWindowDays = 7
+)
+
+// RefreshTrending recomputes the trending scores from recent views
+func (s *MusicService) RefreshTrending(ctx context.Context) error {
+	s.logger.Debug("Refreshing trending scores")
+	if err := s.repo.RefreshTrending(ctx, trendingGravity, trendingWindowDays); err != nil {
+		s.logger.Error("Failed to refresh trending scores", zap.Error(err))
+		return err
+	}
+	s.logger.Info("Trending scores refreshed successfully")
+	return nil
+}
+
+// GetTrendingSongs retrieves the currently trending songs
+func (s *MusicService) GetTrendingSongs(ctx context.Context, limit int) ([]models.TrendingSong, error) {
+	s.logger.Debug("Fetching trending songs", zap.Int("limit", limit))
+	songs, err := s.repo.GetTrendingSongs(ctx, limit)
+	if err != nil {
+		s.logger.Error("Failed to fetch trending songs from database", zap.Error(err))
+		return nil, err
+	}
+	s.logger.Info("Trending songs fetched successfully", zap.Int("count", len(songs)))
+	return songs, nil
+}
+
+// StartTrendingScheduler refreshes the trending scores immediately and then every interval until ctx is cancelled
+func (s *MusicService) StartTrendingScheduler(ctx context.Context, interval time.Duration) {
+	s.logger.Info("Starting trending scheduler", zap.Duration("interval", interval))
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.RefreshTrending(ctx)
+			select {
+			case <-ctx.Done():
+				s.logger.Info("Trending scheduler stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// Named verse delimiters, also stored as the split strategies of songs. Any other delimiter is matched
+// literally, with \n standing for a newline.
+const (
+	// VerseDelimiterBlankLine splits verses at blank lines
+	VerseDelimiterBlankLine = "blank-line"
+	// VerseDelimiterLine makes every line a verse
+	VerseDelimiterLine = "line"
+	// VerseDelimiterMarkers starts a verse at every section marker line such as [Verse 1] or [Chorus]
+	VerseDelimiterMarkers = "markers"
+	// VerseDelimiterFixedLines followed by a count, as in lines:4, makes a verse of every count lines,
+	// blank lines left out, for lyrics sources that neither leave blank lines nor mark sections
+	VerseDelimiterFixedLines = "lines:"
+)
+
+// maxLinesPerVerse bounds the line count of VerseDelimiterFixedLines
+const maxLinesPerVerse = 1000
+
+// ErrPageOutOfRange is returned for a verse page past the last page of the song
+var ErrPageOutOfRange = errors.New("page out of range")
+
+// ErrInvalidVerseDelimiter is returned for a verse delimiter or split strategy that cannot split lyrics
+var ErrInvalidVerseDelimiter = errors.New("invalid verse delimiter")
+
+// maxVerseOffset bounds the index of the first verse of a requested page, so absurd page numbers are
+// rejected before any work rather than overflowing
+const maxVerseOffset = 1 << 30
+
+// DefaultVerseDelimiter is used until ConfigureVerseDelimiter is called
+const DefaultVerseDelimiter = VerseDelimiterBlankLine
+
+// verseMarker matches a section marker line such as [Verse 1] or [Chorus]
+var verseMarker = regexp.MustCompile(`(?m)^[ \t]*\[([^\]\n]+)\][ \t]*$`)
+
+// ConfigureVerseDelimiter sets the delimiter used when a request does not specify one
+func (s *MusicService) ConfigureVerseDelimiter(delimiter string) {
+	if delimiter == "" {
+		delimiter = DefaultVerseDelimiter
+	}
+	s.verseDelimiter = delimiter
+}
+
+// ValidateVerseDelimiter checks a delimiter given by a request or stored as a song's split strategy
+func ValidateVerseDelimiter(delimiter string) error {
+	if delimiter == "" {
+		return fmt.Errorf("%w: the delimiter cannot be empty", ErrInvalidVerseDelimiter)
+	}
+	if count, ok := strings.CutPrefix(delimiter, VerseDelimiterFixedLines); ok {
+		if _, err := linesPerVerse(count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linesPerVerse parses the line count of VerseDelimiterFixedLines
+func linesPerVerse(count string) (int, error) {
+	lines, err := strconv.Atoi(count)
+	if err != nil || lines < 1 || lines > maxLinesPerVerse {
+		return 0, fmt.Errorf("%w: %s needs a line count between 1 and %d", ErrInvalidVerseDelimiter, VerseDelimiterFixedLines, maxLinesPerVerse)
+	}
+	return lines, nil
+}
+
+// splitStrategy returns the delimiter a song's verses are split at when a request names none: its own
+// split strategy, or the configured delimiter
+func (s *MusicService) splitStrategy(strategy *string) string {
+	if strategy != nil && *strategy != "" {
+		return *strategy
+	}
+	return s.verseDelimiter
+}
+
+// verseSpan locates a verse in lyrics with normalized line endings by byte offsets
+type verseSpan struct {
+	label      string
+	start, end int
+}
+
+// normalizeLyrics turns CRLF line endings into LF, the text verse spans are computed on
+func normalizeLyrics(text string) string {
+	return strings.ReplaceAll(text, "\r\n", "\n")
+}
+
+// splitVerses splits lyrics into verses at the delimiter. Empty verses are dropped.
+func splitVerses(text, delimiter string) []Verse {
+	text = normalizeLyrics(text)
+	var verses []Verse
+	for i, span := range findVerses(text, delimiter) {
+		verses = append(verses, Verse{Number: i + 1, Label: span.label, Text: text[span.start:span.end]})
+	}
+	return verses
+}
+
+// findVerses locates the verses of normalized lyrics split at the delimiter, with the space around each
+// verse left out. Empty verses are dropped unless they carry a label.
+func findVerses(text, delimiter string) []verseSpan {
+	var spans []verseSpan
+	add := func(label string, start, end int) {
+		verse := text[start:end]
+		trimmed := strings.TrimLeftFunc(verse, unicode.IsSpace)
+		start += len(verse) - len(trimmed)
+		end = start + len(strings.TrimRightFunc(trimmed, unicode.IsSpace))
+		if start == end && label == "" {
+			return
+		}
+		spans = append(spans, verseSpan{label: label, start: start, end: end})
+	}
+
+	if count, ok := strings.CutPrefix(delimiter, VerseDelimiterFixedLines); ok {
+		// Delimiters are validated before they are used or stored, so a bad count only comes from a strategy
+		// written to the database directly, and takes the largest count
+		lines, err := linesPerVerse(count)
+		if err != nil {
+			lines = maxLinesPerVerse
+		}
+		start, inVerse, offset := 0, 0, 0
+		for offset <= len(text) {
+			end := strings.IndexByte(text[offset:], '\n')
+			if end < 0 {
+				end = len(text)
+			} else {
+				end += offset
+			}
+			if strings.TrimSpace(text[offset:end]) != "" {
+				if inVerse == 0 {
+					start = offset
+				}
+				inVerse++
+				if inVerse == lines {
+					add("", start, end)
+					inVerse = 0
+				}
+			}
+			offset = end + 1
+		}
+		if inVerse > 0 {
+			add("", start, len(text))
+		}
+		return spans
+	}
+
+	switch delimiter {
+	case VerseDelimiterMarkers:
+		markers := verseMarker.FindAllStringSubmatchIndex(text, -1)
+		start, label := 0, ""
+		for _, marker := range markers {
+			add(label, start, marker[0])
+			start, label = marker[1], strings.TrimSpace(text[marker[2]:marker[3]])
+		}
+		add(label, start, len(text))
+		return spans
+	case VerseDelimiterBlankLine:
+		delimiter = "\n\n"
+	case VerseDelimiterLine:
+		delimiter = "\n"
+	default:
+		delimiter = strings.ReplaceAll(delimiter, `\n`, "\n")
+	}
+	start := 0
+	for delimiter != "" {
+		next := strings.Index(text[start:], delimiter)
+		if next < 0 {
+			break
+		}
+		add("", start, start+next)
+		start += next + len(delimiter)
+	}
+	add("", start, len(text))
+	return spans
+}
+
+// characterSpans converts the byte offsets of verse spans into the character offsets the verse index stores,
+// which the database cuts the text by
+func characterSpans(text string, spans []verseSpan) []models.VerseSpan {
+	converted := make([]models.VerseSpan, len(spans))
+	characters, offset := 0, 0
+	for i, span := range spans {
+		characters += utf8.RuneCountInString(text[offset:span.start])
+		start := characters
+		characters += utf8.RuneCountInString(text[span.start:span.end])
+		offset = span.end
+		converted[i] = models.VerseSpan{Number: i + 1, Label: span.label, Start: start, Length: characters - start}
+	}
+	return converted
+}
+
+// indexVerses stores the verse layout of a song's text as just written, for the configured delimiter, so
+// verse pages are read without splitting the text again. Nothing is stored when the write left the text
+// different, and failures are only logged: a song without a current index, or split by its own strategy,
+// is indexed on its first verse read.
+func (s *MusicService) indexVerses(ctx context.Context, songID int, text string) {
+	normalized := normalizeLyrics(text)
+	s.saveVerseIndex(ctx, songID, s.verseDelimiter, text, normalized, findVerses(normalized, s.verseDelimiter))
+}
+
+// saveVerseIndex stores the verse spans found in the normalized form of the text at the delimiter
+func (s *MusicService) saveVerseIndex(ctx context.Context, songID int, delimiter, text, normalized string, spans []verseSpan) {
+	err := s.repo.SaveVerseIndex(ctx, songID, delimiter, repository.TextHash(text), characterSpans(normalized, spans))
+	if err != nil {
+		s.logger.Warn("Failed to index verses", zap.Int("song_id", songID), zap.Error(err))
+	}
+}
+
+// pageBounds checks the page against the number of verses, setting TotalPages, and returns the range of
+// the verses on it. A song without verses has a single, empty page.
+func (p *VersePage) pageBounds() (int, int, error) {
+	p.TotalPages = (p.TotalVerses + p.Limit - 1) / p.Limit
+	if last := max(p.TotalPages, 1); p.Page > last {
+		return 0, 0, fmt.Errorf("%w: page %d is past the last page %d", ErrPageOutOfRange, p.Page, last)
+	}
+	start := (p.Page - 1) * p.Limit
+	return start, min(start+p.Limit, p.TotalVerses), nil
+}
+
+// readVerses reads a page of verses, split at the song's split strategy when no delimiter is given. For the
+// song's split strategy the page is cut out by its verse index; the text is only split when the index is
+// missing or out of date, and then indexed.
+func (s *MusicService) readVerses(ctx context.Context, songID, page, limit int, delimiter string) (*VersePage, error) {
+	index, err := s.repo.GetVerseIndex(ctx, songID)
+	if err != nil {
+		return nil, err
+	}
+	strategy := s.splitStrategy(index.SplitStrategy)
+	if delimiter == "" {
+		delimiter = strategy
+	}
+	if delimiter == strategy && index.Indexed && index.Delimiter == delimiter {
+		result, err := s.readIndexedVerses(ctx, index, page, limit)
+		if result != nil || err != nil {
+			return result, err
+		}
+	}
+
+	song, err := s.repo.GetSongByID(ctx, songID)
+	if err != nil {
+		return nil, err
+	}
+	text := models.StringValue(song.Text)
+	normalized := normalizeLyrics(text)
+	spans := findVerses(normalized, delimiter)
+	if delimiter == s.splitStrategy(song.SplitStrategy) {
+		s.saveVerseIndex(ctx, songID, delimiter, text, normalized, spans)
+	}
+	return versePage(song, normalized, spans, page, limit)
+}
+
+// versePage cuts a page of verses out of normalized lyrics by their spans
+func versePage(song models.Song, normalized string, spans []verseSpan, page, limit int) (*VersePage, error) {
+	result := &VersePage{SongID: song.ID, Group: song.Group, Song: song.Song, TotalVerses: len(spans), Page: page, Limit: limit, Verses: []Verse{}}
+	start, end, err := result.pageBounds()
+	if err != nil {
+		return nil, err
+	}
+	for i := start; i < end; i++ {
+		result.Verses = append(result.Verses, Verse{Number: i + 1, Label: spans[i].label, Text: normalized[spans[i].start:spans[i].end]})
+	}
+	return result, nil
+}
+
+// readIndexedVerses reads a page of verses by the song's verse index, returning a nil page when the text
+// changed since the index was read
+func (s *MusicService) readIndexedVerses(ctx context.Context, index models.VerseIndex, page, limit int) (*VersePage, error) {
+	songID := index.SongID
+	result := &VersePage{SongID: index.SongID, Group: index.Group, Song: index.Song, TotalVerses: index.TotalVerses, Page: page, Limit: limit, Verses: []Verse{}}
+	start, end, err := result.pageBounds()
+	if err != nil || start == end {
+		return result, err
+	}
+	verses, err := s.repo.GetIndexedVerses(ctx, songID, index.Delimiter, start+1, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(verses) != end-start {
+		// The text changed after the index was read
+		s.logger.Debug("Verse index out of date", zap.Int("song_id", songID))
+		return nil, nil
+	}
+	for _, verse := range verses {
+		result.Verses = append(result.Verses, Verse{Number: verse.Number, Label: verse.Label, Text: verse.Text})
+	}
+	return result, nil
+}
+
+// cloneVersePage copies the page, so callers changing it do not change the cached one
+func cloneVersePage(page *VersePage) *VersePage {
+	clone := *page
+	clone.Verses = append([]Verse{}, page.Verses...)
+	return &clone
+}
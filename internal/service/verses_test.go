@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/models"
+	"music-library/internal/repository"
+)
+
+// verseRepository serves one song and keeps its verse index in memory, cutting verses by the stored spans
+type verseRepository struct {
+	repository.Repository
+	song      models.Song
+	hash      string
+	delimiter string
+	spans     []models.VerseSpan
+	reads     int
+}
+
+func (r *verseRepository) ConfigureStatementTimeout(time.Duration) {}
+
+func (r *verseRepository) GetSongByID(context.Context, int) (models.Song, error) {
+	r.reads++
+	return r.song, nil
+}
+
+func (r *verseRepository) SaveVerseIndex(_ context.Context, _ int, delimiter, textHash string, spans []models.VerseSpan) error {
+	if textHash == repository.TextHash(models.StringValue(r.song.Text)) {
+		r.hash, r.delimiter, r.spans = textHash, delimiter, spans
+	}
+	return nil
+}
+
+func (r *verseRepository) current() bool {
+	return r.hash != "" && r.hash == repository.TextHash(models.StringValue(r.song.Text))
+}
+
+func (r *verseRepository) GetVerseIndex(context.Context, int) (models.VerseIndex, error) {
+	index := models.VerseIndex{SongID: r.song.ID, Group: r.song.Group, Song: r.song.Song, SplitStrategy: r.song.SplitStrategy}
+	if r.current() {
+		index.Indexed, index.Delimiter, index.TotalVerses = true, r.delimiter, len(r.spans)
+	}
+	return index, nil
+}
+
+func (r *verseRepository) GetIndexedVerses(_ context.Context, _ int, _ string, from, to int) ([]models.IndexedVerse, error) {
+	if !r.current() {
+		return nil, nil
+	}
+	text := []rune(normalizeLyrics(models.StringValue(r.song.Text)))
+	var verses []models.IndexedVerse
+	for _, span := range r.spans[from-1 : to] {
+		verses = append(verses, models.IndexedVerse{Number: span.Number, Label: span.Label, Text: string(text[span.Start : span.Start+span.Length])})
+	}
+	return verses, nil
+}
+
+func TestSplitVerses(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		delimiter string
+		want      []Verse
+	}{
+		{
+			name:      "Blank Lines",
+			text:      "Verse 1\r\n\r\nVerse 2\n\n\n\nVerse 3\n",
+			delimiter: VerseDelimiterBlankLine,
+			want:      []Verse{{Number: 1, Text: "Verse 1"}, {Number: 2, Text: "Verse 2"}, {Number: 3, Text: "Verse 3"}},
+		},
+		{
+			name:      "Lines",
+			text:      "First line\nSecond line",
+			delimiter: VerseDelimiterLine,
+			want:      []Verse{{Number: 1, Text: "First line"}, {Number: 2, Text: "Second line"}},
+		},
+		{
+			name:      "Markers",
+			text:      "Intro line\n[Verse 1]\nOoh baby\nCan you hear me\n\n[Chorus]\nSupermassive\n[Outro]",
+			delimiter: VerseDelimiterMarkers,
+			want: []Verse{
+				{Number: 1, Text: "Intro line"},
+				{Number: 2, Label: "Verse 1", Text: "Ooh baby\nCan you hear me"},
+				{Number: 3, Label: "Chorus", Text: "Supermassive"},
+				{Number: 4, Label: "Outro", Text: ""},
+			},
+		},
+		{
+			name:      "Fixed Lines",
+			text:      "One\nTwo\n\nThree\r\nFour\nFive\n",
+			delimiter: VerseDelimiterFixedLines + "2",
+			want:      []Verse{{Number: 1, Text: "One\nTwo"}, {Number: 2, Text: "Three\nFour"}, {Number: 3, Text: "Five"}},
+		},
+		{
+			name:      "Literal",
+			text:      "One\n--\nTwo",
+			delimiter: `\n--\n`,
+			want:      []Verse{{Number: 1, Text: "One"}, {Number: 2, Text: "Two"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitVerses(tt.text, tt.delimiter))
+		})
+	}
+}
+
+func TestCharacterSpans(t *testing.T) {
+	text := normalizeLyrics("Ünï côdé\r\n\r\n  Ñaña  \n\n[Chorus]\nÖö")
+	for _, delimiter := range []string{VerseDelimiterBlankLine, VerseDelimiterLine, VerseDelimiterMarkers, VerseDelimiterFixedLines + "2"} {
+		verses := splitVerses(text, delimiter)
+		spans := characterSpans(text, findVerses(text, delimiter))
+		require.Len(t, spans, len(verses), delimiter)
+		runes := []rune(text)
+		for i, span := range spans {
+			assert.Equal(t, verses[i].Number, span.Number)
+			assert.Equal(t, verses[i].Label, span.Label)
+			assert.Equal(t, verses[i].Text, string(runes[span.Start:span.Start+span.Length]), delimiter)
+			assert.Equal(t, utf8.RuneCountInString(verses[i].Text), span.Length)
+		}
+	}
+}
+
+func TestGetVersesByIndex(t *testing.T) {
+	text := "Verse 1\n\nVerse 2\n\nVerse 3"
+	repo := &verseRepository{song: models.Song{ID: 1, Group: "Muse", Song: "Uprising", Text: &text}}
+	svc := NewMusicService(repo, zap.NewNop(), nil)
+	svc.ConfigureVerseDelimiter("")
+
+	page, err := svc.GetVerses(context.Background(), 1, 2, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.TotalVerses)
+	assert.Equal(t, 2, page.TotalPages)
+	assert.Equal(t, []Verse{{Number: 3, Text: "Verse 3"}}, page.Verses)
+	assert.Len(t, repo.spans, 3, "the first read indexes the verses")
+
+	reads := repo.reads
+	page, err = svc.GetVerses(context.Background(), 1, 1, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, []Verse{{Number: 1, Text: "Verse 1"}, {Number: 2, Text: "Verse 2"}}, page.Verses)
+	assert.Equal(t, reads, repo.reads, "indexed verses are read without the whole text")
+
+	edited := "Only verse"
+	repo.song.Text = &edited
+	page, err = svc.GetVerses(context.Background(), 1, 1, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.TotalVerses, "an index of the old text is not used")
+
+	_, err = svc.GetVerses(context.Background(), 1, 2, 2, "")
+	assert.ErrorIs(t, err, ErrPageOutOfRange)
+	_, err = svc.GetVerses(context.Background(), 1, 1<<62, 10, "")
+	assert.ErrorIs(t, err, ErrPageOutOfRange)
+
+	empty := ""
+	repo.song.Text = &empty
+	page, err = svc.GetVerses(context.Background(), 1, 1, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, page.TotalPages)
+	assert.Empty(t, page.Verses, "a song without verses has an empty first page")
+}
+
+func TestValidateVerseDelimiter(t *testing.T) {
+	for _, delimiter := range []string{VerseDelimiterBlankLine, VerseDelimiterMarkers, VerseDelimiterFixedLines + "4", `\n--\n`} {
+		assert.NoError(t, ValidateVerseDelimiter(delimiter), delimiter)
+	}
+	for _, delimiter := range []string{"", VerseDelimiterFixedLines, VerseDelimiterFixedLines + "0", VerseDelimiterFixedLines + "x", VerseDelimiterFixedLines + "5000"} {
+		assert.ErrorIs(t, ValidateVerseDelimiter(delimiter), ErrInvalidVerseDelimiter, delimiter)
+	}
+}
+
+func TestGetVersesBySplitStrategy(t *testing.T) {
+	text := "[Verse]\nOne\nTwo\n[Chorus]\nThree"
+	strategy := VerseDelimiterMarkers
+	repo := &verseRepository{song: models.Song{ID: 1, Group: "Muse", Song: "Uprising", Text: &text, SplitStrategy: &strategy}}
+	svc := NewMusicService(repo, zap.NewNop(), nil)
+	svc.indexVerses(context.Background(), 1, text)
+	assert.Equal(t, DefaultVerseDelimiter, repo.delimiter, "writes index the configured delimiter")
+
+	page, err := svc.GetVerses(context.Background(), 1, 1, 10, "")
+	require.NoError(t, err)
+	assert.Equal(t, []Verse{{Number: 1, Label: "Verse", Text: "One\nTwo"}, {Number: 2, Label: "Chorus", Text: "Three"}}, page.Verses)
+	assert.Equal(t, VerseDelimiterMarkers, repo.delimiter, "the song is indexed at its own strategy")
+
+	reads := repo.reads
+	_, err = svc.GetVerses(context.Background(), 1, 1, 10, "")
+	require.NoError(t, err)
+	assert.Equal(t, reads, repo.reads, "verses split at the song's strategy are read by the index")
+
+	page, err = svc.GetVerses(context.Background(), 1, 1, 10, VerseDelimiterLine)
+	require.NoError(t, err)
+	assert.Equal(t, 5, page.TotalVerses, "a requested delimiter takes precedence")
+	assert.Equal(t, VerseDelimiterMarkers, repo.delimiter)
+
+	_, err = svc.GetVerses(context.Background(), 1, 1, 10, VerseDelimiterFixedLines+"0")
+	assert.ErrorIs(t, err, ErrInvalidVerseDelimiter)
+}
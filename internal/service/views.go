@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/analytics"
+)
+
+// recordView buffers a lyrics view of a song until the next flush
+func (s *MusicService) recordView(songID int) {
+	s.viewsMu.Lock()
+	s.pendingViews[songID]++
+	s.viewsMu.Unlock()
+	s.publish(analytics.EventView, songID, 1)
+}
+
+// FlushViews writes the buffered view counts to the database.
+// Counts are put back into the buffer when the write fails so no views are lost.
+func (s *MusicService) FlushViews(ctx context.Context) error {
+	s.viewsMu.Lock()
+	pending := s.pendingViews
+	s.pendingViews = make(map[int]int64)
+	s.viewsMu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := s.repo.IncrementSongViews(ctx, pending); err != nil {
+		s.logger.Error("Failed to flush song views", zap.Int("songs", len(pending)), zap.Error(err))
+		s.viewsMu.Lock()
+		for id, count := range pending {
+			s.pendingViews[id] += count
+		}
+		s.viewsMu.Unlock()
+		return err
+	}
+	s.logger.Debug("Song views flushed", zap.Int("songs", len(pending)))
+	return nil
+}
+
+// StartViewFlusher flushes buffered view counts every interval, and once more when ctx is cancelled
+func (s *MusicService) StartViewFlusher(ctx context.Context, interval time.Duration) {
+	s.logger.Info("Starting view counter flusher", zap.Duration("interval", interval))
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				// The buffered views are still written once the jobs are told to stop
+				s.FlushViews(context.WithoutCancel(ctx))
+				s.logger.Info("View counter flusher stopped")
+				return
+			case <-ticker.C:
+				s.FlushViews(ctx)
+			}
+		}
+	}()
+}
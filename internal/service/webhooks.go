@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/changes"
+	"music-library/internal/metrics"
+	"music-library/internal/models"
+)
+
+// ErrInvalidWebhook is returned when registering a webhook without a valid URL or events
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// ErrInvalidDeliveryStatus is returned when listing deliveries by an unknown status
+var ErrInvalidDeliveryStatus = errors.New("invalid delivery status")
+
+// ErrDeliveryPending is returned when retrying a delivery that is still queued
+var ErrDeliveryPending = errors.New("delivery is still pending")
+
+// MaxWebhookDeliveries is the number of deliveries listed at most
+const MaxWebhookDeliveries = 500
+
+// CreateWebhook registers a URL notified of the catalog events of the given types. A secret is generated
+// when none is given; the returned webhook carries it, as it is never shown again.
+func (s *MusicService) CreateWebhook(ctx context.Context, target string, events []string, secret string) (_ models.Webhook, err error) {
+	defer metrics.ObserveOperation("create_webhook", time.Now(), &err)
+	s.logger.Debug("Creating webhook", zap.String("url", target), zap.Strings("events", events))
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return models.Webhook{}, fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if len(events) == 0 {
+		return models.Webhook{}, fmt.Errorf("%w: at least one event is required", ErrInvalidWebhook)
+	}
+	seen := make(map[string]bool, len(events))
+	subscribed := make([]string, 0, len(events))
+	for _, event := range events {
+		if !changes.IsEventType(event) {
+			return models.Webhook{}, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			subscribed = append(subscribed, event)
+		}
+	}
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			return models.Webhook{}, err
+		}
+	}
+	webhook, err := s.repo.CreateWebhook(ctx, target, subscribed, secret)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	s.logger.Info("Webhook created successfully", zap.Int("id", webhook.ID), zap.String("url", target))
+	return webhook, nil
+}
+
+// GetWebhooks returns the registered webhooks, without their secrets
+func (s *MusicService) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	s.logger.Debug("Fetching webhooks")
+	webhooks, err := s.repo.GetWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook unregisters a webhook, dropping its queued deliveries. sql.ErrNoRows is returned when it does
+// not exist.
+func (s *MusicService) DeleteWebhook(ctx context.Context, id int) error {
+	s.logger.Debug("Deleting webhook", zap.Int("id", id))
+	if err := s.repo.DeleteWebhook(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Info("Webhook deleted successfully", zap.Int("id", id))
+	return nil
+}
+
+// GetWebhookDeliveries returns the newest deliveries of a webhook, optionally of a single status. sql.ErrNoRows
+// is returned when the webhook does not exist.
+func (s *MusicService) GetWebhookDeliveries(ctx context.Context, id int, status string, limit int) ([]models.WebhookDelivery, error) {
+	s.logger.Debug("Fetching webhook deliveries", zap.Int("id", id), zap.String("status", status), zap.Int("limit", limit))
+	switch status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryFailed:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDeliveryStatus, status)
+	}
+	if limit < 1 || limit > MaxWebhookDeliveries {
+		limit = MaxWebhookDeliveries
+	}
+	return s.repo.GetWebhookDeliveries(ctx, id, status, limit)
+}
+
+// RotateWebhookSecret replaces the signing secret of a webhook, generating one when none is given; the
+// returned webhook carries it, as it is never shown again. Deliveries are also signed with the replaced secret
+// for the grace period of the dispatcher. sql.ErrNoRows is returned when the webhook does not exist.
+func (s *MusicService) RotateWebhookSecret(ctx context.Context, id int, secret string) (_ models.Webhook, err error) {
+	defer metrics.ObserveOperation("rotate_webhook_secret", time.Now(), &err)
+	s.logger.Debug("Rotating webhook secret", zap.Int("id", id))
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			return models.Webhook{}, err
+		}
+	}
+	webhook, err := s.repo.RotateWebhookSecret(ctx, id, secret)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	s.logger.Info("Webhook secret rotated successfully", zap.Int("id", id))
+	return webhook, nil
+}
+
+// RetryWebhookDelivery queues a delivered or failed delivery of a webhook to be sent again right away, with a
+// fresh set of attempts. sql.ErrNoRows is returned when the webhook has no such delivery, and
+// ErrDeliveryPending when it is still queued.
+func (s *MusicService) RetryWebhookDelivery(ctx context.Context, webhookID int, id int64) (_ models.WebhookDelivery, err error) {
+	defer metrics.ObserveOperation("retry_webhook_delivery", time.Now(), &err)
+	s.logger.Debug("Retrying webhook delivery", zap.Int("webhook_id", webhookID), zap.Int64("id", id))
+	delivery, err := s.repo.RetryWebhookDelivery(ctx, webhookID, id)
+	if err == sql.ErrNoRows {
+		// Nothing was retried, either because the delivery does not exist or because it is pending
+		if _, err := s.repo.GetWebhookDelivery(ctx, webhookID, id); err != nil {
+			return models.WebhookDelivery{}, err
+		}
+		return models.WebhookDelivery{}, ErrDeliveryPending
+	}
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	s.logger.Info("Webhook delivery queued for retry", zap.Int("webhook_id", webhookID), zap.Int64("id", id))
+	return delivery, nil
+}
+
+// generateWebhookSecret returns a random signing secret
+func generateWebhookSecret() (string, error) {
+	generated := make([]byte, 32)
+	if _, err := rand.Read(generated); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(generated), nil
+}
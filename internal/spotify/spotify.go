@@ -0,0 +1,187 @@
+package spotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default endpoints of the Spotify accounts service and Web API
+const (
+	DefaultTokenURL = "https://accounts.spotify.com/api/token"
+	DefaultAPIURL   = "https://api.spotify.com/v1"
+)
+
+// ErrNotFound is returned when Spotify has no track matching a song
+var ErrNotFound = errors.New("track not found on Spotify")
+
+// tokenExpiryMargin renews access tokens this long before Spotify expires them
+const tokenExpiryMargin = time.Minute
+
+// Track is the metadata of a Spotify track; fields Spotify does not know are empty
+type Track struct {
+	Album      string
+	DurationMs int
+	ISRC       string
+	ArtworkURL string
+}
+
+// Client looks tracks up in the Spotify Web API, authenticating with the client credentials flow.
+// The access token is cached and renewed shortly before it expires.
+type Client struct {
+	ClientID     string
+	ClientSecret string
+	Client       *http.Client
+
+	// mu guards the endpoints, which Configure changes while lookups run, and the access token
+	mu       sync.Mutex
+	tokenURL string
+	apiURL   string
+	token    string
+	expires  time.Time
+}
+
+// NewClient creates a Client for the default Spotify endpoints
+func NewClient(clientID, clientSecret string, client *http.Client) *Client {
+	return &Client{ClientID: clientID, ClientSecret: clientSecret, tokenURL: DefaultTokenURL, apiURL: DefaultAPIURL, Client: client}
+}
+
+// Configure moves the client to other endpoints while lookups run; an empty URL returns to the default
+// endpoint. Moving to another token endpoint drops the cached access token.
+func (c *Client) Configure(tokenURL, apiURL string) {
+	if tokenURL == "" {
+		tokenURL = DefaultTokenURL
+	}
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	apiURL = strings.TrimSuffix(apiURL, "/")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if tokenURL != c.tokenURL {
+		c.token, c.expires = "", time.Time{}
+	}
+	c.tokenURL, c.apiURL = tokenURL, apiURL
+}
+
+// URLs returns the token and API endpoints the client uses
+func (c *Client) URLs() (tokenURL, apiURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokenURL, c.apiURL
+}
+
+// FromEnv builds the client from SPOTIFY_CLIENT_ID and SPOTIFY_CLIENT_SECRET, returning nil when neither
+// is set. SPOTIFY_TOKEN_URL and SPOTIFY_API_URL override the endpoints.
+func FromEnv(client *http.Client) (*Client, error) {
+	clientID, clientSecret := os.Getenv("SPOTIFY_CLIENT_ID"), os.Getenv("SPOTIFY_CLIENT_SECRET")
+	if clientID == "" && clientSecret == "" {
+		return nil, nil
+	}
+	if clientID == "" || clientSecret == "" {
+		return nil, errors.New("SPOTIFY_CLIENT_ID and SPOTIFY_CLIENT_SECRET must be set together")
+	}
+	c := NewClient(clientID, clientSecret, client)
+	c.Configure(os.Getenv("SPOTIFY_TOKEN_URL"), os.Getenv("SPOTIFY_API_URL"))
+	return c, nil
+}
+
+// SearchTrack returns the best match for the song by the artist
+func (c *Client) SearchTrack(ctx context.Context, artist, title string) (Track, error) {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return Track{}, err
+	}
+	query := url.Values{
+		"q":     {fmt.Sprintf("track:%s artist:%s", title, artist)},
+		"type":  {"track"},
+		"limit": {"1"},
+	}
+	_, apiURL := c.URLs()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"/search?"+query.Encode(), nil)
+	if err != nil {
+		return Track{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var result struct {
+		Tracks struct {
+			Items []struct {
+				DurationMs int `json:"duration_ms"`
+				Album      struct {
+					Name   string `json:"name"`
+					Images []struct {
+						URL string `json:"url"`
+					} `json:"images"`
+				} `json:"album"`
+				ExternalIDs struct {
+					ISRC string `json:"isrc"`
+				} `json:"external_ids"`
+			} `json:"items"`
+		} `json:"tracks"`
+	}
+	if err := c.do(req, &result); err != nil {
+		return Track{}, err
+	}
+	if len(result.Tracks.Items) == 0 {
+		return Track{}, ErrNotFound
+	}
+	item := result.Tracks.Items[0]
+	track := Track{Album: item.Album.Name, DurationMs: item.DurationMs, ISRC: item.ExternalIDs.ISRC}
+	// Spotify lists the artwork largest first
+	if len(item.Album.Images) > 0 {
+		track.ArtworkURL = item.Album.Images[0].URL
+	}
+	return track, nil
+}
+
+// accessToken returns the cached access token, requesting a new one when it is missing or about to expire
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.ClientID, c.ClientSecret)
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := c.do(req, &result); err != nil {
+		return "", fmt.Errorf("spotify token: %w", err)
+	}
+	c.token = result.AccessToken
+	c.expires = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - tokenExpiryMargin)
+	return c.token, nil
+}
+
+// do sends the request and decodes the JSON response into out
+func (c *Client) do(req *http.Request, out any) error {
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("spotify returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
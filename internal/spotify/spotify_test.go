@@ -0,0 +1,84 @@
+package spotify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchTrack(t *testing.T) {
+	tokenRequests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		id, secret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "id", id)
+		assert.Equal(t, "secret", secret)
+		assert.Equal(t, "client_credentials", r.FormValue("grant_type"))
+		w.Write([]byte(`{"access_token": "token", "token_type": "Bearer", "expires_in": 3600}`))
+	})
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if r.URL.Query().Get("q") != "track:Uprising artist:Muse" {
+			w.Write([]byte(`{"tracks": {"items": []}}`))
+			return
+		}
+		w.Write([]byte(`{"tracks": {"items": [{
+			"duration_ms": 304840,
+			"external_ids": {"isrc": "GBAHT0900320"},
+			"album": {"name": "The Resistance", "images": [{"url": "https://i.scdn.co/large", "width": 640}, {"url": "https://i.scdn.co/small", "width": 64}]}
+		}]}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := NewClient("id", "secret", server.Client())
+	client.Configure(server.URL+"/token", server.URL)
+
+	track, err := client.SearchTrack(context.Background(), "Muse", "Uprising")
+	require.NoError(t, err)
+	assert.Equal(t, Track{Album: "The Resistance", DurationMs: 304840, ISRC: "GBAHT0900320", ArtworkURL: "https://i.scdn.co/large"}, track)
+
+	_, err = client.SearchTrack(context.Background(), "Muse", "Unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 1, tokenRequests, "the access token is reused until it expires")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("SPOTIFY_CLIENT_ID", "")
+	t.Setenv("SPOTIFY_CLIENT_SECRET", "")
+	client, err := FromEnv(http.DefaultClient)
+	require.NoError(t, err)
+	assert.Nil(t, client)
+
+	t.Setenv("SPOTIFY_CLIENT_ID", "id")
+	_, err = FromEnv(http.DefaultClient)
+	assert.Error(t, err)
+
+	t.Setenv("SPOTIFY_CLIENT_SECRET", "secret")
+	t.Setenv("SPOTIFY_API_URL", "http://spotify.test/v1/")
+	client, err = FromEnv(http.DefaultClient)
+	require.NoError(t, err)
+	tokenURL, apiURL := client.URLs()
+	assert.Equal(t, DefaultTokenURL, tokenURL)
+	assert.Equal(t, "http://spotify.test/v1", apiURL)
+}
+
+func TestConfigureDropsTokenOfPreviousEndpoint(t *testing.T) {
+	client := NewClient("id", "secret", http.DefaultClient)
+	client.token, client.expires = "token", time.Now().Add(time.Hour)
+
+	client.Configure("", "http://spotify.test/v1/")
+	assert.Equal(t, "token", client.token, "the token outlives a change of the API endpoint")
+
+	client.Configure("http://spotify.test/token", "")
+	assert.Empty(t, client.token)
+	tokenURL, apiURL := client.URLs()
+	assert.Equal(t, "http://spotify.test/token", tokenURL)
+	assert.Equal(t, DefaultAPIURL, apiURL)
+}
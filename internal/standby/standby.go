@@ -0,0 +1,320 @@
+package standby
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"music-library/internal/changes"
+	"music-library/internal/models"
+	"music-library/internal/secrets"
+)
+
+// File names of the log: one NDJSON file per UTC day, gzipped once the day is over
+const (
+	filePrefix = "changes-"
+	fileSuffix = ".ndjson"
+	gzipSuffix = ".ndjson.gz"
+	dayLayout  = "2006-01-02"
+)
+
+// uploadTries is the number of attempts to upload a finished day, uploadBackoff times the attempt apart
+const (
+	uploadTries   = 3
+	uploadBackoff = 5 * time.Second
+)
+
+// Store reads the songs whose state is logged with their events
+type Store interface {
+	GetSongByID(ctx context.Context, id int) (models.Song, error)
+}
+
+// Record is a line of the log: a catalog event and, for the creation or update of a song, the song as it was
+// stored by the change. Replaying the records in ID order, skipping the IDs already seen, rebuilds the songs.
+type Record struct {
+	changes.Event
+	Song *models.Song `json:"song,omitempty"`
+}
+
+// Config locates the log
+type Config struct {
+	// Dir is the directory the daily files are written to; the log is off without it
+	Dir string
+	// UploadURL is the bucket prefix the finished days are sent to with an HTTP PUT of <UploadURL>/<file name>,
+	// as accepted by the XML APIs of S3-compatible and GCS buckets with a pre-authorized URL or a bearer token.
+	// Finished days are only kept in Dir without it.
+	UploadURL string
+	// UploadToken is sent as a bearer token with the uploads when set
+	UploadToken string
+}
+
+// ConfigFromEnv reads the log configuration from STANDBY_LOG_DIR, STANDBY_UPLOAD_URL and STANDBY_UPLOAD_TOKEN,
+// the token being read from the file named by STANDBY_UPLOAD_TOKEN_FILE when not set
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Dir:       os.Getenv("STANDBY_LOG_DIR"),
+		UploadURL: strings.TrimSuffix(os.Getenv("STANDBY_UPLOAD_URL"), "/"),
+	}
+	if cfg.UploadURL != "" && cfg.Dir == "" {
+		return cfg, fmt.Errorf("STANDBY_UPLOAD_URL requires STANDBY_LOG_DIR")
+	}
+	token, err := secrets.Read("STANDBY_UPLOAD_TOKEN")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.UploadToken = token
+	return cfg, nil
+}
+
+// Log appends every catalog event to daily NDJSON files, an append-only copy of the change feed kept apart
+// from the database to rebuild the catalog from when its backups fail. A file is gzipped, and uploaded when an
+// upload URL is configured, once its day is over. Events are appended at least once, as relayed from the outbox.
+type Log struct {
+	store  Store
+	client *http.Client
+	logger *zap.Logger
+	cfg    Config
+	now    func() time.Time
+
+	mu   sync.Mutex
+	day  string
+	file *os.File
+	// finished receives the paths of the files of the days that are over
+	finished chan string
+	wg       sync.WaitGroup
+}
+
+// NewLog creates a log writing to the configured directory, created if missing
+func NewLog(store Store, client *http.Client, logger *zap.Logger, cfg Config) (*Log, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create standby log directory: %w", err)
+	}
+	return &Log{
+		store:    store,
+		client:   client,
+		logger:   logger,
+		cfg:      cfg,
+		now:      time.Now,
+		finished: make(chan string, 16),
+	}, nil
+}
+
+// Start finishes the files of past days left by a previous process, then the days that end from now on,
+// until ctx is cancelled
+func (l *Log) Start(ctx context.Context) {
+	l.logger.Info("Starting standby log", zap.String("dir", l.cfg.Dir), zap.Bool("upload", l.cfg.UploadURL != ""))
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		for _, path := range l.pastFiles() {
+			l.finish(ctx, path)
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case path := <-l.finished:
+				l.finish(ctx, path)
+			}
+		}
+	}()
+}
+
+// Wait blocks until the log has stopped, then closes the file of the current day
+func (l *Log) Wait() {
+	l.wg.Wait()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file != nil {
+		if err := l.file.Close(); err != nil {
+			l.logger.Error("Failed to close standby log", zap.Error(err))
+		}
+		l.file = nil
+	}
+}
+
+// Append writes the record of an event to the file of the current day. It is the outbox relay subscriber of
+// the log, so an event that fails to be written is relayed again.
+func (l *Log) Append(ctx context.Context, event changes.Event) error {
+	record := Record{Event: event}
+	if event.SongID != 0 && (event.Type == changes.EventSongCreated || event.Type == changes.EventSongUpdated) {
+		song, err := l.store.GetSongByID(ctx, event.SongID)
+		switch {
+		case err == nil:
+			record.Song = &song
+		case err != sql.ErrNoRows:
+			return err
+		}
+		// A song deleted since is logged without its state, its deletion following
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.rotate(); err != nil {
+		return err
+	}
+	if _, err := l.file.Write(line); err != nil {
+		l.logger.Error("Failed to append to standby log", zap.Uint64("event_id", event.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// rotate opens the file of the current day, handing the file of the previous one to be finished
+func (l *Log) rotate() error {
+	day := l.now().UTC().Format(dayLayout)
+	if l.file != nil && day == l.day {
+		return nil
+	}
+	if l.file != nil {
+		path := l.file.Name()
+		if err := l.file.Close(); err != nil {
+			l.logger.Error("Failed to close standby log", zap.String("path", path), zap.Error(err))
+		}
+		l.file = nil
+		select {
+		case l.finished <- path:
+		default:
+			// Picked up on the next start
+			l.logger.Warn("Standby log finishing is behind", zap.String("path", path))
+		}
+	}
+	file, err := os.OpenFile(filepath.Join(l.cfg.Dir, filePrefix+day+fileSuffix), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		l.logger.Error("Failed to open standby log", zap.String("day", day), zap.Error(err))
+		return err
+	}
+	l.day, l.file = day, file
+	return nil
+}
+
+// pastFiles lists the files of the days before the current one that are not gzipped yet, oldest first
+func (l *Log) pastFiles() []string {
+	paths, err := filepath.Glob(filepath.Join(l.cfg.Dir, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return nil
+	}
+	today := filePrefix + l.now().UTC().Format(dayLayout) + fileSuffix
+	past := paths[:0]
+	for _, path := range paths {
+		if filepath.Base(path) < today {
+			past = append(past, path)
+		}
+	}
+	sort.Strings(past)
+	return past
+}
+
+// finish gzips the file of a day that is over and uploads it
+func (l *Log) finish(ctx context.Context, path string) {
+	compressed, err := compress(path)
+	if err != nil {
+		l.logger.Error("Failed to compress standby log", zap.String("path", path), zap.Error(err))
+		return
+	}
+	l.logger.Info("Standby log rotated", zap.String("path", compressed))
+	if l.cfg.UploadURL == "" {
+		return
+	}
+	for try := 1; ; try++ {
+		err := l.upload(ctx, compressed)
+		if err == nil {
+			l.logger.Info("Standby log uploaded", zap.String("path", compressed))
+			return
+		}
+		if try >= uploadTries || ctx.Err() != nil {
+			l.logger.Error("Failed to upload standby log", zap.String("path", compressed), zap.Error(err))
+			return
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Duration(try) * uploadBackoff):
+		}
+	}
+}
+
+// compress replaces a file with its gzipped copy and returns the path of the copy
+func compress(path string) (string, error) {
+	source, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+	target := strings.TrimSuffix(path, fileSuffix) + gzipSuffix
+	// Written aside first, so a crash never leaves a truncated copy under the final name
+	partial, err := os.Create(target + ".tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(partial.Name())
+	writer := bufio.NewWriter(partial)
+	zw := gzip.NewWriter(writer)
+	zw.Name = filepath.Base(path)
+	if _, err := io.Copy(zw, source); err != nil {
+		partial.Close()
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		partial.Close()
+		return "", err
+	}
+	if err := writer.Flush(); err != nil {
+		partial.Close()
+		return "", err
+	}
+	if err := partial.Sync(); err != nil {
+		partial.Close()
+		return "", err
+	}
+	if err := partial.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(partial.Name(), target); err != nil {
+		return "", err
+	}
+	return target, os.Remove(path)
+}
+
+// upload sends a gzipped file to the bucket
+func (l *Log) upload(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, l.cfg.UploadURL+"/"+filepath.Base(path), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+	if l.cfg.UploadToken != "" {
+		req.Header.Set("Authorization", "Bearer "+l.cfg.UploadToken)
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("bucket responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
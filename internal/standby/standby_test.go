@@ -0,0 +1,109 @@
+package standby
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/changes"
+	"music-library/internal/models"
+)
+
+// songStore serves songs from a map
+type songStore map[int]models.Song
+
+func (s songStore) GetSongByID(_ context.Context, id int) (models.Song, error) {
+	song, ok := s[id]
+	if !ok {
+		return models.Song{}, sql.ErrNoRows
+	}
+	return song, nil
+}
+
+func TestLogAppendsRecordsAndRotatesDays(t *testing.T) {
+	uploaded := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		io.Copy(io.Discard, r.Body)
+		uploaded <- r.URL.Path
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	store := songStore{7: {ID: 7, Group: "Muse", Song: "Uprising"}}
+	log, err := NewLog(store, server.Client(), zap.NewNop(), Config{Dir: dir, UploadURL: server.URL + "/dr", UploadToken: "secret"})
+	require.NoError(t, err)
+	now := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+	log.now = func() time.Time { return now }
+	ctx, cancel := context.WithCancel(context.Background())
+	log.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		log.Wait()
+	})
+
+	require.NoError(t, log.Append(ctx, changes.Event{ID: 1, Type: changes.EventSongCreated, SongID: 7}))
+	require.NoError(t, log.Append(ctx, changes.Event{ID: 2, Type: changes.EventSongUpdated, SongID: 8}))
+	now = now.Add(time.Minute)
+	require.NoError(t, log.Append(ctx, changes.Event{ID: 3, Type: changes.EventSongDeleted, SongID: 7}))
+
+	select {
+	case path := <-uploaded:
+		assert.Equal(t, "/dr/changes-2026-03-01.ndjson.gz", path)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the finished day was not uploaded")
+	}
+
+	file, err := os.Open(filepath.Join(dir, "changes-2026-03-01.ndjson.gz"))
+	require.NoError(t, err)
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	var records []Record
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		var record Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 2)
+	if assert.NotNil(t, records[0].Song) {
+		assert.Equal(t, "Uprising", records[0].Song.Song)
+	}
+	assert.Nil(t, records[1].Song, "a song deleted since is logged without its state")
+	assert.NoFileExists(t, filepath.Join(dir, "changes-2026-03-01.ndjson"))
+	assert.FileExists(t, filepath.Join(dir, "changes-2026-03-02.ndjson"))
+}
+
+func TestLogFinishesPastDaysOnStart(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "changes-2026-02-27.ndjson"), []byte("{}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "changes-2026-03-01.ndjson"), []byte("{}\n"), 0o644))
+	log, err := NewLog(songStore{}, nil, zap.NewNop(), Config{Dir: dir})
+	require.NoError(t, err)
+	log.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+	assert.Equal(t, []string{filepath.Join(dir, "changes-2026-02-27.ndjson")}, log.pastFiles())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	log.Start(ctx)
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, "changes-2026-02-27.ndjson.gz"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	log.Wait()
+	assert.FileExists(t, filepath.Join(dir, "changes-2026-03-01.ndjson"), "the current day is left open")
+}
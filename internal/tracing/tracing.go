@@ -0,0 +1,171 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// HeaderRequestID carries the ID of a request to and from the service and to the providers it calls
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs accepted from clients, longer ones are replaced
+const maxRequestIDLength = 128
+
+// Attributes set on the spans of outbound calls
+const (
+	AttributeProvider   = attribute.Key("provider")
+	AttributeRequestID  = attribute.Key("request_id")
+	AttributeMethod     = attribute.Key("http.request.method")
+	AttributeServer     = attribute.Key("server.address")
+	AttributePath       = attribute.Key("url.path")
+	AttributeStatusCode = attribute.Key("http.response.status_code")
+	AttributeDurationMS = attribute.Key("http.client.duration_ms")
+)
+
+// propagator writes and reads the W3C traceparent and tracestate headers. It is used directly rather than
+// through the global propagator, so traces are propagated whether or not an SDK is configured.
+var propagator = propagation.TraceContext{}
+
+// requestIDKey is the context key of the request ID
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by the context, or an empty string
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random request ID
+func NewRequestID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// ValidRequestID reports whether a request ID received from a client can be passed on: not empty, not
+// too long and made of printable ASCII, so it cannot break the headers or logs it is written to
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Extract returns the context continuing the trace of the traceparent header of an incoming request, or
+// starting a new trace when the request carries none
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return ensureTrace(propagator.Extract(ctx, propagation.HeaderCarrier(header)))
+}
+
+// ensureTrace returns the context unchanged when it carries a trace, and otherwise a context carrying a
+// new random trace. No SDK records spans, so without it the spans started under the context would have
+// no trace ID to propagate to the providers.
+func ensureTrace(ctx context.Context) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+	rand.Read(traceID[:])
+	rand.Read(spanID[:])
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+}
+
+// Wrap returns a copy of the client whose requests run in a client span named after the provider. The
+// trace and request ID of the request's context are sent in the traceparent and X-Request-ID headers,
+// starting a new trace for requests made outside of one, and the response status and time of the
+// provider are recorded on the span and logged with the trace and request ID, so a slow or failing
+// lookup can be followed into the provider's own traces and logs.
+func Wrap(provider string, client *http.Client, logger *zap.Logger) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped := *client
+	wrapped.Transport = &transport{
+		provider: provider,
+		tracer:   otel.Tracer("music-library/internal/tracing"),
+		logger:   logger,
+		base:     base,
+	}
+	return &wrapped
+}
+
+// transport traces the requests it sends
+type transport struct {
+	provider string
+	tracer   trace.Tracer
+	logger   *zap.Logger
+	base     http.RoundTripper
+}
+
+// RoundTrip sends the request with the propagation headers, within a span recording its outcome
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := t.tracer.Start(ensureTrace(req.Context()), "HTTP "+req.Method+" "+t.provider,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			AttributeProvider.String(t.provider),
+			AttributeMethod.String(req.Method),
+			AttributeServer.String(req.URL.Host),
+			AttributePath.String(req.URL.Path),
+		))
+	defer span.End()
+
+	// The request is cloned, as a RoundTripper must not modify the request it is given
+	req = req.Clone(ctx)
+	propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if id := RequestID(ctx); id != "" {
+		req.Header.Set(HeaderRequestID, id)
+		span.SetAttributes(AttributeRequestID.String(id))
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	duration := float64(time.Since(start).Microseconds()) / 1000
+	span.SetAttributes(AttributeDurationMS.Float64(duration))
+	fields := []zap.Field{
+		zap.String("provider", t.provider),
+		zap.String("method", req.Method),
+		zap.String("path", req.URL.Path),
+		zap.Float64("duration_ms", duration),
+		zap.String("trace_id", span.SpanContext().TraceID().String()),
+	}
+	if id := RequestID(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		t.logger.Warn("Provider call failed", append(fields, zap.Error(err))...)
+		return nil, err
+	}
+	span.SetAttributes(AttributeStatusCode.Int(resp.StatusCode))
+	fields = append(fields, zap.Int("status", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, resp.Status)
+		t.logger.Warn("Provider call failed", fields...)
+		return resp, nil
+	}
+	t.logger.Info("Provider call finished", fields...)
+	return resp, nil
+}
@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWrapPropagatesHeaders(t *testing.T) {
+	var traceparent, requestID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent, requestID = r.Header.Get("traceparent"), r.Header.Get(HeaderRequestID)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	parent := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	ctx := WithRequestID(trace.ContextWithSpanContext(context.Background(), parent), "req-1")
+
+	core, logs := observer.New(zap.InfoLevel)
+	client := Wrap("info", server.Client(), zap.New(core))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/info", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.True(t, strings.HasPrefix(traceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-"), traceparent)
+	assert.Equal(t, "req-1", requestID)
+	assert.Empty(t, req.Header.Get(HeaderRequestID), "the caller's request is left unmodified")
+	if entries := logs.FilterMessage("Provider call finished").AllUntimed(); assert.Len(t, entries, 1) {
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "info", fields["provider"])
+		assert.EqualValues(t, http.StatusNoContent, fields["status"])
+		assert.Contains(t, fields, "duration_ms")
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields["trace_id"])
+		assert.Equal(t, "req-1", fields["request_id"])
+	}
+
+	// Without a trace a new one is started, and without a request ID none is sent
+	req, err = http.NewRequest(http.MethodGet, server.URL+"/info", nil)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Regexp(t, "^00-[0-9a-f]{32}-[0-9a-f]{16}-00$", traceparent)
+	assert.NotContains(t, traceparent, "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Empty(t, requestID)
+}
+
+func TestExtract(t *testing.T) {
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	span := trace.SpanContextFromContext(Extract(context.Background(), header))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID().String())
+	assert.True(t, span.IsRemote())
+
+	// A request without a traceparent starts a new trace
+	assert.True(t, trace.SpanContextFromContext(Extract(context.Background(), http.Header{})).IsValid())
+}
+
+func TestValidRequestID(t *testing.T) {
+	assert.True(t, ValidRequestID("b7c1e2f0-request"))
+	assert.False(t, ValidRequestID(""))
+	assert.False(t, ValidRequestID("two words"))
+	assert.False(t, ValidRequestID("line\nbreak"))
+	assert.False(t, ValidRequestID(strings.Repeat("a", maxRequestIDLength+1)))
+	assert.Len(t, NewRequestID(), 32)
+}
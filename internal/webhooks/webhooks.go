@@ -0,0 +1,427 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"music-library/internal/changes"
+	"music-library/internal/models"
+)
+
+// Headers of a delivery request. The signature is the hex HMAC-SHA256 of the timestamp, a dot and the body,
+// keyed with the webhook secret, so receivers can check the sender and reject replays of old deliveries.
+// For a grace period after the secret is rotated, the signature with the previous secret follows, comma
+// separated, so receivers can switch to the new secret at their own pace.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// maxResponseBody is the number of bytes of a response body read to keep the connection reusable
+const maxResponseBody = 64 << 10
+
+// Store persists the deliveries
+type Store interface {
+	EnqueueWebhookDeliveries(ctx context.Context, eventType string, payload []byte) (int64, error)
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration, exclude []int) ([]models.DueDelivery, error)
+	DeferWebhookDelivery(ctx context.Context, id int64, after time.Duration) error
+	FinishWebhookDelivery(ctx context.Context, id int64, status string, responseStatus *int, message *string, retryAfter time.Duration) error
+}
+
+// Config sizes the delivery workers and their retries
+type Config struct {
+	// Workers is the number of deliveries sent concurrently
+	Workers int
+	// TargetConcurrency is the number of deliveries sent concurrently to a single webhook, so a slow
+	// receiver holds no more workers than that
+	TargetConcurrency int
+	// TargetRate is the number of deliveries per second sent to a single webhook; 0 does not limit it
+	TargetRate float64
+	// SecretGracePeriod is how long deliveries are also signed with the previous secret of a webhook after
+	// its secret is rotated
+	SecretGracePeriod time.Duration
+	// BatchSize is the largest number of due deliveries claimed at once
+	BatchSize int
+	// PollInterval is the time between two checks for deliveries due for a retry
+	PollInterval time.Duration
+	// Timeout bounds a single delivery request
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts after which a delivery is failed
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry, doubled for every further one up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultConfig is used for the values NewDispatcher is not given
+var DefaultConfig = Config{
+	Workers:           8,
+	TargetConcurrency: 2,
+	SecretGracePeriod: 24 * time.Hour,
+	BatchSize:         10,
+	PollInterval:      5 * time.Second,
+	Timeout:           10 * time.Second,
+	MaxAttempts:       8,
+	BaseDelay:         10 * time.Second,
+	MaxDelay:          time.Hour,
+}
+
+// ConfigFromEnv reads the delivery configuration from WEBHOOK_WORKERS, WEBHOOK_TARGET_CONCURRENCY,
+// WEBHOOK_TARGET_RATE, WEBHOOK_SECRET_GRACE_PERIOD, WEBHOOK_TIMEOUT, WEBHOOK_MAX_ATTEMPTS,
+// WEBHOOK_RETRY_BASE_DELAY and WEBHOOK_RETRY_MAX_DELAY
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig
+	for _, setting := range []struct {
+		key   string
+		value *int
+	}{
+		{"WEBHOOK_WORKERS", &cfg.Workers},
+		{"WEBHOOK_TARGET_CONCURRENCY", &cfg.TargetConcurrency},
+		{"WEBHOOK_MAX_ATTEMPTS", &cfg.MaxAttempts},
+	} {
+		if value := os.Getenv(setting.key); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				return cfg, fmt.Errorf("%s must be a positive number: %q", setting.key, value)
+			}
+			*setting.value = parsed
+		}
+	}
+	for _, setting := range []struct {
+		key   string
+		value *time.Duration
+	}{
+		{"WEBHOOK_TIMEOUT", &cfg.Timeout},
+		{"WEBHOOK_RETRY_BASE_DELAY", &cfg.BaseDelay},
+		{"WEBHOOK_RETRY_MAX_DELAY", &cfg.MaxDelay},
+		{"WEBHOOK_SECRET_GRACE_PERIOD", &cfg.SecretGracePeriod},
+	} {
+		if value := os.Getenv(setting.key); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed <= 0 {
+				return cfg, fmt.Errorf("%s must be a positive duration: %q", setting.key, value)
+			}
+			*setting.value = parsed
+		}
+	}
+	if value := os.Getenv("WEBHOOK_TARGET_RATE"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			return cfg, fmt.Errorf("WEBHOOK_TARGET_RATE must be a non-negative number: %q", value)
+		}
+		cfg.TargetRate = parsed
+	}
+	if cfg.TargetConcurrency > cfg.Workers {
+		cfg.TargetConcurrency = cfg.Workers
+	}
+	return cfg, nil
+}
+
+// Sign returns the signature of a delivery body sent at the timestamp, as found in the signature header
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher queues a delivery for every catalog event a webhook subscribes to and sends the queued
+// deliveries, retrying failures with exponential backoff. Deliveries are stored before they are sent, so
+// they survive restarts; a delivery may be sent more than once, and receivers dedupe by its ID.
+//
+// Every webhook gets at most TargetConcurrency of the workers and TargetRate deliveries per second. The
+// deliveries to a webhook at its limits are left in the queue, or handed back when claimed, so the
+// deliveries to the other webhooks keep flowing while one receiver is slow.
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	logger *zap.Logger
+	cfg    Config
+	// wake claims deliveries early when deliveries were queued or a worker freed up
+	wake chan struct{}
+	// slots holds a token for every delivery being sent
+	slots chan struct{}
+	mu    sync.Mutex
+	// targets tracks the webhooks being delivered to or rate limited
+	targets map[int]*target
+	wg      sync.WaitGroup
+}
+
+// target is the state of the deliveries to a single webhook
+type target struct {
+	inflight int
+	// limiter is nil when the rate is not limited
+	limiter *rate.Limiter
+}
+
+// NewDispatcher creates a dispatcher; a zero configuration takes the defaults
+func NewDispatcher(store Store, client *http.Client, logger *zap.Logger, cfg Config) *Dispatcher {
+	if cfg.Workers < 1 {
+		cfg.Workers = DefaultConfig.Workers
+	}
+	if cfg.TargetConcurrency < 1 || cfg.TargetConcurrency > cfg.Workers {
+		cfg.TargetConcurrency = min(DefaultConfig.TargetConcurrency, cfg.Workers)
+	}
+	if cfg.SecretGracePeriod <= 0 {
+		cfg.SecretGracePeriod = DefaultConfig.SecretGracePeriod
+	}
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = DefaultConfig.BatchSize
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = DefaultConfig.MaxAttempts
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultConfig.PollInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultConfig.Timeout
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = DefaultConfig.BaseDelay
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = DefaultConfig.MaxDelay
+	}
+	return &Dispatcher{
+		store:   store,
+		client:  client,
+		logger:  logger,
+		cfg:     cfg,
+		wake:    make(chan struct{}, 1),
+		slots:   make(chan struct{}, cfg.Workers),
+		targets: make(map[int]*target),
+	}
+}
+
+// Start claims the queued deliveries and sends them on the workers until ctx is cancelled. Deliveries left
+// pending by a previous process are sent too.
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.logger.Info("Starting webhook dispatcher", zap.Int("workers", d.cfg.Workers), zap.Int("target_concurrency", d.cfg.TargetConcurrency),
+		zap.Float64("target_rate", d.cfg.TargetRate), zap.Int("max_attempts", d.cfg.MaxAttempts))
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.work(ctx)
+	}()
+}
+
+// Wait blocks until the dispatcher has stopped and the deliveries being sent are recorded
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Enqueue stores a delivery of the event for every webhook subscribed to its type and wakes a worker. It is
+// the outbox relay subscriber of the dispatcher, so the deliveries are stored in the transaction marking the
+// event relayed.
+func (d *Dispatcher) Enqueue(ctx context.Context, event changes.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	count, err := d.store.EnqueueWebhookDeliveries(ctx, event.Type, payload)
+	if err != nil {
+		d.logger.Error("Failed to queue webhook deliveries", zap.String("event_type", event.Type), zap.Uint64("event_id", event.ID), zap.Error(err))
+		return err
+	}
+	if count > 0 {
+		d.signal()
+	}
+	return nil
+}
+
+// signal wakes the claiming loop without blocking
+func (d *Dispatcher) signal() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// work claims due deliveries for the free workers until ctx is cancelled, checking for more every poll
+// interval or when woken
+func (d *Dispatcher) work(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	// A claimed delivery is sent right away, so it is not retried before its request had time to time out
+	lease := 2 * d.cfg.Timeout
+	for {
+		limit := min(d.cfg.Workers-len(d.slots), d.cfg.BatchSize)
+		claimed := 0
+		if limit > 0 {
+			deliveries, err := d.store.ClaimWebhookDeliveries(ctx, limit, lease, d.saturated())
+			if err != nil && ctx.Err() == nil {
+				d.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+			}
+			for _, delivery := range deliveries {
+				d.dispatch(ctx, delivery)
+			}
+			claimed = len(deliveries)
+		}
+		if claimed > 0 && claimed == limit {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// saturated returns the webhooks that cannot take another delivery now, which are not claimed for
+func (d *Dispatcher) saturated() []int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	ids := []int{}
+	for id, t := range d.targets {
+		if t.inflight >= d.cfg.TargetConcurrency || t.limiter != nil && t.limiter.Tokens() < 1 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// dispatch sends a claimed delivery on a worker, or hands it back when its webhook is at its limits, for
+// when the webhook frees up
+func (d *Dispatcher) dispatch(ctx context.Context, delivery models.DueDelivery) {
+	d.mu.Lock()
+	t := d.targets[delivery.WebhookID]
+	if t == nil {
+		t = &target{}
+		if d.cfg.TargetRate > 0 {
+			t.limiter = rate.NewLimiter(rate.Limit(d.cfg.TargetRate), d.cfg.TargetConcurrency)
+		}
+		d.targets[delivery.WebhookID] = t
+	}
+	wait := time.Duration(0)
+	if t.inflight >= d.cfg.TargetConcurrency {
+		wait = d.cfg.PollInterval
+	} else if t.limiter != nil {
+		reservation := t.limiter.Reserve()
+		if wait = reservation.Delay(); wait > 0 {
+			reservation.Cancel()
+		}
+	}
+	if wait > 0 {
+		d.mu.Unlock()
+		d.logger.Debug("Deferring webhook delivery", zap.Int64("delivery_id", delivery.ID), zap.Int("webhook_id", delivery.WebhookID), zap.Duration("wait", wait))
+		if err := d.store.DeferWebhookDelivery(context.WithoutCancel(ctx), delivery.ID, wait); err != nil {
+			d.logger.Error("Failed to defer webhook delivery", zap.Int64("delivery_id", delivery.ID), zap.Error(err))
+		}
+		return
+	}
+	t.inflight++
+	d.mu.Unlock()
+
+	d.slots <- struct{}{}
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.deliver(ctx, delivery)
+		d.release(delivery.WebhookID)
+	}()
+}
+
+// release frees the worker and the webhook slot of a finished delivery, forgetting a webhook left idle with
+// its full rate allowance, and wakes the claiming loop
+func (d *Dispatcher) release(webhookID int) {
+	d.mu.Lock()
+	if t := d.targets[webhookID]; t != nil {
+		t.inflight--
+		if t.inflight == 0 && (t.limiter == nil || t.limiter.Tokens() >= float64(t.limiter.Burst())) {
+			delete(d.targets, webhookID)
+		}
+	}
+	d.mu.Unlock()
+	<-d.slots
+	d.signal()
+}
+
+// deliver sends a delivery and records its outcome, scheduling a retry after a failure until the attempts
+// run out
+func (d *Dispatcher) deliver(ctx context.Context, delivery models.DueDelivery) {
+	logger := d.logger.With(zap.Int64("delivery_id", delivery.ID), zap.Int("webhook_id", delivery.WebhookID), zap.Int("attempt", delivery.Attempts))
+	responseStatus, err := d.send(ctx, delivery)
+	status, retryAfter := models.DeliveryDelivered, time.Duration(0)
+	var message *string
+	if err != nil {
+		text := err.Error()
+		message = &text
+		status, retryAfter = models.DeliveryPending, d.backoff(delivery.Attempts)
+		if delivery.Attempts >= d.cfg.MaxAttempts {
+			status = models.DeliveryFailed
+		}
+		logger.Warn("Webhook delivery failed", zap.String("status", status), zap.Duration("retry_after", retryAfter), zap.Error(err))
+	} else {
+		logger.Info("Webhook delivered", zap.String("event_type", delivery.EventType))
+	}
+	if err := d.store.FinishWebhookDelivery(context.WithoutCancel(ctx), delivery.ID, status, responseStatus, message, retryAfter); err != nil {
+		logger.Error("Failed to record webhook delivery", zap.Error(err))
+	}
+}
+
+// send posts the delivery, failing on any response but a 2xx one
+func (d *Dispatcher) send(ctx context.Context, delivery models.DueDelivery) (*int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "music-library-webhooks")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, d.signature(delivery, timestamp))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+	status := resp.StatusCode
+	if status < 200 || status > 299 {
+		return &status, fmt.Errorf("unexpected response status %d", status)
+	}
+	return &status, nil
+}
+
+// signature returns the signature header of the delivery, adding the signature with the previous secret of
+// the webhook during the grace period of a rotation
+func (d *Dispatcher) signature(delivery models.DueDelivery, timestamp string) string {
+	signature := Sign(delivery.Secret, timestamp, delivery.Payload)
+	if delivery.PreviousSecret != nil && delivery.SecretRotatedAt != nil && time.Since(*delivery.SecretRotatedAt) < d.cfg.SecretGracePeriod {
+		signature += "," + Sign(*delivery.PreviousSecret, timestamp, delivery.Payload)
+	}
+	return signature
+}
+
+// backoff returns the wait before the retry following the attempt
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.BaseDelay
+	for i := 1; i < attempt && delay < d.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.MaxDelay)
+}
@@ -0,0 +1,308 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"music-library/internal/changes"
+	"music-library/internal/models"
+)
+
+// memoryStore keeps deliveries in memory, to webhooks identified by their position in urls from 1
+type memoryStore struct {
+	mu         sync.Mutex
+	urls       []string
+	secret     string
+	deliveries []*models.WebhookDelivery
+	finished   chan int64
+}
+
+func newMemoryStore(urls ...string) *memoryStore {
+	return &memoryStore{urls: urls, secret: "s3cret", finished: make(chan int64, 16)}
+}
+
+func (s *memoryStore) EnqueueWebhookDeliveries(_ context.Context, eventType string, payload []byte) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.urls {
+		s.deliveries = append(s.deliveries, &models.WebhookDelivery{
+			ID:            int64(len(s.deliveries) + 1),
+			WebhookID:     i + 1,
+			EventType:     eventType,
+			Payload:       payload,
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+	return int64(len(s.urls)), nil
+}
+
+func (s *memoryStore) ClaimWebhookDeliveries(_ context.Context, limit int, lease time.Duration, exclude []int) ([]models.DueDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []models.DueDelivery
+	for _, delivery := range s.deliveries {
+		if len(due) == limit || delivery.Status != models.DeliveryPending || delivery.NextAttemptAt.After(time.Now()) ||
+			slices.Contains(exclude, delivery.WebhookID) {
+			continue
+		}
+		delivery.Attempts++
+		delivery.NextAttemptAt = time.Now().Add(lease)
+		due = append(due, models.DueDelivery{WebhookDelivery: *delivery, URL: s.urls[delivery.WebhookID-1], Secret: s.secret})
+	}
+	return due, nil
+}
+
+func (s *memoryStore) DeferWebhookDelivery(_ context.Context, id int64, after time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delivery := s.deliveries[id-1]
+	delivery.Attempts--
+	delivery.NextAttemptAt = time.Now().Add(after)
+	return nil
+}
+
+func (s *memoryStore) FinishWebhookDelivery(_ context.Context, id int64, status string, responseStatus *int, message *string, retryAfter time.Duration) error {
+	s.mu.Lock()
+	delivery := s.deliveries[id-1]
+	delivery.Status = status
+	delivery.ResponseStatus = responseStatus
+	delivery.Error = message
+	delivery.NextAttemptAt = time.Now().Add(retryAfter)
+	s.mu.Unlock()
+	s.finished <- id
+	return nil
+}
+
+func (s *memoryStore) delivery(id int64) models.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.deliveries[id-1]
+}
+
+// waitFinished waits for the next recorded delivery outcome
+func (s *memoryStore) waitFinished(t *testing.T) int64 {
+	select {
+	case id := <-s.finished:
+		return id
+	case <-time.After(5 * time.Second):
+		t.Fatal("no delivery finished")
+		return 0
+	}
+}
+
+func TestSign(t *testing.T) {
+	signature := Sign("secret", "1700000000", []byte(`{"type":"song.created"}`))
+	assert.Regexp(t, "^sha256=[0-9a-f]{64}$", signature)
+	assert.Equal(t, signature, Sign("secret", "1700000000", []byte(`{"type":"song.created"}`)))
+	assert.NotEqual(t, signature, Sign("other", "1700000000", []byte(`{"type":"song.created"}`)))
+	assert.NotEqual(t, signature, Sign("secret", "1700000001", []byte(`{"type":"song.created"}`)))
+}
+
+func TestSignatureDuringRotation(t *testing.T) {
+	dispatcher := NewDispatcher(nil, nil, zap.NewNop(), Config{SecretGracePeriod: time.Hour})
+	previous := "old"
+	rotatedAt := time.Now().Add(-time.Minute)
+	delivery := models.DueDelivery{WebhookDelivery: models.WebhookDelivery{Payload: []byte(`{}`)}, Secret: "new",
+		PreviousSecret: &previous, SecretRotatedAt: &rotatedAt}
+	assert.Equal(t, Sign("new", "1700000000", []byte(`{}`))+","+Sign("old", "1700000000", []byte(`{}`)),
+		dispatcher.signature(delivery, "1700000000"))
+
+	rotatedAt = time.Now().Add(-2 * time.Hour)
+	assert.Equal(t, Sign("new", "1700000000", []byte(`{}`)), dispatcher.signature(delivery, "1700000000"),
+		"the previous secret is dropped after the grace period")
+}
+
+func TestDispatcherDeliversSignedEvents(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	t.Cleanup(server.Close)
+
+	store := newMemoryStore(server.URL)
+	dispatcher := NewDispatcher(store, server.Client(), zap.NewNop(), Config{PollInterval: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	dispatcher.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		dispatcher.Wait()
+	})
+
+	require.NoError(t, dispatcher.Enqueue(ctx, changes.Event{ID: 3, Type: changes.EventSongUpdated, SongID: 7, OccurredAt: time.Now()}))
+	id := store.waitFinished(t)
+	delivery := store.delivery(id)
+	assert.Equal(t, models.DeliveryDelivered, delivery.Status)
+	require.NotNil(t, delivery.ResponseStatus)
+	assert.Equal(t, http.StatusOK, *delivery.ResponseStatus)
+
+	req, body := <-received, <-bodies
+	assert.Equal(t, changes.EventSongUpdated, req.Header.Get(HeaderEvent))
+	assert.Equal(t, "1", req.Header.Get(HeaderDelivery))
+	assert.Equal(t, Sign("s3cret", req.Header.Get(HeaderTimestamp), body), req.Header.Get(HeaderSignature))
+	assert.JSONEq(t, string(delivery.Payload), string(body))
+	assert.Contains(t, string(body), `"song_id":7`)
+}
+
+func TestDispatcherRetriesFailures(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	store := newMemoryStore(server.URL)
+	_, err := store.EnqueueWebhookDeliveries(context.Background(), changes.EventSongDeleted, []byte(`{}`))
+	require.NoError(t, err)
+	dispatcher := NewDispatcher(store, server.Client(), zap.NewNop(),
+		Config{PollInterval: 10 * time.Millisecond, BaseDelay: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	dispatcher.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		dispatcher.Wait()
+	})
+
+	store.waitFinished(t)
+	delivery := store.delivery(1)
+	assert.Equal(t, models.DeliveryPending, delivery.Status, "a failed delivery is retried")
+	require.NotNil(t, delivery.Error)
+	assert.Equal(t, http.StatusServiceUnavailable, *delivery.ResponseStatus)
+
+	store.waitFinished(t)
+	delivery = store.delivery(1)
+	assert.Equal(t, models.DeliveryDelivered, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+}
+
+func TestDispatcherFailsAfterMaxAttempts(t *testing.T) {
+	store := newMemoryStore("http://127.0.0.1:1/hook")
+	_, err := store.EnqueueWebhookDeliveries(context.Background(), changes.EventSongCreated, []byte(`{}`))
+	require.NoError(t, err)
+	dispatcher := NewDispatcher(store, &http.Client{}, zap.NewNop(),
+		Config{PollInterval: 10 * time.Millisecond, BaseDelay: time.Millisecond, MaxAttempts: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+	dispatcher.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		dispatcher.Wait()
+	})
+
+	store.waitFinished(t)
+	store.waitFinished(t)
+	delivery := store.delivery(1)
+	assert.Equal(t, models.DeliveryFailed, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Nil(t, delivery.ResponseStatus)
+}
+
+func TestBackoff(t *testing.T) {
+	dispatcher := NewDispatcher(nil, nil, zap.NewNop(), Config{BaseDelay: time.Second, MaxDelay: 5 * time.Second})
+	assert.Equal(t, time.Second, dispatcher.backoff(1))
+	assert.Equal(t, 2*time.Second, dispatcher.backoff(2))
+	assert.Equal(t, 4*time.Second, dispatcher.backoff(3))
+	assert.Equal(t, 5*time.Second, dispatcher.backoff(4))
+	assert.Equal(t, 5*time.Second, dispatcher.backoff(40))
+}
+
+func TestDispatcherIsolatesSlowTargets(t *testing.T) {
+	release := make(chan struct{})
+	var inflight, peak atomic.Int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peak.Store(max(peak.Load(), inflight.Add(1)))
+		defer inflight.Add(-1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(slow.Close)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(fast.Close)
+
+	store := newMemoryStore(slow.URL, fast.URL)
+	for i := 0; i < 3; i++ {
+		_, err := store.EnqueueWebhookDeliveries(context.Background(), changes.EventSongCreated, []byte(`{}`))
+		require.NoError(t, err)
+	}
+	dispatcher := NewDispatcher(store, &http.Client{}, zap.NewNop(),
+		Config{Workers: 4, TargetConcurrency: 1, PollInterval: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	dispatcher.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		dispatcher.Wait()
+	})
+
+	for i := 0; i < 3; i++ {
+		delivery := store.delivery(store.waitFinished(t))
+		assert.Equal(t, 2, delivery.WebhookID, "only the fast webhook gets its deliveries while the slow one is stuck")
+		assert.Equal(t, models.DeliveryDelivered, delivery.Status)
+	}
+	close(release)
+	for i := 0; i < 3; i++ {
+		delivery := store.delivery(store.waitFinished(t))
+		assert.Equal(t, 1, delivery.WebhookID)
+		assert.Equal(t, 1, delivery.Attempts, "deferring a delivery does not count as an attempt")
+	}
+	assert.Equal(t, int32(1), peak.Load())
+}
+
+func TestDispatcherLimitsTargetRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	store := newMemoryStore(server.URL)
+	for i := 0; i < 4; i++ {
+		_, err := store.EnqueueWebhookDeliveries(context.Background(), changes.EventSongCreated, []byte(`{}`))
+		require.NoError(t, err)
+	}
+	dispatcher := NewDispatcher(store, server.Client(), zap.NewNop(),
+		Config{TargetConcurrency: 1, TargetRate: 20, PollInterval: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	dispatcher.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		dispatcher.Wait()
+	})
+
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, models.DeliveryDelivered, store.delivery(store.waitFinished(t)).Status)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 140*time.Millisecond, "4 deliveries at 20 per second take 150ms")
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("WEBHOOK_TARGET_RATE", "2.5")
+	t.Setenv("WEBHOOK_SECRET_GRACE_PERIOD", "1h")
+	t.Setenv("WEBHOOK_WORKERS", "1")
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, cfg.TargetRate)
+	assert.Equal(t, time.Hour, cfg.SecretGracePeriod)
+	assert.Equal(t, 1, cfg.TargetConcurrency, "a webhook cannot get more than all the workers")
+
+	t.Setenv("WEBHOOK_TARGET_RATE", "-1")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,4 @@
+CREATE TABLE song_views (
+                       song_id INTEGER PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+                       views BIGINT NOT NULL DEFAULT 0
+);
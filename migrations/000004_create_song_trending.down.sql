@@ -0,0 +1,2 @@
+DROP TABLE song_trending;
+DROP TABLE song_view_days;
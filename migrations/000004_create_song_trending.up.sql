@@ -0,0 +1,14 @@
+CREATE TABLE song_view_days (
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       day DATE NOT NULL,
+                       views BIGINT NOT NULL DEFAULT 0,
+                       PRIMARY KEY (song_id, day)
+);
+
+CREATE TABLE song_trending (
+                       song_id INTEGER PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+                       score DOUBLE PRECISION NOT NULL,
+                       computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX song_trending_score_idx ON song_trending (score DESC);
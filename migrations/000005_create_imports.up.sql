@@ -0,0 +1,10 @@
+CREATE TABLE imports (
+                       id VARCHAR(32) PRIMARY KEY,
+                       status VARCHAR(20) NOT NULL DEFAULT 'running',
+                       checkpoint_row INTEGER NOT NULL DEFAULT 0,
+                       created INTEGER NOT NULL DEFAULT 0,
+                       updated INTEGER NOT NULL DEFAULT 0,
+                       failed INTEGER NOT NULL DEFAULT 0,
+                       created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+                       updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+ALTER TABLE songs ADD COLUMN enriched_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX songs_enriched_at_idx ON songs (enriched_at NULLS FIRST, id);
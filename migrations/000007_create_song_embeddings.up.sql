@@ -0,0 +1,16 @@
+-- Semantic search is optional: the table is only created where the pgvector extension is available
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+        CREATE EXTENSION IF NOT EXISTS vector;
+
+        CREATE TABLE song_embeddings (
+                       song_id INTEGER PRIMARY KEY REFERENCES songs (id) ON DELETE CASCADE,
+                       model VARCHAR(255) NOT NULL,
+                       content_hash CHAR(32) NOT NULL,
+                       embedding vector NOT NULL,
+                       updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+        );
+    END IF;
+END
+$$;
@@ -0,0 +1,7 @@
+ALTER TABLE songs ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', song_name), 'A') ||
+    setweight(to_tsvector('simple', group_name), 'B') ||
+    setweight(to_tsvector('simple', COALESCE(text, '')), 'C')
+) STORED;
+
+CREATE INDEX songs_search_vector_idx ON songs USING GIN (search_vector);
@@ -0,0 +1 @@
+DROP TABLE classification_suggestions;
@@ -0,0 +1,14 @@
+CREATE TABLE classification_suggestions (
+                       id SERIAL PRIMARY KEY,
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       kind VARCHAR(20) NOT NULL,
+                       value VARCHAR(100) NOT NULL,
+                       confidence DOUBLE PRECISION NOT NULL,
+                       source VARCHAR(100) NOT NULL,
+                       status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                       created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+                       reviewed_at TIMESTAMP WITH TIME ZONE,
+                       UNIQUE (song_id, kind, value)
+);
+
+CREATE INDEX idx_classification_suggestions_status ON classification_suggestions (status, created_at);
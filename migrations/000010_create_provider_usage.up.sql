@@ -0,0 +1,6 @@
+CREATE TABLE provider_usage (
+                       provider VARCHAR(50) NOT NULL,
+                       day DATE NOT NULL,
+                       calls INTEGER NOT NULL DEFAULT 0,
+                       PRIMARY KEY (provider, day)
+);
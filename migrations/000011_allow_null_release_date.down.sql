@@ -0,0 +1,4 @@
+UPDATE songs SET release_date = '' WHERE release_date IS NULL;
+
+ALTER TABLE songs
+ALTER COLUMN release_date SET NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE songs
+ALTER COLUMN release_date DROP NOT NULL;
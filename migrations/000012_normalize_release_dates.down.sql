@@ -0,0 +1,2 @@
+-- Normalized release dates are valid in the previous schema, so there is nothing to revert.
+SELECT 1;
@@ -0,0 +1,5 @@
+UPDATE songs
+SET release_date = to_char(to_date(release_date, 'YYYY-MM-DD'), 'DD.MM.YYYY')
+WHERE release_date ~ '^\d{4}-\d{2}-\d{2}$';
+
+UPDATE songs SET release_date = NULL WHERE release_date = '';
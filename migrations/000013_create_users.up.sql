@@ -0,0 +1,6 @@
+CREATE TABLE users (
+                       id SERIAL PRIMARY KEY,
+                       username VARCHAR(100) NOT NULL UNIQUE,
+                       password_hash VARCHAR(100) NOT NULL,
+                       created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1 @@
+DROP TABLE user_preferences;
\ No newline at end of file
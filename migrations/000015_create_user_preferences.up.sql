@@ -0,0 +1,8 @@
+CREATE TABLE user_preferences (
+                       user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+                       page_size INTEGER NOT NULL DEFAULT 0,
+                       sort VARCHAR(20) NOT NULL DEFAULT '',
+                       language VARCHAR(35) NOT NULL DEFAULT '',
+                       explicit_filter BOOLEAN NOT NULL DEFAULT FALSE,
+                       updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,12 @@
+CREATE TABLE tags (
+                       id SERIAL PRIMARY KEY,
+                       name VARCHAR(50) NOT NULL UNIQUE
+);
+
+CREATE TABLE song_tags (
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+                       PRIMARY KEY (song_id, tag_id)
+);
+
+CREATE INDEX idx_song_tags_tag_id ON song_tags(tag_id);
@@ -0,0 +1 @@
+DROP TABLE song_listeners;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE song_listeners (
+                       song_id INTEGER PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+                       listeners BIGINT NOT NULL DEFAULT 0,
+                       fetched_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_song_listeners_fetched_at ON song_listeners (fetched_at);
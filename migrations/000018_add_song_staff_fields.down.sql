@@ -0,0 +1,2 @@
+ALTER TABLE songs DROP COLUMN licensing_fee;
+ALTER TABLE songs DROP COLUMN notes;
@@ -0,0 +1,2 @@
+ALTER TABLE songs ADD COLUMN notes TEXT;
+ALTER TABLE songs ADD COLUMN licensing_fee NUMERIC(12, 2);
@@ -0,0 +1,5 @@
+ALTER TABLE songs DROP COLUMN metadata_synced_at;
+ALTER TABLE songs DROP COLUMN artwork_url;
+ALTER TABLE songs DROP COLUMN isrc;
+ALTER TABLE songs DROP COLUMN duration_ms;
+ALTER TABLE songs DROP COLUMN album;
@@ -0,0 +1,6 @@
+ALTER TABLE songs ADD COLUMN album TEXT;
+ALTER TABLE songs ADD COLUMN duration_ms INTEGER;
+ALTER TABLE songs ADD COLUMN isrc TEXT;
+ALTER TABLE songs ADD COLUMN artwork_url TEXT;
+-- metadata_synced_at is set once the track was looked up, found or not, so misses are not retried every run
+ALTER TABLE songs ADD COLUMN metadata_synced_at TIMESTAMP;
@@ -0,0 +1,3 @@
+DROP INDEX idx_songs_pending_enrichment;
+ALTER TABLE songs DROP COLUMN enrichment_error;
+ALTER TABLE songs DROP COLUMN enrichment_status;
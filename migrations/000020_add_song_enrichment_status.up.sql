@@ -0,0 +1,5 @@
+ALTER TABLE songs ADD COLUMN enrichment_status TEXT NOT NULL DEFAULT 'complete'
+    CHECK (enrichment_status IN ('pending_enrichment', 'complete', 'failed'));
+ALTER TABLE songs ADD COLUMN enrichment_error TEXT;
+
+CREATE INDEX idx_songs_pending_enrichment ON songs (id) WHERE enrichment_status = 'pending_enrichment';
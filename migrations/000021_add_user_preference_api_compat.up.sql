@@ -0,0 +1 @@
+ALTER TABLE user_preferences ADD COLUMN api_compat VARCHAR(10) NOT NULL DEFAULT '';
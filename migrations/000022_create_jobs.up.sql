@@ -0,0 +1,17 @@
+CREATE TABLE jobs (
+                       id VARCHAR(32) PRIMARY KEY,
+                       kind VARCHAR(50) NOT NULL,
+                       status VARCHAR(20) NOT NULL DEFAULT 'queued'
+                           CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+                       total INTEGER NOT NULL DEFAULT 0,
+                       processed INTEGER NOT NULL DEFAULT 0,
+                       failed INTEGER NOT NULL DEFAULT 0,
+                       result JSONB,
+                       error TEXT,
+                       created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                       started_at TIMESTAMP WITH TIME ZONE,
+                       finished_at TIMESTAMP WITH TIME ZONE,
+                       updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_jobs_unfinished ON jobs (kind) WHERE status IN ('queued', 'running');
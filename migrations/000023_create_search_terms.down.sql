@@ -0,0 +1,3 @@
+DROP MATERIALIZED VIEW IF EXISTS search_terms;
+DROP INDEX IF EXISTS songs_song_name_trgm_idx;
+DROP INDEX IF EXISTS songs_group_name_trgm_idx;
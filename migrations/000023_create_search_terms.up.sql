@@ -0,0 +1,22 @@
+-- Search suggestions are optional: trigram indexes and the lyric terms are only created where pg_trgm is available
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'pg_trgm') THEN
+        CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+        CREATE INDEX songs_group_name_trgm_idx ON songs USING GIN (group_name gin_trgm_ops);
+        CREATE INDEX songs_song_name_trgm_idx ON songs USING GIN (song_name gin_trgm_ops);
+
+        -- The most widespread lyric words, refreshed periodically by the application
+        CREATE MATERIALIZED VIEW search_terms AS
+            SELECT word AS term, ndoc AS songs
+            FROM ts_stat('SELECT to_tsvector(''simple'', COALESCE(text, '''')) FROM songs')
+            WHERE length(word) >= 3
+            ORDER BY ndoc DESC, word
+            LIMIT 10000;
+
+        CREATE UNIQUE INDEX search_terms_term_idx ON search_terms (term);
+        CREATE INDEX search_terms_term_trgm_idx ON search_terms USING GIN (term gin_trgm_ops);
+    END IF;
+END
+$$;
@@ -0,0 +1,16 @@
+CREATE TABLE api_captures (
+                       id BIGSERIAL PRIMARY KEY,
+                       provider VARCHAR(50) NOT NULL,
+                       method VARCHAR(10) NOT NULL,
+                       url TEXT NOT NULL,
+                       request_headers JSONB NOT NULL,
+                       request_body TEXT,
+                       status INTEGER,
+                       response_headers JSONB,
+                       response_body TEXT,
+                       duration_ms DOUBLE PRECISION NOT NULL,
+                       error TEXT,
+                       created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_captures_provider ON api_captures (provider, id DESC);
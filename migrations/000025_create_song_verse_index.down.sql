@@ -0,0 +1,2 @@
+DROP TABLE song_verses;
+DROP TABLE song_verse_index;
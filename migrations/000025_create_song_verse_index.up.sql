@@ -0,0 +1,16 @@
+CREATE TABLE song_verse_index (
+                       song_id INTEGER PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+                       delimiter TEXT NOT NULL,
+                       content_hash CHAR(32) NOT NULL,
+                       total_verses INTEGER NOT NULL,
+                       indexed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE song_verses (
+                       song_id INTEGER NOT NULL REFERENCES song_verse_index(song_id) ON DELETE CASCADE,
+                       number INTEGER NOT NULL,
+                       label TEXT NOT NULL DEFAULT '',
+                       start_offset INTEGER NOT NULL,
+                       length INTEGER NOT NULL,
+                       PRIMARY KEY (song_id, number)
+);
@@ -0,0 +1,8 @@
+CREATE TABLE song_overrides (
+                       user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       text TEXT NOT NULL,
+                       created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                       updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                       PRIMARY KEY (user_id, song_id)
+);
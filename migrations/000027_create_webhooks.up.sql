@@ -0,0 +1,24 @@
+CREATE TABLE webhooks (
+                       id SERIAL PRIMARY KEY,
+                       url TEXT NOT NULL,
+                       events TEXT[] NOT NULL,
+                       secret TEXT NOT NULL,
+                       created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE webhook_deliveries (
+                       id BIGSERIAL PRIMARY KEY,
+                       webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+                       event_type VARCHAR(50) NOT NULL,
+                       payload JSONB NOT NULL,
+                       status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                       attempts INTEGER NOT NULL DEFAULT 0,
+                       next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                       response_status INTEGER,
+                       error TEXT,
+                       created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                       delivered_at TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, id DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_songs_legal_hold;
+ALTER TABLE songs DROP COLUMN legal_hold;
@@ -0,0 +1,3 @@
+ALTER TABLE songs ADD COLUMN legal_hold BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_songs_legal_hold ON songs (id) WHERE legal_hold;
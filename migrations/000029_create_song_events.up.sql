@@ -0,0 +1,9 @@
+CREATE TABLE song_events (
+                       id BIGSERIAL PRIMARY KEY,
+                       event_type TEXT NOT NULL,
+                       song_id INTEGER,
+                       count INTEGER NOT NULL DEFAULT 0,
+                       occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_song_events_occurred_at ON song_events (occurred_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_song_events_unpublished;
+
+ALTER TABLE song_events DROP COLUMN IF EXISTS published_at;
@@ -0,0 +1,5 @@
+ALTER TABLE song_events ADD COLUMN published_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE song_events SET published_at = occurred_at;
+
+CREATE INDEX idx_song_events_unpublished ON song_events (id) WHERE published_at IS NULL;
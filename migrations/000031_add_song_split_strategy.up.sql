@@ -0,0 +1 @@
+ALTER TABLE songs ADD COLUMN split_strategy TEXT;
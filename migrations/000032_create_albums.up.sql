@@ -0,0 +1,18 @@
+CREATE TABLE albums (
+                       id SERIAL PRIMARY KEY,
+                       title VARCHAR(255) NOT NULL,
+                       group_name VARCHAR(255) NOT NULL,
+                       release_date VARCHAR(10),
+                       artwork_url TEXT,
+                       created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                       updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_timestamp
+    BEFORE UPDATE ON albums
+    FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+ALTER TABLE songs ADD COLUMN album_id INTEGER REFERENCES albums(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_songs_album_id ON songs (album_id) WHERE album_id IS NOT NULL;
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS link_song_artist ON songs;
+DROP FUNCTION IF EXISTS link_song_artist();
+
+DROP INDEX IF EXISTS idx_songs_artist_id;
+ALTER TABLE songs DROP COLUMN IF EXISTS artist_id;
+
+DROP TABLE IF EXISTS artists;
@@ -0,0 +1,41 @@
+CREATE TABLE artists (
+                       id SERIAL PRIMARY KEY,
+                       name VARCHAR(255) NOT NULL UNIQUE,
+                       country CHAR(2),
+                       formed_year INTEGER,
+                       bio TEXT,
+                       created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                       updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_timestamp
+    BEFORE UPDATE ON artists
+    FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+ALTER TABLE songs ADD COLUMN artist_id INTEGER REFERENCES artists(id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_songs_artist_id ON songs (artist_id);
+
+-- Backfill an artist for every group already in the library. Linking the songs is not a change of their
+-- data, so it leaves their updated_at alone.
+INSERT INTO artists (name) SELECT DISTINCT group_name FROM songs ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE songs DISABLE TRIGGER update_timestamp;
+UPDATE songs s SET artist_id = a.id FROM artists a WHERE a.name = s.group_name;
+ALTER TABLE songs ENABLE TRIGGER update_timestamp;
+
+-- Every write path of songs names the group only, so the artist is resolved, and created when new, from it
+CREATE OR REPLACE FUNCTION link_song_artist()
+    RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO artists (name) VALUES (NEW.group_name) ON CONFLICT (name) DO NOTHING;
+    SELECT id INTO NEW.artist_id FROM artists WHERE name = NEW.group_name;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER link_song_artist
+    BEFORE INSERT OR UPDATE OF group_name ON songs
+    FOR EACH ROW
+EXECUTE FUNCTION link_song_artist();
@@ -0,0 +1,2 @@
+ALTER TABLE webhooks DROP COLUMN IF EXISTS secret_rotated_at;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS previous_secret;
@@ -0,0 +1,2 @@
+ALTER TABLE webhooks ADD COLUMN previous_secret TEXT;
+ALTER TABLE webhooks ADD COLUMN secret_rotated_at TIMESTAMP;
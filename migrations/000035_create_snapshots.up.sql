@@ -0,0 +1,8 @@
+CREATE TABLE snapshots (
+                       id SERIAL PRIMARY KEY,
+                       reason VARCHAR(50) NOT NULL,
+                       song_count INTEGER NOT NULL,
+                       data JSONB NOT NULL,
+                       created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                       restored_at TIMESTAMP WITH TIME ZONE
+);
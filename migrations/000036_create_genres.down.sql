@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS song_genres;
+DROP TABLE IF EXISTS genres;
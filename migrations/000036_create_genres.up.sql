@@ -0,0 +1,25 @@
+CREATE TABLE genres (
+                       id SERIAL PRIMARY KEY,
+                       name VARCHAR(100) NOT NULL,
+                       parent_id INTEGER REFERENCES genres(id) ON DELETE RESTRICT,
+                       created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                       updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                       CHECK (parent_id <> id)
+);
+
+CREATE UNIQUE INDEX idx_genres_name ON genres (LOWER(name));
+CREATE INDEX idx_genres_parent_id ON genres (parent_id);
+
+CREATE TRIGGER update_timestamp
+    BEFORE UPDATE ON genres
+    FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+CREATE TABLE song_genres (
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       genre_id INTEGER NOT NULL REFERENCES genres(id) ON DELETE CASCADE,
+                       created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                       PRIMARY KEY (song_id, genre_id)
+);
+
+CREATE INDEX idx_song_genres_genre_id ON song_genres (genre_id);
@@ -0,0 +1,17 @@
+CREATE TABLE song_titles (
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       lang VARCHAR(35) NOT NULL,
+                       title VARCHAR(255) NOT NULL,
+                       kind VARCHAR(20) NOT NULL DEFAULT 'translation',
+                       search_vector tsvector GENERATED ALWAYS AS (setweight(to_tsvector('simple', title), 'A')) STORED,
+                       created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                       updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+                       PRIMARY KEY (song_id, lang)
+);
+
+CREATE INDEX song_titles_search_vector_idx ON song_titles USING GIN (search_vector);
+
+CREATE TRIGGER update_timestamp
+    BEFORE UPDATE ON song_titles
+    FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
@@ -0,0 +1,2 @@
+DROP TABLE song_favorites;
+DROP TABLE song_ratings;
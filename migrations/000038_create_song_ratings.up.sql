@@ -0,0 +1,19 @@
+CREATE TABLE song_ratings (
+                       user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+                       created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                       updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                       PRIMARY KEY (user_id, song_id)
+);
+
+CREATE INDEX song_ratings_song_id_idx ON song_ratings (song_id);
+
+CREATE TABLE song_favorites (
+                       user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                       PRIMARY KEY (user_id, song_id)
+);
+
+CREATE INDEX song_favorites_song_id_idx ON song_favorites (song_id);
@@ -0,0 +1,9 @@
+CREATE TABLE song_revisions (
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       revision INTEGER NOT NULL,
+                       reason VARCHAR(20) NOT NULL,
+                       restored_from INTEGER,
+                       data JSONB NOT NULL,
+                       created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                       PRIMARY KEY (song_id, revision)
+);
@@ -0,0 +1,9 @@
+CREATE TABLE trashed_songs (
+                       id INTEGER PRIMARY KEY,
+                       group_name VARCHAR(255) NOT NULL,
+                       song_name VARCHAR(255) NOT NULL,
+                       data JSONB NOT NULL,
+                       deleted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX trashed_songs_deleted_at_idx ON trashed_songs (deleted_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_song_views_views;
+
+DROP INDEX IF EXISTS idx_songs_lower_group_song;
\ No newline at end of file
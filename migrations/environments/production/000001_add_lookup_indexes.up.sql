@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_songs_lower_group_song ON songs (LOWER(group_name), LOWER(song_name));
+
+CREATE INDEX IF NOT EXISTS idx_song_views_views ON song_views (views DESC);
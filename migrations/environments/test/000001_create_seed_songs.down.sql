@@ -0,0 +1,4 @@
+DELETE FROM songs s USING seed_songs seed
+WHERE s.group_name = seed.group_name AND s.song_name = seed.song_name;
+
+DROP TABLE seed_songs;
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE seed_songs (
+                       group_name VARCHAR(255) NOT NULL,
+                       song_name VARCHAR(255) NOT NULL,
+                       release_date VARCHAR(10),
+                       text TEXT,
+                       link VARCHAR(255),
+                       PRIMARY KEY (group_name, song_name)
+);
+
+INSERT INTO seed_songs (group_name, song_name, release_date, text, link) VALUES
+    ('Muse', 'Supermassive Black Hole', '16.07.2006', E'Ooh baby, don''t you know I suffer?\nOoh baby, can you hear me moan?\n\nYou caught me under false pretenses\nHow long before you let me go?', 'https://www.youtube.com/watch?v=Xsp3_a-PMTw'),
+    ('Muse', 'Uprising', '07.09.2009', E'Paranoia is in bloom\nThe PR transmissions will resume\n\nThey will not force us\nThey will stop degrading us', 'https://www.youtube.com/watch?v=w8KQmps-Sog'),
+    ('Radiohead', 'Karma Police', '25.08.1997', E'Karma police, arrest this man\nHe talks in maths\n\nThis is what you''ll get\nWhen you mess with us', 'https://www.youtube.com/watch?v=1uYWYWPc9HU');
+
+INSERT INTO songs (group_name, song_name, release_date, text, link)
+SELECT seed.group_name, seed.song_name, seed.release_date, seed.text, seed.link
+FROM seed_songs seed
+WHERE NOT EXISTS (
+    SELECT 1 FROM songs s
+    WHERE LOWER(s.group_name) = LOWER(seed.group_name) AND LOWER(s.song_name) = LOWER(seed.song_name)
+);
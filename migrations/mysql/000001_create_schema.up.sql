@@ -0,0 +1,389 @@
+-- The schema of the MySQL backend, for MySQL 8.0 and MariaDB 10.6 or later: the PostgreSQL schema as of 000040
+-- in a single migration. Times are stored in UTC, updated_at columns are maintained by ON UPDATE, and names
+-- compare without case, as the default collation of utf8mb4 does.
+
+CREATE TABLE albums (
+                       id INT AUTO_INCREMENT PRIMARY KEY,
+                       title VARCHAR(255) NOT NULL,
+                       group_name VARCHAR(255) NOT NULL,
+                       release_date VARCHAR(10),
+                       artwork_url TEXT,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE artists (
+                       id INT AUTO_INCREMENT PRIMARY KEY,
+                       name VARCHAR(255) NOT NULL UNIQUE,
+                       country CHAR(2),
+                       formed_year INT,
+                       bio TEXT,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE songs (
+                       id INT AUTO_INCREMENT PRIMARY KEY,
+                       group_name VARCHAR(255) NOT NULL,
+                       song_name VARCHAR(255) NOT NULL,
+                       release_date VARCHAR(10),
+                       text MEDIUMTEXT,
+                       link VARCHAR(255),
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+                       enriched_at DATETIME(6),
+                       notes TEXT,
+                       licensing_fee DECIMAL(12, 2),
+                       album TEXT,
+                       duration_ms INT,
+                       isrc TEXT,
+                       artwork_url TEXT,
+                       -- metadata_synced_at is set once the track was looked up, found or not, so misses are not retried every run
+                       metadata_synced_at DATETIME(6),
+                       enrichment_status VARCHAR(20) NOT NULL DEFAULT 'complete'
+                           CHECK (enrichment_status IN ('pending_enrichment', 'complete', 'failed')),
+                       enrichment_error TEXT,
+                       legal_hold BOOLEAN NOT NULL DEFAULT FALSE,
+                       split_strategy VARCHAR(20),
+                       album_id INT,
+                       artist_id INT,
+                       CONSTRAINT songs_album_id_fkey FOREIGN KEY (album_id) REFERENCES albums(id) ON DELETE SET NULL,
+                       CONSTRAINT songs_artist_id_fkey FOREIGN KEY (artist_id) REFERENCES artists(id) ON DELETE RESTRICT,
+                       INDEX songs_enriched_at_idx (enriched_at, id),
+                       INDEX idx_songs_enrichment_status (enrichment_status, id),
+                       INDEX idx_songs_legal_hold (legal_hold),
+                       -- The search: across every field for matching, and field by field for ranking
+                       FULLTEXT INDEX songs_search_idx (song_name, group_name, text),
+                       FULLTEXT INDEX songs_song_name_search_idx (song_name),
+                       FULLTEXT INDEX songs_group_name_search_idx (group_name),
+                       FULLTEXT INDEX songs_text_search_idx (text)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- Every write path of songs names the group only, so the artist is resolved, and created when new, from it
+CREATE TRIGGER link_song_artist
+    BEFORE INSERT ON songs
+    FOR EACH ROW
+BEGIN
+    INSERT INTO artists (name) VALUES (NEW.group_name) ON DUPLICATE KEY UPDATE id = id;
+    SET NEW.artist_id = (SELECT id FROM artists WHERE name = NEW.group_name);
+END;
+
+CREATE TRIGGER relink_song_artist
+    BEFORE UPDATE ON songs
+    FOR EACH ROW
+BEGIN
+    IF NOT (NEW.group_name <=> OLD.group_name) THEN
+        INSERT INTO artists (name) VALUES (NEW.group_name) ON DUPLICATE KEY UPDATE id = id;
+        SET NEW.artist_id = (SELECT id FROM artists WHERE name = NEW.group_name);
+    END IF;
+END;
+
+CREATE TABLE song_views (
+                       song_id INT PRIMARY KEY,
+                       views BIGINT NOT NULL DEFAULT 0,
+                       CONSTRAINT song_views_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE song_view_days (
+                       song_id INT NOT NULL,
+                       day DATE NOT NULL,
+                       views BIGINT NOT NULL DEFAULT 0,
+                       PRIMARY KEY (song_id, day),
+                       CONSTRAINT song_view_days_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE song_trending (
+                       song_id INT PRIMARY KEY,
+                       score DOUBLE NOT NULL,
+                       computed_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       INDEX song_trending_score_idx (score DESC),
+                       CONSTRAINT song_trending_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE song_listeners (
+                       song_id INT PRIMARY KEY,
+                       listeners BIGINT NOT NULL DEFAULT 0,
+                       fetched_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       INDEX idx_song_listeners_fetched_at (fetched_at),
+                       CONSTRAINT song_listeners_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE imports (
+                       id VARCHAR(32) PRIMARY KEY,
+                       status VARCHAR(20) NOT NULL DEFAULT 'running',
+                       checkpoint_row INT NOT NULL DEFAULT 0,
+                       created INT NOT NULL DEFAULT 0,
+                       updated INT NOT NULL DEFAULT 0,
+                       failed INT NOT NULL DEFAULT 0,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE jobs (
+                       id VARCHAR(32) PRIMARY KEY,
+                       kind VARCHAR(50) NOT NULL,
+                       status VARCHAR(20) NOT NULL DEFAULT 'queued'
+                           CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+                       total INT NOT NULL DEFAULT 0,
+                       processed INT NOT NULL DEFAULT 0,
+                       failed INT NOT NULL DEFAULT 0,
+                       result JSON,
+                       error TEXT,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       started_at DATETIME(6),
+                       finished_at DATETIME(6),
+                       updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       INDEX idx_jobs_status (status, kind)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- Embeddings are JSON arrays of floats, compared by the application
+CREATE TABLE song_embeddings (
+                       song_id INT PRIMARY KEY,
+                       model VARCHAR(255) NOT NULL,
+                       content_hash CHAR(32) NOT NULL,
+                       embedding JSON NOT NULL,
+                       updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       INDEX idx_song_embeddings_model (model),
+                       CONSTRAINT song_embeddings_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- The most widespread lyric words, refreshed periodically by the application. Terms are told apart by
+-- their exact letters, as the words of the lyrics are.
+CREATE TABLE search_terms (
+                       term VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin PRIMARY KEY,
+                       songs INT NOT NULL
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE classification_suggestions (
+                       id INT AUTO_INCREMENT PRIMARY KEY,
+                       song_id INT NOT NULL,
+                       kind VARCHAR(20) NOT NULL,
+                       value VARCHAR(100) NOT NULL,
+                       confidence DOUBLE NOT NULL,
+                       source VARCHAR(100) NOT NULL,
+                       status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       reviewed_at DATETIME(6),
+                       UNIQUE (song_id, kind, value),
+                       INDEX idx_classification_suggestions_status (status, created_at),
+                       CONSTRAINT classification_suggestions_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE provider_usage (
+                       provider VARCHAR(50) NOT NULL,
+                       day DATE NOT NULL,
+                       calls INT NOT NULL DEFAULT 0,
+                       PRIMARY KEY (provider, day)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE users (
+                       id INT AUTO_INCREMENT PRIMARY KEY,
+                       username VARCHAR(100) NOT NULL UNIQUE,
+                       password_hash VARCHAR(100) NOT NULL,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       role VARCHAR(20) NOT NULL DEFAULT 'viewer'
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE user_preferences (
+                       user_id INT PRIMARY KEY,
+                       page_size INT NOT NULL DEFAULT 0,
+                       sort VARCHAR(20) NOT NULL DEFAULT '',
+                       language VARCHAR(35) NOT NULL DEFAULT '',
+                       explicit_filter BOOLEAN NOT NULL DEFAULT FALSE,
+                       updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       api_compat VARCHAR(10) NOT NULL DEFAULT '',
+                       CONSTRAINT user_preferences_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE tags (
+                       id INT AUTO_INCREMENT PRIMARY KEY,
+                       name VARCHAR(50) NOT NULL UNIQUE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE song_tags (
+                       song_id INT NOT NULL,
+                       tag_id INT NOT NULL,
+                       PRIMARY KEY (song_id, tag_id),
+                       INDEX idx_song_tags_tag_id (tag_id),
+                       CONSTRAINT song_tags_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE,
+                       CONSTRAINT song_tags_tag_id_fkey FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE api_captures (
+                       id BIGINT AUTO_INCREMENT PRIMARY KEY,
+                       provider VARCHAR(50) NOT NULL,
+                       method VARCHAR(10) NOT NULL,
+                       url TEXT NOT NULL,
+                       request_headers JSON NOT NULL,
+                       request_body MEDIUMTEXT,
+                       status INT,
+                       response_headers JSON,
+                       response_body MEDIUMTEXT,
+                       duration_ms DOUBLE NOT NULL,
+                       error TEXT,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       INDEX idx_api_captures_provider (provider, id DESC)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE song_verse_index (
+                       song_id INT PRIMARY KEY,
+                       delimiter VARCHAR(255) NOT NULL,
+                       content_hash CHAR(32) NOT NULL,
+                       total_verses INT NOT NULL,
+                       indexed_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       CONSTRAINT song_verse_index_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE song_verses (
+                       song_id INT NOT NULL,
+                       number INT NOT NULL,
+                       label VARCHAR(255) NOT NULL DEFAULT '',
+                       start_offset INT NOT NULL,
+                       length INT NOT NULL,
+                       PRIMARY KEY (song_id, number),
+                       CONSTRAINT song_verses_song_id_fkey FOREIGN KEY (song_id) REFERENCES song_verse_index(song_id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE song_overrides (
+                       user_id INT NOT NULL,
+                       song_id INT NOT NULL,
+                       text MEDIUMTEXT NOT NULL,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       PRIMARY KEY (user_id, song_id),
+                       CONSTRAINT song_overrides_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+                       CONSTRAINT song_overrides_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- events is a JSON array of the event types the webhook subscribes to
+CREATE TABLE webhooks (
+                       id INT AUTO_INCREMENT PRIMARY KEY,
+                       url TEXT NOT NULL,
+                       events JSON NOT NULL,
+                       secret VARCHAR(255) NOT NULL,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       previous_secret VARCHAR(255),
+                       secret_rotated_at DATETIME(6)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE webhook_deliveries (
+                       id BIGINT AUTO_INCREMENT PRIMARY KEY,
+                       webhook_id INT NOT NULL,
+                       event_type VARCHAR(50) NOT NULL,
+                       payload JSON NOT NULL,
+                       status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                       attempts INT NOT NULL DEFAULT 0,
+                       next_attempt_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       response_status INT,
+                       error TEXT,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       delivered_at DATETIME(6),
+                       INDEX idx_webhook_deliveries_due (status, next_attempt_at),
+                       INDEX idx_webhook_deliveries_webhook (webhook_id, id DESC),
+                       CONSTRAINT webhook_deliveries_webhook_id_fkey FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE song_events (
+                       id BIGINT AUTO_INCREMENT PRIMARY KEY,
+                       event_type VARCHAR(50) NOT NULL,
+                       song_id INT,
+                       count INT NOT NULL DEFAULT 0,
+                       occurred_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       published_at DATETIME(6),
+                       INDEX idx_song_events_occurred_at (occurred_at),
+                       INDEX idx_song_events_unpublished (published_at, id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE snapshots (
+                       id INT AUTO_INCREMENT PRIMARY KEY,
+                       reason VARCHAR(50) NOT NULL,
+                       song_count INT NOT NULL,
+                       data JSON NOT NULL,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       restored_at DATETIME(6)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE genres (
+                       id INT AUTO_INCREMENT PRIMARY KEY,
+                       name VARCHAR(100) NOT NULL UNIQUE,
+                       parent_id INT,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+                       INDEX idx_genres_parent_id (parent_id),
+                       CONSTRAINT genres_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES genres(id) ON DELETE RESTRICT
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+-- MySQL does not let CHECK constraints name AUTO_INCREMENT columns, so a genre being its own parent is
+-- rejected here
+CREATE TRIGGER check_genre_parent
+    BEFORE UPDATE ON genres
+    FOR EACH ROW
+BEGIN
+    IF NEW.parent_id = NEW.id THEN
+        SIGNAL SQLSTATE '23000' SET MESSAGE_TEXT = 'genres_check: a genre cannot be its own parent';
+    END IF;
+END;
+
+CREATE TABLE song_genres (
+                       song_id INT NOT NULL,
+                       genre_id INT NOT NULL,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       PRIMARY KEY (song_id, genre_id),
+                       INDEX idx_song_genres_genre_id (genre_id),
+                       CONSTRAINT song_genres_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE,
+                       CONSTRAINT song_genres_genre_id_fkey FOREIGN KEY (genre_id) REFERENCES genres(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE song_titles (
+                       song_id INT NOT NULL,
+                       lang VARCHAR(35) NOT NULL,
+                       title VARCHAR(255) NOT NULL,
+                       kind VARCHAR(20) NOT NULL DEFAULT 'translation',
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+                       PRIMARY KEY (song_id, lang),
+                       FULLTEXT INDEX song_titles_search_idx (title),
+                       CONSTRAINT song_titles_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE song_ratings (
+                       user_id INT NOT NULL,
+                       song_id INT NOT NULL,
+                       rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       PRIMARY KEY (user_id, song_id),
+                       INDEX song_ratings_song_id_idx (song_id),
+                       CONSTRAINT song_ratings_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+                       CONSTRAINT song_ratings_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE song_favorites (
+                       user_id INT NOT NULL,
+                       song_id INT NOT NULL,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       PRIMARY KEY (user_id, song_id),
+                       INDEX song_favorites_song_id_idx (song_id),
+                       CONSTRAINT song_favorites_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+                       CONSTRAINT song_favorites_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE song_revisions (
+                       song_id INT NOT NULL,
+                       revision INT NOT NULL,
+                       reason VARCHAR(20) NOT NULL,
+                       restored_from INT,
+                       data JSON NOT NULL,
+                       created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       PRIMARY KEY (song_id, revision),
+                       CONSTRAINT song_revisions_song_id_fkey FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE trashed_songs (
+                       id INT PRIMARY KEY,
+                       group_name VARCHAR(255) NOT NULL,
+                       song_name VARCHAR(255) NOT NULL,
+                       data JSON NOT NULL,
+                       deleted_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+                       INDEX trashed_songs_deleted_at_idx (deleted_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,2 @@
+ALTER TABLE jobs DROP FOREIGN KEY jobs_owner_id_fkey,
+    DROP COLUMN owner_id;
@@ -0,0 +1,2 @@
+ALTER TABLE jobs ADD COLUMN owner_id INT,
+    ADD CONSTRAINT jobs_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE SET NULL;
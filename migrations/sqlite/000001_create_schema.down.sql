@@ -0,0 +1,32 @@
+DROP TABLE IF EXISTS trashed_songs;
+DROP TABLE IF EXISTS song_revisions;
+DROP TABLE IF EXISTS song_favorites;
+DROP TABLE IF EXISTS song_ratings;
+DROP TABLE IF EXISTS song_titles;
+DROP TABLE IF EXISTS song_genres;
+DROP TABLE IF EXISTS genres;
+DROP TABLE IF EXISTS snapshots;
+DROP TABLE IF EXISTS song_events;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS song_overrides;
+DROP TABLE IF EXISTS song_verses;
+DROP TABLE IF EXISTS song_verse_index;
+DROP TABLE IF EXISTS api_captures;
+DROP TABLE IF EXISTS song_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS user_preferences;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS provider_usage;
+DROP TABLE IF EXISTS classification_suggestions;
+DROP TABLE IF EXISTS search_terms;
+DROP TABLE IF EXISTS song_embeddings;
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS imports;
+DROP TABLE IF EXISTS song_listeners;
+DROP TABLE IF EXISTS song_trending;
+DROP TABLE IF EXISTS song_view_days;
+DROP TABLE IF EXISTS song_views;
+DROP TABLE IF EXISTS songs;
+DROP TABLE IF EXISTS artists;
+DROP TABLE IF EXISTS albums;
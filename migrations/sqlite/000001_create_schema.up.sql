@@ -0,0 +1,399 @@
+-- The schema of the SQLite backend: the PostgreSQL schema as of 000040 in a single migration. Times are
+-- stored in UTC as 'YYYY-MM-DD HH:MM:SS.SSS' text, so they compare in order; JSON is stored as text.
+
+CREATE TABLE albums (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,
+                       title VARCHAR(255) NOT NULL,
+                       group_name VARCHAR(255) NOT NULL,
+                       release_date VARCHAR(10),
+                       artwork_url TEXT,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE artists (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,
+                       name VARCHAR(255) NOT NULL UNIQUE,
+                       country CHAR(2),
+                       formed_year INTEGER,
+                       bio TEXT,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE songs (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,
+                       group_name VARCHAR(255) NOT NULL,
+                       song_name VARCHAR(255) NOT NULL,
+                       release_date VARCHAR(10),
+                       text TEXT,
+                       link VARCHAR(255),
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       enriched_at TIMESTAMP,
+                       notes TEXT,
+                       licensing_fee NUMERIC(12, 2),
+                       album TEXT,
+                       duration_ms INTEGER,
+                       isrc TEXT,
+                       artwork_url TEXT,
+                       -- metadata_synced_at is set once the track was looked up, found or not, so misses are not retried every run
+                       metadata_synced_at TIMESTAMP,
+                       enrichment_status TEXT NOT NULL DEFAULT 'complete'
+                           CHECK (enrichment_status IN ('pending_enrichment', 'complete', 'failed')),
+                       enrichment_error TEXT,
+                       legal_hold BOOLEAN NOT NULL DEFAULT FALSE,
+                       split_strategy TEXT,
+                       album_id INTEGER REFERENCES albums(id) ON DELETE SET NULL,
+                       artist_id INTEGER REFERENCES artists(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX songs_enriched_at_idx ON songs (enriched_at, id);
+CREATE INDEX idx_songs_pending_enrichment ON songs (id) WHERE enrichment_status = 'pending_enrichment';
+CREATE INDEX idx_songs_legal_hold ON songs (id) WHERE legal_hold;
+CREATE INDEX idx_songs_album_id ON songs (album_id) WHERE album_id IS NOT NULL;
+CREATE INDEX idx_songs_artist_id ON songs (artist_id);
+
+-- Every data column but updated_at itself and artist_id, which is derived from group_name, touches the song
+CREATE TRIGGER update_timestamp
+    AFTER UPDATE OF group_name, song_name, release_date, text, link, created_at, enriched_at, notes, licensing_fee,
+        album, duration_ms, isrc, artwork_url, metadata_synced_at, enrichment_status, enrichment_error, legal_hold,
+        split_strategy, album_id ON songs
+    FOR EACH ROW
+BEGIN
+    UPDATE songs SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+
+-- Every write path of songs names the group only, so the artist is resolved, and created when new, from it
+CREATE TRIGGER link_song_artist
+    AFTER INSERT ON songs
+    FOR EACH ROW
+BEGIN
+    INSERT INTO artists (name) VALUES (NEW.group_name) ON CONFLICT (name) DO NOTHING;
+    UPDATE songs SET artist_id = (SELECT id FROM artists WHERE name = NEW.group_name) WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER relink_song_artist
+    AFTER UPDATE OF group_name ON songs
+    FOR EACH ROW
+BEGIN
+    INSERT INTO artists (name) VALUES (NEW.group_name) ON CONFLICT (name) DO NOTHING;
+    UPDATE songs SET artist_id = (SELECT id FROM artists WHERE name = NEW.group_name) WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER update_album_timestamp
+    AFTER UPDATE OF title, group_name, release_date, artwork_url ON albums
+    FOR EACH ROW
+BEGIN
+    UPDATE albums SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER update_artist_timestamp
+    AFTER UPDATE OF name, country, formed_year, bio ON artists
+    FOR EACH ROW
+BEGIN
+    UPDATE artists SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+
+CREATE TABLE song_views (
+                       song_id INTEGER PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+                       views BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE song_view_days (
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       day DATE NOT NULL,
+                       views BIGINT NOT NULL DEFAULT 0,
+                       PRIMARY KEY (song_id, day)
+);
+
+CREATE TABLE song_trending (
+                       song_id INTEGER PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+                       score DOUBLE PRECISION NOT NULL,
+                       computed_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX song_trending_score_idx ON song_trending (score DESC);
+
+CREATE TABLE song_listeners (
+                       song_id INTEGER PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+                       listeners BIGINT NOT NULL DEFAULT 0,
+                       fetched_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX idx_song_listeners_fetched_at ON song_listeners (fetched_at);
+
+CREATE TABLE imports (
+                       id VARCHAR(32) PRIMARY KEY,
+                       status VARCHAR(20) NOT NULL DEFAULT 'running',
+                       checkpoint_row INTEGER NOT NULL DEFAULT 0,
+                       created INTEGER NOT NULL DEFAULT 0,
+                       updated INTEGER NOT NULL DEFAULT 0,
+                       failed INTEGER NOT NULL DEFAULT 0,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE jobs (
+                       id VARCHAR(32) PRIMARY KEY,
+                       kind VARCHAR(50) NOT NULL,
+                       status VARCHAR(20) NOT NULL DEFAULT 'queued'
+                           CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+                       total INTEGER NOT NULL DEFAULT 0,
+                       processed INTEGER NOT NULL DEFAULT 0,
+                       failed INTEGER NOT NULL DEFAULT 0,
+                       result BLOB,
+                       error TEXT,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       started_at TIMESTAMP,
+                       finished_at TIMESTAMP,
+                       updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX idx_jobs_unfinished ON jobs (kind) WHERE status IN ('queued', 'running');
+
+-- Embeddings are JSON arrays of floats, compared by the cosine_similarity function the application registers
+CREATE TABLE song_embeddings (
+                       song_id INTEGER PRIMARY KEY REFERENCES songs (id) ON DELETE CASCADE,
+                       model VARCHAR(255) NOT NULL,
+                       content_hash CHAR(32) NOT NULL,
+                       embedding TEXT NOT NULL,
+                       updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+-- The most widespread lyric words, refreshed periodically by the application
+CREATE TABLE search_terms (
+                       term TEXT PRIMARY KEY,
+                       songs INTEGER NOT NULL
+);
+
+CREATE TABLE classification_suggestions (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       kind VARCHAR(20) NOT NULL,
+                       value VARCHAR(100) NOT NULL,
+                       confidence DOUBLE PRECISION NOT NULL,
+                       source VARCHAR(100) NOT NULL,
+                       status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       reviewed_at TIMESTAMP,
+                       UNIQUE (song_id, kind, value)
+);
+
+CREATE INDEX idx_classification_suggestions_status ON classification_suggestions (status, created_at);
+
+CREATE TABLE provider_usage (
+                       provider VARCHAR(50) NOT NULL,
+                       day DATE NOT NULL,
+                       calls INTEGER NOT NULL DEFAULT 0,
+                       PRIMARY KEY (provider, day)
+);
+
+CREATE TABLE users (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,
+                       username VARCHAR(100) NOT NULL UNIQUE,
+                       password_hash VARCHAR(100) NOT NULL,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       role VARCHAR(20) NOT NULL DEFAULT 'viewer'
+);
+
+CREATE TABLE user_preferences (
+                       user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+                       page_size INTEGER NOT NULL DEFAULT 0,
+                       sort VARCHAR(20) NOT NULL DEFAULT '',
+                       language VARCHAR(35) NOT NULL DEFAULT '',
+                       explicit_filter BOOLEAN NOT NULL DEFAULT FALSE,
+                       updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       api_compat VARCHAR(10) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE tags (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,
+                       name VARCHAR(50) NOT NULL UNIQUE
+);
+
+CREATE TABLE song_tags (
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+                       PRIMARY KEY (song_id, tag_id)
+);
+
+CREATE INDEX idx_song_tags_tag_id ON song_tags(tag_id);
+
+CREATE TABLE api_captures (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,
+                       provider VARCHAR(50) NOT NULL,
+                       method VARCHAR(10) NOT NULL,
+                       url TEXT NOT NULL,
+                       request_headers BLOB NOT NULL,
+                       request_body TEXT,
+                       status INTEGER,
+                       response_headers BLOB,
+                       response_body TEXT,
+                       duration_ms DOUBLE PRECISION NOT NULL,
+                       error TEXT,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX idx_api_captures_provider ON api_captures (provider, id DESC);
+
+CREATE TABLE song_verse_index (
+                       song_id INTEGER PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+                       delimiter TEXT NOT NULL,
+                       content_hash CHAR(32) NOT NULL,
+                       total_verses INTEGER NOT NULL,
+                       indexed_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE song_verses (
+                       song_id INTEGER NOT NULL REFERENCES song_verse_index(song_id) ON DELETE CASCADE,
+                       number INTEGER NOT NULL,
+                       label TEXT NOT NULL DEFAULT '',
+                       start_offset INTEGER NOT NULL,
+                       length INTEGER NOT NULL,
+                       PRIMARY KEY (song_id, number)
+);
+
+CREATE TABLE song_overrides (
+                       user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       text TEXT NOT NULL,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       PRIMARY KEY (user_id, song_id)
+);
+
+-- events is a JSON array of the event types the webhook subscribes to
+CREATE TABLE webhooks (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,
+                       url TEXT NOT NULL,
+                       events TEXT NOT NULL,
+                       secret TEXT NOT NULL,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       previous_secret TEXT,
+                       secret_rotated_at TIMESTAMP
+);
+
+CREATE TABLE webhook_deliveries (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,
+                       webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+                       event_type VARCHAR(50) NOT NULL,
+                       payload BLOB NOT NULL,
+                       status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                       attempts INTEGER NOT NULL DEFAULT 0,
+                       next_attempt_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       response_status INTEGER,
+                       error TEXT,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       delivered_at TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, id DESC);
+
+CREATE TABLE song_events (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,
+                       event_type TEXT NOT NULL,
+                       song_id INTEGER,
+                       count INTEGER NOT NULL DEFAULT 0,
+                       occurred_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       published_at TIMESTAMP
+);
+
+CREATE INDEX idx_song_events_occurred_at ON song_events (occurred_at);
+CREATE INDEX idx_song_events_unpublished ON song_events (id) WHERE published_at IS NULL;
+
+CREATE TABLE snapshots (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,
+                       reason VARCHAR(50) NOT NULL,
+                       song_count INTEGER NOT NULL,
+                       data TEXT NOT NULL,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       restored_at TIMESTAMP
+);
+
+CREATE TABLE genres (
+                       id INTEGER PRIMARY KEY AUTOINCREMENT,
+                       name VARCHAR(100) NOT NULL,
+                       parent_id INTEGER REFERENCES genres(id) ON DELETE RESTRICT,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       CHECK (parent_id <> id)
+);
+
+CREATE UNIQUE INDEX idx_genres_name ON genres (LOWER(name));
+CREATE INDEX idx_genres_parent_id ON genres (parent_id);
+
+CREATE TRIGGER update_genre_timestamp
+    AFTER UPDATE OF name, parent_id ON genres
+    FOR EACH ROW
+BEGIN
+    UPDATE genres SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
+
+CREATE TABLE song_genres (
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       genre_id INTEGER NOT NULL REFERENCES genres(id) ON DELETE CASCADE,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       PRIMARY KEY (song_id, genre_id)
+);
+
+CREATE INDEX idx_song_genres_genre_id ON song_genres (genre_id);
+
+CREATE TABLE song_titles (
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       lang VARCHAR(35) NOT NULL,
+                       title VARCHAR(255) NOT NULL,
+                       kind VARCHAR(20) NOT NULL DEFAULT 'translation',
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       PRIMARY KEY (song_id, lang)
+);
+
+CREATE TRIGGER update_song_title_timestamp
+    AFTER UPDATE OF title, kind ON song_titles
+    FOR EACH ROW
+BEGIN
+    UPDATE song_titles SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+    WHERE song_id = NEW.song_id AND lang = NEW.lang;
+END;
+
+CREATE TABLE song_ratings (
+                       user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       PRIMARY KEY (user_id, song_id)
+);
+
+CREATE INDEX song_ratings_song_id_idx ON song_ratings (song_id);
+
+CREATE TABLE song_favorites (
+                       user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       PRIMARY KEY (user_id, song_id)
+);
+
+CREATE INDEX song_favorites_song_id_idx ON song_favorites (song_id);
+
+CREATE TABLE song_revisions (
+                       song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+                       revision INTEGER NOT NULL,
+                       reason VARCHAR(20) NOT NULL,
+                       restored_from INTEGER,
+                       data TEXT NOT NULL,
+                       created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+                       PRIMARY KEY (song_id, revision)
+);
+
+CREATE TABLE trashed_songs (
+                       id INTEGER PRIMARY KEY,
+                       group_name VARCHAR(255) NOT NULL,
+                       song_name VARCHAR(255) NOT NULL,
+                       data TEXT NOT NULL,
+                       deleted_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX trashed_songs_deleted_at_idx ON trashed_songs (deleted_at);
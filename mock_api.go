@@ -18,10 +18,11 @@ func main() {
 			return
 		}
 
-		response := map[string]string{
+		response := map[string]any{
 			"releaseDate": "16.07.2006",
 			"text":        "Ooh baby, don't you know I suffer?\n\nOoh baby, can you hear me moan?",
 			"link":        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+			"listeners":   len(group)*1000 + len(song),
 		}
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {